@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+
+	gardenerclient "github.com/gardener/gardener/pkg/client/core/clientset/versioned/typed/core/v1beta1"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/common/director"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/common/gardener"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/common/hyperscaler/azure"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/lms"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process/input"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process/provisioning"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/provider"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/provisioner"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/runtime"
+)
+
+// dependencies holds the clients of the external systems used by the broker
+type dependencies struct {
+	provisionerClient provisioner.Client
+	directorClient    provisioning.DirectorClient
+	lmsClient         lms.Client
+	k8sClient         client.Client
+
+	gardenerClient         gardenerclient.CoreV1beta1Interface
+	gardenerSecrets        corev1.SecretInterface
+	gardenerShoots         gardenerclient.ShootInterface
+	gardenerSecretBindings gardenerclient.SecretBindingInterface
+
+	componentsProvider input.ComponentListProvider
+	trialRegions       map[string]string
+
+	// the event hubs are created and removed using separate providers, so the dev mode can fake both flows
+	azureProvisioningProvider   azure.HyperscalerProvider
+	azureDeprovisioningProvider azure.HyperscalerProvider
+
+	close func()
+}
+
+// newDependencies creates the clients of the real external systems
+func newDependencies(ctx context.Context, cfg Config, logs logrus.FieldLogger) (dependencies, error) {
+	k8sCfg, err := config.GetConfig()
+	if err != nil {
+		return dependencies{}, err
+	}
+	cli, err := initClient(k8sCfg)
+	if err != nil {
+		return dependencies{}, err
+	}
+
+	gardenerClusterConfig, err := gardener.NewGardenerClusterConfig(cfg.Gardener.KubeconfigPath)
+	if err != nil {
+		return dependencies{}, err
+	}
+	gardenerClient, err := gardener.NewClient(gardenerClusterConfig)
+	if err != nil {
+		return dependencies{}, err
+	}
+	gardenerSecrets, err := gardener.NewGardenerSecretsInterface(gardenerClusterConfig, cfg.Gardener.Project)
+	if err != nil {
+		return dependencies{}, err
+	}
+	gardenerShoots, err := gardener.NewGardenerShootInterface(gardenerClusterConfig, cfg.Gardener.Project)
+	if err != nil {
+		return dependencies{}, err
+	}
+	gardenerSecretBindings, err := gardener.NewGardenerSecretBindingsInterface(gardenerClusterConfig, cfg.Gardener.Project)
+	if err != nil {
+		return dependencies{}, err
+	}
+
+	regions, err := provider.ReadPlatformRegionMappingFromFile(cfg.TrialRegionMappingFilePath)
+	if err != nil {
+		return dependencies{}, err
+	}
+
+	return dependencies{
+		provisionerClient: provisioner.NewProvisionerClient(cfg.Provisioning.URL, cfg.DumpProvisionerRequests),
+		directorClient:    director.NewDirectorClient(ctx, cfg.Director, logs.WithField("service", "directorClient")),
+		lmsClient:         lms.NewClient(cfg.LMS, logs.WithField("service", "lmsClient")),
+		k8sClient:         cli,
+
+		gardenerClient:         gardenerClient,
+		gardenerSecrets:        gardenerSecrets,
+		gardenerShoots:         gardenerShoots,
+		gardenerSecretBindings: gardenerSecretBindings,
+
+		componentsProvider: runtime.NewComponentsListProvider(cfg.ManagedRuntimeComponentsYAMLFilePath),
+		trialRegions:       regions,
+
+		azureProvisioningProvider:   azure.NewAzureProvider(),
+		azureDeprovisioningProvider: azure.NewAzureProvider(),
+
+		close: func() {},
+	}, nil
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"fmt"
+
+	gardenerapi "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	gardenerfake "github.com/gardener/gardener/pkg/client/core/clientset/versioned/fake"
+	gardenerclient "github.com/gardener/gardener/pkg/client/core/clientset/versioned/typed/core/v1beta1"
+	"github.com/kyma-project/kyma/components/kyma-operator/pkg/apis/installer/v1alpha1"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	coreV1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/common/director"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/common/hyperscaler"
+	azuretesting "github.com/kyma-project/control-plane/components/kyma-environment-broker/common/hyperscaler/azure/testing"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/avs"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/lms"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/provider"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/provisioner"
+	schema "github.com/kyma-project/control-plane/components/provisioner/pkg/gqlschema"
+)
+
+const (
+	devModeGardenerProject = "kyma-dev"
+	devModeDomain          = "kyma.local"
+	// devModeConfirmationTokenKey signs the confirmation tokens of the orphaned shoots cleanup
+	devModeConfirmationTokenKey = "kyma-dev-mode-confirmation-token"
+	// devModeSecretsPerHyperscaler is the number of hyperscaler accounts available for the global accounts
+	devModeSecretsPerHyperscaler = 10
+
+	shootGlobalAccountLabel  = "account"
+	shootSubAccountLabel     = "subaccount"
+	shootRuntimeIDAnnotation = "kcp.provisioner.kyma-project.io/runtime-id"
+)
+
+// newDevModeDependencies creates in-process fakes of all external systems, which allows to run the provisioning,
+// deprovisioning and upgrade flows locally and in e2e tests. The configuration is adjusted to use the memory storage
+// and the fake AVS server, EDP and IAS integrations are disabled.
+func newDevModeDependencies(cfg *Config, logs logrus.FieldLogger) (dependencies, error) {
+	cfg.DbInMemory = true
+	cfg.EDP.Disabled = true
+	cfg.IAS.Disabled = true
+	if cfg.Gardener.Project == "" {
+		cfg.Gardener.Project = devModeGardenerProject
+	}
+	if cfg.LMS.ClusterType == "" {
+		cfg.LMS.ClusterType = lms.ClusterTypeSingleNode
+	}
+	if cfg.OrphanCleanup.ConfirmationTokenKey == "" {
+		cfg.OrphanCleanup.ConfirmationTokenKey = devModeConfirmationTokenKey
+	}
+
+	avsServer := avs.NewFakeServer()
+	avsServer.Configure(&cfg.Avs)
+
+	sch := k8sruntime.NewScheme()
+	if err := coreV1.AddToScheme(sch); err != nil {
+		avsServer.Close()
+		return dependencies{}, errors.Wrap(err, "while creating kubernetes scheme")
+	}
+
+	gardenerNamespace := fmt.Sprintf("garden-%s", cfg.Gardener.Project)
+	gardenerClient := gardenerfake.NewSimpleClientset().CoreV1beta1()
+	gardenerSecrets := k8sfake.NewSimpleClientset(fixDevModeHyperscalerSecrets(gardenerNamespace)...).CoreV1().Secrets(gardenerNamespace)
+
+	regions := map[string]string{}
+	if cfg.TrialRegionMappingFilePath != "" {
+		r, err := provider.ReadPlatformRegionMappingFromFile(cfg.TrialRegionMappingFilePath)
+		if err != nil {
+			avsServer.Close()
+			return dependencies{}, err
+		}
+		regions = r
+	}
+
+	return dependencies{
+		provisionerClient: &devModeProvisionerClient{
+			FakeClient: provisioner.NewFakeClientWithAutoFinish(),
+			shoots:     gardenerClient.Shoots(gardenerNamespace),
+			log:        logs.WithField("service", "devModeProvisioner"),
+		},
+		directorClient: director.NewFakeClient(devModeDomain),
+		lmsClient:      lms.NewFakeClient(0),
+		k8sClient:      fake.NewFakeClientWithScheme(sch),
+
+		gardenerClient:         gardenerClient,
+		gardenerSecrets:        gardenerSecrets,
+		gardenerShoots:         gardenerClient.Shoots(gardenerNamespace),
+		gardenerSecretBindings: gardenerClient.SecretBindings(gardenerNamespace),
+
+		componentsProvider: devModeComponentsProvider{},
+		trialRegions:       regions,
+
+		azureProvisioningProvider:   azuretesting.NewFakeHyperscalerProvider(azuretesting.NewFakeNamespaceClientHappyPath()),
+		azureDeprovisioningProvider: azuretesting.NewFakeHyperscalerProvider(azuretesting.NewFakeNamespaceClientResourceGroupDoesNotExist()),
+
+		close: avsServer.Close,
+	}, nil
+}
+
+// fixDevModeHyperscalerSecrets creates the shared secrets used by the trial runtimes and the pool of secrets
+// assigned to the global accounts
+func fixDevModeHyperscalerSecrets(namespace string) []k8sruntime.Object {
+	credentials := map[string][]byte{
+		"subscriptionID": []byte("dev-subscription"),
+		"clientID":       []byte("dev-client"),
+		"clientSecret":   []byte("dev-secret"),
+		"tenantID":       []byte("dev-tenant"),
+	}
+
+	var secrets []k8sruntime.Object
+	for _, hyperscalerType := range []hyperscaler.Type{hyperscaler.Azure, hyperscaler.GCP} {
+		secrets = append(secrets, &coreV1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-shared", hyperscalerType),
+				Namespace: namespace,
+				Labels: map[string]string{
+					"hyperscalerType": string(hyperscalerType),
+					"shared":          "true",
+				},
+			},
+			Data: credentials,
+		})
+		for i := 0; i < devModeSecretsPerHyperscaler; i++ {
+			secrets = append(secrets, &coreV1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("%s-%d", hyperscalerType, i),
+					Namespace: namespace,
+					Labels: map[string]string{
+						"hyperscalerType": string(hyperscalerType),
+					},
+				},
+				Data: credentials,
+			})
+		}
+	}
+
+	return secrets
+}
+
+// devModeProvisionerClient maintains the Gardener shoots of the runtimes in the fake Gardener,
+// so the runtimes can be targeted by orchestrations
+type devModeProvisionerClient struct {
+	*provisioner.FakeClient
+	shoots gardenerclient.ShootInterface
+	log    logrus.FieldLogger
+}
+
+func (c *devModeProvisionerClient) ProvisionRuntime(accountID, subAccountID string, config schema.ProvisionRuntimeInput) (schema.OperationStatus, error) {
+	status, err := c.FakeClient.ProvisionRuntime(accountID, subAccountID, config)
+	if err != nil {
+		return status, err
+	}
+
+	runtimeID := *status.RuntimeID
+	shoot := &gardenerapi.Shoot{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: fmt.Sprintf("c-%s", runtimeID[:7]),
+			Labels: map[string]string{
+				shootGlobalAccountLabel: accountID,
+				shootSubAccountLabel:    subAccountID,
+			},
+			Annotations: map[string]string{
+				shootRuntimeIDAnnotation: runtimeID,
+			},
+		},
+		Spec: gardenerapi.ShootSpec{
+			Maintenance: &gardenerapi.Maintenance{
+				TimeWindow: &gardenerapi.MaintenanceTimeWindow{
+					Begin: "030000+0000",
+					End:   "040000+0000",
+				},
+			},
+		},
+	}
+	if config.ClusterConfig != nil && config.ClusterConfig.GardenerConfig != nil {
+		shoot.Spec.Region = config.ClusterConfig.GardenerConfig.Region
+	}
+	if _, err := c.shoots.Create(shoot); err != nil {
+		c.log.Warnf("cannot create shoot for runtime %s: %s", runtimeID, err)
+	}
+
+	return status, nil
+}
+
+func (c *devModeProvisionerClient) DeprovisionRuntime(accountID, runtimeID string) (string, error) {
+	shoots, err := c.shoots.List(metav1.ListOptions{})
+	if err != nil {
+		c.log.Warnf("cannot list shoots: %s", err)
+	} else {
+		for _, shoot := range shoots.Items {
+			if shoot.Annotations[shootRuntimeIDAnnotation] != runtimeID {
+				continue
+			}
+			if err := c.shoots.Delete(shoot.Name, &metav1.DeleteOptions{}); err != nil {
+				c.log.Warnf("cannot delete shoot %s: %s", shoot.Name, err)
+			}
+		}
+	}
+
+	return c.FakeClient.DeprovisionRuntime(accountID, runtimeID)
+}
+
+// devModeComponentsProvider provides an empty list of Kyma components instead of fetching them from the Kyma release
+type devModeComponentsProvider struct{}
+
+func (devModeComponentsProvider) AllComponents(string) ([]v1alpha1.KymaComponent, error) {
+	return []v1alpha1.KymaComponent{}, nil
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"io"
 	"log"
@@ -12,7 +13,7 @@ import (
 
 	"code.cloudfoundry.org/lager"
 	"github.com/dlmiddlecote/sqlstats"
-	gardenerclient "github.com/gardener/gardener/pkg/client/core/clientset/versioned/typed/core/v1beta1"
+	"github.com/google/uuid"
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
 	"github.com/pkg/errors"
@@ -24,39 +25,60 @@ import (
 	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
-	"sigs.k8s.io/controller-runtime/pkg/client/config"
 
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/common/director"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/common/gardener"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/common/hyperscaler"
-	"github.com/kyma-project/control-plane/components/kyma-environment-broker/common/hyperscaler/azure"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/accountmigration"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/appinfo"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/auditlog"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/avs"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/capacity"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/consistency"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/edp"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/event"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/freetier"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/health"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/httputil"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/ias"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/installeroverrides"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/killswitch"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/kymaversion"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/lms"
+	kebLogger "github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/logger"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/metrics"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/middleware"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/operation"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/orchestration"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/orchestration/cluster"
 	orchestrate "github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/orchestration/handlers"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/orchestration/kyma"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/orphan"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process"
+	accountmigrationprocess "github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process/accountmigration"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process/deprovisioning"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process/input"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process/migrate_plan"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process/provisioning"
+	suspensionprocess "github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process/suspension"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process/update"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process/upgrade_cluster"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process/upgrade_kyma"
-	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/provider"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/provisioner"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/runtime"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/runtime/components"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/runtimestate"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/scheduler"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/shootname"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/staleoperation"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession/dbmodel"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/suspension"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/tracing"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/trialexpiration"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/upgradeverification"
 )
 
 // Config holds configuration for the whole application
@@ -78,6 +100,10 @@ type Config struct {
 	// because some data must not be visible in the log file.
 	DumpProvisionerRequests bool `envconfig:"default=false"`
 
+	// LogLevel is the initial log level of all components. The level of a single component
+	// can be changed at runtime using the /log-levels endpoint exposed on the status port.
+	LogLevel string `envconfig:"default=info"`
+
 	Host       string `envconfig:"optional"`
 	Port       string `envconfig:"default=8080"`
 	StatusPort string `envconfig:"default=8071"`
@@ -90,6 +116,7 @@ type Config struct {
 	ServiceManager provisioning.ServiceManagerOverrideConfig
 
 	KymaVersion                          string
+	KymaChannels                         kymaversion.Config
 	EnableOnDemandVersion                bool `envconfig:"default=false"`
 	ManagedRuntimeComponentsYAMLFilePath string
 	DefaultRequestRegion                 string `envconfig:"default=cf-eu10"`
@@ -108,17 +135,75 @@ type Config struct {
 		Name      string
 	}
 
+	// ProvisioningStages and DeprovisioningStages define the timeouts and the SLOs of the process stages,
+	// the stage with the zero timeout is not limited
+	ProvisioningStages struct {
+		PreparationTimeout  time.Duration `envconfig:"default=2h"`
+		PreparationSLO      time.Duration `envconfig:"default=5m"`
+		RegistrationTimeout time.Duration `envconfig:"default=2h"`
+		RegistrationSLO     time.Duration `envconfig:"default=5m"`
+		InstallationTimeout time.Duration `envconfig:"default=0"`
+		InstallationSLO     time.Duration `envconfig:"default=1h"`
+	}
+	DeprovisioningStages struct {
+		CleanupTimeout        time.Duration `envconfig:"default=2h"`
+		CleanupSLO            time.Duration `envconfig:"default=5m"`
+		RuntimeRemovalTimeout time.Duration `envconfig:"default=0"`
+		RuntimeRemovalSLO     time.Duration `envconfig:"default=30m"`
+	}
+
 	TrialRegionMappingFilePath string
 	MaxPaginationPage          int `envconfig:"default=100"`
+
+	// CapacityStatsTTL defines how long the capacity stats are reused by the /info/capacity endpoint and the metrics.
+	// The value 0 disables the cache.
+	CapacityStatsTTL time.Duration `envconfig:"default=5m"`
+
+	Orchestration orchestration.Config
+
+	// Scheduler runs the periodic jobs, such as the removal of the old runtime states and the consistency check,
+	// in a single broker replica at a time
+	Scheduler scheduler.Config
+
+	RuntimeStateRetention runtimestate.Config
+
+	ShootNameBackfill shootname.Config
+
+	FreeTier freetier.Config
+
+	Consistency consistency.Config
+
+	OrphanCleanup orphan.Config
+
+	// Gateway configures the verification of the ID tokens which the API gateway adds to the audited requests
+	Gateway httputil.GatewayConfig
+
+	TrialExpiration trialexpiration.Config
+
+	StaleOperations staleoperation.Config
+
+	OperationsBatch operation.BatchConfig
+
+	UpgradeVerification upgradeverification.Config
+
+	Tracing tracing.Config
 }
 
 func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// create and fill config
+	devMode := flag.Bool("dev-mode", false, "run the broker with in-process fakes of all external systems and the memory storage")
+	flag.Parse()
+
+	// create and fill config, in the dev mode all configuration options are optional
 	var cfg Config
-	err := envconfig.InitWithPrefix(&cfg, "APP")
+	var err error
+	if *devMode {
+		err = envconfig.InitWithOptions(&cfg, envconfig.Options{Prefix: "APP", AllOptional: true})
+	} else {
+		err = envconfig.InitWithPrefix(&cfg, "APP")
+	}
 	fatalOnError(err)
 
 	// create logger
@@ -130,21 +215,31 @@ func main() {
 
 	logs := logrus.New()
 	logs.SetFormatter(&logrus.JSONFormatter{})
+	logLevel, err := logrus.ParseLevel(cfg.LogLevel)
+	fatalOnError(err)
+	logs.SetLevel(logLevel)
+	logLevels := kebLogger.NewLevels(logs)
 
-	logger.Info("Registering healthz endpoint for health probes")
-	health.NewServer(cfg.Host, cfg.StatusPort, logs).ServeAsync()
+	flushSpans, err := tracing.Init(cfg.Tracing, logs.WithField("service", "tracing"))
+	fatalOnError(err)
+	defer flushSpans()
 
-	// create provisioner client
-	provisionerClient := provisioner.NewProvisionerClient(cfg.Provisioning.URL, cfg.DumpProvisionerRequests)
+	logger.Info("Registering healthz and log levels endpoints")
+	health.NewServer(cfg.Host, cfg.StatusPort, logs).ServeAsync(kebLogger.NewLevelsHandler(logLevels, logs.WithField("service", "logLevels")))
 
-	// create kubernetes client
-	k8sCfg, err := config.GetConfig()
-	fatalOnError(err)
-	cli, err := initClient(k8sCfg)
+	// create clients of the external systems
+	var deps dependencies
+	if *devMode {
+		logger.Info("Running in the dev mode, all external systems are faked")
+		deps, err = newDevModeDependencies(&cfg, logs)
+	} else {
+		deps, err = newDependencies(ctx, cfg, logs)
+	}
 	fatalOnError(err)
-
-	// create director client
-	directorClient := director.NewDirectorClient(ctx, cfg.Director, logs.WithField("service", "directorClient"))
+	defer deps.close()
+	provisionerClient := deps.provisionerClient
+	directorClient := deps.directorClient
+	cli := deps.k8sClient
 
 	// create storage
 	var db storage.BrokerStorage
@@ -160,7 +255,7 @@ func main() {
 
 	// LMS
 	fatalOnError(cfg.LMS.Validate())
-	lmsClient := lms.NewClient(cfg.LMS, logs.WithField("service", "lmsClient"))
+	lmsClient := deps.lmsClient
 	lmsTenantManager := lms.NewTenantManager(db.LMSTenants(), lmsClient, logs.WithField("service", "lmsTenantManager"))
 
 	// Register disabler. Convention:
@@ -175,24 +270,13 @@ func main() {
 
 	disabledComponentsProvider := runtime.NewDisabledComponentsProvider()
 
-	runtimeProvider := runtime.NewComponentsListProvider(cfg.ManagedRuntimeComponentsYAMLFilePath)
-	gardenerClusterConfig, err := gardener.NewGardenerClusterConfig(cfg.Gardener.KubeconfigPath)
-	fatalOnError(err)
-	gardenerClient, err := gardener.NewClient(gardenerClusterConfig)
-	fatalOnError(err)
-	gardenerSecrets, err := gardener.NewGardenerSecretsInterface(gardenerClusterConfig, cfg.Gardener.Project)
-	fatalOnError(err)
-	gardenerShoots, err := gardener.NewGardenerShootInterface(gardenerClusterConfig, cfg.Gardener.Project)
-	fatalOnError(err)
-
-	gardenerAccountPool := hyperscaler.NewAccountPool(gardenerSecrets, gardenerShoots)
-	gardenerSharedPool := hyperscaler.NewSharedGardenerAccountPool(gardenerSecrets, gardenerShoots)
+	gardenerAccountPool := hyperscaler.NewAccountPool(deps.gardenerSecrets, deps.gardenerShoots)
+	gardenerSharedPool := hyperscaler.NewSharedGardenerAccountPool(deps.gardenerSecrets, deps.gardenerShoots)
 	accountProvider := hyperscaler.NewAccountProvider(gardenerAccountPool, gardenerSharedPool)
+	byoSubscriptions := hyperscaler.NewBYOSubscriptions(deps.gardenerSecrets, deps.gardenerSecretBindings)
 
-	regions, err := provider.ReadPlatformRegionMappingFromFile(cfg.TrialRegionMappingFilePath)
-	fatalOnError(err)
-	logs.Infof("Platform region mapping for trial: %v", regions)
-	inputFactory, err := input.NewInputBuilderFactory(optComponentsSvc, disabledComponentsProvider, runtimeProvider, cfg.Provisioning, cfg.KymaVersion, regions)
+	logs.Infof("Platform region mapping for trial: %v", deps.trialRegions)
+	inputFactory, err := input.NewInputBuilderFactory(optComponentsSvc, disabledComponentsProvider, deps.componentsProvider, cfg.Provisioning, cfg.KymaVersion, deps.trialRegions)
 	fatalOnError(err)
 
 	edpClient := edp.NewClient(cfg.EDP, logs.WithField("service", "edpClient"))
@@ -216,16 +300,26 @@ func main() {
 
 	// metrics collectors
 	metrics.RegisterAll(eventBroker, db.Operations(), db.Instances())
+	process.RegisterStateTransitionRecorder(eventBroker, db.OperationEvents())
+	stepDurationCollector := metrics.NewStepDurationCollector()
+	prometheus.MustRegister(stepDurationCollector)
+	stepHooks := process.StepHooks{stepDurationCollector}
 
 	// setup operation managers
-	provisionManager := provisioning.NewManager(db.Operations(), eventBroker, logs.WithField("provisioning", "manager"))
-	deprovisionManager := deprovisioning.NewManager(db.Operations(), eventBroker, logs.WithField("deprovisioning", "manager"))
+	provisionManager := provisioning.NewManager(db.Operations(), eventBroker, logLevels.Component("provisioning"))
+	deprovisionManager := deprovisioning.NewManager(db.Operations(), eventBroker, logLevels.Component("deprovisioning"))
+	for _, hook := range stepHooks {
+		provisionManager.AddHook(hook)
+		deprovisionManager.AddHook(hook)
+	}
 
 	// define steps
-	kymaVersionConfigurator := provisioning.NewKymaVersionConfigurator(ctx, cli, cfg.VersionConfig.Namespace, cfg.VersionConfig.Name, logs)
+	kymaVersionConfigurator := kymaversion.NewResolver(cfg.KymaChannels, db.KymaChannels(),
+		provisioning.NewKymaVersionConfigurator(ctx, cli, cfg.VersionConfig.Namespace, cfg.VersionConfig.Name, logs))
 	provisioningInit := provisioning.NewInitialisationStep(db.Operations(), db.Instances(),
 		provisionerClient, directorClient, inputFactory, externalEvalCreator, iasTypeSetter, cfg.Provisioning.Timeout,
-		kymaVersionConfigurator)
+		kymaVersionConfigurator, provisioning.NewShootDiagnostics(deps.gardenerShoots),
+		provisioning.NewRegistryPullSecrets(upgradeverification.NewRuntimeClient))
 	provisionManager.InitStep(provisioningInit)
 
 	provisioningSteps := []struct {
@@ -256,7 +350,7 @@ func main() {
 		{
 			weight: 2,
 			step: provisioning.NewSkipForTrialPlanStep(db.Operations(),
-				provisioning.NewProvisionAzureEventHubStep(db.Operations(), azure.NewAzureProvider(), accountProvider, ctx)),
+				provisioning.NewProvisionAzureEventHubStep(db.Operations(), deps.azureProvisioningProvider, accountProvider, ctx)),
 		},
 		{
 			weight: 2,
@@ -267,6 +361,10 @@ func main() {
 			weight: 2,
 			step:   provisioning.NewOverridesFromSecretsAndConfigStep(ctx, cli, db.Operations()),
 		},
+		{
+			weight: 2,
+			step:   provisioning.NewInstallerOverridesStep(db.Operations(), db.InstallerOverrides()),
+		},
 		{
 			weight: 2,
 			step:   provisioning.NewServiceManagerOverridesStep(db.Operations(), cfg.ServiceManager),
@@ -287,7 +385,7 @@ func main() {
 		},
 		{
 			weight: 10,
-			step:   provisioning.NewCreateRuntimeStep(db.Operations(), db.RuntimeStates(), db.Instances(), provisionerClient),
+			step:   provisioning.NewCreateRuntimeStep(db.Operations(), db.RuntimeStates(), db.Instances(), provisionerClient, directorClient),
 		},
 	}
 	for _, step := range provisioningSteps {
@@ -295,8 +393,28 @@ func main() {
 			provisionManager.AddStep(step.weight, step.step)
 		}
 	}
+	// the installation stage lasts until the provisioning finishes, it includes the waiting for the Provisioner
+	// done by the initialisation step
+	provisionManager.DefineStage(1, process.Stage{Name: "preparation", Timeout: cfg.ProvisioningStages.PreparationTimeout, SLO: cfg.ProvisioningStages.PreparationSLO})
+	provisionManager.DefineStage(5, process.Stage{Name: "registration", Timeout: cfg.ProvisioningStages.RegistrationTimeout, SLO: cfg.ProvisioningStages.RegistrationSLO})
+	provisionManager.DefineStage(10, process.Stage{Name: "installation", Timeout: cfg.ProvisioningStages.InstallationTimeout, SLO: cfg.ProvisioningStages.InstallationSLO})
+
+	// cleanup of external systems is independent, run it concurrently
+	externalCleanupSteps := []deprovisioning.Step{
+		deprovisioning.NewAvsEvaluationsRemovalStep(avsDel, externalEvalAssistant, internalEvalAssistant),
+		deprovisioning.NewSkipForTrialPlanStep(
+			deprovisioning.NewDeprovisionAzureEventHubStep(deps.azureDeprovisioningProvider, accountProvider, ctx)),
+		deprovisioning.NewLMSDeregistrationStep(db.Operations(), lmsClient),
+	}
+	if !cfg.EDP.Disabled {
+		externalCleanupSteps = append(externalCleanupSteps, deprovisioning.NewEDPDeregistrationStep(edpClient, cfg.EDP))
+	}
+	if !cfg.IAS.Disabled {
+		externalCleanupSteps = append(externalCleanupSteps, deprovisioning.NewIASDeregistrationStep(bundleBuilder))
+	}
 
-	deprovisioningInit := deprovisioning.NewInitialisationStep(db.Operations(), db.Instances(), provisionerClient, accountProvider)
+	deprovisioningInit := deprovisioning.NewInitialisationStep(db.Operations(), db.Instances(), db.InstancesArchived(), db.FreeTierUsage(), provisionerClient, accountProvider,
+		deprovisioning.NewRemoveSubscriptionSecretStep(db.Operations(), byoSubscriptions))
 	deprovisionManager.InitStep(deprovisioningInit)
 	deprovisioningSteps := []struct {
 		disabled bool
@@ -305,22 +423,7 @@ func main() {
 	}{
 		{
 			weight: 1,
-			step:   deprovisioning.NewAvsEvaluationsRemovalStep(avsDel, db.Operations(), externalEvalAssistant, internalEvalAssistant),
-		},
-		{
-			weight: 1,
-			step: deprovisioning.NewSkipForTrialPlanStep(db.Operations(),
-				deprovisioning.NewDeprovisionAzureEventHubStep(db.Operations(), azure.NewAzureProvider(), accountProvider, ctx)),
-		},
-		{
-			weight:   1,
-			step:     deprovisioning.NewEDPDeregistrationStep(edpClient, cfg.EDP),
-			disabled: cfg.EDP.Disabled,
-		},
-		{
-			weight:   1,
-			step:     deprovisioning.NewIASDeregistrationStep(db.Operations(), bundleBuilder),
-			disabled: cfg.IAS.Disabled,
+			step:   deprovisioning.NewParallelStep(db.Operations(), externalCleanupSteps...),
 		},
 		{
 			weight: 10,
@@ -332,24 +435,89 @@ func main() {
 			deprovisionManager.AddStep(step.weight, step.step)
 		}
 	}
+	deprovisionManager.DefineStage(1, process.Stage{Name: "cleanup", Timeout: cfg.DeprovisioningStages.CleanupTimeout, SLO: cfg.DeprovisioningStages.CleanupSLO})
+	deprovisionManager.DefineStage(10, process.Stage{Name: "runtime_removal", Timeout: cfg.DeprovisioningStages.RuntimeRemovalTimeout, SLO: cfg.DeprovisioningStages.RuntimeRemovalSLO})
 
 	// run queues
 	const workersAmount = 5
-	provisionQueue := process.NewQueue(provisionManager, logs)
+	provisionQueue := process.NewQueue(provisionManager, logLevels.Component("provisioning"))
 	provisionQueue.Run(ctx.Done(), workersAmount)
 
-	deprovisionQueue := process.NewQueue(deprovisionManager, logs)
+	deprovisionQueue := process.NewQueue(deprovisionManager, logLevels.Component("deprovisioning"))
 	deprovisionQueue.Run(ctx.Done(), workersAmount)
 
-	plansValidator, err := broker.NewPlansSchemaValidator()
+	// the plan migration provisions the new runtime using the provisioning queue and removes the runtime of the source plan
+	// only when the new runtime is provisioned
+	planMigrationManager := migrate_plan.NewManager(db.Operations(), eventBroker, logLevels.Component("planMigration"))
+	for _, hook := range stepHooks {
+		planMigrationManager.AddHook(hook)
+	}
+	planMigrationManager.InitStep(migrate_plan.NewInitialisationStep(db.Operations(), db.Instances(), provisionerClient))
+	planMigrationManager.AddStep(1, migrate_plan.NewProvisionTargetRuntimeStep(db.Operations(), db.Instances(), provisionQueue))
+	planMigrationManager.AddStep(2, migrate_plan.NewRemoveSourceRuntimeStep(db.Operations(), db.Instances(), provisionerClient))
+	planMigrationManager.AddStep(3, migrate_plan.NewFinishStep(db.Operations(), db.FreeTierUsage()))
+
+	planMigrationQueue := process.NewQueue(planMigrationManager, logLevels.Component("planMigration"))
+	planMigrationQueue.Run(ctx.Done(), workersAmount)
+
+	// the update applies the changed parameters to the cluster with the shoot upgrade in the Provisioner,
+	// the OIDC configuration is set in the shoot in Gardener
+	gardenerNamespace := fmt.Sprintf("garden-%s", cfg.Gardener.Project)
+	updateManager := update.NewManager(db.Operations(), eventBroker, logLevels.Component("update"))
+	for _, hook := range stepHooks {
+		updateManager.AddHook(hook)
+	}
+	updateManager.InitStep(update.NewInitialisationStep(db.Operations(), db.Instances(), provisionerClient))
+	updateManager.AddStep(1, update.NewUpdateOIDCStep(db.Operations(), deps.gardenerClient.Shoots(gardenerNamespace)))
+	updateManager.AddStep(2, update.NewUpgradeShootStep(db.Operations(), db.Instances(), provisionerClient))
+
+	updateQueue := process.NewQueue(updateManager, logLevels.Component("update"))
+	updateQueue.Run(ctx.Done(), workersAmount)
+
+	// the suspension hibernates the cluster of the trial runtime in Gardener, the resumption wakes it up
+	suspensionManager := suspensionprocess.NewManager(db.Operations(), eventBroker, logLevels.Component("suspension"))
+	for _, hook := range stepHooks {
+		suspensionManager.AddHook(hook)
+	}
+	suspensionManager.InitStep(suspensionprocess.NewInitialisationStep(db.Operations(), deps.gardenerClient.Shoots(gardenerNamespace)))
+	suspensionManager.AddStep(1, suspensionprocess.NewHibernateShootStep(db.Operations(), deps.gardenerClient.Shoots(gardenerNamespace)))
+
+	suspensionQueue := process.NewQueue(suspensionManager, logLevels.Component("suspension"))
+	suspensionQueue.Run(ctx.Done(), workersAmount)
+
+	// the account migration moves the instance to another global account and subaccount
+	accountMigrationManager := accountmigrationprocess.NewManager(db.Operations(), eventBroker, logLevels.Component("accountMigration"))
+	for _, hook := range stepHooks {
+		accountMigrationManager.AddHook(hook)
+	}
+	accountMigrationManager.InitStep(accountmigrationprocess.NewInitialisationStep(db.Operations()))
+	accountMigrationManager.AddStep(1, accountmigrationprocess.NewMigrateAccountStep(db.Operations(), db.Instances(), directorClient, deps.gardenerClient.Shoots(gardenerNamespace)))
+
+	accountMigrationQueue := process.NewQueue(accountMigrationManager, logLevels.Component("accountMigration"))
+	accountMigrationQueue.Run(ctx.Done(), workersAmount)
+
+	// the schemas served in the catalog are used to validate the provisioning parameters
+	plansSchemas, err := broker.NewPlansSchemas(optComponentsSvc)
 	fatalOnError(err)
+	plansValidator := plansSchemas.Validators()
+
+	// the catalog fetched from the plan configuration service replaces the built-in one
+	var catalog broker.CatalogSource
+	if cfg.Broker.Catalog.URL != "" {
+		remoteCatalog := broker.NewRemoteCatalog(cfg.Broker.Catalog, &http.Client{Timeout: 30 * time.Second}, logs)
+		remoteCatalog.Run(ctx)
+		catalog = remoteCatalog
+	}
+
+	freeTier := freetier.NewService(db.FreeTierUsage(), cfg.FreeTier, logs)
+	killSwitches := killswitch.NewService(db.KillSwitches(), logLevels.Component("killSwitches"))
 
 	// create KymaEnvironmentBroker endpoints
 	kymaEnvBroker := &broker.KymaEnvironmentBroker{
-		broker.NewServices(cfg.Broker, optComponentsSvc, logs),
-		broker.NewProvision(cfg.Broker, db.Operations(), db.Instances(), provisionQueue, inputFactory, plansValidator, cfg.EnableOnDemandVersion, logs),
+		broker.NewServices(cfg.Broker, plansSchemas, catalog, logs),
+		broker.NewProvision(cfg.Broker, db.Operations(), db.Instances(), provisionQueue, inputFactory, plansValidator, byoSubscriptions, freeTier, killSwitches, cfg.EnableOnDemandVersion, logs),
 		broker.NewDeprovision(db.Instances(), db.Operations(), deprovisionQueue, logs),
-		broker.NewUpdate(logs),
+		broker.NewUpdate(cfg.Broker, db.Instances(), db.Operations(), byoSubscriptions, planMigrationQueue, updateQueue, plansValidator, logs),
 		broker.NewGetInstance(db.Instances(), logs),
 		broker.NewLastOperation(db.Operations(), logs),
 		broker.NewBind(logs),
@@ -360,29 +528,96 @@ func main() {
 
 	// create server
 	router := mux.NewRouter()
+	router.Use(tracing.Middleware(cfg.Tracing.ServiceName))
+	router.Use(middleware.AddCorrelationIDToContext())
 
 	// create info endpoints
 	respWriter := httputil.NewResponseWriter(logs, cfg.DevelopmentMode)
 	runtimesInfoHandler := appinfo.NewRuntimeInfoHandler(db.Instances(), cfg.DefaultRequestRegion, respWriter)
 	router.Handle("/info/runtimes", runtimesInfoHandler)
+	operationStatsHandler := appinfo.NewOperationStatsHandler(db.Operations(), respWriter)
+	router.Handle("/info/operations/stats", operationStatsHandler)
+	regionsInfoHandler := appinfo.NewRegionsInfoHandler(db.Operations(), gardenerAccountPool, respWriter)
+	router.Handle("/info/regions", regionsInfoHandler)
+	capacityStats := capacity.NewStatsCache(capacity.NewCalculator(db.Instances()), cfg.CapacityStatsTTL)
+	capacityInfoHandler := appinfo.NewCapacityInfoHandler(capacityStats, respWriter)
+	router.Handle("/info/capacity", capacityInfoHandler)
+	prometheus.MustRegister(metrics.NewCapacityCollector(capacityStats))
 
 	// create metrics endpoint
 	router.Handle("/metrics", promhttp.Handler())
 
-	gardenerNamespace := fmt.Sprintf("garden-%s", cfg.Gardener.Project)
-	kymaQueue, err := NewOrchestrationProcessingQueue(ctx, db, cli, provisionerClient, gardenerClient,
-		gardenerNamespace, eventBroker, inputFactory, nil, time.Minute, logs)
+	orchestrationLogs := logLevels.Component("orchestration")
+	shootCache := orchestration.NewShootCache(deps.gardenerClient.Shoots(gardenerNamespace), cfg.Orchestration.ShootCacheTTL, orchestrationLogs)
+	shootCache.Run(ctx)
+	runtimeResolver := orchestration.NewGardenerRuntimeResolver(shootCache, db.Instances(), orchestrationLogs)
+
+	// the upgrade operations succeed only after the post-upgrade checks passed if the verification is enabled
+	var upgradeVerifier upgrade_kyma.UpgradeVerifier
+	if !cfg.UpgradeVerification.Disabled {
+		checks := upgradeverification.NewChecks(cfg.UpgradeVerification, httputil.NewClient(30, false), avsClient, db.Operations(), provisionerClient)
+		upgradeVerifier = upgradeverification.NewVerifier(cfg.UpgradeVerification, checks...)
+	}
+
+	kymaQueue, err := NewOrchestrationProcessingQueue(ctx, db, cli, provisionerClient, runtimeResolver,
+		eventBroker, inputFactory, kymaVersionConfigurator, nil, upgradeVerifier, cfg.Orchestration, time.Minute, stepHooks, logLevels)
+	fatalOnError(err)
+	clusterQueue := NewClusterOrchestrationProcessingQueue(ctx, db, provisionerClient, runtimeResolver, eventBroker,
+		cfg.Provisioning.KubernetesVersion, cfg.Orchestration, time.Minute, stepHooks, logLevels)
+
+	// set the shoot names of the instances provisioned before the shoot name was stored in the background
+	shootname.NewBackfill(db.Instances(), provisionerClient, cfg.ShootNameBackfill, logLevels.Component("shootNameBackfill")).Run(ctx)
+
+	consistencyChecker := consistency.NewChecker(db.Instances(), db.Operations(), db.Orchestrations(), cfg.Consistency, logLevels.Component("consistency"))
+	prometheus.MustRegister(metrics.NewConsistencyCollector(consistencyChecker))
+
+	// the expired trial instances are deprovisioned in the same way as the instances deprovisioned by their owners
+	var trialNotifier trialexpiration.Notifier
+	if cfg.TrialExpiration.WebhookURL != "" {
+		trialNotifier = trialexpiration.NewWebhookNotifier(cfg.TrialExpiration.WebhookURL, httputil.NewClient(30, false))
+	}
+	trialExpiration := trialexpiration.NewService(db.Instances(), db.TrialExpirations(), kymaEnvBroker.DeprovisionEndpoint, trialNotifier, eventBroker, cfg.TrialExpiration, logLevels.Component("trialExpiration"))
+
+	// fail the operations in progress which are not processed anymore, e.g. lost on the restart of the broker
+	staleDetector := staleoperation.NewDetector(db.Operations(), eventBroker, cfg.StaleOperations, logLevels.Component("staleOperations"))
+	prometheus.MustRegister(metrics.NewStaleOperationsCollector(staleDetector))
+
+	// the shoots of the runtimes without the instance are detected periodically and cleaned up on demand,
+	// both are turned off until the key signing the confirmation tokens is configured
+	var orphanService *orphan.Service
+	if cfg.OrphanCleanup.ConfirmationTokenKey != "" {
+		orphanService, err = orphan.NewService(deps.gardenerClient.Shoots(gardenerNamespace), db.Instances(), provisionerClient, cfg.OrphanCleanup, logLevels.Component("orphanCleanup"))
+		fatalOnError(err)
+		prometheus.MustRegister(metrics.NewOrphansCollector(orphanService))
+	} else {
+		logs.Warn("Orphan cleanup endpoints and orphan detection are disabled, the confirmation token key is not configured")
+	}
+
+	// run the periodic jobs in the background, the lock kept in the storage ensures that every job is run by a single replica
+	replica, err := replicaName()
 	fatalOnError(err)
+	jobScheduler, err := newJobScheduler(db, replica, cfg, consistencyChecker, trialExpiration, staleDetector, orphanService, logLevels)
+	fatalOnError(err)
+	jobScheduler.Run(ctx)
 
-	orchestrationHandler := orchestrate.NewOrchestrationHandler(db, kymaQueue, cfg.MaxPaginationPage, logs)
+	orchestrationHandler := orchestrate.NewOrchestrationHandler(db, kymaQueue, clusterQueue, runtimeResolver, eventBroker, cfg.MaxPaginationPage, cfg.Orchestration, orchestrationLogs)
 
 	if !cfg.DisableProcessOperationsInProgress {
 		err = processOperationsInProgressByType(dbmodel.OperationTypeProvision, db.Operations(), provisionQueue, logs)
 		fatalOnError(err)
 		err = processOperationsInProgressByType(dbmodel.OperationTypeDeprovision, db.Operations(), deprovisionQueue, logs)
 		fatalOnError(err)
-		err = reprocessOrchestrations(db.Orchestrations(), kymaQueue, logs)
+		err = processOperationsInProgressByType(dbmodel.OperationTypeMigratePlan, db.Operations(), planMigrationQueue, logs)
+		fatalOnError(err)
+		err = processOperationsInProgressByType(dbmodel.OperationTypeUpdate, db.Operations(), updateQueue, logs)
+		fatalOnError(err)
+		err = processOperationsInProgressByType(dbmodel.OperationTypeSuspension, db.Operations(), suspensionQueue, logs)
+		fatalOnError(err)
+		err = processOperationsInProgressByType(dbmodel.OperationTypeAccountMigration, db.Operations(), accountMigrationQueue, logs)
 		fatalOnError(err)
+		err = reprocessOrchestrations(db.Orchestrations(), kymaQueue, clusterQueue, logs)
+		fatalOnError(err)
+
 	} else {
 		logger.Info("Skipping processing operation in progress on start")
 	}
@@ -398,17 +633,113 @@ func main() {
 	}
 
 	orchestrationHandler.AttachRoutes(router)
+	kymaversion.NewHandler(db.KymaChannels(), kymaVersionConfigurator, logLevels.Component("kymaChannels")).AttachRoutes(router)
+	installeroverrides.NewHandler(db.InstallerOverrides(), optComponentsSvc, logLevels.Component("installerOverrides")).AttachRoutes(router)
+	freetier.NewHandler(freeTier, logLevels.Component("freeTier")).AttachRoutes(router)
+	killswitch.NewHandler(killSwitches, httputil.NewGatewayAuthenticator(ctx, cfg.Gateway), logLevels.Component("killSwitches")).AttachRoutes(router)
+	consistency.NewHandler(consistencyChecker, logLevels.Component("consistency")).AttachRoutes(router)
+	scheduler.NewHandler(jobScheduler, logLevels.Component("scheduler")).AttachRoutes(router)
+	if orphanService != nil {
+		orphan.NewHandler(orphanService, logLevels.Component("orphanCleanup")).AttachRoutes(router)
+	}
+	suspensionService := suspension.NewService(db.Instances(), db.Operations(), suspensionQueue, logLevels.Component("suspension"))
+	suspension.NewHandler(suspensionService, logLevels.Component("suspension")).AttachRoutes(router)
+	accountMigrationService := accountmigration.NewService(db.Instances(), db.Operations(), accountMigrationQueue, logLevels.Component("accountMigration"))
+	accountmigration.NewHandler(accountMigrationService, logLevels.Component("accountMigration")).AttachRoutes(router)
 	svr := handlers.CustomLoggingHandler(os.Stdout, router, func(writer io.Writer, params handlers.LogFormatterParams) {
 		logs.Infof("Call handled: method=%s url=%s statusCode=%d size=%d", params.Request.Method, params.URL.Path, params.StatusCode, params.Size)
 	})
 
 	// create list runtimes endpoint
-	runtimeHandler := runtime.NewHandler(db.Instances(), db.Operations(), cfg.MaxPaginationPage, cfg.DefaultRequestRegion)
+	runtimeHandler := runtime.NewHandler(db.Instances(), db.Operations(), db.InstancesArchived(), db.RuntimeStates(), db.TrialExpirations(), cfg.MaxPaginationPage, cfg.DefaultRequestRegion)
 	runtimeHandler.AttachRoutes(router)
 
+	// create operation events endpoint
+	operation.NewEventsHandler(db.Operations(), db.OperationEvents()).AttachRoutes(router)
+
+	// create correlation lookup endpoint
+	operation.NewCorrelationHandler(db.Operations()).AttachRoutes(router)
+
+	// create bulk retry and abandon endpoint, the Kyma upgrade operations are retried with their orchestration
+	operationsBatch := operation.NewBatch(db.Operations(), db.Jobs(), eventBroker, replica, map[dbmodel.OperationType]operation.Queue{
+		dbmodel.OperationTypeProvision:        provisionQueue,
+		dbmodel.OperationTypeDeprovision:      deprovisionQueue,
+		dbmodel.OperationTypeMigratePlan:      planMigrationQueue,
+		dbmodel.OperationTypeUpdate:           updateQueue,
+		dbmodel.OperationTypeSuspension:       suspensionQueue,
+		dbmodel.OperationTypeAccountMigration: accountMigrationQueue,
+	}, cfg.OperationsBatch, logLevels.Component("operationsBatch"))
+	operation.NewBatchHandler(operationsBatch, logLevels.Component("operationsBatch")).AttachRoutes(router)
+
 	fatalOnError(http.ListenAndServe(cfg.Host+":"+cfg.Port, svr))
 }
 
+// replicaName identifies the broker replica by the host name and a random suffix, so the replicas restarted
+// on the same host are told apart
+func replicaName() (string, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return "", errors.Wrap(err, "while getting host name")
+	}
+	return fmt.Sprintf("%s-%s", hostname, uuid.New().String()[:8]), nil
+}
+
+// newJobScheduler registers the periodic jobs of the broker run by the given replica
+func newJobScheduler(db storage.BrokerStorage, replica string, cfg Config, consistencyChecker *consistency.Checker, trialExpiration *trialexpiration.Service,
+	staleDetector *staleoperation.Detector, orphanService *orphan.Service, logLevels *kebLogger.Levels) (*scheduler.Scheduler, error) {
+	jobScheduler := scheduler.NewScheduler(db.Jobs(), replica, cfg.Scheduler, logLevels.Component("scheduler"))
+
+	janitor := runtimestate.NewJanitor(db.RuntimeStates(), cfg.RuntimeStateRetention, logLevels.Component("runtimeStateJanitor"))
+	jobs := []scheduler.Job{
+		{
+			Name:     "runtime-state-cleanup",
+			Interval: cfg.RuntimeStateRetention.Interval,
+			Disabled: cfg.RuntimeStateRetention.Disabled,
+			Run: func(context.Context) error {
+				_, err := janitor.CleanUp()
+				return err
+			},
+		},
+		{
+			Name:     "consistency-check",
+			Interval: cfg.Consistency.Interval,
+			Disabled: cfg.Consistency.Disabled,
+			Run: func(context.Context) error {
+				_, err := consistencyChecker.Check()
+				return err
+			},
+		},
+		{
+			Name:     "trial-expiration",
+			Interval: cfg.TrialExpiration.Interval,
+			Disabled: cfg.TrialExpiration.Disabled,
+			Run:      trialExpiration.Run,
+		},
+		{
+			Name:     "stale-operations",
+			Interval: cfg.StaleOperations.Interval,
+			Disabled: cfg.StaleOperations.Disabled,
+			Run:      staleDetector.Run,
+		},
+	}
+	// the orphan service is not created if the orphan cleanup is not configured
+	if orphanService != nil {
+		jobs = append(jobs, scheduler.Job{
+			Name:     "orphan-detection",
+			Interval: cfg.OrphanCleanup.DetectionInterval,
+			Disabled: cfg.OrphanCleanup.DetectionDisabled,
+			Run:      orphanService.Run,
+		})
+	}
+	for _, job := range jobs {
+		if err := jobScheduler.Register(job); err != nil {
+			return nil, err
+		}
+	}
+
+	return jobScheduler, nil
+}
+
 // queues all in progress operations by type
 func processOperationsInProgressByType(opType dbmodel.OperationType, op storage.Operations, queue *process.Queue, log logrus.FieldLogger) error {
 	operations, err := op.GetOperationsInProgressByType(opType)
@@ -422,17 +753,21 @@ func processOperationsInProgressByType(opType dbmodel.OperationType, op storage.
 	return nil
 }
 
-func reprocessOrchestrations(op storage.Orchestrations, queue *process.Queue, log logrus.FieldLogger) error {
-	if err := processOrchestration(internal.InProgress, op, queue, log); err != nil {
+// reprocessOrchestrations adds the unfinished orchestrations to the queue of their type
+func reprocessOrchestrations(op storage.Orchestrations, kymaQueue, clusterQueue *process.Queue, log logrus.FieldLogger) error {
+	if err := processOrchestration(internal.InProgress, op, kymaQueue, clusterQueue, log); err != nil {
 		return errors.Wrap(err, "while processing in progress orchestrations")
 	}
-	if err := processOrchestration(internal.Pending, op, queue, log); err != nil {
+	if err := processOrchestration(internal.Pending, op, kymaQueue, clusterQueue, log); err != nil {
 		return errors.Wrap(err, "while processing pending orchestrations")
 	}
+	if err := processOrchestration(internal.Canceling, op, kymaQueue, clusterQueue, log); err != nil {
+		return errors.Wrap(err, "while processing canceling orchestrations")
+	}
 	return nil
 }
 
-func processOrchestration(state string, op storage.Orchestrations, queue *process.Queue, log logrus.FieldLogger) error {
+func processOrchestration(state string, op storage.Orchestrations, kymaQueue, clusterQueue *process.Queue, log logrus.FieldLogger) error {
 	orchestrations, err := op.ListByState(state)
 	if err != nil {
 		return errors.Wrap(err, "while getting in progress orchestrations from storage")
@@ -442,6 +777,10 @@ func processOrchestration(state string, op storage.Orchestrations, queue *proces
 	})
 
 	for _, o := range orchestrations {
+		queue := kymaQueue
+		if o.IsUpgradeCluster() {
+			queue = clusterQueue
+		}
 		queue.Add(o.OrchestrationID)
 		log.Infof("Resuming the processing of %s orchestration ID: %s", state, o.OrchestrationID)
 	}
@@ -476,14 +815,19 @@ func fatalOnError(err error) {
 }
 
 func NewOrchestrationProcessingQueue(ctx context.Context, db storage.BrokerStorage,
-	cli client.Client, provisionerClient provisioner.Client,
-	gardenerClient gardenerclient.CoreV1beta1Interface, gardenerNamespace string, pub event.Publisher,
-	inputFactory input.CreatorForPlan, icfg *upgrade_kyma.TimeSchedule,
-	pollingInterval time.Duration, logs logrus.FieldLogger) (*process.Queue, error) {
-
-	upgradeKymaManager := upgrade_kyma.NewManager(db.Operations(), pub, logs.WithField("upgradeKyma", "manager"))
+	cli client.Client, provisionerClient provisioner.Client, runtimeResolver orchestration.RuntimeResolver, pub event.Publisher,
+	inputFactory input.CreatorForPlan, kymaVersionConfigurator upgrade_kyma.KymaVersionConfigurator, icfg *upgrade_kyma.TimeSchedule,
+	upgradeVerifier upgrade_kyma.UpgradeVerifier, orchestrationConfig orchestration.Config, pollingInterval time.Duration, stepHooks process.StepHooks, logLevels *kebLogger.Levels) (*process.Queue, error) {
+
+	logs := logLevels.Component("orchestration")
+	upgradeKymaLogs := logLevels.Component("upgradeKyma")
+	upgradeKymaManager := upgrade_kyma.NewManager(db.Operations(), pub, upgradeKymaLogs)
+	for _, hook := range stepHooks {
+		upgradeKymaManager.AddHook(hook)
+	}
+	provisionerRateLimiter := orchestration.NewProvisionerRateLimiter(orchestrationConfig.ProvisionerMutationsPerMinute)
 
-	upgradeKymaInit := upgrade_kyma.NewInitialisationStep(db.Operations(), db.Instances(), provisionerClient, inputFactory, icfg)
+	upgradeKymaInit := upgrade_kyma.NewInitialisationStep(db.Operations(), db.Instances(), provisionerClient, inputFactory, kymaVersionConfigurator, icfg, upgradeVerifier)
 	upgradeKymaManager.InitStep(upgradeKymaInit)
 	upgradeKymaSteps := []struct {
 		disabled bool
@@ -494,9 +838,13 @@ func NewOrchestrationProcessingQueue(ctx context.Context, db storage.BrokerStora
 			weight: 2,
 			step:   upgrade_kyma.NewOverridesFromSecretsAndConfigStep(ctx, cli, db.Operations()),
 		},
+		{
+			weight: 2,
+			step:   upgrade_kyma.NewInstallerOverridesStep(db.Operations(), db.InstallerOverrides()),
+		},
 		{
 			weight: 10,
-			step:   upgrade_kyma.NewUpgradeKymaStep(db.Operations(), db.RuntimeStates(), provisionerClient, icfg),
+			step:   upgrade_kyma.NewUpgradeKymaStep(db.Operations(), db.RuntimeStates(), provisionerClient, icfg, provisionerRateLimiter),
 		},
 	}
 	for _, step := range upgradeKymaSteps {
@@ -505,11 +853,9 @@ func NewOrchestrationProcessingQueue(ctx context.Context, db storage.BrokerStora
 		}
 	}
 
-	upgradeKymaQueue := process.NewQueue(upgradeKymaManager, logs)
+	upgradeKymaQueue := process.NewQueue(upgradeKymaManager, upgradeKymaLogs)
 	upgradeKymaQueue.Run(ctx.Done(), 5)
 
-	runtimeResolver := orchestration.NewGardenerRuntimeResolver(gardenerClient, gardenerNamespace, db.Instances(), logs)
-
 	orchestrateKymaManager := kyma.NewUpgradeKymaManager(db.Orchestrations(), db.Operations(),
 		upgradeKymaManager, runtimeResolver, pollingInterval, logs)
 	queue := process.NewQueue(orchestrateKymaManager, logs)
@@ -519,3 +865,34 @@ func NewOrchestrationProcessingQueue(ctx context.Context, db storage.BrokerStora
 
 	return queue, nil
 }
+
+// NewClusterOrchestrationProcessingQueue creates the queue of the orchestrations upgrading the clusters of the runtimes
+// to the given Kubernetes version, the cluster orchestrations are processed independently of the Kyma orchestrations
+func NewClusterOrchestrationProcessingQueue(ctx context.Context, db storage.BrokerStorage, provisionerClient provisioner.Client,
+	runtimeResolver orchestration.RuntimeResolver, pub event.Publisher, kubernetesVersion string, orchestrationConfig orchestration.Config,
+	pollingInterval time.Duration, stepHooks process.StepHooks, logLevels *kebLogger.Levels) *process.Queue {
+
+	logs := logLevels.Component("orchestration")
+	upgradeClusterLogs := logLevels.Component("upgradeCluster")
+	upgradeClusterManager := upgrade_cluster.NewManager(db.Operations(), pub, upgradeClusterLogs)
+	for _, hook := range stepHooks {
+		upgradeClusterManager.AddHook(hook)
+	}
+	provisionerRateLimiter := orchestration.NewProvisionerRateLimiter(orchestrationConfig.ProvisionerMutationsPerMinute)
+
+	upgradeClusterInit := upgrade_cluster.NewInitialisationStep(db.Operations(), db.Instances(), provisionerClient)
+	upgradeClusterManager.InitStep(upgradeClusterInit)
+	upgradeClusterManager.AddStep(10, upgrade_cluster.NewUpgradeClusterStep(db.Operations(), db.Instances(), provisionerClient, kubernetesVersion, provisionerRateLimiter))
+
+	upgradeClusterQueue := process.NewQueue(upgradeClusterManager, upgradeClusterLogs)
+	upgradeClusterQueue.Run(ctx.Done(), 5)
+
+	orchestrateClusterManager := cluster.NewUpgradeClusterManager(db.Orchestrations(), db.Operations(),
+		upgradeClusterManager, runtimeResolver, pollingInterval, logs)
+	queue := process.NewQueue(orchestrateClusterManager, logs)
+
+	// only one cluster orchestration can be processed at the same time
+	queue.Run(ctx.Done(), 1)
+
+	return queue
+}
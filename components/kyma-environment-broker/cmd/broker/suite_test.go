@@ -16,9 +16,13 @@ import (
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/broker"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/event"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/kymaversion"
+	kebLogger "github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/logger"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/orchestration"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process/input"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process/input/automock"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process/provisioning"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process/upgrade_kyma"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/provisioner"
 	kebRuntime "github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/runtime"
@@ -84,12 +88,18 @@ func NewOrchestrationSuite(t *testing.T) *OrchestrationSuite {
 
 	eventBroker := event.NewPubSub()
 
-	kymaQueue, err := NewOrchestrationProcessingQueue(ctx, db, cli, provisionerClient, gardenerClient.CoreV1beta1(),
-		gardenerNamespace, eventBroker, inputFactory, &upgrade_kyma.TimeSchedule{
+	kymaVersionConfigurator := kymaversion.NewResolver(kymaversion.Config{}, db.KymaChannels(),
+		provisioning.NewKymaVersionConfigurator(ctx, cli, "kcp-system", "kyma-versions", logs))
+
+	shootCache := orchestration.NewShootCache(gardenerClient.CoreV1beta1().Shoots(gardenerNamespace), 0, logs)
+	runtimeResolver := orchestration.NewGardenerRuntimeResolver(shootCache, db.Instances(), logs)
+
+	kymaQueue, err := NewOrchestrationProcessingQueue(ctx, db, cli, provisionerClient, runtimeResolver,
+		eventBroker, inputFactory, kymaVersionConfigurator, &upgrade_kyma.TimeSchedule{
 			Retry:              10 * time.Millisecond,
 			StatusCheck:        100 * time.Millisecond,
 			UpgradeKymaTimeout: 2 * time.Second,
-		}, 250*time.Millisecond, logs)
+		}, nil, orchestration.Config{}, 250*time.Millisecond, nil, kebLogger.NewLevels(logs))
 
 	return &OrchestrationSuite{
 		gardenerNamespace:  gardenerNamespace,
@@ -0,0 +1,63 @@
+package command
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/cmd/cli/logger"
+	"github.com/spf13/cobra"
+)
+
+const (
+	bashShell       = "bash"
+	zshShell        = "zsh"
+	fishShell       = "fish"
+	powershellShell = "powershell"
+)
+
+// CompletionCommand represents an execution of the kcp completion command
+type CompletionCommand struct {
+	log logger.Logger
+}
+
+// NewCompletionCmd constructs a new instance of CompletionCommand and configures it in terms of a cobra.Command
+func NewCompletionCmd(log logger.Logger) *cobra.Command {
+	cmd := CompletionCommand{log: log}
+	cobraCmd := &cobra.Command{
+		Use:   fmt.Sprintf("completion %s|%s|%s|%s", bashShell, zshShell, fishShell, powershellShell),
+		Short: "Generates the shell completion script.",
+		Long: `Generates the completion script of the kcp CLI for the given shell and prints it to the standard output.
+Besides the commands and options, the script completes the selectors of the --target and --target-exclude options and the values of the --output option.
+The completion of the option values is supported in bash and fish.
+
+To load the completions in the current bash session, run:
+  source <(kcp completion bash)
+To load the completions for each zsh session, run once:
+  kcp completion zsh > "${fpath[1]}/_kcp"
+To load the completions for each fish session, run once:
+  kcp completion fish > ~/.config/fish/completions/kcp.fish
+To load the completions in the current PowerShell session, run:
+  kcp completion powershell | Out-String | Invoke-Expression`,
+		Example:   `  kcp completion bash > /etc/bash_completion.d/kcp    Install the bash completion script for all users.`,
+		Args:      cobra.ExactValidArgs(1),
+		ValidArgs: []string{bashShell, zshShell, fishShell, powershellShell},
+		RunE:      func(cobraCmd *cobra.Command, args []string) error { return cmd.Run(cobraCmd, args[0]) },
+	}
+	return cobraCmd
+}
+
+// Run executes the completion command
+func (cmd *CompletionCommand) Run(cobraCmd *cobra.Command, shell string) error {
+	root := cobraCmd.Root()
+	switch shell {
+	case bashShell:
+		return root.GenBashCompletion(os.Stdout)
+	case zshShell:
+		return root.GenZshCompletion(os.Stdout)
+	case fishShell:
+		return root.GenFishCompletion(os.Stdout, true)
+	case powershellShell:
+		return root.GenPowerShellCompletion(os.Stdout)
+	}
+	return fmt.Errorf("unsupported shell: %s", shell)
+}
@@ -0,0 +1,343 @@
+package command
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/cmd/cli/logger"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+const (
+	configCmdName = "config"
+
+	sourceFlag    = "flag"
+	sourceEnv     = "env"
+	sourceProfile = "profile"
+	sourceConfig  = "config file"
+	sourceUnset   = "unset"
+
+	maskedValue = "********"
+	// credentialExpiryWarning is the period before the expiration of the Gardener credentials in which a warning is shown
+	credentialExpiryWarning = 7 * 24 * time.Hour
+)
+
+// ConfigViewCommand represents an execution of the kcp config view command
+type ConfigViewCommand struct {
+	log          logger.Logger
+	output       string
+	effective    bool
+	checkTimeout time.Duration
+}
+
+// effectiveConfig is the resolved configuration of the CLI printed by the kcp config view --effective command
+type effectiveConfig struct {
+	ConfigFile string            `json:"configFile,omitempty"`
+	Profile    string            `json:"profile,omitempty"`
+	Options    []effectiveOption `json:"options"`
+	Warnings   []string          `json:"warnings,omitempty"`
+}
+
+// effectiveOption is a single global option with its resolved value and the source the value comes from
+type effectiveOption struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+// NewConfigCmd constructs the config command and all subcommands under the config command
+func NewConfigCmd(log logger.Logger) *cobra.Command {
+	cobraCmd := &cobra.Command{
+		Use:   configCmdName,
+		Short: "Displays the configuration of the KCP CLI.",
+		Long:  "Displays the configuration of the KCP CLI. The config commands work without the required global options, so they can be used to troubleshoot an incomplete configuration.",
+	}
+
+	cobraCmd.AddCommand(NewConfigViewCmd(log))
+	return cobraCmd
+}
+
+// NewConfigViewCmd constructs a new instance of ConfigViewCommand and configures it in terms of a cobra.Command
+func NewConfigViewCmd(log logger.Logger) *cobra.Command {
+	cmd := ConfigViewCommand{log: log}
+	cobraCmd := &cobra.Command{
+		Use:   "view",
+		Short: "Displays the KCP CLI config file.",
+		Long: `Displays the content of the KCP CLI config file.
+If the --effective option is specified, the command displays every global option with its resolved value and the source of the value instead.
+The source is one of the following, in the order of precedence:
+  - flag        : The option is given on the command line
+  - env         : The option is set using the KCP_* environment variable
+  - profile     : The option is set in the selected profile of the config file
+  - config file : The option is set at the top level of the config file
+  - unset       : The option is not set.
+The value of the oidc-client-secret option is masked.
+
+The effective configuration is validated and the following problems are reported as warnings:
+  - Required options which are not set
+  - URLs which are invalid or not reachable
+  - Gardener kubeconfig which cannot be loaded, or which contains credentials that are expired or expire within 7 days.`,
+		Example: `  kcp config view                                     Display the content of the config file.
+  kcp config view --effective                         Display the resolved global options and the configuration warnings.
+  kcp config view --effective --profile prod -o json  Display the resolved global options of the prod profile in the JSON format.`,
+		Args:    cobra.NoArgs,
+		PreRunE: func(_ *cobra.Command, _ []string) error { return cmd.Validate() },
+		RunE:    func(cobraCmd *cobra.Command, _ []string) error { return cmd.Run(cobraCmd) },
+	}
+
+	cobraCmd.Flags().BoolVar(&cmd.effective, "effective", false, "Option that displays every global option with its resolved value and source, and the configuration warnings.")
+	cobraCmd.Flags().DurationVar(&cmd.checkTimeout, "check-timeout", 5*time.Second, "Timeout of the reachability check of each URL option. Applies only with the --effective option.")
+	SetOutputOpt(cobraCmd, &cmd.output)
+	return cobraCmd
+}
+
+// Run executes the config view command
+func (cmd *ConfigViewCommand) Run(cobraCmd *cobra.Command) error {
+	if !cmd.effective {
+		return printConfigFile(os.Stdout)
+	}
+
+	config := effectiveConfig{
+		ConfigFile: viper.ConfigFileUsed(),
+		Profile:    activeProfile,
+	}
+	for _, name := range allGlobalOpts() {
+		value := viper.GetString(name)
+		if name == GlobalOpts.oidcClientSecret && value != "" {
+			value = maskedValue
+		}
+		config.Options = append(config.Options, effectiveOption{
+			Name:   name,
+			Value:  value,
+			Source: optionSource(cobraCmd, name),
+		})
+	}
+	config.Warnings = cmd.validateConfig()
+
+	if isObjectOutput(cmd.output) {
+		return printObject(os.Stdout, cmd.output, config)
+	}
+	return printEffectiveConfig(os.Stdout, config)
+}
+
+// Validate checks the input parameters of the config view command
+func (cmd *ConfigViewCommand) Validate() error {
+	if !cmd.effective && cmd.output != tableOutput {
+		return errors.New("--output can be used only with --effective")
+	}
+	if cmd.checkTimeout <= 0 {
+		return fmt.Errorf("invalid value for check-timeout: %s. The value must be positive", cmd.checkTimeout)
+	}
+	return ValidateOutputOpt(cmd.output)
+}
+
+func printConfigFile(w io.Writer) error {
+	path := viper.ConfigFileUsed()
+	if path == "" {
+		return errors.New("config file not found. See kcp --help for the config file lookup")
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.Wrap(err, "while reading config file")
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func printEffectiveConfig(w io.Writer, config effectiveConfig) error {
+	configFile := config.ConfigFile
+	if configFile == "" {
+		configFile = "<none>"
+	}
+	fmt.Fprintf(w, "Config file: %s\n", configFile)
+	if config.Profile != "" {
+		fmt.Fprintf(w, "Profile: %s\n", config.Profile)
+	}
+	fmt.Fprintln(w)
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "OPTION\tVALUE\tSOURCE")
+	for _, opt := range config.Options {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", opt.Name, opt.Value, opt.Source)
+	}
+	err := tw.Flush()
+	if err != nil {
+		return err
+	}
+
+	if len(config.Warnings) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Warnings:")
+		for _, warning := range config.Warnings {
+			fmt.Fprintf(w, "  - %s\n", warning)
+		}
+	}
+	return nil
+}
+
+// optionSource tells where the resolved value of the global option comes from, following the precedence used by viper
+func optionSource(cobraCmd *cobra.Command, name string) string {
+	if flag := cobraCmd.Flags().Lookup(name); flag != nil && flag.Changed {
+		return sourceFlag
+	}
+	if _, found := os.LookupEnv(optionEnv(name)); found {
+		return sourceEnv
+	}
+	if activeProfile != "" && viper.IsSet(fmt.Sprintf("%s.%s.%s", profilesKey, activeProfile, name)) {
+		return fmt.Sprintf("%s %s", sourceProfile, activeProfile)
+	}
+	if viper.InConfig(name) {
+		return sourceConfig
+	}
+	return sourceUnset
+}
+
+// optionEnv returns the name of the environment variable of the global option, e.g. KCP_KEB_API_URL
+func optionEnv(name string) string {
+	return "KCP_" + strings.ToUpper(strings.Replace(name, "-", "_", -1))
+}
+
+func (cmd *ConfigViewCommand) validateConfig() []string {
+	var warnings []string
+	for _, name := range requiredGlobalOpts() {
+		if viper.GetString(name) == "" {
+			warnings = append(warnings, fmt.Sprintf("%s: required option is not set", name))
+		}
+	}
+
+	client := &http.Client{Timeout: cmd.checkTimeout}
+	for _, name := range []string{GlobalOpts.oidcIssuerURL, GlobalOpts.kebAPIURL, GlobalOpts.kubeconfigAPIURL} {
+		value := viper.GetString(name)
+		if value == "" {
+			continue
+		}
+		if err := checkURL(client, value); err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s: %s", name, err))
+		}
+	}
+
+	if path := GlobalOpts.GardenerKubeconfig(); path != "" {
+		if err := checkGardenerKubeconfig(path, time.Now()); err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s: %s", GlobalOpts.gardenerKubeconfig, err))
+		}
+	}
+	return warnings
+}
+
+// checkURL checks the URL is valid and the server responds, any HTTP response is treated as reachable
+func checkURL(client *http.Client, value string) error {
+	u, err := url.Parse(value)
+	if err != nil {
+		return errors.Wrap(err, "invalid URL")
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid URL %s: the URL must be an absolute http or https URL", value)
+	}
+
+	resp, err := client.Get(value)
+	if err != nil {
+		return errors.Wrapf(err, "URL %s is not reachable", value)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// checkGardenerKubeconfig checks the Gardener kubeconfig can be loaded and the credentials of its current context
+// are not expired and do not expire soon
+func checkGardenerKubeconfig(path string, now time.Time) error {
+	config, err := clientcmd.LoadFromFile(path)
+	if err != nil {
+		return errors.Wrap(err, "while loading kubeconfig")
+	}
+	kubeContext, found := config.Contexts[config.CurrentContext]
+	if !found {
+		return fmt.Errorf("current context %q of kubeconfig %s not found", config.CurrentContext, path)
+	}
+	authInfo, found := config.AuthInfos[kubeContext.AuthInfo]
+	if !found {
+		return fmt.Errorf("user %q of kubeconfig %s not found", kubeContext.AuthInfo, path)
+	}
+
+	expiresAt, err := credentialExpiration(authInfo)
+	if err != nil {
+		return err
+	}
+	switch {
+	case expiresAt.IsZero():
+		return nil
+	case expiresAt.Before(now):
+		return fmt.Errorf("credentials of kubeconfig %s expired at %s", path, expiresAt.Format(time.RFC3339))
+	case expiresAt.Before(now.Add(credentialExpiryWarning)):
+		return fmt.Errorf("credentials of kubeconfig %s expire at %s", path, expiresAt.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// credentialExpiration returns the expiration of the client certificate or the JWT token of the kubeconfig user,
+// the zero time is returned if the credentials do not expire or the expiration is unknown
+func credentialExpiration(authInfo *clientcmdapi.AuthInfo) (time.Time, error) {
+	certData := authInfo.ClientCertificateData
+	if len(certData) == 0 && authInfo.ClientCertificate != "" {
+		data, err := ioutil.ReadFile(authInfo.ClientCertificate)
+		if err != nil {
+			return time.Time{}, errors.Wrap(err, "while reading client certificate")
+		}
+		certData = data
+	}
+	if len(certData) > 0 {
+		block, _ := pem.Decode(certData)
+		if block == nil {
+			return time.Time{}, errors.New("client certificate is not PEM encoded")
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return time.Time{}, errors.Wrap(err, "while parsing client certificate")
+		}
+		return cert.NotAfter, nil
+	}
+
+	token := authInfo.Token
+	if token == "" && authInfo.TokenFile != "" {
+		data, err := ioutil.ReadFile(authInfo.TokenFile)
+		if err != nil {
+			return time.Time{}, errors.Wrap(err, "while reading token file")
+		}
+		token = strings.TrimSpace(string(data))
+	}
+	return tokenExpiration(token), nil
+}
+
+// tokenExpiration returns the exp claim of the JWT token without verifying the token, the opaque tokens
+// and the tokens without the exp claim do not expire
+func tokenExpiration(token string) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}
+	}
+	return time.Unix(claims.Exp, 0)
+}
@@ -0,0 +1,159 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/cmd/cli/credential"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/common/httperror"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/spf13/cobra"
+)
+
+// Exit codes of the kcp CLI, so the scripts can react differently to different classes of failures
+const (
+	ExitCodeGeneralError    = 1
+	ExitCodeValidationError = 2
+	ExitCodeAuthError       = 3
+	ExitCodeNotFound        = 4
+	ExitCodeServerError     = 5
+	// the orchestration awaited with the --wait option failed or was canceled
+	ExitCodeOrchestrationFailed   = 6
+	ExitCodeOrchestrationCanceled = 7
+)
+
+// Reasons reported in the JSON error output for the exit codes
+const (
+	reasonGeneralError    = "GeneralError"
+	reasonValidationError = "ValidationError"
+	reasonAuthError       = "AuthError"
+	reasonNotFound        = "NotFound"
+	reasonServerError     = "ServerError"
+
+	reasonOrchestrationFailed   = "OrchestrationFailed"
+	reasonOrchestrationCanceled = "OrchestrationCanceled"
+)
+
+// outputFormatAnnotation marks the --output option which selects the output format, the JSON format
+// applies to the errors as well
+const outputFormatAnnotation = "kcp_output_format"
+
+// validationError is returned when the command is called with invalid arguments or options
+type validationError struct {
+	err error
+}
+
+func (e *validationError) Error() string {
+	return e.err.Error()
+}
+
+func (e *validationError) Cause() error {
+	return e.err
+}
+
+func newValidationError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &validationError{err: err}
+}
+
+// errorOutput is the error printed when the JSON output is selected
+type errorOutput struct {
+	Error      string `json:"error"`
+	Reason     string `json:"reason"`
+	ExitCode   int    `json:"exitCode"`
+	StatusCode int    `json:"statusCode,omitempty"`
+}
+
+// markValidationErrors marks the errors returned by the arguments and options validation of the command
+// and all its subcommands as validation errors
+func markValidationErrors(cmd *cobra.Command) {
+	if validateArgs := cmd.Args; validateArgs != nil {
+		cmd.Args = func(cobraCmd *cobra.Command, args []string) error {
+			return newValidationError(validateArgs(cobraCmd, args))
+		}
+	}
+	if preRunE := cmd.PreRunE; preRunE != nil {
+		cmd.PreRunE = func(cobraCmd *cobra.Command, args []string) error {
+			return newValidationError(preRunE(cobraCmd, args))
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		markValidationErrors(sub)
+	}
+}
+
+// ExitCode returns the exit code of the CLI for the error returned by the command
+func ExitCode(err error) int {
+	code, _ := classifyError(err)
+	return code
+}
+
+// PrintError prints the error returned by the command executed from the given root command, the error is printed
+// as JSON to the standard output if the command was called with the JSON output, otherwise to the standard error
+func PrintError(root *cobra.Command, args []string, err error) {
+	if !jsonOutputRequested(root, args) {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return
+	}
+	printErrorJSON(os.Stdout, err)
+}
+
+func printErrorJSON(w io.Writer, err error) {
+	code, reason := classifyError(err)
+	out := errorOutput{Error: err.Error(), Reason: reason, ExitCode: code}
+	if statusCode, found := httperror.StatusCode(err); found {
+		out.StatusCode = statusCode
+	}
+	if perr := printJSON(w, out); perr != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+	}
+}
+
+func classifyError(err error) (int, string) {
+	for e := err; e != nil; e = httperror.Unwrap(e) {
+		switch e := e.(type) {
+		case *validationError:
+			return ExitCodeValidationError, reasonValidationError
+		case *credential.AuthError:
+			return ExitCodeAuthError, reasonAuthError
+		case *orchestrationError:
+			if e.state == internal.Canceled {
+				return ExitCodeOrchestrationCanceled, reasonOrchestrationCanceled
+			}
+			return ExitCodeOrchestrationFailed, reasonOrchestrationFailed
+		case *httperror.ResponseError:
+			switch {
+			case e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden:
+				return ExitCodeAuthError, reasonAuthError
+			case e.StatusCode == http.StatusNotFound:
+				return ExitCodeNotFound, reasonNotFound
+			case e.StatusCode == http.StatusBadRequest:
+				return ExitCodeValidationError, reasonValidationError
+			case e.StatusCode >= http.StatusInternalServerError:
+				return ExitCodeServerError, reasonServerError
+			}
+		}
+	}
+	return ExitCodeGeneralError, reasonGeneralError
+}
+
+// jsonOutputRequested checks if the executed command selects the JSON output, the options of the command
+// are already parsed when the command returns
+func jsonOutputRequested(root *cobra.Command, args []string) bool {
+	cmd, _, err := root.Find(args)
+	if err != nil || cmd == nil {
+		return false
+	}
+	flag := cmd.Flags().Lookup("output")
+	if flag == nil {
+		return false
+	}
+	if _, marked := flag.Annotations[outputFormatAnnotation]; !marked {
+		return false
+	}
+	return flag.Value.String() == jsonOutput
+}
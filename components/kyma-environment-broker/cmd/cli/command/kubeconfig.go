@@ -3,17 +3,33 @@ package command
 import (
 	"context"
 	"fmt"
-	"io/ioutil"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/pkg/errors"
 
 	"github.com/kyma-project/control-plane/components/kubeconfig-service/pkg/client"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/cmd/cli/credential"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/cmd/cli/fileutil"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/cmd/cli/logger"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/common/runtime"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+const (
+	layoutShoot   = "shoot"
+	layoutAccount = "account"
+	layoutMerged  = "merged"
+
+	mergedKubeconfigFile = "kubeconfig.yaml"
+	// stdoutOutput prints the kubeconfig to the standard output instead of saving it
+	stdoutOutput = "-"
 )
 
 // KubeconfigCommand represents an execution of the kcp kubeconfig command
@@ -24,6 +40,12 @@ type KubeconfigCommand struct {
 	subAccountID    string
 	runtimeID       string
 	outputPath      string
+	kubeconfigDir   string
+	layout          string
+	serviceAccount  string
+	namespace       string
+	role            string
+	merge           bool
 }
 
 type kubeconfig struct {
@@ -46,21 +68,46 @@ func NewKubeconfigCmd(log logger.Logger) *cobra.Command {
 The Runtime can be specified by one of the following:
   - Global account / subaccount pair with the --account and --subaccount options
   - Global account / Runtime ID pair with the --account and --runtime-id options
+  - Runtime ID with the --runtime-id option
   - Shoot cluster name with the --shoot option.
 
-By default, the kubeconfig file is saved to the current directory. The output file name can be specified using the --output option.`,
+By default, the kubeconfig file is saved to the current directory. The output file name can be specified using the --output option, use - to print the kubeconfig to the standard output.
+To add the Runtime to your existing kubeconfig file, specify the --merge option. The clusters, contexts, and users of the Runtime replace the ones with the same names,
+and the current context is switched to the Runtime. The kubeconfig file is taken from the --output option, the KUBECONFIG environment variable, or $HOME/.kube/config, in this order.
+
+To download the kubeconfig files of all Runtimes matching the given options, specify the output directory with the --kubeconfig-dir option.
+In this mode, the options are used as filters and the --layout option defines how the kubeconfig files are stored in the directory:
+  - shoot   : Each kubeconfig file is saved as {SHOOT NAME}.yaml (default).
+  - account : Each kubeconfig file is saved as {GLOBAL ACCOUNT ID}/{SHOOT NAME}.yaml.
+  - merged  : All kubeconfig files are merged into a single kubeconfig.yaml file. The merge follows the KUBECONFIG merging rules,
+              so the first cluster, context, or user with a given name wins, and the current context is taken from the first kubeconfig.
+
+By default, the kubeconfig file authenticates with your OIDC identity. To hand limited access to the Runtime to automation, specify the --service-account option.
+The service account with the given name is created in the --namespace namespace of the Runtime using your identity and bound to the --role cluster role in this namespace. The namespace is created if it does not exist.
+The saved kubeconfig file authenticates with the token of this service account. The already existing service account and role binding are reused. The existing <service-account>-token secret is reused only if it holds the token of this service account.`,
 		Example: `  kcp kubeconfig -g GAID -s SAID -o /my/path/runtime.config  Downloads the kubeconfig file using global account ID and subaccount ID.
-  kcp kubeconfig -g GAID -r RUNTIMEID                    Downloads the kubeconfig file using global account ID and Runtime ID.
-  kcp kubeconfig -c c-178e034                            Downloads the kubeconfig file using a Shoot cluster name.`,
+  kcp kubeconfig -g GAID -r RUNTIMEID                        Downloads the kubeconfig file using global account ID and Runtime ID.
+  kcp kubeconfig -c c-178e034                                Downloads the kubeconfig file using a Shoot cluster name.
+  kcp kubeconfig -r RUNTIMEID -o -                           Prints the kubeconfig using a Runtime ID to the standard output.
+  kcp kubeconfig -c c-178e034 --merge                        Adds the Runtime to the kubeconfig file of the user and switches the current context to it.
+  kcp kubeconfig -g GAID --kubeconfig-dir /my/path           Downloads the kubeconfig files of all Runtimes of a global account.
+  kcp kubeconfig --kubeconfig-dir /my/path --layout merged   Downloads the kubeconfig files of all Runtimes and merges them into a single file.
+  kcp kubeconfig -c c-178e034 --service-account ci -n ci     Creates the ci service account with the view role in the ci namespace and saves its kubeconfig file.`,
 		PreRunE: func(_ *cobra.Command, _ []string) error { return cmd.Validate() },
 		RunE:    func(cobraCmd *cobra.Command, _ []string) error { return cmd.Run(cobraCmd) },
 	}
 
-	cobraCmd.Flags().StringVarP(&cmd.outputPath, "output", "o", "", "Path to the file to save the downloaded kubeconfig to. Defaults to {CLUSTER NAME}.yaml in the current directory if not specified.")
+	cobraCmd.Flags().StringVarP(&cmd.outputPath, "output", "o", "", "Path to the file to save the downloaded kubeconfig to. Defaults to {CLUSTER NAME}.yaml in the current directory if not specified. Use - to print the kubeconfig to the standard output.")
 	cobraCmd.Flags().StringVarP(&cmd.globalAccountID, "account", "g", "", "Global account ID of the specific Kyma Runtime.")
 	cobraCmd.Flags().StringVarP(&cmd.subAccountID, "subaccount", "s", "", "Subccount ID of the specific Kyma Runtime.")
 	cobraCmd.Flags().StringVarP(&cmd.runtimeID, "runtime-id", "r", "", "Runtime ID of the specific Kyma Runtime.")
 	cobraCmd.Flags().StringVarP(&cmd.shoot, "shoot", "c", "", "Shoot cluster name of the specific Kyma Runtime.")
+	cobraCmd.Flags().StringVar(&cmd.kubeconfigDir, "kubeconfig-dir", "", "Path to the directory to save the kubeconfig files of all Kyma Runtimes matching the options to.")
+	cobraCmd.Flags().StringVar(&cmd.layout, "layout", layoutShoot, fmt.Sprintf("Layout of the kubeconfig files saved to the --kubeconfig-dir directory. The possible values are: %s, %s, %s.", layoutShoot, layoutAccount, layoutMerged))
+	cobraCmd.Flags().StringVar(&cmd.serviceAccount, "service-account", "", "Name of the service account to create in the Runtime. If specified, the saved kubeconfig file authenticates with the token of this service account.")
+	cobraCmd.Flags().StringVarP(&cmd.namespace, "namespace", "n", "default", "Namespace of the service account specified with the --service-account option.")
+	cobraCmd.Flags().StringVar(&cmd.role, "role", "view", "Cluster role bound to the service account in its namespace.")
+	cobraCmd.Flags().BoolVar(&cmd.merge, "merge", false, "Merges the downloaded kubeconfig into the existing kubeconfig file and switches the current context to the Runtime.")
 
 	return cobraCmd
 }
@@ -70,6 +117,10 @@ func (cmd *KubeconfigCommand) Run(cobraCmd *cobra.Command) error {
 	cred := CLICredentialManager(cmd.log)
 	client := client.NewClient(cobraCmd.Context(), GlobalOpts.KubeconfigAPIURL(), cred)
 
+	if cmd.kubeconfigDir != "" {
+		return cmd.downloadKubeconfigs(cobraCmd.Context(), cred, client)
+	}
+
 	// Resolve Global Account / Subaccount, or Shoot name to Global Account / Runtime ID
 	if cmd.globalAccountID == "" || cmd.runtimeID == "" {
 		err := cmd.resolveRuntimeAttributes(cobraCmd.Context(), cred)
@@ -81,6 +132,12 @@ func (cmd *KubeconfigCommand) Run(cobraCmd *cobra.Command) error {
 	if err != nil {
 		return errors.Wrap(err, "while getting kubeconfig")
 	}
+	if cmd.serviceAccount != "" {
+		kc, err = cmd.serviceAccountKubeconfig(kc)
+		if err != nil {
+			return errors.Wrap(err, "while creating service account kubeconfig")
+		}
+	}
 	err = cmd.saveKubeconfig(kc)
 	return err
 }
@@ -90,39 +147,212 @@ func (cmd *KubeconfigCommand) Validate() error {
 	if GlobalOpts.KubeconfigAPIURL() == "" {
 		return fmt.Errorf("missing required %s option", GlobalOpts.kubeconfigAPIURL)
 	}
-	if cmd.globalAccountID != "" && (cmd.subAccountID != "" || cmd.runtimeID != "") || cmd.shoot != "" {
+	if cmd.serviceAccount != "" {
+		if err := cmd.validateServiceAccount(); err != nil {
+			return err
+		}
+	}
+	if cmd.kubeconfigDir != "" {
+		return cmd.validateKubeconfigDir()
+	}
+	if cmd.merge && cmd.outputPath == stdoutOutput {
+		return errors.New("--merge should not be used together with the standard output")
+	}
+	if cmd.globalAccountID != "" && (cmd.subAccountID != "" || cmd.runtimeID != "") || cmd.shoot != "" || cmd.runtimeID != "" {
 		return nil
 	}
-	return errors.New("at least one of the following options have to be specified: account/subaccount, account/runtime-id, shoot")
+	return errors.New("at least one of the following options have to be specified: account/subaccount, account/runtime-id, runtime-id, shoot")
 }
 
 func (cmd *KubeconfigCommand) resolveRuntimeAttributes(ctx context.Context, cred credential.Manager) error {
+	rt, err := resolveRuntime(ctx, cred, cmd.globalAccountID, cmd.subAccountID, cmd.runtimeID, cmd.shoot)
+	if err != nil {
+		return err
+	}
+
+	cmd.runtimeID = rt.RuntimeID
+	cmd.globalAccountID = rt.GlobalAccountID
+	return nil
+}
+
+// resolveRuntime returns the single Runtime identified by the Shoot name, the Runtime ID, or the global account / subaccount pair
+func resolveRuntime(ctx context.Context, cred credential.Manager, globalAccountID, subAccountID, runtimeID, shoot string) (runtime.RuntimeDTO, error) {
 	rtClient := runtime.NewClient(ctx, GlobalOpts.KEBAPIURL(), cred)
 	params := runtime.ListParameters{}
-	if cmd.shoot != "" {
-		params.Shoots = []string{cmd.shoot}
-	} else {
+	switch {
+	case shoot != "":
+		params.Shoots = []string{shoot}
+	case runtimeID != "":
+		params.RuntimeIDs = []string{runtimeID}
+	default:
+		params.GlobalAccountIDs = []string{globalAccountID}
+		params.SubAccountIDs = []string{subAccountID}
+	}
+
+	rp, err := rtClient.ListRuntimes(params)
+	if err != nil {
+		return runtime.RuntimeDTO{}, err
+	}
+	if rp.Count < 1 {
+		return runtime.RuntimeDTO{}, fmt.Errorf("no runtimes matched the input options")
+	}
+	if rp.Count > 1 {
+		return runtime.RuntimeDTO{}, fmt.Errorf("multiple runtimes (%d) matched the input options", rp.Count)
+	}
+	return rp.Data[0], nil
+}
+
+func (cmd *KubeconfigCommand) validateServiceAccount() error {
+	if cmd.kubeconfigDir != "" {
+		return errors.New("--service-account should not be used together with --kubeconfig-dir")
+	}
+	for option, value := range map[string]string{"service-account": cmd.serviceAccount, "namespace": cmd.namespace, "role": cmd.role} {
+		if errs := validation.IsDNS1123Subdomain(value); len(errs) > 0 {
+			return fmt.Errorf("invalid value for %s: %s", option, strings.Join(errs, ", "))
+		}
+	}
+	return nil
+}
+
+func (cmd *KubeconfigCommand) validateKubeconfigDir() error {
+	if cmd.outputPath != "" {
+		return errors.New("--output should not be used together with --kubeconfig-dir")
+	}
+	if cmd.merge {
+		return errors.New("--merge should not be used together with --kubeconfig-dir")
+	}
+	switch cmd.layout {
+	case layoutShoot, layoutAccount, layoutMerged:
+		return nil
+	}
+	return fmt.Errorf("invalid value for layout: %s", cmd.layout)
+}
+
+// downloadKubeconfigs saves the kubeconfig files of all runtimes matching the options to the kubeconfig directory
+func (cmd *KubeconfigCommand) downloadKubeconfigs(ctx context.Context, cred credential.Manager, kcClient client.Client) error {
+	rtClient := runtime.NewClient(ctx, GlobalOpts.KEBAPIURL(), cred)
+	params := runtime.ListParameters{}
+	if cmd.globalAccountID != "" {
 		params.GlobalAccountIDs = []string{cmd.globalAccountID}
+	}
+	if cmd.subAccountID != "" {
 		params.SubAccountIDs = []string{cmd.subAccountID}
 	}
+	if cmd.runtimeID != "" {
+		params.RuntimeIDs = []string{cmd.runtimeID}
+	}
+	if cmd.shoot != "" {
+		params.Shoots = []string{cmd.shoot}
+	}
 
 	rp, err := rtClient.ListRuntimes(params)
 	if err != nil {
-		return err
+		return errors.Wrap(err, "while listing runtimes")
 	}
 	if rp.Count < 1 {
 		return fmt.Errorf("no runtimes matched the input options")
 	}
-	if rp.Count > 1 {
-		return fmt.Errorf("multiple runtimes (%d) matched the input options", rp.Count)
+	// the order defines which kubeconfig wins when the kubeconfig files are merged
+	sort.Slice(rp.Data, func(i, j int) bool {
+		return rp.Data[i].ShootName < rp.Data[j].ShootName
+	})
+
+	merged := clientcmdapi.NewConfig()
+	for _, rt := range rp.Data {
+		kc, err := kcClient.GetKubeConfig(rt.GlobalAccountID, rt.RuntimeID)
+		if err != nil {
+			return errors.Wrapf(err, "while getting kubeconfig of runtime %s", rt.RuntimeID)
+		}
+
+		switch cmd.layout {
+		case layoutMerged:
+			err = cmd.mergeKubeconfig(merged, kc)
+			if err != nil {
+				return errors.Wrapf(err, "while merging kubeconfig of runtime %s", rt.RuntimeID)
+			}
+		case layoutAccount:
+			err = cmd.writeKubeconfig(filepath.Join(cmd.kubeconfigDir, rt.GlobalAccountID, fmt.Sprintf("%s.yaml", rt.ShootName)), []byte(kc))
+		default:
+			err = cmd.writeKubeconfig(filepath.Join(cmd.kubeconfigDir, fmt.Sprintf("%s.yaml", rt.ShootName)), []byte(kc))
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if cmd.layout != layoutMerged {
+		return nil
+	}
+	data, err := clientcmd.Write(*merged)
+	if err != nil {
+		return errors.Wrap(err, "while serializing merged kubeconfig")
+	}
+	return cmd.writeKubeconfig(filepath.Join(cmd.kubeconfigDir, mergedKubeconfigFile), data)
+}
+
+// mergeKubeconfig merges the kubeconfig into the given config following the KUBECONFIG merging rules,
+// the first cluster, context or user with a given name and the first current context win
+func (cmd *KubeconfigCommand) mergeKubeconfig(merged *clientcmdapi.Config, kubeconfig string) error {
+	cfg, err := clientcmd.Load([]byte(kubeconfig))
+	if err != nil {
+		return errors.Wrap(err, "while parsing kubeconfig")
+	}
+
+	var ignored []string
+	for name, cluster := range cfg.Clusters {
+		if _, found := merged.Clusters[name]; found {
+			ignored = append(ignored, "cluster "+name)
+			continue
+		}
+		merged.Clusters[name] = cluster
+	}
+	// the runtimes share the same OIDC user, so the already defined users are skipped silently
+	for name, authInfo := range cfg.AuthInfos {
+		if _, found := merged.AuthInfos[name]; found {
+			continue
+		}
+		merged.AuthInfos[name] = authInfo
+	}
+	for name, kubeContext := range cfg.Contexts {
+		if _, found := merged.Contexts[name]; found {
+			ignored = append(ignored, "context "+name)
+			continue
+		}
+		merged.Contexts[name] = kubeContext
+	}
+	if merged.CurrentContext == "" {
+		merged.CurrentContext = cfg.CurrentContext
+	}
+	if len(ignored) > 0 {
+		cmd.log.Printf("Ignoring already defined %s", strings.Join(ignored, ", "))
 	}
 
-	cmd.runtimeID = rp.Data[0].RuntimeID
-	cmd.globalAccountID = rp.Data[0].GlobalAccountID
+	return nil
+}
+
+func (cmd *KubeconfigCommand) writeKubeconfig(path string, data []byte) error {
+	err := os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return errors.Wrapf(err, "while creating directory for %s", path)
+	}
+	err = fileutil.WriteFileAtomic(path, data, 0600)
+	if err != nil {
+		return errors.Wrap(err, "while saving kubeconfig")
+	}
+	fmt.Printf("Kubeconfig saved to %s\n", path)
+
 	return nil
 }
 
 func (cmd *KubeconfigCommand) saveKubeconfig(kubeconfig string) error {
+	if cmd.outputPath == stdoutOutput {
+		_, err := fmt.Fprint(os.Stdout, kubeconfig)
+		return err
+	}
+	if cmd.merge {
+		return cmd.mergeIntoKubeconfigFile(kubeconfig)
+	}
+
 	// Assemble default output path based on cluster name if output path was not given
 	if cmd.outputPath == "" {
 		clusterName, err := clusterNameFromKubeconfig(kubeconfig)
@@ -136,7 +366,7 @@ func (cmd *KubeconfigCommand) saveKubeconfig(kubeconfig string) error {
 		cmd.outputPath = fmt.Sprintf("%s/%s.yaml", dir, clusterName)
 	}
 
-	err := ioutil.WriteFile(cmd.outputPath, []byte(kubeconfig), 0600)
+	err := fileutil.WriteFileAtomic(cmd.outputPath, []byte(kubeconfig), 0600)
 	if err != nil {
 		return errors.Wrap(err, "while saving kubeconfig")
 	}
@@ -145,6 +375,48 @@ func (cmd *KubeconfigCommand) saveKubeconfig(kubeconfig string) error {
 	return nil
 }
 
+// mergeIntoKubeconfigFile merges the kubeconfig into the existing kubeconfig file of the user, unlike the KUBECONFIG
+// merging rules the clusters, contexts and users of the Runtime win, so the file is refreshed when the command is repeated
+func (cmd *KubeconfigCommand) mergeIntoKubeconfigFile(kubeconfig string) error {
+	path := cmd.outputPath
+	if path == "" {
+		path = clientcmd.NewDefaultPathOptions().GetDefaultFilename()
+	}
+	cfg, err := clientcmd.Load([]byte(kubeconfig))
+	if err != nil {
+		return errors.Wrap(err, "while parsing kubeconfig")
+	}
+
+	existing := clientcmdapi.NewConfig()
+	if _, err := os.Stat(path); err == nil {
+		existing, err = clientcmd.LoadFromFile(path)
+		if err != nil {
+			return errors.Wrapf(err, "while loading kubeconfig %s", path)
+		}
+	} else if !os.IsNotExist(err) {
+		return errors.Wrapf(err, "while checking kubeconfig %s", path)
+	}
+
+	for name, cluster := range cfg.Clusters {
+		existing.Clusters[name] = cluster
+	}
+	for name, authInfo := range cfg.AuthInfos {
+		existing.AuthInfos[name] = authInfo
+	}
+	for name, kubeContext := range cfg.Contexts {
+		existing.Contexts[name] = kubeContext
+	}
+	if cfg.CurrentContext != "" {
+		existing.CurrentContext = cfg.CurrentContext
+	}
+
+	data, err := clientcmd.Write(*existing)
+	if err != nil {
+		return errors.Wrap(err, "while serializing merged kubeconfig")
+	}
+	return cmd.writeKubeconfig(path, data)
+}
+
 func clusterNameFromKubeconfig(rawKubeConfig string) (string, error) {
 	var kubeCfg kubeconfig
 	var clusterName string
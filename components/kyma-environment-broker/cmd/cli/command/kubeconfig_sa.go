@@ -0,0 +1,144 @@
+package command
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+const (
+	serviceAccountTokenTimeout  = time.Minute
+	serviceAccountTokenInterval = 2 * time.Second
+	serviceAccountManagedByKey  = "app.kubernetes.io/managed-by"
+	serviceAccountManagedBy     = "kcp-cli"
+)
+
+// serviceAccountKubeconfig creates the service account bound to the role in the namespace of the Runtime using
+// the operator kubeconfig and returns the kubeconfig which authenticates with the token of the service account.
+// The already existing service account, role binding and token secret are reused.
+func (cmd *KubeconfigCommand) serviceAccountKubeconfig(operatorKubeconfig string) (string, error) {
+	cfg, err := clientcmd.Load([]byte(operatorKubeconfig))
+	if err != nil {
+		return "", errors.Wrap(err, "while parsing kubeconfig")
+	}
+	restCfg, err := clientcmd.NewDefaultClientConfig(*cfg, &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return "", errors.Wrap(err, "while creating client config")
+	}
+	clientset, err := kubernetes.NewForConfig(restCfg)
+	if err != nil {
+		return "", errors.Wrap(err, "while creating kubernetes client")
+	}
+
+	token, err := cmd.ensureServiceAccountToken(clientset)
+	if err != nil {
+		return "", err
+	}
+
+	kubeContext, found := cfg.Contexts[cfg.CurrentContext]
+	if !found {
+		return "", fmt.Errorf("current context %s not found in kubeconfig", cfg.CurrentContext)
+	}
+	cluster, found := cfg.Clusters[kubeContext.Cluster]
+	if !found {
+		return "", fmt.Errorf("cluster %s not found in kubeconfig", kubeContext.Cluster)
+	}
+
+	saCfg := clientcmdapi.NewConfig()
+	saCfg.Clusters[kubeContext.Cluster] = cluster
+	saCfg.AuthInfos[cmd.serviceAccount] = &clientcmdapi.AuthInfo{Token: token}
+	saCfg.Contexts[kubeContext.Cluster] = &clientcmdapi.Context{
+		Cluster:   kubeContext.Cluster,
+		AuthInfo:  cmd.serviceAccount,
+		Namespace: cmd.namespace,
+	}
+	saCfg.CurrentContext = kubeContext.Cluster
+
+	data, err := clientcmd.Write(*saCfg)
+	if err != nil {
+		return "", errors.Wrap(err, "while serializing service account kubeconfig")
+	}
+	return string(data), nil
+}
+
+// ensureServiceAccountToken creates the namespace, the service account, its role binding and token secret if they
+// do not exist, and returns the token of the service account
+func (cmd *KubeconfigCommand) ensureServiceAccountToken(clientset kubernetes.Interface) (string, error) {
+	labels := map[string]string{serviceAccountManagedByKey: serviceAccountManagedBy}
+
+	_, err := clientset.CoreV1().Namespaces().Create(&corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: cmd.namespace, Labels: labels},
+	})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return "", errors.Wrapf(err, "while creating namespace %s", cmd.namespace)
+	}
+
+	_, err = clientset.CoreV1().ServiceAccounts(cmd.namespace).Create(&corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: cmd.serviceAccount, Namespace: cmd.namespace, Labels: labels},
+	})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return "", errors.Wrapf(err, "while creating service account %s/%s", cmd.namespace, cmd.serviceAccount)
+	}
+
+	bindingName := fmt.Sprintf("%s-%s", cmd.serviceAccount, cmd.role)
+	_, err = clientset.RbacV1().RoleBindings(cmd.namespace).Create(&rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: bindingName, Namespace: cmd.namespace, Labels: labels},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     cmd.role,
+		},
+		Subjects: []rbacv1.Subject{{
+			Kind:      rbacv1.ServiceAccountKind,
+			Name:      cmd.serviceAccount,
+			Namespace: cmd.namespace,
+		}},
+	})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return "", errors.Wrapf(err, "while creating role binding %s/%s", cmd.namespace, bindingName)
+	}
+
+	// the token secret is created explicitly, so the token does not depend on the automatically generated secrets
+	secretName := fmt.Sprintf("%s-token", cmd.serviceAccount)
+	_, err = clientset.CoreV1().Secrets(cmd.namespace).Create(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        secretName,
+			Namespace:   cmd.namespace,
+			Labels:      labels,
+			Annotations: map[string]string{corev1.ServiceAccountNameKey: cmd.serviceAccount},
+		},
+		Type: corev1.SecretTypeServiceAccountToken,
+	})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return "", errors.Wrapf(err, "while creating token secret %s/%s", cmd.namespace, secretName)
+	}
+
+	var token string
+	err = wait.PollImmediate(serviceAccountTokenInterval, serviceAccountTokenTimeout, func() (bool, error) {
+		secret, err := clientset.CoreV1().Secrets(cmd.namespace).Get(secretName, metav1.GetOptions{})
+		if err != nil {
+			return false, errors.Wrapf(err, "while getting token secret %s/%s", cmd.namespace, secretName)
+		}
+		// the existing secret with the same name can hold the token of another service account
+		if secret.Type != corev1.SecretTypeServiceAccountToken || secret.Annotations[corev1.ServiceAccountNameKey] != cmd.serviceAccount {
+			return false, fmt.Errorf("secret %s/%s is not the token of service account %s", cmd.namespace, secretName, cmd.serviceAccount)
+		}
+		token = string(secret.Data[corev1.ServiceAccountTokenKey])
+		return token != "", nil
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "while waiting for the token of service account %s/%s", cmd.namespace, cmd.serviceAccount)
+	}
+	cmd.log.Printf("Using service account %s/%s bound to the %s cluster role", cmd.namespace, cmd.serviceAccount, cmd.role)
+
+	return token, nil
+}
@@ -9,9 +9,10 @@ import (
 
 // LoginCommand represents an execution of the kcp login command
 type LoginCommand struct {
-	log      logger.Logger
-	username string
-	password string
+	log        logger.Logger
+	username   string
+	password   string
+	deviceCode bool
 }
 
 // NewLoginCmd constructs a new instance of LoginCommand and configures it in terms of a cobra.Command
@@ -23,12 +24,15 @@ func NewLoginCmd(log logger.Logger) *cobra.Command {
 		Short:   "Performs OIDC login required by all commands.",
 		Long: `Initiates OIDC login to obtain the ID token which is required by all CLI commands.
 By default, without any options, the OIDC authorization code flow is executed. It prompts the user to navigate to a local address in the browser and get redirected to the OIDC Authentication Server login page.
-Service accounts can execute the resource owner credentials flow by specifying the --username and --password options.`,
+On machines without a browser, specify the --device-code option to execute the OIDC device code flow. It prints the address and the code to enter in the browser on any other device.
+Service accounts can execute the resource owner credentials flow by specifying the --username and --password options.
+The ID token and the refresh token are stored in the $HOME/.kcp/credentials directory. The other commands refresh the stored token when it expires, so you do not need to log in again until the refresh token expires.`,
 		PreRunE: func(_ *cobra.Command, _ []string) error { return cmd.Validate() },
 		RunE:    func(cobraCmd *cobra.Command, _ []string) error { return cmd.Run(cobraCmd) },
 	}
 	cobraCmd.Flags().StringVarP(&cmd.username, "username", "u", "", "Username to use for the resource owner credentials flow.")
 	cobraCmd.Flags().StringVarP(&cmd.password, "password", "p", "", "Password to use for the resource owner credentials flow.")
+	cobraCmd.Flags().BoolVar(&cmd.deviceCode, "device-code", false, "Executes the device code flow instead of the authorization code flow.")
 
 	return cobraCmd
 }
@@ -37,10 +41,13 @@ Service accounts can execute the resource owner credentials flow by specifying t
 func (cmd *LoginCommand) Run(cobraCmd *cobra.Command) error {
 	cred := CLICredentialManager(cmd.log)
 	var err error
-	if cmd.username == "" {
-		_, err = cred.GetTokenByAuthCode(cobraCmd.Context())
-	} else {
+	switch {
+	case cmd.username != "":
 		_, err = cred.GetTokenByROPC(cobraCmd.Context(), cmd.username, cmd.password)
+	case cmd.deviceCode:
+		_, err = cred.GetTokenByDeviceCode(cobraCmd.Context())
+	default:
+		_, err = cred.GetTokenByAuthCode(cobraCmd.Context())
 	}
 
 	if err != nil {
@@ -54,5 +61,8 @@ func (cmd *LoginCommand) Validate() error {
 	if cmd.username != "" && cmd.password == "" || cmd.username == "" && cmd.password != "" {
 		return errors.New("both username and password must be specified for resource owner credentials login")
 	}
+	if cmd.deviceCode && cmd.username != "" {
+		return errors.New("device code login cannot be used together with username and password")
+	}
 	return nil
 }
@@ -11,15 +11,26 @@ import (
 )
 
 var configPath string
+var profile string
+
+// activeProfile holds the name of the profile applied from the config file, if any
+var activeProfile string
 
 const (
 	configEnv string = "KCPCONFIG"
 	configDir string = ".kcp"
+	// profileEnv selects the profile of the config file if the --profile option is not given
+	profileEnv string = "KCP_PROFILE"
+	// profilesKey holds the named profiles in the config file
+	profilesKey string = "profiles"
+	// defaultProfileKey holds the name of the profile used if no profile is selected
+	defaultProfileKey string = "default-profile"
 )
 
 const (
 	tableOutput string = "table"
 	jsonOutput  string = "json"
+	yamlOutput  string = "yaml"
 )
 
 const (
@@ -27,8 +38,13 @@ const (
 	targetSubaccount = "subaccount"
 	targetRuntimeID  = "runtime-id"
 	targetRegion     = "region"
+	targetPlan       = "plan"
+	targetShoot      = "shoot"
 )
 
+// allPlanNames lists the names of the service plans offered by Kyma Environment Broker
+var allPlanNames = []string{"azure", "azure_lite", "gcp", "trial"}
+
 // GlobalOptionsKey is the type for holding the configuration key for each global parameter
 type GlobalOptionsKey struct {
 	oidcIssuerURL      string
@@ -63,18 +79,27 @@ func SetGlobalOpts(cmd *cobra.Command) {
 	cmd.PersistentFlags().String(GlobalOpts.kebAPIURL, "", "Kyma Environment Broker API URL to use for all commands. Can also be set using the KCP_KEB_API_URL environment variable.")
 	viper.BindPFlag(GlobalOpts.kebAPIURL, cmd.PersistentFlags().Lookup(GlobalOpts.kebAPIURL))
 
-	cmd.PersistentFlags().String(GlobalOpts.kubeconfigAPIURL, "", "OIDC Kubeconfig Service API URL used by the kcp kubeconfig and taskrun commands. Can also be set using the KCP_KUBECONFIG_API_URL environment variable.")
+	cmd.PersistentFlags().String(GlobalOpts.kubeconfigAPIURL, "", "OIDC Kubeconfig Service API URL used by the kcp kubeconfig, port-forward, and taskrun commands. Can also be set using the KCP_KUBECONFIG_API_URL environment variable.")
 	viper.BindPFlag(GlobalOpts.kubeconfigAPIURL, cmd.PersistentFlags().Lookup(GlobalOpts.kubeconfigAPIURL))
 
 	cmd.PersistentFlags().String(GlobalOpts.gardenerKubeconfig, "", "Path to the kubeconfig file of the corresponding Gardener project which has permissions to list/get Shoots. Can also be set using the KCP_GARDENER_KUBECONFIG environment variable.")
 	viper.BindPFlag(GlobalOpts.gardenerKubeconfig, cmd.PersistentFlags().Lookup(GlobalOpts.gardenerKubeconfig))
 }
 
+// allGlobalOpts lists the configuration keys of all global parameters
+func allGlobalOpts() []string {
+	return []string{GlobalOpts.oidcIssuerURL, GlobalOpts.oidcClientID, GlobalOpts.oidcClientSecret, GlobalOpts.kebAPIURL, GlobalOpts.kubeconfigAPIURL, GlobalOpts.gardenerKubeconfig}
+}
+
+// requiredGlobalOpts lists the configuration keys of the global parameters required by all commands
+func requiredGlobalOpts() []string {
+	return []string{GlobalOpts.oidcIssuerURL, GlobalOpts.oidcClientID, GlobalOpts.oidcClientSecret, GlobalOpts.kebAPIURL}
+}
+
 // ValidateGlobalOpts checks the presence of the required global configuration parameters
 func ValidateGlobalOpts() error {
-	var reqGlobalOpts = []string{GlobalOpts.oidcIssuerURL, GlobalOpts.oidcClientID, GlobalOpts.oidcClientSecret, GlobalOpts.kebAPIURL}
 	var missingGlobalOpts []string
-	for _, opt := range reqGlobalOpts {
+	for _, opt := range requiredGlobalOpts() {
 		if viper.GetString(opt) == "" {
 			missingGlobalOpts = append(missingGlobalOpts, opt)
 		}
@@ -118,16 +143,22 @@ func (keys *GlobalOptionsKey) GardenerKubeconfig() string {
 
 // SetOutputOpt configures the optput type option on the given command
 func SetOutputOpt(cmd *cobra.Command, opt *string) {
-	cmd.Flags().StringVarP(opt, "output", "o", tableOutput, fmt.Sprintf("Output type of displayed Runtime(s). The possible values are: %s, %s.", tableOutput, jsonOutput))
+	cmd.Flags().StringVarP(opt, "output", "o", tableOutput, fmt.Sprintf("Output type of displayed Runtime(s). The possible values are: %s, %s, %s, %s=<spec>, %s=<template>. The %s spec is a comma-separated list of <header>:<field path> columns, e.g. %s=SHOOT:.shootName,GLOBAL_ACCOUNT:.globalAccountID. The fields are referenced by their names in the %s output.",
+		tableOutput, jsonOutput, yamlOutput, customColumnsOutput, goTemplateOutput, customColumnsOutput, customColumnsOutput, jsonOutput))
+	_ = cmd.Flags().SetAnnotation("output", outputFormatAnnotation, []string{"true"})
+	// the registration fails only if the flag does not exist
+	_ = cmd.RegisterFlagCompletionFunc("output", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{tableOutput, jsonOutput, yamlOutput}, cobra.ShellCompDirectiveNoFileComp
+	})
 }
 
 // ValidateOutputOpt checks whether the given optput type is one of the valid values
 func ValidateOutputOpt(opt string) error {
 	switch opt {
-	case tableOutput, jsonOutput:
+	case tableOutput, jsonOutput, yamlOutput:
 		return nil
 	}
-	return fmt.Errorf("invalid value for output: %s", opt)
+	return validateObjectOutput(opt)
 }
 
 // SetRuntimeTargetOpts configures runtime target options on the given command
@@ -139,10 +170,43 @@ A target specifier is a comma-separated list of the following selectors:
   account=<REGEXP>    : Regex pattern to match against the Runtime's global account field, e.g. "CA50125541TID000000000741207136", "CA.*"
   subaccount=<REGEXP> : Regex pattern to match against the Runtime's subaccount field, e.g. "0d20e315-d0b4-48a2-9512-49bc8eb03cd1"
   region=<REGEXP>     : Regex pattern to match against the Runtime's provider region field, e.g. "europe|eu-"
-  runtime-id=<ID>     : Runtime ID is used to indicate a specific Runtime`)
+  shoot=<REGEXP>      : Regex pattern to match against the Runtime's Gardener Shoot cluster name, e.g. "c-178e034"
+  runtime-id=<ID>     : Runtime ID is used to indicate a specific Runtime
+  plan=<NAME>         : Name of the Runtime's service plan, one of: azure, azure_lite, gcp, trial`)
 	cmd.Flags().StringArrayVarP(targetExcludeInputs, "target-exclude", "e", nil,
 		`List of Runtime target specifiers to exclude. You can specify this option multiple times.
 A target specifier is a comma-separated list of the selectors described under the --target option.`)
+	// the registration fails only if the flag does not exist
+	_ = cmd.RegisterFlagCompletionFunc("target", runtimeTargetCompletion(true))
+	_ = cmd.RegisterFlagCompletionFunc("target-exclude", runtimeTargetCompletion(false))
+}
+
+// runtimeTargetCompletion returns the shell completion of the target specifier, the selectors completed
+// before the last comma of the specifier are kept in the completed values
+func runtimeTargetCompletion(include bool) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		prefix, selector := "", toComplete
+		if i := strings.LastIndex(toComplete, ","); i >= 0 {
+			prefix, selector = toComplete[:i+1], toComplete[i+1:]
+		}
+
+		completions := make([]string, 0)
+		if strings.HasPrefix(selector, targetPlan+"=") {
+			for _, plan := range allPlanNames {
+				completions = append(completions, fmt.Sprintf("%s%s=%s", prefix, targetPlan, plan))
+			}
+			return completions, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		keys := []string{targetAccount, targetSubaccount, targetRegion, targetShoot, targetRuntimeID, targetPlan}
+		if include {
+			completions = append(completions, prefix+internal.TargetAll)
+		}
+		for _, key := range keys {
+			completions = append(completions, fmt.Sprintf("%s%s=", prefix, key))
+		}
+		return completions, cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+	}
 }
 
 // ValidateTransformRuntimeTargetOpts checks the validity of runtime target options, and transforms them for internal usage
@@ -208,12 +272,27 @@ func parseRuntimeTarget(targetInput string, targets *[]internal.RuntimeTarget, i
 				return err
 			}
 			target.Region = selectorValue
+		case targetShoot:
+			err := checkRuntimeTargetSelector(selectorKey, selectorValue, flagName)
+			if err != nil {
+				return err
+			}
+			target.Shoot = selectorValue
 		case targetRuntimeID:
 			err := checkRuntimeTargetSelector(selectorKey, selectorValue, flagName)
 			if err != nil {
 				return err
 			}
 			target.RuntimeID = selectorValue
+		case targetPlan:
+			err := checkRuntimeTargetSelector(selectorKey, selectorValue, flagName)
+			if err != nil {
+				return err
+			}
+			if !containsString(allPlanNames, selectorValue) {
+				return fmt.Errorf("%s %s has invalid value: %s", flagName, selectorKey, selectorValue)
+			}
+			target.PlanName = selectorValue
 		default:
 			return fmt.Errorf("invalid selector: %s %s", flagName, selectorKey)
 		}
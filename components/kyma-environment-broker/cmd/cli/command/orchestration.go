@@ -1,15 +1,23 @@
 package command
 
 import (
-	"errors"
 	"fmt"
+	"io"
+	"os"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/cmd/cli/logger"
+	orchestrationClient "github.com/kyma-project/control-plane/components/kyma-environment-broker/common/orchestration"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/orchestration"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
+const operationsSubview = "operations"
+
 // OrchestrationCommand represents an execution of the kcp orchestrations command
 type OrchestrationCommand struct {
 	log       logger.Logger
@@ -22,25 +30,30 @@ type OrchestrationCommand struct {
 func NewOrchestrationCmd(log logger.Logger) *cobra.Command {
 	cmd := OrchestrationCommand{log: log}
 	cobraCmd := &cobra.Command{
-		Use:     "orchestrations [id]",
+		Use:     "orchestrations [id] [operations]",
 		Aliases: []string{"orchestration", "o"},
 		Short:   "Displays Kyma Control Plane (KCP) orchestrations.",
 		Long: `Displays KCP orchestrations and their primary attributes, such as identifiers, type, state, parameters, or Runtime operations.
-The command has two modes:
+The command has three modes:
   - Without specifying an orchestration ID as an argument. In this mode, the command lists all orchestrations, or orchestrations matching the --state option, if provided.
   - When specifying an orchestration ID as an argument. In this mode, the command displays details about the specific orchestration.
-     If the optional --operation flag is provided, it displays details of the specified Runtime operation within the orchestration.`,
+     If the optional --operation flag is provided, it displays details of the specified Runtime operation within the orchestration.
+  - When specifying an orchestration ID and the operations subview as arguments. In this mode, the command lists all Runtime operations scheduled by the orchestration.`,
 		Example: `  kcp orchestrations --state inprogress                                   Display all orchestrations which are in progress.
   kcp orchestration 0c4357f5-83e0-4b72-9472-49b5cd417c00                  Display details about a specific orchestration.
-  kcp orchestration 0c4357f5-83e0-4b72-9472-49b5cd417c00 --operation OID  Display details of the specified Runtime operation within the orchestration.`,
-		Args:    cobra.MaximumNArgs(1),
+  kcp orchestration 0c4357f5-83e0-4b72-9472-49b5cd417c00 --operation OID  Display details of the specified Runtime operation within the orchestration.
+  kcp orchestration 0c4357f5-83e0-4b72-9472-49b5cd417c00 operations       Display all Runtime operations scheduled by the orchestration.`,
+		Args:    cobra.MaximumNArgs(2),
 		PreRunE: func(_ *cobra.Command, args []string) error { return cmd.Validate(args) },
-		RunE:    func(_ *cobra.Command, args []string) error { return cmd.Run(args) },
+		RunE:    func(cobraCmd *cobra.Command, args []string) error { return cmd.Run(cobraCmd, args) },
 	}
 
 	SetOutputOpt(cobraCmd, &cmd.output)
 	cobraCmd.Flags().StringVarP(&cmd.state, "state", "s", "", fmt.Sprintf("Filter output by state. The possible values are: %s.", strings.Join(allOrchestrationStates(), ", ")))
 	cobraCmd.Flags().StringVar(&cmd.operation, "operation", "", "Option that displays details of the specified Runtime operation when a given orchestration is selected.")
+	cobraCmd.AddCommand(NewOrchestrationCancelCmd(log))
+	cobraCmd.AddCommand(NewOrchestrationRetryCmd(log))
+	cobraCmd.AddCommand(NewOrchestrationRuntimesCmd(log))
 	return cobraCmd
 }
 
@@ -50,7 +63,7 @@ func orchestrationToCLIState(state string) string {
 
 func allOrchestrationStates() []string {
 	var states = []string{}
-	for _, state := range []string{internal.Pending, internal.InProgress, internal.Succeeded, internal.Failed} {
+	for _, state := range []string{internal.Pending, internal.InProgress, internal.Succeeded, internal.Failed, internal.Canceling, internal.Canceled} {
 		states = append(states, orchestrationToCLIState(state))
 	}
 
@@ -73,9 +86,106 @@ func validateOrchestrationState(inputState string, args []string) error {
 }
 
 // Run executes the orchestrations command
-func (cmd *OrchestrationCommand) Run(args []string) error {
-	fmt.Println("Not implemented yet.")
-	return nil
+func (cmd *OrchestrationCommand) Run(cobraCmd *cobra.Command, args []string) error {
+	cred := CLICredentialManager(cmd.log)
+	client := orchestrationClient.NewClient(cobraCmd.Context(), GlobalOpts.KEBAPIURL(), cred)
+
+	switch {
+	case len(args) == 0:
+		return cmd.listOrchestrations(client)
+	case len(args) == 2:
+		return cmd.listOperations(client, args[0])
+	case cmd.operation != "":
+		return cmd.showOperation(client, args[0], cmd.operation)
+	default:
+		return cmd.showOrchestration(client, args[0])
+	}
+}
+
+func (cmd *OrchestrationCommand) listOrchestrations(client orchestrationClient.Client) error {
+	list, err := client.ListOrchestrations()
+	if err != nil {
+		return errors.Wrap(err, "while listing orchestrations")
+	}
+
+	orchestrations := make([]orchestration.StatusResponse, 0, len(list.Data))
+	for _, o := range list.Data {
+		if cmd.state == "" || orchestrationToCLIState(o.State) == cmd.state {
+			orchestrations = append(orchestrations, o)
+		}
+	}
+
+	if isObjectOutput(cmd.output) {
+		return printObject(os.Stdout, cmd.output, orchestrations)
+	}
+	return printOrchestrations(os.Stdout, orchestrations)
+}
+
+func (cmd *OrchestrationCommand) showOrchestration(client orchestrationClient.Client, orchestrationID string) error {
+	status, err := client.GetOrchestration(orchestrationID)
+	if err != nil {
+		return errors.Wrap(err, "while getting orchestration")
+	}
+
+	if isObjectOutput(cmd.output) {
+		return printObject(os.Stdout, cmd.output, status)
+	}
+	return printOrchestrations(os.Stdout, []orchestration.StatusResponse{status})
+}
+
+func (cmd *OrchestrationCommand) listOperations(client orchestrationClient.Client, orchestrationID string) error {
+	list, err := client.ListOperations(orchestrationID)
+	if err != nil {
+		return errors.Wrap(err, "while listing operations")
+	}
+
+	if isObjectOutput(cmd.output) {
+		return printObject(os.Stdout, cmd.output, list.Data)
+	}
+	return printOperations(os.Stdout, list.Data)
+}
+
+func (cmd *OrchestrationCommand) showOperation(client orchestrationClient.Client, orchestrationID, operationID string) error {
+	operation, err := client.GetOperation(orchestrationID, operationID)
+	if err != nil {
+		return errors.Wrap(err, "while getting operation")
+	}
+
+	if isObjectOutput(cmd.output) {
+		return printObject(os.Stdout, cmd.output, operation)
+	}
+	return printOperations(os.Stdout, []orchestration.OperationResponse{operation.OperationResponse})
+}
+
+func printOrchestrations(w io.Writer, orchestrations []orchestration.StatusResponse) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "ORCHESTRATION ID\tSTATE\tSCHEDULE\tDRY RUN\tCREATED AT\tDESCRIPTION")
+	for _, o := range orchestrations {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%t\t%s\t%s\n",
+			o.OrchestrationID,
+			orchestrationToCLIState(o.State),
+			o.Parameters.Strategy.Schedule,
+			o.Parameters.DryRun,
+			o.CreatedAt.Format(time.RFC3339),
+			o.Description)
+	}
+	return tw.Flush()
+}
+
+func printOperations(w io.Writer, operations []orchestration.OperationResponse) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "OPERATION ID\tSHOOT\tGLOBAL ACCOUNT\tRUNTIME ID\tSTATE\tRETRIES\tREASON")
+	for _, op := range operations {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%d\t%s\n",
+			op.OperationID,
+			op.ShootName,
+			op.GlobalAccountID,
+			op.RuntimeID,
+			orchestrationToCLIState(op.State),
+			op.RetryCount,
+			op.ResultReason)
+	}
+	return tw.Flush()
 }
 
 // Validate checks the input parameters of the orchestrations command
@@ -93,6 +203,14 @@ func (cmd *OrchestrationCommand) Validate(args []string) error {
 	if cmd.operation != "" && len(args) == 0 {
 		return errors.New("--operation should only be used when orchestration id is given as an argument")
 	}
+	if len(args) == 2 {
+		if args[1] != operationsSubview {
+			return fmt.Errorf("invalid subview: %s, the only supported subview is %s", args[1], operationsSubview)
+		}
+		if cmd.operation != "" {
+			return fmt.Errorf("--operation should not be used together with the %s subview", operationsSubview)
+		}
+	}
 
 	return nil
 }
@@ -0,0 +1,59 @@
+package command
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/cmd/cli/logger"
+	orchestrationClient "github.com/kyma-project/control-plane/components/kyma-environment-broker/common/orchestration"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/orchestration"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// OrchestrationCancelCommand represents an execution of the kcp orchestrations cancel command
+type OrchestrationCancelCommand struct {
+	log    logger.Logger
+	output string
+}
+
+// NewOrchestrationCancelCmd constructs a new instance of OrchestrationCancelCommand and configures it in terms of a cobra.Command
+func NewOrchestrationCancelCmd(log logger.Logger) *cobra.Command {
+	cmd := OrchestrationCancelCommand{log: log}
+	cobraCmd := &cobra.Command{
+		Use:   "cancel {id}",
+		Short: "Cancels the orchestration.",
+		Long: `Cancels an orchestration which is not finished yet. The orchestration gets the canceling state and its Runtime operations which are in progress get the canceled state.
+The scheduled Runtime operations are not executed. Once no operation is in progress, the orchestration gets the canceled state.
+The cancellation does not revert the Runtime operations which have already finished. Use the kcp orchestrations retry command to schedule the canceled operations again.`,
+		Example: `  kcp orchestrations cancel 0c4357f5-83e0-4b72-9472-49b5cd417c00  Cancel the orchestration.`,
+		Args:    cobra.ExactArgs(1),
+		PreRunE: func(_ *cobra.Command, _ []string) error { return cmd.Validate() },
+		RunE:    func(cobraCmd *cobra.Command, args []string) error { return cmd.Run(cobraCmd, args[0]) },
+	}
+
+	SetOutputOpt(cobraCmd, &cmd.output)
+	return cobraCmd
+}
+
+// Run executes the orchestrations cancel command
+func (cmd *OrchestrationCancelCommand) Run(cobraCmd *cobra.Command, orchestrationID string) error {
+	cred := CLICredentialManager(cmd.log)
+	client := orchestrationClient.NewClient(cobraCmd.Context(), GlobalOpts.KEBAPIURL(), cred)
+
+	status, err := client.CancelOrchestration(orchestrationID)
+	if err != nil {
+		return errors.Wrap(err, "while canceling orchestration")
+	}
+
+	if isObjectOutput(cmd.output) {
+		return printObject(os.Stdout, cmd.output, status)
+	}
+	fmt.Printf("Orchestration %s is being canceled\n", orchestrationID)
+	return printOrchestrations(os.Stdout, []orchestration.StatusResponse{status})
+}
+
+// Validate checks the input parameters of the orchestrations cancel command
+func (cmd *OrchestrationCancelCommand) Validate() error {
+	return ValidateOutputOpt(cmd.output)
+}
@@ -0,0 +1,73 @@
+package command
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/cmd/cli/logger"
+	orchestrationClient "github.com/kyma-project/control-plane/components/kyma-environment-broker/common/orchestration"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/orchestration"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// OrchestrationRetryCommand represents an execution of the kcp orchestrations retry command
+type OrchestrationRetryCommand struct {
+	log          logger.Logger
+	output       string
+	retryRequest orchestration.RetryRequest
+}
+
+// NewOrchestrationRetryCmd constructs a new instance of OrchestrationRetryCommand and configures it in terms of a cobra.Command
+func NewOrchestrationRetryCmd(log logger.Logger) *cobra.Command {
+	cmd := OrchestrationRetryCommand{log: log}
+	cobraCmd := &cobra.Command{
+		Use:   "retry {id} [--operation OID ...]",
+		Short: "Retries the failed and canceled Runtime operations of the orchestration.",
+		Long: `Retries the Runtime operations of a failed or canceled orchestration. The retried operations are scheduled again within the same orchestration.
+By default, all failed and canceled operations are retried. Use the --failed-only flag to skip the canceled operations, or the --operation flag to retry only the specified operations.
+Use the --dry-run flag to list the operations which would be retried without scheduling them.`,
+		Example: `  kcp orchestrations retry 0c4357f5-83e0-4b72-9472-49b5cd417c00                  Retry all failed and canceled Runtime operations of the orchestration.
+  kcp orchestrations retry 0c4357f5-83e0-4b72-9472-49b5cd417c00 --failed-only    Retry only the failed Runtime operations of the orchestration.
+  kcp orchestrations retry 0c4357f5-83e0-4b72-9472-49b5cd417c00 --operation OID  Retry the specified Runtime operation of the orchestration.
+  kcp orchestrations retry 0c4357f5-83e0-4b72-9472-49b5cd417c00 --dry-run        Display the Runtime operations which would be retried.`,
+		Args:    cobra.ExactArgs(1),
+		PreRunE: func(_ *cobra.Command, _ []string) error { return cmd.Validate() },
+		RunE:    func(cobraCmd *cobra.Command, args []string) error { return cmd.Run(cobraCmd, args[0]) },
+	}
+
+	SetOutputOpt(cobraCmd, &cmd.output)
+	cobraCmd.Flags().StringSliceVar(&cmd.retryRequest.Operations, "operation", nil, "ID of the Runtime operation to retry. Can be specified multiple times. By default, all failed and canceled operations are retried.")
+	cobraCmd.Flags().BoolVar(&cmd.retryRequest.FailedOnly, "failed-only", false, "Option that retries only the failed Runtime operations and skips the canceled ones.")
+	cobraCmd.Flags().BoolVar(&cmd.retryRequest.DryRun, "dry-run", false, "Option that displays the Runtime operations which would be retried without scheduling them.")
+	return cobraCmd
+}
+
+// Run executes the orchestrations retry command
+func (cmd *OrchestrationRetryCommand) Run(cobraCmd *cobra.Command, orchestrationID string) error {
+	cred := CLICredentialManager(cmd.log)
+	client := orchestrationClient.NewClient(cobraCmd.Context(), GlobalOpts.KEBAPIURL(), cred)
+
+	response, err := client.RetryOrchestration(orchestrationID, cmd.retryRequest)
+	if err != nil {
+		return errors.Wrap(err, "while retrying orchestration")
+	}
+
+	if isObjectOutput(cmd.output) {
+		return printObject(os.Stdout, cmd.output, response)
+	}
+	if response.DryRun {
+		fmt.Printf("Operations which would be retried: %d\n", len(response.RetryOperations))
+	} else {
+		fmt.Printf("Retried operations: %d\n", len(response.RetryOperations))
+	}
+	for _, id := range response.RetryOperations {
+		fmt.Println(id)
+	}
+	return nil
+}
+
+// Validate checks the input parameters of the orchestrations retry command
+func (cmd *OrchestrationRetryCommand) Validate() error {
+	return ValidateOutputOpt(cmd.output)
+}
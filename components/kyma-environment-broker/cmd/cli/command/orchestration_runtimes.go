@@ -0,0 +1,83 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/cmd/cli/logger"
+	orchestrationClient "github.com/kyma-project/control-plane/components/kyma-environment-broker/common/orchestration"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// OrchestrationRuntimesCommand represents an execution of the kcp orchestrations runtimes command
+type OrchestrationRuntimesCommand struct {
+	log    logger.Logger
+	output string
+}
+
+// NewOrchestrationRuntimesCmd constructs a new instance of OrchestrationRuntimesCommand and configures it in terms of a cobra.Command
+func NewOrchestrationRuntimesCmd(log logger.Logger) *cobra.Command {
+	cmd := OrchestrationRuntimesCommand{log: log}
+	cobraCmd := &cobra.Command{
+		Use:   "runtimes {id}",
+		Short: "Displays the Runtimes targeted by the orchestration.",
+		Long: `Displays the Runtimes resolved from the targets of the orchestration, so the targets can be checked before the Runtime operations are executed.
+The orchestration resolves its targets when it is started. For the pending orchestration which has not resolved its targets yet, the targets are resolved
+when the command is executed and the result is marked as a preview, because the Runtimes resolved when the orchestration is started can differ.`,
+		Example: `  kcp orchestrations runtimes 0c4357f5-83e0-4b72-9472-49b5cd417c00  Display the Runtimes targeted by the orchestration.`,
+		Args:    cobra.ExactArgs(1),
+		PreRunE: func(_ *cobra.Command, _ []string) error { return cmd.Validate() },
+		RunE:    func(cobraCmd *cobra.Command, args []string) error { return cmd.Run(cobraCmd, args[0]) },
+	}
+
+	SetOutputOpt(cobraCmd, &cmd.output)
+	return cobraCmd
+}
+
+// Run executes the orchestrations runtimes command
+func (cmd *OrchestrationRuntimesCommand) Run(cobraCmd *cobra.Command, orchestrationID string) error {
+	cred := CLICredentialManager(cmd.log)
+	client := orchestrationClient.NewClient(cobraCmd.Context(), GlobalOpts.KEBAPIURL(), cred)
+
+	list, err := client.ListRuntimes(orchestrationID)
+	if err != nil {
+		return errors.Wrap(err, "while listing runtimes")
+	}
+
+	// the custom columns are printed for the Runtimes, the other outputs print the whole response
+	if isCustomColumnsOutput(cmd.output) {
+		return printObject(os.Stdout, cmd.output, list.Data)
+	}
+	if isObjectOutput(cmd.output) {
+		return printObject(os.Stdout, cmd.output, list)
+	}
+	if list.Preview {
+		fmt.Printf("Orchestration %s has not resolved its targets yet, the Runtimes are a preview\n", orchestrationID)
+	}
+	return printOrchestrationRuntimes(os.Stdout, list.Data)
+}
+
+func printOrchestrationRuntimes(w io.Writer, runtimes []internal.Runtime) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "RUNTIME ID\tINSTANCE ID\tGLOBAL ACCOUNT\tSUBACCOUNT\tSHOOT\tMAINTENANCE WINDOW")
+	for _, rt := range runtimes {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s-%s\n",
+			rt.RuntimeID,
+			rt.InstanceID,
+			rt.GlobalAccountID,
+			rt.SubAccountID,
+			rt.ShootName,
+			rt.MaintenanceWindowBegin.Format("15:04"),
+			rt.MaintenanceWindowEnd.Format("15:04"))
+	}
+	return tw.Flush()
+}
+
+// Validate checks the input parameters of the orchestrations runtimes command
+func (cmd *OrchestrationRuntimesCommand) Validate() error {
+	return ValidateOutputOpt(cmd.output)
+}
@@ -0,0 +1,127 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/cmd/cli/logger"
+	orchestrationClient "github.com/kyma-project/control-plane/components/kyma-environment-broker/common/orchestration"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/orchestration"
+	"github.com/pkg/errors"
+)
+
+// maxWaitErrors is the number of consecutive failed polls after which the waiting for the orchestration is given up
+const maxWaitErrors = 5
+
+// orchestrationError is returned when the awaited orchestration finished without success
+type orchestrationError struct {
+	orchestrationID string
+	state           string
+	description     string
+}
+
+func (e *orchestrationError) Error() string {
+	if e.description == "" {
+		return fmt.Sprintf("orchestration %s %s", e.orchestrationID, e.state)
+	}
+	return fmt.Sprintf("orchestration %s %s: %s", e.orchestrationID, e.state, e.description)
+}
+
+// orchestrationWaiter polls the orchestration until it reaches a terminal state and prints the progress
+// each time the state of the orchestration or of its operations changes
+type orchestrationWaiter struct {
+	client   orchestrationClient.Client
+	log      logger.Logger
+	out      io.Writer
+	interval time.Duration
+}
+
+// Wait blocks until the orchestration is finished, the timeout elapses or the context is cancelled. The error is nil
+// only if the orchestration succeeded, the failed or canceled orchestration is reported with orchestrationError
+func (w *orchestrationWaiter) Wait(ctx context.Context, orchestrationID string, timeout time.Duration) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	lastProgress := ""
+	failedPolls := 0
+	for {
+		status, progress, err := w.poll(orchestrationID)
+		switch {
+		case err != nil:
+			failedPolls++
+			if failedPolls >= maxWaitErrors {
+				return errors.Wrap(err, "while waiting for orchestration")
+			}
+			w.log.Printf("while polling orchestration %s: %s", orchestrationID, err)
+		default:
+			failedPolls = 0
+			if progress != lastProgress {
+				fmt.Fprintf(w.out, "%s %s\n", time.Now().Format(time.RFC3339), progress)
+				lastProgress = progress
+			}
+			switch status.State {
+			case internal.Succeeded:
+				return nil
+			case internal.Failed, internal.Canceled:
+				return &orchestrationError{orchestrationID: orchestrationID, state: status.State, description: status.Description}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return errors.Errorf("orchestration %s not finished within %s", orchestrationID, timeout)
+			}
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *orchestrationWaiter) poll(orchestrationID string) (orchestration.StatusResponse, string, error) {
+	status, err := w.client.GetOrchestration(orchestrationID)
+	if err != nil {
+		return status, "", errors.Wrap(err, "while getting orchestration")
+	}
+	operations, err := w.client.ListOperations(orchestrationID)
+	if err != nil {
+		return status, "", errors.Wrap(err, "while listing operations")
+	}
+
+	return status, formatProgress(status, operations.Data), nil
+}
+
+// formatProgress describes the state of the orchestration with the number of its operations in each state,
+// e.g. "in progress, operations: 2 in progress, 5 succeeded"
+func formatProgress(status orchestration.StatusResponse, operations []orchestration.OperationResponse) string {
+	if len(operations) == 0 {
+		return status.State
+	}
+
+	counts := map[string]int{}
+	for _, op := range operations {
+		counts[op.State]++
+	}
+	states := make([]string, 0, len(counts))
+	for state := range counts {
+		states = append(states, state)
+	}
+	sort.Strings(states)
+
+	parts := make([]string, 0, len(states))
+	for _, state := range states {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[state], state))
+	}
+	return fmt.Sprintf("%s, operations: %s", status.State, strings.Join(parts, ", "))
+}
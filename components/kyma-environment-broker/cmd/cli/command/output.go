@@ -0,0 +1,259 @@
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// customColumnsOutput prints the fields of the objects selected by the spec as the table columns,
+	// the spec is a comma-separated list of the <header>:<field path> pairs, e.g. SHOOT:.shootName
+	customColumnsOutput string = "custom-columns"
+	// goTemplateOutput applies the Go template to the objects
+	goTemplateOutput string = "go-template"
+	// noneValue is printed in the custom column if the field does not exist
+	noneValue = "<none>"
+)
+
+// fieldPathSegment matches a single segment of the field path, e.g. operations or operations[0]
+var fieldPathSegment = regexp.MustCompile(`^([^.\[\]]+)((?:\[\d+\])*)$`)
+
+// customColumn is a single column of the custom-columns output
+type customColumn struct {
+	header string
+	path   []string
+}
+
+// isObjectOutput checks whether the given output type prints the objects as they are returned by the API
+func isObjectOutput(output string) bool {
+	return output != tableOutput
+}
+
+// isCustomColumnsOutput checks whether the given output type prints the objects as the custom columns
+func isCustomColumnsOutput(output string) bool {
+	return strings.HasPrefix(output, customColumnsOutput+"=")
+}
+
+// validateObjectOutput checks the spec of the custom-columns output and the template of the go-template output
+func validateObjectOutput(output string) error {
+	kind, arg, found := splitOutput(output)
+	if !found {
+		return fmt.Errorf("invalid value for output: %s", output)
+	}
+	switch kind {
+	case customColumnsOutput:
+		_, err := parseCustomColumns(arg)
+		return err
+	case goTemplateOutput:
+		_, err := parseGoTemplate(arg)
+		return err
+	}
+	return fmt.Errorf("invalid value for output: %s", output)
+}
+
+// printObject prints the object in the format selected by the output type
+func printObject(w io.Writer, output string, obj interface{}) error {
+	switch output {
+	case jsonOutput:
+		return printJSON(w, obj)
+	case yamlOutput:
+		return printYAML(w, obj)
+	}
+
+	kind, arg, _ := splitOutput(output)
+	switch kind {
+	case customColumnsOutput:
+		columns, err := parseCustomColumns(arg)
+		if err != nil {
+			return err
+		}
+		return printCustomColumns(w, columns, obj)
+	case goTemplateOutput:
+		tmpl, err := parseGoTemplate(arg)
+		if err != nil {
+			return err
+		}
+		return printGoTemplate(w, tmpl, obj)
+	}
+	return fmt.Errorf("invalid value for output: %s", output)
+}
+
+func printJSON(w io.Writer, obj interface{}) error {
+	data, err := json.MarshalIndent(obj, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "while marshalling output")
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// printYAML prints the object in the YAML format, the keys are the same as in the JSON format
+func printYAML(w io.Writer, obj interface{}) error {
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		return errors.Wrap(err, "while marshalling output")
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// printCustomColumns prints a row for every item of the list, or a single row for the single object
+func printCustomColumns(w io.Writer, columns []customColumn, obj interface{}) error {
+	value, err := toGeneric(obj)
+	if err != nil {
+		return err
+	}
+	items, isList := value.([]interface{})
+	if !isList {
+		items = []interface{}{value}
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	headers := make([]string, 0, len(columns))
+	for _, column := range columns {
+		headers = append(headers, column.header)
+	}
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	for _, item := range items {
+		cells := make([]string, 0, len(columns))
+		for _, column := range columns {
+			cells = append(cells, formatCell(lookupField(item, column.path)))
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+// printGoTemplate executes the template with the object, the fields are referenced by their JSON names
+func printGoTemplate(w io.Writer, tmpl *template.Template, obj interface{}) error {
+	value, err := toGeneric(obj)
+	if err != nil {
+		return err
+	}
+	if err := tmpl.Execute(w, value); err != nil {
+		return errors.Wrap(err, "while executing output template")
+	}
+	return nil
+}
+
+func splitOutput(output string) (string, string, bool) {
+	parts := strings.SplitN(output, "=", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+func parseCustomColumns(spec string) ([]customColumn, error) {
+	columns := make([]customColumn, 0)
+	for _, def := range strings.Split(spec, ",") {
+		parts := strings.SplitN(def, ":", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid custom column %q, the expected format is <header>:<field path>", def)
+		}
+		path, err := parseFieldPath(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, customColumn{header: parts[0], path: path})
+	}
+	return columns, nil
+}
+
+// parseFieldPath splits the field path, e.g. .status.provisioning.state or {.operations[0].id}, into the object keys
+// and the list indexes
+func parseFieldPath(path string) ([]string, error) {
+	trimmed := strings.TrimSuffix(strings.TrimPrefix(path, "{"), "}")
+	if !strings.HasPrefix(trimmed, ".") || trimmed == "." {
+		return nil, fmt.Errorf("invalid field path %q, the path must start with a dot, e.g. .shootName", path)
+	}
+
+	segments := make([]string, 0)
+	for _, segment := range strings.Split(trimmed[1:], ".") {
+		match := fieldPathSegment.FindStringSubmatch(segment)
+		if match == nil {
+			return nil, fmt.Errorf("invalid field path %q", path)
+		}
+		segments = append(segments, match[1])
+		for _, index := range strings.Split(match[2], "]") {
+			if index != "" {
+				segments = append(segments, index+"]")
+			}
+		}
+	}
+	return segments, nil
+}
+
+// lookupField returns the value of the field, the list indexes are the path segments in the [n] format
+func lookupField(value interface{}, path []string) interface{} {
+	for _, segment := range path {
+		if strings.HasPrefix(segment, "[") {
+			list, ok := value.([]interface{})
+			if !ok {
+				return nil
+			}
+			index, err := strconv.Atoi(strings.Trim(segment, "[]"))
+			if err != nil || index >= len(list) {
+				return nil
+			}
+			value = list[index]
+			continue
+		}
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[segment]
+	}
+	return value
+}
+
+func formatCell(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return noneValue
+	case string:
+		return v
+	case json.Number, bool:
+		return fmt.Sprint(v)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+func parseGoTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("output").Parse(text)
+	if err != nil {
+		return nil, errors.Wrap(err, "while parsing output template")
+	}
+	return tmpl, nil
+}
+
+// toGeneric converts the object to the maps and lists of its JSON representation, so the fields are referenced
+// by their JSON names, the numbers are kept as they are printed in the JSON output
+func toGeneric(obj interface{}) (interface{}, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, errors.Wrap(err, "while marshalling output")
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, errors.Wrap(err, "while unmarshalling output")
+	}
+	return value, nil
+}
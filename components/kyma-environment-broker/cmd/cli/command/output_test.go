@@ -0,0 +1,293 @@
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testOperation struct {
+	State string `json:"state"`
+}
+
+type testRuntime struct {
+	ID         string          `json:"id"`
+	ShootName  string          `json:"shootName"`
+	Count      int64           `json:"count"`
+	Operations []testOperation `json:"operations"`
+}
+
+func TestParseFieldPath(t *testing.T) {
+	for tN, tC := range map[string]struct {
+		path     string
+		expected []string
+		valid    bool
+	}{
+		"single field": {
+			path:     ".shootName",
+			expected: []string{"shootName"},
+			valid:    true,
+		},
+		"nested fields in braces": {
+			path:     "{.status.provisioning.state}",
+			expected: []string{"status", "provisioning", "state"},
+			valid:    true,
+		},
+		"list index": {
+			path:     ".operations[0].id",
+			expected: []string{"operations", "[0]", "id"},
+			valid:    true,
+		},
+		"nested list indexes": {
+			path:     ".matrix[1][2]",
+			expected: []string{"matrix", "[1]", "[2]"},
+			valid:    true,
+		},
+		"missing leading dot": {
+			path: "shootName",
+		},
+		"dot only": {
+			path: ".",
+		},
+		"dot only in braces": {
+			path: "{.}",
+		},
+		"empty segment": {
+			path: ".status..state",
+		},
+		"index which is not a number": {
+			path: ".operations[first].id",
+		},
+		"unclosed index": {
+			path: ".operations[0.id",
+		},
+	} {
+		t.Run(tN, func(t *testing.T) {
+			// when
+			path, err := parseFieldPath(tC.path)
+
+			// then
+			if !tC.valid {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tC.expected, path)
+		})
+	}
+}
+
+func TestParseCustomColumns(t *testing.T) {
+	for tN, tC := range map[string]struct {
+		spec     string
+		expected []customColumn
+		valid    bool
+	}{
+		"single column": {
+			spec:     "SHOOT:.shootName",
+			expected: []customColumn{{header: "SHOOT", path: []string{"shootName"}}},
+			valid:    true,
+		},
+		"several columns": {
+			spec: "ID:.id,STATE:{.operations[0].state}",
+			expected: []customColumn{
+				{header: "ID", path: []string{"id"}},
+				{header: "STATE", path: []string{"operations", "[0]", "state"}},
+			},
+			valid: true,
+		},
+		"column without path": {
+			spec: "SHOOT",
+		},
+		"column without header": {
+			spec: ":.shootName",
+		},
+		"invalid path": {
+			spec: "SHOOT:shootName",
+		},
+		"empty column": {
+			spec: "SHOOT:.shootName,",
+		},
+	} {
+		t.Run(tN, func(t *testing.T) {
+			// when
+			columns, err := parseCustomColumns(tC.spec)
+
+			// then
+			if !tC.valid {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tC.expected, columns)
+		})
+	}
+}
+
+func TestLookupField(t *testing.T) {
+	// given
+	value, err := toGeneric(testRuntime{
+		ID:         "runtime-id",
+		Operations: []testOperation{{State: "succeeded"}},
+	})
+	require.NoError(t, err)
+
+	for tN, tC := range map[string]struct {
+		path     []string
+		expected interface{}
+	}{
+		"field": {
+			path:     []string{"id"},
+			expected: "runtime-id",
+		},
+		"field of list item": {
+			path:     []string{"operations", "[0]", "state"},
+			expected: "succeeded",
+		},
+		"missing field": {
+			path:     []string{"unknown"},
+			expected: nil,
+		},
+		"index out of range": {
+			path:     []string{"operations", "[1]", "state"},
+			expected: nil,
+		},
+		"index of object": {
+			path:     []string{"[0]"},
+			expected: nil,
+		},
+		"field of string": {
+			path:     []string{"id", "length"},
+			expected: nil,
+		},
+		"number": {
+			path:     []string{"count"},
+			expected: json.Number("0"),
+		},
+	} {
+		t.Run(tN, func(t *testing.T) {
+			// when
+			field := lookupField(value, tC.path)
+
+			// then
+			assert.Equal(t, tC.expected, field)
+		})
+	}
+}
+
+func TestFormatCell(t *testing.T) {
+	for tN, tC := range map[string]struct {
+		value    interface{}
+		expected string
+	}{
+		"missing field":  {value: nil, expected: noneValue},
+		"string":         {value: "succeeded", expected: "succeeded"},
+		"empty string":   {value: "", expected: ""},
+		"number":         {value: json.Number("1234567890123"), expected: "1234567890123"},
+		"bool":           {value: true, expected: "true"},
+		"object":         {value: map[string]interface{}{"state": "failed"}, expected: `{"state":"failed"}`},
+		"list":           {value: []interface{}{"a", "b"}, expected: `["a","b"]`},
+		"list with null": {value: []interface{}{nil}, expected: `[null]`},
+	} {
+		t.Run(tN, func(t *testing.T) {
+			assert.Equal(t, tC.expected, formatCell(tC.value))
+		})
+	}
+}
+
+func TestValidateObjectOutput(t *testing.T) {
+	for tN, tC := range map[string]struct {
+		output string
+		valid  bool
+	}{
+		"custom columns":              {output: "custom-columns=ID:.id,SHOOT:.shootName", valid: true},
+		"custom columns without spec": {output: "custom-columns="},
+		"invalid custom columns":      {output: "custom-columns=ID"},
+		"go template":                 {output: "go-template={{.id}}", valid: true},
+		"invalid go template":         {output: "go-template={{.id"},
+		"unknown output":              {output: "jsonpath={.id}"},
+		"output without argument":     {output: "custom-columns"},
+	} {
+		t.Run(tN, func(t *testing.T) {
+			// when
+			err := validateObjectOutput(tC.output)
+
+			// then
+			if tC.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestPrintObject(t *testing.T) {
+	runtimes := []testRuntime{
+		{ID: "runtime-1", ShootName: "c-1", Operations: []testOperation{{State: "succeeded"}}},
+		{ID: "rt-2"},
+	}
+
+	for tN, tC := range map[string]struct {
+		output   string
+		obj      interface{}
+		expected string
+	}{
+		"custom columns of list": {
+			output: "custom-columns=ID:.id,STATE:.operations[0].state",
+			obj:    runtimes,
+			expected: "ID         STATE\n" +
+				"runtime-1  succeeded\n" +
+				"rt-2       <none>\n",
+		},
+		"custom columns of single object": {
+			output: "custom-columns=ID:.id,COUNT:.count",
+			obj:    testRuntime{ID: "x", Count: 1234567890123},
+			expected: "ID  COUNT\n" +
+				"x   1234567890123\n",
+		},
+		"go template of list": {
+			output:   "go-template={{range .}}{{.id}}={{.shootName}};{{end}}",
+			obj:      runtimes,
+			expected: "runtime-1=c-1;rt-2=;",
+		},
+		"go template with number": {
+			output:   "go-template={{.count}}",
+			obj:      testRuntime{Count: 1234567890123},
+			expected: "1234567890123",
+		},
+		"json": {
+			output:   jsonOutput,
+			obj:      testOperation{State: "succeeded"},
+			expected: "{\n  \"state\": \"succeeded\"\n}\n",
+		},
+		"yaml": {
+			output:   yamlOutput,
+			obj:      testOperation{State: "succeeded"},
+			expected: "state: succeeded\n",
+		},
+	} {
+		t.Run(tN, func(t *testing.T) {
+			// given
+			buf := &bytes.Buffer{}
+
+			// when
+			err := printObject(buf, tC.output, tC.obj)
+
+			// then
+			require.NoError(t, err)
+			assert.Equal(t, tC.expected, buf.String())
+		})
+	}
+
+	t.Run("should fail for invalid output", func(t *testing.T) {
+		// when
+		err := printObject(&bytes.Buffer{}, "custom-columns=ID", runtimes)
+
+		// then
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,184 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kyma-project/control-plane/components/kubeconfig-service/pkg/client"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/cmd/cli/logger"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// portForwardTarget is the well-known component of the Runtime which can be forwarded by its name
+type portForwardTarget struct {
+	namespace string
+	resource  string
+	ports     []string
+}
+
+var portForwardTargets = map[string]portForwardTarget{
+	"grafana":    {namespace: "kyma-system", resource: "svc/monitoring-grafana", ports: []string{"3000:80"}},
+	"prometheus": {namespace: "kyma-system", resource: "svc/monitoring-prometheus", ports: []string{"9090:9090"}},
+	"tracing":    {namespace: "kyma-system", resource: "svc/tracing-jaeger-query", ports: []string{"16686:16686"}},
+	"kiali":      {namespace: "kyma-system", resource: "svc/kiali-server", ports: []string{"20001:20001"}},
+}
+
+// PortForwardCommand represents an execution of the kcp port-forward command
+type PortForwardCommand struct {
+	log             logger.Logger
+	shoot           string
+	globalAccountID string
+	subAccountID    string
+	runtimeID       string
+	namespace       string
+	address         string
+	kubectl         string
+	target          portForwardTarget
+}
+
+// NewPortForwardCmd constructs a new instance of PortForwardCommand and configures it in terms of a cobra.Command
+func NewPortForwardCmd(log logger.Logger) *cobra.Command {
+	cmd := PortForwardCommand{log: log}
+	cobraCmd := &cobra.Command{
+		Use:     "port-forward {TARGET} [[LOCAL PORT:]REMOTE PORT ...]",
+		Aliases: []string{"pf"},
+		Short:   "Forwards local ports to a service or a Pod of a given Kyma Runtime.",
+		Long: fmt.Sprintf(`Downloads the kubeconfig file for a given Kyma Runtime and forwards one or more local ports to a service or a Pod of the Runtime using kubectl port-forward.
+The Runtime is specified in the same way as for the kcp kubeconfig command, by one of the following:
+  - Global account / subaccount pair with the --account and --subaccount options
+  - Global account / Runtime ID pair with the --account and --runtime-id options
+  - Runtime ID with the --runtime-id option
+  - Shoot cluster name with the --shoot option.
+
+The target is either a resource accepted by kubectl port-forward, such as svc/{NAME}, deployment/{NAME}, or a Pod name, in the --namespace namespace,
+or one of the following well-known components of Kyma, which are forwarded with their default ports unless the ports are specified: %s.
+
+The ports are forwarded until the command is interrupted. The downloaded kubeconfig file is kept in a temporary directory only while the ports are forwarded, and it is removed on exit.
+The kubectl binary must be installed. Use the --kubectl option if it is not in the PATH.`, strings.Join(portForwardTargetNames(), ", ")),
+		Example: `  kcp port-forward -c c-178e034 grafana                  Forwards the local port 3000 to Grafana of the Runtime.
+  kcp pf -r RUNTIMEID tracing                            Forwards the local port 16686 to Jaeger of the Runtime.
+  kcp pf -c c-178e034 grafana 8080:80                    Forwards the local port 8080 to Grafana of the Runtime.
+  kcp pf -c c-178e034 -n my-ns svc/my-service 8080:80    Forwards the local port 8080 to the port 80 of the my-service service in the my-ns namespace.`,
+		Args:    cobra.MinimumNArgs(1),
+		PreRunE: func(_ *cobra.Command, args []string) error { return cmd.Validate(args) },
+		RunE:    func(cobraCmd *cobra.Command, _ []string) error { return cmd.Run(cobraCmd) },
+	}
+
+	cobraCmd.Flags().StringVarP(&cmd.globalAccountID, "account", "g", "", "Global account ID of the specific Kyma Runtime.")
+	cobraCmd.Flags().StringVarP(&cmd.subAccountID, "subaccount", "s", "", "Subccount ID of the specific Kyma Runtime.")
+	cobraCmd.Flags().StringVarP(&cmd.runtimeID, "runtime-id", "r", "", "Runtime ID of the specific Kyma Runtime.")
+	cobraCmd.Flags().StringVarP(&cmd.shoot, "shoot", "c", "", "Shoot cluster name of the specific Kyma Runtime.")
+	cobraCmd.Flags().StringVarP(&cmd.namespace, "namespace", "n", "default", "Namespace of the target. The well-known components are always forwarded from their own namespace.")
+	cobraCmd.Flags().StringVar(&cmd.address, "address", "localhost", "Addresses to listen on, comma separated. Passed to kubectl port-forward.")
+	cobraCmd.Flags().StringVar(&cmd.kubectl, "kubectl", "kubectl", "Path to the kubectl binary.")
+
+	return cobraCmd
+}
+
+// Run executes the port-forward command
+func (cmd *PortForwardCommand) Run(cobraCmd *cobra.Command) error {
+	ctx := cobraCmd.Context()
+	cred := CLICredentialManager(cmd.log)
+
+	if cmd.globalAccountID == "" || cmd.runtimeID == "" {
+		rt, err := resolveRuntime(ctx, cred, cmd.globalAccountID, cmd.subAccountID, cmd.runtimeID, cmd.shoot)
+		if err != nil {
+			return errors.Wrap(err, "while resolving runtime")
+		}
+		cmd.runtimeID = rt.RuntimeID
+		cmd.globalAccountID = rt.GlobalAccountID
+	}
+	kc, err := client.NewClient(ctx, GlobalOpts.KubeconfigAPIURL(), cred).GetKubeConfig(cmd.globalAccountID, cmd.runtimeID)
+	if err != nil {
+		return errors.Wrap(err, "while getting kubeconfig")
+	}
+
+	dir, err := ioutil.TempDir("", "kcp-port-forward-")
+	if err != nil {
+		return errors.Wrap(err, "while creating temporary directory")
+	}
+	defer os.RemoveAll(dir)
+	kubeconfigPath := filepath.Join(dir, workspaceKubeconfigFile)
+	err = ioutil.WriteFile(kubeconfigPath, []byte(kc), 0600)
+	if err != nil {
+		return errors.Wrap(err, "while saving kubeconfig")
+	}
+
+	return cmd.forward(ctx, kubeconfigPath)
+}
+
+// Validate checks the input parameters of the port-forward command
+func (cmd *PortForwardCommand) Validate(args []string) error {
+	if GlobalOpts.KubeconfigAPIURL() == "" {
+		return fmt.Errorf("missing required %s option", GlobalOpts.kubeconfigAPIURL)
+	}
+	if !(cmd.globalAccountID != "" && (cmd.subAccountID != "" || cmd.runtimeID != "") || cmd.shoot != "" || cmd.runtimeID != "") {
+		return errors.New("at least one of the following options have to be specified: account/subaccount, account/runtime-id, runtime-id, shoot")
+	}
+
+	target, wellKnown := portForwardTargets[args[0]]
+	if !wellKnown {
+		target = portForwardTarget{namespace: cmd.namespace, resource: args[0]}
+	}
+	if len(args) > 1 {
+		target.ports = args[1:]
+	}
+	if len(target.ports) == 0 {
+		return fmt.Errorf("at least one port has to be specified for %s", args[0])
+	}
+	cmd.target = target
+	return nil
+}
+
+// forward runs kubectl port-forward until it exits or the context is done, in which case it is killed
+// together with its subprocesses
+func (cmd *PortForwardCommand) forward(ctx context.Context, kubeconfigPath string) error {
+	args := append([]string{
+		"port-forward",
+		"--kubeconfig", kubeconfigPath,
+		"--namespace", cmd.target.namespace,
+		"--address", cmd.address,
+		cmd.target.resource,
+	}, cmd.target.ports...)
+	execCmd := exec.Command(cmd.kubectl, args...)
+	execCmd.Stdout = os.Stdout
+	execCmd.Stderr = os.Stderr
+	setProcessGroup(execCmd)
+
+	fmt.Printf("Forwarding %s to %s/%s, press Ctrl+C to stop\n", strings.Join(cmd.target.ports, ", "), cmd.target.namespace, cmd.target.resource)
+	if err := execCmd.Start(); err != nil {
+		return errors.Wrap(err, "while starting kubectl port-forward")
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- execCmd.Wait() }()
+	select {
+	case err := <-done:
+		if err != nil {
+			return errors.Wrap(err, "while forwarding ports")
+		}
+		return nil
+	case <-ctx.Done():
+		if err := killProcessGroup(execCmd); err != nil {
+			cmd.log.Printf("while killing kubectl port-forward: %s", err)
+		}
+		<-done
+		return nil
+	}
+}
+
+func portForwardTargetNames() []string {
+	names := make([]string, 0, len(portForwardTargets))
+	for name := range portForwardTargets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
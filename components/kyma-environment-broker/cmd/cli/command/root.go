@@ -27,7 +27,27 @@ The CLI supports configuration file for common (global) options needed for all c
   - $HOME/.kcp/config.yaml (default path).
 
 The configuration file is in YAML format and supports the following global options: %s, %s, %s, %s, %s, %s.
-See the **Global Options** section of each command for the description of these options.`, GlobalOpts.oidcIssuerURL, GlobalOpts.oidcClientID, GlobalOpts.oidcClientSecret, GlobalOpts.kebAPIURL, GlobalOpts.kubeconfigAPIURL, GlobalOpts.gardenerKubeconfig)
+See the **Global Options** section of each command for the description of these options.
+
+The configuration file can also contain named profiles, for example for the dev, stage, and prod landscapes. Each profile under the %s key supports the same global options,
+which override the options specified at the top level of the file. The profile is selected using the --profile option or the %s environment variable,
+otherwise the profile named in the %s key is used, if any. For example:
+  %s: dev
+  %s:
+    dev:
+      %s: https://kyma-env-broker.dev.example.com
+    prod:
+      %s: https://kyma-env-broker.example.com
+
+The CLI exits with the following codes, so the scripts can react differently to different classes of failures:
+  - %d - general error
+  - %d - validation error, for example invalid arguments or options
+  - %d - authentication or authorization failure
+  - %d - resource not found
+  - %d - server error
+If the command is called with the --output json option, the error is printed to the standard output as a JSON object with the error, reason, exitCode, and statusCode fields.`, GlobalOpts.oidcIssuerURL, GlobalOpts.oidcClientID, GlobalOpts.oidcClientSecret, GlobalOpts.kebAPIURL, GlobalOpts.kubeconfigAPIURL, GlobalOpts.gardenerKubeconfig,
+		profilesKey, profileEnv, defaultProfileKey, defaultProfileKey, profilesKey, GlobalOpts.kebAPIURL, GlobalOpts.kebAPIURL,
+		ExitCodeGeneralError, ExitCodeValidationError, ExitCodeAuthError, ExitCodeNotFound, ExitCodeServerError)
 
 	cmd := &cobra.Command{
 		Use:     "kcp",
@@ -35,15 +55,21 @@ See the **Global Options** section of each command for the description of these
 		Long:    description,
 		Version: Version,
 		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
-			if cmd.CalledAs() != "help" {
-				return ValidateGlobalOpts()
+			if requiresGlobalOpts(cmd) {
+				return newValidationError(ValidateGlobalOpts())
 			}
 			return nil
 		},
 		SilenceUsage: true,
+		// the errors are printed by PrintError, either as text or as JSON
+		SilenceErrors: true,
 	}
+	cmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return newValidationError(err)
+	})
 
 	cmd.PersistentFlags().StringVar(&configPath, "config", os.Getenv(configEnv), "Path to the KCP CLI config file. Can also be set using the KCPCONFIG environment variable. Defaults to $HOME/.kcp/config.yaml .")
+	cmd.PersistentFlags().StringVar(&profile, "profile", os.Getenv(profileEnv), "Name of the profile in the KCP CLI config file to use. Can also be set using the KCP_PROFILE environment variable. Defaults to the profile named in the default-profile key of the config file.")
 	SetGlobalOpts(cmd)
 	log.AddFlags(cmd.PersistentFlags())
 	cmd.PersistentFlags().BoolP("help", "h", false, "Option that displays help for the CLI.")
@@ -53,12 +79,29 @@ See the **Global Options** section of each command for the description of these
 		NewRuntimeCmd(log),
 		NewOrchestrationCmd(log),
 		NewKubeconfigCmd(log),
+		NewPortForwardCmd(log),
 		NewUpgradeCmd(log),
 		NewTaskRunCmd(log),
+		NewCompletionCmd(log),
+		NewConfigCmd(log),
 	)
+	markValidationErrors(cmd)
 	return cmd
 }
 
+// requiresGlobalOpts tells if the command needs the global options, the help, the shell completion, and the config
+// commands work without them
+func requiresGlobalOpts(cmd *cobra.Command) bool {
+	switch cmd.CalledAs() {
+	case "help", "completion", configCmdName, cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+		return false
+	}
+	if cmd.HasParent() && cmd.Parent().Name() == configCmdName {
+		return false
+	}
+	return true
+}
+
 func initConfig() {
 	// If config file is set via flags or ENV, use that path,
 	// otherwise try to load the config from $HOME/{configDir}/config.yaml
@@ -86,6 +129,35 @@ func initConfig() {
 		fmt.Println("Error:", err)
 		os.Exit(1)
 	}
+
+	err = applyProfile()
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+}
+
+// applyProfile merges the options of the selected profile into the configuration, so they override the options
+// at the top level of the config file, while the flags and the environment variables still take precedence
+func applyProfile() error {
+	name := profile
+	if name == "" {
+		name = viper.GetString(defaultProfileKey)
+	}
+	if name == "" {
+		return nil
+	}
+
+	profiles := viper.GetStringMap(profilesKey)
+	if _, found := profiles[strings.ToLower(name)]; !found {
+		return fmt.Errorf("profile %s not found in the config file %s", name, viper.ConfigFileUsed())
+	}
+	settings := viper.Sub(fmt.Sprintf("%s.%s", profilesKey, name))
+	if settings == nil {
+		return fmt.Errorf("profile %s in the config file %s is empty", name, viper.ConfigFileUsed())
+	}
+	activeProfile = strings.ToLower(name)
+	return viper.MergeConfigMap(settings.AllSettings())
 }
 
 // CLICredentialManager returns a credential.Manager configured using the CLI global options
@@ -2,11 +2,40 @@ package command
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/cmd/cli/logger"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/httputil"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
+// runtimeState is the state of the Runtime derived from its operations
+type runtimeState string
+
+const (
+	stateProvisioning   runtimeState = "provisioning"
+	stateSucceeded      runtimeState = "succeeded"
+	stateFailed         runtimeState = "failed"
+	stateUpgrading      runtimeState = "upgrading"
+	stateDeprovisioning runtimeState = "deprovisioning"
+	stateDeprovisioned  runtimeState = "deprovisioned"
+)
+
+var allRuntimeStates = []runtimeState{stateProvisioning, stateSucceeded, stateFailed, stateUpgrading, stateDeprovisioning, stateDeprovisioned}
+
+// runtimeFields are the fields of the Runtime which can be selected with the fields option
+var runtimeFields = httputil.FieldNames(runtime.RuntimeDTO{})
+
+// timeFilterLayouts are the accepted formats of the time range options
+var timeFilterLayouts = []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02"}
+
 // RuntimeCommand represents an execution of the kcp runtimes command
 type RuntimeCommand struct {
 	log              logger.Logger
@@ -17,6 +46,15 @@ type RuntimeCommand struct {
 	runtimeIDs       []string
 	instanceIDs      []string
 	regions          []string
+	plans            []string
+	states           []string
+	createdAfter     string
+	createdBefore    string
+	fields           []string
+	search           string
+
+	createdAfterTime  time.Time
+	createdBeforeTime time.Time
 }
 
 // NewRuntimeCmd constructs a new instance of RuntimeCommand and configures it in terms of a cobra.Command
@@ -30,9 +68,18 @@ func NewRuntimeCmd(log logger.Logger) *cobra.Command {
 The command supports filtering Runtimes based on various attributes. See the list of options for more details.`,
 		Example: `  kcp runtimes                                           Display table overview about all Runtimes.
   kcp rt -c c-178e034 -o json                            Display all details about one Runtime identified by a Shoot name in the JSON format.
-  kcp runtimes --account CA4836781TID000000000123456789  Display all Runtimes of a given global account.`,
+  kcp runtimes --account CA4836781TID000000000123456789  Display all Runtimes of a given global account.
+  kcp runtimes --plan azure --state failed               Display all Runtimes of the azure plan whose last operation failed.
+  kcp rt --created-after 2020-11-20T10:00                Display all Runtimes created on 20 November 2020 at 10:00 UTC or later.
+  kcp rt -o json --fields runtimeID,shootName            Display only the Runtime IDs and Shoot names of all Runtimes in the JSON format.
+  kcp runtimes --plan trial -o yaml                      Display all details about the Runtimes of the trial plan in the YAML format.
+  kcp rt --search 4f2a9b1                                Display all Runtimes with an identifier or a Shoot name containing 4f2a9b1.
+  kcp rt -o custom-columns=SHOOT:.shootName,GA:.globalAccountID
+                                                         Display the Shoot names and global accounts of all Runtimes.
+  kcp rt -o go-template='{{range .}}{{.shootName}}{{"\n"}}{{end}}'
+                                                         Display the Shoot names of all Runtimes, one per line.`,
 		PreRunE: func(_ *cobra.Command, _ []string) error { return cmd.Validate() },
-		RunE:    func(_ *cobra.Command, _ []string) error { return cmd.Run() },
+		RunE:    func(cobraCmd *cobra.Command, _ []string) error { return cmd.Run(cobraCmd) },
 	}
 
 	SetOutputOpt(cobraCmd, &cmd.output)
@@ -40,15 +87,137 @@ The command supports filtering Runtimes based on various attributes. See the lis
 	cobraCmd.Flags().StringSliceVarP(&cmd.globalAccountIDs, "account", "g", nil, "Filter by global account ID. You can provide multiple values, either separated by a comma (e.g. GAID1,GAID2), or by specifying the option multiple times.")
 	cobraCmd.Flags().StringSliceVarP(&cmd.subAccountIDs, "subaccount", "s", nil, "Filter by subaccount ID. You can provide multiple values, either separated by a comma (e.g. SAID1,SAID2), or by specifying the option multiple times.")
 	cobraCmd.Flags().StringSliceVarP(&cmd.runtimeIDs, "runtime-id", "i", nil, "Filter by Runtime ID. You can provide multiple values, either separated by a comma (e.g. ID1,ID2), or by specifying the option multiple times.")
+	cobraCmd.Flags().StringSliceVar(&cmd.instanceIDs, "instance-id", nil, "Filter by service instance ID. You can provide multiple values, either separated by a comma (e.g. ID1,ID2), or by specifying the option multiple times.")
 	cobraCmd.Flags().StringSliceVarP(&cmd.regions, "region", "r", nil, "Filter by provider region. You can provide multiple values, either separated by a comma (e.g. westeurope,northeurope), or by specifying the option multiple times.")
+	cobraCmd.Flags().StringSliceVarP(&cmd.plans, "plan", "p", nil, fmt.Sprintf("Filter by service plan name. The possible values are: %s. You can provide multiple values, either separated by a comma (e.g. azure,gcp), or by specifying the option multiple times.", strings.Join(allPlanNames, ", ")))
+	cobraCmd.Flags().StringSliceVar(&cmd.states, "state", nil, fmt.Sprintf("Filter by Runtime state. The possible values are: %s. You can provide multiple values, either separated by a comma (e.g. failed,upgrading), or by specifying the option multiple times.", joinRuntimeStates()))
+	cobraCmd.Flags().StringVar(&cmd.createdAfter, "created-after", "", "Filter Runtimes created at or after the given time. The time is in the RFC3339 format (e.g. 2020-11-20T10:00:00Z), or as a UTC date with an optional time (e.g. 2020-11-20 or 2020-11-20T10:00).")
+	cobraCmd.Flags().StringVar(&cmd.createdBefore, "created-before", "", "Filter Runtimes created before the given time. The time is in the RFC3339 format (e.g. 2020-11-20T12:00:00Z), or as a UTC date with an optional time (e.g. 2020-11-21 or 2020-11-20T12:00).")
+	cobraCmd.Flags().StringVar(&cmd.search, "search", "", "Filter Runtimes whose instance ID, Runtime ID, global account ID, subaccount ID, or Shoot cluster name contains the given text. The case is ignored.")
+	cobraCmd.Flags().StringSliceVar(&cmd.fields, "fields", nil, fmt.Sprintf("Display only the given fields of the Runtimes. Cannot be used with the table output. The possible values are: %s. You can provide multiple values, either separated by a comma (e.g. runtimeID,shootName), or by specifying the option multiple times.", strings.Join(runtimeFields, ", ")))
+	cobraCmd.AddCommand(NewRuntimeCleanupOrphanCmd(log))
 
 	return cobraCmd
 }
 
 // Run executes the runtimes command
-func (cmd *RuntimeCommand) Run() error {
-	fmt.Println("Not implemented yet.")
-	return nil
+func (cmd *RuntimeCommand) Run(cobraCmd *cobra.Command) error {
+	cred := CLICredentialManager(cmd.log)
+	client := runtime.NewClient(cobraCmd.Context(), GlobalOpts.KEBAPIURL(), cred)
+
+	rp, err := client.ListRuntimes(runtime.ListParameters{
+		GlobalAccountIDs: cmd.globalAccountIDs,
+		SubAccountIDs:    cmd.subAccountIDs,
+		InstanceIDs:      cmd.instanceIDs,
+		RuntimeIDs:       cmd.runtimeIDs,
+		Regions:          cmd.regions,
+		Shoots:           cmd.shoots,
+		Plans:            cmd.plans,
+		CreatedAfter:     cmd.createdAfterTime,
+		CreatedBefore:    cmd.createdBeforeTime,
+		WithParameters:   containsString(cmd.fields, "parameters"),
+		Fields:           cmd.requestedFields(),
+		Search:           cmd.search,
+	})
+	if err != nil {
+		return errors.Wrap(err, "while listing runtimes")
+	}
+
+	// the state is derived from the operations of the runtime, hence it is filtered on the client side
+	runtimes := make([]runtime.RuntimeDTO, 0, len(rp.Data))
+	for _, rt := range rp.Data {
+		if cmd.matchState(rt) {
+			runtimes = append(runtimes, rt)
+		}
+	}
+
+	if isObjectOutput(cmd.output) {
+		projected, err := httputil.Project(runtimes, cmd.fields)
+		if err != nil {
+			return errors.Wrap(err, "while selecting runtime fields")
+		}
+		return printObject(os.Stdout, cmd.output, projected)
+	}
+	return printRuntimes(os.Stdout, runtimes)
+}
+
+// requestedFields returns the fields requested from the server, the status is always requested when filtering
+// by the state because the state is derived from the operations of the Runtime
+func (cmd *RuntimeCommand) requestedFields() []string {
+	if len(cmd.fields) == 0 || len(cmd.states) == 0 || containsString(cmd.fields, "status") {
+		return cmd.fields
+	}
+	return append(append([]string{}, cmd.fields...), "status")
+}
+
+func (cmd *RuntimeCommand) matchState(rt runtime.RuntimeDTO) bool {
+	if len(cmd.states) == 0 {
+		return true
+	}
+	state := string(runtimeStateOf(rt))
+	for _, s := range cmd.states {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
+// runtimeStateOf derives the state of the Runtime from its latest operation
+func runtimeStateOf(rt runtime.RuntimeDTO) runtimeState {
+	if op := rt.Status.Deprovisioning; op != nil {
+		switch domain.LastOperationState(op.State) {
+		case domain.Succeeded:
+			return stateDeprovisioned
+		case domain.Failed:
+			return stateFailed
+		default:
+			return stateDeprovisioning
+		}
+	}
+	// upgrade operations are ordered from the latest one
+	if len(rt.Status.UpgradingKyma.Data) > 0 {
+		switch domain.LastOperationState(rt.Status.UpgradingKyma.Data[0].State) {
+		case domain.InProgress:
+			return stateUpgrading
+		case domain.Failed:
+			return stateFailed
+		}
+	}
+	if op := rt.Status.Provisioning; op != nil {
+		switch domain.LastOperationState(op.State) {
+		case domain.InProgress:
+			return stateProvisioning
+		case domain.Failed:
+			return stateFailed
+		}
+	}
+	return stateSucceeded
+}
+
+func joinRuntimeStates() string {
+	states := make([]string, 0, len(allRuntimeStates))
+	for _, s := range allRuntimeStates {
+		states = append(states, string(s))
+	}
+	return strings.Join(states, ", ")
+}
+
+func printRuntimes(w io.Writer, runtimes []runtime.RuntimeDTO) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "GLOBAL ACCOUNT\tSUBACCOUNT\tSHOOT\tREGION\tPLAN\tRUNTIME ID\tSTATE\tCREATED AT")
+	for _, rt := range runtimes {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			rt.GlobalAccountID,
+			rt.SubAccountID,
+			rt.ShootName,
+			rt.ProviderRegion,
+			rt.ServicePlanName,
+			rt.RuntimeID,
+			runtimeStateOf(rt),
+			rt.Status.CreatedAt.Format(time.RFC3339))
+	}
+	return tw.Flush()
 }
 
 // Validate checks the input parameters of the runtimes command
@@ -57,5 +226,63 @@ func (cmd *RuntimeCommand) Validate() error {
 	if err != nil {
 		return err
 	}
+	for _, plan := range cmd.plans {
+		if !containsString(allPlanNames, plan) {
+			return fmt.Errorf("invalid value for plan: %s", plan)
+		}
+	}
+	for _, state := range cmd.states {
+		if !isRuntimeState(state) {
+			return fmt.Errorf("invalid value for state: %s", state)
+		}
+	}
+	for _, field := range cmd.fields {
+		if !containsString(runtimeFields, field) {
+			return fmt.Errorf("invalid value for fields: %s", field)
+		}
+	}
+	if len(cmd.fields) > 0 && !isObjectOutput(cmd.output) {
+		return fmt.Errorf("fields cannot be used with the %s output", tableOutput)
+	}
+	if cmd.createdAfterTime, err = parseTimeFilter(cmd.createdAfter); err != nil {
+		return fmt.Errorf("invalid value for created-after: %s", cmd.createdAfter)
+	}
+	if cmd.createdBeforeTime, err = parseTimeFilter(cmd.createdBefore); err != nil {
+		return fmt.Errorf("invalid value for created-before: %s", cmd.createdBefore)
+	}
+	if !cmd.createdAfterTime.IsZero() && !cmd.createdBeforeTime.IsZero() && !cmd.createdAfterTime.Before(cmd.createdBeforeTime) {
+		return fmt.Errorf("created-after must be earlier than created-before")
+	}
 	return nil
 }
+
+// parseTimeFilter parses the value of a time range option, the empty value results in the zero time
+func parseTimeFilter(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range timeFilterLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unsupported time format: %s", value)
+}
+
+func isRuntimeState(value string) bool {
+	for _, s := range allRuntimeStates {
+		if string(s) == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+package command
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/cmd/cli/logger"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/common/httperror"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/common/runtime"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// RuntimeCleanupOrphanCommand represents an execution of the kcp runtimes cleanup-orphan command
+type RuntimeCleanupOrphanCommand struct {
+	log logger.Logger
+	yes bool
+}
+
+// NewRuntimeCleanupOrphanCmd constructs a new instance of RuntimeCleanupOrphanCommand and configures it in terms of a cobra.Command
+func NewRuntimeCleanupOrphanCmd(log logger.Logger) *cobra.Command {
+	cmd := RuntimeCleanupOrphanCommand{log: log}
+	cobraCmd := &cobra.Command{
+		Use:   "cleanup-orphan {shoot}",
+		Short: "Deletes the orphaned Shoot cluster of a Runtime without the service instance.",
+		Long: `Deletes the Gardener Shoot cluster of a Runtime for which no service instance exists in Kyma Environment Broker.
+Kyma Environment Broker confirms that the Shoot is an orphan and issues a short-lived confirmation token. The command displays the details of the orphaned Shoot
+and asks you to type the Shoot name to confirm the deletion. After the confirmation, the Shoot is deleted by deprovisioning its Runtime in the Provisioner.
+The Shoot is not deleted if it does not exist, is already being deleted, or if the service instance of its Runtime exists.`,
+		Example: `  kcp runtimes cleanup-orphan c-178e034        Delete the orphaned Shoot after the interactive confirmation.
+  kcp runtimes cleanup-orphan c-178e034 --yes  Delete the orphaned Shoot without the interactive confirmation.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cobraCmd *cobra.Command, args []string) error { return cmd.Run(cobraCmd, args[0]) },
+	}
+
+	cobraCmd.Flags().BoolVarP(&cmd.yes, "yes", "y", false, "Option that skips the interactive confirmation of the deletion. The orphan status of the Shoot is still confirmed by Kyma Environment Broker.")
+	return cobraCmd
+}
+
+// Run executes the runtimes cleanup-orphan command
+func (cmd *RuntimeCleanupOrphanCommand) Run(cobraCmd *cobra.Command, shootName string) error {
+	cred := CLICredentialManager(cmd.log)
+	client := runtime.NewClient(cobraCmd.Context(), GlobalOpts.KEBAPIURL(), cred)
+
+	orphan, err := client.ConfirmOrphan(shootName)
+	if err != nil {
+		return errors.Wrapf(err, "while confirming orphaned shoot %s%s", shootName, orphanErrorHint(err))
+	}
+	err = printOrphan(os.Stdout, orphan)
+	if err != nil {
+		return err
+	}
+
+	if !cmd.yes {
+		err = confirmDeletion(os.Stdin, os.Stdout, shootName)
+		if err != nil {
+			return err
+		}
+	}
+
+	response, err := client.CleanupOrphan(shootName, orphan.ConfirmationToken)
+	if err != nil {
+		return errors.Wrapf(err, "while cleaning up orphaned shoot %s%s", shootName, orphanErrorHint(err))
+	}
+	fmt.Printf("Deletion of Shoot %s triggered, Provisioner operation ID: %s\n", response.ShootName, response.ProvisionerOperationID)
+	return nil
+}
+
+func printOrphan(w io.Writer, orphan runtime.OrphanDTO) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "SHOOT\tRUNTIME ID\tGLOBAL ACCOUNT\tSUBACCOUNT\tCREATED AT")
+	fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+		orphan.ShootName,
+		orphan.RuntimeID,
+		orphan.GlobalAccountID,
+		orphan.SubAccountID,
+		orphan.CreatedAt.Format("2006/01/02 15:04:05"))
+	return tw.Flush()
+}
+
+// confirmDeletion asks the user to type the shoot name, the deletion is aborted if the typed name does not match
+func confirmDeletion(r io.Reader, w io.Writer, shootName string) error {
+	fmt.Fprintf(w, "\nThe Shoot and all its data will be deleted. Type the Shoot name to confirm: ")
+	answer, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return errors.Wrap(err, "while reading confirmation")
+	}
+	if strings.TrimSpace(answer) != shootName {
+		return errors.New("deletion aborted, the typed name does not match the Shoot name")
+	}
+	return nil
+}
+
+// orphanErrorHint explains the status codes returned by the orphan endpoints
+func orphanErrorHint(err error) string {
+	code, found := httperror.StatusCode(err)
+	if !found {
+		return ""
+	}
+	switch code {
+	case http.StatusConflict:
+		return " (the Shoot is not an orphan or is already being deleted)"
+	case http.StatusPreconditionFailed:
+		return " (the confirmation token is invalid or expired, run the command again)"
+	}
+	return ""
+}
@@ -1,13 +1,48 @@
 package command
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"text/tabwriter"
+	"time"
 
+	"github.com/pkg/errors"
+
+	"github.com/kyma-project/control-plane/components/kubeconfig-service/pkg/client"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/cmd/cli/fileutil"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/cmd/cli/logger"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/common/runtime"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
 	"github.com/spf13/cobra"
 )
 
+const workspaceKubeconfigFile = "kubeconfig.yaml"
+
+const (
+	taskSucceeded = "succeeded"
+	taskFailed    = "failed"
+	taskTimedOut  = "timed out"
+	taskCanceled  = "canceled"
+)
+
+// taskResult is the result of the command executed for a single Runtime
+type taskResult struct {
+	runtime  runtime.RuntimeDTO
+	status   string
+	exitCode int
+	output   []byte
+	err      error
+}
+
 // TaskRunCommand represents an execution of the kcp taskrun command
 type TaskRunCommand struct {
 	log                 logger.Logger
@@ -17,13 +52,18 @@ type TaskRunCommand struct {
 	targets             internal.TargetSpec
 	kubeconfigDir       string
 	keepKubeconfigs     bool
+	script              string
+	files               []string
+	timeout             time.Duration
+	runtimeTimeout      time.Duration
+	args                []string
 }
 
 // NewTaskRunCmd constructs a new instance of TaskRunCommand and configures it in terms of a cobra.Command
 func NewTaskRunCmd(log logger.Logger) *cobra.Command {
 	cmd := TaskRunCommand{log: log}
 	cobraCmd := &cobra.Command{
-		Use:     "taskrun --target {TARGET SPEC} ... [--target-exclude {TARGET SPEC} ...] COMMAND [ARGS ...]",
+		Use:     "taskrun --target {TARGET SPEC} ... [--target-exclude {TARGET SPEC} ...] {COMMAND | --script FILE [--file FILE ...]} [ARGS ...]",
 		Aliases: []string{"task", "t"},
 		Short:   "Runs generic tasks on one or more Kyma Runtimes.",
 		Long: `Runs a command, which can be a script or a program with arbitrary arguments, on targets of Kyma Runtimes.
@@ -36,28 +76,88 @@ For each subprocess, the following Runtime-specific data are passed as environme
   - RUNTIME_NAME     : Shoot cluster name
   - RUNTIME_ID       : Runtime ID of the Runtime
 
+Each subprocess is executed in a separate temporary working directory, which contains the kubeconfig file of the Runtime.
+Instead of a command, you can provide a local script using the --script option. The script and any auxiliary files specified with the --file option are copied to the working directory of each Runtime, and the script is executed from there with the given arguments.
+
+The --runtime-timeout option limits the execution for a single Runtime, and the --timeout option limits the whole run. The commands which exceed the timeout are killed and the Runtimes which were not processed yet are skipped.
+The working directories are removed also when the run times out or is interrupted, unless the --keep option is set.
+
 	If all subprocesses finish successfully with the zero status code, the exit status is zero (0). If one or more subprocesses exit with a non-zero status, the command will also exit with a non-zero status.`,
 		Example: `  kcp taskrun --target all kubectl patch deployment valid-deployment -p '{"metadata":{"labels":{"my-label": "my-value"}}}'
     Execute a kubectl patch operation for all Runtimes.
   kcp taskrun --target account=CA4836781TID000000000123456789 /usr/local/bin/awesome-script.sh
     Run a maintenance script for all Runtimes of a given global account.
   kcp taskrun --target all helm upgrade -i -n kyma-system my-kyma-addon --values overrides.yaml
-    Deploy a Helm chart on all Runtimes.`,
-		Args:    cobra.MinimumNArgs(1),
-		PreRunE: func(_ *cobra.Command, _ []string) error { return cmd.Validate() },
-		RunE:    func(_ *cobra.Command, _ []string) error { return cmd.Run() },
+    Deploy a Helm chart on all Runtimes.
+  kcp taskrun --target all --script ./remediate.sh --file manifests.yaml --file values.yaml
+    Run a local remediation script with its helper files on all Runtimes.
+  kcp taskrun --target all --runtime-timeout 5m --timeout 1h kubectl rollout status deployment/my-deployment
+    Wait for a rollout on all Runtimes, at most 5 minutes for each Runtime and 1 hour in total.`,
+		PreRunE: func(_ *cobra.Command, args []string) error {
+			cmd.args = args
+			return cmd.Validate()
+		},
+		RunE: func(cobraCmd *cobra.Command, _ []string) error { return cmd.Run(cobraCmd) },
 	}
 
 	SetRuntimeTargetOpts(cobraCmd, &cmd.targetInputs, &cmd.targetExcludeInputs)
 	cobraCmd.Flags().IntVarP(&cmd.parallelism, "parallelism", "p", 8, "Number of parallel commands to execute.")
 	cobraCmd.Flags().StringVar(&cmd.kubeconfigDir, "kubeconfig-dir", "", "Directory to download Runtime kubeconfig files to. By default, it is a random-generated directory in the OS-specific default temporary directory (e.g. /tmp in Linux).")
 	cobraCmd.Flags().BoolVar(&cmd.keepKubeconfigs, "keep", false, "Option that allows you to keep downloaded kubeconfig files after execution for caching purposes.")
+	cobraCmd.Flags().StringVar(&cmd.script, "script", "", "Path to a local script to execute for each Runtime instead of a command. The script is copied to the working directory of each Runtime.")
+	cobraCmd.Flags().StringArrayVar(&cmd.files, "file", nil, "Path to an auxiliary file which is copied to the working directory of each Runtime. You can specify this option multiple times.")
+	cobraCmd.Flags().DurationVar(&cmd.timeout, "timeout", 0, "Maximum duration of the whole run, e.g. \"1h\". The running commands are killed and the remaining Runtimes are skipped when it is exceeded. By default, there is no timeout.")
+	cobraCmd.Flags().DurationVar(&cmd.runtimeTimeout, "runtime-timeout", 0, "Maximum duration of the command for a single Runtime, e.g. \"5m\". The command is killed when it is exceeded. By default, there is no timeout.")
 	return cobraCmd
 }
 
 // Run executes the taskrun command
-func (cmd *TaskRunCommand) Run() error {
-	fmt.Println("Not implemented yet.")
+func (cmd *TaskRunCommand) Run(cobraCmd *cobra.Command) error {
+	ctx := cobraCmd.Context()
+	if cmd.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cmd.timeout)
+		defer cancel()
+	}
+
+	cred := CLICredentialManager(cmd.log)
+	rtClient := runtime.NewClient(cobraCmd.Context(), GlobalOpts.KEBAPIURL(), cred)
+	kcClient := client.NewClient(cobraCmd.Context(), GlobalOpts.KubeconfigAPIURL(), cred)
+
+	runtimes, err := cmd.resolveRuntimes(rtClient)
+	if err != nil {
+		return errors.Wrap(err, "while resolving targets")
+	}
+	if len(runtimes) == 0 {
+		return errors.New("no runtimes matched the targets")
+	}
+
+	results := make([]taskResult, len(runtimes))
+	workers := make(chan struct{}, cmd.parallelism)
+	var wg sync.WaitGroup
+	for i, rt := range runtimes {
+		wg.Add(1)
+		go func(i int, rt runtime.RuntimeDTO) {
+			defer wg.Done()
+			select {
+			case workers <- struct{}{}:
+			case <-ctx.Done():
+				results[i] = taskResult{runtime: rt, status: contextStatus(ctx), exitCode: -1, err: errors.New("skipped")}
+				return
+			}
+			defer func() { <-workers }()
+			results[i] = cmd.runOnRuntime(ctx, kcClient, rt)
+		}(i, rt)
+	}
+	wg.Wait()
+
+	failed, err := printTaskResults(os.Stdout, results)
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("command failed on %d of %d runtimes", failed, len(results))
+	}
 	return nil
 }
 
@@ -67,6 +167,249 @@ func (cmd *TaskRunCommand) Validate() error {
 	if err != nil {
 		return err
 	}
-	// TODO: check if cmd.kubeconfigDir dir exists if input was given
+	if GlobalOpts.KubeconfigAPIURL() == "" {
+		return fmt.Errorf("missing required %s option", GlobalOpts.kubeconfigAPIURL)
+	}
+	if cmd.parallelism < 1 {
+		return errors.New("--parallelism must be at least 1")
+	}
+	if cmd.timeout < 0 || cmd.runtimeTimeout < 0 {
+		return errors.New("--timeout and --runtime-timeout must not be negative")
+	}
+	if cmd.kubeconfigDir != "" {
+		info, err := os.Stat(cmd.kubeconfigDir)
+		if err != nil {
+			return errors.Wrapf(err, "while checking %s", cmd.kubeconfigDir)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("%s is not a directory", cmd.kubeconfigDir)
+		}
+	}
+	if cmd.script == "" {
+		if len(cmd.args) == 0 {
+			return errors.New("either a command or the --script option has to be specified")
+		}
+		if len(cmd.files) > 0 {
+			return errors.New("the --file option can be used only together with the --script option")
+		}
+		return nil
+	}
+
+	names := map[string]string{workspaceKubeconfigFile: "Runtime kubeconfig"}
+	for _, path := range append([]string{cmd.script}, cmd.files...) {
+		info, err := os.Stat(path)
+		if err != nil {
+			return errors.Wrapf(err, "while checking %s", path)
+		}
+		if !info.Mode().IsRegular() {
+			return fmt.Errorf("%s is not a regular file", path)
+		}
+		name := filepath.Base(path)
+		if other, exists := names[name]; exists {
+			return fmt.Errorf("%s conflicts with %s in the working directory", path, other)
+		}
+		names[name] = path
+	}
 	return nil
 }
+
+// prepareWorkspace creates a temporary working directory for the given Runtime with the Runtime kubeconfig,
+// the script and the auxiliary files. It returns the path to the created directory.
+func (cmd *TaskRunCommand) prepareWorkspace(rt runtime.RuntimeDTO, kubeconfig string) (string, error) {
+	dir, err := ioutil.TempDir(cmd.kubeconfigDir, fmt.Sprintf("kcp-taskrun-%s-", rt.ShootName))
+	if err != nil {
+		return "", errors.Wrap(err, "while creating working directory")
+	}
+
+	err = ioutil.WriteFile(filepath.Join(dir, workspaceKubeconfigFile), []byte(kubeconfig), 0600)
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", errors.Wrap(err, "while saving kubeconfig")
+	}
+
+	if cmd.script != "" {
+		for _, path := range append([]string{cmd.script}, cmd.files...) {
+			err = fileutil.CopyFile(path, filepath.Join(dir, filepath.Base(path)))
+			if err != nil {
+				os.RemoveAll(dir)
+				return "", errors.Wrap(err, "while copying files to working directory")
+			}
+		}
+	}
+
+	return dir, nil
+}
+
+// runtimeCommand assembles the command to execute for the given Runtime in the given working directory
+func (cmd *TaskRunCommand) runtimeCommand(rt runtime.RuntimeDTO, workDir string) *exec.Cmd {
+	var execCmd *exec.Cmd
+	if cmd.script != "" {
+		execCmd = exec.Command(filepath.Join(workDir, filepath.Base(cmd.script)), cmd.args...)
+	} else {
+		execCmd = exec.Command(cmd.args[0], cmd.args[1:]...)
+	}
+
+	execCmd.Dir = workDir
+	setProcessGroup(execCmd)
+	execCmd.Env = append(os.Environ(),
+		fmt.Sprintf("KUBECONFIG=%s", filepath.Join(workDir, workspaceKubeconfigFile)),
+		fmt.Sprintf("GLOBALACCOUNT_ID=%s", rt.GlobalAccountID),
+		fmt.Sprintf("SUBACCOUNT_ID=%s", rt.SubAccountID),
+		fmt.Sprintf("RUNTIME_NAME=%s", rt.ShootName),
+		fmt.Sprintf("RUNTIME_ID=%s", rt.RuntimeID),
+	)
+	return execCmd
+}
+
+// executeOnRuntime runs the command for a single Runtime in its own working directory,
+// which is removed after the execution unless the --keep option is set.
+// The command is killed together with its subprocesses when the context is done.
+func (cmd *TaskRunCommand) executeOnRuntime(ctx context.Context, rt runtime.RuntimeDTO, kubeconfig string) ([]byte, error) {
+	workDir, err := cmd.prepareWorkspace(rt, kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	if !cmd.keepKubeconfigs {
+		defer os.RemoveAll(workDir)
+	}
+
+	var output bytes.Buffer
+	execCmd := cmd.runtimeCommand(rt, workDir)
+	execCmd.Stdout = &output
+	execCmd.Stderr = &output
+	if err := execCmd.Start(); err != nil {
+		return nil, err
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- execCmd.Wait() }()
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		if kerr := killProcessGroup(execCmd); kerr != nil {
+			cmd.log.Printf("while killing command for runtime %s: %s", rt.RuntimeID, kerr)
+		}
+		err = <-done
+	}
+	return output.Bytes(), err
+}
+
+// runOnRuntime downloads the kubeconfig of the Runtime and executes the command for it
+func (cmd *TaskRunCommand) runOnRuntime(ctx context.Context, kcClient client.Client, rt runtime.RuntimeDTO) taskResult {
+	result := taskResult{runtime: rt, status: taskFailed, exitCode: -1}
+	kubeconfig, err := kcClient.GetKubeConfig(rt.GlobalAccountID, rt.RuntimeID)
+	if err != nil {
+		result.err = errors.Wrap(err, "while getting kubeconfig")
+		return result
+	}
+
+	if cmd.runtimeTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cmd.runtimeTimeout)
+		defer cancel()
+	}
+	if ctx.Err() == nil {
+		result.output, err = cmd.executeOnRuntime(ctx, rt, kubeconfig)
+	}
+	if ctx.Err() != nil {
+		result.status = contextStatus(ctx)
+		return result
+	}
+	switch exitErr := err.(type) {
+	case nil:
+		result.status = taskSucceeded
+		result.exitCode = 0
+	case *exec.ExitError:
+		result.exitCode = exitErr.ExitCode()
+	default:
+		result.err = err
+	}
+	return result
+}
+
+// contextStatus returns the status of the Runtime task interrupted by the done context
+func contextStatus(ctx context.Context) string {
+	if ctx.Err() == context.DeadlineExceeded {
+		return taskTimedOut
+	}
+	return taskCanceled
+}
+
+// resolveRuntimes returns the Runtimes matching the targets in the same way as the orchestrations resolve them,
+// only the Runtimes which are provisioned and not deprovisioned are targeted
+func (cmd *TaskRunCommand) resolveRuntimes(rtClient runtime.Client) ([]runtime.RuntimeDTO, error) {
+	rp, err := rtClient.ListRuntimes(runtime.ListParameters{})
+	if err != nil {
+		return nil, errors.Wrap(err, "while listing runtimes")
+	}
+
+	runtimes := make([]runtime.RuntimeDTO, 0)
+	for _, rt := range rp.Data {
+		if rt.Status.Provisioning == nil || domain.LastOperationState(rt.Status.Provisioning.State) != domain.Succeeded || rt.Status.Deprovisioning != nil {
+			continue
+		}
+		if matchRuntimeTargets(rt, cmd.targets.Exclude) || !matchRuntimeTargets(rt, cmd.targets.Include) {
+			continue
+		}
+		runtimes = append(runtimes, rt)
+	}
+	return runtimes, nil
+}
+
+// matchRuntimeTargets returns true if the Runtime matches any of the targets
+func matchRuntimeTargets(rt runtime.RuntimeDTO, targets []internal.RuntimeTarget) bool {
+	for _, target := range targets {
+		if matchRuntimeTarget(rt, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchRuntimeTarget returns true if the Runtime matches all selectors of the target
+func matchRuntimeTarget(rt runtime.RuntimeDTO, target internal.RuntimeTarget) bool {
+	if target.RuntimeID != "" {
+		return target.RuntimeID == rt.RuntimeID
+	}
+	if target.PlanName != "" && target.PlanName != rt.ServicePlanName {
+		return false
+	}
+	for _, selector := range [][2]string{
+		{target.GlobalAccount, rt.GlobalAccountID},
+		{target.SubAccount, rt.SubAccountID},
+		{target.Region, rt.ProviderRegion},
+		{target.Shoot, rt.ShootName},
+	} {
+		pattern, value := selector[0], selector[1]
+		if pattern == "" {
+			continue
+		}
+		if matched, err := regexp.MatchString(pattern, value); err != nil || !matched {
+			return false
+		}
+	}
+	return target.Target == "" || target.Target == internal.TargetAll
+}
+
+// printTaskResults prints the output of every Runtime followed by the summary and returns the number of failed Runtimes
+func printTaskResults(w io.Writer, results []taskResult) (int, error) {
+	failed := 0
+	for _, r := range results {
+		fmt.Fprintf(w, "=== %s (runtime ID: %s, %s, exit code: %d) ===\n", r.runtime.ShootName, r.runtime.RuntimeID, r.status, r.exitCode)
+		if r.err != nil {
+			fmt.Fprintf(w, "Error: %s\n", r.err)
+		}
+		w.Write(r.output)
+		if r.status != taskSucceeded {
+			failed++
+		}
+	}
+
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "SHOOT\tRUNTIME ID\tGLOBAL ACCOUNT\tSTATUS\tEXIT CODE")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\n", r.runtime.ShootName, r.runtime.RuntimeID, r.runtime.GlobalAccountID, r.status, r.exitCode)
+	}
+	return failed, tw.Flush()
+}
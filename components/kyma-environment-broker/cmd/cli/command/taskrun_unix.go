@@ -0,0 +1,18 @@
+// +build !windows
+
+package command
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup starts the command in its own process group, so the processes started by the command are killed with it
+func setProcessGroup(execCmd *exec.Cmd) {
+	execCmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// killProcessGroup kills the command together with all processes in its process group
+func killProcessGroup(execCmd *exec.Cmd) error {
+	return syscall.Kill(-execCmd.Process.Pid, syscall.SIGKILL)
+}
@@ -0,0 +1,15 @@
+// +build windows
+
+package command
+
+import (
+	"os/exec"
+)
+
+// setProcessGroup is a no-op on Windows, where only the command itself is killed
+func setProcessGroup(_ *exec.Cmd) {}
+
+// killProcessGroup kills the command
+func killProcessGroup(execCmd *exec.Cmd) error {
+	return execCmd.Process.Kill()
+}
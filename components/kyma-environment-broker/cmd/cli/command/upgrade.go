@@ -2,6 +2,7 @@ package command
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -16,10 +17,21 @@ type UpgradeCommand struct {
 	targetExcludeInputs []string
 	strategy            string
 	parallelWorkers     int
+	canaryPercentage    int
+	canaryCount         int
+	canarySoakTime      time.Duration
+	canaryFailures      int
 	schedule            string
+	skipUpgradedWithin  time.Duration
+	installationTimeout time.Duration
 	orchestrationParams internal.OrchestrationParameters
 }
 
+var strategyInputToParam = map[string]internal.StrategyType{
+	"parallel": internal.ParallelStrategy,
+	"canary":   internal.CanaryStrategy,
+}
+
 var scheduleInputToParam = map[string]internal.ScheduleType{
 	"":                  "",
 	"immediate":         "immediate",
@@ -42,10 +54,17 @@ func NewUpgradeCmd(log logger.Logger) *cobra.Command {
 // SetUpgradeOpts configures the upgrade specific options on the given command
 func (cmd *UpgradeCommand) SetUpgradeOpts(cobraCmd *cobra.Command) {
 	SetRuntimeTargetOpts(cobraCmd, &cmd.targetInputs, &cmd.targetExcludeInputs)
-	cobraCmd.Flags().StringVar(&cmd.strategy, "strategy", "parallel", "Orchestration strategy to use. Currently the only supported strategy is parallel.")
-	cobraCmd.Flags().IntVar(&cmd.parallelWorkers, "parallel-workers", 0, "Number of parallel workers to use in parallel orchestration strategy. By default the amount of workers will be auto-selected on control plane server side.")
+	cobraCmd.Flags().StringVar(&cmd.strategy, "strategy", "parallel", "Orchestration strategy to use. Possible values: \"parallel\", \"canary\". The canary strategy upgrades a batch of the targeted Runtimes first, and continues with the rest once the batch succeeded.")
+	cobraCmd.Flags().IntVar(&cmd.parallelWorkers, "parallel-workers", 0, "Number of parallel workers to use in parallel orchestration strategy, and in both phases of the canary strategy. By default the amount of workers will be auto-selected on control plane server side.")
+	cobraCmd.Flags().IntVar(&cmd.canaryPercentage, "canary-percentage", 0, "Percentage of the targeted Runtimes to upgrade in the canary batch. By default the percentage will be auto-selected on control plane server side.")
+	cobraCmd.Flags().IntVar(&cmd.canaryCount, "canary-count", 0, "Fixed number of the targeted Runtimes to upgrade in the canary batch. Takes precedence over --canary-percentage.")
+	cobraCmd.Flags().DurationVar(&cmd.canarySoakTime, "canary-soak-time", 0, "Time to wait after the canary batch finished before upgrading the rest of the Runtimes, e.g. \"30m\".")
+	cobraCmd.Flags().IntVar(&cmd.canaryFailures, "canary-failure-threshold", 0, "Number of failed upgrades tolerated in the canary batch. If the threshold is exceeded, the orchestration fails without upgrading the rest of the Runtimes.")
 	cobraCmd.Flags().StringVar(&cmd.schedule, "schedule", "", "Orchestration schedule to use. Possible values: \"immediate\", \"maintenancewindow\". By default the schedule will be auto-selected on control plane server side.")
+	cobraCmd.Flags().DurationVar(&cmd.skipUpgradedWithin, "skip-upgraded-within", 0, "Skip the Runtimes successfully upgraded within the given period, e.g. \"72h\". Prevents back-to-back upgrades when orchestrations overlap.")
 	cobraCmd.Flags().BoolVar(&cmd.orchestrationParams.DryRun, "dry-run", false, "Perform the orchestration without executing the actual upgrage operations for the Runtimes. The details can be obtained using the \"kcp orchestrations\" command.")
+	cobraCmd.Flags().BoolVar(&cmd.orchestrationParams.AllowDowngrade, "allow-downgrade", false, "Allow to upgrade the Runtimes to a Kyma version lower than the installed one. By default, such Runtimes are skipped.")
+	cobraCmd.Flags().DurationVar(&cmd.installationTimeout, "installation-timeout", 0, "Time limit of the Kyma installation of every upgrade, e.g. \"3h\". The value must be within the limits configured in the control plane. By default the installation timeout of the Provisioner is used.")
 }
 
 // ValidateTransformUpgradeOpts checks in the input upgrade options, and transforms them for internal usage
@@ -54,10 +73,65 @@ func (cmd *UpgradeCommand) ValidateTransformUpgradeOpts() error {
 	if err != nil {
 		return err
 	}
+	if strategyParam, ok := strategyInputToParam[cmd.strategy]; ok {
+		cmd.orchestrationParams.Strategy.Type = strategyParam
+	} else {
+		return fmt.Errorf("invalid value for strategy: %s. Check kcp upgrade --help for more information", cmd.strategy)
+	}
+	if cmd.parallelWorkers < 0 {
+		return fmt.Errorf("invalid value for parallel-workers: %d. The value must not be negative", cmd.parallelWorkers)
+	}
+	cmd.orchestrationParams.Strategy.Parallel.Workers = cmd.parallelWorkers
+	err = cmd.validateTransformCanaryOpts()
+	if err != nil {
+		return err
+	}
 	if scheduleParam, ok := scheduleInputToParam[cmd.schedule]; ok {
 		cmd.orchestrationParams.Strategy.Schedule = scheduleParam
 	} else {
 		return fmt.Errorf("invalid value for schedule: %s. Check kcp upgrade --help for more information", cmd.schedule)
 	}
+	if cmd.skipUpgradedWithin < 0 {
+		return fmt.Errorf("invalid value for skip-upgraded-within: %s. The value must not be negative", cmd.skipUpgradedWithin)
+	}
+	if cmd.skipUpgradedWithin > 0 {
+		cmd.orchestrationParams.SkipUpgradedWithin = cmd.skipUpgradedWithin.String()
+	}
+	if cmd.installationTimeout < 0 {
+		return fmt.Errorf("invalid value for installation-timeout: %s. The value must not be negative", cmd.installationTimeout)
+	}
+	if cmd.installationTimeout > 0 {
+		cmd.orchestrationParams.InstallationTimeout = cmd.installationTimeout.String()
+	}
+	return nil
+}
+
+func (cmd *UpgradeCommand) validateTransformCanaryOpts() error {
+	if cmd.orchestrationParams.Strategy.Type != internal.CanaryStrategy {
+		if cmd.canaryPercentage != 0 || cmd.canaryCount != 0 || cmd.canarySoakTime != 0 || cmd.canaryFailures != 0 {
+			return fmt.Errorf("canary options can be used only with --strategy canary")
+		}
+		return nil
+	}
+	if cmd.canaryPercentage < 0 || cmd.canaryPercentage > 100 {
+		return fmt.Errorf("invalid value for canary-percentage: %d. The value must be between 0 and 100", cmd.canaryPercentage)
+	}
+	if cmd.canaryCount < 0 {
+		return fmt.Errorf("invalid value for canary-count: %d. The value must not be negative", cmd.canaryCount)
+	}
+	if cmd.canarySoakTime < 0 {
+		return fmt.Errorf("invalid value for canary-soak-time: %s. The value must not be negative", cmd.canarySoakTime)
+	}
+	if cmd.canaryFailures < 0 {
+		return fmt.Errorf("invalid value for canary-failure-threshold: %d. The value must not be negative", cmd.canaryFailures)
+	}
+
+	canary := &cmd.orchestrationParams.Strategy.Canary
+	canary.Percentage = cmd.canaryPercentage
+	canary.Count = cmd.canaryCount
+	canary.FailureThreshold = cmd.canaryFailures
+	if cmd.canarySoakTime > 0 {
+		canary.SoakTime = cmd.canarySoakTime.String()
+	}
 	return nil
 }
@@ -2,14 +2,22 @@ package command
 
 import (
 	"fmt"
+	"os"
+	"time"
 
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/cmd/cli/logger"
+	orchestrationClient "github.com/kyma-project/control-plane/components/kyma-environment-broker/common/orchestration"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
 // UpgradeKymaCommand represents an execution of the kcp upgrade kyma command. Inherits fields and methods of UpgradeCommand
 type UpgradeKymaCommand struct {
 	UpgradeCommand
+	simulate     bool
+	wait         bool
+	waitInterval time.Duration
+	waitTimeout  time.Duration
 }
 
 // NewUpgradeKymaCmd constructs a new instance of UpgradeKymaCommand and configures it in terms of a cobra.Command
@@ -25,22 +33,71 @@ func NewUpgradeKymaCmd(log logger.Logger) *cobra.Command {
 		Long: `Upgrades or reconfigures Kyma on targets of Runtimes.
 The upgrade is performed by Kyma Control Plane (KCP) within a new orchestration asynchronously. The ID of the orchestration is returned by the command upon success.
 The targets of Runtimes are specified via the --target and --target-exclude options. At least one --target must be specified.
-The Kyma version and configurations to use for the upgrade are taken from Kyma Control Plane during the processing of the orchestration.`,
+The Kyma version and configurations to use for the upgrade are taken from Kyma Control Plane during the processing of the orchestration.
+Use the --simulate flag to estimate the duration of the orchestration from the durations of the past upgrades of the targeted Runtimes without starting it.
+Use the --wait flag to block until the orchestration is finished. The progress of the orchestration is printed each time it changes, and the command exits with code 6 if the orchestration failed, or with code 7 if it was canceled.`,
 		PreRunE: func(_ *cobra.Command, _ []string) error { return cmd.Validate() },
 		Example: `  kcp upgrade kyma --target all --schedule maintenancewindow     Upgrade Kyma on all Runtimes in their next respective maintenance window hours.
   kcp upgrade kyma --target "account=CA.*"                       Upgrade Kyma on Runtimes of all global accounts starting with CA.
   kcp upgrade kyma --target all --target-exclude "account=CA.*"  Upgrade Kyma on Runtimes of all global accounts not starting with CA.
-  kcp upgrade kyma --target "region=europe|eu|uk"                Upgrade Kyma on Runtimes whose region belongs to Europe.`,
-		RunE: func(_ *cobra.Command, _ []string) error { return cmd.Run() },
+  kcp upgrade kyma --target "region=europe|eu|uk"                Upgrade Kyma on Runtimes whose region belongs to Europe.
+  kcp upgrade kyma --target "plan=azure_lite"                    Upgrade Kyma on Runtimes of the azure_lite service plan.
+  kcp upgrade kyma --target all --strategy canary --canary-percentage 10 --canary-soak-time 1h
+                                                                 Upgrade Kyma on 10% of all Runtimes first, and on the rest one hour after the canary batch succeeded.
+  kcp upgrade kyma --target all --skip-upgraded-within 72h       Upgrade Kyma on all Runtimes except the ones upgraded within the last 72 hours.
+  kcp upgrade kyma --target all --parallel-workers 10 --simulate Display the estimated duration of the upgrade of all Runtimes with 10 parallel workers.
+  kcp upgrade kyma --target "plan=azure" --wait --wait-timeout 6h
+                                                                 Upgrade Kyma on Runtimes of the azure service plan and wait up to 6 hours until the orchestration is finished.`,
+		RunE: func(cobraCmd *cobra.Command, _ []string) error { return cmd.Run(cobraCmd) },
 	}
 
 	cmd.SetUpgradeOpts(cobraCmd)
+	cobraCmd.Flags().BoolVar(&cmd.simulate, "simulate", false, "Option that estimates the duration of the orchestration from the durations of the past upgrades without starting the orchestration.")
+	cobraCmd.Flags().BoolVar(&cmd.wait, "wait", false, "Wait until the orchestration is finished, printing its progress. The exit code reflects the result of the orchestration.")
+	cobraCmd.Flags().DurationVar(&cmd.waitInterval, "wait-interval", 30*time.Second, "Interval of polling the orchestration status with the --wait option.")
+	cobraCmd.Flags().DurationVar(&cmd.waitTimeout, "wait-timeout", 0, "Maximum time to wait for the orchestration with the --wait option, e.g. \"6h\". By default, the command waits without a limit.")
 	return cobraCmd
 }
 
 // Run executes the upgrade kyma command
-func (cmd *UpgradeKymaCommand) Run() error {
-	fmt.Println("Not implemented yet.")
+func (cmd *UpgradeKymaCommand) Run(cobraCmd *cobra.Command) error {
+	cred := CLICredentialManager(cmd.log)
+	client := orchestrationClient.NewClient(cobraCmd.Context(), GlobalOpts.KEBAPIURL(), cred)
+
+	if cmd.simulate {
+		return cmd.runSimulation(client)
+	}
+
+	response, err := client.UpgradeKyma(cmd.orchestrationParams)
+	if err != nil {
+		return errors.Wrap(err, "while triggering kyma upgrade")
+	}
+
+	fmt.Println("OrchestrationID:", response.OrchestrationID)
+	if !cmd.wait {
+		return nil
+	}
+
+	waiter := orchestrationWaiter{client: client, log: cmd.log, out: os.Stdout, interval: cmd.waitInterval}
+	return waiter.Wait(cobraCmd.Context(), response.OrchestrationID, cmd.waitTimeout)
+}
+
+func (cmd *UpgradeKymaCommand) runSimulation(client orchestrationClient.Client) error {
+	response, err := client.SimulateUpgradeKyma(cmd.orchestrationParams)
+	if err != nil {
+		return errors.Wrap(err, "while simulating kyma upgrade")
+	}
+
+	fmt.Printf("Runtimes:                %d (%d with upgrade history)\n", response.Runtimes, response.RuntimesWithHistory)
+	fmt.Println("Median upgrade duration:", response.MedianUpgradeDuration)
+	fmt.Println("Parallel workers:       ", response.Workers)
+	fmt.Println("Estimated duration:     ", response.EstimatedDuration)
+	fmt.Println("Estimated end:          ", response.EstimatedEnd.Format(time.RFC3339))
+	fmt.Println()
+	fmt.Println("WORKERS  ESTIMATED DURATION")
+	for _, estimate := range response.WorkerEstimates {
+		fmt.Printf("%-7d  %s\n", estimate.Workers, estimate.EstimatedDuration)
+	}
 	return nil
 }
 
@@ -50,5 +107,14 @@ func (cmd *UpgradeKymaCommand) Validate() error {
 	if err != nil {
 		return err
 	}
+	if cmd.wait && cmd.simulate {
+		return errors.New("--wait cannot be used together with --simulate")
+	}
+	if cmd.waitInterval <= 0 {
+		return fmt.Errorf("invalid value for wait-interval: %s. The value must be positive", cmd.waitInterval)
+	}
+	if cmd.waitTimeout < 0 {
+		return fmt.Errorf("invalid value for wait-timeout: %s. The value must not be negative", cmd.waitTimeout)
+	}
 	return nil
 }
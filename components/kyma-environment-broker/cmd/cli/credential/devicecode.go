@@ -0,0 +1,196 @@
+package credential
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/int128/kubelogin/pkg/adaptors/tokencache"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/cmd/cli/logger"
+	"github.com/pkg/errors"
+)
+
+const (
+	deviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+	// defaultDeviceCodeInterval is the polling interval used if the authorization server does not return any
+	defaultDeviceCodeInterval = 5 * time.Second
+	// slowDownInterval is added to the polling interval every time the authorization server asks to slow down
+	slowDownInterval = 5 * time.Second
+	deviceCodeScopes = "openid offline_access"
+)
+
+// providerMetadata is the part of the OIDC discovery document used by the device code flow
+type providerMetadata struct {
+	TokenEndpoint               string `json:"token_endpoint"`
+	DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+}
+
+// deviceAuthorization is the response of the device authorization endpoint as defined in RFC 8628
+type deviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+type deviceTokenResponse struct {
+	IDToken      string `json:"id_token"`
+	RefreshToken string `json:"refresh_token"`
+	Error        string `json:"error"`
+	Description  string `json:"error_description"`
+}
+
+// deviceCodeFlow executes the OAuth 2.0 device authorization grant, so the user can log in with a browser
+// on any device, for example when the CLI runs on a remote machine without a browser
+type deviceCodeFlow struct {
+	httpClient *http.Client
+	logger     logger.Logger
+}
+
+// Do obtains the ID token and the refresh token using the device code flow
+func (f *deviceCodeFlow) Do(ctx context.Context, issuerURL, clientID, clientSecret string) (*tokencache.Value, error) {
+	metadata, err := f.discover(ctx, issuerURL)
+	if err != nil {
+		return nil, err
+	}
+	if metadata.DeviceAuthorizationEndpoint == "" {
+		return nil, errors.Errorf("OIDC provider %s does not support the device code flow", issuerURL)
+	}
+
+	form := url.Values{"client_id": {clientID}, "scope": {deviceCodeScopes}}
+	if clientSecret != "" {
+		form.Set("client_secret", clientSecret)
+	}
+	var authorization deviceAuthorization
+	status, err := f.postForm(ctx, metadata.DeviceAuthorizationEndpoint, form, &authorization)
+	if err != nil {
+		return nil, errors.Wrap(err, "while requesting device code")
+	}
+	if status != http.StatusOK {
+		return nil, errors.Errorf("while requesting device code: unexpected status code %d", status)
+	}
+
+	if authorization.VerificationURIComplete != "" {
+		f.logger.Printf("Open %s in the browser to log in", authorization.VerificationURIComplete)
+	} else {
+		f.logger.Printf("Open %s in the browser and enter the code %s to log in", authorization.VerificationURI, authorization.UserCode)
+	}
+
+	return f.poll(ctx, metadata.TokenEndpoint, clientID, clientSecret, authorization)
+}
+
+// poll requests the token until the user completes the login, the device code expires, or the context is done
+func (f *deviceCodeFlow) poll(ctx context.Context, tokenEndpoint, clientID, clientSecret string, authorization deviceAuthorization) (*tokencache.Value, error) {
+	interval := defaultDeviceCodeInterval
+	if authorization.Interval > 0 {
+		interval = time.Duration(authorization.Interval) * time.Second
+	}
+	expiresAt := time.Now().Add(time.Duration(authorization.ExpiresIn) * time.Second)
+
+	form := url.Values{
+		"grant_type":  {deviceCodeGrantType},
+		"device_code": {authorization.DeviceCode},
+		"client_id":   {clientID},
+	}
+	if clientSecret != "" {
+		form.Set("client_secret", clientSecret)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+		if authorization.ExpiresIn > 0 && time.Now().After(expiresAt) {
+			return nil, errors.New("device code expired before the login was completed")
+		}
+
+		var response deviceTokenResponse
+		if _, err := f.postForm(ctx, tokenEndpoint, form, &response); err != nil {
+			return nil, errors.Wrap(err, "while requesting token")
+		}
+		switch response.Error {
+		case "":
+			if response.IDToken == "" {
+				return nil, errors.New("token response does not contain the ID token")
+			}
+			return &tokencache.Value{IDToken: response.IDToken, RefreshToken: response.RefreshToken}, nil
+		case "authorization_pending":
+		case "slow_down":
+			interval += slowDownInterval
+		default:
+			return nil, errors.Errorf("while requesting token: %s %s", response.Error, response.Description)
+		}
+	}
+}
+
+func (f *deviceCodeFlow) discover(ctx context.Context, issuerURL string) (providerMetadata, error) {
+	wellKnown := strings.TrimSuffix(issuerURL, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequest(http.MethodGet, wellKnown, nil)
+	if err != nil {
+		return providerMetadata{}, errors.Wrap(err, "while creating discovery request")
+	}
+	resp, err := f.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return providerMetadata{}, errors.Wrapf(err, "while calling %s", wellKnown)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return providerMetadata{}, errors.Errorf("while calling %s: unexpected status code %d", wellKnown, resp.StatusCode)
+	}
+
+	var metadata providerMetadata
+	if err := json.NewDecoder(resp.Body).Decode(&metadata); err != nil {
+		return providerMetadata{}, errors.Wrap(err, "while decoding OIDC discovery document")
+	}
+	return metadata, nil
+}
+
+// postForm posts the form and decodes the JSON response, the OAuth 2.0 errors are returned in the body with the 400 status code
+func (f *deviceCodeFlow) postForm(ctx context.Context, endpoint string, form url.Values, out interface{}) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return 0, errors.Wrap(err, "while creating request")
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := f.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return 0, errors.Wrapf(err, "while calling %s", endpoint)
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return resp.StatusCode, errors.Wrapf(err, "while decoding response of %s with status code %d", endpoint, resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// idTokenExpiry returns the expiry of the ID token from its claims, the signature is verified by the OIDC provider
+// when the token is used
+func idTokenExpiry(idToken string) (time.Time, error) {
+	parts := strings.Split(idToken, ".")
+	if len(parts) != 3 {
+		return time.Time{}, errors.New("ID token is not a valid JWT")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "while decoding ID token payload")
+	}
+	var claims struct {
+		Expiry int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return time.Time{}, errors.Wrap(err, "while unmarshalling ID token claims")
+	}
+	if claims.Expiry == 0 {
+		return time.Time{}, errors.New("ID token does not contain the expiry")
+	}
+	return time.Unix(claims.Expiry, 0), nil
+}
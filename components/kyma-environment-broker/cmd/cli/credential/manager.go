@@ -2,6 +2,9 @@ package credential
 
 import (
 	"context"
+	"net/http"
+	"os"
+	"path/filepath"
 	"sync"
 	"time"
 
@@ -16,33 +19,60 @@ import (
 	"github.com/int128/kubelogin/pkg/usecases/authentication/authcode"
 	"github.com/int128/kubelogin/pkg/usecases/authentication/ropc"
 	"github.com/int128/kubelogin/pkg/usecases/credentialplugin"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/cmd/cli/fileutil"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/cmd/cli/logger"
+	"github.com/pkg/errors"
 	"golang.org/x/oauth2"
 	"k8s.io/client-go/util/homedir"
 )
 
-var defaultTokenCacheDir = homedir.HomeDir() + "/.kube/cache/oidc-login"
+// defaultTokenCacheDir keeps the ID and refresh tokens of all login flows, so the commands refresh the token
+// obtained by kcp login instead of asking the user to log in again
+var defaultTokenCacheDir = homedir.HomeDir() + "/.kcp/credentials"
 var defaultListenAddress = []string{"127.0.0.1:8000", "127.0.0.1:18000"}
 
-const defaultAuthenticationTimeout = 180 * time.Second
+const (
+	defaultAuthenticationTimeout = 180 * time.Second
+	// defaultLockTimeout allows to wait for another kcp process which authenticates the user interactively
+	defaultLockTimeout = defaultAuthenticationTimeout + 30*time.Second
+	tokenCacheLockFile = ".kcp.lock"
+)
 
 // Manager is a client for an OIDC provider capable of authenticating users and retrieving ID tokens through
 //   - Authorization code grant flow using browser for interactive use
+//   - Device code flow for interactive use on machines without a browser
 //   - Resource owner password credentials flow for non-interactive use
 // Manager implements the oauth2.TokenSource interface to interact with client libraries depending on the oauth2 package for obtaining auth token.
 type Manager interface {
 	GetTokenByAuthCode(ctx context.Context) (string, error)
 	GetTokenByROPC(ctx context.Context, username, password string) (string, error)
+	GetTokenByDeviceCode(ctx context.Context) (string, error)
 	TokenExpiry() time.Time
 	Token() (*oauth2.Token, error)
 }
 
+// AuthError is returned when the ID token cannot be obtained, for example the login failed or the refresh token expired
+type AuthError struct {
+	Err error
+}
+
+func (e *AuthError) Error() string {
+	return e.Err.Error()
+}
+
+// Cause returns the error which caused the authentication failure
+func (e *AuthError) Cause() error {
+	return e.Err
+}
+
 type manager struct {
-	getter *credentialplugin.GetToken
-	input  credentialplugin.Input
-	token  string
-	expiry time.Time
-	mux    sync.Mutex
+	getter     *credentialplugin.GetToken
+	deviceCode *deviceCodeFlow
+	tokenCache tokencache.Interface
+	input      credentialplugin.Input
+	token      string
+	expiry     time.Time
+	mux        sync.Mutex
 }
 
 type tokenWriter struct {
@@ -79,7 +109,13 @@ func NewManager(oidcIssuerURL, oidcClientID, oidcClientSecret string, logger log
 		},
 	}
 
+	tokenCache := &tokencache.Repository{}
 	mgr := &manager{
+		deviceCode: &deviceCodeFlow{
+			httpClient: &http.Client{Timeout: defaultAuthenticationTimeout},
+			logger:     logger,
+		},
+		tokenCache: tokenCache,
 		input: credentialplugin.Input{
 			IssuerURL:     oidcIssuerURL,
 			ClientID:      oidcClientID,
@@ -91,7 +127,7 @@ func NewManager(oidcIssuerURL, oidcClientID, oidcClientSecret string, logger log
 	getToken := &credentialplugin.GetToken{
 		Logger:               logger,
 		Authentication:       auth,
-		TokenCacheRepository: &tokencache.Repository{},
+		TokenCacheRepository: tokenCache,
 		NewCertPool:          certpool.New,
 		Writer:               writer,
 	}
@@ -111,9 +147,9 @@ func (mgr *manager) GetTokenByAuthCode(ctx context.Context) (string, error) {
 	}
 	mgr.mux.Lock()
 	defer mgr.mux.Unlock()
-	err := mgr.getter.Do(ctx, in)
+	err := mgr.getToken(ctx, in)
 	if err != nil {
-		return "", err
+		return "", &AuthError{Err: err}
 	}
 	return mgr.token, nil
 }
@@ -127,9 +163,48 @@ func (mgr *manager) GetTokenByROPC(ctx context.Context, username, password strin
 	}
 	mgr.mux.Lock()
 	defer mgr.mux.Unlock()
-	err := mgr.getter.Do(ctx, in)
+	err := mgr.getToken(ctx, in)
+	if err != nil {
+		return "", &AuthError{Err: err}
+	}
+	return mgr.token, nil
+}
+
+// GetTokenByDeviceCode initiates the device code flow to request a new ID token and stores it in the local cache
+// together with the refresh token, so the following commands do not need to log in again until the refresh token expires
+func (mgr *manager) GetTokenByDeviceCode(ctx context.Context) (string, error) {
+	in := mgr.input
+	mgr.mux.Lock()
+	defer mgr.mux.Unlock()
+	err := mgr.withTokenCacheLock(ctx, in.TokenCacheDir, func() error {
+		ctx, cancel := context.WithTimeout(ctx, defaultAuthenticationTimeout)
+		defer cancel()
+		value, err := mgr.deviceCode.Do(ctx, in.IssuerURL, in.ClientID, in.ClientSecret)
+		if err != nil {
+			return errors.Wrap(err, "while executing device code flow")
+		}
+		expiry, err := idTokenExpiry(value.IDToken)
+		if err != nil {
+			return err
+		}
+		// the key is the same as the one used by the other flows, so they find and refresh the stored token
+		key := tokencache.Key{
+			IssuerURL:      in.IssuerURL,
+			ClientID:       in.ClientID,
+			ClientSecret:   in.ClientSecret,
+			ExtraScopes:    in.ExtraScopes,
+			CACertFilename: in.CACertFilename,
+			CACertData:     in.CACertData,
+			SkipTLSVerify:  in.SkipTLSVerify,
+		}
+		if err := mgr.tokenCache.Save(in.TokenCacheDir, key, *value); err != nil {
+			return errors.Wrap(err, "while storing token in cache")
+		}
+		mgr.cacheToken(value.IDToken, expiry)
+		return nil
+	})
 	if err != nil {
-		return "", err
+		return "", &AuthError{Err: err}
 	}
 	return mgr.token, nil
 }
@@ -145,9 +220,9 @@ func (mgr *manager) Token() (*oauth2.Token, error) {
 	}
 	mgr.mux.Lock()
 	defer mgr.mux.Unlock()
-	err := mgr.getter.Do(context.TODO(), in)
+	err := mgr.getToken(context.TODO(), in)
 	if err != nil {
-		return nil, err
+		return nil, &AuthError{Err: err}
 	}
 	return &oauth2.Token{AccessToken: mgr.token, Expiry: mgr.expiry}, nil
 }
@@ -158,6 +233,32 @@ func (mgr *manager) TokenExpiry() time.Time {
 	return mgr.expiry
 }
 
+// getToken holds an exclusive lock on the token cache directory while the token is read, refreshed and stored,
+// so concurrent kcp processes sharing the cache neither corrupt the cache files nor refresh the same token twice
+func (mgr *manager) getToken(ctx context.Context, in credentialplugin.Input) error {
+	return mgr.withTokenCacheLock(ctx, in.TokenCacheDir, func() error {
+		return mgr.getter.Do(ctx, in)
+	})
+}
+
+// withTokenCacheLock runs the given function while holding an exclusive lock on the token cache directory
+func (mgr *manager) withTokenCacheLock(ctx context.Context, dir string, fn func() error) error {
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return errors.Wrapf(err, "while creating token cache directory %s", dir)
+	}
+
+	lockCtx, cancel := context.WithTimeout(ctx, defaultLockTimeout)
+	defer cancel()
+	lock, err := fileutil.AcquireLock(lockCtx, filepath.Join(dir, tokenCacheLockFile))
+	if err != nil {
+		return errors.Wrap(err, "while locking token cache")
+	}
+	defer lock.Release()
+
+	return fn()
+}
+
 func (mgr *manager) cacheToken(token string, expiry time.Time) {
 	mgr.token = token
 	mgr.expiry = expiry
@@ -0,0 +1,41 @@
+package fileutil
+
+import (
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// CopyFile copies the content of the src file to the dst file, keeping the permissions of the source file.
+// The dst file is truncated if it already exists.
+func CopyFile(src, dst string) (err error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return errors.Wrapf(err, "while opening %s", src)
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return errors.Wrapf(err, "while getting file info of %s", src)
+	}
+	if !info.Mode().IsRegular() {
+		return errors.Errorf("%s is not a regular file", src)
+	}
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return errors.Wrapf(err, "while creating %s", dst)
+	}
+	defer func() {
+		if cerr := out.Close(); cerr != nil && err == nil {
+			err = errors.Wrapf(cerr, "while closing %s", dst)
+		}
+	}()
+
+	if _, err = io.Copy(out, in); err != nil {
+		return errors.Wrapf(err, "while copying %s to %s", src, dst)
+	}
+	return nil
+}
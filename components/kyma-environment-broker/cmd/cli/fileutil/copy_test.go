@@ -0,0 +1,50 @@
+package fileutil
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopyFile(t *testing.T) {
+	t.Run("should copy content and permissions", func(t *testing.T) {
+		// given
+		dir, err := ioutil.TempDir("", "kcp-copy")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+		src := filepath.Join(dir, "script.sh")
+		dst := filepath.Join(dir, "copied.sh")
+		require.NoError(t, ioutil.WriteFile(src, []byte("#!/bin/sh\necho ok\n"), 0755))
+		require.NoError(t, os.Chmod(src, 0755))
+
+		// when
+		err = CopyFile(src, dst)
+
+		// then
+		require.NoError(t, err)
+		data, err := ioutil.ReadFile(dst)
+		require.NoError(t, err)
+		assert.Equal(t, "#!/bin/sh\necho ok\n", string(data))
+
+		info, err := os.Stat(dst)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+	})
+
+	t.Run("should fail for directory", func(t *testing.T) {
+		// given
+		dir, err := ioutil.TempDir("", "kcp-copy")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		// when
+		err = CopyFile(dir, filepath.Join(dir, "copied"))
+
+		// then
+		assert.Error(t, err)
+	})
+}
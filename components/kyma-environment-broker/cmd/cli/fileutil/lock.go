@@ -0,0 +1,56 @@
+package fileutil
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const lockPollInterval = 100 * time.Millisecond
+
+// Lock is an exclusive advisory lock on a file shared between processes.
+// It guards files which are read and written by several kcp processes at the same time,
+// for example when CI jobs share the same home directory.
+type Lock struct {
+	file *os.File
+}
+
+// AcquireLock blocks until the exclusive lock on the given file is acquired or the context is done.
+// The lock file is created if it does not exist.
+func AcquireLock(ctx context.Context, path string) (*Lock, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, errors.Wrapf(err, "while opening lock file %s", path)
+	}
+
+	ticker := time.NewTicker(lockPollInterval)
+	defer ticker.Stop()
+	for {
+		locked, err := tryLock(file)
+		if err != nil {
+			file.Close()
+			return nil, errors.Wrapf(err, "while locking file %s", path)
+		}
+		if locked {
+			return &Lock{file: file}, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			file.Close()
+			return nil, errors.Wrapf(ctx.Err(), "while waiting for lock on file %s", path)
+		case <-ticker.C:
+		}
+	}
+}
+
+// Release releases the lock so other processes can acquire it
+func (l *Lock) Release() error {
+	if err := unlock(l.file); err != nil {
+		l.file.Close()
+		return errors.Wrapf(err, "while unlocking file %s", l.file.Name())
+	}
+	return l.file.Close()
+}
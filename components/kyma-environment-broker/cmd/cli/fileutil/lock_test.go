@@ -0,0 +1,41 @@
+package fileutil
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcquireLock(t *testing.T) {
+	// given
+	dir, err := ioutil.TempDir("", "kcp-lock")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, ".lock")
+
+	lock, err := AcquireLock(context.Background(), path)
+	require.NoError(t, err)
+
+	// when
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	_, err = AcquireLock(ctx, path)
+
+	// then
+	assert.Error(t, err)
+
+	// when
+	err = lock.Release()
+	require.NoError(t, err)
+	lock, err = AcquireLock(context.Background(), path)
+
+	// then
+	require.NoError(t, err)
+	assert.NoError(t, lock.Release())
+}
@@ -0,0 +1,42 @@
+package fileutil
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// WriteFileAtomic writes data to the file in a way that readers never see a partially written file.
+// The data is written to a temporary file in the same directory, which is then renamed to the target file.
+func WriteFileAtomic(filename string, data []byte, perm os.FileMode) (err error) {
+	tmp, err := ioutil.TempFile(filepath.Dir(filename), "."+filepath.Base(filename)+".tmp")
+	if err != nil {
+		return errors.Wrap(err, "while creating temporary file")
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return errors.Wrap(err, "while writing temporary file")
+	}
+	if err = tmp.Sync(); err != nil {
+		tmp.Close()
+		return errors.Wrap(err, "while syncing temporary file")
+	}
+	if err = tmp.Close(); err != nil {
+		return errors.Wrap(err, "while closing temporary file")
+	}
+	if err = os.Chmod(tmp.Name(), perm); err != nil {
+		return errors.Wrap(err, "while setting file permissions")
+	}
+	if err = os.Rename(tmp.Name(), filename); err != nil {
+		return errors.Wrapf(err, "while renaming temporary file to %s", filename)
+	}
+	return nil
+}
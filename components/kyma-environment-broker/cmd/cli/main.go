@@ -1,33 +1,47 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/cmd/cli/command"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/cmd/cli/logger"
 )
 
+// shutdownGracePeriod is the time given to the command to clean up after the signal is received,
+// for example to kill the subprocesses and remove the temporary files of kcp taskrun
+const shutdownGracePeriod = 10 * time.Second
+
 func main() {
-	setupCloseHandler()
+	ctx, cancel := context.WithCancel(context.Background())
+	setupCloseHandler(cancel)
 	log := logger.New()
 	cmd := command.New(log)
 
-	err := cmd.Execute()
+	err := cmd.ExecuteContext(ctx)
 	if err != nil {
-		os.Exit(1)
+		command.PrintError(cmd, os.Args[1:], err)
+		os.Exit(command.ExitCode(err))
 	}
-
 }
 
-func setupCloseHandler() {
+// setupCloseHandler cancels the context of the command on the first signal and exits
+// once the grace period elapses or the second signal is received
+func setupCloseHandler(cancel context.CancelFunc) {
 	c := make(chan os.Signal, 2)
 	signal.Notify(c, syscall.SIGTERM, syscall.SIGINT)
 	go func() {
 		sig := <-c
 		fmt.Printf("\r- Signal '%v' received from Terminal. Exiting...\n ", sig)
+		cancel()
+		select {
+		case <-c:
+		case <-time.After(shutdownGracePeriod):
+		}
 		os.Exit(0)
 	}()
 }
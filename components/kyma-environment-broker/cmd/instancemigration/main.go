@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/instancemigration"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession/dbmodel"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/vrischmann/envconfig"
+)
+
+const (
+	exportMode = "export"
+	importMode = "import"
+)
+
+type config struct {
+	Database storage.Config
+	// File is the snapshot written by the export and read by the import
+	File string
+	// InstanceIDs, GlobalAccountIDs and SubAccountIDs select the exported instances, at least one of them must be set
+	InstanceIDs      []string `envconfig:"optional"`
+	GlobalAccountIDs []string `envconfig:"optional"`
+	SubAccountIDs    []string `envconfig:"optional"`
+	// SnapshotKey encrypts the credentials and the secret overrides in the snapshot, the import needs the same key
+	SnapshotKey string
+}
+
+func main() {
+	if len(os.Args) != 2 || (os.Args[1] != exportMode && os.Args[1] != importMode) {
+		log.Fatalf("Usage: %s %s|%s", os.Args[0], exportMode, importMode)
+	}
+	mode := os.Args[1]
+
+	cfg := config{}
+	err := envconfig.InitWithPrefix(&cfg, "APP")
+	fatalOnError(errors.Wrap(err, "while loading instance migration config"))
+	fatalOnError(instancemigration.ValidateKey(cfg.SnapshotKey))
+
+	logger := log.New()
+	logger.SetFormatter(&log.JSONFormatter{})
+
+	db, _, err := storage.NewFromConfig(cfg.Database, logger.WithField("service", "storage"))
+	fatalOnError(err)
+
+	switch mode {
+	case exportMode:
+		fatalOnError(export(cfg, db, logger))
+	case importMode:
+		fatalOnError(importSnapshot(cfg, db, logger))
+	}
+}
+
+func export(cfg config, db storage.BrokerStorage, logger *log.Logger) error {
+	if len(cfg.InstanceIDs) == 0 && len(cfg.GlobalAccountIDs) == 0 && len(cfg.SubAccountIDs) == 0 {
+		return errors.New("at least one of APP_INSTANCE_IDS, APP_GLOBAL_ACCOUNT_IDS and APP_SUB_ACCOUNT_IDS must be set")
+	}
+
+	exporter := instancemigration.NewExporter(db.Instances(), db.Operations(), db.RuntimeStates(), cfg.SnapshotKey, logger)
+	snapshot, err := exporter.Export(dbmodel.InstanceFilter{
+		InstanceIDs:      cfg.InstanceIDs,
+		GlobalAccountIDs: cfg.GlobalAccountIDs,
+		SubAccountIDs:    cfg.SubAccountIDs,
+	})
+	if err != nil {
+		return errors.Wrap(err, "while exporting instances")
+	}
+
+	raw, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "while marshalling snapshot")
+	}
+	if err := ioutil.WriteFile(cfg.File, raw, 0600); err != nil {
+		return errors.Wrapf(err, "while writing snapshot to %s", cfg.File)
+	}
+	logger.Infof("Exported %d instances to %s", len(snapshot.Instances), cfg.File)
+	return nil
+}
+
+func importSnapshot(cfg config, db storage.BrokerStorage, logger *log.Logger) error {
+	raw, err := ioutil.ReadFile(cfg.File)
+	if err != nil {
+		return errors.Wrapf(err, "while reading snapshot from %s", cfg.File)
+	}
+	var snapshot instancemigration.Snapshot
+	if err := json.Unmarshal(raw, &snapshot); err != nil {
+		return errors.Wrap(err, "while unmarshalling snapshot")
+	}
+
+	importer := instancemigration.NewImporter(db, cfg.SnapshotKey, logger)
+	if err := importer.Import(snapshot); err != nil {
+		return errors.Wrap(err, "while importing instances")
+	}
+	logger.Infof("Imported %d instances from %s", len(snapshot.Instances), cfg.File)
+	return nil
+}
+
+func fatalOnError(err error) {
+	if err != nil {
+		log.Fatal(err)
+	}
+}
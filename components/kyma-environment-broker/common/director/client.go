@@ -8,9 +8,12 @@ import (
 
 	"github.com/kyma-incubator/compass/components/director/pkg/graphql"
 	kebError "github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/error"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/tracing"
 	machineGraph "github.com/machinebox/graphql"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/label"
 	"golang.org/x/oauth2/clientcredentials"
 )
 
@@ -28,6 +31,7 @@ type Client struct {
 	graphQLClient GraphQLClient
 	queryProvider queryProvider
 	log           logrus.FieldLogger
+	ctx           context.Context
 }
 
 type (
@@ -54,6 +58,7 @@ func NewDirectorClient(ctx context.Context, config Config, log logrus.FieldLogge
 	}
 	httpClientOAuth := cfg.Client(ctx)
 	httpClientOAuth.Timeout = 30 * time.Second
+	httpClientOAuth.Transport = tracing.NewTransport(httpClientOAuth.Transport)
 
 	graphQLClient := machineGraph.NewClient(config.URL, machineGraph.WithHTTPClient(httpClientOAuth))
 
@@ -61,14 +66,25 @@ func NewDirectorClient(ctx context.Context, config Config, log logrus.FieldLogge
 		graphQLClient: graphQLClient,
 		queryProvider: queryProvider{},
 		log:           log,
+		ctx:           context.Background(),
 	}
 }
 
+// WithContext returns the copy of the client which sends the requests with the given context, so the requests
+// are cancelled when the context is done and are recorded in the trace of the context. The correlation ID
+// carried by the context is sent in the header of every request.
+func (dc *Client) WithContext(ctx context.Context) *Client {
+	withContext := *dc
+	withContext.ctx = ctx
+	return &withContext
+}
+
 // GetConsoleURL fetches, validates and returns console URL from director component based on runtime ID
 func (dc *Client) GetConsoleURL(accountID, runtimeID string) (string, error) {
 	query := dc.queryProvider.Runtime(runtimeID)
 	req := machineGraph.NewRequest(query)
 	req.Header.Add(accountIDKey, accountID)
+	dc.addCorrelationID(req)
 
 	dc.log.Info("Send request to director")
 	response, err := dc.fetchURLFromDirector(req)
@@ -85,6 +101,7 @@ func (dc *Client) SetLabel(accountID, runtimeID, key, value string) error {
 	query := dc.queryProvider.SetRuntimeLabel(runtimeID, key, value)
 	req := machineGraph.NewRequest(query)
 	req.Header.Add(accountIDKey, accountID)
+	dc.addCorrelationID(req)
 
 	dc.log.Info("Setup label in director")
 	response, err := dc.setLabelsInDirector(req)
@@ -100,11 +117,13 @@ func (dc *Client) SetLabel(accountID, runtimeID, key, value string) error {
 	return nil
 }
 
-// GetRuntimeID fetches runtime ID with given label name from director component
+// GetRuntimeID fetches runtime ID with given label name from director component,
+// returns NotFoundError when no runtime is labeled with the given instance ID
 func (dc *Client) GetRuntimeID(accountID, instanceID string) (string, error) {
 	query := dc.queryProvider.RuntimeForInstanceId(instanceID)
 	req := machineGraph.NewRequest(query)
 	req.Header.Add(accountIDKey, accountID)
+	dc.addCorrelationID(req)
 
 	dc.log.Info("Send request to director")
 	response, err := dc.getRuntimeIdFromDirector(req)
@@ -119,7 +138,7 @@ func (dc *Client) GetRuntimeID(accountID, instanceID string) (string, error) {
 func (dc *Client) fetchURLFromDirector(req *machineGraph.Request) (*getURLResponse, error) {
 	var response getURLResponse
 
-	err := dc.graphQLClient.Run(context.Background(), req, &response)
+	err := dc.run("GetConsoleURL", req, &response)
 	if err != nil {
 		dc.log.Errorf("call to director failed: %s", err)
 		return &getURLResponse{}, kebError.AsTemporaryError(err, "while requesting to director client")
@@ -131,7 +150,7 @@ func (dc *Client) fetchURLFromDirector(req *machineGraph.Request) (*getURLRespon
 func (dc *Client) setLabelsInDirector(req *machineGraph.Request) (*runtimeLabelResponse, error) {
 	var response runtimeLabelResponse
 
-	err := dc.graphQLClient.Run(context.Background(), req, &response)
+	err := dc.run("SetLabel", req, &response)
 	if err != nil {
 		dc.log.Errorf("call to director failed: %s", err)
 		return &runtimeLabelResponse{}, kebError.AsTemporaryError(err, "while requesting to director client")
@@ -143,7 +162,7 @@ func (dc *Client) setLabelsInDirector(req *machineGraph.Request) (*runtimeLabelR
 func (dc *Client) getRuntimeIdFromDirector(req *machineGraph.Request) (*getRuntimeIdResponse, error) {
 	var response getRuntimeIdResponse
 
-	err := dc.graphQLClient.Run(context.Background(), req, &response)
+	err := dc.run("GetRuntimeID", req, &response)
 	if err != nil {
 		dc.log.Errorf("call to director failed: %s", err)
 		return &getRuntimeIdResponse{}, kebError.AsTemporaryError(err, "while requesting to director client")
@@ -183,10 +202,24 @@ func (dc *Client) getURLFromRuntime(response *graphql.RuntimeExt) (string, error
 
 func (dc *Client) getIDFromRuntime(response *graphql.RuntimePageExt) (string, error) {
 	if response.Data == nil || len(response.Data) == 0 || response.Data[0] == nil {
-		return "", errors.New("got empty data from director response")
+		return "", kebError.NewNotFoundError("got empty data from director response")
 	}
 	if len(response.Data) > 1 {
 		return "", errors.Errorf("expected single runtime, got: %v", response.Data)
 	}
 	return response.Data[0].ID, nil
 }
+
+// run sends the request within the span of the Director call
+func (dc *Client) run(name string, req *machineGraph.Request, response interface{}) error {
+	ctx, span := tracing.StartSpan(dc.ctx, fmt.Sprintf("director/%s", name), label.String("keb.director.tenant", req.Header.Get(accountIDKey)))
+	err := dc.graphQLClient.Run(ctx, req, response)
+	tracing.End(ctx, span, err)
+	return err
+}
+
+func (dc *Client) addCorrelationID(req *machineGraph.Request) {
+	if correlationID, ok := httputil.CorrelationIDFromContext(dc.ctx); ok {
+		req.Header.Set(httputil.CorrelationIDHeader, correlationID)
+	}
+}
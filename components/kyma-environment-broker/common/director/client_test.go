@@ -8,6 +8,7 @@ import (
 	"github.com/kyma-incubator/compass/components/director/pkg/graphql"
 	mocks "github.com/kyma-project/control-plane/components/kyma-environment-broker/common/director/automock"
 	kebError "github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/error"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/httputil"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/logger"
 	machineGraphql "github.com/machinebox/graphql"
 	"github.com/stretchr/testify/assert"
@@ -296,6 +297,99 @@ func TestClient_SetLabel(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestClient_WithCorrelationID(t *testing.T) {
+	// given
+	var (
+		accountID  = "ad568853-ecf3-433a-8638-e53aa6bead5d"
+		runtimeID  = "775dc85e-825b-4ddf-abf6-da0dd002b66e"
+		labelKey   = "testKey"
+		labelValue = "testValue"
+	)
+
+	qc := &mocks.GraphQLClient{}
+	cfg := Config{}
+
+	client := NewDirectorClient(context.Background(), cfg, logger.NewLogDummy())
+	client.graphQLClient = qc
+
+	ctx := httputil.ContextWithCorrelationID(context.Background(), "correlation-id")
+	request := createGraphQLLabelRequest(client, accountID, runtimeID, labelKey, labelValue)
+	request.Header.Set(httputil.CorrelationIDHeader, "correlation-id")
+
+	qc.On("Run", ctx, request, mock.AnythingOfType("*director.runtimeLabelResponse")).Run(func(args mock.Arguments) {
+		arg, ok := args.Get(2).(*runtimeLabelResponse)
+		if !ok {
+			return
+		}
+		arg.Result = &graphql.Label{
+			Key:   labelKey,
+			Value: labelValue,
+		}
+	}).Return(nil)
+	defer qc.AssertExpectations(t)
+
+	// when
+	err := client.WithContext(ctx).SetLabel(accountID, runtimeID, labelKey, labelValue)
+
+	// then
+	assert.NoError(t, err)
+}
+
+func TestClient_GetRuntimeID(t *testing.T) {
+	// given
+	var (
+		accountID  = "ad568853-ecf3-433a-8638-e53aa6bead5d"
+		instanceID = "0d5d2b38-5d36-4a4a-8d2c-9a1d7a0c8b4e"
+		runtimeID  = "775dc85e-825b-4ddf-abf6-da0dd002b66e"
+	)
+
+	t.Run("runtime ID returned successfully", func(t *testing.T) {
+		// given
+		qc := &mocks.GraphQLClient{}
+		client := NewDirectorClient(context.Background(), Config{}, logger.NewLogDummy())
+		client.graphQLClient = qc
+
+		request := createGraphQLRuntimeIDRequest(client, accountID, instanceID)
+
+		qc.On("Run", context.Background(), request, mock.AnythingOfType("*director.getRuntimeIdResponse")).Run(func(args mock.Arguments) {
+			arg, ok := args.Get(2).(*getRuntimeIdResponse)
+			if !ok {
+				return
+			}
+			arg.Result = graphql.RuntimePageExt{
+				Data: []*graphql.RuntimeExt{{Runtime: graphql.Runtime{ID: runtimeID}}},
+			}
+		}).Return(nil)
+		defer qc.AssertExpectations(t)
+
+		// when
+		id, err := client.GetRuntimeID(accountID, instanceID)
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, runtimeID, id)
+	})
+
+	t.Run("runtime not found", func(t *testing.T) {
+		// given
+		qc := &mocks.GraphQLClient{}
+		client := NewDirectorClient(context.Background(), Config{}, logger.NewLogDummy())
+		client.graphQLClient = qc
+
+		request := createGraphQLRuntimeIDRequest(client, accountID, instanceID)
+
+		qc.On("Run", context.Background(), request, mock.AnythingOfType("*director.getRuntimeIdResponse")).Return(nil)
+		defer qc.AssertExpectations(t)
+
+		// when
+		_, err := client.GetRuntimeID(accountID, instanceID)
+
+		// then
+		assert.Error(t, err)
+		assert.True(t, kebError.IsNotFoundError(err))
+	})
+}
+
 func createGraphQLRequest(client *Client, accountID, runtimeID string) *machineGraphql.Request {
 	query := client.queryProvider.Runtime(runtimeID)
 	request := machineGraphql.NewRequest(query)
@@ -311,3 +405,11 @@ func createGraphQLLabelRequest(client *Client, accountID, runtimeID, key, label
 
 	return request
 }
+
+func createGraphQLRuntimeIDRequest(client *Client, accountID, instanceID string) *machineGraphql.Request {
+	query := client.queryProvider.RuntimeForInstanceId(instanceID)
+	request := machineGraphql.NewRequest(query)
+	request.Header.Add(accountIDKey, accountID)
+
+	return request
+}
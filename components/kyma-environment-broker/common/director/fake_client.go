@@ -0,0 +1,52 @@
+package director
+
+import (
+	"fmt"
+	"sync"
+
+	kebError "github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/error"
+)
+
+// FakeClient implements the director client methods used by the broker without calling the Director,
+// the console URL of every runtime is built using the given domain
+type FakeClient struct {
+	mu     sync.Mutex
+	domain string
+	labels map[string]map[string]string
+}
+
+func NewFakeClient(domain string) *FakeClient {
+	return &FakeClient{
+		domain: domain,
+		labels: make(map[string]map[string]string),
+	}
+}
+
+func (c *FakeClient) GetConsoleURL(accountID, runtimeID string) (string, error) {
+	return fmt.Sprintf("https://console.%s.%s", runtimeID, c.domain), nil
+}
+
+func (c *FakeClient) SetLabel(accountID, runtimeID, key, value string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, found := c.labels[runtimeID]; !found {
+		c.labels[runtimeID] = make(map[string]string)
+	}
+	c.labels[runtimeID][key] = value
+	return nil
+}
+
+// GetRuntimeID always returns NotFoundError, runtimes are not registered in the fake Director
+func (c *FakeClient) GetRuntimeID(accountID, instanceID string) (string, error) {
+	return "", kebError.NewNotFoundError("runtime for instance %s not found", instanceID)
+}
+
+// GetLabel returns the value of the runtime label and true if the label was set
+func (c *FakeClient) GetLabel(runtimeID, key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	value, found := c.labels[runtimeID][key]
+	return value, found
+}
@@ -50,6 +50,18 @@ func NewGardenerShootInterface(gardenerClusterCfg *restclient.Config, gardenerPr
 	return gardenerClusterClient.Shoots(gardenerNamespace), nil
 }
 
+func NewGardenerSecretBindingsInterface(gardenerClusterCfg *restclient.Config, gardenerProjectName string) (gardener_apis.SecretBindingInterface, error) {
+
+	gardenerNamespace := gardenerNamespace(gardenerProjectName)
+
+	gardenerClusterClient, err := gardener_apis.NewForConfig(gardenerClusterCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return gardenerClusterClient.SecretBindings(gardenerNamespace), nil
+}
+
 func RESTConfig(kubeconfig []byte) (*restclient.Config, error) {
 	return clientcmd.RESTConfigFromKubeConfig(kubeconfig)
 }
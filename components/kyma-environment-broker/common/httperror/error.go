@@ -0,0 +1,44 @@
+package httperror
+
+import (
+	"fmt"
+)
+
+// ResponseError is returned by the KEB API clients when the API responds with an unexpected status code
+type ResponseError struct {
+	URL        string
+	StatusCode int
+	Status     string
+}
+
+// NewResponseError constructs the ResponseError for the given URL and response status
+func NewResponseError(url string, statusCode int, status string) *ResponseError {
+	return &ResponseError{URL: url, StatusCode: statusCode, Status: status}
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("calling %s returned %d (%s) status", e.URL, e.StatusCode, e.Status)
+}
+
+// StatusCode returns the status code of the ResponseError wrapped in the given error, false if there is none
+func StatusCode(err error) (int, bool) {
+	for err != nil {
+		if respErr, ok := err.(*ResponseError); ok {
+			return respErr.StatusCode, true
+		}
+		err = Unwrap(err)
+	}
+	return 0, false
+}
+
+// Unwrap returns the error wrapped in the given error, it supports both the errors wrapped with
+// the github.com/pkg/errors package and the standard library, nil is returned if no error is wrapped
+func Unwrap(err error) error {
+	switch e := err.(type) {
+	case interface{ Cause() error }:
+		return e.Cause()
+	case interface{ Unwrap() error }:
+		return e.Unwrap()
+	}
+	return nil
+}
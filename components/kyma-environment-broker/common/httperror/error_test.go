@@ -0,0 +1,29 @@
+package httperror
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusCode(t *testing.T) {
+	// given
+	respErr := NewResponseError("http://keb/runtimes", http.StatusNotFound, "404 Not Found")
+	wrapped := errors.Wrap(&url.Error{Op: "Get", URL: "http://keb/runtimes", Err: errors.Wrap(respErr, "while calling")}, "while listing runtimes")
+
+	// when
+	code, found := StatusCode(wrapped)
+
+	// then
+	assert.True(t, found)
+	assert.Equal(t, http.StatusNotFound, code)
+
+	// when
+	_, found = StatusCode(errors.New("connection refused"))
+
+	// then
+	assert.False(t, found)
+}
@@ -31,6 +31,7 @@ type AccountPool interface {
 	IsSecretUsed(hyperscalerType Type, tenantName string) (bool, error)
 	IsSecretDirty(hyperscalerType Type, tenantName string) (bool, error)
 	IsSecretInternal(hyperscalerType Type, tenantName string) (bool, error)
+	CountFreeCredentials(hyperscalerType Type) (int, error)
 }
 
 func NewAccountPool(secretsClient corev1.SecretInterface, shootsClient gardener_apis.ShootInterface) AccountPool {
@@ -125,6 +126,19 @@ func (p *secretsAccountPool) IsSecretUsed(hyperscalerType Type, tenantName strin
 	return false, nil
 }
 
+// CountFreeCredentials returns the number of secrets which are not assigned to any tenant yet
+func (p *secretsAccountPool) CountFreeCredentials(hyperscalerType Type) (int, error) {
+	labelSelector := fmt.Sprintf("shared!=true, !tenantName, !dirty, hyperscalerType=%s", hyperscalerType)
+	secrets, err := p.secretsClient.List(metav1.ListOptions{
+		LabelSelector: labelSelector,
+	})
+	if err != nil {
+		return 0, errors.Wrapf(err, "error listing secrets for LabelSelector: %s", labelSelector)
+	}
+
+	return len(secrets.Items), nil
+}
+
 func (p *secretsAccountPool) Credentials(hyperscalerType Type, tenantName string) (Credentials, error) {
 
 	labelSelector := fmt.Sprintf("tenantName=%s,hyperscalerType=%s", tenantName, hyperscalerType)
@@ -173,6 +173,24 @@ func TestSecretsAccountPool_MarkSecretAsDirty(t *testing.T) {
 	})
 }
 
+func TestSecretsAccountPool_CountFreeCredentials(t *testing.T) {
+	//given
+	accPool := newTestAccountPool()
+
+	for hyperscalerType, expected := range map[Type]int{
+		GCP:   1,
+		Azure: 0,
+		AWS:   1,
+	} {
+		//when
+		count, err := accPool.CountFreeCredentials(hyperscalerType)
+
+		//then
+		require.NoError(t, err)
+		assert.Equal(t, expected, count, string(hyperscalerType))
+	}
+}
+
 func newTestAccountPool() AccountPool {
 	secret1 := &corev1.Secret{
 		ObjectMeta: machineryv1.ObjectMeta{
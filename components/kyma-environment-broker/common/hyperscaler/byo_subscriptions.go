@@ -0,0 +1,206 @@
+package hyperscaler
+
+import (
+	"strings"
+
+	kebError "github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/error"
+
+	gardener_types "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	gardener_apis "github.com/gardener/gardener/pkg/client/core/clientset/versioned/typed/core/v1beta1"
+	"github.com/pkg/errors"
+	apiv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+// labels of the BYO subscription secrets differ from the labels used by the accounts pool,
+// so the customer-provided credentials are never assigned to other tenants
+const (
+	byoLabel                = "byo"
+	byoTenantNameLabel      = "byoTenantName"
+	byoHyperscalerTypeLabel = "byoHyperscalerType"
+)
+
+// requiredCredentialKeys defines the keys of the secret data expected by Gardener for the given hyperscaler
+var requiredCredentialKeys = map[Type][]string{
+	GCP:   {"serviceaccount.json"},
+	Azure: {"clientID", "clientSecret", "subscriptionID", "tenantID"},
+	AWS:   {"accessKeyID", "secretAccessKey"},
+}
+
+// BYOSubscriptions manages hyperscaler subscriptions provided by the customers (BYO subscriptions).
+// The credentials are kept in secrets of the Gardener project namespace labeled with byo=true,
+// every secret has the secret binding with the same name which is used as the target secret of the cluster.
+type BYOSubscriptions struct {
+	secretsClient  corev1.SecretInterface
+	bindingsClient gardener_apis.SecretBindingInterface
+}
+
+func NewBYOSubscriptions(secretsClient corev1.SecretInterface, bindingsClient gardener_apis.SecretBindingInterface) *BYOSubscriptions {
+	return &BYOSubscriptions{
+		secretsClient:  secretsClient,
+		bindingsClient: bindingsClient,
+	}
+}
+
+// Attach validates the existing secret with the customer-provided credentials and makes it available for Gardener
+func (s *BYOSubscriptions) Attach(planID, tenantName, secretName string) error {
+	hyperscalerType, err := HyperscalerTypeForPlanID(planID)
+	if err != nil {
+		return err
+	}
+
+	secret, err := s.secretsClient.Get(secretName, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		return errors.Errorf("subscription secret %s does not exist", secretName)
+	case err != nil:
+		return kebError.AsTemporaryError(err, "while getting subscription secret %s", secretName)
+	}
+
+	if err := checkSubscriptionSecret(secret, hyperscalerType, tenantName); err != nil {
+		return err
+	}
+	if err := validateCredentialData(hyperscalerType, secret.Data); err != nil {
+		return errors.Wrapf(err, "while validating subscription secret %s", secretName)
+	}
+
+	return s.ensureSecretBinding(secret)
+}
+
+// Store creates the secret with the customer-provided credentials or replaces the credentials
+// of the existing secret (credentials rotation)
+func (s *BYOSubscriptions) Store(planID, tenantName, secretName string, credentials map[string]string) error {
+	hyperscalerType, err := HyperscalerTypeForPlanID(planID)
+	if err != nil {
+		return err
+	}
+
+	data := make(map[string][]byte, len(credentials))
+	for key, value := range credentials {
+		data[key] = []byte(value)
+	}
+	if err := validateCredentialData(hyperscalerType, data); err != nil {
+		return err
+	}
+
+	secret, err := s.secretsClient.Get(secretName, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		secret, err = s.secretsClient.Create(&apiv1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: secretName,
+				Labels: map[string]string{
+					byoLabel:                "true",
+					byoTenantNameLabel:      tenantName,
+					byoHyperscalerTypeLabel: string(hyperscalerType),
+				},
+			},
+			Type: apiv1.SecretTypeOpaque,
+			Data: data,
+		})
+		if err != nil {
+			return kebError.AsTemporaryError(err, "while creating subscription secret %s", secretName)
+		}
+	case err != nil:
+		return kebError.AsTemporaryError(err, "while getting subscription secret %s", secretName)
+	default:
+		if err := checkSubscriptionSecret(secret, hyperscalerType, tenantName); err != nil {
+			return err
+		}
+		secret.Data = data
+		secret, err = s.secretsClient.Update(secret)
+		if err != nil {
+			return kebError.AsTemporaryError(err, "while updating subscription secret %s", secretName)
+		}
+	}
+
+	return s.ensureSecretBinding(secret)
+}
+
+// Delete removes the secret binding and the secret of the customer-provided subscription once the cluster using them
+// is removed. The secret which does not belong to the given tenant is not removed.
+func (s *BYOSubscriptions) Delete(tenantName, secretName string) error {
+	secretExists := true
+	secret, err := s.secretsClient.Get(secretName, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		secretExists = false
+	case err != nil:
+		return kebError.AsTemporaryError(err, "while getting subscription secret %s", secretName)
+	case secret.Labels[byoLabel] != "true":
+		return errors.Errorf("secret %s is not labeled as a customer-provided subscription", secretName)
+	case secret.Labels[byoTenantNameLabel] != tenantName:
+		return errors.Errorf("secret %s does not belong to the global account %s", secretName, tenantName)
+	}
+
+	err = s.bindingsClient.Delete(secretName, &metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return kebError.AsTemporaryError(err, "while deleting secret binding %s", secretName)
+	}
+	if !secretExists {
+		return nil
+	}
+
+	err = s.secretsClient.Delete(secretName, &metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return kebError.AsTemporaryError(err, "while deleting subscription secret %s", secretName)
+	}
+
+	return nil
+}
+
+func (s *BYOSubscriptions) ensureSecretBinding(secret *apiv1.Secret) error {
+	_, err := s.bindingsClient.Get(secret.Name, metav1.GetOptions{})
+	switch {
+	case err == nil:
+		return nil
+	case !apierrors.IsNotFound(err):
+		return kebError.AsTemporaryError(err, "while getting secret binding %s", secret.Name)
+	}
+
+	_, err = s.bindingsClient.Create(&gardener_types.SecretBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   secret.Name,
+			Labels: map[string]string{byoLabel: "true"},
+		},
+		SecretRef: apiv1.SecretReference{
+			Name:      secret.Name,
+			Namespace: secret.Namespace,
+		},
+	})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return kebError.AsTemporaryError(err, "while creating secret binding %s", secret.Name)
+	}
+
+	return nil
+}
+
+func checkSubscriptionSecret(secret *apiv1.Secret, hyperscalerType Type, tenantName string) error {
+	if secret.Labels[byoLabel] != "true" {
+		return errors.Errorf("secret %s is not labeled as a customer-provided subscription", secret.Name)
+	}
+	if secret.Labels[byoTenantNameLabel] != tenantName {
+		return errors.Errorf("secret %s does not belong to the global account %s", secret.Name, tenantName)
+	}
+	if secret.Labels[byoHyperscalerTypeLabel] != string(hyperscalerType) {
+		return errors.Errorf("secret %s does not hold %s credentials", secret.Name, hyperscalerType)
+	}
+
+	return nil
+}
+
+func validateCredentialData(hyperscalerType Type, data map[string][]byte) error {
+	var missing []string
+	for _, key := range requiredCredentialKeys[hyperscalerType] {
+		if len(data[key]) == 0 {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		return errors.Errorf("missing %s credentials: %s", hyperscalerType, strings.Join(missing, ", "))
+	}
+
+	return nil
+}
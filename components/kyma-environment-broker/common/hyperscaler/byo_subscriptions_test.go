@@ -0,0 +1,267 @@
+package hyperscaler
+
+import (
+	"testing"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/broker"
+
+	gardener_fake "github.com/gardener/gardener/pkg/client/core/clientset/versioned/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	machineryv1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	v1 "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+const byoTenant = "byo-tenant"
+
+func TestBYOSubscriptions_Attach(t *testing.T) {
+	for name, tc := range map[string]struct {
+		secretName    string
+		planID        string
+		tenantName    string
+		expectedError string
+	}{
+		"valid secret": {
+			secretName: "byo-azure",
+			planID:     broker.AzurePlanID,
+			tenantName: byoTenant,
+		},
+		"secret does not exist": {
+			secretName:    "not-existing",
+			planID:        broker.AzurePlanID,
+			tenantName:    byoTenant,
+			expectedError: "subscription secret not-existing does not exist",
+		},
+		"secret from the accounts pool": {
+			secretName:    "pool-secret",
+			planID:        broker.AzurePlanID,
+			tenantName:    byoTenant,
+			expectedError: "secret pool-secret is not labeled as a customer-provided subscription",
+		},
+		"secret of other global account": {
+			secretName:    "byo-azure",
+			planID:        broker.AzurePlanID,
+			tenantName:    "other-tenant",
+			expectedError: "secret byo-azure does not belong to the global account other-tenant",
+		},
+		"secret of other hyperscaler": {
+			secretName:    "byo-azure",
+			planID:        broker.GCPPlanID,
+			tenantName:    byoTenant,
+			expectedError: "secret byo-azure does not hold gcp credentials",
+		},
+		"secret with missing credentials": {
+			secretName:    "byo-incomplete",
+			planID:        broker.AzurePlanID,
+			tenantName:    byoTenant,
+			expectedError: "while validating subscription secret byo-incomplete: missing azure credentials: clientSecret, tenantID",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// given
+			subscriptions, _ := newTestBYOSubscriptions()
+
+			// when
+			err := subscriptions.Attach(tc.planID, tc.tenantName, tc.secretName)
+
+			// then
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+
+			binding, err := subscriptions.bindingsClient.Get(tc.secretName, machineryv1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, tc.secretName, binding.SecretRef.Name)
+			assert.Equal(t, testNamespace, binding.SecretRef.Namespace)
+		})
+	}
+}
+
+func TestBYOSubscriptions_Store(t *testing.T) {
+	t.Run("should create secret and secret binding", func(t *testing.T) {
+		// given
+		subscriptions, secrets := newTestBYOSubscriptions()
+
+		// when
+		err := subscriptions.Store(broker.GCPPlanID, byoTenant, "byo-new", map[string]string{"serviceaccount.json": "{}"})
+
+		// then
+		require.NoError(t, err)
+
+		secret, err := secrets.Get("byo-new", machineryv1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "{}", string(secret.Data["serviceaccount.json"]))
+		assert.Equal(t, map[string]string{
+			"byo":                "true",
+			"byoTenantName":      byoTenant,
+			"byoHyperscalerType": "gcp",
+		}, secret.Labels)
+
+		_, err = subscriptions.bindingsClient.Get("byo-new", machineryv1.GetOptions{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("should rotate credentials of existing secret", func(t *testing.T) {
+		// given
+		subscriptions, secrets := newTestBYOSubscriptions()
+
+		// when
+		err := subscriptions.Store(broker.AzurePlanID, byoTenant, "byo-azure", fixAzureCredentials("rotated"))
+
+		// then
+		require.NoError(t, err)
+
+		secret, err := secrets.Get("byo-azure", machineryv1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "rotated", string(secret.Data["clientSecret"]))
+	})
+
+	t.Run("should not overwrite secret of other global account", func(t *testing.T) {
+		// given
+		subscriptions, secrets := newTestBYOSubscriptions()
+
+		// when
+		err := subscriptions.Store(broker.AzurePlanID, "other-tenant", "byo-azure", fixAzureCredentials("rotated"))
+
+		// then
+		assert.EqualError(t, err, "secret byo-azure does not belong to the global account other-tenant")
+
+		secret, err := secrets.Get("byo-azure", machineryv1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "secret", string(secret.Data["clientSecret"]))
+	})
+
+	t.Run("should reject incomplete credentials", func(t *testing.T) {
+		// given
+		subscriptions, _ := newTestBYOSubscriptions()
+
+		// when
+		err := subscriptions.Store(broker.AzurePlanID, byoTenant, "byo-new", map[string]string{"clientID": "id"})
+
+		// then
+		assert.EqualError(t, err, "missing azure credentials: clientSecret, subscriptionID, tenantID")
+	})
+}
+
+func TestBYOSubscriptions_Delete(t *testing.T) {
+	t.Run("should delete secret and secret binding", func(t *testing.T) {
+		// given
+		subscriptions, secrets := newTestBYOSubscriptions()
+		err := subscriptions.Store(broker.GCPPlanID, byoTenant, "byo-new", map[string]string{"serviceaccount.json": "{}"})
+		require.NoError(t, err)
+
+		// when
+		err = subscriptions.Delete(byoTenant, "byo-new")
+
+		// then
+		require.NoError(t, err)
+
+		_, err = secrets.Get("byo-new", machineryv1.GetOptions{})
+		assert.True(t, apierrors.IsNotFound(err))
+		_, err = subscriptions.bindingsClient.Get("byo-new", machineryv1.GetOptions{})
+		assert.True(t, apierrors.IsNotFound(err))
+	})
+
+	t.Run("should not fail if secret does not exist", func(t *testing.T) {
+		// given
+		subscriptions, _ := newTestBYOSubscriptions()
+
+		// when
+		err := subscriptions.Delete(byoTenant, "byo-not-existing")
+
+		// then
+		assert.NoError(t, err)
+	})
+
+	t.Run("should not delete secret of other global account", func(t *testing.T) {
+		// given
+		subscriptions, secrets := newTestBYOSubscriptions()
+
+		// when
+		err := subscriptions.Delete("other-tenant", "byo-azure")
+
+		// then
+		assert.EqualError(t, err, "secret byo-azure does not belong to the global account other-tenant")
+
+		_, err = secrets.Get("byo-azure", machineryv1.GetOptions{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("should not delete pool secret", func(t *testing.T) {
+		// given
+		subscriptions, secrets := newTestBYOSubscriptions()
+
+		// when
+		err := subscriptions.Delete(byoTenant, "pool-secret")
+
+		// then
+		assert.EqualError(t, err, "secret pool-secret is not labeled as a customer-provided subscription")
+
+		_, err = secrets.Get("pool-secret", machineryv1.GetOptions{})
+		assert.NoError(t, err)
+	})
+}
+
+func newTestBYOSubscriptions() (*BYOSubscriptions, v1.SecretInterface) {
+	byoSecret := &corev1.Secret{
+		ObjectMeta: machineryv1.ObjectMeta{
+			Name: "byo-azure", Namespace: testNamespace,
+			Labels: map[string]string{
+				"byo":                "true",
+				"byoTenantName":      byoTenant,
+				"byoHyperscalerType": "azure",
+			},
+		},
+		Data: toSecretData(fixAzureCredentials("secret")),
+	}
+	incompleteSecret := &corev1.Secret{
+		ObjectMeta: machineryv1.ObjectMeta{
+			Name: "byo-incomplete", Namespace: testNamespace,
+			Labels: map[string]string{
+				"byo":                "true",
+				"byoTenantName":      byoTenant,
+				"byoHyperscalerType": "azure",
+			},
+		},
+		Data: map[string][]byte{
+			"clientID":       []byte("id"),
+			"subscriptionID": []byte("subscription"),
+		},
+	}
+	poolSecret := &corev1.Secret{
+		ObjectMeta: machineryv1.ObjectMeta{
+			Name: "pool-secret", Namespace: testNamespace,
+			Labels: map[string]string{
+				"hyperscalerType": "azure",
+			},
+		},
+		Data: toSecretData(fixAzureCredentials("secret")),
+	}
+
+	secrets := fake.NewSimpleClientset(byoSecret, incompleteSecret, poolSecret).CoreV1().Secrets(testNamespace)
+	bindings := gardener_fake.NewSimpleClientset().CoreV1beta1().SecretBindings(testNamespace)
+
+	return NewBYOSubscriptions(secrets, bindings), secrets
+}
+
+func fixAzureCredentials(clientSecret string) map[string]string {
+	return map[string]string{
+		"clientID":       "id",
+		"clientSecret":   clientSecret,
+		"subscriptionID": "subscription",
+		"tenantID":       "tenant",
+	}
+}
+
+func toSecretData(credentials map[string]string) map[string][]byte {
+	data := make(map[string][]byte, len(credentials))
+	for key, value := range credentials {
+		data[key] = []byte(value)
+	}
+	return data
+}
@@ -0,0 +1,286 @@
+package orchestration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/common/httperror"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/common/pagination"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/orchestration"
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+)
+
+const defaultPageSize = 100
+
+// Client is the interface to interact with the KEB /orchestrations API as an HTTP client using OIDC ID token in JWT format.
+type Client interface {
+	ListOrchestrations() (orchestration.StatusResponseList, error)
+	GetOrchestration(orchestrationID string) (orchestration.StatusResponse, error)
+	ListOperations(orchestrationID string) (orchestration.OperationResponseList, error)
+	ListRuntimes(orchestrationID string) (orchestration.RuntimeResponseList, error)
+	GetOperation(orchestrationID, operationID string) (orchestration.OperationDetailResponse, error)
+	UpgradeKyma(params internal.OrchestrationParameters) (orchestration.UpgradeResponse, error)
+	SimulateUpgradeKyma(params internal.OrchestrationParameters) (orchestration.SimulationResponse, error)
+	CancelOrchestration(orchestrationID string) (orchestration.StatusResponse, error)
+	RetryOrchestration(orchestrationID string, retryRequest orchestration.RetryRequest) (orchestration.RetryResponse, error)
+}
+
+type client struct {
+	url        string
+	httpClient *http.Client
+}
+
+// NewClient constructs and returns new Client for KEB /orchestrations API
+// It takes the following arguments:
+//   - ctx  : context in which the http request will be executed
+//   - url  : base url of all KEB APIs, e.g. https://kyma-env-broker.kyma.local
+//   - auth : TokenSource object which provides the ID token for the HTTP request
+func NewClient(ctx context.Context, url string, auth oauth2.TokenSource) Client {
+	return &client{
+		url:        url,
+		httpClient: oauth2.NewClient(ctx, auth),
+	}
+}
+
+// ListOrchestrations fetches all orchestrations from KEB
+func (c *client) ListOrchestrations() (orchestration.StatusResponseList, error) {
+	orchestrations := orchestration.StatusResponseList{}
+	pages := newPager(fmt.Sprintf("%s/orchestrations", c.url))
+	for {
+		var op orchestration.StatusResponseList
+		err := c.get(pages.url(), &op)
+		if err != nil {
+			return orchestrations, err
+		}
+
+		orchestrations.TotalCount = op.TotalCount
+		orchestrations.Count += op.Count
+		orchestrations.Data = append(orchestrations.Data, op.Data...)
+		if !pages.next(op.Count, orchestrations.Count, orchestrations.TotalCount, op.NextCursor) {
+			return orchestrations, nil
+		}
+	}
+}
+
+// GetOrchestration fetches the status of the orchestration with the given ID
+func (c *client) GetOrchestration(orchestrationID string) (orchestration.StatusResponse, error) {
+	var status orchestration.StatusResponse
+	err := c.get(fmt.Sprintf("%s/orchestrations/%s", c.url, orchestrationID), &status)
+	return status, err
+}
+
+// ListOperations fetches all Runtime operations scheduled by the orchestration with the given ID
+func (c *client) ListOperations(orchestrationID string) (orchestration.OperationResponseList, error) {
+	operations := orchestration.OperationResponseList{}
+	pages := newPager(fmt.Sprintf("%s/orchestrations/%s/operations", c.url, orchestrationID))
+	for {
+		var op orchestration.OperationResponseList
+		err := c.get(pages.url(), &op)
+		if err != nil {
+			return operations, err
+		}
+
+		operations.TotalCount = op.TotalCount
+		operations.Count += op.Count
+		operations.Data = append(operations.Data, op.Data...)
+		if !pages.next(op.Count, operations.Count, operations.TotalCount, op.NextCursor) {
+			return operations, nil
+		}
+	}
+}
+
+// ListRuntimes fetches all Runtimes targeted by the orchestration with the given ID
+func (c *client) ListRuntimes(orchestrationID string) (orchestration.RuntimeResponseList, error) {
+	runtimes := orchestration.RuntimeResponseList{}
+	for page := 1; ; page++ {
+		var rt orchestration.RuntimeResponseList
+		err := c.get(pagedURL(fmt.Sprintf("%s/orchestrations/%s/runtimes", c.url, orchestrationID), page), &rt)
+		if err != nil {
+			return runtimes, err
+		}
+
+		runtimes.TotalCount = rt.TotalCount
+		runtimes.Count += rt.Count
+		runtimes.Data = append(runtimes.Data, rt.Data...)
+		runtimes.Preview = runtimes.Preview || rt.Preview
+		if rt.Count == 0 || runtimes.Count >= runtimes.TotalCount {
+			return runtimes, nil
+		}
+	}
+}
+
+// GetOperation fetches the details of the Runtime operation with the given ID scheduled by the given orchestration
+func (c *client) GetOperation(orchestrationID, operationID string) (orchestration.OperationDetailResponse, error) {
+	var operation orchestration.OperationDetailResponse
+	err := c.get(fmt.Sprintf("%s/orchestrations/%s/operations/%s", c.url, orchestrationID, operationID), &operation)
+	return operation, err
+}
+
+// UpgradeKyma creates a new Kyma upgrade orchestration with the given parameters
+func (c *client) UpgradeKyma(params internal.OrchestrationParameters) (orchestration.UpgradeResponse, error) {
+	var response orchestration.UpgradeResponse
+	body, err := json.Marshal(params)
+	if err != nil {
+		return response, errors.Wrap(err, "while marshalling orchestration parameters")
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/upgrade/kyma", c.url), bytes.NewReader(body))
+	if err != nil {
+		return response, errors.Wrap(err, "while creating request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	err = c.do(req, http.StatusAccepted, &response)
+	return response, err
+}
+
+// SimulateUpgradeKyma estimates the duration of the Kyma upgrade orchestration with the given parameters
+// from the durations of the past upgrades, the orchestration is not created
+func (c *client) SimulateUpgradeKyma(params internal.OrchestrationParameters) (orchestration.SimulationResponse, error) {
+	var response orchestration.SimulationResponse
+	body, err := json.Marshal(params)
+	if err != nil {
+		return response, errors.Wrap(err, "while marshalling orchestration parameters")
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/upgrade/kyma/simulate", c.url), bytes.NewReader(body))
+	if err != nil {
+		return response, errors.Wrap(err, "while creating request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	err = c.do(req, http.StatusOK, &response)
+	return response, err
+}
+
+// CancelOrchestration cancels the orchestration with the given ID, the operations in progress are canceled
+// and the scheduled operations are not executed
+func (c *client) CancelOrchestration(orchestrationID string) (orchestration.StatusResponse, error) {
+	var status orchestration.StatusResponse
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/orchestrations/%s/cancel", c.url, orchestrationID), nil)
+	if err != nil {
+		return status, errors.Wrap(err, "while creating request")
+	}
+
+	err = c.do(req, http.StatusOK, &status)
+	return status, err
+}
+
+// RetryOrchestration schedules again the failed and canceled operations of the orchestration with the given ID,
+// in the dry run mode only the operations which would be retried are returned
+func (c *client) RetryOrchestration(orchestrationID string, retryRequest orchestration.RetryRequest) (orchestration.RetryResponse, error) {
+	var response orchestration.RetryResponse
+	body, err := json.Marshal(retryRequest)
+	if err != nil {
+		return response, errors.Wrap(err, "while marshalling retry request")
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/orchestrations/%s/retry", c.url, orchestrationID), bytes.NewReader(body))
+	if err != nil {
+		return response, errors.Wrap(err, "while creating request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	expectedStatus := http.StatusAccepted
+	if retryRequest.DryRun {
+		expectedStatus = http.StatusOK
+	}
+	err = c.do(req, expectedStatus, &response)
+	return response, err
+}
+
+func (c *client) get(url string, obj interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return errors.Wrap(err, "while creating request")
+	}
+
+	return c.do(req, http.StatusOK, obj)
+}
+
+func (c *client) do(req *http.Request, expectedStatus int, obj interface{}) (err error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "while calling %s", req.URL.String())
+	}
+
+	// Drain response body and close, return error to context if there isn't any.
+	defer func() {
+		derr := drainResponseBody(resp.Body)
+		if err == nil {
+			err = derr
+		}
+		cerr := resp.Body.Close()
+		if err == nil {
+			err = cerr
+		}
+	}()
+
+	if resp.StatusCode != expectedStatus {
+		return httperror.NewResponseError(req.URL.String(), resp.StatusCode, resp.Status)
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(obj)
+	if err != nil {
+		return errors.Wrap(err, "while decoding response body")
+	}
+
+	return nil
+}
+
+func pagedURL(url string, page int) string {
+	return fmt.Sprintf("%s?%s=%s&%s=%s", url, pagination.PageParam, strconv.Itoa(page), pagination.PageSizeParam, strconv.Itoa(defaultPageSize))
+}
+
+// pager iterates over the pages of a list. It follows the cursor returned by KEB, so the pages neither skip
+// nor repeat objects created in the meantime, and falls back to the page numbers if KEB does not return the cursor.
+type pager struct {
+	baseURL string
+	page    int
+	cursor  string
+}
+
+func newPager(baseURL string) *pager {
+	return &pager{baseURL: baseURL, page: 1}
+}
+
+func (p *pager) url() string {
+	if p.cursor != "" {
+		return fmt.Sprintf("%s?%s=%s&%s=%s", p.baseURL, pagination.CursorParam, p.cursor, pagination.PageSizeParam, strconv.Itoa(defaultPageSize))
+	}
+	return pagedURL(p.baseURL, p.page)
+}
+
+// next moves to the page following the fetched page with the given number of objects, false is returned
+// if the fetched page is the last one
+func (p *pager) next(count, fetched, totalCount int, nextCursor string) bool {
+	switch {
+	case count == 0:
+		return false
+	case nextCursor != "":
+		p.cursor = nextCursor
+		return true
+	case p.cursor != "":
+		// the page fetched with the cursor which does not point to the next page is the last one
+		return false
+	default:
+		p.page++
+		return fetched < totalCount
+	}
+}
+
+func drainResponseBody(body io.Reader) error {
+	if body == nil {
+		return nil
+	}
+	_, err := io.Copy(ioutil.Discard, io.LimitReader(body, 4096))
+	return err
+}
@@ -0,0 +1,345 @@
+package orchestration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/common/pagination"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/orchestration"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+type FakeTokenSource string
+
+var fixToken FakeTokenSource = "fake-token-1234"
+
+func (t FakeTokenSource) Token() (*oauth2.Token, error) {
+	return &oauth2.Token{
+		AccessToken: string(t),
+		Expiry:      time.Now().Add(time.Duration(12 * time.Hour)),
+	}, nil
+}
+
+func TestClient_ListOrchestrations(t *testing.T) {
+	//given
+	called := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called++
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/orchestrations", r.URL.Path)
+		assert.Equal(t, r.Header.Get("Authorization"), fmt.Sprintf("Bearer %s", fixToken))
+		assert.Equal(t, strconv.Itoa(called), r.URL.Query().Get(pagination.PageParam))
+
+		err := json.NewEncoder(w).Encode(orchestration.StatusResponseList{
+			Data:       []orchestration.StatusResponse{{OrchestrationID: fmt.Sprintf("id-%d", called)}},
+			Count:      1,
+			TotalCount: 2,
+		})
+		require.NoError(t, err)
+	}))
+	defer ts.Close()
+	client := NewClient(context.TODO(), ts.URL, fixToken)
+
+	//when
+	list, err := client.ListOrchestrations()
+
+	//then
+	require.NoError(t, err)
+	assert.Equal(t, 2, called)
+	assert.Equal(t, 2, list.Count)
+	assert.Equal(t, 2, list.TotalCount)
+	require.Len(t, list.Data, 2)
+	assert.Equal(t, "id-1", list.Data[0].OrchestrationID)
+	assert.Equal(t, "id-2", list.Data[1].OrchestrationID)
+}
+
+func TestClient_ListOrchestrationsWithCursor(t *testing.T) {
+	//given
+	called := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called++
+		query := r.URL.Query()
+		response := orchestration.StatusResponseList{
+			Data:       []orchestration.StatusResponse{{OrchestrationID: fmt.Sprintf("id-%d", called)}},
+			Count:      1,
+			TotalCount: 2,
+		}
+		switch called {
+		case 1:
+			assert.Equal(t, "1", query.Get(pagination.PageParam))
+			response.NextCursor = "cursor-1"
+		default:
+			assert.Equal(t, "cursor-1", query.Get(pagination.CursorParam))
+			assert.Empty(t, query.Get(pagination.PageParam), "the page must not be sent with the cursor")
+			// an orchestration created in the meantime increases the total count, the cursor ends the listing
+			response.TotalCount = 3
+		}
+
+		err := json.NewEncoder(w).Encode(response)
+		require.NoError(t, err)
+	}))
+	defer ts.Close()
+	client := NewClient(context.TODO(), ts.URL, fixToken)
+
+	//when
+	list, err := client.ListOrchestrations()
+
+	//then
+	require.NoError(t, err)
+	assert.Equal(t, 2, called)
+	assert.Equal(t, 2, list.Count)
+	require.Len(t, list.Data, 2)
+	assert.Equal(t, "id-1", list.Data[0].OrchestrationID)
+	assert.Equal(t, "id-2", list.Data[1].OrchestrationID)
+}
+
+func TestClient_GetOrchestration(t *testing.T) {
+	//given
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/orchestrations/id", r.URL.Path)
+
+		err := json.NewEncoder(w).Encode(orchestration.StatusResponse{OrchestrationID: "id", State: "in progress"})
+		require.NoError(t, err)
+	}))
+	defer ts.Close()
+	client := NewClient(context.TODO(), ts.URL, fixToken)
+
+	//when
+	status, err := client.GetOrchestration("id")
+
+	//then
+	require.NoError(t, err)
+	assert.Equal(t, "id", status.OrchestrationID)
+	assert.Equal(t, "in progress", status.State)
+}
+
+func TestClient_ListOperations(t *testing.T) {
+	//given
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/orchestrations/id/operations", r.URL.Path)
+
+		err := json.NewEncoder(w).Encode(orchestration.OperationResponseList{
+			Data:       []orchestration.OperationResponse{{OperationID: "op-1"}, {OperationID: "op-2"}},
+			Count:      2,
+			TotalCount: 2,
+		})
+		require.NoError(t, err)
+	}))
+	defer ts.Close()
+	client := NewClient(context.TODO(), ts.URL, fixToken)
+
+	//when
+	list, err := client.ListOperations("id")
+
+	//then
+	require.NoError(t, err)
+	assert.Equal(t, 2, list.Count)
+	assert.Len(t, list.Data, 2)
+}
+
+func TestClient_ListRuntimes(t *testing.T) {
+	//given
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/orchestrations/id/runtimes", r.URL.Path)
+
+		err := json.NewEncoder(w).Encode(orchestration.RuntimeResponseList{
+			Data:       []internal.Runtime{{RuntimeID: "runtime-1"}, {RuntimeID: "runtime-2"}},
+			Count:      2,
+			TotalCount: 2,
+			Preview:    true,
+		})
+		require.NoError(t, err)
+	}))
+	defer ts.Close()
+	client := NewClient(context.TODO(), ts.URL, fixToken)
+
+	//when
+	list, err := client.ListRuntimes("id")
+
+	//then
+	require.NoError(t, err)
+	assert.Equal(t, 2, list.Count)
+	assert.Len(t, list.Data, 2)
+	assert.True(t, list.Preview)
+}
+
+func TestClient_GetOperation(t *testing.T) {
+	t.Run("operation found", func(t *testing.T) {
+		//given
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/orchestrations/id/operations/op-1", r.URL.Path)
+
+			err := json.NewEncoder(w).Encode(orchestration.OperationDetailResponse{
+				OperationResponse: orchestration.OperationResponse{OperationID: "op-1", OrchestrationID: "id"},
+			})
+			require.NoError(t, err)
+		}))
+		defer ts.Close()
+		client := NewClient(context.TODO(), ts.URL, fixToken)
+
+		//when
+		operation, err := client.GetOperation("id", "op-1")
+
+		//then
+		require.NoError(t, err)
+		assert.Equal(t, "op-1", operation.OperationID)
+		assert.Equal(t, "id", operation.OrchestrationID)
+	})
+
+	t.Run("operation not found", func(t *testing.T) {
+		//given
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer ts.Close()
+		client := NewClient(context.TODO(), ts.URL, fixToken)
+
+		//when
+		_, err := client.GetOperation("id", "op-1")
+
+		//then
+		assert.Error(t, err)
+	})
+}
+
+func TestClient_UpgradeKyma(t *testing.T) {
+	//given
+	params := internal.OrchestrationParameters{
+		Targets: internal.TargetSpec{
+			Include: []internal.RuntimeTarget{{Target: internal.TargetAll}},
+		},
+		Strategy: internal.StrategySpec{
+			Type:     internal.ParallelStrategy,
+			Schedule: internal.Immediate,
+		},
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/upgrade/kyma", r.URL.Path)
+
+		var got internal.OrchestrationParameters
+		err := json.NewDecoder(r.Body).Decode(&got)
+		require.NoError(t, err)
+		assert.Equal(t, params, got)
+
+		w.WriteHeader(http.StatusAccepted)
+		err = json.NewEncoder(w).Encode(orchestration.UpgradeResponse{OrchestrationID: "id"})
+		require.NoError(t, err)
+	}))
+	defer ts.Close()
+	client := NewClient(context.TODO(), ts.URL, fixToken)
+
+	//when
+	response, err := client.UpgradeKyma(params)
+
+	//then
+	require.NoError(t, err)
+	assert.Equal(t, "id", response.OrchestrationID)
+}
+
+func TestClient_SimulateUpgradeKyma(t *testing.T) {
+	//given
+	params := internal.OrchestrationParameters{
+		Targets: internal.TargetSpec{
+			Include: []internal.RuntimeTarget{{Target: internal.TargetAll}},
+		},
+		Strategy: internal.StrategySpec{
+			Type:     internal.ParallelStrategy,
+			Parallel: internal.ParallelStrategySpec{Workers: 5},
+		},
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/upgrade/kyma/simulate", r.URL.Path)
+
+		var got internal.OrchestrationParameters
+		err := json.NewDecoder(r.Body).Decode(&got)
+		require.NoError(t, err)
+		assert.Equal(t, params, got)
+
+		err = json.NewEncoder(w).Encode(orchestration.SimulationResponse{Runtimes: 10, Workers: 5, EstimatedDuration: "1h0m0s"})
+		require.NoError(t, err)
+	}))
+	defer ts.Close()
+	client := NewClient(context.TODO(), ts.URL, fixToken)
+
+	//when
+	response, err := client.SimulateUpgradeKyma(params)
+
+	//then
+	require.NoError(t, err)
+	assert.Equal(t, 10, response.Runtimes)
+	assert.Equal(t, "1h0m0s", response.EstimatedDuration)
+}
+
+func TestClient_CancelOrchestration(t *testing.T) {
+	//given
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "/orchestrations/id/cancel", r.URL.Path)
+
+		err := json.NewEncoder(w).Encode(orchestration.StatusResponse{OrchestrationID: "id", State: internal.Canceling})
+		require.NoError(t, err)
+	}))
+	defer ts.Close()
+	client := NewClient(context.TODO(), ts.URL, fixToken)
+
+	//when
+	status, err := client.CancelOrchestration("id")
+
+	//then
+	require.NoError(t, err)
+	assert.Equal(t, "id", status.OrchestrationID)
+	assert.Equal(t, internal.Canceling, status.State)
+}
+
+func TestClient_RetryOrchestration(t *testing.T) {
+	for tn, tc := range map[string]struct {
+		dryRun bool
+		status int
+	}{
+		"retry":   {dryRun: false, status: http.StatusAccepted},
+		"dry run": {dryRun: true, status: http.StatusOK},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			//given
+			retryRequest := orchestration.RetryRequest{
+				Operations: []string{"op-1"},
+				FailedOnly: true,
+				DryRun:     tc.dryRun,
+			}
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "/orchestrations/id/retry", r.URL.Path)
+
+				var got orchestration.RetryRequest
+				err := json.NewDecoder(r.Body).Decode(&got)
+				require.NoError(t, err)
+				assert.Equal(t, retryRequest, got)
+
+				w.WriteHeader(tc.status)
+				err = json.NewEncoder(w).Encode(orchestration.RetryResponse{OrchestrationID: "id", RetryOperations: []string{"op-1"}, DryRun: tc.dryRun})
+				require.NoError(t, err)
+			}))
+			defer ts.Close()
+			client := NewClient(context.TODO(), ts.URL, fixToken)
+
+			//when
+			response, err := client.RetryOrchestration("id", retryRequest)
+
+			//then
+			require.NoError(t, err)
+			assert.Equal(t, []string{"op-1"}, response.RetryOperations)
+			assert.Equal(t, tc.dryRun, response.DryRun)
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// cursor is the position of the last object of the page. The objects are ordered by the creation time and the ID,
+// so the next page starts after the cursor even if the objects are created or removed in the meantime.
+type cursor struct {
+	CreatedAt time.Time `json:"createdAt"`
+	ID        string    `json:"id"`
+}
+
+// EncodeCursor returns the opaque token which points to the object with the given creation time and ID
+func EncodeCursor(createdAt time.Time, id string) string {
+	raw, _ := json.Marshal(cursor{CreatedAt: createdAt, ID: id})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor returns the creation time and the ID of the object the token returned by EncodeCursor points to
+func DecodeCursor(token string) (time.Time, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return time.Time{}, "", errors.New("cursor is malformed")
+	}
+	var c cursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.ID == "" || c.CreatedAt.IsZero() {
+		return time.Time{}, "", errors.New("cursor is malformed")
+	}
+	return c.CreatedAt, c.ID, nil
+}
+
+// ExtractCursorFromRequest returns the creation time and the ID of the object the cursor query parameter points to,
+// found is false if the page is requested by the page number
+func ExtractCursorFromRequest(req *http.Request) (createdAt time.Time, id string, found bool, err error) {
+	query := req.URL.Query()
+	token := query.Get(CursorParam)
+	if token == "" {
+		return time.Time{}, "", false, nil
+	}
+	if _, found := query[PageParam]; found {
+		return time.Time{}, "", false, errors.Errorf("%s and %s query parameters cannot be used together", PageParam, CursorParam)
+	}
+	createdAt, id, err = DecodeCursor(token)
+	if err != nil {
+		return time.Time{}, "", false, err
+	}
+	return createdAt, id, true, nil
+}
@@ -31,14 +31,14 @@ func ValidatePageParameters(pageSize, page int) error {
 func ConvertPageAndPageSizeToOffset(pageSize, page int) int {
 	if page < 2 {
 		return 0
-	} else {
-		return page*pageSize - 1
 	}
+	return (page - 1) * pageSize
 }
 
 const (
 	PageSizeParam = "page_size"
 	PageParam     = "page"
+	CursorParam   = "cursor"
 )
 
 func ExtractPaginationConfigFromRequest(req *http.Request, maxPage int) (int, int, error) {
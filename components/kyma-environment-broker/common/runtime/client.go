@@ -1,6 +1,7 @@
 package runtime
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -9,17 +10,31 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"sync"
+	"time"
 
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/common/httperror"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/common/pagination"
 	"github.com/pkg/errors"
 	"golang.org/x/oauth2"
 )
 
-const defaultPageSize = 100
+const (
+	defaultPageSize = 100
+	// maxParallelPageRequests limits the number of pages fetched concurrently, so listing a large number
+	// of runtimes does not flood KEB with requests
+	maxParallelPageRequests = 4
+	// maxRateLimitedAttempts limits the number of attempts to fetch a page rejected by the rate limiter
+	maxRateLimitedAttempts = 5
+	defaultRetryAfter      = time.Second
+)
 
 // Client is the interface to interact with the KEB /runtimes API as an HTTP client using OIDC ID token in JWT format.
 type Client interface {
 	ListRuntimes(params ListParameters) (RuntimesPage, error)
+	GetRuntime(runtimeID string) (RuntimeDTO, error)
+	ConfirmOrphan(shootName string) (OrphanDTO, error)
+	CleanupOrphan(shootName, confirmationToken string) (OrphanCleanupResponse, error)
 }
 
 type client struct {
@@ -41,68 +56,245 @@ func NewClient(ctx context.Context, url string, auth oauth2.TokenSource) Client
 
 // ListRuntimes fetches the runtimes from KEB according to the given parameters.
 // If params.Page or params.PageSize is not set (zero), the client will fetch and return all runtimes.
+// The first page is fetched to learn the total count. The remaining pages are fetched one by one following
+// the cursor returned by KEB, so no runtime is skipped or repeated when runtimes are provisioned in the meantime.
+// If KEB does not return the cursor, the remaining pages are fetched concurrently and merged in the order of the pages.
 func (c *client) ListRuntimes(params ListParameters) (RuntimesPage, error) {
-	runtimes := RuntimesPage{}
-	getAll := false
-	fetchedAll := false
-	if params.Page == 0 || params.PageSize == 0 {
-		getAll = true
-		params.Page = 1
-		params.PageSize = defaultPageSize
+	if (params.Page != 0 || params.Cursor != "") && params.PageSize != 0 {
+		return c.fetchPage(params)
 	}
 
-	for !fetchedAll {
-		req, err := http.NewRequest("GET", fmt.Sprintf("%s/runtimes", c.url), nil)
-		if err != nil {
-			return runtimes, errors.Wrap(err, "while creating request")
+	params.Page = 1
+	params.PageSize = defaultPageSize
+	params.Cursor = ""
+	first, err := c.fetchPage(params)
+	if err != nil {
+		return RuntimesPage{}, err
+	}
+	if first.NextCursor != "" {
+		return c.fetchPagesAfter(params, first)
+	}
+	if first.Count == 0 || first.Count >= first.TotalCount {
+		return first, nil
+	}
+
+	// the server may limit the page size, so the size of the first page is used to compute the number of pages
+	pageCount := (first.TotalCount + first.Count - 1) / first.Count
+	pages := make([]RuntimesPage, pageCount)
+	errs := make([]error, pageCount)
+	pages[0] = first
+
+	var wg sync.WaitGroup
+	workers := make(chan struct{}, maxParallelPageRequests)
+	for i := 1; i < pageCount; i++ {
+		wg.Add(1)
+		workers <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-workers }()
+			pageParams := params
+			pageParams.Page = i + 1
+			pages[i], errs[i] = c.fetchPage(pageParams)
+		}(i)
+	}
+	wg.Wait()
+
+	runtimes := RuntimesPage{TotalCount: first.TotalCount}
+	for i, page := range pages {
+		if errs[i] != nil {
+			return RuntimesPage{}, errs[i]
 		}
-		setQuery(req.URL, params)
+		runtimes.Count += page.Count
+		runtimes.Data = append(runtimes.Data, page.Data...)
+	}
 
-		resp, err := c.httpClient.Do(req)
+	return runtimes, nil
+}
+
+// fetchPagesAfter fetches the pages following the first page with the cursors returned by KEB
+func (c *client) fetchPagesAfter(params ListParameters, first RuntimesPage) (RuntimesPage, error) {
+	runtimes := RuntimesPage{Data: first.Data, Count: first.Count, TotalCount: first.TotalCount}
+	params.Page = 0
+	for cursor := first.NextCursor; cursor != ""; {
+		params.Cursor = cursor
+		page, err := c.fetchPage(params)
 		if err != nil {
-			return runtimes, errors.Wrapf(err, "while calling %s", req.URL.String())
+			return RuntimesPage{}, err
 		}
+		runtimes.Count += page.Count
+		runtimes.Data = append(runtimes.Data, page.Data...)
+		cursor = page.NextCursor
+	}
 
-		// Drain response body and close, return error to context if there isn't any.
-		defer func() {
-			derr := drainResponseBody(resp.Body)
-			if err == nil {
-				err = derr
-			}
-			cerr := resp.Body.Close()
-			if err == nil {
-				err = cerr
-			}
-		}()
-
-		if resp.StatusCode != http.StatusOK {
-			return runtimes, fmt.Errorf("calling %s returned %d (%s) status", req.URL.String(), resp.StatusCode, resp.Status)
+	return runtimes, nil
+}
+
+// fetchPage fetches a single page of the runtimes, the request is repeated after the time given by the server
+// if the rate limit is exceeded
+func (c *client) fetchPage(params ListParameters) (RuntimesPage, error) {
+	for attempt := 1; ; attempt++ {
+		page, retryAfter, err := c.doFetchPage(params)
+		if err != nil || retryAfter == 0 {
+			return page, err
 		}
+		if attempt == maxRateLimitedAttempts {
+			return RuntimesPage{}, fmt.Errorf("rate limit exceeded while fetching page of runtimes")
+		}
+		time.Sleep(retryAfter)
+	}
+}
 
-		var rp RuntimesPage
-		decoder := json.NewDecoder(resp.Body)
-		err = decoder.Decode(&rp)
-		if err != nil {
-			return runtimes, errors.Wrap(err, "while decoding response body")
+func (c *client) doFetchPage(params ListParameters) (runtimes RuntimesPage, retryAfter time.Duration, err error) {
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/runtimes", c.url), nil)
+	if err != nil {
+		return runtimes, 0, errors.Wrap(err, "while creating request")
+	}
+	setQuery(req.URL, params)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return runtimes, 0, errors.Wrapf(err, "while calling %s", req.URL.String())
+	}
+
+	// Drain response body and close, return error to context if there isn't any.
+	defer func() {
+		derr := drainResponseBody(resp.Body)
+		if err == nil {
+			err = derr
+		}
+		cerr := resp.Body.Close()
+		if err == nil {
+			err = cerr
 		}
+	}()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return runtimes, parseRetryAfter(resp.Header.Get("Retry-After")), nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return runtimes, 0, httperror.NewResponseError(req.URL.String(), resp.StatusCode, resp.Status)
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&runtimes)
+	if err != nil {
+		return runtimes, 0, errors.Wrap(err, "while decoding response body")
+	}
+
+	return runtimes, 0, nil
+}
 
-		runtimes.TotalCount = rp.TotalCount
-		runtimes.Count += rp.Count
-		runtimes.Data = append(runtimes.Data, rp.Data...)
-		if getAll {
-			params.Page++
-			fetchedAll = runtimes.Count >= runtimes.TotalCount
-		} else {
-			fetchedAll = true
+// parseRetryAfter returns the delay given in seconds in the Retry-After header, or the default delay
+func parseRetryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds <= 0 {
+		return defaultRetryAfter
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// GetRuntime fetches details of the runtime with the given ID, including the data required to access its API server.
+func (c *client) GetRuntime(runtimeID string) (runtime RuntimeDTO, err error) {
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/runtimes/%s", c.url, runtimeID), nil)
+	if err != nil {
+		return runtime, errors.Wrap(err, "while creating request")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return runtime, errors.Wrapf(err, "while calling %s", req.URL.String())
+	}
+
+	// Drain response body and close, return error to context if there isn't any.
+	defer func() {
+		derr := drainResponseBody(resp.Body)
+		if err == nil {
+			err = derr
 		}
+		cerr := resp.Body.Close()
+		if err == nil {
+			err = cerr
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return runtime, httperror.NewResponseError(req.URL.String(), resp.StatusCode, resp.Status)
 	}
 
-	return runtimes, nil
+	err = json.NewDecoder(resp.Body).Decode(&runtime)
+	if err != nil {
+		return runtime, errors.Wrap(err, "while decoding response body")
+	}
+
+	return runtime, nil
+}
+
+// ConfirmOrphan checks the shoot is an orphan and returns its details with the confirmation token required by CleanupOrphan
+func (c *client) ConfirmOrphan(shootName string) (OrphanDTO, error) {
+	var orphan OrphanDTO
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/orphans/%s", c.url, shootName), nil)
+	if err != nil {
+		return orphan, errors.Wrap(err, "while creating request")
+	}
+
+	err = c.do(req, http.StatusOK, &orphan)
+	return orphan, err
+}
+
+// CleanupOrphan triggers the deletion of the orphaned shoot, the confirmation token is returned by ConfirmOrphan
+func (c *client) CleanupOrphan(shootName, confirmationToken string) (OrphanCleanupResponse, error) {
+	var response OrphanCleanupResponse
+	body, err := json.Marshal(OrphanCleanupRequest{ConfirmationToken: confirmationToken})
+	if err != nil {
+		return response, errors.Wrap(err, "while marshalling cleanup request")
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/orphans/%s/cleanup", c.url, shootName), bytes.NewReader(body))
+	if err != nil {
+		return response, errors.Wrap(err, "while creating request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	err = c.do(req, http.StatusAccepted, &response)
+	return response, err
+}
+
+func (c *client) do(req *http.Request, expectedStatus int, obj interface{}) (err error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "while calling %s", req.URL.String())
+	}
+
+	// Drain response body and close, return error to context if there isn't any.
+	defer func() {
+		derr := drainResponseBody(resp.Body)
+		if err == nil {
+			err = derr
+		}
+		cerr := resp.Body.Close()
+		if err == nil {
+			err = cerr
+		}
+	}()
+
+	if resp.StatusCode != expectedStatus {
+		return httperror.NewResponseError(req.URL.String(), resp.StatusCode, resp.Status)
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(obj)
+	if err != nil {
+		return errors.Wrap(err, "while decoding response body")
+	}
+
+	return nil
 }
 
 func setQuery(url *url.URL, params ListParameters) {
 	query := url.Query()
-	query.Add(pagination.PageParam, strconv.Itoa(params.Page))
+	if params.Cursor != "" {
+		query.Add(pagination.CursorParam, params.Cursor)
+	} else {
+		query.Add(pagination.PageParam, strconv.Itoa(params.Page))
+	}
 	query.Add(pagination.PageSizeParam, strconv.Itoa(params.PageSize))
 	setParamList(query, GlobalAccountIDParam, params.GlobalAccountIDs)
 	setParamList(query, SubAccountIDParam, params.SubAccountIDs)
@@ -110,6 +302,21 @@ func setQuery(url *url.URL, params ListParameters) {
 	setParamList(query, RuntimeIDParam, params.RuntimeIDs)
 	setParamList(query, RegionParam, params.Regions)
 	setParamList(query, ShootParam, params.Shoots)
+	setParamList(query, PlanParam, params.Plans)
+	setParamTime(query, CreatedAfterParam, params.CreatedAfter)
+	setParamTime(query, CreatedBeforeParam, params.CreatedBefore)
+	setParamTime(query, UpdatedAfterParam, params.UpdatedAfter)
+	setParamTime(query, UpdatedBeforeParam, params.UpdatedBefore)
+	if params.State != "" {
+		query.Add(StateParam, params.State)
+	}
+	if params.WithParameters {
+		query.Add(ParamsParam, "true")
+	}
+	setParamList(query, FieldsParam, params.Fields)
+	if params.Search != "" {
+		query.Add(SearchParam, params.Search)
+	}
 	url.RawQuery = query.Encode()
 }
 
@@ -119,6 +326,12 @@ func setParamList(query url.Values, key string, values []string) {
 	}
 }
 
+func setParamTime(query url.Values, key string, value time.Time) {
+	if !value.IsZero() {
+		query.Add(key, value.Format(time.RFC3339))
+	}
+}
+
 func drainResponseBody(body io.Reader) error {
 	if body == nil {
 		return nil
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strconv"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -44,6 +45,10 @@ func TestClient_ListRuntimes(t *testing.T) {
 			RuntimeIDs:       []string{"rid1", "rid2"},
 			Regions:          []string{"region1", "region2"},
 			Shoots:           []string{"shoot1", "shoot2"},
+			Plans:            []string{"azure", "gcp"},
+			CreatedAfter:     time.Date(2020, 11, 20, 10, 0, 0, 0, time.UTC),
+			CreatedBefore:    time.Date(2020, 11, 20, 12, 0, 0, 0, time.UTC),
+			Fields:           []string{"runtimeID", "instanceID"},
 		}
 		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			called++
@@ -59,6 +64,11 @@ func TestClient_ListRuntimes(t *testing.T) {
 			assert.ElementsMatch(t, params.RuntimeIDs, query[RuntimeIDParam])
 			assert.ElementsMatch(t, params.Regions, query[RegionParam])
 			assert.ElementsMatch(t, params.Shoots, query[ShootParam])
+			assert.ElementsMatch(t, params.Plans, query[PlanParam])
+			assert.Equal(t, "2020-11-20T10:00:00Z", query.Get(CreatedAfterParam))
+			assert.Equal(t, "2020-11-20T12:00:00Z", query.Get(CreatedBeforeParam))
+			assert.Empty(t, query[UpdatedAfterParam])
+			assert.Equal(t, params.Fields, query[FieldsParam])
 
 			err := respondRuntimes(w, []RuntimeDTO{runtime1, runtime2}, 2)
 			require.NoError(t, err)
@@ -103,6 +113,158 @@ func TestClient_ListRuntimes(t *testing.T) {
 		assert.Equal(t, 4, rp.TotalCount)
 		assert.Len(t, rp.Data, 4)
 	})
+
+	t.Run("test parallel pagination keeps the order of pages", func(t *testing.T) {
+		// given
+		var called int32
+		pageSize, totalCount := 2, 9
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&called, 1)
+			page, err := strconv.Atoi(r.URL.Query().Get(pagination.PageParam))
+			require.NoError(t, err)
+			// later pages are returned faster to verify the merge order
+			time.Sleep(time.Duration(10-page) * time.Millisecond)
+
+			runtimes := make([]RuntimeDTO, 0, pageSize)
+			for i := (page - 1) * pageSize; i < page*pageSize && i < totalCount; i++ {
+				runtimes = append(runtimes, fixRuntimeDTO(fmt.Sprintf("runtime%d", i)))
+			}
+			err = respondRuntimes(w, runtimes, totalCount)
+			require.NoError(t, err)
+		}))
+		defer ts.Close()
+		client := NewClient(context.TODO(), ts.URL, fixToken)
+
+		// when
+		rp, err := client.ListRuntimes(ListParameters{})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, int32(5), atomic.LoadInt32(&called))
+		assert.Equal(t, totalCount, rp.Count)
+		assert.Equal(t, totalCount, rp.TotalCount)
+		require.Len(t, rp.Data, totalCount)
+		for i, rt := range rp.Data {
+			assert.Equal(t, fmt.Sprintf("runtime%d", i), rt.RuntimeID)
+		}
+	})
+
+	t.Run("test rate limited page is fetched again", func(t *testing.T) {
+		// given
+		var called int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&called, 1) == 2 {
+				w.Header().Set("Retry-After", "1")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			page := r.URL.Query().Get(pagination.PageParam)
+			runtimes := []RuntimeDTO{runtime1}
+			if page == "2" {
+				runtimes = []RuntimeDTO{runtime2}
+			}
+			err := respondRuntimes(w, runtimes, 2)
+			require.NoError(t, err)
+		}))
+		defer ts.Close()
+		client := NewClient(context.TODO(), ts.URL, fixToken)
+
+		// when
+		rp, err := client.ListRuntimes(ListParameters{})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, int32(3), atomic.LoadInt32(&called))
+		require.Len(t, rp.Data, 2)
+		assert.Equal(t, runtime1.RuntimeID, rp.Data[0].RuntimeID)
+		assert.Equal(t, runtime2.RuntimeID, rp.Data[1].RuntimeID)
+	})
+
+	t.Run("test pagination follows the cursor", func(t *testing.T) {
+		// given
+		var called int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			query := r.URL.Query()
+			rp := RuntimesPage{Count: 1, TotalCount: 3}
+			switch atomic.AddInt32(&called, 1) {
+			case 1:
+				assert.Equal(t, "1", query.Get(pagination.PageParam))
+				rp.Data, rp.NextCursor = []RuntimeDTO{runtime1}, "cursor-1"
+			case 2:
+				assert.Equal(t, "cursor-1", query.Get(pagination.CursorParam))
+				assert.Empty(t, query[pagination.PageParam], "the page must not be sent with the cursor")
+				rp.Data, rp.NextCursor = []RuntimeDTO{runtime2}, "cursor-2"
+			default:
+				assert.Equal(t, "cursor-2", query.Get(pagination.CursorParam))
+				rp.Data = []RuntimeDTO{runtime3}
+			}
+			err := json.NewEncoder(w).Encode(rp)
+			require.NoError(t, err)
+		}))
+		defer ts.Close()
+		client := NewClient(context.TODO(), ts.URL, fixToken)
+
+		// when
+		rp, err := client.ListRuntimes(ListParameters{})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, int32(3), atomic.LoadInt32(&called))
+		assert.Equal(t, 3, rp.Count)
+		assert.Equal(t, 3, rp.TotalCount)
+		require.Len(t, rp.Data, 3)
+		assert.Equal(t, runtime1.RuntimeID, rp.Data[0].RuntimeID)
+		assert.Equal(t, runtime2.RuntimeID, rp.Data[1].RuntimeID)
+		assert.Equal(t, runtime3.RuntimeID, rp.Data[2].RuntimeID)
+	})
+
+	t.Run("test error of any page fails the listing", func(t *testing.T) {
+		// given
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get(pagination.PageParam) == "3" {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			err := respondRuntimes(w, []RuntimeDTO{runtime1}, 4)
+			require.NoError(t, err)
+		}))
+		defer ts.Close()
+		client := NewClient(context.TODO(), ts.URL, fixToken)
+
+		// when
+		_, err := client.ListRuntimes(ListParameters{})
+
+		// then
+		assert.Error(t, err)
+	})
+}
+
+func TestClient_GetRuntime(t *testing.T) {
+	// given
+	runtime := fixRuntimeDTO("runtime1")
+	runtime.Access = &RuntimeAccess{
+		APIServerURL: "https://api.runtime1.kyma.local",
+		CABundle:     "ca-bundle",
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/runtimes/runtime1", r.URL.Path)
+		assert.Equal(t, r.Header.Get("Authorization"), fmt.Sprintf("Bearer %s", fixToken))
+
+		err := json.NewEncoder(w).Encode(runtime)
+		require.NoError(t, err)
+	}))
+	defer ts.Close()
+	client := NewClient(context.TODO(), ts.URL, fixToken)
+
+	// when
+	got, err := client.GetRuntime("runtime1")
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, runtime.RuntimeID, got.RuntimeID)
+	require.NotNil(t, got.Access)
+	assert.Equal(t, *runtime.Access, *got.Access)
 }
 
 func fixRuntimeDTO(id string) RuntimeDTO {
@@ -16,7 +16,46 @@ type RuntimeDTO struct {
 	ServiceClassName string        `json:"serviceClassName"`
 	ServicePlanID    string        `json:"servicePlanID"`
 	ServicePlanName  string        `json:"servicePlanName"`
+	UserID           string        `json:"userID,omitempty"`
+	KymaVersion      string        `json:"kymaVersion,omitempty"`
 	Status           RuntimeStatus `json:"status"`
+	// Access is returned only by the runtime details endpoint
+	Access *RuntimeAccess `json:"access,omitempty"`
+	// Parameters are returned only when requested with the params query parameter
+	Parameters *ProvisioningParameters `json:"parameters,omitempty"`
+	// ExpiresAt is returned only for the trial runtimes, which are deprovisioned when they expire
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// RuntimeAccess holds the data required to connect to the API server of the runtime
+type RuntimeAccess struct {
+	APIServerURL string `json:"apiServerURL"`
+	CABundle     string `json:"caBundle"`
+}
+
+// ProvisioningParameters holds the provisioning parameters of the runtime, the sensitive data such as
+// the hyperscaler subscription or the ERS context are never returned
+type ProvisioningParameters struct {
+	MachineType   *string  `json:"machineType,omitempty"`
+	Region        *string  `json:"region,omitempty"`
+	Zones         []string `json:"zones,omitempty"`
+	VolumeSizeGb  *int     `json:"volumeSizeGb,omitempty"`
+	AutoScalerMin *int     `json:"autoScalerMin,omitempty"`
+	AutoScalerMax *int     `json:"autoScalerMax,omitempty"`
+	KymaVersion   string   `json:"kymaVersion,omitempty"`
+	Provider      string   `json:"provider,omitempty"`
+	// OIDC is the OpenID Connect configuration of the kube-apiserver of the cluster set by the update of the runtime
+	OIDC *OIDCConfig `json:"oidc,omitempty"`
+}
+
+// OIDCConfig holds the OpenID Connect authentication of the kube-apiserver of the cluster
+type OIDCConfig struct {
+	ClientID       string   `json:"clientID"`
+	IssuerURL      string   `json:"issuerURL"`
+	GroupsClaim    string   `json:"groupsClaim,omitempty"`
+	SigningAlgs    []string `json:"signingAlgs,omitempty"`
+	UsernameClaim  string   `json:"usernameClaim,omitempty"`
+	UsernamePrefix string   `json:"usernamePrefix,omitempty"`
 }
 
 type RuntimeStatus struct {
@@ -25,6 +64,18 @@ type RuntimeStatus struct {
 	Provisioning   *Operation     `json:"provisioning"`
 	Deprovisioning *Operation     `json:"deprovisioning,omitempty"`
 	UpgradingKyma  OperationsData `json:"upgradingKyma,omitempty"`
+	// Update is the latest update of the instance parameters
+	Update *Operation `json:"update,omitempty"`
+	// Suspension is the latest suspension or resumption of the trial runtime
+	Suspension *SuspensionOperation `json:"suspension,omitempty"`
+	// Suspended is true if the cluster of the trial runtime is hibernated
+	Suspended bool `json:"suspended,omitempty"`
+}
+
+// SuspensionOperation is the operation which suspends (action suspend) or resumes (action resume) the runtime
+type SuspensionOperation struct {
+	Operation
+	Action string `json:"action"`
 }
 
 type OperationsData struct {
@@ -37,14 +88,68 @@ type Operation struct {
 	State           string    `json:"state"`
 	Description     string    `json:"description"`
 	CreatedAt       time.Time `json:"createdAt"`
+	UpdatedAt       time.Time `json:"updatedAt"`
 	OperationID     string    `json:"operationID"`
 	OrchestrationID *string   `json:"orchestrationID,omitempty"`
+	KymaVersion     string    `json:"kymaVersion,omitempty"`
 }
 
 type RuntimesPage struct {
 	Data       []RuntimeDTO `json:"data"`
 	Count      int          `json:"count"`
 	TotalCount int          `json:"totalCount"`
+	// NextCursor points to the next page of the runtimes, it is empty if the page is the last one
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// OrphanDTO describes the Gardener shoot of a runtime without the instance record, the confirmation token
+// must be passed to the cleanup request to trigger the deletion of the shoot
+type OrphanDTO struct {
+	ShootName         string    `json:"shootName"`
+	RuntimeID         string    `json:"runtimeID"`
+	GlobalAccountID   string    `json:"globalAccountID"`
+	SubAccountID      string    `json:"subAccountID"`
+	CreatedAt         time.Time `json:"createdAt"`
+	ConfirmationToken string    `json:"confirmationToken"`
+	TokenExpiresAt    time.Time `json:"tokenExpiresAt"`
+}
+
+type OrphanCleanupRequest struct {
+	ConfirmationToken string `json:"confirmationToken"`
+}
+
+type OrphanCleanupResponse struct {
+	ShootName              string `json:"shootName"`
+	RuntimeID              string `json:"runtimeID"`
+	ProvisionerOperationID string `json:"provisionerOperationID"`
+}
+
+// SuspensionResponse is returned when the suspension or resumption of the runtime is started
+type SuspensionResponse struct {
+	OperationID string `json:"operationID"`
+	Action      string `json:"action"`
+}
+
+// MigrationRequest moves the instance to another global account and subaccount
+type MigrationRequest struct {
+	GlobalAccountID string `json:"globalAccountID"`
+	SubAccountID    string `json:"subAccountID"`
+}
+
+// MigrationResponse describes the migration of the instance to another global account and subaccount, the tenant
+// is the global account the runtime is registered in by the Provisioner and the Director. The operation is not set
+// if the instance is already in the requested accounts.
+type MigrationResponse struct {
+	OperationID             string `json:"operationID,omitempty"`
+	State                   string `json:"state,omitempty"`
+	Description             string `json:"description,omitempty"`
+	InstanceID              string `json:"instanceID"`
+	RuntimeID               string `json:"runtimeID,omitempty"`
+	GlobalAccountID         string `json:"globalAccountID"`
+	SubAccountID            string `json:"subAccountID"`
+	PreviousGlobalAccountID string `json:"previousGlobalAccountID,omitempty"`
+	PreviousSubAccountID    string `json:"previousSubAccountID,omitempty"`
+	Tenant                  string `json:"tenant,omitempty"`
 }
 
 const (
@@ -54,15 +159,43 @@ const (
 	RuntimeIDParam       = "runtime_id"
 	RegionParam          = "region"
 	ShootParam           = "shoot"
+	PlanParam            = "plan"
+	CreatedAfterParam    = "created_after"
+	CreatedBeforeParam   = "created_before"
+	UpdatedAfterParam    = "updated_after"
+	UpdatedBeforeParam   = "updated_before"
+	StateParam           = "state"
+	ParamsParam          = "params"
+	FieldsParam          = "fields"
+	SearchParam          = "search"
 )
 
+// StateDeprovisioned selects the runtimes removed after the successful deprovisioning
+const StateDeprovisioned = "deprovisioned"
+
 type ListParameters struct {
-	Page             int
-	PageSize         int
+	Page     int
+	PageSize int
+	// Cursor selects the page following the page which returned the cursor, Page is not sent when set
+	Cursor           string
 	GlobalAccountIDs []string
 	SubAccountIDs    []string
 	InstanceIDs      []string
 	RuntimeIDs       []string
 	Regions          []string
 	Shoots           []string
+	Plans            []string
+	// the time range filters are in the RFC3339 format and are skipped when set to the zero value
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	UpdatedAfter  time.Time
+	UpdatedBefore time.Time
+	// State selects the deprovisioned runtimes when set to StateDeprovisioned, the existing runtimes are returned by default
+	State string
+	// WithParameters includes the provisioning parameters of the runtimes in the response
+	WithParameters bool
+	// Fields selects the fields of the runtimes returned in the response, all fields are returned if empty
+	Fields []string
+	// Search selects the runtimes whose instance ID, runtime ID, global account, subaccount, or shoot name contains the text
+	Search string
 }
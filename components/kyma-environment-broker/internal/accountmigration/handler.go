@@ -0,0 +1,105 @@
+package accountmigration
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/middleware"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+type Handler struct {
+	service *Service
+	log     logrus.FieldLogger
+}
+
+func NewHandler(service *Service, log logrus.FieldLogger) *Handler {
+	return &Handler{
+		service: service,
+		log:     log,
+	}
+}
+
+func (h *Handler) AttachRoutes(router *mux.Router) {
+	router.HandleFunc("/runtimes/{instance_id}/migrate", h.migrate).Methods(http.MethodPost)
+	router.HandleFunc("/runtimes/{instance_id}/migrate", h.getMigration).Methods(http.MethodGet)
+}
+
+// migrate starts the migration of the instance to the global account and the subaccount from the request body
+func (h *Handler) migrate(w http.ResponseWriter, r *http.Request) {
+	instanceID := mux.Vars(r)["instance_id"]
+
+	var request runtime.MigrationRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, errors.Wrap(err, "while decoding request body"))
+		return
+	}
+	if request.GlobalAccountID == "" || request.SubAccountID == "" {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, errors.New("globalAccountID and subAccountID must be set"))
+		return
+	}
+
+	correlationID, _ := middleware.CorrelationIDFromContext(r.Context())
+	operation, err := h.service.Migrate(instanceID, Target{
+		GlobalAccountID: request.GlobalAccountID,
+		SubAccountID:    request.SubAccountID,
+	}, correlationID)
+	if err != nil {
+		h.writeError(w, err, "while migrating instance")
+		return
+	}
+	if operation == nil {
+		httputil.WriteResponse(w, http.StatusOK, runtime.MigrationResponse{
+			InstanceID:      instanceID,
+			GlobalAccountID: request.GlobalAccountID,
+			SubAccountID:    request.SubAccountID,
+		})
+		return
+	}
+
+	httputil.WriteResponse(w, http.StatusAccepted, toResponse(*operation))
+}
+
+// getMigration returns the latest migration of the instance
+func (h *Handler) getMigration(w http.ResponseWriter, r *http.Request) {
+	operation, err := h.service.LastMigration(mux.Vars(r)["instance_id"])
+	if err != nil {
+		h.writeError(w, err, "while getting migration of instance")
+		return
+	}
+
+	httputil.WriteResponse(w, http.StatusOK, toResponse(operation))
+}
+
+func toResponse(operation internal.AccountMigrationOperation) runtime.MigrationResponse {
+	return runtime.MigrationResponse{
+		OperationID:             operation.ID,
+		State:                   string(operation.State),
+		Description:             operation.Description,
+		InstanceID:              operation.InstanceID,
+		RuntimeID:               operation.RuntimeID,
+		GlobalAccountID:         operation.TargetGlobalAccountID,
+		SubAccountID:            operation.TargetSubAccountID,
+		PreviousGlobalAccountID: operation.SourceGlobalAccountID,
+		PreviousSubAccountID:    operation.SourceSubAccountID,
+		Tenant:                  operation.Tenant,
+	}
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, err error, context string) {
+	switch err.(type) {
+	case NotFoundError:
+		httputil.WriteErrorResponse(w, http.StatusNotFound, err)
+	case ConflictError:
+		httputil.WriteErrorResponse(w, http.StatusConflict, err)
+	default:
+		h.log.Errorf("%s: %v", context, err)
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, context))
+	}
+}
@@ -0,0 +1,180 @@
+package accountmigration_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/accountmigration"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+
+	"github.com/gorilla/mux"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	t.Run("should start migration of instance to another global account and subaccount", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		require.NoError(t, db.Instances().Insert(fixInstance("instance")))
+		queue := &fakeQueue{}
+		router := fixRouter(db, queue)
+
+		// when
+		rr := serve(router, http.MethodPost, "/runtimes/instance/migrate", `{"globalAccountID":"new-ga","subAccountID":"new-sa"}`)
+
+		// then
+		require.Equal(t, http.StatusAccepted, rr.Code)
+		var response runtime.MigrationResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		require.NotEmpty(t, response.OperationID)
+		assert.Equal(t, runtime.MigrationResponse{
+			OperationID:             response.OperationID,
+			State:                   string(domain.InProgress),
+			Description:             "Operation created",
+			InstanceID:              "instance",
+			RuntimeID:               "runtime-instance",
+			GlobalAccountID:         "new-ga",
+			SubAccountID:            "new-sa",
+			PreviousGlobalAccountID: "ga",
+			PreviousSubAccountID:    "sa",
+			Tenant:                  "ga",
+		}, response)
+		assert.Equal(t, []string{response.OperationID}, queue.operationIDs)
+
+		operation, err := db.Operations().GetAccountMigrationOperationByID(response.OperationID)
+		require.NoError(t, err)
+		assert.Equal(t, "instance", operation.InstanceID)
+		assert.Equal(t, "shoot-instance", operation.ShootName)
+
+		// when
+		rr = serve(router, http.MethodPost, "/runtimes/instance/migrate", `{"globalAccountID":"new-ga","subAccountID":"new-sa"}`)
+
+		// then
+		require.Equal(t, http.StatusAccepted, rr.Code)
+		var repeated runtime.MigrationResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &repeated))
+		assert.Equal(t, response.OperationID, repeated.OperationID, "the migration in progress is returned")
+		assert.Len(t, queue.operationIDs, 1)
+
+		// when
+		rr = serve(router, http.MethodPost, "/runtimes/instance/migrate", `{"globalAccountID":"other-ga","subAccountID":"other-sa"}`)
+
+		// then
+		assert.Equal(t, http.StatusConflict, rr.Code, "the migration in progress blocks other migrations")
+
+		// when
+		rr = serve(router, http.MethodGet, "/runtimes/instance/migrate", "")
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+		var last runtime.MigrationResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &last))
+		assert.Equal(t, response.OperationID, last.OperationID)
+	})
+
+	t.Run("should not start migration of instance which is in target accounts", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		require.NoError(t, db.Instances().Insert(fixInstance("instance")))
+		queue := &fakeQueue{}
+		router := fixRouter(db, queue)
+
+		// when
+		rr := serve(router, http.MethodPost, "/runtimes/instance/migrate", `{"globalAccountID":"ga","subAccountID":"sa"}`)
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+		var response runtime.MigrationResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Empty(t, response.OperationID)
+		assert.Empty(t, queue.operationIDs)
+	})
+
+	t.Run("should reject migration of instance with operation in progress", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		require.NoError(t, db.Instances().Insert(fixInstance("instance")))
+		require.NoError(t, db.Operations().InsertProvisioningOperation(internal.ProvisioningOperation{
+			Operation: internal.Operation{ID: "provisioning", InstanceID: "instance", State: domain.InProgress},
+		}))
+		queue := &fakeQueue{}
+		router := fixRouter(db, queue)
+
+		// when
+		rr := serve(router, http.MethodPost, "/runtimes/instance/migrate", `{"globalAccountID":"new-ga","subAccountID":"new-sa"}`)
+
+		// then
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		assert.Empty(t, queue.operationIDs)
+	})
+
+	t.Run("should return not found for unknown instance", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		router := fixRouter(db, &fakeQueue{})
+
+		// when
+		rr := serve(router, http.MethodPost, "/runtimes/unknown/migrate", `{"globalAccountID":"new-ga","subAccountID":"new-sa"}`)
+
+		// then
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+
+		// when
+		rr = serve(router, http.MethodGet, "/runtimes/unknown/migrate", "")
+
+		// then
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("should reject request without subaccount", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		router := fixRouter(db, &fakeQueue{})
+
+		// when
+		rr := serve(router, http.MethodPost, "/runtimes/instance/migrate", `{"globalAccountID":"new-ga"}`)
+
+		// then
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func fixRouter(db storage.BrokerStorage, queue accountmigration.Queue) *mux.Router {
+	router := mux.NewRouter()
+	service := accountmigration.NewService(db.Instances(), db.Operations(), queue, logrus.New())
+	accountmigration.NewHandler(service, logrus.New()).AttachRoutes(router)
+	return router
+}
+
+func serve(router *mux.Router, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+func fixInstance(id string) internal.Instance {
+	return internal.Instance{
+		InstanceID:      id,
+		RuntimeID:       "runtime-" + id,
+		GlobalAccountID: "ga",
+		SubAccountID:    "sa",
+		ShootName:       "shoot-" + id,
+	}
+}
+
+type fakeQueue struct {
+	operationIDs []string
+}
+
+func (q *fakeQueue) Add(operationID string) {
+	q.operationIDs = append(q.operationIDs, operationID)
+}
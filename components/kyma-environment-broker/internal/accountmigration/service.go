@@ -0,0 +1,141 @@
+package accountmigration
+
+import (
+	"fmt"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
+
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Queue is the queue of the account migration process
+type Queue interface {
+	Add(operationID string)
+}
+
+// NotFoundError is returned if the instance or the migration does not exist
+type NotFoundError struct {
+	message string
+}
+
+func (e NotFoundError) Error() string {
+	return e.message
+}
+
+// ConflictError is returned if an operation of the instance is in progress
+type ConflictError struct {
+	message string
+}
+
+func (e ConflictError) Error() string {
+	return e.message
+}
+
+// Target is the global account and the subaccount the instance is moved to
+type Target struct {
+	GlobalAccountID string
+	SubAccountID    string
+}
+
+// Service starts the migration of the instance to another global account and subaccount. The migration is run
+// as the account migration operation, which changes the labels of the runtime in the Director and in Gardener,
+// and the instance, and reverts the applied changes if any of them fails, see the accountmigration process.
+// The other operations of the instance are rejected while the migration is in progress.
+// The runtime stays in the tenant of the global account the instance was provisioned in, because neither the Provisioner
+// nor the Director can move the runtime to another tenant. The tenant is kept in the instance, see internal.Instance.Tenant.
+type Service struct {
+	instances  storage.Instances
+	operations storage.Operations
+	queue      Queue
+	log        logrus.FieldLogger
+}
+
+func NewService(instances storage.Instances, operations storage.Operations, queue Queue, log logrus.FieldLogger) *Service {
+	return &Service{
+		instances:  instances,
+		operations: operations,
+		queue:      queue,
+		log:        log,
+	}
+}
+
+// Migrate starts the migration of the instance to the target global account and subaccount and returns
+// the migration operation. The migration in progress to the same accounts is returned instead of starting
+// a new one, and nil is returned if the instance is already in the target accounts.
+func (s *Service) Migrate(instanceID string, target Target, correlationID string) (*internal.AccountMigrationOperation, error) {
+	log := s.log.WithField("instanceID", instanceID)
+
+	instance, err := s.instances.GetByID(instanceID)
+	switch {
+	case dberr.IsNotFound(err):
+		return nil, NotFoundError{message: fmt.Sprintf("instance %s does not exist", instanceID)}
+	case err != nil:
+		return nil, errors.Wrap(err, "while getting instance")
+	}
+
+	latest, err := s.operations.GetAccountMigrationOperationByInstanceID(instanceID)
+	switch {
+	case dberr.IsNotFound(err):
+		latest = nil
+	case err != nil:
+		return nil, errors.Wrap(err, "while getting account migration operation")
+	}
+	if latest != nil && latest.State == domain.InProgress && latest.TargetGlobalAccountID == target.GlobalAccountID && latest.TargetSubAccountID == target.SubAccountID {
+		log.Infof("Account migration operation %s is already in progress", latest.ID)
+		return latest, nil
+	}
+	if instance.GlobalAccountID == target.GlobalAccountID && instance.SubAccountID == target.SubAccountID {
+		log.Infof("Instance is already in global account %s and subaccount %s", target.GlobalAccountID, target.SubAccountID)
+		return nil, nil
+	}
+
+	if err := s.checkOperations(instanceID); err != nil {
+		return nil, err
+	}
+
+	operation := internal.NewAccountMigrationOperation(*instance, target.GlobalAccountID, target.SubAccountID)
+	operation.CorrelationID = correlationID
+	if err := s.operations.InsertAccountMigrationOperation(operation); err != nil {
+		return nil, errors.Wrap(err, "while inserting account migration operation")
+	}
+
+	log.Infof("Starting account migration operation %s from global account %s and subaccount %s to global account %s and subaccount %s",
+		operation.ID, operation.SourceGlobalAccountID, operation.SourceSubAccountID, target.GlobalAccountID, target.SubAccountID)
+	s.queue.Add(operation.ID)
+
+	return &operation, nil
+}
+
+// LastMigration returns the latest migration operation of the instance
+func (s *Service) LastMigration(instanceID string) (internal.AccountMigrationOperation, error) {
+	operation, err := s.operations.GetAccountMigrationOperationByInstanceID(instanceID)
+	switch {
+	case dberr.IsNotFound(err):
+		return internal.AccountMigrationOperation{}, NotFoundError{message: fmt.Sprintf("instance %s was not migrated", instanceID)}
+	case err != nil:
+		return internal.AccountMigrationOperation{}, errors.Wrap(err, "while getting account migration operation")
+	}
+	return *operation, nil
+}
+
+// checkOperations returns the error if any operation of the instance is in progress, because the operations
+// read the accounts of the instance
+func (s *Service) checkOperations(instanceID string) error {
+	operations, err := s.operations.ListOperationsByInstanceID(instanceID)
+	switch {
+	case dberr.IsNotFound(err):
+		return nil
+	case err != nil:
+		return errors.Wrap(err, "while listing operations of the instance")
+	}
+	for _, op := range operations {
+		if op.State == domain.InProgress {
+			return ConflictError{message: fmt.Sprintf("operation %s of the instance is in progress", op.ID)}
+		}
+	}
+	return nil
+}
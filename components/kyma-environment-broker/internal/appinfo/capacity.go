@@ -0,0 +1,70 @@
+package appinfo
+
+import (
+	"net/http"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/httputil"
+)
+
+//go:generate mockery -name=CapacityStatsGetter -output=automock -outpkg=automock -case=underscore
+
+type CapacityStatsGetter interface {
+	GetCapacityStats() (internal.CapacityStats, error)
+}
+
+type CapacityInfoHandler struct {
+	statsGetter CapacityStatsGetter
+	respWriter  ResponseWriter
+}
+
+func NewCapacityInfoHandler(statsGetter CapacityStatsGetter, respWriter ResponseWriter) *CapacityInfoHandler {
+	return &CapacityInfoHandler{
+		statsGetter: statsGetter,
+		respWriter:  respWriter,
+	}
+}
+
+// ServeHTTP returns the number of instances and the maximum numbers of nodes and vCPUs requested by them
+// per provider region, broken down by the machine type
+//   GET /info/capacity
+func (h *CapacityInfoHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.statsGetter.GetCapacityStats()
+	if err != nil {
+		h.respWriter.InternalServerError(w, r, err, "while calculating capacity")
+		return
+	}
+
+	if err := httputil.JSONEncode(w, h.mapToDTO(stats)); err != nil {
+		h.respWriter.InternalServerError(w, r, err, "while encoding response to JSON")
+		return
+	}
+}
+
+// mapToDTO groups the entries by the region, the entries are already sorted by the provider and the region
+func (h *CapacityInfoHandler) mapToDTO(stats internal.CapacityStats) CapacityDTO {
+	dto := CapacityDTO{Regions: make([]RegionCapacityDTO, 0)}
+	for _, entry := range stats.Entries {
+		last := len(dto.Regions) - 1
+		if last < 0 || dto.Regions[last].Provider != entry.Provider || dto.Regions[last].Region != entry.Region {
+			dto.Regions = append(dto.Regions, RegionCapacityDTO{
+				Provider:     entry.Provider,
+				Region:       entry.Region,
+				MachineTypes: make([]MachineCapacityDTO, 0),
+			})
+			last++
+		}
+		region := &dto.Regions[last]
+		region.Instances += entry.Instances
+		region.MaxNodes += entry.MaxNodes
+		region.MaxVCPUs += entry.MaxVCPUs
+		region.MachineTypes = append(region.MachineTypes, MachineCapacityDTO{
+			MachineType: entry.MachineType,
+			Instances:   entry.Instances,
+			MaxNodes:    entry.MaxNodes,
+			MaxVCPUs:    entry.MaxVCPUs,
+		})
+	}
+
+	return dto
+}
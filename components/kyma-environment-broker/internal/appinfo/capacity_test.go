@@ -0,0 +1,80 @@
+package appinfo_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/appinfo"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/appinfo/automock"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapacityInfoHandler(t *testing.T) {
+	// given
+	statsGetter := &automock.CapacityStatsGetter{}
+	defer statsGetter.AssertExpectations(t)
+	statsGetter.On("GetCapacityStats").Return(internal.CapacityStats{Entries: []internal.CapacityEntry{
+		{Provider: "azure", Region: "westeurope", MachineType: "Standard_D4_v3", Instances: 1, MaxNodes: 4, MaxVCPUs: 16},
+		{Provider: "azure", Region: "westeurope", MachineType: "Standard_D8_v3", Instances: 2, MaxNodes: 20, MaxVCPUs: 160},
+		{Provider: "gcp", Region: "europe-west4", MachineType: "n1-standard-4", Instances: 1, MaxNodes: 4, MaxVCPUs: 16},
+	}}, nil)
+
+	var (
+		fixReq  = httptest.NewRequest("GET", "http://example.com/info/capacity", nil)
+		respSpy = httptest.NewRecorder()
+		writer  = httputil.NewResponseWriter(logger.NewLogDummy(), true)
+	)
+	handler := appinfo.NewCapacityInfoHandler(statsGetter, writer)
+
+	// when
+	handler.ServeHTTP(respSpy, fixReq)
+
+	// then
+	require.Equal(t, http.StatusOK, respSpy.Result().StatusCode)
+
+	var capacity appinfo.CapacityDTO
+	require.NoError(t, json.Unmarshal(respSpy.Body.Bytes(), &capacity))
+	assert.Equal(t, appinfo.CapacityDTO{Regions: []appinfo.RegionCapacityDTO{
+		{
+			Provider: "azure", Region: "westeurope", Instances: 3, MaxNodes: 24, MaxVCPUs: 176,
+			MachineTypes: []appinfo.MachineCapacityDTO{
+				{MachineType: "Standard_D4_v3", Instances: 1, MaxNodes: 4, MaxVCPUs: 16},
+				{MachineType: "Standard_D8_v3", Instances: 2, MaxNodes: 20, MaxVCPUs: 160},
+			},
+		},
+		{
+			Provider: "gcp", Region: "europe-west4", Instances: 1, MaxNodes: 4, MaxVCPUs: 16,
+			MachineTypes: []appinfo.MachineCapacityDTO{
+				{MachineType: "n1-standard-4", Instances: 1, MaxNodes: 4, MaxVCPUs: 16},
+			},
+		},
+	}}, capacity)
+}
+
+func TestCapacityInfoHandlerFailure(t *testing.T) {
+	// given
+	statsGetter := &automock.CapacityStatsGetter{}
+	defer statsGetter.AssertExpectations(t)
+	statsGetter.On("GetCapacityStats").Return(internal.CapacityStats{}, errors.New("ups.. internal info"))
+
+	var (
+		fixReq  = httptest.NewRequest("GET", "http://example.com/info/capacity", nil)
+		respSpy = httptest.NewRecorder()
+		writer  = httputil.NewResponseWriter(logger.NewLogDummy(), true)
+	)
+	handler := appinfo.NewCapacityInfoHandler(statsGetter, writer)
+
+	// when
+	handler.ServeHTTP(respSpy, fixReq)
+
+	// then
+	assert.Equal(t, http.StatusInternalServerError, respSpy.Result().StatusCode)
+}
@@ -29,3 +29,77 @@ type (
 		Description string `json:"description"`
 	}
 )
+
+type (
+	OperationStatsDTO struct {
+		Interval string                    `json:"interval"`
+		Window   string                    `json:"window"`
+		Buckets  []OperationStatsBucketDTO `json:"buckets"`
+		// Plans and Regions hold the current numbers of provisioning and deprovisioning operations
+		// per plan name and per provider region
+		Plans   map[string]OperationStatesDTO `json:"plans"`
+		Regions map[string]OperationStatesDTO `json:"regions"`
+	}
+
+	OperationStatsBucketDTO struct {
+		Start time.Time `json:"start"`
+		// Operations holds the numbers of operations per operation type (provision, deprovision, upgradeKyma)
+		Operations map[string]OperationCountsDTO `json:"operations"`
+	}
+
+	OperationCountsDTO struct {
+		Started   int `json:"started"`
+		Succeeded int `json:"succeeded"`
+		Failed    int `json:"failed"`
+	}
+
+	OperationStatesDTO struct {
+		Provisioning   OperationStateCountsDTO `json:"provisioning"`
+		Deprovisioning OperationStateCountsDTO `json:"deprovisioning"`
+	}
+
+	OperationStateCountsDTO struct {
+		InProgress int `json:"inProgress"`
+		Succeeded  int `json:"succeeded"`
+		Failed     int `json:"failed"`
+	}
+)
+
+type (
+	RegionsDTO struct {
+		Provider string `json:"provider"`
+		// AvailableCredentials holds the number of hyperscaler accounts which are not assigned to any tenant yet
+		AvailableCredentials int `json:"availableCredentials"`
+		// Regions are ranked from the least to the most loaded one
+		Regions []RegionDTO `json:"regions"`
+	}
+
+	RegionDTO struct {
+		Name                   string `json:"name"`
+		ProvisioningInProgress int    `json:"provisioningInProgress"`
+	}
+)
+
+type (
+	CapacityDTO struct {
+		Regions []RegionCapacityDTO `json:"regions"`
+	}
+
+	// RegionCapacityDTO holds the capacity requested by the instances in the region of the provider,
+	// the maximum numbers of nodes and vCPUs are the capacity the clusters can scale up to
+	RegionCapacityDTO struct {
+		Provider     string               `json:"provider"`
+		Region       string               `json:"region"`
+		Instances    int                  `json:"instances"`
+		MaxNodes     int                  `json:"maxNodes"`
+		MaxVCPUs     int                  `json:"maxVCPUs"`
+		MachineTypes []MachineCapacityDTO `json:"machineTypes"`
+	}
+
+	MachineCapacityDTO struct {
+		MachineType string `json:"machineType"`
+		Instances   int    `json:"instances"`
+		MaxNodes    int    `json:"maxNodes"`
+		MaxVCPUs    int    `json:"maxVCPUs"`
+	}
+)
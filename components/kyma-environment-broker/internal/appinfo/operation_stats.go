@@ -0,0 +1,172 @@
+package appinfo
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/httputil"
+
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultStatsInterval = time.Hour
+	defaultStatsWindow   = 24 * time.Hour
+	maxStatsBuckets      = 1000
+
+	// unknownPlan and defaultRegion replace the empty plan ID and region in the breakdown of the operations
+	unknownPlan   = "unknown"
+	defaultRegion = "default"
+)
+
+//go:generate mockery -name=OperationStatsGetter -output=automock -outpkg=automock -case=underscore
+
+type OperationStatsGetter interface {
+	GetOperationStats() (internal.OperationStats, error)
+	GetOperationTimeStats(from time.Time, window, interval time.Duration) (internal.OperationTimeStats, error)
+}
+
+type OperationStatsHandler struct {
+	statsGetter OperationStatsGetter
+	respWriter  ResponseWriter
+	now         func() time.Time
+}
+
+func NewOperationStatsHandler(statsGetter OperationStatsGetter, respWriter ResponseWriter) *OperationStatsHandler {
+	return &OperationStatsHandler{
+		statsGetter: statsGetter,
+		respWriter:  respWriter,
+		now:         time.Now,
+	}
+}
+
+// ServeHTTP returns numbers of operations started, succeeded and failed per operation type in the time buckets,
+// and the current numbers of provisioning and deprovisioning operations per state for every plan and region
+//   GET /info/operations/stats?interval=1h&window=24h
+func (h *OperationStatsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	interval, window, err := h.parseQuery(r)
+	if err != nil {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// the last bucket contains the current time, so the buckets are aligned to the interval
+	to := h.now().UTC().Truncate(interval).Add(interval)
+	stats, err := h.statsGetter.GetOperationTimeStats(to.Add(-window), window, interval)
+	if err != nil {
+		h.respWriter.InternalServerError(w, r, err, "while fetching operation stats")
+		return
+	}
+	current, err := h.statsGetter.GetOperationStats()
+	if err != nil {
+		h.respWriter.InternalServerError(w, r, err, "while fetching operation stats per plan and region")
+		return
+	}
+
+	if err := httputil.JSONEncode(w, h.mapToDTO(stats, current, window)); err != nil {
+		h.respWriter.InternalServerError(w, r, err, "while encoding response to JSON")
+		return
+	}
+}
+
+func (h *OperationStatsHandler) parseQuery(r *http.Request) (time.Duration, time.Duration, error) {
+	interval, err := durationParam(r, "interval", defaultStatsInterval)
+	if err != nil {
+		return 0, 0, err
+	}
+	window, err := durationParam(r, "window", defaultStatsWindow)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	switch {
+	case interval < time.Minute:
+		return 0, 0, errors.New("interval must be at least 1m")
+	case window < interval || window%interval != 0:
+		return 0, 0, errors.New("window must be a multiple of the interval")
+	case window/interval > maxStatsBuckets:
+		return 0, 0, errors.Errorf("window must not contain more than %d intervals", maxStatsBuckets)
+	}
+
+	return interval, window, nil
+}
+
+func durationParam(r *http.Request, name string, defaultValue time.Duration) (time.Duration, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, errors.Wrapf(err, "while parsing %s query parameter", name)
+	}
+	return duration, nil
+}
+
+func (h *OperationStatsHandler) mapToDTO(stats internal.OperationTimeStats, current internal.OperationStats, window time.Duration) OperationStatsDTO {
+	buckets := make([]OperationStatsBucketDTO, 0, len(stats.Buckets))
+	for _, bucket := range stats.Buckets {
+		operations := make(map[string]OperationCountsDTO, len(bucket.Operations))
+		for operationType, counts := range bucket.Operations {
+			operations[operationType] = OperationCountsDTO{
+				Started:   counts.Started,
+				Succeeded: counts.Succeeded,
+				Failed:    counts.Failed,
+			}
+		}
+		buckets = append(buckets, OperationStatsBucketDTO{
+			Start:      bucket.Start,
+			Operations: operations,
+		})
+	}
+
+	plans := make(map[string]OperationStatesDTO, len(current.PerPlan))
+	for planID, stateStats := range current.PerPlan {
+		plans[planName(planID)] = mapStatesToDTO(stateStats)
+	}
+	regions := make(map[string]OperationStatesDTO, len(current.PerRegion))
+	for region, stateStats := range current.PerRegion {
+		if region == "" {
+			region = defaultRegion
+		}
+		regions[region] = mapStatesToDTO(stateStats)
+	}
+
+	return OperationStatsDTO{
+		Interval: stats.Interval.String(),
+		Window:   window.String(),
+		Buckets:  buckets,
+		Plans:    plans,
+		Regions:  regions,
+	}
+}
+
+func mapStatesToDTO(stats internal.OperationStateStats) OperationStatesDTO {
+	return OperationStatesDTO{
+		Provisioning:   mapStateCountsToDTO(stats.Provisioning),
+		Deprovisioning: mapStateCountsToDTO(stats.Deprovisioning),
+	}
+}
+
+func mapStateCountsToDTO(counts map[domain.LastOperationState]int) OperationStateCountsDTO {
+	return OperationStateCountsDTO{
+		InProgress: counts[domain.InProgress],
+		Succeeded:  counts[domain.Succeeded],
+		Failed:     counts[domain.Failed],
+	}
+}
+
+// planName returns the name of the plan, the plan ID is returned for the plans not known by the broker
+func planName(planID string) string {
+	if planID == "" {
+		return unknownPlan
+	}
+	if plan, found := broker.Plans[planID]; found {
+		return plan.PlanDefinition.Name
+	}
+	return planID
+}
@@ -0,0 +1,137 @@
+package appinfo_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/appinfo"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/appinfo/automock"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/logger"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOperationStatsHandler(t *testing.T) {
+	// given
+	now := time.Now().UTC()
+	memStorage := storage.NewMemoryStorage()
+	require.NoError(t, memStorage.Operations().InsertProvisioningOperation(internal.ProvisioningOperation{
+		Operation: internal.Operation{ID: "op-1", State: domain.Succeeded, CreatedAt: now.Add(-2 * time.Hour), UpdatedAt: now},
+	}))
+	require.NoError(t, memStorage.Operations().InsertProvisioningOperation(internal.ProvisioningOperation{
+		Operation:              internal.Operation{ID: "op-2", State: domain.InProgress, CreatedAt: now, UpdatedAt: now},
+		ProvisioningParameters: fmt.Sprintf(`{"plan_id":"%s","parameters":{"region":"westeurope"}}`, broker.AzurePlanID),
+	}))
+	require.NoError(t, memStorage.Operations().InsertDeprovisioningOperation(internal.DeprovisioningOperation{
+		Operation: internal.Operation{ID: "op-3", State: domain.Failed, CreatedAt: now, UpdatedAt: now},
+	}))
+	require.NoError(t, memStorage.Operations().InsertProvisioningOperation(internal.ProvisioningOperation{
+		Operation: internal.Operation{ID: "op-4", State: domain.Succeeded, CreatedAt: now.Add(-48 * time.Hour), UpdatedAt: now.Add(-47 * time.Hour)},
+	}))
+
+	var (
+		fixReq  = httptest.NewRequest("GET", "http://example.com/info/operations/stats?interval=1h&window=3h", nil)
+		respSpy = httptest.NewRecorder()
+		writer  = httputil.NewResponseWriter(logger.NewLogDummy(), true)
+	)
+	handler := appinfo.NewOperationStatsHandler(memStorage.Operations(), writer)
+
+	// when
+	handler.ServeHTTP(respSpy, fixReq)
+
+	// then
+	require.Equal(t, http.StatusOK, respSpy.Result().StatusCode)
+
+	var stats appinfo.OperationStatsDTO
+	require.NoError(t, json.Unmarshal(respSpy.Body.Bytes(), &stats))
+	assert.Equal(t, "1h0m0s", stats.Interval)
+	assert.Equal(t, "3h0m0s", stats.Window)
+	require.Len(t, stats.Buckets, 3)
+
+	assert.Equal(t, now.Truncate(time.Hour).Add(-2*time.Hour), stats.Buckets[0].Start.UTC())
+	assert.Equal(t, map[string]appinfo.OperationCountsDTO{
+		"provision": {Started: 1},
+	}, stats.Buckets[0].Operations)
+	assert.Empty(t, stats.Buckets[1].Operations)
+	assert.Equal(t, map[string]appinfo.OperationCountsDTO{
+		"provision":   {Started: 1, Succeeded: 1},
+		"deprovision": {Started: 1, Failed: 1},
+	}, stats.Buckets[2].Operations)
+
+	assert.Equal(t, map[string]appinfo.OperationStatesDTO{
+		broker.AzurePlanName: {
+			Provisioning: appinfo.OperationStateCountsDTO{InProgress: 1},
+		},
+		"unknown": {
+			Provisioning:   appinfo.OperationStateCountsDTO{Succeeded: 2},
+			Deprovisioning: appinfo.OperationStateCountsDTO{Failed: 1},
+		},
+	}, stats.Plans)
+	assert.Equal(t, map[string]appinfo.OperationStatesDTO{
+		"westeurope": {
+			Provisioning: appinfo.OperationStateCountsDTO{InProgress: 1},
+		},
+		"default": {
+			Provisioning:   appinfo.OperationStateCountsDTO{Succeeded: 2},
+			Deprovisioning: appinfo.OperationStateCountsDTO{Failed: 1},
+		},
+	}, stats.Regions)
+}
+
+func TestOperationStatsHandlerInvalidQuery(t *testing.T) {
+	for name, query := range map[string]string{
+		"invalid interval":             "interval=abc",
+		"too short interval":           "interval=1s&window=1h",
+		"window not multiple":          "interval=1h&window=90m",
+		"too many buckets":             "interval=1m&window=720h",
+		"window shorter than interval": "interval=2h&window=1h",
+	} {
+		t.Run(name, func(t *testing.T) {
+			// given
+			var (
+				fixReq  = httptest.NewRequest("GET", "http://example.com/info/operations/stats?"+query, nil)
+				respSpy = httptest.NewRecorder()
+				writer  = httputil.NewResponseWriter(logger.NewLogDummy(), true)
+			)
+			handler := appinfo.NewOperationStatsHandler(&automock.OperationStatsGetter{}, writer)
+
+			// when
+			handler.ServeHTTP(respSpy, fixReq)
+
+			// then
+			assert.Equal(t, http.StatusBadRequest, respSpy.Result().StatusCode)
+		})
+	}
+}
+
+func TestOperationStatsHandlerFailure(t *testing.T) {
+	// given
+	var (
+		fixReq  = httptest.NewRequest("GET", "http://example.com/info/operations/stats", nil)
+		respSpy = httptest.NewRecorder()
+		writer  = httputil.NewResponseWriter(logger.NewLogDummy(), true)
+	)
+
+	statsGetter := &automock.OperationStatsGetter{}
+	defer statsGetter.AssertExpectations(t)
+	statsGetter.On("GetOperationTimeStats", mock.Anything, 24*time.Hour, time.Hour).Return(internal.OperationTimeStats{}, errors.New("ups.. internal info"))
+	handler := appinfo.NewOperationStatsHandler(statsGetter, writer)
+
+	// when
+	handler.ServeHTTP(respSpy, fixReq)
+
+	// then
+	assert.Equal(t, http.StatusInternalServerError, respSpy.Result().StatusCode)
+}
@@ -0,0 +1,140 @@
+package appinfo
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/common/hyperscaler"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession/dbmodel"
+
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/pkg/errors"
+)
+
+const defaultBacklogPageSize = 100
+
+//go:generate mockery -name=ProvisioningOperationLister -output=automock -outpkg=automock -case=underscore
+//go:generate mockery -name=CredentialsCounter -output=automock -outpkg=automock -case=underscore
+
+type (
+	ProvisioningOperationLister interface {
+		ListProvisioningOperations(filter dbmodel.OperationFilter, pageSize, page int) ([]internal.ProvisioningOperation, int, int, error)
+	}
+
+	CredentialsCounter interface {
+		CountFreeCredentials(hyperscalerType hyperscaler.Type) (int, error)
+	}
+)
+
+// regionsProvider describes the regions of the provider and the plans which use the hyperscaler account pool
+type regionsProvider struct {
+	hyperscalerType hyperscaler.Type
+	regions         []string
+	planIDs         []string
+}
+
+var regionsProviders = map[string]regionsProvider{
+	"azure": {
+		hyperscalerType: hyperscaler.Azure,
+		regions:         broker.AzureRegions(),
+		planIDs:         []string{broker.AzurePlanID},
+	},
+}
+
+type RegionsInfoHandler struct {
+	operations  ProvisioningOperationLister
+	credentials CredentialsCounter
+	respWriter  ResponseWriter
+}
+
+func NewRegionsInfoHandler(operations ProvisioningOperationLister, credentials CredentialsCounter, respWriter ResponseWriter) *RegionsInfoHandler {
+	return &RegionsInfoHandler{
+		operations:  operations,
+		credentials: credentials,
+		respWriter:  respWriter,
+	}
+}
+
+// ServeHTTP returns the regions of the provider ranked by the number of provisioning operations in progress,
+// together with the number of credentials available in the hyperscaler account pool
+//   GET /info/regions?provider=azure
+func (h *RegionsInfoHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	providerName := r.URL.Query().Get("provider")
+	provider, found := regionsProviders[providerName]
+	if !found {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, errors.Errorf("unsupported provider %q", providerName))
+		return
+	}
+
+	freeCredentials, err := h.credentials.CountFreeCredentials(provider.hyperscalerType)
+	if err != nil {
+		h.respWriter.InternalServerError(w, r, err, "while counting free credentials")
+		return
+	}
+
+	backlog, err := h.provisioningBacklog(provider)
+	if err != nil {
+		h.respWriter.InternalServerError(w, r, err, "while fetching provisioning operations in progress")
+		return
+	}
+
+	if err := httputil.JSONEncode(w, h.mapToDTO(providerName, provider, freeCredentials, backlog)); err != nil {
+		h.respWriter.InternalServerError(w, r, err, "while encoding response to JSON")
+		return
+	}
+}
+
+// provisioningBacklog returns the number of provisioning operations in progress per region
+func (h *RegionsInfoHandler) provisioningBacklog(provider regionsProvider) (map[string]int, error) {
+	filter := dbmodel.OperationFilter{
+		States:  []string{string(domain.InProgress)},
+		PlanIDs: provider.planIDs,
+	}
+	operations, count, totalCount, err := h.operations.ListProvisioningOperations(filter, defaultBacklogPageSize, 1)
+	if err != nil {
+		return nil, err
+	}
+	if count < totalCount {
+		operations, _, _, err = h.operations.ListProvisioningOperations(filter, totalCount, 1)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	backlog := make(map[string]int)
+	for _, op := range operations {
+		pp, err := op.GetProvisioningParameters()
+		if err != nil {
+			return nil, errors.Wrapf(err, "while getting provisioning parameters of operation %s", op.ID)
+		}
+		if pp.Parameters.Region == nil {
+			continue
+		}
+		backlog[*pp.Parameters.Region]++
+	}
+
+	return backlog, nil
+}
+
+func (h *RegionsInfoHandler) mapToDTO(providerName string, provider regionsProvider, freeCredentials int, backlog map[string]int) RegionsDTO {
+	regions := make([]RegionDTO, 0, len(provider.regions))
+	for _, region := range provider.regions {
+		regions = append(regions, RegionDTO{
+			Name:                   region,
+			ProvisioningInProgress: backlog[region],
+		})
+	}
+	// regions with the same backlog keep the order of the provider regions
+	sort.SliceStable(regions, func(i, j int) bool {
+		return regions[i].ProvisioningInProgress < regions[j].ProvisioningInProgress
+	})
+
+	return RegionsDTO{
+		Provider:             providerName,
+		AvailableCredentials: freeCredentials,
+		Regions:              regions,
+	}
+}
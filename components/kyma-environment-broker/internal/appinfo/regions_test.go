@@ -0,0 +1,117 @@
+package appinfo_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/common/hyperscaler"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/appinfo"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/appinfo/automock"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/logger"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/ptr"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegionsInfoHandler(t *testing.T) {
+	// given
+	memStorage := storage.NewMemoryStorage()
+	for id, op := range map[string]struct {
+		planID string
+		region string
+		state  domain.LastOperationState
+	}{
+		"op-1": {planID: broker.AzurePlanID, region: "westeurope", state: domain.InProgress},
+		"op-2": {planID: broker.AzurePlanID, region: "westeurope", state: domain.InProgress},
+		"op-3": {planID: broker.AzurePlanID, region: "eastus", state: domain.InProgress},
+		"op-4": {planID: broker.AzurePlanID, region: "centralus", state: domain.Succeeded},
+		"op-5": {planID: broker.GCPPlanID, region: "centralus", state: domain.InProgress},
+	} {
+		operation := internal.ProvisioningOperation{
+			Operation: internal.Operation{ID: id, State: op.state, CreatedAt: time.Now()},
+		}
+		require.NoError(t, operation.SetProvisioningParameters(internal.ProvisioningParameters{
+			PlanID:     op.planID,
+			Parameters: internal.ProvisioningParametersDTO{Region: ptr.String(op.region)},
+		}))
+		require.NoError(t, memStorage.Operations().InsertProvisioningOperation(operation))
+	}
+
+	credentials := &automock.CredentialsCounter{}
+	defer credentials.AssertExpectations(t)
+	credentials.On("CountFreeCredentials", hyperscaler.Azure).Return(3, nil)
+
+	var (
+		fixReq  = httptest.NewRequest("GET", "http://example.com/info/regions?provider=azure", nil)
+		respSpy = httptest.NewRecorder()
+		writer  = httputil.NewResponseWriter(logger.NewLogDummy(), true)
+	)
+	handler := appinfo.NewRegionsInfoHandler(memStorage.Operations(), credentials, writer)
+
+	// when
+	handler.ServeHTTP(respSpy, fixReq)
+
+	// then
+	require.Equal(t, http.StatusOK, respSpy.Result().StatusCode)
+
+	var regions appinfo.RegionsDTO
+	require.NoError(t, json.Unmarshal(respSpy.Body.Bytes(), &regions))
+	assert.Equal(t, "azure", regions.Provider)
+	assert.Equal(t, 3, regions.AvailableCredentials)
+	require.Len(t, regions.Regions, len(broker.AzureRegions()))
+
+	assert.Equal(t, appinfo.RegionDTO{Name: "centralus"}, regions.Regions[0])
+	assert.Equal(t, appinfo.RegionDTO{Name: "eastus", ProvisioningInProgress: 1}, regions.Regions[len(regions.Regions)-2])
+	assert.Equal(t, appinfo.RegionDTO{Name: "westeurope", ProvisioningInProgress: 2}, regions.Regions[len(regions.Regions)-1])
+}
+
+func TestRegionsInfoHandlerUnsupportedProvider(t *testing.T) {
+	// given
+	var (
+		fixReq  = httptest.NewRequest("GET", "http://example.com/info/regions?provider=openstack", nil)
+		respSpy = httptest.NewRecorder()
+		writer  = httputil.NewResponseWriter(logger.NewLogDummy(), true)
+	)
+	handler := appinfo.NewRegionsInfoHandler(&automock.ProvisioningOperationLister{}, &automock.CredentialsCounter{}, writer)
+
+	// when
+	handler.ServeHTTP(respSpy, fixReq)
+
+	// then
+	assert.Equal(t, http.StatusBadRequest, respSpy.Result().StatusCode)
+}
+
+func TestRegionsInfoHandlerFailure(t *testing.T) {
+	// given
+	var (
+		fixReq  = httptest.NewRequest("GET", "http://example.com/info/regions?provider=azure", nil)
+		respSpy = httptest.NewRecorder()
+		writer  = httputil.NewResponseWriter(logger.NewLogDummy(), true)
+	)
+
+	credentials := &automock.CredentialsCounter{}
+	defer credentials.AssertExpectations(t)
+	credentials.On("CountFreeCredentials", hyperscaler.Azure).Return(1, nil)
+
+	operations := &automock.ProvisioningOperationLister{}
+	defer operations.AssertExpectations(t)
+	operations.On("ListProvisioningOperations", mock.Anything, mock.Anything, 1).Return(nil, 0, 0, errors.New("ups.. internal info"))
+	handler := appinfo.NewRegionsInfoHandler(operations, credentials, writer)
+
+	// when
+	handler.ServeHTTP(respSpy, fixReq)
+
+	// then
+	assert.Equal(t, http.StatusInternalServerError, respSpy.Result().StatusCode)
+}
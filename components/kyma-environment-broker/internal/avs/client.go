@@ -11,6 +11,7 @@ import (
 	"strings"
 
 	kebError "github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/error"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/tracing"
 
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
@@ -33,6 +34,14 @@ func NewClient(ctx context.Context, avsConfig Config, log logrus.FieldLogger) (*
 	}, nil
 }
 
+// WithContext returns the copy of the client which sends the requests with the given context, so the requests
+// are cancelled when the context is done and are recorded in the trace of the context
+func (c *Client) WithContext(ctx context.Context) *Client {
+	withContext := *c
+	withContext.ctx = ctx
+	return &withContext
+}
+
 func (c *Client) CreateEvaluation(evaluationRequest *BasicEvaluationCreateRequest) (_ *BasicEvaluationCreateResponse, err error) {
 	var responseObject BasicEvaluationCreateResponse
 
@@ -65,6 +74,33 @@ func (c *Client) CreateEvaluation(evaluationRequest *BasicEvaluationCreateReques
 	return &responseObject, nil
 }
 
+// GetEvaluation fetches the evaluation with the given ID, the returned status tells if the evaluation is active
+func (c *Client) GetEvaluation(evaluationId int64) (_ *BasicEvaluationCreateResponse, err error) {
+	var responseObject BasicEvaluationCreateResponse
+
+	request, err := http.NewRequest(http.MethodGet, appendId(c.avsConfig.ApiEndpoint, evaluationId), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "while creating request")
+	}
+
+	response, err := c.execute(request, false, true)
+	if err != nil {
+		return nil, errors.Wrap(err, "while executing GetEvaluation request")
+	}
+	defer func() {
+		if closeErr := c.closeResponseBody(response); closeErr != nil {
+			err = kebError.AsTemporaryError(closeErr, "while closing GetEvaluation response")
+		}
+	}()
+
+	err = json.NewDecoder(response.Body).Decode(&responseObject)
+	if err != nil {
+		return nil, errors.Wrap(err, "while decode get evaluation response")
+	}
+
+	return &responseObject, nil
+}
+
 func (c *Client) RemoveReferenceFromParentEval(evaluationId int64) (err error) {
 	absoluteURL := fmt.Sprintf("%s/child/%d", appendId(c.avsConfig.ApiEndpoint, c.avsConfig.ParentId), evaluationId)
 	response, err := c.deleteRequest(absoluteURL)
@@ -129,13 +165,18 @@ func (c *Client) deleteRequest(absoluteURL string) (*http.Response, error) {
 	return response, nil
 }
 
-func (c *Client) execute(request *http.Request, allowNotFound bool, allowResetToken bool) (*http.Response, error) {
+func (c *Client) execute(request *http.Request, allowNotFound bool, allowResetToken bool) (response *http.Response, err error) {
+	ctx, span := tracing.StartSpan(c.ctx, fmt.Sprintf("avs/%s", request.Method))
+	defer func() {
+		tracing.End(ctx, span, err)
+	}()
+
 	httpClient, err := getHttpClient(c.ctx, c.avsConfig)
 	if err != nil {
 		return &http.Response{}, errors.Wrap(err, "while getting http client")
 	}
 	defer httpClient.CloseIdleConnections()
-	response, err := httpClient.Do(request)
+	response, err = httpClient.Do(request.WithContext(ctx))
 	if err != nil {
 		return &http.Response{}, kebError.AsTemporaryError(err, "while executing request by http client")
 	}
@@ -1,6 +1,7 @@
 package avs
 
 import (
+	"context"
 	"time"
 
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
@@ -42,6 +43,13 @@ func NewDelegator(client *Client, avsConfig Config, operationsStorage storage.Op
 	}
 }
 
+// WithContext returns the copy of the delegator which calls AVS with the given context
+func (del *Delegator) WithContext(ctx context.Context) *Delegator {
+	withContext := *del
+	withContext.client = del.client.WithContext(ctx)
+	return &withContext
+}
+
 func (del *Delegator) CreateEvaluation(logger logrus.FieldLogger, operation internal.ProvisioningOperation, evalAssistant EvalAssistant, url string) (internal.ProvisioningOperation, time.Duration, error) {
 	logger.Infof("starting the step avs internal id [%d] and avs external id [%d]", operation.Avs.AvsEvaluationInternalId, operation.Avs.AVSEvaluationExternalId)
 
@@ -81,23 +89,31 @@ func (del *Delegator) CreateEvaluation(logger logrus.FieldLogger, operation inte
 	return updatedOperation, d, nil
 }
 
+// DeleteAvsEvaluation deletes the evaluation and marks it as deleted. The deletion is stored on top of the latest
+// version of the operation, so the concurrent updates of the other deprovisioning steps are not lost and the evaluation
+// is not deleted again after the restart. Only the AVS lifecycle data of the returned operation is updated.
 func (del *Delegator) DeleteAvsEvaluation(deProvisioningOperation internal.DeprovisioningOperation, logger logrus.FieldLogger, assistant EvalAssistant) (internal.DeprovisioningOperation, error) {
 	if assistant.IsAlreadyDeleted(deProvisioningOperation.Avs) {
 		logger.Infof("Evaluations have been deleted previously")
 		return deProvisioningOperation, nil
 	}
 
-	if err := del.tryDeleting(assistant, deProvisioningOperation.Avs, logger); err != nil {
+	// the evaluation was never created, there is nothing to delete in AVS
+	if assistant.GetEvaluationId(deProvisioningOperation.Avs) == 0 {
+		logger.Infof("Evaluation does not exist, skipping")
+	} else if err := del.tryDeleting(assistant, deProvisioningOperation.Avs, logger); err != nil {
 		return deProvisioningOperation, err
 	}
 
-	assistant.markDeleted(&deProvisioningOperation.Avs)
-
-	updatedDeProvisioningOp, err := del.operationsStorage.UpdateDeprovisioningOperation(deProvisioningOperation)
+	updated, err := storage.UpdateWithRetryDeprovisioningOperation(del.operationsStorage, deProvisioningOperation.ID, func(latest *internal.DeprovisioningOperation) {
+		assistant.markDeleted(&latest.Avs)
+	})
 	if err != nil {
 		return deProvisioningOperation, err
 	}
-	return *updatedDeProvisioningOp, nil
+	deProvisioningOperation.Avs = updated.Avs
+
+	return deProvisioningOperation, nil
 }
 
 func (del *Delegator) tryDeleting(assistant EvalAssistant, lifecycleData internal.AvsLifecycleData, logger logrus.FieldLogger) error {
@@ -0,0 +1,147 @@
+package avs
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+
+	"github.com/gorilla/mux"
+	"golang.org/x/oauth2"
+)
+
+const (
+	fakeServerAccessToken = "fake-access-token"
+	fakeServerTokenPath   = "/oauth/token"
+	fakeServerAPIPath     = "/api/v2/evaluationmetadata"
+)
+
+// FakeServer is an in-process AVS server which stores the evaluations in memory,
+// it allows to run the broker without the AVS
+type FakeServer struct {
+	mu          sync.Mutex
+	server      *httptest.Server
+	nextID      int64
+	evaluations map[int64]BasicEvaluationCreateRequest
+}
+
+// NewFakeServer starts the fake AVS server, the server must be closed by the caller
+func NewFakeServer() *FakeServer {
+	s := &FakeServer{
+		nextID:      1,
+		evaluations: make(map[int64]BasicEvaluationCreateRequest),
+	}
+
+	r := mux.NewRouter()
+	r.HandleFunc(fakeServerTokenPath, s.token).Methods(http.MethodPost)
+	r.HandleFunc(fakeServerAPIPath, s.createEvaluation).Methods(http.MethodPost)
+	r.HandleFunc(fakeServerAPIPath+"/{evalId}", s.getEvaluation).Methods(http.MethodGet)
+	r.HandleFunc(fakeServerAPIPath+"/{evalId}", s.deleteEvaluation).Methods(http.MethodDelete)
+	r.HandleFunc(fakeServerAPIPath+"/{parentId}/child/{evalId}", s.removeReferenceFromParentEval).Methods(http.MethodDelete)
+	s.server = httptest.NewServer(r)
+
+	return s
+}
+
+// Configure points the endpoints of the given configuration to the fake server
+func (s *FakeServer) Configure(cfg *Config) {
+	cfg.OauthTokenEndpoint = s.server.URL + fakeServerTokenPath
+	cfg.ApiEndpoint = s.server.URL + fakeServerAPIPath
+}
+
+func (s *FakeServer) Close() {
+	s.server.Close()
+}
+
+// Evaluations returns the number of existing evaluations
+func (s *FakeServer) Evaluations() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.evaluations)
+}
+
+func (s *FakeServer) token(w http.ResponseWriter, _ *http.Request) {
+	writeFakeServerResponse(w, oauth2.Token{
+		AccessToken: fakeServerAccessToken,
+		TokenType:   "Bearer",
+	})
+}
+
+func (s *FakeServer) createEvaluation(w http.ResponseWriter, r *http.Request) {
+	var request BasicEvaluationCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	id := s.nextID
+	s.nextID++
+	s.evaluations[id] = request
+	s.mu.Unlock()
+
+	writeFakeServerResponse(w, BasicEvaluationCreateResponse{
+		Id:          id,
+		Name:        request.Name,
+		Description: request.Description,
+		URL:         request.URL,
+		Status:      StatusActive,
+	})
+}
+
+func (s *FakeServer) getEvaluation(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["evalId"], 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	request, found := s.evaluations[id]
+	s.mu.Unlock()
+	if !found {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	writeFakeServerResponse(w, BasicEvaluationCreateResponse{
+		Id:          id,
+		Name:        request.Name,
+		Description: request.Description,
+		URL:         request.URL,
+		Status:      StatusActive,
+	})
+}
+
+func (s *FakeServer) deleteEvaluation(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["evalId"], 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, found := s.evaluations[id]; !found {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	delete(s.evaluations, id)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *FakeServer) removeReferenceFromParentEval(w http.ResponseWriter, _ *http.Request) {
+	// the parent evaluation is not tracked by the fake server
+	w.WriteHeader(http.StatusOK)
+}
+
+func writeFakeServerResponse(w http.ResponseWriter, response interface{}) {
+	body, err := json.Marshal(response)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body)
+}
@@ -0,0 +1,49 @@
+package avs
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeServer(t *testing.T) {
+	// given
+	server := NewFakeServer()
+	defer server.Close()
+
+	cfg := Config{ParentId: parentEvaluationID}
+	server.Configure(&cfg)
+	client, err := NewClient(context.TODO(), cfg, logrus.New())
+	require.NoError(t, err)
+
+	// when
+	response, err := client.CreateEvaluation(&BasicEvaluationCreateRequest{
+		Name:     "test_evaluation",
+		ParentId: parentEvaluationID,
+	})
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, "test_evaluation", response.Name)
+	assert.Equal(t, 1, server.Evaluations())
+
+	// when
+	evaluation, err := client.GetEvaluation(response.Id)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, "test_evaluation", evaluation.Name)
+	assert.Equal(t, StatusActive, evaluation.Status)
+
+	// when
+	err = client.RemoveReferenceFromParentEval(response.Id)
+	require.NoError(t, err)
+	err = client.DeleteEvaluation(response.Id)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, 0, server.Evaluations())
+}
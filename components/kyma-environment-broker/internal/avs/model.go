@@ -14,6 +14,9 @@ const (
 	contentCheckType = "NOT_CONTAINS"
 	threshold        = "30000"
 	visibility       = "PUBLIC"
+
+	// StatusActive is the status of the evaluation which is monitored
+	StatusActive = "ACTIVE"
 )
 
 type BasicEvaluationCreateRequest struct {
@@ -34,6 +34,7 @@ type KymaEnvironmentBroker struct {
 // Config represents configuration for broker
 type Config struct {
 	EnablePlans EnablePlans `envconfig:"default=azure"`
+	Catalog     CatalogConfig
 }
 
 // EnablePlans defines the plans that should be available for provisioning
@@ -0,0 +1,114 @@
+package broker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// CatalogConfig configures the external plan configuration service the service catalog is fetched from
+type CatalogConfig struct {
+	// URL of the catalog in the OSB format, the built-in catalog is used if the URL is not set
+	URL             string        `envconfig:"optional"`
+	RefreshInterval time.Duration `envconfig:"default=5m"`
+}
+
+// CatalogSource provides the service catalog which overrides the built-in one,
+// false is returned if the catalog is not available
+type CatalogSource interface {
+	Services() ([]domain.Service, bool)
+}
+
+type catalogResponse struct {
+	Services []domain.Service `json:"services"`
+}
+
+// RemoteCatalog periodically fetches the service catalog from the plan configuration service. The ETag of the last
+// response is sent in the If-None-Match header, so the catalog is transferred and decoded only if it changed.
+// The last fetched catalog is served when the service is not available.
+type RemoteCatalog struct {
+	url             string
+	refreshInterval time.Duration
+	httpClient      *http.Client
+	log             logrus.FieldLogger
+
+	mu       sync.RWMutex
+	etag     string
+	services []domain.Service
+	fetched  bool
+}
+
+func NewRemoteCatalog(cfg CatalogConfig, httpClient *http.Client, log logrus.FieldLogger) *RemoteCatalog {
+	return &RemoteCatalog{
+		url:             cfg.URL,
+		refreshInterval: cfg.RefreshInterval,
+		httpClient:      httpClient,
+		log:             log.WithField("service", "RemoteCatalog"),
+	}
+}
+
+// Run refreshes the catalog every configured interval until the context is done
+func (c *RemoteCatalog) Run(ctx context.Context) {
+	go wait.Until(func() {
+		if err := c.Refresh(); err != nil {
+			c.log.Errorf("while refreshing the service catalog: %s", err)
+		}
+	}, c.refreshInterval, ctx.Done())
+}
+
+// Refresh fetches the catalog if it was changed since the last refresh
+func (c *RemoteCatalog) Refresh() error {
+	req, err := http.NewRequest(http.MethodGet, c.url, nil)
+	if err != nil {
+		return errors.Wrap(err, "while creating request")
+	}
+	c.mu.RLock()
+	if c.etag != "" {
+		req.Header.Set("If-None-Match", c.etag)
+	}
+	c.mu.RUnlock()
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "while calling %s", c.url)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return nil
+	case http.StatusOK:
+	default:
+		return fmt.Errorf("unexpected status code %d returned from %s", resp.StatusCode, c.url)
+	}
+
+	var catalog catalogResponse
+	if err := json.NewDecoder(resp.Body).Decode(&catalog); err != nil {
+		return errors.Wrap(err, "while decoding the catalog")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.services = catalog.Services
+	c.etag = resp.Header.Get("ETag")
+	c.fetched = true
+	c.log.Infof("Service catalog refreshed, ETag: %q", c.etag)
+
+	return nil
+}
+
+// Services returns the last fetched catalog
+func (c *RemoteCatalog) Services() ([]domain.Service, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.services, c.fetched
+}
@@ -0,0 +1,112 @@
+package broker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const catalogETag = `"catalog-v1"`
+
+func TestRemoteCatalog_Refresh(t *testing.T) {
+	// given
+	var served int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == catalogETag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		served++
+		w.Header().Set("ETag", catalogETag)
+		_, err := w.Write([]byte(`{"services": [{"id": "service-id", "name": "kymaruntime", "plans": [{"id": "` + AzurePlanID + `", "name": "azure"}]}]}`))
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	catalog := NewRemoteCatalog(CatalogConfig{URL: server.URL}, server.Client(), logrus.New())
+
+	// when
+	_, fetched := catalog.Services()
+
+	// then
+	assert.False(t, fetched)
+
+	// when
+	err := catalog.Refresh()
+	require.NoError(t, err)
+	err = catalog.Refresh()
+	require.NoError(t, err)
+
+	// then
+	services, fetched := catalog.Services()
+	assert.True(t, fetched)
+	assert.Equal(t, 1, served)
+	require.Len(t, services, 1)
+	assert.Equal(t, "kymaruntime", services[0].Name)
+}
+
+func TestServices_ExternalCatalog(t *testing.T) {
+	// given
+	catalog := fixedCatalog{
+		{
+			ID:          KymaServiceID,
+			Name:        "changed-name",
+			Description: "changed service description",
+			Tags:        []string{"changed"},
+			Plans: []domain.ServicePlan{
+				{
+					ID:          AzurePlanID,
+					Name:        "changed-plan-name",
+					Description: "changed description",
+					Metadata:    &domain.ServicePlanMetadata{DisplayName: "Azure (changed)"},
+					Schemas:     &domain.ServiceSchemas{},
+				},
+				{ID: GCPPlanID, Name: "gcp"},
+				{ID: "unknown-plan-id", Name: "unknown"},
+			},
+		},
+		{ID: "unknown-service-id", Name: "unknown"},
+	}
+	schemas, err := NewPlansSchemas(fixedComponents{"kiali"})
+	require.NoError(t, err)
+	endpoint := NewServices(Config{EnablePlans: []string{"azure"}}, schemas, catalog, logrus.New())
+
+	// when
+	services, err := endpoint.Services(context.TODO())
+
+	// then
+	require.NoError(t, err)
+	require.Len(t, services, 1)
+	assert.Equal(t, KymaServiceName, services[0].Name)
+	assert.Equal(t, "changed service description", services[0].Description)
+	assert.Equal(t, []string{"changed"}, services[0].Tags)
+
+	require.Len(t, services[0].Plans, 1)
+	plan := services[0].Plans[0]
+	assert.Equal(t, AzurePlanName, plan.Name)
+	assert.Equal(t, "changed description", plan.Description)
+	assert.Equal(t, "Azure (changed)", plan.Metadata.DisplayName)
+
+	expectedSchemas, err := schemas.ServiceSchemas(AzurePlanID)
+	require.NoError(t, err)
+	assert.Equal(t, expectedSchemas, plan.Schemas, "the schemas used by the validation must be served")
+	assert.Len(t, catalog[0].Plans, 3)
+}
+
+type fixedCatalog []domain.Service
+
+func (c fixedCatalog) Services() ([]domain.Service, bool) {
+	return c, true
+}
+
+type fixedComponents []string
+
+func (c fixedComponents) GetAllOptionalComponentsNames() []string {
+	return c
+}
@@ -5,22 +5,29 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	kebError "github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/error"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/middleware"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/ptr"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/tracing"
 
 	"github.com/google/uuid"
 	"github.com/pivotal-cf/brokerapi/v7/domain"
 	"github.com/pivotal-cf/brokerapi/v7/domain/apiresponses"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 //go:generate mockery -name=Queue -output=automock -outpkg=automock -case=underscore
 //go:generate mockery -name=PlanValidator -output=automock -outpkg=automock -case=underscore
+//go:generate mockery -name=SubscriptionSecrets -output=automock -outpkg=automock -case=underscore
 
 type (
 	Queue interface {
@@ -30,6 +37,24 @@ type (
 	PlanValidator interface {
 		IsPlanSupport(planID string) bool
 	}
+
+	// SubscriptionSecrets manages secrets of the customer-provided hyperscaler subscriptions (BYO subscriptions)
+	SubscriptionSecrets interface {
+		Attach(planID, tenantName, secretName string) error
+		Store(planID, tenantName, secretName string, credentials map[string]string) error
+	}
+
+	// FreeTier tracks the lifetime of the instances of the free plans and enforces the usage limit per global account
+	FreeTier interface {
+		CheckLimit(globalAccountID string) error
+		Start(instanceID, globalAccountID, planID string) error
+	}
+
+	// KillSwitches rejects the new provisioning of the plans and in the regions disabled by the operators,
+	// for example during the hyperscaler incidents
+	KillSwitches interface {
+		ProvisioningDisabled(parameters internal.ProvisioningParameters) (string, bool, error)
+	}
 )
 
 type ProvisionEndpoint struct {
@@ -39,12 +64,15 @@ type ProvisionEndpoint struct {
 	builderFactory       PlanValidator
 	enabledPlanIDs       map[string]struct{}
 	plansSchemaValidator PlansSchemaValidator
+	subscriptions        SubscriptionSecrets
+	freeTier             FreeTier
+	killSwitches         KillSwitches
 	kymaVerOnDemand      bool
 
 	log logrus.FieldLogger
 }
 
-func NewProvision(cfg Config, operationsStorage storage.Operations, instanceStorage storage.Instances, q Queue, builderFactory PlanValidator, validator PlansSchemaValidator, kvod bool, log logrus.FieldLogger) *ProvisionEndpoint {
+func NewProvision(cfg Config, operationsStorage storage.Operations, instanceStorage storage.Instances, q Queue, builderFactory PlanValidator, validator PlansSchemaValidator, subscriptions SubscriptionSecrets, freeTier FreeTier, killSwitches KillSwitches, kvod bool, log logrus.FieldLogger) *ProvisionEndpoint {
 	enabledPlanIDs := map[string]struct{}{}
 	for _, planName := range cfg.EnablePlans {
 		id := planIDsMapping[planName]
@@ -53,6 +81,9 @@ func NewProvision(cfg Config, operationsStorage storage.Operations, instanceStor
 
 	return &ProvisionEndpoint{
 		plansSchemaValidator: validator,
+		subscriptions:        subscriptions,
+		freeTier:             freeTier,
+		killSwitches:         killSwitches,
 		operationsStorage:    operationsStorage,
 		instanceStorage:      instanceStorage,
 		queue:                q,
@@ -67,7 +98,8 @@ func NewProvision(cfg Config, operationsStorage storage.Operations, instanceStor
 //   PUT /v2/service_instances/{instance_id}
 func (b *ProvisionEndpoint) Provision(ctx context.Context, instanceID string, details domain.ProvisionDetails, asyncAllowed bool) (domain.ProvisionedServiceSpec, error) {
 	operationID := uuid.New().String()
-	logger := b.log.WithFields(logrus.Fields{"instanceID": instanceID, "operationID": operationID, "planID": details.PlanID})
+	correlationID, _ := middleware.CorrelationIDFromContext(ctx)
+	logger := b.log.WithFields(logrus.Fields{"instanceID": instanceID, "operationID": operationID, "planID": details.PlanID, "correlationID": correlationID})
 	logger.Info("Provision called")
 	// validation of incoming input
 	ersContext, parameters, err := b.validateAndExtract(details, logger)
@@ -75,6 +107,8 @@ func (b *ProvisionEndpoint) Provision(ctx context.Context, instanceID string, de
 		errMsg := fmt.Sprintf("[instanceID: %s] %s", instanceID, err)
 		return domain.ProvisionedServiceSpec{}, apiresponses.NewFailureResponse(err, http.StatusBadRequest, errMsg)
 	}
+	// the credentials payload must be neither logged nor stored with the provisioning parameters
+	subscriptionCredentials := extractSubscriptionCredentials(instanceID, &parameters)
 
 	region, found := middleware.RegionFromContext(ctx)
 	if !found {
@@ -90,8 +124,10 @@ func (b *ProvisionEndpoint) Provision(ctx context.Context, instanceID string, de
 		PlatformRegion: region,
 	}
 
-	logger.Infof("Starting provisioning runtime: Name=%s, GlobalAccountID=%s, SubAccountID=%s PlatformRegion=%s", parameters.Name, ersContext.GlobalAccountID, ersContext.SubAccountID, region)
-	logger.Infof("Runtime parameters: %+v", parameters)
+	logger.Infof("Starting provisioning runtime: Name=%s, GlobalAccountID=%s, SubAccountID=%s PlatformRegion=%s UserID=%s", parameters.Name, ersContext.GlobalAccountID, ersContext.SubAccountID, region, ersContext.UserID)
+	if loggable, err := provisioningParameters.LoggableFields(); err == nil {
+		logger.Infof("Runtime parameters: %v", loggable)
+	}
 
 	// check if operation with instance ID already created
 	existingOperation, errStorage := b.operationsStorage.GetProvisioningOperationByInstanceID(instanceID)
@@ -103,18 +139,35 @@ func (b *ProvisionEndpoint) Provision(ctx context.Context, instanceID string, de
 		return b.handleExistingOperation(existingOperation, provisioningParameters, logger)
 	}
 
+	msg, disabled, err := b.killSwitches.ProvisioningDisabled(provisioningParameters)
+	switch {
+	case err != nil:
+		logger.Errorf("cannot check kill switches: %s", err)
+		return domain.ProvisionedServiceSpec{}, errors.New("cannot check kill switches")
+	case disabled:
+		logger.Infof("Provisioning rejected by kill switch: %s", msg)
+		return domain.ProvisionedServiceSpec{}, apiresponses.NewFailureResponse(errors.New(msg), http.StatusBadRequest, fmt.Sprintf("[instanceID: %s] %s", instanceID, msg))
+	}
+
+	if parameters.Subscription != nil {
+		err := b.prepareSubscription(provisioningParameters, subscriptionCredentials)
+		if err != nil {
+			logger.Errorf("cannot prepare customer-provided subscription: %s", err)
+			return domain.ProvisionedServiceSpec{}, subscriptionFailureResponse(err, instanceID)
+		}
+	}
+
 	// create and save new operation
 	operation, err := internal.NewProvisioningOperationWithID(operationID, instanceID, provisioningParameters)
 	if err != nil {
 		logger.Errorf("cannot create new operation: %s", err)
 		return domain.ProvisionedServiceSpec{}, errors.New("cannot create new operation")
 	}
+	operation.TraceContext = tracing.ToCarrier(ctx)
+	operation.CorrelationID = correlationID
 
-	err = b.operationsStorage.InsertProvisioningOperation(operation)
-	if err != nil {
-		logger.Errorf("cannot save operation: %s", err)
-		return domain.ProvisionedServiceSpec{}, errors.New("cannot save operation")
-	}
+	// the instance is saved first, so the storage rejects the second trial instance in the subaccount
+	// before the provisioning operation is created
 	err = b.instanceStorage.Insert(internal.Instance{
 		InstanceID:             instanceID,
 		GlobalAccountID:        ersContext.GlobalAccountID,
@@ -125,10 +178,28 @@ func (b *ProvisionEndpoint) Provision(ctx context.Context, instanceID string, de
 		ServicePlanName:        Plans[provisioningParameters.PlanID].PlanDefinition.Name,
 		ProvisioningParameters: operation.ProvisioningParameters,
 	})
-	if err != nil {
+	switch {
+	case dberr.IsAlreadyExists(err) && IsTrialPlan(details.PlanID):
+		return domain.ProvisionedServiceSpec{}, b.trialExistsFailure(instanceID, ersContext.SubAccountID, logger)
+	case err != nil:
 		logger.Errorf("cannot save instance in storage: %s", err)
 		return domain.ProvisionedServiceSpec{}, errors.New("cannot save instance")
 	}
+	err = b.operationsStorage.InsertProvisioningOperation(operation)
+	if err != nil {
+		logger.Errorf("cannot save operation: %s", err)
+		if err := b.instanceStorage.Delete(instanceID); err != nil {
+			logger.Errorf("cannot remove instance without operation from storage: %s", err)
+		}
+		return domain.ProvisionedServiceSpec{}, errors.New("cannot save operation")
+	}
+	if IsTrialPlan(details.PlanID) {
+		err = b.freeTier.Start(instanceID, ersContext.GlobalAccountID, details.PlanID)
+		if err != nil {
+			logger.Errorf("cannot save free tier usage in storage: %s", err)
+			return domain.ProvisionedServiceSpec{}, errors.New("cannot save free tier usage")
+		}
+	}
 
 	logger.Info("Adding operation to provisioning queue")
 	b.queue.Add(operation.ID)
@@ -176,24 +247,46 @@ func (b *ProvisionEndpoint) validateAndExtract(details domain.ProvisionDetails,
 	}
 	parameters.LicenceType = b.determineLicenceType(details.PlanID)
 
+	if err := validateSubscription(details.PlanID, parameters); err != nil {
+		return ersContext, parameters, err
+	}
+	if err := validateRegistry(parameters.Registry); err != nil {
+		return ersContext, parameters, err
+	}
+
 	found := b.builderFactory.IsPlanSupport(details.PlanID)
 	if !found {
 		return ersContext, parameters, errors.Errorf("the plan ID not known, planID: %s", details.PlanID)
 	}
 
+	// only one trial instance is allowed per subaccount, which is enforced by the storage when the instance is saved
 	if IsTrialPlan(details.PlanID) {
-		count, err := b.instanceStorage.GetNumberOfInstancesForGlobalAccountID(ersContext.GlobalAccountID)
-		if err != nil {
-			return ersContext, parameters, errors.Wrap(err, "while checking if a trial Kyma instance exists for given global account")
+		if err := b.freeTier.CheckLimit(ersContext.GlobalAccountID); err != nil {
+			logger.Infof("Provisioning Trial SKR rejected: %s", err)
+			return ersContext, parameters, err
 		}
+	}
+
+	return ersContext, parameters, nil
+}
 
-		if count > 0 {
-			logger.Info("Provisioning Trial SKR rejected, such instance was already created for this Global Account")
-			return ersContext, parameters, errors.Errorf("The Trial Kyma was created for the global account, but there is only one allowed")
+// trialExistsFailure returns the failure response to the provisioning of the trial instance in the subaccount
+// which already has one, the response points to the existing instance if it can be found
+func (b *ProvisionEndpoint) trialExistsFailure(instanceID, subAccountID string, logger logrus.FieldLogger) error {
+	msg := fmt.Sprintf("the trial Kyma Runtime already exists in the subaccount %s and only one is allowed", subAccountID)
+	instances, err := b.instanceStorage.FindAllInstancesForSubAccounts([]string{subAccountID})
+	if err != nil {
+		logger.Warnf("cannot find the existing trial instance: %s", err)
+	}
+	for _, instance := range instances {
+		if IsTrialPlan(instance.ServicePlanID) {
+			msg = fmt.Sprintf("%s, the existing instance ID: %s", msg, instance.InstanceID)
+			break
 		}
 	}
 
-	return ersContext, parameters, nil
+	logger.Infof("Provisioning Trial SKR rejected: %s", msg)
+	return apiresponses.NewFailureResponse(errors.New(msg), http.StatusBadRequest, fmt.Sprintf("[instanceID: %s] %s", instanceID, msg))
 }
 
 func (b *ProvisionEndpoint) extractERSContext(details domain.ProvisionDetails) (internal.ERSContext, error) {
@@ -223,13 +316,125 @@ func (b *ProvisionEndpoint) extractInputParameters(details domain.ProvisionDetai
 	return parameters, nil
 }
 
+func validateSubscription(planID string, parameters internal.ProvisioningParametersDTO) error {
+	subscription := parameters.Subscription
+	switch {
+	case subscription == nil:
+		return nil
+	case IsTrialPlan(planID):
+		return errors.New("customer-provided subscription is not supported for the trial plan")
+	case parameters.TargetSecret != nil:
+		return errors.New("subscription and targetSecret parameters cannot be used together")
+	case subscription.SecretName != "" && len(subscription.Credentials) > 0:
+		return errors.New("subscription secretName and credentials cannot be used together")
+	case subscription.SecretName == "" && len(subscription.Credentials) == 0:
+		return errors.New("subscription requires either secretName or credentials")
+	}
+
+	return nil
+}
+
+// registryPathComponent matches a single component of the repository path, the same way as the image references do
+var registryPathComponent = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|[-]*)[a-z0-9]+)*$`)
+
+func validateRegistry(registry *internal.RegistryDTO) error {
+	if registry == nil {
+		return nil
+	}
+	if registry.URL == "" {
+		return errors.New("registry requires the url")
+	}
+	if strings.Contains(registry.URL, "://") {
+		return errors.Errorf("registry url %q must not contain the scheme", registry.URL)
+	}
+
+	components := strings.Split(registry.URL, "/")
+	hostAndPort := strings.SplitN(components[0], ":", 2)
+	if errs := validation.IsDNS1123Subdomain(hostAndPort[0]); len(errs) > 0 {
+		return errors.Errorf("registry url %q has invalid host: %s", registry.URL, strings.Join(errs, ", "))
+	}
+	if len(hostAndPort) == 2 {
+		port, err := strconv.Atoi(hostAndPort[1])
+		if err != nil || len(validation.IsValidPortNum(port)) > 0 {
+			return errors.Errorf("registry url %q has invalid port %q", registry.URL, hostAndPort[1])
+		}
+	}
+	for _, component := range components[1:] {
+		if !registryPathComponent.MatchString(component) {
+			return errors.Errorf("registry url %q has invalid path component %q", registry.URL, component)
+		}
+	}
+
+	seen := make(map[string]struct{}, len(registry.PullSecrets))
+	for _, secret := range registry.PullSecrets {
+		if errs := validation.IsDNS1123Subdomain(secret.Name); len(errs) > 0 {
+			return errors.Errorf("registry pull secret name %q is invalid: %s", secret.Name, strings.Join(errs, ", "))
+		}
+		if _, found := seen[secret.Name]; found {
+			return errors.Errorf("registry pull secret %q is specified more than once", secret.Name)
+		}
+		if secret.Username == "" || secret.Password == "" {
+			return errors.Errorf("registry pull secret %q requires the username and the password", secret.Name)
+		}
+		seen[secret.Name] = struct{}{}
+	}
+
+	return nil
+}
+
+// extractSubscriptionCredentials removes the credentials payload of the customer-provided subscription from the parameters,
+// the payload is stored as a secret named after the instance and the parameters keep only the name of the secret
+func extractSubscriptionCredentials(instanceID string, parameters *internal.ProvisioningParametersDTO) map[string]string {
+	if parameters.Subscription == nil {
+		return nil
+	}
+
+	credentials := parameters.Subscription.Credentials
+	secretName := parameters.Subscription.SecretName
+	if len(credentials) > 0 {
+		secretName = SubscriptionSecretName(instanceID)
+	}
+	parameters.Subscription = &internal.SubscriptionDTO{SecretName: secretName}
+	parameters.TargetSecret = &secretName
+
+	return credentials
+}
+
+func (b *ProvisionEndpoint) prepareSubscription(pp internal.ProvisioningParameters, credentials map[string]string) error {
+	secretName := pp.Parameters.Subscription.SecretName
+	if len(credentials) > 0 {
+		return b.subscriptions.Store(pp.PlanID, pp.ErsContext.GlobalAccountID, secretName, credentials)
+	}
+
+	return b.subscriptions.Attach(pp.PlanID, pp.ErsContext.GlobalAccountID, secretName)
+}
+
+// SubscriptionSecretName returns the name of the secret which holds the credentials payload of the customer-provided subscription
+func SubscriptionSecretName(instanceID string) string {
+	return fmt.Sprintf("byo-%s", instanceID)
+}
+
+func subscriptionFailureResponse(err error, instanceID string) error {
+	errMsg := fmt.Sprintf("[instanceID: %s] %s", instanceID, err)
+	if kebError.IsTemporaryError(err) {
+		return apiresponses.NewFailureResponse(err, http.StatusInternalServerError, errMsg)
+	}
+
+	return apiresponses.NewFailureResponse(err, http.StatusBadRequest, errMsg)
+}
+
 func (b *ProvisionEndpoint) handleExistingOperation(operation *internal.ProvisioningOperation, input internal.ProvisioningParameters, log logrus.FieldLogger) (domain.ProvisionedServiceSpec, error) {
 	pp, err := operation.GetProvisioningParameters()
 	if err != nil {
 		log.Errorf("cannot get provisioning parameters from exist operation", err)
 		return domain.ProvisionedServiceSpec{}, errors.New("cannot get provisioning parameters from exist operation")
 	}
-	if pp.IsEqual(input) {
+	diff, err := pp.Diff(input)
+	if err != nil {
+		log.Errorf("cannot compare provisioning parameters with exist operation: %s", err)
+		return domain.ProvisionedServiceSpec{}, errors.New("cannot compare provisioning parameters with exist operation")
+	}
+	if len(diff) == 0 {
 		return domain.ProvisionedServiceSpec{
 			IsAsync:       true,
 			AlreadyExists: true,
@@ -237,7 +442,16 @@ func (b *ProvisionEndpoint) handleExistingOperation(operation *internal.Provisio
 		}, nil
 	}
 
-	err = errors.New("provisioning operation already exist")
+	// the conflicting fields are returned in the error description, which is the only part of the OSB error response
+	// the platform can act on
+	conflicts, err := json.Marshal(diff)
+	if err != nil {
+		log.Errorf("cannot marshal provisioning parameters diff: %s", err)
+		return domain.ProvisionedServiceSpec{}, errors.New("cannot marshal provisioning parameters diff")
+	}
+	log.Infof("Provisioning parameters differ from the exist operation: %s", conflicts)
+
+	err = errors.Errorf("provisioning operation already exist with different parameters: %s", conflicts)
 	msg := fmt.Sprintf("provisioning operation with InstanceID %s already exist", operation.InstanceID)
 	return domain.ProvisionedServiceSpec{}, apiresponses.NewFailureResponse(err, http.StatusConflict, msg)
 }
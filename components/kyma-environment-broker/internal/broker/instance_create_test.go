@@ -7,16 +7,23 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/broker"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/broker/automock"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/freetier"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/killswitch"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/middleware"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/ptr"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
 
 	"github.com/kyma-incubator/compass/components/director/pkg/jsonschema"
 	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/pivotal-cf/brokerapi/v7/domain/apiresponses"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -55,6 +62,9 @@ func TestProvision_Provision(t *testing.T) {
 			queue,
 			factoryBuilder,
 			fixAlwaysPassJSONValidator(),
+			nil,
+			fixFreeTier(memoryStorage),
+			fixKillSwitches(memoryStorage),
 			false,
 			logrus.StandardLogger(),
 		)
@@ -88,6 +98,134 @@ func TestProvision_Provision(t *testing.T) {
 
 		assert.Equal(t, instance.ProvisioningParameters, operation.ProvisioningParameters)
 		assert.Equal(t, instance.GlobalAccountID, globalAccountID)
+
+		usage, err := memoryStorage.FreeTierUsage().ListByGlobalAccountID(globalAccountID)
+		require.NoError(t, err)
+		require.Len(t, usage, 1)
+		assert.Equal(t, instanceID, usage[0].InstanceID)
+	})
+
+	t.Run("trial is rejected when the free tier limit is used up", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		err := memoryStorage.FreeTierUsage().Insert(internal.FreeTierUsageEntry{
+			InstanceID:      "removed-instance-id",
+			GlobalAccountID: globalAccountID,
+			PlanID:          broker.TrialPlanID,
+			StartedAt:       time.Now().Add(-48 * time.Hour),
+			FinishedAt:      time.Now().Add(-12 * time.Hour),
+		})
+		require.NoError(t, err)
+
+		factoryBuilder := &automock.PlanValidator{}
+		factoryBuilder.On("IsPlanSupport", broker.TrialPlanID).Return(true)
+
+		provisionEndpoint := broker.NewProvision(
+			broker.Config{EnablePlans: []string{"gcp", "azure", "trial"}},
+			memoryStorage.Operations(),
+			memoryStorage.Instances(),
+			nil,
+			factoryBuilder,
+			fixAlwaysPassJSONValidator(),
+			nil,
+			freetier.NewService(memoryStorage.FreeTierUsage(), freetier.Config{MaxInstanceHours: 24}, logrus.StandardLogger()),
+			fixKillSwitches(memoryStorage),
+			false,
+			logrus.StandardLogger(),
+		)
+
+		// when
+		_, err = provisionEndpoint.Provision(fixReqCtxWithRegion(t, "req-region"), instanceID, domain.ProvisionDetails{
+			ServiceID:     serviceID,
+			PlanID:        broker.TrialPlanID,
+			RawParameters: json.RawMessage(fmt.Sprintf(`{"name": "%s"}`, clusterName)),
+			RawContext:    json.RawMessage(fmt.Sprintf(`{"globalaccount_id": "%s", "subaccount_id": "%s"}`, globalAccountID, subAccountID)),
+		}, true)
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "free tier limit of 24 instance-hours")
+	})
+
+	t.Run("provisioning is rejected by the kill switch of the default provider region", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		killSwitches := fixKillSwitches(memoryStorage)
+		_, err := killSwitches.Disable(killswitch.ScopeRegion, "westeurope", "Azure incident in West Europe", "operator")
+		require.NoError(t, err)
+
+		factoryBuilder := &automock.PlanValidator{}
+		factoryBuilder.On("IsPlanSupport", planID).Return(true)
+
+		provisionEndpoint := broker.NewProvision(
+			broker.Config{EnablePlans: []string{"gcp", "azure"}},
+			memoryStorage.Operations(),
+			memoryStorage.Instances(),
+			nil,
+			factoryBuilder,
+			fixAlwaysPassJSONValidator(),
+			nil,
+			fixFreeTier(memoryStorage),
+			killSwitches,
+			false,
+			logrus.StandardLogger(),
+		)
+
+		// when
+		_, err = provisionEndpoint.Provision(fixReqCtxWithRegion(t, "req-region"), instanceID, domain.ProvisionDetails{
+			ServiceID:     serviceID,
+			PlanID:        planID,
+			RawParameters: json.RawMessage(fmt.Sprintf(`{"name": "%s"}`, clusterName)),
+			RawContext:    json.RawMessage(fmt.Sprintf(`{"globalaccount_id": "%s", "subaccount_id": "%s"}`, globalAccountID, subAccountID)),
+		}, true)
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Azure incident in West Europe")
+		_, err = memoryStorage.Instances().GetByID(instanceID)
+		assert.True(t, dberr.IsNotFound(err))
+	})
+
+	t.Run("provisioning is allowed after the plan kill switch is enabled again", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		killSwitches := fixKillSwitches(memoryStorage)
+		_, err := killSwitches.Disable(killswitch.ScopePlan, broker.AzurePlanName, "maintenance", "operator")
+		require.NoError(t, err)
+		_, err = killSwitches.Enable(killswitch.ScopePlan, broker.AzurePlanName, "operator")
+		require.NoError(t, err)
+
+		queue := &automock.Queue{}
+		queue.On("Add", mock.AnythingOfType("string"))
+
+		factoryBuilder := &automock.PlanValidator{}
+		factoryBuilder.On("IsPlanSupport", planID).Return(true)
+
+		provisionEndpoint := broker.NewProvision(
+			broker.Config{EnablePlans: []string{"gcp", "azure"}},
+			memoryStorage.Operations(),
+			memoryStorage.Instances(),
+			queue,
+			factoryBuilder,
+			fixAlwaysPassJSONValidator(),
+			nil,
+			fixFreeTier(memoryStorage),
+			killSwitches,
+			false,
+			logrus.StandardLogger(),
+		)
+
+		// when
+		response, err := provisionEndpoint.Provision(fixReqCtxWithRegion(t, "req-region"), instanceID, domain.ProvisionDetails{
+			ServiceID:     serviceID,
+			PlanID:        planID,
+			RawParameters: json.RawMessage(fmt.Sprintf(`{"name": "%s"}`, clusterName)),
+			RawContext:    json.RawMessage(fmt.Sprintf(`{"globalaccount_id": "%s", "subaccount_id": "%s"}`, globalAccountID, subAccountID)),
+		}, true)
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, response.IsAsync)
 	})
 
 	t.Run("existing operation ID will be return", func(t *testing.T) {
@@ -108,6 +246,9 @@ func TestProvision_Provision(t *testing.T) {
 			nil,
 			factoryBuilder,
 			fixAlwaysPassJSONValidator(),
+			nil,
+			fixFreeTier(memoryStorage),
+			fixKillSwitches(memoryStorage),
 			false,
 			logrus.StandardLogger(),
 		)
@@ -134,6 +275,7 @@ func TestProvision_Provision(t *testing.T) {
 		err = memoryStorage.Instances().Insert(internal.Instance{
 			InstanceID:      instanceID,
 			GlobalAccountID: globalAccountID,
+			SubAccountID:    subAccountID,
 			ServiceID:       serviceID,
 			ServicePlanID:   broker.TrialPlanID,
 		})
@@ -149,6 +291,9 @@ func TestProvision_Provision(t *testing.T) {
 			nil,
 			factoryBuilder,
 			fixAlwaysPassJSONValidator(),
+			nil,
+			fixFreeTier(memoryStorage),
+			fixKillSwitches(memoryStorage),
 			false,
 			logrus.StandardLogger(),
 		)
@@ -162,14 +307,62 @@ func TestProvision_Provision(t *testing.T) {
 		}, true)
 
 		// then
-		assert.EqualError(t, err, "The Trial Kyma was created for the global account, but there is only one allowed")
+		assert.EqualError(t, err, fmt.Sprintf("the trial Kyma Runtime already exists in the subaccount %s and only one is allowed, the existing instance ID: %s", subAccountID, instanceID))
+		_, err = memoryStorage.Instances().GetByID("new-instance-id")
+		assert.True(t, dberr.IsNotFound(err))
+		_, err = memoryStorage.Operations().GetProvisioningOperationByInstanceID("new-instance-id")
+		assert.True(t, dberr.IsNotFound(err), "the operation must not be created for the rejected instance")
+	})
+
+	t.Run("trial is allowed in other subaccount of the global account", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		err := memoryStorage.Instances().Insert(internal.Instance{
+			InstanceID:      "other-instance-id",
+			GlobalAccountID: globalAccountID,
+			SubAccountID:    "other-subaccount-id",
+			ServiceID:       serviceID,
+			ServicePlanID:   broker.TrialPlanID,
+		})
+		assert.NoError(t, err)
+
+		queue := &automock.Queue{}
+		queue.On("Add", mock.AnythingOfType("string"))
+
+		factoryBuilder := &automock.PlanValidator{}
+		factoryBuilder.On("IsPlanSupport", broker.TrialPlanID).Return(true)
+
+		provisionEndpoint := broker.NewProvision(
+			broker.Config{EnablePlans: []string{"gcp", "azure", broker.TrialPlanName}},
+			memoryStorage.Operations(),
+			memoryStorage.Instances(),
+			queue,
+			factoryBuilder,
+			fixAlwaysPassJSONValidator(),
+			nil,
+			fixFreeTier(memoryStorage),
+			fixKillSwitches(memoryStorage),
+			false,
+			logrus.StandardLogger(),
+		)
+
+		// when
+		_, err = provisionEndpoint.Provision(fixReqCtxWithRegion(t, "dummy"), instanceID, domain.ProvisionDetails{
+			ServiceID:     serviceID,
+			PlanID:        broker.TrialPlanID,
+			RawParameters: json.RawMessage(fmt.Sprintf(`{"name": "%s"}`, clusterName)),
+			RawContext:    json.RawMessage(fmt.Sprintf(`{"globalaccount_id": "%s", "subaccount_id": "%s"}`, globalAccountID, subAccountID)),
+		}, true)
+
+		// then
+		assert.NoError(t, err)
 	})
 
 	t.Run("provision trial", func(t *testing.T) {
 		// given
 		memoryStorage := storage.NewMemoryStorage()
 		memoryStorage.Instances().Insert(internal.Instance{
-			InstanceID:      instanceID,
+			InstanceID:      "other-instance-id",
 			GlobalAccountID: "other-global-account",
 			ServiceID:       serviceID,
 			ServicePlanID:   broker.TrialPlanID,
@@ -188,6 +381,9 @@ func TestProvision_Provision(t *testing.T) {
 			queue,
 			factoryBuilder,
 			fixAlwaysPassJSONValidator(),
+			nil,
+			fixFreeTier(memoryStorage),
+			fixKillSwitches(memoryStorage),
 			false,
 			logrus.StandardLogger(),
 		)
@@ -243,6 +439,9 @@ func TestProvision_Provision(t *testing.T) {
 			nil,
 			factoryBuilder,
 			fixAlwaysPassJSONValidator(),
+			nil,
+			fixFreeTier(memoryStorage),
+			fixKillSwitches(memoryStorage),
 			false,
 			logrus.StandardLogger(),
 		)
@@ -256,10 +455,59 @@ func TestProvision_Provision(t *testing.T) {
 		}, true)
 
 		// then
-		assert.EqualError(t, err, "provisioning operation already exist")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "provisioning operation already exist with different parameters")
+		assert.Contains(t, err.Error(), `{"field":"ers_context.globalaccount_id","existing":"`+globalAccountID+`","requested":"1cafb9c8-c8f8-478a-948a-9cb53bb76aa4"}`)
+		assert.Contains(t, err.Error(), `{"field":"platform_region","existing":"`+region+`","requested":"dummy"}`)
+		failure, ok := err.(*apiresponses.FailureResponse)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusConflict, failure.ValidatedStatusCode(nil))
 		assert.Empty(t, response.OperationData)
 	})
 
+	t.Run("existing operation ID will be returned for reordered zones", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		existing := fixExistOperation()
+		existing.ProvisioningParameters = fmt.Sprintf(
+			`{"plan_id":"%s", "service_id": "%s", "ers_context":{"globalaccount_id": "%s", "subaccount_id": "%s"}, "parameters":{"name": "%s", "zones": ["a", "b"]}, "platform_region": "%s"}`,
+			planID, serviceID, globalAccountID, subAccountID, clusterName, region)
+		err := memoryStorage.Operations().InsertProvisioningOperation(existing)
+		assert.NoError(t, err)
+		err = memoryStorage.Instances().Insert(fixInstance())
+		assert.NoError(t, err)
+
+		factoryBuilder := &automock.PlanValidator{}
+		factoryBuilder.On("IsPlanSupport", planID).Return(true)
+
+		provisionEndpoint := broker.NewProvision(
+			broker.Config{EnablePlans: []string{"gcp", "azure", "azure_lite"}},
+			memoryStorage.Operations(),
+			memoryStorage.Instances(),
+			nil,
+			factoryBuilder,
+			fixAlwaysPassJSONValidator(),
+			nil,
+			fixFreeTier(memoryStorage),
+			fixKillSwitches(memoryStorage),
+			false,
+			logrus.StandardLogger(),
+		)
+
+		// when
+		response, err := provisionEndpoint.Provision(fixReqCtxWithRegion(t, region), instanceID, domain.ProvisionDetails{
+			ServiceID:     serviceID,
+			PlanID:        planID,
+			RawParameters: json.RawMessage(fmt.Sprintf(`{"name": "%s", "zones": ["b", "a"]}`, clusterName)),
+			RawContext:    json.RawMessage(fmt.Sprintf(`{"globalaccount_id": "%s", "subaccount_id": "%s"}`, globalAccountID, subAccountID)),
+		}, true)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, existOperationID, response.OperationData)
+		assert.True(t, response.AlreadyExists)
+	})
+
 	t.Run("return error on wrong input parameters", func(t *testing.T) {
 		// given
 		// #setup memory storage
@@ -281,6 +529,9 @@ func TestProvision_Provision(t *testing.T) {
 			nil,
 			factoryBuilder,
 			fixValidator,
+			nil,
+			fixFreeTier(memoryStorage),
+			fixKillSwitches(memoryStorage),
 			false,
 			logrus.StandardLogger(),
 		)
@@ -323,6 +574,9 @@ func TestProvision_Provision(t *testing.T) {
 			nil,
 			factoryBuilder,
 			fixValidator,
+			nil,
+			fixFreeTier(memoryStorage),
+			fixKillSwitches(memoryStorage),
 			false,
 			logrus.StandardLogger(),
 		)
@@ -364,6 +618,9 @@ func TestProvision_Provision(t *testing.T) {
 			queue,
 			factoryBuilder,
 			fixValidator,
+			nil,
+			fixFreeTier(memoryStorage),
+			fixKillSwitches(memoryStorage),
 			true,
 			logrus.StandardLogger(),
 		)
@@ -404,6 +661,9 @@ func TestProvision_Provision(t *testing.T) {
 			nil,
 			factoryBuilder,
 			fixValidator,
+			nil,
+			nil,
+			nil,
 			true,
 			logrus.StandardLogger(),
 		)
@@ -440,6 +700,9 @@ func TestProvision_Provision(t *testing.T) {
 			queue,
 			factoryBuilder,
 			fixValidator,
+			nil,
+			fixFreeTier(memoryStorage),
+			fixKillSwitches(memoryStorage),
 			false,
 			logrus.StandardLogger(),
 		)
@@ -485,6 +748,9 @@ func TestProvision_Provision(t *testing.T) {
 			queue,
 			factoryBuilder,
 			fixValidator,
+			nil,
+			fixFreeTier(memoryStorage),
+			fixKillSwitches(memoryStorage),
 			false,
 			logrus.StandardLogger(),
 		)
@@ -527,6 +793,9 @@ func TestProvision_Provision(t *testing.T) {
 			queue,
 			factoryBuilder,
 			fixValidator,
+			nil,
+			fixFreeTier(memoryStorage),
+			fixKillSwitches(memoryStorage),
 			false,
 			logrus.StandardLogger(),
 		)
@@ -548,6 +817,282 @@ func TestProvision_Provision(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, ptr.String(internal.LicenceTypeLite), parameters.Parameters.LicenceType)
 	})
+
+	t.Run("operation has the correlation ID of the request", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+
+		queue := &automock.Queue{}
+		queue.On("Add", mock.AnythingOfType("string"))
+
+		factoryBuilder := &automock.PlanValidator{}
+		factoryBuilder.On("IsPlanSupport", planID).Return(true)
+
+		provisionEndpoint := broker.NewProvision(
+			broker.Config{EnablePlans: []string{"gcp", "azure"}},
+			memoryStorage.Operations(),
+			memoryStorage.Instances(),
+			queue,
+			factoryBuilder,
+			fixAlwaysPassJSONValidator(),
+			nil,
+			fixFreeTier(memoryStorage),
+			fixKillSwitches(memoryStorage),
+			false,
+			logrus.StandardLogger(),
+		)
+
+		// when
+		response, err := provisionEndpoint.Provision(fixReqCtxWithRegionAndCorrelationID(t, "req-region", "correlation-id"), instanceID, domain.ProvisionDetails{
+			ServiceID:     serviceID,
+			PlanID:        planID,
+			RawParameters: json.RawMessage(fmt.Sprintf(`{"name": "%s"}`, clusterName)),
+			RawContext:    json.RawMessage(fmt.Sprintf(`{"globalaccount_id": "%s", "subaccount_id": "%s"}`, globalAccountID, subAccountID)),
+		}, true)
+		require.NoError(t, err)
+
+		// then
+		ops, err := memoryStorage.Operations().ListOperationsByCorrelationID("correlation-id")
+		require.NoError(t, err)
+		require.Len(t, ops, 1)
+		assert.Equal(t, response.OperationData, ops[0].ID)
+	})
+}
+
+func TestProvision_ProvisionWithSubscription(t *testing.T) {
+	t.Run("credentials payload should be stored as a secret", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+
+		queue := &automock.Queue{}
+		queue.On("Add", mock.AnythingOfType("string"))
+
+		factoryBuilder := &automock.PlanValidator{}
+		factoryBuilder.On("IsPlanSupport", planID).Return(true)
+
+		secretName := fmt.Sprintf("byo-%s", instanceID)
+		subscriptions := &automock.SubscriptionSecrets{}
+		subscriptions.On("Store", planID, globalAccountID, secretName, map[string]string{"clientID": "id", "clientSecret": "secret"}).Return(nil).Once()
+		defer subscriptions.AssertExpectations(t)
+
+		provisionEndpoint := broker.NewProvision(
+			broker.Config{EnablePlans: []string{"gcp", "azure"}},
+			memoryStorage.Operations(),
+			memoryStorage.Instances(),
+			queue,
+			factoryBuilder,
+			fixAlwaysPassJSONValidator(),
+			subscriptions,
+			fixFreeTier(memoryStorage),
+			fixKillSwitches(memoryStorage),
+			false,
+			logrus.StandardLogger(),
+		)
+
+		// when
+		response, err := provisionEndpoint.Provision(fixReqCtxWithRegion(t, region), instanceID, domain.ProvisionDetails{
+			ServiceID:     serviceID,
+			PlanID:        planID,
+			RawParameters: json.RawMessage(fmt.Sprintf(`{"name": "%s", "subscription": {"credentials": {"clientID": "id", "clientSecret": "secret"}}}`, clusterName)),
+			RawContext:    json.RawMessage(fmt.Sprintf(`{"globalaccount_id": "%s", "subaccount_id": "%s"}`, globalAccountID, subAccountID)),
+		}, true)
+
+		// then
+		require.NoError(t, err)
+
+		operation, err := memoryStorage.Operations().GetProvisioningOperationByID(response.OperationData)
+		require.NoError(t, err)
+		assert.NotContains(t, operation.ProvisioningParameters, "clientSecret")
+
+		parameters, err := operation.GetProvisioningParameters()
+		require.NoError(t, err)
+		assert.Equal(t, &internal.SubscriptionDTO{SecretName: secretName}, parameters.Parameters.Subscription)
+		assert.Equal(t, ptr.String(secretName), parameters.Parameters.TargetSecret)
+	})
+
+	t.Run("invalid subscription secret should be rejected", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+
+		factoryBuilder := &automock.PlanValidator{}
+		factoryBuilder.On("IsPlanSupport", planID).Return(true)
+
+		subscriptions := &automock.SubscriptionSecrets{}
+		subscriptions.On("Attach", planID, globalAccountID, "customer-secret").Return(errors.New("secret customer-secret does not belong to the global account")).Once()
+		defer subscriptions.AssertExpectations(t)
+
+		provisionEndpoint := broker.NewProvision(
+			broker.Config{EnablePlans: []string{"gcp", "azure"}},
+			memoryStorage.Operations(),
+			memoryStorage.Instances(),
+			nil,
+			factoryBuilder,
+			fixAlwaysPassJSONValidator(),
+			subscriptions,
+			fixFreeTier(memoryStorage),
+			fixKillSwitches(memoryStorage),
+			false,
+			logrus.StandardLogger(),
+		)
+
+		// when
+		_, err := provisionEndpoint.Provision(fixReqCtxWithRegion(t, region), instanceID, domain.ProvisionDetails{
+			ServiceID:     serviceID,
+			PlanID:        planID,
+			RawParameters: json.RawMessage(fmt.Sprintf(`{"name": "%s", "subscription": {"secretName": "customer-secret"}}`, clusterName)),
+			RawContext:    json.RawMessage(fmt.Sprintf(`{"globalaccount_id": "%s", "subaccount_id": "%s"}`, globalAccountID, subAccountID)),
+		}, true)
+
+		// then
+		assertFailureStatus(t, err, http.StatusBadRequest)
+
+		_, err = memoryStorage.Instances().GetByID(instanceID)
+		assert.True(t, dberr.IsNotFound(err))
+	})
+
+	t.Run("subscription is not supported for trial plan", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+
+		factoryBuilder := &automock.PlanValidator{}
+		factoryBuilder.On("IsPlanSupport", broker.TrialPlanID).Return(true)
+
+		provisionEndpoint := broker.NewProvision(
+			broker.Config{EnablePlans: []string{"trial"}},
+			memoryStorage.Operations(),
+			memoryStorage.Instances(),
+			nil,
+			factoryBuilder,
+			fixAlwaysPassJSONValidator(),
+			&automock.SubscriptionSecrets{},
+			fixFreeTier(memoryStorage),
+			fixKillSwitches(memoryStorage),
+			false,
+			logrus.StandardLogger(),
+		)
+
+		// when
+		_, err := provisionEndpoint.Provision(fixReqCtxWithRegion(t, region), instanceID, domain.ProvisionDetails{
+			ServiceID:     serviceID,
+			PlanID:        broker.TrialPlanID,
+			RawParameters: json.RawMessage(fmt.Sprintf(`{"name": "%s", "subscription": {"secretName": "customer-secret"}}`, clusterName)),
+			RawContext:    json.RawMessage(fmt.Sprintf(`{"globalaccount_id": "%s", "subaccount_id": "%s"}`, globalAccountID, subAccountID)),
+		}, true)
+
+		// then
+		assert.EqualError(t, err, "customer-provided subscription is not supported for the trial plan")
+	})
+}
+
+func TestProvision_ProvisionWithRegistry(t *testing.T) {
+	t.Run("registry should be persisted with the provisioning parameters", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+
+		queue := &automock.Queue{}
+		queue.On("Add", mock.AnythingOfType("string"))
+
+		factoryBuilder := &automock.PlanValidator{}
+		factoryBuilder.On("IsPlanSupport", planID).Return(true)
+
+		provisionEndpoint := broker.NewProvision(
+			broker.Config{EnablePlans: []string{"gcp", "azure"}},
+			memoryStorage.Operations(),
+			memoryStorage.Instances(),
+			queue,
+			factoryBuilder,
+			fixAlwaysPassJSONValidator(),
+			&automock.SubscriptionSecrets{},
+			fixFreeTier(memoryStorage),
+			fixKillSwitches(memoryStorage),
+			false,
+			logrus.StandardLogger(),
+		)
+
+		// when
+		response, err := provisionEndpoint.Provision(fixReqCtxWithRegion(t, region), instanceID, domain.ProvisionDetails{
+			ServiceID:     serviceID,
+			PlanID:        planID,
+			RawParameters: json.RawMessage(fmt.Sprintf(`{"name": "%s", "registry": {"url": "registry.example.com:5000/kyma", "pullSecrets": [{"name": "registry-credentials", "username": "kyma", "password": "secret"}]}}`, clusterName)),
+			RawContext:    json.RawMessage(fmt.Sprintf(`{"globalaccount_id": "%s", "subaccount_id": "%s"}`, globalAccountID, subAccountID)),
+		}, true)
+
+		// then
+		require.NoError(t, err)
+
+		operation, err := memoryStorage.Operations().GetProvisioningOperationByID(response.OperationData)
+		require.NoError(t, err)
+
+		parameters, err := operation.GetProvisioningParameters()
+		require.NoError(t, err)
+		assert.Equal(t, &internal.RegistryDTO{
+			URL:         "registry.example.com:5000/kyma",
+			PullSecrets: []internal.RegistryPullSecretDTO{{Name: "registry-credentials", Username: "kyma", Password: "secret"}},
+		}, parameters.Parameters.Registry)
+	})
+
+	for name, tc := range map[string]struct {
+		registry      string
+		expectedError string
+	}{
+		"missing url": {
+			registry:      `{"pullSecrets": [{"name": "registry-credentials", "username": "kyma", "password": "secret"}]}`,
+			expectedError: "registry requires the url",
+		},
+		"url with scheme": {
+			registry:      `{"url": "https://registry.example.com"}`,
+			expectedError: `registry url "https://registry.example.com" must not contain the scheme`,
+		},
+		"invalid port": {
+			registry:      `{"url": "registry.example.com:99999"}`,
+			expectedError: `registry url "registry.example.com:99999" has invalid port "99999"`,
+		},
+		"invalid path": {
+			registry:      `{"url": "registry.example.com/Kyma"}`,
+			expectedError: `registry url "registry.example.com/Kyma" has invalid path component "Kyma"`,
+		},
+		"duplicated pull secret": {
+			registry:      `{"url": "registry.example.com", "pullSecrets": [{"name": "registry-credentials", "username": "kyma", "password": "secret"}, {"name": "registry-credentials", "username": "kyma", "password": "secret"}]}`,
+			expectedError: `registry pull secret "registry-credentials" is specified more than once`,
+		},
+		"pull secret without password": {
+			registry:      `{"url": "registry.example.com", "pullSecrets": [{"name": "registry-credentials", "username": "kyma"}]}`,
+			expectedError: `registry pull secret "registry-credentials" requires the username and the password`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// given
+			memoryStorage := storage.NewMemoryStorage()
+
+			factoryBuilder := &automock.PlanValidator{}
+			factoryBuilder.On("IsPlanSupport", planID).Return(true)
+
+			provisionEndpoint := broker.NewProvision(
+				broker.Config{EnablePlans: []string{"gcp", "azure"}},
+				memoryStorage.Operations(),
+				memoryStorage.Instances(),
+				nil,
+				factoryBuilder,
+				fixAlwaysPassJSONValidator(),
+				&automock.SubscriptionSecrets{},
+				fixFreeTier(memoryStorage),
+				fixKillSwitches(memoryStorage),
+				false,
+				logrus.StandardLogger(),
+			)
+
+			// when
+			_, err := provisionEndpoint.Provision(fixReqCtxWithRegion(t, region), instanceID, domain.ProvisionDetails{
+				ServiceID:     serviceID,
+				PlanID:        planID,
+				RawParameters: json.RawMessage(fmt.Sprintf(`{"name": "%s", "registry": %s}`, clusterName, tc.registry)),
+				RawContext:    json.RawMessage(fmt.Sprintf(`{"globalaccount_id": "%s", "subaccount_id": "%s"}`, globalAccountID, subAccountID)),
+			}, true)
+
+			// then
+			assert.EqualError(t, err, tc.expectedError)
+		})
+	}
 }
 
 func fixExistOperation() internal.ProvisioningOperation {
@@ -562,6 +1107,14 @@ func fixExistOperation() internal.ProvisioningOperation {
 	}
 }
 
+func fixFreeTier(db storage.BrokerStorage) *freetier.Service {
+	return freetier.NewService(db.FreeTierUsage(), freetier.Config{}, logrus.StandardLogger())
+}
+
+func fixKillSwitches(db storage.BrokerStorage) *killswitch.Service {
+	return killswitch.NewService(db.KillSwitches(), logrus.StandardLogger())
+}
+
 func fixAlwaysPassJSONValidator() broker.PlansSchemaValidator {
 	validatorMock := &automock.JSONSchemaValidator{}
 	validatorMock.On("ValidateString", mock.Anything).Return(jsonschema.ValidationResult{Valid: true}, nil)
@@ -597,3 +1150,67 @@ func fixReqCtxWithRegion(t *testing.T, region string) context.Context {
 	middleware.AddRegionToContext(region).Middleware(spyHandler).ServeHTTP(httptest.NewRecorder(), req)
 	return ctx
 }
+
+func fixReqCtxWithRegionAndCorrelationID(t *testing.T, region, correlationID string) context.Context {
+	t.Helper()
+
+	req, err := http.NewRequest("GET", "http://url.io", nil)
+	require.NoError(t, err)
+	req.Header.Set(httputil.CorrelationIDHeader, correlationID)
+	var ctx context.Context
+	spyHandler := http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+		ctx = req.Context()
+	})
+
+	handler := middleware.AddRegionToContext(region).Middleware(spyHandler)
+	middleware.AddCorrelationIDToContext().Middleware(handler).ServeHTTP(httptest.NewRecorder(), req)
+	return ctx
+}
+
+func TestProvision_ProvisionWithUserID(t *testing.T) {
+	// given
+	memoryStorage := storage.NewMemoryStorage()
+
+	queue := &automock.Queue{}
+	queue.On("Add", mock.AnythingOfType("string"))
+
+	factoryBuilder := &automock.PlanValidator{}
+	factoryBuilder.On("IsPlanSupport", planID).Return(true)
+
+	provisionEndpoint := broker.NewProvision(
+		broker.Config{EnablePlans: []string{"gcp", "azure"}},
+		memoryStorage.Operations(),
+		memoryStorage.Instances(),
+		queue,
+		factoryBuilder,
+		fixAlwaysPassJSONValidator(),
+		&automock.SubscriptionSecrets{},
+		fixFreeTier(memoryStorage),
+		fixKillSwitches(memoryStorage),
+		false,
+		logrus.StandardLogger(),
+	)
+
+	// when
+	response, err := provisionEndpoint.Provision(fixReqCtxWithRegion(t, region), instanceID, domain.ProvisionDetails{
+		ServiceID:     serviceID,
+		PlanID:        planID,
+		RawParameters: json.RawMessage(fmt.Sprintf(`{"name": "%s"}`, clusterName)),
+		RawContext:    json.RawMessage(fmt.Sprintf(`{"globalaccount_id": "%s", "subaccount_id": "%s", "user_id": "john.smith@email.com"}`, globalAccountID, subAccountID)),
+	}, true)
+
+	// then
+	require.NoError(t, err)
+
+	operation, err := memoryStorage.Operations().GetProvisioningOperationByID(response.OperationData)
+	require.NoError(t, err)
+	parameters, err := operation.GetProvisioningParameters()
+	require.NoError(t, err)
+	assert.Equal(t, "john.smith@email.com", parameters.ErsContext.UserID)
+
+	instance, err := memoryStorage.Instances().GetByID(instanceID)
+	require.NoError(t, err)
+	parameters, err = instance.GetProvisioningParameters()
+	require.NoError(t, err)
+	assert.Equal(t, "john.smith@email.com", parameters.ErsContext.UserID)
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/middleware"
 
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
@@ -13,6 +14,7 @@ import (
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
 
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/tracing"
 	"github.com/pivotal-cf/brokerapi/v7/domain"
 	"github.com/pivotal-cf/brokerapi/v7/domain/apiresponses"
 	"github.com/sirupsen/logrus"
@@ -22,7 +24,7 @@ type DeprovisionEndpoint struct {
 	log logrus.FieldLogger
 
 	instancesStorage  storage.Instances
-	operationsStorage storage.Deprovisioning
+	operationsStorage storage.Operations
 
 	queue Queue
 }
@@ -40,7 +42,8 @@ func NewDeprovision(instancesStorage storage.Instances, operationsStorage storag
 // Deprovision deletes an existing service instance
 //  DELETE /v2/service_instances/{instance_id}
 func (b *DeprovisionEndpoint) Deprovision(ctx context.Context, instanceID string, details domain.DeprovisionDetails, asyncAllowed bool) (domain.DeprovisionServiceSpec, error) {
-	logger := b.log.WithFields(logrus.Fields{"instanceID": instanceID})
+	correlationID, _ := middleware.CorrelationIDFromContext(ctx)
+	logger := b.log.WithFields(logrus.Fields{"instanceID": instanceID, "correlationID": correlationID})
 	logger.Infof("Deprovisioning triggered, details: %+v", details)
 
 	instance, err := b.instancesStorage.GetByID(instanceID)
@@ -80,6 +83,10 @@ func (b *DeprovisionEndpoint) Deprovision(ctx context.Context, instanceID string
 			OperationData: existingOperation.ID,
 		}, nil
 	}
+	if err := checkAccountMigration(b.operationsStorage, instanceID, logger); err != nil {
+		return domain.DeprovisionServiceSpec{}, err
+	}
+
 	// create and save new operation
 	operationID := uuid.New().String()
 	logger = logger.WithField("operationID", operationID)
@@ -88,6 +95,8 @@ func (b *DeprovisionEndpoint) Deprovision(ctx context.Context, instanceID string
 		logger.Errorf("cannot create new operation: %s", err)
 		return domain.DeprovisionServiceSpec{}, errors.New("cannot create new operation")
 	}
+	operation.TraceContext = tracing.ToCarrier(ctx)
+	operation.CorrelationID = correlationID
 	err = b.operationsStorage.InsertDeprovisioningOperation(operation)
 	if err != nil {
 		logger.Errorf("cannot save operation: %s", err)
@@ -4,7 +4,9 @@ import (
 	"context"
 	"net/http"
 
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
 
 	"github.com/pivotal-cf/brokerapi/v7/domain"
 	"github.com/pivotal-cf/brokerapi/v7/domain/apiresponses"
@@ -27,18 +29,20 @@ func NewLastOperation(os storage.Operations, log logrus.FieldLogger) *LastOperat
 
 // LastOperation fetches last operation state for a service instance
 //   GET /v2/service_instances/{instance_id}/last_operation
+// The operation given in the `operation` query parameter is returned, so the platform polling an older operation
+// gets its state even if newer operations were created for the instance. The latest operation of the instance
+// is returned if the parameter is not set.
 func (b *LastOperationEndpoint) LastOperation(ctx context.Context, instanceID string, details domain.PollDetails) (domain.LastOperation, error) {
 	logger := b.log.WithField("instanceID", instanceID).WithField("operationID", details.OperationData)
 
-	operation, err := b.operationStorage.GetOperationByID(details.OperationData)
-	if err != nil {
-		logger.Errorf("cannot get operation from storage: %s", err)
-		return domain.LastOperation{}, errors.Wrapf(err, "while getting operation from storage")
-	}
-
-	if operation.InstanceID != instanceID {
+	operation, err := b.getOperation(instanceID, details.OperationData)
+	switch {
+	case dberr.IsNotFound(err):
 		err := errors.Errorf("operation does not exist")
 		return domain.LastOperation{}, apiresponses.NewFailureResponseBuilder(err, http.StatusBadRequest, err.Error())
+	case err != nil:
+		logger.Errorf("cannot get operation from storage: %s", err)
+		return domain.LastOperation{}, errors.Wrapf(err, "while getting operation from storage")
 	}
 
 	return domain.LastOperation{
@@ -46,3 +50,20 @@ func (b *LastOperationEndpoint) LastOperation(ctx context.Context, instanceID st
 		Description: operation.Description,
 	}, nil
 }
+
+func (b *LastOperationEndpoint) getOperation(instanceID, operationID string) (*internal.Operation, error) {
+	if operationID != "" {
+		return b.operationStorage.GetOperationByInstanceAndID(instanceID, operationID)
+	}
+
+	// operations are sorted by the creation time
+	operations, err := b.operationStorage.ListOperationsByInstanceID(instanceID)
+	if err != nil {
+		return nil, err
+	}
+	if len(operations) == 0 {
+		return nil, dberr.NotFound("operations for instance %s not found", instanceID)
+	}
+
+	return &operations[len(operations)-1], nil
+}
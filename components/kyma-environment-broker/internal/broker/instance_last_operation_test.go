@@ -2,15 +2,19 @@ package broker_test
 
 import (
 	"context"
+	"net/http"
 	"testing"
+	"time"
 
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/broker"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
 
 	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/pivotal-cf/brokerapi/v7/domain/apiresponses"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -40,6 +44,56 @@ func TestLastOperation_LastOperation(t *testing.T) {
 	}, response)
 }
 
+func TestLastOperation_LastOperationByInstanceAndID(t *testing.T) {
+	// given
+	memoryStorage := storage.NewMemoryStorage()
+	err := memoryStorage.Operations().InsertProvisioningOperation(fixOperation())
+	assert.NoError(t, err)
+	err = memoryStorage.Operations().InsertDeprovisioningOperation(internal.DeprovisioningOperation{
+		Operation: internal.Operation{
+			ID:          "deprovisioning-operation-id",
+			InstanceID:  instID,
+			State:       domain.InProgress,
+			Description: "deprovisioning in progress",
+			CreatedAt:   time.Now().Add(time.Minute),
+		},
+	})
+	assert.NoError(t, err)
+
+	lastOperationEndpoint := broker.NewLastOperation(memoryStorage.Operations(), logrus.StandardLogger())
+
+	t.Run("should return the historical operation given in the query parameter", func(t *testing.T) {
+		// when
+		response, err := lastOperationEndpoint.LastOperation(context.TODO(), instID, domain.PollDetails{OperationData: operationID})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, domain.Succeeded, response.State)
+		assert.Equal(t, operationDescription, response.Description)
+	})
+
+	t.Run("should return the latest operation if the operation is not given", func(t *testing.T) {
+		// when
+		response, err := lastOperationEndpoint.LastOperation(context.TODO(), instID, domain.PollDetails{})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, domain.InProgress, response.State)
+		assert.Equal(t, "deprovisioning in progress", response.Description)
+	})
+
+	t.Run("should reject operation of another instance", func(t *testing.T) {
+		// when
+		_, err := lastOperationEndpoint.LastOperation(context.TODO(), "other-instance-id", domain.PollDetails{OperationData: operationID})
+
+		// then
+		require.Error(t, err)
+		failure, ok := err.(*apiresponses.FailureResponse)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusBadRequest, failure.ValidatedStatusCode(nil))
+	})
+}
+
 func fixOperation() internal.ProvisioningOperation {
 	return internal.ProvisioningOperation{
 		Operation: internal.Operation{
@@ -2,26 +2,394 @@ package broker
 
 import (
 	"context"
-	"errors"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/middleware"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
 
 	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/pivotal-cf/brokerapi/v7/domain/apiresponses"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
 
+// supportedPlanMigrations lists the plans to which an instance of the given plan can be migrated
+var supportedPlanMigrations = map[string][]string{
+	TrialPlanID: {AzurePlanID},
+}
+
 type UpdateEndpoint struct {
+	instanceStorage      storage.Instances
+	operationStorage     storage.Operations
+	subscriptions        SubscriptionSecrets
+	migrationQueue       Queue
+	updateQueue          Queue
+	plansSchemaValidator PlansSchemaValidator
+	enabledPlanIDs       map[string]struct{}
+
 	log logrus.FieldLogger
 }
 
-func NewUpdate(log logrus.FieldLogger) *UpdateEndpoint {
-	return &UpdateEndpoint{log: log.WithField("service", "UpdateEndpoint")}
+func NewUpdate(cfg Config, instanceStorage storage.Instances, operationStorage storage.Operations, subscriptions SubscriptionSecrets, migrationQueue Queue, updateQueue Queue, validator PlansSchemaValidator, log logrus.FieldLogger) *UpdateEndpoint {
+	enabledPlanIDs := map[string]struct{}{}
+	for _, planName := range cfg.EnablePlans {
+		id := planIDsMapping[planName]
+		enabledPlanIDs[id] = struct{}{}
+	}
+
+	return &UpdateEndpoint{
+		instanceStorage:      instanceStorage,
+		operationStorage:     operationStorage,
+		subscriptions:        subscriptions,
+		migrationQueue:       migrationQueue,
+		updateQueue:          updateQueue,
+		plansSchemaValidator: validator,
+		enabledPlanIDs:       enabledPlanIDs,
+		log:                  log.WithField("service", "UpdateEndpoint"),
+	}
 }
 
-// Update modifies an existing service instance
+// Update modifies an existing service instance. The instance can be migrated to another plan (only trial to azure
+// is supported), the parameters of the cluster (autoscaler, max surge and unavailable, machine type, OIDC) can be changed
+// or the credentials of the customer-provided subscription can be rotated
 //  PATCH /v2/service_instances/{instance_id}
 func (b *UpdateEndpoint) Update(ctx context.Context, instanceID string, details domain.UpdateDetails, asyncAllowed bool) (domain.UpdateServiceSpec, error) {
-	b.log.Infof("Update instanceID: %s", instanceID)
-	b.log.Infof("Update details: %+v", details)
-	b.log.Infof("Update asyncAllowed: %v", asyncAllowed)
+	correlationID, _ := middleware.CorrelationIDFromContext(ctx)
+	logger := b.log.WithFields(logrus.Fields{"instanceID": instanceID, "planID": details.PlanID, "correlationID": correlationID})
+	logger.Infof("Update called, asyncAllowed: %v", asyncAllowed)
+
+	instance, err := b.instanceStorage.GetByID(instanceID)
+	switch {
+	case dberr.IsNotFound(err):
+		return domain.UpdateServiceSpec{}, apiresponses.ErrInstanceDoesNotExist
+	case err != nil:
+		logger.Errorf("cannot get instance from storage: %s", err)
+		return domain.UpdateServiceSpec{}, errors.New("cannot get instance from storage")
+	}
+
+	if err := checkAccountMigration(b.operationStorage, instance.InstanceID, logger); err != nil {
+		return domain.UpdateServiceSpec{}, err
+	}
+
+	var parameters internal.UpdatingParametersDTO
+	if len(details.RawParameters) > 0 {
+		if err := json.Unmarshal(details.RawParameters, &parameters); err != nil {
+			errMsg := fmt.Sprintf("[instanceID: %s] while unmarshaling raw parameters: %s", instanceID, err)
+			return domain.UpdateServiceSpec{}, apiresponses.NewFailureResponse(err, http.StatusBadRequest, errMsg)
+		}
+	}
+	if details.PlanID != "" && details.PlanID != instance.ServicePlanID {
+		return b.migratePlan(ctx, *instance, details.PlanID, parameters, asyncAllowed, logger)
+	}
+	if parameters.UpdatesCluster() {
+		return b.updateCluster(ctx, *instance, parameters, asyncAllowed, logger)
+	}
+	if parameters.Subscription == nil {
+		err := errors.New("only the plan, the cluster parameters or the credentials of the customer-provided subscription can be updated")
+		return domain.UpdateServiceSpec{}, apiresponses.NewFailureResponse(err, http.StatusUnprocessableEntity, err.Error())
+	}
+
+	pp, err := instance.GetProvisioningParameters()
+	if err != nil {
+		logger.Errorf("cannot get provisioning parameters of the instance: %s", err)
+		return domain.UpdateServiceSpec{}, errors.New("cannot get provisioning parameters of the instance")
+	}
+	secretName, err := rotatedSubscriptionSecret(pp.Parameters.Subscription, *parameters.Subscription)
+	if err != nil {
+		errMsg := fmt.Sprintf("[instanceID: %s] %s", instanceID, err)
+		return domain.UpdateServiceSpec{}, apiresponses.NewFailureResponse(err, http.StatusUnprocessableEntity, errMsg)
+	}
+
+	logger.Infof("Rotating credentials of the customer-provided subscription stored in secret %s", secretName)
+	err = b.subscriptions.Store(instance.ServicePlanID, instance.GlobalAccountID, secretName, parameters.Subscription.Credentials)
+	if err != nil {
+		logger.Errorf("cannot rotate credentials of the customer-provided subscription: %s", err)
+		return domain.UpdateServiceSpec{}, subscriptionFailureResponse(err, instanceID)
+	}
+
+	return domain.UpdateServiceSpec{IsAsync: false}, nil
+}
+
+// migratePlan starts the asynchronous migration of the instance to the given plan. A new runtime is provisioned
+// for the same instance and the runtime of the current plan is removed only when the new runtime is provisioned,
+// so the instance ID and the subaccount registrations are preserved, while the runtime ID and the dashboard URL
+// are replaced
+func (b *UpdateEndpoint) migratePlan(ctx context.Context, instance internal.Instance, planID string, parameters internal.UpdatingParametersDTO, asyncAllowed bool, logger logrus.FieldLogger) (domain.UpdateServiceSpec, error) {
+	logger = logger.WithField("sourcePlanID", instance.ServicePlanID)
+
+	if !isPlanMigrationSupported(instance.ServicePlanID, planID) {
+		err := errors.Errorf("the plan cannot be changed from %s to %s", instance.ServicePlanID, planID)
+		return domain.UpdateServiceSpec{}, apiresponses.NewFailureResponse(err, http.StatusUnprocessableEntity, err.Error())
+	}
+	if _, exists := b.enabledPlanIDs[planID]; !exists {
+		err := errors.Errorf("the plan %s is not available", planID)
+		return domain.UpdateServiceSpec{}, apiresponses.NewFailureResponse(err, http.StatusUnprocessableEntity, err.Error())
+	}
+	if parameters.Subscription != nil || parameters.UpdatesCluster() {
+		err := errors.New("the parameters cannot be updated together with the plan")
+		return domain.UpdateServiceSpec{}, apiresponses.NewFailureResponse(err, http.StatusUnprocessableEntity, err.Error())
+	}
+	if !asyncAllowed {
+		return domain.UpdateServiceSpec{}, apiresponses.ErrAsyncRequired
+	}
+
+	// check if the migration of the instance is already in progress
+	migration, err := b.operationStorage.GetPlanMigrationOperationByInstanceID(instance.InstanceID)
+	switch {
+	case err == nil && migration.State == domain.InProgress:
+		logger.Infof("Plan migration %s is already in progress", migration.ID)
+		return domain.UpdateServiceSpec{IsAsync: true, OperationData: migration.ID}, nil
+	case err != nil && !dberr.IsNotFound(err):
+		logger.Errorf("cannot get plan migration operation from storage: %s", err)
+		return domain.UpdateServiceSpec{}, errors.New("cannot get plan migration operation from storage")
+	}
+	update, err := b.operationStorage.GetUpdatingOperationByInstanceID(instance.InstanceID)
+	switch {
+	case err == nil && update.State == domain.InProgress:
+		return domain.UpdateServiceSpec{}, apiresponses.ErrConcurrentInstanceAccess
+	case err != nil && !dberr.IsNotFound(err):
+		logger.Errorf("cannot get updating operation from storage: %s", err)
+		return domain.UpdateServiceSpec{}, errors.New("cannot get updating operation from storage")
+	}
+
+	if err := b.checkProvisioned(instance, "plan", logger); err != nil {
+		return domain.UpdateServiceSpec{}, err
+	}
+
+	pp, err := instance.GetProvisioningParameters()
+	if err != nil {
+		logger.Errorf("cannot get provisioning parameters of the instance: %s", err)
+		return domain.UpdateServiceSpec{}, errors.New("cannot get provisioning parameters of the instance")
+	}
+	pp.PlanID = planID
+	// the parameters specific to the trial plan are not valid for the target plan, its defaults are used instead
+	pp.Parameters.Provider = nil
+	pp.Parameters.Region = nil
+	pp.Parameters.TargetSecret = nil
+
+	// the provisioning of the source runtime holds its registrations, which are kept by the runtime of the target plan
+	provisioning, err := b.operationStorage.GetProvisioningOperationByInstanceID(instance.InstanceID)
+	if err != nil {
+		logger.Errorf("cannot get provisioning operation from storage: %s", err)
+		return domain.UpdateServiceSpec{}, errors.New("cannot get provisioning operation from storage")
+	}
+
+	operation, err := internal.NewPlanMigrationOperation(instance, pp)
+	if err != nil {
+		logger.Errorf("cannot create plan migration operation: %s", err)
+		return domain.UpdateServiceSpec{}, errors.New("cannot create plan migration operation")
+	}
+	operation.SourceProvisioningOperationID = provisioning.ID
+	operation.CorrelationID, _ = middleware.CorrelationIDFromContext(ctx)
+	err = b.operationStorage.InsertPlanMigrationOperation(operation)
+	if err != nil {
+		logger.Errorf("cannot save plan migration operation: %s", err)
+		return domain.UpdateServiceSpec{}, errors.New("cannot save plan migration operation")
+	}
+
+	logger.Infof("Migrating the instance to the plan, operation %s", operation.ID)
+	b.migrationQueue.Add(operation.ID)
+
+	return domain.UpdateServiceSpec{IsAsync: true, OperationData: operation.ID}, nil
+}
+
+// updateCluster starts the asynchronous update of the cluster of the runtime. The provisioning parameters
+// of the instance are changed when the Provisioner applied the new parameters to the cluster.
+func (b *UpdateEndpoint) updateCluster(ctx context.Context, instance internal.Instance, parameters internal.UpdatingParametersDTO, asyncAllowed bool, logger logrus.FieldLogger) (domain.UpdateServiceSpec, error) {
+	if parameters.Subscription != nil {
+		err := errors.New("the customer-provided subscription cannot be updated together with the cluster parameters")
+		return domain.UpdateServiceSpec{}, apiresponses.NewFailureResponse(err, http.StatusUnprocessableEntity, err.Error())
+	}
+	if instance.ServicePlanID == TrialPlanID {
+		err := errors.New("the cluster parameters of the trial plan cannot be changed")
+		return domain.UpdateServiceSpec{}, apiresponses.NewFailureResponse(err, http.StatusUnprocessableEntity, err.Error())
+	}
+	if !asyncAllowed {
+		return domain.UpdateServiceSpec{}, apiresponses.ErrAsyncRequired
+	}
+
+	// check if the update of the instance is already in progress
+	update, err := b.operationStorage.GetUpdatingOperationByInstanceID(instance.InstanceID)
+	switch {
+	case err == nil && update.State == domain.InProgress && reflect.DeepEqual(update.UpdatingParameters, parameters):
+		logger.Infof("Update %s is already in progress", update.ID)
+		return domain.UpdateServiceSpec{IsAsync: true, OperationData: update.ID}, nil
+	case err == nil && update.State == domain.InProgress:
+		return domain.UpdateServiceSpec{}, apiresponses.ErrConcurrentInstanceAccess
+	case err != nil && !dberr.IsNotFound(err):
+		logger.Errorf("cannot get updating operation from storage: %s", err)
+		return domain.UpdateServiceSpec{}, errors.New("cannot get updating operation from storage")
+	}
+	migration, err := b.operationStorage.GetPlanMigrationOperationByInstanceID(instance.InstanceID)
+	switch {
+	case err == nil && migration.State == domain.InProgress:
+		return domain.UpdateServiceSpec{}, apiresponses.ErrConcurrentInstanceAccess
+	case err != nil && !dberr.IsNotFound(err):
+		logger.Errorf("cannot get plan migration operation from storage: %s", err)
+		return domain.UpdateServiceSpec{}, errors.New("cannot get plan migration operation from storage")
+	}
+
+	if err := b.checkProvisioned(instance, "cluster parameters", logger); err != nil {
+		return domain.UpdateServiceSpec{}, err
+	}
+
+	pp, err := instance.GetProvisioningParameters()
+	if err != nil {
+		logger.Errorf("cannot get provisioning parameters of the instance: %s", err)
+		return domain.UpdateServiceSpec{}, errors.New("cannot get provisioning parameters of the instance")
+	}
+	if err := b.validateClusterParameters(instance.ServicePlanID, pp.Parameters, parameters); err != nil {
+		errMsg := fmt.Sprintf("[instanceID: %s] %s", instance.InstanceID, err)
+		return domain.UpdateServiceSpec{}, apiresponses.NewFailureResponse(err, http.StatusBadRequest, errMsg)
+	}
+
+	operation := internal.NewUpdatingOperation(instance, parameters)
+	operation.CorrelationID, _ = middleware.CorrelationIDFromContext(ctx)
+	err = b.operationStorage.InsertUpdatingOperation(operation)
+	if err != nil {
+		logger.Errorf("cannot save updating operation: %s", err)
+		return domain.UpdateServiceSpec{}, errors.New("cannot save updating operation")
+	}
+
+	logger.Infof("Updating the cluster parameters of the instance, operation %s", operation.ID)
+	b.updateQueue.Add(operation.ID)
+
+	return domain.UpdateServiceSpec{IsAsync: true, OperationData: operation.ID}, nil
+}
+
+// checkProvisioned returns the failure response if the instance is not provisioned successfully, is being deprovisioned
+// or its runtime is suspended
+func (b *UpdateEndpoint) checkProvisioned(instance internal.Instance, change string, logger logrus.FieldLogger) error {
+	provisioning, err := b.operationStorage.GetProvisioningOperationByInstanceID(instance.InstanceID)
+	if err != nil {
+		logger.Errorf("cannot get provisioning operation from storage: %s", err)
+		return errors.New("cannot get provisioning operation from storage")
+	}
+	if provisioning.State != domain.Succeeded {
+		err := errors.Errorf("the %s can be changed only when the instance is provisioned", change)
+		return apiresponses.NewFailureResponse(err, http.StatusUnprocessableEntity, err.Error())
+	}
+	_, err = b.operationStorage.GetDeprovisioningOperationByInstanceID(instance.InstanceID)
+	switch {
+	case err == nil:
+		err := errors.Errorf("the %s cannot be changed when the instance is being deprovisioned", change)
+		return apiresponses.NewFailureResponse(err, http.StatusUnprocessableEntity, err.Error())
+	case !dberr.IsNotFound(err):
+		logger.Errorf("cannot get deprovisioning operation from storage: %s", err)
+		return errors.New("cannot get deprovisioning operation from storage")
+	}
+	return b.checkSuspension(instance, change, logger)
+}
+
+// checkSuspension returns the failure response if the runtime is suspended or being suspended or resumed, because
+// the cluster is hibernated. The runtime which suspension or resumption failed must be resumed first, the state
+// of its cluster is not known.
+func (b *UpdateEndpoint) checkSuspension(instance internal.Instance, change string, logger logrus.FieldLogger) error {
+	suspension, err := b.operationStorage.GetSuspensionOperationByInstanceID(instance.InstanceID)
+	switch {
+	case dberr.IsNotFound(err):
+		return nil
+	case err != nil:
+		logger.Errorf("cannot get suspension operation from storage: %s", err)
+		return errors.New("cannot get suspension operation from storage")
+	case suspension.State == domain.InProgress:
+		logger.Infof("Suspension %s is in progress", suspension.ID)
+		return apiresponses.ErrConcurrentInstanceAccess
+	case suspension.Suspends() || suspension.State == domain.Failed:
+		err := errors.Errorf("the %s cannot be changed when the runtime is suspended", change)
+		return apiresponses.NewFailureResponse(err, http.StatusUnprocessableEntity, err.Error())
+	}
+	return nil
+}
+
+// clusterParameters are the parameters of the cluster validated with the schema of the plan
+type clusterParameters struct {
+	Name           string  `json:"name"`
+	MachineType    *string `json:"machineType,omitempty"`
+	AutoScalerMin  *int    `json:"autoScalerMin,omitempty"`
+	AutoScalerMax  *int    `json:"autoScalerMax,omitempty"`
+	MaxSurge       *int    `json:"maxSurge,omitempty"`
+	MaxUnavailable *int    `json:"maxUnavailable,omitempty"`
+}
+
+// validateClusterParameters validates the changed parameters of the cluster with the schema of the plan
+// and the autoscaler limits resulting from the update of the provisioning parameters
+func (b *UpdateEndpoint) validateClusterParameters(planID string, provisioning internal.ProvisioningParametersDTO, parameters internal.UpdatingParametersDTO) error {
+	parameters.ApplyTo(&provisioning)
+	if provisioning.AutoScalerMin != nil && provisioning.AutoScalerMax != nil && *provisioning.AutoScalerMin > *provisioning.AutoScalerMax {
+		return errors.Errorf("autoScalerMin %d cannot be greater than autoScalerMax %d", *provisioning.AutoScalerMin, *provisioning.AutoScalerMax)
+	}
+	if parameters.OIDC != nil {
+		if err := parameters.OIDC.Validate(); err != nil {
+			return err
+		}
+	}
+
+	validator, found := b.plansSchemaValidator[planID]
+	if !found {
+		return nil
+	}
+	raw, err := json.Marshal(clusterParameters{
+		Name:           provisioning.Name,
+		MachineType:    parameters.MachineType,
+		AutoScalerMin:  parameters.AutoScalerMin,
+		AutoScalerMax:  parameters.AutoScalerMax,
+		MaxSurge:       parameters.MaxSurge,
+		MaxUnavailable: parameters.MaxUnavailable,
+	})
+	if err != nil {
+		return errors.Wrap(err, "while marshaling cluster parameters")
+	}
+	result, err := validator.ValidateString(string(raw))
+	if err != nil {
+		return errors.Wrap(err, "while executing JSON schema validator")
+	}
+	if !result.Valid {
+		return errors.Wrap(result.Error, "while validating input parameters")
+	}
+	return nil
+}
+
+func isPlanMigrationSupported(sourcePlanID, targetPlanID string) bool {
+	for _, planID := range supportedPlanMigrations[sourcePlanID] {
+		if planID == targetPlanID {
+			return true
+		}
+	}
+	return false
+}
+
+// rotatedSubscriptionSecret returns the name of the secret which credentials are replaced,
+// the secret used by the cluster cannot be changed
+func rotatedSubscriptionSecret(provisioned *internal.SubscriptionDTO, update internal.SubscriptionDTO) (string, error) {
+	switch {
+	case provisioned == nil:
+		return "", errors.New("the instance was not provisioned in the customer-provided subscription")
+	case update.SecretName != "" && update.SecretName != provisioned.SecretName:
+		return "", errors.Errorf("the subscription secret cannot be changed from %s", provisioned.SecretName)
+	case len(update.Credentials) == 0:
+		return "", errors.New("subscription credentials are required")
+	}
+
+	return provisioned.SecretName, nil
+}
 
-	return domain.UpdateServiceSpec{}, errors.New("not supported")
+// checkAccountMigration rejects the operation while the instance is migrated to another global account,
+// because the migration changes the accounts of the instance and reverts them if it fails
+func checkAccountMigration(operations storage.AccountMigration, instanceID string, logger logrus.FieldLogger) error {
+	migration, err := operations.GetAccountMigrationOperationByInstanceID(instanceID)
+	switch {
+	case err == nil && migration.State == domain.InProgress:
+		logger.Infof("Account migration %s is in progress", migration.ID)
+		return apiresponses.ErrConcurrentInstanceAccess
+	case err != nil && !dberr.IsNotFound(err):
+		logger.Errorf("cannot get account migration operation from storage: %s", err)
+		return errors.New("cannot get account migration operation from storage")
+	}
+	return nil
 }
@@ -0,0 +1,520 @@
+package broker_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/broker/automock"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/ptr"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/pivotal-cf/brokerapi/v7/domain/apiresponses"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateEndpoint_Update(t *testing.T) {
+	t.Run("should rotate credentials of the customer-provided subscription", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		err := memoryStorage.Instances().Insert(fixInstanceWithSubscription("customer-secret"))
+		require.NoError(t, err)
+
+		credentials := map[string]string{"clientID": "id", "clientSecret": "rotated"}
+		subscriptions := &automock.SubscriptionSecrets{}
+		subscriptions.On("Store", planID, globalAccountID, "customer-secret", credentials).Return(nil).Once()
+		defer subscriptions.AssertExpectations(t)
+
+		updateEndpoint := broker.NewUpdate(broker.Config{}, memoryStorage.Instances(), memoryStorage.Operations(), subscriptions, &automock.Queue{}, &automock.Queue{}, broker.PlansSchemaValidator{}, logrus.StandardLogger())
+
+		// when
+		response, err := updateEndpoint.Update(context.Background(), instanceID, domain.UpdateDetails{
+			PlanID:        planID,
+			RawParameters: json.RawMessage(`{"subscription": {"credentials": {"clientID": "id", "clientSecret": "rotated"}}}`),
+		}, true)
+
+		// then
+		require.NoError(t, err)
+		assert.False(t, response.IsAsync)
+	})
+
+	t.Run("should not change the secret of the customer-provided subscription", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		err := memoryStorage.Instances().Insert(fixInstanceWithSubscription("customer-secret"))
+		require.NoError(t, err)
+
+		updateEndpoint := broker.NewUpdate(broker.Config{}, memoryStorage.Instances(), memoryStorage.Operations(), &automock.SubscriptionSecrets{}, &automock.Queue{}, &automock.Queue{}, broker.PlansSchemaValidator{}, logrus.StandardLogger())
+
+		// when
+		_, err = updateEndpoint.Update(context.Background(), instanceID, domain.UpdateDetails{
+			PlanID:        planID,
+			RawParameters: json.RawMessage(`{"subscription": {"secretName": "other-secret", "credentials": {"clientID": "id"}}}`),
+		}, true)
+
+		// then
+		assertFailureStatus(t, err, http.StatusUnprocessableEntity)
+	})
+
+	t.Run("should reject update of instance provisioned in the accounts pool", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		instance := fixInstance()
+		instance.ProvisioningParameters = `{"parameters": {"name": "cluster-testing"}}`
+		err := memoryStorage.Instances().Insert(instance)
+		require.NoError(t, err)
+
+		updateEndpoint := broker.NewUpdate(broker.Config{}, memoryStorage.Instances(), memoryStorage.Operations(), &automock.SubscriptionSecrets{}, &automock.Queue{}, &automock.Queue{}, broker.PlansSchemaValidator{}, logrus.StandardLogger())
+
+		// when
+		_, err = updateEndpoint.Update(context.Background(), instanceID, domain.UpdateDetails{
+			PlanID:        planID,
+			RawParameters: json.RawMessage(`{"subscription": {"credentials": {"clientID": "id"}}}`),
+		}, true)
+
+		// then
+		assertFailureStatus(t, err, http.StatusUnprocessableEntity)
+	})
+
+	t.Run("should start migration of the trial instance to the azure plan", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		err := memoryStorage.Instances().Insert(fixTrialInstance())
+		require.NoError(t, err)
+		err = memoryStorage.Operations().InsertProvisioningOperation(fixProvisioningOperation(domain.Succeeded))
+		require.NoError(t, err)
+
+		queue := &automock.Queue{}
+		queue.On("Add", mock.AnythingOfType("string")).Return().Once()
+		defer queue.AssertExpectations(t)
+
+		updateEndpoint := broker.NewUpdate(broker.Config{EnablePlans: []string{"azure", "trial"}}, memoryStorage.Instances(), memoryStorage.Operations(), &automock.SubscriptionSecrets{}, queue, &automock.Queue{}, broker.PlansSchemaValidator{}, logrus.StandardLogger())
+
+		// when
+		response, err := updateEndpoint.Update(context.Background(), instanceID, domain.UpdateDetails{PlanID: broker.AzurePlanID}, true)
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, response.IsAsync)
+
+		operation, err := memoryStorage.Operations().GetPlanMigrationOperationByID(response.OperationData)
+		require.NoError(t, err)
+		assert.Equal(t, domain.InProgress, operation.State)
+		assert.Equal(t, broker.TrialPlanID, operation.SourcePlanID)
+		assert.Equal(t, "runtime-id", operation.SourceRuntimeID)
+		assert.Equal(t, fixProvisioningOperation(domain.Succeeded).ID, operation.SourceProvisioningOperationID)
+		assert.Equal(t, fixTrialInstance().ProvisioningParameters, operation.SourceProvisioningParameters)
+
+		pp, err := operation.GetProvisioningParameters()
+		require.NoError(t, err)
+		assert.Equal(t, broker.AzurePlanID, pp.PlanID)
+		assert.Equal(t, clusterName, pp.Parameters.Name)
+		assert.Nil(t, pp.Parameters.Region)
+	})
+
+	t.Run("should return the plan migration in progress", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		instance := fixTrialInstance()
+		err := memoryStorage.Instances().Insert(instance)
+		require.NoError(t, err)
+		migration, err := internal.NewPlanMigrationOperation(instance, internal.ProvisioningParameters{PlanID: broker.AzurePlanID})
+		require.NoError(t, err)
+		err = memoryStorage.Operations().InsertPlanMigrationOperation(migration)
+		require.NoError(t, err)
+
+		updateEndpoint := broker.NewUpdate(broker.Config{EnablePlans: []string{"azure", "trial"}}, memoryStorage.Instances(), memoryStorage.Operations(), &automock.SubscriptionSecrets{}, &automock.Queue{}, &automock.Queue{}, broker.PlansSchemaValidator{}, logrus.StandardLogger())
+
+		// when
+		response, err := updateEndpoint.Update(context.Background(), instanceID, domain.UpdateDetails{PlanID: broker.AzurePlanID}, true)
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, response.IsAsync)
+		assert.Equal(t, migration.ID, response.OperationData)
+	})
+
+	t.Run("should reject plan migration which is not supported", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		err := memoryStorage.Instances().Insert(fixTrialInstance())
+		require.NoError(t, err)
+
+		updateEndpoint := broker.NewUpdate(broker.Config{EnablePlans: []string{"gcp", "trial"}}, memoryStorage.Instances(), memoryStorage.Operations(), &automock.SubscriptionSecrets{}, &automock.Queue{}, &automock.Queue{}, broker.PlansSchemaValidator{}, logrus.StandardLogger())
+
+		// when
+		_, err = updateEndpoint.Update(context.Background(), instanceID, domain.UpdateDetails{PlanID: broker.GCPPlanID}, true)
+
+		// then
+		assertFailureStatus(t, err, http.StatusUnprocessableEntity)
+	})
+
+	t.Run("should reject plan migration when the instance is not provisioned", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		err := memoryStorage.Instances().Insert(fixTrialInstance())
+		require.NoError(t, err)
+		err = memoryStorage.Operations().InsertProvisioningOperation(fixProvisioningOperation(domain.InProgress))
+		require.NoError(t, err)
+
+		updateEndpoint := broker.NewUpdate(broker.Config{EnablePlans: []string{"azure", "trial"}}, memoryStorage.Instances(), memoryStorage.Operations(), &automock.SubscriptionSecrets{}, &automock.Queue{}, &automock.Queue{}, broker.PlansSchemaValidator{}, logrus.StandardLogger())
+
+		// when
+		_, err = updateEndpoint.Update(context.Background(), instanceID, domain.UpdateDetails{PlanID: broker.AzurePlanID}, true)
+
+		// then
+		assertFailureStatus(t, err, http.StatusUnprocessableEntity)
+	})
+
+	t.Run("should reject plan migration when the update is in progress", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		instance := fixTrialInstance()
+		err := memoryStorage.Instances().Insert(instance)
+		require.NoError(t, err)
+		err = memoryStorage.Operations().InsertProvisioningOperation(fixProvisioningOperation(domain.Succeeded))
+		require.NoError(t, err)
+		update := internal.NewUpdatingOperation(instance, internal.UpdatingParametersDTO{AutoScalerMax: ptr.Integer(6)})
+		err = memoryStorage.Operations().InsertUpdatingOperation(update)
+		require.NoError(t, err)
+
+		updateEndpoint := broker.NewUpdate(broker.Config{EnablePlans: []string{"azure", "trial"}}, memoryStorage.Instances(), memoryStorage.Operations(), &automock.SubscriptionSecrets{}, &automock.Queue{}, &automock.Queue{}, broker.PlansSchemaValidator{}, logrus.StandardLogger())
+
+		// when
+		_, err = updateEndpoint.Update(context.Background(), instanceID, domain.UpdateDetails{PlanID: broker.AzurePlanID}, true)
+
+		// then
+		assert.Equal(t, apiresponses.ErrConcurrentInstanceAccess, err)
+	})
+
+	t.Run("should start update of the cluster parameters", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		err := memoryStorage.Instances().Insert(fixInstanceWithClusterParameters())
+		require.NoError(t, err)
+		err = memoryStorage.Operations().InsertProvisioningOperation(fixProvisioningOperation(domain.Succeeded))
+		require.NoError(t, err)
+
+		queue := &automock.Queue{}
+		queue.On("Add", mock.AnythingOfType("string")).Return().Once()
+		defer queue.AssertExpectations(t)
+
+		validator, err := broker.NewPlansSchemaValidator()
+		require.NoError(t, err)
+
+		updateEndpoint := broker.NewUpdate(broker.Config{}, memoryStorage.Instances(), memoryStorage.Operations(), &automock.SubscriptionSecrets{}, &automock.Queue{}, queue, validator, logrus.StandardLogger())
+
+		// when
+		response, err := updateEndpoint.Update(context.Background(), instanceID, domain.UpdateDetails{
+			RawParameters: json.RawMessage(`{"autoScalerMin": 3, "machineType": "Standard_D8_v3"}`),
+		}, true)
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, response.IsAsync)
+
+		operation, err := memoryStorage.Operations().GetUpdatingOperationByID(response.OperationData)
+		require.NoError(t, err)
+		assert.Equal(t, domain.InProgress, operation.State)
+		assert.Equal(t, 3, *operation.UpdatingParameters.AutoScalerMin)
+		assert.Equal(t, "Standard_D8_v3", *operation.UpdatingParameters.MachineType)
+		assert.Nil(t, operation.UpdatingParameters.AutoScalerMax)
+
+		instance, err := memoryStorage.Instances().GetByID(instanceID)
+		require.NoError(t, err)
+		pp, err := instance.GetProvisioningParameters()
+		require.NoError(t, err)
+		assert.Equal(t, 2, *pp.Parameters.AutoScalerMin, "the instance must be updated only when the update succeeded")
+	})
+
+	t.Run("should start update of the OIDC configuration", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		err := memoryStorage.Instances().Insert(fixInstanceWithClusterParameters())
+		require.NoError(t, err)
+		err = memoryStorage.Operations().InsertProvisioningOperation(fixProvisioningOperation(domain.Succeeded))
+		require.NoError(t, err)
+
+		queue := &automock.Queue{}
+		queue.On("Add", mock.AnythingOfType("string")).Return().Once()
+		defer queue.AssertExpectations(t)
+
+		validator, err := broker.NewPlansSchemaValidator()
+		require.NoError(t, err)
+
+		updateEndpoint := broker.NewUpdate(broker.Config{}, memoryStorage.Instances(), memoryStorage.Operations(), &automock.SubscriptionSecrets{}, &automock.Queue{}, queue, validator, logrus.StandardLogger())
+
+		// when
+		response, err := updateEndpoint.Update(context.Background(), instanceID, domain.UpdateDetails{
+			RawParameters: json.RawMessage(`{"oidc": {"clientID": "client", "issuerURL": "https://issuer.example.com", "signingAlgs": ["RS256"]}}`),
+		}, true)
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, response.IsAsync)
+
+		operation, err := memoryStorage.Operations().GetUpdatingOperationByID(response.OperationData)
+		require.NoError(t, err)
+		assert.Equal(t, &internal.OIDCConfigDTO{
+			ClientID:    "client",
+			IssuerURL:   "https://issuer.example.com",
+			SigningAlgs: []string{"RS256"},
+		}, operation.UpdatingParameters.OIDC)
+	})
+
+	t.Run("should return the update in progress", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		instance := fixInstanceWithClusterParameters()
+		err := memoryStorage.Instances().Insert(instance)
+		require.NoError(t, err)
+		update := internal.NewUpdatingOperation(instance, internal.UpdatingParametersDTO{AutoScalerMax: ptr.Integer(6)})
+		err = memoryStorage.Operations().InsertUpdatingOperation(update)
+		require.NoError(t, err)
+
+		updateEndpoint := broker.NewUpdate(broker.Config{}, memoryStorage.Instances(), memoryStorage.Operations(), &automock.SubscriptionSecrets{}, &automock.Queue{}, &automock.Queue{}, broker.PlansSchemaValidator{}, logrus.StandardLogger())
+
+		// when
+		response, err := updateEndpoint.Update(context.Background(), instanceID, domain.UpdateDetails{
+			RawParameters: json.RawMessage(`{"autoScalerMax": 6}`),
+		}, true)
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, response.IsAsync)
+		assert.Equal(t, update.ID, response.OperationData)
+	})
+
+	t.Run("should reject other update when the update is in progress", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		instance := fixInstanceWithClusterParameters()
+		err := memoryStorage.Instances().Insert(instance)
+		require.NoError(t, err)
+		update := internal.NewUpdatingOperation(instance, internal.UpdatingParametersDTO{AutoScalerMax: ptr.Integer(6)})
+		err = memoryStorage.Operations().InsertUpdatingOperation(update)
+		require.NoError(t, err)
+
+		updateEndpoint := broker.NewUpdate(broker.Config{}, memoryStorage.Instances(), memoryStorage.Operations(), &automock.SubscriptionSecrets{}, &automock.Queue{}, &automock.Queue{}, broker.PlansSchemaValidator{}, logrus.StandardLogger())
+
+		// when
+		_, err = updateEndpoint.Update(context.Background(), instanceID, domain.UpdateDetails{
+			RawParameters: json.RawMessage(`{"autoScalerMax": 8}`),
+		}, true)
+
+		// then
+		assert.Equal(t, apiresponses.ErrConcurrentInstanceAccess, err)
+	})
+
+	t.Run("should reject update when the account migration is in progress", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		instance := fixInstanceWithClusterParameters()
+		err := memoryStorage.Instances().Insert(instance)
+		require.NoError(t, err)
+		err = memoryStorage.Operations().InsertAccountMigrationOperation(internal.NewAccountMigrationOperation(instance, "new-ga", "new-sa"))
+		require.NoError(t, err)
+
+		updateEndpoint := broker.NewUpdate(broker.Config{}, memoryStorage.Instances(), memoryStorage.Operations(), &automock.SubscriptionSecrets{}, &automock.Queue{}, &automock.Queue{}, broker.PlansSchemaValidator{}, logrus.StandardLogger())
+
+		// when
+		_, err = updateEndpoint.Update(context.Background(), instanceID, domain.UpdateDetails{
+			RawParameters: json.RawMessage(`{"autoScalerMax": 8}`),
+		}, true)
+
+		// then
+		assert.Equal(t, apiresponses.ErrConcurrentInstanceAccess, err)
+	})
+
+	t.Run("should reject update when the runtime is suspended", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		instance := fixInstanceWithClusterParameters()
+		err := memoryStorage.Instances().Insert(instance)
+		require.NoError(t, err)
+		err = memoryStorage.Operations().InsertProvisioningOperation(fixProvisioningOperation(domain.Succeeded))
+		require.NoError(t, err)
+		suspension := internal.NewSuspensionOperation(instance, internal.SuspensionActionSuspend)
+		suspension.State = domain.Succeeded
+		err = memoryStorage.Operations().InsertSuspensionOperation(suspension)
+		require.NoError(t, err)
+
+		updateEndpoint := broker.NewUpdate(broker.Config{}, memoryStorage.Instances(), memoryStorage.Operations(), &automock.SubscriptionSecrets{}, &automock.Queue{}, &automock.Queue{}, broker.PlansSchemaValidator{}, logrus.StandardLogger())
+
+		// when
+		_, err = updateEndpoint.Update(context.Background(), instanceID, domain.UpdateDetails{
+			RawParameters: json.RawMessage(`{"autoScalerMax": 8}`),
+		}, true)
+
+		// then
+		assertFailureStatus(t, err, http.StatusUnprocessableEntity)
+
+		// given
+		resumption := internal.NewSuspensionOperation(instance, internal.SuspensionActionResume)
+		resumption.CreatedAt = suspension.CreatedAt.Add(time.Minute)
+		err = memoryStorage.Operations().InsertSuspensionOperation(resumption)
+		require.NoError(t, err)
+
+		// when
+		_, err = updateEndpoint.Update(context.Background(), instanceID, domain.UpdateDetails{
+			RawParameters: json.RawMessage(`{"autoScalerMax": 8}`),
+		}, true)
+
+		// then
+		assert.Equal(t, apiresponses.ErrConcurrentInstanceAccess, err, "the runtime is being resumed")
+	})
+
+	for name, tc := range map[string]struct {
+		instance       internal.Instance
+		provisioning   domain.LastOperationState
+		parameters     string
+		asyncAllowed   bool
+		expectedStatus int
+	}{
+		"should reject autoScalerMin greater than autoScalerMax of the instance": {
+			instance:       fixInstanceWithClusterParameters(),
+			provisioning:   domain.Succeeded,
+			parameters:     `{"autoScalerMin": 5}`,
+			asyncAllowed:   true,
+			expectedStatus: http.StatusBadRequest,
+		},
+		"should reject machine type which is not valid for the plan": {
+			instance:       fixInstanceWithClusterParameters(),
+			provisioning:   domain.Succeeded,
+			parameters:     `{"machineType": "n1-standard-4"}`,
+			asyncAllowed:   true,
+			expectedStatus: http.StatusBadRequest,
+		},
+		"should reject OIDC configuration without client ID": {
+			instance:       fixInstanceWithClusterParameters(),
+			provisioning:   domain.Succeeded,
+			parameters:     `{"oidc": {"issuerURL": "https://issuer.example.com"}}`,
+			asyncAllowed:   true,
+			expectedStatus: http.StatusBadRequest,
+		},
+		"should reject OIDC configuration with issuer which is not https": {
+			instance:       fixInstanceWithClusterParameters(),
+			provisioning:   domain.Succeeded,
+			parameters:     `{"oidc": {"clientID": "client", "issuerURL": "http://issuer.example.com"}}`,
+			asyncAllowed:   true,
+			expectedStatus: http.StatusBadRequest,
+		},
+		"should reject update of the cluster parameters of the trial plan": {
+			instance:       fixTrialInstance(),
+			provisioning:   domain.Succeeded,
+			parameters:     `{"autoScalerMax": 6}`,
+			asyncAllowed:   true,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		"should reject update of the cluster parameters together with the subscription": {
+			instance:       fixInstanceWithClusterParameters(),
+			provisioning:   domain.Succeeded,
+			parameters:     `{"autoScalerMax": 6, "subscription": {"secretName": "secret"}}`,
+			asyncAllowed:   true,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		"should reject update of the cluster parameters when the instance is not provisioned": {
+			instance:       fixInstanceWithClusterParameters(),
+			provisioning:   domain.InProgress,
+			parameters:     `{"autoScalerMax": 6}`,
+			asyncAllowed:   true,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		"should require asynchronous update of the cluster parameters": {
+			instance:       fixInstanceWithClusterParameters(),
+			provisioning:   domain.Succeeded,
+			parameters:     `{"autoScalerMax": 6}`,
+			asyncAllowed:   false,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// given
+			memoryStorage := storage.NewMemoryStorage()
+			err := memoryStorage.Instances().Insert(tc.instance)
+			require.NoError(t, err)
+			err = memoryStorage.Operations().InsertProvisioningOperation(fixProvisioningOperation(tc.provisioning))
+			require.NoError(t, err)
+
+			validator, err := broker.NewPlansSchemaValidator()
+			require.NoError(t, err)
+
+			updateEndpoint := broker.NewUpdate(broker.Config{}, memoryStorage.Instances(), memoryStorage.Operations(), &automock.SubscriptionSecrets{}, &automock.Queue{}, &automock.Queue{}, validator, logrus.StandardLogger())
+
+			// when
+			_, err = updateEndpoint.Update(context.Background(), instanceID, domain.UpdateDetails{
+				RawParameters: json.RawMessage(tc.parameters),
+			}, tc.asyncAllowed)
+
+			// then
+			assertFailureStatus(t, err, tc.expectedStatus)
+		})
+	}
+
+	t.Run("should return error when instance does not exist", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		updateEndpoint := broker.NewUpdate(broker.Config{}, memoryStorage.Instances(), memoryStorage.Operations(), &automock.SubscriptionSecrets{}, &automock.Queue{}, &automock.Queue{}, broker.PlansSchemaValidator{}, logrus.StandardLogger())
+
+		// when
+		_, err := updateEndpoint.Update(context.Background(), instanceID, domain.UpdateDetails{PlanID: planID}, true)
+
+		// then
+		assert.Equal(t, apiresponses.ErrInstanceDoesNotExist, err)
+	})
+}
+
+func fixInstanceWithSubscription(secretName string) internal.Instance {
+	instance := fixInstance()
+	instance.ProvisioningParameters = fmt.Sprintf(`{"plan_id": "%s", "parameters": {"name": "%s", "targetSecret": "%s", "subscription": {"secretName": "%s"}}}`,
+		planID, clusterName, secretName, secretName)
+
+	return instance
+}
+
+func fixInstanceWithClusterParameters() internal.Instance {
+	instance := fixInstance()
+	instance.ProvisioningParameters = fmt.Sprintf(`{"plan_id": "%s", "parameters": {"name": "%s", "autoScalerMin": 2, "autoScalerMax": 4, "machineType": "Standard_D8_v3"}}`,
+		planID, clusterName)
+
+	return instance
+}
+
+func fixTrialInstance() internal.Instance {
+	instance := fixInstance()
+	instance.ServicePlanID = broker.TrialPlanID
+	instance.ServicePlanName = broker.TrialPlanName
+	instance.RuntimeID = "runtime-id"
+	instance.ProvisioningParameters = fmt.Sprintf(`{"plan_id": "%s", "parameters": {"name": "%s", "region": "europe"}}`,
+		broker.TrialPlanID, clusterName)
+
+	return instance
+}
+
+func fixProvisioningOperation(state domain.LastOperationState) internal.ProvisioningOperation {
+	return internal.ProvisioningOperation{
+		Operation: internal.Operation{
+			ID:         "provisioning-id",
+			InstanceID: instanceID,
+			State:      state,
+		},
+	}
+}
+
+func assertFailureStatus(t *testing.T, err error, status int) {
+	t.Helper()
+
+	require.Error(t, err)
+	failure, ok := err.(*apiresponses.FailureResponse)
+	require.True(t, ok)
+	assert.Equal(t, status, failure.ValidatedStatusCode(nil))
+}
@@ -49,13 +49,16 @@ type Type struct {
 	Items           []Type        `json:"items,omitempty"`
 	AdditionalItems *bool         `json:"additionalItems,omitempty"`
 	UniqueItems     *bool         `json:"uniqueItems,omitempty"`
+	// Properties and Required describe the nested object parameters
+	Properties map[string]Type `json:"properties,omitempty"`
+	Required   []string        `json:"required,omitempty"`
 }
 
 type RootSchema struct {
 	Schema string `json:"$schema"`
 	Type
 	Properties interface{} `json:"properties"`
-	Required   []string    `json:"required"`
+	Required   []string    `json:"required,omitempty"`
 }
 
 type ProvisioningProperties struct {
@@ -70,6 +73,69 @@ type ProvisioningProperties struct {
 	AutoScalerMax  Type `json:"autoScalerMax"`
 	MaxSurge       Type `json:"maxSurge"`
 	MaxUnavailable Type `json:"maxUnavailable"`
+	Registry       Type `json:"registry"`
+	Subscription   Type `json:"subscription"`
+}
+
+// UpdateProperties are the parameters which can be changed by the update of the instance, see internal.UpdatingParametersDTO
+type UpdateProperties struct {
+	MachineType    Type `json:"machineType"`
+	AutoScalerMin  Type `json:"autoScalerMin"`
+	AutoScalerMax  Type `json:"autoScalerMax"`
+	MaxSurge       Type `json:"maxSurge"`
+	MaxUnavailable Type `json:"maxUnavailable"`
+	Subscription   Type `json:"subscription"`
+	OIDC           Type `json:"oidc"`
+}
+
+// registrySchema describes the private container registry mirror, see internal.RegistryDTO
+func registrySchema() Type {
+	return Type{
+		Type: "object",
+		Properties: map[string]Type{
+			"url": {Type: "string"},
+			"pullSecrets": {
+				Type: "array",
+				Items: []Type{{
+					Type: "object",
+					Properties: map[string]Type{
+						"name":     {Type: "string"},
+						"username": {Type: "string"},
+						"password": {Type: "string"},
+					},
+					Required: []string{"name", "username", "password"},
+				}},
+			},
+		},
+		Required: []string{"url"},
+	}
+}
+
+// subscriptionSchema describes the customer-provided hyperscaler subscription, see internal.SubscriptionDTO
+func subscriptionSchema() Type {
+	return Type{
+		Type: "object",
+		Properties: map[string]Type{
+			"secretName":  {Type: "string"},
+			"credentials": {Type: "object"},
+		},
+	}
+}
+
+// oidcSchema describes the OpenID Connect configuration of the kube-apiserver, see internal.OIDCConfigDTO
+func oidcSchema() Type {
+	return Type{
+		Type: "object",
+		Properties: map[string]Type{
+			"clientID":       {Type: "string"},
+			"issuerURL":      {Type: "string"},
+			"groupsClaim":    {Type: "string"},
+			"signingAlgs":    {Type: "array", Items: []Type{{Type: "string"}}},
+			"usernameClaim":  {Type: "string"},
+			"usernamePrefix": {Type: "string"},
+		},
+		Required: []string{"clientID", "issuerURL"},
+	}
 }
 
 func GCPSchema(machineTypes []string) []byte {
@@ -158,6 +224,8 @@ func GCPSchema(machineTypes []string) []byte {
 			MaxUnavailable: Type{
 				Type: "integer",
 			},
+			Registry:     registrySchema(),
+			Subscription: subscriptionSchema(),
 		},
 		Required: []string{"name"},
 	}
@@ -224,6 +292,8 @@ func AzureSchema(machineTypes []string) []byte {
 			MaxUnavailable: Type{
 				Type: "integer",
 			},
+			Registry:     registrySchema(),
+			Subscription: subscriptionSchema(),
 		},
 		Required: []string{"name"},
 	}
@@ -235,6 +305,42 @@ func AzureSchema(machineTypes []string) []byte {
 	return bytes
 }
 
+// UpdateSchema returns the schema of the parameters of the instance update, the trial plan does not support the update
+func UpdateSchema(machineTypes []string) []byte {
+	rs := RootSchema{
+		Schema: "http://json-schema.org/draft-04/schema#",
+		Type: Type{
+			Type: "object",
+		},
+		Properties: UpdateProperties{
+			MachineType: Type{
+				Type: "string",
+				Enum: ToInterfaceSlice(machineTypes),
+			},
+			AutoScalerMin: Type{
+				Type: "integer",
+			},
+			AutoScalerMax: Type{
+				Type: "integer",
+			},
+			MaxSurge: Type{
+				Type: "integer",
+			},
+			MaxUnavailable: Type{
+				Type: "integer",
+			},
+			Subscription: subscriptionSchema(),
+			OIDC:         oidcSchema(),
+		},
+	}
+
+	bytes, err := json.Marshal(rs)
+	if err != nil {
+		panic(err)
+	}
+	return bytes
+}
+
 func TrialSchema() []byte {
 	schema := `{
   "$schema": "http://json-schema.org/draft-04/schema#",
@@ -280,6 +386,8 @@ func ToInterfaceSlice(input []string) []interface{} {
 var Plans = map[string]struct {
 	PlanDefinition        domain.ServicePlan
 	provisioningRawSchema []byte
+	// updateRawSchema is nil for the plans which do not support the update
+	updateRawSchema []byte
 }{
 	GCPPlanID: {
 		PlanDefinition: domain.ServicePlan{
@@ -298,6 +406,7 @@ var Plans = map[string]struct {
 			},
 		},
 		provisioningRawSchema: GCPSchema([]string{"n1-standard-2", "n1-standard-4", "n1-standard-8", "n1-standard-16", "n1-standard-32", "n1-standard-64"}),
+		updateRawSchema:       UpdateSchema([]string{"n1-standard-2", "n1-standard-4", "n1-standard-8", "n1-standard-16", "n1-standard-32", "n1-standard-64"}),
 	},
 	AzurePlanID: {
 		PlanDefinition: domain.ServicePlan{
@@ -316,6 +425,7 @@ var Plans = map[string]struct {
 			},
 		},
 		provisioningRawSchema: AzureSchema([]string{"Standard_D8_v3"}),
+		updateRawSchema:       UpdateSchema([]string{"Standard_D8_v3"}),
 	},
 	AzureLitePlanID: {
 		PlanDefinition: domain.ServicePlan{
@@ -334,6 +444,7 @@ var Plans = map[string]struct {
 			},
 		},
 		provisioningRawSchema: AzureSchema([]string{"Standard_D4_v3"}),
+		updateRawSchema:       UpdateSchema([]string{"Standard_D4_v3"}),
 	},
 	TrialPlanID: {
 		PlanDefinition: domain.ServicePlan{
@@ -0,0 +1,126 @@
+package broker
+
+import (
+	"encoding/json"
+
+	"github.com/kyma-incubator/compass/components/director/pkg/jsonschema"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/pkg/errors"
+)
+
+// planSchema is the JSON schema of the provisioning parameters of a plan together with its validator
+// and the schema of the update parameters, which is nil if the plan does not support the update
+type planSchema struct {
+	provisioning map[string]interface{}
+	update       map[string]interface{}
+	validator    JSONSchemaValidator
+}
+
+// PlansSchemas holds the JSON schemas of the provisioning and update parameters generated per plan. The schemas
+// are served in the catalog and the provisioning schemas validate the provisioning requests, so the forms rendered
+// by the platform from the catalog accept exactly the parameters accepted by the broker.
+type PlansSchemas struct {
+	plans map[string]planSchema
+}
+
+// NewPlansSchemas generates the schemas of all plans, the components parameter of the plans other than trial
+// lists the optional components
+func NewPlansSchemas(optionalComponents OptionalComponentNamesProvider) (*PlansSchemas, error) {
+	schemas := &PlansSchemas{plans: map[string]planSchema{}}
+
+	for id, plan := range Plans {
+		provisioning := map[string]interface{}{}
+		if err := json.Unmarshal(plan.provisioningRawSchema, &provisioning); err != nil {
+			return nil, errors.Wrapf(err, "while decoding provisioning schema for Plan ID %s", id)
+		}
+		if !IsTrialPlan(id) {
+			addComponentsToSchema(provisioning, optionalComponents.GetAllOptionalComponentsNames())
+		}
+
+		raw, err := json.Marshal(provisioning)
+		if err != nil {
+			return nil, errors.Wrapf(err, "while encoding provisioning schema for Plan ID %s", id)
+		}
+		validator, err := jsonschema.NewValidatorFromStringSchema(string(raw))
+		if err != nil {
+			return nil, errors.Wrapf(err, "while creating schema validator for Plan ID %s", id)
+		}
+
+		var update map[string]interface{}
+		if plan.updateRawSchema != nil {
+			if err := json.Unmarshal(plan.updateRawSchema, &update); err != nil {
+				return nil, errors.Wrapf(err, "while decoding update schema for Plan ID %s", id)
+			}
+		}
+
+		schemas.plans[id] = planSchema{
+			provisioning: provisioning,
+			update:       update,
+			validator:    validator,
+		}
+	}
+
+	return schemas, nil
+}
+
+// ServiceSchemas returns the schemas of the plan served in the catalog, every call returns a new copy
+// which can be modified by the caller
+func (s *PlansSchemas) ServiceSchemas(planID string) (*domain.ServiceSchemas, error) {
+	plan, found := s.plans[planID]
+	if !found {
+		return nil, errors.Errorf("schema for Plan ID %s not found", planID)
+	}
+
+	parameters, err := copySchema(plan.provisioning)
+	if err != nil {
+		return nil, errors.Wrapf(err, "while copying provisioning schema for Plan ID %s", planID)
+	}
+	schemas := &domain.ServiceSchemas{
+		Instance: domain.ServiceInstanceSchema{
+			Create: domain.Schema{
+				Parameters: parameters,
+			},
+		},
+	}
+	if plan.update != nil {
+		update, err := copySchema(plan.update)
+		if err != nil {
+			return nil, errors.Wrapf(err, "while copying update schema for Plan ID %s", planID)
+		}
+		schemas.Instance.Update = domain.Schema{Parameters: update}
+	}
+
+	return schemas, nil
+}
+
+func copySchema(schema map[string]interface{}) (map[string]interface{}, error) {
+	raw, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+	copied := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &copied); err != nil {
+		return nil, err
+	}
+	return copied, nil
+}
+
+// Validators returns the validators of the provisioning parameters of all plans
+func (s *PlansSchemas) Validators() PlansSchemaValidator {
+	validators := PlansSchemaValidator{}
+	for id, plan := range s.plans {
+		validators[id] = plan.validator
+	}
+	return validators
+}
+
+func addComponentsToSchema(schema map[string]interface{}, componentNames []string) {
+	props := schema["properties"].(map[string]interface{})
+	props["components"] = map[string]interface{}{
+		"type": "array",
+		"items": map[string]interface{}{
+			"type": "string",
+			"enum": componentNames,
+		},
+	}
+}
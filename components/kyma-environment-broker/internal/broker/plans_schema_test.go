@@ -0,0 +1,141 @@
+package broker_test
+
+import (
+	"testing"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process/input/automock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlansSchemas_Validators(t *testing.T) {
+	// given
+	optComponentsProviderMock := &automock.OptionalComponentNamesProvider{}
+	optComponentsProviderMock.On("GetAllOptionalComponentsNames").Return([]string{"kiali", "tracing", "knative-eventing"})
+
+	schemas, err := broker.NewPlansSchemas(optComponentsProviderMock)
+	require.NoError(t, err)
+	validators := schemas.Validators()
+
+	for tN, tC := range map[string]struct {
+		planID    string
+		inputJSON string
+		valid     bool
+	}{
+		"all optional components": {
+			planID:    broker.AzurePlanID,
+			inputJSON: `{"name": "runtime", "components": ["kiali", "tracing", "knative-eventing"]}`,
+			valid:     true,
+		},
+		"unknown component": {
+			planID:    broker.GCPPlanID,
+			inputJSON: `{"name": "runtime", "components": ["unknown"]}`,
+			valid:     false,
+		},
+		"missing name": {
+			planID:    broker.AzureLitePlanID,
+			inputJSON: `{"components": ["kiali"]}`,
+			valid:     false,
+		},
+		"registry": {
+			planID:    broker.GCPPlanID,
+			inputJSON: `{"name": "runtime", "registry": {"url": "registry.example.com/kyma", "pullSecrets": [{"name": "registry-credentials", "username": "kyma", "password": "secret"}]}}`,
+			valid:     true,
+		},
+		"registry without url": {
+			planID:    broker.AzurePlanID,
+			inputJSON: `{"name": "runtime", "registry": {"pullSecrets": []}}`,
+			valid:     false,
+		},
+		"registry pull secret without password": {
+			planID:    broker.AzurePlanID,
+			inputJSON: `{"name": "runtime", "registry": {"url": "registry.example.com", "pullSecrets": [{"name": "registry-credentials", "username": "kyma"}]}}`,
+			valid:     false,
+		},
+		"subscription": {
+			planID:    broker.GCPPlanID,
+			inputJSON: `{"name": "runtime", "subscription": {"credentials": {"serviceaccount.json": "{}"}}}`,
+			valid:     true,
+		},
+		"subscription secret name not a string": {
+			planID:    broker.AzurePlanID,
+			inputJSON: `{"name": "runtime", "subscription": {"secretName": 1}}`,
+			valid:     false,
+		},
+		"trial region": {
+			planID:    broker.TrialPlanID,
+			inputJSON: `{"name": "runtime", "region": "europe"}`,
+			valid:     true,
+		},
+	} {
+		t.Run(tN, func(t *testing.T) {
+			// when
+			result, err := validators[tC.planID].ValidateString(tC.inputJSON)
+
+			// then
+			require.NoError(t, err)
+			assert.Equal(t, tC.valid, result.Valid)
+		})
+	}
+}
+
+func TestPlansSchemas_ServiceSchemas(t *testing.T) {
+	// given
+	optComponentsProviderMock := &automock.OptionalComponentNamesProvider{}
+	optComponentsProviderMock.On("GetAllOptionalComponentsNames").Return([]string{"kiali"})
+
+	schemas, err := broker.NewPlansSchemas(optComponentsProviderMock)
+	require.NoError(t, err)
+
+	t.Run("should return a copy of the schema", func(t *testing.T) {
+		// given
+		served, err := schemas.ServiceSchemas(broker.AzurePlanID)
+		require.NoError(t, err)
+		delete(served.Instance.Create.Parameters, "properties")
+
+		// when
+		served, err = schemas.ServiceSchemas(broker.AzurePlanID)
+
+		// then
+		require.NoError(t, err)
+		assert.Contains(t, served.Instance.Create.Parameters, "properties")
+	})
+
+	t.Run("should not add components to the trial plan", func(t *testing.T) {
+		// when
+		served, err := schemas.ServiceSchemas(broker.TrialPlanID)
+
+		// then
+		require.NoError(t, err)
+		assert.NotContains(t, served.Instance.Create.Parameters["properties"], "components")
+	})
+
+	t.Run("should return the update schema with the OIDC parameters", func(t *testing.T) {
+		// when
+		served, err := schemas.ServiceSchemas(broker.GCPPlanID)
+
+		// then
+		require.NoError(t, err)
+		assert.Contains(t, served.Instance.Update.Parameters["properties"], "oidc")
+		assert.Contains(t, served.Instance.Update.Parameters["properties"], "subscription")
+	})
+
+	t.Run("should not return the update schema for the trial plan", func(t *testing.T) {
+		// when
+		served, err := schemas.ServiceSchemas(broker.TrialPlanID)
+
+		// then
+		require.NoError(t, err)
+		assert.Nil(t, served.Instance.Update.Parameters)
+	})
+
+	t.Run("should return error for unknown plan", func(t *testing.T) {
+		// when
+		_, err := schemas.ServiceSchemas("unknown")
+
+		// then
+		assert.Error(t, err)
+	})
+}
@@ -5,8 +5,17 @@ import (
 	"encoding/json"
 	"reflect"
 	"testing"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession/dbmodel"
 )
 
+func TestTrialPlanIDKnownByStorage(t *testing.T) {
+	// the storage allows only one trial instance per subaccount, so it must know the trial plan ID
+	if TrialPlanID != dbmodel.TrialPlanID {
+		t.Errorf("TrialPlanID = %s, the storage uses %s", TrialPlanID, dbmodel.TrialPlanID)
+	}
+}
+
 func TestSchemaGenerator(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -70,6 +79,46 @@ func TestSchemaGenerator(t *testing.T) {
 		},
 			"maxUnavailable": {
 			"type": "integer"
+		},
+			"registry": {
+			"type": "object",
+			"properties": {
+			"pullSecrets": {
+			"type": "array",
+			"items": [
+			{
+				"type": "object",
+				"properties": {
+				"name": {
+				"type": "string"
+			},
+				"password": {
+				"type": "string"
+			},
+				"username": {
+				"type": "string"
+			}
+			},
+				"required": ["name", "username", "password"]
+			}
+			]
+		},
+			"url": {
+			"type": "string"
+		}
+		},
+			"required": ["url"]
+		},
+			"subscription": {
+			"type": "object",
+			"properties": {
+			"credentials": {
+			"type": "object"
+		},
+			"secretName": {
+			"type": "string"
+		}
+		}
 		}
 		},
 			"required": [
@@ -132,6 +181,46 @@ func TestSchemaGenerator(t *testing.T) {
 		},
 			"maxUnavailable": {
 			"type": "integer"
+		},
+			"registry": {
+			"type": "object",
+			"properties": {
+			"pullSecrets": {
+			"type": "array",
+			"items": [
+			{
+				"type": "object",
+				"properties": {
+				"name": {
+				"type": "string"
+			},
+				"password": {
+				"type": "string"
+			},
+				"username": {
+				"type": "string"
+			}
+			},
+				"required": ["name", "username", "password"]
+			}
+			]
+		},
+			"url": {
+			"type": "string"
+		}
+		},
+			"required": ["url"]
+		},
+			"subscription": {
+			"type": "object",
+			"properties": {
+			"credentials": {
+			"type": "object"
+		},
+			"secretName": {
+			"type": "string"
+		}
+		}
 		}
 		},
 			"required": [
@@ -223,6 +312,46 @@ func TestSchemaGenerator(t *testing.T) {
 		},
 			"maxUnavailable": {
 			"type": "integer"
+		},
+			"registry": {
+			"type": "object",
+			"properties": {
+			"pullSecrets": {
+			"type": "array",
+			"items": [
+			{
+				"type": "object",
+				"properties": {
+				"name": {
+				"type": "string"
+			},
+				"password": {
+				"type": "string"
+			},
+				"username": {
+				"type": "string"
+			}
+			},
+				"required": ["name", "username", "password"]
+			}
+			]
+		},
+			"url": {
+			"type": "string"
+		}
+		},
+			"required": ["url"]
+		},
+			"subscription": {
+			"type": "object",
+			"properties": {
+			"credentials": {
+			"type": "object"
+		},
+			"secretName": {
+			"type": "string"
+		}
+		}
 		}
 		},
 			"required": [
@@ -272,6 +401,74 @@ func TestTrialSchemaGenerator(t *testing.T) {
 
 }
 
+func TestUpdateSchemaGenerator(t *testing.T) {
+	want := `{
+  "$schema": "http://json-schema.org/draft-04/schema#",
+  "type": "object",
+  "properties": {
+    "machineType": {
+      "type": "string",
+      "enum": ["Standard_D8_v3"]
+    },
+    "autoScalerMin": {
+      "type": "integer"
+    },
+    "autoScalerMax": {
+      "type": "integer"
+    },
+    "maxSurge": {
+      "type": "integer"
+    },
+    "maxUnavailable": {
+      "type": "integer"
+    },
+    "subscription": {
+      "type": "object",
+      "properties": {
+        "credentials": {
+          "type": "object"
+        },
+        "secretName": {
+          "type": "string"
+        }
+      }
+    },
+    "oidc": {
+      "type": "object",
+      "properties": {
+        "clientID": {
+          "type": "string"
+        },
+        "groupsClaim": {
+          "type": "string"
+        },
+        "issuerURL": {
+          "type": "string"
+        },
+        "signingAlgs": {
+          "type": "array",
+          "items": [
+            {
+              "type": "string"
+            }
+          ]
+        },
+        "usernameClaim": {
+          "type": "string"
+        },
+        "usernamePrefix": {
+          "type": "string"
+        }
+      },
+      "required": ["clientID", "issuerURL"]
+    }
+  }
+}`
+
+	got := UpdateSchema([]string{"Standard_D8_v3"})
+	validateSchema(t, got, want)
+}
+
 func validateSchema(t *testing.T, got []byte, want string) {
 	var prettyWant bytes.Buffer
 	err := json.Indent(&prettyWant, []byte(want), "", "  ")
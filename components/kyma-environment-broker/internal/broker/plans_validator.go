@@ -1,6 +1,5 @@
-// Currently added in this package to be able to access plans schemas.
-// In future we need to refactor that approach and in one single place have Plan Schemas, Plan Schemas Validator, Plan Schemas Defaults
-// Currently it is shared between `broker`, `provider` and `proces/provisioning/input` packages.
+// The schemas served in the catalog and their validators are kept together in PlansSchemas.
+// Plan Schemas Defaults are still shared between `broker`, `provider` and `proces/provisioning/input` packages.
 package broker
 
 import (
@@ -16,6 +15,8 @@ type JSONSchemaValidator interface {
 
 type PlansSchemaValidator map[string]JSONSchemaValidator
 
+// NewPlansSchemaValidator creates the validators of the generated plan schemas without the list of the optional
+// components, the broker endpoints use the validators of PlansSchemas
 func NewPlansSchemaValidator() (PlansSchemaValidator, error) {
 	planIDs := []string{GCPPlanID, AzurePlanID, AzureLitePlanID, TrialPlanID}
 	validators := PlansSchemaValidator{}
@@ -2,7 +2,6 @@ package broker
 
 import (
 	"context"
-	"encoding/json"
 
 	"github.com/pivotal-cf/brokerapi/v7/domain"
 	"github.com/sirupsen/logrus"
@@ -16,11 +15,15 @@ type OptionalComponentNamesProvider interface {
 type ServicesEndpoint struct {
 	log logrus.FieldLogger
 
-	optionalComponents OptionalComponentNamesProvider
-	enabledPlanIDs     map[string]struct{}
+	schemas        *PlansSchemas
+	enabledPlanIDs map[string]struct{}
+	catalog        CatalogSource
 }
 
-func NewServices(cfg Config, optComponentsSvc OptionalComponentNamesProvider, log logrus.FieldLogger) *ServicesEndpoint {
+// NewServices creates the catalog endpoint, the descriptive metadata of the catalog from the given source takes precedence
+// over the built-in one, the source can be nil. The plans and their schemas are always served from the built-in catalog,
+// because the same schemas are used to validate the provisioning parameters.
+func NewServices(cfg Config, schemas *PlansSchemas, catalog CatalogSource, log logrus.FieldLogger) *ServicesEndpoint {
 	enabledPlanIDs := map[string]struct{}{}
 	for _, planName := range cfg.EnablePlans {
 		id := planIDsMapping[planName]
@@ -28,15 +31,30 @@ func NewServices(cfg Config, optComponentsSvc OptionalComponentNamesProvider, lo
 	}
 
 	return &ServicesEndpoint{
-		log:                log.WithField("service", "ServicesEndpoint"),
-		optionalComponents: optComponentsSvc,
-		enabledPlanIDs:     enabledPlanIDs,
+		log:            log.WithField("service", "ServicesEndpoint"),
+		schemas:        schemas,
+		enabledPlanIDs: enabledPlanIDs,
+		catalog:        catalog,
 	}
 }
 
 // Services gets the catalog of services offered by the service broker
 //   GET /v2/catalog
 func (b *ServicesEndpoint) Services(ctx context.Context) ([]domain.Service, error) {
+	services, err := b.builtInServices()
+	if err != nil {
+		return nil, err
+	}
+	if b.catalog != nil {
+		if external, ok := b.catalog.Services(); ok {
+			return b.mergeMetadata(services, external), nil
+		}
+	}
+
+	return services, nil
+}
+
+func (b *ServicesEndpoint) builtInServices() ([]domain.Service, error) {
 	var availableServicePlans []domain.ServicePlan
 
 	for _, plan := range Plans {
@@ -45,14 +63,12 @@ func (b *ServicesEndpoint) Services(ctx context.Context) ([]domain.Service, erro
 			continue
 		}
 		p := plan.PlanDefinition
-		err := json.Unmarshal(plan.provisioningRawSchema, &p.Schemas.Instance.Create.Parameters)
-		if !IsTrialPlan(p.ID) {
-			b.addComponentsToSchema(&p.Schemas.Instance.Create.Parameters)
-			if err != nil {
-				b.log.Errorf("Could not decode provisioning schema: %s", err)
-				return nil, err
-			}
+		schemas, err := b.schemas.ServiceSchemas(p.ID)
+		if err != nil {
+			b.log.Errorf("Could not get provisioning schema: %s", err)
+			return nil, err
 		}
+		p.Schemas = schemas
 		availableServicePlans = append(availableServicePlans, p)
 	}
 
@@ -62,7 +78,9 @@ func (b *ServicesEndpoint) Services(ctx context.Context) ([]domain.Service, erro
 			Name:        KymaServiceName,
 			Description: "[EXPERIMENTAL] Service Class for Kyma Runtime",
 			Bindable:    true,
-			Plans:       availableServicePlans,
+			// the trial plan can be changed to the azure plan
+			PlanUpdatable: true,
+			Plans:         availableServicePlans,
 			Metadata: &domain.ServiceMetadata{
 				DisplayName:         "Kyma Runtime",
 				LongDescription:     "Kyma Runtime experimental service class",
@@ -78,13 +96,55 @@ func (b *ServicesEndpoint) Services(ctx context.Context) ([]domain.Service, erro
 	}, nil
 }
 
-func (b *ServicesEndpoint) addComponentsToSchema(schema *map[string]interface{}) {
-	props := (*schema)["properties"].(map[string]interface{})
-	props["components"] = map[string]interface{}{
-		"type": "array",
-		"items": map[string]interface{}{
-			"type": "string",
-			"enum": b.optionalComponents.GetAllOptionalComponentsNames(),
-		},
+// mergeMetadata copies the descriptive fields of the services and plans from the external catalog to the built-in one.
+// The IDs, names and schemas stay the built-in ones, so the schemas served to the platform match the validation
+// of the provisioning parameters. The empty fields of the external catalog do not override the built-in ones,
+// the services and plans which are not built in or not enabled are ignored.
+func (b *ServicesEndpoint) mergeMetadata(services, external []domain.Service) []domain.Service {
+	externalByID := make(map[string]domain.Service, len(external))
+	for _, service := range external {
+		externalByID[service.ID] = service
+	}
+
+	result := make([]domain.Service, 0, len(services))
+	for _, service := range services {
+		ext, found := externalByID[service.ID]
+		if !found {
+			result = append(result, service)
+			continue
+		}
+		if ext.Description != "" {
+			service.Description = ext.Description
+		}
+		if ext.Metadata != nil {
+			service.Metadata = ext.Metadata
+		}
+		if len(ext.Tags) != 0 {
+			service.Tags = ext.Tags
+		}
+
+		externalPlans := make(map[string]domain.ServicePlan, len(ext.Plans))
+		for _, plan := range ext.Plans {
+			if _, enabled := b.enabledPlanIDs[plan.ID]; !enabled {
+				b.log.Warnf("Skipping plan %s (%s) of the external catalog, the plan is not enabled", plan.Name, plan.ID)
+				continue
+			}
+			externalPlans[plan.ID] = plan
+		}
+		plans := make([]domain.ServicePlan, 0, len(service.Plans))
+		for _, plan := range service.Plans {
+			if extPlan, found := externalPlans[plan.ID]; found {
+				if extPlan.Description != "" {
+					plan.Description = extPlan.Description
+				}
+				if extPlan.Metadata != nil {
+					plan.Metadata = extPlan.Metadata
+				}
+			}
+			plans = append(plans, plan)
+		}
+		service.Plans = plans
+		result = append(result, service)
 	}
+	return result
 }
@@ -23,9 +23,13 @@ func TestServices_Services(t *testing.T) {
 	optComponentsNames := []string{"kiali", "tracing"}
 	optComponentsProviderMock.On("GetAllOptionalComponentsNames").Return(optComponentsNames)
 
+	schemas, err := broker.NewPlansSchemas(optComponentsProviderMock)
+	require.NoError(t, err)
+
 	servicesEndpoint := broker.NewServices(
 		broker.Config{EnablePlans: []string{"gcp", "azure"}},
-		optComponentsProviderMock,
+		schemas,
+		nil,
 		logrus.StandardLogger(),
 	)
 
@@ -36,6 +40,7 @@ func TestServices_Services(t *testing.T) {
 	require.NoError(t, err)
 	assert.Len(t, services, 1)
 	assert.Len(t, services[0].Plans, 2)
+	assert.True(t, services[0].PlanUpdatable)
 
 	// assert provisioning schema
 	componentItem := services[0].Plans[0].Schemas.Instance.Create.Parameters["properties"].(map[string]interface{})["components"]
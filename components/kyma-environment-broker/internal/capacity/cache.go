@@ -0,0 +1,57 @@
+package capacity
+
+import (
+	"sync"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+)
+
+// StatsGetter provides the capacity requested by the instances
+type StatsGetter interface {
+	GetCapacityStats() (internal.CapacityStats, error)
+}
+
+// StatsCache keeps the capacity stats, so the Prometheus scrapes and the calls of the /info/capacity endpoint
+// do not load the provisioning parameters of all instances every time. The stats expire after the TTL,
+// the TTL 0 disables the caching. The implementation is thread safe.
+type StatsCache struct {
+	getter StatsGetter
+	ttl    time.Duration
+
+	mu        sync.Mutex
+	stats     internal.CapacityStats
+	expiresAt time.Time
+}
+
+// NewStatsCache constructs a StatsCache for the given stats getter
+func NewStatsCache(getter StatsGetter, ttl time.Duration) *StatsCache {
+	return &StatsCache{
+		getter: getter,
+		ttl:    ttl,
+	}
+}
+
+// GetCapacityStats returns the cached stats, the stats are calculated again if they expired.
+// The calculation runs under the lock, so the concurrent callers wait for a single calculation.
+// The returned stats must not be modified.
+func (c *StatsCache) GetCapacityStats() (internal.CapacityStats, error) {
+	if c.ttl <= 0 {
+		return c.getter.GetCapacityStats()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if time.Now().Before(c.expiresAt) {
+		return c.stats, nil
+	}
+
+	stats, err := c.getter.GetCapacityStats()
+	if err != nil {
+		return internal.CapacityStats{}, err
+	}
+	c.stats = stats
+	c.expiresAt = time.Now().Add(c.ttl)
+
+	return stats, nil
+}
@@ -0,0 +1,85 @@
+package capacity_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/capacity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsCache_GetCapacityStats(t *testing.T) {
+	t.Run("should reuse the stats until they expire", func(t *testing.T) {
+		// given
+		getter := &countingStatsGetter{}
+		cache := capacity.NewStatsCache(getter, 50*time.Millisecond)
+
+		// when
+		first, err := cache.GetCapacityStats()
+		require.NoError(t, err)
+		second, err := cache.GetCapacityStats()
+		require.NoError(t, err)
+
+		// then
+		assert.Equal(t, 1, getter.calls)
+		assert.Equal(t, first, second)
+
+		// when
+		time.Sleep(60 * time.Millisecond)
+		_, err = cache.GetCapacityStats()
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 2, getter.calls)
+	})
+
+	t.Run("should not cache the error", func(t *testing.T) {
+		// given
+		getter := &countingStatsGetter{err: errors.New("database is down")}
+		cache := capacity.NewStatsCache(getter, time.Minute)
+
+		// when
+		_, err := cache.GetCapacityStats()
+		require.Error(t, err)
+		getter.err = nil
+		_, err = cache.GetCapacityStats()
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 2, getter.calls)
+	})
+
+	t.Run("should calculate the stats every time when the TTL is 0", func(t *testing.T) {
+		// given
+		getter := &countingStatsGetter{}
+		cache := capacity.NewStatsCache(getter, 0)
+
+		// when
+		_, err := cache.GetCapacityStats()
+		require.NoError(t, err)
+		_, err = cache.GetCapacityStats()
+		require.NoError(t, err)
+
+		// then
+		assert.Equal(t, 2, getter.calls)
+	})
+}
+
+type countingStatsGetter struct {
+	calls int
+	err   error
+}
+
+func (g *countingStatsGetter) GetCapacityStats() (internal.CapacityStats, error) {
+	g.calls++
+	if g.err != nil {
+		return internal.CapacityStats{}, g.err
+	}
+	return internal.CapacityStats{Entries: []internal.CapacityEntry{
+		{Provider: "azure", Region: "westeurope", MachineType: "Standard_D8_v3", Instances: g.calls},
+	}}, nil
+}
@@ -0,0 +1,146 @@
+package capacity
+
+import (
+	"sort"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/broker"
+	cloudProvider "github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/provider"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession/dbmodel"
+	"github.com/kyma-project/control-plane/components/provisioner/pkg/gqlschema"
+
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/pkg/errors"
+)
+
+// vCPUs holds the number of vCPUs of the machine types offered by the plans
+var vCPUs = map[string]int{
+	"Standard_D2_v3":  2,
+	"Standard_D4_v3":  4,
+	"Standard_D8_v3":  8,
+	"Standard_D16_v3": 16,
+	"Standard_D32_v3": 32,
+	"Standard_D48_v3": 48,
+	"Standard_D64_v3": 64,
+	"n1-standard-2":   2,
+	"n1-standard-4":   4,
+	"n1-standard-8":   8,
+	"n1-standard-16":  16,
+	"n1-standard-32":  32,
+	"n1-standard-64":  64,
+}
+
+type hyperscalerInput interface {
+	Defaults() *gqlschema.ClusterConfigInput
+	ApplyParameters(input *gqlschema.ClusterConfigInput, pp internal.ProvisioningParameters)
+}
+
+// Calculator aggregates the capacity requested by the instances from their provisioning parameters,
+// the parameters which are not set are taken from the defaults of the plan
+type Calculator struct {
+	instances storage.Instances
+}
+
+func NewCalculator(instances storage.Instances) *Calculator {
+	return &Calculator{
+		instances: instances,
+	}
+}
+
+// GetCapacityStats returns the capacity of all instances except the ones whose provisioning failed,
+// the instances being deprovisioned still consume their capacity
+func (c *Calculator) GetCapacityStats() (internal.CapacityStats, error) {
+	instances, _, _, err := c.instances.ListWithState(dbmodel.InstanceFilter{})
+	if err != nil {
+		return internal.CapacityStats{}, errors.Wrap(err, "while listing instances")
+	}
+
+	type key struct{ provider, region, machineType string }
+	entries := make(map[key]*internal.CapacityEntry)
+	for _, instance := range instances {
+		if instance.LastOperationType == string(dbmodel.OperationTypeProvision) && instance.LastOperation != nil && instance.LastOperation.State == domain.Failed {
+			continue
+		}
+		config, err := clusterConfig(instance.Instance)
+		if err != nil {
+			return internal.CapacityStats{}, errors.Wrapf(err, "while resolving cluster configuration of instance %s", instance.InstanceID)
+		}
+		if config == nil {
+			continue
+		}
+
+		k := key{provider: config.Provider, region: config.Region, machineType: config.MachineType}
+		entry, found := entries[k]
+		if !found {
+			entry = &internal.CapacityEntry{Provider: k.provider, Region: k.region, MachineType: k.machineType}
+			entries[k] = entry
+		}
+		entry.Instances++
+		entry.MaxNodes += config.AutoScalerMax
+		entry.MaxVCPUs += config.AutoScalerMax * vCPUs[config.MachineType]
+	}
+
+	stats := internal.CapacityStats{Entries: make([]internal.CapacityEntry, 0, len(entries))}
+	for _, entry := range entries {
+		stats.Entries = append(stats.Entries, *entry)
+	}
+	sort.Slice(stats.Entries, func(i, j int) bool {
+		a, b := stats.Entries[i], stats.Entries[j]
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		if a.Region != b.Region {
+			return a.Region < b.Region
+		}
+		return a.MachineType < b.MachineType
+	})
+
+	return stats, nil
+}
+
+// clusterConfig applies the provisioning parameters to the defaults of the plan in the same way as the provisioning,
+// the region stored in the instance takes precedence because it is the region the cluster was created in.
+// It returns nil for the plans without a cluster configuration.
+func clusterConfig(instance internal.Instance) (*gqlschema.GardenerConfigInput, error) {
+	pp, err := instance.GetProvisioningParameters()
+	if err != nil {
+		return nil, errors.Wrap(err, "while getting provisioning parameters")
+	}
+
+	var provider hyperscalerInput
+	switch pp.PlanID {
+	case broker.GCPPlanID:
+		provider = &cloudProvider.GcpInput{}
+	case broker.AzurePlanID:
+		provider = &cloudProvider.AzureInput{}
+	case broker.AzureLitePlanID:
+		provider = &cloudProvider.AzureLiteInput{}
+	case broker.TrialPlanID:
+		if pp.Parameters.Provider != nil && *pp.Parameters.Provider == internal.Gcp {
+			provider = &cloudProvider.GcpTrialInput{}
+		} else {
+			provider = &cloudProvider.AzureTrialInput{}
+		}
+	default:
+		return nil, nil
+	}
+
+	input := provider.Defaults()
+	config := input.GardenerConfig
+	if pp.Parameters.AutoScalerMax != nil {
+		config.AutoScalerMax = *pp.Parameters.AutoScalerMax
+	}
+	if pp.Parameters.MachineType != nil && *pp.Parameters.MachineType != "" {
+		config.MachineType = *pp.Parameters.MachineType
+	}
+	if pp.Parameters.Region != nil && *pp.Parameters.Region != "" {
+		config.Region = *pp.Parameters.Region
+	}
+	provider.ApplyParameters(input, pp)
+	if instance.ProviderRegion != "" {
+		config.Region = instance.ProviderRegion
+	}
+
+	return config, nil
+}
@@ -0,0 +1,68 @@
+package capacity_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/capacity"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/ptr"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculator_GetCapacityStats(t *testing.T) {
+	// given
+	db := storage.NewMemoryStorage()
+	gcp := internal.Gcp
+	for _, instance := range []struct {
+		id             string
+		providerRegion string
+		parameters     internal.ProvisioningParameters
+	}{
+		{id: "azure-1", providerRegion: "westeurope", parameters: internal.ProvisioningParameters{PlanID: broker.AzurePlanID}},
+		{id: "azure-2", parameters: internal.ProvisioningParameters{PlanID: broker.AzurePlanID, Parameters: internal.ProvisioningParametersDTO{
+			Region:        ptr.String("westeurope"),
+			AutoScalerMax: ptr.Integer(20),
+		}}},
+		{id: "azure-lite", providerRegion: "eastus", parameters: internal.ProvisioningParameters{PlanID: broker.AzureLitePlanID}},
+		{id: "gcp", providerRegion: "us-central1", parameters: internal.ProvisioningParameters{PlanID: broker.GCPPlanID, Parameters: internal.ProvisioningParametersDTO{
+			MachineType: ptr.String("n1-standard-8"),
+		}}},
+		{id: "trial", parameters: internal.ProvisioningParameters{PlanID: broker.TrialPlanID, Parameters: internal.ProvisioningParametersDTO{
+			Provider: &gcp,
+			Region:   ptr.String("us"),
+		}}},
+		{id: "failed", providerRegion: "westeurope", parameters: internal.ProvisioningParameters{PlanID: broker.AzurePlanID}},
+	} {
+		pp, err := json.Marshal(instance.parameters)
+		require.NoError(t, err)
+		require.NoError(t, db.Instances().Insert(internal.Instance{
+			InstanceID:             instance.id,
+			ServicePlanID:          instance.parameters.PlanID,
+			ProviderRegion:         instance.providerRegion,
+			ProvisioningParameters: string(pp),
+			CreatedAt:              time.Now(),
+		}))
+	}
+	require.NoError(t, db.Operations().InsertProvisioningOperation(internal.ProvisioningOperation{
+		Operation: internal.Operation{ID: "failed-provisioning", InstanceID: "failed", State: domain.Failed, CreatedAt: time.Now()},
+	}))
+
+	// when
+	stats, err := capacity.NewCalculator(db.Instances()).GetCapacityStats()
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []internal.CapacityEntry{
+		{Provider: "azure", Region: "eastus", MachineType: "Standard_D4_v3", Instances: 1, MaxNodes: 4, MaxVCPUs: 16},
+		{Provider: "azure", Region: "westeurope", MachineType: "Standard_D8_v3", Instances: 2, MaxNodes: 30, MaxVCPUs: 240},
+		{Provider: "gcp", Region: "us-central1", MachineType: "n1-standard-8", Instances: 1, MaxNodes: 4, MaxVCPUs: 32},
+		{Provider: "gcp", Region: "us-east4", MachineType: "n1-standard-4", Instances: 1, MaxNodes: 2, MaxVCPUs: 8},
+	}, stats.Entries)
+}
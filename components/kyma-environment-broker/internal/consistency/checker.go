@@ -0,0 +1,328 @@
+package consistency
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession/dbmodel"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+type Config struct {
+	// Disabled turns off the periodic consistency check
+	Disabled bool `envconfig:"default=false"`
+	// Interval defines how often the storage is checked
+	Interval time.Duration `envconfig:"default=24h"`
+	// AutoRepair enables the repair of the violations which are safe to repair
+	AutoRepair bool `envconfig:"default=false"`
+}
+
+// Invariants checked by the Checker
+const (
+	// InstanceWithoutProvisioning - every instance has at least one provisioning operation
+	InstanceWithoutProvisioning = "instance_without_provisioning_operation"
+	// ConcurrentOperations - at most one operation of the instance is in progress
+	ConcurrentOperations = "concurrent_operations_in_progress"
+	// OrchestrationOperationsCount - the orchestration has one operation for every resolved runtime
+	OrchestrationOperationsCount = "orchestration_operations_count"
+	// FinishedOrchestrationOperation - no operation of the finished orchestration is in progress
+	FinishedOrchestrationOperation = "finished_orchestration_operation_in_progress"
+)
+
+// Invariants lists all invariants checked by the Checker
+var Invariants = []string{
+	InstanceWithoutProvisioning,
+	ConcurrentOperations,
+	OrchestrationOperationsCount,
+	FinishedOrchestrationOperation,
+}
+
+// Violation describes the data which breaks one of the invariants together with the suggested repair
+type Violation struct {
+	Invariant       string   `json:"invariant"`
+	InstanceID      string   `json:"instanceID,omitempty"`
+	OrchestrationID string   `json:"orchestrationID,omitempty"`
+	OperationIDs    []string `json:"operationIDs,omitempty"`
+	Description     string   `json:"description"`
+	Suggestion      string   `json:"suggestion"`
+	Repaired        bool     `json:"repaired"`
+}
+
+type Report struct {
+	CheckedAt  time.Time   `json:"checkedAt"`
+	Violations []Violation `json:"violations"`
+}
+
+// Checker validates the invariants of the data kept in the storage, the check is run periodically by the scheduler
+// and on demand. The violations are reported in the logs, the metrics and the admin endpoint. The storage is never
+// modified unless the auto repair is enabled, and even then only the violations which are safe to repair are fixed.
+type Checker struct {
+	instances      storage.Instances
+	operations     storage.Operations
+	orchestrations storage.Orchestrations
+	cfg            Config
+	log            logrus.FieldLogger
+
+	mu         sync.RWMutex
+	lastReport *Report
+}
+
+func NewChecker(instances storage.Instances, operations storage.Operations, orchestrations storage.Orchestrations, cfg Config, log logrus.FieldLogger) *Checker {
+	return &Checker{
+		instances:      instances,
+		operations:     operations,
+		orchestrations: orchestrations,
+		cfg:            cfg,
+		log:            log,
+	}
+}
+
+// Check validates all invariants, repairs the safe cases if enabled and returns the report
+func (c *Checker) Check() (Report, error) {
+	report := Report{
+		CheckedAt:  time.Now(),
+		Violations: make([]Violation, 0),
+	}
+
+	for _, check := range []func() ([]Violation, error){
+		c.checkProvisioningOperations,
+		c.checkOperationsInProgress,
+		c.checkOrchestrations,
+	} {
+		violations, err := check()
+		if err != nil {
+			return Report{}, err
+		}
+		report.Violations = append(report.Violations, violations...)
+	}
+
+	for _, v := range report.Violations {
+		c.log.Warnf("Consistency violation %s: %s (repaired: %t)", v.Invariant, v.Description, v.Repaired)
+	}
+	c.log.Infof("Storage consistency check finished, found %d violations", len(report.Violations))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lastReport = &report
+
+	return report, nil
+}
+
+// LastReport returns the report of the latest check, false is returned if the storage was not checked yet
+func (c *Checker) LastReport() (Report, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.lastReport == nil {
+		return Report{}, false
+	}
+	return *c.lastReport, true
+}
+
+// ViolationCounts returns the number of the violations found by the latest check per invariant,
+// the repaired violations are not counted
+func (c *Checker) ViolationCounts() map[string]int {
+	counts := make(map[string]int, len(Invariants))
+	for _, invariant := range Invariants {
+		counts[invariant] = 0
+	}
+
+	report, found := c.LastReport()
+	if !found {
+		return counts
+	}
+	for _, v := range report.Violations {
+		if !v.Repaired {
+			counts[v.Invariant]++
+		}
+	}
+	return counts
+}
+
+func (c *Checker) checkProvisioningOperations() ([]Violation, error) {
+	instances, err := c.instances.FindAllJoinedWithOperations()
+	if err != nil {
+		return nil, errors.Wrap(err, "while listing instances with operations")
+	}
+
+	provisioned := make(map[string]bool)
+	for _, instance := range instances {
+		isProvisioning := instance.Type.Valid && instance.Type.String == string(dbmodel.OperationTypeProvision)
+		provisioned[instance.InstanceID] = provisioned[instance.InstanceID] || isProvisioning
+	}
+
+	notProvisioned := make([]string, 0)
+	for instanceID, found := range provisioned {
+		if !found {
+			notProvisioned = append(notProvisioned, instanceID)
+		}
+	}
+	sort.Strings(notProvisioned)
+
+	violations := make([]Violation, 0)
+	for _, instanceID := range notProvisioned {
+		violations = append(violations, Violation{
+			Invariant:   InstanceWithoutProvisioning,
+			InstanceID:  instanceID,
+			Description: fmt.Sprintf("instance %s has no provisioning operation", instanceID),
+			Suggestion:  "check if the runtime of the instance exists, deprovision the instance or remove it from the storage",
+		})
+	}
+	return violations, nil
+}
+
+func (c *Checker) checkOperationsInProgress() ([]Violation, error) {
+	inProgress := make(map[string][]string)
+	for _, opType := range []dbmodel.OperationType{
+		dbmodel.OperationTypeProvision,
+		dbmodel.OperationTypeDeprovision,
+		dbmodel.OperationTypeUpgradeKyma,
+		dbmodel.OperationTypeUpgradeCluster,
+		dbmodel.OperationTypeMigratePlan,
+		dbmodel.OperationTypeUpdate,
+		dbmodel.OperationTypeSuspension,
+		dbmodel.OperationTypeAccountMigration,
+	} {
+		operations, err := c.operations.GetOperationsInProgressByType(opType)
+		if err != nil {
+			return nil, errors.Wrapf(err, "while listing %s operations in progress", opType)
+		}
+		for _, op := range operations {
+			inProgress[op.InstanceID] = append(inProgress[op.InstanceID], op.ID)
+		}
+	}
+
+	violations := make([]Violation, 0)
+	for instanceID, operationIDs := range inProgress {
+		if len(operationIDs) < 2 {
+			continue
+		}
+		sort.Strings(operationIDs)
+		violations = append(violations, Violation{
+			Invariant:    ConcurrentOperations,
+			InstanceID:   instanceID,
+			OperationIDs: operationIDs,
+			Description:  fmt.Sprintf("instance %s has %d operations in progress", instanceID, len(operationIDs)),
+			Suggestion:   "check which of the operations is processed and mark the stale ones as failed",
+		})
+	}
+	sort.Slice(violations, func(i, j int) bool {
+		return violations[i].InstanceID < violations[j].InstanceID
+	})
+	return violations, nil
+}
+
+func (c *Checker) checkOrchestrations() ([]Violation, error) {
+	violations := make([]Violation, 0)
+	for _, state := range []string{internal.InProgress, internal.Canceling, internal.Succeeded, internal.Failed, internal.Canceled} {
+		orchestrations, err := c.orchestrations.ListByState(state)
+		if err != nil {
+			return nil, errors.Wrapf(err, "while listing orchestrations in %s state", state)
+		}
+		for _, o := range orchestrations {
+			v, err := c.checkOrchestration(o)
+			if err != nil {
+				return nil, err
+			}
+			violations = append(violations, v...)
+		}
+	}
+
+	v, err := c.checkOperationsOfFinishedOrchestrations()
+	if err != nil {
+		return nil, err
+	}
+	return append(violations, v...), nil
+}
+
+// checkOrchestration compares the number of the operations with the number of the resolved runtimes,
+// the runtimes are not recorded by the orchestrations created before they were persisted, such orchestrations are skipped
+func (c *Checker) checkOrchestration(o internal.Orchestration) ([]Violation, error) {
+	if len(o.Runtimes) == 0 {
+		return nil, nil
+	}
+
+	stats, err := c.operations.GetOperationStatsForOrchestration(o.OrchestrationID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "while getting operation stats for orchestration %s", o.OrchestrationID)
+	}
+	count := 0
+	for _, n := range stats {
+		count += n
+	}
+	if count == len(o.Runtimes) {
+		return nil, nil
+	}
+
+	return []Violation{{
+		Invariant:       OrchestrationOperationsCount,
+		OrchestrationID: o.OrchestrationID,
+		Description:     fmt.Sprintf("orchestration %s resolved %d runtimes but has %d operations", o.OrchestrationID, len(o.Runtimes), count),
+		Suggestion:      "compare the runtimes of the orchestration with its operations and retry the orchestration for the missing runtimes",
+	}}, nil
+}
+
+// checkOperationsOfFinishedOrchestrations finds the upgrade operations which are still in progress although
+// their orchestration is finished. The operations which were not sent to the Provisioner are safe to repair,
+// they are canceled in the same way as the operations of the canceled orchestration.
+func (c *Checker) checkOperationsOfFinishedOrchestrations() ([]Violation, error) {
+	operations, err := c.operations.GetOperationsInProgressByType(dbmodel.OperationTypeUpgradeKyma)
+	if err != nil {
+		return nil, errors.Wrap(err, "while listing upgrade kyma operations in progress")
+	}
+
+	violations := make([]Violation, 0)
+	for _, op := range operations {
+		if op.OrchestrationID == "" {
+			continue
+		}
+		o, err := c.orchestrations.GetByID(op.OrchestrationID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "while getting orchestration %s", op.OrchestrationID)
+		}
+		if !o.IsFinished() {
+			continue
+		}
+
+		violation := Violation{
+			Invariant:       FinishedOrchestrationOperation,
+			InstanceID:      op.InstanceID,
+			OrchestrationID: op.OrchestrationID,
+			OperationIDs:    []string{op.ID},
+			Description:     fmt.Sprintf("operation %s is in progress although orchestration %s is %s", op.ID, o.OrchestrationID, o.State),
+			Suggestion:      "cancel the operation if it was not sent to the Provisioner, otherwise wait for the Provisioner operation and update the state",
+		}
+		if c.cfg.AutoRepair && op.ProvisionerOperationID == "" {
+			violation.Repaired, err = c.cancelOperation(op.ID)
+			if err != nil {
+				return nil, err
+			}
+		}
+		violations = append(violations, violation)
+	}
+	return violations, nil
+}
+
+// cancelOperation cancels the upgrade operation unless it was sent to the Provisioner in the meantime
+func (c *Checker) cancelOperation(operationID string) (bool, error) {
+	canceled := false
+	_, err := storage.UpdateWithRetryUpgradeKymaOperation(c.operations, operationID, func(op *internal.UpgradeKymaOperation) {
+		canceled = false
+		if op.ProvisionerOperationID != "" || op.IsFinished() {
+			return
+		}
+		op.State = internal.OperationCanceled
+		op.Description = "operation canceled by the consistency check, the orchestration is already finished"
+		canceled = true
+	})
+	if err != nil {
+		return false, errors.Wrapf(err, "while canceling upgrade operation %s", operationID)
+	}
+	return canceled, nil
+}
@@ -0,0 +1,152 @@
+package consistency_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/consistency"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+
+	"github.com/gorilla/mux"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecker(t *testing.T) {
+	t.Run("should report the violations", func(t *testing.T) {
+		// given
+		db := fixStorage(t)
+		checker := consistency.NewChecker(db.Instances(), db.Operations(), db.Orchestrations(), consistency.Config{}, logrus.New())
+
+		// when
+		report, err := checker.Check()
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, report.Violations, 4)
+		assert.Equal(t, consistency.InstanceWithoutProvisioning, report.Violations[0].Invariant)
+		assert.Equal(t, "orphan", report.Violations[0].InstanceID)
+		assert.Equal(t, consistency.ConcurrentOperations, report.Violations[1].Invariant)
+		assert.Equal(t, "busy", report.Violations[1].InstanceID)
+		assert.Equal(t, []string{"busy-deprovisioning", "busy-provisioning"}, report.Violations[1].OperationIDs)
+		assert.Equal(t, consistency.OrchestrationOperationsCount, report.Violations[2].Invariant)
+		assert.Equal(t, "orchestration", report.Violations[2].OrchestrationID)
+		assert.Equal(t, consistency.FinishedOrchestrationOperation, report.Violations[3].Invariant)
+		assert.False(t, report.Violations[3].Repaired)
+
+		op, err := db.Operations().GetUpgradeKymaOperationByID("upgrade")
+		require.NoError(t, err)
+		assert.Equal(t, domain.InProgress, op.State)
+
+		assert.Equal(t, map[string]int{
+			consistency.InstanceWithoutProvisioning:    1,
+			consistency.ConcurrentOperations:           1,
+			consistency.OrchestrationOperationsCount:   1,
+			consistency.FinishedOrchestrationOperation: 1,
+		}, checker.ViolationCounts())
+	})
+
+	t.Run("should repair the safe violations", func(t *testing.T) {
+		// given
+		db := fixStorage(t)
+		checker := consistency.NewChecker(db.Instances(), db.Operations(), db.Orchestrations(), consistency.Config{AutoRepair: true}, logrus.New())
+
+		// when
+		report, err := checker.Check()
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, report.Violations, 4)
+		assert.True(t, report.Violations[3].Repaired)
+
+		op, err := db.Operations().GetUpgradeKymaOperationByID("upgrade")
+		require.NoError(t, err)
+		assert.Equal(t, internal.OperationCanceled, op.State)
+		assert.Equal(t, 0, checker.ViolationCounts()[consistency.FinishedOrchestrationOperation])
+	})
+}
+
+func TestHandler(t *testing.T) {
+	// given
+	db := fixStorage(t)
+	checker := consistency.NewChecker(db.Instances(), db.Operations(), db.Orchestrations(), consistency.Config{}, logrus.New())
+
+	router := mux.NewRouter()
+	consistency.NewHandler(checker, logrus.New()).AttachRoutes(router)
+
+	// when
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/consistency/report", nil))
+
+	// then
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	// when
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/consistency/check", nil))
+
+	// then
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	// when
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/consistency/report", nil))
+
+	// then
+	require.Equal(t, http.StatusOK, rr.Code)
+	var report consistency.Report
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+	assert.Len(t, report.Violations, 4)
+}
+
+// fixStorage prepares the storage with one violation of every invariant
+func fixStorage(t *testing.T) storage.BrokerStorage {
+	db := storage.NewMemoryStorage()
+	now := time.Now()
+
+	for _, instanceID := range []string{"orphan", "busy", "upgraded"} {
+		require.NoError(t, db.Instances().Insert(internal.Instance{InstanceID: instanceID, CreatedAt: now}))
+	}
+
+	require.NoError(t, db.Operations().InsertProvisioningOperation(internal.ProvisioningOperation{
+		Operation: fixOperation("busy-provisioning", "busy", domain.InProgress),
+	}))
+	require.NoError(t, db.Operations().InsertDeprovisioningOperation(internal.DeprovisioningOperation{
+		Operation: fixOperation("busy-deprovisioning", "busy", domain.InProgress),
+	}))
+	require.NoError(t, db.Operations().InsertProvisioningOperation(internal.ProvisioningOperation{
+		Operation: fixOperation("upgraded-provisioning", "upgraded", domain.Succeeded),
+	}))
+
+	// the orchestration resolved two runtimes but only one operation was created, and the operation
+	// was not sent to the Provisioner although the orchestration is finished
+	upgrade := fixOperation("upgrade", "upgraded", domain.InProgress)
+	upgrade.OrchestrationID = "orchestration"
+	require.NoError(t, db.Operations().InsertUpgradeKymaOperation(internal.UpgradeKymaOperation{
+		RuntimeOperation: internal.RuntimeOperation{Operation: upgrade},
+	}))
+	require.NoError(t, db.Orchestrations().Insert(internal.Orchestration{
+		OrchestrationID: "orchestration",
+		State:           internal.Failed,
+		CreatedAt:       now,
+		Runtimes:        []internal.Runtime{{InstanceID: "upgraded"}, {InstanceID: "missing"}},
+	}))
+
+	return db
+}
+
+func fixOperation(id, instanceID string, state domain.LastOperationState) internal.Operation {
+	return internal.Operation{
+		ID:         id,
+		InstanceID: instanceID,
+		State:      state,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+}
@@ -0,0 +1,51 @@
+package consistency
+
+import (
+	"net/http"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/httputil"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+type Handler struct {
+	checker *Checker
+	log     logrus.FieldLogger
+}
+
+func NewHandler(checker *Checker, log logrus.FieldLogger) *Handler {
+	return &Handler{
+		checker: checker,
+		log:     log,
+	}
+}
+
+func (h *Handler) AttachRoutes(router *mux.Router) {
+	router.HandleFunc("/consistency/report", h.getReport).Methods(http.MethodGet)
+	router.HandleFunc("/consistency/check", h.check).Methods(http.MethodPost)
+}
+
+// getReport returns the report of the latest consistency check
+func (h *Handler) getReport(w http.ResponseWriter, _ *http.Request) {
+	report, found := h.checker.LastReport()
+	if !found {
+		httputil.WriteErrorResponse(w, http.StatusNotFound, errors.New("the storage consistency was not checked yet"))
+		return
+	}
+
+	httputil.WriteResponse(w, http.StatusOK, report)
+}
+
+// check runs the consistency check immediately and returns its report
+func (h *Handler) check(w http.ResponseWriter, _ *http.Request) {
+	report, err := h.checker.Check()
+	if err != nil {
+		h.log.Errorf("while checking storage consistency: %v", err)
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "while checking storage consistency"))
+		return
+	}
+
+	httputil.WriteResponse(w, http.StatusOK, report)
+}
@@ -1,7 +1,13 @@
 package internal
 
 import (
+	"encoding/json"
+	"net/url"
 	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
 )
 
 const (
@@ -20,25 +26,167 @@ type ProvisioningParameters struct {
 	PlatformRegion string `json:"platform_region"`
 }
 
+// redactedValue replaces the values of the provisioning parameters which are not safe to expose
+const redactedValue = "<redacted>"
+
+// loggableFields lists the provisioning parameters whose values are safe to expose, the values of all other
+// parameters are redacted, so the credentials of a newly added parameter are not exposed by default
+var loggableFields = []string{
+	"plan_id",
+	"service_id",
+	"platform_region",
+	"ers_context.tenant_id",
+	"ers_context.subaccount_id",
+	"ers_context.globalaccount_id",
+	"parameters.name",
+	"parameters.targetSecret",
+	"parameters.volumeSizeGb",
+	"parameters.machineType",
+	"parameters.region",
+	"parameters.purpose",
+	"parameters.licence_type",
+	"parameters.zones",
+	"parameters.autoScalerMin",
+	"parameters.autoScalerMax",
+	"parameters.maxSurge",
+	"parameters.maxUnavailable",
+	"parameters.components",
+	"parameters.kymaVersion",
+	"parameters.provider",
+	"parameters.subscription.secretName",
+	"parameters.registry.url",
+	"parameters.oidc",
+}
+
+// ParameterDiff describes a provisioning parameter with different values in two sets of provisioning parameters
+type ParameterDiff struct {
+	Field     string      `json:"field"`
+	Existing  interface{} `json:"existing"`
+	Requested interface{} `json:"requested"`
+}
+
 func (p ProvisioningParameters) IsEqual(input ProvisioningParameters) bool {
-	if p.PlanID != input.PlanID {
-		return false
+	diff, err := p.Diff(input)
+	return err == nil && len(diff) == 0
+}
+
+// Diff compares the provisioning parameters with the input and returns the differing fields sorted by the field path.
+// Both sets are normalized before the comparison, so the order of zones and components, as well as
+// null and empty values, do not cause a difference.
+func (p ProvisioningParameters) Diff(input ProvisioningParameters) ([]ParameterDiff, error) {
+	existing, err := p.normalizedFields()
+	if err != nil {
+		return nil, err
 	}
-	if p.ServiceID != input.ServiceID {
-		return false
+	requested, err := input.normalizedFields()
+	if err != nil {
+		return nil, err
 	}
-	if p.PlatformRegion != input.PlatformRegion {
-		return false
+
+	fields := map[string]struct{}{}
+	for field := range existing {
+		fields[field] = struct{}{}
+	}
+	for field := range requested {
+		fields[field] = struct{}{}
+	}
+
+	diff := make([]ParameterDiff, 0)
+	for field := range fields {
+		existingValue, requestedValue := existing[field], requested[field]
+		if reflect.DeepEqual(existingValue, requestedValue) {
+			continue
+		}
+		if !isLoggableField(field) {
+			existingValue, requestedValue = redact(existingValue), redact(requestedValue)
+		}
+		diff = append(diff, ParameterDiff{Field: field, Existing: existingValue, Requested: requestedValue})
 	}
+	sort.Slice(diff, func(i, j int) bool {
+		return diff[i].Field < diff[j].Field
+	})
 
-	if !reflect.DeepEqual(p.ErsContext, input.ErsContext) {
-		return false
+	return diff, nil
+}
+
+// LoggableFields returns the provisioning parameters as a flat map of the JSON field paths and their values,
+// the values of the fields which are not safe to log are redacted
+func (p ProvisioningParameters) LoggableFields() (map[string]interface{}, error) {
+	fields, err := p.normalizedFields()
+	if err != nil {
+		return nil, err
+	}
+	for field, value := range fields {
+		if !isLoggableField(field) {
+			fields[field] = redact(value)
+		}
 	}
-	if !reflect.DeepEqual(p.Parameters, input.Parameters) {
-		return false
+	return fields, nil
+}
+
+// normalizedFields returns the provisioning parameters as a flat map of the JSON field paths and their values
+func (p ProvisioningParameters) normalizedFields() (map[string]interface{}, error) {
+	p.Parameters.Zones = sortedCopy(p.Parameters.Zones)
+	p.Parameters.OptionalComponentsToInstall = sortedCopy(p.Parameters.OptionalComponentsToInstall)
+
+	raw, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, err
+	}
+
+	fields := map[string]interface{}{}
+	flattenFields("", data, fields)
+	return fields, nil
+}
+
+func flattenFields(prefix string, value interface{}, fields map[string]interface{}) {
+	switch v := value.(type) {
+	case nil:
+		return
+	case map[string]interface{}:
+		for key, nested := range v {
+			path := key
+			if prefix != "" {
+				path = prefix + "." + key
+			}
+			flattenFields(path, nested, fields)
+		}
+	case []interface{}:
+		if len(v) > 0 {
+			fields[prefix] = v
+		}
+	default:
+		fields[prefix] = v
+	}
+}
+
+func sortedCopy(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	sorted := append([]string{}, values...)
+	sort.Strings(sorted)
+	return sorted
+}
+
+func isLoggableField(field string) bool {
+	for _, loggable := range loggableFields {
+		if field == loggable || strings.HasPrefix(field, loggable+".") {
+			return true
+		}
 	}
+	return false
+}
 
-	return true
+func redact(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	return redactedValue
 }
 
 type TrialCloudProvider string
@@ -66,12 +214,110 @@ type ProvisioningParametersDTO struct {
 	KymaVersion                 string   `json:"kymaVersion"`
 	//Provider - used in Trial plan to determine which cloud provider to use during provisioning
 	Provider *TrialCloudProvider `json:"provider"`
+	// Subscription - customer-provided hyperscaler subscription (BYO subscription) used instead of the accounts pool
+	Subscription *SubscriptionDTO `json:"subscription,omitempty"`
+	// Registry - private container registry mirror used instead of the public registries (air-gapped installation)
+	Registry *RegistryDTO `json:"registry,omitempty"`
+	// OIDC - the OpenID Connect configuration of the kube-apiserver of the cluster, set by the update of the instance
+	OIDC *OIDCConfigDTO `json:"oidc,omitempty"`
+}
+
+// OIDCConfigDTO configures the OpenID Connect authentication of the kube-apiserver of the cluster,
+// it is applied to the shoot in Gardener
+type OIDCConfigDTO struct {
+	ClientID       string   `json:"clientID"`
+	IssuerURL      string   `json:"issuerURL"`
+	GroupsClaim    string   `json:"groupsClaim,omitempty"`
+	SigningAlgs    []string `json:"signingAlgs,omitempty"`
+	UsernameClaim  string   `json:"usernameClaim,omitempty"`
+	UsernamePrefix string   `json:"usernamePrefix,omitempty"`
+}
+
+// Validate returns the error if the client ID or the issuer URL is missing, the kube-apiserver accepts
+// only the issuer with the https scheme
+func (o OIDCConfigDTO) Validate() error {
+	if o.ClientID == "" {
+		return errors.New("OIDC clientID is required")
+	}
+	issuer, err := url.Parse(o.IssuerURL)
+	if err != nil || issuer.Scheme != "https" || issuer.Host == "" {
+		return errors.Errorf("OIDC issuerURL %q must be a valid https URL", o.IssuerURL)
+	}
+	return nil
+}
+
+// RegistryDTO configures the private container registry mirror the Kyma components are installed from,
+// so the runtimes in restricted networks do not pull the images from the public registries
+type RegistryDTO struct {
+	// URL is the registry host with the optional port and path, without the scheme, e.g. "registry.example.com:5000/kyma"
+	URL string `json:"url"`
+	// PullSecrets are the image pull secrets the Kyma components use to authenticate to the registry,
+	// the broker creates them in the runtime
+	PullSecrets []RegistryPullSecretDTO `json:"pullSecrets,omitempty"`
+}
+
+// RegistryPullSecretDTO holds the name of the image pull secret and the credentials to the registry it contains
+type RegistryPullSecretDTO struct {
+	Name     string `json:"name"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// SubscriptionDTO references the customer-provided hyperscaler credentials, either by the name of the secret
+// created in the Gardener project namespace or by the credentials payload. The payload is stored as a secret
+// by the broker and is never persisted with the provisioning parameters.
+type SubscriptionDTO struct {
+	SecretName  string            `json:"secretName,omitempty"`
+	Credentials map[string]string `json:"credentials,omitempty"`
+}
+
+// UpdatingParametersDTO holds the parameters which can be changed for the existing instance
+type UpdatingParametersDTO struct {
+	// Subscription - new credentials of the customer-provided hyperscaler subscription (credentials rotation)
+	Subscription *SubscriptionDTO `json:"subscription,omitempty"`
+
+	// following parameters change the cluster of the runtime
+	AutoScalerMin  *int    `json:"autoScalerMin,omitempty"`
+	AutoScalerMax  *int    `json:"autoScalerMax,omitempty"`
+	MaxSurge       *int    `json:"maxSurge,omitempty"`
+	MaxUnavailable *int    `json:"maxUnavailable,omitempty"`
+	MachineType    *string `json:"machineType,omitempty"`
+	// OIDC replaces the OpenID Connect configuration of the cluster, it is not passed to the Provisioner
+	OIDC *OIDCConfigDTO `json:"oidc,omitempty"`
+}
+
+// UpdatesCluster tells whether any of the parameters which change the cluster of the runtime is set
+func (p UpdatingParametersDTO) UpdatesCluster() bool {
+	return p.AutoScalerMin != nil || p.AutoScalerMax != nil || p.MaxSurge != nil || p.MaxUnavailable != nil || p.MachineType != nil || p.OIDC != nil
+}
+
+// ApplyTo overrides the provisioning parameters with the parameters which are set
+func (p UpdatingParametersDTO) ApplyTo(parameters *ProvisioningParametersDTO) {
+	if p.AutoScalerMin != nil {
+		parameters.AutoScalerMin = p.AutoScalerMin
+	}
+	if p.AutoScalerMax != nil {
+		parameters.AutoScalerMax = p.AutoScalerMax
+	}
+	if p.MaxSurge != nil {
+		parameters.MaxSurge = p.MaxSurge
+	}
+	if p.MaxUnavailable != nil {
+		parameters.MaxUnavailable = p.MaxUnavailable
+	}
+	if p.MachineType != nil {
+		parameters.MachineType = p.MachineType
+	}
+	if p.OIDC != nil {
+		parameters.OIDC = p.OIDC
+	}
 }
 
 type ERSContext struct {
 	TenantID        string                  `json:"tenant_id"`
 	SubAccountID    string                  `json:"subaccount_id"`
 	GlobalAccountID string                  `json:"globalaccount_id"`
+	UserID          string                  `json:"user_id,omitempty"`
 	ServiceManager  *ServiceManagerEntryDTO `json:"sm_platform_credentials,omitempty"`
 }
 
@@ -0,0 +1,69 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvisioningParameters_Diff(t *testing.T) {
+	// given
+	region := "westeurope"
+	existing := ProvisioningParameters{
+		ErsContext: ERSContext{GlobalAccountID: "ga-1", UserID: "john.smith@example.com"},
+		Parameters: ProvisioningParametersDTO{
+			Region:       &region,
+			Subscription: &SubscriptionDTO{Credentials: map[string]string{"clientSecret": "secret-1"}},
+			OIDC:         &OIDCConfigDTO{ClientID: "kyma", IssuerURL: "https://issuer.example.com"},
+		},
+	}
+	requested := ProvisioningParameters{
+		ErsContext: ERSContext{GlobalAccountID: "ga-2", UserID: "jane.doe@example.com"},
+		Parameters: ProvisioningParametersDTO{
+			Subscription: &SubscriptionDTO{Credentials: map[string]string{"clientSecret": "secret-2"}},
+			Registry:     &RegistryDTO{URL: "registry.example.com", PullSecrets: []RegistryPullSecretDTO{{Name: "pull", Password: "secret-3"}}},
+			OIDC:         &OIDCConfigDTO{ClientID: "kyma", IssuerURL: "https://other.example.com"},
+		},
+	}
+
+	// when
+	diff, err := existing.Diff(requested)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []ParameterDiff{
+		{Field: "ers_context.globalaccount_id", Existing: "ga-1", Requested: "ga-2"},
+		{Field: "ers_context.user_id", Existing: redactedValue, Requested: redactedValue},
+		{Field: "parameters.oidc.issuerURL", Existing: "https://issuer.example.com", Requested: "https://other.example.com"},
+		{Field: "parameters.region", Existing: "westeurope", Requested: nil},
+		{Field: "parameters.registry.pullSecrets", Existing: nil, Requested: redactedValue},
+		{Field: "parameters.registry.url", Existing: nil, Requested: "registry.example.com"},
+		{Field: "parameters.subscription.credentials.clientSecret", Existing: redactedValue, Requested: redactedValue},
+	}, diff)
+}
+
+func TestProvisioningParameters_LoggableFields(t *testing.T) {
+	// given
+	machineType := "Standard_D8_v3"
+	pp := ProvisioningParameters{
+		PlanID:     "plan-id",
+		ErsContext: ERSContext{SubAccountID: "sa", ServiceManager: &ServiceManagerEntryDTO{URL: "https://sm.example.com"}},
+		Parameters: ProvisioningParametersDTO{
+			MachineType:  &machineType,
+			Subscription: &SubscriptionDTO{SecretName: "byo", Credentials: map[string]string{"clientSecret": "secret"}},
+		},
+	}
+
+	// when
+	fields, err := pp.LoggableFields()
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, "plan-id", fields["plan_id"])
+	assert.Equal(t, "sa", fields["ers_context.subaccount_id"])
+	assert.Equal(t, "Standard_D8_v3", fields["parameters.machineType"])
+	assert.Equal(t, "byo", fields["parameters.subscription.secretName"])
+	assert.Equal(t, redactedValue, fields["parameters.subscription.credentials.clientSecret"])
+	assert.Equal(t, redactedValue, fields["ers_context.sm_platform_credentials.url"])
+}
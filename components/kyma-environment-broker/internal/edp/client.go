@@ -41,6 +41,7 @@ type Client struct {
 	config     Config
 	httpClient *http.Client
 	log        logrus.FieldLogger
+	ctx        context.Context
 }
 
 func NewClient(config Config, log logrus.FieldLogger) *Client {
@@ -57,9 +58,18 @@ func NewClient(config Config, log logrus.FieldLogger) *Client {
 		config:     config,
 		httpClient: httpClientOAuth,
 		log:        log,
+		ctx:        context.Background(),
 	}
 }
 
+// WithContext returns the copy of the client which sends the requests with the given context, so the requests
+// are cancelled when the context is done
+func (c *Client) WithContext(ctx context.Context) *Client {
+	withContext := *c
+	withContext.ctx = ctx
+	return &withContext
+}
+
 func (c *Client) dataTenantURL() string {
 	return fmt.Sprintf(dataTenantTmpl, c.config.AdminURL, c.config.Namespace)
 }
@@ -79,7 +89,7 @@ func (c *Client) CreateDataTenant(data DataTenantPayload) error {
 
 func (c *Client) DeleteDataTenant(name, env string) (err error) {
 	URL := fmt.Sprintf("%s/%s/%s", c.dataTenantURL(), name, env)
-	request, err := http.NewRequest(http.MethodDelete, URL, nil)
+	request, err := http.NewRequestWithContext(c.ctx, http.MethodDelete, URL, nil)
 	if err != nil {
 		return errors.Wrap(err, "while creating delete dataTenant request")
 	}
@@ -108,7 +118,7 @@ func (c *Client) CreateMetadataTenant(name, env string, data MetadataTenantPaylo
 
 func (c *Client) DeleteMetadataTenant(name, env, key string) (err error) {
 	URL := fmt.Sprintf("%s/%s", c.metadataTenantURL(name, env), key)
-	request, err := http.NewRequest(http.MethodDelete, URL, nil)
+	request, err := http.NewRequestWithContext(c.ctx, http.MethodDelete, URL, nil)
 	if err != nil {
 		return errors.Wrap(err, "while creating delete metadata request")
 	}
@@ -128,7 +138,7 @@ func (c *Client) DeleteMetadataTenant(name, env, key string) (err error) {
 
 func (c *Client) GetMetadataTenant(name, env string) (_ []MetadataItem, err error) {
 	var metadata []MetadataItem
-	request, err := http.NewRequest(http.MethodGet, c.metadataTenantURL(name, env), nil)
+	request, err := http.NewRequestWithContext(c.ctx, http.MethodGet, c.metadataTenantURL(name, env), nil)
 	if err != nil {
 		return metadata, errors.Wrap(err, "while creating GET metadata tenant request")
 	}
@@ -152,7 +162,7 @@ func (c *Client) GetMetadataTenant(name, env string) (_ []MetadataItem, err erro
 }
 
 func (c *Client) post(URL string, data []byte) (err error) {
-	request, err := http.NewRequest(http.MethodPost, URL, bytes.NewBuffer(data))
+	request, err := http.NewRequestWithContext(c.ctx, http.MethodPost, URL, bytes.NewBuffer(data))
 	if err != nil {
 		return errors.Wrapf(err, "while creating POST request for %s", URL)
 	}
@@ -80,6 +80,29 @@ func TestClient_DeleteDataTenant(t *testing.T) {
 	assert.Equal(t, http.StatusNotFound, response.StatusCode)
 }
 
+func TestClient_DeleteNotExistingTenant(t *testing.T) {
+	// given
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer testServer.Close()
+
+	config := Config{
+		AdminURL:  testServer.URL,
+		Namespace: testNamespace,
+	}
+	client := NewClient(config, logger.NewLogDummy())
+	client.setHttpClient(testServer.Client())
+
+	// when
+	dataErr := client.DeleteDataTenant(subAccountID, environment)
+	metadataErr := client.DeleteMetadataTenant(subAccountID, environment, "tK")
+
+	// then
+	assert.NoError(t, dataErr)
+	assert.NoError(t, metadataErr)
+}
+
 func TestClient_CreateMetadataTenant(t *testing.T) {
 	// given
 	testServer := fixHTTPServer(t)
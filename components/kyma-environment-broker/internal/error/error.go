@@ -31,3 +31,22 @@ func IsTemporaryError(err error) bool {
 	})
 	return ok && nfe.Temporary()
 }
+
+type NotFoundError struct {
+	message string
+}
+
+func NewNotFoundError(msg string, args ...interface{}) *NotFoundError {
+	return &NotFoundError{message: fmt.Sprintf(msg, args...)}
+}
+
+func (nfe NotFoundError) Error() string { return nfe.message }
+func (NotFoundError) NotFound() bool    { return true }
+
+func IsNotFoundError(err error) bool {
+	cause := errors.Cause(err)
+	nfe, ok := cause.(interface {
+		NotFound() bool
+	})
+	return ok && nfe.NotFound()
+}
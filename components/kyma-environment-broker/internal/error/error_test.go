@@ -28,3 +28,19 @@ func TestWrapf(t *testing.T) {
 	assert.Equal(t, "wrap err arg1: some error: argErr", e2.Error())
 	assert.Equal(t, "wrap err arg1: some error: argErr", e3.Error())
 }
+
+func TestNotFoundError(t *testing.T) {
+	// given
+	err1 := fmt.Errorf("some error: %s", "argErr")
+	err2 := NewNotFoundError("runtime %s not found", "abc")
+
+	// when
+	e1 := errors.Wrap(err1, "wrap err")
+	e2 := errors.Wrap(err2, "wrap err")
+
+	// then
+	assert.False(t, IsNotFoundError(e1))
+	assert.True(t, IsNotFoundError(e2))
+	assert.False(t, IsTemporaryError(e2))
+	assert.Equal(t, "wrap err: runtime abc not found", e2.Error())
+}
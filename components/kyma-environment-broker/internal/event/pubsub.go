@@ -5,7 +5,7 @@ import (
 	"reflect"
 	"sync"
 
-	"github.com/sirupsen/logrus"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/logger"
 )
 
 type Handler = func(ctx context.Context, ev interface{}) error
@@ -19,9 +19,10 @@ type Subscriber interface {
 }
 
 // PubSub implements a simple event broker which allows to send event across the application.
+// The errors of the handlers are logged with the logger of the published context, see logger.AddToContext,
+// so they carry the fields of the operation which published the event.
 type PubSub struct {
-	mu  sync.Mutex
-	log logrus.FieldLogger
+	mu sync.Mutex
 
 	handlers map[reflect.Type][]Handler
 }
@@ -40,7 +41,7 @@ func (b *PubSub) Publish(ctx context.Context, ev interface{}) {
 			go func(h Handler) {
 				err := h(ctx, ev)
 				if err != nil {
-					b.log.Errorf("error while calling pubsub event handler: %s", err.Error())
+					logger.FromContext(ctx).Errorf("error while calling pubsub event handler for %s: %s", tt, err.Error())
 				}
 			}(handler)
 		}
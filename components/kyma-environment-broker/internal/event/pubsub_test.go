@@ -2,11 +2,14 @@ package event_test
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/event"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/logger"
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"k8s.io/apimachinery/pkg/util/wait"
 )
@@ -57,6 +60,46 @@ func TestPubSub(t *testing.T) {
 	}))
 }
 
+func TestPubSub_HandlerErrorLoggedWithContextLogger(t *testing.T) {
+	// given
+	entries := make(chan *logrus.Entry, 1)
+	log := logrus.New()
+	log.AddHook(entryHook{entries: entries})
+	ctx := logger.AddToContext(context.TODO(), log.WithField(logger.OperationIDField, "operation-id"))
+
+	svc := event.NewPubSub()
+	svc.Subscribe(eventA{}, func(ctx context.Context, ev interface{}) error {
+		return errors.New("handler failed")
+	})
+
+	// when
+	svc.Publish(ctx, eventA{msg: "event"})
+
+	// then
+	select {
+	case entry := <-entries:
+		assert.Equal(t, logrus.ErrorLevel, entry.Level)
+		assert.Contains(t, entry.Message, "handler failed")
+		assert.Equal(t, "operation-id", entry.Data[logger.OperationIDField])
+	case <-time.After(2 * time.Second):
+		t.Fatal("the error of the handler was not logged")
+	}
+}
+
+// entryHook passes the logged entries to the test
+type entryHook struct {
+	entries chan *logrus.Entry
+}
+
+func (h entryHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h entryHook) Fire(entry *logrus.Entry) error {
+	h.entries <- entry
+	return nil
+}
+
 func containsA(slice []eventA, item eventA) bool {
 	for _, s := range slice {
 		if s == item {
@@ -0,0 +1,40 @@
+package freetier
+
+import (
+	"net/http"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/httputil"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+type Handler struct {
+	service *Service
+	log     logrus.FieldLogger
+}
+
+func NewHandler(service *Service, log logrus.FieldLogger) *Handler {
+	return &Handler{
+		service: service,
+		log:     log,
+	}
+}
+
+func (h *Handler) AttachRoutes(router *mux.Router) {
+	router.HandleFunc("/free-tier-usage/{global_account_id}", h.getUsage).Methods(http.MethodGet)
+}
+
+func (h *Handler) getUsage(w http.ResponseWriter, r *http.Request) {
+	globalAccountID := mux.Vars(r)["global_account_id"]
+
+	usage, err := h.service.Usage(globalAccountID)
+	if err != nil {
+		h.log.Errorf("while getting free tier usage of global account %s: %v", globalAccountID, err)
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrapf(err, "while getting free tier usage of global account %s", globalAccountID))
+		return
+	}
+
+	httputil.WriteResponse(w, http.StatusOK, usage)
+}
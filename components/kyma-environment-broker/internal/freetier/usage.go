@@ -0,0 +1,103 @@
+package freetier
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Config configures the limits of the free plans
+type Config struct {
+	// MaxInstanceHours is the cumulative lifetime of the free instances allowed per global account,
+	// the usage is not limited if it is set to zero
+	MaxInstanceHours int `envconfig:"default=0"`
+}
+
+// Usage is the cumulative usage of the free plans by the global account
+type Usage struct {
+	GlobalAccountID string  `json:"globalAccountID"`
+	InstanceHours   float64 `json:"instanceHours"`
+	ActiveInstances int     `json:"activeInstances"`
+	// LimitInstanceHours is not set if the usage is not limited
+	LimitInstanceHours int `json:"limitInstanceHours,omitempty"`
+}
+
+// Exceeded returns true if the usage reached the limit
+func (u Usage) Exceeded() bool {
+	return u.LimitInstanceHours > 0 && u.InstanceHours >= float64(u.LimitInstanceHours)
+}
+
+// Service tracks the lifetime of the free instances and enforces the configured limit
+type Service struct {
+	storage storage.FreeTierUsage
+	cfg     Config
+	log     logrus.FieldLogger
+}
+
+func NewService(storage storage.FreeTierUsage, cfg Config, log logrus.FieldLogger) *Service {
+	return &Service{
+		storage: storage,
+		cfg:     cfg,
+		log:     log.WithField("service", "FreeTierUsage"),
+	}
+}
+
+// Start records the creation of the free instance, the instance which is already tracked is not changed
+func (s *Service) Start(instanceID, globalAccountID, planID string) error {
+	err := s.storage.Insert(internal.FreeTierUsageEntry{
+		InstanceID:      instanceID,
+		GlobalAccountID: globalAccountID,
+		PlanID:          planID,
+		StartedAt:       time.Now(),
+	})
+	if err != nil && !dberr.IsAlreadyExists(err) {
+		return errors.Wrapf(err, "while inserting free tier usage of instance %s", instanceID)
+	}
+	return nil
+}
+
+// Usage calculates the cumulative usage of the global account, the instances which still exist are counted until now
+func (s *Service) Usage(globalAccountID string) (Usage, error) {
+	entries, err := s.storage.ListByGlobalAccountID(globalAccountID)
+	if err != nil {
+		return Usage{}, errors.Wrapf(err, "while listing free tier usage of global account %s", globalAccountID)
+	}
+
+	usage := Usage{
+		GlobalAccountID:    globalAccountID,
+		LimitInstanceHours: s.cfg.MaxInstanceHours,
+	}
+	now := time.Now()
+	for _, entry := range entries {
+		finishedAt := entry.FinishedAt
+		if finishedAt.IsZero() {
+			finishedAt = now
+			usage.ActiveInstances++
+		}
+		usage.InstanceHours += finishedAt.Sub(entry.StartedAt).Hours()
+	}
+
+	return usage, nil
+}
+
+// CheckLimit returns an error if the global account used up the free tier
+func (s *Service) CheckLimit(globalAccountID string) error {
+	if s.cfg.MaxInstanceHours == 0 {
+		return nil
+	}
+	usage, err := s.Usage(globalAccountID)
+	if err != nil {
+		return err
+	}
+	if usage.Exceeded() {
+		s.log.Infof("Global account %s used %.2f of %d free instance-hours", globalAccountID, usage.InstanceHours, usage.LimitInstanceHours)
+		return fmt.Errorf("the global account used up the free tier limit of %d instance-hours", usage.LimitInstanceHours)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package freetier_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/freetier"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService(t *testing.T) {
+	// given
+	db := storage.NewMemoryStorage()
+	now := time.Now()
+	for _, entry := range []internal.FreeTierUsageEntry{
+		{InstanceID: "removed", GlobalAccountID: "ga-id", StartedAt: now.Add(-30 * time.Hour), FinishedAt: now.Add(-20 * time.Hour)},
+		{InstanceID: "active", GlobalAccountID: "ga-id", StartedAt: now.Add(-5 * time.Hour)},
+		{InstanceID: "other", GlobalAccountID: "other-ga-id", StartedAt: now.Add(-100 * time.Hour)},
+	} {
+		require.NoError(t, db.FreeTierUsage().Insert(entry))
+	}
+
+	t.Run("should calculate the usage of the global account", func(t *testing.T) {
+		// given
+		svc := freetier.NewService(db.FreeTierUsage(), freetier.Config{MaxInstanceHours: 24}, logrus.New())
+
+		// when
+		usage, err := svc.Usage("ga-id")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "ga-id", usage.GlobalAccountID)
+		assert.InDelta(t, 15, usage.InstanceHours, 0.01)
+		assert.Equal(t, 1, usage.ActiveInstances)
+		assert.Equal(t, 24, usage.LimitInstanceHours)
+		assert.NoError(t, svc.CheckLimit("ga-id"))
+	})
+
+	t.Run("should reject the global account which used up the limit", func(t *testing.T) {
+		// given
+		svc := freetier.NewService(db.FreeTierUsage(), freetier.Config{MaxInstanceHours: 10}, logrus.New())
+
+		// when
+		err := svc.CheckLimit("ga-id")
+
+		// then
+		assert.EqualError(t, err, "the global account used up the free tier limit of 10 instance-hours")
+	})
+
+	t.Run("should not limit the usage by default", func(t *testing.T) {
+		// given
+		svc := freetier.NewService(db.FreeTierUsage(), freetier.Config{}, logrus.New())
+
+		// when
+		err := svc.CheckLimit("other-ga-id")
+
+		// then
+		assert.NoError(t, err)
+	})
+
+	t.Run("should not change the usage of the instance tracked again", func(t *testing.T) {
+		// given
+		svc := freetier.NewService(db.FreeTierUsage(), freetier.Config{}, logrus.New())
+
+		// when
+		err := svc.Start("removed", "ga-id", "plan-id")
+
+		// then
+		require.NoError(t, err)
+		usage, err := svc.Usage("ga-id")
+		require.NoError(t, err)
+		assert.InDelta(t, 15, usage.InstanceHours, 0.01)
+	})
+}
+
+func TestHandler(t *testing.T) {
+	// given
+	db := storage.NewMemoryStorage()
+	err := db.FreeTierUsage().Insert(internal.FreeTierUsageEntry{
+		InstanceID:      "instance-id",
+		GlobalAccountID: "ga-id",
+		StartedAt:       time.Now().Add(-2 * time.Hour),
+		FinishedAt:      time.Now(),
+	})
+	require.NoError(t, err)
+
+	router := mux.NewRouter()
+	svc := freetier.NewService(db.FreeTierUsage(), freetier.Config{}, logrus.New())
+	freetier.NewHandler(svc, logrus.New()).AttachRoutes(router)
+
+	req, err := http.NewRequest(http.MethodGet, "/free-tier-usage/ga-id", nil)
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+
+	// when
+	router.ServeHTTP(rr, req)
+
+	// then
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var usage freetier.Usage
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &usage))
+	assert.Equal(t, "ga-id", usage.GlobalAccountID)
+	assert.InDelta(t, 2, usage.InstanceHours, 0.01)
+	assert.Equal(t, 0, usage.ActiveInstances)
+	assert.Equal(t, 0, usage.LimitInstanceHours)
+}
@@ -8,6 +8,11 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+// RouteAttacher registers additional routes on the status server
+type RouteAttacher interface {
+	AttachRoutes(router *mux.Router)
+}
+
 type Server struct {
 	Address string
 	Log     log.FieldLogger
@@ -20,9 +25,15 @@ func NewServer(host, port string, log *log.Logger) *Server {
 	}
 }
 
-func (srv *Server) ServeAsync() {
+// ServeAsync starts the server with the healthz endpoint and routes of the given attachers.
+// The server listens on the status port which is not exposed outside of the cluster,
+// so it is the right place for administrative endpoints.
+func (srv *Server) ServeAsync(attachers ...RouteAttacher) {
 	healthRouter := mux.NewRouter()
 	healthRouter.HandleFunc("/healthz", livenessHandler())
+	for _, attacher := range attachers {
+		attacher.AttachRoutes(healthRouter)
+	}
 	go func() {
 		err := http.ListenAndServe(srv.Address, healthRouter)
 		if err != nil {
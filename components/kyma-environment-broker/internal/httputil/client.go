@@ -4,6 +4,8 @@ import (
 	"crypto/tls"
 	"net/http"
 	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/tracing"
 )
 
 func NewClient(timeoutSec time.Duration, skipCertVerification bool) *http.Client {
@@ -11,7 +13,7 @@ func NewClient(timeoutSec time.Duration, skipCertVerification bool) *http.Client
 	transport.TLSClientConfig.InsecureSkipVerify = skipCertVerification
 
 	return &http.Client{
-		Transport: transport,
+		Transport: tracing.NewTransport(transport),
 		Timeout:   timeoutSec * time.Second,
 	}
 }
@@ -22,7 +24,7 @@ func NewRenegotiationTLSClient(timeoutSec time.Duration, skipCertVerification bo
 	transport.TLSClientConfig.InsecureSkipVerify = skipCertVerification
 
 	return &http.Client{
-		Transport: transport,
+		Transport: tracing.NewTransport(transport),
 		Timeout:   timeoutSec * time.Second,
 	}
 }
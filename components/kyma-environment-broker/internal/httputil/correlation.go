@@ -0,0 +1,24 @@
+package httputil
+
+import "context"
+
+// CorrelationIDHeader carries the correlation ID of the KEB request and of the operation started by it. The header is set
+// in the responses of the broker and in the requests sent to the Provisioner and the Director for the operation.
+const CorrelationIDHeader = "X-Correlation-ID"
+
+type correlationIDKey struct{}
+
+// ContextWithCorrelationID returns the context which carries the correlation ID, the clients send it in the
+// CorrelationIDHeader of the requests sent with the context. The context is returned unchanged for the empty ID.
+func ContextWithCorrelationID(ctx context.Context, correlationID string) context.Context {
+	if correlationID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, correlationIDKey{}, correlationID)
+}
+
+// CorrelationIDFromContext returns the correlation ID carried by the context if possible
+func CorrelationIDFromContext(ctx context.Context) (string, bool) {
+	correlationID, ok := ctx.Value(correlationIDKey{}).(string)
+	return correlationID, ok
+}
@@ -0,0 +1,100 @@
+package httputil
+
+import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// FieldsParam is the query parameter which selects the fields of the listed objects returned in the response,
+// the fields are given either separated by a comma or by specifying the parameter multiple times
+const FieldsParam = "fields"
+
+// ProjectedPage is the page of the listed objects reduced to the requested fields
+type ProjectedPage struct {
+	Data       interface{} `json:"data"`
+	Count      int         `json:"count"`
+	TotalCount int         `json:"totalCount"`
+	NextCursor string      `json:"nextCursor,omitempty"`
+}
+
+// FieldNames returns the JSON names of the fields of the given struct
+func FieldNames(v interface{}) []string {
+	t := reflect.TypeOf(v)
+	names := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// Fields returns the fields requested with the fields query parameter, nil if all fields are requested.
+// Every requested field must be one of the allowed ones.
+func Fields(query url.Values, allowed []string) ([]string, error) {
+	var fields []string
+	for _, value := range query[FieldsParam] {
+		for _, field := range strings.Split(value, ",") {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				continue
+			}
+			if !contains(allowed, field) {
+				return nil, errors.Errorf("unknown field %q, the supported fields are: %s", field, strings.Join(allowed, ", "))
+			}
+			if !contains(fields, field) {
+				fields = append(fields, field)
+			}
+		}
+	}
+	return fields, nil
+}
+
+// HasField returns true if the field is requested, all fields are requested if none is given
+func HasField(fields []string, field string) bool {
+	return len(fields) == 0 || contains(fields, field)
+}
+
+// Project returns the given slice of objects reduced to the given fields, the slice is returned
+// unchanged if no field is given
+func Project(items interface{}, fields []string) (interface{}, error) {
+	if len(fields) == 0 {
+		return items, nil
+	}
+
+	data, err := json.Marshal(items)
+	if err != nil {
+		return nil, errors.Wrap(err, "while marshalling items")
+	}
+	var all []map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, errors.Wrap(err, "while unmarshalling items")
+	}
+
+	projected := make([]map[string]json.RawMessage, 0, len(all))
+	for _, item := range all {
+		selected := make(map[string]json.RawMessage, len(fields))
+		for _, field := range fields {
+			if value, found := item[field]; found {
+				selected[field] = value
+			}
+		}
+		projected = append(projected, selected)
+	}
+	return projected, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package httputil
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/coreos/go-oidc"
+	"github.com/pkg/errors"
+)
+
+type GatewayConfig struct {
+	// JWKSURL is the URL of the keys which Oathkeeper signs the ID tokens of the id_token mutator with
+	JWKSURL string `envconfig:"default=http://ory-oathkeeper-api.kyma-system.svc.cluster.local:4456/.well-known/jwks.json"`
+	// Issuer is the issuer of the ID tokens configured for the id_token mutator of Oathkeeper
+	Issuer string
+	// Audience is the audience which the access rules of the broker set in the ID tokens
+	Audience string `envconfig:"default=kyma-environment-broker"`
+}
+
+// GatewayAuthenticator authenticates the requests which came through the API gateway. The id_token mutator
+// of the Oathkeeper access rule replaces the token of the client with the ID token signed by Oathkeeper, so unlike
+// the X-User header the user cannot be forged by the in-cluster callers which skip the gateway.
+type GatewayAuthenticator struct {
+	verifier *oidc.IDTokenVerifier
+}
+
+func NewGatewayAuthenticator(ctx context.Context, cfg GatewayConfig) *GatewayAuthenticator {
+	keySet := oidc.NewRemoteKeySet(ctx, cfg.JWKSURL)
+	return &GatewayAuthenticator{
+		verifier: oidc.NewVerifier(cfg.Issuer, keySet, &oidc.Config{ClientID: cfg.Audience}),
+	}
+}
+
+// Authenticate returns the user of the request signed by the gateway
+func (a *GatewayAuthenticator) Authenticate(r *http.Request) (string, error) {
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return "", errors.New("the request does not contain the ID token issued by the gateway")
+	}
+	token, err := a.verifier.Verify(r.Context(), strings.TrimPrefix(header, "Bearer "))
+	if err != nil {
+		return "", errors.Wrap(err, "while verifying the ID token issued by the gateway")
+	}
+	if token.Subject == "" {
+		return "", errors.New("the ID token issued by the gateway has no subject")
+	}
+	return token.Subject, nil
+}
@@ -0,0 +1,12 @@
+package httputil
+
+import "net/http"
+
+// UserHeader holds the subject of the authenticated token. The header is set by the header mutator of the Oathkeeper
+// access rules, which overwrites the value sent by the client, so the user cannot be forged by the caller.
+const UserHeader = "X-User"
+
+// User returns the authenticated user of the request, empty if the request was not authenticated by Oathkeeper
+func User(r *http.Request) string {
+	return r.Header.Get(UserHeader)
+}
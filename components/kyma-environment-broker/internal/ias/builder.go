@@ -1,6 +1,7 @@
 package ias
 
 import (
+	"context"
 	"net/http"
 )
 
@@ -48,3 +49,15 @@ func (b *Builder) NewBundle(identifier string, inputID SPInputID) (Bundle, error
 	}
 	return NewServiceProviderBundle(identifier, ServiceProviderInputs[inputID], b.iasClient, b.config), nil
 }
+
+// BundleBuilderWithContext returns the builder of the bundles which send the IAS requests with the given context.
+// Other implementations of the BundleBuilder, for example the mocks, are returned unchanged.
+func BundleBuilderWithContext(b BundleBuilder, ctx context.Context) BundleBuilder {
+	builder, ok := b.(*Builder)
+	if !ok {
+		return b
+	}
+	withContext := *builder
+	withContext.iasClient = builder.iasClient.WithContext(ctx)
+	return &withContext
+}
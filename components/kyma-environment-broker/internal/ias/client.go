@@ -2,6 +2,7 @@ package ias
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -34,6 +35,7 @@ type (
 		config         ClientConfig
 		httpClient     *http.Client
 		closeBodyError error
+		ctx            context.Context
 	}
 
 	Request struct {
@@ -49,9 +51,18 @@ func NewClient(cli *http.Client, cfg ClientConfig) *Client {
 	return &Client{
 		config:     cfg,
 		httpClient: cli,
+		ctx:        context.Background(),
 	}
 }
 
+// WithContext returns the copy of the client which sends the requests with the given context, so the requests
+// are cancelled when the context is done
+func (c *Client) WithContext(ctx context.Context) *Client {
+	withContext := *c
+	withContext.ctx = ctx
+	return &withContext
+}
+
 func (c *Client) SetOIDCConfiguration(spID string, payload OIDCType) error {
 	return c.call(c.serviceProviderPath(spID), payload)
 }
@@ -231,7 +242,7 @@ func (c *Client) jsonRequest(path string, method string, payload interface{}) (*
 
 func (c *Client) do(sciReq *Request) (*http.Response, error) {
 	url := fmt.Sprintf("%s%s", c.config.URL, sciReq.Path)
-	req, err := http.NewRequest(sciReq.Method, url, sciReq.Body)
+	req, err := http.NewRequestWithContext(c.ctx, sciReq.Method, url, sciReq.Body)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,150 @@
+package installeroverrides
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+type InstallerOverridesDTO struct {
+	GlobalAccountID    string                       `json:"globalAccountID"`
+	Components         map[string]bool              `json:"components,omitempty"`
+	ComponentOverrides map[string]map[string]string `json:"componentOverrides,omitempty"`
+	GlobalOverrides    map[string]string            `json:"globalOverrides,omitempty"`
+	UpdatedAt          time.Time                    `json:"updatedAt"`
+}
+
+type InstallerOverridesRequest struct {
+	// Components enables (true) or disables (false) the optional components
+	Components         map[string]bool              `json:"components"`
+	ComponentOverrides map[string]map[string]string `json:"componentOverrides"`
+	GlobalOverrides    map[string]string            `json:"globalOverrides"`
+}
+
+// OptionalComponentNamesProvider provides optional components names
+type OptionalComponentNamesProvider interface {
+	GetAllOptionalComponentsNames() []string
+}
+
+type Handler struct {
+	overrides          storage.InstallerOverrides
+	optionalComponents OptionalComponentNamesProvider
+	log                logrus.FieldLogger
+}
+
+func NewHandler(overrides storage.InstallerOverrides, optionalComponents OptionalComponentNamesProvider, log logrus.FieldLogger) *Handler {
+	return &Handler{
+		overrides:          overrides,
+		optionalComponents: optionalComponents,
+		log:                log,
+	}
+}
+
+func (h *Handler) AttachRoutes(router *mux.Router) {
+	router.HandleFunc("/installer-overrides/{global_account_id}", h.getOverrides).Methods(http.MethodGet)
+	router.HandleFunc("/installer-overrides/{global_account_id}", h.setOverrides).Methods(http.MethodPut)
+	router.HandleFunc("/installer-overrides/{global_account_id}", h.deleteOverrides).Methods(http.MethodDelete)
+}
+
+func (h *Handler) getOverrides(w http.ResponseWriter, r *http.Request) {
+	globalAccountID := mux.Vars(r)["global_account_id"]
+
+	overrides, found, err := h.overrides.GetOverrides(globalAccountID)
+	if err != nil {
+		h.log.Errorf("while getting installer overrides for global account %s: %v", globalAccountID, err)
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrapf(err, "while getting installer overrides for global account %s", globalAccountID))
+		return
+	}
+	if !found {
+		httputil.WriteErrorResponse(w, http.StatusNotFound, errors.Errorf("installer overrides for global account %s are not set", globalAccountID))
+		return
+	}
+
+	httputil.WriteResponse(w, http.StatusOK, toDTO(overrides))
+}
+
+func (h *Handler) setOverrides(w http.ResponseWriter, r *http.Request) {
+	globalAccountID := mux.Vars(r)["global_account_id"]
+
+	var request InstallerOverridesRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, errors.Wrap(err, "while decoding request body"))
+		return
+	}
+	if err := h.validate(request); err != nil {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	overrides := internal.InstallerOverrides{
+		GlobalAccountID:    globalAccountID,
+		Components:         request.Components,
+		ComponentOverrides: request.ComponentOverrides,
+		GlobalOverrides:    request.GlobalOverrides,
+		UpdatedAt:          time.Now(),
+	}
+	if err := h.overrides.UpsertOverrides(overrides); err != nil {
+		h.log.Errorf("while storing installer overrides for global account %s: %v", globalAccountID, err)
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrapf(err, "while storing installer overrides for global account %s", globalAccountID))
+		return
+	}
+	h.log.Infof("Installer overrides for global account %s stored", globalAccountID)
+
+	httputil.WriteResponse(w, http.StatusOK, toDTO(overrides))
+}
+
+func (h *Handler) deleteOverrides(w http.ResponseWriter, r *http.Request) {
+	globalAccountID := mux.Vars(r)["global_account_id"]
+
+	if err := h.overrides.DeleteOverrides(globalAccountID); err != nil {
+		h.log.Errorf("while deleting installer overrides for global account %s: %v", globalAccountID, err)
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrapf(err, "while deleting installer overrides for global account %s", globalAccountID))
+		return
+	}
+	h.log.Infof("Installer overrides for global account %s deleted", globalAccountID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// validate checks if only the optional components are toggled and all overrides have keys
+func (h *Handler) validate(request InstallerOverridesRequest) error {
+	optional := map[string]struct{}{}
+	for _, name := range h.optionalComponents.GetAllOptionalComponentsNames() {
+		optional[name] = struct{}{}
+	}
+	for name := range request.Components {
+		if _, ok := optional[name]; !ok {
+			return errors.Errorf("component %q is not optional and cannot be enabled or disabled", name)
+		}
+	}
+	for name, values := range request.ComponentOverrides {
+		if name == "" {
+			return errors.New("component name of the overrides must not be empty")
+		}
+		if _, ok := values[""]; ok {
+			return errors.Errorf("overrides of the component %q must not have empty keys", name)
+		}
+	}
+	if _, ok := request.GlobalOverrides[""]; ok {
+		return errors.New("global overrides must not have empty keys")
+	}
+	return nil
+}
+
+func toDTO(overrides internal.InstallerOverrides) InstallerOverridesDTO {
+	return InstallerOverridesDTO{
+		GlobalAccountID:    overrides.GlobalAccountID,
+		Components:         overrides.Components,
+		ComponentOverrides: overrides.ComponentOverrides,
+		GlobalOverrides:    overrides.GlobalOverrides,
+		UpdatedAt:          overrides.UpdatedAt,
+	}
+}
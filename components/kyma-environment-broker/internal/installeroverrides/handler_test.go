@@ -0,0 +1,95 @@
+package installeroverrides_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/installeroverrides"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	// given
+	db := storage.NewMemoryStorage()
+
+	router := mux.NewRouter()
+	installeroverrides.NewHandler(db.InstallerOverrides(), optionalComponents{"kiali", "tracing"}, logrus.New()).AttachRoutes(router)
+
+	t.Run("should return not found for global account without overrides", func(t *testing.T) {
+		// when
+		rr := serve(t, router, http.MethodGet, "/installer-overrides/ga-id", nil)
+
+		// then
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("should reject toggle of component which is not optional", func(t *testing.T) {
+		// when
+		rr := serve(t, router, http.MethodPut, "/installer-overrides/ga-id", installeroverrides.InstallerOverridesRequest{
+			Components: map[string]bool{"istio": false},
+		})
+
+		// then
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("should store the overrides of the global account", func(t *testing.T) {
+		// when
+		rr := serve(t, router, http.MethodPut, "/installer-overrides/ga-id", installeroverrides.InstallerOverridesRequest{
+			Components:         map[string]bool{"kiali": true},
+			ComponentOverrides: map[string]map[string]string{"monitoring": {"prometheus.resources.limits.memory": "4Gi"}},
+		})
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		rr = serve(t, router, http.MethodGet, "/installer-overrides/ga-id", nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var dto installeroverrides.InstallerOverridesDTO
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &dto))
+		assert.Equal(t, "ga-id", dto.GlobalAccountID)
+		assert.Equal(t, map[string]bool{"kiali": true}, dto.Components)
+		assert.Equal(t, "4Gi", dto.ComponentOverrides["monitoring"]["prometheus.resources.limits.memory"])
+	})
+
+	t.Run("should delete the overrides of the global account", func(t *testing.T) {
+		// when
+		rr := serve(t, router, http.MethodDelete, "/installer-overrides/ga-id", nil)
+
+		// then
+		require.Equal(t, http.StatusNoContent, rr.Code)
+		_, found, err := db.InstallerOverrides().GetOverrides("ga-id")
+		require.NoError(t, err)
+		assert.False(t, found)
+	})
+}
+
+type optionalComponents []string
+
+func (c optionalComponents) GetAllOptionalComponentsNames() []string {
+	return c
+}
+
+func serve(t *testing.T, router *mux.Router, method, url string, body interface{}) *httptest.ResponseRecorder {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		require.NoError(t, err)
+	}
+	req, err := http.NewRequest(method, url, bytes.NewBuffer(payload))
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
@@ -0,0 +1,62 @@
+package installeroverrides
+
+import (
+	"sort"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/provisioner/pkg/gqlschema"
+)
+
+// Apply merges the installer overrides of the global account into the installer configuration. The steps applying
+// the overrides run after the overrides from the ConfigMaps and Secrets are collected, so the values of the global
+// account take precedence.
+func Apply(creator internal.ProvisionerInputCreator, overrides internal.InstallerOverrides) {
+	for _, name := range sortedKeys(overrides.Components) {
+		if overrides.Components[name] {
+			creator.EnableOptionalComponent(name)
+		} else {
+			creator.DisableOptionalComponent(name)
+		}
+	}
+
+	components := make([]string, 0, len(overrides.ComponentOverrides))
+	for name := range overrides.ComponentOverrides {
+		components = append(components, name)
+	}
+	sort.Strings(components)
+	for _, name := range components {
+		if entries := toConfigEntries(overrides.ComponentOverrides[name]); len(entries) > 0 {
+			creator.AppendOverrides(name, entries)
+		}
+	}
+
+	if entries := toConfigEntries(overrides.GlobalOverrides); len(entries) > 0 {
+		creator.AppendGlobalOverrides(entries)
+	}
+}
+
+func toConfigEntries(values map[string]string) []*gqlschema.ConfigEntryInput {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	entries := make([]*gqlschema.ConfigEntryInput, 0, len(keys))
+	for _, key := range keys {
+		entries = append(entries, &gqlschema.ConfigEntryInput{
+			Key:   key,
+			Value: values[key],
+		})
+	}
+	return entries
+}
+
+func sortedKeys(values map[string]bool) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
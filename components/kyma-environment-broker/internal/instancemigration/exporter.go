@@ -0,0 +1,332 @@
+package instancemigration
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession/dbmodel"
+
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	exportPageSize = 100
+	// consistencyAttempts is the number of attempts to export the instance whose operations change during the export
+	consistencyAttempts = 3
+)
+
+// Exporter exports the selected instances together with their operations and runtime states
+type Exporter struct {
+	instances     storage.Instances
+	operations    storage.Operations
+	runtimeStates storage.RuntimeStates
+	encrypt       secretTransformer
+	log           logrus.FieldLogger
+	now           func() time.Time
+}
+
+// NewExporter creates the exporter which encrypts the sensitive fields of the snapshot with the key,
+// the key must pass ValidateKey
+func NewExporter(instances storage.Instances, operations storage.Operations, runtimeStates storage.RuntimeStates, key string, log logrus.FieldLogger) *Exporter {
+	return &Exporter{
+		instances:     instances,
+		operations:    operations,
+		runtimeStates: runtimeStates,
+		encrypt:       encryptWith(storage.NewEncrypter(key)),
+		log:           log,
+		now:           time.Now,
+	}
+}
+
+// Export exports the instances matching the filter, the pagination set in the filter is ignored.
+// The export fails if any of the instances has an operation in progress, because the instance is still changed
+// by the broker. The credentials in the provisioning parameters and the values of the secret overrides
+// in the runtime states are encrypted.
+func (e *Exporter) Export(filter dbmodel.InstanceFilter) (Snapshot, error) {
+	instances, err := e.listInstances(filter)
+	if err != nil {
+		return Snapshot{}, errors.Wrap(err, "while listing instances")
+	}
+
+	snapshot := Snapshot{
+		Version:    SnapshotVersion,
+		ExportedAt: e.now(),
+		Instances:  make([]InstanceSnapshot, 0, len(instances)),
+	}
+	for _, instance := range instances {
+		exported, err := e.exportInstance(instance.InstanceID)
+		if err != nil {
+			return Snapshot{}, errors.Wrapf(err, "while exporting instance %s", instance.InstanceID)
+		}
+		snapshot.Instances = append(snapshot.Instances, exported)
+		e.log.Infof("Exported instance %s with %d operations and %d runtime states",
+			instance.InstanceID, len(exported.Operations), len(exported.RuntimeStates))
+	}
+
+	if err := Validate(snapshot); err != nil {
+		return Snapshot{}, errors.Wrap(err, "exported snapshot is not valid")
+	}
+	return snapshot, nil
+}
+
+func (e *Exporter) listInstances(filter dbmodel.InstanceFilter) ([]internal.Instance, error) {
+	filter.PageSize = exportPageSize
+	filter.SkipLargeColumns = false
+
+	result := make([]internal.Instance, 0)
+	for page := 1; ; page++ {
+		filter.Page = page
+		instances, count, totalCount, err := e.instances.List(filter)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, instances...)
+		if count == 0 || len(result) >= totalCount {
+			return result, nil
+		}
+	}
+}
+
+// exportInstance reads the instance with its operations and runtime states, the export is repeated
+// if the operations of the instance changed while they were read
+func (e *Exporter) exportInstance(instanceID string) (InstanceSnapshot, error) {
+	for attempt := 1; attempt <= consistencyAttempts; attempt++ {
+		before, err := e.listOperations(instanceID)
+		if err != nil {
+			return InstanceSnapshot{}, err
+		}
+		for _, op := range before {
+			if op.State == domain.InProgress {
+				return InstanceSnapshot{}, errors.Errorf("operation %s is in progress", op.ID)
+			}
+		}
+
+		exported, err := e.readInstance(instanceID, before)
+		if err != nil {
+			return InstanceSnapshot{}, err
+		}
+
+		after, err := e.listOperations(instanceID)
+		if err != nil {
+			return InstanceSnapshot{}, err
+		}
+		if sameOperations(before, after) {
+			return exported, nil
+		}
+		e.log.Warnf("Operations of the instance %s changed during the export, attempt %d of %d", instanceID, attempt, consistencyAttempts)
+	}
+	return InstanceSnapshot{}, errors.Errorf("operations of the instance changed during %d export attempts", consistencyAttempts)
+}
+
+func (e *Exporter) listOperations(instanceID string) ([]internal.Operation, error) {
+	operations, err := e.operations.ListOperationsByInstanceID(instanceID)
+	switch {
+	case dberr.IsNotFound(err):
+		return []internal.Operation{}, nil
+	case err != nil:
+		return nil, errors.Wrap(err, "while listing operations")
+	}
+	return operations, nil
+}
+
+func (e *Exporter) readInstance(instanceID string, listed []internal.Operation) (InstanceSnapshot, error) {
+	instance, err := e.instances.GetByID(instanceID)
+	if err != nil {
+		return InstanceSnapshot{}, errors.Wrap(err, "while getting instance")
+	}
+	instance.ProvisioningParameters, err = transformProvisioningParameters(instance.ProvisioningParameters, e.encrypt)
+	if err != nil {
+		return InstanceSnapshot{}, errors.Wrap(err, "while encrypting instance provisioning parameters")
+	}
+
+	operations, err := e.readOperations(instanceID)
+	if err != nil {
+		return InstanceSnapshot{}, err
+	}
+	exported := map[string]bool{}
+	for _, op := range operations {
+		exported[op.ID] = true
+	}
+	for _, op := range listed {
+		if !exported[op.ID] {
+			return InstanceSnapshot{}, errors.Errorf("operation %s cannot be exported, only the latest provisioning, "+
+				"deprovisioning and plan migration operations of the instance are supported", op.ID)
+		}
+	}
+
+	states := make([]internal.RuntimeState, 0)
+	if instance.RuntimeID != "" {
+		runtimeStates, err := e.runtimeStates.ListByRuntimeID(instance.RuntimeID)
+		if err != nil && !dberr.IsNotFound(err) {
+			return InstanceSnapshot{}, errors.Wrap(err, "while listing runtime states")
+		}
+		for _, state := range runtimeStates {
+			// the runtime states of the operations which are not exported would have no reference in the target database
+			if !exported[state.OperationID] {
+				e.log.Warnf("Skipping runtime state %s of the operation %s which does not belong to the instance %s", state.ID, state.OperationID, instanceID)
+				continue
+			}
+			encrypted, err := transformRuntimeState(state, e.encrypt)
+			if err != nil {
+				return InstanceSnapshot{}, errors.Wrap(err, "while encrypting runtime state")
+			}
+			states = append(states, encrypted)
+		}
+	}
+
+	return InstanceSnapshot{
+		Instance:      *instance,
+		Operations:    operations,
+		RuntimeStates: states,
+	}, nil
+}
+
+// readOperations reads the operations of the instance with their type specific data
+func (e *Exporter) readOperations(instanceID string) ([]OperationSnapshot, error) {
+	result := make([]OperationSnapshot, 0)
+	add := func(op internal.Operation, opType dbmodel.OperationType, data interface{}) error {
+		// the orchestrations are not exported, the operation would reference the orchestration missing in the target database
+		op.OrchestrationID = ""
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return errors.Wrapf(err, "while marshalling operation %s", op.ID)
+		}
+		result = append(result, OperationSnapshot{Operation: op, Type: string(opType), Data: raw})
+		return nil
+	}
+
+	provisioning, err := e.operations.GetProvisioningOperationByInstanceID(instanceID)
+	switch {
+	case err == nil:
+		if provisioning.ProvisioningParameters, err = e.encryptProvisioningParameters(provisioning.Operation, provisioning.ProvisioningParameters); err != nil {
+			return nil, err
+		}
+		if err := add(provisioning.Operation, dbmodel.OperationTypeProvision, provisioning); err != nil {
+			return nil, err
+		}
+	case !dberr.IsNotFound(err):
+		return nil, errors.Wrap(err, "while getting provisioning operation")
+	}
+
+	upgradeKymaOperations, err := e.operations.ListUpgradeKymaOperationsByInstanceID(instanceID)
+	if err != nil && !dberr.IsNotFound(err) {
+		return nil, errors.Wrap(err, "while listing upgrade kyma operations")
+	}
+	for _, op := range upgradeKymaOperations {
+		if op.ProvisioningParameters, err = e.encryptProvisioningParameters(op.Operation, op.ProvisioningParameters); err != nil {
+			return nil, err
+		}
+		if err := add(op.Operation, dbmodel.OperationTypeUpgradeKyma, op); err != nil {
+			return nil, err
+		}
+	}
+
+	upgradeClusterOperations, err := e.operations.ListUpgradeClusterOperationsByInstanceID(instanceID)
+	if err != nil && !dberr.IsNotFound(err) {
+		return nil, errors.Wrap(err, "while listing upgrade cluster operations")
+	}
+	for _, op := range upgradeClusterOperations {
+		if op.ProvisioningParameters, err = e.encryptProvisioningParameters(op.Operation, op.ProvisioningParameters); err != nil {
+			return nil, err
+		}
+		if err := add(op.Operation, dbmodel.OperationTypeUpgradeCluster, op); err != nil {
+			return nil, err
+		}
+	}
+
+	planMigration, err := e.operations.GetPlanMigrationOperationByInstanceID(instanceID)
+	switch {
+	case err == nil:
+		if planMigration.ProvisioningParameters, err = e.encryptProvisioningParameters(planMigration.Operation, planMigration.ProvisioningParameters); err != nil {
+			return nil, err
+		}
+		if planMigration.SourceProvisioningParameters, err = e.encryptProvisioningParameters(planMigration.Operation, planMigration.SourceProvisioningParameters); err != nil {
+			return nil, err
+		}
+		if err := add(planMigration.Operation, dbmodel.OperationTypeMigratePlan, planMigration); err != nil {
+			return nil, err
+		}
+	case !dberr.IsNotFound(err):
+		return nil, errors.Wrap(err, "while getting plan migration operation")
+	}
+
+	updating, err := e.operations.GetUpdatingOperationByInstanceID(instanceID)
+	switch {
+	case err == nil:
+		if err := add(updating.Operation, dbmodel.OperationTypeUpdate, updating); err != nil {
+			return nil, err
+		}
+	case !dberr.IsNotFound(err):
+		return nil, errors.Wrap(err, "while getting updating operation")
+	}
+
+	suspension, err := e.operations.GetSuspensionOperationByInstanceID(instanceID)
+	switch {
+	case err == nil:
+		if err := add(suspension.Operation, dbmodel.OperationTypeSuspension, suspension); err != nil {
+			return nil, err
+		}
+	case !dberr.IsNotFound(err):
+		return nil, errors.Wrap(err, "while getting suspension operation")
+	}
+
+	accountMigration, err := e.operations.GetAccountMigrationOperationByInstanceID(instanceID)
+	switch {
+	case err == nil:
+		if err := add(accountMigration.Operation, dbmodel.OperationTypeAccountMigration, accountMigration); err != nil {
+			return nil, err
+		}
+	case !dberr.IsNotFound(err):
+		return nil, errors.Wrap(err, "while getting account migration operation")
+	}
+
+	deprovisioning, err := e.operations.GetDeprovisioningOperationByInstanceID(instanceID)
+	switch {
+	case err == nil:
+		if deprovisioning.ProvisioningParameters, err = e.encryptProvisioningParameters(deprovisioning.Operation, deprovisioning.ProvisioningParameters); err != nil {
+			return nil, err
+		}
+		if err := add(deprovisioning.Operation, dbmodel.OperationTypeDeprovision, deprovisioning); err != nil {
+			return nil, err
+		}
+	case !dberr.IsNotFound(err):
+		return nil, errors.Wrap(err, "while getting deprovisioning operation")
+	}
+
+	return result, nil
+}
+
+// sameOperations checks if both lists contain the same operations in the same versions and states
+func sameOperations(before, after []internal.Operation) bool {
+	if len(before) != len(after) {
+		return false
+	}
+	versions := map[string]string{}
+	for _, op := range before {
+		versions[op.ID] = operationVersion(op)
+	}
+	for _, op := range after {
+		if versions[op.ID] != operationVersion(op) {
+			return false
+		}
+	}
+	return true
+}
+
+func operationVersion(op internal.Operation) string {
+	return fmt.Sprintf("%d/%s/%s", op.Version, op.State, op.UpdatedAt)
+}
+
+func (e *Exporter) encryptProvisioningParameters(op internal.Operation, raw string) (string, error) {
+	encrypted, err := transformProvisioningParameters(raw, e.encrypt)
+	if err != nil {
+		return "", errors.Wrapf(err, "while encrypting provisioning parameters of operation %s", op.ID)
+	}
+	return encrypted, nil
+}
@@ -0,0 +1,280 @@
+package instancemigration
+
+import (
+	"encoding/json"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession/dbmodel"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Importer imports the exported instances into the database
+type Importer struct {
+	db      storage.BrokerStorage
+	decrypt secretTransformer
+	log     logrus.FieldLogger
+}
+
+// NewImporter creates the importer which decrypts the sensitive fields of the snapshot with the key
+// the snapshot was exported with
+func NewImporter(db storage.BrokerStorage, key string, log logrus.FieldLogger) *Importer {
+	return &Importer{
+		db:      db,
+		decrypt: decryptWith(storage.NewEncrypter(key)),
+		log:     log,
+	}
+}
+
+// Import validates the snapshot and checks that none of the instances, operations and runtime states exist
+// in the database before anything is written, then inserts the instances together with their operations
+// and runtime states in a single transaction, so nothing is imported if any of the inserts or the decryption
+// of the sensitive fields fails
+func (i *Importer) Import(snapshot Snapshot) error {
+	if err := Validate(snapshot); err != nil {
+		return errors.Wrap(err, "snapshot is not valid")
+	}
+	if err := i.checkConflicts(snapshot); err != nil {
+		return errors.Wrap(err, "snapshot conflicts with the database")
+	}
+
+	return i.db.InTransaction(func(tx storage.BrokerStorage) error {
+		for _, instance := range snapshot.Instances {
+			if err := importInstance(tx, instance, i.decrypt); err != nil {
+				return errors.Wrapf(err, "while importing instance %s, none of the instances was imported", instance.Instance.InstanceID)
+			}
+			i.log.Infof("Inserted instance %s with %d operations and %d runtime states",
+				instance.Instance.InstanceID, len(instance.Operations), len(instance.RuntimeStates))
+		}
+		return nil
+	})
+}
+
+// Validate checks the referential integrity of the snapshot: every operation belongs to its instance, every runtime
+// state belongs to the operation of the same instance and none of the IDs is repeated
+func Validate(snapshot Snapshot) error {
+	if snapshot.Version != SnapshotVersion {
+		return errors.Errorf("unsupported snapshot version %d, expected %d", snapshot.Version, SnapshotVersion)
+	}
+
+	var result *multierror.Error
+	instanceIDs := map[string]bool{}
+	operationIDs := map[string]bool{}
+	stateIDs := map[string]bool{}
+	for _, instance := range snapshot.Instances {
+		instanceID := instance.Instance.InstanceID
+		if instanceID == "" {
+			result = multierror.Append(result, errors.New("instance without ID"))
+			continue
+		}
+		if instanceIDs[instanceID] {
+			result = multierror.Append(result, errors.Errorf("instance %s is repeated", instanceID))
+		}
+		instanceIDs[instanceID] = true
+
+		instanceOperations := map[string]bool{}
+		for _, op := range instance.Operations {
+			switch {
+			case op.ID == "":
+				result = multierror.Append(result, errors.Errorf("operation without ID in instance %s", instanceID))
+				continue
+			case operationIDs[op.ID]:
+				result = multierror.Append(result, errors.Errorf("operation %s is repeated", op.ID))
+			case op.InstanceID != instanceID:
+				result = multierror.Append(result, errors.Errorf("operation %s belongs to instance %s instead of %s", op.ID, op.InstanceID, instanceID))
+			case !supportedOperationType(op.Type):
+				result = multierror.Append(result, errors.Errorf("operation %s has unsupported type %q", op.ID, op.Type))
+			}
+			operationIDs[op.ID] = true
+			instanceOperations[op.ID] = true
+		}
+
+		for _, state := range instance.RuntimeStates {
+			switch {
+			case state.ID == "":
+				result = multierror.Append(result, errors.Errorf("runtime state without ID in instance %s", instanceID))
+				continue
+			case stateIDs[state.ID]:
+				result = multierror.Append(result, errors.Errorf("runtime state %s is repeated", state.ID))
+			case !instanceOperations[state.OperationID]:
+				result = multierror.Append(result, errors.Errorf("runtime state %s references operation %s which is not exported with instance %s", state.ID, state.OperationID, instanceID))
+			case state.RuntimeID != instance.Instance.RuntimeID:
+				result = multierror.Append(result, errors.Errorf("runtime state %s belongs to runtime %s instead of %s", state.ID, state.RuntimeID, instance.Instance.RuntimeID))
+			}
+			stateIDs[state.ID] = true
+		}
+	}
+
+	return result.ErrorOrNil()
+}
+
+func supportedOperationType(opType string) bool {
+	switch dbmodel.OperationType(opType) {
+	case dbmodel.OperationTypeProvision,
+		dbmodel.OperationTypeDeprovision,
+		dbmodel.OperationTypeUpgradeKyma,
+		dbmodel.OperationTypeUpgradeCluster,
+		dbmodel.OperationTypeMigratePlan,
+		dbmodel.OperationTypeUpdate,
+		dbmodel.OperationTypeSuspension,
+		dbmodel.OperationTypeAccountMigration:
+		return true
+	}
+	return false
+}
+
+func (i *Importer) checkConflicts(snapshot Snapshot) error {
+	var result *multierror.Error
+	for _, instance := range snapshot.Instances {
+		_, err := i.db.Instances().GetByID(instance.Instance.InstanceID)
+		switch {
+		case err == nil:
+			result = multierror.Append(result, errors.Errorf("instance %s already exists", instance.Instance.InstanceID))
+		case !dberr.IsNotFound(err):
+			return errors.Wrapf(err, "while getting instance %s", instance.Instance.InstanceID)
+		}
+
+		for _, op := range instance.Operations {
+			_, err := i.db.Operations().GetOperationByID(op.ID)
+			switch {
+			case err == nil:
+				result = multierror.Append(result, errors.Errorf("operation %s already exists", op.ID))
+			case !dberr.IsNotFound(err):
+				return errors.Wrapf(err, "while getting operation %s", op.ID)
+			}
+		}
+
+		for _, state := range instance.RuntimeStates {
+			_, err := i.db.RuntimeStates().GetByOperationID(state.OperationID)
+			switch {
+			case err == nil:
+				result = multierror.Append(result, errors.Errorf("runtime state of operation %s already exists", state.OperationID))
+			case !dberr.IsNotFound(err):
+				return errors.Wrapf(err, "while getting runtime state of operation %s", state.OperationID)
+			}
+		}
+	}
+	return result.ErrorOrNil()
+}
+
+func importInstance(tx storage.BrokerStorage, snapshot InstanceSnapshot, decrypt secretTransformer) error {
+	instance := snapshot.Instance
+	pp, err := transformProvisioningParameters(instance.ProvisioningParameters, decrypt)
+	if err != nil {
+		return errors.Wrap(err, "while decrypting instance provisioning parameters, check the snapshot key")
+	}
+	instance.ProvisioningParameters = pp
+	if err := tx.Instances().Insert(instance); err != nil {
+		return errors.Wrap(err, "while inserting instance")
+	}
+	for _, op := range snapshot.Operations {
+		if err := insertOperation(tx.Operations(), op, decrypt); err != nil {
+			return errors.Wrapf(err, "while inserting operation %s", op.ID)
+		}
+	}
+	for _, state := range snapshot.RuntimeStates {
+		state, err := transformRuntimeState(state, decrypt)
+		if err != nil {
+			return errors.Wrap(err, "while decrypting runtime state, check the snapshot key")
+		}
+		if err := tx.RuntimeStates().Insert(state); err != nil {
+			return errors.Wrapf(err, "while inserting runtime state %s", state.ID)
+		}
+	}
+	return nil
+}
+
+// insertOperation inserts the operation with the decrypted provisioning parameters
+func insertOperation(operations storage.Operations, snapshot OperationSnapshot, decrypt secretTransformer) error {
+	decryptParameters := func(raw *string) error {
+		decrypted, err := transformProvisioningParameters(*raw, decrypt)
+		if err != nil {
+			return errors.Wrap(err, "while decrypting provisioning parameters, check the snapshot key")
+		}
+		*raw = decrypted
+		return nil
+	}
+
+	switch dbmodel.OperationType(snapshot.Type) {
+	case dbmodel.OperationTypeProvision:
+		var op internal.ProvisioningOperation
+		if err := json.Unmarshal(snapshot.Data, &op); err != nil {
+			return errors.Wrap(err, "while unmarshalling provisioning operation")
+		}
+		op.Operation = snapshot.Operation
+		if err := decryptParameters(&op.ProvisioningParameters); err != nil {
+			return err
+		}
+		return operations.InsertProvisioningOperation(op)
+	case dbmodel.OperationTypeDeprovision:
+		var op internal.DeprovisioningOperation
+		if err := json.Unmarshal(snapshot.Data, &op); err != nil {
+			return errors.Wrap(err, "while unmarshalling deprovisioning operation")
+		}
+		op.Operation = snapshot.Operation
+		if err := decryptParameters(&op.ProvisioningParameters); err != nil {
+			return err
+		}
+		return operations.InsertDeprovisioningOperation(op)
+	case dbmodel.OperationTypeUpgradeKyma:
+		var op internal.UpgradeKymaOperation
+		if err := json.Unmarshal(snapshot.Data, &op); err != nil {
+			return errors.Wrap(err, "while unmarshalling upgrade kyma operation")
+		}
+		op.Operation = snapshot.Operation
+		if err := decryptParameters(&op.ProvisioningParameters); err != nil {
+			return err
+		}
+		return operations.InsertUpgradeKymaOperation(op)
+	case dbmodel.OperationTypeUpgradeCluster:
+		var op internal.UpgradeClusterOperation
+		if err := json.Unmarshal(snapshot.Data, &op); err != nil {
+			return errors.Wrap(err, "while unmarshalling upgrade cluster operation")
+		}
+		op.Operation = snapshot.Operation
+		if err := decryptParameters(&op.ProvisioningParameters); err != nil {
+			return err
+		}
+		return operations.InsertUpgradeClusterOperation(op)
+	case dbmodel.OperationTypeMigratePlan:
+		var op internal.PlanMigrationOperation
+		if err := json.Unmarshal(snapshot.Data, &op); err != nil {
+			return errors.Wrap(err, "while unmarshalling plan migration operation")
+		}
+		op.Operation = snapshot.Operation
+		if err := decryptParameters(&op.ProvisioningParameters); err != nil {
+			return err
+		}
+		if err := decryptParameters(&op.SourceProvisioningParameters); err != nil {
+			return err
+		}
+		return operations.InsertPlanMigrationOperation(op)
+	case dbmodel.OperationTypeUpdate:
+		var op internal.UpdatingOperation
+		if err := json.Unmarshal(snapshot.Data, &op); err != nil {
+			return errors.Wrap(err, "while unmarshalling updating operation")
+		}
+		op.Operation = snapshot.Operation
+		return operations.InsertUpdatingOperation(op)
+	case dbmodel.OperationTypeSuspension:
+		var op internal.SuspensionOperation
+		if err := json.Unmarshal(snapshot.Data, &op); err != nil {
+			return errors.Wrap(err, "while unmarshalling suspension operation")
+		}
+		op.Operation = snapshot.Operation
+		return operations.InsertSuspensionOperation(op)
+	case dbmodel.OperationTypeAccountMigration:
+		var op internal.AccountMigrationOperation
+		if err := json.Unmarshal(snapshot.Data, &op); err != nil {
+			return errors.Wrap(err, "while unmarshalling account migration operation")
+		}
+		op.Operation = snapshot.Operation
+		return operations.InsertAccountMigrationOperation(op)
+	default:
+		return errors.Errorf("unsupported operation type %q", snapshot.Type)
+	}
+}
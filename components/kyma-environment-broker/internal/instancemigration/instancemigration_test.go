@@ -0,0 +1,248 @@
+package instancemigration
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/ptr"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession/dbmodel"
+	"github.com/kyma-project/control-plane/components/provisioner/pkg/gqlschema"
+
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	provisioningParameters = `{"plan_id":"plan","ers_context":{"globalaccount_id":"ga-1","sm_platform_credentials":{"url":"https://sm","credentials":{"basic":{"username":"user","password":"sm-password"}}}}}`
+	snapshotKey            = "3s6v9y$B&E)H@McQfTjWnZr4u7w!z%C*"
+)
+
+func TestExportImport(t *testing.T) {
+	// given
+	now := time.Now()
+	source := storage.NewMemoryStorage()
+	fixInstance(t, source, "instance-1", "runtime-1", "ga-1", now)
+	fixInstance(t, source, "instance-2", "runtime-2", "ga-2", now)
+	require.NoError(t, source.Operations().InsertUpgradeKymaOperation(internal.UpgradeKymaOperation{
+		RuntimeOperation: internal.RuntimeOperation{
+			Operation: internal.Operation{ID: "upgrade-1", InstanceID: "instance-1", State: domain.Succeeded, OrchestrationID: "orchestration-1", CreatedAt: now.Add(time.Hour)},
+			RuntimeID: "runtime-1",
+		},
+		ProvisioningParameters: provisioningParameters,
+	}))
+	require.NoError(t, source.RuntimeStates().Insert(internal.RuntimeState{
+		ID:          "state-upgrade-1",
+		RuntimeID:   "runtime-1",
+		OperationID: "upgrade-1",
+		KymaConfig: gqlschema.KymaConfigInput{
+			Version: "1.18.0",
+			Configuration: []*gqlschema.ConfigEntryInput{
+				{Key: "password", Value: "secret", Secret: ptr.Bool(true)},
+				{Key: "domain", Value: "example.com"},
+			},
+		},
+	}))
+	exporter := NewExporter(source.Instances(), source.Operations(), source.RuntimeStates(), snapshotKey, logrus.New())
+
+	// when
+	snapshot, err := exporter.Export(dbmodel.InstanceFilter{GlobalAccountIDs: []string{"ga-1"}})
+
+	// then
+	require.NoError(t, err)
+	require.Len(t, snapshot.Instances, 1)
+	exported := snapshot.Instances[0]
+	assert.Equal(t, "instance-1", exported.Instance.InstanceID)
+	require.Len(t, exported.Operations, 2)
+	for _, op := range exported.Operations {
+		assert.Empty(t, op.OrchestrationID)
+	}
+	require.Len(t, exported.RuntimeStates, 2)
+
+	// when
+	raw, err := json.Marshal(snapshot)
+	require.NoError(t, err)
+	var decoded Snapshot
+	require.NoError(t, json.Unmarshal(raw, &decoded))
+	target := storage.NewMemoryStorage()
+	err = NewImporter(target, snapshotKey, logrus.New()).Import(decoded)
+
+	// then
+	require.NoError(t, err)
+	instance, err := target.Instances().GetByID("instance-1")
+	require.NoError(t, err)
+	assert.Equal(t, "runtime-1", instance.RuntimeID)
+	pp, err := instance.GetProvisioningParameters()
+	require.NoError(t, err)
+	require.NotNil(t, pp.ErsContext.ServiceManager)
+	assert.Equal(t, "sm-password", pp.ErsContext.ServiceManager.Credentials.BasicAuth.Password)
+	provisioning, err := target.Operations().GetProvisioningOperationByInstanceID("instance-1")
+	require.NoError(t, err)
+	assert.Equal(t, "provisioning-instance-1", provisioning.ID)
+	assert.Equal(t, "runtime-1", provisioning.RuntimeID)
+	upgrade, err := target.Operations().GetUpgradeKymaOperationByID("upgrade-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.Succeeded, upgrade.State)
+	state, err := target.RuntimeStates().GetByOperationID("upgrade-1")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", state.KymaConfig.Configuration[0].Value)
+	assert.Equal(t, "example.com", state.KymaConfig.Configuration[1].Value)
+	_, err = target.Instances().GetByID("instance-2")
+	assert.Error(t, err)
+
+	// when the snapshot is imported again
+	err = NewImporter(target, snapshotKey, logrus.New()).Import(decoded)
+
+	// then
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "instance instance-1 already exists")
+	assert.Contains(t, err.Error(), "operation upgrade-1 already exists")
+}
+
+func TestExportImport_EncryptedSecrets(t *testing.T) {
+	// given
+	source := storage.NewMemoryStorage()
+	fixInstance(t, source, "instance-1", "runtime-1", "ga-1", time.Now())
+	require.NoError(t, source.RuntimeStates().Insert(internal.RuntimeState{
+		ID:          "state-password",
+		RuntimeID:   "runtime-1",
+		OperationID: "provisioning-instance-1",
+		KymaConfig: gqlschema.KymaConfigInput{
+			Configuration: []*gqlschema.ConfigEntryInput{
+				{Key: "password", Value: "override-password", Secret: ptr.Bool(true)},
+				{Key: "domain", Value: "example.com"},
+			},
+		},
+	}))
+	exporter := NewExporter(source.Instances(), source.Operations(), source.RuntimeStates(), snapshotKey, logrus.New())
+
+	// when
+	snapshot, err := exporter.Export(dbmodel.InstanceFilter{InstanceIDs: []string{"instance-1"}})
+
+	// then
+	require.NoError(t, err)
+	raw, err := json.Marshal(snapshot)
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), "sm-password")
+	assert.NotContains(t, string(raw), "override-password")
+	assert.Contains(t, string(raw), "example.com")
+
+	// when
+	target := storage.NewMemoryStorage()
+	err = NewImporter(target, snapshotKey, logrus.New()).Import(snapshot)
+
+	// then
+	require.NoError(t, err)
+	instance, err := target.Instances().GetByID("instance-1")
+	require.NoError(t, err)
+	pp, err := instance.GetProvisioningParameters()
+	require.NoError(t, err)
+	require.NotNil(t, pp.ErsContext.ServiceManager)
+	assert.Equal(t, "sm-password", pp.ErsContext.ServiceManager.Credentials.BasicAuth.Password)
+	state, err := target.RuntimeStates().GetByOperationID("provisioning-instance-1")
+	require.NoError(t, err)
+	for _, entry := range state.KymaConfig.Configuration {
+		if entry.Key == "password" {
+			assert.Equal(t, "override-password", entry.Value)
+		}
+	}
+}
+
+func TestImport_DecryptionFailure(t *testing.T) {
+	// given
+	snapshot := Snapshot{
+		Version: SnapshotVersion,
+		Instances: []InstanceSnapshot{
+			{Instance: internal.Instance{InstanceID: "instance-1", RuntimeID: "runtime-1"}},
+			{Instance: internal.Instance{
+				InstanceID: "instance-2",
+				RuntimeID:  "runtime-2",
+				// the password was not encrypted with the snapshot key
+				ProvisioningParameters: provisioningParameters,
+			}},
+		},
+	}
+	target := storage.NewMemoryStorage()
+
+	// when
+	err := NewImporter(target, snapshotKey, logrus.New()).Import(snapshot)
+
+	// then
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "check the snapshot key")
+	_, err = target.Instances().GetByID("instance-1")
+	assert.Error(t, err, "the import of the first instance must be rolled back")
+}
+
+func TestExporter_OperationInProgress(t *testing.T) {
+	// given
+	db := storage.NewMemoryStorage()
+	fixInstance(t, db, "instance-1", "runtime-1", "ga-1", time.Now())
+	require.NoError(t, db.Operations().InsertUpgradeKymaOperation(internal.UpgradeKymaOperation{
+		RuntimeOperation: internal.RuntimeOperation{
+			Operation: internal.Operation{ID: "upgrade-1", InstanceID: "instance-1", State: domain.InProgress},
+		},
+	}))
+	exporter := NewExporter(db.Instances(), db.Operations(), db.RuntimeStates(), snapshotKey, logrus.New())
+
+	// when
+	_, err := exporter.Export(dbmodel.InstanceFilter{InstanceIDs: []string{"instance-1"}})
+
+	// then
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "operation upgrade-1 is in progress")
+}
+
+func TestValidate(t *testing.T) {
+	// given
+	snapshot := Snapshot{
+		Version: SnapshotVersion,
+		Instances: []InstanceSnapshot{
+			{
+				Instance: internal.Instance{InstanceID: "instance-1", RuntimeID: "runtime-1"},
+				Operations: []OperationSnapshot{
+					{Operation: internal.Operation{ID: "op-1", InstanceID: "instance-1"}, Type: string(dbmodel.OperationTypeProvision)},
+					{Operation: internal.Operation{ID: "op-2", InstanceID: "instance-2"}, Type: string(dbmodel.OperationTypeUpgradeKyma)},
+				},
+				RuntimeStates: []internal.RuntimeState{
+					{ID: "state-1", RuntimeID: "runtime-1", OperationID: "op-1"},
+					{ID: "state-2", RuntimeID: "runtime-1", OperationID: "op-3"},
+				},
+			},
+		},
+	}
+
+	// when
+	err := Validate(snapshot)
+
+	// then
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "operation op-2 belongs to instance instance-2 instead of instance-1")
+	assert.Contains(t, err.Error(), "runtime state state-2 references operation op-3")
+	assert.NotContains(t, err.Error(), "state-1")
+}
+
+func fixInstance(t *testing.T, db storage.BrokerStorage, instanceID, runtimeID, globalAccountID string, createdAt time.Time) {
+	require.NoError(t, db.Instances().Insert(internal.Instance{
+		InstanceID:             instanceID,
+		RuntimeID:              runtimeID,
+		GlobalAccountID:        globalAccountID,
+		ProvisioningParameters: provisioningParameters,
+		CreatedAt:              createdAt,
+	}))
+	operationID := "provisioning-" + instanceID
+	require.NoError(t, db.Operations().InsertProvisioningOperation(internal.ProvisioningOperation{
+		Operation:              internal.Operation{ID: operationID, InstanceID: instanceID, State: domain.Succeeded, CreatedAt: createdAt},
+		RuntimeID:              runtimeID,
+		ProvisioningParameters: provisioningParameters,
+	}))
+	require.NoError(t, db.RuntimeStates().Insert(internal.RuntimeState{
+		ID:          "state-" + operationID,
+		RuntimeID:   runtimeID,
+		OperationID: operationID,
+	}))
+}
@@ -0,0 +1,156 @@
+package instancemigration
+
+import (
+	"crypto/aes"
+	"encoding/json"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/provisioner/pkg/gqlschema"
+
+	"github.com/pkg/errors"
+)
+
+// SnapshotVersion is the version of the snapshot format, the import rejects the snapshots in other versions
+const SnapshotVersion = 1
+
+// Snapshot holds the instances exported from the KEB database together with their operations and runtime states.
+// The credentials in the provisioning parameters and the values of the secret overrides in the runtime states
+// are encrypted with the snapshot key, all other fields are in plain text.
+type Snapshot struct {
+	Version    int                `json:"version"`
+	ExportedAt time.Time          `json:"exportedAt"`
+	Instances  []InstanceSnapshot `json:"instances"`
+}
+
+// InstanceSnapshot holds a single instance with all its operations and runtime states
+type InstanceSnapshot struct {
+	Instance      internal.Instance       `json:"instance"`
+	Operations    []OperationSnapshot     `json:"operations"`
+	RuntimeStates []internal.RuntimeState `json:"runtimeStates"`
+}
+
+// OperationSnapshot holds the operation in the same form as it is stored in the database: the common fields,
+// the operation type and the type specific data
+type OperationSnapshot struct {
+	internal.Operation
+
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// ValidateKey checks that the snapshot key is a valid AES key, which is 16, 24 or 32 bytes long
+func ValidateKey(key string) error {
+	_, err := aes.NewCipher([]byte(key))
+	return errors.Wrap(err, "invalid snapshot key")
+}
+
+// secretTransformer encrypts or decrypts the value of a sensitive field of the snapshot
+type secretTransformer func(value string) (string, error)
+
+func encryptWith(enc *storage.Encrypter) secretTransformer {
+	return func(value string) (string, error) {
+		encrypted, err := enc.Encrypt([]byte(value))
+		return string(encrypted), err
+	}
+}
+
+func decryptWith(enc *storage.Encrypter) secretTransformer {
+	return func(value string) (string, error) {
+		decrypted, err := enc.Decrypt([]byte(value))
+		return string(decrypted), err
+	}
+}
+
+// transform applies the transformer to the value, the empty values are not transformed
+func (t secretTransformer) transform(value *string) error {
+	if *value == "" {
+		return nil
+	}
+	transformed, err := t(*value)
+	if err != nil {
+		return err
+	}
+	*value = transformed
+	return nil
+}
+
+// transformProvisioningParameters applies the transformer to the Service Manager password, the subscription
+// credentials and the registry passwords in the serialized provisioning parameters, the empty parameters
+// are returned unchanged
+func transformProvisioningParameters(raw string, t secretTransformer) (string, error) {
+	if raw == "" {
+		return raw, nil
+	}
+	var params internal.ProvisioningParameters
+	if err := json.Unmarshal([]byte(raw), &params); err != nil {
+		return "", errors.Wrap(err, "while unmarshalling provisioning parameters")
+	}
+	if params.ErsContext.ServiceManager != nil {
+		if err := t.transform(&params.ErsContext.ServiceManager.Credentials.BasicAuth.Password); err != nil {
+			return "", errors.Wrap(err, "while transforming Service Manager password")
+		}
+	}
+	if params.Parameters.Subscription != nil {
+		for key, value := range params.Parameters.Subscription.Credentials {
+			if err := t.transform(&value); err != nil {
+				return "", errors.Wrapf(err, "while transforming subscription credential %s", key)
+			}
+			params.Parameters.Subscription.Credentials[key] = value
+		}
+	}
+	if params.Parameters.Registry != nil {
+		for i := range params.Parameters.Registry.PullSecrets {
+			if err := t.transform(&params.Parameters.Registry.PullSecrets[i].Password); err != nil {
+				return "", errors.Wrapf(err, "while transforming password of pull secret %s", params.Parameters.Registry.PullSecrets[i].Name)
+			}
+		}
+	}
+	transformed, err := json.Marshal(params)
+	if err != nil {
+		return "", errors.Wrap(err, "while marshalling provisioning parameters")
+	}
+	return string(transformed), nil
+}
+
+// transformRuntimeState applies the transformer to the values of the secret overrides of the runtime state
+func transformRuntimeState(state internal.RuntimeState, t secretTransformer) (internal.RuntimeState, error) {
+	configuration, err := transformSecrets(state.KymaConfig.Configuration, t)
+	if err != nil {
+		return internal.RuntimeState{}, errors.Wrapf(err, "while transforming secret overrides of runtime state %s", state.ID)
+	}
+	state.KymaConfig.Configuration = configuration
+
+	components := make([]*gqlschema.ComponentConfigurationInput, 0, len(state.KymaConfig.Components))
+	for _, component := range state.KymaConfig.Components {
+		if component == nil {
+			continue
+		}
+		transformed := *component
+		transformed.Configuration, err = transformSecrets(component.Configuration, t)
+		if err != nil {
+			return internal.RuntimeState{}, errors.Wrapf(err, "while transforming secret overrides of component %s in runtime state %s", component.Component, state.ID)
+		}
+		components = append(components, &transformed)
+	}
+	state.KymaConfig.Components = components
+	return state, nil
+}
+
+func transformSecrets(entries []*gqlschema.ConfigEntryInput, t secretTransformer) ([]*gqlschema.ConfigEntryInput, error) {
+	transformed := make([]*gqlschema.ConfigEntryInput, 0, len(entries))
+	for _, entry := range entries {
+		if entry == nil {
+			continue
+		}
+		copied := *entry
+		if copied.Secret != nil && *copied.Secret {
+			if err := t.transform(&copied.Value); err != nil {
+				return nil, errors.Wrapf(err, "while transforming override %s", copied.Key)
+			}
+		}
+		transformed = append(transformed, &copied)
+	}
+	return transformed, nil
+}
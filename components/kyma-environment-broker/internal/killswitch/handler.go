@@ -0,0 +1,156 @@
+package killswitch
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/httputil"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// KillSwitchRequest changes the kill switch, the user who changed it is taken from the authenticated request
+type KillSwitchRequest struct {
+	// Disabled rejects (true) or allows (false) the provisioning
+	Disabled bool   `json:"disabled"`
+	Message  string `json:"message"`
+}
+
+type KillSwitchEventDTO struct {
+	Scope     string    `json:"scope"`
+	Name      string    `json:"name"`
+	Disabled  bool      `json:"disabled"`
+	Message   string    `json:"message,omitempty"`
+	User      string    `json:"user"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type KillSwitchesDTO struct {
+	// Switches are the kill switches which currently reject the provisioning
+	Switches []Switch `json:"switches"`
+	// Events are all changes of the kill switches sorted by the time of the change
+	Events []KillSwitchEventDTO `json:"events"`
+}
+
+// Authenticator returns the user of the request which came through the API gateway
+type Authenticator interface {
+	Authenticate(r *http.Request) (string, error)
+}
+
+type Handler struct {
+	service       *Service
+	authenticator Authenticator
+	log           logrus.FieldLogger
+}
+
+func NewHandler(service *Service, authenticator Authenticator, log logrus.FieldLogger) *Handler {
+	return &Handler{
+		service:       service,
+		authenticator: authenticator,
+		log:           log,
+	}
+}
+
+func (h *Handler) AttachRoutes(router *mux.Router) {
+	router.HandleFunc("/kill-switches/{scope}/{name}", h.setSwitch).Methods(http.MethodPut)
+	router.HandleFunc("/info/kill-switches", h.getSwitches).Methods(http.MethodGet)
+}
+
+func (h *Handler) setSwitch(w http.ResponseWriter, r *http.Request) {
+	scope := mux.Vars(r)["scope"]
+	name := mux.Vars(r)["name"]
+
+	// the audited user is taken only from the token signed by the gateway, neither from the request body nor from
+	// the headers which the in-cluster callers skipping the gateway could set, so the audit trail cannot be forged
+	user, err := h.authenticator.Authenticate(r)
+	if err != nil {
+		httputil.WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	var request KillSwitchRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, errors.Wrap(err, "while decoding request body"))
+		return
+	}
+	if err := validate(scope, name, request); err != nil {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var event internal.KillSwitchEvent
+	if request.Disabled {
+		event, err = h.service.Disable(scope, name, request.Message, user)
+	} else {
+		event, err = h.service.Enable(scope, name, user)
+	}
+	if err != nil {
+		h.log.Errorf("while changing the %s %s kill switch: %v", scope, name, err)
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrapf(err, "while changing the %s %s kill switch", scope, name))
+		return
+	}
+
+	httputil.WriteResponse(w, http.StatusOK, toEventDTO(event))
+}
+
+func (h *Handler) getSwitches(w http.ResponseWriter, _ *http.Request) {
+	events, err := h.service.Events()
+	if err != nil {
+		h.log.Errorf("while getting kill switches: %v", err)
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "while getting kill switches"))
+		return
+	}
+
+	dto := KillSwitchesDTO{
+		Switches: activeSwitches(events),
+		Events:   make([]KillSwitchEventDTO, 0, len(events)),
+	}
+	for _, event := range events {
+		dto.Events = append(dto.Events, toEventDTO(event))
+	}
+
+	httputil.WriteResponse(w, http.StatusOK, dto)
+}
+
+// validate checks if the switch of the known plan or of the region is changed,
+// the provisioning can be disabled only with the message for the rejected requests
+func validate(scope, name string, request KillSwitchRequest) error {
+	switch scope {
+	case ScopePlan:
+		if !isPlanName(name) {
+			return errors.Errorf("plan %q is not known", name)
+		}
+	case ScopeRegion:
+	default:
+		return errors.Errorf("scope %q is not supported, use %s or %s", scope, ScopePlan, ScopeRegion)
+	}
+	if request.Disabled && request.Message == "" {
+		return errors.New("message must be set when the provisioning is disabled")
+	}
+	return nil
+}
+
+func isPlanName(name string) bool {
+	for _, plan := range broker.Plans {
+		if plan.PlanDefinition.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func toEventDTO(event internal.KillSwitchEvent) KillSwitchEventDTO {
+	return KillSwitchEventDTO{
+		Scope:     event.Scope,
+		Name:      event.Name,
+		Disabled:  event.Disabled,
+		Message:   event.Message,
+		User:      event.User,
+		CreatedAt: event.CreatedAt,
+	}
+}
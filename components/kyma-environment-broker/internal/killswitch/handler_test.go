@@ -0,0 +1,136 @@
+package killswitch_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/killswitch"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	// given
+	db := storage.NewMemoryStorage()
+
+	router := mux.NewRouter()
+	killswitch.NewHandler(killswitch.NewService(db.KillSwitches(), logrus.New()), bearerAuthenticator{}, logrus.New()).AttachRoutes(router)
+
+	t.Run("should reject switch of unknown plan", func(t *testing.T) {
+		// when
+		rr := serve(t, router, http.MethodPut, "/kill-switches/plan/unknown", "operator", killswitch.KillSwitchRequest{
+			Disabled: true,
+			Message:  "maintenance",
+		})
+
+		// then
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("should reject switch without authenticated user", func(t *testing.T) {
+		// when
+		rr := serve(t, router, http.MethodPut, "/kill-switches/region/westeurope", "", killswitch.KillSwitchRequest{
+			Disabled: true,
+			Message:  "maintenance",
+		})
+
+		// then
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("should reject switch with user header which was not set by the gateway", func(t *testing.T) {
+		// given
+		payload, err := json.Marshal(killswitch.KillSwitchRequest{Disabled: true, Message: "maintenance"})
+		require.NoError(t, err)
+		req, err := http.NewRequest(http.MethodPut, "/kill-switches/region/westeurope", bytes.NewBuffer(payload))
+		require.NoError(t, err)
+		req.Header.Set(httputil.UserHeader, "operator")
+
+		// when
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("should reject disabling without message", func(t *testing.T) {
+		// when
+		rr := serve(t, router, http.MethodPut, "/kill-switches/region/westeurope", "operator", killswitch.KillSwitchRequest{
+			Disabled: true,
+		})
+
+		// then
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("should disable and enable the provisioning", func(t *testing.T) {
+		// when
+		rr := serve(t, router, http.MethodPut, "/kill-switches/region/westeurope", "operator", killswitch.KillSwitchRequest{
+			Disabled: true,
+			Message:  "Azure incident",
+		})
+		require.Equal(t, http.StatusOK, rr.Code)
+		rr = serve(t, router, http.MethodPut, "/kill-switches/plan/azure", "operator", killswitch.KillSwitchRequest{
+			Disabled: true,
+			Message:  "maintenance",
+		})
+		require.Equal(t, http.StatusOK, rr.Code)
+		rr = serve(t, router, http.MethodPut, "/kill-switches/plan/azure", "other-operator", killswitch.KillSwitchRequest{})
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		rr = serve(t, router, http.MethodGet, "/info/kill-switches", "", nil)
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var dto killswitch.KillSwitchesDTO
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &dto))
+		require.Len(t, dto.Switches, 1)
+		assert.Equal(t, "westeurope", dto.Switches[0].Name)
+		assert.Equal(t, "Azure incident", dto.Switches[0].Message)
+		require.Len(t, dto.Events, 3)
+		assert.Equal(t, "other-operator", dto.Events[2].User)
+		assert.False(t, dto.Events[2].Disabled)
+	})
+}
+
+func serve(t *testing.T, router *mux.Router, method, url, user string, body interface{}) *httptest.ResponseRecorder {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		require.NoError(t, err)
+	}
+	req, err := http.NewRequest(method, url, bytes.NewBuffer(payload))
+	require.NoError(t, err)
+	if user != "" {
+		req.Header.Set("Authorization", "Bearer "+user)
+	}
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+// bearerAuthenticator accepts the bearer token as the user, the token signed by the gateway is verified
+// by httputil.GatewayAuthenticator
+type bearerAuthenticator struct{}
+
+func (bearerAuthenticator) Authenticate(r *http.Request) (string, error) {
+	user := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if user == "" {
+		return "", errors.New("token is missing")
+	}
+	return user, nil
+}
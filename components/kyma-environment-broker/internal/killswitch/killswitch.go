@@ -0,0 +1,178 @@
+package killswitch
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/provider"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// ScopePlan switches reject the provisioning of the plan with the given name
+	ScopePlan = "plan"
+	// ScopeRegion switches reject the provisioning in the given platform or provider region
+	ScopeRegion = "region"
+)
+
+// Switch is the kill switch which currently rejects the provisioning of the plan or in the region
+type Switch struct {
+	Scope      string    `json:"scope"`
+	Name       string    `json:"name"`
+	Message    string    `json:"message"`
+	DisabledBy string    `json:"disabledBy"`
+	DisabledAt time.Time `json:"disabledAt"`
+}
+
+// Service changes the kill switches and checks the provisioning requests against them. The state of the switches
+// is derived from their events, which are kept in the storage as the audit trail of the changes.
+type Service struct {
+	storage storage.KillSwitches
+	log     logrus.FieldLogger
+}
+
+func NewService(storage storage.KillSwitches, log logrus.FieldLogger) *Service {
+	return &Service{
+		storage: storage,
+		log:     log,
+	}
+}
+
+// Disable rejects the new provisioning of the plan or in the region with the given message, the deprovisioning
+// and the operations of the existing instances are not affected
+func (s *Service) Disable(scope, name, message, user string) (internal.KillSwitchEvent, error) {
+	return s.record(internal.KillSwitchEvent{
+		Scope:    scope,
+		Name:     name,
+		Disabled: true,
+		Message:  message,
+		User:     user,
+	})
+}
+
+// Enable allows the provisioning of the plan or in the region again
+func (s *Service) Enable(scope, name, user string) (internal.KillSwitchEvent, error) {
+	return s.record(internal.KillSwitchEvent{
+		Scope: scope,
+		Name:  name,
+		User:  user,
+	})
+}
+
+func (s *Service) record(event internal.KillSwitchEvent) (internal.KillSwitchEvent, error) {
+	event.ID = uuid.New().String()
+	event.CreatedAt = time.Now()
+	if err := s.storage.InsertEvent(event); err != nil {
+		return internal.KillSwitchEvent{}, errors.Wrapf(err, "while saving event of the %s %s kill switch", event.Scope, event.Name)
+	}
+
+	if event.Disabled {
+		s.log.Infof("Provisioning disabled for the %s %s by %s: %s", event.Scope, event.Name, event.User, event.Message)
+	} else {
+		s.log.Infof("Provisioning enabled for the %s %s by %s", event.Scope, event.Name, event.User)
+	}
+	return event, nil
+}
+
+// Events returns all changes of the kill switches sorted by the time of the change
+func (s *Service) Events() ([]internal.KillSwitchEvent, error) {
+	events, err := s.storage.ListEvents()
+	if err != nil {
+		return nil, errors.Wrap(err, "while listing kill switch events")
+	}
+	return events, nil
+}
+
+// Switches returns the kill switches which currently reject the provisioning, sorted by the scope and the name.
+// Only the latest event of every switch is read, so the check of every provisioning request does not grow
+// with the audit trail.
+func (s *Service) Switches() ([]Switch, error) {
+	events, err := s.storage.ListLatestEvents()
+	if err != nil {
+		return nil, errors.Wrap(err, "while listing latest kill switch events")
+	}
+	return activeSwitches(events), nil
+}
+
+// ProvisioningDisabled returns the message for the provisioning request with the given parameters if any kill switch
+// rejects it. The switches of the plan name, the platform region, and the provider region apply to the request.
+func (s *Service) ProvisioningDisabled(parameters internal.ProvisioningParameters) (string, bool, error) {
+	switches, err := s.Switches()
+	if err != nil {
+		return "", false, err
+	}
+
+	planName := broker.Plans[parameters.PlanID].PlanDefinition.Name
+	regions := map[string]struct{}{}
+	for _, region := range []string{parameters.PlatformRegion, providerRegion(parameters)} {
+		if region != "" {
+			regions[region] = struct{}{}
+		}
+	}
+
+	for _, sw := range switches {
+		switch sw.Scope {
+		case ScopePlan:
+			if sw.Name == planName {
+				return fmt.Sprintf("provisioning of the %s plan is temporarily disabled: %s", sw.Name, sw.Message), true, nil
+			}
+		case ScopeRegion:
+			if _, found := regions[sw.Name]; found {
+				return fmt.Sprintf("provisioning in the %s region is temporarily disabled: %s", sw.Name, sw.Message), true, nil
+			}
+		}
+	}
+	return "", false, nil
+}
+
+// activeSwitches replays the events in order and returns the switches whose latest event disabled the provisioning
+func activeSwitches(events []internal.KillSwitchEvent) []Switch {
+	latest := map[string]internal.KillSwitchEvent{}
+	for _, event := range events {
+		latest[event.Scope+"/"+event.Name] = event
+	}
+
+	switches := make([]Switch, 0)
+	for _, event := range latest {
+		if !event.Disabled {
+			continue
+		}
+		switches = append(switches, Switch{
+			Scope:      event.Scope,
+			Name:       event.Name,
+			Message:    event.Message,
+			DisabledBy: event.User,
+			DisabledAt: event.CreatedAt,
+		})
+	}
+	sort.Slice(switches, func(i, j int) bool {
+		if switches[i].Scope != switches[j].Scope {
+			return switches[i].Scope < switches[j].Scope
+		}
+		return switches[i].Name < switches[j].Name
+	})
+	return switches
+}
+
+// providerRegion returns the region requested in the provisioning parameters or the default region of the plan.
+// The provider region of the trial plan is resolved from the platform region during the provisioning, so only
+// the switch of the platform region applies to it.
+func providerRegion(parameters internal.ProvisioningParameters) string {
+	if parameters.Parameters.Region != nil && *parameters.Parameters.Region != "" {
+		return *parameters.Parameters.Region
+	}
+	switch parameters.PlanID {
+	case broker.AzurePlanID, broker.AzureLitePlanID:
+		return provider.DefaultAzureRegion
+	case broker.GCPPlanID:
+		return provider.DefaultGCPRegion
+	}
+	return ""
+}
@@ -0,0 +1,103 @@
+package killswitch_test
+
+import (
+	"testing"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/killswitch"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/ptr"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_ProvisioningDisabled(t *testing.T) {
+	// given
+	db := storage.NewMemoryStorage()
+	svc := killswitch.NewService(db.KillSwitches(), logrus.New())
+
+	_, err := svc.Disable(killswitch.ScopePlan, broker.GCPPlanName, "GCP maintenance", "operator")
+	require.NoError(t, err)
+	_, err = svc.Disable(killswitch.ScopeRegion, "westeurope", "Azure incident", "operator")
+	require.NoError(t, err)
+	_, err = svc.Disable(killswitch.ScopeRegion, "cf-us10", "platform maintenance", "operator")
+	require.NoError(t, err)
+	_, err = svc.Enable(killswitch.ScopeRegion, "cf-us10", "other-operator")
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		parameters      internal.ProvisioningParameters
+		expectedMessage string
+	}{
+		"disabled plan": {
+			parameters:      fixParameters(broker.GCPPlanID, "cf-eu10", ptr.String("europe-west3")),
+			expectedMessage: "provisioning of the gcp plan is temporarily disabled: GCP maintenance",
+		},
+		"disabled requested provider region": {
+			parameters:      fixParameters(broker.AzureLitePlanID, "cf-eu10", ptr.String("westeurope")),
+			expectedMessage: "provisioning in the westeurope region is temporarily disabled: Azure incident",
+		},
+		"disabled default provider region": {
+			parameters:      fixParameters(broker.AzurePlanID, "cf-eu10", nil),
+			expectedMessage: "provisioning in the westeurope region is temporarily disabled: Azure incident",
+		},
+		"other provider region": {
+			parameters: fixParameters(broker.AzurePlanID, "cf-eu10", ptr.String("northeurope")),
+		},
+		"enabled platform region": {
+			parameters: fixParameters(broker.TrialPlanID, "cf-us10", nil),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// when
+			msg, disabled, err := svc.ProvisioningDisabled(tc.parameters)
+
+			// then
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedMessage != "", disabled)
+			assert.Equal(t, tc.expectedMessage, msg)
+		})
+	}
+}
+
+func TestService_Switches(t *testing.T) {
+	// given
+	db := storage.NewMemoryStorage()
+	svc := killswitch.NewService(db.KillSwitches(), logrus.New())
+
+	_, err := svc.Disable(killswitch.ScopeRegion, "westeurope", "first incident", "operator")
+	require.NoError(t, err)
+	_, err = svc.Disable(killswitch.ScopeRegion, "westeurope", "second incident", "other-operator")
+	require.NoError(t, err)
+	_, err = svc.Disable(killswitch.ScopePlan, broker.AzurePlanName, "maintenance", "operator")
+	require.NoError(t, err)
+	_, err = svc.Enable(killswitch.ScopePlan, broker.AzurePlanName, "operator")
+	require.NoError(t, err)
+
+	// when
+	switches, err := svc.Switches()
+	require.NoError(t, err)
+	events, err := svc.Events()
+	require.NoError(t, err)
+
+	// then
+	require.Len(t, switches, 1)
+	assert.Equal(t, killswitch.ScopeRegion, switches[0].Scope)
+	assert.Equal(t, "westeurope", switches[0].Name)
+	assert.Equal(t, "second incident", switches[0].Message)
+	assert.Equal(t, "other-operator", switches[0].DisabledBy)
+	assert.Len(t, events, 4)
+}
+
+func fixParameters(planID, platformRegion string, region *string) internal.ProvisioningParameters {
+	return internal.ProvisioningParameters{
+		PlanID:         planID,
+		PlatformRegion: platformRegion,
+		Parameters: internal.ProvisioningParametersDTO{
+			Region: region,
+		},
+	}
+}
@@ -0,0 +1,100 @@
+package kymaversion
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+type ChannelSubscriptionDTO struct {
+	GlobalAccountID string    `json:"globalAccountID"`
+	Channel         string    `json:"channel"`
+	KymaVersion     string    `json:"kymaVersion"`
+	UpdatedAt       time.Time `json:"updatedAt"`
+}
+
+type ChannelSubscriptionRequest struct {
+	Channel string `json:"channel"`
+}
+
+type Handler struct {
+	subscriptions storage.KymaChannels
+	resolver      *Resolver
+	log           logrus.FieldLogger
+}
+
+func NewHandler(subscriptions storage.KymaChannels, resolver *Resolver, log logrus.FieldLogger) *Handler {
+	return &Handler{
+		subscriptions: subscriptions,
+		resolver:      resolver,
+		log:           log,
+	}
+}
+
+func (h *Handler) AttachRoutes(router *mux.Router) {
+	router.HandleFunc("/kyma-channels/{global_account_id}", h.getSubscription).Methods(http.MethodGet)
+	router.HandleFunc("/kyma-channels/{global_account_id}", h.setSubscription).Methods(http.MethodPut)
+}
+
+func (h *Handler) getSubscription(w http.ResponseWriter, r *http.Request) {
+	globalAccountID := mux.Vars(r)["global_account_id"]
+
+	subscription, found, err := h.subscriptions.GetSubscription(globalAccountID)
+	if err != nil {
+		h.log.Errorf("while getting kyma channel subscription for global account %s: %v", globalAccountID, err)
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrapf(err, "while getting kyma channel subscription for global account %s", globalAccountID))
+		return
+	}
+	if !found {
+		httputil.WriteErrorResponse(w, http.StatusNotFound, errors.Errorf("global account %s is not subscribed to any kyma channel", globalAccountID))
+		return
+	}
+
+	httputil.WriteResponse(w, http.StatusOK, h.toDTO(subscription))
+}
+
+func (h *Handler) setSubscription(w http.ResponseWriter, r *http.Request) {
+	globalAccountID := mux.Vars(r)["global_account_id"]
+
+	var request ChannelSubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, errors.Wrap(err, "while decoding request body"))
+		return
+	}
+	if _, enabled := h.resolver.VersionForChannel(request.Channel); !enabled {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, errors.Errorf("kyma channel %q is not enabled", request.Channel))
+		return
+	}
+
+	subscription := internal.KymaChannelSubscription{
+		GlobalAccountID: globalAccountID,
+		Channel:         request.Channel,
+		UpdatedAt:       time.Now(),
+	}
+	if err := h.subscriptions.UpsertSubscription(subscription); err != nil {
+		h.log.Errorf("while storing kyma channel subscription for global account %s: %v", globalAccountID, err)
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrapf(err, "while storing kyma channel subscription for global account %s", globalAccountID))
+		return
+	}
+	h.log.Infof("Global account %s subscribed to %s kyma channel", globalAccountID, request.Channel)
+
+	httputil.WriteResponse(w, http.StatusOK, h.toDTO(subscription))
+}
+
+func (h *Handler) toDTO(subscription internal.KymaChannelSubscription) ChannelSubscriptionDTO {
+	version, _ := h.resolver.VersionForChannel(subscription.Channel)
+	return ChannelSubscriptionDTO{
+		GlobalAccountID: subscription.GlobalAccountID,
+		Channel:         subscription.Channel,
+		KymaVersion:     version,
+		UpdatedAt:       subscription.UpdatedAt,
+	}
+}
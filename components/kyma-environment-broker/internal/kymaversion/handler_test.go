@@ -0,0 +1,79 @@
+package kymaversion_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/kymaversion"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	// given
+	db := storage.NewMemoryStorage()
+	resolver := kymaversion.NewResolver(kymaversion.Config{Fast: "1.17.0"}, db.KymaChannels(), fixedVersions{})
+
+	router := mux.NewRouter()
+	kymaversion.NewHandler(db.KymaChannels(), resolver, logrus.New()).AttachRoutes(router)
+
+	t.Run("should return not found for global account without subscription", func(t *testing.T) {
+		// when
+		rr := serve(t, router, http.MethodGet, "/kyma-channels/ga-id", nil)
+
+		// then
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("should reject channel which is not enabled", func(t *testing.T) {
+		// when
+		rr := serve(t, router, http.MethodPut, "/kyma-channels/ga-id", kymaversion.ChannelSubscriptionRequest{Channel: kymaversion.RegularChannel})
+
+		// then
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("should subscribe global account to the channel", func(t *testing.T) {
+		// when
+		rr := serve(t, router, http.MethodPut, "/kyma-channels/ga-id", kymaversion.ChannelSubscriptionRequest{Channel: kymaversion.FastChannel})
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		rr = serve(t, router, http.MethodGet, "/kyma-channels/ga-id", nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var dto kymaversion.ChannelSubscriptionDTO
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &dto))
+		assert.Equal(t, "ga-id", dto.GlobalAccountID)
+		assert.Equal(t, kymaversion.FastChannel, dto.Channel)
+		assert.Equal(t, "1.17.0", dto.KymaVersion)
+
+		version, found, err := resolver.ForGlobalAccount("ga-id")
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, "1.17.0", version)
+	})
+}
+
+func serve(t *testing.T, router *mux.Router, method, url string, body interface{}) *httptest.ResponseRecorder {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		require.NoError(t, err)
+	}
+	req, err := http.NewRequest(method, url, bytes.NewBuffer(payload))
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
@@ -0,0 +1,72 @@
+package kymaversion
+
+import (
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	FastChannel    = "fast"
+	RegularChannel = "regular"
+)
+
+// Config holds the Kyma versions the release channels point to, the channel without a version is disabled
+type Config struct {
+	Fast    string `envconfig:"optional"`
+	Regular string `envconfig:"optional"`
+}
+
+// VersionConfigurator returns the Kyma version configured for the global account
+type VersionConfigurator interface {
+	ForGlobalAccount(string) (string, bool, error)
+}
+
+// Resolver resolves the Kyma version for the global account based on the release channel which the global account
+// is subscribed to. The channel is resolved to the concrete version at the time of provisioning or upgrade,
+// so the global account follows the channel when its version changes.
+type Resolver struct {
+	channels      map[string]string
+	subscriptions storage.KymaChannels
+	fallback      VersionConfigurator
+}
+
+func NewResolver(cfg Config, subscriptions storage.KymaChannels, fallback VersionConfigurator) *Resolver {
+	channels := make(map[string]string)
+	for name, version := range map[string]string{
+		FastChannel:    cfg.Fast,
+		RegularChannel: cfg.Regular,
+	} {
+		if version != "" {
+			channels[name] = version
+		}
+	}
+
+	return &Resolver{
+		channels:      channels,
+		subscriptions: subscriptions,
+		fallback:      fallback,
+	}
+}
+
+// VersionForChannel returns the Kyma version of the given channel, returns false if the channel is not enabled
+func (r *Resolver) VersionForChannel(channel string) (string, bool) {
+	version, found := r.channels[channel]
+	return version, found
+}
+
+// ForGlobalAccount returns the Kyma version of the channel which the global account is subscribed to.
+// If the global account is not subscribed to any enabled channel, the version configured for the global account is returned.
+func (r *Resolver) ForGlobalAccount(globalAccountID string) (string, bool, error) {
+	subscription, found, err := r.subscriptions.GetSubscription(globalAccountID)
+	if err != nil {
+		return "", false, errors.Wrapf(err, "while getting kyma channel subscription for global account %s", globalAccountID)
+	}
+	if found {
+		if version, enabled := r.VersionForChannel(subscription.Channel); enabled {
+			return version, true, nil
+		}
+	}
+
+	return r.fallback.ForGlobalAccount(globalAccountID)
+}
@@ -0,0 +1,60 @@
+package kymaversion_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/kymaversion"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolver_ForGlobalAccount(t *testing.T) {
+	// given
+	db := storage.NewMemoryStorage()
+	for ga, channel := range map[string]string{
+		"ga-fast":     kymaversion.FastChannel,
+		"ga-regular":  kymaversion.RegularChannel,
+		"ga-disabled": "nightly",
+	} {
+		err := db.KymaChannels().UpsertSubscription(internal.KymaChannelSubscription{GlobalAccountID: ga, Channel: channel, UpdatedAt: time.Now()})
+		require.NoError(t, err)
+	}
+
+	resolver := kymaversion.NewResolver(kymaversion.Config{Fast: "1.17.0", Regular: "1.16.0"}, db.KymaChannels(), fixedVersions{
+		"ga-disabled":   "1.15.0",
+		"ga-configured": "1.15.1",
+	})
+
+	for name, tc := range map[string]struct {
+		globalAccountID string
+		expectedVersion string
+		expectedFound   bool
+	}{
+		"fast channel":                   {globalAccountID: "ga-fast", expectedVersion: "1.17.0", expectedFound: true},
+		"regular channel":                {globalAccountID: "ga-regular", expectedVersion: "1.16.0", expectedFound: true},
+		"disabled channel":               {globalAccountID: "ga-disabled", expectedVersion: "1.15.0", expectedFound: true},
+		"version configured for account": {globalAccountID: "ga-configured", expectedVersion: "1.15.1", expectedFound: true},
+		"default version":                {globalAccountID: "ga-other", expectedVersion: "", expectedFound: false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// when
+			version, found, err := resolver.ForGlobalAccount(tc.globalAccountID)
+
+			// then
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedFound, found)
+			assert.Equal(t, tc.expectedVersion, version)
+		})
+	}
+}
+
+type fixedVersions map[string]string
+
+func (f fixedVersions) ForGlobalAccount(globalAccountID string) (string, bool, error) {
+	version, found := f[globalAccountID]
+	return version, found, nil
+}
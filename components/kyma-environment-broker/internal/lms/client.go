@@ -2,6 +2,7 @@ package lms
 
 import (
 	"bytes"
+	"context"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
@@ -27,6 +28,7 @@ type Client interface {
 	GetCACertificate(tenantID string) (cert string, found bool, err error)
 	GetCertificateByURL(url string) (cert string, found bool, err error)
 	RequestCertificate(tenantID string, subject pkix.Name) (string, []byte, error)
+	DeleteCertificateByURL(url string) error
 }
 
 // ClusterType can be ha or single-node
@@ -43,6 +45,7 @@ type client struct {
 	samlTenant string
 
 	log logrus.FieldLogger
+	ctx context.Context
 }
 
 const (
@@ -80,9 +83,22 @@ func NewClient(cfg Config, log logrus.FieldLogger) Client {
 		token:       cfg.Token,
 		samlTenant:  cfg.SamlTenant,
 		log:         log,
+		ctx:         context.Background(),
 	}
 }
 
+// WithContext returns the client which sends the requests with the given context, so the requests are cancelled
+// when the context is done. Other implementations of the Client, for example the fake client, are returned unchanged.
+func WithContext(c Client, ctx context.Context) Client {
+	cli, ok := c.(*client)
+	if !ok {
+		return c
+	}
+	withContext := *cli
+	withContext.ctx = ctx
+	return &withContext
+}
+
 type createTenantPayload struct {
 	Name            string   `json:"name"`
 	Region          string   `json:"region"`
@@ -139,7 +155,7 @@ func (c *client) CreateTenant(input CreateTenantInput) (o CreateTenantOutput, er
 
 	url := fmt.Sprintf("%s/tenants", c.url)
 	c.log.Debugf("url: %s", url)
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonPayload))
+	req, err := http.NewRequestWithContext(c.ctx, "POST", url, bytes.NewBuffer(jsonPayload))
 	if err != nil {
 		return CreateTenantOutput{}, errors.Wrapf(err, "while creating request Create Tenant")
 	}
@@ -182,7 +198,7 @@ func (c *client) CreateTenant(input CreateTenantInput) (o CreateTenantOutput, er
 }
 
 func (c *client) GetTenantStatus(tenantID string) (status TenantStatus, err error) {
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s/tenants/%s/status", c.url, tenantID), nil)
+	req, err := http.NewRequestWithContext(c.ctx, "GET", fmt.Sprintf("%s/tenants/%s/status", c.url, tenantID), nil)
 	if err != nil {
 		return TenantStatus{}, errors.Wrap(err, "while creating Get Tenant Status request")
 	}
@@ -220,7 +236,7 @@ func (c *client) GetTenantStatus(tenantID string) (status TenantStatus, err erro
 }
 
 func (c *client) GetTenantInfo(tenantID string) (status TenantInfo, err error) {
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s/tenants/%s", c.url, tenantID), nil)
+	req, err := http.NewRequestWithContext(c.ctx, "GET", fmt.Sprintf("%s/tenants/%s", c.url, tenantID), nil)
 	if err != nil {
 		return TenantInfo{}, errors.Wrapf(err, "while creating Get Tenant request")
 	}
@@ -258,7 +274,7 @@ func (c *client) GetTenantInfo(tenantID string) (status TenantInfo, err error) {
 }
 
 func (c *client) GetCertificateByURL(url string) (cert string, found bool, err error) {
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(c.ctx, "GET", url, nil)
 	if err != nil {
 		return "", false, errors.Wrapf(err, "while creating Get Certificate request (%s)", url)
 	}
@@ -301,6 +317,43 @@ func (c *client) GetCertificateByURL(url string) (cert string, found bool, err e
 	return certResponse.Cert, true, nil
 }
 
+// DeleteCertificateByURL revokes the certificate requested for the runtime, the certificate which does not exist
+// is treated as already deleted
+func (c *client) DeleteCertificateByURL(url string) (err error) {
+	req, err := http.NewRequestWithContext(c.ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		return errors.Wrapf(err, "while creating Delete Certificate request (%s)", url)
+	}
+	req.Header.Add("X-LMS-Token", c.token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return kebError.AsTemporaryError(err, "while calling Delete Certificate endpoint (%s)", url)
+	}
+	defer func() {
+		if drainErr := iosafety.DrainReader(resp.Body); drainErr != nil {
+			err = kebError.AsTemporaryError(drainErr, "while trying to drain body reader")
+		}
+
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			err = kebError.AsTemporaryError(closeErr, "while trying to close body reader")
+		}
+	}()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil
+	case resp.StatusCode >= http.StatusInternalServerError:
+		body, _ := ioutil.ReadAll(resp.Body)
+		return kebError.NewTemporaryError("error when calling delete cert endpoint, status code: %d, body: %s", resp.StatusCode, body)
+	case resp.StatusCode >= 400:
+		body, _ := ioutil.ReadAll(resp.Body)
+		return errors.Errorf("error when calling delete cert endpoint, status code: %d, body: %s", resp.StatusCode, body)
+	}
+
+	return nil
+}
+
 func (c *client) getCertificate(tenantID string, certID string) (cert string, found bool, err error) {
 	return c.GetCertificateByURL(fmt.Sprintf("%s/tenants/%s/certs/%s", c.url, tenantID, certID))
 }
@@ -325,7 +378,7 @@ func (c *client) RequestCertificate(tenantID string, subject pkix.Name) (string,
 		return "", privateKey, errors.Wrap(err, "while encoding Create Request payload")
 	}
 
-	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/tenants/%s/certs", c.url, tenantID), bytes.NewBuffer(jsonPayload))
+	req, err := http.NewRequestWithContext(c.ctx, http.MethodPost, fmt.Sprintf("%s/tenants/%s/certs", c.url, tenantID), bytes.NewBuffer(jsonPayload))
 	if err != nil {
 		return "", privateKey, errors.Wrap(err, "while creating request certificate")
 	}
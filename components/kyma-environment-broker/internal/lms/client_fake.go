@@ -94,6 +94,10 @@ func (f *FakeClient) RequestCertificate(tenantID string, subj pkix.Name) (id str
 	return "id-001", []byte(FakePrivateKey), nil
 }
 
+func (f *FakeClient) DeleteCertificateByURL(url string) error {
+	return nil
+}
+
 // assert methods
 func (f *FakeClient) IsCertRequestedForTenant(tenantID string) bool {
 	f.mu.Lock()
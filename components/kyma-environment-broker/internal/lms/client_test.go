@@ -193,3 +193,38 @@ func createClient(url string) Client {
 		Token:       token,
 	}, logrus.StandardLogger())
 }
+
+func TestClient_DeleteCertificateByURL(t *testing.T) {
+	for name, tc := range map[string]struct {
+		status        int
+		expectedError bool
+	}{
+		"deleted":         {status: http.StatusNoContent},
+		"already deleted": {status: http.StatusNotFound},
+		"server error":    {status: http.StatusInternalServerError, expectedError: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// given
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, fmt.Sprintf("/tenants/%s/certs/cert-id", tenantID), r.URL.Path)
+				assert.Equal(t, http.MethodDelete, r.Method)
+				assert.Equal(t, r.Header.Get("X-LMS-Token"), token)
+
+				w.WriteHeader(tc.status)
+			}))
+			defer ts.Close()
+
+			client := createClient(ts.URL)
+
+			// when
+			err := client.DeleteCertificateByURL(fmt.Sprintf("%s/tenants/%s/certs/cert-id", ts.URL, tenantID))
+
+			// then
+			if tc.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
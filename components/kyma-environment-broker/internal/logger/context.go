@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Standard field names used to correlate log entries of a single operation
+const (
+	OperationIDField   = "operationID"
+	InstanceIDField    = "instanceID"
+	StepField          = "step"
+	CorrelationIDField = "correlationID"
+)
+
+// The key type is not exported to prevent collisions with context keys
+// defined in other packages.
+type key int
+
+const loggerKey key = iota + 1
+
+// AddToContext returns a copy of the parent context which carries the given logger.
+func AddToContext(ctx context.Context, log logrus.FieldLogger) context.Context {
+	return context.WithValue(ctx, loggerKey, log)
+}
+
+// FromContext returns the logger associated with the context.
+// If there is no logger in the context, the standard logrus logger is returned.
+func FromContext(ctx context.Context) logrus.FieldLogger {
+	if ctx == nil {
+		return logrus.StandardLogger()
+	}
+	log, ok := ctx.Value(loggerKey).(logrus.FieldLogger)
+	if !ok {
+		return logrus.StandardLogger()
+	}
+	return log
+}
+
+// WithOperation returns a logger with the operation and instance correlation fields set
+func WithOperation(log logrus.FieldLogger, operationID, instanceID string) logrus.FieldLogger {
+	return log.WithFields(logrus.Fields{OperationIDField: operationID, InstanceIDField: instanceID})
+}
+
+// WithCorrelationID returns a logger with the correlation ID field set, the logger is returned unchanged
+// for the operations without the correlation ID, e.g. created by the orchestrations
+func WithCorrelationID(log logrus.FieldLogger, correlationID string) logrus.FieldLogger {
+	if correlationID == "" {
+		return log
+	}
+	return log.WithField(CorrelationIDField, correlationID)
+}
@@ -0,0 +1,90 @@
+package logger
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+const componentField = "component"
+
+// Levels is a registry of per-component loggers. Every component gets its own logrus.Logger
+// sharing the output and formatter of the base logger, so the log level of a single component
+// can be changed at runtime without affecting the others.
+type Levels struct {
+	mu      sync.RWMutex
+	base    *logrus.Logger
+	loggers map[string]*logrus.Logger
+}
+
+// NewLevels creates a registry which derives component loggers from the given base logger
+func NewLevels(base *logrus.Logger) *Levels {
+	return &Levels{
+		base:    base,
+		loggers: make(map[string]*logrus.Logger),
+	}
+}
+
+// Component returns the logger for the given component, the logger is created on first use
+func (l *Levels) Component(name string) logrus.FieldLogger {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	lgr, found := l.loggers[name]
+	if !found {
+		lgr = &logrus.Logger{
+			Out:          l.base.Out,
+			Formatter:    l.base.Formatter,
+			Hooks:        l.base.Hooks,
+			ReportCaller: l.base.ReportCaller,
+			ExitFunc:     l.base.ExitFunc,
+			Level:        l.base.GetLevel(),
+		}
+		l.loggers[name] = lgr
+	}
+
+	return lgr.WithField(componentField, name)
+}
+
+// SetLevel changes the log level of the given component
+func (l *Levels) SetLevel(component string, level logrus.Level) error {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	lgr, found := l.loggers[component]
+	if !found {
+		return fmt.Errorf("component %s is not registered", component)
+	}
+	lgr.SetLevel(level)
+
+	return nil
+}
+
+// Levels returns the current log level of every registered component
+func (l *Levels) Levels() map[string]string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	result := make(map[string]string, len(l.loggers))
+	for name, lgr := range l.loggers {
+		result[name] = lgr.GetLevel().String()
+	}
+
+	return result
+}
+
+// Components returns sorted names of all registered components
+func (l *Levels) Components() []string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	names := make([]string, 0, len(l.loggers))
+	for name := range l.loggers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
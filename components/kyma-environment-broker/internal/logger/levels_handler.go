@@ -0,0 +1,65 @@
+package logger
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/httputil"
+)
+
+// LevelDTO is the payload of the log level API
+type LevelDTO struct {
+	Level string `json:"level"`
+}
+
+// LevelsHandler exposes the log levels of the registered components
+//   GET /log-levels
+//   PUT /log-levels/{component}
+type LevelsHandler struct {
+	levels *Levels
+	log    logrus.FieldLogger
+}
+
+// NewLevelsHandler creates a handler for the log level API
+func NewLevelsHandler(levels *Levels, log logrus.FieldLogger) *LevelsHandler {
+	return &LevelsHandler{
+		levels: levels,
+		log:    log,
+	}
+}
+
+func (h *LevelsHandler) AttachRoutes(router *mux.Router) {
+	router.HandleFunc("/log-levels", h.getLevels).Methods(http.MethodGet)
+	router.HandleFunc("/log-levels/{component}", h.setLevel).Methods(http.MethodPut)
+}
+
+func (h *LevelsHandler) getLevels(w http.ResponseWriter, _ *http.Request) {
+	httputil.WriteResponse(w, http.StatusOK, h.levels.Levels())
+}
+
+func (h *LevelsHandler) setLevel(w http.ResponseWriter, r *http.Request) {
+	component := mux.Vars(r)["component"]
+
+	var dto LevelDTO
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, errors.Wrap(err, "while decoding request body"))
+		return
+	}
+	level, err := logrus.ParseLevel(dto.Level)
+	if err != nil {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, errors.Wrap(err, "while parsing log level"))
+		return
+	}
+
+	if err := h.levels.SetLevel(component, level); err != nil {
+		httputil.WriteErrorResponse(w, http.StatusNotFound, err)
+		return
+	}
+	h.log.Infof("Log level of component %s changed to %s", component, level)
+
+	httputil.WriteResponse(w, http.StatusOK, LevelDTO{Level: level.String()})
+}
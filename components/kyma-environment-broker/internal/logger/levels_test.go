@@ -0,0 +1,113 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/logger"
+)
+
+func TestLevels_SetLevelPerComponent(t *testing.T) {
+	// given
+	buffer := &bytes.Buffer{}
+	base := logrus.New()
+	base.Out = buffer
+	base.SetFormatter(&logrus.JSONFormatter{})
+	levels := logger.NewLevels(base)
+
+	provisioning := levels.Component("provisioning")
+	deprovisioning := levels.Component("deprovisioning")
+
+	// when
+	err := levels.SetLevel("provisioning", logrus.DebugLevel)
+	require.NoError(t, err)
+
+	provisioning.Debug("provisioning debug")
+	deprovisioning.Debug("deprovisioning debug")
+
+	// then
+	assert.Contains(t, buffer.String(), "provisioning debug")
+	assert.Contains(t, buffer.String(), `"component":"provisioning"`)
+	assert.NotContains(t, buffer.String(), "deprovisioning debug")
+	assert.Equal(t, map[string]string{"provisioning": "debug", "deprovisioning": "info"}, levels.Levels())
+	assert.Equal(t, []string{"deprovisioning", "provisioning"}, levels.Components())
+}
+
+func TestLevels_SetLevelForUnknownComponent(t *testing.T) {
+	// given
+	levels := logger.NewLevels(logrus.New())
+
+	// when
+	err := levels.SetLevel("unknown", logrus.DebugLevel)
+
+	// then
+	assert.Error(t, err)
+}
+
+func TestLevelsHandler(t *testing.T) {
+	// given
+	levels := logger.NewLevels(logrus.New())
+	levels.Component("orchestration")
+
+	router := mux.NewRouter()
+	logger.NewLevelsHandler(levels, logger.NewLogDummy()).AttachRoutes(router)
+
+	for tn, tc := range map[string]struct {
+		component    string
+		body         string
+		expectedCode int
+	}{
+		"valid level": {
+			component:    "orchestration",
+			body:         `{"level":"debug"}`,
+			expectedCode: http.StatusOK,
+		},
+		"invalid level": {
+			component:    "orchestration",
+			body:         `{"level":"verbose"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		"unknown component": {
+			component:    "unknown",
+			body:         `{"level":"debug"}`,
+			expectedCode: http.StatusNotFound,
+		},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			// when
+			req := httptest.NewRequest(http.MethodPut, "/log-levels/"+tc.component, strings.NewReader(tc.body))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			// then
+			assert.Equal(t, tc.expectedCode, rr.Code)
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/log-levels", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"orchestration":"debug"}`, rr.Body.String())
+}
+
+func TestContext(t *testing.T) {
+	// given
+	log := logger.NewLogDummy()
+
+	// when
+	ctx := logger.AddToContext(context.Background(), log)
+
+	// then
+	assert.Equal(t, log, logger.FromContext(ctx))
+	assert.Equal(t, logrus.StandardLogger(), logger.FromContext(context.Background()))
+}
@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// CapacityStatsGetter provides the capacity requested by the instances per provider region and machine type:
+// - compass_keb_capacity_instances - number of instances
+// - compass_keb_capacity_max_nodes - number of nodes the clusters can scale up to
+// - compass_keb_capacity_max_vcpus - number of vCPUs the clusters can scale up to
+type CapacityStatsGetter interface {
+	GetCapacityStats() (internal.CapacityStats, error)
+}
+
+type CapacityCollector struct {
+	statsGetter CapacityStatsGetter
+
+	instancesDesc *prometheus.Desc
+	maxNodesDesc  *prometheus.Desc
+	maxVCPUsDesc  *prometheus.Desc
+}
+
+func NewCapacityCollector(statsGetter CapacityStatsGetter) *CapacityCollector {
+	labels := []string{"provider", "region", "machine_type"}
+	return &CapacityCollector{
+		statsGetter: statsGetter,
+
+		instancesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(prometheusNamespace, prometheusSubsystem, "capacity_instances"),
+			"The number of instances by provider, region and machine type",
+			labels,
+			nil),
+		maxNodesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(prometheusNamespace, prometheusSubsystem, "capacity_max_nodes"),
+			"The maximum number of nodes requested by the instances by provider, region and machine type",
+			labels,
+			nil),
+		maxVCPUsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(prometheusNamespace, prometheusSubsystem, "capacity_max_vcpus"),
+			"The maximum number of vCPUs requested by the instances by provider, region and machine type",
+			labels,
+			nil),
+	}
+}
+
+func (c *CapacityCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.instancesDesc
+	ch <- c.maxNodesDesc
+	ch <- c.maxVCPUsDesc
+}
+
+// Collect implements the prometheus.Collector interface.
+func (c *CapacityCollector) Collect(ch chan<- prometheus.Metric) {
+	stats, err := c.statsGetter.GetCapacityStats()
+	if err != nil {
+		logrus.Error(err)
+		return
+	}
+
+	for _, entry := range stats.Entries {
+		collect(ch, c.instancesDesc, entry.Instances, entry.Provider, entry.Region, entry.MachineType)
+		collect(ch, c.maxNodesDesc, entry.MaxNodes, entry.Provider, entry.Region, entry.MachineType)
+		collect(ch, c.maxVCPUsDesc, entry.MaxVCPUs, entry.Provider, entry.Region, entry.MachineType)
+	}
+}
@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ConsistencyViolationsGetter provides the number of the violations found by the latest storage consistency check:
+// - compass_keb_consistency_violations - the number of the not repaired violations per invariant
+type ConsistencyViolationsGetter interface {
+	ViolationCounts() map[string]int
+}
+
+type ConsistencyCollector struct {
+	violationsGetter ConsistencyViolationsGetter
+
+	violationsDesc *prometheus.Desc
+}
+
+func NewConsistencyCollector(violationsGetter ConsistencyViolationsGetter) *ConsistencyCollector {
+	return &ConsistencyCollector{
+		violationsGetter: violationsGetter,
+
+		violationsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(prometheusNamespace, prometheusSubsystem, "consistency_violations"),
+			"The number of the storage consistency violations found by the latest check",
+			[]string{"invariant"},
+			nil),
+	}
+}
+
+func (c *ConsistencyCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.violationsDesc
+}
+
+// Collect implements the prometheus.Collector interface.
+func (c *ConsistencyCollector) Collect(ch chan<- prometheus.Metric) {
+	for invariant, num := range c.violationsGetter.ViolationCounts() {
+		collect(ch, c.violationsDesc, num, invariant)
+	}
+}
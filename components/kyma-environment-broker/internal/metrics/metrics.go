@@ -10,7 +10,8 @@ func RegisterAll(sub event.Subscriber, operationStatsGetter OperationsStatsGette
 	opResultCollector := NewOperationResultCollector()
 	opDurationCollector := NewOperationDurationCollector()
 	stepResultCollector := NewStepResultCollector()
-	prometheus.MustRegister(opResultCollector, opDurationCollector, stepResultCollector)
+	stageDurationCollector := NewStageDurationCollector()
+	prometheus.MustRegister(opResultCollector, opDurationCollector, stepResultCollector, stageDurationCollector)
 	prometheus.MustRegister(NewOperationsCollector(operationStatsGetter))
 	prometheus.MustRegister(NewInstancesCollector(instanceStatsGetter))
 
@@ -20,4 +21,5 @@ func RegisterAll(sub event.Subscriber, operationStatsGetter OperationsStatsGette
 	sub.Subscribe(process.DeprovisioningStepProcessed{}, opDurationCollector.OnDeprovisioningStepProcessed)
 	sub.Subscribe(process.ProvisioningStepProcessed{}, stepResultCollector.OnProvisioningStepProcessed)
 	sub.Subscribe(process.DeprovisioningStepProcessed{}, stepResultCollector.OnDeprovisioningStepProcessed)
+	sub.Subscribe(process.StageFinished{}, stageDurationCollector.OnStageFinished)
 }
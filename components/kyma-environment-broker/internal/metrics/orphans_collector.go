@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// OrphansGetter provides the number of the orphaned shoots found by the latest detection:
+// - compass_keb_orphaned_shoots - the number of the shoots of the runtimes without the instance
+type OrphansGetter interface {
+	OrphansCount() int
+}
+
+type OrphansCollector struct {
+	orphansGetter OrphansGetter
+
+	orphansDesc *prometheus.Desc
+}
+
+func NewOrphansCollector(orphansGetter OrphansGetter) *OrphansCollector {
+	return &OrphansCollector{
+		orphansGetter: orphansGetter,
+
+		orphansDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(prometheusNamespace, prometheusSubsystem, "orphaned_shoots"),
+			"The number of the shoots of the runtimes without the instance found by the latest detection",
+			[]string{},
+			nil),
+	}
+}
+
+func (c *OrphansCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.orphansDesc
+}
+
+// Collect implements the prometheus.Collector interface.
+func (c *OrphansCollector) Collect(ch chan<- prometheus.Metric) {
+	collect(ch, c.orphansDesc, c.orphansGetter.OrphansCount())
+}
@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// StageDurationCollector provides the following metrics:
+// - compass_keb_stage_duration_seconds{"operation_type", "stage", "result"}
+// - compass_keb_stage_slo_total{"operation_type", "stage", "compliant"}
+// - compass_keb_stage_slo_seconds{"operation_type", "stage"}
+// The duration is the time the operation spent in the stage, including the step retries. The result is "done"
+// if the operation entered the next stage or succeeded, otherwise "failed". The stage is compliant if it is done
+// within its SLO, the SLO gauge holds the SLO of the stage, so the alerts can compare the durations with it.
+type StageDurationCollector struct {
+	histogram *prometheus.HistogramVec
+	slo       *prometheus.CounterVec
+	target    *prometheus.GaugeVec
+}
+
+func NewStageDurationCollector() *StageDurationCollector {
+	return &StageDurationCollector{
+		histogram: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: prometheusNamespace,
+			Subsystem: prometheusSubsystem,
+			Name:      "stage_duration_seconds",
+			Help:      "The time the operation spent in the process stage",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 16),
+		}, []string{"operation_type", "stage", "result"}),
+		slo: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: prometheusNamespace,
+			Subsystem: prometheusSubsystem,
+			Name:      "stage_slo_total",
+			Help:      "The number of the finished process stages by the compliance with the stage SLO",
+		}, []string{"operation_type", "stage", "compliant"}),
+		target: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: prometheusNamespace,
+			Subsystem: prometheusSubsystem,
+			Name:      "stage_slo_seconds",
+			Help:      "The expected maximum time of the process stage",
+		}, []string{"operation_type", "stage"}),
+	}
+}
+
+func (c *StageDurationCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.histogram.Describe(ch)
+	c.slo.Describe(ch)
+	c.target.Describe(ch)
+}
+
+func (c *StageDurationCollector) Collect(ch chan<- prometheus.Metric) {
+	c.histogram.Collect(ch)
+	c.slo.Collect(ch)
+	c.target.Collect(ch)
+}
+
+func (c *StageDurationCollector) OnStageFinished(_ context.Context, ev interface{}) error {
+	stage, ok := ev.(process.StageFinished)
+	if !ok {
+		return fmt.Errorf("expected process.StageFinished but got %+v", ev)
+	}
+
+	c.histogram.WithLabelValues(stage.Process, stage.Stage.Name, stage.Result).Observe(stage.Duration.Seconds())
+	c.slo.WithLabelValues(stage.Process, stage.Stage.Name, strconv.FormatBool(stage.Compliant())).Inc()
+	if stage.Stage.SLO > 0 {
+		c.target.WithLabelValues(stage.Process, stage.Stage.Name).Set(stage.Stage.SLO.Seconds())
+	}
+	return nil
+}
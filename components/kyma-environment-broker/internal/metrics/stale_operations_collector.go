@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// StaleOperationsGetter provides the number of the stale operations failed by the detector:
+// - compass_keb_stale_operations_total - the number of the failed stale operations per operation type
+type StaleOperationsGetter interface {
+	FailedCounts() map[string]int
+}
+
+type StaleOperationsCollector struct {
+	staleGetter StaleOperationsGetter
+
+	staleDesc *prometheus.Desc
+}
+
+func NewStaleOperationsCollector(staleGetter StaleOperationsGetter) *StaleOperationsCollector {
+	return &StaleOperationsCollector{
+		staleGetter: staleGetter,
+
+		staleDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(prometheusNamespace, prometheusSubsystem, "stale_operations_total"),
+			"The number of the operations in progress longer than their maximum lifetime which were failed",
+			[]string{"operation_type"},
+			nil),
+	}
+}
+
+func (c *StaleOperationsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.staleDesc
+}
+
+// Collect implements the prometheus.Collector interface.
+func (c *StaleOperationsCollector) Collect(ch chan<- prometheus.Metric) {
+	for opType, num := range c.staleGetter.FailedCounts() {
+		m, err := prometheus.NewConstMetric(c.staleDesc, prometheus.CounterValue, float64(num), opType)
+		if err != nil {
+			logrus.Errorf("unable to register metric %s", err.Error())
+			continue
+		}
+		ch <- m
+	}
+}
@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	stepResultDone   = "done"
+	stepResultRetry  = "retry"
+	stepResultFailed = "failed"
+)
+
+// StepDurationCollector is the step hook which provides the following metrics:
+// - compass_keb_step_duration_seconds{"operation_type", "step_name", "result"}
+// - compass_keb_step_retries_total{"operation_type", "step_name"}
+// - compass_keb_step_conflicts_total{"operation_type", "step_name"}
+// The result is "failed" if the step returned an error, "retry" if the step is repeated later, otherwise "done".
+// The step which exceeded its timeout is counted as the retry. The conflicts are counted for every update
+// of the operation made by the step which hit the optimistic locking conflict of the storage.
+type StepDurationCollector struct {
+	histogram *prometheus.HistogramVec
+	retries   *prometheus.CounterVec
+	conflicts *prometheus.CounterVec
+}
+
+func NewStepDurationCollector() *StepDurationCollector {
+	return &StepDurationCollector{
+		histogram: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: prometheusNamespace,
+			Subsystem: prometheusSubsystem,
+			Name:      "step_duration_seconds",
+			Help:      "The time of a single run of the operation step",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 4, 10),
+		}, []string{"operation_type", "step_name", "result"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: prometheusNamespace,
+			Subsystem: prometheusSubsystem,
+			Name:      "step_retries_total",
+			Help:      "The number of the operation step runs which are repeated later",
+		}, []string{"operation_type", "step_name"}),
+		conflicts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: prometheusNamespace,
+			Subsystem: prometheusSubsystem,
+			Name:      "step_conflicts_total",
+			Help:      "The number of the operation updates made by the step which hit the storage conflict",
+		}, []string{"operation_type", "step_name"}),
+	}
+}
+
+func (c *StepDurationCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.histogram.Describe(ch)
+	c.retries.Describe(ch)
+	c.conflicts.Describe(ch)
+}
+
+func (c *StepDurationCollector) Collect(ch chan<- prometheus.Metric) {
+	c.histogram.Collect(ch)
+	c.retries.Collect(ch)
+	c.conflicts.Collect(ch)
+}
+
+func (c *StepDurationCollector) BeforeStep(ctx context.Context, _ process.StepInfo) context.Context {
+	return process.WithConflictCounter(ctx)
+}
+
+func (c *StepDurationCollector) AfterStep(ctx context.Context, step process.StepInfo) {
+	result := stepResultDone
+	switch {
+	case step.Error != nil:
+		result = stepResultFailed
+	case step.When != 0:
+		result = stepResultRetry
+		c.retries.WithLabelValues(step.Process, step.StepName).Inc()
+	}
+	if conflicts := process.Conflicts(ctx); conflicts > 0 {
+		c.conflicts.WithLabelValues(step.Process, step.StepName).Add(float64(conflicts))
+	}
+	c.histogram.WithLabelValues(step.Process, step.StepName, result).Observe(step.Duration.Seconds())
+}
@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStepDurationCollector(t *testing.T) {
+	t.Run("should count the retries", func(t *testing.T) {
+		// given
+		collector := NewStepDurationCollector()
+		step := process.StepInfo{Process: process.ProvisioningProcess, StepName: "Create_Runtime"}
+
+		// when
+		runStep(collector, step, func(context.Context) process.StepInfo {
+			step.When = time.Minute
+			return step
+		})
+		runStep(collector, step, func(context.Context) process.StepInfo {
+			step.When = time.Minute
+			return step
+		})
+		runStep(collector, step, func(context.Context) process.StepInfo {
+			step.When = 0
+			return step
+		})
+		runStep(collector, step, func(context.Context) process.StepInfo {
+			step.Error = errors.New("failed")
+			return step
+		})
+
+		// then
+		assert.Equal(t, float64(2), counterValue(t, collector.retries, process.ProvisioningProcess, "Create_Runtime"))
+		assert.Equal(t, uint64(2), histogramCount(t, collector.histogram, process.ProvisioningProcess, "Create_Runtime", stepResultRetry))
+		assert.Equal(t, uint64(1), histogramCount(t, collector.histogram, process.ProvisioningProcess, "Create_Runtime", stepResultDone))
+		assert.Equal(t, uint64(1), histogramCount(t, collector.histogram, process.ProvisioningProcess, "Create_Runtime", stepResultFailed))
+	})
+
+	t.Run("should count the conflicts of the operation updates made by the step", func(t *testing.T) {
+		// given
+		collector := NewStepDurationCollector()
+		memory := storage.NewMemoryStorage()
+		operations := memory.Operations()
+		operation := internal.ProvisioningOperation{Operation: internal.Operation{ID: "operation-id", State: domain.InProgress}}
+		require.NoError(t, operations.InsertProvisioningOperation(operation))
+		_, err := operations.UpdateProvisioningOperation(operation)
+		require.NoError(t, err)
+		step := process.StepInfo{Process: process.ProvisioningProcess, StepName: "Resolve_Credentials"}
+
+		// when
+		runStep(collector, step, func(ctx context.Context) process.StepInfo {
+			opManager := process.NewProvisionOperationManager(operations).WithContext(ctx)
+			_, step.When = opManager.UpdateOperation(operation)
+			_, step.When = opManager.UpdateOperation(operation)
+			return step
+		})
+		runStep(collector, step, func(context.Context) process.StepInfo {
+			return step
+		})
+
+		// then
+		assert.Equal(t, float64(2), counterValue(t, collector.conflicts, process.ProvisioningProcess, "Resolve_Credentials"))
+		assert.Equal(t, float64(1), counterValue(t, collector.retries, process.ProvisioningProcess, "Resolve_Credentials"))
+	})
+}
+
+// runStep calls the collector around the step the same way the managers do
+func runStep(collector *StepDurationCollector, step process.StepInfo, run func(ctx context.Context) process.StepInfo) {
+	ctx := collector.BeforeStep(context.Background(), step)
+	collector.AfterStep(ctx, run(ctx))
+}
+
+func counterValue(t *testing.T, counter *prometheus.CounterVec, labels ...string) float64 {
+	metric := &dto.Metric{}
+	require.NoError(t, counter.WithLabelValues(labels...).Write(metric))
+	return metric.GetCounter().GetValue()
+}
+
+func histogramCount(t *testing.T, histogram *prometheus.HistogramVec, labels ...string) uint64 {
+	metric := &dto.Metric{}
+	require.NoError(t, histogram.WithLabelValues(labels...).(prometheus.Metric).Write(metric))
+	return metric.GetHistogram().GetSampleCount()
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/httputil"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// correlationIDPattern limits the correlation ID sent by the caller, so it can be stored with the operations
+// and sent further in the headers
+var correlationIDPattern = regexp.MustCompile(`^[a-zA-Z0-9._:-]{1,64}$`)
+
+// AddCorrelationIDToContext stores the correlation ID of the request in the context and returns it in the response header.
+// The correlation ID sent by the caller is used, a new one is generated if the caller did not send a valid one.
+func AddCorrelationIDToContext() mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			correlationID := req.Header.Get(httputil.CorrelationIDHeader)
+			if !correlationIDPattern.MatchString(correlationID) {
+				correlationID = uuid.New().String()
+			}
+
+			w.Header().Set(httputil.CorrelationIDHeader, correlationID)
+			newCtx := httputil.ContextWithCorrelationID(req.Context(), correlationID)
+			next.ServeHTTP(w, req.WithContext(newCtx))
+		})
+	}
+}
+
+// CorrelationIDFromContext returns the correlation ID of the request associated with the context if possible.
+func CorrelationIDFromContext(ctx context.Context) (string, bool) {
+	return httputil.CorrelationIDFromContext(ctx)
+}
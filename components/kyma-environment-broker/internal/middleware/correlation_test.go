@@ -0,0 +1,82 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/middleware"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCorrelationIDFromRequest(t *testing.T) {
+	// given
+	const fixCorrelationID = "correlation-id-A"
+
+	req, err := http.NewRequest(http.MethodGet, "http://url.dev/endpoint", nil)
+	require.NoError(t, err)
+	req.Header.Set(httputil.CorrelationIDHeader, fixCorrelationID)
+
+	var gotCtx context.Context
+	spyHandler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		gotCtx = req.Context()
+	})
+
+	router := mux.NewRouter()
+	router.Use(middleware.AddCorrelationIDToContext())
+	router.Path("/endpoint").Handler(spyHandler)
+	rec := httptest.NewRecorder()
+
+	// when
+	router.ServeHTTP(rec, req)
+	gotCorrelationID, found := middleware.CorrelationIDFromContext(gotCtx)
+
+	// then
+	assert.True(t, found)
+	assert.Equal(t, fixCorrelationID, gotCorrelationID)
+	assert.Equal(t, fixCorrelationID, rec.Header().Get(httputil.CorrelationIDHeader))
+}
+
+func TestCorrelationIDGenerated(t *testing.T) {
+	for name, header := range map[string]string{
+		"missing":   "",
+		"too long":  strings.Repeat("a", 65),
+		"forbidden": "correlation id\nwith new line",
+	} {
+		t.Run(name, func(t *testing.T) {
+			// given
+			req, err := http.NewRequest(http.MethodGet, "http://url.dev/endpoint", nil)
+			require.NoError(t, err)
+			if header != "" {
+				req.Header.Set(httputil.CorrelationIDHeader, header)
+			}
+
+			var gotCtx context.Context
+			spyHandler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				gotCtx = req.Context()
+			})
+
+			router := mux.NewRouter()
+			router.Use(middleware.AddCorrelationIDToContext())
+			router.Path("/endpoint").Handler(spyHandler)
+			rec := httptest.NewRecorder()
+
+			// when
+			router.ServeHTTP(rec, req)
+			gotCorrelationID, found := middleware.CorrelationIDFromContext(gotCtx)
+
+			// then
+			assert.True(t, found)
+			_, err = uuid.Parse(gotCorrelationID)
+			assert.NoError(t, err, "the generated correlation ID is UUID")
+			assert.Equal(t, gotCorrelationID, rec.Header().Get(httputil.CorrelationIDHeader))
+		})
+	}
+}
@@ -23,6 +23,7 @@ type ProvisionerInputCreator interface {
 	CreateProvisionRuntimeInput() (gqlschema.ProvisionRuntimeInput, error)
 	CreateUpgradeRuntimeInput() (gqlschema.UpgradeRuntimeInput, error)
 	EnableOptionalComponent(componentName string) ProvisionerInputCreator
+	DisableOptionalComponent(componentName string) ProvisionerInputCreator
 }
 
 type LMSTenant struct {
@@ -36,6 +37,8 @@ type LMS struct {
 	TenantID    string    `json:"tenant_id"`
 	Failed      bool      `json:"failed"`
 	RequestedAt time.Time `json:"requested_at"`
+	// CertificateURL points to the certificate requested for the runtime, the certificate is revoked by the deprovisioning
+	CertificateURL string `json:"certificate_url,omitempty"`
 }
 
 type AvsLifecycleData struct {
@@ -64,11 +67,32 @@ type Instance struct {
 	ProvisioningParameters string
 	ProviderRegion         string
 
+	// APIServerURL and CABundle allow to access the shoot cluster, both are set when the provisioning succeeded
+	APIServerURL string
+	CABundle     string
+
+	// ShootName is the name of the Gardener shoot cluster of the runtime, set when the provisioning succeeded
+	ShootName string
+
+	// OriginalGlobalAccountID is the global account the instance was provisioned in, set only when the instance
+	// was migrated to another global account
+	OriginalGlobalAccountID string
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	DeletedAt time.Time
 }
 
+// Tenant returns the tenant of the runtime in the Provisioner, the Director, and the hyperscaler account pool.
+// The runtime cannot be moved to another tenant, so it is the global account the instance was provisioned in,
+// also when the instance was migrated to another global account later.
+func (instance Instance) Tenant() string {
+	if instance.OriginalGlobalAccountID != "" {
+		return instance.OriginalGlobalAccountID
+	}
+	return instance.GlobalAccountID
+}
+
 func (instance Instance) GetProvisioningParameters() (ProvisioningParameters, error) {
 	var pp ProvisioningParameters
 
@@ -80,6 +104,16 @@ func (instance Instance) GetProvisioningParameters() (ProvisioningParameters, er
 	return pp, nil
 }
 
+func (instance *Instance) SetProvisioningParameters(parameters ProvisioningParameters) error {
+	params, err := json.Marshal(parameters)
+	if err != nil {
+		return errors.Wrap(err, "while marshaling provisioning parameters")
+	}
+
+	instance.ProvisioningParameters = string(params)
+	return nil
+}
+
 type Operation struct {
 	ID        string
 	Version   int
@@ -93,6 +127,60 @@ type Operation struct {
 
 	// OrchestrationID specifies the origin orchestration which triggers the operation, empty for OSB operations (provisioning/deprovisioning)
 	OrchestrationID string
+	// CorrelationID links the operation with the OSB request which created it and with the calls to the Provisioner and the Director
+	CorrelationID string
+
+	// Stage is the name of the process stage the operation is in, StageStartedAt is the time the operation entered it,
+	// both are empty if no stages are defined for the process
+	Stage          string
+	StageStartedAt time.Time
+	// RetriedAt is the time the failed operation was retried by the operations batch job, zero if it was not retried
+	RetriedAt time.Time
+}
+
+// TimeoutStart returns the time from which the timeout of the operation is measured, the timeout of the retried
+// operation is measured from the retry, otherwise it would be exceeded right after the retry
+func (o Operation) TimeoutStart() time.Time {
+	if o.RetriedAt.After(o.CreatedAt) {
+		return o.RetriedAt
+	}
+	return o.CreatedAt
+}
+
+// ArchivedInstance holds the instance removed after the successful deprovisioning together with its operations,
+// so the removed environments can still be queried, e.g. by billing and audit
+type ArchivedInstance struct {
+	Instance
+
+	Operations []ArchivedOperation
+	ArchivedAt time.Time
+}
+
+// ArchivedOperation holds the operation of the archived instance together with its type
+type ArchivedOperation struct {
+	Operation
+
+	Type string
+}
+
+// FreeTierUsageEntry records the lifetime of a single instance of the free plan,
+// the FinishedAt time is zero as long as the instance exists
+type FreeTierUsageEntry struct {
+	InstanceID      string
+	GlobalAccountID string
+	PlanID          string
+	StartedAt       time.Time
+	FinishedAt      time.Time
+}
+
+// TrialExpiration records when the trial instance expires, the NotifiedAt and DeprovisionedAt times are zero
+// until the owner of the instance is notified and the deprovisioning of the expired instance is triggered
+type TrialExpiration struct {
+	InstanceID      string
+	ExpiresAt       time.Time
+	NotifiedAt      time.Time
+	DeprovisionedAt time.Time
+	UpdatedAt       time.Time
 }
 
 type InstanceWithOperation struct {
@@ -103,6 +191,15 @@ type InstanceWithOperation struct {
 	Description sql.NullString
 }
 
+// InstanceWithState holds the instance together with its latest operation
+type InstanceWithState struct {
+	Instance
+
+	// LastOperation is nil when no operation was created for the instance
+	LastOperation     *Operation
+	LastOperationType string
+}
+
 // ProvisioningOperation holds all information about provisioning operation
 type ProvisioningOperation struct {
 	Operation `json:"-"`
@@ -117,8 +214,29 @@ type ProvisioningOperation struct {
 	Avs AvsLifecycleData `json:"avs"`
 
 	RuntimeID string `json:"runtime_id"`
+	// RuntimeResolution records how the runtime was obtained from the Provisioner
+	RuntimeResolution RuntimeResolution `json:"runtime_resolution"`
+	// ShootDigest summarizes the status of the Gardener shoot when the provisioning failed
+	ShootDigest string `json:"shoot_digest,omitempty"`
+	// TraceContext holds the trace of the provisioning request, the processing of the operation is recorded in this trace
+	TraceContext map[string]string `json:"trace_context,omitempty"`
+	// KeepRegistrations is set for the provisioning of the runtime of the target plan of the plan migration,
+	// the EDP registration and the AVS evaluations of the instance are kept from the runtime of the source plan
+	KeepRegistrations bool `json:"keep_registrations,omitempty"`
 }
 
+// RuntimeResolution describes the outcome of the runtime creation request sent to the Provisioner
+type RuntimeResolution string
+
+const (
+	// RuntimeSubmitted means the runtime creation was requested but its outcome is not known yet
+	RuntimeSubmitted RuntimeResolution = "submitted"
+	// RuntimeCreated means the Provisioner accepted the runtime creation request
+	RuntimeCreated RuntimeResolution = "created"
+	// RuntimeAdopted means the runtime created by a previous, seemingly failed request was found and reused
+	RuntimeAdopted RuntimeResolution = "adopted"
+)
+
 // DeprovisioningOperation holds all information about de-provisioning operation
 type DeprovisioningOperation struct {
 	Operation `json:"-"`
@@ -128,6 +246,12 @@ type DeprovisioningOperation struct {
 	EventHub               EventHub         `json:"eh"`
 	SubAccountID           string           `json:"-"`
 	RuntimeID              string           `json:"runtime_id"`
+
+	// FinishedSteps holds the names of the steps run by the ParallelStep which finished, they are not run again
+	FinishedSteps []string `json:"finished_steps,omitempty"`
+
+	// TraceContext holds the trace of the deprovisioning request, the processing of the operation is recorded in this trace
+	TraceContext map[string]string `json:"trace_context,omitempty"`
 }
 
 // RuntimeOperation holds information about operation performed on a runtime
@@ -141,6 +265,16 @@ type RuntimeOperation struct {
 	RuntimeID              string    `json:"runtimeId"`
 	GlobalAccountID        string    `json:"globalAccountId"`
 	SubAccountID           string    `json:"subAccountId"`
+
+	// RetryCount and LastRetryAt describe the retries of the operation for the given runtime, both the retries
+	// of the failed operation requested for the orchestration and the retries of its steps
+	RetryCount  int       `json:"retryCount"`
+	LastRetryAt time.Time `json:"lastRetryAt"`
+	// RetriedSince is the time of the last update of the operation before the current retries of its step,
+	// the retries of the step are limited by the time since then
+	RetriedSince time.Time `json:"retriedSince"`
+	// ResultReason is a short reason of the operation result, which allows to group the results of the orchestration
+	ResultReason string `json:"resultReason"`
 }
 
 // UpgradeKymaOperation holds all information about upgrade Kyma operation
@@ -150,6 +284,161 @@ type UpgradeKymaOperation struct {
 
 	PlanID                 string `json:"plan_id"`
 	ProvisioningParameters string `json:"provisioning_parameters"`
+	// AllowDowngrade is taken from the orchestration parameters
+	AllowDowngrade bool `json:"allowDowngrade,omitempty"`
+	// InstallationTimeout is taken from the orchestration parameters, 0 means the default installation timeout of the Provisioner
+	InstallationTimeout time.Duration `json:"installationTimeout,omitempty"`
+	// Verification holds the result of the post-upgrade verification, it is empty if the verification is disabled
+	Verification *UpgradeVerification `json:"verification,omitempty"`
+}
+
+const (
+	VerificationPending = "pending"
+	VerificationPassed  = "passed"
+	VerificationFailed  = "failed"
+)
+
+// UpgradeVerification is the result of the checks executed on the runtime after the Kyma upgrade succeeded,
+// the runtime is counted as upgraded only once all checks passed
+type UpgradeVerification struct {
+	State      string              `json:"state"`
+	StartedAt  time.Time           `json:"startedAt"`
+	FinishedAt time.Time           `json:"finishedAt"`
+	Checks     []VerificationCheck `json:"checks"`
+}
+
+// VerificationCheck is the result of a single post-upgrade check, the message describes the last attempt
+type VerificationCheck struct {
+	Name      string    `json:"name"`
+	State     string    `json:"state"`
+	Message   string    `json:"message,omitempty"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// UpgradeClusterOperation holds all information about upgrade cluster (shoot) operation
+type UpgradeClusterOperation struct {
+	RuntimeOperation `json:"runtime_operation"`
+	InputCreator     ProvisionerInputCreator `json:"-"`
+
+	PlanID                 string `json:"plan_id"`
+	ProvisioningParameters string `json:"provisioning_parameters"`
+}
+
+// PlanMigrationOperation holds all information about the migration of the instance to another service plan,
+// the runtime of the source plan is removed and a new runtime of the target plan is provisioned for the same instance
+type PlanMigrationOperation struct {
+	Operation `json:"-"`
+
+	SourcePlanID    string `json:"source_plan_id"`
+	SourceRuntimeID string `json:"source_runtime_id"`
+	// the runtime data of the source plan, the instance is switched back to it when the provisioning of the target plan fails
+	SourceDashboardURL string `json:"source_dashboard_url"`
+	SourceAPIServerURL string `json:"source_api_server_url"`
+	SourceCABundle     string `json:"source_ca_bundle"`
+	SourceShootName    string `json:"source_shoot_name"`
+	// SourceProvisioningParameters are the parameters of the instance in the source plan, the instance is restored
+	// with them when the provisioning of the target plan fails
+	SourceProvisioningParameters string `json:"source_provisioning_parameters"`
+	// SourceProvisioningOperationID identifies the operation which provisioned the runtime of the source plan
+	SourceProvisioningOperationID string `json:"source_provisioning_operation_id"`
+	// ProvisioningParameters are the parameters of the instance in the target plan
+	ProvisioningParameters string `json:"provisioning_parameters"`
+
+	// SourceRuntimeRemoved is set when the runtime of the source plan was deprovisioned
+	SourceRuntimeRemoved bool `json:"source_runtime_removed"`
+	// ProvisioningOperationID identifies the operation which provisions the runtime of the target plan
+	ProvisioningOperationID string `json:"provisioning_operation_id"`
+	// TargetProvisionerOperationID identifies the Provisioner operation which removes the runtime of the target plan
+	// after its provisioning failed
+	TargetProvisionerOperationID string `json:"target_provisioner_operation_id"`
+	// TargetRuntimeRemoved is set when the runtime of the target plan was removed after its provisioning failed
+	TargetRuntimeRemoved bool `json:"target_runtime_removed"`
+	// RestoringOperationID identifies the provisioning operation which records the runtime of the source plan
+	// as the latest provisioning of the instance after the provisioning of the target plan failed
+	RestoringOperationID string `json:"restoring_operation_id"`
+}
+
+// UpdatingOperation holds all information about the update of the parameters of the instance, the changed
+// parameters are applied to the cluster (shoot) of the runtime by the Provisioner
+type UpdatingOperation struct {
+	Operation `json:"-"`
+
+	RuntimeID string `json:"runtime_id"`
+	ShootName string `json:"shoot_name"`
+	// UpdatingParameters are the parameters sent in the update request, only the parameters which are set are changed
+	UpdatingParameters UpdatingParametersDTO `json:"updating_parameters"`
+	// OIDCConfigApplied is set when the OIDC configuration from the updating parameters was set in the shoot
+	OIDCConfigApplied bool `json:"oidc_config_applied"`
+}
+
+// Actions of the SuspensionOperation
+const (
+	SuspensionActionSuspend = "suspend"
+	SuspensionActionResume  = "resume"
+)
+
+// SuspensionOperation holds all information about the suspension of the trial runtime, which hibernates
+// the cluster (shoot) of the runtime in Gardener, or about the resumption which wakes the cluster up
+type SuspensionOperation struct {
+	Operation `json:"-"`
+
+	RuntimeID string `json:"runtime_id"`
+	ShootName string `json:"shoot_name"`
+	// Action is either SuspensionActionSuspend or SuspensionActionResume
+	Action string `json:"action"`
+	// HibernationRequested is set when the desired hibernation state was applied to the shoot
+	HibernationRequested bool `json:"hibernation_requested"`
+}
+
+// Suspends tells whether the operation hibernates the cluster of the runtime
+func (o *SuspensionOperation) Suspends() bool {
+	return o.Action == SuspensionActionSuspend
+}
+
+// AccountMigrationOperation holds all information about the migration of the instance to another global account
+// and subaccount. The changes applied to the runtime are stored with the operation, so they are reverted when
+// the migration fails, also after the restart of the broker.
+type AccountMigrationOperation struct {
+	Operation `json:"-"`
+
+	RuntimeID string `json:"runtime_id"`
+	ShootName string `json:"shoot_name"`
+	// Tenant is the global account the runtime is registered in by the Provisioner and the Director
+	Tenant string `json:"tenant"`
+
+	SourceGlobalAccountID string `json:"source_global_account_id"`
+	SourceSubAccountID    string `json:"source_sub_account_id"`
+	TargetGlobalAccountID string `json:"target_global_account_id"`
+	TargetSubAccountID    string `json:"target_sub_account_id"`
+
+	// AppliedChanges are the names of the changes applied in order, they are reverted in the reverse order
+	AppliedChanges []string `json:"applied_changes"`
+	// RevertReason is set when the migration failed and the applied changes are being reverted
+	RevertReason string `json:"revert_reason"`
+}
+
+// Reverting tells whether the migration failed and the applied changes are being reverted
+func (o *AccountMigrationOperation) Reverting() bool {
+	return o.RevertReason != ""
+}
+
+// KymaChannelSubscription holds the Kyma release channel which the global account is subscribed to
+type KymaChannelSubscription struct {
+	GlobalAccountID string
+	Channel         string
+	UpdatedAt       time.Time
+}
+
+// InstallerOverrides holds the Kyma installation overrides applied to all runtimes of the global account
+// during provisioning and upgrades
+type InstallerOverrides struct {
+	GlobalAccountID string
+	// Components enables (true) or disables (false) the optional components regardless of the provisioning parameters
+	Components map[string]bool
+	// ComponentOverrides holds the overrides (e.g. resource settings) per component name
+	ComponentOverrides map[string]map[string]string
+	GlobalOverrides    map[string]string
+	UpdatedAt          time.Time
 }
 
 // Orchestration holds all information about an orchestration.
@@ -162,16 +451,39 @@ type Orchestration struct {
 	CreatedAt       time.Time
 	UpdatedAt       time.Time
 	Parameters      OrchestrationParameters
+	// Type tells which operations are performed on the runtimes, the orchestration without the type upgrades Kyma
+	Type OrchestrationType
+	// Runtimes holds the runtimes resolved from the targets when the orchestration is started
+	Runtimes []Runtime
 }
 
 func (o *Orchestration) IsFinished() bool {
-	return o.State == Succeeded || o.State == Failed
+	return o.State == Succeeded || o.State == Failed || o.State == Canceled
+}
+
+// IsUpgradeCluster tells whether the orchestration upgrades the clusters instead of Kyma
+func (o *Orchestration) IsUpgradeCluster() bool {
+	return o.Type == UpgradeClusterOrchestration
 }
 
+// OrchestrationType is the type of the operations performed by the orchestration
+type OrchestrationType string
+
+const (
+	UpgradeKymaOrchestration    OrchestrationType = "upgradeKyma"
+	UpgradeClusterOrchestration OrchestrationType = "upgradeCluster"
+)
+
 type OrchestrationParameters struct {
 	Targets  TargetSpec   `json:"targets"`
 	Strategy StrategySpec `json:"strategy,omitempty"`
 	DryRun   bool         `json:"dryRun,omitempty"`
+	// SkipUpgradedWithin excludes the runtimes successfully upgraded within the given period, e.g. "72h"
+	SkipUpgradedWithin string `json:"skipUpgradedWithin,omitempty"`
+	// AllowDowngrade allows to upgrade the runtimes to a Kyma version lower than the installed one
+	AllowDowngrade bool `json:"allowDowngrade,omitempty"`
+	// InstallationTimeout overrides the default installation timeout of the Provisioner for the upgrades, e.g. "3h"
+	InstallationTimeout string `json:"installationTimeout,omitempty"`
 }
 
 const (
@@ -179,8 +491,20 @@ const (
 	InProgress = "in progress"
 	Succeeded  = "succeeded"
 	Failed     = "failed"
+	Canceling  = "canceling"
+	Canceled   = "canceled"
 )
 
+// OperationCanceled is the terminal state of the orchestration's runtime operation canceled together with the orchestration
+const OperationCanceled domain.LastOperationState = "canceled"
+
+// OperationSkipped is the terminal state of the orchestration's runtime operation rejected before the upgrade was started
+const OperationSkipped domain.LastOperationState = "skipped"
+
+// OperationPending is reported in the orchestration stats for the runtime operation in progress which was not sent
+// to the provisioner yet, e.g. waiting for a free worker or the maintenance window. It is never stored as the operation state.
+const OperationPending domain.LastOperationState = "pending"
+
 // Runtime is the data type which captures the needed SKR specific attributes to perform reconciliations on a given runtime.
 type Runtime struct {
 	InstanceID      string `json:"instanceId"`
@@ -243,6 +567,8 @@ type RuntimeTarget struct {
 	SubAccount string `json:"subAccount,omitempty"`
 	// Regex pattern to match against the shoot cluster's Region field (not SCP platform-region). E.g. "europe|eu-"
 	Region string `json:"region,omitempty"`
+	// Regex pattern to match against the shoot cluster's name. E.g. "c-178e034"
+	Shoot string `json:"shoot,omitempty"`
 	// RuntimeID is used to indicate a specific runtime
 	RuntimeID string `json:"runtimeID,omitempty"`
 	// PlanName is used to match runtimes with the same plan
@@ -253,6 +579,7 @@ type StrategyType string
 
 const (
 	ParallelStrategy StrategyType = "parallel"
+	CanaryStrategy   StrategyType = "canary"
 )
 
 type ScheduleType string
@@ -267,11 +594,25 @@ type ParallelStrategySpec struct {
 	Workers int `json:"workers"`
 }
 
+// CanaryStrategySpec defines parameters for the canary orchestration strategy. The canary batch is executed first
+// using the parallel strategy parameters, the rest of the operations is executed once the canary batch succeeded.
+type CanaryStrategySpec struct {
+	// Percentage of the targeted runtimes upgraded in the canary batch, used when Count is not set
+	Percentage int `json:"percentage,omitempty"`
+	// Count is the fixed number of runtimes upgraded in the canary batch
+	Count int `json:"count,omitempty"`
+	// SoakTime is the time to wait after the canary batch finished before continuing, e.g. "30m"
+	SoakTime string `json:"soakTime,omitempty"`
+	// FailureThreshold is the number of failed canary operations tolerated, exceeding it fails the orchestration
+	FailureThreshold int `json:"failureThreshold,omitempty"`
+}
+
 // StrategySpec is the strategy part common for all orchestration trigger/status API
 type StrategySpec struct {
 	Type     StrategyType         `json:"type"`
 	Schedule ScheduleType         `json:"schedule,omitempty"`
 	Parallel ParallelStrategySpec `json:"parallel,omitempty"`
+	Canary   CanaryStrategySpec   `json:"canary,omitempty"`
 }
 
 // TargetSpec is the targets part common for all orchestration trigger/status API
@@ -280,10 +621,184 @@ type TargetSpec struct {
 	Exclude []RuntimeTarget `json:"exclude,omitempty"`
 }
 
+// OperationEvent records a single state transition of the operation
+type OperationEvent struct {
+	ID          string
+	OperationID string
+	InstanceID  string
+	// Actor is the component which changed the state of the operation
+	Actor    string
+	StepName string
+	OldState domain.LastOperationState
+	NewState domain.LastOperationState
+	// Description is the description of the operation after the transition
+	Description string
+	CreatedAt   time.Time
+}
+
+// KillSwitchEvent records a single change of the kill switch which rejects the provisioning of the plan or in the region,
+// the latest event of the switch determines its state
+type KillSwitchEvent struct {
+	ID string
+	// Scope is either the plan or the region, Name is the plan name or the region name respectively
+	Scope string
+	Name  string
+	// Disabled is true if the provisioning was disabled and false if it was enabled again
+	Disabled bool
+	// Message is returned to the provisioning requests rejected by the switch
+	Message string
+	// User is the operator who changed the switch
+	User      string
+	CreatedAt time.Time
+}
+
+// JobRun records a single execution of a periodic job of the broker
+type JobRun struct {
+	ID      string
+	JobName string
+	// Owner identifies the broker replica which executed the job
+	Owner string
+	// Trigger is either "schedule" or "manual"
+	Trigger string
+	// State is one of InProgress, Succeeded or Failed
+	State string
+	// Error describes the failure of the job
+	Error string
+	// Details holds the job specific progress, e.g. the counters of the operations batch job
+	Details    string
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
+
 // OperationStats provide number of operations per type and state
 type OperationStats struct {
 	Provisioning   map[domain.LastOperationState]int
 	Deprovisioning map[domain.LastOperationState]int
+
+	// PerPlan and PerRegion break down the same numbers by the service plan ID and by the provider region
+	// from the provisioning parameters, the region is empty if the default region of the provider is used
+	PerPlan   map[string]OperationStateStats
+	PerRegion map[string]OperationStateStats
+}
+
+// OperationStateStats provide number of provisioning and deprovisioning operations per state
+type OperationStateStats struct {
+	Provisioning   map[domain.LastOperationState]int
+	Deprovisioning map[domain.LastOperationState]int
+}
+
+// NewOperationStats creates empty stats with zero provisioning and deprovisioning operations in every state
+func NewOperationStats() OperationStats {
+	stats := newOperationStateStats()
+	return OperationStats{
+		Provisioning:   stats.Provisioning,
+		Deprovisioning: stats.Deprovisioning,
+		PerPlan:        make(map[string]OperationStateStats),
+		PerRegion:      make(map[string]OperationStateStats),
+	}
+}
+
+func newOperationStateStats() OperationStateStats {
+	return OperationStateStats{
+		Provisioning:   map[domain.LastOperationState]int{domain.InProgress: 0, domain.Succeeded: 0, domain.Failed: 0},
+		Deprovisioning: map[domain.LastOperationState]int{domain.InProgress: 0, domain.Succeeded: 0, domain.Failed: 0},
+	}
+}
+
+// AddProvisioning adds the number of provisioning operations in the given state for the plan and the region
+func (s OperationStats) AddProvisioning(planID, region string, state domain.LastOperationState, total int) {
+	s.Provisioning[state] += total
+	stateStatsFor(s.PerPlan, planID).Provisioning[state] += total
+	stateStatsFor(s.PerRegion, region).Provisioning[state] += total
+}
+
+// AddDeprovisioning adds the number of deprovisioning operations in the given state for the plan and the region
+func (s OperationStats) AddDeprovisioning(planID, region string, state domain.LastOperationState, total int) {
+	s.Deprovisioning[state] += total
+	stateStatsFor(s.PerPlan, planID).Deprovisioning[state] += total
+	stateStatsFor(s.PerRegion, region).Deprovisioning[state] += total
+}
+
+func stateStatsFor(breakdown map[string]OperationStateStats, key string) OperationStateStats {
+	stats, found := breakdown[key]
+	if !found {
+		stats = newOperationStateStats()
+		breakdown[key] = stats
+	}
+	return stats
+}
+
+// NewOrchestrationStats creates empty stats with zero runtime operations of the orchestration in every state
+func NewOrchestrationStats() map[domain.LastOperationState]int {
+	return map[domain.LastOperationState]int{
+		OperationPending:  0,
+		domain.InProgress: 0,
+		domain.Succeeded:  0,
+		domain.Failed:     0,
+		OperationCanceled: 0,
+		OperationSkipped:  0,
+	}
+}
+
+// OperationTimeStats provide number of operations started, succeeded and failed per operation type
+// in the consecutive time buckets of the same length
+type OperationTimeStats struct {
+	From     time.Time
+	Interval time.Duration
+	Buckets  []OperationStatsBucket
+}
+
+// OperationStatsBucket provide number of operations per operation type in the bucket starting at Start
+type OperationStatsBucket struct {
+	Start      time.Time
+	Operations map[string]OperationCounts
+}
+
+// OperationCounts provide number of operations started, succeeded and failed
+type OperationCounts struct {
+	Started   int
+	Succeeded int
+	Failed    int
+}
+
+// NewOperationTimeStats creates empty buckets of the given interval which cover the window starting at from
+func NewOperationTimeStats(from time.Time, window, interval time.Duration) OperationTimeStats {
+	buckets := make([]OperationStatsBucket, window/interval)
+	for i := range buckets {
+		buckets[i] = OperationStatsBucket{
+			Start:      from.Add(time.Duration(i) * interval),
+			Operations: make(map[string]OperationCounts),
+		}
+	}
+
+	return OperationTimeStats{
+		From:     from,
+		Interval: interval,
+		Buckets:  buckets,
+	}
+}
+
+// BucketIndex returns the index of the bucket which contains the given time, false if the time is out of the window
+func (s OperationTimeStats) BucketIndex(at time.Time) (int, bool) {
+	if at.Before(s.From) {
+		return 0, false
+	}
+	idx := int(at.Sub(s.From) / s.Interval)
+
+	return idx, idx < len(s.Buckets)
+}
+
+// Add adds the numbers of operations of the given type to the bucket with the given index
+func (s OperationTimeStats) Add(idx int, operationType string, counts OperationCounts) {
+	if idx < 0 || idx >= len(s.Buckets) {
+		return
+	}
+	current := s.Buckets[idx].Operations[operationType]
+	s.Buckets[idx].Operations[operationType] = OperationCounts{
+		Started:   current.Started + counts.Started,
+		Succeeded: current.Succeeded + counts.Succeeded,
+		Failed:    current.Failed + counts.Failed,
+	}
 }
 
 // InstanceStats provide number of instances per Global Account ID
@@ -292,6 +807,24 @@ type InstanceStats struct {
 	PerGlobalAccountID     map[string]int
 }
 
+// CapacityStats provide the capacity requested by the instances per provider region and machine type,
+// the entries are sorted by the provider, the region and the machine type
+type CapacityStats struct {
+	Entries []CapacityEntry
+}
+
+// CapacityEntry holds the capacity requested by the instances of one machine type in one provider region,
+// MaxNodes and MaxVCPUs are the capacity the clusters can scale up to with their autoscaler maximum
+type CapacityEntry struct {
+	Provider    string
+	Region      string
+	MachineType string
+	Instances   int
+	MaxNodes    int
+	// MaxVCPUs is zero if the number of vCPUs of the machine type is unknown
+	MaxVCPUs int
+}
+
 // NewProvisioningOperation creates a fresh (just starting) instance of the ProvisioningOperation
 func NewProvisioningOperation(instanceID string, parameters ProvisioningParameters) (ProvisioningOperation, error) {
 	return NewProvisioningOperationWithID(uuid.New().String(), instanceID, parameters)
@@ -396,6 +929,131 @@ func (do *UpgradeKymaOperation) SetProvisioningParameters(parameters Provisionin
 	return nil
 }
 
+func (do *UpgradeClusterOperation) GetProvisioningParameters() (ProvisioningParameters, error) {
+	var pp ProvisioningParameters
+
+	err := json.Unmarshal([]byte(do.ProvisioningParameters), &pp)
+	if err != nil {
+		return pp, errors.Wrap(err, "while unmarshaling provisioning parameters")
+	}
+
+	return pp, nil
+}
+
+func (do *UpgradeClusterOperation) SetProvisioningParameters(parameters ProvisioningParameters) error {
+	params, err := json.Marshal(parameters)
+	if err != nil {
+		return errors.Wrap(err, "while marshaling provisioning parameters")
+	}
+
+	do.ProvisioningParameters = string(params)
+	return nil
+}
+
+// NewPlanMigrationOperation creates a fresh (just starting) instance of the PlanMigrationOperation
+func NewPlanMigrationOperation(instance Instance, parameters ProvisioningParameters) (PlanMigrationOperation, error) {
+	operation := PlanMigrationOperation{
+		Operation: Operation{
+			ID:          uuid.New().String(),
+			Version:     0,
+			Description: "Operation created",
+			InstanceID:  instance.InstanceID,
+			State:       domain.InProgress,
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+		},
+		SourcePlanID:                 instance.ServicePlanID,
+		SourceRuntimeID:              instance.RuntimeID,
+		SourceDashboardURL:           instance.DashboardURL,
+		SourceAPIServerURL:           instance.APIServerURL,
+		SourceCABundle:               instance.CABundle,
+		SourceShootName:              instance.ShootName,
+		SourceProvisioningParameters: instance.ProvisioningParameters,
+	}
+	err := operation.SetProvisioningParameters(parameters)
+
+	return operation, err
+}
+
+func (mo *PlanMigrationOperation) GetProvisioningParameters() (ProvisioningParameters, error) {
+	var pp ProvisioningParameters
+
+	err := json.Unmarshal([]byte(mo.ProvisioningParameters), &pp)
+	if err != nil {
+		return pp, errors.Wrap(err, "while unmarshaling provisioning parameters")
+	}
+
+	return pp, nil
+}
+
+func (mo *PlanMigrationOperation) SetProvisioningParameters(parameters ProvisioningParameters) error {
+	params, err := json.Marshal(parameters)
+	if err != nil {
+		return errors.Wrap(err, "while marshaling provisioning parameters")
+	}
+
+	mo.ProvisioningParameters = string(params)
+	return nil
+}
+
+// NewUpdatingOperation creates a fresh (just starting) instance of the UpdatingOperation
+func NewUpdatingOperation(instance Instance, parameters UpdatingParametersDTO) UpdatingOperation {
+	return UpdatingOperation{
+		Operation: Operation{
+			ID:          uuid.New().String(),
+			Version:     0,
+			Description: "Operation created",
+			InstanceID:  instance.InstanceID,
+			State:       domain.InProgress,
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+		},
+		RuntimeID:          instance.RuntimeID,
+		ShootName:          instance.ShootName,
+		UpdatingParameters: parameters,
+	}
+}
+
+// NewSuspensionOperation creates a fresh (just starting) instance of the SuspensionOperation
+func NewSuspensionOperation(instance Instance, action string) SuspensionOperation {
+	return SuspensionOperation{
+		Operation: Operation{
+			ID:          uuid.New().String(),
+			Version:     0,
+			Description: "Operation created",
+			InstanceID:  instance.InstanceID,
+			State:       domain.InProgress,
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+		},
+		RuntimeID: instance.RuntimeID,
+		ShootName: instance.ShootName,
+		Action:    action,
+	}
+}
+
+// NewAccountMigrationOperation creates a fresh (just starting) instance of the AccountMigrationOperation
+func NewAccountMigrationOperation(instance Instance, targetGlobalAccountID, targetSubAccountID string) AccountMigrationOperation {
+	return AccountMigrationOperation{
+		Operation: Operation{
+			ID:          uuid.New().String(),
+			Version:     0,
+			Description: "Operation created",
+			InstanceID:  instance.InstanceID,
+			State:       domain.InProgress,
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+		},
+		RuntimeID:             instance.RuntimeID,
+		ShootName:             instance.ShootName,
+		Tenant:                instance.Tenant(),
+		SourceGlobalAccountID: instance.GlobalAccountID,
+		SourceSubAccountID:    instance.SubAccountID,
+		TargetGlobalAccountID: targetGlobalAccountID,
+		TargetSubAccountID:    targetSubAccountID,
+	}
+}
+
 func (o *Operation) IsFinished() bool {
 	return o.State != InProgress
 }
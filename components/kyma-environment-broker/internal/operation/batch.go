@@ -0,0 +1,498 @@
+package operation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/event"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/scheduler"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession/dbmodel"
+
+	"github.com/google/uuid"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// BatchAction is applied by the batch job to every operation matching the filter
+type BatchAction string
+
+const (
+	// BatchRetry moves the failed operations back to the in progress state and queues them again
+	BatchRetry BatchAction = "retry"
+	// BatchAbandon fails the operations in progress, so they no longer block the next operations of the instance
+	BatchAbandon BatchAction = "abandon"
+)
+
+// BatchJobName is the name of the batch jobs in the job storage, the lock of this name ensures that only one batch job
+// runs at a time in all broker replicas
+const BatchJobName = "operations-batch"
+
+// BatchActor is recorded as the actor of the state transitions made by the batch jobs started without the user
+const BatchActor = "operations-batch"
+
+// AbandonedDescriptionPrefix starts the description of every operation failed by the abandon action,
+// so such operations can be told apart from the ones failed by their steps
+const AbandonedDescriptionPrefix = "abandoned operation:"
+
+type BatchConfig struct {
+	// Size limits the number of the operations read and changed at once
+	Size int `envconfig:"default=50"`
+	// Interval is the pause between the batches, so the storage and the queues are not flooded
+	Interval time.Duration `envconfig:"default=1s"`
+	// HistoryLimit is the number of the latest jobs kept for the progress queries
+	HistoryLimit int `envconfig:"default=20"`
+	// LockTTL defines how long the lock of the running job is valid, the lock is extended after every batch,
+	// so the TTL only limits how long the next job is blocked after the replica running the job crashed
+	LockTTL time.Duration `envconfig:"default=5m"`
+}
+
+// Queue processes the operations of one type
+type Queue interface {
+	Add(operationID string)
+}
+
+// BatchFilter selects the operations of the batch job, empty fields are not used for filtering except the type
+type BatchFilter struct {
+	Type  string `json:"type"`
+	State string `json:"state,omitempty"`
+	// OlderThan selects the operations created earlier than the given duration ago, e.g. "24h"
+	OlderThan       string `json:"olderThan,omitempty"`
+	OrchestrationID string `json:"orchestrationID,omitempty"`
+}
+
+type BatchRequest struct {
+	Filter BatchFilter `json:"filter"`
+	Action BatchAction `json:"action"`
+}
+
+// BatchJob describes the progress of the batch job, the counters are updated after every operation
+type BatchJob struct {
+	ID     string      `json:"id"`
+	Action BatchAction `json:"action"`
+	Filter BatchFilter `json:"filter"`
+	// User started the job, the state transitions made by the job are recorded with the user as the actor
+	User string `json:"user,omitempty"`
+	// State is one of InProgress, Succeeded or Failed
+	State string `json:"state"`
+	// Total is the number of the operations matching the filter when the job started
+	Total int `json:"total"`
+	// Processed counts the operations changed by the action, Skipped counts the ones which changed their state
+	// in the meantime and were left untouched
+	Processed  int        `json:"processed"`
+	Skipped    int        `json:"skipped"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  time.Time  `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+// BadRequestError is returned if the batch request is invalid
+type BadRequestError struct {
+	message string
+}
+
+func (e BadRequestError) Error() string {
+	return e.message
+}
+
+// NotFoundError is returned if the batch job does not exist
+type NotFoundError struct {
+	message string
+}
+
+func (e NotFoundError) Error() string {
+	return e.message
+}
+
+// ConflictError is returned if another batch job is in progress
+type ConflictError struct {
+	message string
+}
+
+func (e ConflictError) Error() string {
+	return e.message
+}
+
+// Batch retries or abandons all operations matching the filter instead of handling the operations one by one.
+// The job runs in the background and reads the operations in batches of the configured size, so the number
+// of the selected operations is not limited. The jobs are kept in the job storage, so only one job runs at a time
+// in all broker replicas and the progress of the job can be queried from any replica.
+type Batch struct {
+	operations storage.Operations
+	jobs       storage.Jobs
+	publisher  event.Publisher
+	owner      string
+	queues     map[dbmodel.OperationType]Queue
+	cfg        BatchConfig
+	log        logrus.FieldLogger
+	now        func() time.Time
+}
+
+// NewBatch constructs a Batch run by the broker replica identified by the owner, which retries the operations
+// of the types with the given queues. The Kyma upgrade operations can only be abandoned, they are retried together
+// with their orchestration.
+func NewBatch(operations storage.Operations, jobs storage.Jobs, pub event.Publisher, owner string, queues map[dbmodel.OperationType]Queue, cfg BatchConfig, log logrus.FieldLogger) *Batch {
+	return &Batch{
+		operations: operations,
+		jobs:       jobs,
+		publisher:  pub,
+		owner:      owner,
+		queues:     queues,
+		cfg:        cfg,
+		log:        log,
+		now:        time.Now,
+	}
+}
+
+// Start validates the request and starts the batch job of the given user in the background
+func (b *Batch) Start(req BatchRequest, user string) (BatchJob, error) {
+	opType, filter, err := b.operationFilter(req)
+	if err != nil {
+		return BatchJob{}, err
+	}
+
+	job := &BatchJob{
+		ID:        uuid.New().String(),
+		Action:    req.Action,
+		Filter:    req.Filter,
+		User:      user,
+		State:     internal.InProgress,
+		StartedAt: b.now(),
+	}
+	// the lock is owned by the job, so the jobs started one after another in the same replica do not share it
+	acquired, err := b.jobs.AcquireLock(BatchJobName, job.ID, job.StartedAt, job.StartedAt.Add(b.cfg.LockTTL))
+	switch {
+	case err != nil:
+		return BatchJob{}, errors.Wrap(err, "while acquiring batch job lock")
+	case !acquired:
+		return BatchJob{}, ConflictError{message: "another batch job is in progress"}
+	}
+
+	if err := b.failInterruptedJobs(); err != nil {
+		b.releaseLock(job)
+		return BatchJob{}, err
+	}
+	if err := b.insert(job); err != nil {
+		b.releaseLock(job)
+		return BatchJob{}, err
+	}
+
+	started := *job
+	go b.run(job, opType, filter)
+
+	return started, nil
+}
+
+// Job returns the progress of the batch job, only the configured number of the latest jobs is kept
+func (b *Batch) Job(jobID string) (BatchJob, error) {
+	runs, err := b.jobs.ListRuns(BatchJobName, b.cfg.HistoryLimit)
+	if err != nil {
+		return BatchJob{}, errors.Wrap(err, "while listing batch jobs")
+	}
+	for _, run := range runs {
+		if run.ID == jobID {
+			return toBatchJob(run)
+		}
+	}
+	return BatchJob{}, NotFoundError{message: fmt.Sprintf("batch job %s not found", jobID)}
+}
+
+// operationFilter validates the request and converts it to the filter of the operations storage
+func (b *Batch) operationFilter(req BatchRequest) (dbmodel.OperationType, dbmodel.OperationFilter, error) {
+	var state domain.LastOperationState
+	switch req.Action {
+	case BatchRetry:
+		state = domain.Failed
+	case BatchAbandon:
+		state = domain.InProgress
+	default:
+		return "", dbmodel.OperationFilter{}, BadRequestError{message: fmt.Sprintf("action must be one of %q, %q", BatchRetry, BatchAbandon)}
+	}
+	if req.Filter.State != "" && req.Filter.State != string(state) {
+		return "", dbmodel.OperationFilter{}, BadRequestError{message: fmt.Sprintf("only the operations in %s state can be selected for the %s action", state, req.Action)}
+	}
+
+	opType := dbmodel.OperationType(req.Filter.Type)
+	_, queued := b.queues[opType]
+	switch {
+	case req.Filter.Type == "":
+		return "", dbmodel.OperationFilter{}, BadRequestError{message: "operation type must be specified"}
+	case opType == dbmodel.OperationTypeUpgradeKyma && req.Action == BatchRetry:
+		return "", dbmodel.OperationFilter{}, BadRequestError{message: "the Kyma upgrade operations are retried with their orchestration"}
+	case !queued && opType != dbmodel.OperationTypeUpgradeKyma:
+		return "", dbmodel.OperationFilter{}, BadRequestError{message: fmt.Sprintf("operation type %s is not supported", opType)}
+	}
+
+	filter := dbmodel.OperationFilter{
+		States:          []string{string(state)},
+		OrchestrationID: req.Filter.OrchestrationID,
+	}
+	if req.Filter.OlderThan != "" {
+		olderThan, err := time.ParseDuration(req.Filter.OlderThan)
+		if err != nil || olderThan <= 0 {
+			return "", dbmodel.OperationFilter{}, BadRequestError{message: fmt.Sprintf("invalid value for olderThan: %s, the value must be a positive duration, e.g. \"24h\"", req.Filter.OlderThan)}
+		}
+		filter.CreatedBefore = b.now().Add(-olderThan)
+	}
+	return opType, filter, nil
+}
+
+// run applies the action to the operations matching the filter batch by batch. The operations are read
+// with the cursor, so the operations changed by the previous batches do not shift the next ones. The job
+// is changed only by this goroutine, its progress is stored after every batch.
+func (b *Batch) run(job *BatchJob, opType dbmodel.OperationType, filter dbmodel.OperationFilter) {
+	log := b.log.WithField("batchJobID", job.ID).WithField("action", job.Action).WithField("operationType", opType)
+	log.Infof("Starting batch job")
+
+	first := true
+	for {
+		operations, _, totalCount, err := b.operations.ListOperationsByType(opType, filter, b.cfg.Size, 1)
+		if err != nil {
+			b.finish(job, log, errors.Wrapf(err, "while listing %s operations", opType))
+			return
+		}
+		if first {
+			job.Total = totalCount
+			first = false
+		}
+
+		for _, op := range operations {
+			changed, err := b.apply(job, opType, op.ID)
+			if err != nil {
+				b.finish(job, log, err)
+				return
+			}
+			if changed {
+				job.Processed++
+			} else {
+				job.Skipped++
+			}
+		}
+
+		if len(operations) < b.cfg.Size {
+			b.finish(job, log, nil)
+			return
+		}
+		b.update(job, log)
+		last := operations[len(operations)-1]
+		filter.After = &dbmodel.OperationCursor{CreatedAt: last.CreatedAt, OperationID: last.ID}
+		time.Sleep(b.cfg.Interval)
+	}
+}
+
+// apply changes the operation unless its state changed in the meantime, the retried operation is queued again.
+// The state transition is recorded with the user who started the job as the actor.
+func (b *Batch) apply(job *BatchJob, opType dbmodel.OperationType, operationID string) (bool, error) {
+	now := b.now()
+	changed := false
+	var oldState domain.LastOperationState
+	var changedOperation internal.Operation
+	mutate := func(op *internal.Operation) {
+		changed = false
+		oldState = op.State
+		switch job.Action {
+		case BatchAbandon:
+			if op.State != domain.InProgress {
+				return
+			}
+			op.State = domain.Failed
+			op.Description = fmt.Sprintf("%s failed by the operations batch job %s", AbandonedDescriptionPrefix, job.ID)
+		case BatchRetry:
+			if op.State != domain.Failed {
+				return
+			}
+			op.State = domain.InProgress
+			op.Description = fmt.Sprintf("operation scheduled for retry by the operations batch job %s", job.ID)
+			// the time of the stage is measured again, otherwise the retried operation exceeds the timeout of its stage
+			if op.Stage != "" {
+				op.StageStartedAt = now
+			}
+			// the deprovisioning is triggered again in the provisioner, the same as for the repeated deprovisioning request
+			if opType == dbmodel.OperationTypeDeprovision {
+				op.ProvisionerOperationID = ""
+			}
+			// the timeout of the operations without stages is measured again, see internal.Operation.TimeoutStart
+			op.RetriedAt = now
+		}
+		changed = true
+		changedOperation = *op
+	}
+
+	var err error
+	switch opType {
+	case dbmodel.OperationTypeProvision:
+		_, err = storage.UpdateWithRetryProvisioningOperation(b.operations, operationID, func(op *internal.ProvisioningOperation) {
+			mutate(&op.Operation)
+		})
+	case dbmodel.OperationTypeDeprovision:
+		_, err = storage.UpdateWithRetryDeprovisioningOperation(b.operations, operationID, func(op *internal.DeprovisioningOperation) {
+			mutate(&op.Operation)
+		})
+	case dbmodel.OperationTypeUpgradeKyma:
+		_, err = storage.UpdateWithRetryUpgradeKymaOperation(b.operations, operationID, func(op *internal.UpgradeKymaOperation) {
+			mutate(&op.Operation)
+		})
+	case dbmodel.OperationTypeMigratePlan:
+		_, err = storage.UpdateWithRetryPlanMigrationOperation(b.operations, operationID, func(op *internal.PlanMigrationOperation) {
+			mutate(&op.Operation)
+		})
+	case dbmodel.OperationTypeUpdate:
+		_, err = storage.UpdateWithRetryUpdatingOperation(b.operations, operationID, func(op *internal.UpdatingOperation) {
+			mutate(&op.Operation)
+		})
+	case dbmodel.OperationTypeSuspension:
+		_, err = storage.UpdateWithRetrySuspensionOperation(b.operations, operationID, func(op *internal.SuspensionOperation) {
+			mutate(&op.Operation)
+		})
+	case dbmodel.OperationTypeAccountMigration:
+		_, err = storage.UpdateWithRetryAccountMigrationOperation(b.operations, operationID, func(op *internal.AccountMigrationOperation) {
+			mutate(&op.Operation)
+			// the retried migration applies the changes again instead of reverting them
+			if changed && job.Action == BatchRetry {
+				op.RevertReason = ""
+			}
+		})
+	default:
+		return false, errors.Errorf("unsupported operation type %s", opType)
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "while updating %s operation %s", opType, operationID)
+	}
+
+	if !changed {
+		return false, nil
+	}
+	actor := job.User
+	if actor == "" {
+		actor = BatchActor
+	}
+	b.publisher.Publish(context.Background(), process.OperationStateChanged{OldState: oldState, Operation: changedOperation, Actor: actor})
+
+	if job.Action == BatchRetry {
+		b.queues[opType].Add(operationID)
+	}
+	return true, nil
+}
+
+// update stores the progress of the job and extends the lock of the job
+func (b *Batch) update(job *BatchJob, log logrus.FieldLogger) {
+	if err := b.store(job); err != nil {
+		log.Errorf("while storing progress of batch job: %s", err)
+	}
+
+	now := b.now()
+	acquired, err := b.jobs.AcquireLock(BatchJobName, job.ID, now, now.Add(b.cfg.LockTTL))
+	switch {
+	case err != nil:
+		log.Errorf("while extending batch job lock: %s", err)
+	case !acquired:
+		log.Warn("The batch job lock expired and was taken over by another job")
+	}
+}
+
+func (b *Batch) finish(job *BatchJob, log logrus.FieldLogger, err error) {
+	defer b.releaseLock(job)
+
+	finishedAt := b.now()
+	job.FinishedAt = &finishedAt
+	job.State = internal.Succeeded
+	if err != nil {
+		job.State = internal.Failed
+		job.Error = err.Error()
+		log.Errorf("Batch job failed after %d processed operations: %s", job.Processed, err)
+	} else {
+		log.Infof("Batch job finished, processed %d and skipped %d operations", job.Processed, job.Skipped)
+	}
+	if err := b.store(job); err != nil {
+		log.Errorf("while storing result of batch job: %s", err)
+	}
+}
+
+// failInterruptedJobs fails the jobs left in progress by the replica which crashed or was restarted, the lock
+// is held when it is called, so none of the jobs is running
+func (b *Batch) failInterruptedJobs() error {
+	runs, err := b.jobs.ListRuns(BatchJobName, b.cfg.HistoryLimit)
+	if err != nil {
+		return errors.Wrap(err, "while listing batch jobs")
+	}
+	for _, run := range runs {
+		if run.State != internal.InProgress {
+			continue
+		}
+		job, err := toBatchJob(run)
+		if err != nil {
+			return err
+		}
+		finishedAt := b.now()
+		job.FinishedAt = &finishedAt
+		job.State = internal.Failed
+		job.Error = "the batch job was interrupted"
+		if err := b.store(&job); err != nil {
+			return errors.Wrapf(err, "while failing interrupted batch job %s", job.ID)
+		}
+	}
+	return nil
+}
+
+func (b *Batch) insert(job *BatchJob) error {
+	run, err := b.toJobRun(job)
+	if err != nil {
+		return err
+	}
+	if err := b.jobs.InsertRun(run); err != nil {
+		return errors.Wrap(err, "while inserting batch job")
+	}
+	return nil
+}
+
+func (b *Batch) store(job *BatchJob) error {
+	run, err := b.toJobRun(job)
+	if err != nil {
+		return err
+	}
+	if err := b.jobs.UpdateRun(run); err != nil {
+		return errors.Wrap(err, "while updating batch job")
+	}
+	return nil
+}
+
+func (b *Batch) releaseLock(job *BatchJob) {
+	if err := b.jobs.ReleaseLock(BatchJobName, job.ID); err != nil {
+		b.log.Errorf("while releasing lock of batch job %s: %s", job.ID, err)
+	}
+}
+
+// toJobRun converts the job to the run kept in the job storage, the progress of the job is kept in the details
+func (b *Batch) toJobRun(job *BatchJob) (internal.JobRun, error) {
+	details, err := json.Marshal(job)
+	if err != nil {
+		return internal.JobRun{}, errors.Wrap(err, "while marshalling batch job")
+	}
+	run := internal.JobRun{
+		ID:        job.ID,
+		JobName:   BatchJobName,
+		Owner:     b.owner,
+		Trigger:   scheduler.TriggerManual,
+		State:     job.State,
+		Error:     job.Error,
+		Details:   string(details),
+		StartedAt: job.StartedAt,
+	}
+	if job.FinishedAt != nil {
+		run.FinishedAt = *job.FinishedAt
+	}
+	return run, nil
+}
+
+func toBatchJob(run internal.JobRun) (BatchJob, error) {
+	var job BatchJob
+	if err := json.Unmarshal([]byte(run.Details), &job); err != nil {
+		return BatchJob{}, errors.Wrapf(err, "while unmarshalling batch job %s", run.ID)
+	}
+	return job, nil
+}
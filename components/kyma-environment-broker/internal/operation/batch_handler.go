@@ -0,0 +1,72 @@
+package operation
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/httputil"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// BatchHandler exposes the batch jobs which retry or abandon the operations matching the filter
+type BatchHandler struct {
+	batch *Batch
+	log   logrus.FieldLogger
+}
+
+func NewBatchHandler(batch *Batch, log logrus.FieldLogger) *BatchHandler {
+	return &BatchHandler{
+		batch: batch,
+		log:   log,
+	}
+}
+
+func (h *BatchHandler) AttachRoutes(router *mux.Router) {
+	router.HandleFunc("/operations:batch", h.start).Methods(http.MethodPost)
+	router.HandleFunc("/operations:batch/{job_id}", h.getJob).Methods(http.MethodGet)
+}
+
+// start runs the batch job of the requesting user in the background and returns the job, its progress is returned by getJob
+func (h *BatchHandler) start(w http.ResponseWriter, r *http.Request) {
+	req := BatchRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, errors.Wrap(err, "while decoding request body"))
+		return
+	}
+
+	job, err := h.batch.Start(req, httputil.User(r))
+	if err != nil {
+		h.writeError(w, err, "while starting batch job")
+		return
+	}
+
+	httputil.WriteResponse(w, http.StatusAccepted, job)
+}
+
+// getJob returns the progress of the batch job
+func (h *BatchHandler) getJob(w http.ResponseWriter, r *http.Request) {
+	job, err := h.batch.Job(mux.Vars(r)["job_id"])
+	if err != nil {
+		h.writeError(w, err, "while getting batch job")
+		return
+	}
+
+	httputil.WriteResponse(w, http.StatusOK, job)
+}
+
+func (h *BatchHandler) writeError(w http.ResponseWriter, err error, context string) {
+	switch err.(type) {
+	case BadRequestError:
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, err)
+	case NotFoundError:
+		httputil.WriteErrorResponse(w, http.StatusNotFound, err)
+	case ConflictError:
+		httputil.WriteErrorResponse(w, http.StatusConflict, err)
+	default:
+		h.log.Errorf("%s: %v", context, err)
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, context))
+	}
+}
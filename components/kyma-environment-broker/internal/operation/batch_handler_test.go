@@ -0,0 +1,91 @@
+package operation
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/event"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession/dbmodel"
+
+	"github.com/gorilla/mux"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchHandler(t *testing.T) {
+	// given
+	db := storage.NewMemoryStorage()
+	err := db.Operations().InsertProvisioningOperation(internal.ProvisioningOperation{
+		Operation: fixOperation("operation-id", domain.InProgress, time.Now().Add(-time.Hour)),
+	})
+	require.NoError(t, err)
+
+	batch := NewBatch(db.Operations(), db.Jobs(), event.NewPubSub(), fixOwner, map[dbmodel.OperationType]Queue{dbmodel.OperationTypeProvision: &fakeQueue{}}, fixBatchConfig(), logrus.New())
+	router := mux.NewRouter()
+	NewBatchHandler(batch, logrus.New()).AttachRoutes(router)
+
+	t.Run("should start batch job", func(t *testing.T) {
+		// given
+		body := `{"action": "abandon", "filter": {"type": "provision", "olderThan": "30m"}}`
+		req := httptest.NewRequest(http.MethodPost, "/operations:batch", bytes.NewBufferString(body))
+		rr := httptest.NewRecorder()
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		require.Equal(t, http.StatusAccepted, rr.Code)
+		var started BatchJob
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &started))
+		assert.NotEmpty(t, started.ID)
+		assert.Equal(t, BatchAbandon, started.Action)
+
+		// when
+		var job BatchJob
+		require.Eventually(t, func() bool {
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/operations:batch/"+started.ID, nil))
+			if rr.Code != http.StatusOK || json.Unmarshal(rr.Body.Bytes(), &job) != nil {
+				return false
+			}
+			return job.State != internal.InProgress
+		}, 2*time.Second, 10*time.Millisecond)
+
+		// then
+		assert.Equal(t, internal.Succeeded, job.State)
+		assert.Equal(t, 1, job.Processed)
+	})
+
+	t.Run("should reject invalid request", func(t *testing.T) {
+		// given
+		body := `{"action": "retry", "filter": {"type": "upgradeKyma"}}`
+		req := httptest.NewRequest(http.MethodPost, "/operations:batch", bytes.NewBufferString(body))
+		rr := httptest.NewRecorder()
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("should return not found for unknown job", func(t *testing.T) {
+		// given
+		req := httptest.NewRequest(http.MethodGet, "/operations:batch/unknown", nil)
+		rr := httptest.NewRecorder()
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
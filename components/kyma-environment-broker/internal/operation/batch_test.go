@@ -0,0 +1,319 @@
+package operation
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/event"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession/dbmodel"
+
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatch_Abandon(t *testing.T) {
+	// given
+	db := storage.NewMemoryStorage()
+	now := time.Now()
+	for _, op := range []internal.Operation{
+		fixOperation("old-1", domain.InProgress, now.Add(-3*time.Hour)),
+		fixOperation("old-2", domain.InProgress, now.Add(-2*time.Hour)),
+		fixOperation("old-3", domain.InProgress, now.Add(-2*time.Hour)),
+		fixOperation("recent", domain.InProgress, now),
+		fixOperation("failed", domain.Failed, now.Add(-3*time.Hour)),
+	} {
+		err := db.Operations().InsertProvisioningOperation(internal.ProvisioningOperation{Operation: op})
+		require.NoError(t, err)
+	}
+	queue := &fakeQueue{}
+	pub := &recordingPublisher{}
+	batch := NewBatch(db.Operations(), db.Jobs(), pub, fixOwner, map[dbmodel.OperationType]Queue{dbmodel.OperationTypeProvision: queue}, fixBatchConfig(), logrus.New())
+
+	// when
+	job, err := batch.Start(BatchRequest{
+		Action: BatchAbandon,
+		Filter: BatchFilter{Type: string(dbmodel.OperationTypeProvision), OlderThan: "1h"},
+	}, fixUser)
+
+	// then
+	require.NoError(t, err)
+	job = waitForBatchJob(t, batch, job.ID)
+	assert.Equal(t, internal.Succeeded, job.State)
+	assert.Equal(t, 3, job.Total)
+	assert.Equal(t, 3, job.Processed)
+	assert.Equal(t, 0, job.Skipped)
+	assert.NotNil(t, job.FinishedAt)
+
+	for id, expected := range map[string]domain.LastOperationState{
+		"old-1":  domain.Failed,
+		"old-2":  domain.Failed,
+		"old-3":  domain.Failed,
+		"recent": domain.InProgress,
+		"failed": domain.Failed,
+	} {
+		op, err := db.Operations().GetOperationByID(id)
+		require.NoError(t, err)
+		assert.Equal(t, expected, op.State, "state of the operation %s", id)
+	}
+	op, err := db.Operations().GetOperationByID("old-2")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(op.Description, AbandonedDescriptionPrefix))
+	assert.Empty(t, queue.ids())
+
+	changes := pub.stateChanges()
+	require.Len(t, changes, 3)
+	for _, changed := range changes {
+		assert.Equal(t, fixUser, changed.Actor)
+		assert.Equal(t, domain.InProgress, changed.OldState)
+		assert.Equal(t, domain.Failed, changed.Operation.State)
+	}
+}
+
+func TestBatch_AbandonOperationsOfOrchestration(t *testing.T) {
+	// given
+	db := storage.NewMemoryStorage()
+	now := time.Now()
+	for i, orchestrationID := range []string{"orchestration-id", "other-orchestration-id", "orchestration-id"} {
+		op := fixOperation([]string{"first", "other", "second"}[i], domain.InProgress, now.Add(time.Duration(i)*time.Minute))
+		op.OrchestrationID = orchestrationID
+		err := db.Operations().InsertUpgradeKymaOperation(internal.UpgradeKymaOperation{RuntimeOperation: internal.RuntimeOperation{Operation: op}})
+		require.NoError(t, err)
+	}
+	batch := NewBatch(db.Operations(), db.Jobs(), event.NewPubSub(), fixOwner, map[dbmodel.OperationType]Queue{}, fixBatchConfig(), logrus.New())
+
+	// when
+	job, err := batch.Start(BatchRequest{
+		Action: BatchAbandon,
+		Filter: BatchFilter{Type: string(dbmodel.OperationTypeUpgradeKyma), OrchestrationID: "orchestration-id"},
+	}, fixUser)
+
+	// then
+	require.NoError(t, err)
+	job = waitForBatchJob(t, batch, job.ID)
+	assert.Equal(t, internal.Succeeded, job.State)
+	assert.Equal(t, 2, job.Processed)
+
+	for id, expected := range map[string]domain.LastOperationState{
+		"first":  domain.Failed,
+		"other":  domain.InProgress,
+		"second": domain.Failed,
+	} {
+		op, err := db.Operations().GetOperationByID(id)
+		require.NoError(t, err)
+		assert.Equal(t, expected, op.State, "state of the operation %s", id)
+	}
+}
+
+func TestBatch_Retry(t *testing.T) {
+	// given
+	db := storage.NewMemoryStorage()
+	now := time.Now()
+	for _, op := range []internal.Operation{
+		fixOperation("failed-1", domain.Failed, now.Add(-time.Hour)),
+		fixOperation("failed-2", domain.Failed, now.Add(-time.Hour)),
+		fixOperation("succeeded", domain.Succeeded, now.Add(-time.Hour)),
+	} {
+		op.ProvisionerOperationID = "provisioner-operation-id"
+		op.Stage = "cleanup"
+		op.StageStartedAt = now.Add(-time.Hour)
+		err := db.Operations().InsertDeprovisioningOperation(internal.DeprovisioningOperation{Operation: op})
+		require.NoError(t, err)
+	}
+	queue := &fakeQueue{}
+	batch := NewBatch(db.Operations(), db.Jobs(), event.NewPubSub(), fixOwner, map[dbmodel.OperationType]Queue{dbmodel.OperationTypeDeprovision: queue}, fixBatchConfig(), logrus.New())
+
+	// when
+	job, err := batch.Start(BatchRequest{
+		Action: BatchRetry,
+		Filter: BatchFilter{Type: string(dbmodel.OperationTypeDeprovision), State: string(domain.Failed)},
+	}, fixUser)
+
+	// then
+	require.NoError(t, err)
+	job = waitForBatchJob(t, batch, job.ID)
+	assert.Equal(t, internal.Succeeded, job.State)
+	assert.Equal(t, 2, job.Total)
+	assert.Equal(t, 2, job.Processed)
+	assert.ElementsMatch(t, []string{"failed-1", "failed-2"}, queue.ids())
+
+	op, err := db.Operations().GetDeprovisioningOperationByID("failed-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.InProgress, op.State)
+	assert.Empty(t, op.ProvisionerOperationID)
+	assert.True(t, op.StageStartedAt.After(now.Add(-time.Minute)), "the time of the stage must be measured again")
+	assert.True(t, op.TimeoutStart().After(now.Add(-time.Minute)), "the timeout of the operation must be measured again")
+
+	op, err = db.Operations().GetDeprovisioningOperationByID("succeeded")
+	require.NoError(t, err)
+	assert.Equal(t, domain.Succeeded, op.State)
+}
+
+func TestBatch_StartInvalidRequest(t *testing.T) {
+	for name, req := range map[string]BatchRequest{
+		"unknown action": {
+			Action: "delete",
+			Filter: BatchFilter{Type: string(dbmodel.OperationTypeProvision)},
+		},
+		"missing type": {
+			Action: BatchAbandon,
+		},
+		"unsupported type": {
+			Action: BatchAbandon,
+			Filter: BatchFilter{Type: string(dbmodel.OperationTypeUpgradeCluster)},
+		},
+		"retry of Kyma upgrade": {
+			Action: BatchRetry,
+			Filter: BatchFilter{Type: string(dbmodel.OperationTypeUpgradeKyma)},
+		},
+		"state not matching the action": {
+			Action: BatchRetry,
+			Filter: BatchFilter{Type: string(dbmodel.OperationTypeProvision), State: string(domain.InProgress)},
+		},
+		"invalid older than": {
+			Action: BatchAbandon,
+			Filter: BatchFilter{Type: string(dbmodel.OperationTypeProvision), OlderThan: "-1h"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// given
+			db := storage.NewMemoryStorage()
+			batch := NewBatch(db.Operations(), db.Jobs(), event.NewPubSub(), fixOwner, map[dbmodel.OperationType]Queue{dbmodel.OperationTypeProvision: &fakeQueue{}}, fixBatchConfig(), logrus.New())
+
+			// when
+			_, err := batch.Start(req, fixUser)
+
+			// then
+			require.Error(t, err)
+			assert.IsType(t, BadRequestError{}, err)
+		})
+	}
+}
+
+func TestBatch_Job(t *testing.T) {
+	// given
+	db := storage.NewMemoryStorage()
+	batch := NewBatch(db.Operations(), db.Jobs(), event.NewPubSub(), fixOwner, map[dbmodel.OperationType]Queue{dbmodel.OperationTypeProvision: &fakeQueue{}}, BatchConfig{Size: 2, HistoryLimit: 1, LockTTL: time.Minute}, logrus.New())
+
+	first, err := batch.Start(BatchRequest{Action: BatchAbandon, Filter: BatchFilter{Type: string(dbmodel.OperationTypeProvision)}}, fixUser)
+	require.NoError(t, err)
+	waitForBatchJob(t, batch, first.ID)
+	second, err := batch.Start(BatchRequest{Action: BatchAbandon, Filter: BatchFilter{Type: string(dbmodel.OperationTypeProvision)}}, fixUser)
+	require.NoError(t, err)
+	waitForBatchJob(t, batch, second.ID)
+
+	// when
+	_, err = batch.Job(first.ID)
+
+	// then
+	assert.IsType(t, NotFoundError{}, err, "only the latest jobs are kept")
+}
+
+func TestBatch_StartWhileAnotherJobIsInProgress(t *testing.T) {
+	// given
+	db := storage.NewMemoryStorage()
+	now := time.Now()
+	interrupted := BatchJob{ID: "interrupted", Action: BatchAbandon, State: internal.InProgress, Processed: 2, StartedAt: now.Add(-time.Hour)}
+	batch := NewBatch(db.Operations(), db.Jobs(), event.NewPubSub(), fixOwner, map[dbmodel.OperationType]Queue{dbmodel.OperationTypeProvision: &fakeQueue{}}, fixBatchConfig(), logrus.New())
+	run, err := batch.toJobRun(&interrupted)
+	require.NoError(t, err)
+	require.NoError(t, db.Jobs().InsertRun(run))
+	acquired, err := db.Jobs().AcquireLock(BatchJobName, interrupted.ID, now, now.Add(time.Minute))
+	require.NoError(t, err)
+	require.True(t, acquired)
+
+	// when
+	_, err = batch.Start(BatchRequest{Action: BatchAbandon, Filter: BatchFilter{Type: string(dbmodel.OperationTypeProvision)}}, fixUser)
+
+	// then
+	assert.IsType(t, ConflictError{}, err, "the job of another replica holds the lock")
+
+	// when the replica running the job crashed and its lock expired
+	require.NoError(t, db.Jobs().ReleaseLock(BatchJobName, interrupted.ID))
+	job, err := batch.Start(BatchRequest{Action: BatchAbandon, Filter: BatchFilter{Type: string(dbmodel.OperationTypeProvision)}}, fixUser)
+
+	// then
+	require.NoError(t, err)
+	waitForBatchJob(t, batch, job.ID)
+	got, err := batch.Job(interrupted.ID)
+	require.NoError(t, err)
+	assert.Equal(t, internal.Failed, got.State)
+	assert.Equal(t, 2, got.Processed)
+	assert.NotNil(t, got.FinishedAt)
+}
+
+const (
+	fixOwner = "broker-replica"
+	fixUser  = "jane.doe"
+)
+
+func fixBatchConfig() BatchConfig {
+	return BatchConfig{
+		Size:         2,
+		HistoryLimit: 10,
+		LockTTL:      time.Minute,
+	}
+}
+
+func fixOperation(id string, state domain.LastOperationState, createdAt time.Time) internal.Operation {
+	return internal.Operation{
+		ID:         id,
+		InstanceID: "instance-" + id,
+		State:      state,
+		CreatedAt:  createdAt,
+		UpdatedAt:  createdAt,
+	}
+}
+
+func waitForBatchJob(t *testing.T, batch *Batch, jobID string) BatchJob {
+	var job BatchJob
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = batch.Job(jobID)
+		return err == nil && job.State != internal.InProgress
+	}, 2*time.Second, 10*time.Millisecond)
+	return job
+}
+
+type fakeQueue struct {
+	mu    sync.Mutex
+	added []string
+}
+
+func (q *fakeQueue) Add(operationID string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.added = append(q.added, operationID)
+}
+
+func (q *fakeQueue) ids() []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]string{}, q.added...)
+}
+
+type recordingPublisher struct {
+	mu      sync.Mutex
+	changed []process.OperationStateChanged
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, ev interface{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if changed, ok := ev.(process.OperationStateChanged); ok {
+		p.changed = append(p.changed, changed)
+	}
+}
+
+func (p *recordingPublisher) stateChanges() []process.OperationStateChanged {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]process.OperationStateChanged{}, p.changed...)
+}
@@ -0,0 +1,75 @@
+package operation
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/pkg/errors"
+)
+
+type CorrelatedOperationResponse struct {
+	OperationID            string    `json:"operationID"`
+	InstanceID             string    `json:"instanceID"`
+	ProvisionerOperationID string    `json:"provisionerOperationID,omitempty"`
+	OrchestrationID        string    `json:"orchestrationID,omitempty"`
+	State                  string    `json:"state"`
+	Description            string    `json:"description"`
+	CreatedAt              time.Time `json:"createdAt"`
+	UpdatedAt              time.Time `json:"updatedAt"`
+}
+
+type CorrelationResponse struct {
+	CorrelationID string                        `json:"correlationID"`
+	Operations    []CorrelatedOperationResponse `json:"operations"`
+}
+
+// CorrelationHandler exposes the operations created for the request with the given correlation ID together with
+// the IDs of the Provisioner operations, so the traces of KEB and the other components can be stitched together
+type CorrelationHandler struct {
+	operations storage.Operations
+}
+
+func NewCorrelationHandler(operations storage.Operations) *CorrelationHandler {
+	return &CorrelationHandler{
+		operations: operations,
+	}
+}
+
+func (h *CorrelationHandler) AttachRoutes(router *mux.Router) {
+	router.HandleFunc("/correlations/{correlation_id}", h.getCorrelation).Methods(http.MethodGet)
+}
+
+func (h *CorrelationHandler) getCorrelation(w http.ResponseWriter, req *http.Request) {
+	correlationID := mux.Vars(req)["correlation_id"]
+
+	operations, err := h.operations.ListOperationsByCorrelationID(correlationID)
+	if err != nil {
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrapf(err, "while getting operations with correlation ID %s", correlationID))
+		return
+	}
+	if len(operations) == 0 {
+		httputil.WriteErrorResponse(w, http.StatusNotFound, errors.Errorf("operations with correlation ID %s not found", correlationID))
+		return
+	}
+
+	response := CorrelationResponse{
+		CorrelationID: correlationID,
+		Operations:    make([]CorrelatedOperationResponse, 0, len(operations)),
+	}
+	for _, op := range operations {
+		response.Operations = append(response.Operations, CorrelatedOperationResponse{
+			OperationID:            op.ID,
+			InstanceID:             op.InstanceID,
+			ProvisionerOperationID: op.ProvisionerOperationID,
+			OrchestrationID:        op.OrchestrationID,
+			State:                  string(op.State),
+			Description:            op.Description,
+			CreatedAt:              op.CreatedAt,
+			UpdatedAt:              op.UpdatedAt,
+		})
+	}
+	httputil.WriteResponse(w, http.StatusOK, response)
+}
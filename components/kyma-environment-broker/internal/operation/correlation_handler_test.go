@@ -0,0 +1,79 @@
+package operation
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCorrelationHandler_GetCorrelation(t *testing.T) {
+	// given
+	db := storage.NewMemoryStorage()
+	now := time.Now()
+	err := db.Operations().InsertProvisioningOperation(internal.ProvisioningOperation{
+		Operation: internal.Operation{
+			ID:                     "provisioning-id",
+			InstanceID:             "instance-id",
+			ProvisionerOperationID: "provisioner-operation-id",
+			State:                  domain.Succeeded,
+			CorrelationID:          "correlation-id",
+			CreatedAt:              now,
+		},
+	})
+	require.NoError(t, err)
+	err = db.Operations().InsertDeprovisioningOperation(internal.DeprovisioningOperation{
+		Operation: internal.Operation{
+			ID:            "deprovisioning-id",
+			InstanceID:    "instance-id",
+			State:         domain.InProgress,
+			CorrelationID: "other-correlation-id",
+			CreatedAt:     now.Add(time.Hour),
+		},
+	})
+	require.NoError(t, err)
+
+	router := mux.NewRouter()
+	NewCorrelationHandler(db.Operations()).AttachRoutes(router)
+
+	t.Run("should return operations with the correlation ID", func(t *testing.T) {
+		// given
+		req, err := http.NewRequest(http.MethodGet, "/correlations/correlation-id", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+		var response CorrelationResponse
+		err = json.Unmarshal(rr.Body.Bytes(), &response)
+		require.NoError(t, err)
+		assert.Equal(t, "correlation-id", response.CorrelationID)
+		require.Len(t, response.Operations, 1)
+		assert.Equal(t, "provisioning-id", response.Operations[0].OperationID)
+		assert.Equal(t, "provisioner-operation-id", response.Operations[0].ProvisionerOperationID)
+	})
+
+	t.Run("should return not found for unknown correlation ID", func(t *testing.T) {
+		// given
+		req, err := http.NewRequest(http.MethodGet, "/correlations/unknown", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
@@ -0,0 +1,79 @@
+package operation
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
+	"github.com/pkg/errors"
+)
+
+type EventResponse struct {
+	Actor       string    `json:"actor"`
+	StepName    string    `json:"stepName,omitempty"`
+	OldState    string    `json:"oldState"`
+	NewState    string    `json:"newState"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+type EventResponseList struct {
+	Data  []EventResponse `json:"data"`
+	Count int             `json:"count"`
+}
+
+// EventsHandler exposes the state transitions recorded for the operations
+type EventsHandler struct {
+	operations storage.Operations
+	events     storage.OperationEvents
+}
+
+func NewEventsHandler(operations storage.Operations, events storage.OperationEvents) *EventsHandler {
+	return &EventsHandler{
+		operations: operations,
+		events:     events,
+	}
+}
+
+func (h *EventsHandler) AttachRoutes(router *mux.Router) {
+	router.HandleFunc("/operations/{operation_id}/events", h.getEvents).Methods(http.MethodGet)
+}
+
+func (h *EventsHandler) getEvents(w http.ResponseWriter, req *http.Request) {
+	operationID := mux.Vars(req)["operation_id"]
+
+	_, err := h.operations.GetOperationByID(operationID)
+	switch {
+	case dberr.IsNotFound(err):
+		httputil.WriteErrorResponse(w, http.StatusNotFound, errors.Wrapf(err, "while getting operation %s", operationID))
+		return
+	case err != nil:
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrapf(err, "while getting operation %s", operationID))
+		return
+	}
+
+	events, err := h.events.ListEventsByOperationID(operationID)
+	if err != nil {
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrapf(err, "while getting events of the operation %s", operationID))
+		return
+	}
+
+	response := EventResponseList{
+		Data:  make([]EventResponse, 0, len(events)),
+		Count: len(events),
+	}
+	for _, ev := range events {
+		response.Data = append(response.Data, EventResponse{
+			Actor:       ev.Actor,
+			StepName:    ev.StepName,
+			OldState:    string(ev.OldState),
+			NewState:    string(ev.NewState),
+			Description: ev.Description,
+			CreatedAt:   ev.CreatedAt,
+		})
+	}
+	httputil.WriteResponse(w, http.StatusOK, response)
+}
@@ -0,0 +1,76 @@
+package operation
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventsHandler_GetEvents(t *testing.T) {
+	// given
+	db := storage.NewMemoryStorage()
+	err := db.Operations().InsertProvisioningOperation(internal.ProvisioningOperation{
+		Operation: internal.Operation{
+			ID:         "operation-id",
+			InstanceID: "instance-id",
+			State:      domain.Succeeded,
+		},
+	})
+	require.NoError(t, err)
+	err = db.OperationEvents().InsertEvent(internal.OperationEvent{
+		ID:          "event-id",
+		OperationID: "operation-id",
+		InstanceID:  "instance-id",
+		Actor:       "kyma-environment-broker",
+		StepName:    "Check_Runtime_Status",
+		OldState:    domain.InProgress,
+		NewState:    domain.Succeeded,
+		CreatedAt:   time.Now(),
+	})
+	require.NoError(t, err)
+
+	router := mux.NewRouter()
+	NewEventsHandler(db.Operations(), db.OperationEvents()).AttachRoutes(router)
+
+	t.Run("should return events of the operation", func(t *testing.T) {
+		// given
+		req, err := http.NewRequest(http.MethodGet, "/operations/operation-id/events", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+		var response EventResponseList
+		err = json.Unmarshal(rr.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, 1, response.Count)
+		assert.Equal(t, "Check_Runtime_Status", response.Data[0].StepName)
+		assert.Equal(t, string(domain.InProgress), response.Data[0].OldState)
+		assert.Equal(t, string(domain.Succeeded), response.Data[0].NewState)
+	})
+
+	t.Run("should return not found for unknown operation", func(t *testing.T) {
+		// given
+		req, err := http.NewRequest(http.MethodGet, "/operations/unknown/events", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
@@ -0,0 +1,66 @@
+package cluster
+
+import (
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/orchestration"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession/dbmodel"
+
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// upgradeClusterFactory creates the cluster (Gardener shoot) upgrade operations of the orchestration,
+// which are stored as UpgradeClusterOperation, separately from the Kyma upgrade operations
+type upgradeClusterFactory struct {
+	operationStorage storage.Operations
+}
+
+// NewUpgradeClusterManager creates the orchestration manager which upgrades the clusters of the runtimes with the clusterUpgradeExecutor
+func NewUpgradeClusterManager(orchestrationStorage storage.Orchestrations, operationStorage storage.Operations,
+	clusterUpgradeExecutor process.Executor, resolver orchestration.RuntimeResolver,
+	pollingInterval time.Duration, log logrus.FieldLogger) process.Executor {
+	return orchestration.NewManager(orchestrationStorage, operationStorage, &upgradeClusterFactory{operationStorage: operationStorage},
+		clusterUpgradeExecutor, resolver, pollingInterval, log)
+}
+
+func (f *upgradeClusterFactory) InsertOperation(_ internal.Orchestration, operation internal.RuntimeOperation, planID string) error {
+	return f.operationStorage.InsertUpgradeClusterOperation(internal.UpgradeClusterOperation{
+		RuntimeOperation: operation,
+		PlanID:           planID,
+	})
+}
+
+func (f *upgradeClusterFactory) ListOperationsInProgress(orchestrationID string) ([]internal.RuntimeOperation, error) {
+	operations, err := f.operationStorage.GetOperationsInProgressByType(dbmodel.OperationTypeUpgradeCluster)
+	if err != nil {
+		return nil, errors.Wrap(err, "while getting in progress upgrade cluster operations")
+	}
+
+	var result []internal.RuntimeOperation
+	for _, op := range operations {
+		if op.OrchestrationID != orchestrationID {
+			continue
+		}
+		upgradeOperation, err := f.operationStorage.GetUpgradeClusterOperationByID(op.ID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "while getting upgrade cluster operation %s", op.ID)
+		}
+		result = append(result, upgradeOperation.RuntimeOperation)
+	}
+
+	return result, nil
+}
+
+func (f *upgradeClusterFactory) SkipOperation(operationID, reason string) error {
+	_, err := storage.UpdateWithRetryUpgradeClusterOperation(f.operationStorage, operationID, func(upgradeOperation *internal.UpgradeClusterOperation) {
+		upgradeOperation.State = domain.Failed
+		upgradeOperation.Description = "operation skipped, " + reason
+		upgradeOperation.ResultReason = reason
+	})
+	return err
+}
@@ -0,0 +1,121 @@
+package cluster_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/orchestration/automock"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/orchestration/cluster"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession/dbmodel"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+const poolingInterval = 20 * time.Millisecond
+
+func TestUpgradeClusterManager_Execute(t *testing.T) {
+	t.Run("Empty", func(t *testing.T) {
+		// given
+		store := storage.NewMemoryStorage()
+
+		resolver := &automock.RuntimeResolver{}
+		defer resolver.AssertExpectations(t)
+
+		resolver.On("Resolve", internal.TargetSpec{
+			Include: nil,
+			Exclude: nil,
+		}).Return([]internal.Runtime{}, nil)
+
+		id := "id"
+		err := store.Orchestrations().Insert(internal.Orchestration{OrchestrationID: id, State: internal.Pending})
+		require.NoError(t, err)
+
+		svc := cluster.NewUpgradeClusterManager(store.Orchestrations(), store.Operations(), nil, resolver, poolingInterval, logrus.New())
+
+		// when
+		_, err = svc.Execute(id)
+		require.NoError(t, err)
+
+		o, err := store.Orchestrations().GetByID(id)
+		require.NoError(t, err)
+
+		assert.Equal(t, internal.Succeeded, o.State)
+	})
+
+	t.Run("Pending", func(t *testing.T) {
+		// given
+		store := storage.NewMemoryStorage()
+
+		err := store.Operations().InsertProvisioningOperation(internal.ProvisioningOperation{
+			Operation:              internal.Operation{ID: "provisioning-id", InstanceID: "instance-id"},
+			ProvisioningParameters: `{"plan_id": "plan-id"}`,
+		})
+		require.NoError(t, err)
+
+		resolver := &automock.RuntimeResolver{}
+		defer resolver.AssertExpectations(t)
+
+		resolver.On("Resolve", internal.TargetSpec{}).Return([]internal.Runtime{{
+			InstanceID:      "instance-id",
+			RuntimeID:       "runtime-id",
+			GlobalAccountID: "ga-id",
+			SubAccountID:    "sa-id",
+			ShootName:       "shoot",
+		}}, nil).Once()
+
+		id := "id"
+		err = store.Orchestrations().Insert(internal.Orchestration{
+			OrchestrationID: id,
+			State:           internal.Pending,
+			Parameters: internal.OrchestrationParameters{
+				Strategy: internal.StrategySpec{
+					Type:     internal.ParallelStrategy,
+					Schedule: internal.Immediate,
+					Parallel: internal.ParallelStrategySpec{Workers: 1},
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		svc := cluster.NewUpgradeClusterManager(store.Orchestrations(), store.Operations(), &testExecutor{operations: store.Operations()}, resolver, poolingInterval, logrus.New())
+
+		// when
+		_, err = svc.Execute(id)
+		require.NoError(t, err)
+
+		// then
+		o, err := store.Orchestrations().GetByID(id)
+		require.NoError(t, err)
+		assert.Equal(t, internal.Succeeded, o.State)
+
+		ops, _, total, err := store.Operations().ListUpgradeClusterOperationsByOrchestrationID(id, 10, 1)
+		require.NoError(t, err)
+		require.Equal(t, 1, total)
+		assert.Equal(t, "runtime-id", ops[0].RuntimeID)
+		assert.Equal(t, "plan-id", ops[0].PlanID)
+		assert.Equal(t, domain.Succeeded, ops[0].State)
+
+		kymaOps, _, _, err := store.Operations().ListUpgradeKymaOperationsByOrchestrationID(id, dbmodel.OperationFilter{}, 10, 1)
+		require.NoError(t, err)
+		assert.Empty(t, kymaOps)
+	})
+}
+
+type testExecutor struct {
+	operations storage.Operations
+}
+
+func (t *testExecutor) Execute(opID string) (time.Duration, error) {
+	op, err := t.operations.GetUpgradeClusterOperationByID(opID)
+	if err != nil {
+		return 0, err
+	}
+	op.State = domain.Succeeded
+	_, err = t.operations.UpdateUpgradeClusterOperation(*op)
+	return 0, err
+}
@@ -0,0 +1,69 @@
+package orchestration
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Config holds the configuration of the orchestration processing
+type Config struct {
+	// ProvisionerMutationsPerMinute limits the number of Provisioner mutations triggered per minute by a single
+	// orchestration, shared by all its workers. The value 0 disables the limit.
+	ProvisionerMutationsPerMinute int `envconfig:"default=30"`
+
+	// ShootCacheTTL defines how long the listing of the Gardener shoots is reused by the runtime resolver.
+	// The value 0 disables the cache.
+	ShootCacheTTL time.Duration `envconfig:"default=5m"`
+
+	// MinInstallationTimeout and MaxInstallationTimeout limit the installation timeout requested by the orchestration,
+	// which overrides the default installation timeout of the Provisioner. The value 0 disables the limit.
+	MinInstallationTimeout time.Duration `envconfig:"default=30m"`
+	MaxInstallationTimeout time.Duration `envconfig:"default=6h"`
+}
+
+// ValidateInstallationTimeout checks that the installation timeout requested by the orchestration is within the limits
+func (c Config) ValidateInstallationTimeout(timeout string) error {
+	d, err := ParseInstallationTimeout(timeout)
+	if err != nil || d == 0 {
+		return err
+	}
+	if c.MinInstallationTimeout > 0 && d < c.MinInstallationTimeout {
+		return errors.Errorf("installation timeout %q must not be lower than %s", timeout, c.MinInstallationTimeout)
+	}
+	if c.MaxInstallationTimeout > 0 && d > c.MaxInstallationTimeout {
+		return errors.Errorf("installation timeout %q must not be greater than %s", timeout, c.MaxInstallationTimeout)
+	}
+	return nil
+}
+
+// ParseSkipUpgradedWithin parses the cool-down period of the orchestration, the empty value means no runtime is skipped
+func ParseSkipUpgradedWithin(period string) (time.Duration, error) {
+	if period == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(period)
+	if err != nil {
+		return 0, errors.Wrapf(err, "while parsing skip upgraded within period %q", period)
+	}
+	if d < 0 {
+		return 0, errors.Errorf("skip upgraded within period %q must not be negative", period)
+	}
+	return d, nil
+}
+
+// ParseInstallationTimeout parses the installation timeout of the orchestration, the empty value means
+// the default installation timeout of the Provisioner
+func ParseInstallationTimeout(timeout string) (time.Duration, error) {
+	if timeout == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(timeout)
+	if err != nil {
+		return 0, errors.Wrapf(err, "while parsing installation timeout %q", timeout)
+	}
+	if d <= 0 {
+		return 0, errors.Errorf("installation timeout %q must be positive", timeout)
+	}
+	return d, nil
+}
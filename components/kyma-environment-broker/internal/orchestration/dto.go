@@ -1,6 +1,7 @@
 package orchestration
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/kyma-project/control-plane/components/provisioner/pkg/gqlschema"
@@ -10,11 +11,14 @@ import (
 
 type StatusResponse struct {
 	OrchestrationID string                           `json:"orchestrationID"`
+	Type            internal.OrchestrationType       `json:"type"`
 	State           string                           `json:"state"`
 	Description     string                           `json:"description"`
 	CreatedAt       time.Time                        `json:"createdAt"`
 	UpdatedAt       time.Time                        `json:"updatedAt"`
 	Parameters      internal.OrchestrationParameters `json:"parameters"`
+	// Runtimes holds the runtimes targeted by the orchestration, available once the orchestration is started
+	Runtimes []internal.Runtime `json:"runtimes,omitempty"`
 }
 
 type OperationResponse struct {
@@ -31,12 +35,17 @@ type OperationResponse struct {
 	MaintenanceWindowEnd   time.Time `json:"maintenanceWindowEnd"`
 	State                  string    `json:"state"`
 	Description            string    `json:"description"`
+	ResultReason           string    `json:"resultReason"`
+	RetryCount             int       `json:"retryCount"`
+	LastRetryAt            time.Time `json:"lastRetryAt"`
 }
 
 type OperationResponseList struct {
 	Data       []OperationResponse `json:"data"`
 	Count      int                 `json:"count"`
 	TotalCount int                 `json:"totalCount"`
+	// NextCursor points to the next page of the operations, it is empty if the page is the last one
+	NextCursor string `json:"nextCursor,omitempty"`
 }
 
 type OperationDetailResponse struct {
@@ -44,14 +53,119 @@ type OperationDetailResponse struct {
 
 	KymaConfig    gqlschema.KymaConfigInput     `json:"kymaConfig"`
 	ClusterConfig gqlschema.GardenerConfigInput `json:"clusterConfig"`
+	// Verification is the result of the post-upgrade verification of the runtime
+	Verification *internal.UpgradeVerification `json:"verification,omitempty"`
 }
 
 type StatusResponseList struct {
 	Data       []StatusResponse `json:"data"`
 	Count      int              `json:"count"`
 	TotalCount int              `json:"totalCount"`
+	// NextCursor points to the next page of the orchestrations, it is empty if the page is the last one
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// RuntimeResponseList holds the runtimes targeted by the orchestration
+type RuntimeResponseList struct {
+	Data       []internal.Runtime `json:"data"`
+	Count      int                `json:"count"`
+	TotalCount int                `json:"totalCount"`
+	// Preview is true if the orchestration has not resolved its targets yet and the runtimes were resolved
+	// for the request, the runtimes resolved when the orchestration is started can differ
+	Preview bool `json:"preview"`
 }
 
 type UpgradeResponse struct {
 	OrchestrationID string `json:"orchestrationID"`
 }
+
+// RetryRequest narrows down the operations retried by the orchestration retry, the request body is optional
+type RetryRequest struct {
+	// Operations holds the IDs of the operations to retry, all failed and canceled operations are retried if empty
+	Operations []string `json:"operations,omitempty"`
+	// FailedOnly skips the canceled operations
+	FailedOnly bool `json:"failedOnly,omitempty"`
+	// DryRun returns the operations which would be retried without scheduling them again
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+type RetryResponse struct {
+	OrchestrationID string `json:"orchestrationID"`
+	// RetryOperations holds the IDs of the failed and canceled operations scheduled again
+	RetryOperations []string `json:"retryOperations"`
+	DryRun          bool     `json:"dryRun,omitempty"`
+}
+
+// SimulationResponse is the estimate of the orchestration duration based on the durations of the past Kyma upgrades
+type SimulationResponse struct {
+	// Runtimes is the number of the runtimes targeted by the orchestration
+	Runtimes int `json:"runtimes"`
+	// RuntimesWithHistory is the number of the targeted runtimes with at least one succeeded upgrade,
+	// the median upgrade duration of these runtimes is assumed for the others
+	RuntimesWithHistory   int       `json:"runtimesWithHistory"`
+	MedianUpgradeDuration string    `json:"medianUpgradeDuration"`
+	Workers               int       `json:"workers"`
+	EstimatedDuration     string    `json:"estimatedDuration"`
+	EstimatedEnd          time.Time `json:"estimatedEnd"`
+	// WorkerEstimates holds the estimated durations of the orchestration for other numbers of the parallel workers
+	WorkerEstimates []WorkerEstimate `json:"workerEstimates"`
+}
+
+// WorkerEstimate is the estimated duration of the orchestration executed by the given number of the parallel workers
+type WorkerEstimate struct {
+	Workers           int    `json:"workers"`
+	EstimatedDuration string `json:"estimatedDuration"`
+}
+
+// ReportResponse is the complete result of the orchestration, generated once for all its operations
+type ReportResponse struct {
+	OrchestrationID string              `json:"orchestrationID"`
+	State           string              `json:"state"`
+	Description     string              `json:"description"`
+	CreatedAt       time.Time           `json:"createdAt"`
+	UpdatedAt       time.Time           `json:"updatedAt"`
+	Duration        string              `json:"duration"`
+	DryRun          bool                `json:"dryRun"`
+	Targets         internal.TargetSpec `json:"targets"`
+	// Summary holds the number of the operations per state
+	Summary  map[string]int  `json:"summary"`
+	Runtimes []RuntimeReport `json:"runtimes"`
+}
+
+// RuntimeReport is the result of the orchestration for a single runtime
+type RuntimeReport struct {
+	RuntimeID       string    `json:"runtimeID"`
+	GlobalAccountID string    `json:"globalAccountID"`
+	SubAccountID    string    `json:"subAccountID"`
+	ShootName       string    `json:"shootName"`
+	ServicePlanName string    `json:"servicePlanName"`
+	OperationID     string    `json:"operationID"`
+	State           string    `json:"state"`
+	ResultReason    string    `json:"resultReason"`
+	Error           string    `json:"error,omitempty"`
+	KymaVersion     string    `json:"kymaVersion"`
+	RetryCount      int       `json:"retryCount"`
+	StartedAt       time.Time `json:"startedAt"`
+	FinishedAt      time.Time `json:"finishedAt"`
+	Duration        string    `json:"duration"`
+}
+
+// StatsResponse holds the number of the runtime operations of the orchestration per state
+type StatsResponse struct {
+	OrchestrationID string         `json:"orchestrationID"`
+	State           string         `json:"state"`
+	Operations      map[string]int `json:"operations"`
+	Total           int            `json:"total"`
+	// Progress is the percentage of the operations which are neither pending nor in progress
+	Progress int `json:"progress"`
+}
+
+// StatsPath returns the path of the orchestration stats endpoint
+func StatsPath(orchestrationID string) string {
+	return fmt.Sprintf("/orchestrations/%s/stats", orchestrationID)
+}
+
+// ReportPath returns the path of the orchestration report endpoint
+func ReportPath(orchestrationID string) string {
+	return fmt.Sprintf("/orchestrations/%s/report", orchestrationID)
+}
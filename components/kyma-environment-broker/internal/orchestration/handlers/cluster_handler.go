@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/orchestration"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// clusterHandler creates the orchestrations upgrading the clusters of the runtimes to the Kubernetes version
+// configured in the broker, the orchestrations are read, canceled and retried with the routes of the kymaHandler
+type clusterHandler struct {
+	orchestrations storage.Orchestrations
+
+	queue *process.Queue
+	log   logrus.FieldLogger
+}
+
+func NewClusterOrchestrationHandler(orchestrations storage.Orchestrations, q *process.Queue, log logrus.FieldLogger) *clusterHandler {
+	return &clusterHandler{
+		orchestrations: orchestrations,
+		queue:          q,
+		log:            log,
+	}
+}
+
+func (h *clusterHandler) AttachRoutes(router *mux.Router) {
+	router.HandleFunc("/upgrade/cluster", h.createOrchestration).Methods(http.MethodPost)
+}
+
+func (h *clusterHandler) createOrchestration(w http.ResponseWriter, r *http.Request) {
+	params, err := h.orchestrationParameters(r)
+	if err != nil {
+		h.log.Errorf("while reading orchestration parameters: %v", err)
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	now := time.Now()
+	o := internal.Orchestration{
+		OrchestrationID: uuid.New().String(),
+		Type:            internal.UpgradeClusterOrchestration,
+		State:           internal.Pending,
+		Description:     "started processing of cluster upgrade",
+		Parameters:      params,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	err = h.orchestrations.Insert(o)
+	if err != nil {
+		h.log.Errorf("while inserting orchestration to storage: %v", err)
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrapf(err, "while inserting orchestration to storage"))
+		return
+	}
+
+	h.queue.Add(o.OrchestrationID)
+
+	response := orchestration.UpgradeResponse{OrchestrationID: o.OrchestrationID}
+
+	httputil.WriteResponse(w, http.StatusAccepted, response)
+}
+
+// orchestrationParameters decodes and validates the orchestration parameters from the request body,
+// the parameters of the Kyma upgrade are rejected, because they have no effect on the cluster upgrade
+func (h *clusterHandler) orchestrationParameters(r *http.Request) (internal.OrchestrationParameters, error) {
+	params := internal.OrchestrationParameters{}
+
+	if r.Body != nil {
+		err := json.NewDecoder(r.Body).Decode(&params)
+		if err != nil {
+			return params, errors.Wrapf(err, "while decoding request body")
+		}
+	}
+	err := validateTarget(params.Targets)
+	if err != nil {
+		return params, errors.Wrapf(err, "while validating target")
+	}
+	err = validateStrategy(params.Strategy)
+	if err != nil {
+		return params, errors.Wrapf(err, "while validating strategy")
+	}
+	switch {
+	case params.SkipUpgradedWithin != "":
+		return params, errors.New("skipUpgradedWithin is supported only for the Kyma upgrade")
+	case params.AllowDowngrade:
+		return params, errors.New("allowDowngrade is supported only for the Kyma upgrade")
+	case params.InstallationTimeout != "":
+		return params, errors.New("installationTimeout is supported only for the Kyma upgrade")
+	}
+
+	defaultOrchestrationStrategy(&params.Strategy)
+
+	return params, nil
+}
@@ -0,0 +1,177 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/event"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/orchestration"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/orchestration/handlers"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+
+	"github.com/gorilla/mux"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClusterOrchestrationHandler_(t *testing.T) {
+	fixID := "cluster-id-1"
+
+	t.Run("upgrade", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		logs := logrus.New()
+		q := process.NewQueue(&testExecutor{}, logs)
+		router := mux.NewRouter()
+		handlers.NewOrchestrationHandler(db, q, q, nil, event.NewPubSub(), 100, orchestration.Config{}, logs).AttachRoutes(router)
+
+		params := internal.OrchestrationParameters{
+			Targets: internal.TargetSpec{
+				Include: []internal.RuntimeTarget{{RuntimeID: "test"}},
+			},
+		}
+		p, err := json.Marshal(&params)
+		require.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPost, "/upgrade/cluster", bytes.NewBuffer(p))
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		require.Equal(t, http.StatusAccepted, rr.Code)
+
+		var out orchestration.UpgradeResponse
+		err = json.Unmarshal(rr.Body.Bytes(), &out)
+		require.NoError(t, err)
+
+		o, err := db.Orchestrations().GetByID(out.OrchestrationID)
+		require.NoError(t, err)
+		assert.Equal(t, internal.UpgradeClusterOrchestration, o.Type)
+		assert.Equal(t, internal.ParallelStrategy, o.Parameters.Strategy.Type)
+	})
+
+	t.Run("upgrade with the Kyma upgrade parameters", func(t *testing.T) {
+		for tN, params := range map[string]internal.OrchestrationParameters{
+			"skip upgraded within": {SkipUpgradedWithin: "24h"},
+			"allow downgrade":      {AllowDowngrade: true},
+			"installation timeout": {InstallationTimeout: "2h"},
+		} {
+			t.Run(tN, func(t *testing.T) {
+				// given
+				db := storage.NewMemoryStorage()
+				logs := logrus.New()
+				clusterHandler := handlers.NewClusterOrchestrationHandler(db.Orchestrations(), process.NewQueue(&testExecutor{}, logs), logs)
+				router := mux.NewRouter()
+				clusterHandler.AttachRoutes(router)
+
+				params.Targets = internal.TargetSpec{Include: []internal.RuntimeTarget{{RuntimeID: "test"}}}
+				p, err := json.Marshal(&params)
+				require.NoError(t, err)
+
+				req, err := http.NewRequest(http.MethodPost, "/upgrade/cluster", bytes.NewBuffer(p))
+				require.NoError(t, err)
+				rr := httptest.NewRecorder()
+
+				// when
+				router.ServeHTTP(rr, req)
+
+				// then
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+			})
+		}
+	})
+
+	t.Run("cancel and retry", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		err := db.Orchestrations().Insert(internal.Orchestration{
+			OrchestrationID: fixID,
+			Type:            internal.UpgradeClusterOrchestration,
+			State:           internal.InProgress,
+		})
+		require.NoError(t, err)
+		for _, op := range []internal.Operation{
+			{ID: "in-progress-id", OrchestrationID: fixID, State: domain.InProgress},
+			{ID: "failed-id", OrchestrationID: fixID, State: domain.Failed},
+		} {
+			err = db.Operations().InsertUpgradeClusterOperation(internal.UpgradeClusterOperation{
+				RuntimeOperation: internal.RuntimeOperation{Operation: op},
+				PlanID:           broker.AzurePlanID,
+			})
+			require.NoError(t, err)
+		}
+
+		logs := logrus.New()
+		q := process.NewQueue(&testExecutor{}, logs)
+		router := mux.NewRouter()
+		handlers.NewOrchestrationHandler(db, q, q, nil, event.NewPubSub(), 100, orchestration.Config{}, logs).AttachRoutes(router)
+
+		req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/orchestrations/%s/cancel", fixID), nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+		op, err := db.Operations().GetUpgradeClusterOperationByID("in-progress-id")
+		require.NoError(t, err)
+		assert.Equal(t, internal.OperationCanceled, op.State)
+
+		// given
+		o, err := db.Orchestrations().GetByID(fixID)
+		require.NoError(t, err)
+		o.State = internal.Canceled
+		err = db.Orchestrations().Update(*o)
+		require.NoError(t, err)
+
+		req, err = http.NewRequest(http.MethodPost, fmt.Sprintf("/orchestrations/%s/retry", fixID), nil)
+		require.NoError(t, err)
+		rr = httptest.NewRecorder()
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		require.Equal(t, http.StatusAccepted, rr.Code)
+
+		var out orchestration.RetryResponse
+		err = json.Unmarshal(rr.Body.Bytes(), &out)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"in-progress-id", "failed-id"}, out.RetryOperations)
+
+		for _, id := range out.RetryOperations {
+			op, err := db.Operations().GetUpgradeClusterOperationByID(id)
+			require.NoError(t, err)
+			assert.Equal(t, domain.InProgress, op.State)
+			assert.Equal(t, 1, op.RetryCount)
+		}
+
+		// when
+		req, err = http.NewRequest(http.MethodGet, fmt.Sprintf("/orchestrations/%s/operations", fixID), nil)
+		require.NoError(t, err)
+		rr = httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var operations orchestration.OperationResponseList
+		err = json.Unmarshal(rr.Body.Bytes(), &operations)
+		require.NoError(t, err)
+		assert.Equal(t, 2, operations.TotalCount)
+	})
+}
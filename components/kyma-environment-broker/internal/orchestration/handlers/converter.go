@@ -13,11 +13,13 @@ type Converter struct{}
 func (*Converter) OrchestrationToDTO(o *internal.Orchestration) (*orchestration.StatusResponse, error) {
 	return &orchestration.StatusResponse{
 		OrchestrationID: o.OrchestrationID,
+		Type:            o.Type,
 		State:           o.State,
 		Description:     o.Description,
 		CreatedAt:       o.CreatedAt,
 		UpdatedAt:       o.UpdatedAt,
 		Parameters:      o.Parameters,
+		Runtimes:        o.Runtimes,
 	}, nil
 }
 
@@ -58,6 +60,9 @@ func (c *Converter) UpgradeKymaOperationToDTO(op internal.UpgradeKymaOperation)
 		MaintenanceWindowEnd:   op.MaintenanceWindowEnd,
 		State:                  string(op.Operation.State),
 		Description:            op.Operation.Description,
+		ResultReason:           op.ResultReason,
+		RetryCount:             op.RetryCount,
+		LastRetryAt:            op.LastRetryAt,
 	}, nil
 }
 
@@ -88,5 +93,61 @@ func (c *Converter) UpgradeKymaOperationToDetailDTO(op internal.UpgradeKymaOpera
 		OperationResponse: resp,
 		KymaConfig:        kymaConfig,
 		ClusterConfig:     clusterConfig,
+		Verification:      op.Verification,
+	}, nil
+}
+
+func (c *Converter) UpgradeClusterOperationToDTO(op internal.UpgradeClusterOperation) (orchestration.OperationResponse, error) {
+	plan, ok := broker.Plans[op.PlanID]
+	if !ok {
+		return orchestration.OperationResponse{}, errors.Errorf("plan with ID %s not exist in the broker's plans definitions", op.PlanID)
+	}
+	return orchestration.OperationResponse{
+		OperationID:            op.Operation.ID,
+		RuntimeID:              op.RuntimeID,
+		GlobalAccountID:        op.GlobalAccountID,
+		SubAccountID:           op.SubAccountID,
+		OrchestrationID:        op.OrchestrationID,
+		ServicePlanID:          op.PlanID,
+		ServicePlanName:        plan.PlanDefinition.Name,
+		DryRun:                 op.DryRun,
+		ShootName:              op.ShootName,
+		MaintenanceWindowBegin: op.MaintenanceWindowBegin,
+		MaintenanceWindowEnd:   op.MaintenanceWindowEnd,
+		State:                  string(op.Operation.State),
+		Description:            op.Operation.Description,
+		ResultReason:           op.ResultReason,
+		RetryCount:             op.RetryCount,
+		LastRetryAt:            op.LastRetryAt,
+	}, nil
+}
+
+func (c *Converter) UpgradeClusterOperationListToDTO(ops []internal.UpgradeClusterOperation, count, totalCount int) (orchestration.OperationResponseList, error) {
+	data := make([]orchestration.OperationResponse, 0)
+
+	for _, op := range ops {
+		o, err := c.UpgradeClusterOperationToDTO(op)
+		if err != nil {
+			return orchestration.OperationResponseList{}, errors.Wrap(err, "while converting operation to DTO")
+		}
+		data = append(data, o)
+	}
+
+	return orchestration.OperationResponseList{
+		Data:       data,
+		Count:      count,
+		TotalCount: totalCount,
+	}, nil
+}
+
+// UpgradeClusterOperationToDetailDTO converts the cluster upgrade operation, which does not change the Kyma configuration
+func (c *Converter) UpgradeClusterOperationToDetailDTO(op internal.UpgradeClusterOperation, clusterConfig gqlschema.GardenerConfigInput) (orchestration.OperationDetailResponse, error) {
+	resp, err := c.UpgradeClusterOperationToDTO(op)
+	if err != nil {
+		return orchestration.OperationDetailResponse{}, errors.Wrap(err, "while converting operation to DTO")
+	}
+	return orchestration.OperationDetailResponse{
+		OperationResponse: resp,
+		ClusterConfig:     clusterConfig,
 	}, nil
 }
@@ -50,6 +50,8 @@ func TestConverter_UpgradeKymaOperationToDTO(t *testing.T) {
 
 	id := "id"
 	givenOperation := fixOperation(id)
+	givenOperation.RetryCount = 2
+	givenOperation.ResultReason = "upgrade failed"
 
 	// when
 	resp, err := c.UpgradeKymaOperationToDTO(givenOperation)
@@ -57,6 +59,8 @@ func TestConverter_UpgradeKymaOperationToDTO(t *testing.T) {
 	// then
 	require.NoError(t, err)
 	assert.Equal(t, id, resp.OrchestrationID)
+	assert.Equal(t, 2, resp.RetryCount)
+	assert.Equal(t, "upgrade failed", resp.ResultReason)
 }
 
 func TestConverter_UpgradeKymaOperationListToDTO(t *testing.T) {
@@ -86,6 +90,7 @@ func TestConverter_UpgradeKymaOperationToDetailDTO(t *testing.T) {
 
 	id := "id"
 	givenOperation := fixOperation(id)
+	givenOperation.Verification = &internal.UpgradeVerification{State: internal.VerificationPassed}
 	kymaConfig := gqlschema.KymaConfigInput{Version: id}
 	clusterConfig := gqlschema.GardenerConfigInput{KubernetesVersion: id}
 
@@ -97,6 +102,7 @@ func TestConverter_UpgradeKymaOperationToDetailDTO(t *testing.T) {
 	assert.Equal(t, id, resp.OrchestrationID)
 	assert.Equal(t, id, resp.KymaConfig.Version)
 	assert.Equal(t, id, resp.ClusterConfig.KubernetesVersion)
+	assert.Equal(t, internal.VerificationPassed, resp.Verification.State)
 }
 
 func fixOperation(id string) internal.UpgradeKymaOperation {
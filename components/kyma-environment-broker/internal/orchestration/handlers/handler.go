@@ -2,6 +2,8 @@ package handlers
 
 import (
 	"github.com/gorilla/mux"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/event"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/orchestration"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
 	"github.com/sirupsen/logrus"
@@ -15,10 +17,11 @@ type handler struct {
 	handlers []Handler
 }
 
-func NewOrchestrationHandler(db storage.BrokerStorage, kymaQueue *process.Queue, defaultMaxPage int, log logrus.FieldLogger) Handler {
+func NewOrchestrationHandler(db storage.BrokerStorage, kymaQueue, clusterQueue *process.Queue, resolver orchestration.RuntimeResolver, pub event.Publisher, defaultMaxPage int, cfg orchestration.Config, log logrus.FieldLogger) Handler {
 	return &handler{
 		handlers: []Handler{
-			NewKymaOrchestrationHandler(db.Operations(), db.Orchestrations(), db.RuntimeStates(), defaultMaxPage, kymaQueue, log),
+			NewKymaOrchestrationHandler(db.Operations(), db.Orchestrations(), db.RuntimeStates(), defaultMaxPage, cfg, kymaQueue, clusterQueue, resolver, pub, log),
+			NewClusterOrchestrationHandler(db.Orchestrations(), clusterQueue, log),
 		},
 	}
 }
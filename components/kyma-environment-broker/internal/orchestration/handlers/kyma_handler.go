@@ -1,7 +1,10 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"time"
 
@@ -10,46 +13,77 @@ import (
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/httputil"
 
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession/dbmodel"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/event"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/orchestration"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	// defaultCanaryPercentage is the percentage of runtimes upgraded in the canary batch if the canary size is not specified
+	defaultCanaryPercentage = 10
+	// maxParallelWorkers limits the number of upgrade operations executed concurrently by a single orchestration,
+	// so a mass upgrade cannot saturate the Provisioner
+	maxParallelWorkers = 50
+)
+
+// operationFields are the fields of the operation which can be selected with the fields query parameter
+var operationFields = httputil.FieldNames(orchestration.OperationResponse{})
+
 type kymaHandler struct {
 	orchestrations storage.Orchestrations
 	operations     storage.Operations
 	runtimeStates  storage.RuntimeStates
 
-	queue *process.Queue
-	conv  Converter
-	log   logrus.FieldLogger
+	queue        *process.Queue
+	clusterQueue *process.Queue
+	simulator    *orchestration.Simulator
+	publisher    event.Publisher
+	conv         Converter
+	log          logrus.FieldLogger
 
 	defaultMaxPage int
+	cfg            orchestration.Config
 }
 
-func NewKymaOrchestrationHandler(operations storage.Operations, orchestrations storage.Orchestrations, runtimeStates storage.RuntimeStates, defaultMaxPage int, q *process.Queue, log logrus.FieldLogger) *kymaHandler {
+// NewKymaOrchestrationHandler creates the handler of the Kyma upgrade orchestrations and of the routes common to all orchestrations,
+// the canceled and retried cluster upgrade orchestrations are added to the clusterQueue
+func NewKymaOrchestrationHandler(operations storage.Operations, orchestrations storage.Orchestrations, runtimeStates storage.RuntimeStates, defaultMaxPage int, cfg orchestration.Config, q, clusterQueue *process.Queue, resolver orchestration.RuntimeResolver, pub event.Publisher, log logrus.FieldLogger) *kymaHandler {
 	return &kymaHandler{
 		operations:     operations,
 		orchestrations: orchestrations,
 		runtimeStates:  runtimeStates,
 		queue:          q,
+		clusterQueue:   clusterQueue,
+		simulator:      orchestration.NewSimulator(resolver, operations),
+		publisher:      pub,
 		log:            log,
 		conv:           Converter{},
 		defaultMaxPage: defaultMaxPage,
+		cfg:            cfg,
 	}
 }
 
 func (h *kymaHandler) AttachRoutes(router *mux.Router) {
 	router.HandleFunc("/upgrade/kyma", h.createOrchestration).Methods(http.MethodPost)
+	router.HandleFunc("/upgrade/kyma/simulate", h.simulateOrchestration).Methods(http.MethodPost)
 
 	router.HandleFunc("/orchestrations", h.listOrchestration).Methods(http.MethodGet)
 	router.HandleFunc("/orchestrations/{orchestration_id}", h.getOrchestration).Methods(http.MethodGet)
+	router.HandleFunc("/orchestrations/{orchestration_id}/cancel", h.cancelOrchestration).Methods(http.MethodPut)
+	router.HandleFunc("/orchestrations/{orchestration_id}/retry", h.retryOrchestration).Methods(http.MethodPost)
+	router.HandleFunc("/orchestrations/{orchestration_id}/report", h.getReport).Methods(http.MethodGet)
+	router.HandleFunc("/orchestrations/{orchestration_id}/stats", h.getStats).Methods(http.MethodGet)
+	router.HandleFunc("/orchestrations/{orchestration_id}/runtimes", h.listRuntimes).Methods(http.MethodGet)
 	router.HandleFunc("/orchestrations/{orchestration_id}/operations", h.listOperations).Methods(http.MethodGet)
 	router.HandleFunc("/orchestrations/{orchestration_id}/operations/{operation_id}", h.getOperation).Methods(http.MethodGet)
 }
@@ -60,10 +94,56 @@ func (h *kymaHandler) getOrchestration(w http.ResponseWriter, r *http.Request) {
 	o, err := h.orchestrations.GetByID(orchestrationID)
 	if err != nil {
 		h.log.Errorf("while getting orchestration %s: %v", orchestrationID, err)
-		httputil.WriteErrorResponse(w, h.resolveErrorStatus(err), errors.Wrapf(err, "while getting orchestration %s", orchestrationID))
+		httputil.WriteErrorResponse(w, resolveErrorStatus(err), errors.Wrapf(err, "while getting orchestration %s", orchestrationID))
+		return
+	}
+
+	response, err := h.conv.OrchestrationToDTO(o)
+	if err != nil {
+		h.log.Errorf("while converting orchestration: %v", err)
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrapf(err, "while converting orchestration"))
+		return
+	}
+
+	httputil.WriteResponse(w, http.StatusOK, response)
+}
+
+func (h *kymaHandler) cancelOrchestration(w http.ResponseWriter, r *http.Request) {
+	orchestrationID := mux.Vars(r)["orchestration_id"]
+
+	o, err := h.orchestrations.GetByID(orchestrationID)
+	if err != nil {
+		h.log.Errorf("while getting orchestration %s: %v", orchestrationID, err)
+		httputil.WriteErrorResponse(w, resolveErrorStatus(err), errors.Wrapf(err, "while getting orchestration %s", orchestrationID))
+		return
+	}
+	if o.IsFinished() {
+		httputil.WriteErrorResponse(w, http.StatusConflict, errors.Errorf("orchestration %s is already finished with state %s", orchestrationID, o.State))
+		return
+	}
+
+	if o.State != internal.Canceling {
+		o.State = internal.Canceling
+		o.Description = "canceling orchestration"
+		o.UpdatedAt = time.Now()
+		err = h.orchestrations.Update(*o)
+		if err != nil {
+			h.log.Errorf("while updating orchestration %s: %v", orchestrationID, err)
+			httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrapf(err, "while updating orchestration %s", orchestrationID))
+			return
+		}
+	}
+
+	err = h.cancelOperations(r.Context(), o, httputil.User(r))
+	if err != nil {
+		h.log.Errorf("while canceling operations of orchestration %s: %v", orchestrationID, err)
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrapf(err, "while canceling operations of orchestration %s", orchestrationID))
 		return
 	}
 
+	// the orchestration manager marks the orchestration as canceled once all its operations are finished
+	h.queueFor(o).Add(orchestrationID)
+
 	response, err := h.conv.OrchestrationToDTO(o)
 	if err != nil {
 		h.log.Errorf("while converting orchestration: %v", err)
@@ -74,6 +154,403 @@ func (h *kymaHandler) getOrchestration(w http.ResponseWriter, r *http.Request) {
 	httputil.WriteResponse(w, http.StatusOK, response)
 }
 
+// queueFor returns the queue processing the orchestrations of the type of the given orchestration
+func (h *kymaHandler) queueFor(o *internal.Orchestration) *process.Queue {
+	if o.IsUpgradeCluster() {
+		return h.clusterQueue
+	}
+	return h.queue
+}
+
+// cancelOperations moves the in progress upgrade operations of the orchestration to the canceled terminal state,
+// the operations still waiting in the strategy queue are skipped by the upgrade process, which does not execute finished operations.
+// The state transitions are recorded with the user who canceled the orchestration as the actor.
+func (h *kymaHandler) cancelOperations(ctx context.Context, o *internal.Orchestration, user string) error {
+	operationType := dbmodel.OperationTypeUpgradeKyma
+	if o.IsUpgradeCluster() {
+		operationType = dbmodel.OperationTypeUpgradeCluster
+	}
+	operations, err := h.operations.GetOperationsInProgressByType(operationType)
+	if err != nil {
+		return errors.Wrap(err, "while getting in progress upgrade operations")
+	}
+
+	for _, op := range operations {
+		if op.OrchestrationID != o.OrchestrationID {
+			continue
+		}
+		var oldState domain.LastOperationState
+		var canceled internal.Operation
+		cancel := func(runtimeOperation *internal.RuntimeOperation) {
+			oldState = runtimeOperation.State
+			cancelRuntimeOperation(runtimeOperation)
+			canceled = runtimeOperation.Operation
+		}
+		if o.IsUpgradeCluster() {
+			_, err = storage.UpdateWithRetryUpgradeClusterOperation(h.operations, op.ID, func(upgradeOperation *internal.UpgradeClusterOperation) {
+				cancel(&upgradeOperation.RuntimeOperation)
+			})
+		} else {
+			_, err = storage.UpdateWithRetryUpgradeKymaOperation(h.operations, op.ID, func(upgradeOperation *internal.UpgradeKymaOperation) {
+				cancel(&upgradeOperation.RuntimeOperation)
+			})
+		}
+		if err != nil {
+			return errors.Wrapf(err, "while updating upgrade operation %s", op.ID)
+		}
+		h.publisher.Publish(ctx, process.OperationStateChanged{OldState: oldState, Operation: canceled, Actor: user})
+	}
+
+	return nil
+}
+
+func cancelRuntimeOperation(op *internal.RuntimeOperation) {
+	op.State = internal.OperationCanceled
+	op.Description = "operation canceled together with the orchestration"
+	op.ResultReason = "orchestration canceled"
+}
+
+func (h *kymaHandler) retryOrchestration(w http.ResponseWriter, r *http.Request) {
+	orchestrationID := mux.Vars(r)["orchestration_id"]
+
+	retryRequest := orchestration.RetryRequest{}
+	if r.Body != nil {
+		err := json.NewDecoder(r.Body).Decode(&retryRequest)
+		if err != nil && err != io.EOF {
+			h.log.Errorf("while decoding request body: %v", err)
+			httputil.WriteErrorResponse(w, http.StatusBadRequest, errors.Wrapf(err, "while decoding request body"))
+			return
+		}
+	}
+
+	o, err := h.orchestrations.GetByID(orchestrationID)
+	if err != nil {
+		h.log.Errorf("while getting orchestration %s: %v", orchestrationID, err)
+		httputil.WriteErrorResponse(w, resolveErrorStatus(err), errors.Wrapf(err, "while getting orchestration %s", orchestrationID))
+		return
+	}
+	if o.State != internal.Failed && o.State != internal.Canceled {
+		httputil.WriteErrorResponse(w, http.StatusConflict, errors.Errorf("orchestration %s is in %s state, only failed or canceled orchestration can be retried", orchestrationID, o.State))
+		return
+	}
+
+	operations, err := h.listAllOperations(o)
+	if err != nil {
+		h.log.Errorf("while getting operations of orchestration %s: %v", orchestrationID, err)
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrapf(err, "while getting operations of orchestration %s", orchestrationID))
+		return
+	}
+	operations, err = operationsToRetry(operations, retryRequest)
+	if err != nil {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, errors.Wrapf(err, "while selecting operations of orchestration %s to retry", orchestrationID))
+		return
+	}
+
+	if retryRequest.DryRun {
+		response := orchestration.RetryResponse{
+			OrchestrationID: orchestrationID,
+			RetryOperations: operationIDs(operations),
+			DryRun:          true,
+		}
+		httputil.WriteResponse(w, http.StatusOK, response)
+		return
+	}
+
+	retried, err := h.retryOperations(r.Context(), o, operations, httputil.User(r))
+	if err != nil {
+		h.log.Errorf("while retrying operations of orchestration %s: %v", orchestrationID, err)
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrapf(err, "while retrying operations of orchestration %s", orchestrationID))
+		return
+	}
+
+	o.State = internal.InProgress
+	o.Description = fmt.Sprintf("Retrying %d operations", len(retried))
+	o.UpdatedAt = time.Now()
+	err = h.orchestrations.Update(*o)
+	if err != nil {
+		h.log.Errorf("while updating orchestration %s: %v", orchestrationID, err)
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrapf(err, "while updating orchestration %s", orchestrationID))
+		return
+	}
+
+	// the orchestration manager executes again the operations of the orchestration which are in progress
+	h.queueFor(o).Add(orchestrationID)
+
+	response := orchestration.RetryResponse{
+		OrchestrationID: orchestrationID,
+		RetryOperations: retried,
+	}
+
+	httputil.WriteResponse(w, http.StatusAccepted, response)
+}
+
+// operationsToRetry selects the failed and canceled operations, the canceled ones are skipped if only the failed
+// operations are requested. If the operation IDs are given, all of them must belong to the orchestration and be retryable.
+func operationsToRetry(operations []runtimeOperation, req orchestration.RetryRequest) ([]runtimeOperation, error) {
+	retryable := func(op runtimeOperation) bool {
+		return op.State == domain.Failed || (op.State == internal.OperationCanceled && !req.FailedOnly)
+	}
+
+	if len(req.Operations) == 0 {
+		selected := make([]runtimeOperation, 0)
+		for _, op := range operations {
+			if retryable(op) {
+				selected = append(selected, op)
+			}
+		}
+		return selected, nil
+	}
+
+	byID := make(map[string]runtimeOperation, len(operations))
+	for _, op := range operations {
+		byID[op.ID] = op
+	}
+	selected := make([]runtimeOperation, 0, len(req.Operations))
+	for _, id := range req.Operations {
+		op, found := byID[id]
+		if !found {
+			return nil, errors.Errorf("operation %s does not belong to the orchestration", id)
+		}
+		if !retryable(op) {
+			return nil, errors.Errorf("operation %s is in %s state and cannot be retried", id, op.State)
+		}
+		selected = append(selected, op)
+	}
+	return selected, nil
+}
+
+// retryOperations moves the given upgrade operations back to the in progress state and returns their IDs,
+// the state transitions are recorded with the user who retried the orchestration as the actor
+func (h *kymaHandler) retryOperations(ctx context.Context, o *internal.Orchestration, operations []runtimeOperation, user string) ([]string, error) {
+	retried := make([]string, 0)
+	now := time.Now()
+	for _, op := range operations {
+		var err error
+		var oldState domain.LastOperationState
+		var retriedOperation internal.Operation
+		retry := func(runtimeOperation *internal.RuntimeOperation) {
+			oldState = runtimeOperation.State
+			retryRuntimeOperation(runtimeOperation, now)
+			retriedOperation = runtimeOperation.Operation
+		}
+		if o.IsUpgradeCluster() {
+			_, err = storage.UpdateWithRetryUpgradeClusterOperation(h.operations, op.ID, func(upgradeOperation *internal.UpgradeClusterOperation) {
+				retry(&upgradeOperation.RuntimeOperation)
+			})
+		} else {
+			_, err = storage.UpdateWithRetryUpgradeKymaOperation(h.operations, op.ID, func(upgradeOperation *internal.UpgradeKymaOperation) {
+				retry(&upgradeOperation.RuntimeOperation)
+				upgradeOperation.Verification = nil
+			})
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "while updating upgrade operation %s", op.ID)
+		}
+		h.publisher.Publish(ctx, process.OperationStateChanged{OldState: oldState, Operation: retriedOperation, Actor: user})
+		retried = append(retried, op.ID)
+	}
+
+	return retried, nil
+}
+
+func retryRuntimeOperation(op *internal.RuntimeOperation, now time.Time) {
+	op.State = domain.InProgress
+	op.Description = "operation scheduled for retry"
+	op.ResultReason = ""
+	op.RetryCount++
+	op.LastRetryAt = now
+	// the upgrade is triggered again in the provisioner instead of checking the status of the failed one
+	op.ProvisionerOperationID = ""
+	// the maintenance window of the failed operation could already pass, it is moved to the next occurrence
+	if op.MaintenanceWindowEnd.Before(now) {
+		days := int(now.Sub(op.MaintenanceWindowEnd)/(24*time.Hour)) + 1
+		op.MaintenanceWindowBegin = op.MaintenanceWindowBegin.AddDate(0, 0, days)
+		op.MaintenanceWindowEnd = op.MaintenanceWindowEnd.AddDate(0, 0, days)
+	}
+}
+
+func operationIDs(operations []runtimeOperation) []string {
+	ids := make([]string, 0, len(operations))
+	for _, op := range operations {
+		ids = append(ids, op.ID)
+	}
+	return ids
+}
+
+// runtimeOperation is the operation of the orchestration regardless of the orchestration type
+type runtimeOperation struct {
+	internal.RuntimeOperation
+	PlanID string
+}
+
+// listAllOperations returns all upgrade operations of the orchestration regardless of the page size limit
+func (h *kymaHandler) listAllOperations(o *internal.Orchestration) ([]runtimeOperation, error) {
+	if o.IsUpgradeCluster() {
+		return h.listAllClusterOperations(o.OrchestrationID)
+	}
+
+	operations, count, totalCount, err := h.operations.ListUpgradeKymaOperationsByOrchestrationID(o.OrchestrationID, dbmodel.OperationFilter{}, h.defaultMaxPage, 1)
+	if err != nil {
+		return nil, errors.Wrap(err, "while getting upgrade operations")
+	}
+	if count < totalCount {
+		operations, _, _, err = h.operations.ListUpgradeKymaOperationsByOrchestrationID(o.OrchestrationID, dbmodel.OperationFilter{}, totalCount, 1)
+		if err != nil {
+			return nil, errors.Wrap(err, "while getting upgrade operations")
+		}
+	}
+
+	result := make([]runtimeOperation, 0, len(operations))
+	for _, op := range operations {
+		result = append(result, runtimeOperation{RuntimeOperation: op.RuntimeOperation, PlanID: op.PlanID})
+	}
+	return result, nil
+}
+
+func (h *kymaHandler) listAllClusterOperations(orchestrationID string) ([]runtimeOperation, error) {
+	operations, count, totalCount, err := h.operations.ListUpgradeClusterOperationsByOrchestrationID(orchestrationID, h.defaultMaxPage, 1)
+	if err != nil {
+		return nil, errors.Wrap(err, "while getting upgrade cluster operations")
+	}
+	if count < totalCount {
+		operations, _, _, err = h.operations.ListUpgradeClusterOperationsByOrchestrationID(orchestrationID, totalCount, 1)
+		if err != nil {
+			return nil, errors.Wrap(err, "while getting upgrade cluster operations")
+		}
+	}
+
+	result := make([]runtimeOperation, 0, len(operations))
+	for _, op := range operations {
+		result = append(result, runtimeOperation{RuntimeOperation: op.RuntimeOperation, PlanID: op.PlanID})
+	}
+	return result, nil
+}
+
+// listRuntimes returns the runtimes resolved from the targets of the orchestration, the targets of the pending
+// orchestration are resolved for the request without storing the result
+func (h *kymaHandler) listRuntimes(w http.ResponseWriter, r *http.Request) {
+	orchestrationID := mux.Vars(r)["orchestration_id"]
+	pageSize, page, err := pagination.ExtractPaginationConfigFromRequest(r, h.defaultMaxPage)
+	if err != nil {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, errors.Wrap(err, "while getting query parameters"))
+		return
+	}
+
+	o, err := h.orchestrations.GetByID(orchestrationID)
+	if err != nil {
+		h.log.Errorf("while getting orchestration %s: %v", orchestrationID, err)
+		httputil.WriteErrorResponse(w, resolveErrorStatus(err), errors.Wrapf(err, "while getting orchestration %s", orchestrationID))
+		return
+	}
+
+	runtimes := o.Runtimes
+	preview := false
+	if runtimes == nil && o.State == internal.Pending {
+		runtimes, err = h.simulator.ResolveRuntimes(o.Parameters)
+		if err != nil {
+			h.log.Errorf("while resolving runtimes of orchestration %s: %v", orchestrationID, err)
+			httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrapf(err, "while resolving runtimes of orchestration %s", orchestrationID))
+			return
+		}
+		preview = true
+	}
+
+	response := orchestration.RuntimeResponseList{
+		Data:       make([]internal.Runtime, 0),
+		TotalCount: len(runtimes),
+		Preview:    preview,
+	}
+	for i := pagination.ConvertPageAndPageSizeToOffset(pageSize, page); i < len(runtimes) && len(response.Data) < pageSize; i++ {
+		response.Data = append(response.Data, runtimes[i])
+	}
+	response.Count = len(response.Data)
+
+	httputil.WriteResponse(w, http.StatusOK, response)
+}
+
+func (h *kymaHandler) getStats(w http.ResponseWriter, r *http.Request) {
+	orchestrationID := mux.Vars(r)["orchestration_id"]
+
+	o, err := h.orchestrations.GetByID(orchestrationID)
+	if err != nil {
+		h.log.Errorf("while getting orchestration %s: %v", orchestrationID, err)
+		httputil.WriteErrorResponse(w, resolveErrorStatus(err), errors.Wrapf(err, "while getting orchestration %s", orchestrationID))
+		return
+	}
+
+	stats, err := h.operations.GetOperationStatsForOrchestration(orchestrationID)
+	if err != nil {
+		h.log.Errorf("while getting operation stats of orchestration %s: %v", orchestrationID, err)
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrapf(err, "while getting operation stats of orchestration %s", orchestrationID))
+		return
+	}
+
+	response := orchestration.StatsResponse{
+		OrchestrationID: o.OrchestrationID,
+		State:           o.State,
+		Operations:      make(map[string]int, len(stats)),
+	}
+	for state, count := range stats {
+		response.Operations[string(state)] = count
+		response.Total += count
+	}
+	switch {
+	case response.Total > 0:
+		unfinished := stats[internal.OperationPending] + stats[domain.InProgress]
+		response.Progress = (response.Total - unfinished) * 100 / response.Total
+	case o.IsFinished():
+		response.Progress = 100
+	}
+
+	httputil.WriteResponse(w, http.StatusOK, response)
+}
+
+func (h *kymaHandler) getReport(w http.ResponseWriter, r *http.Request) {
+	orchestrationID := mux.Vars(r)["orchestration_id"]
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = reportFormatJSON
+	}
+	if format != reportFormatJSON && format != reportFormatCSV {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, errors.Errorf("unsupported report format %q, use %q or %q", format, reportFormatJSON, reportFormatCSV))
+		return
+	}
+
+	o, err := h.orchestrations.GetByID(orchestrationID)
+	if err != nil {
+		h.log.Errorf("while getting orchestration %s: %v", orchestrationID, err)
+		httputil.WriteErrorResponse(w, resolveErrorStatus(err), errors.Wrapf(err, "while getting orchestration %s", orchestrationID))
+		return
+	}
+
+	operations, err := h.listAllOperations(o)
+	if err != nil {
+		h.log.Errorf("while getting operations of orchestration %s: %v", orchestrationID, err)
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrapf(err, "while getting operations of orchestration %s", orchestrationID))
+		return
+	}
+
+	report, err := h.buildReport(o, operations)
+	if err != nil {
+		h.log.Errorf("while building report of orchestration %s: %v", orchestrationID, err)
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrapf(err, "while building report of orchestration %s", orchestrationID))
+		return
+	}
+
+	if format == reportFormatJSON {
+		httputil.WriteResponse(w, http.StatusOK, report)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=orchestration-%s-report.csv", orchestrationID))
+	w.WriteHeader(http.StatusOK)
+	if err := writeReportCSV(w, report); err != nil {
+		h.log.Errorf("while writing report of orchestration %s: %v", orchestrationID, err)
+	}
+}
+
 func (h *kymaHandler) listOrchestration(w http.ResponseWriter, r *http.Request) {
 	pageSize, page, err := pagination.ExtractPaginationConfigFromRequest(r, h.defaultMaxPage)
 	if err != nil {
@@ -81,7 +558,17 @@ func (h *kymaHandler) listOrchestration(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	orchestrations, count, totalCount, err := h.orchestrations.List(pageSize, page)
+	filter := dbmodel.OrchestrationFilter{PageSize: pageSize, Page: page}
+	createdAt, orchestrationID, found, err := pagination.ExtractCursorFromRequest(r)
+	if err != nil {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, errors.Wrap(err, "while getting query parameters"))
+		return
+	}
+	if found {
+		filter.After = &dbmodel.OrchestrationCursor{CreatedAt: createdAt, OrchestrationID: orchestrationID}
+	}
+
+	orchestrations, count, totalCount, err := h.orchestrations.List(filter)
 	if err != nil {
 		h.log.Errorf("while getting orchestrations: %v", err)
 		httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrapf(err, "while getting orchestrations"))
@@ -94,6 +581,11 @@ func (h *kymaHandler) listOrchestration(w http.ResponseWriter, r *http.Request)
 		httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrapf(err, "while converting orchestrations"))
 		return
 	}
+	// the cursor is returned only for the full pages, because there are no more orchestrations after the page which is not full
+	if len(orchestrations) == pageSize {
+		last := orchestrations[len(orchestrations)-1]
+		response.NextCursor = pagination.EncodeCursor(last.CreatedAt, last.OrchestrationID)
+	}
 
 	httputil.WriteResponse(w, http.StatusOK, response)
 }
@@ -105,50 +597,123 @@ func (h *kymaHandler) listOperations(w http.ResponseWriter, r *http.Request) {
 		httputil.WriteErrorResponse(w, http.StatusBadRequest, errors.Wrap(err, "while getting query parameters"))
 		return
 	}
+	fields, err := httputil.Fields(r.URL.Query(), operationFields)
+	if err != nil {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, errors.Wrap(err, "while getting fields"))
+		return
+	}
 
-	operations, count, totalCount, err := h.operations.ListUpgradeKymaOperationsByOrchestrationID(orchestrationID, pageSize, page)
+	createdAt, operationID, found, err := pagination.ExtractCursorFromRequest(r)
 	if err != nil {
-		h.log.Errorf("while getting operations: %v", err)
-		httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrapf(err, "while getting operations"))
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, errors.Wrap(err, "while getting query parameters"))
 		return
 	}
 
-	response, err := h.conv.UpgradeKymaOperationListToDTO(operations, count, totalCount)
+	o, err := h.orchestrations.GetByID(orchestrationID)
 	if err != nil {
-		h.log.Errorf("while converting operations: %v", err)
-		httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrapf(err, "while converting operations"))
+		h.log.Errorf("while getting orchestration %s: %v", orchestrationID, err)
+		httputil.WriteErrorResponse(w, resolveErrorStatus(err), errors.Wrapf(err, "while getting orchestration %s", orchestrationID))
 		return
 	}
 
-	httputil.WriteResponse(w, http.StatusOK, response)
+	var response orchestration.OperationResponseList
+	if o.IsUpgradeCluster() {
+		// the cluster upgrade operations are paginated with the page number only
+		if found {
+			httputil.WriteErrorResponse(w, http.StatusBadRequest, errors.New("cursor is not supported for the operations of the cluster upgrade orchestration"))
+			return
+		}
+		operations, count, totalCount, err := h.operations.ListUpgradeClusterOperationsByOrchestrationID(orchestrationID, pageSize, page)
+		if err != nil {
+			h.log.Errorf("while getting operations: %v", err)
+			httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrapf(err, "while getting operations"))
+			return
+		}
+		response, err = h.conv.UpgradeClusterOperationListToDTO(operations, count, totalCount)
+		if err != nil {
+			h.log.Errorf("while converting operations: %v", err)
+			httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrapf(err, "while converting operations"))
+			return
+		}
+	} else {
+		filter := dbmodel.OperationFilter{}
+		if found {
+			filter.After = &dbmodel.OperationCursor{CreatedAt: createdAt, OperationID: operationID}
+		}
+		operations, count, totalCount, err := h.operations.ListUpgradeKymaOperationsByOrchestrationID(orchestrationID, filter, pageSize, page)
+		if err != nil {
+			h.log.Errorf("while getting operations: %v", err)
+			httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrapf(err, "while getting operations"))
+			return
+		}
+		response, err = h.conv.UpgradeKymaOperationListToDTO(operations, count, totalCount)
+		if err != nil {
+			h.log.Errorf("while converting operations: %v", err)
+			httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrapf(err, "while converting operations"))
+			return
+		}
+		if len(operations) == pageSize {
+			last := operations[len(operations)-1]
+			response.NextCursor = pagination.EncodeCursor(last.CreatedAt, last.Operation.ID)
+		}
+	}
+	if len(fields) == 0 {
+		httputil.WriteResponse(w, http.StatusOK, response)
+		return
+	}
+
+	data, err := httputil.Project(response.Data, fields)
+	if err != nil {
+		h.log.Errorf("while selecting operation fields: %v", err)
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "while selecting operation fields"))
+		return
+	}
+	httputil.WriteResponse(w, http.StatusOK, httputil.ProjectedPage{
+		Data:       data,
+		Count:      response.Count,
+		TotalCount: response.TotalCount,
+		NextCursor: response.NextCursor,
+	})
 }
 
 func (h *kymaHandler) getOperation(w http.ResponseWriter, r *http.Request) {
+	orchestrationID := mux.Vars(r)["orchestration_id"]
 	operationID := mux.Vars(r)["operation_id"]
 
+	o, err := h.orchestrations.GetByID(orchestrationID)
+	if err != nil {
+		h.log.Errorf("while getting orchestration %s: %v", orchestrationID, err)
+		httputil.WriteErrorResponse(w, resolveErrorStatus(err), errors.Wrapf(err, "while getting orchestration %s", orchestrationID))
+		return
+	}
+	if o.IsUpgradeCluster() {
+		h.getClusterOperation(w, operationID)
+		return
+	}
+
 	operation, err := h.operations.GetUpgradeKymaOperationByID(operationID)
 	if err != nil {
 		h.log.Errorf("while getting upgrade operation %s: %v", operationID, err)
-		httputil.WriteErrorResponse(w, h.resolveErrorStatus(err), errors.Wrapf(err, "while getting operation %s", operationID))
+		httputil.WriteErrorResponse(w, resolveErrorStatus(err), errors.Wrapf(err, "while getting operation %s", operationID))
 		return
 	}
 	provisioningOp, err := h.operations.GetProvisioningOperationByInstanceID(operation.InstanceID)
 	if err != nil {
 		h.log.Errorf("while getting provisioning operation for instance %s: %v", operation.InstanceID, err)
-		httputil.WriteErrorResponse(w, h.resolveErrorStatus(err), errors.Wrapf(err, "while getting provisioning operation for instance %s", operation.InstanceID))
+		httputil.WriteErrorResponse(w, resolveErrorStatus(err), errors.Wrapf(err, "while getting provisioning operation for instance %s", operation.InstanceID))
 		return
 	}
 	provisioningState, err := h.runtimeStates.GetByOperationID(provisioningOp.ID)
 	if err != nil {
 		h.log.Errorf("while getting runtime state for operation %s: %v", provisioningOp.ID, err)
-		httputil.WriteErrorResponse(w, h.resolveErrorStatus(err), errors.Wrapf(err, "while getting runtime state for operation %s", provisioningOp.ID))
+		httputil.WriteErrorResponse(w, resolveErrorStatus(err), errors.Wrapf(err, "while getting runtime state for operation %s", provisioningOp.ID))
 		return
 	}
 
 	upgradeState, err := h.runtimeStates.GetByOperationID(operationID)
 	if err != nil && !dberr.IsNotFound(err) {
 		h.log.Errorf("while getting runtime state for upgrade operation %s: %v", operationID, err)
-		httputil.WriteErrorResponse(w, h.resolveErrorStatus(err), errors.Wrapf(err, "while getting runtime state for upgrade operation %s", operationID))
+		httputil.WriteErrorResponse(w, resolveErrorStatus(err), errors.Wrapf(err, "while getting runtime state for upgrade operation %s", operationID))
 		return
 	}
 
@@ -162,26 +727,44 @@ func (h *kymaHandler) getOperation(w http.ResponseWriter, r *http.Request) {
 	httputil.WriteResponse(w, http.StatusOK, response)
 }
 
-func (h *kymaHandler) createOrchestration(w http.ResponseWriter, r *http.Request) {
-	params := internal.OrchestrationParameters{}
-
-	if r.Body != nil {
-		err := json.NewDecoder(r.Body).Decode(&params)
-		if err != nil {
-			h.log.Errorf("while decoding request body: %v", err)
-			httputil.WriteErrorResponse(w, http.StatusBadRequest, errors.Wrapf(err, "while decoding request body"))
-			return
-		}
+// getClusterOperation returns the cluster upgrade operation with the cluster configuration of the provisioned runtime
+func (h *kymaHandler) getClusterOperation(w http.ResponseWriter, operationID string) {
+	operation, err := h.operations.GetUpgradeClusterOperationByID(operationID)
+	if err != nil {
+		h.log.Errorf("while getting upgrade cluster operation %s: %v", operationID, err)
+		httputil.WriteErrorResponse(w, resolveErrorStatus(err), errors.Wrapf(err, "while getting operation %s", operationID))
+		return
+	}
+	provisioningOp, err := h.operations.GetProvisioningOperationByInstanceID(operation.InstanceID)
+	if err != nil {
+		h.log.Errorf("while getting provisioning operation for instance %s: %v", operation.InstanceID, err)
+		httputil.WriteErrorResponse(w, resolveErrorStatus(err), errors.Wrapf(err, "while getting provisioning operation for instance %s", operation.InstanceID))
+		return
 	}
-	err := h.validateTarget(params.Targets)
+	provisioningState, err := h.runtimeStates.GetByOperationID(provisioningOp.ID)
 	if err != nil {
-		h.log.Errorf("while validating target: %v", err)
-		httputil.WriteErrorResponse(w, http.StatusBadRequest, errors.Wrapf(err, "while validating target"))
+		h.log.Errorf("while getting runtime state for operation %s: %v", provisioningOp.ID, err)
+		httputil.WriteErrorResponse(w, resolveErrorStatus(err), errors.Wrapf(err, "while getting runtime state for operation %s", provisioningOp.ID))
 		return
 	}
 
-	// defaults strategy if not specified to Parallel with Immediate schedule
-	h.defaultOrchestrationStrategy(&params.Strategy)
+	response, err := h.conv.UpgradeClusterOperationToDetailDTO(*operation, provisioningState.ClusterConfig)
+	if err != nil {
+		h.log.Errorf("while converting operation: %v", err)
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrapf(err, "while converting operation"))
+		return
+	}
+
+	httputil.WriteResponse(w, http.StatusOK, response)
+}
+
+func (h *kymaHandler) createOrchestration(w http.ResponseWriter, r *http.Request) {
+	params, err := h.orchestrationParameters(r)
+	if err != nil {
+		h.log.Errorf("while reading orchestration parameters: %v", err)
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
 
 	now := time.Now()
 	o := internal.Orchestration{
@@ -207,7 +790,60 @@ func (h *kymaHandler) createOrchestration(w http.ResponseWriter, r *http.Request
 	httputil.WriteResponse(w, http.StatusAccepted, response)
 }
 
-func (h *kymaHandler) resolveErrorStatus(err error) int {
+// simulateOrchestration estimates the duration of the orchestration with the given parameters
+// from the durations of the past upgrades, no orchestration or operation is created
+func (h *kymaHandler) simulateOrchestration(w http.ResponseWriter, r *http.Request) {
+	params, err := h.orchestrationParameters(r)
+	if err != nil {
+		h.log.Errorf("while reading orchestration parameters: %v", err)
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	response, err := h.simulator.Simulate(params)
+	if err != nil {
+		h.log.Errorf("while simulating orchestration: %v", err)
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrapf(err, "while simulating orchestration"))
+		return
+	}
+
+	httputil.WriteResponse(w, http.StatusOK, response)
+}
+
+// orchestrationParameters decodes and validates the orchestration parameters from the request body,
+// the strategy which is not specified is defaulted to Parallel with Immediate schedule
+func (h *kymaHandler) orchestrationParameters(r *http.Request) (internal.OrchestrationParameters, error) {
+	params := internal.OrchestrationParameters{}
+
+	if r.Body != nil {
+		err := json.NewDecoder(r.Body).Decode(&params)
+		if err != nil {
+			return params, errors.Wrapf(err, "while decoding request body")
+		}
+	}
+	err := validateTarget(params.Targets)
+	if err != nil {
+		return params, errors.Wrapf(err, "while validating target")
+	}
+	err = validateStrategy(params.Strategy)
+	if err != nil {
+		return params, errors.Wrapf(err, "while validating strategy")
+	}
+	_, err = orchestration.ParseSkipUpgradedWithin(params.SkipUpgradedWithin)
+	if err != nil {
+		return params, errors.Wrapf(err, "while validating skip upgraded within period")
+	}
+	err = h.cfg.ValidateInstallationTimeout(params.InstallationTimeout)
+	if err != nil {
+		return params, errors.Wrapf(err, "while validating installation timeout")
+	}
+
+	defaultOrchestrationStrategy(&params.Strategy)
+
+	return params, nil
+}
+
+func resolveErrorStatus(err error) int {
 	switch {
 	case dberr.IsNotFound(err):
 		return http.StatusNotFound
@@ -216,20 +852,61 @@ func (h *kymaHandler) resolveErrorStatus(err error) int {
 	}
 }
 
-func (h *kymaHandler) validateTarget(spec internal.TargetSpec) error {
+func validateTarget(spec internal.TargetSpec) error {
 	if spec.Include == nil || len(spec.Include) == 0 {
 		return errors.New("targets.include array must be not empty")
 	}
+	for _, target := range append(spec.Include, spec.Exclude...) {
+		if target.PlanName != "" && !isPlanName(target.PlanName) {
+			return errors.Errorf("unknown plan name %q", target.PlanName)
+		}
+	}
 	return nil
 }
 
-func (h *kymaHandler) defaultOrchestrationStrategy(spec *internal.StrategySpec) {
+func validateStrategy(spec internal.StrategySpec) error {
+	if spec.Parallel.Workers < 0 || spec.Parallel.Workers > maxParallelWorkers {
+		return errors.Errorf("parallel workers must be between 0 and %d, got %d", maxParallelWorkers, spec.Parallel.Workers)
+	}
+	if spec.Type != internal.CanaryStrategy {
+		return nil
+	}
+	canary := spec.Canary
+	if canary.Percentage < 0 || canary.Percentage > 100 {
+		return errors.Errorf("canary percentage must be between 0 and 100, got %d", canary.Percentage)
+	}
+	if canary.Count < 0 {
+		return errors.Errorf("canary count must not be negative, got %d", canary.Count)
+	}
+	if canary.FailureThreshold < 0 {
+		return errors.Errorf("canary failure threshold must not be negative, got %d", canary.FailureThreshold)
+	}
+	if _, err := orchestration.ParseSoakTime(canary.SoakTime); err != nil {
+		return err
+	}
+	return nil
+}
+
+func isPlanName(name string) bool {
+	for _, plan := range broker.Plans {
+		if plan.PlanDefinition.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func defaultOrchestrationStrategy(spec *internal.StrategySpec) {
 	if spec.Parallel.Workers == 0 {
 		spec.Parallel.Workers = 1
 	}
 
 	switch spec.Type {
 	case internal.ParallelStrategy:
+	case internal.CanaryStrategy:
+		if spec.Canary.Count == 0 && spec.Canary.Percentage == 0 {
+			spec.Canary.Percentage = defaultCanaryPercentage
+		}
 	default:
 		spec.Type = internal.ParallelStrategy
 	}
@@ -2,6 +2,7 @@ package handlers_test
 
 import (
 	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -10,13 +11,18 @@ import (
 	"time"
 
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/event"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/orchestration"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/orchestration/automock"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/orchestration/handlers"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession/dbmodel"
+	"github.com/kyma-project/control-plane/components/provisioner/pkg/gqlschema"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/gorilla/mux"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/require"
 )
@@ -29,7 +35,7 @@ func TestKymaOrchestrationHandler_(t *testing.T) {
 		db := storage.NewMemoryStorage()
 		logs := logrus.New()
 		q := process.NewQueue(&testExecutor{}, logs)
-		kymaHandler := handlers.NewKymaOrchestrationHandler(db.Operations(), db.Orchestrations(), db.RuntimeStates(), 100, q, logs)
+		kymaHandler := handlers.NewKymaOrchestrationHandler(db.Operations(), db.Orchestrations(), db.RuntimeStates(), 100, orchestration.Config{}, q, nil, nil, event.NewPubSub(), logs)
 
 		params := internal.OrchestrationParameters{
 			Targets: internal.TargetSpec{
@@ -83,6 +89,246 @@ func TestKymaOrchestrationHandler_(t *testing.T) {
 		assert.Equal(t, dto.Parameters.Strategy.Schedule, internal.Immediate)
 	})
 
+	t.Run("upgrade with unknown plan name", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		logs := logrus.New()
+		q := process.NewQueue(&testExecutor{}, logs)
+		kymaHandler := handlers.NewKymaOrchestrationHandler(db.Operations(), db.Orchestrations(), db.RuntimeStates(), 100, orchestration.Config{}, q, nil, nil, event.NewPubSub(), logs)
+
+		params := internal.OrchestrationParameters{
+			Targets: internal.TargetSpec{
+				Include: []internal.RuntimeTarget{
+					{
+						PlanName: "openstack",
+					},
+				},
+			},
+		}
+		p, err := json.Marshal(&params)
+		require.NoError(t, err)
+
+		req, err := http.NewRequest("POST", "/upgrade/kyma", bytes.NewBuffer(p))
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+		kymaHandler.AttachRoutes(router)
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("upgrade with invalid canary strategy", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		logs := logrus.New()
+		q := process.NewQueue(&testExecutor{}, logs)
+		kymaHandler := handlers.NewKymaOrchestrationHandler(db.Operations(), db.Orchestrations(), db.RuntimeStates(), 100, orchestration.Config{}, q, nil, nil, event.NewPubSub(), logs)
+
+		params := internal.OrchestrationParameters{
+			Targets: internal.TargetSpec{
+				Include: []internal.RuntimeTarget{
+					{
+						Target: internal.TargetAll,
+					},
+				},
+			},
+			Strategy: internal.StrategySpec{
+				Type: internal.CanaryStrategy,
+				Canary: internal.CanaryStrategySpec{
+					Percentage: 120,
+				},
+			},
+		}
+		p, err := json.Marshal(&params)
+		require.NoError(t, err)
+
+		req, err := http.NewRequest("POST", "/upgrade/kyma", bytes.NewBuffer(p))
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+		kymaHandler.AttachRoutes(router)
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("upgrade with too many parallel workers", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		logs := logrus.New()
+		q := process.NewQueue(&testExecutor{}, logs)
+		kymaHandler := handlers.NewKymaOrchestrationHandler(db.Operations(), db.Orchestrations(), db.RuntimeStates(), 100, orchestration.Config{}, q, nil, nil, event.NewPubSub(), logs)
+
+		params := internal.OrchestrationParameters{
+			Targets: internal.TargetSpec{
+				Include: []internal.RuntimeTarget{
+					{
+						Target: internal.TargetAll,
+					},
+				},
+			},
+			Strategy: internal.StrategySpec{
+				Type: internal.ParallelStrategy,
+				Parallel: internal.ParallelStrategySpec{
+					Workers: 500,
+				},
+			},
+		}
+		p, err := json.Marshal(&params)
+		require.NoError(t, err)
+
+		req, err := http.NewRequest("POST", "/upgrade/kyma", bytes.NewBuffer(p))
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+		kymaHandler.AttachRoutes(router)
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("upgrade with invalid skip upgraded within period", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		logs := logrus.New()
+		q := process.NewQueue(&testExecutor{}, logs)
+		kymaHandler := handlers.NewKymaOrchestrationHandler(db.Operations(), db.Orchestrations(), db.RuntimeStates(), 100, orchestration.Config{}, q, nil, nil, event.NewPubSub(), logs)
+
+		params := internal.OrchestrationParameters{
+			Targets: internal.TargetSpec{
+				Include: []internal.RuntimeTarget{
+					{
+						Target: internal.TargetAll,
+					},
+				},
+			},
+			SkipUpgradedWithin: "-72h",
+		}
+		p, err := json.Marshal(&params)
+		require.NoError(t, err)
+
+		req, err := http.NewRequest("POST", "/upgrade/kyma", bytes.NewBuffer(p))
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+		kymaHandler.AttachRoutes(router)
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("upgrade with installation timeout", func(t *testing.T) {
+		for tN, tC := range map[string]struct {
+			timeout      string
+			expectedCode int
+		}{
+			"within limits":     {timeout: "3h", expectedCode: http.StatusAccepted},
+			"lower than limit":  {timeout: "10m", expectedCode: http.StatusBadRequest},
+			"higher than limit": {timeout: "12h", expectedCode: http.StatusBadRequest},
+			"invalid":           {timeout: "3 hours", expectedCode: http.StatusBadRequest},
+		} {
+			t.Run(tN, func(t *testing.T) {
+				// given
+				db := storage.NewMemoryStorage()
+				logs := logrus.New()
+				q := process.NewQueue(&testExecutor{}, logs)
+				cfg := orchestration.Config{MinInstallationTimeout: 30 * time.Minute, MaxInstallationTimeout: 6 * time.Hour}
+				kymaHandler := handlers.NewKymaOrchestrationHandler(db.Operations(), db.Orchestrations(), db.RuntimeStates(), 100, cfg, q, nil, nil, event.NewPubSub(), logs)
+
+				params := internal.OrchestrationParameters{
+					Targets: internal.TargetSpec{
+						Include: []internal.RuntimeTarget{
+							{
+								Target: internal.TargetAll,
+							},
+						},
+					},
+					InstallationTimeout: tC.timeout,
+				}
+				p, err := json.Marshal(&params)
+				require.NoError(t, err)
+
+				req, err := http.NewRequest("POST", "/upgrade/kyma", bytes.NewBuffer(p))
+				require.NoError(t, err)
+
+				rr := httptest.NewRecorder()
+				router := mux.NewRouter()
+				kymaHandler.AttachRoutes(router)
+
+				// when
+				router.ServeHTTP(rr, req)
+
+				// then
+				require.Equal(t, tC.expectedCode, rr.Code)
+			})
+		}
+	})
+
+	t.Run("simulate", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		logs := logrus.New()
+		q := process.NewQueue(&testExecutor{}, logs)
+		targets := internal.TargetSpec{
+			Include: []internal.RuntimeTarget{{Target: internal.TargetAll}},
+		}
+		resolver := &automock.RuntimeResolver{}
+		defer resolver.AssertExpectations(t)
+		resolver.On("Resolve", targets).Return([]internal.Runtime{
+			{InstanceID: "instance-1", RuntimeID: "runtime-1"},
+			{InstanceID: "instance-2", RuntimeID: "runtime-2"},
+		}, nil)
+		kymaHandler := handlers.NewKymaOrchestrationHandler(db.Operations(), db.Orchestrations(), db.RuntimeStates(), 100, orchestration.Config{}, q, nil, resolver, event.NewPubSub(), logs)
+
+		params := internal.OrchestrationParameters{
+			Targets:  targets,
+			Strategy: internal.StrategySpec{Parallel: internal.ParallelStrategySpec{Workers: 2}},
+		}
+		p, err := json.Marshal(&params)
+		require.NoError(t, err)
+
+		req, err := http.NewRequest("POST", "/upgrade/kyma/simulate", bytes.NewBuffer(p))
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+		kymaHandler.AttachRoutes(router)
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var out orchestration.SimulationResponse
+		err = json.Unmarshal(rr.Body.Bytes(), &out)
+		require.NoError(t, err)
+		assert.Equal(t, 2, out.Runtimes)
+		assert.Equal(t, 2, out.Workers)
+		assert.Equal(t, "30m0s", out.EstimatedDuration)
+
+		orchestrations, _, _, err := db.Orchestrations().List(dbmodel.OrchestrationFilter{PageSize: 100, Page: 1})
+		require.NoError(t, err)
+		assert.Empty(t, orchestrations, "the simulation must not create the orchestration")
+	})
+
 	t.Run("orchestrations", func(t *testing.T) {
 		// given
 		db := storage.NewMemoryStorage()
@@ -94,7 +340,7 @@ func TestKymaOrchestrationHandler_(t *testing.T) {
 
 		logs := logrus.New()
 		q := process.NewQueue(&testExecutor{}, logs)
-		kymaHandler := handlers.NewKymaOrchestrationHandler(db.Operations(), db.Orchestrations(), db.RuntimeStates(), 100, q, logs)
+		kymaHandler := handlers.NewKymaOrchestrationHandler(db.Operations(), db.Orchestrations(), db.RuntimeStates(), 100, orchestration.Config{}, q, nil, nil, event.NewPubSub(), logs)
 
 		req, err := http.NewRequest("GET", "/orchestrations?page_size=1", nil)
 		require.NoError(t, err)
@@ -116,6 +362,36 @@ func TestKymaOrchestrationHandler_(t *testing.T) {
 		assert.Len(t, out.Data, 1)
 		assert.Equal(t, 2, out.TotalCount)
 		assert.Equal(t, 1, out.Count)
+		require.NotEmpty(t, out.NextCursor)
+
+		// given
+		req, err = http.NewRequest(http.MethodGet, fmt.Sprintf("/orchestrations?page_size=1&cursor=%s", out.NextCursor), nil)
+		require.NoError(t, err)
+		rr = httptest.NewRecorder()
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var next orchestration.StatusResponseList
+		err = json.Unmarshal(rr.Body.Bytes(), &next)
+		require.NoError(t, err)
+		require.Len(t, next.Data, 1)
+		assert.NotEqual(t, out.Data[0].OrchestrationID, next.Data[0].OrchestrationID)
+		assert.Equal(t, 2, next.TotalCount)
+
+		// given
+		req, err = http.NewRequest(http.MethodGet, fmt.Sprintf("/orchestrations?page=2&cursor=%s", out.NextCursor), nil)
+		require.NoError(t, err)
+		rr = httptest.NewRecorder()
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusBadRequest, rr.Code, "the page and the cursor must not be used together")
 
 		// given
 		urlPath := fmt.Sprintf("/orchestrations?page=2&page_size=1")
@@ -185,7 +461,7 @@ func TestKymaOrchestrationHandler_(t *testing.T) {
 
 		logs := logrus.New()
 		q := process.NewQueue(&testExecutor{}, logs)
-		kymaHandler := handlers.NewKymaOrchestrationHandler(db.Operations(), db.Orchestrations(), db.RuntimeStates(), 100, q, logs)
+		kymaHandler := handlers.NewKymaOrchestrationHandler(db.Operations(), db.Orchestrations(), db.RuntimeStates(), 100, orchestration.Config{}, q, nil, nil, event.NewPubSub(), logs)
 
 		urlPath := fmt.Sprintf("/orchestrations/%s/operations", fixID)
 		req, err := http.NewRequest("GET", urlPath, nil)
@@ -209,6 +485,38 @@ func TestKymaOrchestrationHandler_(t *testing.T) {
 		assert.Equal(t, 1, out.TotalCount)
 		assert.Equal(t, 1, out.Count)
 
+		// given
+		req, err = http.NewRequest(http.MethodGet, urlPath+"?fields=operationID,state", nil)
+		require.NoError(t, err)
+		rr = httptest.NewRecorder()
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var projected struct {
+			Data       []map[string]interface{} `json:"data"`
+			TotalCount int                      `json:"totalCount"`
+		}
+		err = json.Unmarshal(rr.Body.Bytes(), &projected)
+		require.NoError(t, err)
+		require.Len(t, projected.Data, 1)
+		assert.Equal(t, map[string]interface{}{"operationID": fixID, "state": ""}, projected.Data[0])
+		assert.Equal(t, 1, projected.TotalCount)
+
+		// given
+		req, err = http.NewRequest(http.MethodGet, urlPath+"?fields=unknown", nil)
+		require.NoError(t, err)
+		rr = httptest.NewRecorder()
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+
 		// given
 		urlPath = fmt.Sprintf("/orchestrations/%s/operations/%s", fixID, fixID)
 		req, err = http.NewRequest(http.MethodGet, urlPath, nil)
@@ -228,6 +536,509 @@ func TestKymaOrchestrationHandler_(t *testing.T) {
 		assert.Equal(t, dto.OrchestrationID, fixID)
 		assert.Equal(t, dto.OperationID, fixID)
 	})
+
+	t.Run("cancel", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+
+		err := db.Orchestrations().Insert(internal.Orchestration{OrchestrationID: fixID, State: internal.InProgress})
+		require.NoError(t, err)
+		err = db.Orchestrations().Insert(internal.Orchestration{OrchestrationID: "finished-id", State: internal.Succeeded})
+		require.NoError(t, err)
+		for _, op := range []internal.Operation{
+			{ID: "in-progress-id", OrchestrationID: fixID, State: domain.InProgress},
+			{ID: "succeeded-id", OrchestrationID: fixID, State: domain.Succeeded},
+			{ID: "other-id", OrchestrationID: "other-orchestration-id", State: domain.InProgress},
+		} {
+			err = db.Operations().InsertUpgradeKymaOperation(internal.UpgradeKymaOperation{
+				RuntimeOperation: internal.RuntimeOperation{Operation: op},
+			})
+			require.NoError(t, err)
+		}
+
+		logs := logrus.New()
+		q := process.NewQueue(&testExecutor{}, logs)
+		kymaHandler := handlers.NewKymaOrchestrationHandler(db.Operations(), db.Orchestrations(), db.RuntimeStates(), 100, orchestration.Config{}, q, nil, nil, event.NewPubSub(), logs)
+
+		router := mux.NewRouter()
+		kymaHandler.AttachRoutes(router)
+
+		req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/orchestrations/%s/cancel", fixID), nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var out orchestration.StatusResponse
+		err = json.Unmarshal(rr.Body.Bytes(), &out)
+		require.NoError(t, err)
+		assert.Equal(t, internal.Canceling, out.State)
+
+		o, err := db.Orchestrations().GetByID(fixID)
+		require.NoError(t, err)
+		assert.Equal(t, internal.Canceling, o.State)
+
+		for id, state := range map[string]domain.LastOperationState{
+			"in-progress-id": internal.OperationCanceled,
+			"succeeded-id":   domain.Succeeded,
+			"other-id":       domain.InProgress,
+		} {
+			op, err := db.Operations().GetUpgradeKymaOperationByID(id)
+			require.NoError(t, err)
+			assert.Equal(t, state, op.State, id)
+		}
+
+		// given
+		req, err = http.NewRequest(http.MethodPut, "/orchestrations/finished-id/cancel", nil)
+		require.NoError(t, err)
+		rr = httptest.NewRecorder()
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusConflict, rr.Code)
+
+		// given
+		req, err = http.NewRequest(http.MethodPut, "/orchestrations/not-existing-id/cancel", nil)
+		require.NoError(t, err)
+		rr = httptest.NewRecorder()
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+
+		err := db.Orchestrations().Insert(internal.Orchestration{OrchestrationID: fixID, State: internal.Failed})
+		require.NoError(t, err)
+		err = db.Orchestrations().Insert(internal.Orchestration{OrchestrationID: "in-progress-id", State: internal.InProgress})
+		require.NoError(t, err)
+		windowEnd := time.Now().Add(-time.Hour)
+		err = db.Operations().InsertUpgradeKymaOperation(internal.UpgradeKymaOperation{
+			RuntimeOperation: internal.RuntimeOperation{
+				Operation: internal.Operation{
+					ID:                     fixID,
+					OrchestrationID:        fixID,
+					ProvisionerOperationID: "provisioner-operation-id",
+					State:                  domain.Failed,
+				},
+				MaintenanceWindowBegin: windowEnd.Add(-time.Hour),
+				MaintenanceWindowEnd:   windowEnd,
+				ResultReason:           "upgrade failed",
+			},
+		})
+		require.NoError(t, err)
+
+		logs := logrus.New()
+		q := process.NewQueue(&testExecutor{}, logs)
+		kymaHandler := handlers.NewKymaOrchestrationHandler(db.Operations(), db.Orchestrations(), db.RuntimeStates(), 100, orchestration.Config{}, q, nil, nil, event.NewPubSub(), logs)
+
+		router := mux.NewRouter()
+		kymaHandler.AttachRoutes(router)
+
+		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/orchestrations/%s/retry", fixID), nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		require.Equal(t, http.StatusAccepted, rr.Code)
+
+		var out orchestration.RetryResponse
+		err = json.Unmarshal(rr.Body.Bytes(), &out)
+		require.NoError(t, err)
+		assert.Equal(t, fixID, out.OrchestrationID)
+		assert.Equal(t, []string{fixID}, out.RetryOperations)
+
+		o, err := db.Orchestrations().GetByID(fixID)
+		require.NoError(t, err)
+		assert.Equal(t, internal.InProgress, o.State)
+
+		op, err := db.Operations().GetUpgradeKymaOperationByID(fixID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.InProgress, op.State)
+		assert.Equal(t, 1, op.RetryCount)
+		assert.False(t, op.LastRetryAt.IsZero())
+		assert.Empty(t, op.ResultReason)
+		assert.Empty(t, op.ProvisionerOperationID)
+		assert.True(t, op.MaintenanceWindowEnd.After(time.Now()))
+		assert.Equal(t, time.Hour, op.MaintenanceWindowEnd.Sub(op.MaintenanceWindowBegin))
+
+		// given
+		req, err = http.NewRequest(http.MethodPost, "/orchestrations/in-progress-id/retry", nil)
+		require.NoError(t, err)
+		rr = httptest.NewRecorder()
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusConflict, rr.Code)
+
+		// given
+		req, err = http.NewRequest(http.MethodPost, "/orchestrations/not-existing-id/retry", nil)
+		require.NoError(t, err)
+		rr = httptest.NewRecorder()
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("retry selected operations", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+
+		err := db.Orchestrations().Insert(internal.Orchestration{OrchestrationID: fixID, State: internal.Canceled})
+		require.NoError(t, err)
+		for id, state := range map[string]domain.LastOperationState{
+			"failed-id":    domain.Failed,
+			"canceled-id":  internal.OperationCanceled,
+			"succeeded-id": domain.Succeeded,
+		} {
+			err = db.Operations().InsertUpgradeKymaOperation(internal.UpgradeKymaOperation{
+				RuntimeOperation: internal.RuntimeOperation{
+					Operation: internal.Operation{
+						ID:              id,
+						OrchestrationID: fixID,
+						State:           state,
+					},
+				},
+			})
+			require.NoError(t, err)
+		}
+
+		logs := logrus.New()
+		q := process.NewQueue(&testExecutor{}, logs)
+		kymaHandler := handlers.NewKymaOrchestrationHandler(db.Operations(), db.Orchestrations(), db.RuntimeStates(), 100, orchestration.Config{}, q, nil, nil, event.NewPubSub(), logs)
+
+		router := mux.NewRouter()
+		kymaHandler.AttachRoutes(router)
+
+		retry := func(retryRequest orchestration.RetryRequest) *httptest.ResponseRecorder {
+			body, err := json.Marshal(retryRequest)
+			require.NoError(t, err)
+			req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/orchestrations/%s/retry", fixID), bytes.NewReader(body))
+			require.NoError(t, err)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+			return rr
+		}
+
+		// when
+		rr := retry(orchestration.RetryRequest{DryRun: true})
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+		var out orchestration.RetryResponse
+		err = json.Unmarshal(rr.Body.Bytes(), &out)
+		require.NoError(t, err)
+		assert.True(t, out.DryRun)
+		assert.ElementsMatch(t, []string{"failed-id", "canceled-id"}, out.RetryOperations)
+
+		op, err := db.Operations().GetUpgradeKymaOperationByID("failed-id")
+		require.NoError(t, err)
+		assert.Equal(t, domain.Failed, op.State)
+		o, err := db.Orchestrations().GetByID(fixID)
+		require.NoError(t, err)
+		assert.Equal(t, internal.Canceled, o.State)
+
+		// when
+		rr = retry(orchestration.RetryRequest{Operations: []string{"succeeded-id"}})
+
+		// then
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+		// when
+		rr = retry(orchestration.RetryRequest{Operations: []string{"canceled-id"}, FailedOnly: true})
+
+		// then
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+		// when
+		rr = retry(orchestration.RetryRequest{FailedOnly: true})
+
+		// then
+		require.Equal(t, http.StatusAccepted, rr.Code)
+		out = orchestration.RetryResponse{}
+		err = json.Unmarshal(rr.Body.Bytes(), &out)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"failed-id"}, out.RetryOperations)
+
+		op, err = db.Operations().GetUpgradeKymaOperationByID("failed-id")
+		require.NoError(t, err)
+		assert.Equal(t, domain.InProgress, op.State)
+		op, err = db.Operations().GetUpgradeKymaOperationByID("canceled-id")
+		require.NoError(t, err)
+		assert.Equal(t, internal.OperationCanceled, op.State)
+		o, err = db.Orchestrations().GetByID(fixID)
+		require.NoError(t, err)
+		assert.Equal(t, internal.InProgress, o.State)
+	})
+
+	t.Run("runtimes", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		targets := internal.TargetSpec{
+			Include: []internal.RuntimeTarget{{Target: internal.TargetAll}},
+		}
+		err := db.Orchestrations().Insert(internal.Orchestration{
+			OrchestrationID: fixID,
+			State:           internal.InProgress,
+			Runtimes: []internal.Runtime{
+				{InstanceID: "instance-1", RuntimeID: "runtime-1"},
+				{InstanceID: "instance-2", RuntimeID: "runtime-2"},
+			},
+		})
+		require.NoError(t, err)
+		err = db.Orchestrations().Insert(internal.Orchestration{
+			OrchestrationID: "pending",
+			State:           internal.Pending,
+			Parameters:      internal.OrchestrationParameters{Targets: targets},
+		})
+		require.NoError(t, err)
+
+		resolver := &automock.RuntimeResolver{}
+		defer resolver.AssertExpectations(t)
+		resolver.On("Resolve", targets).Return([]internal.Runtime{
+			{InstanceID: "instance-3", RuntimeID: "runtime-3"},
+		}, nil).Once()
+
+		logs := logrus.New()
+		q := process.NewQueue(&testExecutor{}, logs)
+		kymaHandler := handlers.NewKymaOrchestrationHandler(db.Operations(), db.Orchestrations(), db.RuntimeStates(), 100, orchestration.Config{}, q, nil, resolver, event.NewPubSub(), logs)
+		router := mux.NewRouter()
+		kymaHandler.AttachRoutes(router)
+
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/orchestrations/%s/runtimes?page=2&page_size=1", fixID), nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+		var out orchestration.RuntimeResponseList
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &out))
+		assert.Equal(t, 1, out.Count)
+		assert.Equal(t, 2, out.TotalCount)
+		assert.False(t, out.Preview)
+		require.Len(t, out.Data, 1)
+		assert.Equal(t, "runtime-2", out.Data[0].RuntimeID)
+
+		// when the targets of the pending orchestration are not resolved yet
+		req, err = http.NewRequest(http.MethodGet, "/orchestrations/pending/runtimes", nil)
+		require.NoError(t, err)
+		rr = httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+		out = orchestration.RuntimeResponseList{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &out))
+		assert.True(t, out.Preview)
+		require.Len(t, out.Data, 1)
+		assert.Equal(t, "runtime-3", out.Data[0].RuntimeID)
+
+		// when
+		req, err = http.NewRequest(http.MethodGet, "/orchestrations/unknown/runtimes", nil)
+		require.NoError(t, err)
+		rr = httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("stats", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+
+		err := db.Orchestrations().Insert(internal.Orchestration{OrchestrationID: fixID, State: internal.InProgress})
+		require.NoError(t, err)
+		for id, op := range map[string]internal.Operation{
+			"succeeded":   {State: domain.Succeeded, ProvisionerOperationID: "provisioner-op-1"},
+			"failed":      {State: domain.Failed, ProvisionerOperationID: "provisioner-op-2"},
+			"in-progress": {State: domain.InProgress, ProvisionerOperationID: "provisioner-op-3"},
+			"pending":     {State: domain.InProgress},
+			"canceled":    {State: internal.OperationCanceled},
+		} {
+			op.ID = id
+			op.OrchestrationID = fixID
+			err = db.Operations().InsertUpgradeKymaOperation(internal.UpgradeKymaOperation{RuntimeOperation: internal.RuntimeOperation{Operation: op}})
+			require.NoError(t, err)
+		}
+		err = db.Operations().InsertUpgradeKymaOperation(internal.UpgradeKymaOperation{
+			RuntimeOperation: internal.RuntimeOperation{
+				Operation: internal.Operation{ID: "other", OrchestrationID: "other-orchestration", State: domain.Succeeded},
+			},
+		})
+		require.NoError(t, err)
+
+		logs := logrus.New()
+		q := process.NewQueue(&testExecutor{}, logs)
+		kymaHandler := handlers.NewKymaOrchestrationHandler(db.Operations(), db.Orchestrations(), db.RuntimeStates(), 100, orchestration.Config{}, q, nil, nil, event.NewPubSub(), logs)
+
+		router := mux.NewRouter()
+		kymaHandler.AttachRoutes(router)
+
+		req, err := http.NewRequest(http.MethodGet, orchestration.StatsPath(fixID), nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var out orchestration.StatsResponse
+		err = json.Unmarshal(rr.Body.Bytes(), &out)
+		require.NoError(t, err)
+		assert.Equal(t, internal.InProgress, out.State)
+		assert.Equal(t, 5, out.Total)
+		assert.Equal(t, 60, out.Progress)
+		assert.Equal(t, map[string]int{
+			string(internal.OperationPending):  1,
+			string(domain.InProgress):          1,
+			string(domain.Succeeded):           1,
+			string(domain.Failed):              1,
+			string(internal.OperationCanceled): 1,
+			string(internal.OperationSkipped):  0,
+		}, out.Operations)
+
+		// given
+		req, err = http.NewRequest(http.MethodGet, orchestration.StatsPath("not-existing"), nil)
+		require.NoError(t, err)
+		rr = httptest.NewRecorder()
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("report", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+
+		createdAt := time.Now().Add(-time.Hour)
+		err := db.Orchestrations().Insert(internal.Orchestration{
+			OrchestrationID: fixID,
+			State:           internal.Failed,
+			CreatedAt:       createdAt,
+			UpdatedAt:       createdAt.Add(30 * time.Minute),
+		})
+		require.NoError(t, err)
+		err = db.Operations().InsertUpgradeKymaOperation(internal.UpgradeKymaOperation{
+			RuntimeOperation: internal.RuntimeOperation{
+				Operation: internal.Operation{
+					ID:              fixID,
+					OrchestrationID: fixID,
+					State:           domain.Failed,
+					Description:     "provisioner operation failed",
+					CreatedAt:       createdAt,
+					UpdatedAt:       createdAt.Add(20 * time.Minute),
+				},
+				RuntimeID:    "runtime-id",
+				ResultReason: "upgrade failed",
+			},
+		})
+		require.NoError(t, err)
+		err = db.RuntimeStates().Insert(internal.RuntimeState{
+			ID:          "runtime-state-id",
+			OperationID: fixID,
+			RuntimeID:   "runtime-id",
+			KymaConfig:  gqlschema.KymaConfigInput{Version: "1.17.0"},
+		})
+		require.NoError(t, err)
+
+		logs := logrus.New()
+		q := process.NewQueue(&testExecutor{}, logs)
+		kymaHandler := handlers.NewKymaOrchestrationHandler(db.Operations(), db.Orchestrations(), db.RuntimeStates(), 100, orchestration.Config{}, q, nil, nil, event.NewPubSub(), logs)
+
+		router := mux.NewRouter()
+		kymaHandler.AttachRoutes(router)
+
+		req, err := http.NewRequest(http.MethodGet, orchestration.ReportPath(fixID), nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var out orchestration.ReportResponse
+		err = json.Unmarshal(rr.Body.Bytes(), &out)
+		require.NoError(t, err)
+		assert.Equal(t, fixID, out.OrchestrationID)
+		assert.Equal(t, "30m0s", out.Duration)
+		assert.Equal(t, map[string]int{string(domain.Failed): 1}, out.Summary)
+		require.Len(t, out.Runtimes, 1)
+		assert.Equal(t, "runtime-id", out.Runtimes[0].RuntimeID)
+		assert.Equal(t, "provisioner operation failed", out.Runtimes[0].Error)
+		assert.Equal(t, "1.17.0", out.Runtimes[0].KymaVersion)
+		assert.Equal(t, "20m0s", out.Runtimes[0].Duration)
+
+		// given
+		req, err = http.NewRequest(http.MethodGet, orchestration.ReportPath(fixID)+"?format=csv", nil)
+		require.NoError(t, err)
+		rr = httptest.NewRecorder()
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "text/csv", rr.Header().Get("Content-Type"))
+
+		records, err := csv.NewReader(rr.Body).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 2)
+		assert.Equal(t, "runtimeID", records[0][0])
+		assert.Equal(t, "runtime-id", records[1][0])
+		assert.Equal(t, "upgrade failed", records[1][7])
+
+		// given
+		req, err = http.NewRequest(http.MethodGet, orchestration.ReportPath(fixID)+"?format=xml", nil)
+		require.NoError(t, err)
+		rr = httptest.NewRecorder()
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+		// given
+		req, err = http.NewRequest(http.MethodGet, orchestration.ReportPath("not-existing-id"), nil)
+		require.NoError(t, err)
+		rr = httptest.NewRecorder()
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
 }
 
 type testExecutor struct{}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/orchestration"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
+
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/pkg/errors"
+)
+
+const (
+	reportFormatJSON = "json"
+	reportFormatCSV  = "csv"
+)
+
+var reportCSVHeader = []string{
+	"runtimeID", "globalAccountID", "subAccountID", "shootName", "servicePlanName", "operationID",
+	"state", "resultReason", "error", "kymaVersion", "retryCount", "startedAt", "finishedAt", "duration",
+}
+
+// buildReport collects the results of all operations of the orchestration
+func (h *kymaHandler) buildReport(o *internal.Orchestration, operations []runtimeOperation) (orchestration.ReportResponse, error) {
+	report := orchestration.ReportResponse{
+		OrchestrationID: o.OrchestrationID,
+		State:           o.State,
+		Description:     o.Description,
+		CreatedAt:       o.CreatedAt,
+		UpdatedAt:       o.UpdatedAt,
+		DryRun:          o.Parameters.DryRun,
+		Targets:         o.Parameters.Targets,
+		Summary:         make(map[string]int),
+		Runtimes:        make([]orchestration.RuntimeReport, 0, len(operations)),
+	}
+	if o.IsFinished() {
+		report.Duration = o.UpdatedAt.Sub(o.CreatedAt).String()
+	}
+
+	for _, op := range operations {
+		runtimeReport, err := h.runtimeReport(op)
+		if err != nil {
+			return orchestration.ReportResponse{}, err
+		}
+		report.Summary[runtimeReport.State]++
+		report.Runtimes = append(report.Runtimes, runtimeReport)
+	}
+
+	return report, nil
+}
+
+func (h *kymaHandler) runtimeReport(op runtimeOperation) (orchestration.RuntimeReport, error) {
+	report := orchestration.RuntimeReport{
+		RuntimeID:       op.RuntimeID,
+		GlobalAccountID: op.GlobalAccountID,
+		SubAccountID:    op.SubAccountID,
+		ShootName:       op.ShootName,
+		OperationID:     op.Operation.ID,
+		State:           string(op.Operation.State),
+		ResultReason:    op.ResultReason,
+		RetryCount:      op.RetryCount,
+		StartedAt:       op.Operation.CreatedAt,
+	}
+	if plan, ok := broker.Plans[op.PlanID]; ok {
+		report.ServicePlanName = plan.PlanDefinition.Name
+	}
+	if op.Operation.State == domain.Failed {
+		report.Error = op.Operation.Description
+	}
+	if op.Operation.State != domain.InProgress {
+		report.FinishedAt = op.Operation.UpdatedAt
+		report.Duration = op.Operation.UpdatedAt.Sub(op.Operation.CreatedAt).String()
+	}
+
+	// the runtime state is stored once the upgrade is triggered in the provisioner
+	state, err := h.runtimeStates.GetByOperationID(op.Operation.ID)
+	switch {
+	case err == nil:
+		report.KymaVersion = state.KymaConfig.Version
+	case !dberr.IsNotFound(err):
+		return orchestration.RuntimeReport{}, errors.Wrapf(err, "while getting runtime state for operation %s", op.Operation.ID)
+	}
+
+	return report, nil
+}
+
+// writeReportCSV writes a single row per runtime, the orchestration details are available in the JSON format only
+func writeReportCSV(w io.Writer, report orchestration.ReportResponse) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(reportCSVHeader); err != nil {
+		return errors.Wrap(err, "while writing header")
+	}
+	for _, r := range report.Runtimes {
+		err := writer.Write([]string{
+			r.RuntimeID, r.GlobalAccountID, r.SubAccountID, r.ShootName, r.ServicePlanName, r.OperationID,
+			r.State, r.ResultReason, r.Error, r.KymaVersion, strconv.Itoa(r.RetryCount),
+			formatReportTime(r.StartedAt), formatReportTime(r.FinishedAt), r.Duration,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "while writing runtime %s", r.RuntimeID)
+		}
+	}
+	writer.Flush()
+
+	return writer.Error()
+}
+
+func formatReportTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -108,6 +108,101 @@ func TestUpgradeKymaManager_Execute(t *testing.T) {
 
 	})
 
+	t.Run("PendingWithResolvedRuntimes", func(t *testing.T) {
+		// given
+		store := storage.NewMemoryStorage()
+
+		runtime := internal.Runtime{
+			InstanceID: "instance-id",
+			RuntimeID:  "runtime-id",
+			ShootName:  "c-1234567",
+		}
+		resolver := &automock.RuntimeResolver{}
+		defer resolver.AssertExpectations(t)
+		resolver.On("Resolve", internal.TargetSpec{}).Return([]internal.Runtime{runtime}, nil).Once()
+
+		err := store.Operations().InsertProvisioningOperation(internal.ProvisioningOperation{
+			Operation: internal.Operation{
+				ID:         "provisioning-id",
+				InstanceID: runtime.InstanceID,
+				State:      domain.Succeeded,
+			},
+			ProvisioningParameters: `{"plan_id": "plan-id"}`,
+		})
+		require.NoError(t, err)
+
+		id := "id"
+		err = store.Orchestrations().Insert(internal.Orchestration{
+			OrchestrationID: id,
+			State:           internal.Pending,
+			Parameters: internal.OrchestrationParameters{
+				Strategy: internal.StrategySpec{
+					Type:     internal.ParallelStrategy,
+					Schedule: internal.Immediate,
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		svc := kyma.NewUpgradeKymaManager(store.Orchestrations(), store.Operations(), &succeedingExecutor{operations: store.Operations()}, resolver, poolingInterval, logrus.New())
+
+		// when
+		_, err = svc.Execute(id)
+		require.NoError(t, err)
+
+		// then
+		o, err := store.Orchestrations().GetByID(id)
+		require.NoError(t, err)
+		assert.Equal(t, internal.Succeeded, o.State)
+		assert.Equal(t, []internal.Runtime{runtime}, o.Runtimes)
+	})
+
+	t.Run("PendingWithRecentlyUpgradedRuntime", func(t *testing.T) {
+		// given
+		store := storage.NewMemoryStorage()
+
+		upgraded := internal.Runtime{InstanceID: "upgraded-instance", RuntimeID: "upgraded-runtime"}
+		resolver := &automock.RuntimeResolver{}
+		defer resolver.AssertExpectations(t)
+		resolver.On("Resolve", internal.TargetSpec{}).Return([]internal.Runtime{upgraded}, nil).Once()
+
+		err := store.Operations().InsertUpgradeKymaOperation(internal.UpgradeKymaOperation{
+			RuntimeOperation: internal.RuntimeOperation{
+				Operation: internal.Operation{
+					ID:         "previous-upgrade",
+					InstanceID: upgraded.InstanceID,
+					State:      domain.Succeeded,
+					CreatedAt:  time.Now().Add(-time.Hour),
+					UpdatedAt:  time.Now().Add(-time.Hour),
+				},
+				RuntimeID: upgraded.RuntimeID,
+			},
+		})
+		require.NoError(t, err)
+
+		id := "id"
+		err = store.Orchestrations().Insert(internal.Orchestration{
+			OrchestrationID: id,
+			State:           internal.Pending,
+			Parameters: internal.OrchestrationParameters{
+				SkipUpgradedWithin: "72h",
+			},
+		})
+		require.NoError(t, err)
+
+		svc := kyma.NewUpgradeKymaManager(store.Orchestrations(), store.Operations(), nil, resolver, poolingInterval, logrus.New())
+
+		// when
+		_, err = svc.Execute(id)
+		require.NoError(t, err)
+
+		// then
+		o, err := store.Orchestrations().GetByID(id)
+		require.NoError(t, err)
+		assert.Equal(t, internal.Succeeded, o.State)
+		assert.Empty(t, o.Runtimes)
+	})
+
 	t.Run("InProgressWithRuntimeOperations", func(t *testing.T) {
 		// given
 		store := storage.NewMemoryStorage()
@@ -162,6 +257,77 @@ func TestUpgradeKymaManager_Execute(t *testing.T) {
 		assert.Equal(t, internal.Succeeded, o.State)
 
 	})
+
+	t.Run("InProgressWithRetriedOperation", func(t *testing.T) {
+		// given
+		store := storage.NewMemoryStorage()
+
+		resolver := &automock.RuntimeResolver{}
+		defer resolver.AssertExpectations(t)
+
+		id := "id"
+		err := store.Operations().InsertUpgradeKymaOperation(internal.UpgradeKymaOperation{
+			RuntimeOperation: internal.RuntimeOperation{
+				Operation: internal.Operation{
+					ID:              id,
+					OrchestrationID: id,
+					State:           domain.InProgress,
+				},
+				RetryCount: 1,
+			},
+		})
+		require.NoError(t, err)
+
+		err = store.Orchestrations().Insert(internal.Orchestration{
+			OrchestrationID: id,
+			State:           internal.InProgress,
+			Parameters: internal.OrchestrationParameters{Strategy: internal.StrategySpec{
+				Type:     internal.ParallelStrategy,
+				Schedule: internal.Immediate,
+				Parallel: internal.ParallelStrategySpec{Workers: 1},
+			}},
+		})
+		require.NoError(t, err)
+
+		executor := &succeedingExecutor{operations: store.Operations()}
+		svc := kyma.NewUpgradeKymaManager(store.Orchestrations(), store.Operations(), executor, resolver, poolingInterval, logrus.New())
+
+		// when
+		_, err = svc.Execute(id)
+		require.NoError(t, err)
+
+		// then
+		o, err := store.Orchestrations().GetByID(id)
+		require.NoError(t, err)
+		assert.Equal(t, internal.Succeeded, o.State)
+
+		op, err := store.Operations().GetUpgradeKymaOperationByID(id)
+		require.NoError(t, err)
+		assert.Equal(t, domain.Succeeded, op.State)
+	})
+
+	t.Run("Canceling", func(t *testing.T) {
+		// given
+		store := storage.NewMemoryStorage()
+
+		resolver := &automock.RuntimeResolver{}
+		defer resolver.AssertExpectations(t)
+
+		id := "id"
+		err := store.Orchestrations().Insert(internal.Orchestration{OrchestrationID: id, State: internal.Canceling})
+		require.NoError(t, err)
+
+		svc := kyma.NewUpgradeKymaManager(store.Orchestrations(), store.Operations(), &testExecutor{}, resolver, poolingInterval, logrus.New())
+
+		// when
+		_, err = svc.Execute(id)
+		require.NoError(t, err)
+
+		// then
+		o, err := store.Orchestrations().GetByID(id)
+		require.NoError(t, err)
+		assert.Equal(t, internal.Canceled, o.State)
+	})
 }
 
 type testExecutor struct{}
@@ -169,3 +335,18 @@ type testExecutor struct{}
 func (t *testExecutor) Execute(opID string) (time.Duration, error) {
 	return 0, nil
 }
+
+// succeedingExecutor marks the executed upgrade operation as succeeded
+type succeedingExecutor struct {
+	operations storage.Operations
+}
+
+func (e *succeedingExecutor) Execute(opID string) (time.Duration, error) {
+	op, err := e.operations.GetUpgradeKymaOperationByID(opID)
+	if err != nil {
+		return 0, err
+	}
+	op.State = domain.Succeeded
+	_, err = e.operations.UpdateUpgradeKymaOperation(*op)
+	return 0, err
+}
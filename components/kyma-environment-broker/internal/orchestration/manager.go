@@ -0,0 +1,314 @@
+package orchestration
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
+
+	"github.com/google/uuid"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// OperationFactory handles the runtime operations of a specific type of the orchestration, e.g. the Kyma upgrade
+type OperationFactory interface {
+	// InsertOperation creates and stores the operation of the orchestration for the runtime of the given plan
+	InsertOperation(o internal.Orchestration, operation internal.RuntimeOperation, planID string) error
+	// ListOperationsInProgress returns the operations of the orchestration which are in progress
+	ListOperationsInProgress(orchestrationID string) ([]internal.RuntimeOperation, error)
+	// SkipOperation moves the operation which was not scheduled to the failed state with the given reason
+	SkipOperation(operationID, reason string) error
+}
+
+// manager reconciles the runtimes of the orchestration, the operations of the orchestration type are created
+// by the factory and executed with the strategy of the orchestration
+type manager struct {
+	orchestrationStorage storage.Orchestrations
+	operationStorage     storage.Operations
+	factory              OperationFactory
+	resolver             RuntimeResolver
+	executor             process.Executor
+	log                  logrus.FieldLogger
+	pollingInterval      time.Duration
+}
+
+// NewManager creates the orchestration manager which executes the operations created by the factory with the executor
+func NewManager(orchestrationStorage storage.Orchestrations, operationStorage storage.Operations, factory OperationFactory,
+	executor process.Executor, resolver RuntimeResolver, pollingInterval time.Duration, log logrus.FieldLogger) process.Executor {
+	return &manager{
+		orchestrationStorage: orchestrationStorage,
+		operationStorage:     operationStorage,
+		factory:              factory,
+		resolver:             resolver,
+		executor:             executor,
+		pollingInterval:      pollingInterval,
+		log:                  log,
+	}
+}
+
+// Execute reconciles runtimes for a given orchestration
+func (m *manager) Execute(orchestrationID string) (time.Duration, error) {
+	logger := m.log.WithField("orchestrationID", orchestrationID)
+	m.log.Infof("Processing orchestration %s", orchestrationID)
+	o, err := m.orchestrationStorage.GetByID(orchestrationID)
+	if err != nil {
+		return m.failOrchestration(o, errors.Wrap(err, "while getting orchestration"))
+	}
+	// operations of the canceling orchestration are already canceled, there is nothing to schedule
+	if o.State == internal.Canceling {
+		logger.Infof("Orchestration canceled")
+		return m.updateOrchestration(o, internal.Canceled, "orchestration canceled"), nil
+	}
+
+	operations, err := m.resolveOperations(o, o.Parameters)
+	if err != nil {
+		return m.failOrchestration(o, errors.Wrap(err, "while resolving operations"))
+	}
+
+	err = m.orchestrationStorage.Update(*o)
+	if err != nil {
+		logger.Errorf("while updating orchestration: %v", err)
+		return m.pollingInterval, nil
+	}
+	// do not perform any action if the orchestration is finished
+	if o.IsFinished() {
+		return 0, nil
+	}
+
+	strategy := m.resolveStrategy(o.Parameters.Strategy.Type, logger)
+	_, err = strategy.Execute(filterOperationsInProgress(operations), o.Parameters.Strategy)
+	if canaryErr, ok := IsCanaryFailed(err); ok {
+		logger.Warnf("Canary batch failed: %s", canaryErr)
+		err = m.skipOperations(canaryErr.Skipped, "canary batch of the orchestration failed")
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, "while executing upgrade strategy")
+	}
+
+	err = m.waitForCompletion(o)
+	if err != nil {
+		return 0, errors.Wrap(err, "while checking operations results")
+	}
+
+	err = m.orchestrationStorage.Update(*o)
+	if err != nil {
+		logger.Errorf("while updating orchestration: %v", err)
+		return m.pollingInterval, nil
+	}
+
+	logger.Infof("Finished processing orchestration, state: %s, report: %s", o.State, ReportPath(o.OrchestrationID))
+	return 0, nil
+}
+
+func (m *manager) resolveOperations(o *internal.Orchestration, params internal.OrchestrationParameters) ([]internal.RuntimeOperation, error) {
+	var result []internal.RuntimeOperation
+	// operations of the orchestration in progress are already created, the ones which are not finished
+	// (retried or interrupted by the broker restart) are executed again
+	if o.State == internal.InProgress {
+		return m.factory.ListOperationsInProgress(o.OrchestrationID)
+	}
+	if o.State == internal.Pending {
+		runtimes, err := m.resolver.Resolve(params.Targets)
+		if err != nil {
+			return result, errors.Wrap(err, "while resolving targets")
+		}
+		runtimes, err = m.skipRecentlyUpgraded(runtimes, params.SkipUpgradedWithin)
+		if err != nil {
+			return result, errors.Wrap(err, "while skipping recently upgraded runtimes")
+		}
+		// the resolved runtimes are persisted before the operations are created,
+		// so the clusters touched by the orchestration are visible as soon as possible
+		o.Runtimes = runtimes
+		err = m.orchestrationStorage.Update(*o)
+		if err != nil {
+			return result, errors.Wrap(err, "while saving resolved runtimes")
+		}
+
+		for _, r := range runtimes {
+			// we set planID fetched from provisioning parameters
+			po, err := m.operationStorage.GetProvisioningOperationByInstanceID(r.InstanceID)
+			if err != nil {
+				return nil, errors.Wrapf(err, "while getting provisioning operation for instance id %s", r.InstanceID)
+			}
+			provisioningParams, err := po.GetProvisioningParameters()
+			if err != nil {
+				return nil, errors.Wrap(err, "while getting provisioning operation")
+			}
+			windowBegin, windowEnd := resolveWindowTime(r.MaintenanceWindowBegin, r.MaintenanceWindowEnd)
+
+			op := internal.RuntimeOperation{
+				Operation: internal.Operation{
+					ID:              uuid.New().String(),
+					Version:         0,
+					CreatedAt:       time.Now(),
+					UpdatedAt:       time.Now(),
+					InstanceID:      r.InstanceID,
+					State:           domain.InProgress,
+					Description:     "Operation created",
+					OrchestrationID: o.OrchestrationID,
+				},
+				DryRun:                 params.DryRun,
+				ShootName:              r.ShootName,
+				MaintenanceWindowBegin: windowBegin,
+				MaintenanceWindowEnd:   windowEnd,
+				RuntimeID:              r.RuntimeID,
+				GlobalAccountID:        r.GlobalAccountID,
+				SubAccountID:           r.SubAccountID,
+			}
+			err = m.factory.InsertOperation(*o, op, provisioningParams.PlanID)
+			if err != nil {
+				return nil, errors.Wrapf(err, "while inserting operation for runtime id %s", r.RuntimeID)
+			}
+			result = append(result, op)
+		}
+
+		if len(runtimes) != 0 {
+			o.State = internal.InProgress
+		} else {
+			o.State = internal.Succeeded
+		}
+		o.Description = fmt.Sprintf("Scheduled %d operations", len(runtimes))
+	}
+
+	return result, nil
+}
+
+// skipRecentlyUpgraded removes the runtimes successfully upgraded within the given period,
+// which prevents back-to-back upgrades of the same runtime by overlapping orchestrations
+func (m *manager) skipRecentlyUpgraded(runtimes []internal.Runtime, period string) ([]internal.Runtime, error) {
+	within, err := ParseSkipUpgradedWithin(period)
+	if err != nil || within == 0 {
+		return runtimes, err
+	}
+
+	upgradedIDs, err := m.operationStorage.ListInstanceIDsUpgradedSince(time.Now().Add(-within))
+	if err != nil {
+		return nil, errors.Wrap(err, "while listing upgraded instances")
+	}
+	upgraded := make(map[string]struct{}, len(upgradedIDs))
+	for _, id := range upgradedIDs {
+		upgraded[id] = struct{}{}
+	}
+
+	result := make([]internal.Runtime, 0, len(runtimes))
+	for _, r := range runtimes {
+		if _, found := upgraded[r.InstanceID]; found {
+			m.log.Infof("Skipping runtime %s upgraded within %s", r.RuntimeID, within)
+			continue
+		}
+		result = append(result, r)
+	}
+	return result, nil
+}
+
+func (m *manager) resolveStrategy(sType internal.StrategyType, log logrus.FieldLogger) Strategy {
+	switch sType {
+	case internal.ParallelStrategy:
+		return NewParallelOrchestrationStrategy(m.executor, m.pollingInterval, log)
+	case internal.CanaryStrategy:
+		return NewCanaryOrchestrationStrategy(m.executor, m.operationStorage, m.pollingInterval, log)
+	}
+	return nil
+}
+
+// skipOperations moves the operations which were not scheduled to the failed state,
+// so they are executed again when the orchestration is retried
+func (m *manager) skipOperations(operations []internal.RuntimeOperation, reason string) error {
+	for _, op := range operations {
+		if err := m.factory.SkipOperation(op.ID, reason); err != nil {
+			return errors.Wrapf(err, "while updating operation %s", op.ID)
+		}
+	}
+
+	return nil
+}
+
+func (m *manager) failOrchestration(o *internal.Orchestration, err error) (time.Duration, error) {
+	m.log.Errorf("orchestration %s failed: %s", o.OrchestrationID, err)
+	return m.updateOrchestration(o, internal.Failed, err.Error()), nil
+}
+
+func (m *manager) updateOrchestration(o *internal.Orchestration, state, description string) time.Duration {
+	o.State = state
+	o.Description = description
+	err := m.orchestrationStorage.Update(*o)
+	if err != nil {
+		if !dberr.IsNotFound(err) {
+			m.log.Errorf("while updating orchestration: %v", err)
+			return time.Minute
+		}
+	}
+	return 0
+}
+
+// waitForCompletion waits until all operations of the orchestration are finished, the operations which do not
+// finish on their own are failed by their processes or by the stale operations detector
+func (m *manager) waitForCompletion(o *internal.Orchestration) error {
+	var stats map[domain.LastOperationState]int
+	err := wait.PollInfinite(m.pollingInterval, func() (bool, error) {
+		s, err := m.operationStorage.GetOperationStatsForOrchestration(o.OrchestrationID)
+		if err != nil {
+			m.log.Errorf("while getting operations: %v", err)
+			return false, nil
+		}
+		stats = s
+
+		numberOfInProgress := stats[domain.InProgress] + stats[internal.OperationPending]
+		return numberOfInProgress == 0, nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "while waiting for scheduled operations to finish")
+	}
+
+	orchestrationState := internal.Succeeded
+	if stats[domain.Failed] > 0 {
+		orchestrationState = internal.Failed
+	}
+
+	// the orchestration could be canceled while waiting for the operations
+	current, err := m.orchestrationStorage.GetByID(o.OrchestrationID)
+	if err != nil {
+		return errors.Wrap(err, "while getting orchestration")
+	}
+	if current.State == internal.Canceling {
+		orchestrationState = internal.Canceled
+		o.Description = "orchestration canceled"
+	}
+
+	o.State = orchestrationState
+
+	return nil
+}
+
+func filterOperationsInProgress(ops []internal.RuntimeOperation) []internal.RuntimeOperation {
+	result := make([]internal.RuntimeOperation, 0)
+
+	for _, op := range ops {
+		if op.State == domain.InProgress {
+			result = append(result, op)
+		}
+	}
+
+	return result
+}
+
+// resolves when is the next occurrence of the time window
+func resolveWindowTime(beginTime, endTime time.Time) (time.Time, time.Time) {
+	n := time.Now()
+	start := time.Date(n.Year(), n.Month(), n.Day(), beginTime.Hour(), beginTime.Minute(), beginTime.Second(), beginTime.Nanosecond(), beginTime.Location())
+	end := time.Date(n.Year(), n.Month(), n.Day(), endTime.Hour(), endTime.Minute(), endTime.Second(), endTime.Nanosecond(), endTime.Location())
+
+	// if time window has already passed we wait until next day
+	if start.Before(n) && end.Before(n) {
+		start = start.AddDate(0, 0, 1)
+		end = end.AddDate(0, 0, 1)
+	}
+
+	return start, end
+}
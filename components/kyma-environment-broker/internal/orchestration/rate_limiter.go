@@ -0,0 +1,77 @@
+package orchestration
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// limiterIdleTimeout defines after which time an unused orchestration limiter is dropped
+const limiterIdleTimeout = 10 * time.Minute
+
+type orchestrationLimiter struct {
+	limiter  *rate.Limiter
+	lastUsed time.Time
+}
+
+// ProvisionerRateLimiter limits the number of Provisioner mutations (e.g. upgradeRuntime) triggered per minute
+// by the operations of a single orchestration. One token bucket is kept per orchestration and is shared
+// by all workers processing the orchestration's operations.
+type ProvisionerRateLimiter struct {
+	mu sync.Mutex
+
+	every    time.Duration
+	limiters map[string]*orchestrationLimiter
+}
+
+// NewProvisionerRateLimiter creates a limiter allowing mutationsPerMinute Provisioner mutations per orchestration.
+// The value lower or equal to 0 disables the limit.
+func NewProvisionerRateLimiter(mutationsPerMinute int) *ProvisionerRateLimiter {
+	var every time.Duration
+	if mutationsPerMinute > 0 {
+		every = time.Minute / time.Duration(mutationsPerMinute)
+	}
+	return &ProvisionerRateLimiter{
+		every:    every,
+		limiters: make(map[string]*orchestrationLimiter),
+	}
+}
+
+// Reserve takes a token from the bucket of the given orchestration. It returns 0 if the Provisioner mutation
+// can be performed now, otherwise the time after which the caller should try again.
+func (l *ProvisionerRateLimiter) Reserve(orchestrationID string) time.Duration {
+	if l.every == 0 || orchestrationID == "" {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.removeIdle(now)
+
+	ol, found := l.limiters[orchestrationID]
+	if !found {
+		ol = &orchestrationLimiter{limiter: rate.NewLimiter(rate.Every(l.every), 1)}
+		l.limiters[orchestrationID] = ol
+	}
+	ol.lastUsed = now
+
+	reservation := ol.limiter.ReserveN(now, 1)
+	delay := reservation.DelayFrom(now)
+	if delay > 0 {
+		// the token is not taken, the caller must call Reserve again after the delay
+		reservation.CancelAt(now)
+	}
+
+	return delay
+}
+
+func (l *ProvisionerRateLimiter) removeIdle(now time.Time) {
+	for id, ol := range l.limiters {
+		if now.Sub(ol.lastUsed) > limiterIdleTimeout {
+			delete(l.limiters, id)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package orchestration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProvisionerRateLimiter_Reserve(t *testing.T) {
+	t.Run("should limit mutations of the orchestration", func(t *testing.T) {
+		// given
+		limiter := NewProvisionerRateLimiter(2)
+
+		// when
+		first := limiter.Reserve("orchestration-1")
+		second := limiter.Reserve("orchestration-1")
+		third := limiter.Reserve("orchestration-1")
+
+		// then
+		assert.Zero(t, first)
+		assert.True(t, second > 0 && second <= 30*time.Second, "unexpected delay %s", second)
+		assert.True(t, third > 0 && third <= 30*time.Second, "unexpected delay %s", third)
+	})
+
+	t.Run("should limit every orchestration separately", func(t *testing.T) {
+		// given
+		limiter := NewProvisionerRateLimiter(1)
+
+		// when
+		first := limiter.Reserve("orchestration-1")
+		second := limiter.Reserve("orchestration-2")
+
+		// then
+		assert.Zero(t, first)
+		assert.Zero(t, second)
+	})
+
+	t.Run("should not limit mutations when disabled", func(t *testing.T) {
+		// given
+		limiter := NewProvisionerRateLimiter(0)
+
+		// when
+		first := limiter.Reserve("orchestration-1")
+		second := limiter.Reserve("orchestration-1")
+
+		// then
+		assert.Zero(t, first)
+		assert.Zero(t, second)
+	})
+}
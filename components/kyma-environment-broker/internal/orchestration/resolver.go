@@ -9,10 +9,8 @@ import (
 	"github.com/sirupsen/logrus"
 
 	brokerapi "github.com/pivotal-cf/brokerapi/v7/domain"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	gardenerapi "github.com/gardener/gardener/pkg/apis/core/v1beta1"
-	gardenerclient "github.com/gardener/gardener/pkg/client/core/clientset/versioned/typed/core/v1beta1"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession/dbmodel"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/predicate"
@@ -22,6 +20,7 @@ type instanceOperationStatus struct {
 	internal.Instance
 	provisionState   brokerapi.LastOperationState
 	deprovisionState brokerapi.LastOperationState
+	suspensionState  brokerapi.LastOperationState
 }
 
 // InstanceLister is the interface to get InstanceWithOperation objects from KEB storage
@@ -33,12 +32,10 @@ type InstanceLister interface {
 // GardenerRuntimeResolver is the default resolver which implements the RuntimeResolver interface.
 // This resolver uses the Shoot resources on the Gardener cluster to resolve the runtime targets.
 //
-// Naive implementation, listing all the shoots and perfom filtering on the result.
-// The logic could be optimized with k8s client cache using shoot lister / indexer.
+// The shoots are listed with the ShootLister, usually the ShootCache, and filtered by the target specs.
 // The implementation is thread safe, i.e. it is safe to call Resolve() from multiple threads concurrently.
 type GardenerRuntimeResolver struct {
-	gardenerClient     gardenerclient.CoreV1beta1Interface
-	gardenerNamespace  string
+	shootLister        ShootLister
 	instanceLister     InstanceLister
 	instanceOperations map[string]*instanceOperationStatus
 	instanceMutex      sync.RWMutex
@@ -53,10 +50,9 @@ const (
 )
 
 // NewGardenerRuntimeResolver constructs a GardenerRuntimeResolver with the mandatory input parameters.
-func NewGardenerRuntimeResolver(gardenerClient gardenerclient.CoreV1beta1Interface, gardenerNamespace string, lister InstanceLister, logger logrus.FieldLogger) *GardenerRuntimeResolver {
+func NewGardenerRuntimeResolver(shootLister ShootLister, lister InstanceLister, logger logrus.FieldLogger) *GardenerRuntimeResolver {
 	return &GardenerRuntimeResolver{
-		gardenerClient:     gardenerClient,
-		gardenerNamespace:  gardenerNamespace,
+		shootLister:        shootLister,
 		instanceLister:     lister,
 		instanceOperations: map[string]*instanceOperationStatus{},
 		logger:             logger.WithField("orchestration", "resolver"),
@@ -68,9 +64,9 @@ func (resolver *GardenerRuntimeResolver) Resolve(targets internal.TargetSpec) ([
 	runtimeIncluded := map[string]bool{}
 	runtimeExcluded := map[string]bool{}
 	runtimes := []internal.Runtime{}
-	shoots, err := resolver.getAllShoots()
+	shoots, err := resolver.shootLister.ListShoots()
 	if err != nil {
-		return nil, errors.Wrapf(err, "while listing gardener shoots in namespace %s", resolver.gardenerNamespace)
+		return nil, errors.Wrap(err, "while listing gardener shoots")
 	}
 	err = resolver.syncInstanceOperations()
 	if err != nil {
@@ -105,15 +101,6 @@ func (resolver *GardenerRuntimeResolver) Resolve(targets internal.TargetSpec) ([
 	return runtimes, nil
 }
 
-func (resolver *GardenerRuntimeResolver) getAllShoots() ([]gardenerapi.Shoot, error) {
-	shootList, err := resolver.gardenerClient.Shoots(resolver.gardenerNamespace).List(metav1.ListOptions{})
-	if err != nil {
-		return nil, err
-	}
-
-	return shootList.Items, nil
-}
-
 func (resolver *GardenerRuntimeResolver) syncInstanceOperations() error {
 	instances, err := resolver.instanceLister.FindAllJoinedWithOperations(predicate.SortAscByCreatedAt())
 	if err != nil {
@@ -129,6 +116,7 @@ func (resolver *GardenerRuntimeResolver) syncInstanceOperations() error {
 				inst.Instance,
 				"",
 				"",
+				"",
 			}
 			resolver.instanceOperations[inst.RuntimeID] = runtimeOpStat
 		}
@@ -137,6 +125,8 @@ func (resolver *GardenerRuntimeResolver) syncInstanceOperations() error {
 			runtimeOpStat.provisionState = brokerapi.LastOperationState(inst.State.String)
 		case dbmodel.OperationTypeDeprovision:
 			runtimeOpStat.deprovisionState = brokerapi.LastOperationState(inst.State.String)
+		case dbmodel.OperationTypeSuspension:
+			runtimeOpStat.suspensionState = brokerapi.LastOperationState(inst.State.String)
 		}
 	}
 
@@ -157,6 +147,7 @@ func (resolver *GardenerRuntimeResolver) resolveRuntimeTarget(rt internal.Runtim
 		// Skip runtimes for which
 		//  - there is no succeeded instance provision operation in DB
 		//  - deprovision operation exists in DB
+		//  - the cluster is hibernated or suspension operation is in progress
 		runtimeID := shoot.Annotations[runtimeIDAnnotation]
 		if runtimeID == "" {
 			resolver.logger.Errorf("Failed to get runtimeID from %s annotation for Shoot %s", runtimeIDAnnotation, shoot.Name)
@@ -171,6 +162,10 @@ func (resolver *GardenerRuntimeResolver) resolveRuntimeTarget(rt internal.Runtim
 			resolver.logger.Infof("Skipping Shoot %s (runtimeID: %s, instanceID %s) due to provisioning/deprovisioning state: %s/%s", shoot.Name, runtimeID, instanceOpStatus.InstanceID, instanceOpStatus.provisionState, instanceOpStatus.deprovisionState)
 			continue
 		}
+		if isHibernated(shoot) || instanceOpStatus.suspensionState == brokerapi.InProgress {
+			resolver.logger.Infof("Skipping Shoot %s (runtimeID: %s, instanceID %s) due to suspension of the runtime", shoot.Name, runtimeID, instanceOpStatus.InstanceID)
+			continue
+		}
 		maintenanceWindowBegin, err := time.Parse(maintenanceWindowFormat, shoot.Spec.Maintenance.TimeWindow.Begin)
 		if err != nil {
 			resolver.logger.Errorf("Failed to parse maintenanceWindowBegin value %s of shoot %s ", shoot.Spec.Maintenance.TimeWindow.Begin, shoot.Name)
@@ -221,6 +216,14 @@ func (resolver *GardenerRuntimeResolver) resolveRuntimeTarget(rt internal.Runtim
 			}
 		}
 
+		// Perform match against Shoot name regexp
+		if rt.Shoot != "" {
+			matched, err := regexp.MatchString(rt.Shoot, shoot.Name)
+			if err != nil || !matched {
+				continue
+			}
+		}
+
 		// Check if target: all is specified
 		if rt.Target != "" && rt.Target != internal.TargetAll {
 			continue
@@ -232,6 +235,14 @@ func (resolver *GardenerRuntimeResolver) resolveRuntimeTarget(rt internal.Runtim
 	return runtimes, nil
 }
 
+// isHibernated tells whether the hibernation of the shoot is requested or the shoot is still hibernated
+func isHibernated(shoot gardenerapi.Shoot) bool {
+	if shoot.Spec.Hibernation != nil && shoot.Spec.Hibernation.Enabled != nil && *shoot.Spec.Hibernation.Enabled {
+		return true
+	}
+	return shoot.Status.IsHibernated
+}
+
 func (*GardenerRuntimeResolver) runtimeFromOperationStatus(opStatus *instanceOperationStatus, shootName string, windowBegin, windowEnd time.Time) internal.Runtime {
 	return internal.Runtime{
 		InstanceID:             opStatus.InstanceID,
@@ -44,7 +44,7 @@ func TestResolver_Resolve(t *testing.T) {
 	lister := newInstanceListerMock()
 	defer lister.AssertExpectations(t)
 	logger := logger.NewLogDummy()
-	resolver := NewGardenerRuntimeResolver(client, shootNamespace, lister, logger)
+	resolver := NewGardenerRuntimeResolver(NewShootCache(client.Shoots(shootNamespace), 0, logger), lister, logger)
 
 	expectedRuntime1 := expectedRuntime{
 		shoot:    &shoot1,
@@ -161,6 +161,17 @@ func TestResolver_Resolve(t *testing.T) {
 			},
 			ExpectedRuntimes: []expectedRuntime{expectedRuntime2, expectedRuntime3},
 		},
+		"IncludeShoot": {
+			Target: internal.TargetSpec{
+				Include: []internal.RuntimeTarget{
+					{
+						Shoot: "shoot[13]$",
+					},
+				},
+				Exclude: nil,
+			},
+			ExpectedRuntimes: []expectedRuntime{expectedRuntime1, expectedRuntime3},
+		},
 	} {
 		t.Run(tn, func(t *testing.T) {
 			// when
@@ -190,7 +201,7 @@ func TestResolver_Resolve_GardenerFailure(t *testing.T) {
 	lister := newInstanceListerMock()
 	defer lister.AssertExpectations(t)
 	logger := logger.NewLogDummy()
-	resolver := NewGardenerRuntimeResolver(client, shootNamespace, lister, logger)
+	resolver := NewGardenerRuntimeResolver(NewShootCache(client.Shoots(shootNamespace), 0, logger), lister, logger)
 
 	// when
 	runtimes, err := resolver.Resolve(internal.TargetSpec{
@@ -217,7 +228,7 @@ func TestResolver_Resolve_StorageFailure(t *testing.T) {
 	)
 	defer lister.AssertExpectations(t)
 	logger := logger.NewLogDummy()
-	resolver := NewGardenerRuntimeResolver(client, shootNamespace, lister, logger)
+	resolver := NewGardenerRuntimeResolver(NewShootCache(client.Shoots(shootNamespace), 0, logger), lister, logger)
 
 	// when
 	runtimes, err := resolver.Resolve(internal.TargetSpec{
@@ -234,6 +245,55 @@ func TestResolver_Resolve_StorageFailure(t *testing.T) {
 	assert.Len(t, runtimes, 0)
 }
 
+func TestResolver_Resolve_SuspendedRuntimes(t *testing.T) {
+	// given
+	hibernated := true
+	hibernatedShoot := fixShoot(1, globalAccountID1, region1)
+	hibernatedShoot.Spec.Hibernation = &gardenerapi.Hibernation{Enabled: &hibernated}
+	resumingShoot := fixShoot(2, globalAccountID1, region1)
+	resumingShoot.Status.IsHibernated = true
+	suspendingShoot := fixShoot(3, globalAccountID1, region1)
+	runningShoot := fixShoot(4, globalAccountID1, region1)
+
+	fake := &k8stesting.Fake{}
+	client := &gardenerclient_fake.FakeCoreV1beta1{
+		Fake: fake,
+	}
+	fake.AddReactor("list", "shoots", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, &gardenerapi.ShootList{Items: []gardenerapi.Shoot{hibernatedShoot, resumingShoot, suspendingShoot, runningShoot}}, nil
+	})
+	lister := &automock.InstanceLister{}
+	lister.On("FindAllJoinedWithOperations", mock.Anything).Return(
+		[]internal.InstanceWithOperation{
+			fixInstanceWithOperation(1, globalAccountID1, string(dbmodel.OperationTypeProvision), string(brokerapi.Succeeded), plan1),
+			fixInstanceWithOperation(1, globalAccountID1, string(dbmodel.OperationTypeSuspension), string(brokerapi.Succeeded), plan1),
+			fixInstanceWithOperation(2, globalAccountID1, string(dbmodel.OperationTypeProvision), string(brokerapi.Succeeded), plan1),
+			fixInstanceWithOperation(3, globalAccountID1, string(dbmodel.OperationTypeProvision), string(brokerapi.Succeeded), plan1),
+			fixInstanceWithOperation(3, globalAccountID1, string(dbmodel.OperationTypeSuspension), string(brokerapi.InProgress), plan1),
+			fixInstanceWithOperation(4, globalAccountID1, string(dbmodel.OperationTypeProvision), string(brokerapi.Succeeded), plan1),
+			fixInstanceWithOperation(4, globalAccountID1, string(dbmodel.OperationTypeSuspension), string(brokerapi.Succeeded), plan1),
+		},
+		nil,
+	)
+	defer lister.AssertExpectations(t)
+	logger := logger.NewLogDummy()
+	resolver := NewGardenerRuntimeResolver(NewShootCache(client.Shoots(shootNamespace), 0, logger), lister, logger)
+
+	// when
+	runtimes, err := resolver.Resolve(internal.TargetSpec{
+		Include: []internal.RuntimeTarget{
+			{
+				Target: internal.TargetAll,
+			},
+		},
+	})
+
+	// then
+	require.NoError(t, err)
+	require.Len(t, runtimes, 1)
+	assert.Equal(t, "runtime-id-4", runtimes[0].RuntimeID)
+}
+
 var (
 	shoot1                  = fixShoot(1, globalAccountID1, region1)
 	shoot2                  = fixShoot(2, globalAccountID1, region2)
@@ -0,0 +1,159 @@
+package orchestration
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+
+	gardenerapi "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	gardenerclient "github.com/gardener/gardener/pkg/client/core/clientset/versioned/typed/core/v1beta1"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// watchRetryInterval is the time after which the closed or failed watch of the shoots is started again
+const watchRetryInterval = 5 * time.Second
+
+// ShootLister is the interface to list the shoots of the runtimes
+type ShootLister interface {
+	ListShoots() ([]gardenerapi.Shoot, error)
+}
+
+// ShootCache keeps the listing of the shoots, so the back-to-back orchestrations do not list all the shoots
+// from the Gardener cluster again. The listing expires after the TTL and it is invalidated when the watch
+// started with Run reports a shoot which was added, removed or changed in the way relevant for the resolver.
+// The TTL 0 disables the caching. The implementation is thread safe.
+type ShootCache struct {
+	shoots gardenerclient.ShootInterface
+	ttl    time.Duration
+	log    logrus.FieldLogger
+
+	mu         sync.Mutex
+	items      []gardenerapi.Shoot
+	index      map[string]int
+	expiresAt  time.Time
+	generation int64
+}
+
+// NewShootCache constructs a ShootCache for the given shoots client
+func NewShootCache(shoots gardenerclient.ShootInterface, ttl time.Duration, log logrus.FieldLogger) *ShootCache {
+	return &ShootCache{
+		shoots: shoots,
+		ttl:    ttl,
+		log:    log.WithField("orchestration", "shoot-cache"),
+	}
+}
+
+// ListShoots returns the cached shoots, the shoots are listed from the Gardener cluster if the cache
+// expired or was invalidated. The returned shoots must not be modified.
+func (c *ShootCache) ListShoots() ([]gardenerapi.Shoot, error) {
+	c.mu.Lock()
+	if c.index != nil && time.Now().Before(c.expiresAt) {
+		shoots := c.items
+		c.mu.Unlock()
+		return shoots, nil
+	}
+	generation := c.generation
+	c.mu.Unlock()
+
+	shootList, err := c.shoots.List(metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "while listing shoots")
+	}
+	if c.ttl <= 0 {
+		return shootList.Items, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// the listing is not stored if the cache was invalidated in the meantime, because it may be already outdated
+	if generation == c.generation {
+		c.items = shootList.Items
+		c.index = make(map[string]int, len(shootList.Items))
+		for i, shoot := range shootList.Items {
+			c.index[shoot.Name] = i
+		}
+		c.expiresAt = time.Now().Add(c.ttl)
+	}
+
+	return shootList.Items, nil
+}
+
+// Invalidate drops the cached shoots, the next call of ListShoots lists the shoots from the Gardener cluster
+func (c *ShootCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.invalidateLocked()
+}
+
+// Run watches the shoots in the background and invalidates the cache when they change, until the context is done
+func (c *ShootCache) Run(ctx context.Context) {
+	if c.ttl <= 0 {
+		return
+	}
+	go wait.Until(func() {
+		if err := c.watch(ctx); err != nil {
+			c.log.Warnf("while watching shoots: %s", err)
+		}
+	}, watchRetryInterval, ctx.Done())
+}
+
+func (c *ShootCache) watch(ctx context.Context) error {
+	watcher, err := c.shoots.Watch(metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrap(err, "while starting the watch")
+	}
+	defer watcher.Stop()
+	// the events sent before the watch was started are not known, so the cached listing can be already outdated
+	c.Invalidate()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				c.Invalidate()
+				return errors.New("the watch was closed")
+			}
+			c.onEvent(event)
+		}
+	}
+}
+
+func (c *ShootCache) onEvent(event watch.Event) {
+	shoot, ok := event.Object.(*gardenerapi.Shoot)
+	if !ok {
+		c.Invalidate()
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if event.Type == watch.Modified {
+		if i, found := c.index[shoot.Name]; found && !resolverFieldsChanged(c.items[i], *shoot) {
+			return
+		}
+	}
+	c.log.Debugf("Shoot %s was %s, invalidating the cache", shoot.Name, event.Type)
+	c.invalidateLocked()
+}
+
+func (c *ShootCache) invalidateLocked() {
+	c.items = nil
+	c.index = nil
+	c.generation++
+}
+
+// resolverFieldsChanged reports whether the shoot changed in the fields used to resolve the runtime targets,
+// the frequent changes of the shoot status do not invalidate the cache
+func resolverFieldsChanged(old, new gardenerapi.Shoot) bool {
+	return old.Annotations[runtimeIDAnnotation] != new.Annotations[runtimeIDAnnotation] ||
+		!reflect.DeepEqual(old.Labels, new.Labels) ||
+		old.Spec.Region != new.Spec.Region ||
+		!reflect.DeepEqual(old.Spec.Maintenance, new.Spec.Maintenance)
+}
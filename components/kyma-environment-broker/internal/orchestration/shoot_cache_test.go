@@ -0,0 +1,178 @@
+package orchestration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	gardenerapi "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	gardenerFake "github.com/gardener/gardener/pkg/client/core/clientset/versioned/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/logger"
+)
+
+func TestShootCache_ListShoots(t *testing.T) {
+	t.Run("should reuse the listing until it is invalidated", func(t *testing.T) {
+		// given
+		client := gardenerFake.NewSimpleClientset(fixCachedShoot("shoot-1"))
+		cache := NewShootCache(client.CoreV1beta1().Shoots(shootNamespace), time.Hour, logger.NewLogDummy())
+
+		// when
+		first, err := cache.ListShoots()
+		require.NoError(t, err)
+		second, err := cache.ListShoots()
+		require.NoError(t, err)
+
+		// then
+		assert.Len(t, first, 1)
+		assert.Equal(t, first, second)
+		assert.Equal(t, 1, countShootLists(client))
+
+		// when
+		cache.Invalidate()
+		_, err = cache.ListShoots()
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 2, countShootLists(client))
+	})
+
+	t.Run("should list the shoots every time when disabled", func(t *testing.T) {
+		// given
+		client := gardenerFake.NewSimpleClientset(fixCachedShoot("shoot-1"))
+		cache := NewShootCache(client.CoreV1beta1().Shoots(shootNamespace), 0, logger.NewLogDummy())
+
+		// when
+		_, err := cache.ListShoots()
+		require.NoError(t, err)
+		_, err = cache.ListShoots()
+		require.NoError(t, err)
+
+		// then
+		assert.Equal(t, 2, countShootLists(client))
+	})
+}
+
+func TestShootCache_Run(t *testing.T) {
+	// given
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := gardenerFake.NewSimpleClientset(fixCachedShoot("shoot-1"))
+	shoots := client.CoreV1beta1().Shoots(shootNamespace)
+	cache := NewShootCache(shoots, time.Hour, logger.NewLogDummy())
+	_, err := cache.ListShoots()
+	require.NoError(t, err)
+
+	// when
+	cache.Run(ctx)
+	_, err = shoots.Create(fixCachedShoot("shoot-2"))
+	require.NoError(t, err)
+
+	// then
+	err = wait.PollImmediate(10*time.Millisecond, 2*time.Second, func() (bool, error) {
+		listed, err := cache.ListShoots()
+		return len(listed) == 2, err
+	})
+	assert.NoError(t, err, "the added shoot must invalidate the cache")
+}
+
+func TestShootCache_OnEvent(t *testing.T) {
+	for name, tc := range map[string]struct {
+		event               func(shoot *gardenerapi.Shoot) watch.Event
+		expectedInvalidated bool
+	}{
+		"status change": {
+			event: func(shoot *gardenerapi.Shoot) watch.Event {
+				shoot.Status.IsHibernated = true
+				return watch.Event{Type: watch.Modified, Object: shoot}
+			},
+			expectedInvalidated: false,
+		},
+		"labels change": {
+			event: func(shoot *gardenerapi.Shoot) watch.Event {
+				shoot.Labels[subAccountLabel] = "other-subaccount"
+				return watch.Event{Type: watch.Modified, Object: shoot}
+			},
+			expectedInvalidated: true,
+		},
+		"maintenance window change": {
+			event: func(shoot *gardenerapi.Shoot) watch.Event {
+				shoot.Spec.Maintenance.TimeWindow.Begin = "030000+0000"
+				return watch.Event{Type: watch.Modified, Object: shoot}
+			},
+			expectedInvalidated: true,
+		},
+		"deletion": {
+			event: func(shoot *gardenerapi.Shoot) watch.Event {
+				return watch.Event{Type: watch.Deleted, Object: shoot}
+			},
+			expectedInvalidated: true,
+		},
+		"watch error": {
+			event: func(shoot *gardenerapi.Shoot) watch.Event {
+				return watch.Event{Type: watch.Error, Object: &metav1.Status{Status: metav1.StatusFailure}}
+			},
+			expectedInvalidated: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// given
+			client := gardenerFake.NewSimpleClientset(fixCachedShoot("shoot-1"))
+			cache := NewShootCache(client.CoreV1beta1().Shoots(shootNamespace), time.Hour, logger.NewLogDummy())
+			_, err := cache.ListShoots()
+			require.NoError(t, err)
+
+			// when
+			cache.onEvent(tc.event(fixCachedShoot("shoot-1")))
+			_, err = cache.ListShoots()
+
+			// then
+			require.NoError(t, err)
+			expectedLists := 1
+			if tc.expectedInvalidated {
+				expectedLists = 2
+			}
+			assert.Equal(t, expectedLists, countShootLists(client))
+		})
+	}
+}
+
+func fixCachedShoot(name string) *gardenerapi.Shoot {
+	return &gardenerapi.Shoot{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: shootNamespace,
+			Labels: map[string]string{
+				globalAccountLabel: globalAccountID1,
+				subAccountLabel:    "subaccount-" + name,
+			},
+			Annotations: map[string]string{
+				runtimeIDAnnotation: "runtime-" + name,
+			},
+		},
+		Spec: gardenerapi.ShootSpec{
+			Region: region1,
+			Maintenance: &gardenerapi.Maintenance{
+				TimeWindow: &gardenerapi.MaintenanceTimeWindow{
+					Begin: "010000+0000",
+					End:   "010000+0000",
+				},
+			},
+		},
+	}
+}
+
+func countShootLists(client *gardenerFake.Clientset) int {
+	count := 0
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "list" && action.GetResource().Resource == "shoots" {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,243 @@
+package orchestration
+
+import (
+	"sort"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/pkg/errors"
+)
+
+const (
+	// defaultUpgradeDuration is assumed for every runtime if none of the targeted runtimes was upgraded before
+	defaultUpgradeDuration = 30 * time.Minute
+	// upgradeHistoryLength is the number of the latest succeeded upgrades of the runtime used to estimate its upgrade duration
+	upgradeHistoryLength = 5
+)
+
+// simulatedWorkers are the numbers of parallel workers the simulation estimates the duration for,
+// so the operator is able to compare them with the requested one
+var simulatedWorkers = []int{1, 2, 5, 10, 20, 50}
+
+// Simulator estimates the duration of the Kyma upgrade orchestration without creating any operation. The upgrade
+// duration of every targeted runtime is the median of its latest succeeded upgrades, the runtimes which were not
+// upgraded yet are assumed to take the median duration of the other targeted runtimes.
+type Simulator struct {
+	resolver   RuntimeResolver
+	operations storage.UpgradeKyma
+	now        func() time.Time
+}
+
+// NewSimulator constructs a Simulator which resolves the targets with the given resolver
+func NewSimulator(resolver RuntimeResolver, operations storage.UpgradeKyma) *Simulator {
+	return &Simulator{
+		resolver:   resolver,
+		operations: operations,
+		now:        time.Now,
+	}
+}
+
+type simulatedRuntime struct {
+	// ready is the earliest time the upgrade of the runtime is started by the strategy
+	ready    time.Time
+	duration time.Duration
+}
+
+// Simulate estimates the duration of the orchestration with the given parameters, the strategy must be already defaulted
+func (s *Simulator) Simulate(params internal.OrchestrationParameters) (SimulationResponse, error) {
+	now := s.now()
+	runtimes, err := s.resolveRuntimes(params, now)
+	if err != nil {
+		return SimulationResponse{}, err
+	}
+	soakTime, err := ParseSoakTime(params.Strategy.Canary.SoakTime)
+	if err != nil {
+		return SimulationResponse{}, err
+	}
+
+	simulated := make([]simulatedRuntime, len(runtimes))
+	var known []time.Duration
+	for i, r := range runtimes {
+		duration, found, err := s.upgradeDuration(r.InstanceID)
+		if err != nil {
+			return SimulationResponse{}, errors.Wrapf(err, "while getting upgrade history of runtime %s", r.RuntimeID)
+		}
+		simulated[i].ready = now
+		if params.Strategy.Schedule == internal.MaintenanceWindow {
+			simulated[i].ready = nextMaintenanceWindow(r.MaintenanceWindowBegin, r.MaintenanceWindowEnd, now)
+		}
+		if found {
+			simulated[i].duration = duration
+			known = append(known, duration)
+		}
+	}
+	fallback := defaultUpgradeDuration
+	if len(known) > 0 {
+		fallback = median(known)
+	}
+	for i := range simulated {
+		if simulated[i].duration == 0 {
+			simulated[i].duration = fallback
+		}
+	}
+	// the strategies start the runtimes with the earliest maintenance windows first
+	sort.SliceStable(simulated, func(i, j int) bool {
+		return simulated[i].ready.Before(simulated[j].ready)
+	})
+
+	estimate := func(workers int) time.Duration {
+		if params.Strategy.Type == internal.CanaryStrategy && len(simulated) > 0 {
+			size := CanaryBatchSize(len(simulated), params.Strategy.Canary)
+			canaryEnd := simulateParallel(simulated[:size], workers, now)
+			restEnd := simulateParallel(simulated[size:], workers, canaryEnd.Add(soakTime))
+			return latest(canaryEnd, restEnd).Sub(now)
+		}
+		return simulateParallel(simulated, workers, now).Sub(now)
+	}
+
+	requested := workers(params.Strategy)
+	duration := estimate(requested)
+	response := SimulationResponse{
+		Runtimes:              len(runtimes),
+		RuntimesWithHistory:   len(known),
+		MedianUpgradeDuration: fallback.String(),
+		Workers:               requested,
+		EstimatedDuration:     duration.String(),
+		EstimatedEnd:          now.Add(duration),
+		WorkerEstimates:       make([]WorkerEstimate, 0, len(simulatedWorkers)),
+	}
+	for _, w := range simulatedWorkers {
+		response.WorkerEstimates = append(response.WorkerEstimates, WorkerEstimate{
+			Workers:           w,
+			EstimatedDuration: estimate(w).String(),
+		})
+	}
+
+	return response, nil
+}
+
+// ResolveRuntimes returns the runtimes the orchestration with the given parameters would upgrade if it was started now
+func (s *Simulator) ResolveRuntimes(params internal.OrchestrationParameters) ([]internal.Runtime, error) {
+	return s.resolveRuntimes(params, s.now())
+}
+
+func (s *Simulator) resolveRuntimes(params internal.OrchestrationParameters, now time.Time) ([]internal.Runtime, error) {
+	runtimes, err := s.resolver.Resolve(params.Targets)
+	if err != nil {
+		return nil, errors.Wrap(err, "while resolving targets")
+	}
+	runtimes, err = s.skipRecentlyUpgraded(runtimes, params.SkipUpgradedWithin, now)
+	if err != nil {
+		return nil, errors.Wrap(err, "while skipping recently upgraded runtimes")
+	}
+	return runtimes, nil
+}
+
+func (s *Simulator) skipRecentlyUpgraded(runtimes []internal.Runtime, period string, now time.Time) ([]internal.Runtime, error) {
+	within, err := ParseSkipUpgradedWithin(period)
+	if err != nil || within == 0 {
+		return runtimes, err
+	}
+
+	upgradedIDs, err := s.operations.ListInstanceIDsUpgradedSince(now.Add(-within))
+	if err != nil {
+		return nil, errors.Wrap(err, "while listing upgraded instances")
+	}
+	upgraded := make(map[string]struct{}, len(upgradedIDs))
+	for _, id := range upgradedIDs {
+		upgraded[id] = struct{}{}
+	}
+
+	result := make([]internal.Runtime, 0, len(runtimes))
+	for _, r := range runtimes {
+		if _, found := upgraded[r.InstanceID]; !found {
+			result = append(result, r)
+		}
+	}
+	return result, nil
+}
+
+// upgradeDuration returns the median duration of the latest succeeded Kyma upgrades of the instance,
+// false if the instance was not upgraded yet
+func (s *Simulator) upgradeDuration(instanceID string) (time.Duration, bool, error) {
+	operations, err := s.operations.ListUpgradeKymaOperationsByInstanceID(instanceID)
+	if err != nil {
+		return 0, false, err
+	}
+	sort.Slice(operations, func(i, j int) bool {
+		return operations[i].UpdatedAt.After(operations[j].UpdatedAt)
+	})
+
+	var durations []time.Duration
+	for _, op := range operations {
+		if op.State != domain.Succeeded || op.DryRun {
+			continue
+		}
+		if d := upgradeOperationDuration(op); d > 0 {
+			durations = append(durations, d)
+		}
+		if len(durations) == upgradeHistoryLength {
+			break
+		}
+	}
+	if len(durations) == 0 {
+		return 0, false, nil
+	}
+	return median(durations), true, nil
+}
+
+// upgradeOperationDuration returns the time the upgrade was actually running, the time spent waiting
+// for the maintenance window and the time before the last retry are not counted
+func upgradeOperationDuration(op internal.UpgradeKymaOperation) time.Duration {
+	start := latest(op.CreatedAt, latest(op.MaintenanceWindowBegin, op.LastRetryAt))
+	return op.UpdatedAt.Sub(start)
+}
+
+// simulateParallel assigns the runtimes to the first free worker in the given order and returns the time
+// when the last upgrade finishes, a worker waits if the runtime is not ready yet
+func simulateParallel(runtimes []simulatedRuntime, workers int, start time.Time) time.Time {
+	freeAt := make([]time.Time, workers)
+	for i := range freeAt {
+		freeAt[i] = start
+	}
+
+	end := start
+	for _, r := range runtimes {
+		next := 0
+		for i := range freeAt {
+			if freeAt[i].Before(freeAt[next]) {
+				next = i
+			}
+		}
+		finished := latest(freeAt[next], r.ready).Add(r.duration)
+		freeAt[next] = finished
+		end = latest(end, finished)
+	}
+	return end
+}
+
+// nextMaintenanceWindow returns the beginning of the next maintenance window, the same way
+// the upgrade operations are scheduled in the maintenance window
+func nextMaintenanceWindow(begin, end, now time.Time) time.Time {
+	start := time.Date(now.Year(), now.Month(), now.Day(), begin.Hour(), begin.Minute(), begin.Second(), begin.Nanosecond(), begin.Location())
+	stop := time.Date(now.Year(), now.Month(), now.Day(), end.Hour(), end.Minute(), end.Second(), end.Nanosecond(), end.Location())
+	if start.Before(now) && stop.Before(now) {
+		start = start.AddDate(0, 0, 1)
+	}
+	return latest(start, now)
+}
+
+func median(durations []time.Duration) time.Duration {
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[len(sorted)/2]
+}
+
+func latest(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,151 @@
+package orchestration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/orchestration/automock"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulator_Simulate(t *testing.T) {
+	now := time.Date(2020, 12, 1, 10, 0, 0, 0, time.UTC)
+	targets := internal.TargetSpec{Include: []internal.RuntimeTarget{{Target: internal.TargetAll}}}
+	runtimes := []internal.Runtime{
+		{InstanceID: "instance-1", RuntimeID: "runtime-1"},
+		{InstanceID: "instance-2", RuntimeID: "runtime-2"},
+		{InstanceID: "instance-3", RuntimeID: "runtime-3"},
+	}
+
+	// instance-1 takes 20m (median of 10m, 20m, 30m), instance-2 takes 40m, instance-3 was not upgraded yet,
+	// so it is assumed to take 40m, the median of the other runtimes
+	fixHistory := func(t *testing.T) storage.Operations {
+		operations := storage.NewMemoryStorage().Operations()
+		for _, op := range []internal.UpgradeKymaOperation{
+			fixUpgradeOperation("op-1", "instance-1", domain.Succeeded, now.Add(-72*time.Hour), 10*time.Minute),
+			fixUpgradeOperation("op-2", "instance-1", domain.Succeeded, now.Add(-48*time.Hour), 20*time.Minute),
+			fixUpgradeOperation("op-3", "instance-1", domain.Succeeded, now.Add(-24*time.Hour), 30*time.Minute),
+			fixUpgradeOperation("op-4", "instance-2", domain.Succeeded, now.Add(-24*time.Hour), 40*time.Minute),
+			fixUpgradeOperation("op-5", "instance-2", domain.Failed, now.Add(-12*time.Hour), 5*time.Hour),
+			fixUpgradeOperation("op-6", "instance-3", domain.InProgress, now.Add(-time.Hour), time.Minute),
+		} {
+			require.NoError(t, operations.InsertUpgradeKymaOperation(op))
+		}
+		// the waiting for the maintenance window is not part of the upgrade duration
+		windowed := fixUpgradeOperation("op-7", "instance-2", domain.Succeeded, now.Add(-96*time.Hour), 2*time.Hour+40*time.Minute)
+		windowed.MaintenanceWindowBegin = windowed.CreatedAt.Add(2 * time.Hour)
+		require.NoError(t, operations.InsertUpgradeKymaOperation(windowed))
+		return operations
+	}
+
+	for name, tc := range map[string]struct {
+		strategy              internal.StrategySpec
+		skipUpgradedWithin    string
+		expectedRuntimes      int
+		expectedDuration      string
+		expectedWithOneWorker string
+	}{
+		"parallel": {
+			strategy:              internal.StrategySpec{Type: internal.ParallelStrategy, Parallel: internal.ParallelStrategySpec{Workers: 2}},
+			expectedRuntimes:      3,
+			expectedDuration:      "1h0m0s",
+			expectedWithOneWorker: "1h40m0s",
+		},
+		"canary with soak time": {
+			strategy: internal.StrategySpec{
+				Type:     internal.CanaryStrategy,
+				Parallel: internal.ParallelStrategySpec{Workers: 2},
+				Canary:   internal.CanaryStrategySpec{Count: 1, SoakTime: "1h"},
+			},
+			expectedRuntimes:      3,
+			expectedDuration:      "2h0m0s",
+			expectedWithOneWorker: "2h40m0s",
+		},
+		"skip recently upgraded": {
+			strategy:              internal.StrategySpec{Type: internal.ParallelStrategy, Parallel: internal.ParallelStrategySpec{Workers: 2}},
+			skipUpgradedWithin:    "36h",
+			expectedRuntimes:      1,
+			expectedDuration:      "30m0s",
+			expectedWithOneWorker: "30m0s",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// given
+			resolver := &automock.RuntimeResolver{}
+			defer resolver.AssertExpectations(t)
+			resolver.On("Resolve", targets).Return(runtimes, nil)
+
+			simulator := NewSimulator(resolver, fixHistory(t))
+			simulator.now = func() time.Time { return now }
+
+			// when
+			response, err := simulator.Simulate(internal.OrchestrationParameters{
+				Targets:            targets,
+				Strategy:           tc.strategy,
+				SkipUpgradedWithin: tc.skipUpgradedWithin,
+			})
+
+			// then
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedRuntimes, response.Runtimes)
+			assert.Equal(t, 2, response.Workers)
+			assert.Equal(t, tc.expectedDuration, response.EstimatedDuration)
+			require.Len(t, response.WorkerEstimates, len(simulatedWorkers))
+			assert.Equal(t, WorkerEstimate{Workers: 1, EstimatedDuration: tc.expectedWithOneWorker}, response.WorkerEstimates[0])
+		})
+	}
+
+	t.Run("maintenance window", func(t *testing.T) {
+		// given
+		resolver := &automock.RuntimeResolver{}
+		defer resolver.AssertExpectations(t)
+		resolver.On("Resolve", targets).Return([]internal.Runtime{
+			fixWindowRuntime("runtime-1", 8),
+			fixWindowRuntime("runtime-2", 12),
+		}, nil)
+
+		simulator := NewSimulator(resolver, storage.NewMemoryStorage().Operations())
+		simulator.now = func() time.Time { return now }
+
+		// when
+		response, err := simulator.Simulate(internal.OrchestrationParameters{
+			Targets:  targets,
+			Strategy: internal.StrategySpec{Type: internal.ParallelStrategy, Schedule: internal.MaintenanceWindow, Parallel: internal.ParallelStrategySpec{Workers: 1}},
+		})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 0, response.RuntimesWithHistory)
+		assert.Equal(t, defaultUpgradeDuration.String(), response.MedianUpgradeDuration)
+		// the window of runtime-1 already passed today, so it is upgraded at 08:00 the next day
+		assert.Equal(t, "22h30m0s", response.EstimatedDuration)
+		assert.Equal(t, now.Add(22*time.Hour+30*time.Minute), response.EstimatedEnd)
+	})
+}
+
+func fixUpgradeOperation(id, instanceID string, state domain.LastOperationState, createdAt time.Time, duration time.Duration) internal.UpgradeKymaOperation {
+	return internal.UpgradeKymaOperation{
+		RuntimeOperation: internal.RuntimeOperation{
+			Operation: internal.Operation{
+				ID:         id,
+				InstanceID: instanceID,
+				State:      state,
+				CreatedAt:  createdAt,
+				UpdatedAt:  createdAt.Add(duration),
+			},
+		},
+	}
+}
+
+func fixWindowRuntime(runtimeID string, beginHour int) internal.Runtime {
+	return internal.Runtime{
+		InstanceID:             "instance-" + runtimeID,
+		RuntimeID:              runtimeID,
+		MaintenanceWindowBegin: time.Date(0, 1, 1, beginHour, 0, 0, 0, time.UTC),
+		MaintenanceWindowEnd:   time.Date(0, 1, 1, beginHour+1, 0, 0, 0, time.UTC),
+	}
+}
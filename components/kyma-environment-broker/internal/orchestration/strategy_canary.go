@@ -0,0 +1,165 @@
+package orchestration
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// CanaryFailedError is returned by the canary strategy when the number of failed canary operations exceeded
+// the failure threshold. Skipped contains the operations which were not scheduled.
+type CanaryFailedError struct {
+	Failed    int
+	Threshold int
+	Skipped   []internal.RuntimeOperation
+}
+
+func (e *CanaryFailedError) Error() string {
+	return fmt.Sprintf("%d canary operations failed, the failure threshold is %d", e.Failed, e.Threshold)
+}
+
+// IsCanaryFailed checks if the given error is CanaryFailedError
+func IsCanaryFailed(err error) (*CanaryFailedError, bool) {
+	cause, ok := errors.Cause(err).(*CanaryFailedError)
+	return cause, ok
+}
+
+type CanaryOrchestrationStrategy struct {
+	executor         process.Executor
+	operationStorage storage.Operations
+	pollingInterval  time.Duration
+	log              logrus.FieldLogger
+}
+
+// NewCanaryOrchestrationStrategy creates the strategy which executes the canary batch of operations first,
+// waits until it finishes and then executes the rest of the operations
+func NewCanaryOrchestrationStrategy(executor process.Executor, operationStorage storage.Operations, pollingInterval time.Duration, log logrus.FieldLogger) Strategy {
+	return &CanaryOrchestrationStrategy{
+		executor:         executor,
+		operationStorage: operationStorage,
+		pollingInterval:  pollingInterval,
+		log:              log,
+	}
+}
+
+func (c *CanaryOrchestrationStrategy) Execute(operations []internal.RuntimeOperation, strategySpec internal.StrategySpec) (time.Duration, error) {
+	if len(operations) == 0 {
+		return 0, nil
+	}
+
+	soakTime, err := ParseSoakTime(strategySpec.Canary.SoakTime)
+	if err != nil {
+		return 0, err
+	}
+
+	if strategySpec.Schedule == internal.MaintenanceWindow {
+		// the canary batch consists of the runtimes with the earliest maintenance windows
+		sort.Slice(operations, func(i, j int) bool {
+			return operations[i].MaintenanceWindowBegin.Before(operations[j].MaintenanceWindowBegin)
+		})
+	}
+	size := CanaryBatchSize(len(operations), strategySpec.Canary)
+	canary, rest := operations[:size], operations[size:]
+
+	stopCh := make(chan struct{})
+
+	q := process.NewQueue(newInFlightExecutor(c.executor, workers(strategySpec), c.pollingInterval), c.log)
+	q.Run(stopCh, workers(strategySpec))
+
+	c.log.Infof("Scheduling canary batch of %d operations", len(canary))
+	scheduleOperations(q, canary, strategySpec.Schedule, c.log)
+
+	failed, err := c.waitForCanary(canary)
+	if err != nil {
+		return 0, errors.Wrap(err, "while waiting for canary operations")
+	}
+	if failed > strategySpec.Canary.FailureThreshold {
+		return 0, &CanaryFailedError{
+			Failed:    failed,
+			Threshold: strategySpec.Canary.FailureThreshold,
+			Skipped:   rest,
+		}
+	}
+
+	if len(rest) == 0 {
+		return 0, nil
+	}
+	if soakTime > 0 {
+		c.log.Infof("Canary batch finished, waiting %s before scheduling the rest of the operations", soakTime)
+		time.Sleep(soakTime)
+	}
+
+	c.log.Infof("Scheduling the rest of %d operations", len(rest))
+	scheduleOperations(q, rest, strategySpec.Schedule, c.log)
+
+	return 0, nil
+}
+
+// waitForCanary waits until all canary operations are finished and returns the number of failed ones
+func (c *CanaryOrchestrationStrategy) waitForCanary(canary []internal.RuntimeOperation) (int, error) {
+	ids := make([]string, 0, len(canary))
+	for _, op := range canary {
+		ids = append(ids, op.ID)
+	}
+
+	var failed int
+	err := wait.PollInfinite(c.pollingInterval, func() (bool, error) {
+		ops, err := c.operationStorage.GetOperationsForIDs(ids)
+		if err != nil {
+			c.log.Errorf("while getting canary operations: %v", err)
+			return false, nil
+		}
+
+		failed = 0
+		for _, op := range ops {
+			switch op.State {
+			case domain.InProgress:
+				return false, nil
+			case domain.Failed:
+				failed++
+			}
+		}
+		return true, nil
+	})
+
+	return failed, err
+}
+
+// CanaryBatchSize returns the number of operations executed in the canary batch, at least one operation is executed
+func CanaryBatchSize(total int, spec internal.CanaryStrategySpec) int {
+	size := spec.Count
+	if size == 0 {
+		size = int(math.Ceil(float64(total) * float64(spec.Percentage) / 100))
+	}
+	if size < 1 {
+		size = 1
+	}
+	if size > total {
+		size = total
+	}
+	return size
+}
+
+// ParseSoakTime parses the soak time of the canary strategy, the empty value means no soak time
+func ParseSoakTime(soakTime string) (time.Duration, error) {
+	if soakTime == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(soakTime)
+	if err != nil {
+		return 0, errors.Wrapf(err, "while parsing canary soak time %q", soakTime)
+	}
+	if d < 0 {
+		return 0, errors.Errorf("canary soak time %q must not be negative", soakTime)
+	}
+	return d, nil
+}
@@ -0,0 +1,152 @@
+package orchestration
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanaryOrchestrationStrategy_Execute(t *testing.T) {
+	t.Run("should schedule the rest of operations when canary batch succeeded", func(t *testing.T) {
+		// given
+		store := storage.NewMemoryStorage()
+		ops := fixUpgradeKymaOperations(t, store, 4)
+		executor := &stateExecutor{operations: store.Operations(), states: map[string]domain.LastOperationState{}}
+
+		s := NewCanaryOrchestrationStrategy(executor, store.Operations(), 10*time.Millisecond, logrus.New())
+
+		// when
+		_, err := s.Execute(ops, internal.StrategySpec{
+			Schedule: internal.Immediate,
+			Parallel: internal.ParallelStrategySpec{Workers: 1},
+			Canary:   internal.CanaryStrategySpec{Count: 1},
+		})
+
+		// then
+		require.NoError(t, err)
+		assert.Eventually(t, func() bool {
+			return executor.executed() == 4
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("should fail when canary batch exceeded the failure threshold", func(t *testing.T) {
+		// given
+		store := storage.NewMemoryStorage()
+		ops := fixUpgradeKymaOperations(t, store, 4)
+		executor := &stateExecutor{operations: store.Operations(), states: map[string]domain.LastOperationState{
+			ops[0].ID: domain.Failed,
+			ops[1].ID: domain.Failed,
+		}}
+
+		s := NewCanaryOrchestrationStrategy(executor, store.Operations(), 10*time.Millisecond, logrus.New())
+
+		// when
+		_, err := s.Execute(ops, internal.StrategySpec{
+			Schedule: internal.Immediate,
+			Parallel: internal.ParallelStrategySpec{Workers: 2},
+			Canary:   internal.CanaryStrategySpec{Percentage: 50, FailureThreshold: 1},
+		})
+
+		// then
+		canaryErr, ok := IsCanaryFailed(err)
+		require.True(t, ok)
+		assert.Equal(t, 2, canaryErr.Failed)
+		assert.Len(t, canaryErr.Skipped, 2)
+		assert.Equal(t, 2, executor.executed())
+	})
+}
+
+func TestCanaryBatchSize(t *testing.T) {
+	for tn, tc := range map[string]struct {
+		total    int
+		spec     internal.CanaryStrategySpec
+		expected int
+	}{
+		"count":                   {total: 10, spec: internal.CanaryStrategySpec{Count: 3}, expected: 3},
+		"count bigger than total": {total: 2, spec: internal.CanaryStrategySpec{Count: 3}, expected: 2},
+		"percentage":              {total: 20, spec: internal.CanaryStrategySpec{Percentage: 10}, expected: 2},
+		"percentage rounded up":   {total: 11, spec: internal.CanaryStrategySpec{Percentage: 10}, expected: 2},
+		"count takes precedence":  {total: 20, spec: internal.CanaryStrategySpec{Count: 5, Percentage: 10}, expected: 5},
+		"at least one operation":  {total: 20, spec: internal.CanaryStrategySpec{}, expected: 1},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			assert.Equal(t, tc.expected, CanaryBatchSize(tc.total, tc.spec))
+		})
+	}
+}
+
+func TestParseSoakTime(t *testing.T) {
+	d, err := ParseSoakTime("")
+	assert.NoError(t, err)
+	assert.Zero(t, d)
+
+	d, err = ParseSoakTime("30m")
+	assert.NoError(t, err)
+	assert.Equal(t, 30*time.Minute, d)
+
+	_, err = ParseSoakTime("-1m")
+	assert.Error(t, err)
+
+	_, err = ParseSoakTime("abc")
+	assert.Error(t, err)
+}
+
+func fixUpgradeKymaOperations(t *testing.T, store storage.BrokerStorage, n int) []internal.RuntimeOperation {
+	var ops []internal.RuntimeOperation
+	for i := 0; i < n; i++ {
+		op := internal.UpgradeKymaOperation{
+			RuntimeOperation: internal.RuntimeOperation{
+				Operation: internal.Operation{
+					ID:              fmt.Sprintf("operation-%d", i),
+					State:           domain.InProgress,
+					OrchestrationID: "orchestration-id",
+					CreatedAt:       time.Now(),
+				},
+			},
+		}
+		err := store.Operations().InsertUpgradeKymaOperation(op)
+		require.NoError(t, err)
+		ops = append(ops, op.RuntimeOperation)
+	}
+	return ops
+}
+
+// stateExecutor finishes the operation with the configured state, succeeded by default
+type stateExecutor struct {
+	mu         sync.Mutex
+	operations storage.Operations
+	states     map[string]domain.LastOperationState
+	count      int
+}
+
+func (e *stateExecutor) Execute(opID string) (time.Duration, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.count++
+
+	op, err := e.operations.GetUpgradeKymaOperationByID(opID)
+	if err != nil {
+		return 0, err
+	}
+	op.State = domain.Succeeded
+	if state, found := e.states[opID]; found {
+		op.State = state
+	}
+	_, err = e.operations.UpdateUpgradeKymaOperation(*op)
+
+	return 0, err
+}
+
+func (e *stateExecutor) executed() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.count
+}
@@ -2,6 +2,7 @@ package orchestration
 
 import (
 	"sort"
+	"sync"
 	"time"
 
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
@@ -10,14 +11,18 @@ import (
 )
 
 type ParallelOrchestrationStrategy struct {
-	executor process.Executor
-	log      logrus.FieldLogger
+	executor        process.Executor
+	pollingInterval time.Duration
+	log             logrus.FieldLogger
 }
 
-func NewParallelOrchestrationStrategy(executor process.Executor, log logrus.FieldLogger) Strategy {
+// NewParallelOrchestrationStrategy creates the strategy which upgrades the configured number of runtimes at once,
+// the operations waiting for a free slot are checked again after the polling interval
+func NewParallelOrchestrationStrategy(executor process.Executor, pollingInterval time.Duration, log logrus.FieldLogger) Strategy {
 	return &ParallelOrchestrationStrategy{
-		executor: executor,
-		log:      log,
+		executor:        executor,
+		pollingInterval: pollingInterval,
+		log:             log,
 	}
 }
 
@@ -28,25 +33,96 @@ func (p *ParallelOrchestrationStrategy) Execute(operations []internal.RuntimeOpe
 
 	stopCh := make(chan struct{})
 
-	q := process.NewQueue(p.executor, p.log)
-	q.Run(stopCh, strategySpec.Parallel.Workers)
+	q := process.NewQueue(newInFlightExecutor(p.executor, workers(strategySpec), p.pollingInterval), p.log)
+	q.Run(stopCh, workers(strategySpec))
 
-	if strategySpec.Schedule == internal.MaintenanceWindow {
+	scheduleOperations(q, operations, strategySpec.Schedule, p.log)
+
+	return 0, nil
+}
+
+// workers returns the number of the operations of the orchestration in flight at once,
+// at least one operation is always executed
+func workers(strategySpec internal.StrategySpec) int {
+	if strategySpec.Parallel.Workers < 1 {
+		return 1
+	}
+	return strategySpec.Parallel.Workers
+}
+
+// inFlightExecutor limits the number of the operations of the orchestration in flight. The step triggering the upgrade
+// returns as soon as the upgrade is started in the Provisioner and the operation is executed again to check the
+// upgrade status, so the queue workers only bound the concurrent step executions. The operation is in flight from its
+// first execution until it is finished, the operations exceeding the limit are not executed and are retried after
+// the polling interval.
+type inFlightExecutor struct {
+	executor        process.Executor
+	limit           int
+	pollingInterval time.Duration
+
+	mu       sync.Mutex
+	inFlight map[string]struct{}
+}
+
+func newInFlightExecutor(executor process.Executor, limit int, pollingInterval time.Duration) *inFlightExecutor {
+	return &inFlightExecutor{
+		executor:        executor,
+		limit:           limit,
+		pollingInterval: pollingInterval,
+		inFlight:        make(map[string]struct{}),
+	}
+}
+
+func (e *inFlightExecutor) Execute(operationID string) (time.Duration, error) {
+	if !e.admit(operationID) {
+		return e.pollingInterval, nil
+	}
+
+	when, err := e.executor.Execute(operationID)
+	// the operation is not queued again if it is finished or its processing failed
+	if err != nil || when == 0 {
+		e.release(operationID)
+	}
+	return when, err
+}
+
+// admit returns true if the operation is already in flight or there is a free slot for it
+func (e *inFlightExecutor) admit(operationID string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, found := e.inFlight[operationID]; found {
+		return true
+	}
+	if len(e.inFlight) >= e.limit {
+		return false
+	}
+	e.inFlight[operationID] = struct{}{}
+	return true
+}
+
+func (e *inFlightExecutor) release(operationID string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.inFlight, operationID)
+}
+
+// scheduleOperations adds the operations to the queue according to the given schedule
+func scheduleOperations(q *process.Queue, operations []internal.RuntimeOperation, schedule internal.ScheduleType, log logrus.FieldLogger) {
+	if schedule == internal.MaintenanceWindow {
 		sort.Slice(operations, func(i, j int) bool {
 			return operations[i].MaintenanceWindowBegin.Before(operations[j].MaintenanceWindowBegin)
 		})
 	}
 
 	for _, op := range operations {
-		switch strategySpec.Schedule {
+		switch schedule {
 		case internal.MaintenanceWindow:
 			until := time.Until(op.MaintenanceWindowBegin)
-			p.log.Infof("Upgrade operation %s will be scheduled in %v", op.ID, until)
+			log.Infof("Upgrade operation %s will be scheduled in %v", op.ID, until)
 			q.AddAfter(op.ID, until)
 		case internal.Immediate:
 			q.Add(op.ID)
 		}
 	}
-
-	return 0, nil
 }
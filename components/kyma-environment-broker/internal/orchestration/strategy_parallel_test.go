@@ -1,6 +1,8 @@
 package orchestration
 
 import (
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -13,7 +15,7 @@ import (
 
 func TestNewParallelOrchestrationStrategy(t *testing.T) {
 
-	s := NewParallelOrchestrationStrategy(&testExecutor{}, logrus.New())
+	s := NewParallelOrchestrationStrategy(&testExecutor{}, time.Millisecond, logrus.New())
 
 	startTime, err := time.Parse(maintenanceWindowFormat, "220000+0000")
 	require.NoError(t, err)
@@ -36,8 +38,65 @@ func TestNewParallelOrchestrationStrategy(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestParallelOrchestrationStrategy_LimitsInFlightOperations(t *testing.T) {
+	// given
+	executor := &upgradingExecutor{checks: 3, upgrading: map[string]int{}}
+	s := NewParallelOrchestrationStrategy(executor, time.Millisecond, logrus.New())
+
+	ops := make([]internal.RuntimeOperation, 6)
+	for i := range ops {
+		ops[i] = internal.RuntimeOperation{Operation: internal.Operation{ID: fmt.Sprintf("op-%d", i)}}
+	}
+
+	// when
+	_, err := s.Execute(ops, internal.StrategySpec{
+		Schedule: internal.Immediate,
+		Parallel: internal.ParallelStrategySpec{Workers: 2},
+	})
+
+	// then
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return executor.finishedCount() == len(ops)
+	}, 2*time.Second, 5*time.Millisecond)
+	assert.Equal(t, 2, executor.maxUpgrading)
+}
+
 type testExecutor struct{}
 
 func (t *testExecutor) Execute(opID string) (time.Duration, error) {
 	return 0, nil
 }
+
+// upgradingExecutor simulates the operations which trigger the upgrade and are executed again until the upgrade
+// is finished, it records the highest number of the runtimes upgraded at once
+type upgradingExecutor struct {
+	checks int
+
+	mu           sync.Mutex
+	upgrading    map[string]int
+	finished     int
+	maxUpgrading int
+}
+
+func (e *upgradingExecutor) Execute(opID string) (time.Duration, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.upgrading[opID]++
+	if len(e.upgrading) > e.maxUpgrading {
+		e.maxUpgrading = len(e.upgrading)
+	}
+	if e.upgrading[opID] < e.checks {
+		return time.Millisecond, nil
+	}
+	delete(e.upgrading, opID)
+	e.finished++
+	return 0, nil
+}
+
+func (e *upgradingExecutor) finishedCount() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.finished
+}
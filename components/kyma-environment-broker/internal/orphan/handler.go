@@ -0,0 +1,80 @@
+package orphan
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/httputil"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+type Handler struct {
+	service *Service
+	log     logrus.FieldLogger
+}
+
+func NewHandler(service *Service, log logrus.FieldLogger) *Handler {
+	return &Handler{
+		service: service,
+		log:     log,
+	}
+}
+
+func (h *Handler) AttachRoutes(router *mux.Router) {
+	router.HandleFunc("/orphans/{shoot_name}", h.confirm).Methods(http.MethodGet)
+	router.HandleFunc("/orphans/{shoot_name}/cleanup", h.cleanup).Methods(http.MethodPost)
+}
+
+// confirm returns the details of the orphaned shoot with the confirmation token required by the cleanup
+func (h *Handler) confirm(w http.ResponseWriter, r *http.Request) {
+	shootName := mux.Vars(r)["shoot_name"]
+
+	orphan, err := h.service.Confirm(shootName)
+	if err != nil {
+		h.writeError(w, err, "while confirming orphaned shoot")
+		return
+	}
+
+	httputil.WriteResponse(w, http.StatusOK, orphan)
+}
+
+// cleanup triggers the deletion of the orphaned shoot, the request must contain the valid confirmation token
+func (h *Handler) cleanup(w http.ResponseWriter, r *http.Request) {
+	shootName := mux.Vars(r)["shoot_name"]
+
+	var request runtime.OrphanCleanupRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, errors.Wrap(err, "while decoding request body"))
+		return
+	}
+	if request.ConfirmationToken == "" {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, errors.New("confirmationToken must be set"))
+		return
+	}
+
+	response, err := h.service.Cleanup(r.Context(), shootName, request.ConfirmationToken, httputil.User(r))
+	if err != nil {
+		h.writeError(w, err, "while cleaning up orphaned shoot")
+		return
+	}
+
+	httputil.WriteResponse(w, http.StatusAccepted, response)
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, err error, context string) {
+	switch err.(type) {
+	case NotFoundError:
+		httputil.WriteErrorResponse(w, http.StatusNotFound, err)
+	case NotOrphanError:
+		httputil.WriteErrorResponse(w, http.StatusConflict, err)
+	case InvalidTokenError:
+		httputil.WriteErrorResponse(w, http.StatusPreconditionFailed, err)
+	default:
+		h.log.Errorf("%s: %v", context, err)
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, context))
+	}
+}
@@ -0,0 +1,184 @@
+package orphan_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/orphan"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/provisioner"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+
+	gardenerapi "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	gardenerfake "github.com/gardener/gardener/pkg/client/core/clientset/versioned/fake"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const gardenerNamespace = "garden-kyma"
+
+func TestHandler(t *testing.T) {
+	// given
+	db := storage.NewMemoryStorage()
+	err := db.Instances().Insert(internal.Instance{InstanceID: "instance-1", RuntimeID: "runtime-1"})
+	require.NoError(t, err)
+
+	shoots := gardenerfake.NewSimpleClientset(
+		fixShoot("shoot-1", "runtime-1"),
+		fixShoot("shoot-2", "runtime-2"),
+	).CoreV1beta1().Shoots(gardenerNamespace)
+	provisionerClient := provisioner.NewFakeClient()
+
+	service, err := orphan.NewService(shoots, db.Instances(), provisionerClient, fixConfig(time.Minute), logrus.New())
+	require.NoError(t, err)
+	router := mux.NewRouter()
+	orphan.NewHandler(service, logrus.New()).AttachRoutes(router)
+
+	t.Run("should return not found for unknown shoot", func(t *testing.T) {
+		// when
+		rr := serve(t, router, http.MethodGet, "/orphans/unknown", nil)
+
+		// then
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("should reject shoot of existing instance", func(t *testing.T) {
+		// when
+		rr := serve(t, router, http.MethodGet, "/orphans/shoot-1", nil)
+
+		// then
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("should reject cleanup without confirmation token", func(t *testing.T) {
+		// when
+		rr := serve(t, router, http.MethodPost, "/orphans/shoot-2/cleanup", runtime.OrphanCleanupRequest{})
+
+		// then
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("should reject cleanup with token of another shoot", func(t *testing.T) {
+		// given
+		other, err := service.Confirm("shoot-2")
+		require.NoError(t, err)
+		otherShoots := gardenerfake.NewSimpleClientset(fixShoot("shoot-3", "runtime-3")).CoreV1beta1().Shoots(gardenerNamespace)
+		otherService, err := orphan.NewService(otherShoots, db.Instances(), provisionerClient, fixConfig(time.Minute), logrus.New())
+		require.NoError(t, err)
+		otherRouter := mux.NewRouter()
+		orphan.NewHandler(otherService, logrus.New()).AttachRoutes(otherRouter)
+
+		// when
+		rr := serve(t, otherRouter, http.MethodPost, "/orphans/shoot-3/cleanup", runtime.OrphanCleanupRequest{ConfirmationToken: other.ConfirmationToken})
+
+		// then
+		assert.Equal(t, http.StatusPreconditionFailed, rr.Code)
+	})
+
+	t.Run("should deprovision orphaned shoot with confirmation token", func(t *testing.T) {
+		// given
+		rr := serve(t, router, http.MethodGet, "/orphans/shoot-2", nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var dto runtime.OrphanDTO
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &dto))
+		assert.Equal(t, "runtime-2", dto.RuntimeID)
+		assert.Equal(t, "ga-runtime-2", dto.GlobalAccountID)
+		assert.NotEmpty(t, dto.ConfirmationToken)
+
+		// when
+		rr = serve(t, router, http.MethodPost, "/orphans/shoot-2/cleanup", runtime.OrphanCleanupRequest{ConfirmationToken: dto.ConfirmationToken})
+
+		// then
+		require.Equal(t, http.StatusAccepted, rr.Code)
+		var response runtime.OrphanCleanupResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Equal(t, "runtime-2", response.RuntimeID)
+		status, err := provisionerClient.RuntimeOperationStatus("ga-runtime-2", response.ProvisionerOperationID)
+		require.NoError(t, err)
+		assert.Equal(t, "runtime-2", *status.RuntimeID)
+	})
+
+	t.Run("should accept confirmation token issued by another replica", func(t *testing.T) {
+		// given
+		dto, err := service.Confirm("shoot-2")
+		require.NoError(t, err)
+		replica, err := orphan.NewService(shoots, db.Instances(), provisionerClient, fixConfig(time.Minute), logrus.New())
+		require.NoError(t, err)
+		replicaRouter := mux.NewRouter()
+		orphan.NewHandler(replica, logrus.New()).AttachRoutes(replicaRouter)
+
+		// when
+		rr := serve(t, replicaRouter, http.MethodPost, "/orphans/shoot-2/cleanup", runtime.OrphanCleanupRequest{ConfirmationToken: dto.ConfirmationToken})
+
+		// then
+		assert.Equal(t, http.StatusAccepted, rr.Code)
+	})
+
+	t.Run("should reject expired confirmation token", func(t *testing.T) {
+		// given
+		expiringService, err := orphan.NewService(shoots, db.Instances(), provisionerClient, fixConfig(-time.Minute), logrus.New())
+		require.NoError(t, err)
+		expiringRouter := mux.NewRouter()
+		orphan.NewHandler(expiringService, logrus.New()).AttachRoutes(expiringRouter)
+		dto, err := expiringService.Confirm("shoot-2")
+		require.NoError(t, err)
+
+		// when
+		rr := serve(t, expiringRouter, http.MethodPost, "/orphans/shoot-2/cleanup", runtime.OrphanCleanupRequest{ConfirmationToken: dto.ConfirmationToken})
+
+		// then
+		assert.Equal(t, http.StatusPreconditionFailed, rr.Code)
+	})
+}
+
+func TestNewService_ConfirmationTokenKey(t *testing.T) {
+	// when
+	_, err := orphan.NewService(nil, nil, nil, orphan.Config{ConfirmationTokenTTL: time.Minute, ConfirmationTokenKey: "too-short"}, logrus.New())
+
+	// then
+	assert.Error(t, err)
+}
+
+func fixConfig(ttl time.Duration) orphan.Config {
+	return orphan.Config{
+		ConfirmationTokenTTL: ttl,
+		ConfirmationTokenKey: "0123456789abcdef0123456789abcdef",
+	}
+}
+
+func fixShoot(name, runtimeID string) *gardenerapi.Shoot {
+	return &gardenerapi.Shoot{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   gardenerNamespace,
+			UID:         types.UID(fmt.Sprintf("uid-%s", name)),
+			Labels:      map[string]string{"account": fmt.Sprintf("ga-%s", runtimeID), "subaccount": fmt.Sprintf("sa-%s", runtimeID)},
+			Annotations: map[string]string{"kcp.provisioner.kyma-project.io/runtime-id": runtimeID},
+		},
+	}
+}
+
+func serve(t *testing.T, router *mux.Router, method, path string, body interface{}) *httptest.ResponseRecorder {
+	var data []byte
+	if body != nil {
+		var err error
+		data, err = json.Marshal(body)
+		require.NoError(t, err)
+	}
+	req, err := http.NewRequest(method, path, bytes.NewReader(data))
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
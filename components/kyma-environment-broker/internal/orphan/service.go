@@ -0,0 +1,293 @@
+package orphan
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/provisioner"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
+
+	gardenerapi "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	runtimeIDAnnotation = "kcp.provisioner.kyma-project.io/runtime-id"
+	globalAccountLabel  = "account"
+	subAccountLabel     = "subaccount"
+)
+
+type Config struct {
+	// ConfirmationTokenTTL defines how long the confirmation token of the orphaned shoot is valid
+	ConfirmationTokenTTL time.Duration `envconfig:"default=10m"`
+	// ConfirmationTokenKey is the key signing the confirmation tokens, it must be the same on all replicas of the broker
+	ConfirmationTokenKey string
+	// DetectionDisabled turns off the periodic detection of the orphaned shoots
+	DetectionDisabled bool `envconfig:"default=false"`
+	// DetectionInterval defines how often the orphaned shoots are detected
+	DetectionInterval time.Duration `envconfig:"default=1h"`
+	// DetectionMinAge must be longer than the time the Provisioner needs to accept the provisioning, the runtime ID
+	// is stored in the instance only after that, so the younger shoots are not reported as orphans
+	DetectionMinAge time.Duration `envconfig:"default=1h"`
+}
+
+const minConfirmationTokenKeyLength = 32
+
+// ShootClient is the interface to get and list the shoots in the Gardener cluster
+type ShootClient interface {
+	Get(name string, options metav1.GetOptions) (*gardenerapi.Shoot, error)
+	List(opts metav1.ListOptions) (*gardenerapi.ShootList, error)
+}
+
+// NotFoundError is returned if the shoot does not exist
+type NotFoundError struct {
+	message string
+}
+
+func (e NotFoundError) Error() string {
+	return e.message
+}
+
+// NotOrphanError is returned if the shoot is not an orphan, e.g. the instance of its runtime exists
+type NotOrphanError struct {
+	message string
+}
+
+func (e NotOrphanError) Error() string {
+	return e.message
+}
+
+// InvalidTokenError is returned if the confirmation token does not match the shoot or is expired
+type InvalidTokenError struct {
+	message string
+}
+
+func (e InvalidTokenError) Error() string {
+	return e.message
+}
+
+// Service confirms that the shoot is an orphan, i.e. the shoot of a runtime without the instance record,
+// and triggers the deletion of the orphaned shoot through the Provisioner. The deletion requires the
+// confirmation token issued when the orphan is confirmed, so the shoot cannot be deleted by accident.
+// The token is bound to the shoot UID and the runtime ID, and it is signed with the configured key,
+// so the token issued by one replica of the broker is accepted by the other ones and after the restart.
+// The orphaned shoots are also detected periodically, their number is exposed as the metric.
+type Service struct {
+	shoots            ShootClient
+	instances         storage.Instances
+	provisionerClient provisioner.Client
+	cfg               Config
+	key               []byte
+	log               logrus.FieldLogger
+
+	mu      sync.Mutex
+	orphans int
+}
+
+func NewService(shoots ShootClient, instances storage.Instances, provisionerClient provisioner.Client, cfg Config, log logrus.FieldLogger) (*Service, error) {
+	if len(cfg.ConfirmationTokenKey) < minConfirmationTokenKeyLength {
+		return nil, errors.Errorf("confirmation token key must have at least %d characters", minConfirmationTokenKeyLength)
+	}
+
+	return &Service{
+		shoots:            shoots,
+		instances:         instances,
+		provisionerClient: provisionerClient,
+		cfg:               cfg,
+		key:               []byte(cfg.ConfirmationTokenKey),
+		log:               log,
+	}, nil
+}
+
+// Confirm checks the shoot is an orphan and returns its details together with the confirmation token
+func (s *Service) Confirm(shootName string) (runtime.OrphanDTO, error) {
+	shoot, err := s.getOrphan(shootName)
+	if err != nil {
+		return runtime.OrphanDTO{}, err
+	}
+
+	expiresAt := time.Now().Add(s.cfg.ConfirmationTokenTTL).Truncate(time.Second)
+	return runtime.OrphanDTO{
+		ShootName:         shoot.Name,
+		RuntimeID:         shoot.Annotations[runtimeIDAnnotation],
+		GlobalAccountID:   shoot.Labels[globalAccountLabel],
+		SubAccountID:      shoot.Labels[subAccountLabel],
+		CreatedAt:         shoot.CreationTimestamp.Time,
+		ConfirmationToken: s.token(shoot, expiresAt),
+		TokenExpiresAt:    expiresAt,
+	}, nil
+}
+
+// Cleanup checks again the shoot is an orphan and the confirmation token is valid, and triggers the deprovisioning
+// of the runtime of the shoot in the Provisioner. The Provisioner request is sent with the given context, together
+// with the correlation ID it carries.
+func (s *Service) Cleanup(ctx context.Context, shootName, token, user string) (runtime.OrphanCleanupResponse, error) {
+	shoot, err := s.getOrphan(shootName)
+	if err != nil {
+		return runtime.OrphanCleanupResponse{}, err
+	}
+	if err := s.verifyToken(shoot, token, time.Now()); err != nil {
+		return runtime.OrphanCleanupResponse{}, err
+	}
+
+	runtimeID := shoot.Annotations[runtimeIDAnnotation]
+	globalAccountID := shoot.Labels[globalAccountLabel]
+	if globalAccountID == "" {
+		return runtime.OrphanCleanupResponse{}, NotOrphanError{message: fmt.Sprintf("shoot %s has no %s label", shootName, globalAccountLabel)}
+	}
+
+	operationID, err := provisioner.WithContext(s.provisionerClient, ctx).DeprovisionRuntime(globalAccountID, runtimeID)
+	if err != nil {
+		return runtime.OrphanCleanupResponse{}, errors.Wrapf(err, "while deprovisioning runtime %s", runtimeID)
+	}
+	s.log.Infof("Deprovisioning of orphaned shoot %s (runtime %s) triggered by %q, provisioner operation ID: %s", shootName, runtimeID, user, operationID)
+
+	return runtime.OrphanCleanupResponse{
+		ShootName:              shootName,
+		RuntimeID:              runtimeID,
+		ProvisionerOperationID: operationID,
+	}, nil
+}
+
+// Run detects the orphaned shoots, it is the periodic job run by the scheduler
+func (s *Service) Run(ctx context.Context) error {
+	_, err := s.Detect(ctx)
+	return err
+}
+
+// Detect returns the orphaned shoots sorted by the name. The shoots being deleted, without the runtime ID annotation,
+// or younger than the configured minimal age are skipped. The orphans are only reported, they are cleaned up
+// with the confirmation token, see Cleanup.
+func (s *Service) Detect(ctx context.Context) ([]runtime.OrphanDTO, error) {
+	shoots, err := s.shoots.List(metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "while listing shoots")
+	}
+
+	candidates := make(map[string]gardenerapi.Shoot)
+	runtimeIDs := make([]string, 0)
+	for _, shoot := range shoots.Items {
+		runtimeID := shoot.Annotations[runtimeIDAnnotation]
+		if shoot.DeletionTimestamp != nil || runtimeID == "" || time.Since(shoot.CreationTimestamp.Time) < s.cfg.DetectionMinAge {
+			continue
+		}
+		candidates[runtimeID] = shoot
+		runtimeIDs = append(runtimeIDs, runtimeID)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(runtimeIDs) > 0 {
+		instances, err := s.instances.FindAllInstancesForRuntimes(runtimeIDs)
+		switch {
+		case dberr.IsNotFound(err):
+		case err != nil:
+			return nil, errors.Wrap(err, "while getting instances of runtimes")
+		}
+		for _, instance := range instances {
+			delete(candidates, instance.RuntimeID)
+		}
+	}
+
+	orphans := make([]runtime.OrphanDTO, 0, len(candidates))
+	for runtimeID, shoot := range candidates {
+		s.log.Warnf("Shoot %s of runtime %s created at %s is an orphan, no instance of the runtime exists", shoot.Name, runtimeID, shoot.CreationTimestamp.Time)
+		orphans = append(orphans, runtime.OrphanDTO{
+			ShootName:       shoot.Name,
+			RuntimeID:       runtimeID,
+			GlobalAccountID: shoot.Labels[globalAccountLabel],
+			SubAccountID:    shoot.Labels[subAccountLabel],
+			CreatedAt:       shoot.CreationTimestamp.Time,
+		})
+	}
+	sort.Slice(orphans, func(i, j int) bool {
+		return orphans[i].ShootName < orphans[j].ShootName
+	})
+
+	s.mu.Lock()
+	s.orphans = len(orphans)
+	s.mu.Unlock()
+
+	return orphans, nil
+}
+
+// OrphansCount returns the number of the orphaned shoots found by the latest detection
+func (s *Service) OrphansCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.orphans
+}
+
+// getOrphan returns the shoot if it exists, it is not being deleted, and no instance of its runtime exists
+func (s *Service) getOrphan(shootName string) (*gardenerapi.Shoot, error) {
+	shoot, err := s.shoots.Get(shootName, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		return nil, NotFoundError{message: fmt.Sprintf("shoot %s not found", shootName)}
+	case err != nil:
+		return nil, errors.Wrapf(err, "while getting shoot %s", shootName)
+	}
+
+	if shoot.DeletionTimestamp != nil {
+		return nil, NotOrphanError{message: fmt.Sprintf("shoot %s is already being deleted", shootName)}
+	}
+	runtimeID := shoot.Annotations[runtimeIDAnnotation]
+	if runtimeID == "" {
+		return nil, NotOrphanError{message: fmt.Sprintf("shoot %s has no %s annotation", shootName, runtimeIDAnnotation)}
+	}
+
+	instances, err := s.instances.FindAllInstancesForRuntimes([]string{runtimeID})
+	switch {
+	case dberr.IsNotFound(err):
+	case err != nil:
+		return nil, errors.Wrapf(err, "while getting instance of runtime %s", runtimeID)
+	case len(instances) > 0:
+		return nil, NotOrphanError{message: fmt.Sprintf("shoot %s is not an orphan, runtime %s belongs to instance %s", shootName, runtimeID, instances[0].InstanceID)}
+	}
+
+	return shoot, nil
+}
+
+// token returns the expiration time and the signature of the shoot UID, the runtime ID, and the expiration time
+func (s *Service) token(shoot *gardenerapi.Shoot, expiresAt time.Time) string {
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+	return fmt.Sprintf("%s.%s", expires, s.sign(shoot, expires))
+}
+
+func (s *Service) verifyToken(shoot *gardenerapi.Shoot, token string, now time.Time) error {
+	parts := strings.SplitN(token, ".", 2)
+	if len(parts) != 2 {
+		return InvalidTokenError{message: "confirmation token is malformed"}
+	}
+	expires, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return InvalidTokenError{message: "confirmation token is malformed"}
+	}
+	if !hmac.Equal([]byte(parts[1]), []byte(s.sign(shoot, parts[0]))) {
+		return InvalidTokenError{message: fmt.Sprintf("confirmation token does not match shoot %s", shoot.Name)}
+	}
+	if now.After(time.Unix(expires, 0)) {
+		return InvalidTokenError{message: "confirmation token is expired"}
+	}
+	return nil
+}
+
+func (s *Service) sign(shoot *gardenerapi.Shoot, expires string) string {
+	mac := hmac.New(sha256.New, s.key)
+	fmt.Fprintf(mac, "%s|%s|%s|%s", shoot.Name, shoot.UID, shoot.Annotations[runtimeIDAnnotation], expires)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
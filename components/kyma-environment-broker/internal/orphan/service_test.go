@@ -0,0 +1,56 @@
+package orphan_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/orphan"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/provisioner"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+
+	gardenerfake "github.com/gardener/gardener/pkg/client/core/clientset/versioned/fake"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestService_Detect(t *testing.T) {
+	// given
+	db := storage.NewMemoryStorage()
+	err := db.Instances().Insert(internal.Instance{InstanceID: "instance-1", RuntimeID: "runtime-1"})
+	require.NoError(t, err)
+
+	young := fixShoot("shoot-3", "runtime-3")
+	young.CreationTimestamp = metav1.NewTime(time.Now())
+	deleted := fixShoot("shoot-4", "runtime-4")
+	deletionTimestamp := metav1.NewTime(time.Now())
+	deleted.DeletionTimestamp = &deletionTimestamp
+	withoutRuntime := fixShoot("shoot-5", "")
+
+	shoots := gardenerfake.NewSimpleClientset(
+		fixShoot("shoot-1", "runtime-1"),
+		fixShoot("shoot-2", "runtime-2"),
+		young,
+		deleted,
+		withoutRuntime,
+	).CoreV1beta1().Shoots(gardenerNamespace)
+	cfg := fixConfig(time.Minute)
+	cfg.DetectionMinAge = time.Hour
+	service, err := orphan.NewService(shoots, db.Instances(), provisioner.NewFakeClient(), cfg, logrus.New())
+	require.NoError(t, err)
+
+	// when
+	orphans, err := service.Detect(context.Background())
+
+	// then
+	require.NoError(t, err)
+	require.Len(t, orphans, 1)
+	assert.Equal(t, "shoot-2", orphans[0].ShootName)
+	assert.Equal(t, "runtime-2", orphans[0].RuntimeID)
+	assert.Equal(t, "ga-runtime-2", orphans[0].GlobalAccountID)
+	assert.Empty(t, orphans[0].ConfirmationToken, "the orphans are only reported")
+	assert.Equal(t, 1, service.OrphansCount())
+}
@@ -0,0 +1,94 @@
+package process
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/sirupsen/logrus"
+)
+
+type AccountMigrationOperationManager struct {
+	storage storage.AccountMigration
+	ctx     context.Context
+}
+
+func NewAccountMigrationOperationManager(storage storage.Operations) *AccountMigrationOperationManager {
+	return &AccountMigrationOperationManager{storage: storage, ctx: context.Background()}
+}
+
+// WithContext returns the copy of the manager which records the storage calls in the trace of the given context
+func (om *AccountMigrationOperationManager) WithContext(ctx context.Context) *AccountMigrationOperationManager {
+	withContext := *om
+	withContext.ctx = ctx
+	return &withContext
+}
+
+// OperationSucceeded marks the operation as succeeded and only repeats it if there is a storage error
+func (om *AccountMigrationOperationManager) OperationSucceeded(operation internal.AccountMigrationOperation, description string) (internal.AccountMigrationOperation, time.Duration, error) {
+	updatedOperation, repeat := om.update(operation, domain.Succeeded, description)
+	// repeat in case of storage error
+	if repeat != 0 {
+		return updatedOperation, repeat, nil
+	}
+
+	return updatedOperation, 0, nil
+}
+
+// OperationFailed marks the operation as failed and only repeats it if there is a storage error
+func (om *AccountMigrationOperationManager) OperationFailed(operation internal.AccountMigrationOperation, description string) (internal.AccountMigrationOperation, time.Duration, error) {
+	updatedOperation, repeat := om.update(operation, domain.Failed, description)
+	// repeat in case of storage error
+	if repeat != 0 {
+		return updatedOperation, repeat, nil
+	}
+
+	return updatedOperation, 0, errors.New(description)
+}
+
+// RetryOperation retries an operation for at maxTime in retryInterval steps and fails the operation if retrying failed
+func (om *AccountMigrationOperationManager) RetryOperation(operation internal.AccountMigrationOperation, errorMessage string, retryInterval time.Duration, maxTime time.Duration, log logrus.FieldLogger) (internal.AccountMigrationOperation, time.Duration, error) {
+	since := time.Since(operation.UpdatedAt)
+
+	log.Infof("Retry Operation was triggered with message: %s", errorMessage)
+	log.Infof("Retrying for %s in %s steps", maxTime.String(), retryInterval.String())
+	if since < maxTime {
+		return operation, retryInterval, nil
+	}
+	log.Errorf("Aborting after %s of failing retries", maxTime.String())
+	return om.OperationFailed(operation, errorMessage)
+}
+
+// UpdateOperation updates a given operation
+func (om *AccountMigrationOperationManager) UpdateOperation(operation internal.AccountMigrationOperation) (internal.AccountMigrationOperation, time.Duration) {
+	updatedOperation, err := om.store(operation)
+	if err != nil {
+		return operation, 1 * time.Minute
+	}
+	return *updatedOperation, 0
+}
+
+func (om *AccountMigrationOperationManager) update(operation internal.AccountMigrationOperation, state domain.LastOperationState, description string) (internal.AccountMigrationOperation, time.Duration) {
+	operation.State = state
+	operation.Description = description
+
+	return om.UpdateOperation(operation)
+}
+
+// store updates the operation within the span of the storage call, see storeOperation for the conflict handling
+func (om *AccountMigrationOperationManager) store(operation internal.AccountMigrationOperation) (updatedOperation *internal.AccountMigrationOperation, err error) {
+	err = storeOperation(om.ctx, "storage/UpdateAccountMigrationOperation", func() error {
+		updatedOperation, err = om.storage.UpdateAccountMigrationOperation(operation)
+		return err
+	}, func() (*internal.Operation, error) {
+		updatedOperation, err = om.storage.GetAccountMigrationOperationByID(operation.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &updatedOperation.Operation, nil
+	})
+	return updatedOperation, err
+}
@@ -0,0 +1,63 @@
+package accountmigration
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/sirupsen/logrus"
+)
+
+// InitialisationStep fails the migration which did not apply any change yet if another operation of the instance
+// is in progress. The migration endpoint checks the operations before the migration is created, but an operation
+// could be started in the meantime. The operations started later are rejected because the migration is in progress.
+type InitialisationStep struct {
+	operationManager *process.AccountMigrationOperationManager
+	operations       storage.Operations
+}
+
+func NewInitialisationStep(os storage.Operations) *InitialisationStep {
+	return &InitialisationStep{
+		operationManager: process.NewAccountMigrationOperationManager(os),
+		operations:       os,
+	}
+}
+
+func (s *InitialisationStep) Name() string {
+	return "Account_Migration_Initialization"
+}
+
+func (s *InitialisationStep) Run(operation internal.AccountMigrationOperation, log logrus.FieldLogger) (internal.AccountMigrationOperation, time.Duration, error) {
+	return s.RunWithContext(context.Background(), operation, log)
+}
+
+// RunWithContext runs the step with the storage calls recorded in the trace of the given context
+func (s *InitialisationStep) RunWithContext(ctx context.Context, operation internal.AccountMigrationOperation, log logrus.FieldLogger) (internal.AccountMigrationOperation, time.Duration, error) {
+	step := *s
+	step.operationManager = s.operationManager.WithContext(ctx)
+	return step.run(operation, log)
+}
+
+func (s *InitialisationStep) run(operation internal.AccountMigrationOperation, log logrus.FieldLogger) (internal.AccountMigrationOperation, time.Duration, error) {
+	if len(operation.AppliedChanges) > 0 || operation.Reverting() {
+		return operation, 0, nil
+	}
+
+	operations, err := s.operations.ListOperationsByInstanceID(operation.InstanceID)
+	if err != nil {
+		log.Errorf("unable to list operations of the instance: %s", err)
+		return operation, 10 * time.Second, nil
+	}
+	for _, op := range operations {
+		if op.ID != operation.ID && op.State == domain.InProgress {
+			return s.operationManager.OperationFailed(operation, fmt.Sprintf("operation %s of the instance is in progress", op.ID))
+		}
+	}
+
+	return operation, 0, nil
+}
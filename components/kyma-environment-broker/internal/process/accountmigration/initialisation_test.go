@@ -0,0 +1,64 @@
+package accountmigration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInitialisationStep_Run(t *testing.T) {
+	t.Run("should continue migration without other operation in progress", func(t *testing.T) {
+		// given
+		memoryStorage, operation := fixStorage(t)
+		step := NewInitialisationStep(memoryStorage.Operations())
+
+		// when
+		result, repeat, err := step.Run(operation, logrus.New())
+
+		// then
+		require.NoError(t, err)
+		assert.Zero(t, repeat)
+		assert.Equal(t, domain.InProgress, result.State)
+	})
+
+	t.Run("should fail migration when another operation was started in the meantime", func(t *testing.T) {
+		// given
+		memoryStorage, operation := fixStorage(t)
+		require.NoError(t, memoryStorage.Operations().InsertUpdatingOperation(internal.UpdatingOperation{
+			Operation: internal.Operation{ID: "update", InstanceID: fixInstanceID, State: domain.InProgress, CreatedAt: time.Now()},
+		}))
+		step := NewInitialisationStep(memoryStorage.Operations())
+
+		// when
+		result, _, err := step.Run(operation, logrus.New())
+
+		// then
+		require.Error(t, err)
+		assert.Equal(t, domain.Failed, result.State)
+		assert.Equal(t, "operation update of the instance is in progress", result.Description)
+	})
+
+	t.Run("should not check operations when changes were applied", func(t *testing.T) {
+		// given
+		memoryStorage, operation := fixStorage(t)
+		operation.AppliedChanges = []string{instanceChange}
+		require.NoError(t, memoryStorage.Operations().InsertUpdatingOperation(internal.UpdatingOperation{
+			Operation: internal.Operation{ID: "update", InstanceID: fixInstanceID, State: domain.InProgress, CreatedAt: time.Now()},
+		}))
+		step := NewInitialisationStep(memoryStorage.Operations())
+
+		// when
+		result, repeat, err := step.Run(operation, logrus.New())
+
+		// then
+		require.NoError(t, err)
+		assert.Zero(t, repeat)
+		assert.Equal(t, domain.InProgress, result.State)
+	})
+}
@@ -0,0 +1,255 @@
+package accountmigration
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/common/director"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	kebError "github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/error"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
+
+	gardenerapi "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// the time after which the applied changes are reverted and the operation is marked as failed
+	AccountMigrationTimeout = 1 * time.Hour
+
+	// subAccountLabel is the label of the runtime in the Director, which is set by the Provisioner from the labels
+	// of the provisioning input
+	subAccountLabel = "global_subaccount_id"
+	// globalAccountShootLabel and subAccountShootLabel are the labels of the shoot set by the Provisioner,
+	// the runtime resolver of the orchestrations selects the runtimes by them
+	globalAccountShootLabel = "account"
+	subAccountShootLabel    = "subaccount"
+
+	directorLabelsChange    = "director labels"
+	provisionerLabelsChange = "provisioner labels"
+	instanceChange          = "instance"
+)
+
+// DirectorClient updates the labels of the runtime registered in the Director
+type DirectorClient interface {
+	SetLabel(accountID, runtimeID, key, value string) error
+}
+
+// ShootClient is the interface to get and update the shoots in the Gardener cluster
+type ShootClient interface {
+	Get(name string, options metav1.GetOptions) (*gardenerapi.Shoot, error)
+	Update(shoot *gardenerapi.Shoot) (*gardenerapi.Shoot, error)
+}
+
+// change is a single change of the migration, it is applied with the target accounts of the operation
+// and reverted with the source accounts
+type change struct {
+	name string
+	set  func(operation internal.AccountMigrationOperation, globalAccountID, subAccountID string) error
+}
+
+// MigrateAccountStep applies the changes of the migration one by one. Every change is stored with the operation
+// before it is applied, so when the migration fails the applied changes are reverted in the reverse order,
+// also after the restart of the broker. The temporary errors are retried until the timeout of the migration.
+type MigrateAccountStep struct {
+	operationManager *process.AccountMigrationOperationManager
+	instances        storage.Instances
+	director         DirectorClient
+	shoots           ShootClient
+}
+
+func NewMigrateAccountStep(os storage.Operations, instances storage.Instances, director DirectorClient, shoots ShootClient) *MigrateAccountStep {
+	return &MigrateAccountStep{
+		operationManager: process.NewAccountMigrationOperationManager(os),
+		instances:        instances,
+		director:         director,
+		shoots:           shoots,
+	}
+}
+
+func (s *MigrateAccountStep) Name() string {
+	return "Migrate_Account"
+}
+
+func (s *MigrateAccountStep) Run(operation internal.AccountMigrationOperation, log logrus.FieldLogger) (internal.AccountMigrationOperation, time.Duration, error) {
+	return s.RunWithContext(context.Background(), operation, log)
+}
+
+// RunWithContext runs the step with the Director calls sent with the given context
+func (s *MigrateAccountStep) RunWithContext(ctx context.Context, operation internal.AccountMigrationOperation, log logrus.FieldLogger) (internal.AccountMigrationOperation, time.Duration, error) {
+	step := *s
+	step.operationManager = s.operationManager.WithContext(ctx)
+	step.director = directorClientWithContext(s.director, ctx)
+	return step.run(operation, log)
+}
+
+func (s *MigrateAccountStep) run(operation internal.AccountMigrationOperation, log logrus.FieldLogger) (internal.AccountMigrationOperation, time.Duration, error) {
+	if operation.Reverting() {
+		return s.revert(operation, log)
+	}
+	if time.Since(operation.TimeoutStart()) > AccountMigrationTimeout {
+		log.Infof("operation has reached the time limit: operation started at: %s", operation.TimeoutStart())
+		return s.startRevert(operation, fmt.Sprintf("operation has reached the time limit: %s", AccountMigrationTimeout), log)
+	}
+
+	var repeat time.Duration
+	for _, current := range s.changes(operation) {
+		if !applied(operation, current.name) {
+			operation.AppliedChanges = append(operation.AppliedChanges, current.name)
+			operation, repeat = s.operationManager.UpdateOperation(operation)
+			if repeat != 0 {
+				log.Errorf("cannot save the change %s of the migration", current.name)
+				return operation, 5 * time.Second, nil
+			}
+		}
+
+		err := current.set(operation, operation.TargetGlobalAccountID, operation.TargetSubAccountID)
+		switch {
+		case kebError.IsTemporaryError(err):
+			log.Errorf("unable to migrate %s (temporary error): %s", current.name, err)
+			return operation, 10 * time.Second, nil
+		case err != nil:
+			log.Errorf("unable to migrate %s: %s", current.name, err)
+			// the change which failed is not applied, only the changes before it are reverted
+			operation.AppliedChanges = operation.AppliedChanges[:len(operation.AppliedChanges)-1]
+			return s.startRevert(operation, fmt.Sprintf("unable to migrate %s", current.name), log)
+		}
+		log.Infof("%s migrated", current.name)
+	}
+
+	return s.operationManager.OperationSucceeded(operation, "Instance migrated")
+}
+
+// startRevert stores the reason of the failure with the operation, so the migration is reverted also when the step
+// is run again, and reverts the applied changes
+func (s *MigrateAccountStep) startRevert(operation internal.AccountMigrationOperation, reason string, log logrus.FieldLogger) (internal.AccountMigrationOperation, time.Duration, error) {
+	operation.RevertReason = reason
+	operation, repeat := s.operationManager.UpdateOperation(operation)
+	if repeat != 0 {
+		log.Errorf("cannot save the revert of the migration")
+		return operation, 5 * time.Second, nil
+	}
+	return s.revert(operation, log)
+}
+
+// revert sets the source accounts back in the reverse order of the applied changes and fails the operation
+func (s *MigrateAccountStep) revert(operation internal.AccountMigrationOperation, log logrus.FieldLogger) (internal.AccountMigrationOperation, time.Duration, error) {
+	changes := make(map[string]change)
+	for _, c := range s.changes(operation) {
+		changes[c.name] = c
+	}
+
+	var repeat time.Duration
+	for len(operation.AppliedChanges) > 0 {
+		name := operation.AppliedChanges[len(operation.AppliedChanges)-1]
+		if current, found := changes[name]; found {
+			err := current.set(operation, operation.SourceGlobalAccountID, operation.SourceSubAccountID)
+			switch {
+			case kebError.IsTemporaryError(err):
+				log.Errorf("unable to revert the migration of %s (temporary error): %s", name, err)
+				return s.operationManager.RetryOperation(operation, fmt.Sprintf("%s, unable to revert the migration of %s", operation.RevertReason, name), 10*time.Second, AccountMigrationTimeout, log)
+			case err != nil:
+				log.Errorf("unable to revert the migration of %s: %s", name, err)
+				return s.operationManager.OperationFailed(operation, fmt.Sprintf("%s, unable to revert the migration of %s", operation.RevertReason, name))
+			}
+			log.Infof("migration of %s reverted", name)
+		}
+
+		operation.AppliedChanges = operation.AppliedChanges[:len(operation.AppliedChanges)-1]
+		operation, repeat = s.operationManager.UpdateOperation(operation)
+		if repeat != 0 {
+			log.Errorf("cannot save the reverted change %s of the migration", name)
+			return operation, 5 * time.Second, nil
+		}
+	}
+
+	return s.operationManager.OperationFailed(operation, operation.RevertReason)
+}
+
+func (s *MigrateAccountStep) changes(operation internal.AccountMigrationOperation) []change {
+	var changes []change
+
+	// the runtime is registered in the Director only when the provisioning started
+	if operation.RuntimeID != "" && operation.SourceSubAccountID != operation.TargetSubAccountID {
+		changes = append(changes, change{name: directorLabelsChange, set: s.setDirectorLabels})
+	}
+	// the shoot is created by the Provisioner, its name is stored in the instance when the provisioning succeeded
+	if operation.ShootName != "" {
+		changes = append(changes, change{name: provisionerLabelsChange, set: s.setShootLabels})
+	}
+	changes = append(changes, change{name: instanceChange, set: s.setInstanceAccounts})
+
+	return changes
+}
+
+func (s *MigrateAccountStep) setDirectorLabels(operation internal.AccountMigrationOperation, _, subAccountID string) error {
+	return s.director.SetLabel(operation.Tenant, operation.RuntimeID, subAccountLabel, subAccountID)
+}
+
+// setShootLabels sets the labels of the shoot which the Provisioner set from the accounts of the provisioning request
+func (s *MigrateAccountStep) setShootLabels(operation internal.AccountMigrationOperation, globalAccountID, subAccountID string) error {
+	shoot, err := s.shoots.Get(operation.ShootName, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		return errors.Errorf("shoot %s does not exist", operation.ShootName)
+	case err != nil:
+		return kebError.AsTemporaryError(err, "while getting shoot %s", operation.ShootName)
+	}
+
+	if shoot.Labels == nil {
+		shoot.Labels = make(map[string]string)
+	}
+	shoot.Labels[globalAccountShootLabel] = globalAccountID
+	shoot.Labels[subAccountShootLabel] = subAccountID
+	_, err = s.shoots.Update(shoot)
+	if err != nil {
+		// the conflict is returned if the shoot was changed in the meantime, the shoot is read again
+		return kebError.AsTemporaryError(err, "while updating labels of shoot %s", operation.ShootName)
+	}
+	return nil
+}
+
+// setInstanceAccounts stores the accounts in the instance, the tenant of the runtime is kept in the instance
+// when the instance is moved out of the global account it was provisioned in
+func (s *MigrateAccountStep) setInstanceAccounts(operation internal.AccountMigrationOperation, globalAccountID, subAccountID string) error {
+	instance, err := s.instances.GetByID(operation.InstanceID)
+	switch {
+	case dberr.IsNotFound(err):
+		return errors.Errorf("instance %s does not exist", operation.InstanceID)
+	case err != nil:
+		return kebError.AsTemporaryError(err, "while getting instance")
+	}
+
+	instance.GlobalAccountID = globalAccountID
+	instance.SubAccountID = subAccountID
+	instance.OriginalGlobalAccountID = ""
+	if globalAccountID != operation.Tenant {
+		instance.OriginalGlobalAccountID = operation.Tenant
+	}
+	if err := s.instances.Update(*instance); err != nil {
+		return kebError.AsTemporaryError(err, "while updating instance")
+	}
+	return nil
+}
+
+func applied(operation internal.AccountMigrationOperation, name string) bool {
+	for _, c := range operation.AppliedChanges {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+func directorClientWithContext(dc DirectorClient, ctx context.Context) DirectorClient {
+	if cli, ok := dc.(*director.Client); ok {
+		return cli.WithContext(ctx)
+	}
+	return dc
+}
@@ -0,0 +1,180 @@
+package accountmigration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	kebError "github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/error"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+
+	gardenerapi "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	gardenerfake "github.com/gardener/gardener/pkg/client/core/clientset/versioned/fake"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	fixOperationID       = "3f5a0c1e-7b2d-4c8e-9a1f-2d6b8e4c0a7b"
+	fixInstanceID        = "9d75a545-2e1e-4786-abd8-a37b14e185b9"
+	fixRuntimeID         = "ef4e3210-652c-453e-8015-bba1c1cd1e1c"
+	fixShootName         = "c-1a2b3c4"
+	fixGardenerNamespace = "garden-kyma"
+)
+
+func TestMigrateAccountStep_Run(t *testing.T) {
+	t.Run("should apply all changes", func(t *testing.T) {
+		// given
+		memoryStorage, operation := fixStorage(t)
+		shoots := gardenerfake.NewSimpleClientset(fixShoot()).CoreV1beta1().Shoots(fixGardenerNamespace)
+		director := &fakeDirector{labels: map[string]string{}}
+		step := NewMigrateAccountStep(memoryStorage.Operations(), memoryStorage.Instances(), director, shoots)
+
+		// when
+		result, repeat, err := step.Run(operation, logrus.New())
+
+		// then
+		require.NoError(t, err)
+		assert.Zero(t, repeat)
+		assert.Equal(t, domain.Succeeded, result.State)
+		assert.Equal(t, []string{directorLabelsChange, provisionerLabelsChange, instanceChange}, result.AppliedChanges)
+
+		assert.Equal(t, "new-sa", director.labels["ga/"+fixRuntimeID+"/global_subaccount_id"])
+		shoot, err := shoots.Get(fixShootName, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "new-ga", shoot.Labels["account"])
+		assert.Equal(t, "new-sa", shoot.Labels["subaccount"])
+		instance, err := memoryStorage.Instances().GetByID(fixInstanceID)
+		require.NoError(t, err)
+		assert.Equal(t, "new-ga", instance.GlobalAccountID)
+		assert.Equal(t, "new-sa", instance.SubAccountID)
+		assert.Equal(t, "ga", instance.Tenant(), "the runtime stays in the tenant of the original global account")
+	})
+
+	t.Run("should revert applied changes when shoot does not exist", func(t *testing.T) {
+		// given
+		memoryStorage, operation := fixStorage(t)
+		shoots := gardenerfake.NewSimpleClientset().CoreV1beta1().Shoots(fixGardenerNamespace)
+		director := &fakeDirector{labels: map[string]string{}}
+		step := NewMigrateAccountStep(memoryStorage.Operations(), memoryStorage.Instances(), director, shoots)
+
+		// when
+		result, _, err := step.Run(operation, logrus.New())
+
+		// then
+		require.Error(t, err)
+		assert.Equal(t, domain.Failed, result.State)
+		assert.Equal(t, "unable to migrate provisioner labels", result.Description)
+		assert.Empty(t, result.AppliedChanges)
+		assert.Equal(t, "sa", director.labels["ga/"+fixRuntimeID+"/global_subaccount_id"])
+		instance, err := memoryStorage.Instances().GetByID(fixInstanceID)
+		require.NoError(t, err)
+		assert.Equal(t, "ga", instance.GlobalAccountID)
+	})
+
+	t.Run("should retry temporary error", func(t *testing.T) {
+		// given
+		memoryStorage, operation := fixStorage(t)
+		shoots := gardenerfake.NewSimpleClientset(fixShoot()).CoreV1beta1().Shoots(fixGardenerNamespace)
+		director := &fakeDirector{labels: map[string]string{}, err: kebError.NewTemporaryError("director unavailable")}
+		step := NewMigrateAccountStep(memoryStorage.Operations(), memoryStorage.Instances(), director, shoots)
+
+		// when
+		result, repeat, err := step.Run(operation, logrus.New())
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 10*time.Second, repeat)
+		assert.Equal(t, domain.InProgress, result.State)
+		stored, err := memoryStorage.Operations().GetAccountMigrationOperationByID(fixOperationID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{directorLabelsChange}, stored.AppliedChanges, "the change is stored before it is applied")
+	})
+
+	t.Run("should continue reverting after restart", func(t *testing.T) {
+		// given
+		memoryStorage, operation := fixStorage(t)
+		operation.AppliedChanges = []string{directorLabelsChange, provisionerLabelsChange}
+		operation.RevertReason = "unable to migrate instance"
+		shoots := gardenerfake.NewSimpleClientset(fixShoot()).CoreV1beta1().Shoots(fixGardenerNamespace)
+		director := &fakeDirector{labels: map[string]string{"ga/" + fixRuntimeID + "/global_subaccount_id": "new-sa"}}
+		step := NewMigrateAccountStep(memoryStorage.Operations(), memoryStorage.Instances(), director, shoots)
+
+		// when
+		result, _, err := step.Run(operation, logrus.New())
+
+		// then
+		require.Error(t, err)
+		assert.Equal(t, domain.Failed, result.State)
+		assert.Equal(t, "unable to migrate instance", result.Description)
+		assert.Equal(t, "sa", director.labels["ga/"+fixRuntimeID+"/global_subaccount_id"])
+		shoot, err := shoots.Get(fixShootName, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "ga", shoot.Labels["account"])
+		assert.Equal(t, "sa", shoot.Labels["subaccount"])
+	})
+
+	t.Run("should fail without reverting when revert is not possible", func(t *testing.T) {
+		// given
+		memoryStorage, operation := fixStorage(t)
+		operation.AppliedChanges = []string{directorLabelsChange}
+		operation.RevertReason = "unable to migrate provisioner labels"
+		director := &fakeDirector{labels: map[string]string{}, err: errors.New("forbidden")}
+		step := NewMigrateAccountStep(memoryStorage.Operations(), memoryStorage.Instances(), director, gardenerfake.NewSimpleClientset().CoreV1beta1().Shoots(fixGardenerNamespace))
+
+		// when
+		result, _, err := step.Run(operation, logrus.New())
+
+		// then
+		require.Error(t, err)
+		assert.Equal(t, domain.Failed, result.State)
+		assert.Equal(t, "unable to migrate provisioner labels, unable to revert the migration of director labels", result.Description)
+	})
+}
+
+func fixStorage(t *testing.T) (storage.BrokerStorage, internal.AccountMigrationOperation) {
+	memoryStorage := storage.NewMemoryStorage()
+	instance := internal.Instance{
+		InstanceID:      fixInstanceID,
+		RuntimeID:       fixRuntimeID,
+		GlobalAccountID: "ga",
+		SubAccountID:    "sa",
+		ShootName:       fixShootName,
+	}
+	require.NoError(t, memoryStorage.Instances().Insert(instance))
+
+	operation := internal.NewAccountMigrationOperation(instance, "new-ga", "new-sa")
+	operation.ID = fixOperationID
+	require.NoError(t, memoryStorage.Operations().InsertAccountMigrationOperation(operation))
+	return memoryStorage, operation
+}
+
+func fixShoot() *gardenerapi.Shoot {
+	return &gardenerapi.Shoot{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fixShootName,
+			Namespace: fixGardenerNamespace,
+			Labels: map[string]string{
+				"account":    "ga",
+				"subaccount": "sa",
+			},
+		},
+	}
+}
+
+type fakeDirector struct {
+	labels map[string]string
+	err    error
+}
+
+func (d *fakeDirector) SetLabel(accountID, runtimeID, key, value string) error {
+	if d.err != nil {
+		return d.err
+	}
+	d.labels[accountID+"/"+runtimeID+"/"+key] = value
+	return nil
+}
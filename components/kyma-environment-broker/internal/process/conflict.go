@@ -0,0 +1,56 @@
+package process
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/tracing"
+)
+
+// storeOperation updates the operation changed by the step within the span of the storage call. The step works
+// on its own copy of the operation, so on the conflict the copy is never written over the latest version, which
+// would lose the concurrent changes. The operation finished by someone else in the meantime, e.g. canceled together
+// with its orchestration or failed by the stale operations detector, is read with latest and returned without error,
+// so the process stops. Otherwise the conflict is returned and the step is repeated with the latest version.
+func storeOperation(ctx context.Context, spanName string, update func() error, latest func() (*internal.Operation, error)) error {
+	return tracing.Trace(ctx, spanName, func() error {
+		err := update()
+		if !dberr.IsConflict(err) {
+			return err
+		}
+		countConflict(ctx)
+		operation, getErr := latest()
+		if getErr != nil {
+			return getErr
+		}
+		if operation.IsFinished() {
+			return nil
+		}
+		return err
+	})
+}
+
+type conflictsKey struct{}
+
+// WithConflictCounter returns the context which counts the conflicts of the operation updates made with it,
+// the step hooks use it to find out how many times the step run hit the optimistic locking conflict
+func WithConflictCounter(ctx context.Context) context.Context {
+	return context.WithValue(ctx, conflictsKey{}, new(int64))
+}
+
+// Conflicts returns the number of the conflicts counted in the context created with WithConflictCounter
+func Conflicts(ctx context.Context) int {
+	counter, ok := ctx.Value(conflictsKey{}).(*int64)
+	if !ok {
+		return 0
+	}
+	return int(atomic.LoadInt64(counter))
+}
+
+func countConflict(ctx context.Context) {
+	if counter, ok := ctx.Value(conflictsKey{}).(*int64); ok {
+		atomic.AddInt64(counter, 1)
+	}
+}
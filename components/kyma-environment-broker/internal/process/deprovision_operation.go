@@ -1,6 +1,7 @@
 package process
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -15,14 +16,23 @@ import (
 
 type DeprovisionOperationManager struct {
 	storage storage.Operations
+	ctx     context.Context
 }
 
 func NewDeprovisionOperationManager(storage storage.Operations) *DeprovisionOperationManager {
 	return &DeprovisionOperationManager{
 		storage: storage,
+		ctx:     context.Background(),
 	}
 }
 
+// WithContext returns the copy of the manager which records the storage calls in the trace of the given context
+func (om *DeprovisionOperationManager) WithContext(ctx context.Context) *DeprovisionOperationManager {
+	withContext := *om
+	withContext.ctx = ctx
+	return &withContext
+}
+
 // OperationSucceeded marks the operation as succeeded and only repeats it if there is a storage error
 func (om *DeprovisionOperationManager) OperationSucceeded(operation internal.DeprovisioningOperation, description string) (internal.DeprovisioningOperation, time.Duration, error) {
 	updatedOperation, repeat := om.update(operation, domain.Succeeded, description)
@@ -47,7 +57,7 @@ func (om *DeprovisionOperationManager) OperationFailed(operation internal.Deprov
 
 // UpdateOperation updates a given operation
 func (om *DeprovisionOperationManager) UpdateOperation(operation internal.DeprovisioningOperation) (internal.DeprovisioningOperation, time.Duration, error) {
-	updatedOperation, err := om.storage.UpdateDeprovisioningOperation(operation)
+	updatedOperation, err := om.store(operation)
 	if err != nil {
 		return operation, 1 * time.Minute, nil
 	}
@@ -103,7 +113,7 @@ func (om *DeprovisionOperationManager) update(operation internal.DeprovisioningO
 	operation.State = state
 	operation.Description = fmt.Sprintf("%s : %s", operation.Description, description)
 
-	updatedOperation, err := om.storage.UpdateDeprovisioningOperation(operation)
+	updatedOperation, err := om.store(operation)
 	// repeat if there is a problem with the storage
 	if err != nil {
 		return operation, 1 * time.Minute
@@ -111,3 +121,18 @@ func (om *DeprovisionOperationManager) update(operation internal.DeprovisioningO
 
 	return *updatedOperation, 0
 }
+
+// store updates the operation within the span of the storage call, see storeOperation for the conflict handling
+func (om *DeprovisionOperationManager) store(operation internal.DeprovisioningOperation) (updatedOperation *internal.DeprovisioningOperation, err error) {
+	err = storeOperation(om.ctx, "storage/UpdateDeprovisioningOperation", func() error {
+		updatedOperation, err = om.storage.UpdateDeprovisioningOperation(operation)
+		return err
+	}, func() (*internal.Operation, error) {
+		updatedOperation, err = om.storage.GetDeprovisioningOperationByID(operation.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &updatedOperation.Operation, nil
+	})
+	return updatedOperation, err
+}
@@ -1,31 +1,26 @@
 package deprovisioning
 
 import (
+	"context"
 	"time"
 
-	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process"
-
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/avs"
-	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
 	"github.com/sirupsen/logrus"
 )
 
+// AvsEvaluationRemovalStep is run by the ParallelStep, every deleted evaluation is stored right away by the delegator
 type AvsEvaluationRemovalStep struct {
 	delegator             *avs.Delegator
-	operationsStorage     storage.Operations
 	externalEvalAssistant avs.EvalAssistant
 	internalEvalAssistant avs.EvalAssistant
-	deProvisioningManager *process.DeprovisionOperationManager
 }
 
-func NewAvsEvaluationsRemovalStep(delegator *avs.Delegator, operationsStorage storage.Operations, externalEvalAssistant, internalEvalAssistant avs.EvalAssistant) *AvsEvaluationRemovalStep {
+func NewAvsEvaluationsRemovalStep(delegator *avs.Delegator, externalEvalAssistant, internalEvalAssistant avs.EvalAssistant) *AvsEvaluationRemovalStep {
 	return &AvsEvaluationRemovalStep{
 		delegator:             delegator,
-		operationsStorage:     operationsStorage,
 		externalEvalAssistant: externalEvalAssistant,
 		internalEvalAssistant: internalEvalAssistant,
-		deProvisioningManager: process.NewDeprovisionOperationManager(operationsStorage),
 	}
 }
 
@@ -34,6 +29,17 @@ func (ars *AvsEvaluationRemovalStep) Name() string {
 }
 
 func (ars *AvsEvaluationRemovalStep) Run(deProvisioningOperation internal.DeprovisioningOperation, logger logrus.FieldLogger) (internal.DeprovisioningOperation, time.Duration, error) {
+	return ars.RunWithContext(context.Background(), deProvisioningOperation, logger)
+}
+
+// RunWithContext runs the step with the AVS calls sent with the given context
+func (ars *AvsEvaluationRemovalStep) RunWithContext(ctx context.Context, deProvisioningOperation internal.DeprovisioningOperation, logger logrus.FieldLogger) (internal.DeprovisioningOperation, time.Duration, error) {
+	step := *ars
+	step.delegator = ars.delegator.WithContext(ctx)
+	return step.run(deProvisioningOperation, logger)
+}
+
+func (ars *AvsEvaluationRemovalStep) run(deProvisioningOperation internal.DeprovisioningOperation, logger logrus.FieldLogger) (internal.DeprovisioningOperation, time.Duration, error) {
 	logger.Infof("Avs lifecycle %+v", deProvisioningOperation.Avs)
 	if deProvisioningOperation.Avs.AVSExternalEvaluationDeleted && deProvisioningOperation.Avs.AVSInternalEvaluationDeleted {
 		logger.Infof("Both internal and external evaluations have been deleted")
@@ -42,12 +48,12 @@ func (ars *AvsEvaluationRemovalStep) Run(deProvisioningOperation internal.Deprov
 
 	deProvisioningOperation, err := ars.delegator.DeleteAvsEvaluation(deProvisioningOperation, logger, ars.internalEvalAssistant)
 	if err != nil {
-		return ars.deProvisioningManager.RetryOperationWithoutFail(deProvisioningOperation, err.Error(), 10*time.Second, 10*time.Minute, logger)
+		return retryWithoutFail(deProvisioningOperation, err.Error(), 10*time.Second, 10*time.Minute, logger)
 	}
 
 	deProvisioningOperation, err = ars.delegator.DeleteAvsEvaluation(deProvisioningOperation, logger, ars.externalEvalAssistant)
 	if err != nil {
-		return ars.deProvisioningManager.RetryOperationWithoutFail(deProvisioningOperation, err.Error(), 10*time.Second, 10*time.Minute, logger)
+		return retryWithoutFail(deProvisioningOperation, err.Error(), 10*time.Second, 10*time.Minute, logger)
 	}
 	return deProvisioningOperation, 0, nil
 
@@ -50,7 +50,7 @@ func TestAvsEvaluationsRemovalStep_Run(t *testing.T) {
 	avsDel := avs.NewDelegator(avsClient, avsConfig, memoryStorage.Operations())
 	internalEvalAssistant := avs.NewInternalEvalAssistant(avsConfig)
 	externalEvalAssistant := avs.NewExternalEvalAssistant(avsConfig)
-	step := NewAvsEvaluationsRemovalStep(avsDel, memoryStorage.Operations(), externalEvalAssistant, internalEvalAssistant)
+	step := NewAvsEvaluationsRemovalStep(avsDel, externalEvalAssistant, internalEvalAssistant)
 
 	assert.Equal(t, 0, len(evalIdsHolder))
 	assert.Equal(t, 0, len(parentEvalIdHolder))
@@ -71,6 +71,11 @@ func TestAvsEvaluationsRemovalStep_Run(t *testing.T) {
 	assert.Equal(t, parentEvalIdHolder[internalEvalId], parentEvalId)
 	assert.Equal(t, parentEvalIdHolder[externalEvalId], parentEvalId)
 
+	assert.True(t, deProvisioningOperation.Avs.AVSInternalEvaluationDeleted)
+	assert.True(t, deProvisioningOperation.Avs.AVSExternalEvaluationDeleted)
+	assert.Equal(t, internalEvalId, deProvisioningOperation.Avs.AvsEvaluationInternalId)
+	assert.Equal(t, externalEvalId, deProvisioningOperation.Avs.AVSEvaluationExternalId)
+
 	inDB, err := memoryStorage.Operations().GetDeprovisioningOperationByID(deProvisioningOperation.ID)
 	assert.NoError(t, err)
 	assert.True(t, inDB.Avs.AVSInternalEvaluationDeleted)
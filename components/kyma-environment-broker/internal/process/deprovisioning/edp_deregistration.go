@@ -1,6 +1,7 @@
 package deprovisioning
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -34,6 +35,17 @@ func (s *EDPDeregistrationStep) Name() string {
 }
 
 func (s *EDPDeregistrationStep) Run(operation internal.DeprovisioningOperation, log logrus.FieldLogger) (internal.DeprovisioningOperation, time.Duration, error) {
+	return s.RunWithContext(context.Background(), operation, log)
+}
+
+// RunWithContext runs the step with the EDP calls sent with the given context
+func (s *EDPDeregistrationStep) RunWithContext(ctx context.Context, operation internal.DeprovisioningOperation, log logrus.FieldLogger) (internal.DeprovisioningOperation, time.Duration, error) {
+	step := *s
+	step.client = edpClientWithContext(s.client, ctx)
+	return step.run(operation, log)
+}
+
+func (s *EDPDeregistrationStep) run(operation internal.DeprovisioningOperation, log logrus.FieldLogger) (internal.DeprovisioningOperation, time.Duration, error) {
 	log.Info("Delete DataTenant metadata")
 	for _, key := range []string{
 		edp.MaasConsumerEnvironmentKey,
@@ -69,3 +81,10 @@ func (s *EDPDeregistrationStep) handleError(operation internal.DeprovisioningOpe
 	log.Errorf("Step %s failed. EDP data have not been deleted.", s.Name())
 	return operation, 0, nil
 }
+
+func edpClientWithContext(c EDPClient, ctx context.Context) EDPClient {
+	if cli, ok := c.(*edp.Client); ok {
+		return cli.WithContext(ctx)
+	}
+	return c
+}
@@ -10,21 +10,18 @@ import (
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/common/hyperscaler"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/common/hyperscaler/azure"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
-	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process"
 	processazure "github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process/azure"
-	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
 )
 
+// DeprovisionAzureEventHubStep is run by the ParallelStep, the deleted Event Hub is stored by the ParallelStep
 type DeprovisionAzureEventHubStep struct {
-	OperationManager *process.DeprovisionOperationManager
 	processazure.EventHub
 }
 
-func NewDeprovisionAzureEventHubStep(os storage.Operations, hyperscalerProvider azure.HyperscalerProvider,
+func NewDeprovisionAzureEventHubStep(hyperscalerProvider azure.HyperscalerProvider,
 	accountProvider hyperscaler.AccountProvider,
 	ctx context.Context) DeprovisionAzureEventHubStep {
 	return DeprovisionAzureEventHubStep{
-		OperationManager: process.NewDeprovisionOperationManager(os),
 		EventHub: processazure.EventHub{
 			HyperscalerProvider: hyperscalerProvider,
 			AccountProvider:     accountProvider,
@@ -65,7 +62,7 @@ func (s DeprovisionAzureEventHubStep) Run(operation internal.DeprovisioningOpera
 	if err != nil {
 		// retrying might solve the issue, the HAP could be temporarily unavailable
 		errorMessage := fmt.Sprintf("unable to retrieve Gardener Credentials from HAP lookup: %v", err)
-		return s.OperationManager.RetryOperationWithoutFail(operation, errorMessage, time.Minute, 30*time.Minute, log)
+		return retryWithoutFail(operation, errorMessage, time.Minute, 30*time.Minute, log)
 	}
 	azureCfg, err := azure.GetConfigFromHAPCredentialsAndProvisioningParams(credentials, pp)
 	if err != nil {
@@ -99,7 +96,7 @@ func (s DeprovisionAzureEventHubStep) Run(operation internal.DeprovisioningOpera
 		}
 		// custom error occurred while getting resource group - try again
 		errorMessage := fmt.Sprintf("error while getting resource group, error: %v", err)
-		return s.OperationManager.RetryOperationWithoutFail(operation, errorMessage, time.Minute, time.Hour, log)
+		return retryWithoutFail(operation, errorMessage, time.Minute, time.Hour, log)
 	}
 	// delete the resource group if it still exists and deletion has not been triggered yet
 	if resourceGroup.Properties == nil || resourceGroup.Properties.ProvisioningState == nil {
@@ -111,7 +108,7 @@ func (s DeprovisionAzureEventHubStep) Run(operation internal.DeprovisioningOpera
 		future, err := namespaceClient.DeleteResourceGroup(s.EventHub.Context, tags)
 		if err != nil {
 			errorMessage := fmt.Sprintf("unable to delete Azure resource group: %v", err)
-			return s.OperationManager.RetryOperationWithoutFail(operation, errorMessage, time.Minute, time.Hour,
+			return retryWithoutFail(operation, errorMessage, time.Minute, time.Hour,
 				log)
 		}
 		if future.Status() != azure.FutureOperationSucceeded {
@@ -123,10 +120,10 @@ func (s DeprovisionAzureEventHubStep) Run(operation internal.DeprovisioningOpera
 			}
 			log.Infof("rescheduling step to check deletion of resource group completed after %v",
 				retryAfterDuration)
-			return s.OperationManager.RetryOperationWithoutFail(operation,
+			return retryWithoutFail(operation,
 				"waiting for deprovisioning of azure resource group", retryAfterDuration, time.Hour, log)
 		}
 	}
 	errorMessage := "waiting for deprovisioning of azure resource group"
-	return s.OperationManager.RetryOperationWithoutFail(operation, errorMessage, time.Minute, time.Hour, log)
+	return retryWithoutFail(operation, errorMessage, time.Minute, time.Hour, log)
 }
@@ -220,7 +220,7 @@ func Test_StepsUnhappyPath(t *testing.T) {
 			giveInstance:  fixInstance,
 			giveStep: func(t *testing.T, storage storage.BrokerStorage) DeprovisionAzureEventHubStep {
 				accountProvider := fixAccountProviderGardenerCredentialsError()
-				return NewDeprovisionAzureEventHubStep(storage.Operations(),
+				return NewDeprovisionAzureEventHubStep(
 					// ups ... namespace cannot get listed
 					azuretesting.NewFakeHyperscalerProvider(azuretesting.NewFakeNamespaceClientListError()),
 					accountProvider,
@@ -235,7 +235,7 @@ func Test_StepsUnhappyPath(t *testing.T) {
 			giveInstance:  fixInstance,
 			giveStep: func(t *testing.T, storage storage.BrokerStorage) DeprovisionAzureEventHubStep {
 				accountProvider := fixAccountProviderGardenerCredentialsHAPError()
-				return NewDeprovisionAzureEventHubStep(storage.Operations(),
+				return NewDeprovisionAzureEventHubStep(
 					azuretesting.NewFakeHyperscalerProvider(azuretesting.NewFakeNamespaceAccessKeysNil()),
 					accountProvider,
 					context.Background(),
@@ -249,7 +249,7 @@ func Test_StepsUnhappyPath(t *testing.T) {
 			giveInstance:  fixInstance,
 			giveStep: func(t *testing.T, storage storage.BrokerStorage) DeprovisionAzureEventHubStep {
 				accountProvider := fixAccountProvider()
-				return NewDeprovisionAzureEventHubStep(storage.Operations(),
+				return NewDeprovisionAzureEventHubStep(
 					// ups ... client cannot be created
 					azuretesting.NewFakeHyperscalerProviderError(),
 					accountProvider,
@@ -264,7 +264,7 @@ func Test_StepsUnhappyPath(t *testing.T) {
 			giveInstance:  fixInstance,
 			giveStep: func(t *testing.T, storage storage.BrokerStorage) DeprovisionAzureEventHubStep {
 				accountProvider := fixAccountProvider()
-				return NewDeprovisionAzureEventHubStep(storage.Operations(),
+				return NewDeprovisionAzureEventHubStep(
 					// ups ... can't get resource group
 					azuretesting.NewFakeHyperscalerProvider(azuretesting.NewFakeNamespaceClientResourceGroupConnectionError()),
 					accountProvider,
@@ -279,7 +279,7 @@ func Test_StepsUnhappyPath(t *testing.T) {
 			giveInstance:  fixInstance,
 			giveStep: func(t *testing.T, storage storage.BrokerStorage) DeprovisionAzureEventHubStep {
 				accountProvider := fixAccountProvider()
-				return NewDeprovisionAzureEventHubStep(storage.Operations(),
+				return NewDeprovisionAzureEventHubStep(
 					// ups ... can't delete resource group
 					azuretesting.NewFakeHyperscalerProvider(azuretesting.NewFakeNamespaceClientResourceGroupDeleteError()),
 					accountProvider,
@@ -294,7 +294,7 @@ func Test_StepsUnhappyPath(t *testing.T) {
 			giveInstance:  fixInstance,
 			giveStep: func(t *testing.T, storage storage.BrokerStorage) DeprovisionAzureEventHubStep {
 				accountProvider := fixAccountProvider()
-				return NewDeprovisionAzureEventHubStep(storage.Operations(),
+				return NewDeprovisionAzureEventHubStep(
 					// ups ... can't delete resource group
 					azuretesting.NewFakeHyperscalerProvider(azuretesting.NewFakeNamespaceClientResourceGroupPropertiesError()),
 					accountProvider,
@@ -369,7 +369,7 @@ func fixAccountProvider() *hyperscalerautomock.AccountProvider {
 
 func fixEventHubStep(memoryStorageOp storage.Operations, instanceStorage storage.Instances, hyperscalerProvider azure.HyperscalerProvider,
 	accountProvider *hyperscalerautomock.AccountProvider) DeprovisionAzureEventHubStep {
-	return NewDeprovisionAzureEventHubStep(memoryStorageOp, hyperscalerProvider, accountProvider, context.Background())
+	return NewDeprovisionAzureEventHubStep(hyperscalerProvider, accountProvider, context.Background())
 }
 
 func fixLogger() logrus.FieldLogger {
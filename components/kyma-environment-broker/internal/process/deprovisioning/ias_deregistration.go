@@ -1,26 +1,24 @@
 package deprovisioning
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/ias"
-	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process"
-	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
 
 	"github.com/sirupsen/logrus"
 )
 
+// IASDeregistrationStep is run by the ParallelStep, the step does not store the operation
 type IASDeregistrationStep struct {
-	operationManager *process.DeprovisionOperationManager
-	bundleBuilder    ias.BundleBuilder
+	bundleBuilder ias.BundleBuilder
 }
 
-func NewIASDeregistrationStep(os storage.Operations, bundleBuilder ias.BundleBuilder) *IASDeregistrationStep {
+func NewIASDeregistrationStep(bundleBuilder ias.BundleBuilder) *IASDeregistrationStep {
 	return &IASDeregistrationStep{
-		operationManager: process.NewDeprovisionOperationManager(os),
-		bundleBuilder:    bundleBuilder,
+		bundleBuilder: bundleBuilder,
 	}
 }
 
@@ -29,6 +27,17 @@ func (s *IASDeregistrationStep) Name() string {
 }
 
 func (s *IASDeregistrationStep) Run(operation internal.DeprovisioningOperation, log logrus.FieldLogger) (internal.DeprovisioningOperation, time.Duration, error) {
+	return s.RunWithContext(context.Background(), operation, log)
+}
+
+// RunWithContext runs the step with the IAS calls sent with the given context
+func (s *IASDeregistrationStep) RunWithContext(ctx context.Context, operation internal.DeprovisioningOperation, log logrus.FieldLogger) (internal.DeprovisioningOperation, time.Duration, error) {
+	step := *s
+	step.bundleBuilder = ias.BundleBuilderWithContext(s.bundleBuilder, ctx)
+	return step.run(operation, log)
+}
+
+func (s *IASDeregistrationStep) run(operation internal.DeprovisioningOperation, log logrus.FieldLogger) (internal.DeprovisioningOperation, time.Duration, error) {
 	for spID := range ias.ServiceProviderInputs {
 		spb, err := s.bundleBuilder.NewBundle(operation.InstanceID, spID)
 		if err != nil {
@@ -41,7 +50,7 @@ func (s *IASDeregistrationStep) Run(operation internal.DeprovisioningOperation,
 		if err != nil {
 			msg := fmt.Sprintf("cannot delete ServiceProvider %s", spb.ServiceProviderName())
 			log.Errorf("%s: %s", msg, err)
-			return s.operationManager.RetryOperationWithoutFail(operation, msg, 5*time.Second, 5*time.Minute, log)
+			return retryWithoutFail(operation, msg, 5*time.Second, 5*time.Minute, log)
 		}
 	}
 
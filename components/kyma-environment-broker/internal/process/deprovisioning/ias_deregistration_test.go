@@ -8,7 +8,6 @@ import (
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/ias"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/ias/automock"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/logger"
-	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -17,8 +16,6 @@ const iasInstanceID = "9b130e29-7f1c-4778-8f0a-b9110304cf27"
 
 func TestIASDeregistration_Run(t *testing.T) {
 	// given
-	memoryStorage := storage.NewMemoryStorage()
-
 	bundleBuilder := &automock.BundleBuilder{}
 	defer bundleBuilder.AssertExpectations(t)
 
@@ -35,7 +32,7 @@ func TestIASDeregistration_Run(t *testing.T) {
 		},
 	}
 
-	step := NewIASDeregistrationStep(memoryStorage.Operations(), bundleBuilder)
+	step := NewIASDeregistrationStep(bundleBuilder)
 
 	// when
 	_, repeat, err := step.Run(operation, logger.NewLogDummy())
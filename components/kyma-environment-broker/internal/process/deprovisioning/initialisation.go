@@ -1,6 +1,7 @@
 package deprovisioning
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -8,13 +9,16 @@ import (
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/broker"
 
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/logger"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/provisioner"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession/dbmodel"
 	"github.com/kyma-project/control-plane/components/provisioner/pkg/gqlschema"
 
 	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
 
@@ -25,19 +29,29 @@ const (
 
 type InitialisationStep struct {
 	operationManager  *process.DeprovisionOperationManager
-	operationStorage  storage.Provisioning
+	operationStorage  storage.Operations
 	instanceStorage   storage.Instances
+	archiveStorage    storage.InstancesArchived
+	freeTierStorage   storage.FreeTierUsage
 	provisionerClient provisioner.Client
 	accountProvider   hyperscaler.AccountProvider
+	// runtimeRemovedStep cleans up the resources used by the cluster until the cluster is removed
+	runtimeRemovedStep Step
+	// ctx is passed to the runtimeRemovedStep
+	ctx context.Context
 }
 
-func NewInitialisationStep(os storage.Operations, is storage.Instances, pc provisioner.Client, accountProvider hyperscaler.AccountProvider) *InitialisationStep {
+func NewInitialisationStep(os storage.Operations, is storage.Instances, as storage.InstancesArchived, fs storage.FreeTierUsage, pc provisioner.Client, accountProvider hyperscaler.AccountProvider, runtimeRemovedStep Step) *InitialisationStep {
 	return &InitialisationStep{
-		operationManager:  process.NewDeprovisionOperationManager(os),
-		operationStorage:  os,
-		instanceStorage:   is,
-		provisionerClient: pc,
-		accountProvider:   accountProvider,
+		operationManager:   process.NewDeprovisionOperationManager(os),
+		operationStorage:   os,
+		instanceStorage:    is,
+		archiveStorage:     as,
+		freeTierStorage:    fs,
+		provisionerClient:  pc,
+		accountProvider:    accountProvider,
+		runtimeRemovedStep: runtimeRemovedStep,
+		ctx:                context.Background(),
 	}
 }
 
@@ -46,10 +60,23 @@ func (s *InitialisationStep) Name() string {
 }
 
 func (s *InitialisationStep) Run(operation internal.DeprovisioningOperation, log logrus.FieldLogger) (internal.DeprovisioningOperation, time.Duration, error) {
+	return s.RunWithContext(context.Background(), operation, log)
+}
+
+// RunWithContext runs the step with the Provisioner calls sent with the given context
+func (s *InitialisationStep) RunWithContext(ctx context.Context, operation internal.DeprovisioningOperation, log logrus.FieldLogger) (internal.DeprovisioningOperation, time.Duration, error) {
+	step := *s
+	step.operationManager = s.operationManager.WithContext(ctx)
+	step.provisionerClient = provisioner.WithContext(s.provisionerClient, ctx)
+	step.ctx = ctx
+	return step.runAndRemoveInstance(operation, log)
+}
+
+func (s *InitialisationStep) runAndRemoveInstance(operation internal.DeprovisioningOperation, log logrus.FieldLogger) (internal.DeprovisioningOperation, time.Duration, error) {
 	op, when, err := s.run(operation, log)
 
 	if op.State == domain.Succeeded {
-		repeat, err := s.removeInstance(operation.InstanceID)
+		repeat, err := s.removeInstance(op, log)
 		if err != nil || repeat != 0 {
 			return operation, repeat, err
 		}
@@ -116,7 +143,7 @@ func (s *InitialisationStep) checkRuntimeStatus(operation internal.Deprovisionin
 		return s.operationManager.OperationFailed(operation, fmt.Sprintf("operation has reached the time limit: %s", CheckStatusTimeout))
 	}
 
-	status, err := s.provisionerClient.RuntimeOperationStatus(instance.GlobalAccountID, operation.ProvisionerOperationID)
+	status, err := s.provisionerClient.RuntimeOperationStatus(instance.Tenant(), operation.ProvisionerOperationID)
 	if err != nil {
 		return operation, 1 * time.Minute, nil
 	}
@@ -139,12 +166,16 @@ func (s *InitialisationStep) checkRuntimeStatus(operation internal.Deprovisionin
 					return operation, 0, nil
 				}
 
-				err = s.accountProvider.MarkUnusedGardenerSecretAsDirty(hypType, instance.GlobalAccountID)
+				err = s.accountProvider.MarkUnusedGardenerSecretAsDirty(hypType, instance.Tenant())
 				if err != nil {
 					log.Errorf("after successful deprovisioning failed to release hyperscaler subscription: %s", err)
 					return operation, 10 * time.Second, nil
 				}
 			}
+			operation, repeat, err := runWithContext(s.ctx, s.runtimeRemovedStep, operation, log.WithField(logger.StepField, s.runtimeRemovedStep.Name()))
+			if err != nil || repeat != 0 {
+				return operation, repeat, err
+			}
 			return s.operationManager.OperationSucceeded(operation, msg)
 		}
 
@@ -159,11 +190,135 @@ func (s *InitialisationStep) checkRuntimeStatus(operation internal.Deprovisionin
 	return s.operationManager.OperationFailed(operation, fmt.Sprintf("unsupported provisioner client status: %s", status.State.String()))
 }
 
-func (s *InitialisationStep) removeInstance(instanceID string) (time.Duration, error) {
-	err := s.instanceStorage.Delete(instanceID)
+// removeInstance archives the instance together with its operations and finishes the free tier usage
+// of the instance before the instance is deleted
+func (s *InitialisationStep) removeInstance(operation internal.DeprovisioningOperation, log logrus.FieldLogger) (time.Duration, error) {
+	instance, err := s.instanceStorage.GetByID(operation.InstanceID)
+	switch {
+	case dberr.IsNotFound(err):
+		return 0, nil
+	case err != nil:
+		log.Errorf("unable to get instance from storage: %s", err)
+		return 10 * time.Second, nil
+	}
+
+	if err := s.archiveInstance(*instance, operation); err != nil {
+		log.Errorf("unable to archive instance: %s", err)
+		return 10 * time.Second, nil
+	}
+
+	// the usage is tracked only for the instances of the free plans, for other instances it is a no-op
+	if err := s.freeTierStorage.Finish(instance.InstanceID, time.Now()); err != nil {
+		log.Errorf("unable to finish free tier usage: %s", err)
+		return 10 * time.Second, nil
+	}
+
+	err = s.instanceStorage.Delete(operation.InstanceID)
 	if err != nil {
 		return 10 * time.Second, nil
 	}
 
 	return 0, nil
 }
+
+// archiveInstance copies the instance and its operations to the archive, the instance archived
+// by the previous run of the step, which failed to delete the instance, is not archived again
+func (s *InitialisationStep) archiveInstance(instance internal.Instance, deprovisioning internal.DeprovisioningOperation) error {
+	operations := []internal.ArchivedOperation{}
+
+	provisioning, err := s.operationStorage.GetProvisioningOperationByInstanceID(instance.InstanceID)
+	switch {
+	case err == nil:
+		operations = append(operations, internal.ArchivedOperation{Operation: provisioning.Operation, Type: string(dbmodel.OperationTypeProvision)})
+	case !dberr.IsNotFound(err):
+		return errors.Wrap(err, "while getting provisioning operation")
+	}
+
+	upgradeKymaOperations, err := s.operationStorage.ListUpgradeKymaOperationsByInstanceID(instance.InstanceID)
+	if err != nil && !dberr.IsNotFound(err) {
+		return errors.Wrap(err, "while listing upgrade kyma operations")
+	}
+	for _, op := range upgradeKymaOperations {
+		// the dry run upgrades did not change the runtime
+		if op.InstanceID == instance.InstanceID && !op.DryRun {
+			operations = append(operations, internal.ArchivedOperation{Operation: op.Operation, Type: string(dbmodel.OperationTypeUpgradeKyma)})
+		}
+	}
+
+	upgradeClusterOperations, err := s.operationStorage.ListUpgradeClusterOperationsByInstanceID(instance.InstanceID)
+	if err != nil && !dberr.IsNotFound(err) {
+		return errors.Wrap(err, "while listing upgrade cluster operations")
+	}
+	for _, op := range upgradeClusterOperations {
+		operations = append(operations, internal.ArchivedOperation{Operation: op.Operation, Type: string(dbmodel.OperationTypeUpgradeCluster)})
+	}
+
+	updating, err := s.operationStorage.GetUpdatingOperationByInstanceID(instance.InstanceID)
+	switch {
+	case err == nil:
+		operations = append(operations, internal.ArchivedOperation{Operation: updating.Operation, Type: string(dbmodel.OperationTypeUpdate)})
+	case !dberr.IsNotFound(err):
+		return errors.Wrap(err, "while getting updating operation")
+	}
+
+	// the migrated instance keeps the operation which provisioned the runtime of the source plan
+	migration, err := s.operationStorage.GetPlanMigrationOperationByInstanceID(instance.InstanceID)
+	switch {
+	case err == nil:
+		sourceOperations, err := s.sourceProvisioningOperations(*migration, provisioning)
+		if err != nil {
+			return err
+		}
+		operations = append(operations, sourceOperations...)
+		operations = append(operations, internal.ArchivedOperation{Operation: migration.Operation, Type: string(dbmodel.OperationTypeMigratePlan)})
+	case !dberr.IsNotFound(err):
+		return errors.Wrap(err, "while getting plan migration operation")
+	}
+
+	suspension, err := s.operationStorage.GetSuspensionOperationByInstanceID(instance.InstanceID)
+	switch {
+	case err == nil:
+		operations = append(operations, internal.ArchivedOperation{Operation: suspension.Operation, Type: string(dbmodel.OperationTypeSuspension)})
+	case !dberr.IsNotFound(err):
+		return errors.Wrap(err, "while getting suspension operation")
+	}
+
+	accountMigration, err := s.operationStorage.GetAccountMigrationOperationByInstanceID(instance.InstanceID)
+	switch {
+	case err == nil:
+		operations = append(operations, internal.ArchivedOperation{Operation: accountMigration.Operation, Type: string(dbmodel.OperationTypeAccountMigration)})
+	case !dberr.IsNotFound(err):
+		return errors.Wrap(err, "while getting account migration operation")
+	}
+
+	operations = append(operations, internal.ArchivedOperation{Operation: deprovisioning.Operation, Type: string(dbmodel.OperationTypeDeprovision)})
+
+	err = s.archiveStorage.Insert(internal.ArchivedInstance{
+		Instance:   instance,
+		Operations: operations,
+		ArchivedAt: time.Now(),
+	})
+	if err != nil && !dberr.IsAlreadyExists(err) {
+		return errors.Wrap(err, "while inserting archived instance")
+	}
+	return nil
+}
+
+// sourceProvisioningOperations returns the provisioning operations of the plan migration, which are not the latest
+// provisioning of the instance returned by the storage
+func (s *InitialisationStep) sourceProvisioningOperations(migration internal.PlanMigrationOperation, latest *internal.ProvisioningOperation) ([]internal.ArchivedOperation, error) {
+	var operations []internal.ArchivedOperation
+	for _, id := range []string{migration.SourceProvisioningOperationID, migration.ProvisioningOperationID, migration.RestoringOperationID} {
+		if id == "" || (latest != nil && latest.ID == id) {
+			continue
+		}
+		provisioning, err := s.operationStorage.GetProvisioningOperationByID(id)
+		switch {
+		case err == nil:
+			operations = append(operations, internal.ArchivedOperation{Operation: provisioning.Operation, Type: string(dbmodel.OperationTypeProvision)})
+		case !dberr.IsNotFound(err):
+			return nil, errors.Wrapf(err, "while getting provisioning operation %s", id)
+		}
+	}
+	return operations, nil
+}
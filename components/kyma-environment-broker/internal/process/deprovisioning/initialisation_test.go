@@ -11,9 +11,11 @@ import (
 
 	hyperscalerMocks "github.com/kyma-project/control-plane/components/kyma-environment-broker/common/hyperscaler/automock"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process/deprovisioning/automock"
 	provisionerAutomock "github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/provisioner/automock"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/ptr"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession/dbmodel"
 	"github.com/kyma-project/control-plane/components/provisioner/pkg/gqlschema"
 	"github.com/pivotal-cf/brokerapi/v7/domain"
 	"github.com/sirupsen/logrus"
@@ -58,7 +60,7 @@ func TestInitialisationStep_Run(t *testing.T) {
 			RuntimeID: nil,
 		}, nil)
 
-		step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Instances(), provisionerClient, accountProviderMock)
+		step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Instances(), memoryStorage.InstancesArchived(), memoryStorage.FreeTierUsage(), provisionerClient, accountProviderMock, NewRemoveSubscriptionSecretStep(memoryStorage.Operations(), &automock.SubscriptionSecrets{}))
 
 		// when
 		operation, repeat, err := step.Run(operation, log)
@@ -88,10 +90,16 @@ func TestInitialisationStep_Run(t *testing.T) {
 		instance.RuntimeID = ""
 		err = memoryStorage.Instances().Insert(instance)
 		assert.NoError(t, err)
+		err = memoryStorage.FreeTierUsage().Insert(internal.FreeTierUsageEntry{
+			InstanceID:      instance.InstanceID,
+			GlobalAccountID: fixGlobalAccountID,
+			StartedAt:       time.Now().Add(-time.Hour),
+		})
+		assert.NoError(t, err)
 
 		provisionerClient := &provisionerAutomock.Client{}
 
-		step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Instances(), provisionerClient, accountProviderMock)
+		step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Instances(), memoryStorage.InstancesArchived(), memoryStorage.FreeTierUsage(), provisionerClient, accountProviderMock, NewRemoveSubscriptionSecretStep(memoryStorage.Operations(), &automock.SubscriptionSecrets{}))
 
 		// when
 		operation, repeat, err := step.Run(operation, log)
@@ -104,8 +112,71 @@ func TestInitialisationStep_Run(t *testing.T) {
 		inst, err := memoryStorage.Instances().GetByID(operation.InstanceID)
 		assert.Error(t, err)
 		assert.Nil(t, inst)
+
+		archived, err := memoryStorage.InstancesArchived().GetByID(operation.InstanceID)
+		assert.NoError(t, err)
+		assert.Equal(t, fixGlobalAccountID, archived.GlobalAccountID)
+		assert.Len(t, archived.Operations, 2)
+		assert.Equal(t, string(dbmodel.OperationTypeProvision), archived.Operations[0].Type)
+		assert.Equal(t, string(dbmodel.OperationTypeDeprovision), archived.Operations[1].Type)
+
+		usage, err := memoryStorage.FreeTierUsage().ListByGlobalAccountID(fixGlobalAccountID)
+		assert.NoError(t, err)
+		assert.Len(t, usage, 1)
+		assert.False(t, usage[0].FinishedAt.IsZero())
 	})
 
+	t.Run("Should archive the operations of the plan migration", func(t *testing.T) {
+		// given
+		log := logrus.New()
+		memoryStorage := storage.NewMemoryStorage()
+
+		operation := fixDeprovisioningOperation()
+		operation.ProvisionerOperationID = ""
+		operation.State = domain.Succeeded
+		err := memoryStorage.Operations().InsertDeprovisioningOperation(operation)
+		assert.NoError(t, err)
+
+		sourceProvisioning := fixProvisioningOperation()
+		sourceProvisioning.ID = "source-provisioning-id"
+		sourceProvisioning.CreatedAt = time.Now().Add(-2 * time.Hour)
+		err = memoryStorage.Operations().InsertProvisioningOperation(sourceProvisioning)
+		assert.NoError(t, err)
+		targetProvisioning := fixProvisioningOperation()
+		targetProvisioning.ID = "target-provisioning-id"
+		targetProvisioning.CreatedAt = time.Now().Add(-time.Hour)
+		err = memoryStorage.Operations().InsertProvisioningOperation(targetProvisioning)
+		assert.NoError(t, err)
+
+		instance := fixInstanceRuntimeStatus()
+		instance.RuntimeID = ""
+		err = memoryStorage.Instances().Insert(instance)
+		assert.NoError(t, err)
+
+		migration, err := internal.NewPlanMigrationOperation(instance, internal.ProvisioningParameters{PlanID: broker.AzurePlanID})
+		assert.NoError(t, err)
+		migration.State = domain.Succeeded
+		migration.SourceProvisioningOperationID = sourceProvisioning.ID
+		migration.ProvisioningOperationID = targetProvisioning.ID
+		err = memoryStorage.Operations().InsertPlanMigrationOperation(migration)
+		assert.NoError(t, err)
+
+		step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Instances(), memoryStorage.InstancesArchived(), memoryStorage.FreeTierUsage(), &provisionerAutomock.Client{}, accountProviderMock, NewRemoveSubscriptionSecretStep(memoryStorage.Operations(), &automock.SubscriptionSecrets{}))
+
+		// when
+		_, _, err = step.Run(operation, log)
+
+		// then
+		assert.NoError(t, err)
+
+		archived, err := memoryStorage.InstancesArchived().GetByID(operation.InstanceID)
+		assert.NoError(t, err)
+		var archivedIDs []string
+		for _, op := range archived.Operations {
+			archivedIDs = append(archivedIDs, op.ID)
+		}
+		assert.ElementsMatch(t, []string{targetProvisioning.ID, sourceProvisioning.ID, migration.ID, operation.ID}, archivedIDs)
+	})
 }
 
 func fixDeprovisioningOperation() internal.DeprovisioningOperation {
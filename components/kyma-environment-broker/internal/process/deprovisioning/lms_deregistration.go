@@ -0,0 +1,84 @@
+package deprovisioning
+
+import (
+	"context"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	kebError "github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/error"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/lms"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
+
+	"github.com/sirupsen/logrus"
+)
+
+//go:generate mockery -name=LMSClient -output=automock -outpkg=automock -case=underscore
+type LMSClient interface {
+	DeleteCertificateByURL(url string) error
+}
+
+// LMSDeregistrationStep revokes the LMS certificate requested for the runtime by the provisioning.
+// The step is run by the ParallelStep, the step does not store the operation.
+type LMSDeregistrationStep struct {
+	operationsStorage storage.Operations
+	client            LMSClient
+}
+
+func NewLMSDeregistrationStep(os storage.Operations, client LMSClient) *LMSDeregistrationStep {
+	return &LMSDeregistrationStep{
+		operationsStorage: os,
+		client:            client,
+	}
+}
+
+func (s *LMSDeregistrationStep) Name() string {
+	return "LMS_Deregistration"
+}
+
+func (s *LMSDeregistrationStep) Run(operation internal.DeprovisioningOperation, log logrus.FieldLogger) (internal.DeprovisioningOperation, time.Duration, error) {
+	return s.RunWithContext(context.Background(), operation, log)
+}
+
+// RunWithContext runs the step with the LMS calls sent with the given context
+func (s *LMSDeregistrationStep) RunWithContext(ctx context.Context, operation internal.DeprovisioningOperation, log logrus.FieldLogger) (internal.DeprovisioningOperation, time.Duration, error) {
+	step := *s
+	step.client = lmsClientWithContext(s.client, ctx)
+	return step.run(operation, log)
+}
+
+func (s *LMSDeregistrationStep) run(operation internal.DeprovisioningOperation, log logrus.FieldLogger) (internal.DeprovisioningOperation, time.Duration, error) {
+	provisioning, err := s.operationsStorage.GetProvisioningOperationByInstanceID(operation.InstanceID)
+	switch {
+	case dberr.IsNotFound(err):
+		log.Info("Provisioning operation does not exist, there is no LMS certificate to delete")
+		return operation, 0, nil
+	case err != nil:
+		log.Errorf("unable to get provisioning operation: %s", err)
+		return retryWithoutFail(operation, "cannot get provisioning operation", 10*time.Second, 5*time.Minute, log)
+	}
+	if provisioning.Lms.CertificateURL == "" {
+		log.Info("LMS certificate was not requested for the runtime")
+		return operation, 0, nil
+	}
+
+	log.Infof("Delete LMS certificate %s", provisioning.Lms.CertificateURL)
+	err = s.client.DeleteCertificateByURL(provisioning.Lms.CertificateURL)
+	switch {
+	case kebError.IsTemporaryError(err):
+		log.Errorf("request to LMS failed: %s", err)
+		return retryWithoutFail(operation, "cannot delete LMS certificate", 10*time.Second, 30*time.Minute, log)
+	case err != nil:
+		log.Errorf("Step %s failed. LMS certificate has not been deleted: %s", s.Name(), err)
+		return operation, 0, nil
+	}
+
+	return operation, 0, nil
+}
+
+func lmsClientWithContext(c LMSClient, ctx context.Context) LMSClient {
+	if cli, ok := c.(lms.Client); ok {
+		return lms.WithContext(cli, ctx)
+	}
+	return c
+}
@@ -0,0 +1,92 @@
+package deprovisioning
+
+import (
+	"testing"
+	"time"
+
+	kebError "github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/error"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process/deprovisioning/automock"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const lmsCertificateURL = "https://lms.example.com/certs/5f7d8c7a"
+
+func TestLMSDeregistration_Run(t *testing.T) {
+	for name, tc := range map[string]struct {
+		certificateURL   string
+		deleteErr        error
+		updatedAgo       time.Duration
+		expectedRepeat   time.Duration
+		expectedOmitting bool
+	}{
+		"certificate deleted": {
+			certificateURL: lmsCertificateURL,
+		},
+		"certificate not requested": {},
+		"temporary error is retried": {
+			certificateURL: lmsCertificateURL,
+			deleteErr:      kebError.NewTemporaryError("service unavailable"),
+			expectedRepeat: 10 * time.Second,
+		},
+		"temporary error is omitted after the retries": {
+			certificateURL:   lmsCertificateURL,
+			deleteErr:        kebError.NewTemporaryError("service unavailable"),
+			updatedAgo:       time.Hour,
+			expectedOmitting: true,
+		},
+		"other error is omitted": {
+			certificateURL: lmsCertificateURL,
+			deleteErr:      errors.New("forbidden"),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// given
+			memoryStorage := storage.NewMemoryStorage()
+			provisioning := fixProvisioningOperation()
+			provisioning.ID = "provisioning-" + fixOperationID
+			provisioning.Lms.CertificateURL = tc.certificateURL
+			require.NoError(t, memoryStorage.Operations().InsertProvisioningOperation(provisioning))
+
+			client := &automock.LMSClient{}
+			defer client.AssertExpectations(t)
+			if tc.certificateURL != "" {
+				client.On("DeleteCertificateByURL", tc.certificateURL).Return(tc.deleteErr).Once()
+			}
+
+			operation := fixDeprovisioningOperation()
+			operation.UpdatedAt = time.Now().Add(-tc.updatedAgo)
+			step := NewLMSDeregistrationStep(memoryStorage.Operations(), client)
+
+			// when
+			operation, repeat, err := step.Run(operation, logrus.New())
+
+			// then
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedRepeat, repeat)
+			if tc.expectedOmitting {
+				assert.Contains(t, operation.Description, "cannot delete LMS certificate")
+			} else {
+				assert.Empty(t, operation.Description)
+			}
+		})
+	}
+}
+
+func TestLMSDeregistration_RunWithoutProvisioning(t *testing.T) {
+	// given
+	client := &automock.LMSClient{}
+	defer client.AssertExpectations(t)
+	step := NewLMSDeregistrationStep(storage.NewMemoryStorage().Operations(), client)
+
+	// when
+	_, repeat, err := step.Run(fixDeprovisioningOperation(), logrus.New())
+
+	// then
+	assert.NoError(t, err)
+	assert.Zero(t, repeat)
+}
@@ -2,13 +2,17 @@ package deprovisioning
 
 import (
 	"context"
+	"fmt"
 	"sort"
 	"time"
 
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/event"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/logger"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/tracing"
 	"github.com/pivotal-cf/brokerapi/v7/domain"
 	"github.com/sirupsen/logrus"
 )
@@ -18,12 +22,21 @@ type Step interface {
 	Run(operation internal.DeprovisioningOperation, logger logrus.FieldLogger) (internal.DeprovisioningOperation, time.Duration, error)
 }
 
+// StepWithContext is implemented by the steps which need the deadline of the step run, e.g. to cancel
+// the calls to the external services
+type StepWithContext interface {
+	Step
+	RunWithContext(ctx context.Context, operation internal.DeprovisioningOperation, logger logrus.FieldLogger) (internal.DeprovisioningOperation, time.Duration, error)
+}
+
 type Manager struct {
 	log              logrus.FieldLogger
 	steps            map[int][]Step
 	operationStorage storage.Operations
 
 	publisher event.Publisher
+	hooks     process.StepHooks
+	stages    *process.Stages
 }
 
 func NewManager(storage storage.Operations, pub event.Publisher, logger logrus.FieldLogger) *Manager {
@@ -32,6 +45,7 @@ func NewManager(storage storage.Operations, pub event.Publisher, logger logrus.F
 		operationStorage: storage,
 		steps:            make(map[int][]Step, 0),
 		publisher:        pub,
+		stages:           process.NewStages(process.DeprovisioningProcess),
 	}
 }
 
@@ -46,22 +60,109 @@ func (m *Manager) AddStep(weight int, step Step) {
 	m.steps[weight] = append(m.steps[weight], step)
 }
 
-func (m *Manager) runStep(step Step, operation internal.DeprovisioningOperation, logger logrus.FieldLogger) (internal.DeprovisioningOperation, time.Duration, error) {
+// DefineStage groups the steps starting at the given weight into the stage, the stage lasts until the weight
+// of the next stage, see process.Stages
+func (m *Manager) DefineStage(weight int, stage process.Stage) {
+	if weight <= 0 {
+		weight = 1
+	}
+	m.stages.Define(weight, stage)
+}
+
+// AddHook adds the hook called around every step run
+func (m *Manager) AddHook(hook process.StepHook) {
+	m.hooks = append(m.hooks, hook)
+}
+
+func (m *Manager) runStep(step Step, operation internal.DeprovisioningOperation, log logrus.FieldLogger) (internal.DeprovisioningOperation, time.Duration, error) {
+	ctx, span := tracing.StartOperationSpan(operation.TraceContext, fmt.Sprintf("deprovisioning/%s", step.Name()), operation.ID, operation.InstanceID)
+	ctx = logger.AddToContext(ctx, log)
+	ctx = httputil.ContextWithCorrelationID(ctx, operation.CorrelationID)
+	ctx = m.hooks.Before(ctx, process.StepInfo{Process: process.DeprovisioningProcess, StepName: step.Name(), Operation: operation.Operation})
 	start := time.Now()
-	processedOperation, when, err := step.Run(operation, logger)
-	m.publisher.Publish(context.TODO(), process.DeprovisioningStepProcessed{
+	processedOperation, when, err := m.runStepInStage(ctx, step, operation, log)
+	duration := time.Since(start)
+	m.hooks.After(ctx, process.StepInfo{
+		Process:   process.DeprovisioningProcess,
+		StepName:  step.Name(),
+		Operation: processedOperation.Operation,
+		Duration:  duration,
+		When:      when,
+		Error:     err,
+	})
+	tracing.End(ctx, span, err)
+	m.publisher.Publish(ctx, process.DeprovisioningStepProcessed{
 		StepProcessed: process.StepProcessed{
 			StepName: step.Name(),
-			Duration: time.Since(start),
+			Duration: duration,
 			When:     when,
 			Error:    err,
 		},
 		OldOperation: operation,
 		Operation:    processedOperation,
 	})
+	if operation.State == domain.InProgress && processedOperation.State != domain.InProgress {
+		if finished := m.stages.Finish(processedOperation.Operation, time.Now()); finished != nil {
+			m.publisher.Publish(ctx, *finished)
+		}
+	}
+	return processedOperation, when, err
+}
+
+// runStepInStage fails the operation which exceeded the timeout of its stage instead of running the step
+func (m *Manager) runStepInStage(ctx context.Context, step Step, operation internal.DeprovisioningOperation, log logrus.FieldLogger) (internal.DeprovisioningOperation, time.Duration, error) {
+	if stage, exceeded := m.stages.Exceeded(operation.Operation, time.Now()); exceeded {
+		log.Errorf("Operation exceeded the timeout %s of the stage %s", stage.Timeout, stage.Name)
+		return process.NewDeprovisionOperationManager(m.operationStorage).OperationFailed(operation, fmt.Sprintf("operation exceeded the timeout %s of the %s stage", stage.Timeout, stage.Name))
+	}
+	return m.runStepWithDeadline(ctx, step, operation, log)
+}
+
+// enterStage moves the operation to the stage of the step with the given weight. The stage is saved with
+// the operation, so the time of the stage is measured across all executions of the operation.
+func (m *Manager) enterStage(operation internal.DeprovisioningOperation, weight int, log logrus.FieldLogger) (internal.DeprovisioningOperation, time.Duration) {
+	moved, finished, entered := m.stages.Enter(operation.Operation, weight, time.Now())
+	if !entered {
+		return operation, 0
+	}
+	withStage := operation
+	withStage.Operation = moved
+	updated, when, err := process.NewDeprovisionOperationManager(m.operationStorage).UpdateOperation(withStage)
+	if err != nil || when != 0 {
+		log.Errorf("Cannot save the stage %s of the operation", moved.Stage)
+		return operation, 1 * time.Minute
+	}
+	log.Infof("Operation entered the stage %s", moved.Stage)
+	if finished != nil {
+		m.publisher.Publish(logger.AddToContext(context.Background(), log), *finished)
+	}
+	return updated, 0
+}
+
+// runStepWithDeadline runs the step with process.RunStep, the operation is repeated when the step exceeds its timeout
+func (m *Manager) runStepWithDeadline(ctx context.Context, step Step, operation internal.DeprovisioningOperation, log logrus.FieldLogger) (internal.DeprovisioningOperation, time.Duration, error) {
+	var (
+		processedOperation internal.DeprovisioningOperation
+		when               time.Duration
+		err                error
+	)
+	deadlineErr := process.RunStep(ctx, step, func(ctx context.Context) {
+		processedOperation, when, err = runWithContext(ctx, step, operation, log)
+	})
+	if deadlineErr != nil {
+		return operation, process.StepTimeoutRetryInterval, nil
+	}
 	return processedOperation, when, err
 }
 
+// runWithContext passes the context to the step which implements StepWithContext
+func runWithContext(ctx context.Context, step Step, operation internal.DeprovisioningOperation, log logrus.FieldLogger) (internal.DeprovisioningOperation, time.Duration, error) {
+	if s, ok := step.(StepWithContext); ok {
+		return s.RunWithContext(ctx, operation, log)
+	}
+	return step.Run(operation, log)
+}
+
 func (m *Manager) Execute(operationID string) (time.Duration, error) {
 	op, err := m.operationStorage.GetDeprovisioningOperationByID(operationID)
 	if err != nil {
@@ -69,6 +170,10 @@ func (m *Manager) Execute(operationID string) (time.Duration, error) {
 		return 3 * time.Second, nil
 	}
 	operation := *op
+	// the operation could be failed in the meantime, e.g. abandoned with the operations batch
+	if operation.IsFinished() {
+		return 0, nil
+	}
 
 	provisioningOp, err := m.operationStorage.GetProvisioningOperationByInstanceID(op.InstanceID)
 	if err != nil {
@@ -83,16 +188,21 @@ func (m *Manager) Execute(operationID string) (time.Duration, error) {
 		}
 	}
 
-	logOperation := m.log.WithFields(logrus.Fields{"operation": operationID, "instanceID": operation.InstanceID, "planID": pp.PlanID})
+	logOperation := logger.WithCorrelationID(logger.WithOperation(m.log, operationID, operation.InstanceID), operation.CorrelationID).WithField("planID", pp.PlanID)
 
 	var when time.Duration
 	logOperation.Info("Start process operation steps")
 	for _, weightStep := range m.sortWeight() {
 		steps := m.steps[weightStep]
 		for _, step := range steps {
-			logStep := logOperation.WithField("step", step.Name())
+			logStep := logOperation.WithField(logger.StepField, step.Name())
 			logStep.Infof("Start step")
 
+			operation, when = m.enterStage(operation, weightStep, logStep)
+			if when != 0 {
+				logStep.Infof("Process operation will be repeated in %s ...", when)
+				return when, nil
+			}
 			operation, when, err = m.runStep(step, operation, logStep)
 			if err != nil {
 				logStep.Errorf("Process operation failed: %s", err)
@@ -0,0 +1,201 @@
+package deprovisioning
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/logger"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+)
+
+// ParallelStep runs independent cleanup steps (AVS, EDP, IAS, LMS, Event Hubs) concurrently,
+// so the deprovisioning does not wait through retries of every integration one after another.
+// Every step gets its own copy of the operation. The completion of every step is stored in the operation
+// as soon as the step finished, so the finished steps are skipped when the other steps are retried.
+// The lifecycle data of the results is merged and stored once all of the steps finished. The steps which store
+// the operation on their own must merge their changes into its latest version, see storage.UpdateWithRetryDeprovisioningOperation,
+// the concurrent updates would conflict on the operation version otherwise, see retryWithoutFail.
+type ParallelStep struct {
+	steps             []Step
+	operationsStorage storage.Operations
+}
+
+var _ Step = &ParallelStep{}
+
+func NewParallelStep(os storage.Operations, steps ...Step) *ParallelStep {
+	return &ParallelStep{
+		steps:             steps,
+		operationsStorage: os,
+	}
+}
+
+func (s *ParallelStep) Name() string {
+	names := make([]string, 0, len(s.steps))
+	for _, step := range s.steps {
+		names = append(names, step.Name())
+	}
+	return fmt.Sprintf("Parallel(%s)", strings.Join(names, ","))
+}
+
+type stepResult struct {
+	operation internal.DeprovisioningOperation
+	when      time.Duration
+	err       error
+}
+
+// Timeout returns the longest timeout of the steps run in parallel
+func (s *ParallelStep) Timeout() time.Duration {
+	timeout := process.DefaultStepTimeout
+	for _, step := range s.steps {
+		if t := process.StepTimeout(step); t > timeout {
+			timeout = t
+		}
+	}
+	return timeout
+}
+
+func (s *ParallelStep) Run(operation internal.DeprovisioningOperation, log logrus.FieldLogger) (internal.DeprovisioningOperation, time.Duration, error) {
+	return s.RunWithContext(context.Background(), operation, log)
+}
+
+// RunWithContext passes the context to the steps which need it, see StepWithContext
+func (s *ParallelStep) RunWithContext(ctx context.Context, operation internal.DeprovisioningOperation, log logrus.FieldLogger) (internal.DeprovisioningOperation, time.Duration, error) {
+	results := make([]stepResult, len(s.steps))
+
+	var wg sync.WaitGroup
+	for i, step := range s.steps {
+		if stepFinished(operation, step.Name()) {
+			log.Infof("Step %s already finished, skipping", step.Name())
+			results[i] = stepResult{operation: operation}
+			continue
+		}
+		wg.Add(1)
+		go func(i int, step Step) {
+			defer wg.Done()
+			var result stepResult
+			stepLog := log.WithField(logger.StepField, step.Name())
+			result.operation, result.when, result.err = runWithContext(ctx, step, operation, stepLog)
+			if result.err == nil && result.when == 0 {
+				result.operation.FinishedSteps = append(append([]string{}, result.operation.FinishedSteps...), step.Name())
+				// the completion is stored right away, the step is not run again even if the other steps never finish
+				s.store(result.operation, stepLog)
+			}
+			results[i] = result
+		}(i, step)
+	}
+	wg.Wait()
+
+	var (
+		when   time.Duration
+		errMsg []string
+	)
+	merged := operation
+	for i, result := range results {
+		if result.err != nil {
+			errMsg = append(errMsg, fmt.Sprintf("%s: %s", s.steps[i].Name(), result.err))
+		}
+		if result.when > 0 && (when == 0 || result.when < when) {
+			when = result.when
+		}
+		mergeLifecycleData(&merged, result.operation)
+		// the descriptions appended by the steps to the original description are all kept
+		if strings.HasPrefix(result.operation.Description, operation.Description) {
+			merged.Description += strings.TrimPrefix(result.operation.Description, operation.Description)
+		}
+	}
+
+	updated := merged
+	// the operation is not stored without changes, the update time measures the retries of the steps
+	if changed(operation, merged) {
+		var repeat time.Duration
+		updated, repeat = s.store(merged, log)
+		if repeat != 0 {
+			return updated, repeat, nil
+		}
+	}
+	if len(errMsg) > 0 {
+		return updated, 0, errors.New(strings.Join(errMsg, "; "))
+	}
+
+	return updated, when, nil
+}
+
+func changed(operation, merged internal.DeprovisioningOperation) bool {
+	return operation.Avs != merged.Avs ||
+		operation.EventHub != merged.EventHub ||
+		operation.State != merged.State ||
+		operation.Description != merged.Description ||
+		len(operation.FinishedSteps) != len(merged.FinishedSteps)
+}
+
+func stepFinished(operation internal.DeprovisioningOperation, name string) bool {
+	for _, finished := range operation.FinishedSteps {
+		if finished == name {
+			return true
+		}
+	}
+	return false
+}
+
+// store saves the merged lifecycle data on top of the latest version of the operation, the version could be
+// increased in the meantime e.g. by the operations batch
+func (s *ParallelStep) store(merged internal.DeprovisioningOperation, log logrus.FieldLogger) (internal.DeprovisioningOperation, time.Duration) {
+	updated, err := storage.UpdateWithRetryDeprovisioningOperation(s.operationsStorage, merged.ID, func(latest *internal.DeprovisioningOperation) {
+		mergeLifecycleData(latest, merged)
+		if len(merged.Description) > len(latest.Description) && strings.HasPrefix(merged.Description, latest.Description) {
+			latest.Description = merged.Description
+		}
+	})
+	if err != nil {
+		log.Errorf("unable to update deprovisioning operation: %s", err)
+		return merged, time.Minute
+	}
+	return *updated, 0
+}
+
+// mergeLifecycleData copies cleanup progress of the source operation to the destination
+// operation, the progress is never reverted. The failure of the source operation is copied as well.
+func mergeLifecycleData(dst *internal.DeprovisioningOperation, src internal.DeprovisioningOperation) {
+	dst.Avs.AVSInternalEvaluationDeleted = dst.Avs.AVSInternalEvaluationDeleted || src.Avs.AVSInternalEvaluationDeleted
+	dst.Avs.AVSExternalEvaluationDeleted = dst.Avs.AVSExternalEvaluationDeleted || src.Avs.AVSExternalEvaluationDeleted
+	dst.EventHub.Deleted = dst.EventHub.Deleted || src.EventHub.Deleted
+	for _, name := range src.FinishedSteps {
+		if !stepFinished(*dst, name) {
+			dst.FinishedSteps = append(dst.FinishedSteps[:len(dst.FinishedSteps):len(dst.FinishedSteps)], name)
+		}
+	}
+	if src.State == domain.Failed {
+		dst.State = domain.Failed
+	}
+}
+
+// retryWithoutFail retries the step run by the ParallelStep for at most maxTime in retryInterval steps and omits
+// the step if retrying failed. It works as process.DeprovisionOperationManager RetryOperationWithoutFail, but the
+// description of the omitted step is only added to the returned operation, which is stored by the ParallelStep.
+func retryWithoutFail(operation internal.DeprovisioningOperation, description string, retryInterval, maxTime time.Duration, log logrus.FieldLogger) (internal.DeprovisioningOperation, time.Duration, error) {
+	log.Infof("Retry Operation was triggered with message: %s", description)
+	log.Infof("Retrying for %s in %s steps", maxTime.String(), retryInterval.String())
+	if time.Since(operation.UpdatedAt) < maxTime {
+		return operation, retryInterval, nil
+	}
+
+	log.Errorf("Omitting after %s of failing retries", maxTime.String())
+	operation.Description = fmt.Sprintf("%s : %s", operation.Description, description)
+	return operation, 0, nil
+}
+
+// failWithoutStore marks the operation run by the ParallelStep as failed, the operation is stored by the ParallelStep
+func failWithoutStore(operation internal.DeprovisioningOperation, description string) (internal.DeprovisioningOperation, time.Duration, error) {
+	operation.State = domain.Failed
+	operation.Description = fmt.Sprintf("%s : %s", operation.Description, description)
+	return operation, 0, errors.New(description)
+}
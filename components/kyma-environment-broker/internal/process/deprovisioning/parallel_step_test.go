@@ -0,0 +1,278 @@
+package deprovisioning
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+)
+
+func TestParallelStep_Run(t *testing.T) {
+	for name, tc := range map[string]struct {
+		steps          []Step
+		expectedRepeat time.Duration
+		expectedError  bool
+	}{
+		"all steps finished": {
+			steps: []Step{
+				&funcStep{name: "avs", run: func(op internal.DeprovisioningOperation) (internal.DeprovisioningOperation, time.Duration, error) {
+					op.Avs.AVSInternalEvaluationDeleted = true
+					op.Avs.AVSExternalEvaluationDeleted = true
+					return op, 0, nil
+				}},
+				&funcStep{name: "eventHub", run: func(op internal.DeprovisioningOperation) (internal.DeprovisioningOperation, time.Duration, error) {
+					op.EventHub.Deleted = true
+					return op, 0, nil
+				}},
+			},
+			expectedRepeat: 0,
+		},
+		"the shortest retry is used": {
+			steps: []Step{
+				&funcStep{name: "avs", run: func(op internal.DeprovisioningOperation) (internal.DeprovisioningOperation, time.Duration, error) {
+					op.Avs.AVSInternalEvaluationDeleted = true
+					op.Avs.AVSExternalEvaluationDeleted = true
+					return op, time.Minute, nil
+				}},
+				&funcStep{name: "eventHub", run: func(op internal.DeprovisioningOperation) (internal.DeprovisioningOperation, time.Duration, error) {
+					op.EventHub.Deleted = true
+					return op, 10 * time.Second, nil
+				}},
+				&funcStep{name: "edp", run: func(op internal.DeprovisioningOperation) (internal.DeprovisioningOperation, time.Duration, error) {
+					return op, 0, nil
+				}},
+			},
+			expectedRepeat: 10 * time.Second,
+		},
+		"step failed": {
+			steps: []Step{
+				&funcStep{name: "avs", run: func(op internal.DeprovisioningOperation) (internal.DeprovisioningOperation, time.Duration, error) {
+					op.Avs.AVSInternalEvaluationDeleted = true
+					op.Avs.AVSExternalEvaluationDeleted = true
+					return op, 0, nil
+				}},
+				&funcStep{name: "eventHub", run: func(op internal.DeprovisioningOperation) (internal.DeprovisioningOperation, time.Duration, error) {
+					return op, 0, errors.New("some error")
+				}},
+			},
+			expectedError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// given
+			memoryStorage := storage.NewMemoryStorage()
+			operation := fixDeprovisioningOperation()
+			err := memoryStorage.Operations().InsertDeprovisioningOperation(operation)
+			require.NoError(t, err)
+
+			step := NewParallelStep(memoryStorage.Operations(), tc.steps...)
+
+			// when
+			operation, repeat, err := step.Run(operation, logrus.New())
+
+			// then
+			if tc.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedRepeat, repeat)
+			assert.True(t, operation.Avs.AVSInternalEvaluationDeleted)
+			assert.True(t, operation.Avs.AVSExternalEvaluationDeleted)
+
+			inDB, err := memoryStorage.Operations().GetDeprovisioningOperationByID(operation.ID)
+			require.NoError(t, err)
+			assert.True(t, inDB.Avs.AVSInternalEvaluationDeleted)
+			assert.True(t, inDB.Avs.AVSExternalEvaluationDeleted)
+			assert.Equal(t, operation.Version, inDB.Version)
+		})
+	}
+}
+
+func TestParallelStep_RunWithOperationUpdatedInTheMeantime(t *testing.T) {
+	// given
+	memoryStorage := storage.NewMemoryStorage()
+	operation := fixDeprovisioningOperation()
+	err := memoryStorage.Operations().InsertDeprovisioningOperation(operation)
+	require.NoError(t, err)
+
+	avsStep := &funcStep{name: "avs", run: func(op internal.DeprovisioningOperation) (internal.DeprovisioningOperation, time.Duration, error) {
+		// the operation is updated by another actor, e.g. the operations batch, while the steps are running
+		inDB, err := memoryStorage.Operations().GetDeprovisioningOperationByID(op.ID)
+		require.NoError(t, err)
+		_, err = memoryStorage.Operations().UpdateDeprovisioningOperation(*inDB)
+		require.NoError(t, err)
+
+		op.Avs.AVSInternalEvaluationDeleted = true
+		return op, 0, nil
+	}}
+	eventHubStep := &funcStep{name: "eventHub", run: func(op internal.DeprovisioningOperation) (internal.DeprovisioningOperation, time.Duration, error) {
+		op.EventHub.Deleted = true
+		return op, 0, nil
+	}}
+	step := NewParallelStep(memoryStorage.Operations(), avsStep, eventHubStep)
+
+	// when
+	_, repeat, err := step.Run(operation, logrus.New())
+
+	// then
+	assert.NoError(t, err)
+	assert.Zero(t, repeat)
+
+	inDB, err := memoryStorage.Operations().GetDeprovisioningOperationByID(operation.ID)
+	require.NoError(t, err)
+	assert.True(t, inDB.Avs.AVSInternalEvaluationDeleted)
+	assert.True(t, inDB.EventHub.Deleted)
+}
+
+func TestParallelStep_RunKeepsDescriptionsOfAllSteps(t *testing.T) {
+	// given
+	memoryStorage := storage.NewMemoryStorage()
+	operation := fixDeprovisioningOperation()
+	operation.Description = "deprovisioning"
+	operation.UpdatedAt = time.Now().Add(-time.Hour)
+	err := memoryStorage.Operations().InsertDeprovisioningOperation(operation)
+	require.NoError(t, err)
+
+	iasStep := &funcStep{name: "ias", run: func(op internal.DeprovisioningOperation) (internal.DeprovisioningOperation, time.Duration, error) {
+		return retryWithoutFail(op, "cannot delete IAS service provider", time.Second, time.Minute, logrus.New())
+	}}
+	lmsStep := &funcStep{name: "lms", run: func(op internal.DeprovisioningOperation) (internal.DeprovisioningOperation, time.Duration, error) {
+		return retryWithoutFail(op, "cannot delete LMS certificate", time.Second, time.Minute, logrus.New())
+	}}
+	step := NewParallelStep(memoryStorage.Operations(), iasStep, lmsStep)
+
+	// when
+	operation, repeat, err := step.Run(operation, logrus.New())
+
+	// then
+	assert.NoError(t, err)
+	assert.Zero(t, repeat)
+	assert.Equal(t, "deprovisioning : cannot delete IAS service provider : cannot delete LMS certificate", operation.Description)
+
+	inDB, err := memoryStorage.Operations().GetDeprovisioningOperationByID(operation.ID)
+	require.NoError(t, err)
+	assert.Equal(t, operation.Description, inDB.Description)
+}
+
+func TestParallelStep_RunWithFailedStep(t *testing.T) {
+	// given
+	memoryStorage := storage.NewMemoryStorage()
+	operation := fixDeprovisioningOperation()
+	err := memoryStorage.Operations().InsertDeprovisioningOperation(operation)
+	require.NoError(t, err)
+
+	failingStep := &funcStep{name: "eventHub", run: func(op internal.DeprovisioningOperation) (internal.DeprovisioningOperation, time.Duration, error) {
+		return failWithoutStore(op, "invalid provisioning parameters")
+	}}
+	avsStep := &funcStep{name: "avs", run: func(op internal.DeprovisioningOperation) (internal.DeprovisioningOperation, time.Duration, error) {
+		op.Avs.AVSInternalEvaluationDeleted = true
+		return op, 0, nil
+	}}
+	step := NewParallelStep(memoryStorage.Operations(), failingStep, avsStep)
+
+	// when
+	_, _, err = step.Run(operation, logrus.New())
+
+	// then
+	assert.Error(t, err)
+
+	inDB, err := memoryStorage.Operations().GetDeprovisioningOperationByID(operation.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.Failed, inDB.State)
+	assert.True(t, inDB.Avs.AVSInternalEvaluationDeleted)
+}
+
+func TestParallelStep_RunSkipsFinishedSteps(t *testing.T) {
+	// given
+	memoryStorage := storage.NewMemoryStorage()
+	operation := fixDeprovisioningOperation()
+	err := memoryStorage.Operations().InsertDeprovisioningOperation(operation)
+	require.NoError(t, err)
+
+	var edpRuns, lmsRuns int
+	edpStep := &funcStep{name: "edp", run: func(op internal.DeprovisioningOperation) (internal.DeprovisioningOperation, time.Duration, error) {
+		edpRuns++
+		return op, 0, nil
+	}}
+	lmsStep := &funcStep{name: "lms", run: func(op internal.DeprovisioningOperation) (internal.DeprovisioningOperation, time.Duration, error) {
+		lmsRuns++
+		if lmsRuns < 2 {
+			return op, 10 * time.Second, nil
+		}
+		return op, 0, nil
+	}}
+	step := NewParallelStep(memoryStorage.Operations(), edpStep, lmsStep)
+
+	// when
+	operation, repeat, err := step.Run(operation, logrus.New())
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, 10*time.Second, repeat)
+	assert.Equal(t, []string{"edp"}, operation.FinishedSteps)
+	inDB, err := memoryStorage.Operations().GetDeprovisioningOperationByID(operation.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"edp"}, inDB.FinishedSteps)
+
+	// when
+	operation, repeat, err = step.Run(operation, logrus.New())
+
+	// then
+	require.NoError(t, err)
+	assert.Zero(t, repeat)
+	assert.Equal(t, 1, edpRuns)
+	assert.Equal(t, 2, lmsRuns)
+	assert.ElementsMatch(t, []string{"edp", "lms"}, operation.FinishedSteps)
+	inDB, err = memoryStorage.Operations().GetDeprovisioningOperationByID(operation.ID)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"edp", "lms"}, inDB.FinishedSteps)
+}
+
+func TestParallelStep_RunWithoutChanges(t *testing.T) {
+	// given
+	memoryStorage := storage.NewMemoryStorage()
+	operation := fixDeprovisioningOperation()
+	err := memoryStorage.Operations().InsertDeprovisioningOperation(operation)
+	require.NoError(t, err)
+	inserted, err := memoryStorage.Operations().GetDeprovisioningOperationByID(operation.ID)
+	require.NoError(t, err)
+
+	retryingStep := &funcStep{name: "lms", run: func(op internal.DeprovisioningOperation) (internal.DeprovisioningOperation, time.Duration, error) {
+		return op, 10 * time.Second, nil
+	}}
+	step := NewParallelStep(memoryStorage.Operations(), retryingStep)
+
+	// when
+	_, repeat, err := step.Run(operation, logrus.New())
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, 10*time.Second, repeat)
+
+	// the update time is not changed, it measures the retries of the steps
+	inDB, err := memoryStorage.Operations().GetDeprovisioningOperationByID(operation.ID)
+	require.NoError(t, err)
+	assert.Equal(t, inserted.UpdatedAt, inDB.UpdatedAt)
+	assert.Equal(t, inserted.Version, inDB.Version)
+}
+
+type funcStep struct {
+	name string
+	run  func(op internal.DeprovisioningOperation) (internal.DeprovisioningOperation, time.Duration, error)
+}
+
+func (s *funcStep) Name() string {
+	return s.name
+}
+
+func (s *funcStep) Run(operation internal.DeprovisioningOperation, _ logrus.FieldLogger) (internal.DeprovisioningOperation, time.Duration, error) {
+	return s.run(operation)
+}
@@ -1,6 +1,7 @@
 package deprovisioning
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -37,6 +38,18 @@ func (s *RemoveRuntimeStep) Name() string {
 }
 
 func (s *RemoveRuntimeStep) Run(operation internal.DeprovisioningOperation, log logrus.FieldLogger) (internal.DeprovisioningOperation, time.Duration, error) {
+	return s.RunWithContext(context.Background(), operation, log)
+}
+
+// RunWithContext runs the step with the Provisioner calls sent with the given context
+func (s *RemoveRuntimeStep) RunWithContext(ctx context.Context, operation internal.DeprovisioningOperation, log logrus.FieldLogger) (internal.DeprovisioningOperation, time.Duration, error) {
+	step := *s
+	step.operationManager = s.operationManager.WithContext(ctx)
+	step.provisionerClient = provisioner.WithContext(s.provisionerClient, ctx)
+	return step.run(operation, log)
+}
+
+func (s *RemoveRuntimeStep) run(operation internal.DeprovisioningOperation, log logrus.FieldLogger) (internal.DeprovisioningOperation, time.Duration, error) {
 	if time.Since(operation.UpdatedAt) > RemoveRuntimeTimeout {
 		log.Infof("operation has reached the time limit: updated operation time: %s", operation.UpdatedAt)
 		return s.operationManager.OperationFailed(operation, fmt.Sprintf("operation has reached the time limit: %s", RemoveRuntimeTimeout))
@@ -65,7 +78,7 @@ func (s *RemoveRuntimeStep) Run(operation internal.DeprovisioningOperation, log
 	var provisionerResponse string
 	if operation.ProvisionerOperationID == "" {
 
-		provisionerResponse, err = s.provisionerClient.DeprovisionRuntime(instance.GlobalAccountID, instance.RuntimeID)
+		provisionerResponse, err = s.provisionerClient.DeprovisionRuntime(instance.Tenant(), instance.RuntimeID)
 		if err != nil {
 			log.Errorf("unable to deprovision runtime: %s", err)
 			return operation, 10 * time.Second, nil
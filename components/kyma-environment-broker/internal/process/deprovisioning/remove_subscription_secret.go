@@ -0,0 +1,73 @@
+package deprovisioning
+
+import (
+	"context"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/broker"
+	kebError "github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/error"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+
+	"github.com/sirupsen/logrus"
+)
+
+//go:generate mockery -name=SubscriptionSecrets -output=automock -outpkg=automock -case=underscore
+type SubscriptionSecrets interface {
+	Delete(tenantName, secretName string) error
+}
+
+// RemoveSubscriptionSecretStep removes the secret and the secret binding which KEB created from the credentials
+// of the customer-provided subscription (BYO subscription). The secret created by the customer is kept.
+// The cluster uses the secret until it is removed, so the step is run by the InitialisationStep once the runtime is removed.
+type RemoveSubscriptionSecretStep struct {
+	operationManager *process.DeprovisionOperationManager
+	subscriptions    SubscriptionSecrets
+}
+
+func NewRemoveSubscriptionSecretStep(os storage.Operations, subscriptions SubscriptionSecrets) *RemoveSubscriptionSecretStep {
+	return &RemoveSubscriptionSecretStep{
+		operationManager: process.NewDeprovisionOperationManager(os),
+		subscriptions:    subscriptions,
+	}
+}
+
+func (s *RemoveSubscriptionSecretStep) Name() string {
+	return "Remove_Subscription_Secret"
+}
+
+func (s *RemoveSubscriptionSecretStep) Run(operation internal.DeprovisioningOperation, log logrus.FieldLogger) (internal.DeprovisioningOperation, time.Duration, error) {
+	return s.RunWithContext(context.Background(), operation, log)
+}
+
+// RunWithContext runs the step with the storage calls recorded in the trace of the given context
+func (s *RemoveSubscriptionSecretStep) RunWithContext(ctx context.Context, operation internal.DeprovisioningOperation, log logrus.FieldLogger) (internal.DeprovisioningOperation, time.Duration, error) {
+	step := *s
+	step.operationManager = s.operationManager.WithContext(ctx)
+	return step.run(operation, log)
+}
+
+func (s *RemoveSubscriptionSecretStep) run(operation internal.DeprovisioningOperation, log logrus.FieldLogger) (internal.DeprovisioningOperation, time.Duration, error) {
+	pp, err := operation.GetProvisioningParameters()
+	if err != nil {
+		log.Errorf("cannot fetch provisioning parameters from operation: %s", err)
+		return operation, 0, nil
+	}
+	secretName := broker.SubscriptionSecretName(operation.InstanceID)
+	if pp.Parameters.Subscription == nil || pp.Parameters.Subscription.SecretName != secretName {
+		return operation, 0, nil
+	}
+
+	log.Infof("Delete subscription secret %s", secretName)
+	err = s.subscriptions.Delete(pp.ErsContext.GlobalAccountID, secretName)
+	switch {
+	case kebError.IsTemporaryError(err):
+		log.Errorf("unable to delete subscription secret: %s", err)
+		return s.operationManager.RetryOperationWithoutFail(operation, "cannot delete subscription secret", 10*time.Second, 10*time.Minute, log)
+	case err != nil:
+		log.Errorf("Step %s failed. Subscription secret has not been deleted: %s", s.Name(), err)
+	}
+
+	return operation, 0, nil
+}
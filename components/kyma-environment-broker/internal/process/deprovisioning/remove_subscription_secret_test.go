@@ -0,0 +1,90 @@
+package deprovisioning
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/broker"
+	kebError "github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/error"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process/deprovisioning/automock"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoveSubscriptionSecretStep_Run(t *testing.T) {
+	for name, tc := range map[string]struct {
+		subscription     *internal.SubscriptionDTO
+		deleteErr        error
+		updatedAgo       time.Duration
+		expectedDelete   bool
+		expectedRepeat   time.Duration
+		expectedOmitting bool
+	}{
+		"secret created from the credentials is deleted": {
+			subscription:   &internal.SubscriptionDTO{SecretName: broker.SubscriptionSecretName(fixInstanceID)},
+			expectedDelete: true,
+		},
+		"secret created by the customer is kept": {
+			subscription: &internal.SubscriptionDTO{SecretName: "customer-secret"},
+		},
+		"instance without subscription": {},
+		"temporary error is retried": {
+			subscription:   &internal.SubscriptionDTO{SecretName: broker.SubscriptionSecretName(fixInstanceID)},
+			deleteErr:      kebError.NewTemporaryError("service unavailable"),
+			expectedDelete: true,
+			expectedRepeat: 10 * time.Second,
+		},
+		"temporary error is omitted after the retries": {
+			subscription:     &internal.SubscriptionDTO{SecretName: broker.SubscriptionSecretName(fixInstanceID)},
+			deleteErr:        kebError.NewTemporaryError("service unavailable"),
+			updatedAgo:       time.Hour,
+			expectedDelete:   true,
+			expectedOmitting: true,
+		},
+		"other error is omitted": {
+			subscription:   &internal.SubscriptionDTO{SecretName: broker.SubscriptionSecretName(fixInstanceID)},
+			deleteErr:      errors.New("forbidden"),
+			expectedDelete: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// given
+			memoryStorage := storage.NewMemoryStorage()
+
+			operation := fixDeprovisioningOperation()
+			operation.UpdatedAt = time.Now().Add(-tc.updatedAgo)
+			err := operation.SetProvisioningParameters(internal.ProvisioningParameters{
+				PlanID:     broker.AzurePlanID,
+				ErsContext: internal.ERSContext{GlobalAccountID: fixGlobalAccountID},
+				Parameters: internal.ProvisioningParametersDTO{Subscription: tc.subscription},
+			})
+			require.NoError(t, err)
+			require.NoError(t, memoryStorage.Operations().InsertDeprovisioningOperation(operation))
+
+			subscriptions := &automock.SubscriptionSecrets{}
+			defer subscriptions.AssertExpectations(t)
+			if tc.expectedDelete {
+				subscriptions.On("Delete", fixGlobalAccountID, broker.SubscriptionSecretName(fixInstanceID)).Return(tc.deleteErr).Once()
+			}
+
+			step := NewRemoveSubscriptionSecretStep(memoryStorage.Operations(), subscriptions)
+
+			// when
+			operation, repeat, err := step.Run(operation, logrus.New())
+
+			// then
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedRepeat, repeat)
+			if tc.expectedOmitting {
+				assert.Contains(t, operation.Description, "cannot delete subscription secret")
+			} else {
+				assert.Empty(t, operation.Description)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package deprovisioning
 
 import (
+	"context"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -8,20 +9,18 @@ import (
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/broker"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process"
-	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
 )
 
+// SkipForTrialPlanStep wraps the step run by the ParallelStep, the failed operation is stored by the ParallelStep
 type SkipForTrialPlanStep struct {
-	step             Step
-	OperationManager *process.DeprovisionOperationManager
+	step Step
 }
 
 var _ Step = &SkipForTrialPlanStep{}
 
-func NewSkipForTrialPlanStep(os storage.Operations, step Step) SkipForTrialPlanStep {
+func NewSkipForTrialPlanStep(step Step) SkipForTrialPlanStep {
 	return SkipForTrialPlanStep{
-		step:             step,
-		OperationManager: process.NewDeprovisionOperationManager(os),
+		step: step,
 	}
 }
 
@@ -29,11 +28,21 @@ func (s SkipForTrialPlanStep) Name() string {
 	return s.step.Name()
 }
 
+// Timeout returns the timeout of the wrapped step
+func (s SkipForTrialPlanStep) Timeout() time.Duration {
+	return process.StepTimeout(s.step)
+}
+
 func (s SkipForTrialPlanStep) Run(operation internal.DeprovisioningOperation, log logrus.FieldLogger) (internal.DeprovisioningOperation, time.Duration, error) {
+	return s.RunWithContext(context.Background(), operation, log)
+}
+
+// RunWithContext passes the context to the wrapped step
+func (s SkipForTrialPlanStep) RunWithContext(ctx context.Context, operation internal.DeprovisioningOperation, log logrus.FieldLogger) (internal.DeprovisioningOperation, time.Duration, error) {
 	pp, err := operation.GetProvisioningParameters()
 	if err != nil {
 		log.Errorf("cannot fetch provisioning parameters from operation: %s", err)
-		return s.OperationManager.OperationFailed(operation, "invalid operation provisioning parameters")
+		return failWithoutStore(operation, "invalid operation provisioning parameters")
 	}
 
 	if broker.IsTrialPlan(pp.PlanID) {
@@ -41,5 +50,5 @@ func (s SkipForTrialPlanStep) Run(operation internal.DeprovisioningOperation, lo
 		return operation, 0, nil
 	}
 
-	return s.step.Run(operation, log)
+	return runWithContext(ctx, s.step, operation, log)
 }
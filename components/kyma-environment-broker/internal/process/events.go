@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+
+	"github.com/pivotal-cf/brokerapi/v7/domain"
 )
 
 type StepProcessed struct {
@@ -30,3 +32,57 @@ type UpgradeKymaStepProcessed struct {
 	OldOperation internal.UpgradeKymaOperation
 	Operation    internal.UpgradeKymaOperation
 }
+
+type UpgradeClusterStepProcessed struct {
+	StepProcessed
+	OldOperation internal.UpgradeClusterOperation
+	Operation    internal.UpgradeClusterOperation
+}
+
+type PlanMigrationStepProcessed struct {
+	StepProcessed
+	OldOperation internal.PlanMigrationOperation
+	Operation    internal.PlanMigrationOperation
+}
+
+type UpdatingStepProcessed struct {
+	StepProcessed
+	OldOperation internal.UpdatingOperation
+	Operation    internal.UpdatingOperation
+}
+
+type SuspensionStepProcessed struct {
+	StepProcessed
+	OldOperation internal.SuspensionOperation
+	Operation    internal.SuspensionOperation
+}
+
+type AccountMigrationStepProcessed struct {
+	StepProcessed
+	OldOperation internal.AccountMigrationOperation
+	Operation    internal.AccountMigrationOperation
+}
+
+// StageFinished is published when the operation leaves the stage of the process, either by entering
+// the next stage or by finishing
+type StageFinished struct {
+	Process   string
+	Stage     Stage
+	Operation internal.Operation
+	Duration  time.Duration
+	Result    string
+}
+
+// Compliant tells whether the stage is done within its SLO
+func (e StageFinished) Compliant() bool {
+	return e.Result == StageResultDone && (e.Stage.SLO == 0 || e.Duration <= e.Stage.SLO)
+}
+
+// OperationStateChanged is published when the state of the operation is changed outside of the process steps,
+// e.g. by the user canceling the orchestration or by the stale operations detector
+type OperationStateChanged struct {
+	OldState  domain.LastOperationState
+	Operation internal.Operation
+	// Actor is the user or the component which changed the state
+	Actor string
+}
@@ -1,6 +1,8 @@
 package input
 
 import (
+	"fmt"
+
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/broker"
 	cloudProvider "github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/provider"
@@ -117,15 +119,16 @@ func (f *InputBuilderFactory) CreateProvisionInput(pp internal.ProvisioningParam
 	disabledComponents := mergeMaps(disabledForPlan, f.disabledComponentsProvider.DisabledForAll())
 
 	return &RuntimeInput{
-		provisionRuntimeInput:     initInput,
-		overrides:                 make(map[string][]*gqlschema.ConfigEntryInput, 0),
-		globalOverrides:           make([]*gqlschema.ConfigEntryInput, 0),
-		labels:                    make(map[string]string),
-		mutex:                     nsync.NewNamedMutex(),
-		hyperscalerInputProvider:  provider,
-		optionalComponentsService: f.optComponentsSvc,
-		componentsDisabler:        runtime.NewDisabledComponentsService(disabledComponents),
-		enabledOptionalComponents: map[string]struct{}{},
+		provisionRuntimeInput:      initInput,
+		overrides:                  make(map[string][]*gqlschema.ConfigEntryInput, 0),
+		globalOverrides:            registryOverrides(pp.Parameters.Registry),
+		labels:                     make(map[string]string),
+		mutex:                      nsync.NewNamedMutex(),
+		hyperscalerInputProvider:   provider,
+		optionalComponentsService:  f.optComponentsSvc,
+		componentsDisabler:         runtime.NewDisabledComponentsService(disabledComponents),
+		enabledOptionalComponents:  map[string]struct{}{},
+		disabledOptionalComponents: map[string]struct{}{},
 	}, nil
 }
 
@@ -189,7 +192,7 @@ func (f *InputBuilderFactory) CreateUpgradeInput(pp internal.ProvisioningParamet
 		return nil, errors.Errorf("plan %s in not supported", pp.PlanID)
 	}
 
-	upgradeKymaInput, err := f.initUpgradeRuntimeInput(f.kymaVersion)
+	upgradeKymaInput, err := f.initUpgradeRuntimeInput(pp.Parameters.KymaVersion)
 	if err != nil {
 		return nil, errors.Wrap(err, "while initializing UpgradeRuntimeInput")
 	}
@@ -201,25 +204,39 @@ func (f *InputBuilderFactory) CreateUpgradeInput(pp internal.ProvisioningParamet
 	disabledComponents := mergeMaps(disabledForPlan, f.disabledComponentsProvider.DisabledForAll())
 
 	return &RuntimeInput{
-		upgradeRuntimeInput:       upgradeKymaInput,
-		mutex:                     nsync.NewNamedMutex(),
-		overrides:                 make(map[string][]*gqlschema.ConfigEntryInput, 0),
-		globalOverrides:           make([]*gqlschema.ConfigEntryInput, 0),
-		optionalComponentsService: f.optComponentsSvc,
-		componentsDisabler:        runtime.NewDisabledComponentsService(disabledComponents),
-		enabledOptionalComponents: map[string]struct{}{},
+		upgradeRuntimeInput:        upgradeKymaInput,
+		mutex:                      nsync.NewNamedMutex(),
+		overrides:                  make(map[string][]*gqlschema.ConfigEntryInput, 0),
+		globalOverrides:            registryOverrides(pp.Parameters.Registry),
+		optionalComponentsService:  f.optComponentsSvc,
+		componentsDisabler:         runtime.NewDisabledComponentsService(disabledComponents),
+		enabledOptionalComponents:  map[string]struct{}{},
+		disabledOptionalComponents: map[string]struct{}{},
 	}, nil
 }
 
 func (f *InputBuilderFactory) initUpgradeRuntimeInput(kymaVersion string) (gqlschema.UpgradeRuntimeInput, error) {
 	if kymaVersion == "" {
-		return gqlschema.UpgradeRuntimeInput{}, errors.New("desired kymaVersion cannot be empty")
+		if f.kymaVersion == "" {
+			return gqlschema.UpgradeRuntimeInput{}, errors.New("desired kymaVersion cannot be empty")
+		}
+		return gqlschema.UpgradeRuntimeInput{
+			KymaConfig: &gqlschema.KymaConfigInput{
+				Version:    f.kymaVersion,
+				Components: f.fullComponentsList.DeepCopy(),
+			},
+		}, nil
+	}
+
+	allComponents, err := f.componentsProvider.AllComponents(kymaVersion)
+	if err != nil {
+		return gqlschema.UpgradeRuntimeInput{}, errors.Wrapf(err, "while fetching components for %s Kyma version", kymaVersion)
 	}
 
 	return gqlschema.UpgradeRuntimeInput{
 		KymaConfig: &gqlschema.KymaConfigInput{
 			Version:    kymaVersion,
-			Components: f.fullComponentsList.DeepCopy(),
+			Components: mapToGQLComponentConfigurationInput(allComponents),
 		},
 	}, nil
 }
@@ -250,3 +267,19 @@ func mergeMaps(maps ...map[string]struct{}) map[string]struct{} {
 	}
 	return res
 }
+
+// registryOverrides returns the global overrides which make the Kyma components pull the images from the private
+// registry mirror, the upgrades use the same overrides as the registry is kept in the provisioning parameters.
+// The charts expect the list of the pull secret names, so every name is passed as the list item in the Helm notation.
+func registryOverrides(registry *internal.RegistryDTO) []*gqlschema.ConfigEntryInput {
+	overrides := make([]*gqlschema.ConfigEntryInput, 0)
+	if registry == nil {
+		return overrides
+	}
+
+	overrides = append(overrides, &gqlschema.ConfigEntryInput{Key: "global.containerRegistry.path", Value: registry.URL})
+	for i, secret := range registry.PullSecrets {
+		overrides = append(overrides, &gqlschema.ConfigEntryInput{Key: fmt.Sprintf("global.imagePullSecrets[%d]", i), Value: secret.Name})
+	}
+	return overrides
+}
@@ -102,6 +102,26 @@ func TestInputBuilderFactory_ForPlan(t *testing.T) {
 		assert.NoError(t, err)
 		assert.IsType(t, &RuntimeInput{}, input)
 	})
+
+	t.Run("should build UpgradeRuntimeInput with set version Kyma components", func(t *testing.T) {
+		// given
+		componentsProvider := &automock.ComponentListProvider{}
+		componentsProvider.On("AllComponents", "1.10").Return([]v1alpha1.KymaComponent{}, nil).Once()
+		componentsProvider.On("AllComponents", "1.11").Return([]v1alpha1.KymaComponent{}, nil).Once()
+		defer componentsProvider.AssertExpectations(t)
+
+		ibf, err := NewInputBuilderFactory(nil, runtime.NewDisabledComponentsProvider(), componentsProvider, Config{}, "1.10", fixTrialRegionMapping())
+		assert.NoError(t, err)
+		pp := fixProvisioningParameters(broker.GCPPlanID, "1.11")
+
+		// when
+		input, err := ibf.CreateUpgradeInput(pp)
+
+		// Then
+		assert.NoError(t, err)
+		require.IsType(t, &RuntimeInput{}, input)
+		assert.Equal(t, "1.11", input.(*RuntimeInput).upgradeRuntimeInput.KymaConfig.Version)
+	})
 }
 
 func fixProvisioningParameters(planID, kymaVersion string) internal.ProvisioningParameters {
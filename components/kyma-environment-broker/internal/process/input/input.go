@@ -30,14 +30,25 @@ type RuntimeInput struct {
 	optionalComponentsService OptionalComponentService
 	provisioningParameters    internal.ProvisioningParameters
 
-	componentsDisabler        ComponentsDisabler
-	enabledOptionalComponents map[string]struct{}
+	componentsDisabler         ComponentsDisabler
+	enabledOptionalComponents  map[string]struct{}
+	disabledOptionalComponents map[string]struct{}
 }
 
 func (r *RuntimeInput) EnableOptionalComponent(componentName string) internal.ProvisionerInputCreator {
 	r.mutex.Lock("enabledOptionalComponents")
 	defer r.mutex.Unlock("enabledOptionalComponents")
 	r.enabledOptionalComponents[componentName] = struct{}{}
+	delete(r.disabledOptionalComponents, componentName)
+	return r
+}
+
+// DisableOptionalComponent disables the optional component even if it was selected in the provisioning parameters
+func (r *RuntimeInput) DisableOptionalComponent(componentName string) internal.ProvisionerInputCreator {
+	r.mutex.Lock("enabledOptionalComponents")
+	defer r.mutex.Unlock("enabledOptionalComponents")
+	r.disabledOptionalComponents[componentName] = struct{}{}
+	delete(r.enabledOptionalComponents, componentName)
 	return r
 }
 
@@ -178,12 +189,7 @@ func (r *RuntimeInput) resolveOptionalComponentsForProvisionRuntime() error {
 	r.mutex.Lock("enabledOptionalComponents")
 	defer r.mutex.Unlock("enabledOptionalComponents")
 
-	componentsToInstall := []string{}
-	componentsToInstall = append(componentsToInstall, r.provisioningParameters.Parameters.OptionalComponentsToInstall...)
-	for name := range r.enabledOptionalComponents {
-		componentsToInstall = append(componentsToInstall, name)
-	}
-	toDisable := r.optionalComponentsService.ComputeComponentsToDisable(componentsToInstall)
+	toDisable := r.optionalComponentsService.ComputeComponentsToDisable(r.optionalComponentsToInstall())
 
 	filterOut, err := r.optionalComponentsService.ExecuteDisablers(r.provisionRuntimeInput.KymaConfig.Components, toDisable...)
 	if err != nil {
@@ -199,12 +205,7 @@ func (r *RuntimeInput) resolveOptionalComponentsForUpgradeRuntime() error {
 	r.mutex.Lock("enabledOptionalComponents")
 	defer r.mutex.Unlock("enabledOptionalComponents")
 
-	componentsToInstall := []string{}
-	componentsToInstall = append(componentsToInstall, r.provisioningParameters.Parameters.OptionalComponentsToInstall...)
-	for name := range r.enabledOptionalComponents {
-		componentsToInstall = append(componentsToInstall, name)
-	}
-	toDisable := r.optionalComponentsService.ComputeComponentsToDisable(componentsToInstall)
+	toDisable := r.optionalComponentsService.ComputeComponentsToDisable(r.optionalComponentsToInstall())
 
 	filterOut, err := r.optionalComponentsService.ExecuteDisablers(r.upgradeRuntimeInput.KymaConfig.Components, toDisable...)
 	if err != nil {
@@ -216,6 +217,21 @@ func (r *RuntimeInput) resolveOptionalComponentsForUpgradeRuntime() error {
 	return nil
 }
 
+// optionalComponentsToInstall returns the optional components selected in the provisioning parameters
+// or enabled explicitly, without the explicitly disabled ones, the caller must hold the lock
+func (r *RuntimeInput) optionalComponentsToInstall() []string {
+	componentsToInstall := []string{}
+	for _, name := range r.provisioningParameters.Parameters.OptionalComponentsToInstall {
+		if _, disabled := r.disabledOptionalComponents[name]; !disabled {
+			componentsToInstall = append(componentsToInstall, name)
+		}
+	}
+	for name := range r.enabledOptionalComponents {
+		componentsToInstall = append(componentsToInstall, name)
+	}
+	return componentsToInstall
+}
+
 func (r *RuntimeInput) disableComponentsForProvisionRuntime() error {
 	filterOut, err := r.componentsDisabler.DisableComponents(r.provisionRuntimeInput.KymaConfig.Components)
 	if err != nil {
@@ -166,6 +166,47 @@ func TestShouldDisableComponents(t *testing.T) {
 	})
 }
 
+func TestShouldDisableSelectedOptionalComponent(t *testing.T) {
+	// given
+
+	// One base component: dex
+	// Two optional components selected in the provisioning parameters: Kiali and Tracing
+	// The test checks, if DisableOptionalComponent method removes the selected optional component
+	optionalComponentsDisablers := runtime.ComponentsDisablers{
+		components.Kiali:   runtime.NewGenericComponentDisabler(components.Kiali),
+		components.Tracing: runtime.NewGenericComponentDisabler(components.Tracing),
+	}
+	componentsProvider := &automock.ComponentListProvider{}
+	componentsProvider.On("AllComponents", mock.AnythingOfType("string")).
+		Return([]v1alpha1.KymaComponent{
+			{Name: components.Kiali},
+			{Name: components.Tracing},
+			{Name: "dex"},
+		}, nil)
+
+	builder, err := NewInputBuilderFactory(runtime.NewOptionalComponentsService(optionalComponentsDisablers), runtime.NewDisabledComponentsProvider(), componentsProvider, Config{}, "not-important", fixTrialRegionMapping())
+	assert.NoError(t, err)
+
+	pp := fixProvisioningParameters(broker.AzurePlanID, "")
+	pp.Parameters.OptionalComponentsToInstall = []string{components.Kiali, components.Tracing}
+	creator, err := builder.CreateProvisionInput(pp)
+	require.NoError(t, err)
+
+	// when
+	creator.DisableOptionalComponent(components.Tracing)
+	input, err := creator.CreateProvisionRuntimeInput()
+	require.NoError(t, err)
+
+	// then
+	assertComponentExists(t, input.KymaConfig.Components, gqlschema.ComponentConfigurationInput{
+		Component: components.Kiali,
+	})
+	assertComponentExists(t, input.KymaConfig.Components, gqlschema.ComponentConfigurationInput{
+		Component: "dex",
+	})
+	assert.Len(t, input.KymaConfig.Components, 2)
+}
+
 func TestDisabledComponentsForPlanNotExist(t *testing.T) {
 	// given
 	pp := fixProvisioningParameters("invalid-plan", "")
@@ -295,6 +336,44 @@ func TestInputBuilderFactoryOverrides(t *testing.T) {
 
 		assertContainsAllOverrides(t, out.KymaConfig.Configuration, overridesA1, overridesA1)
 	})
+
+	t.Run("should add registry global overrides for ProvisionRuntimeInput and UpgradeRuntimeInput", func(t *testing.T) {
+		// given
+		optComponentsSvc := dummyOptionalComponentServiceMock(fixKymaComponentList())
+		componentsProvider := &automock.ComponentListProvider{}
+		componentsProvider.On("AllComponents", mock.AnythingOfType("string")).Return(fixKymaComponentList(), nil)
+
+		pp := fixProvisioningParameters(broker.AzurePlanID, "1.14.0")
+		pp.Parameters.Registry = &internal.RegistryDTO{
+			URL: "registry.example.com:5000/kyma",
+			PullSecrets: []internal.RegistryPullSecretDTO{
+				{Name: "registry-credentials", Username: "kyma", Password: "secret"},
+				{Name: "other-credentials", Username: "other", Password: "secret"},
+			},
+		}
+		expectedOverrides := []*gqlschema.ConfigEntryInput{
+			{Key: "global.containerRegistry.path", Value: "registry.example.com:5000/kyma"},
+			{Key: "global.imagePullSecrets[0]", Value: "registry-credentials"},
+			{Key: "global.imagePullSecrets[1]", Value: "other-credentials"},
+		}
+		builder, err := NewInputBuilderFactory(optComponentsSvc, runtime.NewDisabledComponentsProvider(), componentsProvider, Config{}, "not-important", fixTrialRegionMapping())
+		assert.NoError(t, err)
+
+		// when
+		provisionCreator, err := builder.CreateProvisionInput(pp)
+		require.NoError(t, err)
+		provisionInput, err := provisionCreator.CreateProvisionRuntimeInput()
+		require.NoError(t, err)
+
+		upgradeCreator, err := builder.CreateUpgradeInput(pp)
+		require.NoError(t, err)
+		upgradeInput, err := upgradeCreator.CreateUpgradeRuntimeInput()
+		require.NoError(t, err)
+
+		// then
+		assert.Equal(t, expectedOverrides, provisionInput.KymaConfig.Configuration)
+		assert.Equal(t, expectedOverrides, upgradeInput.KymaConfig.Configuration)
+	})
 }
 
 func TestInputBuilderFactoryForAzurePlan(t *testing.T) {
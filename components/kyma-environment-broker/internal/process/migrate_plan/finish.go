@@ -0,0 +1,60 @@
+package migrate_plan
+
+import (
+	"context"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+
+	"github.com/sirupsen/logrus"
+)
+
+// FinishStep finishes the migration when the runtime of the target plan is provisioned
+// and the runtime of the source plan is removed
+type FinishStep struct {
+	operationManager *process.PlanMigrationOperationManager
+	freeTierStorage  storage.FreeTierUsage
+}
+
+func NewFinishStep(os storage.Operations, fs storage.FreeTierUsage) *FinishStep {
+	return &FinishStep{
+		operationManager: process.NewPlanMigrationOperationManager(os),
+		freeTierStorage:  fs,
+	}
+}
+
+func (s *FinishStep) Name() string {
+	return "Finish_Plan_Migration"
+}
+
+func (s *FinishStep) Run(operation internal.PlanMigrationOperation, log logrus.FieldLogger) (internal.PlanMigrationOperation, time.Duration, error) {
+	return s.RunWithContext(context.Background(), operation, log)
+}
+
+// RunWithContext runs the step with the storage calls recorded in the trace of the given context
+func (s *FinishStep) RunWithContext(ctx context.Context, operation internal.PlanMigrationOperation, log logrus.FieldLogger) (internal.PlanMigrationOperation, time.Duration, error) {
+	step := *s
+	step.operationManager = s.operationManager.WithContext(ctx)
+	return step.run(operation, log)
+}
+
+func (s *FinishStep) run(operation internal.PlanMigrationOperation, log logrus.FieldLogger) (internal.PlanMigrationOperation, time.Duration, error) {
+	pp, err := operation.GetProvisioningParameters()
+	if err != nil {
+		log.Errorf("cannot fetch provisioning parameters from operation: %s", err)
+		return s.operationManager.OperationFailed(operation, "invalid operation provisioning parameters")
+	}
+	// the instance migrated from the free plan does not consume the free tier anymore
+	if !broker.IsTrialPlan(pp.PlanID) {
+		err = s.freeTierStorage.Finish(operation.InstanceID, time.Now())
+		if err != nil {
+			log.Errorf("unable to finish free tier usage: %s", err)
+			return operation, 10 * time.Second, nil
+		}
+	}
+
+	return s.operationManager.OperationSucceeded(operation, "Operation succeeded")
+}
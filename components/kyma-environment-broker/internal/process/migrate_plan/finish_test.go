@@ -0,0 +1,45 @@
+package migrate_plan
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFinishStep_Run(t *testing.T) {
+	// given
+	memoryStorage := storage.NewMemoryStorage()
+	operation := fixPlanMigrationOperation(t)
+	operation.SourceRuntimeRemoved = true
+	err := memoryStorage.Operations().InsertPlanMigrationOperation(operation)
+	require.NoError(t, err)
+	err = memoryStorage.FreeTierUsage().Insert(internal.FreeTierUsageEntry{
+		InstanceID:      fixInstanceID,
+		GlobalAccountID: fixGlobalAccountID,
+		PlanID:          broker.TrialPlanID,
+		StartedAt:       time.Now().Add(-time.Hour),
+	})
+	require.NoError(t, err)
+
+	step := NewFinishStep(memoryStorage.Operations(), memoryStorage.FreeTierUsage())
+
+	// when
+	result, repeat, err := step.Run(operation, logrus.New())
+
+	// then
+	require.NoError(t, err)
+	assert.Zero(t, repeat)
+	assert.Equal(t, domain.Succeeded, result.State)
+
+	usage, err := memoryStorage.FreeTierUsage().ListByGlobalAccountID(fixGlobalAccountID)
+	require.NoError(t, err)
+	require.Len(t, usage, 1)
+	assert.False(t, usage[0].FinishedAt.IsZero())
+}
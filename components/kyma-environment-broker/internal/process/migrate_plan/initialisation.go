@@ -0,0 +1,229 @@
+package migrate_plan
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/provisioner"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
+	"github.com/kyma-project/control-plane/components/provisioner/pkg/gqlschema"
+
+	"github.com/google/uuid"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// the time after which the operation is marked as expired, it covers both provisioning and removal of the runtime
+	MigrationTimeout = 8 * time.Hour
+)
+
+// InitialisationStep waits for the provisioning of the runtime of the target plan, the following steps remove
+// the runtime of the source plan only when the runtime of the target plan is provisioned. When the provisioning
+// of the target plan fails, its runtime is removed and the instance is switched back to the runtime of the source
+// plan, which was not touched by the migration, and the migration fails.
+type InitialisationStep struct {
+	operationManager  *process.PlanMigrationOperationManager
+	operationStorage  storage.Provisioning
+	instanceStorage   storage.Instances
+	provisionerClient provisioner.Client
+}
+
+func NewInitialisationStep(os storage.Operations, is storage.Instances, cli provisioner.Client) *InitialisationStep {
+	return &InitialisationStep{
+		operationManager:  process.NewPlanMigrationOperationManager(os),
+		operationStorage:  os,
+		instanceStorage:   is,
+		provisionerClient: cli,
+	}
+}
+
+func (s *InitialisationStep) Name() string {
+	return "Migrate_Plan_Initialization"
+}
+
+func (s *InitialisationStep) Run(operation internal.PlanMigrationOperation, log logrus.FieldLogger) (internal.PlanMigrationOperation, time.Duration, error) {
+	return s.RunWithContext(context.Background(), operation, log)
+}
+
+// RunWithContext runs the step with the Provisioner calls sent with the given context
+func (s *InitialisationStep) RunWithContext(ctx context.Context, operation internal.PlanMigrationOperation, log logrus.FieldLogger) (internal.PlanMigrationOperation, time.Duration, error) {
+	step := *s
+	step.operationManager = s.operationManager.WithContext(ctx)
+	step.provisionerClient = provisioner.WithContext(s.provisionerClient, ctx)
+	return step.run(operation, log)
+}
+
+func (s *InitialisationStep) run(operation internal.PlanMigrationOperation, log logrus.FieldLogger) (internal.PlanMigrationOperation, time.Duration, error) {
+	if time.Since(operation.TimeoutStart()) > MigrationTimeout {
+		log.Infof("operation has reached the time limit: operation started at: %s", operation.TimeoutStart())
+		return s.operationManager.OperationFailed(operation, fmt.Sprintf("operation has reached the time limit: %s", MigrationTimeout))
+	}
+
+	if operation.ProvisioningOperationID == "" {
+		return operation, 0, nil
+	}
+
+	provisioning, err := s.operationStorage.GetProvisioningOperationByID(operation.ProvisioningOperationID)
+	switch {
+	case dberr.IsNotFound(errors.Cause(err)):
+		// the provisioning operation was not created yet, the following steps create it
+		return operation, 0, nil
+	case err != nil:
+		log.Errorf("unable to get provisioning operation %s from storage: %s", operation.ProvisioningOperationID, err)
+		return operation, 10 * time.Second, nil
+	}
+	log.Infof("provisioning of the runtime of the target plan is %s", provisioning.State)
+
+	switch provisioning.State {
+	case domain.Succeeded:
+		// the following steps remove the runtime of the source plan
+		return operation, 0, nil
+	case domain.Failed:
+		log.Errorf("provisioning of the runtime of the target plan failed: %s", provisioning.Description)
+		return s.rollback(operation, *provisioning, log)
+	default:
+		return operation, 1 * time.Minute, nil
+	}
+}
+
+// rollback removes the runtime of the target plan and switches the instance back to the runtime of the source plan
+func (s *InitialisationStep) rollback(operation internal.PlanMigrationOperation, provisioning internal.ProvisioningOperation, log logrus.FieldLogger) (internal.PlanMigrationOperation, time.Duration, error) {
+	operation, repeat, err := s.removeTargetRuntime(operation, provisioning, log)
+	if err != nil || repeat != 0 || operation.IsFinished() {
+		return operation, repeat, err
+	}
+
+	// the ID is stored before the provisioning operation is created, so the restoring can be repeated safely
+	if operation.RestoringOperationID == "" {
+		operation.RestoringOperationID = uuid.New().String()
+		operation, repeat = s.operationManager.UpdateOperation(operation)
+		if repeat != 0 {
+			log.Errorf("cannot save restoring operation ID")
+			return operation, repeat, nil
+		}
+	}
+	log = log.WithField("restoringOperationID", operation.RestoringOperationID)
+
+	if err := s.restoreInstance(operation); err != nil {
+		log.Errorf("unable to restore instance in the source plan: %s", err)
+		return operation, 10 * time.Second, nil
+	}
+	if err := s.recordSourceProvisioning(operation); err != nil {
+		log.Errorf("unable to record provisioning of the source plan: %s", err)
+		return operation, 10 * time.Second, nil
+	}
+
+	log.Info("the instance was restored in the source plan")
+	return s.operationManager.OperationFailed(operation, "provisioning of the runtime of the target plan failed, the instance was restored in the source plan")
+}
+
+// removeTargetRuntime deprovisions the runtime created by the failed provisioning of the target plan and waits until
+// it is removed, the runtime is not created if the Provisioner did not accept the provisioning
+func (s *InitialisationStep) removeTargetRuntime(operation internal.PlanMigrationOperation, provisioning internal.ProvisioningOperation, log logrus.FieldLogger) (internal.PlanMigrationOperation, time.Duration, error) {
+	if operation.TargetRuntimeRemoved || provisioning.RuntimeID == "" {
+		return operation, 0, nil
+	}
+	log = log.WithField("targetRuntimeID", provisioning.RuntimeID)
+
+	instance, err := s.instanceStorage.GetByID(operation.InstanceID)
+	if err != nil {
+		log.Errorf("unable to get instance from storage: %s", err)
+		return operation, 10 * time.Second, nil
+	}
+	tenant := instance.Tenant()
+
+	if operation.TargetProvisionerOperationID == "" {
+		provisionerResponse, err := s.provisionerClient.DeprovisionRuntime(tenant, provisioning.RuntimeID)
+		if err != nil {
+			log.Errorf("unable to deprovision runtime of the target plan: %s", err)
+			return operation, 10 * time.Second, nil
+		}
+		operation.TargetProvisionerOperationID = provisionerResponse
+		log.Infof("removal of the runtime of the target plan started, provisioner operation ID: %s", provisionerResponse)
+
+		var repeat time.Duration
+		operation, repeat = s.operationManager.UpdateOperation(operation)
+		if repeat != 0 {
+			log.Errorf("cannot save operation ID from provisioner")
+			return operation, 5 * time.Second, nil
+		}
+		return operation, 1 * time.Minute, nil
+	}
+
+	status, err := s.provisionerClient.RuntimeOperationStatus(tenant, operation.TargetProvisionerOperationID)
+	if err != nil {
+		log.Errorf("call to provisioner RuntimeOperationStatus failed: %s", err)
+		return operation, 1 * time.Minute, nil
+	}
+	log.Infof("call to provisioner returned %s status", status.State.String())
+
+	switch status.State {
+	case gqlschema.OperationStateSucceeded:
+		operation.TargetRuntimeRemoved = true
+		operation, repeat := s.operationManager.UpdateOperation(operation)
+		if repeat != 0 {
+			log.Errorf("cannot save the removal of the target runtime")
+		}
+		return operation, repeat, nil
+	case gqlschema.OperationStateInProgress, gqlschema.OperationStatePending:
+		return operation, 1 * time.Minute, nil
+	}
+
+	var msg string
+	if status.Message != nil {
+		msg = *status.Message
+	}
+	// the instance is not restored, so the runtime of the target plan is not left behind unnoticed
+	return s.operationManager.OperationFailed(operation, fmt.Sprintf("provisioning of the runtime of the target plan failed and its runtime %s cannot be removed: %s", provisioning.RuntimeID, msg))
+}
+
+// restoreInstance switches the instance back to the source plan and to its runtime
+func (s *InitialisationStep) restoreInstance(operation internal.PlanMigrationOperation) error {
+	instance, err := s.instanceStorage.GetByID(operation.InstanceID)
+	if err != nil {
+		return errors.Wrap(err, "while getting instance")
+	}
+	instance.ServicePlanID = operation.SourcePlanID
+	instance.ServicePlanName = broker.Plans[operation.SourcePlanID].PlanDefinition.Name
+	instance.ProvisioningParameters = operation.SourceProvisioningParameters
+	instance.RuntimeID = operation.SourceRuntimeID
+	instance.DashboardURL = operation.SourceDashboardURL
+	instance.APIServerURL = operation.SourceAPIServerURL
+	instance.CABundle = operation.SourceCABundle
+	instance.ShootName = operation.SourceShootName
+	return errors.Wrap(s.instanceStorage.Update(*instance), "while updating instance")
+}
+
+// recordSourceProvisioning stores the copy of the provisioning of the source plan as the latest provisioning
+// of the instance, so the instance is reported as provisioned in the source plan instead of the failed provisioning
+func (s *InitialisationStep) recordSourceProvisioning(operation internal.PlanMigrationOperation) error {
+	_, err := s.operationStorage.GetProvisioningOperationByID(operation.RestoringOperationID)
+	switch {
+	case err == nil:
+		return nil
+	case !dberr.IsNotFound(errors.Cause(err)):
+		return errors.Wrapf(err, "while getting provisioning operation %s", operation.RestoringOperationID)
+	}
+
+	source, err := s.operationStorage.GetProvisioningOperationByID(operation.SourceProvisioningOperationID)
+	if err != nil {
+		return errors.Wrapf(err, "while getting provisioning operation %s", operation.SourceProvisioningOperationID)
+	}
+	restoring := *source
+	restoring.ID = operation.RestoringOperationID
+	restoring.Version = 0
+	restoring.State = domain.Succeeded
+	restoring.Description = "the instance was restored in the source plan after the failed plan migration"
+	restoring.CreatedAt = time.Now()
+	restoring.UpdatedAt = time.Now()
+	restoring.CorrelationID = operation.CorrelationID
+
+	return errors.Wrap(s.operationStorage.InsertProvisioningOperation(restoring), "while inserting provisioning operation")
+}
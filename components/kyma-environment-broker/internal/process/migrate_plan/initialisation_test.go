@@ -0,0 +1,326 @@
+package migrate_plan
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/broker"
+	provisionerAutomock "github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/provisioner/automock"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/ptr"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/provisioner/pkg/gqlschema"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	fixMigrationOperationID          = "a8a30d4b-58f6-48e0-9b4f-3d8c5e38fa1e"
+	fixProvisioningOperationID       = "17f3ddba-1132-466d-a3c5-920f544d7ea6"
+	fixSourceProvisioningOperationID = "c1a7e1f4-3f5e-4c51-9a39-7f0a0b8f2d61"
+	fixInstanceID                    = "9d75a545-2e1e-4786-abd8-a37b14e185b9"
+	fixRuntimeID                     = "ef4e3210-652c-453e-8015-bba1c1cd1e1c"
+	fixTargetRuntimeID               = "0b0d5c5e-4a8f-4b9c-a1f3-6c7c2f8e4b12"
+	fixGlobalAccountID               = "abf73c71-a653-4951-b9c2-a26d6c2cccbd"
+	fixProvisionerOperationID        = "e04de524-53b3-4890-b05a-296be393e4ba"
+	fixRestoringOperationID          = "5b0b8a9e-6d3a-4b55-8a0e-3f2b21e8c7d4"
+)
+
+func TestInitialisationStep_Run(t *testing.T) {
+	for name, tc := range map[string]struct {
+		provisioningState domain.LastOperationState
+		expectedRepeat    time.Duration
+	}{
+		"should wait for the provisioning in progress": {
+			provisioningState: domain.InProgress,
+			expectedRepeat:    time.Minute,
+		},
+		"should continue when the provisioning succeeded": {
+			provisioningState: domain.Succeeded,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// given
+			memoryStorage := storage.NewMemoryStorage()
+			operation := fixMigrationWithProvisioning(t, memoryStorage, tc.provisioningState, "")
+
+			step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Instances(), &provisionerAutomock.Client{})
+
+			// when
+			result, repeat, err := step.Run(operation, logrus.New())
+
+			// then
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedRepeat, repeat)
+			assert.Equal(t, domain.InProgress, result.State)
+		})
+	}
+
+	t.Run("should trigger removal of the target runtime when provisioning failed", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		operation := fixMigrationWithProvisioning(t, memoryStorage, domain.Failed, fixTargetRuntimeID)
+		err := memoryStorage.Instances().Insert(fixTargetInstance())
+		require.NoError(t, err)
+
+		provisionerClient := &provisionerAutomock.Client{}
+		provisionerClient.On("DeprovisionRuntime", fixGlobalAccountID, fixTargetRuntimeID).Return(fixProvisionerOperationID, nil).Once()
+		defer provisionerClient.AssertExpectations(t)
+
+		step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Instances(), provisionerClient)
+
+		// when
+		result, repeat, err := step.Run(operation, logrus.New())
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, time.Minute, repeat)
+		assert.Equal(t, domain.InProgress, result.State)
+		assert.Equal(t, fixProvisionerOperationID, result.TargetProvisionerOperationID)
+		assert.False(t, result.TargetRuntimeRemoved)
+
+		instance, err := memoryStorage.Instances().GetByID(fixInstanceID)
+		require.NoError(t, err)
+		assert.Equal(t, broker.AzurePlanID, instance.ServicePlanID, "the instance is restored only when the target runtime is removed")
+	})
+
+	t.Run("should wait for the removal of the target runtime in progress", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		operation := fixMigrationWithProvisioning(t, memoryStorage, domain.Failed, fixTargetRuntimeID)
+		operation.TargetProvisionerOperationID = fixProvisionerOperationID
+		err := memoryStorage.Instances().Insert(fixTargetInstance())
+		require.NoError(t, err)
+
+		provisionerClient := &provisionerAutomock.Client{}
+		provisionerClient.On("RuntimeOperationStatus", fixGlobalAccountID, fixProvisionerOperationID).Return(gqlschema.OperationStatus{
+			ID:    ptr.String(fixProvisionerOperationID),
+			State: gqlschema.OperationStateInProgress,
+		}, nil).Once()
+		defer provisionerClient.AssertExpectations(t)
+
+		step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Instances(), provisionerClient)
+
+		// when
+		result, repeat, err := step.Run(operation, logrus.New())
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, time.Minute, repeat)
+		assert.Equal(t, domain.InProgress, result.State)
+	})
+
+	t.Run("should restore the instance in the source plan when the target runtime was removed", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		operation := fixMigrationWithProvisioning(t, memoryStorage, domain.Failed, fixTargetRuntimeID)
+		operation.TargetProvisionerOperationID = fixProvisionerOperationID
+		updated, err := memoryStorage.Operations().UpdatePlanMigrationOperation(operation)
+		require.NoError(t, err)
+		operation = *updated
+		err = memoryStorage.Instances().Insert(fixTargetInstance())
+		require.NoError(t, err)
+
+		provisionerClient := &provisionerAutomock.Client{}
+		provisionerClient.On("RuntimeOperationStatus", fixGlobalAccountID, fixProvisionerOperationID).Return(gqlschema.OperationStatus{
+			ID:    ptr.String(fixProvisionerOperationID),
+			State: gqlschema.OperationStateSucceeded,
+		}, nil).Once()
+		defer provisionerClient.AssertExpectations(t)
+
+		step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Instances(), provisionerClient)
+
+		// when
+		result, _, err := step.Run(operation, logrus.New())
+
+		// then
+		assert.Error(t, err)
+		assert.Equal(t, domain.Failed, result.State)
+		assert.True(t, result.TargetRuntimeRemoved)
+		require.NotEmpty(t, result.RestoringOperationID)
+
+		instance, err := memoryStorage.Instances().GetByID(fixInstanceID)
+		require.NoError(t, err)
+		assert.Equal(t, broker.TrialPlanID, instance.ServicePlanID)
+		assert.Equal(t, broker.TrialPlanName, instance.ServicePlanName)
+		assert.Equal(t, fixInstance().ProvisioningParameters, instance.ProvisioningParameters)
+		assert.Equal(t, fixRuntimeID, instance.RuntimeID)
+		assert.Equal(t, fixInstance().DashboardURL, instance.DashboardURL)
+
+		latest, err := memoryStorage.Operations().GetProvisioningOperationByInstanceID(fixInstanceID)
+		require.NoError(t, err)
+		assert.Equal(t, result.RestoringOperationID, latest.ID)
+		assert.Equal(t, domain.Succeeded, latest.State)
+		assert.Equal(t, fixRuntimeID, latest.RuntimeID)
+	})
+
+	t.Run("should restore the instance when the target runtime was not created", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		operation := fixMigrationWithProvisioning(t, memoryStorage, domain.Failed, "")
+		err := memoryStorage.Instances().Insert(fixTargetInstance())
+		require.NoError(t, err)
+
+		provisionerClient := &provisionerAutomock.Client{}
+		defer provisionerClient.AssertExpectations(t)
+
+		step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Instances(), provisionerClient)
+
+		// when
+		result, _, err := step.Run(operation, logrus.New())
+
+		// then
+		assert.Error(t, err)
+		assert.Equal(t, domain.Failed, result.State)
+		provisionerClient.AssertNotCalled(t, "DeprovisionRuntime", fixGlobalAccountID, "")
+
+		instance, err := memoryStorage.Instances().GetByID(fixInstanceID)
+		require.NoError(t, err)
+		assert.Equal(t, broker.TrialPlanID, instance.ServicePlanID)
+		assert.Equal(t, fixRuntimeID, instance.RuntimeID)
+	})
+
+	t.Run("should fail operation without restoring the instance when the target runtime cannot be removed", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		operation := fixMigrationWithProvisioning(t, memoryStorage, domain.Failed, fixTargetRuntimeID)
+		operation.TargetProvisionerOperationID = fixProvisionerOperationID
+		updated, err := memoryStorage.Operations().UpdatePlanMigrationOperation(operation)
+		require.NoError(t, err)
+		operation = *updated
+		err = memoryStorage.Instances().Insert(fixTargetInstance())
+		require.NoError(t, err)
+
+		provisionerClient := &provisionerAutomock.Client{}
+		provisionerClient.On("RuntimeOperationStatus", fixGlobalAccountID, fixProvisionerOperationID).Return(gqlschema.OperationStatus{
+			ID:      ptr.String(fixProvisionerOperationID),
+			State:   gqlschema.OperationStateFailed,
+			Message: ptr.String("cluster is stuck"),
+		}, nil).Once()
+		defer provisionerClient.AssertExpectations(t)
+
+		step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Instances(), provisionerClient)
+
+		// when
+		result, _, err := step.Run(operation, logrus.New())
+
+		// then
+		assert.Error(t, err)
+		assert.Equal(t, domain.Failed, result.State)
+		assert.Contains(t, result.Description, fixTargetRuntimeID)
+		assert.Empty(t, result.RestoringOperationID)
+
+		instance, err := memoryStorage.Instances().GetByID(fixInstanceID)
+		require.NoError(t, err)
+		assert.Equal(t, broker.AzurePlanID, instance.ServicePlanID)
+	})
+
+	t.Run("should continue when the provisioning was not started", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+
+		operation := fixPlanMigrationOperation(t)
+		operation.ProvisioningOperationID = fixProvisioningOperationID
+		err := memoryStorage.Operations().InsertPlanMigrationOperation(operation)
+		require.NoError(t, err)
+
+		step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Instances(), &provisionerAutomock.Client{})
+
+		// when
+		result, repeat, err := step.Run(operation, logrus.New())
+
+		// then
+		require.NoError(t, err)
+		assert.Zero(t, repeat)
+		assert.Equal(t, domain.InProgress, result.State)
+	})
+
+	t.Run("should fail operation when the time limit is reached", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+
+		operation := fixPlanMigrationOperation(t)
+		operation.CreatedAt = time.Now().Add(-MigrationTimeout - time.Minute)
+		err := memoryStorage.Operations().InsertPlanMigrationOperation(operation)
+		require.NoError(t, err)
+
+		step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Instances(), &provisionerAutomock.Client{})
+
+		// when
+		result, _, err := step.Run(operation, logrus.New())
+
+		// then
+		assert.Error(t, err)
+		assert.Equal(t, domain.Failed, result.State)
+	})
+}
+
+func fixMigrationWithProvisioning(t *testing.T, memoryStorage storage.BrokerStorage, state domain.LastOperationState, targetRuntimeID string) internal.PlanMigrationOperation {
+	operation := fixPlanMigrationOperation(t)
+	operation.ProvisioningOperationID = fixProvisioningOperationID
+	err := memoryStorage.Operations().InsertPlanMigrationOperation(operation)
+	require.NoError(t, err)
+
+	err = memoryStorage.Operations().InsertProvisioningOperation(internal.ProvisioningOperation{
+		Operation: internal.Operation{
+			ID:         fixSourceProvisioningOperationID,
+			InstanceID: fixInstanceID,
+			State:      domain.Succeeded,
+			CreatedAt:  time.Now().Add(-2 * time.Hour),
+		},
+		RuntimeID: fixRuntimeID,
+	})
+	require.NoError(t, err)
+	err = memoryStorage.Operations().InsertProvisioningOperation(internal.ProvisioningOperation{
+		Operation: internal.Operation{
+			ID:         fixProvisioningOperationID,
+			InstanceID: fixInstanceID,
+			State:      state,
+			CreatedAt:  time.Now().Add(-time.Hour),
+		},
+		RuntimeID: targetRuntimeID,
+	})
+	require.NoError(t, err)
+
+	return operation
+}
+
+func fixPlanMigrationOperation(t *testing.T) internal.PlanMigrationOperation {
+	operation, err := internal.NewPlanMigrationOperation(fixInstance(), internal.ProvisioningParameters{
+		PlanID:     broker.AzurePlanID,
+		Parameters: internal.ProvisioningParametersDTO{Name: "cluster"},
+	})
+	require.NoError(t, err)
+	operation.ID = fixMigrationOperationID
+	operation.SourceProvisioningOperationID = fixSourceProvisioningOperationID
+
+	return operation
+}
+
+func fixInstance() internal.Instance {
+	return internal.Instance{
+		InstanceID:      fixInstanceID,
+		RuntimeID:       fixRuntimeID,
+		GlobalAccountID: fixGlobalAccountID,
+		ServicePlanID:   broker.TrialPlanID,
+		ServicePlanName: broker.TrialPlanName,
+		DashboardURL:    "https://console.trial.example.com",
+		ProvisioningParameters: fmt.Sprintf(`{"plan_id":"%s","ers_context":{"globalaccount_id":"%s"},"parameters":{"name":"cluster"}}`,
+			broker.TrialPlanID, fixGlobalAccountID),
+	}
+}
+
+// fixTargetInstance returns the instance switched to the target plan by the migration
+func fixTargetInstance() internal.Instance {
+	instance := fixInstance()
+	instance.ServicePlanID = broker.AzurePlanID
+	instance.ServicePlanName = broker.AzurePlanName
+	instance.RuntimeID = fixTargetRuntimeID
+	instance.DashboardURL = ""
+	instance.ProvisioningParameters = fmt.Sprintf(`{"plan_id":"%s","ers_context":{"globalaccount_id":"%s"},"parameters":{"name":"cluster"}}`,
+		broker.AzurePlanID, fixGlobalAccountID)
+	return instance
+}
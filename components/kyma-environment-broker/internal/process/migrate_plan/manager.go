@@ -0,0 +1,171 @@
+package migrate_plan
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/event"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/logger"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/tracing"
+	"github.com/sirupsen/logrus"
+)
+
+type Step interface {
+	Name() string
+	Run(operation internal.PlanMigrationOperation, logger logrus.FieldLogger) (internal.PlanMigrationOperation, time.Duration, error)
+}
+
+// StepWithContext is implemented by the steps which need the deadline of the step run, e.g. to cancel
+// the calls to the external services
+type StepWithContext interface {
+	Step
+	RunWithContext(ctx context.Context, operation internal.PlanMigrationOperation, logger logrus.FieldLogger) (internal.PlanMigrationOperation, time.Duration, error)
+}
+
+type Manager struct {
+	log              logrus.FieldLogger
+	steps            map[int][]Step
+	operationStorage storage.Operations
+
+	publisher event.Publisher
+	hooks     process.StepHooks
+}
+
+func NewManager(storage storage.Operations, pub event.Publisher, logger logrus.FieldLogger) *Manager {
+	return &Manager{
+		log:              logger,
+		steps:            make(map[int][]Step, 0),
+		operationStorage: storage,
+		publisher:        pub,
+	}
+}
+
+func (m *Manager) InitStep(step Step) {
+	m.AddStep(0, step)
+}
+
+func (m *Manager) AddStep(weight int, step Step) {
+	if weight <= 0 {
+		weight = 1
+	}
+	m.steps[weight] = append(m.steps[weight], step)
+}
+
+// AddHook adds the hook called around every step run
+func (m *Manager) AddHook(hook process.StepHook) {
+	m.hooks = append(m.hooks, hook)
+}
+
+func (m *Manager) runStep(step Step, operation internal.PlanMigrationOperation, log logrus.FieldLogger) (internal.PlanMigrationOperation, time.Duration, error) {
+	ctx, span := tracing.StartOperationSpan(nil, fmt.Sprintf("migrate_plan/%s", step.Name()), operation.ID, operation.InstanceID)
+	ctx = logger.AddToContext(ctx, log)
+	ctx = httputil.ContextWithCorrelationID(ctx, operation.CorrelationID)
+	ctx = m.hooks.Before(ctx, process.StepInfo{Process: process.PlanMigrationProcess, StepName: step.Name(), Operation: operation.Operation})
+	start := time.Now()
+	processedOperation, when, err := m.runStepWithDeadline(ctx, step, operation, log)
+	duration := time.Since(start)
+	m.hooks.After(ctx, process.StepInfo{
+		Process:   process.PlanMigrationProcess,
+		StepName:  step.Name(),
+		Operation: processedOperation.Operation,
+		Duration:  duration,
+		When:      when,
+		Error:     err,
+	})
+	tracing.End(ctx, span, err)
+	m.publisher.Publish(ctx, process.PlanMigrationStepProcessed{
+		OldOperation: operation,
+		Operation:    processedOperation,
+		StepProcessed: process.StepProcessed{
+			StepName: step.Name(),
+			Duration: duration,
+			When:     when,
+			Error:    err,
+		},
+	})
+	return processedOperation, when, err
+}
+
+// runStepWithDeadline runs the step with process.RunStep, the operation is repeated when the step exceeds its timeout
+func (m *Manager) runStepWithDeadline(ctx context.Context, step Step, operation internal.PlanMigrationOperation, log logrus.FieldLogger) (internal.PlanMigrationOperation, time.Duration, error) {
+	var (
+		processedOperation internal.PlanMigrationOperation
+		when               time.Duration
+		err                error
+	)
+	deadlineErr := process.RunStep(ctx, step, func(ctx context.Context) {
+		processedOperation, when, err = runWithContext(ctx, step, operation, log)
+	})
+	if deadlineErr != nil {
+		return operation, process.StepTimeoutRetryInterval, nil
+	}
+	return processedOperation, when, err
+}
+
+// runWithContext passes the context to the step which implements StepWithContext
+func runWithContext(ctx context.Context, step Step, operation internal.PlanMigrationOperation, log logrus.FieldLogger) (internal.PlanMigrationOperation, time.Duration, error) {
+	if s, ok := step.(StepWithContext); ok {
+		return s.RunWithContext(ctx, operation, log)
+	}
+	return step.Run(operation, log)
+}
+
+func (m *Manager) Execute(operationID string) (time.Duration, error) {
+	op, err := m.operationStorage.GetPlanMigrationOperationByID(operationID)
+	if err != nil {
+		m.log.Errorf("Cannot fetch operation from storage: %s", err)
+		return 3 * time.Second, nil
+	}
+	operation := *op
+	if operation.IsFinished() {
+		return 0, nil
+	}
+
+	var when time.Duration
+	logOperation := logger.WithCorrelationID(logger.WithOperation(m.log, operationID, operation.InstanceID), operation.CorrelationID)
+
+	logOperation.Info("Start process operation steps")
+	for _, weightStep := range m.sortWeight() {
+		steps := m.steps[weightStep]
+		for _, step := range steps {
+			logStep := logOperation.WithField(logger.StepField, step.Name())
+			logStep.Infof("Start step")
+
+			operation, when, err = m.runStep(step, operation, logStep)
+			if err != nil {
+				logStep.Errorf("Process operation failed: %s", err)
+				return 0, err
+			}
+			if operation.IsFinished() {
+				logStep.Infof("Operation %q got status %s. Process finished.", operation.ID, operation.State)
+				return 0, nil
+			}
+			if when == 0 {
+				logStep.Info("Process operation successful")
+				continue
+			}
+
+			logStep.Infof("Process operation will be repeated in %s ...", when)
+			return when, nil
+		}
+	}
+
+	logOperation.Infof("Operation %q got status %s. All steps finished.", operation.ID, operation.State)
+	return 0, nil
+}
+
+func (m *Manager) sortWeight() []int {
+	var weight []int
+	for w := range m.steps {
+		weight = append(weight, w)
+	}
+	sort.Ints(weight)
+
+	return weight
+}
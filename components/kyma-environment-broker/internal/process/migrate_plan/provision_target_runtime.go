@@ -0,0 +1,141 @@
+package migrate_plan
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
+
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+type Queue interface {
+	Add(operationId string)
+}
+
+// ProvisionTargetRuntimeStep switches the instance to the target plan and starts the provisioning of its new runtime
+// while the runtime of the source plan is still running. The provisioning registers the runtime in the Director and
+// sets the dashboard URL in the same way as for a new instance, but it keeps the EDP registration and the AVS
+// evaluations of the source runtime, as they belong to the instance.
+type ProvisionTargetRuntimeStep struct {
+	operationManager *process.PlanMigrationOperationManager
+	operationStorage storage.Provisioning
+	instanceStorage  storage.Instances
+	provisionQueue   Queue
+}
+
+func NewProvisionTargetRuntimeStep(os storage.Operations, is storage.Instances, q Queue) *ProvisionTargetRuntimeStep {
+	return &ProvisionTargetRuntimeStep{
+		operationManager: process.NewPlanMigrationOperationManager(os),
+		operationStorage: os,
+		instanceStorage:  is,
+		provisionQueue:   q,
+	}
+}
+
+func (s *ProvisionTargetRuntimeStep) Name() string {
+	return "Provision_Target_Runtime"
+}
+
+func (s *ProvisionTargetRuntimeStep) Run(operation internal.PlanMigrationOperation, log logrus.FieldLogger) (internal.PlanMigrationOperation, time.Duration, error) {
+	return s.RunWithContext(context.Background(), operation, log)
+}
+
+// RunWithContext runs the step with the storage calls recorded in the trace of the given context
+func (s *ProvisionTargetRuntimeStep) RunWithContext(ctx context.Context, operation internal.PlanMigrationOperation, log logrus.FieldLogger) (internal.PlanMigrationOperation, time.Duration, error) {
+	step := *s
+	step.operationManager = s.operationManager.WithContext(ctx)
+	return step.run(operation, log)
+}
+
+func (s *ProvisionTargetRuntimeStep) run(operation internal.PlanMigrationOperation, log logrus.FieldLogger) (internal.PlanMigrationOperation, time.Duration, error) {
+	pp, err := operation.GetProvisioningParameters()
+	if err != nil {
+		log.Errorf("cannot fetch provisioning parameters from operation: %s", err)
+		return s.operationManager.OperationFailed(operation, "invalid operation provisioning parameters")
+	}
+
+	// the initialisation step lets the migration continue only when the provisioning succeeded
+	if operation.ProvisioningOperationID != "" {
+		provisioning, err := s.operationStorage.GetProvisioningOperationByID(operation.ProvisioningOperationID)
+		if err == nil && provisioning.State == domain.Succeeded {
+			return operation, 0, nil
+		}
+	}
+
+	// the ID is stored before the provisioning operation is created, so the step can be repeated safely
+	if operation.ProvisioningOperationID == "" {
+		operation.ProvisioningOperationID = uuid.New().String()
+		var repeat time.Duration
+		operation, repeat = s.operationManager.UpdateOperation(operation)
+		if repeat != 0 {
+			log.Errorf("cannot save provisioning operation ID")
+			return operation, repeat, nil
+		}
+	}
+	log = log.WithField("provisioningOperationID", operation.ProvisioningOperationID)
+
+	instance, err := s.instanceStorage.GetByID(operation.InstanceID)
+	if err != nil {
+		log.Errorf("unable to get instance from storage: %s", err)
+		return operation, 10 * time.Second, nil
+	}
+	// the runtime data of the source plan is cleared, the provisioning sets it again for the new runtime
+	instance.ServicePlanID = pp.PlanID
+	instance.ServicePlanName = broker.Plans[pp.PlanID].PlanDefinition.Name
+	instance.ProvisioningParameters = operation.ProvisioningParameters
+	instance.RuntimeID = ""
+	instance.DashboardURL = ""
+	instance.APIServerURL = ""
+	instance.CABundle = ""
+	instance.ShootName = ""
+	err = s.instanceStorage.Update(*instance)
+	if err != nil {
+		log.Errorf("unable to update instance in storage: %s", err)
+		return operation, 10 * time.Second, nil
+	}
+
+	provisioning, err := internal.NewProvisioningOperationWithID(operation.ProvisioningOperationID, operation.InstanceID, pp)
+	if err != nil {
+		log.Errorf("cannot create provisioning operation: %s", err)
+		return s.operationManager.OperationFailed(operation, "cannot create provisioning operation")
+	}
+	provisioning.KeepRegistrations = true
+	// the evaluations of the source runtime are kept in the operation which provisioned it, the AVS steps
+	// of the provisioning do not create new evaluations when the operation already holds them
+	source, err := s.operationStorage.GetProvisioningOperationByID(operation.SourceProvisioningOperationID)
+	switch {
+	case err == nil:
+		provisioning.Avs = source.Avs
+	case !dberr.IsNotFound(errors.Cause(err)):
+		log.Errorf("unable to get provisioning operation %s from storage: %s", operation.SourceProvisioningOperationID, err)
+		return operation, 10 * time.Second, nil
+	}
+	_, err = s.operationStorage.GetProvisioningOperationByID(provisioning.ID)
+	switch {
+	case err == nil:
+		log.Info("provisioning operation already exists")
+	case dberr.IsNotFound(errors.Cause(err)):
+		err = s.operationStorage.InsertProvisioningOperation(provisioning)
+		if err != nil {
+			log.Errorf("cannot save provisioning operation: %s", err)
+			return operation, 10 * time.Second, nil
+		}
+	default:
+		log.Errorf("cannot get provisioning operation from storage: %s", err)
+		return operation, 10 * time.Second, nil
+	}
+
+	log.Info("provisioning of the runtime of the target plan started")
+	s.provisionQueue.Add(provisioning.ID)
+
+	// the initialisation step waits for the provisioning
+	return operation, 1 * time.Minute, nil
+}
@@ -0,0 +1,99 @@
+package migrate_plan
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/broker"
+	brokerAutomock "github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/broker/automock"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvisionTargetRuntimeStep_Run(t *testing.T) {
+	// given
+	memoryStorage := storage.NewMemoryStorage()
+	err := memoryStorage.Instances().Insert(fixInstance())
+	require.NoError(t, err)
+	err = memoryStorage.Operations().InsertProvisioningOperation(internal.ProvisioningOperation{
+		Operation: internal.Operation{
+			ID:         fixSourceProvisioningOperationID,
+			InstanceID: fixInstanceID,
+			State:      domain.Succeeded,
+		},
+		RuntimeID: fixRuntimeID,
+		Avs: internal.AvsLifecycleData{
+			AvsEvaluationInternalId: 111,
+			AVSEvaluationExternalId: 222,
+		},
+	})
+	require.NoError(t, err)
+
+	operation := fixPlanMigrationOperation(t)
+	err = memoryStorage.Operations().InsertPlanMigrationOperation(operation)
+	require.NoError(t, err)
+
+	queue := &brokerAutomock.Queue{}
+	queue.On("Add", mock.AnythingOfType("string")).Return().Once()
+	defer queue.AssertExpectations(t)
+
+	step := NewProvisionTargetRuntimeStep(memoryStorage.Operations(), memoryStorage.Instances(), queue)
+
+	// when
+	result, repeat, err := step.Run(operation, logrus.New())
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, repeat)
+	require.NotEmpty(t, result.ProvisioningOperationID)
+	queue.AssertCalled(t, "Add", result.ProvisioningOperationID)
+
+	provisioning, err := memoryStorage.Operations().GetProvisioningOperationByID(result.ProvisioningOperationID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.InProgress, provisioning.State)
+	assert.Equal(t, fixInstanceID, provisioning.InstanceID)
+	pp, err := provisioning.GetProvisioningParameters()
+	require.NoError(t, err)
+	assert.Equal(t, broker.AzurePlanID, pp.PlanID)
+	assert.True(t, provisioning.KeepRegistrations)
+	assert.Equal(t, int64(111), provisioning.Avs.AvsEvaluationInternalId)
+	assert.Equal(t, int64(222), provisioning.Avs.AVSEvaluationExternalId)
+
+	instance, err := memoryStorage.Instances().GetByID(fixInstanceID)
+	require.NoError(t, err)
+	assert.Equal(t, broker.AzurePlanID, instance.ServicePlanID)
+	assert.Equal(t, broker.AzurePlanName, instance.ServicePlanName)
+	assert.Empty(t, instance.RuntimeID)
+	assert.Empty(t, instance.DashboardURL)
+}
+
+func TestProvisionTargetRuntimeStep_RunWhenProvisioned(t *testing.T) {
+	// given
+	memoryStorage := storage.NewMemoryStorage()
+	operation := fixMigrationWithProvisioning(t, memoryStorage, domain.Succeeded, fixTargetRuntimeID)
+	err := memoryStorage.Instances().Insert(fixTargetInstance())
+	require.NoError(t, err)
+
+	queue := &brokerAutomock.Queue{}
+	defer queue.AssertExpectations(t)
+
+	step := NewProvisionTargetRuntimeStep(memoryStorage.Operations(), memoryStorage.Instances(), queue)
+
+	// when
+	result, repeat, err := step.Run(operation, logrus.New())
+
+	// then
+	require.NoError(t, err)
+	assert.Zero(t, repeat)
+	assert.Equal(t, fixProvisioningOperationID, result.ProvisioningOperationID)
+	queue.AssertNotCalled(t, "Add", mock.Anything)
+
+	instance, err := memoryStorage.Instances().GetByID(fixInstanceID)
+	require.NoError(t, err)
+	assert.Equal(t, fixTargetRuntimeID, instance.RuntimeID)
+}
@@ -0,0 +1,115 @@
+package migrate_plan
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/provisioner"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/provisioner/pkg/gqlschema"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RemoveSourceRuntimeStep deprovisions the runtime of the source plan and waits until it is removed
+type RemoveSourceRuntimeStep struct {
+	operationManager  *process.PlanMigrationOperationManager
+	instanceStorage   storage.Instances
+	provisionerClient provisioner.Client
+}
+
+func NewRemoveSourceRuntimeStep(os storage.Operations, is storage.Instances, cli provisioner.Client) *RemoveSourceRuntimeStep {
+	return &RemoveSourceRuntimeStep{
+		operationManager:  process.NewPlanMigrationOperationManager(os),
+		instanceStorage:   is,
+		provisionerClient: cli,
+	}
+}
+
+func (s *RemoveSourceRuntimeStep) Name() string {
+	return "Remove_Source_Runtime"
+}
+
+func (s *RemoveSourceRuntimeStep) Run(operation internal.PlanMigrationOperation, log logrus.FieldLogger) (internal.PlanMigrationOperation, time.Duration, error) {
+	return s.RunWithContext(context.Background(), operation, log)
+}
+
+// RunWithContext runs the step with the Provisioner calls sent with the given context
+func (s *RemoveSourceRuntimeStep) RunWithContext(ctx context.Context, operation internal.PlanMigrationOperation, log logrus.FieldLogger) (internal.PlanMigrationOperation, time.Duration, error) {
+	step := *s
+	step.operationManager = s.operationManager.WithContext(ctx)
+	step.provisionerClient = provisioner.WithContext(s.provisionerClient, ctx)
+	return step.run(operation, log)
+}
+
+func (s *RemoveSourceRuntimeStep) run(operation internal.PlanMigrationOperation, log logrus.FieldLogger) (internal.PlanMigrationOperation, time.Duration, error) {
+	if operation.SourceRuntimeRemoved {
+		return operation, 0, nil
+	}
+	if operation.SourceRuntimeID == "" {
+		log.Warnf("Runtime does not exist for instance id %q", operation.InstanceID)
+		return s.markSourceRuntimeRemoved(operation, log)
+	}
+	log = log.WithField("runtimeID", operation.SourceRuntimeID)
+
+	instance, err := s.instanceStorage.GetByID(operation.InstanceID)
+	if err != nil {
+		log.Errorf("unable to get instance from storage: %s", err)
+		return operation, 10 * time.Second, nil
+	}
+
+	if operation.ProvisionerOperationID == "" {
+		provisionerResponse, err := s.provisionerClient.DeprovisionRuntime(instance.Tenant(), operation.SourceRuntimeID)
+		if err != nil {
+			log.Errorf("unable to deprovision runtime: %s", err)
+			return operation, 10 * time.Second, nil
+		}
+		operation.ProvisionerOperationID = provisionerResponse
+		log.Infof("fetched ProvisionerOperationID=%s", provisionerResponse)
+
+		var repeat time.Duration
+		operation, repeat = s.operationManager.UpdateOperation(operation)
+		if repeat != 0 {
+			log.Errorf("cannot save operation ID from provisioner")
+			return operation, 5 * time.Second, nil
+		}
+		return operation, 1 * time.Minute, nil
+	}
+
+	status, err := s.provisionerClient.RuntimeOperationStatus(instance.Tenant(), operation.ProvisionerOperationID)
+	if err != nil {
+		log.Errorf("call to provisioner RuntimeOperationStatus failed: %s", err)
+		return operation, 1 * time.Minute, nil
+	}
+	log.Infof("call to provisioner returned %s status", status.State.String())
+
+	var msg string
+	if status.Message != nil {
+		msg = *status.Message
+	}
+
+	switch status.State {
+	case gqlschema.OperationStateSucceeded:
+		return s.markSourceRuntimeRemoved(operation, log)
+	case gqlschema.OperationStateInProgress, gqlschema.OperationStatePending:
+		return operation, 1 * time.Minute, nil
+	case gqlschema.OperationStateFailed:
+		return s.operationManager.OperationFailed(operation, fmt.Sprintf("removal of the runtime of the source plan failed: %s", msg))
+	}
+
+	return s.operationManager.OperationFailed(operation, fmt.Sprintf("unsupported provisioner client status: %s", status.State.String()))
+}
+
+func (s *RemoveSourceRuntimeStep) markSourceRuntimeRemoved(operation internal.PlanMigrationOperation, log logrus.FieldLogger) (internal.PlanMigrationOperation, time.Duration, error) {
+	operation.SourceRuntimeRemoved = true
+	operation, repeat := s.operationManager.UpdateOperation(operation)
+	if repeat != 0 {
+		log.Errorf("cannot save the removal of the source runtime")
+		return operation, repeat, nil
+	}
+
+	return operation, 0, nil
+}
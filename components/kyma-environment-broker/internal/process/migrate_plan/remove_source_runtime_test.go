@@ -0,0 +1,105 @@
+package migrate_plan
+
+import (
+	"testing"
+	"time"
+
+	provisionerAutomock "github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/provisioner/automock"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/ptr"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/provisioner/pkg/gqlschema"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoveSourceRuntimeStep_Run(t *testing.T) {
+	t.Run("should trigger removal of the source runtime", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		err := memoryStorage.Instances().Insert(fixInstance())
+		require.NoError(t, err)
+
+		operation := fixPlanMigrationOperation(t)
+		err = memoryStorage.Operations().InsertPlanMigrationOperation(operation)
+		require.NoError(t, err)
+
+		provisionerClient := &provisionerAutomock.Client{}
+		provisionerClient.On("DeprovisionRuntime", fixGlobalAccountID, fixRuntimeID).Return(fixProvisionerOperationID, nil).Once()
+		defer provisionerClient.AssertExpectations(t)
+
+		step := NewRemoveSourceRuntimeStep(memoryStorage.Operations(), memoryStorage.Instances(), provisionerClient)
+
+		// when
+		result, repeat, err := step.Run(operation, logrus.New())
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, time.Minute, repeat)
+		assert.Equal(t, fixProvisionerOperationID, result.ProvisionerOperationID)
+		assert.False(t, result.SourceRuntimeRemoved)
+	})
+
+	t.Run("should mark the source runtime as removed when deprovisioning succeeded", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		err := memoryStorage.Instances().Insert(fixInstance())
+		require.NoError(t, err)
+
+		operation := fixPlanMigrationOperation(t)
+		operation.ProvisionerOperationID = fixProvisionerOperationID
+		err = memoryStorage.Operations().InsertPlanMigrationOperation(operation)
+		require.NoError(t, err)
+
+		provisionerClient := &provisionerAutomock.Client{}
+		provisionerClient.On("RuntimeOperationStatus", fixGlobalAccountID, fixProvisionerOperationID).Return(gqlschema.OperationStatus{
+			ID:    ptr.String(fixProvisionerOperationID),
+			State: gqlschema.OperationStateSucceeded,
+		}, nil).Once()
+		defer provisionerClient.AssertExpectations(t)
+
+		step := NewRemoveSourceRuntimeStep(memoryStorage.Operations(), memoryStorage.Instances(), provisionerClient)
+
+		// when
+		result, repeat, err := step.Run(operation, logrus.New())
+
+		// then
+		require.NoError(t, err)
+		assert.Zero(t, repeat)
+		assert.True(t, result.SourceRuntimeRemoved)
+
+		stored, err := memoryStorage.Operations().GetPlanMigrationOperationByID(operation.ID)
+		require.NoError(t, err)
+		assert.True(t, stored.SourceRuntimeRemoved)
+	})
+
+	t.Run("should fail operation when deprovisioning failed", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		err := memoryStorage.Instances().Insert(fixInstance())
+		require.NoError(t, err)
+
+		operation := fixPlanMigrationOperation(t)
+		operation.ProvisionerOperationID = fixProvisionerOperationID
+		err = memoryStorage.Operations().InsertPlanMigrationOperation(operation)
+		require.NoError(t, err)
+
+		provisionerClient := &provisionerAutomock.Client{}
+		provisionerClient.On("RuntimeOperationStatus", fixGlobalAccountID, fixProvisionerOperationID).Return(gqlschema.OperationStatus{
+			ID:      ptr.String(fixProvisionerOperationID),
+			State:   gqlschema.OperationStateFailed,
+			Message: ptr.String("boom"),
+		}, nil).Once()
+		defer provisionerClient.AssertExpectations(t)
+
+		step := NewRemoveSourceRuntimeStep(memoryStorage.Operations(), memoryStorage.Instances(), provisionerClient)
+
+		// when
+		result, _, err := step.Run(operation, logrus.New())
+
+		// then
+		assert.Error(t, err)
+		assert.Equal(t, domain.Failed, result.State)
+	})
+}
@@ -1,6 +1,7 @@
 package process
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -15,10 +16,18 @@ import (
 
 type ProvisionOperationManager struct {
 	storage storage.Provisioning
+	ctx     context.Context
 }
 
 func NewProvisionOperationManager(storage storage.Operations) *ProvisionOperationManager {
-	return &ProvisionOperationManager{storage: storage}
+	return &ProvisionOperationManager{storage: storage, ctx: context.Background()}
+}
+
+// WithContext returns the copy of the manager which records the storage calls in the trace of the given context
+func (om *ProvisionOperationManager) WithContext(ctx context.Context) *ProvisionOperationManager {
+	withContext := *om
+	withContext.ctx = ctx
+	return &withContext
 }
 
 // OperationSucceeded marks the operation as succeeded and only repeats it if there is a storage error
@@ -45,7 +54,7 @@ func (om *ProvisionOperationManager) OperationFailed(operation internal.Provisio
 
 // UpdateOperation updates a given operation
 func (om *ProvisionOperationManager) UpdateOperation(operation internal.ProvisioningOperation) (internal.ProvisioningOperation, time.Duration) {
-	updatedOperation, err := om.storage.UpdateProvisioningOperation(operation)
+	updatedOperation, err := om.store(operation)
 	if err != nil {
 		return operation, 1 * time.Minute
 	}
@@ -76,3 +85,18 @@ func (om *ProvisionOperationManager) update(operation internal.ProvisioningOpera
 
 	return om.UpdateOperation(operation)
 }
+
+// store updates the operation within the span of the storage call, see storeOperation for the conflict handling
+func (om *ProvisionOperationManager) store(operation internal.ProvisioningOperation) (updatedOperation *internal.ProvisioningOperation, err error) {
+	err = storeOperation(om.ctx, "storage/UpdateProvisioningOperation", func() error {
+		updatedOperation, err = om.storage.UpdateProvisioningOperation(operation)
+		return err
+	}, func() (*internal.Operation, error) {
+		updatedOperation, err = om.storage.GetProvisioningOperationByID(operation.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &updatedOperation.Operation, nil
+	})
+	return updatedOperation, err
+}
@@ -1,6 +1,7 @@
 package process
 
 import (
+	"context"
 	"fmt"
 	"testing"
 	"time"
@@ -10,6 +11,7 @@ import (
 
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
 )
 
 func Test_Provision_RetryOperationOnce(t *testing.T) {
@@ -76,3 +78,80 @@ func Test_Provision_RetryOperation(t *testing.T) {
 	assert.True(t, when > 0)
 	assert.Nil(t, err)
 }
+
+func Test_Provision_UpdateOperationKeepsConcurrentChanges(t *testing.T) {
+	// given
+	memory := storage.NewMemoryStorage()
+	operations := memory.Operations()
+	opManager := NewProvisionOperationManager(operations)
+	op := internal.ProvisioningOperation{Operation: internal.Operation{ID: "operation-id", State: domain.InProgress}}
+
+	err := operations.InsertProvisioningOperation(op)
+	require.NoError(t, err)
+	changed, err := operations.GetProvisioningOperationByID(op.ID)
+	require.NoError(t, err)
+	changed.Description = "changed in the meantime"
+	_, err = operations.UpdateProvisioningOperation(*changed)
+	require.NoError(t, err)
+
+	// when
+	op.RuntimeID = "runtime-id"
+	_, when := opManager.UpdateOperation(op)
+
+	// then
+	assert.True(t, when > 0)
+	stored, err := operations.GetProvisioningOperationByID(op.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "changed in the meantime", stored.Description)
+	assert.Empty(t, stored.RuntimeID)
+	assert.Equal(t, 1, stored.Version)
+}
+
+func Test_Provision_UpdateOperationCountsConflicts(t *testing.T) {
+	// given
+	memory := storage.NewMemoryStorage()
+	operations := memory.Operations()
+	ctx := WithConflictCounter(context.Background())
+	opManager := NewProvisionOperationManager(operations).WithContext(ctx)
+	op := internal.ProvisioningOperation{Operation: internal.Operation{ID: "operation-id", State: domain.InProgress}}
+
+	err := operations.InsertProvisioningOperation(op)
+	require.NoError(t, err)
+	latest, when := opManager.UpdateOperation(op)
+	require.Zero(t, when)
+
+	// when
+	_, outdatedWhen := opManager.UpdateOperation(op)
+	_, latestWhen := opManager.UpdateOperation(latest)
+
+	// then
+	assert.True(t, outdatedWhen > 0)
+	assert.Zero(t, latestWhen)
+	assert.Equal(t, 1, Conflicts(ctx))
+	assert.Zero(t, Conflicts(context.Background()))
+}
+
+func Test_Provision_UpdateOperationFinishedInTheMeantime(t *testing.T) {
+	// given
+	memory := storage.NewMemoryStorage()
+	operations := memory.Operations()
+	opManager := NewProvisionOperationManager(operations)
+	op := internal.ProvisioningOperation{Operation: internal.Operation{ID: "operation-id", State: domain.InProgress}}
+
+	err := operations.InsertProvisioningOperation(op)
+	require.NoError(t, err)
+	canceled, err := operations.GetProvisioningOperationByID(op.ID)
+	require.NoError(t, err)
+	canceled.State = internal.OperationCanceled
+	_, err = operations.UpdateProvisioningOperation(*canceled)
+	require.NoError(t, err)
+
+	// when
+	op.RuntimeID = "runtime-id"
+	op, when := opManager.UpdateOperation(op)
+
+	// then
+	assert.Zero(t, when)
+	assert.Equal(t, internal.OperationCanceled, op.State)
+	assert.Empty(t, op.RuntimeID)
+}
@@ -1,6 +1,7 @@
 package provisioning
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net"
@@ -39,6 +40,17 @@ func NewAuditLogOverridesStep(os storage.Operations, cfg auditlog.Config) *Audit
 }
 
 func (alo *AuditLogOverrides) Run(operation internal.ProvisioningOperation, logger logrus.FieldLogger) (internal.ProvisioningOperation, time.Duration, error) {
+	return alo.RunWithContext(context.Background(), operation, logger)
+}
+
+// RunWithContext runs the step with the storage calls recorded in the trace of the given context
+func (alo *AuditLogOverrides) RunWithContext(ctx context.Context, operation internal.ProvisioningOperation, logger logrus.FieldLogger) (internal.ProvisioningOperation, time.Duration, error) {
+	step := *alo
+	step.operationManager = alo.operationManager.WithContext(ctx)
+	return step.run(operation, logger)
+}
+
+func (alo *AuditLogOverrides) run(operation internal.ProvisioningOperation, logger logrus.FieldLogger) (internal.ProvisioningOperation, time.Duration, error) {
 
 	// Fetch the region
 	pp, err := operation.GetProvisioningParameters()
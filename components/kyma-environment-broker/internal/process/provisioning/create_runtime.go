@@ -1,6 +1,7 @@
 package provisioning
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -28,14 +29,16 @@ type CreateRuntimeStep struct {
 	instanceStorage     storage.Instances
 	runtimeStateStorage storage.RuntimeStates
 	provisionerClient   provisioner.Client
+	directorClient      DirectorClient
 }
 
-func NewCreateRuntimeStep(os storage.Operations, runtimeStorage storage.RuntimeStates, is storage.Instances, cli provisioner.Client) *CreateRuntimeStep {
+func NewCreateRuntimeStep(os storage.Operations, runtimeStorage storage.RuntimeStates, is storage.Instances, cli provisioner.Client, dc DirectorClient) *CreateRuntimeStep {
 	return &CreateRuntimeStep{
 		operationManager:    process.NewProvisionOperationManager(os),
 		instanceStorage:     is,
 		provisionerClient:   cli,
 		runtimeStateStorage: runtimeStorage,
+		directorClient:      dc,
 	}
 }
 
@@ -44,6 +47,19 @@ func (s *CreateRuntimeStep) Name() string {
 }
 
 func (s *CreateRuntimeStep) Run(operation internal.ProvisioningOperation, log logrus.FieldLogger) (internal.ProvisioningOperation, time.Duration, error) {
+	return s.RunWithContext(context.Background(), operation, log)
+}
+
+// RunWithContext runs the step with the Provisioner and Director calls sent with the given context
+func (s *CreateRuntimeStep) RunWithContext(ctx context.Context, operation internal.ProvisioningOperation, log logrus.FieldLogger) (internal.ProvisioningOperation, time.Duration, error) {
+	step := *s
+	step.operationManager = s.operationManager.WithContext(ctx)
+	step.provisionerClient = provisioner.WithContext(s.provisionerClient, ctx)
+	step.directorClient = directorClientWithContext(s.directorClient, ctx)
+	return step.run(operation, log)
+}
+
+func (s *CreateRuntimeStep) run(operation internal.ProvisioningOperation, log logrus.FieldLogger) (internal.ProvisioningOperation, time.Duration, error) {
 	if time.Since(operation.UpdatedAt) > CreateRuntimeTimeout {
 		log.Infof("operation has reached the time limit: updated operation time: %s", operation.UpdatedAt)
 		return s.operationManager.OperationFailed(operation, fmt.Sprintf("operation has reached the time limit: %s", CreateRuntimeTimeout))
@@ -62,9 +78,41 @@ func (s *CreateRuntimeStep) Run(operation internal.ProvisioningOperation, log lo
 	}
 
 	var provisionerResponse gqlschema.OperationStatus
+	var repeat time.Duration
+	if operation.ProvisionerOperationID == "" && operation.RuntimeResolution == internal.RuntimeSubmitted {
+		// the previous call could fail after the Provisioner had already stored the runtime
+		existingOperation, found, err := s.existingRuntimeOperation(operation, pp)
+		if err != nil {
+			log.Errorf("unable to check if the runtime already exists: %s", err)
+			return operation, 10 * time.Second, nil
+		}
+		if found {
+			log.Infof("runtime already exists in the provisioner, adopting operation %q", *existingOperation.ID)
+			provisionerResponse = existingOperation
+			operation.ProvisionerOperationID = *existingOperation.ID
+			operation.RuntimeID = *existingOperation.RuntimeID
+			operation.RuntimeResolution = internal.RuntimeAdopted
+			operation, repeat = s.operationManager.UpdateOperation(operation)
+			if repeat != 0 {
+				log.Errorf("cannot save operation ID from provisioner")
+				return operation, 5 * time.Second, nil
+			}
+		}
+	}
+
 	if operation.ProvisionerOperationID == "" {
+		if operation.RuntimeResolution == "" {
+			// persist the submission before calling the Provisioner, so the retried step looks for the runtime first
+			operation.RuntimeResolution = internal.RuntimeSubmitted
+			operation, repeat = s.operationManager.UpdateOperation(operation)
+			if repeat != 0 {
+				log.Errorf("cannot save runtime resolution")
+				return operation, 5 * time.Second, nil
+			}
+		}
+
 		log.Infof("call ProvisionRuntime: kymaVersion=%s, kubernetesVersion=%s", requestInput.KymaConfig.Version, requestInput.ClusterConfig.GardenerConfig.KubernetesVersion)
-		provisionerResponse, err := s.provisionerClient.ProvisionRuntime(pp.ErsContext.GlobalAccountID, pp.ErsContext.SubAccountID, requestInput)
+		provisionerResponse, err = s.provisionerClient.ProvisionRuntime(pp.ErsContext.GlobalAccountID, pp.ErsContext.SubAccountID, requestInput)
 		switch {
 		case kebError.IsTemporaryError(err):
 			log.Errorf("call to provisioner failed (temporary error): %s", err)
@@ -78,7 +126,8 @@ func (s *CreateRuntimeStep) Run(operation internal.ProvisioningOperation, log lo
 		if provisionerResponse.RuntimeID != nil {
 			operation.RuntimeID = *provisionerResponse.RuntimeID
 		}
-		operation, repeat := s.operationManager.UpdateOperation(operation)
+		operation.RuntimeResolution = internal.RuntimeCreated
+		operation, repeat = s.operationManager.UpdateOperation(operation)
 		if repeat != 0 {
 			log.Errorf("cannot save operation ID from provisioner")
 			return operation, 5 * time.Second, nil
@@ -125,6 +174,30 @@ func (s *CreateRuntimeStep) Run(operation internal.ProvisioningOperation, log lo
 	return operation, 1 * time.Second, nil
 }
 
+// existingRuntimeOperation looks for the runtime registered in Director for the instance and returns
+// the last Provisioner operation of that runtime
+func (s *CreateRuntimeStep) existingRuntimeOperation(operation internal.ProvisioningOperation, pp internal.ProvisioningParameters) (gqlschema.OperationStatus, bool, error) {
+	runtimeID, err := s.directorClient.GetRuntimeID(pp.ErsContext.GlobalAccountID, operation.InstanceID)
+	switch {
+	case kebError.IsNotFoundError(err):
+		return gqlschema.OperationStatus{}, false, nil
+	case err != nil:
+		return gqlschema.OperationStatus{}, false, errors.Wrap(err, "while fetching runtime ID from director")
+	}
+
+	status, err := s.provisionerClient.RuntimeStatus(pp.ErsContext.GlobalAccountID, runtimeID)
+	if err != nil {
+		return gqlschema.OperationStatus{}, false, errors.Wrapf(err, "while fetching status of runtime %s", runtimeID)
+	}
+	if status.LastOperationStatus == nil || status.LastOperationStatus.ID == nil {
+		return gqlschema.OperationStatus{}, false, errors.Errorf("runtime %s has no provisioner operation", runtimeID)
+	}
+
+	lastOperation := *status.LastOperationStatus
+	lastOperation.RuntimeID = &runtimeID
+	return lastOperation, true, nil
+}
+
 func (s *CreateRuntimeStep) createProvisionInput(operation internal.ProvisioningOperation, parameters internal.ProvisioningParameters) (gqlschema.ProvisionRuntimeInput, error) {
 	var request gqlschema.ProvisionRuntimeInput
 
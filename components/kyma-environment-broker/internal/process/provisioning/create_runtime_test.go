@@ -8,8 +8,10 @@ import (
 
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/broker"
+	kebError "github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/error"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process/input"
 	inputAutomock "github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process/input/automock"
+	provisioningAutomock "github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process/provisioning/automock"
 	provisionerAutomock "github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/provisioner/automock"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/ptr"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/runtime"
@@ -115,7 +117,7 @@ func TestCreateRuntimeStep_Run(t *testing.T) {
 		RuntimeID: ptr.String(runtimeID),
 	}, nil)
 
-	step := NewCreateRuntimeStep(memoryStorage.Operations(), memoryStorage.RuntimeStates(), memoryStorage.Instances(), provisionerClient)
+	step := NewCreateRuntimeStep(memoryStorage.Operations(), memoryStorage.RuntimeStates(), memoryStorage.Instances(), provisionerClient, &provisioningAutomock.DirectorClient{})
 
 	// when
 	entry := log.WithFields(logrus.Fields{"step": "TEST"})
@@ -125,6 +127,7 @@ func TestCreateRuntimeStep_Run(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 1*time.Second, repeat)
 	assert.Equal(t, provisionerOperationID, operation.ProvisionerOperationID)
+	assert.Equal(t, internal.RuntimeCreated, operation.RuntimeResolution)
 
 	instance, err := memoryStorage.Instances().GetByID(operation.InstanceID)
 	assert.NoError(t, err)
@@ -146,7 +149,7 @@ func TestCreateRuntimeStep_RunWithBadRequestError(t *testing.T) {
 	provisionerClient := &provisionerAutomock.Client{}
 	provisionerClient.On("ProvisionRuntime", globalAccountID, subAccountID, mock.Anything).Return(gqlschema.OperationStatus{}, fmt.Errorf("some permanent error"))
 
-	step := NewCreateRuntimeStep(memoryStorage.Operations(), memoryStorage.RuntimeStates(), memoryStorage.Instances(), provisionerClient)
+	step := NewCreateRuntimeStep(memoryStorage.Operations(), memoryStorage.RuntimeStates(), memoryStorage.Instances(), provisionerClient, &provisioningAutomock.DirectorClient{})
 
 	// when
 	entry := log.WithFields(logrus.Fields{"step": "TEST"})
@@ -157,6 +160,118 @@ func TestCreateRuntimeStep_RunWithBadRequestError(t *testing.T) {
 
 }
 
+func TestCreateRuntimeStep_RunWithTemporaryErrorMarksSubmission(t *testing.T) {
+	// given
+	log := logrus.New()
+	memoryStorage := storage.NewMemoryStorage()
+
+	operation := fixOperationCreateRuntime(t)
+	err := memoryStorage.Operations().InsertProvisioningOperation(operation)
+	assert.NoError(t, err)
+
+	provisionerClient := &provisionerAutomock.Client{}
+	provisionerClient.On("ProvisionRuntime", globalAccountID, subAccountID, mock.Anything).Return(gqlschema.OperationStatus{}, kebError.NewTemporaryError("timeout"))
+
+	step := NewCreateRuntimeStep(memoryStorage.Operations(), memoryStorage.RuntimeStates(), memoryStorage.Instances(), provisionerClient, &provisioningAutomock.DirectorClient{})
+
+	// when
+	entry := log.WithFields(logrus.Fields{"step": "TEST"})
+	operation, repeat, err := step.Run(operation, entry)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Second, repeat)
+	assert.Equal(t, internal.RuntimeSubmitted, operation.RuntimeResolution)
+
+	storedOperation, err := memoryStorage.Operations().GetProvisioningOperationByID(operation.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, internal.RuntimeSubmitted, storedOperation.RuntimeResolution)
+	assert.Empty(t, storedOperation.ProvisionerOperationID)
+}
+
+func TestCreateRuntimeStep_RunAdoptsExistingRuntime(t *testing.T) {
+	// given
+	log := logrus.New()
+	memoryStorage := storage.NewMemoryStorage()
+
+	operation := fixOperationCreateRuntime(t)
+	operation.RuntimeResolution = internal.RuntimeSubmitted
+	err := memoryStorage.Operations().InsertProvisioningOperation(operation)
+	assert.NoError(t, err)
+
+	err = memoryStorage.Instances().Insert(fixInstance())
+	assert.NoError(t, err)
+
+	directorClient := &provisioningAutomock.DirectorClient{}
+	defer directorClient.AssertExpectations(t)
+	directorClient.On("GetRuntimeID", globalAccountID, instanceID).Return(runtimeID, nil)
+
+	provisionerClient := &provisionerAutomock.Client{}
+	defer provisionerClient.AssertExpectations(t)
+	provisionerClient.On("RuntimeStatus", globalAccountID, runtimeID).Return(gqlschema.RuntimeStatus{
+		LastOperationStatus: &gqlschema.OperationStatus{
+			ID:        ptr.String(provisionerOperationID),
+			Operation: gqlschema.OperationTypeProvision,
+			State:     gqlschema.OperationStateInProgress,
+		},
+	}, nil)
+
+	step := NewCreateRuntimeStep(memoryStorage.Operations(), memoryStorage.RuntimeStates(), memoryStorage.Instances(), provisionerClient, directorClient)
+
+	// when
+	entry := log.WithFields(logrus.Fields{"step": "TEST"})
+	operation, repeat, err := step.Run(operation, entry)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, 1*time.Second, repeat)
+	assert.Equal(t, provisionerOperationID, operation.ProvisionerOperationID)
+	assert.Equal(t, runtimeID, operation.RuntimeID)
+	assert.Equal(t, internal.RuntimeAdopted, operation.RuntimeResolution)
+	provisionerClient.AssertNotCalled(t, "ProvisionRuntime", mock.Anything, mock.Anything, mock.Anything)
+
+	instance, err := memoryStorage.Instances().GetByID(operation.InstanceID)
+	assert.NoError(t, err)
+	assert.Equal(t, runtimeID, instance.RuntimeID)
+}
+
+func TestCreateRuntimeStep_RunResubmitsWhenRuntimeNotFound(t *testing.T) {
+	// given
+	log := logrus.New()
+	memoryStorage := storage.NewMemoryStorage()
+
+	operation := fixOperationCreateRuntime(t)
+	operation.RuntimeResolution = internal.RuntimeSubmitted
+	err := memoryStorage.Operations().InsertProvisioningOperation(operation)
+	assert.NoError(t, err)
+
+	err = memoryStorage.Instances().Insert(fixInstance())
+	assert.NoError(t, err)
+
+	directorClient := &provisioningAutomock.DirectorClient{}
+	defer directorClient.AssertExpectations(t)
+	directorClient.On("GetRuntimeID", globalAccountID, instanceID).Return("", kebError.NewNotFoundError("runtime not found"))
+
+	provisionerClient := &provisionerAutomock.Client{}
+	defer provisionerClient.AssertExpectations(t)
+	provisionerClient.On("ProvisionRuntime", globalAccountID, subAccountID, mock.Anything).Return(gqlschema.OperationStatus{
+		ID:        ptr.String(provisionerOperationID),
+		RuntimeID: ptr.String(runtimeID),
+	}, nil)
+
+	step := NewCreateRuntimeStep(memoryStorage.Operations(), memoryStorage.RuntimeStates(), memoryStorage.Instances(), provisionerClient, directorClient)
+
+	// when
+	entry := log.WithFields(logrus.Fields{"step": "TEST"})
+	operation, repeat, err := step.Run(operation, entry)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, 1*time.Second, repeat)
+	assert.Equal(t, provisionerOperationID, operation.ProvisionerOperationID)
+	assert.Equal(t, internal.RuntimeCreated, operation.RuntimeResolution)
+}
+
 func fixOperationCreateRuntime(t *testing.T) internal.ProvisioningOperation {
 	return internal.ProvisioningOperation{
 		Operation: internal.Operation{
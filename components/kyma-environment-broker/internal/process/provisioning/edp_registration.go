@@ -1,6 +1,7 @@
 package provisioning
 
 import (
+	"context"
 	"encoding/base64"
 	"fmt"
 	"strings"
@@ -40,6 +41,22 @@ func (s *EDPRegistrationStep) Name() string {
 }
 
 func (s *EDPRegistrationStep) Run(operation internal.ProvisioningOperation, log logrus.FieldLogger) (internal.ProvisioningOperation, time.Duration, error) {
+	return s.RunWithContext(context.Background(), operation, log)
+}
+
+// RunWithContext runs the step with the EDP calls sent with the given context
+func (s *EDPRegistrationStep) RunWithContext(ctx context.Context, operation internal.ProvisioningOperation, log logrus.FieldLogger) (internal.ProvisioningOperation, time.Duration, error) {
+	step := *s
+	step.operationManager = s.operationManager.WithContext(ctx)
+	step.client = edpClientWithContext(s.client, ctx)
+	return step.run(operation, log)
+}
+
+func (s *EDPRegistrationStep) run(operation internal.ProvisioningOperation, log logrus.FieldLogger) (internal.ProvisioningOperation, time.Duration, error) {
+	if operation.KeepRegistrations {
+		log.Info("the EDP registration of the runtime of the source plan is kept by the plan migration")
+		return operation, 0, nil
+	}
 	parameters, err := operation.GetProvisioningParameters()
 	if err != nil {
 		return s.handleError(operation, err, log, "invalid operation provisioning parameters")
@@ -113,3 +130,10 @@ func (s *EDPRegistrationStep) selectEnvironmentKey(region string, log logrus.Fie
 func (s *EDPRegistrationStep) generateSecret(name, env string) string {
 	return base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s%s", name, env)))
 }
+
+func edpClientWithContext(c EDPClient, ctx context.Context) EDPClient {
+	if cli, ok := c.(*edp.Client); ok {
+		return cli.WithContext(ctx)
+	}
+	return c
+}
@@ -60,6 +60,28 @@ func TestEDPRegistration_Run(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestEDPRegistration_KeepRegistrations(t *testing.T) {
+	// given
+	memoryStorage := storage.NewMemoryStorage()
+	client := &automock.EDPClient{}
+	defer client.AssertExpectations(t)
+
+	step := NewEDPRegistrationStep(memoryStorage.Operations(), client, edp.Config{
+		Environment: edpEnvironment,
+		Required:    true,
+	})
+
+	// when
+	_, repeat, err := step.Run(internal.ProvisioningOperation{
+		ProvisioningParameters: `{"platform_region":"` + edpRegion + `", "ers_context":{"subaccount_id":"` + edpName + `"}}`,
+		KeepRegistrations:      true,
+	}, logger.NewLogDummy())
+
+	// then
+	assert.Equal(t, 0*time.Second, repeat)
+	assert.NoError(t, err)
+}
+
 func TestEDPRegistrationStep_selectEnvironmentKey(t *testing.T) {
 	for name, tc := range map[string]struct {
 		region   string
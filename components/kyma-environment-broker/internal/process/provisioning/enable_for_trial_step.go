@@ -1,6 +1,7 @@
 package provisioning
 
 import (
+	"context"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -30,7 +31,17 @@ func (s *EnableForTrialPlanStep) Name() string {
 	return s.step.Name()
 }
 
+// Timeout returns the timeout of the wrapped step
+func (s *EnableForTrialPlanStep) Timeout() time.Duration {
+	return process.StepTimeout(s.step)
+}
+
 func (s *EnableForTrialPlanStep) Run(operation internal.ProvisioningOperation, log logrus.FieldLogger) (internal.ProvisioningOperation, time.Duration, error) {
+	return s.RunWithContext(context.Background(), operation, log)
+}
+
+// RunWithContext passes the context to the wrapped step
+func (s *EnableForTrialPlanStep) RunWithContext(ctx context.Context, operation internal.ProvisioningOperation, log logrus.FieldLogger) (internal.ProvisioningOperation, time.Duration, error) {
 	pp, err := operation.GetProvisioningParameters()
 	if err != nil {
 		log.Errorf("cannot fetch provisioning parameters from operation: %s", err)
@@ -38,7 +49,7 @@ func (s *EnableForTrialPlanStep) Run(operation internal.ProvisioningOperation, l
 	}
 	if broker.IsTrialPlan(pp.PlanID) {
 		log.Infof("Running step %s", s.Name())
-		return s.step.Run(operation, log)
+		return runWithContext(ctx, s.step, operation, log)
 	}
 
 	return operation, 0, nil
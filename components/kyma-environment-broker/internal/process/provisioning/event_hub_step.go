@@ -57,8 +57,18 @@ func (p *ProvisionAzureEventHubStep) Name() string {
 	return "Provision Azure Event Hubs"
 }
 
-func (p *ProvisionAzureEventHubStep) Run(operation internal.ProvisioningOperation,
-	log logrus.FieldLogger) (internal.ProvisioningOperation, time.Duration, error) {
+func (p *ProvisionAzureEventHubStep) Run(operation internal.ProvisioningOperation, log logrus.FieldLogger) (internal.ProvisioningOperation, time.Duration, error) {
+	return p.RunWithContext(context.Background(), operation, log)
+}
+
+// RunWithContext runs the step with the storage calls recorded in the trace of the given context
+func (p *ProvisionAzureEventHubStep) RunWithContext(ctx context.Context, operation internal.ProvisioningOperation, log logrus.FieldLogger) (internal.ProvisioningOperation, time.Duration, error) {
+	step := *p
+	step.operationManager = p.operationManager.WithContext(ctx)
+	return step.run(operation, log)
+}
+
+func (p *ProvisionAzureEventHubStep) run(operation internal.ProvisioningOperation, log logrus.FieldLogger) (internal.ProvisioningOperation, time.Duration, error) {
 
 	hypType := hyperscaler.Azure
 
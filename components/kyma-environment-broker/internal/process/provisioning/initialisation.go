@@ -1,11 +1,13 @@
 package provisioning
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"strings"
 	"time"
 
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/common/director"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/broker"
 
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
@@ -31,6 +33,7 @@ const (
 type DirectorClient interface {
 	GetConsoleURL(accountID, runtimeID string) (string, error)
 	SetLabel(accountID, runtimeID, key, value string) error
+	GetRuntimeID(accountID, instanceID string) (string, error)
 }
 
 type KymaVersionConfigurator interface {
@@ -47,6 +50,8 @@ type InitialisationStep struct {
 	iasType                 *IASType
 	provisioningTimeout     time.Duration
 	kymaVersionConfigurator KymaVersionConfigurator
+	shootDiagnostics        ShootDiagnostics
+	registryPullSecrets     RegistryPullSecrets
 }
 
 func NewInitialisationStep(os storage.Operations,
@@ -57,7 +62,9 @@ func NewInitialisationStep(os storage.Operations,
 	avsExternalEvalCreator *ExternalEvalCreator,
 	iasType *IASType,
 	timeout time.Duration,
-	configurator KymaVersionConfigurator) *InitialisationStep {
+	configurator KymaVersionConfigurator,
+	diagnostics ShootDiagnostics,
+	pullSecrets RegistryPullSecrets) *InitialisationStep {
 	return &InitialisationStep{
 		operationManager:        process.NewProvisionOperationManager(os),
 		instanceStorage:         is,
@@ -68,6 +75,8 @@ func NewInitialisationStep(os storage.Operations,
 		iasType:                 iasType,
 		provisioningTimeout:     timeout,
 		kymaVersionConfigurator: configurator,
+		shootDiagnostics:        diagnostics,
+		registryPullSecrets:     pullSecrets,
 	}
 }
 
@@ -76,6 +85,19 @@ func (s *InitialisationStep) Name() string {
 }
 
 func (s *InitialisationStep) Run(operation internal.ProvisioningOperation, log logrus.FieldLogger) (internal.ProvisioningOperation, time.Duration, error) {
+	return s.RunWithContext(context.Background(), operation, log)
+}
+
+// RunWithContext runs the step with the Provisioner and Director calls sent with the given context
+func (s *InitialisationStep) RunWithContext(ctx context.Context, operation internal.ProvisioningOperation, log logrus.FieldLogger) (internal.ProvisioningOperation, time.Duration, error) {
+	step := *s
+	step.operationManager = s.operationManager.WithContext(ctx)
+	step.provisionerClient = provisioner.WithContext(s.provisionerClient, ctx)
+	step.directorClient = directorClientWithContext(s.directorClient, ctx)
+	return step.run(operation, log)
+}
+
+func (s *InitialisationStep) run(operation internal.ProvisioningOperation, log logrus.FieldLogger) (internal.ProvisioningOperation, time.Duration, error) {
 	pp, err := operation.GetProvisioningParameters()
 	if err != nil {
 		log.Errorf("cannot fetch provisioning parameters from operation: %s", err)
@@ -154,7 +176,7 @@ func (s *InitialisationStep) configureKymaVersion(pp *internal.ProvisioningParam
 func (s *InitialisationStep) checkRuntimeStatus(operation internal.ProvisioningOperation, log logrus.FieldLogger) (internal.ProvisioningOperation, time.Duration, error) {
 	if time.Since(operation.UpdatedAt) > s.provisioningTimeout {
 		log.Infof("operation has reached the time limit: updated operation time: %s", operation.UpdatedAt)
-		return s.operationManager.OperationFailed(operation, fmt.Sprintf("operation has reached the time limit: %s", s.provisioningTimeout))
+		return s.operationFailed(operation, fmt.Sprintf("operation has reached the time limit: %s", s.provisioningTimeout), log)
 	}
 
 	instance, err := s.instanceStorage.GetByID(operation.InstanceID)
@@ -167,7 +189,7 @@ func (s *InitialisationStep) checkRuntimeStatus(operation internal.ProvisioningO
 		return s.launchPostActions(operation, instance, log, "Operation succeeded")
 	}
 
-	status, err := s.provisionerClient.RuntimeOperationStatus(instance.GlobalAccountID, operation.ProvisionerOperationID)
+	status, err := s.provisionerClient.RuntimeOperationStatus(instance.Tenant(), operation.ProvisionerOperationID)
 	if err != nil {
 		return operation, 1 * time.Minute, nil
 	}
@@ -180,24 +202,89 @@ func (s *InitialisationStep) checkRuntimeStatus(operation internal.ProvisioningO
 
 	switch status.State {
 	case gqlschema.OperationStateSucceeded:
+		if repeat := s.handleRuntimeAccess(instance, log); repeat != 0 {
+			return operation, repeat, nil
+		}
 		repeat, err := s.handleDashboardURL(instance, log)
 		if err != nil || repeat != 0 {
 			return operation, repeat, err
 		}
 		return s.launchPostActions(operation, instance, log, msg)
 	case gqlschema.OperationStateInProgress:
+		s.createRegistryPullSecrets(operation, instance, log)
 		return operation, 2 * time.Minute, nil
 	case gqlschema.OperationStatePending:
 		return operation, 2 * time.Minute, nil
 	case gqlschema.OperationStateFailed:
-		return s.operationManager.OperationFailed(operation, fmt.Sprintf("provisioner client returns failed status: %s", msg))
+		return s.operationFailed(operation, fmt.Sprintf("provisioner client returns failed status: %s", msg), log)
 	}
 
 	return s.operationManager.OperationFailed(operation, fmt.Sprintf("unsupported provisioner client status: %s", status.State.String()))
 }
 
+// createRegistryPullSecrets creates the pull secrets of the private registry mirror in the runtime as soon as
+// the Provisioner exposes the kubeconfig of the created cluster, the Kyma installation retries pulling the images
+// until the secrets exist. The failures are only logged, the secrets are created again in the next check.
+func (s *InitialisationStep) createRegistryPullSecrets(operation internal.ProvisioningOperation, instance *internal.Instance, log logrus.FieldLogger) {
+	if s.registryPullSecrets == nil {
+		return
+	}
+	pp, err := operation.GetProvisioningParameters()
+	if err != nil || pp.Parameters.Registry == nil || len(pp.Parameters.Registry.PullSecrets) == 0 {
+		return
+	}
+
+	status, err := s.provisionerClient.RuntimeStatus(instance.Tenant(), instance.RuntimeID)
+	if err != nil {
+		log.Warnf("cannot get runtime status from provisioner client, registry pull secrets will be created later: %s", err)
+		return
+	}
+	if status.RuntimeConfiguration == nil || status.RuntimeConfiguration.Kubeconfig == nil {
+		log.Info("runtime status does not contain kubeconfig yet, registry pull secrets will be created later")
+		return
+	}
+	if err := s.registryPullSecrets.Create(*status.RuntimeConfiguration.Kubeconfig, *pp.Parameters.Registry); err != nil {
+		log.Warnf("unable to create registry pull secrets in the runtime: %s", err)
+	}
+}
+
+// operationFailed marks the operation as failed, the digest of the Gardener shoot status is stored in the operation
+// and added to the description, so the platform gets the actual cause of the failure
+func (s *InitialisationStep) operationFailed(operation internal.ProvisioningOperation, description string, log logrus.FieldLogger) (internal.ProvisioningOperation, time.Duration, error) {
+	if s.shootDiagnostics != nil && operation.RuntimeID != "" {
+		digest, err := s.shootDigest(operation)
+		if err != nil {
+			log.Warnf("unable to get the status of the shoot: %s", err)
+		}
+		if digest != "" {
+			operation.ShootDigest = digest
+			description = fmt.Sprintf("%s, shoot status: %s", description, digest)
+		}
+	}
+
+	return s.operationManager.OperationFailed(operation, description)
+}
+
+// shootDigest returns the digest of the status of the shoot of the runtime, the name of the shoot is stored
+// in the instance only when the provisioning succeeded, so it is taken from the runtime status of the Provisioner
+func (s *InitialisationStep) shootDigest(operation internal.ProvisioningOperation) (string, error) {
+	instance, err := s.instanceStorage.GetByID(operation.InstanceID)
+	if err != nil {
+		return "", errors.Wrap(err, "while getting instance")
+	}
+	status, err := s.provisionerClient.RuntimeStatus(instance.Tenant(), operation.RuntimeID)
+	if err != nil {
+		return "", errors.Wrap(err, "while getting runtime status")
+	}
+	if status.RuntimeConfiguration == nil || status.RuntimeConfiguration.ClusterConfig == nil || status.RuntimeConfiguration.ClusterConfig.Name == nil {
+		return "", nil
+	}
+
+	return s.shootDiagnostics.Digest(*status.RuntimeConfiguration.ClusterConfig.Name)
+}
+
 func (s *InitialisationStep) handleDashboardURL(instance *internal.Instance, log logrus.FieldLogger) (time.Duration, error) {
-	dashboardURL, err := s.directorClient.GetConsoleURL(instance.GlobalAccountID, instance.RuntimeID)
+	dashboardURL, err := s.directorClient.GetConsoleURL(instance.Tenant(), instance.RuntimeID)
 	if kebError.IsTemporaryError(err) {
 		log.Errorf("cannot get console URL from director client: %s", err)
 		return 3 * time.Minute, nil
@@ -216,6 +303,38 @@ func (s *InitialisationStep) handleDashboardURL(instance *internal.Instance, log
 	return 0, nil
 }
 
+// handleRuntimeAccess sets the shoot name, the API server URL and the CA bundle of the shoot cluster on the instance.
+// The data is not crucial for the provisioning, so the operation is not stopped if it cannot be fetched.
+// The instance is stored together with the dashboard URL.
+func (s *InitialisationStep) handleRuntimeAccess(instance *internal.Instance, log logrus.FieldLogger) time.Duration {
+	status, err := s.provisionerClient.RuntimeStatus(instance.Tenant(), instance.RuntimeID)
+	if kebError.IsTemporaryError(err) {
+		log.Errorf("cannot get runtime status from provisioner client: %s", err)
+		return 1 * time.Minute
+	}
+	if err != nil {
+		log.Errorf("cannot get runtime status from provisioner client, runtime access data will not be set: %s", err)
+		return 0
+	}
+	if status.RuntimeConfiguration != nil && status.RuntimeConfiguration.ClusterConfig != nil && status.RuntimeConfiguration.ClusterConfig.Name != nil {
+		instance.ShootName = *status.RuntimeConfiguration.ClusterConfig.Name
+	}
+	if status.RuntimeConfiguration == nil || status.RuntimeConfiguration.Kubeconfig == nil {
+		log.Warn("runtime status does not contain kubeconfig, runtime access data will not be set")
+		return 0
+	}
+
+	access, err := extractRuntimeAccess(*status.RuntimeConfiguration.Kubeconfig)
+	if err != nil {
+		log.Errorf("cannot extract runtime access data from kubeconfig: %s", err)
+		return 0
+	}
+	instance.APIServerURL = access.APIServerURL
+	instance.CABundle = access.CABundle
+
+	return 0
+}
+
 func (s *InitialisationStep) launchPostActions(operation internal.ProvisioningOperation, instance *internal.Instance, log logrus.FieldLogger, msg string) (internal.ProvisioningOperation, time.Duration, error) {
 	// action #1
 	operation, repeat, err := s.externalEvalCreator.createEval(operation, instance.DashboardURL, log)
@@ -230,7 +349,7 @@ func (s *InitialisationStep) launchPostActions(operation internal.ProvisioningOp
 	}
 	if !s.iasType.Disabled() {
 		grafanaPath := strings.Replace(instance.DashboardURL, "console.", "grafana.", 1)
-		err = s.directorClient.SetLabel(instance.GlobalAccountID, instance.RuntimeID, grafanaURLLabel, grafanaPath)
+		err = s.directorClient.SetLabel(instance.Tenant(), instance.RuntimeID, grafanaURLLabel, grafanaPath)
 		if err != nil {
 			log.Errorf("Cannot set labels in director: %s", err)
 		} else {
@@ -240,3 +359,10 @@ func (s *InitialisationStep) launchPostActions(operation internal.ProvisioningOp
 
 	return s.operationManager.OperationSucceeded(operation, msg)
 }
+
+func directorClientWithContext(dc DirectorClient, ctx context.Context) DirectorClient {
+	if cli, ok := dc.(*director.Client); ok {
+		return cli.WithContext(ctx)
+	}
+	return dc
+}
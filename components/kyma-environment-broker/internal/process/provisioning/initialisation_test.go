@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	gardenerfake "github.com/gardener/gardener/pkg/client/core/clientset/versioned/fake"
 	"github.com/sirupsen/logrus"
 
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/broker"
@@ -21,6 +22,7 @@ import (
 
 	"github.com/pivotal-cf/brokerapi/v7/domain"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -53,6 +55,12 @@ func TestInitialisationStep_RunInitialized(t *testing.T) {
 		Message:   nil,
 		RuntimeID: nil,
 	}, nil)
+	provisionerClient.On("RuntimeStatus", statusGlobalAccountID, statusRuntimeID).Return(gqlschema.RuntimeStatus{
+		RuntimeConfiguration: &gqlschema.RuntimeConfig{
+			ClusterConfig: &gqlschema.GardenerConfig{Name: ptr.String(fixShootName)},
+			Kubeconfig:    ptr.String(fixKubeconfig()),
+		},
+	}, nil)
 
 	directorClient := &automock.DirectorClient{}
 	directorClient.On("GetConsoleURL", statusGlobalAccountID, statusRuntimeID).Return(dashboardURL, nil)
@@ -71,7 +79,7 @@ func TestInitialisationStep_RunInitialized(t *testing.T) {
 	iasType := NewIASType(nil, true)
 
 	step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Instances(), provisionerClient,
-		directorClient, nil, externalEvalCreator, iasType, time.Hour, newInMemoryKymaVersionConfigurator(map[string]string{}))
+		directorClient, nil, externalEvalCreator, iasType, time.Hour, newInMemoryKymaVersionConfigurator(map[string]string{}), nil, nil)
 
 	// when
 	operation, repeat, err := step.Run(operation, logger.NewLogDummy())
@@ -84,6 +92,9 @@ func TestInitialisationStep_RunInitialized(t *testing.T) {
 	updatedInstance, err := memoryStorage.Instances().GetByID(statusInstanceID)
 	assert.NoError(t, err)
 	assert.Equal(t, dashboardURL, updatedInstance.DashboardURL)
+	assert.Equal(t, fixAPIServerURL, updatedInstance.APIServerURL)
+	assert.Equal(t, fixCABundle, updatedInstance.CABundle)
+	assert.Equal(t, fixShootName, updatedInstance.ShootName)
 
 	assert.Equal(t, idh.id, operation.Avs.AVSEvaluationExternalId)
 	inDB, err := memoryStorage.Operations().GetProvisioningOperationByID(operation.ID)
@@ -111,6 +122,11 @@ func TestInitialisationStep_RunUninitialized(t *testing.T) {
 		Message:   nil,
 		RuntimeID: nil,
 	}, nil)
+	provisionerClient.On("RuntimeStatus", statusGlobalAccountID, statusRuntimeID).Return(gqlschema.RuntimeStatus{
+		RuntimeConfiguration: &gqlschema.RuntimeConfig{
+			Kubeconfig: ptr.String(fixKubeconfig()),
+		},
+	}, nil)
 
 	directorClient := &automock.DirectorClient{}
 	directorClient.On("GetConsoleURL", statusGlobalAccountID, statusRuntimeID).Return(dashboardURL, nil)
@@ -129,7 +145,7 @@ func TestInitialisationStep_RunUninitialized(t *testing.T) {
 	iasType := NewIASType(nil, true)
 
 	step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Instances(), provisionerClient,
-		directorClient, nil, externalEvalCreator, iasType, time.Hour, newInMemoryKymaVersionConfigurator(map[string]string{}))
+		directorClient, nil, externalEvalCreator, iasType, time.Hour, newInMemoryKymaVersionConfigurator(map[string]string{}), nil, nil)
 
 	// when
 	operation, repeat, err := step.Run(operation, logger.NewLogDummy())
@@ -142,6 +158,8 @@ func TestInitialisationStep_RunUninitialized(t *testing.T) {
 	updatedInstance, err := memoryStorage.Instances().GetByID(statusInstanceID)
 	assert.NoError(t, err)
 	assert.Equal(t, dashboardURL, updatedInstance.DashboardURL)
+	assert.Equal(t, fixAPIServerURL, updatedInstance.APIServerURL)
+	assert.Equal(t, fixCABundle, updatedInstance.CABundle)
 
 	assert.Equal(t, idh.id, operation.Avs.AVSEvaluationExternalId)
 	inDB, err := memoryStorage.Operations().GetProvisioningOperationByID(operation.ID)
@@ -149,6 +167,101 @@ func TestInitialisationStep_RunUninitialized(t *testing.T) {
 	assert.Equal(t, inDB.Avs.AVSEvaluationExternalId, idh.id)
 }
 
+func TestInitialisationStep_RunFailedWithShootDigest(t *testing.T) {
+	// given
+	memoryStorage := storage.NewMemoryStorage()
+
+	operation := fixOperationRuntimeStatus(t, broker.GCPPlanID)
+	operation.RuntimeID = statusRuntimeID
+	err := memoryStorage.Operations().InsertProvisioningOperation(operation)
+	assert.NoError(t, err)
+
+	instance := fixInstanceRuntimeStatus()
+	err = memoryStorage.Instances().Insert(instance)
+	assert.NoError(t, err)
+
+	provisionerClient := &provisionerAutomock.Client{}
+	provisionerClient.On("RuntimeOperationStatus", statusGlobalAccountID, statusProvisionerOperationID).Return(gqlschema.OperationStatus{
+		ID:        ptr.String(statusProvisionerOperationID),
+		Operation: "",
+		State:     gqlschema.OperationStateFailed,
+		Message:   ptr.String("timeout while waiting for the cluster"),
+		RuntimeID: ptr.String(statusRuntimeID),
+	}, nil)
+
+	provisionerClient.On("RuntimeStatus", statusGlobalAccountID, statusRuntimeID).Return(gqlschema.RuntimeStatus{
+		RuntimeConfiguration: &gqlschema.RuntimeConfig{
+			ClusterConfig: &gqlschema.GardenerConfig{Name: ptr.String("c-1234567")},
+		},
+	}, nil)
+
+	shoot := fixFailedShoot("c-1234567")
+	diagnostics := NewShootDiagnostics(gardenerfake.NewSimpleClientset(&shoot).CoreV1beta1().Shoots("garden-kyma"))
+
+	step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Instances(), provisionerClient,
+		nil, nil, nil, NewIASType(nil, true), time.Hour, newInMemoryKymaVersionConfigurator(map[string]string{}), diagnostics, nil)
+
+	// when
+	operation, repeat, err := step.Run(operation, logger.NewLogDummy())
+
+	// then
+	assert.Error(t, err)
+	assert.Equal(t, time.Duration(0), repeat)
+	assert.Equal(t, domain.Failed, operation.State)
+	assert.Contains(t, operation.Description, "quota exceeded for resource cores")
+
+	inDB, err := memoryStorage.Operations().GetProvisioningOperationByID(operation.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, ShootStatusDigest(shoot), inDB.ShootDigest)
+}
+
+func TestInitialisationStep_RunInProgressCreatesRegistryPullSecrets(t *testing.T) {
+	// given
+	memoryStorage := storage.NewMemoryStorage()
+
+	registry := internal.RegistryDTO{
+		URL:         "registry.example.com:5000/kyma",
+		PullSecrets: []internal.RegistryPullSecretDTO{{Name: "registry-credentials", Username: "kyma", Password: "secret"}},
+	}
+	operation := fixOperationRuntimeStatus(t, broker.GCPPlanID)
+	pp, err := operation.GetProvisioningParameters()
+	require.NoError(t, err)
+	pp.Parameters.Registry = &registry
+	rawParameters, err := json.Marshal(pp)
+	require.NoError(t, err)
+	operation.ProvisioningParameters = string(rawParameters)
+	err = memoryStorage.Operations().InsertProvisioningOperation(operation)
+	assert.NoError(t, err)
+
+	instance := fixInstanceRuntimeStatus()
+	err = memoryStorage.Instances().Insert(instance)
+	assert.NoError(t, err)
+
+	provisionerClient := &provisionerAutomock.Client{}
+	provisionerClient.On("RuntimeOperationStatus", statusGlobalAccountID, statusProvisionerOperationID).Return(gqlschema.OperationStatus{
+		ID:    ptr.String(statusProvisionerOperationID),
+		State: gqlschema.OperationStateInProgress,
+	}, nil)
+	provisionerClient.On("RuntimeStatus", statusGlobalAccountID, statusRuntimeID).Return(gqlschema.RuntimeStatus{
+		RuntimeConfiguration: &gqlschema.RuntimeConfig{
+			Kubeconfig: ptr.String(fixKubeconfig()),
+		},
+	}, nil)
+	pullSecrets := &fakeRegistryPullSecrets{}
+
+	step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Instances(), provisionerClient,
+		nil, nil, nil, NewIASType(nil, true), time.Hour, newInMemoryKymaVersionConfigurator(map[string]string{}), nil, pullSecrets)
+
+	// when
+	_, repeat, err := step.Run(operation, logger.NewLogDummy())
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, 2*time.Minute, repeat)
+	assert.Equal(t, []string{fixKubeconfig()}, pullSecrets.kubeconfigs)
+	assert.Equal(t, []internal.RegistryDTO{registry}, pullSecrets.registries)
+}
+
 func fixOperationRuntimeStatus(t *testing.T, planId string) internal.ProvisioningOperation {
 	return internal.ProvisioningOperation{
 		Operation: internal.Operation{
@@ -224,3 +337,14 @@ type inMemoryKymaVersionConfigurator struct {
 func (c *inMemoryKymaVersionConfigurator) ForGlobalAccount(string) (string, bool, error) {
 	return "", true, nil
 }
+
+type fakeRegistryPullSecrets struct {
+	kubeconfigs []string
+	registries  []internal.RegistryDTO
+}
+
+func (f *fakeRegistryPullSecrets) Create(kubeconfig string, registry internal.RegistryDTO) error {
+	f.kubeconfigs = append(f.kubeconfigs, kubeconfig)
+	f.registries = append(f.registries, registry)
+	return nil
+}
@@ -0,0 +1,66 @@
+package provisioning
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/installeroverrides"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+
+	"github.com/sirupsen/logrus"
+)
+
+// InstallerOverridesStep applies the installer overrides configured for the global account of the instance,
+// the step must run after the OverridesFromSecretsAndConfigStep, so the overrides of the global account take precedence
+type InstallerOverridesStep struct {
+	overrides        storage.InstallerOverrides
+	operationManager *process.ProvisionOperationManager
+}
+
+func NewInstallerOverridesStep(os storage.Operations, overrides storage.InstallerOverrides) *InstallerOverridesStep {
+	return &InstallerOverridesStep{
+		overrides:        overrides,
+		operationManager: process.NewProvisionOperationManager(os),
+	}
+}
+
+func (s *InstallerOverridesStep) Name() string {
+	return "Installer_Overrides"
+}
+
+func (s *InstallerOverridesStep) Run(operation internal.ProvisioningOperation, log logrus.FieldLogger) (internal.ProvisioningOperation, time.Duration, error) {
+	return s.RunWithContext(context.Background(), operation, log)
+}
+
+// RunWithContext runs the step with the storage calls recorded in the trace of the given context
+func (s *InstallerOverridesStep) RunWithContext(ctx context.Context, operation internal.ProvisioningOperation, log logrus.FieldLogger) (internal.ProvisioningOperation, time.Duration, error) {
+	step := *s
+	step.operationManager = s.operationManager.WithContext(ctx)
+	return step.run(operation, log)
+}
+
+func (s *InstallerOverridesStep) run(operation internal.ProvisioningOperation, log logrus.FieldLogger) (internal.ProvisioningOperation, time.Duration, error) {
+	pp, err := operation.GetProvisioningParameters()
+	if err != nil {
+		log.Errorf("cannot fetch provisioning parameters from operation: %s", err)
+		return s.operationManager.OperationFailed(operation, "invalid operation provisioning parameters")
+	}
+
+	overrides, found, err := s.overrides.GetOverrides(pp.ErsContext.GlobalAccountID)
+	if err != nil {
+		errMsg := fmt.Sprintf("cannot fetch installer overrides of global account %s: %s", pp.ErsContext.GlobalAccountID, err)
+		log.Errorf(errMsg)
+		return s.operationManager.RetryOperation(operation, errMsg, 10*time.Second, 30*time.Minute, log)
+	}
+	if !found {
+		return operation, 0, nil
+	}
+
+	installeroverrides.Apply(operation.InputCreator, overrides)
+	log.Infof("Installer overrides of global account %s applied", pp.ErsContext.GlobalAccountID)
+
+	return operation, 0, nil
+}
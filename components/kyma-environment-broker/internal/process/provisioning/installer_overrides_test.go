@@ -0,0 +1,80 @@
+package provisioning
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process/provisioning/automock"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/provisioner/pkg/gqlschema"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallerOverridesStep_Run(t *testing.T) {
+	t.Run("global account with overrides", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		err := memoryStorage.InstallerOverrides().UpsertOverrides(internal.InstallerOverrides{
+			GlobalAccountID:    "ga-id",
+			Components:         map[string]bool{"kiali": true, "tracing": false},
+			ComponentOverrides: map[string]map[string]string{"monitoring": {"prometheus.resources.limits.memory": "4Gi"}},
+			GlobalOverrides:    map[string]string{"global.disableLegacyConnectivity": "true"},
+		})
+		require.NoError(t, err)
+
+		inputCreatorMock := &automock.ProvisionerInputCreator{}
+		defer inputCreatorMock.AssertExpectations(t)
+		inputCreatorMock.On("EnableOptionalComponent", "kiali").Return(nil).Once()
+		inputCreatorMock.On("DisableOptionalComponent", "tracing").Return(nil).Once()
+		inputCreatorMock.On("AppendOverrides", "monitoring", []*gqlschema.ConfigEntryInput{
+			{
+				Key:   "prometheus.resources.limits.memory",
+				Value: "4Gi",
+			},
+		}).Return(nil).Once()
+		inputCreatorMock.On("AppendGlobalOverrides", []*gqlschema.ConfigEntryInput{
+			{
+				Key:   "global.disableLegacyConnectivity",
+				Value: "true",
+			},
+		}).Return(nil).Once()
+		operation := internal.ProvisioningOperation{
+			InputCreator:           inputCreatorMock,
+			ProvisioningParameters: `{"ers_context": {"globalaccount_id": "ga-id"}}`,
+		}
+
+		step := NewInstallerOverridesStep(memoryStorage.Operations(), memoryStorage.InstallerOverrides())
+
+		// when
+		_, repeat, err := step.Run(operation, logrus.New())
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, time.Duration(0), repeat)
+	})
+
+	t.Run("global account without overrides", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+
+		inputCreatorMock := &automock.ProvisionerInputCreator{}
+		defer inputCreatorMock.AssertExpectations(t)
+		operation := internal.ProvisioningOperation{
+			InputCreator:           inputCreatorMock,
+			ProvisioningParameters: `{"ers_context": {"globalaccount_id": "ga-id"}}`,
+		}
+
+		step := NewInstallerOverridesStep(memoryStorage.Operations(), memoryStorage.InstallerOverrides())
+
+		// when
+		_, repeat, err := step.Run(operation, logrus.New())
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, time.Duration(0), repeat)
+	})
+}
@@ -1,6 +1,7 @@
 package provisioning
 
 import (
+	"context"
 	"time"
 
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/avs"
@@ -26,5 +27,16 @@ func (ies *InternalEvaluationStep) Name() string {
 }
 
 func (ies *InternalEvaluationStep) Run(operation internal.ProvisioningOperation, logger logrus.FieldLogger) (internal.ProvisioningOperation, time.Duration, error) {
+	return ies.RunWithContext(context.Background(), operation, logger)
+}
+
+// RunWithContext runs the step with the AVS calls sent with the given context
+func (ies *InternalEvaluationStep) RunWithContext(ctx context.Context, operation internal.ProvisioningOperation, logger logrus.FieldLogger) (internal.ProvisioningOperation, time.Duration, error) {
+	step := *ies
+	step.delegator = ies.delegator.WithContext(ctx)
+	return step.run(operation, logger)
+}
+
+func (ies *InternalEvaluationStep) run(operation internal.ProvisioningOperation, logger logrus.FieldLogger) (internal.ProvisioningOperation, time.Duration, error) {
 	return ies.delegator.CreateEvaluation(logger, operation, ies.iec, "")
 }
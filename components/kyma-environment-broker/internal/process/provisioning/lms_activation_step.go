@@ -1,6 +1,7 @@
 package provisioning
 
 import (
+	"context"
 	"strings"
 	"time"
 
@@ -31,7 +32,17 @@ func (s *LmsActivationStep) Name() string {
 	return s.step.Name()
 }
 
+// Timeout returns the timeout of the wrapped step
+func (s *LmsActivationStep) Timeout() time.Duration {
+	return process.StepTimeout(s.step)
+}
+
 func (s *LmsActivationStep) Run(operation internal.ProvisioningOperation, log logrus.FieldLogger) (internal.ProvisioningOperation, time.Duration, error) {
+	return s.RunWithContext(context.Background(), operation, log)
+}
+
+// RunWithContext passes the context to the wrapped step
+func (s *LmsActivationStep) RunWithContext(ctx context.Context, operation internal.ProvisioningOperation, log logrus.FieldLogger) (internal.ProvisioningOperation, time.Duration, error) {
 	if s.cfg.EnabledForGlobalAccounts != "" && !strings.EqualFold(s.cfg.EnabledForGlobalAccounts, "none") {
 		pp, err := operation.GetProvisioningParameters()
 		if err != nil {
@@ -51,7 +62,7 @@ func (s *LmsActivationStep) Run(operation internal.ProvisioningOperation, log lo
 				return operation, 0, nil
 			}
 
-			return s.step.Run(operation, log)
+			return runWithContext(ctx, s.step, operation, log)
 		}
 	}
 	log.Infof("Skipping step %s because the step is set to skip all global accounts", s.Name())
@@ -1,6 +1,7 @@
 package provisioning
 
 import (
+	"context"
 	"encoding/base64"
 	"errors"
 	"fmt"
@@ -27,6 +28,9 @@ const (
 	tenantReadyRetryInterval = 30 * time.Second
 	lmsTimeout               = 30 * time.Minute
 	kibanaURLLabelKey        = "operator_lmsUrl"
+
+	// the step polls for the signed and the CA certificate, each of them up to the certPollingTimeout
+	lmsCertStepTimeout = 2*certPollingTimeout + process.DefaultStepTimeout
 )
 
 type LmsClient interface {
@@ -59,11 +63,28 @@ func (s *lmsCertStep) Name() string {
 	return "Request_LMS_Certificates"
 }
 
-// Run executes getting LMS certificates steps, which means:
+// Timeout returns the maximum duration of the step run, which includes polling for the certificates
+func (s *lmsCertStep) Timeout() time.Duration {
+	return lmsCertStepTimeout
+}
+
+func (s *lmsCertStep) Run(operation internal.ProvisioningOperation, l logrus.FieldLogger) (internal.ProvisioningOperation, time.Duration, error) {
+	return s.RunWithContext(context.Background(), operation, l)
+}
+
+// RunWithContext runs the step with the LMS calls sent with the given context
+func (s *lmsCertStep) RunWithContext(ctx context.Context, operation internal.ProvisioningOperation, l logrus.FieldLogger) (internal.ProvisioningOperation, time.Duration, error) {
+	step := *s
+	step.operationManager = s.operationManager.WithContext(ctx)
+	step.provider = lmsClientWithContext(s.provider, ctx)
+	return step.run(ctx, operation, l)
+}
+
+// run executes getting LMS certificates steps, which means:
 // 1. check if the tenant is ready
 // 2. request certificates
 // 3. poll CA and signed certificates
-func (s *lmsCertStep) Run(operation internal.ProvisioningOperation, l logrus.FieldLogger) (internal.ProvisioningOperation, time.Duration, error) {
+func (s *lmsCertStep) run(ctx context.Context, operation internal.ProvisioningOperation, l logrus.FieldLogger) (internal.ProvisioningOperation, time.Duration, error) {
 	if operation.Lms.Failed {
 		l.Info("LMS has failed, skipping")
 		return operation, 0, nil
@@ -122,13 +143,14 @@ func (s *lmsCertStep) Run(operation internal.ProvisioningOperation, l logrus.Fie
 		return operation, 5 * time.Second, nil
 	}
 	logger.Infof("Signed Certificate URL: %s", certURL)
+	operation.Lms.CertificateURL = certURL
 
 	var signedCert string
 	var caCert string
 
 	// certs cannot be stored so there is a need to poll until certs are ready
 	// get Signed Certificate
-	err = wait.PollImmediate(pollingInterval, certPollingTimeout, func() (done bool, err error) {
+	err = pollCertificate(ctx, func() (done bool, err error) {
 		c, found, err := s.provider.GetCertificateByURL(certURL)
 		if err != nil {
 			logger.Warnf("Unable to get LMS Signed Certificate: %s, retrying", err.Error())
@@ -141,13 +163,17 @@ func (s *lmsCertStep) Run(operation internal.ProvisioningOperation, l logrus.Fie
 		signedCert = c
 		return true, nil
 	})
+	if ctx.Err() != nil {
+		logger.Warnf("Polling for LMS Signed Certificate interrupted: %s", ctx.Err())
+		return operation, pollingInterval, nil
+	}
 	if err != nil {
 		logger.Errorf("Setting LMS operation failed: %s", err.Error())
 		return s.failLmsAndUpdate(operation, "Getting LMS Signed Certificate timeout")
 	}
 
 	// get CA cert
-	err = wait.PollImmediate(pollingInterval, certPollingTimeout, func() (done bool, err error) {
+	err = pollCertificate(ctx, func() (done bool, err error) {
 		c, found, err := s.provider.GetCACertificate(operation.Lms.TenantID)
 		if err != nil {
 			logger.Warnf("Unable to get LMS CA Certificate: %s", err.Error())
@@ -160,6 +186,10 @@ func (s *lmsCertStep) Run(operation internal.ProvisioningOperation, l logrus.Fie
 		caCert = c
 		return true, nil
 	})
+	if ctx.Err() != nil {
+		logger.Warnf("Polling for LMS CA Certificate interrupted: %s", ctx.Err())
+		return operation, pollingInterval, nil
+	}
 	if err != nil {
 		logger.Errorf("Setting LMS operation failed: %s", err.Error())
 		return s.failLmsAndUpdate(operation, "getting LMS CA certificate timeout")
@@ -194,6 +224,20 @@ func (s *lmsCertStep) Run(operation internal.ProvisioningOperation, l logrus.Fie
 	return operation, 0, nil
 }
 
+// pollCertificate polls for the certificate up to the certPollingTimeout, the polling stops when the context is done
+func pollCertificate(ctx context.Context, condition wait.ConditionFunc) error {
+	ctx, cancel := context.WithTimeout(ctx, certPollingTimeout)
+	defer cancel()
+	return wait.PollImmediateUntil(pollingInterval, condition, ctx.Done())
+}
+
+func lmsClientWithContext(c LmsClient, ctx context.Context) LmsClient {
+	if cli, ok := c.(lms.Client); ok {
+		return lms.WithContext(cli, ctx)
+	}
+	return c
+}
+
 type LmsStep struct {
 	operationManager *process.ProvisionOperationManager
 	isMandatory      bool
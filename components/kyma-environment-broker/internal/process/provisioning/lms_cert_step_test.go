@@ -7,6 +7,7 @@ import (
 
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/lms"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
 	"github.com/kyma-project/control-plane/components/provisioner/pkg/gqlschema"
 	"github.com/stretchr/testify/assert"
@@ -54,6 +55,18 @@ func TestCertStep_Run(t *testing.T) {
 	assert.True(t, cli.IsCertRequestedForTenant(tID))
 }
 
+func TestCertStep_Timeout(t *testing.T) {
+	// given
+	repo := storage.NewMemoryStorage().Operations()
+	step := NewLmsActivationStep(repo, lms.Config{EnabledForGlobalAccounts: "all"}, NewLmsCertificatesStep(nil, repo, false))
+
+	// when
+	timeout := process.StepTimeout(step)
+
+	// then
+	assert.True(t, timeout > 2*certPollingTimeout, "the step run must not be interrupted while polling for the certificates")
+}
+
 func TestCertStep_TenantNotReady(t *testing.T) {
 	runForOptionalAndMandatory(t, func(t *testing.T, isMandatory bool, a asserter) {
 		// given
@@ -177,9 +190,11 @@ func newInputCreator() *simpleInputCreator {
 }
 
 type simpleInputCreator struct {
-	overrides         map[string][]*gqlschema.ConfigEntryInput
-	labels            map[string]string
-	enabledComponents []string
+	overrides          map[string][]*gqlschema.ConfigEntryInput
+	globalOverrides    []*gqlschema.ConfigEntryInput
+	labels             map[string]string
+	enabledComponents  []string
+	disabledComponents []string
 }
 
 func (c *simpleInputCreator) EnableOptionalComponent(name string) internal.ProvisionerInputCreator {
@@ -187,6 +202,11 @@ func (c *simpleInputCreator) EnableOptionalComponent(name string) internal.Provi
 	return c
 }
 
+func (c *simpleInputCreator) DisableOptionalComponent(name string) internal.ProvisionerInputCreator {
+	c.disabledComponents = append(c.disabledComponents, name)
+	return c
+}
+
 func (c *simpleInputCreator) SetLabel(key, val string) internal.ProvisionerInputCreator {
 	c.labels[key] = val
 	return c
@@ -214,6 +234,7 @@ func (c *simpleInputCreator) AppendOverrides(component string, overrides []*gqls
 }
 
 func (c *simpleInputCreator) AppendGlobalOverrides(overrides []*gqlschema.ConfigEntryInput) internal.ProvisionerInputCreator {
+	c.globalOverrides = append(c.globalOverrides, overrides...)
 	return c
 }
 
@@ -1,6 +1,7 @@
 package provisioning
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -42,6 +43,17 @@ func (s *provideLmsTenantStep) Name() string {
 }
 
 func (s *provideLmsTenantStep) Run(operation internal.ProvisioningOperation, logger logrus.FieldLogger) (internal.ProvisioningOperation, time.Duration, error) {
+	return s.RunWithContext(context.Background(), operation, logger)
+}
+
+// RunWithContext runs the step with the storage calls recorded in the trace of the given context
+func (s *provideLmsTenantStep) RunWithContext(ctx context.Context, operation internal.ProvisioningOperation, logger logrus.FieldLogger) (internal.ProvisioningOperation, time.Duration, error) {
+	step := *s
+	step.operationManager = s.operationManager.WithContext(ctx)
+	return step.run(operation, logger)
+}
+
+func (s *provideLmsTenantStep) run(operation internal.ProvisioningOperation, logger logrus.FieldLogger) (internal.ProvisioningOperation, time.Duration, error) {
 	if operation.Lms.TenantID != "" {
 		return operation, 0, nil
 	}
@@ -15,6 +15,7 @@ import (
 	"github.com/pivotal-cf/brokerapi/v7/domain"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"k8s.io/apimachinery/pkg/util/wait"
 )
 
@@ -97,6 +98,153 @@ func TestManager_Execute(t *testing.T) {
 	}
 }
 
+func TestManager_ExecuteFinishedOperation(t *testing.T) {
+	// given
+	memoryStorage := storage.NewMemoryStorage()
+	operation := fixProvisionOperation(operationIDSuccess)
+	operation.State = domain.Failed
+	err := memoryStorage.Operations().InsertProvisioningOperation(operation)
+	require.NoError(t, err)
+
+	manager := NewManager(memoryStorage.Operations(), event.NewPubSub(), logrus.New())
+	manager.InitStep(&testStep{name: "init", storage: memoryStorage.Operations()})
+
+	// when
+	repeat, err := manager.Execute(operationIDSuccess)
+
+	// then
+	require.NoError(t, err)
+	assert.Zero(t, repeat)
+	got, err := memoryStorage.Operations().GetOperationByID(operationIDSuccess)
+	require.NoError(t, err)
+	assert.Equal(t, domain.Failed, got.State)
+	assert.Empty(t, got.Description, "the step must not be run")
+}
+
+func TestManager_ExecuteStepExceedingTimeout(t *testing.T) {
+	// given
+	memoryStorage := storage.NewMemoryStorage()
+	err := memoryStorage.Operations().InsertProvisioningOperation(fixProvisionOperation(operationIDSuccess))
+	assert.NoError(t, err)
+
+	step := &hangingStep{timeout: 10 * time.Millisecond, deadline: make(chan time.Time, 1)}
+
+	manager := NewManager(memoryStorage.Operations(), event.NewPubSub(), logrus.New())
+	manager.InitStep(step)
+
+	// when
+	repeat, err := manager.Execute(operationIDSuccess)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, process.StepTimeoutRetryInterval, repeat)
+	deadline, ok := <-step.deadline
+	assert.True(t, ok, "the step must get the context with the deadline")
+	assert.False(t, deadline.IsZero())
+}
+
+func TestManager_ExecuteWithHooks(t *testing.T) {
+	// given
+	memoryStorage := storage.NewMemoryStorage()
+	err := memoryStorage.Operations().InsertProvisioningOperation(fixProvisionOperation(operationIDFailed))
+	assert.NoError(t, err)
+
+	hook := &recordingHook{}
+	manager := NewManager(memoryStorage.Operations(), event.NewPubSub(), logrus.New())
+	manager.AddHook(hook)
+	manager.InitStep(&testStep{name: "init", storage: memoryStorage.Operations()})
+
+	// when
+	_, err = manager.Execute(operationIDFailed)
+
+	// then
+	assert.Error(t, err)
+	require.Len(t, hook.before, 1)
+	assert.Equal(t, process.ProvisioningProcess, hook.before[0].Process)
+	assert.Equal(t, "init", hook.before[0].StepName)
+	assert.Equal(t, "", hook.before[0].Operation.Description)
+	require.Len(t, hook.after, 1)
+	assert.Equal(t, "init", hook.after[0].StepName)
+	assert.Equal(t, " init", hook.after[0].Operation.Description)
+	assert.Error(t, hook.after[0].Error)
+}
+
+func TestManager_ExecuteWithStages(t *testing.T) {
+	t.Run("should move operation to next stage", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		err := memoryStorage.Operations().InsertProvisioningOperation(fixProvisionOperation(operationIDSuccess))
+		require.NoError(t, err)
+
+		eventBroker := event.NewPubSub()
+		eventCollector := &collectingEventHandler{}
+		eventBroker.Subscribe(process.StageFinished{}, eventCollector.OnEvent)
+
+		manager := NewManager(memoryStorage.Operations(), eventBroker, logrus.New())
+		manager.InitStep(&testStep{name: "init", storage: memoryStorage.Operations()})
+		manager.AddStep(1, &testStep{name: "one", storage: memoryStorage.Operations()})
+		manager.AddStep(2, &testStep{name: "final", storage: memoryStorage.Operations()})
+		manager.DefineStage(0, process.Stage{Name: "preparation", SLO: time.Hour})
+		manager.DefineStage(2, process.Stage{Name: "installation"})
+
+		// when
+		_, err = manager.Execute(operationIDSuccess)
+
+		// then
+		require.NoError(t, err)
+		operation, err := memoryStorage.Operations().GetOperationByID(operationIDSuccess)
+		require.NoError(t, err)
+		assert.Equal(t, "init one final", strings.Trim(operation.Description, " "))
+		assert.Equal(t, "installation", operation.Stage)
+		assert.False(t, operation.StageStartedAt.IsZero())
+
+		require.NoError(t, wait.PollImmediate(20*time.Millisecond, 2*time.Second, func() (bool, error) {
+			return eventCollector.Len() == 1, nil
+		}))
+		finished := eventCollector.Events[0].(process.StageFinished)
+		assert.Equal(t, process.ProvisioningProcess, finished.Process)
+		assert.Equal(t, "preparation", finished.Stage.Name)
+		assert.Equal(t, process.StageResultDone, finished.Result)
+		assert.True(t, finished.Compliant())
+	})
+
+	t.Run("should fail operation exceeding stage timeout", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		operation := fixProvisionOperation(operationIDSuccess)
+		operation.Stage = "preparation"
+		operation.StageStartedAt = time.Now().Add(-2 * time.Hour)
+		err := memoryStorage.Operations().InsertProvisioningOperation(operation)
+		require.NoError(t, err)
+
+		eventBroker := event.NewPubSub()
+		eventCollector := &collectingEventHandler{}
+		eventBroker.Subscribe(process.StageFinished{}, eventCollector.OnEvent)
+
+		manager := NewManager(memoryStorage.Operations(), eventBroker, logrus.New())
+		manager.InitStep(&testStep{name: "init", storage: memoryStorage.Operations()})
+		manager.DefineStage(1, process.Stage{Name: "preparation", Timeout: time.Hour})
+
+		// when
+		_, err = manager.Execute(operationIDSuccess)
+
+		// then
+		require.Error(t, err)
+		failed, err := memoryStorage.Operations().GetOperationByID(operationIDSuccess)
+		require.NoError(t, err)
+		assert.Equal(t, domain.Failed, failed.State)
+		assert.NotContains(t, failed.Description, "init", "the step must not be run")
+
+		require.NoError(t, wait.PollImmediate(20*time.Millisecond, 2*time.Second, func() (bool, error) {
+			return eventCollector.Len() == 1, nil
+		}))
+		finished := eventCollector.Events[0].(process.StageFinished)
+		assert.Equal(t, "preparation", finished.Stage.Name)
+		assert.Equal(t, process.StageResultFailed, finished.Result)
+		assert.False(t, finished.Compliant())
+	})
+}
+
 func fixProvisionOperation(ID string) internal.ProvisioningOperation {
 	return internal.ProvisioningOperation{
 		Operation: internal.Operation{
@@ -138,6 +286,45 @@ func (ts *testStep) Run(operation internal.ProvisioningOperation, logger logrus.
 	}
 }
 
+type hangingStep struct {
+	timeout  time.Duration
+	deadline chan time.Time
+}
+
+func (s *hangingStep) Name() string {
+	return "hanging"
+}
+
+func (s *hangingStep) Timeout() time.Duration {
+	return s.timeout
+}
+
+func (s *hangingStep) Run(operation internal.ProvisioningOperation, logger logrus.FieldLogger) (internal.ProvisioningOperation, time.Duration, error) {
+	return s.RunWithContext(context.Background(), operation, logger)
+}
+
+func (s *hangingStep) RunWithContext(ctx context.Context, operation internal.ProvisioningOperation, logger logrus.FieldLogger) (internal.ProvisioningOperation, time.Duration, error) {
+	deadline, _ := ctx.Deadline()
+	s.deadline <- deadline
+	// the calls to the external services return when the context is done
+	<-ctx.Done()
+	return operation, 0, ctx.Err()
+}
+
+type recordingHook struct {
+	before []process.StepInfo
+	after  []process.StepInfo
+}
+
+func (h *recordingHook) BeforeStep(ctx context.Context, step process.StepInfo) context.Context {
+	h.before = append(h.before, step)
+	return ctx
+}
+
+func (h *recordingHook) AfterStep(ctx context.Context, step process.StepInfo) {
+	h.after = append(h.after, step)
+}
+
 type collectingEventHandler struct {
 	mu     sync.Mutex
 	Events []interface{}
@@ -150,3 +337,10 @@ func (h *collectingEventHandler) OnEvent(ctx context.Context, ev interface{}) er
 	h.Events = append(h.Events, ev)
 	return nil
 }
+
+func (h *collectingEventHandler) Len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return len(h.Events)
+}
@@ -0,0 +1,98 @@
+package provisioning
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+
+	"github.com/pkg/errors"
+	coreV1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// RuntimeClientFactory creates the client of the runtime cluster from its kubeconfig
+type RuntimeClientFactory func(kubeconfig string) (client.Client, error)
+
+// RegistryPullSecrets creates the image pull secrets of the private registry mirror in the runtime,
+// the Kyma components reference them in the global.imagePullSecrets override
+type RegistryPullSecrets interface {
+	Create(kubeconfig string, registry internal.RegistryDTO) error
+}
+
+type runtimeRegistryPullSecrets struct {
+	newClient RuntimeClientFactory
+}
+
+func NewRegistryPullSecrets(newClient RuntimeClientFactory) RegistryPullSecrets {
+	return &runtimeRegistryPullSecrets{
+		newClient: newClient,
+	}
+}
+
+// Create creates the pull secrets in all namespaces of the runtime. The Kyma installer creates the namespaces
+// of the components during the installation, so the method is called until the installation finishes
+// and the secrets which already exist are not changed.
+func (r *runtimeRegistryPullSecrets) Create(kubeconfig string, registry internal.RegistryDTO) error {
+	cli, err := r.newClient(kubeconfig)
+	if err != nil {
+		return errors.Wrap(err, "while creating runtime client")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	namespaces := &coreV1.NamespaceList{}
+	if err := cli.List(ctx, namespaces); err != nil {
+		return errors.Wrap(err, "while listing namespaces")
+	}
+
+	for _, secret := range registry.PullSecrets {
+		dockerConfig, err := dockerConfigJSON(registryServer(registry.URL), secret)
+		if err != nil {
+			return errors.Wrapf(err, "while creating docker config of pull secret %s", secret.Name)
+		}
+		for _, namespace := range namespaces.Items {
+			if namespace.Status.Phase == coreV1.NamespaceTerminating {
+				continue
+			}
+			err := cli.Create(ctx, &coreV1.Secret{
+				ObjectMeta: metaV1.ObjectMeta{
+					Name:      secret.Name,
+					Namespace: namespace.Name,
+				},
+				Type: coreV1.SecretTypeDockerConfigJson,
+				Data: map[string][]byte{
+					coreV1.DockerConfigJsonKey: dockerConfig,
+				},
+			})
+			if err != nil && !apierrors.IsAlreadyExists(err) {
+				return errors.Wrapf(err, "while creating pull secret %s/%s", namespace.Name, secret.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// registryServer returns the host with the optional port of the registry URL, the docker config
+// authenticates to the server and not to the repository path
+func registryServer(url string) string {
+	return strings.SplitN(url, "/", 2)[0]
+}
+
+func dockerConfigJSON(server string, secret internal.RegistryPullSecretDTO) ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"auths": map[string]interface{}{
+			server: map[string]string{
+				"username": secret.Username,
+				"password": secret.Password,
+				"auth":     base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", secret.Username, secret.Password))),
+			},
+		},
+	})
+}
@@ -0,0 +1,56 @@
+package provisioning
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRegistryPullSecrets_Create(t *testing.T) {
+	// given
+	sch := runtime.NewScheme()
+	require.NoError(t, coreV1.AddToScheme(sch))
+	cli := fake.NewFakeClientWithScheme(sch,
+		&coreV1.Namespace{ObjectMeta: metaV1.ObjectMeta{Name: "kyma-system"}},
+		&coreV1.Namespace{ObjectMeta: metaV1.ObjectMeta{Name: "istio-system"}},
+		&coreV1.Secret{
+			ObjectMeta: metaV1.ObjectMeta{Name: "registry-credentials", Namespace: "istio-system"},
+			Data:       map[string][]byte{coreV1.DockerConfigJsonKey: []byte("existing")},
+		},
+	)
+	var kubeconfig string
+	pullSecrets := NewRegistryPullSecrets(func(k string) (client.Client, error) {
+		kubeconfig = k
+		return cli, nil
+	})
+
+	// when
+	err := pullSecrets.Create("runtime-kubeconfig", internal.RegistryDTO{
+		URL:         "registry.example.com:5000/kyma",
+		PullSecrets: []internal.RegistryPullSecretDTO{{Name: "registry-credentials", Username: "kyma", Password: "secret"}},
+	})
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, "runtime-kubeconfig", kubeconfig)
+
+	secret := &coreV1.Secret{}
+	err = cli.Get(context.Background(), client.ObjectKey{Namespace: "kyma-system", Name: "registry-credentials"}, secret)
+	require.NoError(t, err)
+	assert.Equal(t, coreV1.SecretTypeDockerConfigJson, secret.Type)
+	assert.JSONEq(t, `{"auths": {"registry.example.com:5000": {"username": "kyma", "password": "secret", "auth": "a3ltYTpzZWNyZXQ="}}}`,
+		string(secret.Data[coreV1.DockerConfigJsonKey]))
+
+	err = cli.Get(context.Background(), client.ObjectKey{Namespace: "istio-system", Name: "registry-credentials"}, secret)
+	require.NoError(t, err)
+	assert.Equal(t, "existing", string(secret.Data[coreV1.DockerConfigJsonKey]))
+}
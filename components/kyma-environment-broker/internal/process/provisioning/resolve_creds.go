@@ -1,6 +1,7 @@
 package provisioning
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -64,6 +65,17 @@ func (s *ResolveCredentialsStep) Name() string {
 }
 
 func (s *ResolveCredentialsStep) Run(operation internal.ProvisioningOperation, logger logrus.FieldLogger) (internal.ProvisioningOperation, time.Duration, error) {
+	return s.RunWithContext(context.Background(), operation, logger)
+}
+
+// RunWithContext runs the step with the storage calls recorded in the trace of the given context
+func (s *ResolveCredentialsStep) RunWithContext(ctx context.Context, operation internal.ProvisioningOperation, logger logrus.FieldLogger) (internal.ProvisioningOperation, time.Duration, error) {
+	step := *s
+	step.operationManager = s.operationManager.WithContext(ctx)
+	return step.run(operation, logger)
+}
+
+func (s *ResolveCredentialsStep) run(operation internal.ProvisioningOperation, logger logrus.FieldLogger) (internal.ProvisioningOperation, time.Duration, error) {
 
 	pp, err := operation.GetProvisioningParameters()
 	if err != nil {
@@ -112,7 +124,9 @@ func (s *ResolveCredentialsStep) Run(operation internal.ProvisioningOperation, l
 		return s.operationManager.OperationFailed(operation, err.Error())
 	}
 
-	updatedOperation, err := s.opStorage.UpdateProvisioningOperation(operation)
+	updatedOperation, err := storage.UpdateWithRetryProvisioningOperation(s.opStorage, operation.ID, func(latest *internal.ProvisioningOperation) {
+		latest.ProvisioningParameters = operation.ProvisioningParameters
+	})
 	if err != nil {
 		return operation, 1 * time.Minute, nil
 	}
@@ -0,0 +1,38 @@
+package provisioning
+
+import (
+	"github.com/pkg/errors"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// runtimeAccess holds the data needed to reach the API server of the shoot cluster
+type runtimeAccess struct {
+	APIServerURL string
+	CABundle     string
+}
+
+// extractRuntimeAccess reads the API server URL and the CA bundle of the current context
+// from the kubeconfig returned by the Runtime Provisioner
+func extractRuntimeAccess(kubeconfig string) (runtimeAccess, error) {
+	cfg, err := clientcmd.Load([]byte(kubeconfig))
+	if err != nil {
+		return runtimeAccess{}, errors.Wrap(err, "while loading kubeconfig")
+	}
+
+	kubeContext, found := cfg.Contexts[cfg.CurrentContext]
+	if !found {
+		return runtimeAccess{}, errors.Errorf("current context %q not found in kubeconfig", cfg.CurrentContext)
+	}
+	cluster, found := cfg.Clusters[kubeContext.Cluster]
+	if !found {
+		return runtimeAccess{}, errors.Errorf("cluster %q not found in kubeconfig", kubeContext.Cluster)
+	}
+	if cluster.Server == "" {
+		return runtimeAccess{}, errors.New("API server URL is empty")
+	}
+
+	return runtimeAccess{
+		APIServerURL: cluster.Server,
+		CABundle:     string(cluster.CertificateAuthorityData),
+	}, nil
+}
@@ -0,0 +1,69 @@
+package provisioning
+
+import (
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	fixAPIServerURL = "https://api.c-1234.kyma.shoot.live.k8s-hana.ondemand.com"
+	fixCABundle     = "-----BEGIN CERTIFICATE-----\nMIIC\n-----END CERTIFICATE-----\n"
+	fixShootName    = "c-1234"
+)
+
+func TestExtractRuntimeAccess(t *testing.T) {
+	// when
+	access, err := extractRuntimeAccess(fixKubeconfig())
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, fixAPIServerURL, access.APIServerURL)
+	assert.Equal(t, fixCABundle, access.CABundle)
+}
+
+func TestExtractRuntimeAccess_InvalidKubeconfig(t *testing.T) {
+	for name, kubeconfig := range map[string]string{
+		"not a kubeconfig": "{not-yaml",
+		"missing current context": `
+apiVersion: v1
+kind: Config
+current-context: shoot
+clusters: []
+contexts: []
+`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			// when
+			_, err := extractRuntimeAccess(kubeconfig)
+
+			// then
+			assert.Error(t, err)
+		})
+	}
+}
+
+func fixKubeconfig() string {
+	return fmt.Sprintf(`
+apiVersion: v1
+kind: Config
+current-context: shoot--kyma--c-1234
+clusters:
+- name: shoot--kyma--c-1234
+  cluster:
+    server: %s
+    certificate-authority-data: %s
+contexts:
+- name: shoot--kyma--c-1234
+  context:
+    cluster: shoot--kyma--c-1234
+    user: shoot--kyma--c-1234-token
+users:
+- name: shoot--kyma--c-1234-token
+  user:
+    token: token
+`, fixAPIServerURL, base64.StdEncoding.EncodeToString([]byte(fixCABundle)))
+}
@@ -0,0 +1,83 @@
+package provisioning
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	gardenerapi "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	gardenerclient "github.com/gardener/gardener/pkg/client/core/clientset/versioned/typed/core/v1beta1"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// maxShootDigestLength limits the digest, so the operation description stays readable
+const maxShootDigestLength = 1024
+
+// ShootDiagnostics describes the state of the Gardener shoot of the runtime, the digest explains
+// the actual cause of the failed provisioning, e.g. an exceeded quota or an invalid CIDR
+type ShootDiagnostics interface {
+	Digest(shootName string) (string, error)
+}
+
+type gardenerShootDiagnostics struct {
+	shoots gardenerclient.ShootInterface
+}
+
+func NewShootDiagnostics(shoots gardenerclient.ShootInterface) ShootDiagnostics {
+	return &gardenerShootDiagnostics{
+		shoots: shoots,
+	}
+}
+
+// Digest returns the summary of the last operation, the last errors and the unhealthy conditions of the shoot,
+// the empty digest is returned if the shoot does not exist
+func (d *gardenerShootDiagnostics) Digest(shootName string) (string, error) {
+	shoot, err := d.shoots.Get(shootName, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		return "", nil
+	case err != nil:
+		return "", errors.Wrapf(err, "while getting shoot %s", shootName)
+	}
+
+	return ShootStatusDigest(*shoot), nil
+}
+
+// ShootStatusDigest builds a short, human readable summary of the shoot status
+func ShootStatusDigest(shoot gardenerapi.Shoot) string {
+	var parts []string
+
+	if op := shoot.Status.LastOperation; op != nil {
+		parts = append(parts, fmt.Sprintf("last operation %s %s: %s", op.Type, op.State, op.Description))
+	}
+	for _, lastErr := range shoot.Status.LastErrors {
+		description := lastErr.Description
+		if len(lastErr.Codes) > 0 {
+			codes := make([]string, 0, len(lastErr.Codes))
+			for _, code := range lastErr.Codes {
+				codes = append(codes, string(code))
+			}
+			description = fmt.Sprintf("%s (%s)", description, strings.Join(codes, ", "))
+		}
+		parts = append(parts, fmt.Sprintf("error: %s", description))
+	}
+	for _, condition := range shoot.Status.Conditions {
+		if condition.Status == gardenerapi.ConditionTrue {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("condition %s is %s: %s", condition.Type, condition.Status, condition.Message))
+	}
+
+	digest := strings.Join(parts, "; ")
+	if len(digest) > maxShootDigestLength {
+		// the digest is cut at the start of the rune, so the multi-byte character is not split
+		end := maxShootDigestLength
+		for end > 0 && !utf8.RuneStart(digest[end]) {
+			end--
+		}
+		digest = digest[:end] + "..."
+	}
+	return digest
+}
@@ -0,0 +1,98 @@
+package provisioning
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	gardenerapi "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	gardenerfake "github.com/gardener/gardener/pkg/client/core/clientset/versioned/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestShootDiagnostics_Digest(t *testing.T) {
+	// given
+	shoot := fixFailedShoot("c-1234567")
+	shoots := gardenerfake.NewSimpleClientset(&shoot).CoreV1beta1().Shoots("garden-kyma")
+	diagnostics := NewShootDiagnostics(shoots)
+
+	// when
+	digest, err := diagnostics.Digest("c-1234567")
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, "last operation Create Failed: infrastructure reconciliation failed; "+
+		"error: quota exceeded for resource cores (ERR_INFRA_QUOTA_EXCEEDED); "+
+		"condition APIServerAvailable is False: API server is not reachable", digest)
+
+	// when
+	digest, err = diagnostics.Digest("c-unknown")
+
+	// then
+	require.NoError(t, err)
+	assert.Empty(t, digest)
+}
+
+func TestShootStatusDigest_Truncated(t *testing.T) {
+	// given
+	shoot := fixFailedShoot("c-1234567")
+	shoot.Status.LastErrors[0].Description = strings.Repeat("x", 2*maxShootDigestLength)
+
+	// when
+	digest := ShootStatusDigest(shoot)
+
+	// then
+	assert.Len(t, digest, maxShootDigestLength+len("..."))
+}
+
+func TestShootStatusDigest_TruncatedOnRuneBoundary(t *testing.T) {
+	// given
+	shoot := fixFailedShoot("c-1234567")
+	shoot.Status.LastOperation = nil
+	shoot.Status.Conditions = nil
+	// "error: " has 7 bytes, so the 2-byte characters end at the odd offsets and the limit falls inside a character
+	shoot.Status.LastErrors[0].Description = strings.Repeat("ą", maxShootDigestLength)
+	shoot.Status.LastErrors[0].Codes = nil
+
+	// when
+	digest := ShootStatusDigest(shoot)
+
+	// then
+	assert.True(t, utf8.ValidString(digest))
+	assert.Len(t, digest, maxShootDigestLength-1+len("..."))
+}
+
+func fixFailedShoot(name string) gardenerapi.Shoot {
+	return gardenerapi.Shoot{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "garden-kyma",
+		},
+		Status: gardenerapi.ShootStatus{
+			LastOperation: &gardenerapi.LastOperation{
+				Type:        gardenerapi.LastOperationTypeCreate,
+				State:       gardenerapi.LastOperationStateFailed,
+				Description: "infrastructure reconciliation failed",
+			},
+			LastErrors: []gardenerapi.LastError{
+				{
+					Description: "quota exceeded for resource cores",
+					Codes:       []gardenerapi.ErrorCode{gardenerapi.ErrorInfraQuotaExceeded},
+				},
+			},
+			Conditions: []gardenerapi.Condition{
+				{
+					Type:   gardenerapi.ShootControlPlaneHealthy,
+					Status: gardenerapi.ConditionTrue,
+				},
+				{
+					Type:    gardenerapi.ShootAPIServerAvailable,
+					Status:  gardenerapi.ConditionFalse,
+					Message: "API server is not reachable",
+				},
+			},
+		},
+	}
+}
@@ -1,6 +1,7 @@
 package provisioning
 
 import (
+	"context"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -27,7 +28,17 @@ func (s *SkipForTrialPlanStep) Name() string {
 	return s.step.Name()
 }
 
+// Timeout returns the timeout of the wrapped step
+func (s *SkipForTrialPlanStep) Timeout() time.Duration {
+	return process.StepTimeout(s.step)
+}
+
 func (s *SkipForTrialPlanStep) Run(operation internal.ProvisioningOperation, log logrus.FieldLogger) (internal.ProvisioningOperation, time.Duration, error) {
+	return s.RunWithContext(context.Background(), operation, log)
+}
+
+// RunWithContext passes the context to the wrapped step
+func (s *SkipForTrialPlanStep) RunWithContext(ctx context.Context, operation internal.ProvisioningOperation, log logrus.FieldLogger) (internal.ProvisioningOperation, time.Duration, error) {
 	pp, err := operation.GetProvisioningParameters()
 	if err != nil {
 		log.Errorf("cannot fetch provisioning parameters from operation: %s", err)
@@ -38,5 +49,5 @@ func (s *SkipForTrialPlanStep) Run(operation internal.ProvisioningOperation, log
 		return operation, 0, nil
 	}
 
-	return s.step.Run(operation, log)
+	return runWithContext(ctx, s.step, operation, log)
 }
@@ -0,0 +1,134 @@
+package process
+
+import (
+	"sort"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+)
+
+// Results of the stage passed in the StageFinished event
+const (
+	// StageResultDone means the operation entered the next stage or succeeded
+	StageResultDone = "done"
+	// StageResultFailed means the operation failed in the stage, also when the stage exceeded its timeout
+	StageResultFailed = "failed"
+)
+
+// Stage is the named group of the consecutive steps of the process, e.g. the preparation of the runtime input
+// or the installation of the runtime. The time the operation spends in the stage is measured from the first run
+// of a step of the stage until the operation enters the next stage or finishes, so it includes the step retries
+// and the time the operation waits for the external systems.
+type Stage struct {
+	Name string
+	// Timeout is the maximum time the operation can spend in the stage, the operation fails when the stage
+	// exceeds it. The time of the stage is not limited if the timeout is zero.
+	Timeout time.Duration
+	// SLO is the expected maximum time of the stage, the stage which takes longer is reported as not compliant.
+	// Every stage which is done is compliant if the SLO is zero.
+	SLO time.Duration
+}
+
+// Stages assigns the steps of the process to the stages by the step weight. The stage contains the steps with
+// the weight equal to or greater than the weight the stage is defined at, and lower than the weight of the next
+// stage. The steps with the weight lower than the weight of the first stage do not belong to any stage.
+type Stages struct {
+	process string
+	weights []int
+	stages  map[int]Stage
+}
+
+func NewStages(process string) *Stages {
+	return &Stages{
+		process: process,
+		stages:  make(map[int]Stage),
+	}
+}
+
+// Define adds the stage which starts at the steps of the given weight
+func (s *Stages) Define(weight int, stage Stage) {
+	if _, found := s.stages[weight]; !found {
+		s.weights = append(s.weights, weight)
+		sort.Ints(s.weights)
+	}
+	s.stages[weight] = stage
+}
+
+// Enter moves the operation to the stage of the step with the given weight and returns the stage finished by
+// the move, if any. The operation never moves back to the previous stage, so the steps run at the beginning
+// of every execution of the process, e.g. the initialisation step, do not restart the stages.
+func (s *Stages) Enter(operation internal.Operation, weight int, now time.Time) (internal.Operation, *StageFinished, bool) {
+	target, found := s.weightOf(weight)
+	if !found {
+		return operation, nil, false
+	}
+	if current, found := s.weightByName(operation.Stage); found && current >= target {
+		return operation, nil, false
+	}
+
+	finished := s.finish(operation, StageResultDone, now)
+	operation.Stage = s.stages[target].Name
+	operation.StageStartedAt = now
+	return operation, finished, true
+}
+
+// Finish returns the stage finished by the operation which is not in progress anymore
+func (s *Stages) Finish(operation internal.Operation, now time.Time) *StageFinished {
+	result := StageResultDone
+	if operation.State != domain.Succeeded {
+		result = StageResultFailed
+	}
+	return s.finish(operation, result, now)
+}
+
+// Exceeded returns the stage of the operation if the operation spent more time in it than the stage timeout
+func (s *Stages) Exceeded(operation internal.Operation, now time.Time) (Stage, bool) {
+	weight, found := s.weightByName(operation.Stage)
+	if !found {
+		return Stage{}, false
+	}
+	stage := s.stages[weight]
+	if stage.Timeout == 0 || now.Sub(operation.StageStartedAt) <= stage.Timeout {
+		return Stage{}, false
+	}
+	return stage, true
+}
+
+func (s *Stages) finish(operation internal.Operation, result string, now time.Time) *StageFinished {
+	weight, found := s.weightByName(operation.Stage)
+	if !found {
+		return nil
+	}
+	return &StageFinished{
+		Process:   s.process,
+		Stage:     s.stages[weight],
+		Operation: operation,
+		Duration:  now.Sub(operation.StageStartedAt),
+		Result:    result,
+	}
+}
+
+// weightOf returns the weight of the stage the step with the given weight belongs to
+func (s *Stages) weightOf(stepWeight int) (int, bool) {
+	for i := len(s.weights) - 1; i >= 0; i-- {
+		if s.weights[i] <= stepWeight {
+			return s.weights[i], true
+		}
+	}
+	return 0, false
+}
+
+// weightByName returns the weight of the stage with the given name, the stage is not found if it is not defined
+// anymore, e.g. after the stages of the process were changed
+func (s *Stages) weightByName(name string) (int, bool) {
+	if name == "" {
+		return 0, false
+	}
+	for weight, stage := range s.stages {
+		if stage.Name == name {
+			return weight, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,130 @@
+package process
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStages_Enter(t *testing.T) {
+	// given
+	now := time.Now()
+	stages := NewStages(ProvisioningProcess)
+	stages.Define(10, Stage{Name: "installation", SLO: time.Hour})
+	stages.Define(1, Stage{Name: "preparation", SLO: time.Minute})
+
+	// when
+	operation, finished, entered := stages.Enter(internal.Operation{State: domain.InProgress}, 2, now)
+
+	// then
+	require.True(t, entered)
+	assert.Nil(t, finished)
+	assert.Equal(t, "preparation", operation.Stage)
+	assert.Equal(t, now, operation.StageStartedAt)
+
+	// when
+	_, _, entered = stages.Enter(operation, 1, now.Add(time.Minute))
+
+	// then
+	assert.False(t, entered, "the operation must not enter the same stage again")
+
+	// when
+	operation, finished, entered = stages.Enter(operation, 10, now.Add(2*time.Minute))
+
+	// then
+	require.True(t, entered)
+	assert.Equal(t, "installation", operation.Stage)
+	require.NotNil(t, finished)
+	assert.Equal(t, "preparation", finished.Stage.Name)
+	assert.Equal(t, 2*time.Minute, finished.Duration)
+	assert.Equal(t, StageResultDone, finished.Result)
+	assert.False(t, finished.Compliant(), "the stage took longer than its SLO")
+
+	// when
+	_, _, entered = stages.Enter(operation, 1, now.Add(3*time.Minute))
+
+	// then
+	assert.False(t, entered, "the operation must not move back to the previous stage")
+
+	// when
+	operation.State = domain.Succeeded
+	finished = stages.Finish(operation, now.Add(32*time.Minute))
+
+	// then
+	require.NotNil(t, finished)
+	assert.Equal(t, "installation", finished.Stage.Name)
+	assert.Equal(t, StageResultDone, finished.Result)
+	assert.True(t, finished.Compliant())
+}
+
+func TestStages_EnterStepBeforeFirstStage(t *testing.T) {
+	// given
+	stages := NewStages(ProvisioningProcess)
+	stages.Define(5, Stage{Name: "registration"})
+
+	// when
+	operation, finished, entered := stages.Enter(internal.Operation{}, 1, time.Now())
+
+	// then
+	assert.False(t, entered)
+	assert.Nil(t, finished)
+	assert.Empty(t, operation.Stage)
+}
+
+func TestStages_Exceeded(t *testing.T) {
+	// given
+	now := time.Now()
+	stages := NewStages(ProvisioningProcess)
+	stages.Define(1, Stage{Name: "preparation", Timeout: time.Hour})
+	stages.Define(10, Stage{Name: "installation"})
+
+	for name, tc := range map[string]struct {
+		operation internal.Operation
+		exceeded  bool
+	}{
+		"within timeout": {
+			operation: internal.Operation{Stage: "preparation", StageStartedAt: now.Add(-time.Minute)},
+		},
+		"timeout exceeded": {
+			operation: internal.Operation{Stage: "preparation", StageStartedAt: now.Add(-2 * time.Hour)},
+			exceeded:  true,
+		},
+		"stage without timeout": {
+			operation: internal.Operation{Stage: "installation", StageStartedAt: now.Add(-24 * time.Hour)},
+		},
+		"unknown stage": {
+			operation: internal.Operation{Stage: "removed", StageStartedAt: now.Add(-24 * time.Hour)},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// when
+			stage, exceeded := stages.Exceeded(tc.operation, now)
+
+			// then
+			assert.Equal(t, tc.exceeded, exceeded)
+			if tc.exceeded {
+				assert.Equal(t, tc.operation.Stage, stage.Name)
+			}
+		})
+	}
+}
+
+func TestStages_FinishFailed(t *testing.T) {
+	// given
+	now := time.Now()
+	stages := NewStages(DeprovisioningProcess)
+	stages.Define(1, Stage{Name: "cleanup"})
+
+	// when
+	finished := stages.Finish(internal.Operation{State: domain.Failed, Stage: "cleanup", StageStartedAt: now.Add(-time.Minute)}, now)
+
+	// then
+	require.NotNil(t, finished)
+	assert.Equal(t, DeprovisioningProcess, finished.Process)
+	assert.Equal(t, StageResultFailed, finished.Result)
+	assert.False(t, finished.Compliant())
+}
@@ -0,0 +1,105 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/event"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/logger"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/pkg/errors"
+)
+
+// BrokerActor is the actor of the state transitions made by the steps of the broker processes
+const BrokerActor = "kyma-environment-broker"
+
+// StateTransitionRecorder stores an event for every state transition of the operation made by a process step
+// or outside of the processes, so the history of the operation is available after the logs are rotated away
+type StateTransitionRecorder struct {
+	events storage.OperationEvents
+}
+
+// RegisterStateTransitionRecorder subscribes the recorder to the events of all processes and to the state changes
+// made outside of the processes
+func RegisterStateTransitionRecorder(sub event.Subscriber, events storage.OperationEvents) {
+	recorder := &StateTransitionRecorder{events: events}
+
+	sub.Subscribe(ProvisioningStepProcessed{}, recorder.OnStepProcessed)
+	sub.Subscribe(DeprovisioningStepProcessed{}, recorder.OnStepProcessed)
+	sub.Subscribe(UpgradeKymaStepProcessed{}, recorder.OnStepProcessed)
+	sub.Subscribe(UpgradeClusterStepProcessed{}, recorder.OnStepProcessed)
+	sub.Subscribe(PlanMigrationStepProcessed{}, recorder.OnStepProcessed)
+	sub.Subscribe(UpdatingStepProcessed{}, recorder.OnStepProcessed)
+	sub.Subscribe(SuspensionStepProcessed{}, recorder.OnStepProcessed)
+	sub.Subscribe(AccountMigrationStepProcessed{}, recorder.OnStepProcessed)
+	sub.Subscribe(OperationStateChanged{}, recorder.OnStateChanged)
+}
+
+func (r *StateTransitionRecorder) OnStepProcessed(ctx context.Context, ev interface{}) error {
+	var step StepProcessed
+	var oldOperation, operation internal.Operation
+	switch e := ev.(type) {
+	case ProvisioningStepProcessed:
+		step, oldOperation, operation = e.StepProcessed, e.OldOperation.Operation, e.Operation.Operation
+	case DeprovisioningStepProcessed:
+		step, oldOperation, operation = e.StepProcessed, e.OldOperation.Operation, e.Operation.Operation
+	case UpgradeKymaStepProcessed:
+		step, oldOperation, operation = e.StepProcessed, e.OldOperation.Operation, e.Operation.Operation
+	case UpgradeClusterStepProcessed:
+		step, oldOperation, operation = e.StepProcessed, e.OldOperation.Operation, e.Operation.Operation
+	case PlanMigrationStepProcessed:
+		step, oldOperation, operation = e.StepProcessed, e.OldOperation.Operation, e.Operation.Operation
+	case UpdatingStepProcessed:
+		step, oldOperation, operation = e.StepProcessed, e.OldOperation.Operation, e.Operation.Operation
+	case SuspensionStepProcessed:
+		step, oldOperation, operation = e.StepProcessed, e.OldOperation.Operation, e.Operation.Operation
+	case AccountMigrationStepProcessed:
+		step, oldOperation, operation = e.StepProcessed, e.OldOperation.Operation, e.Operation.Operation
+	default:
+		return fmt.Errorf("expected step processed event but got %+v", ev)
+	}
+
+	if oldOperation.State == operation.State {
+		return nil
+	}
+
+	return r.record(ctx, oldOperation.State, operation, BrokerActor, step.StepName)
+}
+
+// OnStateChanged records the state transition made outside of the processes, which has no step
+func (r *StateTransitionRecorder) OnStateChanged(ctx context.Context, ev interface{}) error {
+	e, ok := ev.(OperationStateChanged)
+	if !ok {
+		return fmt.Errorf("expected OperationStateChanged but got %+v", ev)
+	}
+	if e.OldState == e.Operation.State {
+		return nil
+	}
+
+	return r.record(ctx, e.OldState, e.Operation, e.Actor, "")
+}
+
+// record stores the event of the state transition, the transition is logged with the logger of the publisher,
+// which carries the fields of the operation
+func (r *StateTransitionRecorder) record(ctx context.Context, oldState domain.LastOperationState, operation internal.Operation, actor, stepName string) error {
+	err := r.events.InsertEvent(internal.OperationEvent{
+		ID:          uuid.New().String(),
+		OperationID: operation.ID,
+		InstanceID:  operation.InstanceID,
+		Actor:       actor,
+		StepName:    stepName,
+		OldState:    oldState,
+		NewState:    operation.State,
+		Description: operation.Description,
+		CreatedAt:   time.Now(),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "while saving state transition of the operation %s", operation.ID)
+	}
+	logger.FromContext(ctx).Debugf("Recorded state transition of the operation %s from %q to %q by %s", operation.ID, oldState, operation.State, actor)
+	return nil
+}
@@ -0,0 +1,83 @@
+package process
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateTransitionRecorder_OnStepProcessed(t *testing.T) {
+	// given
+	events := storage.NewMemoryStorage().OperationEvents()
+	recorder := &StateTransitionRecorder{events: events}
+
+	operation := internal.ProvisioningOperation{
+		Operation: internal.Operation{
+			ID:         "operation-id",
+			InstanceID: "instance-id",
+			State:      domain.InProgress,
+		},
+	}
+	failedOperation := operation
+	failedOperation.State = domain.Failed
+	failedOperation.Description = "provisioning failed"
+
+	// when
+	err := recorder.OnStepProcessed(context.TODO(), ProvisioningStepProcessed{
+		StepProcessed: StepProcessed{StepName: "Create_Runtime"},
+		OldOperation:  operation,
+		Operation:     operation,
+	})
+	require.NoError(t, err)
+	err = recorder.OnStepProcessed(context.TODO(), ProvisioningStepProcessed{
+		StepProcessed: StepProcessed{StepName: "Check_Runtime_Status"},
+		OldOperation:  operation,
+		Operation:     failedOperation,
+	})
+	require.NoError(t, err)
+
+	// then
+	recorded, err := events.ListEventsByOperationID("operation-id")
+	require.NoError(t, err)
+	require.Len(t, recorded, 1)
+	assert.Equal(t, BrokerActor, recorded[0].Actor)
+	assert.Equal(t, "Check_Runtime_Status", recorded[0].StepName)
+	assert.Equal(t, domain.InProgress, recorded[0].OldState)
+	assert.Equal(t, domain.Failed, recorded[0].NewState)
+	assert.Equal(t, "provisioning failed", recorded[0].Description)
+}
+
+func TestStateTransitionRecorder_OnStateChanged(t *testing.T) {
+	// given
+	events := storage.NewMemoryStorage().OperationEvents()
+	recorder := &StateTransitionRecorder{events: events}
+
+	operation := internal.Operation{
+		ID:          "operation-id",
+		InstanceID:  "instance-id",
+		State:       internal.OperationCanceled,
+		Description: "operation canceled together with the orchestration",
+	}
+
+	// when
+	err := recorder.OnStateChanged(context.TODO(), OperationStateChanged{
+		OldState:  domain.InProgress,
+		Operation: operation,
+		Actor:     "jane.doe",
+	})
+	require.NoError(t, err)
+
+	// then
+	recorded, err := events.ListEventsByOperationID("operation-id")
+	require.NoError(t, err)
+	require.Len(t, recorded, 1)
+	assert.Equal(t, "jane.doe", recorded[0].Actor)
+	assert.Empty(t, recorded[0].StepName)
+	assert.Equal(t, domain.InProgress, recorded[0].OldState)
+	assert.Equal(t, internal.OperationCanceled, recorded[0].NewState)
+}
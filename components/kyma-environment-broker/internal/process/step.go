@@ -0,0 +1,53 @@
+package process
+
+import (
+	"context"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/logger"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultStepTimeout is the maximum duration of a single run of the step which does not declare its own timeout
+	DefaultStepTimeout = 5 * time.Minute
+
+	// StepTimeoutRetryInterval is the time after which the operation is processed again when the step run timed out
+	StepTimeoutRetryInterval = 10 * time.Second
+)
+
+// ErrStepTimeout is returned by RunStep when the step does not finish before its deadline
+var ErrStepTimeout = errors.New("step run exceeded its timeout")
+
+// StepWithTimeout is implemented by the steps which declare the maximum duration of a single run
+type StepWithTimeout interface {
+	Timeout() time.Duration
+}
+
+// StepTimeout returns the maximum duration of a single run of the given step
+func StepTimeout(step interface{}) time.Duration {
+	if s, ok := step.(StepWithTimeout); ok && s.Timeout() > 0 {
+		return s.Timeout()
+	}
+	return DefaultStepTimeout
+}
+
+// RunStep calls run with a context which is done after the timeout of the step and waits until run returns.
+// The steps pass the context to the calls of the external services, so the hanging call returns once the deadline
+// is exceeded. When the deadline is exceeded during the run, RunStep returns ErrStepTimeout and the caller drops
+// the results written by run and processes the operation again after StepTimeoutRetryInterval. The timeout is logged
+// with the logger of the context, which carries the fields of the processed operation.
+func RunStep(ctx context.Context, step interface{}, run func(ctx context.Context)) error {
+	timeout := StepTimeout(step)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	run(ctx)
+
+	if ctx.Err() == context.DeadlineExceeded {
+		logger.FromContext(ctx).Warnf("Step did not finish in %s, the operation will be repeated in %s", timeout, StepTimeoutRetryInterval)
+		return errors.Wrapf(ErrStepTimeout, "deadline of %s exceeded", timeout)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package process
+
+import (
+	"context"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+)
+
+// Names of the processes passed to the step hooks
+const (
+	ProvisioningProcess     = "provisioning"
+	DeprovisioningProcess   = "deprovisioning"
+	UpgradeKymaProcess      = "upgrade_kyma"
+	UpgradeClusterProcess   = "upgrade_cluster"
+	PlanMigrationProcess    = "plan_migration"
+	UpdatingProcess         = "update"
+	SuspensionProcess       = "suspension"
+	AccountMigrationProcess = "account_migration"
+)
+
+// StepInfo describes the step run passed to the step hooks
+type StepInfo struct {
+	Process  string
+	StepName string
+	// Operation is the operation passed to the step in BeforeStep and the operation returned by the step in AfterStep
+	Operation internal.Operation
+
+	// The following fields are set only in AfterStep
+	Duration time.Duration
+	When     time.Duration
+	Error    error
+}
+
+// StepHook is called around every step run of the manager the hook is added to, so the cross-cutting concerns,
+// e.g. metrics or audit events, do not have to be implemented in every step
+type StepHook interface {
+	// BeforeStep is called before the step run, the returned context is passed to the step and to AfterStep
+	BeforeStep(ctx context.Context, step StepInfo) context.Context
+	// AfterStep is called after the step run, also when the step failed or exceeded its timeout
+	AfterStep(ctx context.Context, step StepInfo)
+}
+
+// StepHooks is the list of the hooks of the manager
+type StepHooks []StepHook
+
+// Before calls BeforeStep of all hooks in the order they were added
+func (h StepHooks) Before(ctx context.Context, step StepInfo) context.Context {
+	for _, hook := range h {
+		ctx = hook.BeforeStep(ctx, step)
+	}
+	return ctx
+}
+
+// After calls AfterStep of all hooks in the reversed order, so the first added hook wraps all the others
+func (h StepHooks) After(ctx context.Context, step StepInfo) {
+	for i := len(h) - 1; i >= 0; i-- {
+		h[i].AfterStep(ctx, step)
+	}
+}
@@ -0,0 +1,52 @@
+package process
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type ctxKey string
+
+type recordingHook struct {
+	name  string
+	calls *[]string
+}
+
+func (h recordingHook) BeforeStep(ctx context.Context, step StepInfo) context.Context {
+	*h.calls = append(*h.calls, "before "+h.name)
+	return context.WithValue(ctx, ctxKey(h.name), step.StepName)
+}
+
+func (h recordingHook) AfterStep(ctx context.Context, step StepInfo) {
+	*h.calls = append(*h.calls, "after "+h.name)
+}
+
+func TestStepHooks(t *testing.T) {
+	// given
+	var calls []string
+	hooks := StepHooks{recordingHook{name: "first", calls: &calls}, recordingHook{name: "second", calls: &calls}}
+
+	// when
+	ctx := hooks.Before(context.Background(), StepInfo{StepName: "step"})
+	hooks.After(ctx, StepInfo{StepName: "step"})
+
+	// then
+	assert.Equal(t, []string{"before first", "before second", "after second", "after first"}, calls)
+	assert.Equal(t, "step", ctx.Value(ctxKey("first")))
+	assert.Equal(t, "step", ctx.Value(ctxKey("second")))
+}
+
+func TestStepHooks_Empty(t *testing.T) {
+	// given
+	var hooks StepHooks
+	parent := context.Background()
+
+	// when
+	ctx := hooks.Before(parent, StepInfo{})
+	hooks.After(ctx, StepInfo{})
+
+	// then
+	assert.Equal(t, parent, ctx)
+}
@@ -0,0 +1,75 @@
+package process
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/logger"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+type timeoutStep struct {
+	timeout time.Duration
+}
+
+func (s timeoutStep) Timeout() time.Duration {
+	return s.timeout
+}
+
+func TestStepTimeout(t *testing.T) {
+	assert.Equal(t, time.Minute, StepTimeout(timeoutStep{timeout: time.Minute}))
+	assert.Equal(t, DefaultStepTimeout, StepTimeout(timeoutStep{}), "the default timeout is used for not positive timeouts")
+	assert.Equal(t, DefaultStepTimeout, StepTimeout(struct{}{}))
+}
+
+func TestRunStep(t *testing.T) {
+	t.Run("should return when the run finishes", func(t *testing.T) {
+		// given
+		var deadline time.Time
+		var hasDeadline bool
+
+		// when
+		err := RunStep(context.Background(), timeoutStep{timeout: time.Minute}, func(ctx context.Context) {
+			deadline, hasDeadline = ctx.Deadline()
+		})
+
+		// then
+		assert.NoError(t, err)
+		assert.True(t, hasDeadline)
+		assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, 5*time.Second)
+	})
+
+	t.Run("should report the run exceeding the timeout", func(t *testing.T) {
+		// given
+		returned := false
+		spy := logger.NewLogSpy()
+		ctx := logger.AddToContext(context.Background(), spy.Logger)
+
+		// when
+		err := RunStep(ctx, timeoutStep{timeout: 10 * time.Millisecond}, func(ctx context.Context) {
+			<-ctx.Done()
+			returned = true
+		})
+
+		// then
+		assert.Equal(t, ErrStepTimeout, errors.Cause(err))
+		assert.True(t, returned, "the run must be waited for")
+		spy.AssertLogged(t, logrus.WarnLevel, "Step did not finish in 10ms")
+	})
+
+	t.Run("should not report the run cancelled by the parent context", func(t *testing.T) {
+		// given
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// when
+		err := RunStep(ctx, timeoutStep{timeout: time.Minute}, func(ctx context.Context) {})
+
+		// then
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,91 @@
+package suspension
+
+import (
+	"context"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+
+	gardenerapi "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	"github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ShootClient is the interface to get and update the shoots in the Gardener cluster
+type ShootClient interface {
+	Get(name string, options metav1.GetOptions) (*gardenerapi.Shoot, error)
+	Update(shoot *gardenerapi.Shoot) (*gardenerapi.Shoot, error)
+}
+
+// HibernateShootStep enables the hibernation of the shoot when the runtime is suspended and disables it when
+// the runtime is resumed, the state of the shoot is checked by the initialisation step
+type HibernateShootStep struct {
+	operationManager *process.SuspensionOperationManager
+	shoots           ShootClient
+}
+
+func NewHibernateShootStep(os storage.Operations, shoots ShootClient) *HibernateShootStep {
+	return &HibernateShootStep{
+		operationManager: process.NewSuspensionOperationManager(os),
+		shoots:           shoots,
+	}
+}
+
+func (s *HibernateShootStep) Name() string {
+	return "Hibernate_Shoot"
+}
+
+func (s *HibernateShootStep) Run(operation internal.SuspensionOperation, log logrus.FieldLogger) (internal.SuspensionOperation, time.Duration, error) {
+	return s.RunWithContext(context.Background(), operation, log)
+}
+
+// RunWithContext runs the step with the storage calls recorded in the trace of the given context
+func (s *HibernateShootStep) RunWithContext(ctx context.Context, operation internal.SuspensionOperation, log logrus.FieldLogger) (internal.SuspensionOperation, time.Duration, error) {
+	step := *s
+	step.operationManager = s.operationManager.WithContext(ctx)
+	return step.run(operation, log)
+}
+
+func (s *HibernateShootStep) run(operation internal.SuspensionOperation, log logrus.FieldLogger) (internal.SuspensionOperation, time.Duration, error) {
+	if operation.HibernationRequested {
+		return operation, 0, nil
+	}
+	if operation.ShootName == "" {
+		return s.operationManager.OperationFailed(operation, "the runtime has no shoot")
+	}
+	log = log.WithField("shoot", operation.ShootName)
+
+	shoot, err := s.shoots.Get(operation.ShootName, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		return s.operationManager.OperationFailed(operation, "the shoot of the runtime does not exist")
+	case err != nil:
+		log.Errorf("unable to get shoot: %s", err)
+		return operation, 10 * time.Second, nil
+	}
+
+	hibernated := operation.Suspends()
+	if shoot.Spec.Hibernation == nil {
+		shoot.Spec.Hibernation = &gardenerapi.Hibernation{}
+	}
+	shoot.Spec.Hibernation.Enabled = &hibernated
+	_, err = s.shoots.Update(shoot)
+	if err != nil {
+		// the conflict is returned if the shoot was changed in the meantime, the shoot is read again
+		log.Errorf("unable to update hibernation of shoot: %s", err)
+		return operation, 10 * time.Second, nil
+	}
+	log.Infof("hibernation of the shoot set to %t", hibernated)
+
+	operation.HibernationRequested = true
+	operation, repeat := s.operationManager.UpdateOperation(operation)
+	if repeat != 0 {
+		log.Errorf("cannot save the hibernation request")
+		return operation, 5 * time.Second, nil
+	}
+
+	return operation, 1 * time.Minute, nil
+}
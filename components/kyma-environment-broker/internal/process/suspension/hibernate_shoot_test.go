@@ -0,0 +1,138 @@
+package suspension
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/ptr"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+
+	gardenerapi "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	gardenerfake "github.com/gardener/gardener/pkg/client/core/clientset/versioned/fake"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	fixSuspensionOperationID = "5c3b3e9e-2e4d-4a4b-9a3e-5b7c2f0c1d7a"
+	fixInstanceID            = "9d75a545-2e1e-4786-abd8-a37b14e185b9"
+	fixRuntimeID             = "ef4e3210-652c-453e-8015-bba1c1cd1e1c"
+	fixShootName             = "c-1a2b3c4"
+	fixGardenerNamespace     = "garden-kyma"
+)
+
+func TestHibernateShootStep_Run(t *testing.T) {
+	for action, expected := range map[string]bool{
+		internal.SuspensionActionSuspend: true,
+		internal.SuspensionActionResume:  false,
+	} {
+		t.Run(action, func(t *testing.T) {
+			// given
+			memoryStorage := storage.NewMemoryStorage()
+			operation := fixSuspensionOperation(action)
+			err := memoryStorage.Operations().InsertSuspensionOperation(operation)
+			require.NoError(t, err)
+
+			shoots := gardenerfake.NewSimpleClientset(fixShoot(!expected)).CoreV1beta1().Shoots(fixGardenerNamespace)
+			step := NewHibernateShootStep(memoryStorage.Operations(), shoots)
+
+			// when
+			result, repeat, err := step.Run(operation, logrus.New())
+
+			// then
+			require.NoError(t, err)
+			assert.Equal(t, time.Minute, repeat)
+			assert.Equal(t, domain.InProgress, result.State)
+			assert.True(t, result.HibernationRequested)
+
+			shoot, err := shoots.Get(fixShootName, metav1.GetOptions{})
+			require.NoError(t, err)
+			require.NotNil(t, shoot.Spec.Hibernation)
+			assert.Equal(t, expected, *shoot.Spec.Hibernation.Enabled)
+			assert.Len(t, shoot.Spec.Hibernation.Schedules, 1, "the hibernation schedules must not be changed")
+
+			stored, err := memoryStorage.Operations().GetSuspensionOperationByID(fixSuspensionOperationID)
+			require.NoError(t, err)
+			assert.True(t, stored.HibernationRequested)
+		})
+	}
+
+	t.Run("should not change the shoot again", func(t *testing.T) {
+		// given
+		operation := fixSuspensionOperation(internal.SuspensionActionSuspend)
+		operation.HibernationRequested = true
+		shoots := gardenerfake.NewSimpleClientset(fixShoot(false)).CoreV1beta1().Shoots(fixGardenerNamespace)
+		step := NewHibernateShootStep(storage.NewMemoryStorage().Operations(), shoots)
+
+		// when
+		_, repeat, err := step.Run(operation, logrus.New())
+
+		// then
+		require.NoError(t, err)
+		assert.Zero(t, repeat)
+		shoot, err := shoots.Get(fixShootName, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.False(t, *shoot.Spec.Hibernation.Enabled)
+	})
+
+	t.Run("should fail operation when the shoot does not exist", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		operation := fixSuspensionOperation(internal.SuspensionActionSuspend)
+		err := memoryStorage.Operations().InsertSuspensionOperation(operation)
+		require.NoError(t, err)
+
+		step := NewHibernateShootStep(memoryStorage.Operations(), gardenerfake.NewSimpleClientset().CoreV1beta1().Shoots(fixGardenerNamespace))
+
+		// when
+		result, _, err := step.Run(operation, logrus.New())
+
+		// then
+		assert.Error(t, err)
+		assert.Equal(t, domain.Failed, result.State)
+	})
+}
+
+func fixSuspensionOperation(action string) internal.SuspensionOperation {
+	return internal.SuspensionOperation{
+		Operation: internal.Operation{
+			ID:          fixSuspensionOperationID,
+			InstanceID:  fixInstanceID,
+			State:       domain.InProgress,
+			Description: "Operation created",
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+		},
+		RuntimeID: fixRuntimeID,
+		ShootName: fixShootName,
+		Action:    action,
+	}
+}
+
+func fixShoot(hibernated bool) *gardenerapi.Shoot {
+	return &gardenerapi.Shoot{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fixShootName,
+			Namespace: fixGardenerNamespace,
+		},
+		Spec: gardenerapi.ShootSpec{
+			Hibernation: &gardenerapi.Hibernation{
+				Enabled: &hibernated,
+				Schedules: []gardenerapi.HibernationSchedule{
+					{Start: ptr.String("00 20 * * *")},
+				},
+			},
+		},
+		Status: gardenerapi.ShootStatus{
+			IsHibernated: hibernated,
+			LastOperation: &gardenerapi.LastOperation{
+				Type:  gardenerapi.LastOperationTypeReconcile,
+				State: gardenerapi.LastOperationStateSucceeded,
+			},
+		},
+	}
+}
@@ -0,0 +1,87 @@
+package suspension
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+
+	gardenerapi "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	"github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// the time after which the operation is marked as expired
+	SuspensionTimeout = 1 * time.Hour
+)
+
+// InitialisationStep checks the state of the shoot after its hibernation was changed and finishes the operation
+// when Gardener reconciled the shoot, i.e. the cluster was hibernated or woken up
+type InitialisationStep struct {
+	operationManager *process.SuspensionOperationManager
+	shoots           ShootClient
+}
+
+func NewInitialisationStep(os storage.Operations, shoots ShootClient) *InitialisationStep {
+	return &InitialisationStep{
+		operationManager: process.NewSuspensionOperationManager(os),
+		shoots:           shoots,
+	}
+}
+
+func (s *InitialisationStep) Name() string {
+	return "Suspension_Initialization"
+}
+
+func (s *InitialisationStep) Run(operation internal.SuspensionOperation, log logrus.FieldLogger) (internal.SuspensionOperation, time.Duration, error) {
+	return s.RunWithContext(context.Background(), operation, log)
+}
+
+// RunWithContext runs the step with the storage calls recorded in the trace of the given context
+func (s *InitialisationStep) RunWithContext(ctx context.Context, operation internal.SuspensionOperation, log logrus.FieldLogger) (internal.SuspensionOperation, time.Duration, error) {
+	step := *s
+	step.operationManager = s.operationManager.WithContext(ctx)
+	return step.run(operation, log)
+}
+
+func (s *InitialisationStep) run(operation internal.SuspensionOperation, log logrus.FieldLogger) (internal.SuspensionOperation, time.Duration, error) {
+	if time.Since(operation.TimeoutStart()) > SuspensionTimeout {
+		log.Infof("operation has reached the time limit: operation started at: %s", operation.TimeoutStart())
+		return s.operationManager.OperationFailed(operation, fmt.Sprintf("operation has reached the time limit: %s", SuspensionTimeout))
+	}
+
+	if !operation.HibernationRequested {
+		return operation, 0, nil
+	}
+
+	shoot, err := s.shoots.Get(operation.ShootName, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		return s.operationManager.OperationFailed(operation, "the shoot of the runtime does not exist")
+	case err != nil:
+		log.Errorf("unable to get shoot: %s", err)
+		return operation, 10 * time.Second, nil
+	}
+
+	lastOperation := shoot.Status.LastOperation
+	switch {
+	case shoot.Status.ObservedGeneration < shoot.Generation || lastOperation == nil:
+		log.Info("shoot reconciliation is not started yet")
+		return operation, 1 * time.Minute, nil
+	case lastOperation.State == gardenerapi.LastOperationStateFailed:
+		return s.operationManager.OperationFailed(operation, fmt.Sprintf("shoot reconciliation failed: %s", lastOperation.Description))
+	case lastOperation.State != gardenerapi.LastOperationStateSucceeded || shoot.Status.IsHibernated != operation.Suspends():
+		log.Infof("shoot reconciliation is in state %s", lastOperation.State)
+		return operation, 1 * time.Minute, nil
+	}
+
+	if operation.Suspends() {
+		return s.operationManager.OperationSucceeded(operation, "Runtime suspended")
+	}
+	return s.operationManager.OperationSucceeded(operation, "Runtime resumed")
+}
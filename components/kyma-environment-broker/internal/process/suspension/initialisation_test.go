@@ -0,0 +1,129 @@
+package suspension
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+
+	gardenerapi "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	gardenerfake "github.com/gardener/gardener/pkg/client/core/clientset/versioned/fake"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInitialisationStep_Run(t *testing.T) {
+	for name, tc := range map[string]struct {
+		action         string
+		hibernated     bool
+		shootState     gardenerapi.LastOperationState
+		expectedState  domain.LastOperationState
+		expectedRepeat time.Duration
+	}{
+		"should wait for the hibernation in progress": {
+			action:         internal.SuspensionActionSuspend,
+			hibernated:     false,
+			shootState:     gardenerapi.LastOperationStateProcessing,
+			expectedState:  domain.InProgress,
+			expectedRepeat: time.Minute,
+		},
+		"should wait until the shoot is hibernated": {
+			action:         internal.SuspensionActionSuspend,
+			hibernated:     false,
+			shootState:     gardenerapi.LastOperationStateSucceeded,
+			expectedState:  domain.InProgress,
+			expectedRepeat: time.Minute,
+		},
+		"should mark operation as succeeded when the shoot is hibernated": {
+			action:        internal.SuspensionActionSuspend,
+			hibernated:    true,
+			shootState:    gardenerapi.LastOperationStateSucceeded,
+			expectedState: domain.Succeeded,
+		},
+		"should mark operation as succeeded when the shoot is woken up": {
+			action:        internal.SuspensionActionResume,
+			hibernated:    false,
+			shootState:    gardenerapi.LastOperationStateSucceeded,
+			expectedState: domain.Succeeded,
+		},
+		"should mark operation as failed when the shoot reconciliation failed": {
+			action:        internal.SuspensionActionResume,
+			hibernated:    true,
+			shootState:    gardenerapi.LastOperationStateFailed,
+			expectedState: domain.Failed,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// given
+			memoryStorage := storage.NewMemoryStorage()
+			operation := fixSuspensionOperation(tc.action)
+			operation.HibernationRequested = true
+			err := memoryStorage.Operations().InsertSuspensionOperation(operation)
+			require.NoError(t, err)
+
+			shoot := fixShoot(tc.hibernated)
+			shoot.Status.LastOperation.State = tc.shootState
+			step := NewInitialisationStep(memoryStorage.Operations(), gardenerfake.NewSimpleClientset(shoot).CoreV1beta1().Shoots(fixGardenerNamespace))
+
+			// when
+			result, repeat, _ := step.Run(operation, logrus.New())
+
+			// then
+			assert.Equal(t, tc.expectedRepeat, repeat)
+			assert.Equal(t, tc.expectedState, result.State)
+		})
+	}
+
+	t.Run("should wait until the changed shoot is observed by Gardener", func(t *testing.T) {
+		// given
+		operation := fixSuspensionOperation(internal.SuspensionActionSuspend)
+		operation.HibernationRequested = true
+		shoot := fixShoot(false)
+		shoot.Generation = 2
+		shoot.Status.ObservedGeneration = 1
+		step := NewInitialisationStep(storage.NewMemoryStorage().Operations(), gardenerfake.NewSimpleClientset(shoot).CoreV1beta1().Shoots(fixGardenerNamespace))
+
+		// when
+		result, repeat, err := step.Run(operation, logrus.New())
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, time.Minute, repeat)
+		assert.Equal(t, domain.InProgress, result.State)
+	})
+
+	t.Run("should continue when the hibernation was not requested", func(t *testing.T) {
+		// given
+		operation := fixSuspensionOperation(internal.SuspensionActionSuspend)
+		step := NewInitialisationStep(storage.NewMemoryStorage().Operations(), gardenerfake.NewSimpleClientset().CoreV1beta1().Shoots(fixGardenerNamespace))
+
+		// when
+		result, repeat, err := step.Run(operation, logrus.New())
+
+		// then
+		require.NoError(t, err)
+		assert.Zero(t, repeat)
+		assert.Equal(t, domain.InProgress, result.State)
+	})
+
+	t.Run("should fail operation when the time limit is reached", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		operation := fixSuspensionOperation(internal.SuspensionActionSuspend)
+		operation.CreatedAt = time.Now().Add(-SuspensionTimeout - time.Minute)
+		err := memoryStorage.Operations().InsertSuspensionOperation(operation)
+		require.NoError(t, err)
+
+		step := NewInitialisationStep(memoryStorage.Operations(), gardenerfake.NewSimpleClientset().CoreV1beta1().Shoots(fixGardenerNamespace))
+
+		// when
+		result, _, err := step.Run(operation, logrus.New())
+
+		// then
+		assert.Error(t, err)
+		assert.Equal(t, domain.Failed, result.State)
+	})
+}
@@ -0,0 +1,111 @@
+package update
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/provisioner"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/provisioner/pkg/gqlschema"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// the time after which the operation is marked as expired
+	UpdateTimeout = 3 * time.Hour
+)
+
+// InitialisationStep checks the status of the shoot upgrade in the Provisioner and finishes the operation when
+// the upgrade is finished. The parameters of the instance are changed only when the upgrade succeeded.
+type InitialisationStep struct {
+	operationManager  *process.UpdatingOperationManager
+	instanceStorage   storage.Instances
+	provisionerClient provisioner.Client
+}
+
+func NewInitialisationStep(os storage.Operations, is storage.Instances, cli provisioner.Client) *InitialisationStep {
+	return &InitialisationStep{
+		operationManager:  process.NewUpdatingOperationManager(os),
+		instanceStorage:   is,
+		provisionerClient: cli,
+	}
+}
+
+func (s *InitialisationStep) Name() string {
+	return "Update_Initialization"
+}
+
+func (s *InitialisationStep) Run(operation internal.UpdatingOperation, log logrus.FieldLogger) (internal.UpdatingOperation, time.Duration, error) {
+	return s.RunWithContext(context.Background(), operation, log)
+}
+
+// RunWithContext runs the step with the Provisioner calls sent with the given context
+func (s *InitialisationStep) RunWithContext(ctx context.Context, operation internal.UpdatingOperation, log logrus.FieldLogger) (internal.UpdatingOperation, time.Duration, error) {
+	step := *s
+	step.operationManager = s.operationManager.WithContext(ctx)
+	step.provisionerClient = provisioner.WithContext(s.provisionerClient, ctx)
+	return step.run(operation, log)
+}
+
+func (s *InitialisationStep) run(operation internal.UpdatingOperation, log logrus.FieldLogger) (internal.UpdatingOperation, time.Duration, error) {
+	if time.Since(operation.TimeoutStart()) > UpdateTimeout {
+		log.Infof("operation has reached the time limit: operation started at: %s", operation.TimeoutStart())
+		return s.operationManager.OperationFailed(operation, fmt.Sprintf("operation has reached the time limit: %s", UpdateTimeout))
+	}
+
+	if operation.ProvisionerOperationID == "" {
+		return operation, 0, nil
+	}
+
+	instance, err := s.instanceStorage.GetByID(operation.InstanceID)
+	if err != nil {
+		log.Errorf("unable to get instance from storage: %s", err)
+		return operation, 10 * time.Second, nil
+	}
+
+	status, err := s.provisionerClient.RuntimeOperationStatus(instance.Tenant(), operation.ProvisionerOperationID)
+	if err != nil {
+		log.Errorf("call to provisioner RuntimeOperationStatus failed: %s", err)
+		return operation, 1 * time.Minute, nil
+	}
+	log.Infof("call to provisioner returned %s status", status.State.String())
+
+	var msg string
+	if status.Message != nil {
+		msg = *status.Message
+	}
+
+	switch status.State {
+	case gqlschema.OperationStateSucceeded:
+		return s.updateInstance(operation, instance, log)
+	case gqlschema.OperationStateInProgress, gqlschema.OperationStatePending:
+		return operation, 1 * time.Minute, nil
+	case gqlschema.OperationStateFailed:
+		return s.operationManager.OperationFailed(operation, fmt.Sprintf("provisioner client returns failed status: %s", msg))
+	}
+
+	return s.operationManager.OperationFailed(operation, fmt.Sprintf("unsupported provisioner client status: %s", status.State.String()))
+}
+
+// updateInstance stores the changed parameters in the provisioning parameters of the instance, so the following
+// operations, e.g. the Kyma upgrade, use the current parameters of the cluster
+func (s *InitialisationStep) updateInstance(operation internal.UpdatingOperation, instance *internal.Instance, log logrus.FieldLogger) (internal.UpdatingOperation, time.Duration, error) {
+	pp, err := instance.GetProvisioningParameters()
+	if err != nil {
+		return s.operationManager.OperationFailed(operation, "invalid provisioning parameters of the instance")
+	}
+	operation.UpdatingParameters.ApplyTo(&pp.Parameters)
+	if err := instance.SetProvisioningParameters(pp); err != nil {
+		return s.operationManager.OperationFailed(operation, "cannot set provisioning parameters of the instance")
+	}
+	if err := s.instanceStorage.Update(*instance); err != nil {
+		log.Errorf("unable to update instance in storage: %s", err)
+		return operation, 10 * time.Second, nil
+	}
+
+	return s.operationManager.OperationSucceeded(operation, "Operation succeeded")
+}
@@ -0,0 +1,115 @@
+package update
+
+import (
+	"testing"
+	"time"
+
+	provisionerAutomock "github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/provisioner/automock"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/ptr"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/provisioner/pkg/gqlschema"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInitialisationStep_Run(t *testing.T) {
+	for name, tc := range map[string]struct {
+		provisionerState gqlschema.OperationState
+		expectedState    domain.LastOperationState
+		expectedRepeat   time.Duration
+		expectedMin      int
+	}{
+		"should wait for the shoot upgrade in progress": {
+			provisionerState: gqlschema.OperationStateInProgress,
+			expectedState:    domain.InProgress,
+			expectedRepeat:   time.Minute,
+			expectedMin:      2,
+		},
+		"should mark operation as succeeded and update the instance when the shoot upgrade succeeded": {
+			provisionerState: gqlschema.OperationStateSucceeded,
+			expectedState:    domain.Succeeded,
+			expectedMin:      3,
+		},
+		"should mark operation as failed when the shoot upgrade failed": {
+			provisionerState: gqlschema.OperationStateFailed,
+			expectedState:    domain.Failed,
+			expectedMin:      2,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// given
+			memoryStorage := storage.NewMemoryStorage()
+			err := memoryStorage.Instances().Insert(fixInstance())
+			require.NoError(t, err)
+
+			operation := fixUpdatingOperation()
+			operation.ProvisionerOperationID = fixProvisionerOperationID
+			err = memoryStorage.Operations().InsertUpdatingOperation(operation)
+			require.NoError(t, err)
+
+			provisionerClient := &provisionerAutomock.Client{}
+			provisionerClient.On("RuntimeOperationStatus", fixGlobalAccountID, fixProvisionerOperationID).Return(gqlschema.OperationStatus{
+				ID:      ptr.String(fixProvisionerOperationID),
+				State:   tc.provisionerState,
+				Message: ptr.String("message"),
+			}, nil).Once()
+			defer provisionerClient.AssertExpectations(t)
+
+			step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Instances(), provisionerClient)
+
+			// when
+			result, repeat, _ := step.Run(operation, logrus.New())
+
+			// then
+			assert.Equal(t, tc.expectedRepeat, repeat)
+			assert.Equal(t, tc.expectedState, result.State)
+
+			instance, err := memoryStorage.Instances().GetByID(fixInstanceID)
+			require.NoError(t, err)
+			pp, err := instance.GetProvisioningParameters()
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedMin, *pp.Parameters.AutoScalerMin)
+			assert.Equal(t, "Standard_D8_v3", *pp.Parameters.MachineType, "the parameters which are not updated must not change")
+		})
+	}
+
+	t.Run("should continue when the shoot upgrade was not triggered", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		operation := fixUpdatingOperation()
+
+		provisionerClient := &provisionerAutomock.Client{}
+		defer provisionerClient.AssertExpectations(t)
+
+		step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Instances(), provisionerClient)
+
+		// when
+		result, repeat, err := step.Run(operation, logrus.New())
+
+		// then
+		require.NoError(t, err)
+		assert.Zero(t, repeat)
+		assert.Equal(t, domain.InProgress, result.State)
+	})
+
+	t.Run("should fail operation when the time limit is reached", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+
+		operation := fixUpdatingOperation()
+		operation.CreatedAt = time.Now().Add(-UpdateTimeout - time.Minute)
+		err := memoryStorage.Operations().InsertUpdatingOperation(operation)
+		require.NoError(t, err)
+
+		step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Instances(), &provisionerAutomock.Client{})
+
+		// when
+		result, _, err := step.Run(operation, logrus.New())
+
+		// then
+		assert.Error(t, err)
+		assert.Equal(t, domain.Failed, result.State)
+	})
+}
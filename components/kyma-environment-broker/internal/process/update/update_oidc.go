@@ -0,0 +1,110 @@
+package update
+
+import (
+	"context"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+
+	gardenerapi "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	"github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ShootClient is the interface to get and update the shoots in the Gardener cluster
+type ShootClient interface {
+	Get(name string, options metav1.GetOptions) (*gardenerapi.Shoot, error)
+	Update(shoot *gardenerapi.Shoot) (*gardenerapi.Shoot, error)
+}
+
+// UpdateOIDCStep sets the OIDC configuration of the kube-apiserver in the shoot, because the shoot upgrade
+// in the Provisioner does not support it. The Provisioner keeps the configuration when it upgrades the shoot,
+// and its upgrade finishes when Gardener reconciled the shoot with both changes.
+type UpdateOIDCStep struct {
+	operationManager *process.UpdatingOperationManager
+	shoots           ShootClient
+}
+
+func NewUpdateOIDCStep(os storage.Operations, shoots ShootClient) *UpdateOIDCStep {
+	return &UpdateOIDCStep{
+		operationManager: process.NewUpdatingOperationManager(os),
+		shoots:           shoots,
+	}
+}
+
+func (s *UpdateOIDCStep) Name() string {
+	return "Update_OIDC"
+}
+
+func (s *UpdateOIDCStep) Run(operation internal.UpdatingOperation, log logrus.FieldLogger) (internal.UpdatingOperation, time.Duration, error) {
+	return s.RunWithContext(context.Background(), operation, log)
+}
+
+// RunWithContext runs the step with the storage calls recorded in the trace of the given context
+func (s *UpdateOIDCStep) RunWithContext(ctx context.Context, operation internal.UpdatingOperation, log logrus.FieldLogger) (internal.UpdatingOperation, time.Duration, error) {
+	step := *s
+	step.operationManager = s.operationManager.WithContext(ctx)
+	return step.run(operation, log)
+}
+
+func (s *UpdateOIDCStep) run(operation internal.UpdatingOperation, log logrus.FieldLogger) (internal.UpdatingOperation, time.Duration, error) {
+	oidc := operation.UpdatingParameters.OIDC
+	if oidc == nil || operation.OIDCConfigApplied || operation.ProvisionerOperationID != "" {
+		return operation, 0, nil
+	}
+	if operation.ShootName == "" {
+		return s.operationManager.OperationFailed(operation, "the runtime has no shoot")
+	}
+	log = log.WithField("shoot", operation.ShootName)
+
+	shoot, err := s.shoots.Get(operation.ShootName, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		return s.operationManager.OperationFailed(operation, "the shoot of the runtime does not exist")
+	case err != nil:
+		log.Errorf("unable to get shoot: %s", err)
+		return operation, 10 * time.Second, nil
+	}
+
+	if shoot.Spec.Kubernetes.KubeAPIServer == nil {
+		shoot.Spec.Kubernetes.KubeAPIServer = &gardenerapi.KubeAPIServerConfig{}
+	}
+	shoot.Spec.Kubernetes.KubeAPIServer.OIDCConfig = shootOIDCConfig(*oidc)
+	_, err = s.shoots.Update(shoot)
+	if err != nil {
+		// the conflict is returned if the shoot was changed in the meantime, the shoot is read again
+		log.Errorf("unable to update OIDC configuration of shoot: %s", err)
+		return operation, 10 * time.Second, nil
+	}
+	log.Infof("OIDC configuration of the shoot set to issuer %s and client %s", oidc.IssuerURL, oidc.ClientID)
+
+	operation.OIDCConfigApplied = true
+	operation, repeat := s.operationManager.UpdateOperation(operation)
+	if repeat != 0 {
+		log.Errorf("cannot save the OIDC configuration update")
+		return operation, 5 * time.Second, nil
+	}
+
+	return operation, 0, nil
+}
+
+func shootOIDCConfig(oidc internal.OIDCConfigDTO) *gardenerapi.OIDCConfig {
+	config := &gardenerapi.OIDCConfig{
+		ClientID:    &oidc.ClientID,
+		IssuerURL:   &oidc.IssuerURL,
+		SigningAlgs: oidc.SigningAlgs,
+	}
+	if oidc.GroupsClaim != "" {
+		config.GroupsClaim = &oidc.GroupsClaim
+	}
+	if oidc.UsernameClaim != "" {
+		config.UsernameClaim = &oidc.UsernameClaim
+	}
+	if oidc.UsernamePrefix != "" {
+		config.UsernamePrefix = &oidc.UsernamePrefix
+	}
+	return config
+}
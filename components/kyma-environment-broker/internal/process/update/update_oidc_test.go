@@ -0,0 +1,117 @@
+package update
+
+import (
+	"testing"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+
+	gardenerapi "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	gardenerfake "github.com/gardener/gardener/pkg/client/core/clientset/versioned/fake"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	fixShootName         = "c-1a2b3c4"
+	fixGardenerNamespace = "garden-kyma"
+)
+
+func TestUpdateOIDCStep_Run(t *testing.T) {
+	t.Run("should set OIDC configuration in the shoot", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		operation := fixOIDCUpdatingOperation()
+		err := memoryStorage.Operations().InsertUpdatingOperation(operation)
+		require.NoError(t, err)
+
+		shoots := gardenerfake.NewSimpleClientset(fixShoot()).CoreV1beta1().Shoots(fixGardenerNamespace)
+		step := NewUpdateOIDCStep(memoryStorage.Operations(), shoots)
+
+		// when
+		result, repeat, err := step.Run(operation, logrus.New())
+
+		// then
+		require.NoError(t, err)
+		assert.Zero(t, repeat)
+		assert.True(t, result.OIDCConfigApplied)
+
+		shoot, err := shoots.Get(fixShootName, metav1.GetOptions{})
+		require.NoError(t, err)
+		require.NotNil(t, shoot.Spec.Kubernetes.KubeAPIServer)
+		oidc := shoot.Spec.Kubernetes.KubeAPIServer.OIDCConfig
+		require.NotNil(t, oidc)
+		assert.Equal(t, "client", *oidc.ClientID)
+		assert.Equal(t, "https://issuer.example.com", *oidc.IssuerURL)
+		assert.Equal(t, "groups", *oidc.GroupsClaim)
+		assert.Equal(t, []string{"RS256"}, oidc.SigningAlgs)
+		assert.Nil(t, oidc.UsernamePrefix)
+
+		stored, err := memoryStorage.Operations().GetUpdatingOperationByID(operation.ID)
+		require.NoError(t, err)
+		assert.True(t, stored.OIDCConfigApplied)
+	})
+
+	t.Run("should skip the update without OIDC configuration", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		operation := fixUpdatingOperation()
+
+		shoots := gardenerfake.NewSimpleClientset().CoreV1beta1().Shoots(fixGardenerNamespace)
+		step := NewUpdateOIDCStep(memoryStorage.Operations(), shoots)
+
+		// when
+		result, repeat, err := step.Run(operation, logrus.New())
+
+		// then
+		require.NoError(t, err)
+		assert.Zero(t, repeat)
+		assert.False(t, result.OIDCConfigApplied)
+	})
+
+	t.Run("should fail when the shoot does not exist", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		operation := fixOIDCUpdatingOperation()
+		err := memoryStorage.Operations().InsertUpdatingOperation(operation)
+		require.NoError(t, err)
+
+		shoots := gardenerfake.NewSimpleClientset().CoreV1beta1().Shoots(fixGardenerNamespace)
+		step := NewUpdateOIDCStep(memoryStorage.Operations(), shoots)
+
+		// when
+		result, _, err := step.Run(operation, logrus.New())
+
+		// then
+		require.Error(t, err)
+		assert.Equal(t, domain.Failed, result.State)
+	})
+}
+
+func fixOIDCUpdatingOperation() internal.UpdatingOperation {
+	instance := fixInstance()
+	instance.ShootName = fixShootName
+	operation := internal.NewUpdatingOperation(instance, internal.UpdatingParametersDTO{
+		OIDC: &internal.OIDCConfigDTO{
+			ClientID:    "client",
+			IssuerURL:   "https://issuer.example.com",
+			GroupsClaim: "groups",
+			SigningAlgs: []string{"RS256"},
+		},
+	})
+	operation.ID = fixUpdatingOperationID
+
+	return operation
+}
+
+func fixShoot() *gardenerapi.Shoot {
+	return &gardenerapi.Shoot{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fixShootName,
+			Namespace: fixGardenerNamespace,
+		},
+	}
+}
@@ -0,0 +1,96 @@
+package update
+
+import (
+	"context"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/provisioner"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/provisioner/pkg/gqlschema"
+
+	"github.com/sirupsen/logrus"
+)
+
+// UpgradeShootStep triggers the upgrade of the shoot in the Provisioner with the changed parameters,
+// the status of the upgrade is checked by the initialisation step
+type UpgradeShootStep struct {
+	operationManager  *process.UpdatingOperationManager
+	instanceStorage   storage.Instances
+	provisionerClient provisioner.Client
+}
+
+func NewUpgradeShootStep(os storage.Operations, is storage.Instances, cli provisioner.Client) *UpgradeShootStep {
+	return &UpgradeShootStep{
+		operationManager:  process.NewUpdatingOperationManager(os),
+		instanceStorage:   is,
+		provisionerClient: cli,
+	}
+}
+
+func (s *UpgradeShootStep) Name() string {
+	return "Upgrade_Shoot"
+}
+
+func (s *UpgradeShootStep) Run(operation internal.UpdatingOperation, log logrus.FieldLogger) (internal.UpdatingOperation, time.Duration, error) {
+	return s.RunWithContext(context.Background(), operation, log)
+}
+
+// RunWithContext runs the step with the Provisioner calls sent with the given context
+func (s *UpgradeShootStep) RunWithContext(ctx context.Context, operation internal.UpdatingOperation, log logrus.FieldLogger) (internal.UpdatingOperation, time.Duration, error) {
+	step := *s
+	step.operationManager = s.operationManager.WithContext(ctx)
+	step.provisionerClient = provisioner.WithContext(s.provisionerClient, ctx)
+	return step.run(operation, log)
+}
+
+func (s *UpgradeShootStep) run(operation internal.UpdatingOperation, log logrus.FieldLogger) (internal.UpdatingOperation, time.Duration, error) {
+	if operation.ProvisionerOperationID != "" {
+		return operation, 0, nil
+	}
+	if operation.RuntimeID == "" {
+		return s.operationManager.OperationFailed(operation, "the instance has no runtime")
+	}
+	log = log.WithField("runtimeID", operation.RuntimeID)
+
+	instance, err := s.instanceStorage.GetByID(operation.InstanceID)
+	if err != nil {
+		log.Errorf("unable to get instance from storage: %s", err)
+		return operation, 10 * time.Second, nil
+	}
+
+	provisionerResponse, err := s.provisionerClient.UpgradeShoot(instance.Tenant(), operation.RuntimeID, upgradeShootInput(operation.UpdatingParameters))
+	if err != nil {
+		log.Errorf("call to provisioner UpgradeShoot failed: %s", err)
+		return operation, 1 * time.Minute, nil
+	}
+	if provisionerResponse.ID == nil {
+		log.Errorf("provisioner returned the shoot upgrade without the operation ID")
+		return operation, 1 * time.Minute, nil
+	}
+	operation.ProvisionerOperationID = *provisionerResponse.ID
+	log.Infof("fetched ProvisionerOperationID=%s", operation.ProvisionerOperationID)
+
+	operation, repeat := s.operationManager.UpdateOperation(operation)
+	if repeat != 0 {
+		log.Errorf("cannot save operation ID from provisioner")
+		return operation, 5 * time.Second, nil
+	}
+
+	return operation, 1 * time.Minute, nil
+}
+
+// upgradeShootInput does not contain the OIDC configuration, it is set in the shoot by the UpdateOIDCStep. The upgrade
+// without any changed parameter still waits for the reconciliation of the shoot.
+func upgradeShootInput(parameters internal.UpdatingParametersDTO) gqlschema.UpgradeShootInput {
+	return gqlschema.UpgradeShootInput{
+		GardenerConfig: &gqlschema.GardenerUpgradeInput{
+			MachineType:    parameters.MachineType,
+			AutoScalerMin:  parameters.AutoScalerMin,
+			AutoScalerMax:  parameters.AutoScalerMax,
+			MaxSurge:       parameters.MaxSurge,
+			MaxUnavailable: parameters.MaxUnavailable,
+		},
+	}
+}
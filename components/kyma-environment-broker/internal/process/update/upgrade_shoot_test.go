@@ -0,0 +1,108 @@
+package update
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/broker"
+	provisionerAutomock "github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/provisioner/automock"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/ptr"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/provisioner/pkg/gqlschema"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	fixUpdatingOperationID    = "a8a30d4b-58f6-48e0-9b4f-3d8c5e38fa1e"
+	fixInstanceID             = "9d75a545-2e1e-4786-abd8-a37b14e185b9"
+	fixRuntimeID              = "ef4e3210-652c-453e-8015-bba1c1cd1e1c"
+	fixGlobalAccountID        = "abf73c71-a653-4951-b9c2-a26d6c2cccbd"
+	fixProvisionerOperationID = "e04de524-53b3-4890-b05a-296be393e4ba"
+)
+
+func TestUpgradeShootStep_Run(t *testing.T) {
+	t.Run("should trigger upgrade of the shoot with the changed parameters", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		err := memoryStorage.Instances().Insert(fixInstance())
+		require.NoError(t, err)
+
+		operation := fixUpdatingOperation()
+		err = memoryStorage.Operations().InsertUpdatingOperation(operation)
+		require.NoError(t, err)
+
+		provisionerClient := &provisionerAutomock.Client{}
+		provisionerClient.On("UpgradeShoot", fixGlobalAccountID, fixRuntimeID, gqlschema.UpgradeShootInput{
+			GardenerConfig: &gqlschema.GardenerUpgradeInput{
+				AutoScalerMin: ptr.Integer(3),
+				AutoScalerMax: ptr.Integer(10),
+			},
+		}).Return(gqlschema.OperationStatus{
+			ID:        ptr.String(fixProvisionerOperationID),
+			Operation: gqlschema.OperationTypeUpgradeShoot,
+			State:     gqlschema.OperationStateInProgress,
+			RuntimeID: ptr.String(fixRuntimeID),
+		}, nil).Once()
+		defer provisionerClient.AssertExpectations(t)
+
+		step := NewUpgradeShootStep(memoryStorage.Operations(), memoryStorage.Instances(), provisionerClient)
+
+		// when
+		result, repeat, err := step.Run(operation, logrus.New())
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, time.Minute, repeat)
+		assert.Equal(t, fixProvisionerOperationID, result.ProvisionerOperationID)
+
+		stored, err := memoryStorage.Operations().GetUpdatingOperationByID(operation.ID)
+		require.NoError(t, err)
+		assert.Equal(t, fixProvisionerOperationID, stored.ProvisionerOperationID)
+	})
+
+	t.Run("should not trigger the upgrade again", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+
+		operation := fixUpdatingOperation()
+		operation.ProvisionerOperationID = fixProvisionerOperationID
+
+		provisionerClient := &provisionerAutomock.Client{}
+		defer provisionerClient.AssertExpectations(t)
+
+		step := NewUpgradeShootStep(memoryStorage.Operations(), memoryStorage.Instances(), provisionerClient)
+
+		// when
+		result, repeat, err := step.Run(operation, logrus.New())
+
+		// then
+		require.NoError(t, err)
+		assert.Zero(t, repeat)
+		assert.Equal(t, domain.InProgress, result.State)
+	})
+}
+
+func fixUpdatingOperation() internal.UpdatingOperation {
+	operation := internal.NewUpdatingOperation(fixInstance(), internal.UpdatingParametersDTO{
+		AutoScalerMin: ptr.Integer(3),
+		AutoScalerMax: ptr.Integer(10),
+	})
+	operation.ID = fixUpdatingOperationID
+
+	return operation
+}
+
+func fixInstance() internal.Instance {
+	return internal.Instance{
+		InstanceID:             fixInstanceID,
+		RuntimeID:              fixRuntimeID,
+		GlobalAccountID:        fixGlobalAccountID,
+		ServicePlanID:          broker.AzurePlanID,
+		ServicePlanName:        broker.AzurePlanName,
+		ProvisioningParameters: `{"plan_id":"4deee563-e5ec-4731-b9b1-53b42d855f0c","parameters":{"name":"cluster","autoScalerMin":2,"autoScalerMax":4,"machineType":"Standard_D8_v3"}}`,
+	}
+}
@@ -0,0 +1,119 @@
+package upgrade_cluster
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/provisioner"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
+	"github.com/kyma-project/control-plane/components/provisioner/pkg/gqlschema"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// the time after which the started cluster upgrade is marked as expired
+	UpgradeClusterTimeout = 3 * time.Hour
+)
+
+// InitialisationStep waits for the maintenance window of the operation and checks the status of the shoot upgrade
+// in the Provisioner once the upgrade is triggered by the UpgradeClusterStep
+type InitialisationStep struct {
+	operationManager  *process.UpgradeClusterOperationManager
+	operationStorage  storage.Operations
+	instanceStorage   storage.Instances
+	provisionerClient provisioner.Client
+}
+
+func NewInitialisationStep(os storage.Operations, is storage.Instances, cli provisioner.Client) *InitialisationStep {
+	return &InitialisationStep{
+		operationManager:  process.NewUpgradeClusterOperationManager(os),
+		operationStorage:  os,
+		instanceStorage:   is,
+		provisionerClient: cli,
+	}
+}
+
+func (s *InitialisationStep) Name() string {
+	return "Upgrade_Cluster_Initialisation"
+}
+
+func (s *InitialisationStep) Run(operation internal.UpgradeClusterOperation, log logrus.FieldLogger) (internal.UpgradeClusterOperation, time.Duration, error) {
+	return s.RunWithContext(context.Background(), operation, log)
+}
+
+// RunWithContext runs the step with the Provisioner calls sent with the given context
+func (s *InitialisationStep) RunWithContext(ctx context.Context, operation internal.UpgradeClusterOperation, log logrus.FieldLogger) (internal.UpgradeClusterOperation, time.Duration, error) {
+	step := *s
+	step.operationManager = s.operationManager.WithContext(ctx)
+	step.provisionerClient = provisioner.WithContext(s.provisionerClient, ctx)
+	return step.run(operation, log)
+}
+
+func (s *InitialisationStep) run(operation internal.UpgradeClusterOperation, log logrus.FieldLogger) (internal.UpgradeClusterOperation, time.Duration, error) {
+	if operation.ProvisionerOperationID == "" {
+		// if time window for this operation has finished we reprocess on next time window
+		if operation.MaintenanceWindowEnd.Before(time.Now()) {
+			updatedOperation, err := s.moveToNextMaintenanceWindow(operation)
+			if err != nil {
+				log.Errorf("while moving the operation to the next maintenance window: %s", err)
+				return operation, 5 * time.Second, nil
+			}
+			until := time.Until(updatedOperation.MaintenanceWindowBegin)
+			log.Infof("Upgrade cluster operation %s will be rescheduled in %v", operation.ID, until)
+			return *updatedOperation, until, nil
+		}
+		return operation, 0, nil
+	}
+
+	// the operation is updated for the last time when the upgrade is triggered
+	if time.Since(operation.UpdatedAt) > UpgradeClusterTimeout {
+		log.Infof("operation has reached the time limit: updated operation time: %s", operation.UpdatedAt)
+		return s.operationManager.OperationFailed(operation, fmt.Sprintf("operation has reached the time limit: %s", UpgradeClusterTimeout))
+	}
+
+	instance, err := s.instanceStorage.GetByID(operation.InstanceID)
+	switch {
+	case dberr.IsNotFound(err):
+		return s.operationManager.OperationFailed(operation, "instance was not found")
+	case err != nil:
+		log.Errorf("unable to get instance from storage: %s", err)
+		return operation, 10 * time.Second, nil
+	}
+
+	status, err := s.provisionerClient.RuntimeOperationStatus(instance.Tenant(), operation.ProvisionerOperationID)
+	if err != nil {
+		log.Errorf("call to provisioner RuntimeOperationStatus failed: %s", err)
+		return operation, 1 * time.Minute, nil
+	}
+	log.Infof("call to provisioner returned %s status", status.State.String())
+
+	var msg string
+	if status.Message != nil {
+		msg = *status.Message
+	}
+
+	switch status.State {
+	case gqlschema.OperationStateSucceeded:
+		return s.operationManager.OperationSucceeded(operation, "Operation succeeded")
+	case gqlschema.OperationStateInProgress, gqlschema.OperationStatePending:
+		return operation, 1 * time.Minute, nil
+	case gqlschema.OperationStateFailed:
+		return s.operationManager.OperationFailed(operation, fmt.Sprintf("provisioner client returns failed status: %s", msg))
+	}
+
+	return s.operationManager.OperationFailed(operation, fmt.Sprintf("unsupported provisioner client status: %s", status.State.String()))
+}
+
+func (s *InitialisationStep) moveToNextMaintenanceWindow(operation internal.UpgradeClusterOperation) (*internal.UpgradeClusterOperation, error) {
+	days := int(time.Since(operation.MaintenanceWindowEnd)/(24*time.Hour)) + 1
+
+	return storage.UpdateWithRetryUpgradeClusterOperation(s.operationStorage, operation.ID, func(latest *internal.UpgradeClusterOperation) {
+		latest.MaintenanceWindowBegin = operation.MaintenanceWindowBegin.AddDate(0, 0, days)
+		latest.MaintenanceWindowEnd = operation.MaintenanceWindowEnd.AddDate(0, 0, days)
+	})
+}
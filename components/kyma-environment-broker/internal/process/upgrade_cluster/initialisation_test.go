@@ -0,0 +1,125 @@
+package upgrade_cluster
+
+import (
+	"testing"
+	"time"
+
+	provisionerAutomock "github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/provisioner/automock"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/ptr"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/provisioner/pkg/gqlschema"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInitialisationStep_Run(t *testing.T) {
+	for name, tc := range map[string]struct {
+		provisionerState gqlschema.OperationState
+		expectedState    domain.LastOperationState
+		expectedRepeat   time.Duration
+	}{
+		"should wait for the shoot upgrade in progress": {
+			provisionerState: gqlschema.OperationStateInProgress,
+			expectedState:    domain.InProgress,
+			expectedRepeat:   time.Minute,
+		},
+		"should mark operation as succeeded when the shoot upgrade succeeded": {
+			provisionerState: gqlschema.OperationStateSucceeded,
+			expectedState:    domain.Succeeded,
+		},
+		"should mark operation as failed when the shoot upgrade failed": {
+			provisionerState: gqlschema.OperationStateFailed,
+			expectedState:    domain.Failed,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// given
+			memoryStorage := storage.NewMemoryStorage()
+			err := memoryStorage.Instances().Insert(fixInstance())
+			require.NoError(t, err)
+
+			operation := fixUpgradeClusterOperation()
+			operation.ProvisionerOperationID = fixProvisionerOperationID
+			err = memoryStorage.Operations().InsertUpgradeClusterOperation(operation)
+			require.NoError(t, err)
+
+			provisionerClient := &provisionerAutomock.Client{}
+			provisionerClient.On("RuntimeOperationStatus", fixGlobalAccountID, fixProvisionerOperationID).Return(gqlschema.OperationStatus{
+				ID:      ptr.String(fixProvisionerOperationID),
+				State:   tc.provisionerState,
+				Message: ptr.String("message"),
+			}, nil).Once()
+			defer provisionerClient.AssertExpectations(t)
+
+			step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Instances(), provisionerClient)
+
+			// when
+			result, repeat, _ := step.Run(operation, logrus.New())
+
+			// then
+			assert.Equal(t, tc.expectedRepeat, repeat)
+			assert.Equal(t, tc.expectedState, result.State)
+		})
+	}
+
+	t.Run("should continue when the shoot upgrade was not triggered", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		operation := fixUpgradeClusterOperation()
+
+		provisionerClient := &provisionerAutomock.Client{}
+		defer provisionerClient.AssertExpectations(t)
+
+		step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Instances(), provisionerClient)
+
+		// when
+		result, repeat, err := step.Run(operation, logrus.New())
+
+		// then
+		require.NoError(t, err)
+		assert.Zero(t, repeat)
+		assert.Equal(t, domain.InProgress, result.State)
+	})
+
+	t.Run("should move the operation to the next maintenance window when the window has passed", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		operation := fixUpgradeClusterOperation()
+		operation.MaintenanceWindowBegin = time.Now().Add(-3 * time.Hour)
+		operation.MaintenanceWindowEnd = time.Now().Add(-2 * time.Hour)
+		err := memoryStorage.Operations().InsertUpgradeClusterOperation(operation)
+		require.NoError(t, err)
+
+		step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Instances(), &provisionerAutomock.Client{})
+
+		// when
+		result, repeat, err := step.Run(operation, logrus.New())
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, repeat > 20*time.Hour)
+		assert.True(t, result.MaintenanceWindowBegin.After(time.Now()))
+	})
+
+	t.Run("should fail operation when the time limit is reached", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+
+		operation := fixUpgradeClusterOperation()
+		operation.ProvisionerOperationID = fixProvisionerOperationID
+		operation.UpdatedAt = time.Now().Add(-UpgradeClusterTimeout - time.Minute)
+		err := memoryStorage.Operations().InsertUpgradeClusterOperation(operation)
+		require.NoError(t, err)
+
+		step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Instances(), &provisionerAutomock.Client{})
+
+		// when
+		result, _, err := step.Run(operation, logrus.New())
+
+		// then
+		assert.Error(t, err)
+		assert.Equal(t, domain.Failed, result.State)
+	})
+}
@@ -0,0 +1,109 @@
+package upgrade_cluster
+
+import (
+	"context"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/provisioner"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
+	"github.com/kyma-project/control-plane/components/provisioner/pkg/gqlschema"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RateLimiter limits the Provisioner mutations triggered by the operations of one orchestration
+type RateLimiter interface {
+	// Reserve returns 0 if the mutation can be performed now, otherwise the time after which it should be retried
+	Reserve(orchestrationID string) time.Duration
+}
+
+// UpgradeClusterStep triggers the upgrade of the shoot in the Provisioner to the Kubernetes version
+// configured in the broker, the status of the upgrade is checked by the initialisation step
+type UpgradeClusterStep struct {
+	operationManager  *process.UpgradeClusterOperationManager
+	instanceStorage   storage.Instances
+	provisionerClient provisioner.Client
+	kubernetesVersion string
+	rateLimiter       RateLimiter
+}
+
+// NewUpgradeClusterStep creates the step triggering the upgradeShoot mutation, the rateLimiter is optional
+func NewUpgradeClusterStep(os storage.Operations, is storage.Instances, cli provisioner.Client, kubernetesVersion string, rateLimiter RateLimiter) *UpgradeClusterStep {
+	return &UpgradeClusterStep{
+		operationManager:  process.NewUpgradeClusterOperationManager(os),
+		instanceStorage:   is,
+		provisionerClient: cli,
+		kubernetesVersion: kubernetesVersion,
+		rateLimiter:       rateLimiter,
+	}
+}
+
+func (s *UpgradeClusterStep) Name() string {
+	return "Upgrade_Cluster"
+}
+
+func (s *UpgradeClusterStep) Run(operation internal.UpgradeClusterOperation, log logrus.FieldLogger) (internal.UpgradeClusterOperation, time.Duration, error) {
+	return s.RunWithContext(context.Background(), operation, log)
+}
+
+// RunWithContext runs the step with the Provisioner calls sent with the given context
+func (s *UpgradeClusterStep) RunWithContext(ctx context.Context, operation internal.UpgradeClusterOperation, log logrus.FieldLogger) (internal.UpgradeClusterOperation, time.Duration, error) {
+	step := *s
+	step.operationManager = s.operationManager.WithContext(ctx)
+	step.provisionerClient = provisioner.WithContext(s.provisionerClient, ctx)
+	return step.run(operation, log)
+}
+
+func (s *UpgradeClusterStep) run(operation internal.UpgradeClusterOperation, log logrus.FieldLogger) (internal.UpgradeClusterOperation, time.Duration, error) {
+	if operation.ProvisionerOperationID != "" {
+		return operation, 0, nil
+	}
+	if operation.DryRun {
+		return s.operationManager.OperationSucceeded(operation, "dry run succeeded")
+	}
+
+	instance, err := s.instanceStorage.GetByID(operation.InstanceID)
+	switch {
+	case dberr.IsNotFound(err):
+		return s.operationManager.OperationFailed(operation, "instance was not found")
+	case err != nil:
+		log.Errorf("unable to get instance from storage: %s", err)
+		return operation, 10 * time.Second, nil
+	}
+
+	if s.rateLimiter != nil {
+		if delay := s.rateLimiter.Reserve(operation.OrchestrationID); delay > 0 {
+			log.Infof("provisioner rate limit of the orchestration reached, retrying in %s", delay)
+			return operation, delay, nil
+		}
+	}
+	input := gqlschema.UpgradeShootInput{
+		GardenerConfig: &gqlschema.GardenerUpgradeInput{
+			KubernetesVersion: &s.kubernetesVersion,
+		},
+	}
+	provisionerResponse, err := s.provisionerClient.UpgradeShoot(instance.Tenant(), operation.RuntimeID, input)
+	if err != nil {
+		log.Errorf("call to provisioner UpgradeShoot failed: %s", err)
+		return operation, 1 * time.Minute, nil
+	}
+	if provisionerResponse.ID == nil {
+		log.Errorf("provisioner returned the shoot upgrade without the operation ID")
+		return operation, 1 * time.Minute, nil
+	}
+	operation.ProvisionerOperationID = *provisionerResponse.ID
+	operation.Description = "cluster upgrade in progress"
+	log.Infof("cluster upgrade to Kubernetes version %s started, ProvisionerOperationID=%s", s.kubernetesVersion, operation.ProvisionerOperationID)
+
+	operation, repeat := s.operationManager.UpdateOperation(operation)
+	if repeat != 0 {
+		log.Errorf("cannot save operation ID from provisioner")
+		return operation, 5 * time.Second, nil
+	}
+
+	// the initialisation step checks the status of the upgrade when the operation is run again
+	return operation, 1 * time.Minute, nil
+}
@@ -0,0 +1,175 @@
+package upgrade_cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/broker"
+	provisionerAutomock "github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/provisioner/automock"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/ptr"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/provisioner/pkg/gqlschema"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	fixUpgradeClusterOperationID = "a8a30d4b-58f6-48e0-9b4f-3d8c5e38fa1e"
+	fixOrchestrationID           = "c5d0a4b7-1c1e-4b6a-9a43-45a3b4f0c1d2"
+	fixInstanceID                = "9d75a545-2e1e-4786-abd8-a37b14e185b9"
+	fixRuntimeID                 = "ef4e3210-652c-453e-8015-bba1c1cd1e1c"
+	fixGlobalAccountID           = "abf73c71-a653-4951-b9c2-a26d6c2cccbd"
+	fixProvisionerOperationID    = "e04de524-53b3-4890-b05a-296be393e4ba"
+	fixKubernetesVersion         = "1.18.12"
+)
+
+func TestUpgradeClusterStep_Run(t *testing.T) {
+	t.Run("should trigger upgrade of the shoot to the configured Kubernetes version", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		err := memoryStorage.Instances().Insert(fixInstance())
+		require.NoError(t, err)
+
+		operation := fixUpgradeClusterOperation()
+		err = memoryStorage.Operations().InsertUpgradeClusterOperation(operation)
+		require.NoError(t, err)
+
+		provisionerClient := &provisionerAutomock.Client{}
+		provisionerClient.On("UpgradeShoot", fixGlobalAccountID, fixRuntimeID, gqlschema.UpgradeShootInput{
+			GardenerConfig: &gqlschema.GardenerUpgradeInput{
+				KubernetesVersion: ptr.String(fixKubernetesVersion),
+			},
+		}).Return(gqlschema.OperationStatus{
+			ID:        ptr.String(fixProvisionerOperationID),
+			Operation: gqlschema.OperationTypeUpgradeShoot,
+			State:     gqlschema.OperationStateInProgress,
+			RuntimeID: ptr.String(fixRuntimeID),
+		}, nil).Once()
+		defer provisionerClient.AssertExpectations(t)
+
+		step := NewUpgradeClusterStep(memoryStorage.Operations(), memoryStorage.Instances(), provisionerClient, fixKubernetesVersion, nil)
+
+		// when
+		result, repeat, err := step.Run(operation, logrus.New())
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, time.Minute, repeat)
+		assert.Equal(t, fixProvisionerOperationID, result.ProvisionerOperationID)
+
+		stored, err := memoryStorage.Operations().GetUpgradeClusterOperationByID(operation.ID)
+		require.NoError(t, err)
+		assert.Equal(t, fixProvisionerOperationID, stored.ProvisionerOperationID)
+	})
+
+	t.Run("should not trigger the upgrade again", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+
+		operation := fixUpgradeClusterOperation()
+		operation.ProvisionerOperationID = fixProvisionerOperationID
+
+		provisionerClient := &provisionerAutomock.Client{}
+		defer provisionerClient.AssertExpectations(t)
+
+		step := NewUpgradeClusterStep(memoryStorage.Operations(), memoryStorage.Instances(), provisionerClient, fixKubernetesVersion, nil)
+
+		// when
+		result, repeat, err := step.Run(operation, logrus.New())
+
+		// then
+		require.NoError(t, err)
+		assert.Zero(t, repeat)
+		assert.Equal(t, domain.InProgress, result.State)
+	})
+
+	t.Run("should succeed the dry run without calling the provisioner", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+
+		operation := fixUpgradeClusterOperation()
+		operation.DryRun = true
+		err := memoryStorage.Operations().InsertUpgradeClusterOperation(operation)
+		require.NoError(t, err)
+
+		provisionerClient := &provisionerAutomock.Client{}
+		defer provisionerClient.AssertExpectations(t)
+
+		step := NewUpgradeClusterStep(memoryStorage.Operations(), memoryStorage.Instances(), provisionerClient, fixKubernetesVersion, nil)
+
+		// when
+		result, repeat, err := step.Run(operation, logrus.New())
+
+		// then
+		require.NoError(t, err)
+		assert.Zero(t, repeat)
+		assert.Equal(t, domain.Succeeded, result.State)
+	})
+
+	t.Run("should postpone the upgrade when the provisioner rate limit is reached", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		err := memoryStorage.Instances().Insert(fixInstance())
+		require.NoError(t, err)
+
+		operation := fixUpgradeClusterOperation()
+		err = memoryStorage.Operations().InsertUpgradeClusterOperation(operation)
+		require.NoError(t, err)
+
+		provisionerClient := &provisionerAutomock.Client{}
+		defer provisionerClient.AssertExpectations(t)
+
+		limiter := fakeRateLimiter{delays: map[string]time.Duration{fixOrchestrationID: 3 * time.Second}}
+		step := NewUpgradeClusterStep(memoryStorage.Operations(), memoryStorage.Instances(), provisionerClient, fixKubernetesVersion, limiter)
+
+		// when
+		result, repeat, err := step.Run(operation, logrus.New())
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 3*time.Second, repeat)
+		assert.Empty(t, result.ProvisionerOperationID)
+	})
+}
+
+type fakeRateLimiter struct {
+	delays map[string]time.Duration
+}
+
+func (f fakeRateLimiter) Reserve(orchestrationID string) time.Duration {
+	return f.delays[orchestrationID]
+}
+
+func fixUpgradeClusterOperation() internal.UpgradeClusterOperation {
+	n := time.Now()
+	return internal.UpgradeClusterOperation{
+		RuntimeOperation: internal.RuntimeOperation{
+			Operation: internal.Operation{
+				ID:              fixUpgradeClusterOperationID,
+				InstanceID:      fixInstanceID,
+				OrchestrationID: fixOrchestrationID,
+				State:           domain.InProgress,
+				CreatedAt:       n,
+				UpdatedAt:       n,
+			},
+			RuntimeID:              fixRuntimeID,
+			GlobalAccountID:        fixGlobalAccountID,
+			MaintenanceWindowBegin: n.Add(-time.Hour),
+			MaintenanceWindowEnd:   n.Add(time.Hour),
+		},
+		PlanID: broker.AzurePlanID,
+	}
+}
+
+func fixInstance() internal.Instance {
+	return internal.Instance{
+		InstanceID:      fixInstanceID,
+		RuntimeID:       fixRuntimeID,
+		GlobalAccountID: fixGlobalAccountID,
+		ServicePlanID:   broker.AzurePlanID,
+		ServicePlanName: broker.AzurePlanName,
+	}
+}
@@ -0,0 +1,107 @@
+package process
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/sirupsen/logrus"
+)
+
+type UpgradeClusterOperationManager struct {
+	storage storage.UpgradeCluster
+	ctx     context.Context
+}
+
+func NewUpgradeClusterOperationManager(storage storage.Operations) *UpgradeClusterOperationManager {
+	return &UpgradeClusterOperationManager{storage: storage, ctx: context.Background()}
+}
+
+// WithContext returns the copy of the manager which records the storage calls in the trace of the given context
+func (om *UpgradeClusterOperationManager) WithContext(ctx context.Context) *UpgradeClusterOperationManager {
+	withContext := *om
+	withContext.ctx = ctx
+	return &withContext
+}
+
+// OperationSucceeded marks the operation as succeeded and only repeats it if there is a storage error
+func (om *UpgradeClusterOperationManager) OperationSucceeded(operation internal.UpgradeClusterOperation, description string) (internal.UpgradeClusterOperation, time.Duration, error) {
+	updatedOperation, repeat := om.update(operation, domain.Succeeded, description)
+	// repeat in case of storage error
+	if repeat != 0 {
+		return updatedOperation, repeat, nil
+	}
+
+	return updatedOperation, 0, nil
+}
+
+// OperationFailed marks the operation as failed and only repeats it if there is a storage error
+func (om *UpgradeClusterOperationManager) OperationFailed(operation internal.UpgradeClusterOperation, description string) (internal.UpgradeClusterOperation, time.Duration, error) {
+	updatedOperation, repeat := om.update(operation, domain.Failed, description)
+	// repeat in case of storage error
+	if repeat != 0 {
+		return updatedOperation, repeat, nil
+	}
+
+	return updatedOperation, 0, errors.New(description)
+}
+
+// RetryOperation retries an operation for at maxTime in retryInterval steps and fails the operation if retrying failed,
+// every retry is counted in the operation
+func (om *UpgradeClusterOperationManager) RetryOperation(operation internal.UpgradeClusterOperation, errorMessage string, retryInterval time.Duration, maxTime time.Duration, log logrus.FieldLogger) (internal.UpgradeClusterOperation, time.Duration, error) {
+	since := time.Since(retriedSince(operation.RuntimeOperation))
+
+	log.Infof("Retry Operation was triggered with message: %s", errorMessage)
+	log.Infof("Retrying for %s in %s steps", maxTime.String(), retryInterval.String())
+	if since < maxTime {
+		markRetried(&operation.RuntimeOperation)
+		updatedOperation, err := om.store(operation)
+		if err != nil {
+			log.Errorf("cannot save the retry of the operation: %s", err)
+			return operation, retryInterval, nil
+		}
+		if updatedOperation.IsFinished() {
+			return *updatedOperation, 0, nil
+		}
+		return *updatedOperation, retryInterval, nil
+	}
+	log.Errorf("Aborting after %s of failing retries", maxTime.String())
+	return om.OperationFailed(operation, errorMessage)
+}
+
+// UpdateOperation updates a given operation
+func (om *UpgradeClusterOperationManager) UpdateOperation(operation internal.UpgradeClusterOperation) (internal.UpgradeClusterOperation, time.Duration) {
+	// the update ends the retries of the step
+	operation.RetriedSince = time.Time{}
+	updatedOperation, err := om.store(operation)
+	if err != nil {
+		return operation, 1 * time.Minute
+	}
+	return *updatedOperation, 0
+}
+
+func (om *UpgradeClusterOperationManager) update(operation internal.UpgradeClusterOperation, state domain.LastOperationState, description string) (internal.UpgradeClusterOperation, time.Duration) {
+	operation.State = state
+	operation.Description = description
+	operation.ResultReason = resultReason(description)
+
+	return om.UpdateOperation(operation)
+}
+
+// store updates the operation within the span of the storage call, see storeOperation for the conflict handling
+func (om *UpgradeClusterOperationManager) store(operation internal.UpgradeClusterOperation) (updatedOperation *internal.UpgradeClusterOperation, err error) {
+	err = storeOperation(om.ctx, "storage/UpdateUpgradeClusterOperation", func() error {
+		updatedOperation, err = om.storage.UpdateUpgradeClusterOperation(operation)
+		return err
+	}, func() (*internal.Operation, error) {
+		updatedOperation, err = om.storage.GetUpgradeClusterOperationByID(operation.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &updatedOperation.Operation, nil
+	})
+	return updatedOperation, err
+}
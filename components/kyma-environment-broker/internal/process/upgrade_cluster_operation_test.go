@@ -0,0 +1,149 @@
+package process
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpgradeClusterOperationManager_OperationSucceeded(t *testing.T) {
+	// given
+	memory := storage.NewMemoryStorage()
+	operations := memory.Operations()
+	opManager := NewUpgradeClusterOperationManager(operations)
+	op := fixUpgradeClusterOperation()
+	err := operations.InsertUpgradeClusterOperation(op)
+	require.NoError(t, err)
+
+	// when
+	op, when, err := opManager.OperationSucceeded(op, "task succeeded")
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, domain.Succeeded, op.State)
+	assert.Equal(t, time.Duration(0), when)
+}
+
+func TestUpgradeClusterOperationManager_OperationFailed(t *testing.T) {
+	// given
+	memory := storage.NewMemoryStorage()
+	operations := memory.Operations()
+	opManager := NewUpgradeClusterOperationManager(operations)
+	op := fixUpgradeClusterOperation()
+	err := operations.InsertUpgradeClusterOperation(op)
+	require.NoError(t, err)
+
+	errMsg := "task failed miserably"
+
+	// when
+	op, when, err := opManager.OperationFailed(op, errMsg)
+
+	// then
+	assert.Error(t, err)
+	assert.EqualError(t, err, errMsg)
+	assert.Equal(t, domain.Failed, op.State)
+	assert.Equal(t, time.Duration(0), when)
+}
+
+func TestUpgradeClusterOperationManager_RetryOperation(t *testing.T) {
+	// given
+	memory := storage.NewMemoryStorage()
+	operations := memory.Operations()
+	opManager := NewUpgradeClusterOperationManager(operations)
+	op := internal.UpgradeClusterOperation{}
+	op.UpdatedAt = time.Now()
+	retryInterval := time.Hour
+	errorMessage := fmt.Sprintf("task failed")
+	maxtime := time.Hour * 3 // allow 2 retries
+
+	// this is required to avoid storage retries (without this statement there will be an error => retry)
+	err := operations.InsertUpgradeClusterOperation(op)
+	require.NoError(t, err)
+
+	// then - first call
+	op, when, err := opManager.RetryOperation(op, errorMessage, retryInterval, maxtime, fixLogger())
+
+	// when - first retry
+	assert.True(t, when > 0)
+	assert.Nil(t, err)
+
+	// then - second call
+	t.Log(op.UpdatedAt.String())
+	op.UpdatedAt = op.UpdatedAt.Add(-retryInterval - time.Second) // simulate wait of first retry
+	t.Log(op.UpdatedAt.String())
+	op, when, err = opManager.RetryOperation(op, errorMessage, retryInterval, maxtime, fixLogger())
+
+	// when - second call => retry
+	assert.True(t, when > 0)
+	assert.Nil(t, err)
+
+}
+
+func TestUpgradeClusterOperationManager_RetryOperationCountsRetries(t *testing.T) {
+	// given
+	memory := storage.NewMemoryStorage()
+	operations := memory.Operations()
+	opManager := NewUpgradeClusterOperationManager(operations)
+	op := fixUpgradeClusterOperation()
+	op.UpdatedAt = time.Now().Add(-time.Hour)
+	err := operations.InsertUpgradeClusterOperation(op)
+	require.NoError(t, err)
+
+	// when
+	op, when, err := opManager.RetryOperation(op, "task failed", time.Minute, 90*time.Minute, fixLogger())
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, when)
+	stored, err := operations.GetUpgradeClusterOperationByID(op.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stored.RetryCount)
+	assert.False(t, stored.LastRetryAt.IsZero())
+
+	// when the retry is stored again, the storage sets the update time
+	op.UpdatedAt = time.Now()
+	op, when, err = opManager.RetryOperation(op, "task failed", time.Minute, 90*time.Minute, fixLogger())
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, when)
+	assert.Equal(t, 2, op.RetryCount)
+
+	// when the retries exceed the time limit counted from the update before the first retry
+	op.UpdatedAt = time.Now()
+	op, when, err = opManager.RetryOperation(op, "task failed", time.Minute, 30*time.Minute, fixLogger())
+
+	// then
+	assert.EqualError(t, err, "task failed")
+	assert.Equal(t, domain.Failed, op.State)
+	assert.Equal(t, 2, op.RetryCount)
+	assert.Equal(t, time.Duration(0), when)
+}
+
+func fixUpgradeClusterOperation() internal.UpgradeClusterOperation {
+	return internal.UpgradeClusterOperation{
+		RuntimeOperation: internal.RuntimeOperation{
+			Operation: internal.Operation{
+				ID:                     "2c538027-d1c4-41ef-a26c-c9604483cb6d",
+				Version:                0,
+				CreatedAt:              time.Now(),
+				UpdatedAt:              time.Time{},
+				InstanceID:             "2b6645a1-87e7-491d-bce3-cc0fbe16b6c0",
+				ProvisionerOperationID: "",
+				State:                  domain.InProgress,
+				Description:            "op description",
+			},
+			SubAccountID: "",
+			RuntimeID:    "",
+			DryRun:       false,
+		},
+		ProvisioningParameters: "",
+		InputCreator:           nil,
+	}
+}
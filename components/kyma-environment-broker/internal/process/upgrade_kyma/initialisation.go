@@ -1,6 +1,7 @@
 package upgrade_kyma
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/provisioner"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/upgradeverification"
 	"github.com/kyma-project/control-plane/components/provisioner/pkg/gqlschema"
 
 	"github.com/pivotal-cf/brokerapi/v7/domain"
@@ -22,16 +24,31 @@ const (
 	CheckStatusTimeout = 3 * time.Hour
 )
 
+type KymaVersionConfigurator interface {
+	ForGlobalAccount(string) (string, bool, error)
+}
+
+// UpgradeVerifier verifies the runtime once the Provisioner finished the upgrade, the returned duration
+// tells when the verification must be repeated and is zero once the verification is finished
+type UpgradeVerifier interface {
+	Verify(runtime upgradeverification.Runtime, verification *internal.UpgradeVerification, log logrus.FieldLogger) (*internal.UpgradeVerification, time.Duration)
+}
+
 type InitialisationStep struct {
-	operationManager  *process.UpgradeKymaOperationManager
-	operationStorage  storage.Provisioning
-	instanceStorage   storage.Instances
-	provisionerClient provisioner.Client
-	inputBuilder      input.CreatorForPlan
-	timeSchedule      TimeSchedule
+	operationManager        *process.UpgradeKymaOperationManager
+	operationStorage        storage.Operations
+	instanceStorage         storage.Instances
+	provisionerClient       provisioner.Client
+	inputBuilder            input.CreatorForPlan
+	kymaVersionConfigurator KymaVersionConfigurator
+	timeSchedule            TimeSchedule
+	verifier                UpgradeVerifier
 }
 
-func NewInitialisationStep(os storage.Operations, is storage.Instances, pc provisioner.Client, b input.CreatorForPlan, timeSchedule *TimeSchedule) *InitialisationStep {
+// NewInitialisationStep creates the step which starts the upgrade and checks its status in the Provisioner,
+// the post-upgrade verification is skipped if the verifier is nil
+func NewInitialisationStep(os storage.Operations, is storage.Instances, pc provisioner.Client, b input.CreatorForPlan,
+	configurator KymaVersionConfigurator, timeSchedule *TimeSchedule, verifier UpgradeVerifier) *InitialisationStep {
 	ts := timeSchedule
 	if ts == nil {
 		ts = &TimeSchedule{
@@ -41,12 +58,14 @@ func NewInitialisationStep(os storage.Operations, is storage.Instances, pc provi
 		}
 	}
 	return &InitialisationStep{
-		operationManager:  process.NewUpgradeKymaOperationManager(os),
-		operationStorage:  os,
-		instanceStorage:   is,
-		provisionerClient: pc,
-		inputBuilder:      b,
-		timeSchedule:      *ts,
+		operationManager:        process.NewUpgradeKymaOperationManager(os),
+		operationStorage:        os,
+		instanceStorage:         is,
+		provisionerClient:       pc,
+		inputBuilder:            b,
+		kymaVersionConfigurator: configurator,
+		timeSchedule:            *ts,
+		verifier:                verifier,
 	}
 }
 
@@ -55,11 +74,28 @@ func (s *InitialisationStep) Name() string {
 }
 
 func (s *InitialisationStep) Run(operation internal.UpgradeKymaOperation, log logrus.FieldLogger) (internal.UpgradeKymaOperation, time.Duration, error) {
+	return s.RunWithContext(context.Background(), operation, log)
+}
+
+// RunWithContext runs the step with the Provisioner calls sent with the given context
+func (s *InitialisationStep) RunWithContext(ctx context.Context, operation internal.UpgradeKymaOperation, log logrus.FieldLogger) (internal.UpgradeKymaOperation, time.Duration, error) {
+	step := *s
+	step.operationManager = s.operationManager.WithContext(ctx)
+	step.provisionerClient = provisioner.WithContext(s.provisionerClient, ctx)
+	return step.run(operation, log)
+}
+
+func (s *InitialisationStep) run(operation internal.UpgradeKymaOperation, log logrus.FieldLogger) (internal.UpgradeKymaOperation, time.Duration, error) {
 	// if time window for this operation has finished we reprocess on next time window
 	if operation.MaintenanceWindowEnd.Before(time.Now()) {
-		until := time.Until(operation.MaintenanceWindowBegin)
+		updatedOperation, err := s.moveToNextMaintenanceWindow(operation)
+		if err != nil {
+			log.Errorf("while moving the operation to the next maintenance window: %s", err)
+			return operation, s.timeSchedule.Retry, nil
+		}
+		until := time.Until(updatedOperation.MaintenanceWindowBegin)
 		log.Infof("Upgrade operation %s will be rescheduled in %v", operation.ID, until)
-		return operation, until, nil
+		return *updatedOperation, until, nil
 	}
 
 	// rewrite necessary data from ProvisioningOperation to operation internal.UpgradeOperation
@@ -111,6 +147,19 @@ func (s *InitialisationStep) initializeUpgradeRuntimeRequest(operation internal.
 		return s.operationManager.OperationFailed(operation, "invalid operation provisioning parameters")
 	}
 
+	// the version from the provisioning parameters is not used, the runtime is upgraded to the version
+	// configured for the global account at the time of the upgrade or to the default one
+	pp.Parameters.KymaVersion = ""
+	version, found, err := s.kymaVersionConfigurator.ForGlobalAccount(pp.ErsContext.GlobalAccountID)
+	if err != nil {
+		log.Errorf("cannot resolve Kyma version for global account %s: %s", pp.ErsContext.GlobalAccountID, err)
+		return s.operationManager.RetryOperation(operation, err.Error(), 5*time.Second, 5*time.Minute, log)
+	}
+	if found {
+		log.Infof("upgrading to Kyma version %s configured for the global account", version)
+		pp.Parameters.KymaVersion = version
+	}
+
 	log.Infof("create provisioner input creator for plan ID %q", pp.PlanID)
 	creator, err := s.inputBuilder.CreateUpgradeInput(pp)
 	switch {
@@ -127,12 +176,18 @@ func (s *InitialisationStep) initializeUpgradeRuntimeRequest(operation internal.
 }
 
 func (s *InitialisationStep) checkRuntimeStatus(operation internal.UpgradeKymaOperation, instance *internal.Instance, log logrus.FieldLogger) (internal.UpgradeKymaOperation, time.Duration, error) {
-	if time.Since(operation.UpdatedAt) > CheckStatusTimeout {
+	// the Provisioner fails the upgrade which exceeds the installation timeout requested by the orchestration,
+	// so the status is checked at least as long
+	checkStatusTimeout := CheckStatusTimeout
+	if operation.InstallationTimeout > checkStatusTimeout {
+		checkStatusTimeout = operation.InstallationTimeout
+	}
+	if time.Since(operation.UpdatedAt) > checkStatusTimeout {
 		log.Infof("operation has reached the time limit: updated operation time: %s", operation.UpdatedAt)
-		return s.operationManager.OperationFailed(operation, fmt.Sprintf("operation has reached the time limit: %s", CheckStatusTimeout))
+		return s.operationManager.OperationFailed(operation, fmt.Sprintf("operation has reached the time limit: %s", checkStatusTimeout))
 	}
 
-	status, err := s.provisionerClient.RuntimeOperationStatus(instance.GlobalAccountID, operation.ProvisionerOperationID)
+	status, err := s.provisionerClient.RuntimeOperationStatus(instance.Tenant(), operation.ProvisionerOperationID)
 	if err != nil {
 		return operation, s.timeSchedule.StatusCheck, nil
 	}
@@ -145,7 +200,10 @@ func (s *InitialisationStep) checkRuntimeStatus(operation internal.UpgradeKymaOp
 
 	switch status.State {
 	case gqlschema.OperationStateSucceeded:
-		return s.operationManager.OperationSucceeded(operation, msg)
+		if s.verifier == nil {
+			return s.operationManager.OperationSucceeded(operation, msg)
+		}
+		return s.verifyUpgrade(operation, instance, msg, log)
 	case gqlschema.OperationStateInProgress:
 		return operation, s.timeSchedule.StatusCheck, nil
 	case gqlschema.OperationStatePending:
@@ -156,3 +214,40 @@ func (s *InitialisationStep) checkRuntimeStatus(operation internal.UpgradeKymaOp
 
 	return s.operationManager.OperationFailed(operation, fmt.Sprintf("unsupported provisioner client status: %s", status.State.String()))
 }
+
+// verifyUpgrade runs the post-upgrade checks, the operation succeeds only once all checks passed,
+// so the runtime is not counted as upgraded by the orchestration before it is verified
+func (s *InitialisationStep) verifyUpgrade(operation internal.UpgradeKymaOperation, instance *internal.Instance, msg string, log logrus.FieldLogger) (internal.UpgradeKymaOperation, time.Duration, error) {
+	verification, repeat := s.verifier.Verify(upgradeverification.Runtime{
+		OperationID:     operation.Operation.ID,
+		InstanceID:      instance.InstanceID,
+		RuntimeID:       instance.RuntimeID,
+		GlobalAccountID: instance.Tenant(),
+		DashboardURL:    instance.DashboardURL,
+		RetryCount:      operation.RetryCount,
+	}, operation.Verification, log)
+	operation.Verification = verification
+
+	if repeat != 0 {
+		updatedOperation, retry := s.operationManager.UpdateOperation(operation)
+		if retry != 0 {
+			return updatedOperation, retry, nil
+		}
+		return updatedOperation, repeat, nil
+	}
+	if check, failed := upgradeverification.FailedCheck(verification); failed {
+		return s.operationManager.OperationFailed(operation, fmt.Sprintf("post-upgrade verification failed: check %s: %s", check.Name, check.Message))
+	}
+	return s.operationManager.OperationSucceeded(operation, msg)
+}
+
+// moveToNextMaintenanceWindow shifts the maintenance window of the operation by whole days, the maintenance window
+// of the shoot is repeated daily, so the operation is processed in the first window which has not finished yet
+func (s *InitialisationStep) moveToNextMaintenanceWindow(operation internal.UpgradeKymaOperation) (*internal.UpgradeKymaOperation, error) {
+	days := int(time.Since(operation.MaintenanceWindowEnd)/(24*time.Hour)) + 1
+
+	return storage.UpdateWithRetryUpgradeKymaOperation(s.operationStorage, operation.ID, func(latest *internal.UpgradeKymaOperation) {
+		latest.MaintenanceWindowBegin = operation.MaintenanceWindowBegin.AddDate(0, 0, days)
+		latest.MaintenanceWindowEnd = operation.MaintenanceWindowEnd.AddDate(0, 0, days)
+	})
+}
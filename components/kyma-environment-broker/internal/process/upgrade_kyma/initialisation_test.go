@@ -12,6 +12,7 @@ import (
 	provisionerAutomock "github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/provisioner/automock"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/ptr"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/upgradeverification"
 	"github.com/kyma-project/control-plane/components/provisioner/pkg/gqlschema"
 	"github.com/pivotal-cf/brokerapi/v7/domain"
 	"github.com/sirupsen/logrus"
@@ -55,7 +56,7 @@ func TestInitialisationStep_Run(t *testing.T) {
 			RuntimeID: StringPtr(fixRuntimeID),
 		}, nil)
 
-		step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Instances(), provisionerClient, nil, nil)
+		step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Instances(), provisionerClient, nil, newInMemoryKymaVersionConfigurator(map[string]string{}), nil, nil)
 
 		// when
 		upgradeOperation, repeat, err := step.Run(upgradeOperation, log)
@@ -67,6 +68,51 @@ func TestInitialisationStep_Run(t *testing.T) {
 
 	})
 
+	t.Run("should keep operation in progress until post-upgrade verification finished", func(t *testing.T) {
+		// given
+		log := logrus.New()
+		memoryStorage := storage.NewMemoryStorage()
+
+		err := memoryStorage.Operations().InsertProvisioningOperation(fixProvisioningOperation(t))
+		assert.NoError(t, err)
+		upgradeOperation := fixUpgradeKymaOperation(t)
+		err = memoryStorage.Operations().InsertUpgradeKymaOperation(upgradeOperation)
+		assert.NoError(t, err)
+		err = memoryStorage.Instances().Insert(fixInstanceRuntimeStatus())
+		assert.NoError(t, err)
+
+		provisionerClient := &provisionerAutomock.Client{}
+		provisionerClient.On("RuntimeOperationStatus", fixGlobalAccountID, fixProvisionerOperationID).Return(gqlschema.OperationStatus{
+			ID:        ptr.String(fixProvisionerOperationID),
+			State:     gqlschema.OperationStateSucceeded,
+			RuntimeID: StringPtr(fixRuntimeID),
+		}, nil)
+
+		verifier := &fakeVerifier{results: []string{internal.VerificationPending, internal.VerificationFailed}}
+		step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Instances(), provisionerClient, nil, newInMemoryKymaVersionConfigurator(map[string]string{}), nil, verifier)
+
+		// when
+		upgradeOperation, repeat, err := step.Run(upgradeOperation, log)
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, time.Minute, repeat)
+		assert.Equal(t, domain.InProgress, upgradeOperation.State)
+		storedOperation, err := memoryStorage.Operations().GetUpgradeKymaOperationByID(fixUpgradeOperationID)
+		assert.NoError(t, err)
+		assert.Equal(t, internal.VerificationPending, storedOperation.Verification.State)
+
+		// when
+		upgradeOperation, repeat, err = step.Run(upgradeOperation, log)
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, time.Duration(0), repeat)
+		assert.Equal(t, domain.Failed, upgradeOperation.State)
+		assert.Equal(t, "post-upgrade verification failed", upgradeOperation.ResultReason)
+		assert.Equal(t, internal.VerificationFailed, upgradeOperation.Verification.State)
+	})
+
 	t.Run("should initialize UpgradeRuntimeInput request when run", func(t *testing.T) {
 		// given
 		log := logrus.New()
@@ -89,7 +135,7 @@ func TestInitialisationStep_Run(t *testing.T) {
 		inputBuilder := &automock.CreatorForPlan{}
 		inputBuilder.On("CreateUpgradeInput", fixProvisioningParameters()).Return(&input.RuntimeInput{}, nil)
 
-		step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Instances(), provisionerClient, inputBuilder, nil)
+		step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Instances(), provisionerClient, inputBuilder, newInMemoryKymaVersionConfigurator(map[string]string{}), nil, nil)
 
 		// when
 		op, repeat, err := step.Run(upgradeOperation, log)
@@ -100,6 +146,73 @@ func TestInitialisationStep_Run(t *testing.T) {
 		assert.Equal(t, time.Duration(0), repeat)
 		assert.NotNil(t, op.InputCreator)
 	})
+
+	t.Run("should initialize UpgradeRuntimeInput request with Kyma version configured for global account", func(t *testing.T) {
+		// given
+		log := logrus.New()
+		memoryStorage := storage.NewMemoryStorage()
+
+		provisioningOperation := fixProvisioningOperation(t)
+		err := memoryStorage.Operations().InsertProvisioningOperation(provisioningOperation)
+		assert.NoError(t, err)
+
+		upgradeOperation := fixUpgradeKymaOperation(t)
+		upgradeOperation.ProvisionerOperationID = ""
+		err = memoryStorage.Operations().InsertUpgradeKymaOperation(upgradeOperation)
+		assert.NoError(t, err)
+
+		instance := fixInstanceRuntimeStatus()
+		err = memoryStorage.Instances().Insert(instance)
+		assert.NoError(t, err)
+
+		expectedParameters := fixProvisioningParameters()
+		expectedParameters.Parameters.KymaVersion = "1.17.0"
+
+		provisionerClient := &provisionerAutomock.Client{}
+		inputBuilder := &automock.CreatorForPlan{}
+		inputBuilder.On("CreateUpgradeInput", expectedParameters).Return(&input.RuntimeInput{}, nil)
+
+		configurator := newInMemoryKymaVersionConfigurator(map[string]string{fixGlobalAccountID: "1.17.0"})
+		step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Instances(), provisionerClient, inputBuilder, configurator, nil, nil)
+
+		// when
+		op, repeat, err := step.Run(upgradeOperation, log)
+
+		// then
+		assert.NoError(t, err)
+		inputBuilder.AssertNumberOfCalls(t, "CreateUpgradeInput", 1)
+		assert.Equal(t, time.Duration(0), repeat)
+		assert.NotNil(t, op.InputCreator)
+	})
+
+	t.Run("should reschedule operation to the next maintenance window when the window has finished", func(t *testing.T) {
+		// given
+		log := logrus.New()
+		memoryStorage := storage.NewMemoryStorage()
+
+		n := time.Now()
+		upgradeOperation := fixUpgradeKymaOperation(t)
+		upgradeOperation.MaintenanceWindowBegin = n.Add(-50 * time.Hour)
+		upgradeOperation.MaintenanceWindowEnd = n.Add(-49 * time.Hour)
+		err := memoryStorage.Operations().InsertUpgradeKymaOperation(upgradeOperation)
+		assert.NoError(t, err)
+
+		provisionerClient := &provisionerAutomock.Client{}
+		step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Instances(), provisionerClient, nil, newInMemoryKymaVersionConfigurator(map[string]string{}), nil, nil)
+
+		// when
+		op, repeat, err := step.Run(upgradeOperation, log)
+
+		// then
+		assert.NoError(t, err)
+		assert.True(t, repeat > 0)
+		assert.True(t, repeat <= 22*time.Hour)
+		assert.True(t, op.MaintenanceWindowEnd.After(n))
+		storedOperation, err := memoryStorage.Operations().GetUpgradeKymaOperationByID(upgradeOperation.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, op.MaintenanceWindowBegin, storedOperation.MaintenanceWindowBegin)
+		provisionerClient.AssertExpectations(t)
+	})
 }
 
 func fixUpgradeKymaOperation(t *testing.T) internal.UpgradeKymaOperation {
@@ -166,6 +279,36 @@ func fixInstanceRuntimeStatus() internal.Instance {
 	}
 }
 
+func newInMemoryKymaVersionConfigurator(versions map[string]string) *inMemoryKymaVersionConfigurator {
+	return &inMemoryKymaVersionConfigurator{
+		perGAID: versions,
+	}
+}
+
+type inMemoryKymaVersionConfigurator struct {
+	perGAID map[string]string
+}
+
+func (c *inMemoryKymaVersionConfigurator) ForGlobalAccount(globalAccountID string) (string, bool, error) {
+	version, found := c.perGAID[globalAccountID]
+	return version, found, nil
+}
+
+// fakeVerifier returns the given verification states one after another
+type fakeVerifier struct {
+	results []string
+}
+
+func (v *fakeVerifier) Verify(_ upgradeverification.Runtime, verification *internal.UpgradeVerification, _ logrus.FieldLogger) (*internal.UpgradeVerification, time.Duration) {
+	state := v.results[0]
+	v.results = v.results[1:]
+	check := internal.VerificationCheck{Name: "fake", State: state}
+	if state == internal.VerificationPending {
+		return &internal.UpgradeVerification{State: state, Checks: []internal.VerificationCheck{check}}, time.Minute
+	}
+	return &internal.UpgradeVerification{State: state, Checks: []internal.VerificationCheck{check}}, 0
+}
+
 func StringPtr(s string) *string {
 	return &s
 }
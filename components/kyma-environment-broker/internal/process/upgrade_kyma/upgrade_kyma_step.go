@@ -1,28 +1,44 @@
 package upgrade_kyma
 
 import (
+	"context"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/provisioner"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/ptr"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
 	"github.com/kyma-project/control-plane/components/provisioner/pkg/gqlschema"
+
+	"github.com/Masterminds/semver"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
 
 const DryRunPrefix = "dry_run-"
 
+// RateLimiter limits the Provisioner mutations triggered by the operations of one orchestration
+type RateLimiter interface {
+	// Reserve returns 0 if the mutation can be performed now, otherwise the time after which it should be retried
+	Reserve(orchestrationID string) time.Duration
+}
+
 type UpgradeKymaStep struct {
 	operationManager    *process.UpgradeKymaOperationManager
+	operationStorage    storage.Operations
 	provisionerClient   provisioner.Client
 	runtimeStateStorage storage.RuntimeStates
 	timeSchedule        TimeSchedule
+	rateLimiter         RateLimiter
 }
 
-func NewUpgradeKymaStep(os storage.Operations, runtimeStorage storage.RuntimeStates, cli provisioner.Client, timeSchedule *TimeSchedule) *UpgradeKymaStep {
+// NewUpgradeKymaStep creates the step triggering the upgradeRuntime mutation, the rateLimiter is optional
+func NewUpgradeKymaStep(os storage.Operations, runtimeStorage storage.RuntimeStates, cli provisioner.Client, timeSchedule *TimeSchedule, rateLimiter RateLimiter) *UpgradeKymaStep {
 	ts := timeSchedule
 	if ts == nil {
 		ts = &TimeSchedule{
@@ -33,9 +49,11 @@ func NewUpgradeKymaStep(os storage.Operations, runtimeStorage storage.RuntimeSta
 	}
 	return &UpgradeKymaStep{
 		operationManager:    process.NewUpgradeKymaOperationManager(os),
+		operationStorage:    os,
 		provisionerClient:   cli,
 		runtimeStateStorage: runtimeStorage,
 		timeSchedule:        *ts,
+		rateLimiter:         rateLimiter,
 	}
 }
 
@@ -44,6 +62,18 @@ func (s *UpgradeKymaStep) Name() string {
 }
 
 func (s *UpgradeKymaStep) Run(operation internal.UpgradeKymaOperation, log logrus.FieldLogger) (internal.UpgradeKymaOperation, time.Duration, error) {
+	return s.RunWithContext(context.Background(), operation, log)
+}
+
+// RunWithContext runs the step with the Provisioner calls sent with the given context
+func (s *UpgradeKymaStep) RunWithContext(ctx context.Context, operation internal.UpgradeKymaOperation, log logrus.FieldLogger) (internal.UpgradeKymaOperation, time.Duration, error) {
+	step := *s
+	step.operationManager = s.operationManager.WithContext(ctx)
+	step.provisionerClient = provisioner.WithContext(s.provisionerClient, ctx)
+	return step.run(operation, log)
+}
+
+func (s *UpgradeKymaStep) run(operation internal.UpgradeKymaOperation, log logrus.FieldLogger) (internal.UpgradeKymaOperation, time.Duration, error) {
 	if time.Since(operation.UpdatedAt) > s.timeSchedule.UpgradeKymaTimeout {
 		log.Infof("operation has reached the time limit: updated operation time: %s", operation.UpdatedAt)
 		return s.operationManager.OperationFailed(operation, fmt.Sprintf("operation has reached the time limit: %s", s.timeSchedule.UpgradeKymaTimeout))
@@ -59,6 +89,20 @@ func (s *UpgradeKymaStep) Run(operation internal.UpgradeKymaOperation, log logru
 		return s.operationManager.OperationFailed(operation, "invalid operation data - cannot create upgradeKyma input")
 	}
 
+	// the guardrail is checked only before the upgrade is triggered, the started upgrade is never interrupted
+	if operation.ProvisionerOperationID == "" && !operation.AllowDowngrade {
+		currentVersion, err := s.currentKymaVersion(operation)
+		if err != nil {
+			log.Errorf("cannot resolve the current Kyma version of the runtime: %s", err)
+			return operation, s.timeSchedule.Retry, nil
+		}
+		targetVersion := requestInput.KymaConfig.Version
+		if isDowngrade(currentVersion, targetVersion) {
+			log.Infof("skipping downgrade from Kyma version %s to %s", currentVersion, targetVersion)
+			return s.operationManager.OperationSkipped(operation, fmt.Sprintf("downgrade rejected: the target Kyma version %s is lower than the current version %s", targetVersion, currentVersion))
+		}
+	}
+
 	if operation.DryRun {
 		// runtimeID is set with prefix to indicate the fake runtime state
 		err = s.runtimeStateStorage.Insert(
@@ -72,6 +116,12 @@ func (s *UpgradeKymaStep) Run(operation internal.UpgradeKymaOperation, log logru
 
 	var provisionerResponse gqlschema.OperationStatus
 	if operation.ProvisionerOperationID == "" {
+		if s.rateLimiter != nil {
+			if delay := s.rateLimiter.Reserve(operation.OrchestrationID); delay > 0 {
+				log.Infof("provisioner rate limit of the orchestration reached, retrying in %s", delay)
+				return operation, delay, nil
+			}
+		}
 		// trigger upgradeRuntime mutation
 		provisionerResponse, err := s.provisionerClient.UpgradeRuntime(pp.ErsContext.GlobalAccountID, operation.RuntimeID, requestInput)
 		if err != nil {
@@ -121,6 +171,53 @@ func (s *UpgradeKymaStep) createUpgradeKymaInput(operation internal.UpgradeKymaO
 	if err != nil {
 		return request, errors.Wrap(err, "while building upgradeRuntimeInput for provisioner")
 	}
+	if operation.InstallationTimeout > 0 {
+		request.InstallationTimeoutSeconds = ptr.Integer(int(operation.InstallationTimeout.Seconds()))
+	}
 
 	return request, nil
 }
+
+// currentKymaVersion returns the Kyma version of the latest succeeded provisioning or upgrade operation of the runtime,
+// the versions are taken from the runtime states. Empty version is returned if none of the operations succeeded.
+func (s *UpgradeKymaStep) currentKymaVersion(operation internal.UpgradeKymaOperation) (string, error) {
+	states, err := s.runtimeStateStorage.ListByRuntimeID(operation.RuntimeID)
+	if err != nil && !dberr.IsNotFound(err) {
+		return "", errors.Wrap(err, "while listing runtime states")
+	}
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].CreatedAt.After(states[j].CreatedAt)
+	})
+
+	for _, state := range states {
+		if state.OperationID == operation.ID || state.KymaConfig.Version == "" {
+			continue
+		}
+		op, err := s.operationStorage.GetOperationByID(state.OperationID)
+		switch {
+		case dberr.IsNotFound(err):
+			continue
+		case err != nil:
+			return "", errors.Wrapf(err, "while getting operation %s", state.OperationID)
+		}
+		if op.State == domain.Succeeded {
+			return state.KymaConfig.Version, nil
+		}
+	}
+
+	return "", nil
+}
+
+// isDowngrade returns true if the target version is lower than the current one, the versions which are not
+// semantic versions (e.g. the PR or master builds) cannot be compared and are never treated as a downgrade
+func isDowngrade(current, target string) bool {
+	currentVersion, err := semver.NewVersion(current)
+	if err != nil {
+		return false
+	}
+	targetVersion, err := semver.NewVersion(target)
+	if err != nil {
+		return false
+	}
+	return targetVersion.LessThan(currentVersion)
+}
@@ -14,8 +14,10 @@ import (
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
 	"github.com/kyma-project/control-plane/components/provisioner/pkg/gqlschema"
 	"github.com/kyma-project/kyma/components/kyma-operator/pkg/apis/installer/v1alpha1"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 const (
@@ -65,7 +67,7 @@ func TestUpgradeKymaStep_Run(t *testing.T) {
 		RuntimeID: ptr.String(fixRuntimeID),
 	}, nil)
 
-	step := NewUpgradeKymaStep(memoryStorage.Operations(), memoryStorage.RuntimeStates(), provisionerClient, nil)
+	step := NewUpgradeKymaStep(memoryStorage.Operations(), memoryStorage.RuntimeStates(), provisionerClient, nil, nil)
 
 	// when
 
@@ -77,6 +79,136 @@ func TestUpgradeKymaStep_Run(t *testing.T) {
 	assert.Equal(t, fixProvisionerOperationID, operation.ProvisionerOperationID)
 }
 
+func TestUpgradeKymaStep_RunWithInstallationTimeout(t *testing.T) {
+	// given
+	log := logrus.New()
+	memoryStorage := storage.NewMemoryStorage()
+
+	operation := fixUpgradeKymaOperationWithInputCreator(t)
+	operation.InstallationTimeout = 3 * time.Hour
+	err := memoryStorage.Operations().InsertUpgradeKymaOperation(operation)
+	assert.NoError(t, err)
+
+	provisionerClient := &provisionerAutomock.Client{}
+	provisionerClient.On("UpgradeRuntime", fixGlobalAccountID, fixRuntimeID, mock.MatchedBy(func(input gqlschema.UpgradeRuntimeInput) bool {
+		return input.InstallationTimeoutSeconds != nil && *input.InstallationTimeoutSeconds == 10800
+	})).Return(gqlschema.OperationStatus{
+		ID:        ptr.String(fixProvisionerOperationID),
+		RuntimeID: ptr.String(fixRuntimeID),
+	}, nil)
+	provisionerClient.On("RuntimeOperationStatus", fixGlobalAccountID, fixProvisionerOperationID).Return(gqlschema.OperationStatus{
+		ID:        ptr.String(fixProvisionerOperationID),
+		RuntimeID: ptr.String(fixRuntimeID),
+	}, nil)
+
+	step := NewUpgradeKymaStep(memoryStorage.Operations(), memoryStorage.RuntimeStates(), provisionerClient, nil, nil)
+
+	// when
+	operation, _, err = step.Run(operation, log.WithFields(logrus.Fields{"step": "TEST"}))
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, fixProvisionerOperationID, operation.ProvisionerOperationID)
+	provisionerClient.AssertExpectations(t)
+}
+
+func TestUpgradeKymaStep_RunRateLimited(t *testing.T) {
+	// given
+	log := logrus.New()
+	memoryStorage := storage.NewMemoryStorage()
+
+	operation := fixUpgradeKymaOperationWithInputCreator(t)
+	operation.OrchestrationID = "orchestration-id"
+	err := memoryStorage.Operations().InsertUpgradeKymaOperation(operation)
+	assert.NoError(t, err)
+
+	provisionerClient := &provisionerAutomock.Client{}
+	limiter := fakeRateLimiter{delays: map[string]time.Duration{"orchestration-id": 3 * time.Second}}
+
+	step := NewUpgradeKymaStep(memoryStorage.Operations(), memoryStorage.RuntimeStates(), provisionerClient, nil, limiter)
+
+	// when
+	operation, repeat, err := step.Run(operation, log.WithFields(logrus.Fields{"step": "TEST"}))
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, 3*time.Second, repeat)
+	assert.Empty(t, operation.ProvisionerOperationID)
+	provisionerClient.AssertExpectations(t)
+}
+
+func TestUpgradeKymaStep_RunDowngrade(t *testing.T) {
+	for name, tc := range map[string]struct {
+		allowDowngrade bool
+		currentVersion string
+		expectedState  domain.LastOperationState
+	}{
+		"downgrade is skipped": {
+			currentVersion: "1.11.0",
+			expectedState:  internal.OperationSkipped,
+		},
+		"downgrade is allowed by the orchestration": {
+			allowDowngrade: true,
+			currentVersion: "1.11.0",
+			expectedState:  domain.InProgress,
+		},
+		"upgrade is not affected": {
+			currentVersion: "1.9.0",
+			expectedState:  domain.InProgress,
+		},
+		"not semantic version cannot be compared": {
+			currentVersion: "PR-1234",
+			expectedState:  domain.InProgress,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// given
+			log := logrus.New()
+			memoryStorage := storage.NewMemoryStorage()
+
+			operation := fixUpgradeKymaOperationWithInputCreator(t)
+			operation.State = domain.InProgress
+			operation.AllowDowngrade = tc.allowDowngrade
+			err := memoryStorage.Operations().InsertUpgradeKymaOperation(operation)
+			assert.NoError(t, err)
+
+			provisioningOperation := fixProvisioningOperation(t)
+			provisioningOperation.State = domain.Succeeded
+			err = memoryStorage.Operations().InsertProvisioningOperation(provisioningOperation)
+			assert.NoError(t, err)
+			err = memoryStorage.RuntimeStates().Insert(internal.NewRuntimeState(fixRuntimeID, fixProvisioningOperationID, &gqlschema.KymaConfigInput{Version: tc.currentVersion}, nil))
+			assert.NoError(t, err)
+
+			provisionerClient := &provisionerAutomock.Client{}
+			provisionerClient.On("UpgradeRuntime", fixGlobalAccountID, fixRuntimeID, mock.Anything).Return(gqlschema.OperationStatus{
+				ID:        ptr.String(fixProvisionerOperationID),
+				RuntimeID: ptr.String(fixRuntimeID),
+			}, nil).Maybe()
+			provisionerClient.On("RuntimeOperationStatus", fixGlobalAccountID, fixProvisionerOperationID).Return(gqlschema.OperationStatus{
+				ID:        ptr.String(fixProvisionerOperationID),
+				RuntimeID: ptr.String(fixRuntimeID),
+			}, nil).Maybe()
+
+			step := NewUpgradeKymaStep(memoryStorage.Operations(), memoryStorage.RuntimeStates(), provisionerClient, nil, nil)
+
+			// when
+			operation, _, err = step.Run(operation, log.WithFields(logrus.Fields{"step": "TEST"}))
+
+			// then
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedState, operation.State)
+		})
+	}
+}
+
+type fakeRateLimiter struct {
+	delays map[string]time.Duration
+}
+
+func (f fakeRateLimiter) Reserve(orchestrationID string) time.Duration {
+	return f.delays[orchestrationID]
+}
+
 func fixUpgradeKymaOperationWithInputCreator(t *testing.T) internal.UpgradeKymaOperation {
 	return internal.UpgradeKymaOperation{
 		RuntimeOperation: internal.RuntimeOperation{
@@ -1,7 +1,9 @@
 package process
 
 import (
+	"context"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
@@ -12,10 +14,18 @@ import (
 
 type UpgradeKymaOperationManager struct {
 	storage storage.UpgradeKyma
+	ctx     context.Context
 }
 
 func NewUpgradeKymaOperationManager(storage storage.Operations) *UpgradeKymaOperationManager {
-	return &UpgradeKymaOperationManager{storage: storage}
+	return &UpgradeKymaOperationManager{storage: storage, ctx: context.Background()}
+}
+
+// WithContext returns the copy of the manager which records the storage calls in the trace of the given context
+func (om *UpgradeKymaOperationManager) WithContext(ctx context.Context) *UpgradeKymaOperationManager {
+	withContext := *om
+	withContext.ctx = ctx
+	return &withContext
 }
 
 // OperationSucceeded marks the operation as succeeded and only repeats it if there is a storage error
@@ -40,14 +50,35 @@ func (om *UpgradeKymaOperationManager) OperationFailed(operation internal.Upgrad
 	return updatedOperation, 0, errors.New(description)
 }
 
-// RetryOperation retries an operation for at maxTime in retryInterval steps and fails the operation if retrying failed
+// OperationSkipped marks the operation as skipped and only repeats it if there is a storage error
+func (om *UpgradeKymaOperationManager) OperationSkipped(operation internal.UpgradeKymaOperation, description string) (internal.UpgradeKymaOperation, time.Duration, error) {
+	updatedOperation, repeat := om.update(operation, internal.OperationSkipped, description)
+	// repeat in case of storage error
+	if repeat != 0 {
+		return updatedOperation, repeat, nil
+	}
+
+	return updatedOperation, 0, nil
+}
+
+// RetryOperation retries an operation for at maxTime in retryInterval steps and fails the operation if retrying failed,
+// every retry is counted in the operation
 func (om *UpgradeKymaOperationManager) RetryOperation(operation internal.UpgradeKymaOperation, errorMessage string, retryInterval time.Duration, maxTime time.Duration, log logrus.FieldLogger) (internal.UpgradeKymaOperation, time.Duration, error) {
-	since := time.Since(operation.UpdatedAt)
+	since := time.Since(retriedSince(operation.RuntimeOperation))
 
 	log.Infof("Retry Operation was triggered with message: %s", errorMessage)
 	log.Infof("Retrying for %s in %s steps", maxTime.String(), retryInterval.String())
 	if since < maxTime {
-		return operation, retryInterval, nil
+		markRetried(&operation.RuntimeOperation)
+		updatedOperation, err := om.store(operation)
+		if err != nil {
+			log.Errorf("cannot save the retry of the operation: %s", err)
+			return operation, retryInterval, nil
+		}
+		if updatedOperation.IsFinished() {
+			return *updatedOperation, 0, nil
+		}
+		return *updatedOperation, retryInterval, nil
 	}
 	log.Errorf("Aborting after %s of failing retries", maxTime.String())
 	return om.OperationFailed(operation, errorMessage)
@@ -55,7 +86,9 @@ func (om *UpgradeKymaOperationManager) RetryOperation(operation internal.Upgrade
 
 // UpdateOperation updates a given operation
 func (om *UpgradeKymaOperationManager) UpdateOperation(operation internal.UpgradeKymaOperation) (internal.UpgradeKymaOperation, time.Duration) {
-	updatedOperation, err := om.storage.UpdateUpgradeKymaOperation(operation)
+	// the update ends the retries of the step
+	operation.RetriedSince = time.Time{}
+	updatedOperation, err := om.store(operation)
 	if err != nil {
 		return operation, 1 * time.Minute
 	}
@@ -65,6 +98,55 @@ func (om *UpgradeKymaOperationManager) UpdateOperation(operation internal.Upgrad
 func (om *UpgradeKymaOperationManager) update(operation internal.UpgradeKymaOperation, state domain.LastOperationState, description string) (internal.UpgradeKymaOperation, time.Duration) {
 	operation.State = state
 	operation.Description = description
+	operation.ResultReason = resultReason(description)
 
 	return om.UpdateOperation(operation)
 }
+
+// retriedSince returns the time since which the step of the operation is retried, the retries are stored
+// in the operation, so the time of the last update of the operation cannot be used
+func retriedSince(operation internal.RuntimeOperation) time.Time {
+	if operation.RetriedSince.IsZero() {
+		return operation.UpdatedAt
+	}
+	return operation.RetriedSince
+}
+
+// markRetried counts the retry of the step in the operation
+func markRetried(operation *internal.RuntimeOperation) {
+	operation.RetriedSince = retriedSince(*operation)
+	operation.RetryCount++
+	operation.LastRetryAt = time.Now()
+}
+
+// maxResultReasonLength limits the length of the operation result reason
+const maxResultReasonLength = 64
+
+// resultReason shortens the operation description to the reason of the result, the details following
+// the first colon (e.g. the error message or the timeout) are dropped so the results can be grouped by the reason
+func resultReason(description string) string {
+	reason := description
+	if i := strings.Index(reason, ":"); i > 0 {
+		reason = reason[:i]
+	}
+	runes := []rune(strings.TrimSpace(reason))
+	if len(runes) > maxResultReasonLength {
+		runes = runes[:maxResultReasonLength]
+	}
+	return string(runes)
+}
+
+// store updates the operation within the span of the storage call, see storeOperation for the conflict handling
+func (om *UpgradeKymaOperationManager) store(operation internal.UpgradeKymaOperation) (updatedOperation *internal.UpgradeKymaOperation, err error) {
+	err = storeOperation(om.ctx, "storage/UpdateUpgradeKymaOperation", func() error {
+		updatedOperation, err = om.storage.UpdateUpgradeKymaOperation(operation)
+		return err
+	}, func() (*internal.Operation, error) {
+		updatedOperation, err = om.storage.GetUpgradeKymaOperationByID(operation.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &updatedOperation.Operation, nil
+	})
+	return updatedOperation, err
+}
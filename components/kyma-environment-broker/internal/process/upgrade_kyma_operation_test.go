@@ -2,6 +2,7 @@ package process
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -48,9 +49,34 @@ func TestUpgradeKymaOperationManager_OperationFailed(t *testing.T) {
 	assert.Error(t, err)
 	assert.EqualError(t, err, errMsg)
 	assert.Equal(t, domain.Failed, op.State)
+	assert.Equal(t, errMsg, op.ResultReason)
 	assert.Equal(t, time.Duration(0), when)
 }
 
+func TestResultReason(t *testing.T) {
+	for tn, tc := range map[string]struct {
+		description string
+		expected    string
+	}{
+		"empty description": {
+			description: "",
+			expected:    "",
+		},
+		"description with details": {
+			description: "unable to get runtime state: connection refused",
+			expected:    "unable to get runtime state",
+		},
+		"too long description": {
+			description: strings.Repeat("ą", maxResultReasonLength+10),
+			expected:    strings.Repeat("ą", maxResultReasonLength),
+		},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			assert.Equal(t, tc.expected, resultReason(tc.description))
+		})
+	}
+}
+
 func TestUpgradeKymaOperationManager_RetryOperation(t *testing.T) {
 	// given
 	memory := storage.NewMemoryStorage()
@@ -85,6 +111,47 @@ func TestUpgradeKymaOperationManager_RetryOperation(t *testing.T) {
 
 }
 
+func TestUpgradeKymaOperationManager_RetryOperationCountsRetries(t *testing.T) {
+	// given
+	memory := storage.NewMemoryStorage()
+	operations := memory.Operations()
+	opManager := NewUpgradeKymaOperationManager(operations)
+	op := fixUpgradeKymaOperation()
+	op.UpdatedAt = time.Now().Add(-time.Hour)
+	err := operations.InsertUpgradeKymaOperation(op)
+	require.NoError(t, err)
+
+	// when
+	op, when, err := opManager.RetryOperation(op, "task failed", time.Minute, 90*time.Minute, fixLogger())
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, when)
+	stored, err := operations.GetUpgradeKymaOperationByID(op.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stored.RetryCount)
+	assert.False(t, stored.LastRetryAt.IsZero())
+
+	// when the retry is stored again, the storage sets the update time
+	op.UpdatedAt = time.Now()
+	op, when, err = opManager.RetryOperation(op, "task failed", time.Minute, 90*time.Minute, fixLogger())
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, when)
+	assert.Equal(t, 2, op.RetryCount)
+
+	// when the retries exceed the time limit counted from the update before the first retry
+	op.UpdatedAt = time.Now()
+	op, when, err = opManager.RetryOperation(op, "task failed", time.Minute, 30*time.Minute, fixLogger())
+
+	// then
+	assert.EqualError(t, err, "task failed")
+	assert.Equal(t, domain.Failed, op.State)
+	assert.Equal(t, 2, op.RetryCount)
+	assert.Equal(t, time.Duration(0), when)
+}
+
 func fixUpgradeKymaOperation() internal.UpgradeKymaOperation {
 	return internal.UpgradeKymaOperation{
 		RuntimeOperation: internal.RuntimeOperation{
@@ -7,10 +7,12 @@ import (
 
 	kebError "github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/error"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/tracing"
 
 	gcli "github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/third_party/machinebox/graphql"
 	schema "github.com/kyma-project/control-plane/components/provisioner/pkg/gqlschema"
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/label"
 )
 
 // accountIDKey is a header key name for request send by graphQL client
@@ -25,14 +27,17 @@ type Client interface {
 	ProvisionRuntime(accountID, subAccountID string, config schema.ProvisionRuntimeInput) (schema.OperationStatus, error)
 	DeprovisionRuntime(accountID, runtimeID string) (string, error)
 	UpgradeRuntime(accountID, runtimeID string, config schema.UpgradeRuntimeInput) (schema.OperationStatus, error)
+	UpgradeShoot(accountID, runtimeID string, config schema.UpgradeShootInput) (schema.OperationStatus, error)
 	ReconnectRuntimeAgent(accountID, runtimeID string) (string, error)
 	RuntimeOperationStatus(accountID, operationID string) (schema.OperationStatus, error)
+	RuntimeStatus(accountID, runtimeID string) (schema.RuntimeStatus, error)
 }
 
 type client struct {
 	graphQLClient *gcli.Client
 	queryProvider queryProvider
 	graphqlizer   Graphqlizer
+	ctx           context.Context
 }
 
 func NewProvisionerClient(endpoint string, queryDumping bool) Client {
@@ -47,9 +52,24 @@ func NewProvisionerClient(endpoint string, queryDumping bool) Client {
 		graphQLClient: graphQlClient,
 		queryProvider: queryProvider{},
 		graphqlizer:   Graphqlizer{},
+		ctx:           context.Background(),
 	}
 }
 
+// WithContext returns the client which sends the requests with the given context, so the requests are cancelled
+// when the context is done and are recorded in the trace of the context. The correlation ID carried by the context
+// is sent in the header of every request, so the Provisioner operations can be linked with the KEB operations.
+// Other implementations of the Client, for example the mocks, are returned unchanged.
+func WithContext(c Client, ctx context.Context) Client {
+	cli, ok := c.(*client)
+	if !ok {
+		return c
+	}
+	withContext := *cli
+	withContext.ctx = ctx
+	return &withContext
+}
+
 func (c *client) ProvisionRuntime(accountID, subAccountID string, config schema.ProvisionRuntimeInput) (schema.OperationStatus, error) {
 	provisionRuntimeIptGQL, err := c.graphqlizer.ProvisionRuntimeInputToGraphQL(config)
 	if err != nil {
@@ -62,7 +82,7 @@ func (c *client) ProvisionRuntime(accountID, subAccountID string, config schema.
 	req.Header.Add(subAccountIDKey, subAccountID)
 
 	var response schema.OperationStatus
-	err = c.executeRequest(req, &response)
+	err = c.executeRequest("ProvisionRuntime", req, &response)
 	if err != nil {
 		return schema.OperationStatus{}, errors.Wrap(err, "failed to provision a Runtime")
 	}
@@ -76,7 +96,7 @@ func (c *client) DeprovisionRuntime(accountID, runtimeID string) (string, error)
 	req.Header.Add(accountIDKey, accountID)
 
 	var operationId string
-	err := c.executeRequest(req, &operationId)
+	err := c.executeRequest("DeprovisionRuntime", req, &operationId)
 	if err != nil {
 		return "", errors.Wrap(err, "Failed to deprovision Runtime")
 	}
@@ -94,20 +114,38 @@ func (c *client) UpgradeRuntime(accountID, runtimeID string, config schema.Upgra
 	req.Header.Add(accountIDKey, accountID)
 
 	var res schema.OperationStatus
-	err = c.executeRequest(req, &res)
+	err = c.executeRequest("UpgradeRuntime", req, &res)
 	if err != nil {
 		return schema.OperationStatus{}, errors.Wrap(err, "Failed to upgrade Runtime")
 	}
 	return res, nil
 }
 
+func (c *client) UpgradeShoot(accountID, runtimeID string, config schema.UpgradeShootInput) (schema.OperationStatus, error) {
+	upgradeShootIptGQL, err := c.graphqlizer.UpgradeShootInputToGraphQL(config)
+	if err != nil {
+		return schema.OperationStatus{}, errors.Wrap(err, "Failed to convert Upgrade Shoot Input to query")
+	}
+
+	query := c.queryProvider.upgradeShoot(runtimeID, upgradeShootIptGQL)
+	req := gcli.NewRequest(query)
+	req.Header.Add(accountIDKey, accountID)
+
+	var res schema.OperationStatus
+	err = c.executeRequest("UpgradeShoot", req, &res)
+	if err != nil {
+		return schema.OperationStatus{}, errors.Wrap(err, "Failed to upgrade Shoot")
+	}
+	return res, nil
+}
+
 func (c *client) ReconnectRuntimeAgent(accountID, runtimeID string) (string, error) {
 	query := c.queryProvider.reconnectRuntimeAgent(runtimeID)
 	req := gcli.NewRequest(query)
 	req.Header.Add(accountIDKey, accountID)
 
 	var operationId string
-	err := c.executeRequest(req, &operationId)
+	err := c.executeRequest("ReconnectRuntimeAgent", req, &operationId)
 	if err != nil {
 		return "", errors.Wrap(err, "Failed to reconnect Runtime agent")
 	}
@@ -120,14 +158,27 @@ func (c *client) RuntimeOperationStatus(accountID, operationID string) (schema.O
 	req.Header.Add(accountIDKey, accountID)
 
 	var response schema.OperationStatus
-	err := c.executeRequest(req, &response)
+	err := c.executeRequest("RuntimeOperationStatus", req, &response)
 	if err != nil {
 		return schema.OperationStatus{}, errors.Wrap(err, "Failed to get Runtime operation status")
 	}
 	return response, nil
 }
 
-func (c *client) executeRequest(req *gcli.Request, respDestination interface{}) error {
+func (c *client) RuntimeStatus(accountID, runtimeID string) (schema.RuntimeStatus, error) {
+	query := c.queryProvider.runtimeStatus(runtimeID)
+	req := gcli.NewRequest(query)
+	req.Header.Add(accountIDKey, accountID)
+
+	var response schema.RuntimeStatus
+	err := c.executeRequest("RuntimeStatus", req, &response)
+	if err != nil {
+		return schema.RuntimeStatus{}, errors.Wrap(err, "Failed to get Runtime status")
+	}
+	return response, nil
+}
+
+func (c *client) executeRequest(name string, req *gcli.Request, respDestination interface{}) (err error) {
 	if reflect.ValueOf(respDestination).Kind() != reflect.Ptr {
 		return errors.New("destination is not of pointer type")
 	}
@@ -136,8 +187,17 @@ func (c *client) executeRequest(req *gcli.Request, respDestination interface{})
 		Result interface{} `json:"result"`
 	}
 
+	if correlationID, ok := httputil.CorrelationIDFromContext(c.ctx); ok {
+		req.Header.Set(httputil.CorrelationIDHeader, correlationID)
+	}
+
+	ctx, span := tracing.StartSpan(c.ctx, fmt.Sprintf("provisioner/%s", name), label.String("keb.provisioner.tenant", req.Header.Get(accountIDKey)))
+	defer func() {
+		tracing.End(ctx, span, err)
+	}()
+
 	wrapper := &graphQLResponseWrapper{Result: respDestination}
-	err := c.graphQLClient.Run(context.TODO(), req, wrapper)
+	err = c.graphQLClient.Run(ctx, req, wrapper)
 	switch {
 	case isClientError(err):
 		return err
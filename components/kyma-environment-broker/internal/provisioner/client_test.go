@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	kebError "github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/error"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/httputil"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/ptr"
 	schema "github.com/kyma-project/control-plane/components/provisioner/pkg/gqlschema"
 
@@ -23,6 +24,7 @@ const (
 	provisionRuntimeID            = "4e268c0f-d053-4ab7-b167-6dbc0a0e09a6"
 	provisionRuntimeOperationID   = "c89f7862-0ef9-4d4e-bc82-afbc5ac98b8d"
 	upgradeRuntimeOperationID     = "74f47e0a-9a76-4336-9974-70705500a981"
+	upgradeShootOperationID       = "1b1bd7a1-43c2-4c8a-a4a6-5b1c2ad0c7d2"
 	deprovisionRuntimeOperationID = "f9f7b734-7538-419c-8ac1-37060c60531a"
 )
 
@@ -64,6 +66,23 @@ func TestClient_ProvisionRuntime(t *testing.T) {
 
 		assert.Equal(t, "", tr.getRuntime().name)
 	})
+
+	t.Run("should send correlation ID", func(t *testing.T) {
+		// Given
+		tr := &testResolver{t: t, runtime: &testRuntime{}}
+		testServer := fixHTTPServer(tr)
+		defer testServer.Close()
+
+		ctx := httputil.ContextWithCorrelationID(context.Background(), "correlation-id")
+		client := WithContext(NewProvisionerClient(testServer.URL, false), ctx)
+
+		// When
+		_, err := client.ProvisionRuntime(testAccountID, testSubAccountID, fixProvisionRuntimeInput())
+
+		// Then
+		assert.NoError(t, err)
+		assert.Equal(t, "correlation-id", tr.getRuntime().correlationID)
+	})
 }
 
 func TestClient_DeprovisionRuntime(t *testing.T) {
@@ -155,6 +174,62 @@ func TestClient_UpgradeRuntime(t *testing.T) {
 	})
 }
 
+func TestClient_UpgradeShoot(t *testing.T) {
+	t.Run("should trigger shoot upgrade", func(t *testing.T) {
+		// given
+		tr := &testResolver{t: t, runtime: &testRuntime{}}
+		testServer := fixHTTPServer(tr)
+		defer testServer.Close()
+
+		client := NewProvisionerClient(testServer.URL, false)
+		operation, err := client.ProvisionRuntime(testAccountID, testSubAccountID, fixProvisionRuntimeInput())
+		assert.NoError(t, err)
+
+		// when
+		status, err := client.UpgradeShoot(testAccountID, *operation.RuntimeID, schema.UpgradeShootInput{
+			GardenerConfig: &schema.GardenerUpgradeInput{
+				MachineType:   ptr.String("Standard_D8_v3"),
+				AutoScalerMin: ptr.Integer(3),
+				AutoScalerMax: ptr.Integer(10),
+			},
+		})
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, ptr.String(upgradeShootOperationID), status.ID)
+		assert.Equal(t, schema.OperationStateInProgress, status.State)
+		assert.Equal(t, schema.OperationTypeUpgradeShoot, status.Operation)
+		assert.Equal(t, ptr.String(provisionRuntimeID), status.RuntimeID)
+		assert.Equal(t, "Standard_D8_v3", *tr.getRuntime().shootUpgrade.MachineType)
+		assert.Equal(t, 3, *tr.getRuntime().shootUpgrade.AutoScalerMin)
+		assert.Equal(t, 10, *tr.getRuntime().shootUpgrade.AutoScalerMax)
+		assert.Nil(t, tr.getRuntime().shootUpgrade.MaxSurge)
+	})
+
+	t.Run("provisioner should return error", func(t *testing.T) {
+		// given
+		tr := &testResolver{t: t, runtime: &testRuntime{}}
+		testServer := fixHTTPServer(tr)
+		defer testServer.Close()
+
+		client := NewProvisionerClient(testServer.URL, false)
+		operation, err := client.ProvisionRuntime(testAccountID, testSubAccountID, fixProvisionRuntimeInput())
+		assert.NoError(t, err)
+
+		tr.failed = true
+
+		// when
+		status, err := client.UpgradeShoot(testAccountID, *operation.RuntimeID, schema.UpgradeShootInput{
+			GardenerConfig: &schema.GardenerUpgradeInput{AutoScalerMax: ptr.Integer(10)},
+		})
+
+		// then
+		assert.Error(t, err)
+		assert.Empty(t, status)
+		assert.Nil(t, tr.getRuntime().shootUpgrade)
+	})
+}
+
 func TestClient_ReconnectRuntimeAgent(t *testing.T) {
 	t.Run("should reconnect runtime agent", func(t *testing.T) {
 		// Given
@@ -310,11 +385,13 @@ func TestClient_RuntimeOperationStatus(t *testing.T) {
 type testRuntime struct {
 	tenant                 string
 	clientID               string
+	correlationID          string
 	name                   string
 	runtimeID              string
 	provisionOperationID   string
 	upgradeOperationID     string
 	deprovisionOperationID string
+	shootUpgrade           *schema.GardenerUpgradeInput
 }
 
 type testResolver struct {
@@ -354,6 +431,7 @@ func fixHTTPServer(tr *testResolver) *httptest.Server {
 			}
 			tr.runtime.tenant = accountID
 			tr.runtime.clientID = subAccountID
+			tr.runtime.correlationID = r.Header.Get(httputil.CorrelationIDHeader)
 
 			h.ServeHTTP(w, r)
 		})
@@ -454,8 +532,23 @@ func (tmr testMutationResolver) ReconnectRuntimeAgent(_ context.Context, id stri
 	return "", nil
 }
 
-func (tqr testMutationResolver) UpgradeShoot(ctx context.Context, id string, config schema.UpgradeShootInput) (*schema.OperationStatus, error) {
-	return nil, nil
+func (tmr testMutationResolver) UpgradeShoot(_ context.Context, id string, config schema.UpgradeShootInput) (*schema.OperationStatus, error) {
+	tmr.t.Log("UpgradeShoot testMutationResolver")
+
+	if tmr.failed {
+		return nil, fmt.Errorf("upgrade shoot failed for %s", id)
+	}
+
+	if tmr.runtime.runtimeID == id {
+		tmr.runtime.shootUpgrade = config.GardenerConfig
+	}
+
+	return &schema.OperationStatus{
+		ID:        ptr.String(upgradeShootOperationID),
+		State:     schema.OperationStateInProgress,
+		Operation: schema.OperationTypeUpgradeShoot,
+		RuntimeID: ptr.String(tmr.runtime.runtimeID),
+	}, nil
 }
 
 type testQueryResolver struct {
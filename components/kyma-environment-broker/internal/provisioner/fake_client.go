@@ -13,20 +13,37 @@ type runtime struct {
 }
 
 type FakeClient struct {
-	mu         sync.Mutex
-	runtimes   []runtime
-	upgrades   map[string]schema.UpgradeRuntimeInput
-	operations map[string]schema.OperationStatus
+	mu            sync.Mutex
+	runtimes      []runtime
+	upgrades      map[string]schema.UpgradeRuntimeInput
+	shootUpgrades map[string]schema.UpgradeShootInput
+	operations    map[string]schema.OperationStatus
+	kubeconfigs   map[string]string
+	shootNames    map[string]string
+
+	// autoFinish finishes the operation successfully when its status is checked
+	autoFinish bool
 }
 
 func NewFakeClient() *FakeClient {
 	return &FakeClient{
-		runtimes:   []runtime{},
-		operations: make(map[string]schema.OperationStatus),
-		upgrades:   make(map[string]schema.UpgradeRuntimeInput),
+		runtimes:      []runtime{},
+		operations:    make(map[string]schema.OperationStatus),
+		upgrades:      make(map[string]schema.UpgradeRuntimeInput),
+		shootUpgrades: make(map[string]schema.UpgradeShootInput),
+		kubeconfigs:   make(map[string]string),
+		shootNames:    make(map[string]string),
 	}
 }
 
+// NewFakeClientWithAutoFinish creates the fake client which finishes every operation successfully
+// the first time its status is checked, which allows to run the broker without the Provisioner
+func NewFakeClientWithAutoFinish() *FakeClient {
+	c := NewFakeClient()
+	c.autoFinish = true
+	return c
+}
+
 func (c *FakeClient) GetProvisionRuntimeInput(index int) schema.ProvisionRuntimeInput {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -63,6 +80,20 @@ func (c *FakeClient) SetOperation(id string, operation schema.OperationStatus) {
 	c.operations[id] = operation
 }
 
+func (c *FakeClient) SetKubeconfig(runtimeID, kubeconfig string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.kubeconfigs[runtimeID] = kubeconfig
+}
+
+func (c *FakeClient) SetShootName(runtimeID, shootName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.shootNames[runtimeID] = shootName
+}
+
 // Provisioner Client methods
 
 func (c *FakeClient) ProvisionRuntime(accountID, subAccountID string, config schema.ProvisionRuntimeInput) (schema.OperationStatus, error) {
@@ -87,7 +118,17 @@ func (c *FakeClient) ProvisionRuntime(accountID, subAccountID string, config sch
 }
 
 func (c *FakeClient) DeprovisionRuntime(accountID, runtimeID string) (string, error) {
-	return uuid.New().String(), nil
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	opId := uuid.New().String()
+	c.operations[opId] = schema.OperationStatus{
+		ID:        &opId,
+		RuntimeID: &runtimeID,
+		Operation: schema.OperationTypeDeprovision,
+		State:     schema.OperationStateInProgress,
+	}
+	return opId, nil
 }
 
 func (c *FakeClient) ReconnectRuntimeAgent(accountID, runtimeID string) (string, error) {
@@ -102,9 +143,32 @@ func (c *FakeClient) RuntimeOperationStatus(accountID, operationID string) (sche
 	if !found {
 		return schema.OperationStatus{}, fmt.Errorf("operation not found")
 	}
+	if c.autoFinish && o.State == schema.OperationStateInProgress {
+		o.State = schema.OperationStateSucceeded
+		c.operations[operationID] = o
+	}
 	return o, nil
 }
 
+func (c *FakeClient) RuntimeStatus(accountID, runtimeID string) (schema.RuntimeStatus, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	status := schema.RuntimeStatus{}
+	if shootName, found := c.shootNames[runtimeID]; found {
+		status.RuntimeConfiguration = &schema.RuntimeConfig{
+			ClusterConfig: &schema.GardenerConfig{Name: &shootName},
+		}
+	}
+	if kubeconfig, found := c.kubeconfigs[runtimeID]; found {
+		if status.RuntimeConfiguration == nil {
+			status.RuntimeConfiguration = &schema.RuntimeConfig{}
+		}
+		status.RuntimeConfiguration.Kubeconfig = &kubeconfig
+	}
+	return status, nil
+}
+
 func (c *FakeClient) UpgradeRuntime(accountID, runtimeID string, config schema.UpgradeRuntimeInput) (schema.OperationStatus, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -123,7 +187,34 @@ func (c *FakeClient) UpgradeRuntime(accountID, runtimeID string, config schema.U
 	}, nil
 }
 
+func (c *FakeClient) UpgradeShoot(accountID, runtimeID string, config schema.UpgradeShootInput) (schema.OperationStatus, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	opId := uuid.New().String()
+	c.operations[opId] = schema.OperationStatus{
+		ID:        &opId,
+		RuntimeID: &runtimeID,
+		Operation: schema.OperationTypeUpgradeShoot,
+		State:     schema.OperationStateInProgress,
+	}
+	c.shootUpgrades[runtimeID] = config
+	return schema.OperationStatus{
+		RuntimeID: &runtimeID,
+		ID:        &opId,
+	}, nil
+}
+
 func (c *FakeClient) IsRuntimeUpgraded(runtimeID string) bool {
 	_, found := c.upgrades[runtimeID]
 	return found
 }
+
+// ShootUpgrade returns the input of the last shoot upgrade of the runtime
+func (c *FakeClient) ShootUpgrade(runtimeID string) (schema.UpgradeShootInput, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	input, found := c.shootUpgrades[runtimeID]
+	return input, found
+}
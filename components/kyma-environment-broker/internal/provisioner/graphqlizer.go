@@ -198,7 +198,51 @@ func (g *Graphqlizer) marshal(obj interface{}) string {
 
 func (g *Graphqlizer) UpgradeRuntimeInputToGraphQL(in gqlschema.UpgradeRuntimeInput) (string, error) {
 	return g.genericToGraphQL(in, `{
-		kymaConfig: {{ KymaConfigToGraphQL .KymaConfig }}
+		kymaConfig: {{ KymaConfigToGraphQL .KymaConfig }},
+		{{- if .InstallationTimeoutSeconds }}
+		installationTimeoutSeconds: {{ .InstallationTimeoutSeconds }},
+		{{- end }}
+	}`)
+}
+
+// UpgradeShootInputToGraphQL renders only the parameters which are set, the other parameters of the shoot are not changed
+func (g *Graphqlizer) UpgradeShootInputToGraphQL(in gqlschema.UpgradeShootInput) (string, error) {
+	return g.genericToGraphQL(in, `{
+		{{- if .GardenerConfig }}
+		gardenerConfig: {{ GardenerUpgradeInputToGraphQL .GardenerConfig }},
+		{{- end }}
+	}`)
+}
+
+func (g *Graphqlizer) GardenerUpgradeInputToGraphQL(in gqlschema.GardenerUpgradeInput) (string, error) {
+	return g.genericToGraphQL(in, `{
+		{{- if .KubernetesVersion }}
+		kubernetesVersion: "{{ .KubernetesVersion }}",
+		{{- end }}
+		{{- if .MachineType }}
+		machineType: "{{ .MachineType }}",
+		{{- end }}
+		{{- if .DiskType }}
+		diskType: "{{ .DiskType }}",
+		{{- end }}
+		{{- if .VolumeSizeGb }}
+		volumeSizeGB: {{ .VolumeSizeGb }},
+		{{- end }}
+		{{- if .AutoScalerMin }}
+		autoScalerMin: {{ .AutoScalerMin }},
+		{{- end }}
+		{{- if .AutoScalerMax }}
+		autoScalerMax: {{ .AutoScalerMax }},
+		{{- end }}
+		{{- if .MaxSurge }}
+		maxSurge: {{ .MaxSurge }},
+		{{- end }}
+		{{- if .MaxUnavailable }}
+		maxUnavailable: {{ .MaxUnavailable }},
+		{{- end }}
+		{{- if .Purpose }}
+		purpose: "{{ .Purpose }}",
+		{{- end }}
 	}`)
 }
 
@@ -209,6 +253,7 @@ func (g *Graphqlizer) genericToGraphQL(obj interface{}, tmpl string) (string, er
 	fm["ClusterConfigToGraphQL"] = g.ClusterConfigToGraphQL
 	fm["KymaConfigToGraphQL"] = g.KymaConfigToGraphQL
 	fm["GardenerConfigInputToGraphQL"] = g.GardenerConfigInputToGraphQL
+	fm["GardenerUpgradeInputToGraphQL"] = g.GardenerUpgradeInputToGraphQL
 	fm["AzureProviderConfigInputToGraphQL"] = g.AzureProviderConfigInputToGraphQL
 	fm["GCPProviderConfigInputToGraphQL"] = g.GCPProviderConfigInputToGraphQL
 	fm["AWSProviderConfigInputToGraphQL"] = g.AWSProviderConfigInputToGraphQL
@@ -197,6 +197,52 @@ func Test_GardenerConfigInputToGraphQLWithMachineImage(t *testing.T) {
 	assert.Equal(t, exp, got)
 }
 
+func Test_UpgradeShootInputToGraphQL(t *testing.T) {
+	// given
+	sut := Graphqlizer{}
+	exp := `{
+		gardenerConfig: {
+		machineType: "Standard_D8_v3",
+		autoScalerMin: 3,
+		autoScalerMax: 10,
+	},
+	}`
+
+	// when
+	got, err := sut.UpgradeShootInputToGraphQL(gqlschema.UpgradeShootInput{
+		GardenerConfig: &gqlschema.GardenerUpgradeInput{
+			MachineType:   ptr.String("Standard_D8_v3"),
+			AutoScalerMin: ptr.Integer(3),
+			AutoScalerMax: ptr.Integer(10),
+		},
+	})
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, exp, got)
+}
+
+func Test_UpgradeRuntimeInputToGraphQL(t *testing.T) {
+	// given
+	sut := Graphqlizer{}
+	exp := `{
+		kymaConfig: {
+		version: "1.18.0",
+	},
+		installationTimeoutSeconds: 10800,
+	}`
+
+	// when
+	got, err := sut.UpgradeRuntimeInputToGraphQL(gqlschema.UpgradeRuntimeInput{
+		KymaConfig:                 &gqlschema.KymaConfigInput{Version: "1.18.0"},
+		InstallationTimeoutSeconds: ptr.Integer(10800),
+	})
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, exp, got)
+}
+
 func Test_LabelsToGQL(t *testing.T) {
 
 	sut := Graphqlizer{}
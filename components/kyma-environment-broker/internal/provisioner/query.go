@@ -20,6 +20,14 @@ func (qp queryProvider) upgradeRuntime(runtimeID string, config string) string {
 }`, runtimeID, config, operationStatusData())
 }
 
+func (qp queryProvider) upgradeShoot(runtimeID string, config string) string {
+	return fmt.Sprintf(`mutation {
+	result: upgradeShoot(id: "%s", config: %s) {
+		%s
+}
+}`, runtimeID, config, operationStatusData())
+}
+
 func (qp queryProvider) deprovisionRuntime(runtimeID string) string {
 	return fmt.Sprintf(`mutation {
 	result: deprovisionRuntime(id: "%s")
@@ -32,12 +40,12 @@ func (qp queryProvider) reconnectRuntimeAgent(runtimeID string) string {
 }`, runtimeID)
 }
 
-func (qp queryProvider) runtimeStatus(operationID string) string {
+func (qp queryProvider) runtimeStatus(runtimeID string) string {
 	return fmt.Sprintf(`query {
 	result: runtimeStatus(id: "%s") {
 	%s
 	}
-}`, operationID, runtimeStatusData())
+}`, runtimeID, runtimeStatusData())
 }
 
 func (qp queryProvider) runtimeOperationStatus(operationID string) string {
@@ -5,6 +5,7 @@ import (
 
 	pkg "github.com/kyma-project/control-plane/components/kyma-environment-broker/common/runtime"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
 	"github.com/pkg/errors"
 )
 
@@ -18,18 +19,12 @@ func newConverter(platformRegion string) *converter {
 	}
 }
 
-func (c *converter) setRegionOrDefault(instance internal.Instance, runtime *pkg.RuntimeDTO) error {
-	pp, err := instance.GetProvisioningParameters()
-	if err != nil {
-		return errors.Wrap(err, "while getting provisioning parameters")
-	}
-
+func (c *converter) setRegionOrDefault(pp internal.ProvisioningParameters, runtime *pkg.RuntimeDTO) {
 	if pp.PlatformRegion == "" {
 		runtime.SubAccountRegion = c.defaultSubaccountRegion
 	} else {
 		runtime.SubAccountRegion = pp.PlatformRegion
 	}
-	return nil
 }
 
 func (c *converter) ApplyProvisioningOperation(dto *pkg.RuntimeDTO, pOpr *internal.ProvisioningOperation) {
@@ -45,10 +40,32 @@ func (c *converter) ApplyDeprovisioningOperation(dto *pkg.RuntimeDTO, dOpr *inte
 	}
 }
 
+func (c *converter) ApplyUpdatingOperation(dto *pkg.RuntimeDTO, uOpr *internal.UpdatingOperation) {
+	if uOpr != nil {
+		dto.Status.Update = &pkg.Operation{}
+		c.applyOperation(&uOpr.Operation, dto.Status.Update)
+	}
+}
+
+// ApplySuspensionOperation sets the latest suspension operation. The runtime is suspended when its suspension
+// succeeded and until its resumption succeeded, the cluster is still running while the suspension is in progress.
+func (c *converter) ApplySuspensionOperation(dto *pkg.RuntimeDTO, sOpr *internal.SuspensionOperation) {
+	if sOpr != nil {
+		dto.Status.Suspension = &pkg.SuspensionOperation{Action: sOpr.Action}
+		c.applyOperation(&sOpr.Operation, &dto.Status.Suspension.Operation)
+		if sOpr.Suspends() {
+			dto.Status.Suspended = sOpr.State == domain.Succeeded
+		} else {
+			dto.Status.Suspended = sOpr.State == domain.InProgress
+		}
+	}
+}
+
 func (c *converter) applyOperation(source *internal.Operation, target *pkg.Operation) {
 	if source != nil {
 		target.OperationID = source.ID
 		target.CreatedAt = source.CreatedAt
+		target.UpdatedAt = source.UpdatedAt
 		target.State = string(source.State)
 		target.Description = source.Description
 		if source.OrchestrationID != "" {
@@ -75,19 +92,98 @@ func (c *converter) NewDTO(instance internal.Instance) (pkg.RuntimeDTO, error) {
 		},
 	}
 
-	err := c.setRegionOrDefault(instance, &toReturn)
+	pp, err := instance.GetProvisioningParameters()
 	if err != nil {
-		return pkg.RuntimeDTO{}, errors.Wrap(err, "while setting region")
+		return pkg.RuntimeDTO{}, errors.Wrap(err, "while getting provisioning parameters")
 	}
+	c.setRegionOrDefault(pp, &toReturn)
+	toReturn.UserID = pp.ErsContext.UserID
 
-	urlSplitted := strings.Split(instance.DashboardURL, ".")
-	if len(urlSplitted) > 1 {
-		toReturn.ShootName = urlSplitted[1]
+	toReturn.ShootName = instance.ShootName
+	if toReturn.ShootName == "" {
+		// the shoot name of the instances provisioned before the shoot name was stored is taken from the dashboard URL
+		// until the instance is backfilled, it works only for the default domain of the console
+		urlSplitted := strings.Split(instance.DashboardURL, ".")
+		if len(urlSplitted) > 1 {
+			toReturn.ShootName = urlSplitted[1]
+		}
 	}
 
 	return toReturn, nil
 }
 
+func (c *converter) ApplyAccess(dto *pkg.RuntimeDTO, instance internal.Instance) {
+	if instance.APIServerURL == "" {
+		return
+	}
+	dto.Access = &pkg.RuntimeAccess{
+		APIServerURL: instance.APIServerURL,
+		CABundle:     instance.CABundle,
+	}
+}
+
+// ApplyKymaVersions sets the Kyma versions of the provisioning and upgrade operations from the given versions
+// by the operation ID. The runtime is on the version of the latest succeeded operation, the upgrade operations
+// have to contain all upgrades of the runtime, not only the returned ones.
+func (c *converter) ApplyKymaVersions(dto *pkg.RuntimeDTO, versions map[string]string, ukOprs []internal.UpgradeKymaOperation) {
+	if op := dto.Status.Provisioning; op != nil {
+		op.KymaVersion = versions[op.OperationID]
+		if op.State == string(domain.Succeeded) {
+			dto.KymaVersion = op.KymaVersion
+		}
+	}
+	for i := range dto.Status.UpgradingKyma.Data {
+		op := &dto.Status.UpgradingKyma.Data[i]
+		op.KymaVersion = versions[op.OperationID]
+	}
+
+	var lastUpgrade *internal.UpgradeKymaOperation
+	for i, op := range ukOprs {
+		if op.State != domain.Succeeded || versions[op.ID] == "" {
+			continue
+		}
+		if lastUpgrade == nil || op.CreatedAt.After(lastUpgrade.CreatedAt) {
+			lastUpgrade = &ukOprs[i]
+		}
+	}
+	if lastUpgrade != nil {
+		dto.KymaVersion = versions[lastUpgrade.ID]
+	}
+}
+
+// ApplyProvisioningParameters copies the provisioning parameters of the instance which are safe to expose
+func (c *converter) ApplyProvisioningParameters(dto *pkg.RuntimeDTO, instance internal.Instance) error {
+	pp, err := instance.GetProvisioningParameters()
+	if err != nil {
+		return errors.Wrap(err, "while getting provisioning parameters")
+	}
+
+	params := pp.Parameters
+	dto.Parameters = &pkg.ProvisioningParameters{
+		MachineType:   params.MachineType,
+		Region:        params.Region,
+		Zones:         params.Zones,
+		VolumeSizeGb:  params.VolumeSizeGb,
+		AutoScalerMin: params.AutoScalerMin,
+		AutoScalerMax: params.AutoScalerMax,
+		KymaVersion:   params.KymaVersion,
+	}
+	if params.Provider != nil {
+		dto.Parameters.Provider = string(*params.Provider)
+	}
+	if params.OIDC != nil {
+		dto.Parameters.OIDC = &pkg.OIDCConfig{
+			ClientID:       params.OIDC.ClientID,
+			IssuerURL:      params.OIDC.IssuerURL,
+			GroupsClaim:    params.OIDC.GroupsClaim,
+			SigningAlgs:    params.OIDC.SigningAlgs,
+			UsernameClaim:  params.OIDC.UsernameClaim,
+			UsernamePrefix: params.OIDC.UsernamePrefix,
+		}
+	}
+	return nil
+}
+
 func (c *converter) ApplyUpgradingKymaOperations(dto *pkg.RuntimeDTO, oprs []internal.UpgradeKymaOperation, totalCount int) {
 	dto.Status.UpgradingKyma.TotalCount = totalCount
 	dto.Status.UpgradingKyma.Count = len(oprs)
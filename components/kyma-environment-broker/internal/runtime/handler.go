@@ -2,10 +2,14 @@ package runtime
 
 import (
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/common/pagination"
 	pkg "github.com/kyma-project/control-plane/components/kyma-environment-broker/common/runtime"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/broker"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/httputil"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
@@ -17,30 +21,73 @@ import (
 
 const numberOfUpgradeOperationsToReturn = 2
 
+// runtimeFields are the fields of the runtime which can be selected with the fields query parameter
+var runtimeFields = httputil.FieldNames(pkg.RuntimeDTO{})
+
 //go:generate mockery -name=Converter -output=automock -outpkg=automock -case=underscore
 type Converter interface {
 	InstancesAndOperationsToDTO(internal.Instance, *internal.ProvisioningOperation, *internal.DeprovisioningOperation, *internal.UpgradeKymaOperation) (pkg.RuntimeDTO, error)
 }
 
 type Handler struct {
-	instancesDb  storage.Instances
-	operationsDb storage.Operations
-	converter    *converter
+	instancesDb     storage.Instances
+	operationsDb    storage.Operations
+	archivedDb      storage.InstancesArchived
+	runtimeStatesDb storage.RuntimeStates
+	expirationsDb   storage.TrialExpirations
+	converter       *converter
 
 	defaultMaxPage int
 }
 
-func NewHandler(instanceDb storage.Instances, operationDb storage.Operations, defaultMaxPage int, defaultRequestRegion string) *Handler {
+func NewHandler(instanceDb storage.Instances, operationDb storage.Operations, archivedDb storage.InstancesArchived, runtimeStatesDb storage.RuntimeStates, expirationsDb storage.TrialExpirations, defaultMaxPage int, defaultRequestRegion string) *Handler {
 	return &Handler{
-		instancesDb:    instanceDb,
-		operationsDb:   operationDb,
-		converter:      newConverter(defaultRequestRegion),
-		defaultMaxPage: defaultMaxPage,
+		instancesDb:     instanceDb,
+		operationsDb:    operationDb,
+		archivedDb:      archivedDb,
+		runtimeStatesDb: runtimeStatesDb,
+		expirationsDb:   expirationsDb,
+		converter:       newConverter(defaultRequestRegion),
+		defaultMaxPage:  defaultMaxPage,
 	}
 }
 
 func (h *Handler) AttachRoutes(router *mux.Router) {
 	router.HandleFunc("/runtimes", h.getRuntimes)
+	router.HandleFunc("/runtimes/{runtime_id}", h.getRuntime).Methods(http.MethodGet)
+}
+
+// getRuntime returns details of the runtime including the data required to access its API server
+func (h *Handler) getRuntime(w http.ResponseWriter, req *http.Request) {
+	runtimeID := mux.Vars(req)["runtime_id"]
+	withParams, err := h.getWithParameters(req)
+	if err != nil {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, errors.Wrap(err, "while getting query parameters"))
+		return
+	}
+
+	instances, _, _, err := h.instancesDb.ListWithState(dbmodel.InstanceFilter{
+		PageSize:   1,
+		Page:       1,
+		RuntimeIDs: []string{runtimeID},
+	})
+	if err != nil {
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "while fetching instance"))
+		return
+	}
+	if len(instances) == 0 {
+		httputil.WriteErrorResponse(w, http.StatusNotFound, errors.Errorf("runtime %s not found", runtimeID))
+		return
+	}
+
+	dto, err := h.runtimeDTO(instances[0], withParams, true)
+	if err != nil {
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.converter.ApplyAccess(&dto, instances[0].Instance)
+
+	httputil.WriteResponse(w, http.StatusOK, dto)
 }
 
 func (h *Handler) getRuntimes(w http.ResponseWriter, req *http.Request) {
@@ -51,54 +98,285 @@ func (h *Handler) getRuntimes(w http.ResponseWriter, req *http.Request) {
 		httputil.WriteErrorResponse(w, http.StatusBadRequest, errors.Wrap(err, "while getting query parameters"))
 		return
 	}
-	filter := h.getFilters(req)
+	filter, err := h.getFilters(req)
+	if err != nil {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, errors.Wrap(err, "while getting query parameters"))
+		return
+	}
 	filter.PageSize = pageSize
 	filter.Page = page
+	filter.After, err = h.getCursor(req)
+	if err != nil {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, errors.Wrap(err, "while getting query parameters"))
+		return
+	}
+	withParams, err := h.getWithParameters(req)
+	if err != nil {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, errors.Wrap(err, "while getting query parameters"))
+		return
+	}
+	fields, err := httputil.Fields(req.URL.Query(), runtimeFields)
+	if err != nil {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, errors.Wrap(err, "while getting query parameters"))
+		return
+	}
+	// the operations and the large columns of the instances are loaded only if the fields which need them are requested
+	withOperations := httputil.HasField(fields, "status") || httputil.HasField(fields, "kymaVersion")
+	filter.SkipLargeColumns = !httputil.HasField(fields, "subAccountRegion") && !httputil.HasField(fields, "parameters") && !httputil.HasField(fields, "userID")
 
-	instances, count, totalCount, err := h.instancesDb.List(filter)
+	switch state := req.URL.Query().Get(pkg.StateParam); state {
+	case "":
+	case pkg.StateDeprovisioned:
+		h.getDeprovisionedRuntimes(w, filter, withParams, fields)
+		return
+	default:
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, errors.Errorf("unsupported %s query parameter value: %s", pkg.StateParam, state))
+		return
+	}
+
+	instances, count, totalCount, err := h.instancesDb.ListWithState(filter)
 	if err != nil {
 		httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "while fetching instances"))
 		return
 	}
 
 	for _, instance := range instances {
-		dto, err := h.converter.NewDTO(instance)
+		dto, err := h.runtimeDTO(instance, withParams, withOperations)
 		if err != nil {
-			httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "while converting instance to DTO"))
+			httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
 			return
 		}
+		toReturn = append(toReturn, dto)
+	}
 
-		pOpr, err := h.operationsDb.GetProvisioningOperationByInstanceID(instance.InstanceID)
-		if err != nil && !dberr.IsNotFound(err) {
-			httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "while fetching provisioning operation for instance"))
+	var nextCursor string
+	if len(instances) == filter.PageSize {
+		nextCursor = cursorAfter(instances[len(instances)-1].Instance)
+	}
+	h.writeRuntimesPage(w, toReturn, count, totalCount, nextCursor, fields)
+}
+
+// getCursor returns the cursor after which the requested page starts, nil is returned if the runtimes
+// are requested by the page number
+func (h *Handler) getCursor(req *http.Request) (*dbmodel.InstanceCursor, error) {
+	createdAt, instanceID, found, err := pagination.ExtractCursorFromRequest(req)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &dbmodel.InstanceCursor{CreatedAt: createdAt, InstanceID: instanceID}, nil
+}
+
+// cursorAfter returns the cursor of the page following the page which ends with the given instance. The cursor
+// is returned only for the full pages, because there are no more runtimes after the page which is not full.
+func cursorAfter(last internal.Instance) string {
+	return pagination.EncodeCursor(last.CreatedAt, last.InstanceID)
+}
+
+// writeRuntimesPage writes the page of the runtimes reduced to the requested fields
+func (h *Handler) writeRuntimesPage(w http.ResponseWriter, runtimes []pkg.RuntimeDTO, count, totalCount int, nextCursor string, fields []string) {
+	if len(fields) == 0 {
+		httputil.WriteResponse(w, http.StatusOK, pkg.RuntimesPage{
+			Data:       runtimes,
+			Count:      count,
+			TotalCount: totalCount,
+			NextCursor: nextCursor,
+		})
+		return
+	}
+
+	data, err := httputil.Project(runtimes, fields)
+	if err != nil {
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "while selecting runtime fields"))
+		return
+	}
+	httputil.WriteResponse(w, http.StatusOK, httputil.ProjectedPage{
+		Data:       data,
+		Count:      count,
+		TotalCount: totalCount,
+		NextCursor: nextCursor,
+	})
+}
+
+// getDeprovisionedRuntimes returns the runtimes from the archive of the instances removed after the deprovisioning
+func (h *Handler) getDeprovisionedRuntimes(w http.ResponseWriter, filter dbmodel.InstanceFilter, withParams bool, fields []string) {
+	toReturn := make([]pkg.RuntimeDTO, 0)
+
+	archived, count, totalCount, err := h.archivedDb.List(filter)
+	if err != nil {
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "while fetching archived instances"))
+		return
+	}
+
+	for _, instance := range archived {
+		dto, err := h.archivedRuntimeDTO(instance, withParams)
+		if err != nil {
+			httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
 			return
 		}
-		h.converter.ApplyProvisioningOperation(&dto, pOpr)
+		toReturn = append(toReturn, dto)
+	}
+
+	var nextCursor string
+	if len(archived) == filter.PageSize {
+		nextCursor = cursorAfter(archived[len(archived)-1].Instance)
+	}
+	h.writeRuntimesPage(w, toReturn, count, totalCount, nextCursor, fields)
+}
+
+// archivedRuntimeDTO converts the archived instance to the runtime DTO, all operations are stored together with the instance
+func (h *Handler) archivedRuntimeDTO(archived internal.ArchivedInstance, withParams bool) (pkg.RuntimeDTO, error) {
+	dto, err := h.converter.NewDTO(archived.Instance)
+	if err != nil {
+		return pkg.RuntimeDTO{}, errors.Wrap(err, "while converting archived instance to DTO")
+	}
+	if withParams {
+		if err := h.converter.ApplyProvisioningParameters(&dto, archived.Instance); err != nil {
+			return pkg.RuntimeDTO{}, errors.Wrap(err, "while applying provisioning parameters")
+		}
+	}
+
+	versions, err := h.kymaVersions(archived.RuntimeID)
+	if err != nil {
+		return pkg.RuntimeDTO{}, err
+	}
+
+	ukOprs := make([]internal.UpgradeKymaOperation, 0)
+	for _, op := range archived.Operations {
+		switch dbmodel.OperationType(op.Type) {
+		case dbmodel.OperationTypeProvision:
+			h.converter.ApplyProvisioningOperation(&dto, &internal.ProvisioningOperation{Operation: op.Operation})
+		case dbmodel.OperationTypeDeprovision:
+			h.converter.ApplyDeprovisioningOperation(&dto, &internal.DeprovisioningOperation{Operation: op.Operation})
+		case dbmodel.OperationTypeUpgradeKyma:
+			ukOprs = append(ukOprs, internal.UpgradeKymaOperation{Operation: op.Operation})
+		case dbmodel.OperationTypeUpdate:
+			h.converter.ApplyUpdatingOperation(&dto, &internal.UpdatingOperation{Operation: op.Operation})
+		}
+	}
+	lastUkOprs, totalCount := h.takeLastNonDryRunOperations(ukOprs)
+	h.converter.ApplyUpgradingKymaOperations(&dto, lastUkOprs, totalCount)
+	h.converter.ApplyKymaVersions(&dto, versions, ukOprs)
+
+	return dto, nil
+}
+
+// runtimeDTO converts the instance to the runtime DTO, the latest operation of the instance
+// determines which of the runtime operations need to be fetched from the storage. The operations
+// are not fetched at all if they are not requested.
+func (h *Handler) runtimeDTO(instance internal.InstanceWithState, withParams, withOperations bool) (pkg.RuntimeDTO, error) {
+	dto, err := h.converter.NewDTO(instance.Instance)
+	if err != nil {
+		return pkg.RuntimeDTO{}, errors.Wrap(err, "while converting instance to DTO")
+	}
+	if withParams {
+		if err := h.converter.ApplyProvisioningParameters(&dto, instance.Instance); err != nil {
+			return pkg.RuntimeDTO{}, errors.Wrap(err, "while applying provisioning parameters")
+		}
+	}
+	if err := h.applyExpiration(&dto, instance.Instance); err != nil {
+		return pkg.RuntimeDTO{}, err
+	}
+	if !withOperations {
+		return dto, nil
+	}
+
+	if instance.LastOperation == nil {
+		h.converter.ApplyUpgradingKymaOperations(&dto, nil, 0)
+		return dto, nil
+	}
+
+	versions, err := h.kymaVersions(instance.RuntimeID)
+	if err != nil {
+		return pkg.RuntimeDTO{}, err
+	}
+
+	// upgrade and deprovisioning operations are never created before the provisioning operation
+	if instance.LastOperationType == string(dbmodel.OperationTypeProvision) {
+		h.converter.ApplyProvisioningOperation(&dto, &internal.ProvisioningOperation{Operation: *instance.LastOperation})
+		h.converter.ApplyUpgradingKymaOperations(&dto, nil, 0)
+		h.converter.ApplyKymaVersions(&dto, versions, nil)
+		return dto, nil
+	}
+
+	pOpr, err := h.operationsDb.GetProvisioningOperationByInstanceID(instance.InstanceID)
+	if err != nil && !dberr.IsNotFound(err) {
+		return pkg.RuntimeDTO{}, errors.Wrap(err, "while fetching provisioning operation for instance")
+	}
+	h.converter.ApplyProvisioningOperation(&dto, pOpr)
 
+	// other operations, such as the suspension, can be created after the deprovisioning operation,
+	// so it is fetched unless it is the latest operation of the instance
+	if instance.LastOperationType == string(dbmodel.OperationTypeDeprovision) {
+		h.converter.ApplyDeprovisioningOperation(&dto, &internal.DeprovisioningOperation{Operation: *instance.LastOperation})
+	} else {
 		dOpr, err := h.operationsDb.GetDeprovisioningOperationByInstanceID(instance.InstanceID)
 		if err != nil && !dberr.IsNotFound(err) {
-			httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "while fetching deprovisioning operation for instance"))
-			return
+			return pkg.RuntimeDTO{}, errors.Wrap(err, "while fetching deprovisioning operation for instance")
 		}
 		h.converter.ApplyDeprovisioningOperation(&dto, dOpr)
+	}
 
-		ukOprs, err := h.operationsDb.ListUpgradeKymaOperationsByInstanceID(instance.InstanceID)
+	if instance.LastOperationType == string(dbmodel.OperationTypeUpdate) {
+		h.converter.ApplyUpdatingOperation(&dto, &internal.UpdatingOperation{Operation: *instance.LastOperation})
+	} else {
+		uOpr, err := h.operationsDb.GetUpdatingOperationByInstanceID(instance.InstanceID)
 		if err != nil && !dberr.IsNotFound(err) {
-			httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "while fetching upgrade kyma operation for instance"))
-			return
+			return pkg.RuntimeDTO{}, errors.Wrap(err, "while fetching updating operation for instance")
 		}
-		ukOprs, totalCount := h.takeLastNonDryRunOperations(ukOprs)
-		h.converter.ApplyUpgradingKymaOperations(&dto, ukOprs, totalCount)
+		h.converter.ApplyUpdatingOperation(&dto, uOpr)
+	}
 
-		toReturn = append(toReturn, dto)
+	sOpr, err := h.operationsDb.GetSuspensionOperationByInstanceID(instance.InstanceID)
+	if err != nil && !dberr.IsNotFound(err) {
+		return pkg.RuntimeDTO{}, errors.Wrap(err, "while fetching suspension operation for instance")
 	}
+	h.converter.ApplySuspensionOperation(&dto, sOpr)
 
-	runtimePage := pkg.RuntimesPage{
-		Data:       toReturn,
-		Count:      count,
-		TotalCount: totalCount,
+	ukOprs, err := h.operationsDb.ListUpgradeKymaOperationsByInstanceID(instance.InstanceID)
+	if err != nil && !dberr.IsNotFound(err) {
+		return pkg.RuntimeDTO{}, errors.Wrap(err, "while fetching upgrade kyma operation for instance")
 	}
-	httputil.WriteResponse(w, http.StatusOK, runtimePage)
+	lastUkOprs, totalCount := h.takeLastNonDryRunOperations(ukOprs)
+	h.converter.ApplyUpgradingKymaOperations(&dto, lastUkOprs, totalCount)
+	h.converter.ApplyKymaVersions(&dto, versions, ukOprs)
+
+	return dto, nil
+}
+
+// applyExpiration sets the expiration of the trial runtime, the expiration is stored by the trial expiration job
+// so it is not known until the job checks the runtime for the first time
+func (h *Handler) applyExpiration(dto *pkg.RuntimeDTO, instance internal.Instance) error {
+	if instance.ServicePlanID != broker.TrialPlanID {
+		return nil
+	}
+
+	expiration, found, err := h.expirationsDb.GetExpiration(instance.InstanceID)
+	if err != nil {
+		return errors.Wrap(err, "while fetching trial expiration")
+	}
+	if found {
+		dto.ExpiresAt = &expiration.ExpiresAt
+	}
+	return nil
+}
+
+// kymaVersions returns the Kyma versions of the runtime operations by the operation ID,
+// the versions are taken from the runtime states stored when the operations are started
+func (h *Handler) kymaVersions(runtimeID string) (map[string]string, error) {
+	versions := make(map[string]string)
+	if runtimeID == "" {
+		return versions, nil
+	}
+
+	states, err := h.runtimeStatesDb.ListByRuntimeID(runtimeID)
+	if err != nil && !dberr.IsNotFound(err) {
+		return nil, errors.Wrap(err, "while fetching runtime states")
+	}
+	for _, state := range states {
+		versions[state.OperationID] = state.KymaConfig.Version
+	}
+
+	return versions, nil
 }
 
 func (h *Handler) takeLastNonDryRunOperations(oprs []internal.UpgradeKymaOperation) ([]internal.UpgradeKymaOperation, int) {
@@ -116,7 +394,7 @@ func (h *Handler) takeLastNonDryRunOperations(oprs []internal.UpgradeKymaOperati
 	return toReturn, totalCount
 }
 
-func (h *Handler) getFilters(req *http.Request) dbmodel.InstanceFilter {
+func (h *Handler) getFilters(req *http.Request) (dbmodel.InstanceFilter, error) {
 	var filter dbmodel.InstanceFilter
 	query := req.URL.Query()
 	// For optional filter, zero value (nil) is fine if not supplied
@@ -126,6 +404,38 @@ func (h *Handler) getFilters(req *http.Request) dbmodel.InstanceFilter {
 	filter.RuntimeIDs = query[pkg.RuntimeIDParam]
 	filter.Regions = query[pkg.RegionParam]
 	filter.Domains = query[pkg.ShootParam]
+	filter.Plans = query[pkg.PlanParam]
+	filter.Search = strings.TrimSpace(query.Get(pkg.SearchParam))
+
+	for param, value := range map[string]*time.Time{
+		pkg.CreatedAfterParam:  &filter.CreatedAfter,
+		pkg.CreatedBeforeParam: &filter.CreatedBefore,
+		pkg.UpdatedAfterParam:  &filter.UpdatedAfter,
+		pkg.UpdatedBeforeParam: &filter.UpdatedBefore,
+	} {
+		raw := query.Get(param)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return filter, errors.Wrapf(err, "while parsing %s query parameter", param)
+		}
+		*value = t
+	}
+
+	return filter, nil
+}
 
-	return filter
+// getWithParameters returns true if the provisioning parameters are requested with the params query parameter
+func (h *Handler) getWithParameters(req *http.Request) (bool, error) {
+	raw := req.URL.Query().Get(pkg.ParamsParam)
+	if raw == "" {
+		return false, nil
+	}
+	withParams, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, errors.Wrapf(err, "while parsing %s query parameter", pkg.ParamsParam)
+	}
+	return withParams, nil
 }
@@ -13,7 +13,10 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/broker"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/driver/memory"
+	"github.com/kyma-project/control-plane/components/provisioner/pkg/gqlschema"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -45,7 +48,7 @@ func TestRuntimeHandler(t *testing.T) {
 		err = instances.Insert(testInstance2)
 		require.NoError(t, err)
 
-		runtimeHandler := runtime.NewHandler(instances, operations, 2, "")
+		runtimeHandler := runtime.NewHandler(instances, operations, memory.NewInstancesArchived(), memory.NewRuntimeStates(operations), memory.NewTrialExpirations(), 2, "")
 
 		req, err := http.NewRequest("GET", "/runtimes?page_size=1", nil)
 		require.NoError(t, err)
@@ -90,12 +93,86 @@ func TestRuntimeHandler(t *testing.T) {
 
 	})
 
+	t.Run("test cursor pagination should work", func(t *testing.T) {
+		// given
+		operations := memory.NewOperation()
+		instances := memory.NewInstance(operations)
+		now := time.Now()
+		for i, id := range []string{"Test1", "Test2", "Test3"} {
+			err := instances.Insert(internal.Instance{
+				InstanceID:             id,
+				CreatedAt:              now.Add(time.Duration(i) * time.Minute),
+				ProvisioningParameters: "{}",
+			})
+			require.NoError(t, err)
+		}
+
+		runtimeHandler := runtime.NewHandler(instances, operations, memory.NewInstancesArchived(), memory.NewRuntimeStates(operations), memory.NewTrialExpirations(), 2, "")
+		router := mux.NewRouter()
+		runtimeHandler.AttachRoutes(router)
+
+		getPage := func(urlPath string) pkg.RuntimesPage {
+			req, err := http.NewRequest(http.MethodGet, urlPath, nil)
+			require.NoError(t, err)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			var out pkg.RuntimesPage
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &out))
+			return out
+		}
+
+		// when
+		first := getPage("/runtimes?page_size=2")
+
+		// then
+		require.Len(t, first.Data, 2)
+		assert.Equal(t, "Test1", first.Data[0].InstanceID)
+		assert.Equal(t, "Test2", first.Data[1].InstanceID)
+		require.NotEmpty(t, first.NextCursor)
+
+		// given
+		// the instance created before the cursor does not shift the next page
+		err := instances.Insert(internal.Instance{
+			InstanceID:             "Test0",
+			CreatedAt:              now.Add(-time.Minute),
+			ProvisioningParameters: "{}",
+		})
+		require.NoError(t, err)
+
+		// when
+		second := getPage("/runtimes?page_size=2&cursor=" + first.NextCursor)
+
+		// then
+		require.Len(t, second.Data, 1)
+		assert.Equal(t, "Test3", second.Data[0].InstanceID)
+		assert.Equal(t, 4, second.TotalCount)
+		assert.Empty(t, second.NextCursor, "there is no next page")
+
+		for _, urlPath := range []string{
+			"/runtimes?page=2&cursor=" + first.NextCursor,
+			"/runtimes?cursor=malformed",
+		} {
+			// given
+			req, err := http.NewRequest(http.MethodGet, urlPath, nil)
+			require.NoError(t, err)
+			rr := httptest.NewRecorder()
+
+			// when
+			router.ServeHTTP(rr, req)
+
+			// then
+			assert.Equal(t, http.StatusBadRequest, rr.Code, urlPath)
+		}
+	})
+
 	t.Run("test validation should work", func(t *testing.T) {
 		// given
 		operations := memory.NewOperation()
 		instances := memory.NewInstance(operations)
 
-		runtimeHandler := runtime.NewHandler(instances, operations, 2, "region")
+		runtimeHandler := runtime.NewHandler(instances, operations, memory.NewInstancesArchived(), memory.NewRuntimeStates(operations), memory.NewTrialExpirations(), 2, "region")
 
 		req, err := http.NewRequest("GET", "/runtimes?page_size=a", nil)
 		require.NoError(t, err)
@@ -141,9 +218,9 @@ func TestRuntimeHandler(t *testing.T) {
 		err = instances.Insert(testInstance2)
 		require.NoError(t, err)
 
-		runtimeHandler := runtime.NewHandler(instances, operations, 2, "")
+		runtimeHandler := runtime.NewHandler(instances, operations, memory.NewInstancesArchived(), memory.NewRuntimeStates(operations), memory.NewTrialExpirations(), 2, "")
 
-		req, err := http.NewRequest("GET", fmt.Sprintf("/runtimes?account=%s&subaccount=%s&instance_id=%s&runtime_id=%s&region=%s&shoot=%s", testID1, testID1, testID1, testID1, testID1, testID1), nil)
+		req, err := http.NewRequest("GET", fmt.Sprintf("/runtimes?account=%s&subaccount=%s&instance_id=%s&runtime_id=%s&region=%s&shoot=%s&plan=%s", testID1, testID1, testID1, testID1, testID1, testID1, testID1), nil)
 		require.NoError(t, err)
 
 		rr := httptest.NewRecorder()
@@ -164,6 +241,511 @@ func TestRuntimeHandler(t *testing.T) {
 		assert.Equal(t, 1, out.TotalCount)
 		assert.Equal(t, 1, out.Count)
 		assert.Equal(t, testID1, out.Data[0].InstanceID)
+		assert.Nil(t, out.Data[0].Access)
+	})
+
+	t.Run("test search should work", func(t *testing.T) {
+		// given
+		operations := memory.NewOperation()
+		instances := memory.NewInstance(operations)
+		now := time.Now()
+		for i, id := range []string{"Alpha1", "Beta2", "Gamma3"} {
+			err := instances.Insert(fixInstance(id, now.Add(time.Duration(i)*time.Minute)))
+			require.NoError(t, err)
+		}
+
+		runtimeHandler := runtime.NewHandler(instances, operations, memory.NewInstancesArchived(), memory.NewRuntimeStates(operations), memory.NewTrialExpirations(), 2, "")
+
+		req, err := http.NewRequest("GET", "/runtimes?search=+beta+", nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+		runtimeHandler.AttachRoutes(router)
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var out pkg.RuntimesPage
+
+		err = json.Unmarshal(rr.Body.Bytes(), &out)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, out.TotalCount)
+		require.Len(t, out.Data, 1)
+		assert.Equal(t, "Beta2", out.Data[0].InstanceID)
+	})
+
+	t.Run("test filtering by creation time should work", func(t *testing.T) {
+		// given
+		operations := memory.NewOperation()
+		instances := memory.NewInstance(operations)
+		testTime := time.Date(2020, 11, 20, 10, 0, 0, 0, time.UTC)
+
+		for i, created := range []time.Time{testTime.Add(-time.Hour), testTime, testTime.Add(time.Hour)} {
+			err := instances.Insert(fixInstance(fmt.Sprintf("Test%d", i), created))
+			require.NoError(t, err)
+		}
+
+		runtimeHandler := runtime.NewHandler(instances, operations, memory.NewInstancesArchived(), memory.NewRuntimeStates(operations), memory.NewTrialExpirations(), 10, "")
+
+		req, err := http.NewRequest("GET", fmt.Sprintf("/runtimes?created_after=%s&created_before=%s",
+			testTime.Format(time.RFC3339), testTime.Add(time.Hour).Format(time.RFC3339)), nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+		runtimeHandler.AttachRoutes(router)
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var out pkg.RuntimesPage
+
+		err = json.Unmarshal(rr.Body.Bytes(), &out)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, out.TotalCount)
+		assert.Equal(t, "Test1", out.Data[0].InstanceID)
+
+		// when
+		req, err = http.NewRequest("GET", "/runtimes?created_after=yesterday", nil)
+		require.NoError(t, err)
+		rr = httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		// then
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("test runtime details should contain access data", func(t *testing.T) {
+		// given
+		operations := memory.NewOperation()
+		instances := memory.NewInstance(operations)
+		testID1 := "Test1"
+		testInstance1 := fixInstance(testID1, time.Now())
+		testInstance1.APIServerURL = "https://api.test1.kyma.local"
+		testInstance1.CABundle = "ca-bundle"
+		testInstance1.ProvisioningParameters = `{"ers_context": {"user_id": "john.smith@email.com"}}`
+
+		err := instances.Insert(testInstance1)
+		require.NoError(t, err)
+
+		runtimeHandler := runtime.NewHandler(instances, operations, memory.NewInstancesArchived(), memory.NewRuntimeStates(operations), memory.NewTrialExpirations(), 2, "")
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+		runtimeHandler.AttachRoutes(router)
+
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/runtimes/%s", testID1), nil)
+		require.NoError(t, err)
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var out pkg.RuntimeDTO
+		err = json.Unmarshal(rr.Body.Bytes(), &out)
+		require.NoError(t, err)
+
+		assert.Equal(t, testID1, out.RuntimeID)
+		require.NotNil(t, out.Access)
+		assert.Equal(t, "https://api.test1.kyma.local", out.Access.APIServerURL)
+		assert.Equal(t, "ca-bundle", out.Access.CABundle)
+		assert.Equal(t, "john.smith@email.com", out.UserID)
+
+		// given
+		req, err = http.NewRequest(http.MethodGet, "/runtimes/not-existing", nil)
+		require.NoError(t, err)
+		rr = httptest.NewRecorder()
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("test runtime status should contain operations", func(t *testing.T) {
+		// given
+		operations := memory.NewOperation()
+		instances := memory.NewInstance(operations)
+		testTime := time.Now()
+		provisionedID := "Provisioned"
+		deprovisionedID := "Deprovisioned"
+		updatedID := "Updated"
+		retriedID := "Retried"
+
+		err := instances.Insert(fixInstance(provisionedID, testTime))
+		require.NoError(t, err)
+		err = instances.Insert(fixInstance(deprovisionedID, testTime.Add(time.Minute)))
+		require.NoError(t, err)
+		err = instances.Insert(fixInstance(updatedID, testTime.Add(2*time.Minute)))
+		require.NoError(t, err)
+		err = instances.Insert(fixInstance(retriedID, testTime.Add(3*time.Minute)))
+		require.NoError(t, err)
+
+		err = operations.InsertProvisioningOperation(internal.ProvisioningOperation{
+			Operation: fixOperation("p-1", provisionedID, testTime),
+		})
+		require.NoError(t, err)
+		err = operations.InsertProvisioningOperation(internal.ProvisioningOperation{
+			Operation: fixOperation("p-2", deprovisionedID, testTime),
+		})
+		require.NoError(t, err)
+		err = operations.InsertUpgradeKymaOperation(internal.UpgradeKymaOperation{
+			RuntimeOperation: internal.RuntimeOperation{Operation: fixOperation("u-2", deprovisionedID, testTime.Add(time.Hour))},
+		})
+		require.NoError(t, err)
+		err = operations.InsertUpdatingOperation(internal.UpdatingOperation{
+			Operation: fixOperation("up-2", deprovisionedID, testTime.Add(90*time.Minute)),
+		})
+		require.NoError(t, err)
+		err = operations.InsertDeprovisioningOperation(internal.DeprovisioningOperation{
+			Operation: fixOperation("d-2", deprovisionedID, testTime.Add(2*time.Hour)),
+		})
+		require.NoError(t, err)
+		err = operations.InsertProvisioningOperation(internal.ProvisioningOperation{
+			Operation: fixOperation("p-3", updatedID, testTime),
+		})
+		require.NoError(t, err)
+		err = operations.InsertUpdatingOperation(internal.UpdatingOperation{
+			Operation: fixOperation("up-3", updatedID, testTime.Add(time.Hour)),
+		})
+		require.NoError(t, err)
+		err = operations.InsertSuspensionOperation(internal.SuspensionOperation{
+			Operation: fixOperation("s-3", updatedID, testTime.Add(2*time.Hour)),
+			Action:    internal.SuspensionActionSuspend,
+		})
+		require.NoError(t, err)
+		// the suspension is created after the deprovisioning, so the deprovisioning is not the latest operation
+		err = operations.InsertProvisioningOperation(internal.ProvisioningOperation{
+			Operation: fixOperation("p-4", retriedID, testTime),
+		})
+		require.NoError(t, err)
+		err = operations.InsertDeprovisioningOperation(internal.DeprovisioningOperation{
+			Operation: fixOperation("d-4", retriedID, testTime.Add(time.Hour)),
+		})
+		require.NoError(t, err)
+		err = operations.InsertSuspensionOperation(internal.SuspensionOperation{
+			Operation: fixOperation("s-4", retriedID, testTime.Add(2*time.Hour)),
+			Action:    internal.SuspensionActionSuspend,
+		})
+		require.NoError(t, err)
+
+		runtimeStates := memory.NewRuntimeStates(operations)
+		err = runtimeStates.Insert(fixRuntimeState("s-1", provisionedID, "p-1", "1.16.0"))
+		require.NoError(t, err)
+		err = runtimeStates.Insert(fixRuntimeState("s-2", deprovisionedID, "p-2", "1.16.0"))
+		require.NoError(t, err)
+		err = runtimeStates.Insert(fixRuntimeState("s-3", deprovisionedID, "u-2", "1.17.0"))
+		require.NoError(t, err)
+
+		runtimeHandler := runtime.NewHandler(instances, operations, memory.NewInstancesArchived(), runtimeStates, memory.NewTrialExpirations(), 4, "")
+
+		req, err := http.NewRequest("GET", "/runtimes", nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+		runtimeHandler.AttachRoutes(router)
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var out pkg.RuntimesPage
+		err = json.Unmarshal(rr.Body.Bytes(), &out)
+		require.NoError(t, err)
+		require.Len(t, out.Data, 4)
+
+		provisioned := out.Data[0]
+		assert.Equal(t, provisionedID, provisioned.InstanceID)
+		assert.Equal(t, "p-1", provisioned.Status.Provisioning.OperationID)
+		assert.Nil(t, provisioned.Status.Deprovisioning)
+		assert.Nil(t, provisioned.Status.Update)
+		assert.Nil(t, provisioned.Status.Suspension)
+		assert.False(t, provisioned.Status.Suspended)
+		assert.Equal(t, 0, provisioned.Status.UpgradingKyma.TotalCount)
+		assert.Equal(t, "1.16.0", provisioned.KymaVersion)
+		assert.Equal(t, "1.16.0", provisioned.Status.Provisioning.KymaVersion)
+
+		deprovisioned := out.Data[1]
+		assert.Equal(t, deprovisionedID, deprovisioned.InstanceID)
+		assert.Equal(t, "p-2", deprovisioned.Status.Provisioning.OperationID)
+		require.NotNil(t, deprovisioned.Status.Deprovisioning)
+		assert.Equal(t, "d-2", deprovisioned.Status.Deprovisioning.OperationID)
+		assert.Equal(t, 1, deprovisioned.Status.UpgradingKyma.TotalCount)
+		assert.Equal(t, "u-2", deprovisioned.Status.UpgradingKyma.Data[0].OperationID)
+		assert.Equal(t, "1.17.0", deprovisioned.Status.UpgradingKyma.Data[0].KymaVersion)
+		assert.Equal(t, "1.17.0", deprovisioned.KymaVersion)
+		require.NotNil(t, deprovisioned.Status.Update)
+		assert.Equal(t, "up-2", deprovisioned.Status.Update.OperationID)
+
+		updated := out.Data[2]
+		assert.Equal(t, updatedID, updated.InstanceID)
+		assert.Equal(t, "p-3", updated.Status.Provisioning.OperationID)
+		require.NotNil(t, updated.Status.Update)
+		assert.Equal(t, "up-3", updated.Status.Update.OperationID)
+		require.NotNil(t, updated.Status.Suspension)
+		assert.Equal(t, "s-3", updated.Status.Suspension.OperationID)
+		assert.Equal(t, internal.SuspensionActionSuspend, updated.Status.Suspension.Action)
+		assert.True(t, updated.Status.Suspended)
+
+		retried := out.Data[3]
+		assert.Equal(t, retriedID, retried.InstanceID)
+		require.NotNil(t, retried.Status.Deprovisioning)
+		assert.Equal(t, "d-4", retried.Status.Deprovisioning.OperationID)
+		require.NotNil(t, retried.Status.Suspension)
+		assert.Equal(t, "s-4", retried.Status.Suspension.OperationID)
+	})
+
+	t.Run("should return archived runtimes for deprovisioned state", func(t *testing.T) {
+		// given
+		operations := memory.NewOperation()
+		instances := memory.NewInstance(operations)
+		archived := memory.NewInstancesArchived()
+		testTime := time.Now()
+
+		err := instances.Insert(fixInstance("existing", testTime))
+		require.NoError(t, err)
+		err = archived.Insert(internal.ArchivedInstance{
+			Instance: fixInstance("removed", testTime),
+			Operations: []internal.ArchivedOperation{
+				{Operation: fixOperation("p-1", "removed", testTime), Type: "provision"},
+				{Operation: fixOperation("u-1", "removed", testTime.Add(time.Hour)), Type: "upgradeKyma"},
+				{Operation: fixOperation("d-1", "removed", testTime.Add(2*time.Hour)), Type: "deprovision"},
+			},
+			ArchivedAt: testTime.Add(3 * time.Hour),
+		})
+		require.NoError(t, err)
+
+		runtimeHandler := runtime.NewHandler(instances, operations, archived, memory.NewRuntimeStates(operations), memory.NewTrialExpirations(), 2, "")
+
+		req, err := http.NewRequest("GET", "/runtimes?state=deprovisioned", nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+		runtimeHandler.AttachRoutes(router)
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var out pkg.RuntimesPage
+		err = json.Unmarshal(rr.Body.Bytes(), &out)
+		require.NoError(t, err)
+		require.Len(t, out.Data, 1)
+		assert.Equal(t, 1, out.TotalCount)
+
+		removed := out.Data[0]
+		assert.Equal(t, "removed", removed.InstanceID)
+		assert.Equal(t, "p-1", removed.Status.Provisioning.OperationID)
+		require.NotNil(t, removed.Status.Deprovisioning)
+		assert.Equal(t, "d-1", removed.Status.Deprovisioning.OperationID)
+		assert.Equal(t, 1, removed.Status.UpgradingKyma.TotalCount)
+	})
+
+	t.Run("should reject unsupported state", func(t *testing.T) {
+		// given
+		operations := memory.NewOperation()
+		runtimeHandler := runtime.NewHandler(memory.NewInstance(operations), operations, memory.NewInstancesArchived(), memory.NewRuntimeStates(operations), memory.NewTrialExpirations(), 2, "")
+
+		req, err := http.NewRequest("GET", "/runtimes?state=unknown", nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+		runtimeHandler.AttachRoutes(router)
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("should return only the selected fields", func(t *testing.T) {
+		// given
+		operations := memory.NewOperation()
+		instances := memory.NewInstance(operations)
+		testTime := time.Now()
+		err := instances.Insert(fixInstance("instance-1", testTime))
+		require.NoError(t, err)
+		err = operations.InsertProvisioningOperation(internal.ProvisioningOperation{
+			Operation: fixOperation("p-1", "instance-1", testTime),
+		})
+		require.NoError(t, err)
+
+		runtimeHandler := runtime.NewHandler(instances, operations, memory.NewInstancesArchived(), memory.NewRuntimeStates(operations), memory.NewTrialExpirations(), 2, "")
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+		runtimeHandler.AttachRoutes(router)
+
+		req, err := http.NewRequest(http.MethodGet, "/runtimes?fields=runtimeID,instanceID&fields=status", nil)
+		require.NoError(t, err)
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+		var raw struct {
+			Data []map[string]json.RawMessage `json:"data"`
+		}
+		err = json.Unmarshal(rr.Body.Bytes(), &raw)
+		require.NoError(t, err)
+		require.Len(t, raw.Data, 1)
+		assert.Len(t, raw.Data[0], 3)
+
+		var out pkg.RuntimesPage
+		err = json.Unmarshal(rr.Body.Bytes(), &out)
+		require.NoError(t, err)
+		assert.Equal(t, 1, out.TotalCount)
+		assert.Equal(t, "instance-1", out.Data[0].InstanceID)
+		assert.Equal(t, "p-1", out.Data[0].Status.Provisioning.OperationID)
+		assert.Empty(t, out.Data[0].GlobalAccountID)
+
+		// given
+		req, err = http.NewRequest(http.MethodGet, "/runtimes?fields=runtimeID,unknown", nil)
+		require.NoError(t, err)
+		rr = httptest.NewRecorder()
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("should return sanitized provisioning parameters on demand", func(t *testing.T) {
+		// given
+		operations := memory.NewOperation()
+		instances := memory.NewInstance(operations)
+		testID := "Test1"
+		testInstance := fixInstance(testID, time.Now())
+		testInstance.ProvisioningParameters = `{"ers_context":{"subaccount_id":"sa"},"parameters":{"machineType":"Standard_D8_v3","region":"westeurope","autoScalerMin":2,"autoScalerMax":5,"subscription":{"credentials":{"clientSecret":"secret"}},"oidc":{"clientID":"kyma","issuerURL":"https://issuer.example.com","signingAlgs":["RS256"]}}}`
+		err := instances.Insert(testInstance)
+		require.NoError(t, err)
+
+		runtimeHandler := runtime.NewHandler(instances, operations, memory.NewInstancesArchived(), memory.NewRuntimeStates(operations), memory.NewTrialExpirations(), 2, "")
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+		runtimeHandler.AttachRoutes(router)
+
+		req, err := http.NewRequest(http.MethodGet, "/runtimes", nil)
+		require.NoError(t, err)
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+		var out pkg.RuntimesPage
+		err = json.Unmarshal(rr.Body.Bytes(), &out)
+		require.NoError(t, err)
+		require.Len(t, out.Data, 1)
+		assert.Nil(t, out.Data[0].Parameters)
+
+		// given
+		req, err = http.NewRequest(http.MethodGet, "/runtimes?params=true", nil)
+		require.NoError(t, err)
+		rr = httptest.NewRecorder()
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.NotContains(t, rr.Body.String(), "secret")
+		err = json.Unmarshal(rr.Body.Bytes(), &out)
+		require.NoError(t, err)
+		require.Len(t, out.Data, 1)
+		params := out.Data[0].Parameters
+		require.NotNil(t, params)
+		assert.Equal(t, "Standard_D8_v3", *params.MachineType)
+		assert.Equal(t, "westeurope", *params.Region)
+		assert.Equal(t, 2, *params.AutoScalerMin)
+		assert.Equal(t, 5, *params.AutoScalerMax)
+		assert.Equal(t, &pkg.OIDCConfig{ClientID: "kyma", IssuerURL: "https://issuer.example.com", SigningAlgs: []string{"RS256"}}, params.OIDC)
+
+		// given
+		req, err = http.NewRequest(http.MethodGet, fmt.Sprintf("/runtimes/%s?params=true", testID), nil)
+		require.NoError(t, err)
+		rr = httptest.NewRecorder()
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+		var details pkg.RuntimeDTO
+		err = json.Unmarshal(rr.Body.Bytes(), &details)
+		require.NoError(t, err)
+		require.NotNil(t, details.Parameters)
+		assert.Equal(t, "Standard_D8_v3", *details.Parameters.MachineType)
+
+		// given
+		req, err = http.NewRequest(http.MethodGet, "/runtimes?params=maybe", nil)
+		require.NoError(t, err)
+		rr = httptest.NewRecorder()
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("should return the expiration of the trial runtime", func(t *testing.T) {
+		// given
+		operations := memory.NewOperation()
+		instances := memory.NewInstance(operations)
+		expirations := memory.NewTrialExpirations()
+		testTime := time.Now()
+		trial := fixInstance("trial", testTime)
+		trial.ServicePlanID = broker.TrialPlanID
+		require.NoError(t, instances.Insert(trial))
+		require.NoError(t, instances.Insert(fixInstance("azure", testTime.Add(time.Second))))
+		expiresAt := testTime.Add(14 * 24 * time.Hour).UTC()
+		require.NoError(t, expirations.UpsertExpiration(internal.TrialExpiration{InstanceID: "trial", ExpiresAt: expiresAt}))
+
+		runtimeHandler := runtime.NewHandler(instances, operations, memory.NewInstancesArchived(), memory.NewRuntimeStates(operations), expirations, 2, "")
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+		runtimeHandler.AttachRoutes(router)
+
+		req, err := http.NewRequest(http.MethodGet, "/runtimes", nil)
+		require.NoError(t, err)
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+		var out pkg.RuntimesPage
+		err = json.Unmarshal(rr.Body.Bytes(), &out)
+		require.NoError(t, err)
+		require.Len(t, out.Data, 2)
+		require.NotNil(t, out.Data[0].ExpiresAt)
+		assert.True(t, expiresAt.Equal(*out.Data[0].ExpiresAt))
+		assert.Nil(t, out.Data[1].ExpiresAt)
 	})
 }
 
@@ -183,3 +765,24 @@ func fixInstance(id string, t time.Time) internal.Instance {
 		ProvisioningParameters: "{}",
 	}
 }
+
+func fixOperation(id, instanceID string, createdAt time.Time) internal.Operation {
+	return internal.Operation{
+		ID:          id,
+		InstanceID:  instanceID,
+		CreatedAt:   createdAt,
+		State:       domain.Succeeded,
+		Description: id,
+	}
+}
+
+func fixRuntimeState(id, runtimeID, operationID, kymaVersion string) internal.RuntimeState {
+	return internal.RuntimeState{
+		ID:          id,
+		RuntimeID:   runtimeID,
+		OperationID: operationID,
+		KymaConfig: gqlschema.KymaConfigInput{
+			Version: kymaVersion,
+		},
+	}
+}
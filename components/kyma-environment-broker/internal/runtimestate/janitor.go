@@ -0,0 +1,46 @@
+package runtimestate
+
+import (
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+type Config struct {
+	// Disabled turns off the removal of the old runtime states
+	Disabled bool `envconfig:"default=false"`
+	// TTL defines how long the runtime states are kept
+	TTL time.Duration `envconfig:"default=2160h"`
+	// Interval defines how often the old runtime states are removed
+	Interval time.Duration `envconfig:"default=1h"`
+}
+
+// Janitor removes the runtime states older than the configured TTL, otherwise the runtime states table grows
+// with every provisioning and upgrade operation. The states which still describe the runtime, i.e. the newest one and
+// the one holding the current Kyma version, are never removed. The removal is run periodically by the scheduler.
+type Janitor struct {
+	runtimeStates storage.RuntimeStates
+	cfg           Config
+	log           logrus.FieldLogger
+}
+
+func NewJanitor(runtimeStates storage.RuntimeStates, cfg Config, log logrus.FieldLogger) *Janitor {
+	return &Janitor{
+		runtimeStates: runtimeStates,
+		cfg:           cfg,
+		log:           log,
+	}
+}
+
+// CleanUp removes the superseded runtime states created before the TTL and returns the number of removed entries
+func (j *Janitor) CleanUp() (int, error) {
+	deleted, err := j.runtimeStates.DeleteSupersededCreatedBefore(time.Now().Add(-j.cfg.TTL))
+	if err != nil {
+		return 0, err
+	}
+	if deleted > 0 {
+		j.log.Infof("Removed %d superseded runtime states older than %s", deleted, j.cfg.TTL)
+	}
+	return deleted, nil
+}
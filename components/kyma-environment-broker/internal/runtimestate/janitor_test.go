@@ -0,0 +1,57 @@
+package runtimestate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJanitor_CleanUp(t *testing.T) {
+	// given
+	db := storage.NewMemoryStorage()
+	runtimeStates := db.RuntimeStates()
+	err := db.Operations().InsertUpgradeKymaOperation(internal.UpgradeKymaOperation{
+		Operation: internal.Operation{ID: "op-upgrade", State: domain.Succeeded},
+	})
+	require.NoError(t, err)
+	err = db.Operations().InsertUpgradeKymaOperation(internal.UpgradeKymaOperation{
+		Operation: internal.Operation{ID: "op-failed", State: domain.Failed},
+	})
+	require.NoError(t, err)
+
+	for _, state := range []internal.RuntimeState{
+		{ID: "old", RuntimeID: "runtime", OperationID: "op-old", CreatedAt: time.Now().Add(-96 * time.Hour)},
+		{ID: "upgrade", RuntimeID: "runtime", OperationID: "op-upgrade", CreatedAt: time.Now().Add(-72 * time.Hour)},
+		{ID: "failed", RuntimeID: "runtime", OperationID: "op-failed", CreatedAt: time.Now().Add(-48 * time.Hour)},
+		{ID: "new", RuntimeID: "runtime", OperationID: "op-new", CreatedAt: time.Now()},
+		{ID: "other", RuntimeID: "other-runtime", OperationID: "op-other", CreatedAt: time.Now().Add(-48 * time.Hour)},
+	} {
+		err = runtimeStates.Insert(state)
+		require.NoError(t, err)
+	}
+
+	janitor := NewJanitor(runtimeStates, Config{TTL: 24 * time.Hour, Interval: time.Hour}, logrus.New())
+
+	// when
+	deleted, err := janitor.CleanUp()
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+
+	for _, removed := range []string{"op-old", "op-failed"} {
+		_, err = runtimeStates.GetByOperationID(removed)
+		assert.Error(t, err)
+	}
+	// the newest states of the runtimes and the state with the current Kyma version are kept
+	for _, kept := range []string{"op-upgrade", "op-new", "op-other"} {
+		_, err = runtimeStates.GetByOperationID(kept)
+		assert.NoError(t, err)
+	}
+}
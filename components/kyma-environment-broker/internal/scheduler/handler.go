@@ -0,0 +1,146 @@
+package scheduler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/httputil"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultHistoryLimit = 20
+	maxHistoryLimit     = 100
+)
+
+type JobDTO struct {
+	Name     string     `json:"name"`
+	Interval string     `json:"interval"`
+	Enabled  bool       `json:"enabled"`
+	LastRun  *JobRunDTO `json:"lastRun,omitempty"`
+}
+
+type JobRunDTO struct {
+	ID         string     `json:"id"`
+	JobName    string     `json:"jobName"`
+	Owner      string     `json:"owner"`
+	Trigger    string     `json:"trigger"`
+	State      string     `json:"state"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  time.Time  `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+type Handler struct {
+	scheduler *Scheduler
+	log       logrus.FieldLogger
+}
+
+func NewHandler(scheduler *Scheduler, log logrus.FieldLogger) *Handler {
+	return &Handler{
+		scheduler: scheduler,
+		log:       log,
+	}
+}
+
+func (h *Handler) AttachRoutes(router *mux.Router) {
+	router.HandleFunc("/jobs", h.listJobs).Methods(http.MethodGet)
+	router.HandleFunc("/jobs/{name}/runs", h.listRuns).Methods(http.MethodGet)
+	router.HandleFunc("/jobs/{name}/trigger", h.trigger).Methods(http.MethodPost)
+}
+
+// listJobs returns all periodic jobs together with their latest runs
+func (h *Handler) listJobs(w http.ResponseWriter, _ *http.Request) {
+	statuses, err := h.scheduler.Jobs()
+	if err != nil {
+		h.writeError(w, err, "while listing jobs")
+		return
+	}
+
+	jobs := make([]JobDTO, 0, len(statuses))
+	for _, status := range statuses {
+		job := JobDTO{
+			Name:     status.Name,
+			Interval: status.Interval.String(),
+			Enabled:  status.Enabled,
+		}
+		if status.LastRun != nil {
+			run := toJobRunDTO(*status.LastRun)
+			job.LastRun = &run
+		}
+		jobs = append(jobs, job)
+	}
+
+	httputil.WriteResponse(w, http.StatusOK, jobs)
+}
+
+// listRuns returns the latest runs of the job, the number of runs is limited with the limit query parameter
+func (h *Handler) listRuns(w http.ResponseWriter, r *http.Request) {
+	limit := defaultHistoryLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxHistoryLimit {
+			httputil.WriteErrorResponse(w, http.StatusBadRequest, errors.Errorf("limit must be a number between 1 and %d", maxHistoryLimit))
+			return
+		}
+		limit = parsed
+	}
+
+	runs, err := h.scheduler.History(mux.Vars(r)["name"], limit)
+	if err != nil {
+		h.writeError(w, err, "while listing job runs")
+		return
+	}
+
+	dtos := make([]JobRunDTO, 0, len(runs))
+	for _, run := range runs {
+		dtos = append(dtos, toJobRunDTO(run))
+	}
+
+	httputil.WriteResponse(w, http.StatusOK, dtos)
+}
+
+// trigger starts the job immediately, the job runs in the background and its result is recorded in the runs
+func (h *Handler) trigger(w http.ResponseWriter, r *http.Request) {
+	run, err := h.scheduler.Trigger(mux.Vars(r)["name"])
+	if err != nil {
+		h.writeError(w, err, "while triggering job")
+		return
+	}
+
+	httputil.WriteResponse(w, http.StatusAccepted, toJobRunDTO(run))
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, err error, context string) {
+	switch err.(type) {
+	case NotFoundError:
+		httputil.WriteErrorResponse(w, http.StatusNotFound, err)
+	case RunningError:
+		httputil.WriteErrorResponse(w, http.StatusConflict, err)
+	default:
+		h.log.Errorf("%s: %v", context, err)
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, context))
+	}
+}
+
+func toJobRunDTO(run internal.JobRun) JobRunDTO {
+	dto := JobRunDTO{
+		ID:        run.ID,
+		JobName:   run.JobName,
+		Owner:     run.Owner,
+		Trigger:   run.Trigger,
+		State:     run.State,
+		Error:     run.Error,
+		StartedAt: run.StartedAt,
+	}
+	if !run.FinishedAt.IsZero() {
+		finishedAt := run.FinishedAt
+		dto.FinishedAt = &finishedAt
+	}
+	return dto
+}
@@ -0,0 +1,361 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// Triggers of the job runs
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+)
+
+type Config struct {
+	// Disabled turns off the scheduled runs of all periodic jobs, the jobs can still be triggered on demand
+	Disabled bool `envconfig:"default=false"`
+	// CheckInterval defines how often the scheduler checks which jobs are due
+	CheckInterval time.Duration `envconfig:"default=1m"`
+	// LockTTL defines how long the lock of the running job is valid, the lock is extended while the job runs,
+	// so the TTL only limits how long the job is blocked after the replica running it crashed
+	LockTTL time.Duration `envconfig:"default=5m"`
+	// HistoryRetention defines how long the records of the job runs are kept
+	HistoryRetention time.Duration `envconfig:"default=720h"`
+}
+
+// Job is a periodic task of the broker
+type Job struct {
+	Name string
+	// Interval defines how often the job is run by any of the broker replicas
+	Interval time.Duration
+	// Disabled turns off the scheduled runs of the job, the job can still be triggered on demand
+	Disabled bool
+	Run      func(ctx context.Context) error
+}
+
+// JobStatus describes the registered job together with its latest run
+type JobStatus struct {
+	Name     string
+	Interval time.Duration
+	// Enabled is false if the scheduled runs of the job are turned off
+	Enabled bool
+	LastRun *internal.JobRun
+}
+
+// NotFoundError is returned if the job is not registered
+type NotFoundError struct {
+	message string
+}
+
+func (e NotFoundError) Error() string {
+	return e.message
+}
+
+// RunningError is returned if the job is already running in any of the broker replicas
+type RunningError struct {
+	message string
+}
+
+func (e RunningError) Error() string {
+	return e.message
+}
+
+// Scheduler runs the periodic jobs of the broker. Every broker replica runs its own scheduler, the lock of the job
+// kept in the storage ensures that the job is run by a single replica at a time, and the history of the runs kept
+// in the storage ensures that the job is run once per its interval regardless of the number of replicas.
+type Scheduler struct {
+	jobStorage storage.Jobs
+	owner      string
+	cfg        Config
+	log        logrus.FieldLogger
+	now        func() time.Time
+
+	mu      sync.Mutex
+	ctx     context.Context
+	jobs    map[string]Job
+	names   []string
+	running map[string]bool
+}
+
+// NewScheduler constructs the scheduler of the broker replica identified by the owner
+func NewScheduler(jobStorage storage.Jobs, owner string, cfg Config, log logrus.FieldLogger) *Scheduler {
+	return &Scheduler{
+		jobStorage: jobStorage,
+		owner:      owner,
+		cfg:        cfg,
+		log:        log,
+		now:        time.Now,
+		ctx:        context.Background(),
+		jobs:       make(map[string]Job),
+		running:    make(map[string]bool),
+	}
+}
+
+// Register adds the job to the scheduler, the names of the jobs must be unique
+func (s *Scheduler) Register(job Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.jobs[job.Name]; exists {
+		return errors.Errorf("job %s is already registered", job.Name)
+	}
+	if job.Interval <= 0 && !job.Disabled {
+		return errors.Errorf("interval of job %s must be positive", job.Name)
+	}
+	s.jobs[job.Name] = job
+	s.names = append(s.names, job.Name)
+
+	return nil
+}
+
+// Run checks the jobs every configured interval and runs the due ones until the context is done
+func (s *Scheduler) Run(ctx context.Context) {
+	s.mu.Lock()
+	s.ctx = ctx
+	s.mu.Unlock()
+
+	if s.cfg.Disabled {
+		s.log.Info("Scheduled runs of the periodic jobs are disabled")
+		return
+	}
+	go wait.Until(func() {
+		for _, job := range s.enabledJobs() {
+			if _, _, err := s.start(job, TriggerSchedule); err != nil {
+				s.log.Errorf("while starting job %s: %s", job.Name, err)
+			}
+		}
+	}, s.cfg.CheckInterval, ctx.Done())
+}
+
+// Trigger starts the job immediately in the background and returns its run, the job must not be running
+func (s *Scheduler) Trigger(name string) (internal.JobRun, error) {
+	job, found := s.job(name)
+	if !found {
+		return internal.JobRun{}, NotFoundError{message: fmt.Sprintf("job %s is not registered", name)}
+	}
+
+	run, started, err := s.start(job, TriggerManual)
+	if err != nil {
+		return internal.JobRun{}, err
+	}
+	if !started {
+		return internal.JobRun{}, RunningError{message: fmt.Sprintf("job %s is already running", name)}
+	}
+	return run, nil
+}
+
+// Jobs returns the status of all registered jobs in the order of the registration
+func (s *Scheduler) Jobs() ([]JobStatus, error) {
+	s.mu.Lock()
+	jobs := make([]Job, 0, len(s.names))
+	for _, name := range s.names {
+		jobs = append(jobs, s.jobs[name])
+	}
+	s.mu.Unlock()
+
+	statuses := make([]JobStatus, 0, len(jobs))
+	for _, job := range jobs {
+		runs, err := s.jobStorage.ListRuns(job.Name, 1)
+		if err != nil {
+			return nil, errors.Wrapf(err, "while getting the latest run of job %s", job.Name)
+		}
+		status := JobStatus{
+			Name:     job.Name,
+			Interval: job.Interval,
+			Enabled:  !s.cfg.Disabled && !job.Disabled,
+		}
+		if len(runs) > 0 {
+			status.LastRun = &runs[0]
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// History returns at most limit latest runs of the job
+func (s *Scheduler) History(name string, limit int) ([]internal.JobRun, error) {
+	if _, found := s.job(name); !found {
+		return nil, NotFoundError{message: fmt.Sprintf("job %s is not registered", name)}
+	}
+	return s.jobStorage.ListRuns(name, limit)
+}
+
+// start runs the job in the background if it is not running in any replica, and in case of the scheduled run
+// only if it was not started by any replica within its interval. False is returned if the job was not started.
+func (s *Scheduler) start(job Job, trigger string) (internal.JobRun, bool, error) {
+	if !s.markRunning(job.Name) {
+		return internal.JobRun{}, false, nil
+	}
+
+	now := s.now()
+	acquired, err := s.jobStorage.AcquireLock(job.Name, s.owner, now, now.Add(s.cfg.LockTTL))
+	if err != nil {
+		s.unmarkRunning(job.Name)
+		return internal.JobRun{}, false, errors.Wrap(err, "while acquiring job lock")
+	}
+	if !acquired {
+		s.unmarkRunning(job.Name)
+		return internal.JobRun{}, false, nil
+	}
+
+	// the history is checked while the lock is held, so another replica cannot start the job in the meantime
+	due, err := s.due(job, trigger, now)
+	if err != nil || !due {
+		s.release(job.Name)
+		return internal.JobRun{}, false, err
+	}
+
+	run := internal.JobRun{
+		ID:        uuid.New().String(),
+		JobName:   job.Name,
+		Owner:     s.owner,
+		Trigger:   trigger,
+		State:     internal.InProgress,
+		StartedAt: now,
+	}
+	if err := s.jobStorage.InsertRun(run); err != nil {
+		s.release(job.Name)
+		return internal.JobRun{}, false, errors.Wrap(err, "while inserting job run")
+	}
+
+	go s.execute(job, run)
+
+	return run, true, nil
+}
+
+func (s *Scheduler) due(job Job, trigger string, now time.Time) (bool, error) {
+	if trigger == TriggerManual {
+		return true, nil
+	}
+	runs, err := s.jobStorage.ListRuns(job.Name, 1)
+	if err != nil {
+		return false, errors.Wrap(err, "while getting the latest job run")
+	}
+	return len(runs) == 0 || now.Sub(runs[0].StartedAt) >= job.Interval, nil
+}
+
+// execute runs the job, extends its lock until the job finishes, and records the result of the run
+func (s *Scheduler) execute(job Job, run internal.JobRun) {
+	defer s.release(job.Name)
+	log := s.log.WithField("job", job.Name).WithField("runID", run.ID)
+	log.Infof("Starting %s run of the job", run.Trigger)
+
+	s.mu.Lock()
+	ctx, cancel := context.WithCancel(s.ctx)
+	s.mu.Unlock()
+	defer cancel()
+
+	// the lock is released only after the extension stopped, otherwise the released lock could be acquired again
+	done := make(chan struct{})
+	var extension sync.WaitGroup
+	extension.Add(1)
+	go func() {
+		defer extension.Done()
+		s.extendLock(job.Name, log, done, cancel)
+	}()
+
+	err := s.runJob(ctx, job)
+	close(done)
+	extension.Wait()
+
+	run.State = internal.Succeeded
+	run.FinishedAt = s.now()
+	if err != nil {
+		run.State = internal.Failed
+		run.Error = err.Error()
+		log.Errorf("Job failed after %s: %s", run.FinishedAt.Sub(run.StartedAt), err)
+	} else {
+		log.Infof("Job succeeded after %s", run.FinishedAt.Sub(run.StartedAt))
+	}
+	if err := s.jobStorage.UpdateRun(run); err != nil {
+		log.Errorf("while updating job run: %s", err)
+	}
+
+	if _, err := s.jobStorage.DeleteRunsStartedBefore(run.FinishedAt.Add(-s.cfg.HistoryRetention)); err != nil {
+		log.Errorf("while removing old job runs: %s", err)
+	}
+}
+
+// extendLock extends the lock of the running job every third of the lock TTL until done is closed. The job
+// is cancelled when its lock was taken over by another replica, so the job is not run by two replicas at a time.
+func (s *Scheduler) extendLock(name string, log logrus.FieldLogger, done <-chan struct{}, cancel context.CancelFunc) {
+	wait.Until(func() {
+		now := s.now()
+		acquired, err := s.jobStorage.AcquireLock(name, s.owner, now, now.Add(s.cfg.LockTTL))
+		switch {
+		case err != nil:
+			log.Errorf("while extending job lock: %s", err)
+		case !acquired:
+			log.Warn("The job lock expired and was taken over by another replica, cancelling the job")
+			cancel()
+		}
+	}, s.cfg.LockTTL/3, done)
+}
+
+// runJob runs the job with the given context, the panic of the job fails the run
+func (s *Scheduler) runJob(ctx context.Context, job Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("panic: %v", r)
+		}
+	}()
+
+	return job.Run(ctx)
+}
+
+func (s *Scheduler) release(name string) {
+	if err := s.jobStorage.ReleaseLock(name, s.owner); err != nil {
+		s.log.Errorf("while releasing lock of job %s: %s", name, err)
+	}
+	s.unmarkRunning(name)
+}
+
+func (s *Scheduler) enabledJobs() []Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs := make([]Job, 0, len(s.names))
+	for _, name := range s.names {
+		if job := s.jobs[name]; !job.Disabled {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs
+}
+
+func (s *Scheduler) job(name string) (Job, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, found := s.jobs[name]
+	return job, found
+}
+
+// markRunning returns false if the job is already running in this replica
+func (s *Scheduler) markRunning(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running[name] {
+		return false
+	}
+	s.running[name] = true
+	return true
+}
+
+func (s *Scheduler) unmarkRunning(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.running, name)
+}
@@ -0,0 +1,208 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var fixConfig = Config{CheckInterval: time.Minute, LockTTL: time.Minute, HistoryRetention: 24 * time.Hour}
+
+func TestScheduler_RunsJobInSingleReplica(t *testing.T) {
+	// given
+	jobStorage := storage.NewMemoryStorage().Jobs()
+	release := make(chan struct{})
+	calls := make(chan string, 10)
+	newReplica := func(owner string) *Scheduler {
+		s := NewScheduler(jobStorage, owner, fixConfig, logrus.New())
+		require.NoError(t, s.Register(Job{
+			Name:     "job",
+			Interval: time.Hour,
+			Run: func(context.Context) error {
+				calls <- owner
+				<-release
+				return nil
+			},
+		}))
+		return s
+	}
+	replicaA := newReplica("replica-a")
+	replicaB := newReplica("replica-b")
+	job, _ := replicaA.job("job")
+
+	// when
+	run, started, err := replicaA.start(job, TriggerSchedule)
+
+	// then
+	require.NoError(t, err)
+	require.True(t, started)
+	assert.Equal(t, "replica-a", <-calls)
+	assert.Equal(t, internal.InProgress, run.State)
+
+	// when
+	_, started, err = replicaB.start(job, TriggerSchedule)
+
+	// then
+	require.NoError(t, err)
+	assert.False(t, started, "the job running in another replica must not be started")
+
+	// when
+	_, err = replicaB.Trigger("job")
+
+	// then
+	assert.IsType(t, RunningError{}, err)
+
+	// when
+	close(release)
+
+	// then
+	waitForRun(t, jobStorage, "job", internal.Succeeded)
+	_, started, err = replicaB.start(job, TriggerSchedule)
+	require.NoError(t, err)
+	assert.False(t, started, "the job must not be run again within its interval")
+
+	// when
+	triggered, err := replicaB.Trigger("job")
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, "replica-b", <-calls)
+	assert.Equal(t, TriggerManual, triggered.Trigger)
+	assert.Equal(t, "replica-b", triggered.Owner)
+	waitForRun(t, jobStorage, "job", internal.Succeeded)
+}
+
+func TestScheduler_CancelsJobWhenLockIsTakenOver(t *testing.T) {
+	// given
+	jobStorage := storage.NewMemoryStorage().Jobs()
+	cfg := fixConfig
+	cfg.LockTTL = 30 * time.Millisecond
+	s := NewScheduler(jobStorage, "replica-a", cfg, logrus.New())
+	started := make(chan struct{})
+	require.NoError(t, s.Register(Job{
+		Name:     "job",
+		Interval: time.Hour,
+		Run: func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}))
+
+	// when
+	_, err := s.Trigger("job")
+	require.NoError(t, err)
+	<-started
+	// the lock of the replica which stopped responding for longer than the lock TTL is taken over by another replica
+	now := time.Now()
+	acquired, err := jobStorage.AcquireLock("job", "replica-b", now.Add(time.Hour), now.Add(2*time.Hour))
+	require.NoError(t, err)
+	require.True(t, acquired)
+
+	// then
+	var run internal.JobRun
+	require.Eventually(t, func() bool {
+		runs, err := jobStorage.ListRuns("job", 1)
+		require.NoError(t, err)
+		run = runs[0]
+		return run.State == internal.Failed
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, context.Canceled.Error(), run.Error)
+}
+
+func TestScheduler_RunsDueJob(t *testing.T) {
+	// given
+	jobStorage := storage.NewMemoryStorage().Jobs()
+	now := time.Date(2020, 12, 23, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, jobStorage.InsertRun(internal.JobRun{ID: "previous", JobName: "job", State: internal.Succeeded, StartedAt: now.Add(-time.Hour)}))
+
+	s := NewScheduler(jobStorage, "replica", fixConfig, logrus.New())
+	s.now = func() time.Time { return now }
+	require.NoError(t, s.Register(Job{Name: "job", Interval: time.Hour, Run: func(context.Context) error { return nil }}))
+	job, _ := s.job("job")
+
+	// when
+	run, started, err := s.start(job, TriggerSchedule)
+
+	// then
+	require.NoError(t, err)
+	assert.True(t, started, "the job is due after its interval")
+	assert.Equal(t, TriggerSchedule, run.Trigger)
+	assert.Equal(t, now, run.StartedAt)
+}
+
+func TestScheduler_RecordsFailedRuns(t *testing.T) {
+	for name, run := range map[string]func(context.Context) error{
+		"error": func(context.Context) error {
+			return errors.New("job failed")
+		},
+		"panic": func(context.Context) error {
+			panic("job failed")
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// given
+			jobStorage := storage.NewMemoryStorage().Jobs()
+			s := NewScheduler(jobStorage, "replica", fixConfig, logrus.New())
+			require.NoError(t, s.Register(Job{Name: "job", Interval: time.Hour, Run: run}))
+
+			// when
+			_, err := s.Trigger("job")
+
+			// then
+			require.NoError(t, err)
+			failed := waitForRun(t, jobStorage, "job", internal.Failed)
+			assert.Contains(t, failed.Error, "job failed")
+			assert.False(t, failed.FinishedAt.IsZero())
+		})
+	}
+}
+
+func TestScheduler_Register(t *testing.T) {
+	// given
+	s := NewScheduler(storage.NewMemoryStorage().Jobs(), "replica", fixConfig, logrus.New())
+	require.NoError(t, s.Register(Job{Name: "job", Interval: time.Hour}))
+
+	// when
+	err := s.Register(Job{Name: "job", Interval: time.Hour})
+
+	// then
+	assert.Error(t, err, "the names of the jobs must be unique")
+
+	// when
+	err = s.Register(Job{Name: "other-job"})
+
+	// then
+	assert.Error(t, err, "the enabled job must have the interval")
+
+	// when
+	_, err = s.Trigger("unknown")
+
+	// then
+	assert.IsType(t, NotFoundError{}, err)
+}
+
+// waitForRun waits until the latest run of the job reaches the state and its lock is released
+func waitForRun(t *testing.T, jobStorage storage.Jobs, jobName, state string) internal.JobRun {
+	var latest internal.JobRun
+	assert.Eventually(t, func() bool {
+		runs, err := jobStorage.ListRuns(jobName, 1)
+		require.NoError(t, err)
+		if len(runs) == 0 || runs[0].State != state {
+			return false
+		}
+		latest = runs[0]
+		acquired, err := jobStorage.AcquireLock(jobName, "probe", time.Now(), time.Now())
+		require.NoError(t, err)
+		return acquired
+	}, time.Second, 10*time.Millisecond)
+	return latest
+}
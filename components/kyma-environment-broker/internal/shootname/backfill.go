@@ -0,0 +1,131 @@
+package shootname
+
+import (
+	"context"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/provisioner"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession/dbmodel"
+
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const pageSize = 100
+
+type Config struct {
+	// Disabled turns off the backfill of the shoot names of the existing instances
+	Disabled bool `envconfig:"default=false"`
+}
+
+// Backfill sets the shoot name of the instances provisioned before the shoot name was stored on the instance.
+// The shoot name is taken from the runtime status returned by the Provisioner. The backfill runs once on start,
+// the instances which cannot be backfilled are retried on the next start of the broker.
+type Backfill struct {
+	instances         storage.Instances
+	provisionerClient provisioner.Client
+	cfg               Config
+	log               logrus.FieldLogger
+}
+
+func NewBackfill(instances storage.Instances, provisionerClient provisioner.Client, cfg Config, log logrus.FieldLogger) *Backfill {
+	return &Backfill{
+		instances:         instances,
+		provisionerClient: provisionerClient,
+		cfg:               cfg,
+		log:               log,
+	}
+}
+
+// Run backfills the shoot names in the background until all instances are processed or the context is done
+func (b *Backfill) Run(ctx context.Context) {
+	if b.cfg.Disabled {
+		b.log.Info("Backfill of the shoot names is disabled")
+		return
+	}
+	go func() {
+		if _, err := b.Do(ctx); err != nil {
+			b.log.Errorf("while backfilling shoot names: %s", err)
+		}
+	}()
+}
+
+// Do sets the shoot name of every instance without it and returns the number of updated instances
+func (b *Backfill) Do(ctx context.Context) (int, error) {
+	updated := 0
+	filter := dbmodel.InstanceFilter{PageSize: pageSize, Page: 1, SkipLargeColumns: true}
+	for {
+		instances, _, _, err := b.instances.ListWithState(filter)
+		if err != nil {
+			return updated, errors.Wrap(err, "while listing instances")
+		}
+
+		for _, instance := range instances {
+			select {
+			case <-ctx.Done():
+				return updated, ctx.Err()
+			default:
+			}
+			if !b.requiresBackfill(instance) {
+				continue
+			}
+			done, err := b.backfill(instance.Instance)
+			if err != nil {
+				b.log.Warnf("cannot backfill shoot name of instance %s: %s", instance.InstanceID, err)
+				continue
+			}
+			if done {
+				updated++
+			}
+		}
+
+		if len(instances) < pageSize {
+			break
+		}
+		last := instances[len(instances)-1]
+		filter.After = &dbmodel.InstanceCursor{CreatedAt: last.CreatedAt, InstanceID: last.InstanceID}
+	}
+
+	if updated > 0 {
+		b.log.Infof("Backfilled shoot names of %d instances", updated)
+	}
+	return updated, nil
+}
+
+// requiresBackfill skips the instances without the runtime and the instances with the operation in progress,
+// the shoot name of the provisioned instance is set when the provisioning succeeds
+func (b *Backfill) requiresBackfill(instance internal.InstanceWithState) bool {
+	if instance.ShootName != "" || instance.RuntimeID == "" {
+		return false
+	}
+	return instance.LastOperation == nil || instance.LastOperation.State != domain.InProgress
+}
+
+// backfill sets the shoot name of the instance and tells whether the instance was updated
+func (b *Backfill) backfill(listed internal.Instance) (bool, error) {
+	status, err := b.provisionerClient.RuntimeStatus(listed.Tenant(), listed.RuntimeID)
+	if err != nil {
+		return false, errors.Wrapf(err, "while getting status of runtime %s", listed.RuntimeID)
+	}
+	if status.RuntimeConfiguration == nil || status.RuntimeConfiguration.ClusterConfig == nil ||
+		status.RuntimeConfiguration.ClusterConfig.Name == nil || *status.RuntimeConfiguration.ClusterConfig.Name == "" {
+		return false, errors.Errorf("status of runtime %s does not contain the shoot name", listed.RuntimeID)
+	}
+
+	// the listed instance does not contain the large columns, the instance is read again right before the update
+	// to not overwrite the changes made in the meantime
+	instance, err := b.instances.GetByID(listed.InstanceID)
+	if err != nil {
+		return false, errors.Wrap(err, "while getting instance")
+	}
+	if instance.ShootName != "" || instance.RuntimeID != listed.RuntimeID {
+		return false, nil
+	}
+	instance.ShootName = *status.RuntimeConfiguration.ClusterConfig.Name
+	if err := b.instances.Update(*instance); err != nil {
+		return false, errors.Wrap(err, "while updating instance")
+	}
+	return true, nil
+}
@@ -0,0 +1,58 @@
+package shootname
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/provisioner"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackfill_Do(t *testing.T) {
+	// given
+	now := time.Now()
+	instances := storage.NewMemoryStorage().Instances()
+	for i, instance := range []internal.Instance{
+		{InstanceID: "missing", RuntimeID: "runtime-missing", DashboardURL: "https://console.custom.domain"},
+		{InstanceID: "set", RuntimeID: "runtime-set", ShootName: "c-set"},
+		{InstanceID: "no-runtime"},
+		{InstanceID: "unknown", RuntimeID: "runtime-unknown"},
+	} {
+		instance.ProvisioningParameters = "{}"
+		instance.CreatedAt = now.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, instances.Insert(instance))
+	}
+
+	provisionerClient := provisioner.NewFakeClient()
+	provisionerClient.SetShootName("runtime-missing", "c-missing")
+	provisionerClient.SetShootName("runtime-set", "c-other")
+
+	backfill := NewBackfill(instances, provisionerClient, Config{}, logrus.New())
+
+	// when
+	updated, err := backfill.Do(context.Background())
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, 1, updated)
+
+	for id, shootName := range map[string]string{
+		"missing":    "c-missing",
+		"set":        "c-set",
+		"no-runtime": "",
+		"unknown":    "",
+	} {
+		instance, err := instances.GetByID(id)
+		require.NoError(t, err)
+		assert.Equal(t, shootName, instance.ShootName, "shoot name of instance %s", id)
+	}
+	instance, err := instances.GetByID("missing")
+	require.NoError(t, err)
+	assert.Equal(t, "https://console.custom.domain", instance.DashboardURL)
+}
@@ -0,0 +1,220 @@
+package staleoperation
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/event"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession/dbmodel"
+
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Actor is recorded as the actor of the state transitions made by the Detector
+const Actor = "stale-operations-detector"
+
+// DescriptionPrefix starts the description of every operation failed by the Detector,
+// so such operations can be told apart from the ones failed by their steps
+const DescriptionPrefix = "stale operation:"
+
+type Config struct {
+	// Disabled turns off the detection of the stale operations
+	Disabled bool `envconfig:"default=false"`
+	// Interval defines how often the operations in progress are checked
+	Interval time.Duration `envconfig:"default=15m"`
+	// ProvisioningMaxLifetime must be longer than the provisioning timeout
+	ProvisioningMaxLifetime time.Duration `envconfig:"default=24h"`
+	// DeprovisioningMaxLifetime must be longer than the time the deprovisioning waits for the Provisioner
+	DeprovisioningMaxLifetime time.Duration `envconfig:"default=12h"`
+	// UpgradeKymaMaxLifetime must be longer than the Kyma installation timeout, including the timeouts overridden by the orchestrations
+	UpgradeKymaMaxLifetime time.Duration `envconfig:"default=24h"`
+	// PlanMigrationMaxLifetime must be longer than the plan migration timeout
+	PlanMigrationMaxLifetime time.Duration `envconfig:"default=24h"`
+	// UpdatingMaxLifetime must be longer than the update timeout
+	UpdatingMaxLifetime time.Duration `envconfig:"default=4h"`
+	// SuspensionMaxLifetime must be longer than the suspension timeout
+	SuspensionMaxLifetime time.Duration `envconfig:"default=2h"`
+	// AccountMigrationMaxLifetime must be longer than the account migration timeout
+	AccountMigrationMaxLifetime time.Duration `envconfig:"default=2h"`
+}
+
+// operationTypes are the types of the operations checked by the Detector
+var operationTypes = []dbmodel.OperationType{
+	dbmodel.OperationTypeProvision,
+	dbmodel.OperationTypeDeprovision,
+	dbmodel.OperationTypeUpgradeKyma,
+	dbmodel.OperationTypeMigratePlan,
+	dbmodel.OperationTypeUpdate,
+	dbmodel.OperationTypeSuspension,
+	dbmodel.OperationTypeAccountMigration,
+}
+
+// Detector fails the operations in progress which were not updated for longer than their maximum lifetime, for example
+// the operations lost on the restart of the broker before they were queued. Such operations would otherwise stay
+// in progress forever and block the next operations of the instance. The detection relies only on the stored
+// operations, so it gives the same result in every broker replica, the Detector is run as the periodic job.
+type Detector struct {
+	operations storage.Operations
+	publisher  event.Publisher
+	cfg        Config
+	log        logrus.FieldLogger
+	now        func() time.Time
+
+	mu     sync.Mutex
+	failed map[dbmodel.OperationType]int
+}
+
+// NewDetector constructs a Detector
+func NewDetector(operations storage.Operations, pub event.Publisher, cfg Config, log logrus.FieldLogger) *Detector {
+	failed := make(map[dbmodel.OperationType]int, len(operationTypes))
+	for _, opType := range operationTypes {
+		failed[opType] = 0
+	}
+	return &Detector{
+		operations: operations,
+		publisher:  pub,
+		cfg:        cfg,
+		log:        log,
+		now:        time.Now,
+		failed:     failed,
+	}
+}
+
+// Run fails the stale operations, it is the periodic job run by the scheduler
+func (d *Detector) Run(ctx context.Context) error {
+	_, err := d.Detect(ctx)
+	return err
+}
+
+// Detect fails the stale operations and returns their IDs
+func (d *Detector) Detect(ctx context.Context) ([]string, error) {
+	now := d.now()
+	stale := make([]string, 0)
+	for _, opType := range operationTypes {
+		maxLifetime := d.maxLifetime(opType)
+		operations, err := d.operations.GetOperationsInProgressByType(opType)
+		if err != nil {
+			return stale, errors.Wrapf(err, "while listing %s operations in progress", opType)
+		}
+		for _, op := range operations {
+			// the processed operation is updated by its steps, the operation not updated for so long is not processed
+			if now.Sub(op.UpdatedAt) <= maxLifetime {
+				continue
+			}
+			description := fmt.Sprintf("%s not updated for more than %s", DescriptionPrefix, maxLifetime)
+			failed, err := d.fail(ctx, opType, op.ID, description, now.Add(-maxLifetime))
+			if err != nil {
+				return stale, err
+			}
+			if !failed {
+				continue
+			}
+			d.log.Warnf("Failed %s operation %s of instance %s updated at %s: %s", opType, op.ID, op.InstanceID, op.UpdatedAt, description)
+			stale = append(stale, op.ID)
+			d.countFailed(opType)
+		}
+	}
+	return stale, nil
+}
+
+// FailedCounts returns the number of the stale operations failed since the start of the broker per operation type
+func (d *Detector) FailedCounts() map[string]int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	counts := make(map[string]int, len(d.failed))
+	for opType, n := range d.failed {
+		counts[string(opType)] = n
+	}
+	return counts
+}
+
+func (d *Detector) countFailed(opType dbmodel.OperationType) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.failed[opType]++
+}
+
+func (d *Detector) maxLifetime(opType dbmodel.OperationType) time.Duration {
+	switch opType {
+	case dbmodel.OperationTypeDeprovision:
+		return d.cfg.DeprovisioningMaxLifetime
+	case dbmodel.OperationTypeUpgradeKyma:
+		return d.cfg.UpgradeKymaMaxLifetime
+	case dbmodel.OperationTypeMigratePlan:
+		return d.cfg.PlanMigrationMaxLifetime
+	case dbmodel.OperationTypeUpdate:
+		return d.cfg.UpdatingMaxLifetime
+	case dbmodel.OperationTypeSuspension:
+		return d.cfg.SuspensionMaxLifetime
+	case dbmodel.OperationTypeAccountMigration:
+		return d.cfg.AccountMigrationMaxLifetime
+	default:
+		return d.cfg.ProvisioningMaxLifetime
+	}
+}
+
+// fail marks the operation as failed unless it was finished or updated after the given time in the meantime,
+// the state transition of the failed operation is recorded with the Detector as the actor
+func (d *Detector) fail(ctx context.Context, opType dbmodel.OperationType, operationID, description string, updatedBefore time.Time) (bool, error) {
+	failed := false
+	var failedOperation internal.Operation
+	markFailed := func(op *internal.Operation) {
+		failed = false
+		if op.State != domain.InProgress || op.UpdatedAt.After(updatedBefore) {
+			return
+		}
+		op.State = domain.Failed
+		op.Description = description
+		failed = true
+		failedOperation = *op
+	}
+
+	var err error
+	switch opType {
+	case dbmodel.OperationTypeProvision:
+		_, err = storage.UpdateWithRetryProvisioningOperation(d.operations, operationID, func(op *internal.ProvisioningOperation) {
+			markFailed(&op.Operation)
+		})
+	case dbmodel.OperationTypeDeprovision:
+		_, err = storage.UpdateWithRetryDeprovisioningOperation(d.operations, operationID, func(op *internal.DeprovisioningOperation) {
+			markFailed(&op.Operation)
+		})
+	case dbmodel.OperationTypeUpgradeKyma:
+		_, err = storage.UpdateWithRetryUpgradeKymaOperation(d.operations, operationID, func(op *internal.UpgradeKymaOperation) {
+			markFailed(&op.Operation)
+		})
+	case dbmodel.OperationTypeMigratePlan:
+		_, err = storage.UpdateWithRetryPlanMigrationOperation(d.operations, operationID, func(op *internal.PlanMigrationOperation) {
+			markFailed(&op.Operation)
+		})
+	case dbmodel.OperationTypeUpdate:
+		_, err = storage.UpdateWithRetryUpdatingOperation(d.operations, operationID, func(op *internal.UpdatingOperation) {
+			markFailed(&op.Operation)
+		})
+	case dbmodel.OperationTypeSuspension:
+		_, err = storage.UpdateWithRetrySuspensionOperation(d.operations, operationID, func(op *internal.SuspensionOperation) {
+			markFailed(&op.Operation)
+		})
+	case dbmodel.OperationTypeAccountMigration:
+		_, err = storage.UpdateWithRetryAccountMigrationOperation(d.operations, operationID, func(op *internal.AccountMigrationOperation) {
+			markFailed(&op.Operation)
+		})
+	default:
+		return false, errors.Errorf("unsupported operation type %s", opType)
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "while failing %s operation %s", opType, operationID)
+	}
+	if failed {
+		d.publisher.Publish(ctx, process.OperationStateChanged{OldState: domain.InProgress, Operation: failedOperation, Actor: Actor})
+	}
+	return failed, nil
+}
@@ -0,0 +1,108 @@
+package staleoperation
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession/dbmodel"
+
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetector_Detect(t *testing.T) {
+	// given
+	now := time.Date(2020, 12, 1, 10, 0, 0, 0, time.UTC)
+	db := storage.NewMemoryStorage()
+	for _, op := range []internal.Operation{
+		{ID: "stale", InstanceID: "instance-1", State: domain.InProgress, CreatedAt: now.Add(-48 * time.Hour), UpdatedAt: now.Add(-25 * time.Hour)},
+		{ID: "processed", InstanceID: "instance-2", State: domain.InProgress, CreatedAt: now.Add(-48 * time.Hour), UpdatedAt: now.Add(-time.Hour)},
+		{ID: "recent", InstanceID: "instance-3", State: domain.InProgress, CreatedAt: now.Add(-time.Hour), UpdatedAt: now.Add(-time.Hour)},
+		{ID: "finished", InstanceID: "instance-4", State: domain.Succeeded, CreatedAt: now.Add(-48 * time.Hour), UpdatedAt: now.Add(-48 * time.Hour)},
+	} {
+		require.NoError(t, db.Operations().InsertProvisioningOperation(internal.ProvisioningOperation{Operation: op}))
+	}
+	require.NoError(t, db.Operations().InsertDeprovisioningOperation(internal.DeprovisioningOperation{
+		Operation: internal.Operation{ID: "stale-deprovisioning", InstanceID: "instance-5", State: domain.InProgress, CreatedAt: now.Add(-13 * time.Hour), UpdatedAt: now.Add(-13 * time.Hour)},
+	}))
+	require.NoError(t, db.Operations().InsertUpgradeKymaOperation(internal.UpgradeKymaOperation{
+		Operation: internal.Operation{ID: "stale-upgrade", InstanceID: "instance-6", State: domain.InProgress, CreatedAt: now.Add(-7 * time.Hour), UpdatedAt: now.Add(-7 * time.Hour)},
+	}))
+
+	pub := &recordingPublisher{}
+	detector := NewDetector(db.Operations(), pub, Config{
+		ProvisioningMaxLifetime:   24 * time.Hour,
+		DeprovisioningMaxLifetime: 12 * time.Hour,
+		UpgradeKymaMaxLifetime:    6 * time.Hour,
+	}, logrus.New())
+	detector.now = func() time.Time { return now }
+
+	// when
+	stale, err := detector.Detect(context.TODO())
+
+	// then
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"stale", "stale-deprovisioning", "stale-upgrade"}, stale)
+
+	op, err := db.Operations().GetProvisioningOperationByID("stale")
+	require.NoError(t, err)
+	assert.Equal(t, domain.Failed, op.State)
+	assert.True(t, strings.HasPrefix(op.Description, DescriptionPrefix))
+
+	for _, id := range []string{"processed", "recent"} {
+		op, err := db.Operations().GetProvisioningOperationByID(id)
+		require.NoError(t, err)
+		assert.Equal(t, domain.InProgress, op.State)
+	}
+
+	deprovisioning, err := db.Operations().GetDeprovisioningOperationByID("stale-deprovisioning")
+	require.NoError(t, err)
+	assert.Equal(t, domain.Failed, deprovisioning.State)
+
+	upgrade, err := db.Operations().GetUpgradeKymaOperationByID("stale-upgrade")
+	require.NoError(t, err)
+	assert.Equal(t, domain.Failed, upgrade.State)
+
+	assert.Equal(t, map[string]int{
+		string(dbmodel.OperationTypeProvision):        1,
+		string(dbmodel.OperationTypeDeprovision):      1,
+		string(dbmodel.OperationTypeUpgradeKyma):      1,
+		string(dbmodel.OperationTypeMigratePlan):      0,
+		string(dbmodel.OperationTypeUpdate):           0,
+		string(dbmodel.OperationTypeSuspension):       0,
+		string(dbmodel.OperationTypeAccountMigration): 0,
+	}, detector.FailedCounts())
+
+	require.Len(t, pub.events, 3)
+	for _, ev := range pub.events {
+		changed, ok := ev.(process.OperationStateChanged)
+		require.True(t, ok)
+		assert.Equal(t, Actor, changed.Actor)
+		assert.Equal(t, domain.InProgress, changed.OldState)
+		assert.Equal(t, domain.Failed, changed.Operation.State)
+	}
+
+	// when the detection runs again
+	stale, err = detector.Detect(context.TODO())
+
+	// then the failed operations are not counted again
+	require.NoError(t, err)
+	assert.Empty(t, stale)
+	assert.Equal(t, 1, detector.FailedCounts()[string(dbmodel.OperationTypeProvision)])
+	assert.Len(t, pub.events, 3)
+}
+
+type recordingPublisher struct {
+	events []interface{}
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, ev interface{}) {
+	p.events = append(p.events, ev)
+}
@@ -0,0 +1,38 @@
+// +build database_integration
+
+package storage_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/testsuite"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresStorageCompliance(t *testing.T) {
+	ctx := context.Background()
+
+	cleanupNetwork, err := storage.EnsureTestNetworkForDB(t, ctx)
+	require.NoError(t, err)
+	defer cleanupNetwork()
+
+	testsuite.Run(t, func(t *testing.T) (storage.BrokerStorage, func()) {
+		containerCleanupFunc, cfg, err := storage.InitTestDBContainer(t, ctx, "test_DB_1")
+		require.NoError(t, err)
+
+		err = storage.InitTestDBTables(t, cfg.ConnectionURL())
+		require.NoError(t, err)
+
+		brokerStorage, connection, err := storage.NewFromConfig(cfg, logrus.StandardLogger())
+		require.NoError(t, err)
+
+		return brokerStorage, func() {
+			storage.CloseDatabase(t, connection)
+			containerCleanupFunc()
+		}
+	})
+}
@@ -0,0 +1,17 @@
+package storage_test
+
+import (
+	"testing"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/testsuite"
+)
+
+// The compliance tests describe the behavior expected from every storage driver, they are run
+// against the memory driver here and against the postgres driver in the database integration tests.
+
+func TestMemoryStorageCompliance(t *testing.T) {
+	testsuite.Run(t, func(t *testing.T) (storage.BrokerStorage, func()) {
+		return storage.NewMemoryStorage(), func() {}
+	})
+}
@@ -67,3 +67,11 @@ func IsConflict(err error) bool {
 	}
 	return dbe.Code() == CodeConflict
 }
+
+func IsAlreadyExists(err error) bool {
+	dbe, ok := err.(Error)
+	if !ok {
+		return false
+	}
+	return dbe.Code() == CodeAlreadyExists
+}
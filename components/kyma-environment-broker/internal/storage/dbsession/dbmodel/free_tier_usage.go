@@ -0,0 +1,11 @@
+package dbmodel
+
+import "time"
+
+type FreeTierUsageDTO struct {
+	InstanceID      string
+	GlobalAccountID string
+	PlanID          string
+	StartedAt       time.Time
+	FinishedAt      time.Time
+}
@@ -0,0 +1,9 @@
+package dbmodel
+
+import "time"
+
+type InstallerOverridesDTO struct {
+	GlobalAccountID string
+	Data            string
+	UpdatedAt       time.Time
+}
@@ -1,9 +1,35 @@
 package dbmodel
 
+import (
+	"database/sql"
+	"time"
+
+	"github.com/gocraft/dbr"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+)
+
+const (
+	// TrialPlanID is the ID of the trial plan, only one instance of this plan is allowed per subaccount.
+	// It is the same as broker.TrialPlanID, which cannot be imported by the storage because of the import cycle.
+	TrialPlanID = "7d55d31d-35ae-4438-bf13-6ffdfa107d9f"
+	// TrialSubAccountIndexName is the name of the unique index which allows only one trial instance per subaccount
+	TrialSubAccountIndexName = "instances_trial_sub_account_id_uidx"
+)
+
+// InstanceCursor points to the instance after which the next page of the instances starts. The instances are ordered
+// by the creation time and the instance ID, so the pages neither skip nor repeat instances when other instances
+// are created or removed in the meantime.
+type InstanceCursor struct {
+	CreatedAt  time.Time
+	InstanceID string
+}
+
 // InstanceFilter holds the filters when queryíing Instances
 type InstanceFilter struct {
-	PageSize         int
-	Page             int
+	PageSize int
+	Page     int
+	// After selects the PageSize instances following the cursor, Page is ignored when set
+	After            *InstanceCursor
 	GlobalAccountIDs []string
 	SubAccountIDs    []string
 	InstanceIDs      []string
@@ -11,4 +37,30 @@ type InstanceFilter struct {
 	Regions          []string
 	Plans            []string
 	Domains          []string
+	// Search selects the instances whose ID, runtime ID, global account, subaccount, or dashboard URL
+	// contains the given text, the case is ignored
+	Search string
+	// the time range predicates are skipped when set to the zero value
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	UpdatedAfter  time.Time
+	UpdatedBefore time.Time
+	// SkipLargeColumns does not load the provisioning parameters and the CA bundle, the largest columns of the instance,
+	// the returned instances hold the empty JSON object as the provisioning parameters and the empty CA bundle
+	SkipLargeColumns bool
+}
+
+// InstanceWithStateDTO holds the row of the instances_with_state view,
+// the last operation columns are empty when no operation was created for the instance
+type InstanceWithStateDTO struct {
+	internal.Instance
+
+	LastOperationID              sql.NullString
+	LastOperationType            sql.NullString
+	LastOperationState           sql.NullString
+	LastOperationVersion         sql.NullInt64
+	LastOperationDescription     sql.NullString
+	LastOperationOrchestrationID sql.NullString
+	LastOperationCreatedAt       dbr.NullTime
+	LastOperationUpdatedAt       dbr.NullTime
 }
@@ -0,0 +1,15 @@
+package dbmodel
+
+import (
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+)
+
+// InstanceArchivedDTO holds the row of the instances_archived table, the operations of the instance are stored as JSON
+type InstanceArchivedDTO struct {
+	internal.Instance
+
+	Operations string
+	ArchivedAt time.Time
+}
@@ -0,0 +1,21 @@
+package dbmodel
+
+import "time"
+
+type JobLockDTO struct {
+	JobName     string
+	Owner       string
+	LockedUntil time.Time
+}
+
+type JobRunDTO struct {
+	ID         string
+	JobName    string
+	Owner      string
+	Trigger    string
+	State      string
+	Error      string
+	Details    string
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
@@ -0,0 +1,13 @@
+package dbmodel
+
+import "time"
+
+type KillSwitchEventDTO struct {
+	ID        string
+	Scope     string
+	Name      string
+	Disabled  bool
+	Message   string
+	ChangedBy string
+	CreatedAt time.Time
+}
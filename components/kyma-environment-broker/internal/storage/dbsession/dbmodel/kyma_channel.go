@@ -0,0 +1,9 @@
+package dbmodel
+
+import "time"
+
+type KymaChannelSubscriptionDTO struct {
+	GlobalAccountID string
+	Channel         string
+	UpdatedAt       time.Time
+}
@@ -17,6 +17,16 @@ const (
 	OperationTypeUndefined OperationType = ""
 	// OperationTypeUpgradeKyma means upgrade Kyma OperationType
 	OperationTypeUpgradeKyma OperationType = "upgradeKyma"
+	// OperationTypeUpgradeCluster means upgrade cluster (shoot) OperationType
+	OperationTypeUpgradeCluster OperationType = "upgradeCluster"
+	// OperationTypeMigratePlan means migration of the instance to another service plan OperationType
+	OperationTypeMigratePlan OperationType = "migratePlan"
+	// OperationTypeUpdate means update of the instance parameters OperationType
+	OperationTypeUpdate OperationType = "update"
+	// OperationTypeSuspension means suspension or resumption of the trial runtime OperationType
+	OperationTypeSuspension OperationType = "suspension"
+	// OperationTypeAccountMigration means migration of the instance to another global account and subaccount OperationType
+	OperationTypeAccountMigration OperationType = "accountMigration"
 )
 
 type OperationDTO struct {
@@ -27,8 +37,13 @@ type OperationDTO struct {
 
 	InstanceID        string
 	OrchestrationID   sql.NullString
+	CorrelationID     sql.NullString
 	TargetOperationID string
 
+	Stage          string
+	StageStartedAt sql.NullTime
+	RetriedAt      sql.NullTime
+
 	Data        string
 	State       string
 	Description string
@@ -36,12 +51,53 @@ type OperationDTO struct {
 	Type OperationType
 }
 
+// OperationFilter holds the filters when querying Operations, empty fields are not used for filtering
+type OperationFilter struct {
+	States  []string
+	PlanIDs []string
+	// OrchestrationID selects the operations triggered by the orchestration
+	OrchestrationID string
+	// CreatedAfter and CreatedBefore define the time range of the operation creation,
+	// CreatedAfter is inclusive and CreatedBefore is exclusive
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	// After selects the operations following the cursor, the page is ignored when set
+	After *OperationCursor
+}
+
+// OperationCursor points to the operation after which the next page of the operations starts. The operations
+// are ordered by the creation time and the operation ID, so the pages neither skip nor repeat operations
+// when other operations are created in the meantime.
+type OperationCursor struct {
+	CreatedAt   time.Time
+	OperationID string
+}
+
 type OperationStatEntry struct {
 	Type  string
 	State string
 	Total int
 }
 
+// OperationPlanRegionStatEntry holds number of operations of the given type and state for the service plan
+// and the provider region from the provisioning parameters of the operation
+type OperationPlanRegionStatEntry struct {
+	Type   string
+	State  string
+	PlanID string
+	Region string
+	Total  int
+}
+
+// OperationBucketStatEntry holds number of operations of the given type started (empty state) or finished
+// with the given state in the time bucket with the given index
+type OperationBucketStatEntry struct {
+	Type   string
+	State  string
+	Bucket int
+	Total  int
+}
+
 type InstanceByGlobalAccountIDStatEntry struct {
 	GlobalAccountID string
 	Total           int
@@ -0,0 +1,18 @@
+package dbmodel
+
+import (
+	"database/sql"
+	"time"
+)
+
+type OperationEventDTO struct {
+	ID          string
+	OperationID string
+	InstanceID  string
+	Actor       string
+	StepName    sql.NullString
+	OldState    sql.NullString
+	NewState    string
+	Description sql.NullString
+	CreatedAt   time.Time
+}
@@ -1,19 +1,38 @@
 package dbmodel
 
 import (
+	"database/sql"
 	"encoding/json"
 	"time"
 
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
 )
 
+// OrchestrationFilter holds the pagination of the orchestrations
+type OrchestrationFilter struct {
+	PageSize int
+	Page     int
+	// After selects the PageSize orchestrations following the cursor, Page is ignored when set
+	After *OrchestrationCursor
+}
+
+// OrchestrationCursor points to the orchestration after which the next page of the orchestrations starts,
+// the orchestrations are ordered by the creation time and the orchestration ID
+type OrchestrationCursor struct {
+	CreatedAt       time.Time
+	OrchestrationID string
+}
+
 type OrchestrationDTO struct {
 	OrchestrationID string
+	Type            string
 	State           string
 	Description     string
 	CreatedAt       time.Time
 	UpdatedAt       time.Time
 	Parameters      string
+	// RuntimeOperations holds the resolved runtimes of the orchestration
+	RuntimeOperations sql.NullString
 }
 
 func NewOrchestrationDTO(o internal.Orchestration) (OrchestrationDTO, error) {
@@ -21,14 +40,28 @@ func NewOrchestrationDTO(o internal.Orchestration) (OrchestrationDTO, error) {
 	if err != nil {
 		return OrchestrationDTO{}, err
 	}
+	runtimes, err := json.Marshal(o.Runtimes)
+	if err != nil {
+		return OrchestrationDTO{}, err
+	}
+
+	orchestrationType := o.Type
+	if orchestrationType == "" {
+		orchestrationType = internal.UpgradeKymaOrchestration
+	}
 
 	dto := OrchestrationDTO{
 		OrchestrationID: o.OrchestrationID,
+		Type:            string(orchestrationType),
 		State:           o.State,
 		CreatedAt:       o.CreatedAt,
 		UpdatedAt:       o.UpdatedAt,
 		Description:     o.Description,
 		Parameters:      string(params),
+		RuntimeOperations: sql.NullString{
+			String: string(runtimes),
+			Valid:  o.Runtimes != nil,
+		},
 	}
 	return dto, nil
 }
@@ -39,12 +72,21 @@ func (o *OrchestrationDTO) ToOrchestration() (internal.Orchestration, error) {
 	if err != nil {
 		return internal.Orchestration{}, err
 	}
+	var runtimes []internal.Runtime
+	if o.RuntimeOperations.Valid {
+		err = json.Unmarshal([]byte(o.RuntimeOperations.String), &runtimes)
+		if err != nil {
+			return internal.Orchestration{}, err
+		}
+	}
 	return internal.Orchestration{
 		OrchestrationID: o.OrchestrationID,
+		Type:            internal.OrchestrationType(o.Type),
 		State:           o.State,
 		Description:     o.Description,
 		CreatedAt:       o.CreatedAt,
 		UpdatedAt:       o.UpdatedAt,
 		Parameters:      params,
+		Runtimes:        runtimes,
 	}, nil
 }
@@ -0,0 +1,11 @@
+package dbmodel
+
+import "time"
+
+type TrialExpirationDTO struct {
+	InstanceID      string
+	ExpiresAt       time.Time
+	NotifiedAt      time.Time
+	DeprovisionedAt time.Time
+	UpdatedAt       time.Time
+}
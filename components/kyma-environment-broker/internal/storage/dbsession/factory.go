@@ -1,6 +1,8 @@
 package dbsession
 
 import (
+	"time"
+
 	dbr "github.com/gocraft/dbr"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
@@ -22,21 +24,38 @@ type ReadSession interface {
 	FindAllInstancesForSubAccounts(subAccountslist []string) ([]internal.Instance, dberr.Error)
 	GetInstanceByID(instanceID string) (internal.Instance, dberr.Error)
 	GetOperationByID(opID string) (dbmodel.OperationDTO, dberr.Error)
+	GetOperationByInstanceAndID(instanceID, opID string) (dbmodel.OperationDTO, dberr.Error)
 	GetOperationsInProgressByType(operationType dbmodel.OperationType) ([]dbmodel.OperationDTO, dberr.Error)
 	GetOperationByTypeAndInstanceID(inID string, opType dbmodel.OperationType) (dbmodel.OperationDTO, dberr.Error)
 	GetOperationsByTypeAndInstanceID(inID string, opType dbmodel.OperationType) ([]dbmodel.OperationDTO, dberr.Error)
+	ListInstanceIDsWithSucceededOperationsSince(opType dbmodel.OperationType, since time.Time) ([]string, dberr.Error)
 	GetOperationsForIDs(opIdList []string) ([]dbmodel.OperationDTO, dberr.Error)
+	GetOperationsByInstanceID(inID string) ([]dbmodel.OperationDTO, dberr.Error)
+	GetOperationsByCorrelationID(correlationID string) ([]dbmodel.OperationDTO, dberr.Error)
 	GetLMSTenant(name, region string) (dbmodel.LMSTenantDTO, dberr.Error)
-	GetOperationStats() ([]dbmodel.OperationStatEntry, error)
+	GetKymaChannelSubscription(globalAccountID string) (dbmodel.KymaChannelSubscriptionDTO, dberr.Error)
+	ListOperationEventsByOperationID(operationID string) ([]dbmodel.OperationEventDTO, dberr.Error)
+	GetInstallerOverrides(globalAccountID string) (dbmodel.InstallerOverridesDTO, dberr.Error)
+	GetArchivedInstanceByID(instanceID string) (dbmodel.InstanceArchivedDTO, dberr.Error)
+	ListArchivedInstances(filter dbmodel.InstanceFilter) ([]dbmodel.InstanceArchivedDTO, int, int, error)
+	ListFreeTierUsageByGlobalAccountID(globalAccountID string) ([]dbmodel.FreeTierUsageDTO, dberr.Error)
+	ListKillSwitchEvents() ([]dbmodel.KillSwitchEventDTO, dberr.Error)
+	ListLatestKillSwitchEvents() ([]dbmodel.KillSwitchEventDTO, dberr.Error)
+	ListJobRuns(jobName string, limit int) ([]dbmodel.JobRunDTO, dberr.Error)
+	GetTrialExpiration(instanceID string) (dbmodel.TrialExpirationDTO, dberr.Error)
+	GetOperationStats() ([]dbmodel.OperationPlanRegionStatEntry, error)
+	GetOperationBucketStats(from, to time.Time, interval time.Duration) ([]dbmodel.OperationBucketStatEntry, error)
 	GetInstanceStats() ([]dbmodel.InstanceByGlobalAccountIDStatEntry, error)
 	GetNumberOfInstancesForGlobalAccountID(globalAccountID string) (int, error)
 	GetRuntimeStateByOperationID(operationID string) (dbmodel.RuntimeStateDTO, dberr.Error)
 	ListRuntimeStateByRuntimeID(runtimeID string) ([]dbmodel.RuntimeStateDTO, dberr.Error)
 	GetOrchestrationByID(oID string) (dbmodel.OrchestrationDTO, dberr.Error)
 	ListOrchestrationsByState(state string) ([]dbmodel.OrchestrationDTO, error)
-	ListOrchestrations(pageSize, page int) ([]dbmodel.OrchestrationDTO, int, int, error)
+	ListOrchestrations(filter dbmodel.OrchestrationFilter) ([]dbmodel.OrchestrationDTO, int, int, error)
 	ListInstances(filter dbmodel.InstanceFilter) ([]internal.Instance, int, int, error)
-	ListOperationsByOrchestrationID(orchestrationID string, pageSize, page int) ([]dbmodel.OperationDTO, int, int, error)
+	ListInstancesWithState(filter dbmodel.InstanceFilter) ([]dbmodel.InstanceWithStateDTO, int, int, error)
+	ListOperationsByOrchestrationID(orchestrationID string, filter dbmodel.OperationFilter, pageSize, page int) ([]dbmodel.OperationDTO, int, int, error)
+	ListOperationsByType(operationType dbmodel.OperationType, filter dbmodel.OperationFilter, pageSize, page int) ([]dbmodel.OperationDTO, int, int, error)
 	GetOperationStatsForOrchestration(orchestrationID string) ([]dbmodel.OperationStatEntry, error)
 }
 
@@ -50,7 +69,23 @@ type WriteSession interface {
 	InsertOrchestration(o dbmodel.OrchestrationDTO) dberr.Error
 	UpdateOrchestration(o dbmodel.OrchestrationDTO) dberr.Error
 	InsertRuntimeState(state dbmodel.RuntimeStateDTO) dberr.Error
+	DeleteRuntimeStateByOperationID(operationID string) dberr.Error
+	DeleteSupersededRuntimeStatesCreatedBefore(before time.Time) (int, dberr.Error)
 	InsertLMSTenant(dto dbmodel.LMSTenantDTO) dberr.Error
+	UpsertKymaChannelSubscription(dto dbmodel.KymaChannelSubscriptionDTO) dberr.Error
+	InsertOperationEvent(dto dbmodel.OperationEventDTO) dberr.Error
+	UpsertInstallerOverrides(dto dbmodel.InstallerOverridesDTO) dberr.Error
+	InsertArchivedInstance(dto dbmodel.InstanceArchivedDTO) dberr.Error
+	InsertFreeTierUsage(dto dbmodel.FreeTierUsageDTO) dberr.Error
+	FinishFreeTierUsage(instanceID string, finishedAt time.Time) dberr.Error
+	InsertKillSwitchEvent(dto dbmodel.KillSwitchEventDTO) dberr.Error
+	AcquireJobLock(dto dbmodel.JobLockDTO, now time.Time) (bool, dberr.Error)
+	ReleaseJobLock(jobName, owner string) dberr.Error
+	InsertJobRun(dto dbmodel.JobRunDTO) dberr.Error
+	UpdateJobRun(dto dbmodel.JobRunDTO) dberr.Error
+	DeleteJobRunsStartedBefore(before time.Time) (int, dberr.Error)
+	UpsertTrialExpiration(dto dbmodel.TrialExpirationDTO) dberr.Error
+	DeleteInstallerOverrides(globalAccountID string) dberr.Error
 }
 
 type Transaction interface {
@@ -99,3 +134,25 @@ func (sf *factory) NewSessionWithinTransaction() (WriteSessionWithinTransaction,
 		transaction: dbTransaction,
 	}, nil
 }
+
+// NewTransactionFactory returns the factory which write sessions write within the given transaction, the read sessions
+// are not part of the transaction and do not see its writes
+func NewTransactionFactory(factory Factory, tx WriteSessionWithinTransaction) Factory {
+	return &transactionFactory{
+		Factory: factory,
+		tx:      tx,
+	}
+}
+
+type transactionFactory struct {
+	Factory
+	tx WriteSessionWithinTransaction
+}
+
+func (tf *transactionFactory) NewWriteSession() WriteSession {
+	return tf.tx
+}
+
+func (tf *transactionFactory) NewSessionWithinTransaction() (WriteSessionWithinTransaction, dberr.Error) {
+	return nil, dberr.Internal("nested transactions are not supported")
+}
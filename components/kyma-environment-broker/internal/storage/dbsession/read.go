@@ -3,6 +3,7 @@ package dbsession
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -24,7 +25,7 @@ type readSession struct {
 func (r readSession) getInstancesJoinedWithOperationStatement() *dbr.SelectStmt {
 	join := fmt.Sprintf("%s.instance_id = %s.instance_id", postsql.InstancesTableName, postsql.OperationTableName)
 	stmt := r.session.
-		Select("instances.instance_id, instances.runtime_id, instances.global_account_id, instances.service_id, instances.service_plan_id, instances.dashboard_url, instances.provisioning_parameters, instances.created_at, instances.updated_at, instances.deleted_at, instances.sub_account_id, instances.service_name, instances.service_plan_name, instances.provider_region, operations.state, operations.description, operations.type").
+		Select("instances.instance_id, instances.runtime_id, instances.global_account_id, instances.service_id, instances.service_plan_id, instances.dashboard_url, instances.provisioning_parameters, instances.created_at, instances.updated_at, instances.deleted_at, instances.sub_account_id, instances.service_name, instances.service_plan_name, instances.provider_region, instances.api_server_url, instances.ca_bundle, instances.shoot_name, instances.original_global_account_id, operations.state, operations.description, operations.type").
 		From(postsql.InstancesTableName).
 		LeftJoin(postsql.OperationTableName, join)
 	return stmt
@@ -113,6 +114,20 @@ func (r readSession) GetOperationByID(opID string) (dbmodel.OperationDTO, dberr.
 	return operation, nil
 }
 
+func (r readSession) GetOperationByInstanceAndID(instanceID, opID string) (dbmodel.OperationDTO, dberr.Error) {
+	condition := dbr.And(dbr.Eq("instance_id", instanceID), dbr.Eq("id", opID))
+	operation, err := r.getOperation(condition)
+	if err != nil {
+		switch {
+		case dberr.IsNotFound(err):
+			return dbmodel.OperationDTO{}, dberr.NotFound("for instance ID: %s and ID: %s %s", instanceID, opID, err)
+		default:
+			return dbmodel.OperationDTO{}, err
+		}
+	}
+	return operation, nil
+}
+
 func (r readSession) GetOrchestrationByID(oID string) (dbmodel.OrchestrationDTO, dberr.Error) {
 	condition := dbr.Eq("orchestration_id", oID)
 	operation, err := r.getOrchestration(condition)
@@ -143,20 +158,28 @@ func (r readSession) ListOrchestrationsByState(state string) ([]dbmodel.Orchestr
 	return orchestrations, nil
 }
 
-func (r readSession) ListOrchestrations(pageSize, page int) ([]dbmodel.OrchestrationDTO, int, int, error) {
+func (r readSession) ListOrchestrations(filter dbmodel.OrchestrationFilter) ([]dbmodel.OrchestrationDTO, int, int, error) {
 	var orchestrations []dbmodel.OrchestrationDTO
 
-	err := pagination.ValidatePageParameters(pageSize, page)
-	if err != nil {
-		return nil, -1, -1, errors.Wrap(err, "while converting page and pageSize to SQL statement")
-	}
-
-	_, err = r.session.Select("*").
+	stmt := r.session.Select("*").
 		From(postsql.OrchestrationTableName).
 		OrderBy(postsql.CreatedAtField).
-		Limit(uint64(pageSize)).
-		Offset(uint64(pagination.ConvertPageAndPageSizeToOffset(pageSize, page))).
-		Load(&orchestrations)
+		OrderBy("orchestration_id").
+		Limit(uint64(filter.PageSize))
+	if filter.After != nil {
+		stmt.Where("(created_at, orchestration_id) > (?, ?)", filter.After.CreatedAt, filter.After.OrchestrationID)
+	} else {
+		err := pagination.ValidatePageParameters(filter.PageSize, filter.Page)
+		if err != nil {
+			return nil, -1, -1, errors.Wrap(err, "while converting page and pageSize to SQL statement")
+		}
+		stmt.Offset(uint64(pagination.ConvertPageAndPageSizeToOffset(filter.PageSize, filter.Page)))
+	}
+
+	_, err := stmt.Load(&orchestrations)
+	if err != nil {
+		return nil, -1, -1, dberr.Internal("Failed to get orchestrations: %s", err)
+	}
 
 	totalCount, err := r.getOrchestrationCount()
 	if err != nil {
@@ -227,6 +250,58 @@ func (r readSession) GetOperationsByTypeAndInstanceID(inID string, opType dbmode
 	return operations, nil
 }
 
+func (r readSession) GetOperationsByInstanceID(inID string) ([]dbmodel.OperationDTO, dberr.Error) {
+	var operations []dbmodel.OperationDTO
+
+	_, err := r.session.
+		Select("*").
+		From(postsql.OperationTableName).
+		Where(dbr.Eq("instance_id", inID)).
+		OrderAsc(postsql.CreatedAtField).
+		Load(&operations)
+
+	if err != nil {
+		return nil, dberr.Internal("Failed to get operations: %s", err)
+	}
+	return operations, nil
+}
+
+func (r readSession) GetOperationsByCorrelationID(correlationID string) ([]dbmodel.OperationDTO, dberr.Error) {
+	var operations []dbmodel.OperationDTO
+
+	_, err := r.session.
+		Select("*").
+		From(postsql.OperationTableName).
+		Where(dbr.Eq("correlation_id", correlationID)).
+		OrderAsc(postsql.CreatedAtField).
+		Load(&operations)
+
+	if err != nil {
+		return nil, dberr.Internal("Failed to get operations: %s", err)
+	}
+	return operations, nil
+}
+
+// ListInstanceIDsWithSucceededOperationsSince returns the IDs of the instances with the operations of the given type
+// which succeeded since the given time, the dry run operations are not taken into account
+func (r readSession) ListInstanceIDsWithSucceededOperationsSince(opType dbmodel.OperationType, since time.Time) ([]string, dberr.Error) {
+	var instanceIDs []string
+
+	_, err := r.session.
+		Select("DISTINCT instance_id").
+		From(postsql.OperationTableName).
+		Where(dbr.Eq("type", string(opType))).
+		Where(dbr.Eq("state", string(domain.Succeeded))).
+		Where(dbr.Gte("updated_at", since)).
+		// the dry run flag is kept in the runtime operation serialized in the operation data
+		Where("data->'runtime_operation'->>'dryRun' IS DISTINCT FROM ?", "true").
+		Load(&instanceIDs)
+	if err != nil {
+		return nil, dberr.Internal("Failed to get instance IDs: %s", err)
+	}
+	return instanceIDs, nil
+}
+
 func (r readSession) GetOperationsForIDs(opIDlist []string) ([]dbmodel.OperationDTO, dberr.Error) {
 	var operations []dbmodel.OperationDTO
 
@@ -241,23 +316,52 @@ func (r readSession) GetOperationsForIDs(opIDlist []string) ([]dbmodel.Operation
 	return operations, nil
 }
 
-func (r readSession) ListOperationsByOrchestrationID(orchestrationID string, pageSize, page int) ([]dbmodel.OperationDTO, int, int, error) {
+func (r readSession) ListOperationsByOrchestrationID(orchestrationID string, filter dbmodel.OperationFilter, pageSize, page int) ([]dbmodel.OperationDTO, int, int, error) {
 	var ops []dbmodel.OperationDTO
-	condition := dbr.Eq("orchestration_id", orchestrationID)
 
-	_, err := r.session.
+	stmt := r.session.
 		Select("*").
 		From(postsql.OperationTableName).
-		Where(condition).
+		Where(dbr.Eq("orchestration_id", orchestrationID)).
 		OrderBy(postsql.CreatedAtField).
-		Offset(uint64(pagination.ConvertPageAndPageSizeToOffset(pageSize, page))).
-		Limit(uint64(pageSize)).
-		Load(&ops)
+		OrderBy("id")
+	addOperationPagination(stmt, filter, pageSize, page)
+	addOperationFilters(stmt, filter)
+
+	_, err := stmt.Load(&ops)
 	if err != nil {
 		return nil, -1, -1, dberr.Internal("Failed to get operations: %s", err)
 	}
 
-	totalCount, err := r.getOperationCount(orchestrationID)
+	totalCount, err := r.getOperationCount(orchestrationID, filter)
+	if err != nil {
+		return nil, -1, -1, err
+	}
+
+	return ops,
+		len(ops),
+		totalCount,
+		nil
+}
+
+func (r readSession) ListOperationsByType(operationType dbmodel.OperationType, filter dbmodel.OperationFilter, pageSize, page int) ([]dbmodel.OperationDTO, int, int, error) {
+	var ops []dbmodel.OperationDTO
+
+	stmt := r.session.
+		Select("*").
+		From(postsql.OperationTableName).
+		Where(dbr.Eq("type", string(operationType))).
+		OrderBy(postsql.CreatedAtField).
+		OrderBy("id")
+	addOperationPagination(stmt, filter, pageSize, page)
+	addOperationFilters(stmt, filter)
+
+	_, err := stmt.Load(&ops)
+	if err != nil {
+		return nil, -1, -1, dberr.Internal("Failed to get operations: %s", err)
+	}
+
+	totalCount, err := r.getOperationCountByType(operationType, filter)
 	if err != nil {
 		return nil, -1, -1, err
 	}
@@ -355,20 +459,165 @@ func (r readSession) GetLMSTenant(name, region string) (dbmodel.LMSTenantDTO, db
 	return dto, nil
 }
 
-func (r readSession) GetOperationStats() ([]dbmodel.OperationStatEntry, error) {
-	var rows []dbmodel.OperationStatEntry
-	_, err := r.session.SelectBySql(fmt.Sprintf("select type, state, count(*) as total from %s group by type, state",
+func (r readSession) GetKymaChannelSubscription(globalAccountID string) (dbmodel.KymaChannelSubscriptionDTO, dberr.Error) {
+	var dto dbmodel.KymaChannelSubscriptionDTO
+	err := r.session.
+		Select("*").
+		From(postsql.KymaChannelTableName).
+		Where(dbr.Eq("global_account_id", globalAccountID)).
+		LoadOne(&dto)
+
+	if err != nil {
+		if err == dbr.ErrNotFound {
+			return dbmodel.KymaChannelSubscriptionDTO{}, dberr.NotFound("Cannot find kyma channel subscription for global account: '%s'", globalAccountID)
+		}
+		return dbmodel.KymaChannelSubscriptionDTO{}, dberr.Internal("Failed to get kyma channel subscription: %s", err)
+	}
+	return dto, nil
+}
+
+func (r readSession) GetInstallerOverrides(globalAccountID string) (dbmodel.InstallerOverridesDTO, dberr.Error) {
+	var dto dbmodel.InstallerOverridesDTO
+	err := r.session.
+		Select("*").
+		From(postsql.InstallerOverridesTableName).
+		Where(dbr.Eq("global_account_id", globalAccountID)).
+		LoadOne(&dto)
+
+	if err != nil {
+		if err == dbr.ErrNotFound {
+			return dbmodel.InstallerOverridesDTO{}, dberr.NotFound("Cannot find installer overrides for global account: '%s'", globalAccountID)
+		}
+		return dbmodel.InstallerOverridesDTO{}, dberr.Internal("Failed to get installer overrides: %s", err)
+	}
+	return dto, nil
+}
+
+func (r readSession) ListFreeTierUsageByGlobalAccountID(globalAccountID string) ([]dbmodel.FreeTierUsageDTO, dberr.Error) {
+	var entries []dbmodel.FreeTierUsageDTO
+	_, err := r.session.
+		Select("*").
+		From(postsql.FreeTierUsageTableName).
+		Where(dbr.Eq("global_account_id", globalAccountID)).
+		OrderAsc("started_at").
+		Load(&entries)
+
+	if err != nil {
+		return nil, dberr.Internal("Failed to get free tier usage: %s", err)
+	}
+	return entries, nil
+}
+
+func (r readSession) ListKillSwitchEvents() ([]dbmodel.KillSwitchEventDTO, dberr.Error) {
+	var events []dbmodel.KillSwitchEventDTO
+	_, err := r.session.
+		Select("*").
+		From(postsql.KillSwitchEventTableName).
+		OrderBy(postsql.CreatedAtField).
+		Load(&events)
+	if err != nil {
+		return nil, dberr.Internal("Failed to get kill switch events: %s", err)
+	}
+	return events, nil
+}
+
+// ListLatestKillSwitchEvents reads only the latest event of every kill switch, which decides the state of the switch
+func (r readSession) ListLatestKillSwitchEvents() ([]dbmodel.KillSwitchEventDTO, dberr.Error) {
+	var events []dbmodel.KillSwitchEventDTO
+	_, err := r.session.SelectBySql(fmt.Sprintf(`select distinct on (scope, name) * from %s
+		order by scope, name, %s desc`,
+		postsql.KillSwitchEventTableName, postsql.CreatedAtField)).Load(&events)
+	if err != nil {
+		return nil, dberr.Internal("Failed to get latest kill switch events: %s", err)
+	}
+	return events, nil
+}
+
+func (r readSession) ListJobRuns(jobName string, limit int) ([]dbmodel.JobRunDTO, dberr.Error) {
+	var runs []dbmodel.JobRunDTO
+	_, err := r.session.
+		Select("*").
+		From(postsql.JobRunTableName).
+		Where(dbr.Eq("job_name", jobName)).
+		OrderDesc("started_at").
+		Limit(uint64(limit)).
+		Load(&runs)
+	if err != nil {
+		return nil, dberr.Internal("Failed to get job runs: %s", err)
+	}
+	return runs, nil
+}
+
+func (r readSession) GetTrialExpiration(instanceID string) (dbmodel.TrialExpirationDTO, dberr.Error) {
+	var dto dbmodel.TrialExpirationDTO
+	err := r.session.
+		Select("*").
+		From(postsql.TrialExpirationTableName).
+		Where(dbr.Eq("instance_id", instanceID)).
+		LoadOne(&dto)
+
+	if err != nil {
+		if err == dbr.ErrNotFound {
+			return dbmodel.TrialExpirationDTO{}, dberr.NotFound("Cannot find trial expiration for instance: '%s'", instanceID)
+		}
+		return dbmodel.TrialExpirationDTO{}, dberr.Internal("Failed to get trial expiration: %s", err)
+	}
+	return dto, nil
+}
+
+func (r readSession) ListOperationEventsByOperationID(operationID string) ([]dbmodel.OperationEventDTO, dberr.Error) {
+	var events []dbmodel.OperationEventDTO
+	_, err := r.session.
+		Select("*").
+		From(postsql.OperationEventTableName).
+		Where(dbr.Eq("operation_id", operationID)).
+		OrderBy(postsql.CreatedAtField).
+		Load(&events)
+	if err != nil {
+		return nil, dberr.Internal("Failed to get operation events: %s", err)
+	}
+	return events, nil
+}
+
+// GetOperationStats counts operations per type and state, broken down by the plan ID and the region
+// from the provisioning parameters, the operations without the provisioning parameters have both empty
+func (r readSession) GetOperationStats() ([]dbmodel.OperationPlanRegionStatEntry, error) {
+	var rows []dbmodel.OperationPlanRegionStatEntry
+	_, err := r.session.SelectBySql(fmt.Sprintf(`select type, state,
+		coalesce(nullif(data->>'provisioning_parameters', '')::json->>'plan_id', '') as plan_id,
+		coalesce(nullif(data->>'provisioning_parameters', '')::json->'parameters'->>'region', '') as region,
+		count(*) as total
+		from %s group by type, state, plan_id, region`,
 		postsql.OperationTableName)).Load(&rows)
 	return rows, err
 }
 
+// GetOperationBucketStats counts operations started (by creation time) and finished (by the time of the last update)
+// in the time buckets of the given interval, the operations started have empty state
+func (r readSession) GetOperationBucketStats(from, to time.Time, interval time.Duration) ([]dbmodel.OperationBucketStatEntry, error) {
+	var rows []dbmodel.OperationBucketStatEntry
+	// timestamps are passed as epoch seconds to not depend on the time zone of the database session
+	start := float64(from.UnixNano()) / float64(time.Second)
+	end := float64(to.UnixNano()) / float64(time.Second)
+	seconds := interval.Seconds()
+	_, err := r.session.SelectBySql(fmt.Sprintf(`select type, '' as state, floor((extract(epoch from created_at) - ?) / ?)::int as bucket, count(*) as total
+		from %s where created_at >= to_timestamp(?) and created_at < to_timestamp(?) group by type, bucket
+		union all
+		select type, state, floor((extract(epoch from updated_at) - ?) / ?)::int as bucket, count(*) as total
+		from %s where state in (?, ?) and updated_at >= to_timestamp(?) and updated_at < to_timestamp(?) group by type, state, bucket`,
+		postsql.OperationTableName, postsql.OperationTableName),
+		start, seconds, start, end,
+		start, seconds, string(domain.Succeeded), string(domain.Failed), start, end).Load(&rows)
+
+	return rows, err
+}
+
 func (r readSession) GetOperationStatsForOrchestration(orchestrationID string) ([]dbmodel.OperationStatEntry, error) {
 	var rows []dbmodel.OperationStatEntry
-	_, err := r.session.Select("state, count(*) as total").
-		From(postsql.OperationTableName).
-		Where(dbr.Eq("orchestration_id", orchestrationID)).
-		GroupBy("state").
-		Load(&rows)
+	// the operations in progress which were not sent to the provisioner yet are reported as pending
+	_, err := r.session.SelectBySql(fmt.Sprintf(`select case when state = ? and coalesce(target_operation_id, '') = '' then ? else state end as state, count(*) as total
+		from %s where orchestration_id = ? group by 1`, postsql.OperationTableName),
+		string(domain.InProgress), string(internal.OperationPending), orchestrationID).Load(&rows)
 
 	return rows, err
 }
@@ -399,18 +648,59 @@ func (r readSession) ListInstances(filter dbmodel.InstanceFilter) ([]internal.In
 	stmt := r.session.
 		Select("*").
 		From(postsql.InstancesTableName).
-		OrderBy(postsql.CreatedAtField)
+		OrderBy(postsql.CreatedAtField).
+		OrderBy("instance_id")
 
-	// Add pagination
-	if filter.Page > 0 && filter.PageSize > 0 {
-		stmt = stmt.Paginate(uint64(filter.Page), uint64(filter.PageSize))
+	addPagination(stmt, filter)
+	addFilters(stmt, filter)
+
+	_, err := stmt.Load(&instances)
+	if err != nil {
+		return nil, -1, -1, errors.Wrap(err, "while fetching instances")
+	}
+
+	totalCount, err := r.getInstanceCount(filter)
+	if err != nil {
+		return nil, -1, -1, err
 	}
 
+	return instances,
+		len(instances),
+		totalCount,
+		nil
+}
+
+// instancesWithStateColumns are the columns of the instances_with_state view without the largest columns
+// of the instance, which are replaced with the empty values
+var instancesWithStateColumns = strings.Join([]string{
+	"instance_id", "runtime_id", "global_account_id", "sub_account_id", "service_id", "service_name",
+	"service_plan_id", "service_plan_name", "dashboard_url", "'{}' as provisioning_parameters", "provider_region",
+	"api_server_url", "'' as ca_bundle", "shoot_name", "original_global_account_id",
+	"created_at", "updated_at", "deleted_at",
+	"last_operation_id", "last_operation_type", "last_operation_state", "last_operation_version",
+	"last_operation_description", "last_operation_orchestration_id", "last_operation_created_at",
+	"last_operation_updated_at",
+}, ", ")
+
+func (r readSession) ListInstancesWithState(filter dbmodel.InstanceFilter) ([]dbmodel.InstanceWithStateDTO, int, int, error) {
+	var instances []dbmodel.InstanceWithStateDTO
+
+	columns := "*"
+	if filter.SkipLargeColumns {
+		columns = instancesWithStateColumns
+	}
+	stmt := r.session.
+		Select(columns).
+		From(postsql.InstancesWithStateViewName).
+		OrderBy(postsql.CreatedAtField).
+		OrderBy("instance_id")
+
+	addPagination(stmt, filter)
 	addFilters(stmt, filter)
 
 	_, err := stmt.Load(&instances)
 	if err != nil {
-		return nil, -1, -1, errors.Wrap(err, "while fetching instances")
+		return nil, -1, -1, errors.Wrap(err, "while fetching instances with state")
 	}
 
 	totalCount, err := r.getInstanceCount(filter)
@@ -424,6 +714,57 @@ func (r readSession) ListInstances(filter dbmodel.InstanceFilter) ([]internal.In
 		nil
 }
 
+func (r readSession) GetArchivedInstanceByID(instanceID string) (dbmodel.InstanceArchivedDTO, dberr.Error) {
+	var dto dbmodel.InstanceArchivedDTO
+	err := r.session.
+		Select("*").
+		From(postsql.InstancesArchivedTableName).
+		Where(dbr.Eq("instance_id", instanceID)).
+		LoadOne(&dto)
+
+	if err != nil {
+		if err == dbr.ErrNotFound {
+			return dbmodel.InstanceArchivedDTO{}, dberr.NotFound("Cannot find archived instance for instanceID:'%s'", instanceID)
+		}
+		return dbmodel.InstanceArchivedDTO{}, dberr.Internal("Failed to get archived instance: %s", err)
+	}
+	return dto, nil
+}
+
+// ListArchivedInstances lists the archived instances, the filters are the same as for the instances table
+// because the archive holds all columns of the instances table
+func (r readSession) ListArchivedInstances(filter dbmodel.InstanceFilter) ([]dbmodel.InstanceArchivedDTO, int, int, error) {
+	var instances []dbmodel.InstanceArchivedDTO
+
+	stmt := r.session.
+		Select("*").
+		From(postsql.InstancesArchivedTableName).
+		OrderBy(postsql.CreatedAtField).
+		OrderBy("instance_id")
+
+	addPagination(stmt, filter)
+	addFilters(stmt, filter)
+
+	_, err := stmt.Load(&instances)
+	if err != nil {
+		return nil, -1, -1, errors.Wrap(err, "while fetching archived instances")
+	}
+
+	var res struct {
+		Total int
+	}
+	countStmt := r.session.Select("count(*) as total").From(postsql.InstancesArchivedTableName)
+	addFilters(countStmt, filter)
+	if err := countStmt.LoadOne(&res); err != nil {
+		return nil, -1, -1, errors.Wrap(err, "while counting archived instances")
+	}
+
+	return instances,
+		len(instances),
+		res.Total,
+		nil
+}
+
 func (r readSession) getInstanceCount(filter dbmodel.InstanceFilter) (int, error) {
 	var res struct {
 		Total int
@@ -435,6 +776,33 @@ func (r readSession) getInstanceCount(filter dbmodel.InstanceFilter) (int, error
 	return res.Total, err
 }
 
+// instanceSearchExpression joins the columns matched by the search filter, it must be the same as the expression
+// of the instances_search_idx trigram index, otherwise the index is not used. The shoot name is matched
+// by the dashboard URL which contains it.
+const instanceSearchExpression = "instance_id || ' ' || runtime_id || ' ' || global_account_id || ' ' || sub_account_id || ' ' || dashboard_url"
+
+// likeEscaper escapes the wildcards of the LIKE pattern, so the search text is matched literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+func escapeLike(text string) string {
+	return likeEscaper.Replace(text)
+}
+
+// addPagination limits the instances to the page, the instances following the cursor are returned instead
+// of the page if the cursor is set. The pagination is not applied to the counting of the instances.
+func addPagination(stmt *dbr.SelectStmt, filter dbmodel.InstanceFilter) {
+	if filter.After != nil {
+		stmt.Where("(created_at, instance_id) > (?, ?)", filter.After.CreatedAt, filter.After.InstanceID)
+		if filter.PageSize > 0 {
+			stmt.Limit(uint64(filter.PageSize))
+		}
+		return
+	}
+	if filter.Page > 0 && filter.PageSize > 0 {
+		stmt.Paginate(uint64(filter.Page), uint64(filter.PageSize))
+	}
+}
+
 func addFilters(stmt *dbr.SelectStmt, filter dbmodel.InstanceFilter) {
 	if len(filter.GlobalAccountIDs) > 0 {
 		stmt.Where("global_account_id IN ?", filter.GlobalAccountIDs)
@@ -461,16 +829,45 @@ func addFilters(stmt *dbr.SelectStmt, filter dbmodel.InstanceFilter) {
 		domainMatch := fmt.Sprintf(`[./](%s)(\.[0-9A-Za-z-]+)*$`, strings.Join(filter.Domains, "|"))
 		stmt.Where("dashboard_url ~ ?", domainMatch)
 	}
+	if filter.Search != "" {
+		stmt.Where(fmt.Sprintf("(%s) ILIKE ?", instanceSearchExpression), "%"+escapeLike(filter.Search)+"%")
+	}
+	if !filter.CreatedAfter.IsZero() {
+		stmt.Where("created_at >= ?", filter.CreatedAfter)
+	}
+	if !filter.CreatedBefore.IsZero() {
+		stmt.Where("created_at < ?", filter.CreatedBefore)
+	}
+	if !filter.UpdatedAfter.IsZero() {
+		stmt.Where("updated_at >= ?", filter.UpdatedAfter)
+	}
+	if !filter.UpdatedBefore.IsZero() {
+		stmt.Where("updated_at < ?", filter.UpdatedBefore)
+	}
 }
 
-func (r readSession) getOperationCount(orchestrationID string) (int, error) {
+func (r readSession) getOperationCount(orchestrationID string, filter dbmodel.OperationFilter) (int, error) {
 	var res struct {
 		Total int
 	}
-	err := r.session.Select("count(*) as total").
+	stmt := r.session.Select("count(*) as total").
 		From(postsql.OperationTableName).
-		Where(dbr.Eq("orchestration_id", orchestrationID)).
-		LoadOne(&res)
+		Where(dbr.Eq("orchestration_id", orchestrationID))
+	addOperationFilters(stmt, filter)
+	err := stmt.LoadOne(&res)
+
+	return res.Total, err
+}
+
+func (r readSession) getOperationCountByType(operationType dbmodel.OperationType, filter dbmodel.OperationFilter) (int, error) {
+	var res struct {
+		Total int
+	}
+	stmt := r.session.Select("count(*) as total").
+		From(postsql.OperationTableName).
+		Where(dbr.Eq("type", string(operationType)))
+	addOperationFilters(stmt, filter)
+	err := stmt.LoadOne(&res)
 
 	return res.Total, err
 }
@@ -485,3 +882,33 @@ func (r readSession) getOrchestrationCount() (int, error) {
 
 	return res.Total, err
 }
+
+// addOperationPagination limits the operations to the page, the operations following the cursor are returned instead
+// of the page if the cursor is set. The pagination is not applied to the counting of the operations.
+func addOperationPagination(stmt *dbr.SelectStmt, filter dbmodel.OperationFilter, pageSize, page int) {
+	stmt.Limit(uint64(pageSize))
+	if filter.After != nil {
+		stmt.Where("(created_at, id) > (?, ?)", filter.After.CreatedAt, filter.After.OperationID)
+		return
+	}
+	stmt.Offset(uint64(pagination.ConvertPageAndPageSizeToOffset(pageSize, page)))
+}
+
+func addOperationFilters(stmt *dbr.SelectStmt, filter dbmodel.OperationFilter) {
+	if len(filter.States) > 0 {
+		stmt.Where("state IN ?", filter.States)
+	}
+	if filter.OrchestrationID != "" {
+		stmt.Where(dbr.Eq("orchestration_id", filter.OrchestrationID))
+	}
+	if len(filter.PlanIDs) > 0 {
+		// provisioning parameters are stored as a JSON string in the operation data
+		stmt.Where("(data->>'provisioning_parameters')::json->>'plan_id' IN ?", filter.PlanIDs)
+	}
+	if !filter.CreatedAfter.IsZero() {
+		stmt.Where(dbr.Gte(postsql.CreatedAtField, filter.CreatedAfter))
+	}
+	if !filter.CreatedBefore.IsZero() {
+		stmt.Where(dbr.Lt(postsql.CreatedAtField, filter.CreatedBefore))
+	}
+}
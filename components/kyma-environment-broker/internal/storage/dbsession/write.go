@@ -1,6 +1,7 @@
 package dbsession
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession/dbmodel"
@@ -10,6 +11,7 @@ import (
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/postsql"
 	"github.com/lib/pq"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
 )
 
 const (
@@ -34,12 +36,19 @@ func (ws writeSession) InsertInstance(instance internal.Instance) dberr.Error {
 		Pair("dashboard_url", instance.DashboardURL).
 		Pair("provisioning_parameters", instance.ProvisioningParameters).
 		Pair("provider_region", instance.ProviderRegion).
+		Pair("api_server_url", instance.APIServerURL).
+		Pair("ca_bundle", instance.CABundle).
+		Pair("shoot_name", instance.ShootName).
+		Pair("original_global_account_id", instance.OriginalGlobalAccountID).
 		// in postgres database it will be equal to "0001-01-01 00:00:00+00"
 		Pair("deleted_at", time.Time{}).
 		Exec()
 
 	if err != nil {
 		if err, ok := err.(*pq.Error); ok {
+			if err.Code == UniqueViolationErrorCode && err.Constraint == dbmodel.TrialSubAccountIndexName {
+				return dberr.AlreadyExists("trial instance for subaccount %s already exist", instance.SubAccountID)
+			}
 			if err.Code == UniqueViolationErrorCode {
 				return dberr.AlreadyExists("operation with id %s already exist", instance.InstanceID)
 			}
@@ -72,6 +81,10 @@ func (ws writeSession) UpdateInstance(instance internal.Instance) dberr.Error {
 		Set("dashboard_url", instance.DashboardURL).
 		Set("provisioning_parameters", instance.ProvisioningParameters).
 		Set("provider_region", instance.ProviderRegion).
+		Set("api_server_url", instance.APIServerURL).
+		Set("ca_bundle", instance.CABundle).
+		Set("shoot_name", instance.ShootName).
+		Set("original_global_account_id", instance.OriginalGlobalAccountID).
 		Set("updated_at", time.Now()).
 		Exec()
 	if err != nil {
@@ -94,6 +107,10 @@ func (ws writeSession) InsertOperation(op dbmodel.OperationDTO) dberr.Error {
 		Pair("type", op.Type).
 		Pair("data", op.Data).
 		Pair("orchestration_id", op.OrchestrationID.String).
+		Pair("correlation_id", op.CorrelationID.String).
+		Pair("stage", op.Stage).
+		Pair("stage_started_at", op.StageStartedAt).
+		Pair("retried_at", op.RetriedAt).
 		Exec()
 
 	if err != nil {
@@ -111,11 +128,13 @@ func (ws writeSession) InsertOperation(op dbmodel.OperationDTO) dberr.Error {
 func (ws writeSession) InsertOrchestration(o dbmodel.OrchestrationDTO) dberr.Error {
 	_, err := ws.insertInto(postsql.OrchestrationTableName).
 		Pair("orchestration_id", o.OrchestrationID).
+		Pair("type", o.Type).
 		Pair("created_at", o.CreatedAt).
 		Pair("updated_at", o.UpdatedAt).
 		Pair("description", o.Description).
 		Pair("state", o.State).
 		Pair("parameters", o.Parameters).
+		Pair("runtime_operations", o.RuntimeOperations).
 		Exec()
 
 	if err != nil {
@@ -138,6 +157,7 @@ func (ws writeSession) UpdateOrchestration(o dbmodel.OrchestrationDTO) dberr.Err
 		Set("description", o.Description).
 		Set("state", o.State).
 		Set("parameters", o.Parameters).
+		Set("runtime_operations", o.RuntimeOperations).
 		Exec()
 
 	if err != nil {
@@ -182,6 +202,40 @@ func (ws writeSession) InsertRuntimeState(state dbmodel.RuntimeStateDTO) dberr.E
 	return nil
 }
 
+func (ws writeSession) DeleteRuntimeStateByOperationID(operationID string) dberr.Error {
+	_, err := ws.deleteFrom(postsql.RuntimeStateTableName).
+		Where(dbr.Eq("operation_id", operationID)).
+		Exec()
+
+	if err != nil {
+		return dberr.Internal("Failed to delete record from RuntimeState table: %s", err)
+	}
+	return nil
+}
+
+// DeleteSupersededRuntimeStatesCreatedBefore keeps the newest runtime state of every runtime and the newest state
+// of its succeeded provisioning or Kyma upgrade operation, see storage.RuntimeStates
+func (ws writeSession) DeleteSupersededRuntimeStatesCreatedBefore(before time.Time) (int, dberr.Error) {
+	res, err := ws.deleteFrom(postsql.RuntimeStateTableName).
+		Where(dbr.Lt(postsql.CreatedAtField, before)).
+		Where(fmt.Sprintf(`id not in (select distinct on (runtime_id) id from %s order by runtime_id, created_at desc)`,
+			postsql.RuntimeStateTableName)).
+		Where(fmt.Sprintf(`id not in (select distinct on (rs.runtime_id) rs.id from %s rs join %s o on o.id = rs.operation_id
+			where o.state = ? and o.type in (?, ?) order by rs.runtime_id, rs.created_at desc)`,
+			postsql.RuntimeStateTableName, postsql.OperationTableName),
+			string(domain.Succeeded), string(dbmodel.OperationTypeProvision), string(dbmodel.OperationTypeUpgradeKyma)).
+		Exec()
+
+	if err != nil {
+		return 0, dberr.Internal("Failed to delete records from RuntimeState table: %s", err)
+	}
+	rAffected, err := res.RowsAffected()
+	if err != nil {
+		return 0, dberr.Internal("the DB driver does not support RowsAffected operation")
+	}
+	return int(rAffected), nil
+}
+
 func (ws writeSession) InsertLMSTenant(dto dbmodel.LMSTenantDTO) dberr.Error {
 	_, err := ws.insertInto(postsql.LMSTenantTableName).
 		Pair("id", dto.ID).
@@ -202,6 +256,347 @@ func (ws writeSession) InsertLMSTenant(dto dbmodel.LMSTenantDTO) dberr.Error {
 	return nil
 }
 
+// UpsertKymaChannelSubscription updates the channel of the global account or inserts the subscription if it does not exist
+func (ws writeSession) UpsertKymaChannelSubscription(dto dbmodel.KymaChannelSubscriptionDTO) dberr.Error {
+	res, err := ws.update(postsql.KymaChannelTableName).
+		Where(dbr.Eq("global_account_id", dto.GlobalAccountID)).
+		Set("channel", dto.Channel).
+		Set("updated_at", dto.UpdatedAt).
+		Exec()
+	if err != nil {
+		return dberr.Internal("Failed to update record to kyma channel subscription table: %s", err)
+	}
+	rAffected, err := res.RowsAffected()
+	if err != nil {
+		return dberr.Internal("the DB driver does not support RowsAffected operation")
+	}
+	if rAffected > 0 {
+		return nil
+	}
+
+	_, err = ws.insertInto(postsql.KymaChannelTableName).
+		Pair("global_account_id", dto.GlobalAccountID).
+		Pair("channel", dto.Channel).
+		Pair("updated_at", dto.UpdatedAt).
+		Exec()
+	if err != nil {
+		if err, ok := err.(*pq.Error); ok {
+			if err.Code == UniqueViolationErrorCode {
+				return dberr.Conflict("kyma channel subscription for global account %s was created in the meantime", dto.GlobalAccountID)
+			}
+		}
+		return dberr.Internal("Failed to insert record to kyma channel subscription table: %s", err)
+	}
+
+	return nil
+}
+
+// UpsertTrialExpiration updates the expiration of the trial instance or inserts it if it does not exist
+func (ws writeSession) UpsertTrialExpiration(dto dbmodel.TrialExpirationDTO) dberr.Error {
+	res, err := ws.update(postsql.TrialExpirationTableName).
+		Where(dbr.Eq("instance_id", dto.InstanceID)).
+		Set("expires_at", dto.ExpiresAt).
+		Set("notified_at", dto.NotifiedAt).
+		Set("deprovisioned_at", dto.DeprovisionedAt).
+		Set("updated_at", dto.UpdatedAt).
+		Exec()
+	if err != nil {
+		return dberr.Internal("Failed to update record to trial expiration table: %s", err)
+	}
+	rAffected, err := res.RowsAffected()
+	if err != nil {
+		return dberr.Internal("the DB driver does not support RowsAffected operation")
+	}
+	if rAffected > 0 {
+		return nil
+	}
+
+	_, err = ws.insertInto(postsql.TrialExpirationTableName).
+		Pair("instance_id", dto.InstanceID).
+		Pair("expires_at", dto.ExpiresAt).
+		Pair("notified_at", dto.NotifiedAt).
+		Pair("deprovisioned_at", dto.DeprovisionedAt).
+		Pair("updated_at", dto.UpdatedAt).
+		Exec()
+	if err != nil {
+		if err, ok := err.(*pq.Error); ok {
+			if err.Code == UniqueViolationErrorCode {
+				return dberr.Conflict("trial expiration for instance %s was created in the meantime", dto.InstanceID)
+			}
+		}
+		return dberr.Internal("Failed to insert record to trial expiration table: %s", err)
+	}
+
+	return nil
+}
+
+// UpsertInstallerOverrides replaces the overrides of the global account or inserts them if they do not exist
+func (ws writeSession) UpsertInstallerOverrides(dto dbmodel.InstallerOverridesDTO) dberr.Error {
+	res, err := ws.update(postsql.InstallerOverridesTableName).
+		Where(dbr.Eq("global_account_id", dto.GlobalAccountID)).
+		Set("data", dto.Data).
+		Set("updated_at", dto.UpdatedAt).
+		Exec()
+	if err != nil {
+		return dberr.Internal("Failed to update record to installer overrides table: %s", err)
+	}
+	rAffected, err := res.RowsAffected()
+	if err != nil {
+		return dberr.Internal("the DB driver does not support RowsAffected operation")
+	}
+	if rAffected > 0 {
+		return nil
+	}
+
+	_, err = ws.insertInto(postsql.InstallerOverridesTableName).
+		Pair("global_account_id", dto.GlobalAccountID).
+		Pair("data", dto.Data).
+		Pair("updated_at", dto.UpdatedAt).
+		Exec()
+	if err != nil {
+		if err, ok := err.(*pq.Error); ok {
+			if err.Code == UniqueViolationErrorCode {
+				return dberr.Conflict("installer overrides for global account %s were created in the meantime", dto.GlobalAccountID)
+			}
+		}
+		return dberr.Internal("Failed to insert record to installer overrides table: %s", err)
+	}
+
+	return nil
+}
+
+func (ws writeSession) DeleteInstallerOverrides(globalAccountID string) dberr.Error {
+	_, err := ws.deleteFrom(postsql.InstallerOverridesTableName).
+		Where(dbr.Eq("global_account_id", globalAccountID)).
+		Exec()
+	if err != nil {
+		return dberr.Internal("Failed to delete record from installer overrides table: %s", err)
+	}
+	return nil
+}
+
+func (ws writeSession) InsertArchivedInstance(dto dbmodel.InstanceArchivedDTO) dberr.Error {
+	_, err := ws.insertInto(postsql.InstancesArchivedTableName).
+		Pair("instance_id", dto.InstanceID).
+		Pair("runtime_id", dto.RuntimeID).
+		Pair("global_account_id", dto.GlobalAccountID).
+		Pair("sub_account_id", dto.SubAccountID).
+		Pair("service_id", dto.ServiceID).
+		Pair("service_name", dto.ServiceName).
+		Pair("service_plan_id", dto.ServicePlanID).
+		Pair("service_plan_name", dto.ServicePlanName).
+		Pair("dashboard_url", dto.DashboardURL).
+		Pair("provisioning_parameters", dto.ProvisioningParameters).
+		Pair("provider_region", dto.ProviderRegion).
+		Pair("api_server_url", dto.APIServerURL).
+		Pair("ca_bundle", dto.CABundle).
+		Pair("shoot_name", dto.ShootName).
+		Pair("created_at", dto.CreatedAt).
+		Pair("updated_at", dto.UpdatedAt).
+		Pair("deleted_at", dto.DeletedAt).
+		Pair("operations", dto.Operations).
+		Pair("archived_at", dto.ArchivedAt).
+		Exec()
+
+	if err != nil {
+		if err, ok := err.(*pq.Error); ok {
+			if err.Code == UniqueViolationErrorCode {
+				return dberr.AlreadyExists("archived instance with id %s already exist", dto.InstanceID)
+			}
+		}
+		return dberr.Internal("Failed to insert record to archived instances table: %s", err)
+	}
+
+	return nil
+}
+
+func (ws writeSession) InsertFreeTierUsage(dto dbmodel.FreeTierUsageDTO) dberr.Error {
+	_, err := ws.insertInto(postsql.FreeTierUsageTableName).
+		Pair("instance_id", dto.InstanceID).
+		Pair("global_account_id", dto.GlobalAccountID).
+		Pair("plan_id", dto.PlanID).
+		Pair("started_at", dto.StartedAt).
+		Pair("finished_at", dto.FinishedAt).
+		Exec()
+
+	if err != nil {
+		if err, ok := err.(*pq.Error); ok {
+			if err.Code == UniqueViolationErrorCode {
+				return dberr.AlreadyExists("free tier usage of instance %s already exist", dto.InstanceID)
+			}
+		}
+		return dberr.Internal("Failed to insert record to free tier usage table: %s", err)
+	}
+
+	return nil
+}
+
+func (ws writeSession) InsertKillSwitchEvent(dto dbmodel.KillSwitchEventDTO) dberr.Error {
+	_, err := ws.insertInto(postsql.KillSwitchEventTableName).
+		Pair("id", dto.ID).
+		Pair("scope", dto.Scope).
+		Pair("name", dto.Name).
+		Pair("disabled", dto.Disabled).
+		Pair("message", dto.Message).
+		Pair("changed_by", dto.ChangedBy).
+		Pair("created_at", dto.CreatedAt).
+		Exec()
+	if err != nil {
+		if err, ok := err.(*pq.Error); ok {
+			if err.Code == UniqueViolationErrorCode {
+				return dberr.AlreadyExists("kill switch event with id %s already exist", dto.ID)
+			}
+		}
+		return dberr.Internal("Failed to insert record to kill switch events table: %s", err)
+	}
+
+	return nil
+}
+
+// AcquireJobLock takes over the lock which expired before now or is held by the same owner, or inserts the lock
+// if it does not exist. False is returned if the lock is held by another owner.
+func (ws writeSession) AcquireJobLock(dto dbmodel.JobLockDTO, now time.Time) (bool, dberr.Error) {
+	res, err := ws.update(postsql.JobLockTableName).
+		Where(dbr.Eq("job_name", dto.JobName)).
+		Where(dbr.Or(dbr.Lt("locked_until", now), dbr.Eq("owner", dto.Owner))).
+		Set("owner", dto.Owner).
+		Set("locked_until", dto.LockedUntil).
+		Exec()
+	if err != nil {
+		return false, dberr.Internal("Failed to update record of job locks table: %s", err)
+	}
+	rAffected, err := res.RowsAffected()
+	if err != nil {
+		return false, dberr.Internal("the DB driver does not support RowsAffected operation")
+	}
+	if rAffected > 0 {
+		return true, nil
+	}
+
+	_, err = ws.insertInto(postsql.JobLockTableName).
+		Pair("job_name", dto.JobName).
+		Pair("owner", dto.Owner).
+		Pair("locked_until", dto.LockedUntil).
+		Exec()
+	if err != nil {
+		if err, ok := err.(*pq.Error); ok {
+			if err.Code == UniqueViolationErrorCode {
+				// the lock exists and is held by another owner
+				return false, nil
+			}
+		}
+		return false, dberr.Internal("Failed to insert record to job locks table: %s", err)
+	}
+
+	return true, nil
+}
+
+func (ws writeSession) ReleaseJobLock(jobName, owner string) dberr.Error {
+	_, err := ws.deleteFrom(postsql.JobLockTableName).
+		Where(dbr.And(dbr.Eq("job_name", jobName), dbr.Eq("owner", owner))).
+		Exec()
+	if err != nil {
+		return dberr.Internal("Failed to delete record from job locks table: %s", err)
+	}
+	return nil
+}
+
+func (ws writeSession) InsertJobRun(dto dbmodel.JobRunDTO) dberr.Error {
+	_, err := ws.insertInto(postsql.JobRunTableName).
+		Pair("id", dto.ID).
+		Pair("job_name", dto.JobName).
+		Pair("owner", dto.Owner).
+		Pair("trigger", dto.Trigger).
+		Pair("state", dto.State).
+		Pair("error", dto.Error).
+		Pair("details", dto.Details).
+		Pair("started_at", dto.StartedAt).
+		Pair("finished_at", dto.FinishedAt).
+		Exec()
+	if err != nil {
+		if err, ok := err.(*pq.Error); ok {
+			if err.Code == UniqueViolationErrorCode {
+				return dberr.AlreadyExists("job run with id %s already exist", dto.ID)
+			}
+		}
+		return dberr.Internal("Failed to insert record to job runs table: %s", err)
+	}
+
+	return nil
+}
+
+func (ws writeSession) UpdateJobRun(dto dbmodel.JobRunDTO) dberr.Error {
+	res, err := ws.update(postsql.JobRunTableName).
+		Where(dbr.Eq("id", dto.ID)).
+		Set("state", dto.State).
+		Set("error", dto.Error).
+		Set("details", dto.Details).
+		Set("finished_at", dto.FinishedAt).
+		Exec()
+	if err != nil {
+		return dberr.Internal("Failed to update record of job runs table: %s", err)
+	}
+	rAffected, err := res.RowsAffected()
+	if err != nil {
+		return dberr.Internal("the DB driver does not support RowsAffected operation")
+	}
+	if rAffected == 0 {
+		return dberr.NotFound("Cannot find job run with ID:'%s'", dto.ID)
+	}
+
+	return nil
+}
+
+func (ws writeSession) DeleteJobRunsStartedBefore(before time.Time) (int, dberr.Error) {
+	res, err := ws.deleteFrom(postsql.JobRunTableName).
+		Where(dbr.Lt("started_at", before)).
+		Exec()
+	if err != nil {
+		return 0, dberr.Internal("Failed to delete records from job runs table: %s", err)
+	}
+	rAffected, err := res.RowsAffected()
+	if err != nil {
+		return 0, dberr.Internal("the DB driver does not support RowsAffected operation")
+	}
+	return int(rAffected), nil
+}
+
+// FinishFreeTierUsage sets the finish time of the entry which is not finished yet
+func (ws writeSession) FinishFreeTierUsage(instanceID string, finishedAt time.Time) dberr.Error {
+	_, err := ws.update(postsql.FreeTierUsageTableName).
+		Where(dbr.And(dbr.Eq("instance_id", instanceID), dbr.Eq("finished_at", time.Time{}))).
+		Set("finished_at", finishedAt).
+		Exec()
+	if err != nil {
+		return dberr.Internal("Failed to update record of free tier usage table: %s", err)
+	}
+	return nil
+}
+
+func (ws writeSession) InsertOperationEvent(dto dbmodel.OperationEventDTO) dberr.Error {
+	_, err := ws.insertInto(postsql.OperationEventTableName).
+		Pair("id", dto.ID).
+		Pair("operation_id", dto.OperationID).
+		Pair("instance_id", dto.InstanceID).
+		Pair("actor", dto.Actor).
+		Pair("step_name", dto.StepName).
+		Pair("old_state", dto.OldState).
+		Pair("new_state", dto.NewState).
+		Pair("description", dto.Description).
+		Pair("created_at", dto.CreatedAt).
+		Exec()
+	if err != nil {
+		if err, ok := err.(*pq.Error); ok {
+			if err.Code == UniqueViolationErrorCode {
+				return dberr.AlreadyExists("operation event with id %s already exist", dto.ID)
+			}
+		}
+		return dberr.Internal("Failed to insert record to operation events table: %s", err)
+	}
+
+	return nil
+}
+
 func (ws writeSession) UpdateOperation(op dbmodel.OperationDTO) dberr.Error {
 	res, err := ws.update(postsql.OperationTableName).
 		Where(dbr.Eq("id", op.ID)).
@@ -216,6 +611,10 @@ func (ws writeSession) UpdateOperation(op dbmodel.OperationDTO) dberr.Error {
 		Set("type", op.Type).
 		Set("data", op.Data).
 		Set("orchestration_id", op.OrchestrationID.String).
+		Set("correlation_id", op.CorrelationID.String).
+		Set("stage", op.Stage).
+		Set("stage_started_at", op.StageStartedAt).
+		Set("retried_at", op.RetriedAt).
 		Exec()
 
 	if err != nil {
@@ -0,0 +1,79 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
+)
+
+type freeTierUsage struct {
+	mu sync.Mutex
+
+	entries map[string]internal.FreeTierUsageEntry
+}
+
+func NewFreeTierUsage() *freeTierUsage {
+	return &freeTierUsage{
+		entries: make(map[string]internal.FreeTierUsageEntry, 0),
+	}
+}
+
+func (s *freeTierUsage) Insert(entry internal.FreeTierUsageEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.entries[entry.InstanceID]; exists {
+		return dberr.AlreadyExists("free tier usage of instance %s already exist", entry.InstanceID)
+	}
+	s.entries[entry.InstanceID] = entry
+	return nil
+}
+
+func (s *freeTierUsage) Finish(instanceID string, finishedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, exists := s.entries[instanceID]
+	if !exists || !entry.FinishedAt.IsZero() {
+		return nil
+	}
+	entry.FinishedAt = finishedAt
+	s.entries[instanceID] = entry
+	return nil
+}
+
+func (s *freeTierUsage) ListByGlobalAccountID(globalAccountID string) ([]internal.FreeTierUsageEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]internal.FreeTierUsageEntry, 0)
+	for _, entry := range s.entries {
+		if entry.GlobalAccountID == globalAccountID {
+			result = append(result, entry)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].StartedAt.Before(result[j].StartedAt)
+	})
+	return result, nil
+}
+
+// Checkpoint returns the function which restores the content of the storage from the time of the call
+func (s *freeTierUsage) Checkpoint() func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := make(map[string]internal.FreeTierUsageEntry, len(s.entries))
+	for k, v := range s.entries {
+		entries[k] = v
+	}
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.entries = entries
+	}
+}
@@ -0,0 +1,60 @@
+package memory
+
+import (
+	"sync"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+)
+
+type installerOverrides struct {
+	mu sync.Mutex
+
+	data map[string]internal.InstallerOverrides
+}
+
+func NewInstallerOverrides() *installerOverrides {
+	return &installerOverrides{
+		data: make(map[string]internal.InstallerOverrides, 0),
+	}
+}
+
+func (s *installerOverrides) GetOverrides(globalAccountID string) (internal.InstallerOverrides, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	overrides, exists := s.data[globalAccountID]
+	return overrides, exists, nil
+}
+
+func (s *installerOverrides) UpsertOverrides(overrides internal.InstallerOverrides) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data[overrides.GlobalAccountID] = overrides
+	return nil
+}
+
+func (s *installerOverrides) DeleteOverrides(globalAccountID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.data, globalAccountID)
+	return nil
+}
+
+// Checkpoint returns the function which restores the content of the storage from the time of the call
+func (s *installerOverrides) Checkpoint() func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data := make(map[string]internal.InstallerOverrides, len(s.data))
+	for k, v := range s.data {
+		data[k] = v
+	}
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.data = data
+	}
+}
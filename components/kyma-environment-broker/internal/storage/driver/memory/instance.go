@@ -4,7 +4,9 @@ import (
 	"database/sql"
 	"regexp"
 	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"fmt"
 
@@ -15,7 +17,7 @@ import (
 )
 
 type Instance struct {
-	mu                sync.Mutex
+	mu                sync.RWMutex
 	instances         map[string]internal.Instance
 	operationsStorage *operations
 }
@@ -26,7 +28,11 @@ func NewInstance(operations *operations) *Instance {
 		operationsStorage: operations,
 	}
 }
+
 func (s *Instance) FindAllJoinedWithOperations(prct ...predicate.Predicate) ([]internal.InstanceWithOperation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	var instances []internal.InstanceWithOperation
 
 	// simulate left join without grouping on column
@@ -81,6 +87,9 @@ func (s *Instance) FindAllJoinedWithOperations(prct ...predicate.Predicate) ([]i
 }
 
 func (s *Instance) FindAllInstancesForRuntimes(runtimeIdList []string) ([]internal.Instance, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	var instances []internal.Instance
 
 	for _, runtimeID := range runtimeIdList {
@@ -99,6 +108,9 @@ func (s *Instance) FindAllInstancesForRuntimes(runtimeIdList []string) ([]intern
 }
 
 func (s *Instance) FindAllInstancesForSubAccounts(subAccountslist []string) ([]internal.Instance, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	var instances []internal.Instance
 
 	for _, subAccount := range subAccountslist {
@@ -113,6 +125,9 @@ func (s *Instance) FindAllInstancesForSubAccounts(subAccountslist []string) ([]i
 }
 
 func (s *Instance) GetNumberOfInstancesForGlobalAccountID(globalAccountID string) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	numberOfInstances := 0
 	for _, inst := range s.instances {
 		if inst.GlobalAccountID == globalAccountID {
@@ -123,6 +138,9 @@ func (s *Instance) GetNumberOfInstancesForGlobalAccountID(globalAccountID string
 }
 
 func (s *Instance) GetByID(instanceID string) (*internal.Instance, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	inst, ok := s.instances[instanceID]
 	if !ok {
 		return nil, dberr.NotFound("instance with id %s not exist", instanceID)
@@ -142,6 +160,16 @@ func (s *Instance) Delete(instanceID string) error {
 func (s *Instance) Insert(instance internal.Instance) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	if _, exists := s.instances[instance.InstanceID]; exists {
+		return dberr.AlreadyExists("instance with id %s already exist", instance.InstanceID)
+	}
+	if instance.ServicePlanID == dbmodel.TrialPlanID {
+		for _, i := range s.instances {
+			if i.ServicePlanID == dbmodel.TrialPlanID && i.SubAccountID == instance.SubAccountID {
+				return dberr.AlreadyExists("trial instance for subaccount %s already exist", instance.SubAccountID)
+			}
+		}
+	}
 	s.instances[instance.InstanceID] = instance
 
 	return nil
@@ -156,20 +184,31 @@ func (s *Instance) Update(instance internal.Instance) error {
 }
 
 func (s *Instance) GetInstanceStats() (internal.InstanceStats, error) {
-	return internal.InstanceStats{}, fmt.Errorf("not implemented")
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := internal.InstanceStats{
+		PerGlobalAccountID: make(map[string]int),
+	}
+	for _, inst := range s.instances {
+		result.PerGlobalAccountID[inst.GlobalAccountID] = result.PerGlobalAccountID[inst.GlobalAccountID] + 1
+		result.TotalNumberOfInstances = result.TotalNumberOfInstances + 1
+	}
+	return result, nil
 }
 
 func (s *Instance) List(filter dbmodel.InstanceFilter) ([]internal.Instance, int, int, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	var toReturn []internal.Instance
 
-	offset := convertPageAndPageSizeToOffset(filter.PageSize, filter.Page)
-
 	instances := s.filterInstances(filter)
 	sortInstancesByCreatedAt(instances)
 
-	for i := offset; i < offset+filter.PageSize && i < len(instances); i++ {
+	offset, limit := pageBounds(filter, len(instances), func(i int) bool {
+		return instanceAfterCursor(instances[i], *filter.After)
+	})
+	for i := offset; i < offset+limit && i < len(instances); i++ {
 		toReturn = append(toReturn, s.instances[instances[i].InstanceID])
 	}
 
@@ -179,15 +218,78 @@ func (s *Instance) List(filter dbmodel.InstanceFilter) ([]internal.Instance, int
 		nil
 }
 
+func (s *Instance) ListWithState(filter dbmodel.InstanceFilter) ([]internal.InstanceWithState, int, int, error) {
+	instances, count, totalCount, err := s.List(filter)
+	if err != nil {
+		return nil, -1, -1, err
+	}
+
+	toReturn := make([]internal.InstanceWithState, 0, len(instances))
+	for _, instance := range instances {
+		if filter.SkipLargeColumns {
+			instance.ProvisioningParameters = "{}"
+			instance.CABundle = ""
+		}
+		lastOperation, lastOperationType := s.operationsStorage.lastOperationByInstanceID(instance.InstanceID)
+		toReturn = append(toReturn, internal.InstanceWithState{
+			Instance:          instance,
+			LastOperation:     lastOperation,
+			LastOperationType: string(lastOperationType),
+		})
+	}
+
+	return toReturn, count, totalCount, nil
+}
+
+// sortInstancesByCreatedAt sorts the instances by the creation time and the instance ID, the same as the cursor
 func sortInstancesByCreatedAt(instances []internal.Instance) {
 	sort.Slice(instances, func(i, j int) bool {
-		return instances[i].CreatedAt.Before(instances[j].CreatedAt)
+		if !instances[i].CreatedAt.Equal(instances[j].CreatedAt) {
+			return instances[i].CreatedAt.Before(instances[j].CreatedAt)
+		}
+		return instances[i].InstanceID < instances[j].InstanceID
 	})
 }
 
+func instanceAfterCursor(instance internal.Instance, cursor dbmodel.InstanceCursor) bool {
+	if !instance.CreatedAt.Equal(cursor.CreatedAt) {
+		return instance.CreatedAt.After(cursor.CreatedAt)
+	}
+	return instance.InstanceID > cursor.InstanceID
+}
+
+// pageBounds returns the offset and the limit of the page of the n sorted instances, all instances are returned
+// if the page is not specified. If the cursor is set, the page starts at the first instance after the cursor,
+// which is checked with the afterCursor function.
+func pageBounds(filter dbmodel.InstanceFilter, n int, afterCursor func(i int) bool) (int, int) {
+	offset, limit := 0, n
+	if filter.After != nil {
+		offset = sort.Search(n, afterCursor)
+		if filter.PageSize > 0 {
+			limit = filter.PageSize
+		}
+		return offset, limit
+	}
+	if filter.Page > 0 && filter.PageSize > 0 {
+		offset = convertPageAndPageSizeToOffset(filter.PageSize, filter.Page)
+		limit = filter.PageSize
+	}
+	return offset, limit
+}
+
 func (s *Instance) filterInstances(filter dbmodel.InstanceFilter) []internal.Instance {
 	inst := make([]internal.Instance, 0, len(s.instances))
-	var ok bool
+	for _, v := range s.instances {
+		if matchInstanceFilter(v, filter) {
+			inst = append(inst, v)
+		}
+	}
+
+	return inst
+}
+
+// matchInstanceFilter checks if the instance matches all predicates of the filter
+func matchInstanceFilter(v internal.Instance, filter dbmodel.InstanceFilter) bool {
 	equal := func(a, b string) bool {
 		return a == b
 	}
@@ -200,34 +302,17 @@ func (s *Instance) filterInstances(filter dbmodel.InstanceFilter) []internal.Ins
 		return err == nil && matched
 	}
 
-	for _, v := range s.instances {
-		if ok = matchFilter(v.InstanceID, filter.InstanceIDs, equal); !ok {
-			continue
-		}
-		if ok = matchFilter(v.GlobalAccountID, filter.GlobalAccountIDs, equal); !ok {
-			continue
-		}
-		if ok = matchFilter(v.SubAccountID, filter.SubAccountIDs, equal); !ok {
-			continue
-		}
-		if ok = matchFilter(v.RuntimeID, filter.RuntimeIDs, equal); !ok {
-			continue
-		}
-		if ok = matchFilter(v.ServicePlanName, filter.Plans, equal); !ok {
-			continue
-		}
-		if ok = matchFilter(v.ProviderRegion, filter.Regions, equal); !ok {
-			continue
-		}
+	return matchFilter(v.InstanceID, filter.InstanceIDs, equal) &&
+		matchFilter(v.GlobalAccountID, filter.GlobalAccountIDs, equal) &&
+		matchFilter(v.SubAccountID, filter.SubAccountIDs, equal) &&
+		matchFilter(v.RuntimeID, filter.RuntimeIDs, equal) &&
+		matchFilter(v.ServicePlanName, filter.Plans, equal) &&
+		matchFilter(v.ProviderRegion, filter.Regions, equal) &&
 		// Match domains with dashboard url
-		if ok = matchFilter(v.DashboardURL, filter.Domains, domainMatch); !ok {
-			continue
-		}
-
-		inst = append(inst, v)
-	}
-
-	return inst
+		matchFilter(v.DashboardURL, filter.Domains, domainMatch) &&
+		matchSearch(v, filter.Search) &&
+		matchTimeRange(v.CreatedAt, filter.CreatedAfter, filter.CreatedBefore) &&
+		matchTimeRange(v.UpdatedAt, filter.UpdatedAfter, filter.UpdatedBefore)
 }
 
 func matchFilter(value string, filters []string, match func(string, string) bool) bool {
@@ -242,10 +327,46 @@ func matchFilter(value string, filters []string, match func(string, string) bool
 	return false
 }
 
+// matchSearch checks if any of the searched fields of the instance contains the text, the case is ignored
+func matchSearch(v internal.Instance, text string) bool {
+	if text == "" {
+		return true
+	}
+	fields := strings.Join([]string{v.InstanceID, v.RuntimeID, v.GlobalAccountID, v.SubAccountID, v.DashboardURL}, " ")
+	return strings.Contains(strings.ToLower(fields), strings.ToLower(text))
+}
+
+// matchTimeRange checks if the value is within [after, before), the zero value of a bound disables it
+func matchTimeRange(value, after, before time.Time) bool {
+	if !after.IsZero() && value.Before(after) {
+		return false
+	}
+	if !before.IsZero() && !value.Before(before) {
+		return false
+	}
+	return true
+}
+
 func convertPageAndPageSizeToOffset(pageSize, page int) int {
 	if page < 2 {
 		return 0
-	} else {
-		return page*pageSize - 1
+	}
+	return (page - 1) * pageSize
+}
+
+// Checkpoint returns the function which restores the content of the storage from the time of the call
+func (s *Instance) Checkpoint() func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	instances := make(map[string]internal.Instance, len(s.instances))
+	for k, v := range s.instances {
+		instances[k] = v
+	}
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.instances = instances
 	}
 }
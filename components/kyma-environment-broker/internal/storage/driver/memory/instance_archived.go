@@ -0,0 +1,89 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession/dbmodel"
+)
+
+type instancesArchived struct {
+	mu sync.Mutex
+
+	instances map[string]internal.ArchivedInstance
+}
+
+func NewInstancesArchived() *instancesArchived {
+	return &instancesArchived{
+		instances: make(map[string]internal.ArchivedInstance, 0),
+	}
+}
+
+func (s *instancesArchived) Insert(archived internal.ArchivedInstance) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.instances[archived.InstanceID]; exists {
+		return dberr.AlreadyExists("archived instance with id %s already exist", archived.InstanceID)
+	}
+	s.instances[archived.InstanceID] = archived
+	return nil
+}
+
+func (s *instancesArchived) GetByID(instanceID string) (*internal.ArchivedInstance, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	archived, exists := s.instances[instanceID]
+	if !exists {
+		return nil, dberr.NotFound("archived instance with id %s not exist", instanceID)
+	}
+	return &archived, nil
+}
+
+func (s *instancesArchived) List(filter dbmodel.InstanceFilter) ([]internal.ArchivedInstance, int, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	matching := make([]internal.ArchivedInstance, 0)
+	for _, archived := range s.instances {
+		if matchInstanceFilter(archived.Instance, filter) {
+			matching = append(matching, archived)
+		}
+	}
+	sort.Slice(matching, func(i, j int) bool {
+		if !matching[i].CreatedAt.Equal(matching[j].CreatedAt) {
+			return matching[i].CreatedAt.Before(matching[j].CreatedAt)
+		}
+		return matching[i].InstanceID < matching[j].InstanceID
+	})
+
+	offset, limit := pageBounds(filter, len(matching), func(i int) bool {
+		return instanceAfterCursor(matching[i].Instance, *filter.After)
+	})
+	result := make([]internal.ArchivedInstance, 0)
+	for i := offset; i < offset+limit && i < len(matching); i++ {
+		result = append(result, matching[i])
+	}
+
+	return result, len(result), len(matching), nil
+}
+
+// Checkpoint returns the function which restores the content of the storage from the time of the call
+func (s *instancesArchived) Checkpoint() func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	instances := make(map[string]internal.ArchivedInstance, len(s.instances))
+	for k, v := range s.instances {
+		instances[k] = v
+	}
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.instances = instances
+	}
+}
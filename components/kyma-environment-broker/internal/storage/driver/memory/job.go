@@ -0,0 +1,138 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
+)
+
+type jobLock struct {
+	owner       string
+	lockedUntil time.Time
+}
+
+type jobs struct {
+	mu sync.Mutex
+
+	locks map[string]jobLock
+	runs  map[string]internal.JobRun
+}
+
+func NewJobs() *jobs {
+	return &jobs{
+		locks: make(map[string]jobLock, 0),
+		runs:  make(map[string]internal.JobRun, 0),
+	}
+}
+
+func (s *jobs) AcquireLock(jobName, owner string, now, until time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if lock, exists := s.locks[jobName]; exists && lock.owner != owner && !lock.lockedUntil.Before(now) {
+		return false, nil
+	}
+	s.locks[jobName] = jobLock{owner: owner, lockedUntil: until}
+
+	return true, nil
+}
+
+func (s *jobs) ReleaseLock(jobName, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if lock, exists := s.locks[jobName]; exists && lock.owner == owner {
+		delete(s.locks, jobName)
+	}
+
+	return nil
+}
+
+func (s *jobs) InsertRun(run internal.JobRun) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.runs[run.ID]; exists {
+		return dberr.AlreadyExists("job run with id %s already exist", run.ID)
+	}
+	s.runs[run.ID] = run
+
+	return nil
+}
+
+func (s *jobs) UpdateRun(run internal.JobRun) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, exists := s.runs[run.ID]
+	if !exists {
+		return dberr.NotFound("job run with id %s not exist", run.ID)
+	}
+	stored.State = run.State
+	stored.Error = run.Error
+	stored.Details = run.Details
+	stored.FinishedAt = run.FinishedAt
+	s.runs[run.ID] = stored
+
+	return nil
+}
+
+func (s *jobs) ListRuns(jobName string, limit int) ([]internal.JobRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]internal.JobRun, 0)
+	for _, run := range s.runs {
+		if run.JobName == jobName {
+			result = append(result, run)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].StartedAt.After(result[j].StartedAt)
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+
+	return result, nil
+}
+
+func (s *jobs) DeleteRunsStartedBefore(before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deleted := 0
+	for id, run := range s.runs {
+		if run.StartedAt.Before(before) {
+			delete(s.runs, id)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+// Checkpoint returns the function which restores the content of the storage from the time of the call
+func (s *jobs) Checkpoint() func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	locks := make(map[string]jobLock, len(s.locks))
+	for k, v := range s.locks {
+		locks[k] = v
+	}
+	runs := make(map[string]internal.JobRun, len(s.runs))
+	for k, v := range s.runs {
+		runs[k] = v
+	}
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.locks = locks
+		s.runs = runs
+	}
+}
@@ -0,0 +1,89 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
+)
+
+type killSwitches struct {
+	mu sync.Mutex
+
+	// events are kept in the insertion order, so the events created at the same time keep their order
+	events []internal.KillSwitchEvent
+	ids    map[string]struct{}
+}
+
+func NewKillSwitches() *killSwitches {
+	return &killSwitches{
+		ids: make(map[string]struct{}, 0),
+	}
+}
+
+func (s *killSwitches) InsertEvent(event internal.KillSwitchEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.ids[event.ID]; exists {
+		return dberr.AlreadyExists("kill switch event with id %s already exist", event.ID)
+	}
+	s.ids[event.ID] = struct{}{}
+	s.events = append(s.events, event)
+
+	return nil
+}
+
+func (s *killSwitches) ListEvents() ([]internal.KillSwitchEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]internal.KillSwitchEvent, len(s.events))
+	copy(result, s.events)
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+
+	return result, nil
+}
+
+func (s *killSwitches) ListLatestEvents() ([]internal.KillSwitchEvent, error) {
+	events, _ := s.ListEvents()
+
+	latest := map[string]internal.KillSwitchEvent{}
+	for _, event := range events {
+		latest[event.Scope+"/"+event.Name] = event
+	}
+	result := make([]internal.KillSwitchEvent, 0, len(latest))
+	for _, event := range latest {
+		result = append(result, event)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Scope != result[j].Scope {
+			return result[i].Scope < result[j].Scope
+		}
+		return result[i].Name < result[j].Name
+	})
+
+	return result, nil
+}
+
+// Checkpoint returns the function which restores the content of the storage from the time of the call
+func (s *killSwitches) Checkpoint() func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	events := append([]internal.KillSwitchEvent(nil), s.events...)
+	ids := make(map[string]struct{}, len(s.ids))
+	for k, v := range s.ids {
+		ids[k] = v
+	}
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.events = events
+		s.ids = ids
+	}
+}
@@ -0,0 +1,52 @@
+package memory
+
+import (
+	"sync"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+)
+
+type kymaChannels struct {
+	mu sync.Mutex
+
+	data map[string]internal.KymaChannelSubscription
+}
+
+func NewKymaChannels() *kymaChannels {
+	return &kymaChannels{
+		data: make(map[string]internal.KymaChannelSubscription, 0),
+	}
+}
+
+func (s *kymaChannels) GetSubscription(globalAccountID string) (internal.KymaChannelSubscription, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subscription, exists := s.data[globalAccountID]
+	return subscription, exists, nil
+}
+
+func (s *kymaChannels) UpsertSubscription(subscription internal.KymaChannelSubscription) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data[subscription.GlobalAccountID] = subscription
+	return nil
+}
+
+// Checkpoint returns the function which restores the content of the storage from the time of the call
+func (s *kymaChannels) Checkpoint() func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data := make(map[string]internal.KymaChannelSubscription, len(s.data))
+	for k, v := range s.data {
+		data[k] = v
+	}
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.data = data
+	}
+}
@@ -49,3 +49,20 @@ func (s *lmsTenants) InsertTenant(tenant internal.LMSTenant) error {
 
 	return nil
 }
+
+// Checkpoint returns the function which restores the content of the storage from the time of the call
+func (s *lmsTenants) Checkpoint() func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data := make(map[key]internal.LMSTenant, len(s.data))
+	for k, v := range s.data {
+		data[k] = v
+	}
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.data = data
+	}
+}
@@ -1,8 +1,10 @@
 package memory
 
 import (
+	"encoding/json"
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/common/pagination"
 
@@ -13,20 +15,32 @@ import (
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession/dbmodel"
 )
 
+// operations keeps the operations by value, every getter holds the read lock and returns a copy of the stored
+// operation, so the callers never share the data with the storage and the readers do not block each other
 type operations struct {
-	mu sync.Mutex
+	mu sync.RWMutex
 
-	provisioningOperations   map[string]internal.ProvisioningOperation
-	deprovisioningOperations map[string]internal.DeprovisioningOperation
-	upgradeKymaOperations    map[string]internal.UpgradeKymaOperation
+	provisioningOperations     map[string]internal.ProvisioningOperation
+	deprovisioningOperations   map[string]internal.DeprovisioningOperation
+	upgradeKymaOperations      map[string]internal.UpgradeKymaOperation
+	upgradeClusterOperations   map[string]internal.UpgradeClusterOperation
+	planMigrationOperations    map[string]internal.PlanMigrationOperation
+	updatingOperations         map[string]internal.UpdatingOperation
+	suspensionOperations       map[string]internal.SuspensionOperation
+	accountMigrationOperations map[string]internal.AccountMigrationOperation
 }
 
 // NewOperation creates in-memory storage for OSB operations.
 func NewOperation() *operations {
 	return &operations{
-		provisioningOperations:   make(map[string]internal.ProvisioningOperation, 0),
-		deprovisioningOperations: make(map[string]internal.DeprovisioningOperation, 0),
-		upgradeKymaOperations:    make(map[string]internal.UpgradeKymaOperation, 0),
+		provisioningOperations:     make(map[string]internal.ProvisioningOperation, 0),
+		deprovisioningOperations:   make(map[string]internal.DeprovisioningOperation, 0),
+		upgradeKymaOperations:      make(map[string]internal.UpgradeKymaOperation, 0),
+		upgradeClusterOperations:   make(map[string]internal.UpgradeClusterOperation, 0),
+		planMigrationOperations:    make(map[string]internal.PlanMigrationOperation, 0),
+		updatingOperations:         make(map[string]internal.UpdatingOperation, 0),
+		suspensionOperations:       make(map[string]internal.SuspensionOperation, 0),
+		accountMigrationOperations: make(map[string]internal.AccountMigrationOperation, 0),
 	}
 }
 
@@ -44,6 +58,9 @@ func (s *operations) InsertProvisioningOperation(operation internal.Provisioning
 }
 
 func (s *operations) GetProvisioningOperationByID(operationID string) (*internal.ProvisioningOperation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	op, exists := s.provisioningOperations[operationID]
 	if !exists {
 		return nil, dberr.NotFound("instance provisioning operation with id %s not found", operationID)
@@ -51,13 +68,26 @@ func (s *operations) GetProvisioningOperationByID(operationID string) (*internal
 	return &op, nil
 }
 
+// GetProvisioningOperationByInstanceID returns the latest provisioning operation of the instance,
+// the instance is provisioned again when it is migrated to another plan
 func (s *operations) GetProvisioningOperationByInstanceID(instanceID string) (*internal.ProvisioningOperation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var latest *internal.ProvisioningOperation
 	for _, op := range s.provisioningOperations {
-		if op.InstanceID == instanceID {
-			return &op, nil
+		if op.InstanceID != instanceID {
+			continue
+		}
+		if latest == nil || op.CreatedAt.After(latest.CreatedAt) {
+			found := op
+			latest = &found
 		}
 	}
-	return nil, dberr.NotFound("instance provisioning operation with instanceID %s not found", instanceID)
+	if latest == nil {
+		return nil, dberr.NotFound("instance provisioning operation with instanceID %s not found", instanceID)
+	}
+	return latest, nil
 }
 
 func (s *operations) UpdateProvisioningOperation(op internal.ProvisioningOperation) (*internal.ProvisioningOperation, error) {
@@ -77,6 +107,95 @@ func (s *operations) UpdateProvisioningOperation(op internal.ProvisioningOperati
 	return &op, nil
 }
 
+func (s *operations) ListProvisioningOperations(filter dbmodel.OperationFilter, pageSize, page int) ([]internal.ProvisioningOperation, int, int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	operations := make([]internal.ProvisioningOperation, 0)
+	for _, op := range s.provisioningOperations {
+		if matchOperationFilter(op.Operation, filter) && matchPlanFilter(op, filter.PlanIDs) {
+			operations = append(operations, op)
+		}
+	}
+	sort.Slice(operations, func(i, j int) bool {
+		return operationBefore(operations[i].Operation, operations[j].Operation)
+	})
+
+	offset := operationsPageOffset(filter, pageSize, page, len(operations), func(i int) internal.Operation {
+		return operations[i].Operation
+	})
+	result := make([]internal.ProvisioningOperation, 0)
+	for i := offset; i < offset+pageSize && i < len(operations); i++ {
+		result = append(result, operations[i])
+	}
+
+	return result,
+		len(result),
+		len(operations),
+		nil
+}
+
+// operationBefore orders the operations by the creation time and the ID, the same as the cursor
+func operationBefore(a, b internal.Operation) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+	return a.ID < b.ID
+}
+
+// operationsPageOffset returns the offset of the page of the n sorted operations. If the cursor is set,
+// the page starts at the first operation after the cursor and the page number is ignored.
+func operationsPageOffset(filter dbmodel.OperationFilter, pageSize, page, n int, operation func(i int) internal.Operation) int {
+	if filter.After == nil {
+		return pagination.ConvertPageAndPageSizeToOffset(pageSize, page)
+	}
+	cursor := internal.Operation{ID: filter.After.OperationID, CreatedAt: filter.After.CreatedAt}
+	return sort.Search(n, func(i int) bool {
+		return operationBefore(cursor, operation(i))
+	})
+}
+
+func matchOperationFilter(op internal.Operation, filter dbmodel.OperationFilter) bool {
+	if len(filter.States) > 0 {
+		found := false
+		for _, state := range filter.States {
+			if string(op.State) == state {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if filter.OrchestrationID != "" && op.OrchestrationID != filter.OrchestrationID {
+		return false
+	}
+	if !filter.CreatedAfter.IsZero() && op.CreatedAt.Before(filter.CreatedAfter) {
+		return false
+	}
+	if !filter.CreatedBefore.IsZero() && !op.CreatedAt.Before(filter.CreatedBefore) {
+		return false
+	}
+	return true
+}
+
+func matchPlanFilter(op internal.ProvisioningOperation, planIDs []string) bool {
+	if len(planIDs) == 0 {
+		return true
+	}
+	pp, err := op.GetProvisioningParameters()
+	if err != nil {
+		return false
+	}
+	for _, planID := range planIDs {
+		if pp.PlanID == planID {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *operations) InsertDeprovisioningOperation(operation internal.DeprovisioningOperation) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -91,6 +210,9 @@ func (s *operations) InsertDeprovisioningOperation(operation internal.Deprovisio
 }
 
 func (s *operations) GetDeprovisioningOperationByID(operationID string) (*internal.DeprovisioningOperation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	op, exists := s.deprovisioningOperations[operationID]
 	if !exists {
 		return nil, dberr.NotFound("instance deprovisioning operation with id %s not found", operationID)
@@ -98,14 +220,25 @@ func (s *operations) GetDeprovisioningOperationByID(operationID string) (*intern
 	return &op, nil
 }
 
+// GetDeprovisioningOperationByInstanceID returns the latest deprovisioning operation of the instance
 func (s *operations) GetDeprovisioningOperationByInstanceID(instanceID string) (*internal.DeprovisioningOperation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var latest *internal.DeprovisioningOperation
 	for _, op := range s.deprovisioningOperations {
-		if op.InstanceID == instanceID {
-			return &op, nil
+		if op.InstanceID != instanceID {
+			continue
+		}
+		if latest == nil || op.CreatedAt.After(latest.CreatedAt) {
+			found := op
+			latest = &found
 		}
 	}
-
-	return nil, dberr.NotFound("instance deprovisioning operation with instanceID %s not found", instanceID)
+	if latest == nil {
+		return nil, dberr.NotFound("instance deprovisioning operation with instanceID %s not found", instanceID)
+	}
+	return latest, nil
 }
 
 func (s *operations) UpdateDeprovisioningOperation(op internal.DeprovisioningOperation) (*internal.DeprovisioningOperation, error) {
@@ -139,6 +272,9 @@ func (s *operations) InsertUpgradeKymaOperation(operation internal.UpgradeKymaOp
 }
 
 func (s *operations) GetUpgradeKymaOperationByID(operationID string) (*internal.UpgradeKymaOperation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	op, exists := s.upgradeKymaOperations[operationID]
 	if !exists {
 		return nil, dberr.NotFound("instance upgradeKyma operation with id %s not found", operationID)
@@ -146,14 +282,25 @@ func (s *operations) GetUpgradeKymaOperationByID(operationID string) (*internal.
 	return &op, nil
 }
 
+// GetUpgradeKymaOperationByInstanceID returns the latest upgrade kyma operation of the instance
 func (s *operations) GetUpgradeKymaOperationByInstanceID(instanceID string) (*internal.UpgradeKymaOperation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var latest *internal.UpgradeKymaOperation
 	for _, op := range s.upgradeKymaOperations {
-		if op.InstanceID == instanceID {
-			return &op, nil
+		if op.InstanceID != instanceID {
+			continue
+		}
+		if latest == nil || op.CreatedAt.After(latest.CreatedAt) {
+			found := op
+			latest = &found
 		}
 	}
-
-	return nil, dberr.NotFound("instance upgradeKyma operation with instanceID %s not found", instanceID)
+	if latest == nil {
+		return nil, dberr.NotFound("instance upgradeKyma operation with instanceID %s not found", instanceID)
+	}
+	return latest, nil
 }
 
 func (s *operations) UpdateUpgradeKymaOperation(op internal.UpgradeKymaOperation) (*internal.UpgradeKymaOperation, error) {
@@ -173,7 +320,347 @@ func (s *operations) UpdateUpgradeKymaOperation(op internal.UpgradeKymaOperation
 	return &op, nil
 }
 
+func (s *operations) InsertUpgradeClusterOperation(operation internal.UpgradeClusterOperation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := operation.ID
+	if _, exists := s.upgradeClusterOperations[id]; exists {
+		return dberr.AlreadyExists("instance operation with id %s already exist", id)
+	}
+
+	s.upgradeClusterOperations[id] = operation
+	return nil
+}
+
+func (s *operations) GetUpgradeClusterOperationByID(operationID string) (*internal.UpgradeClusterOperation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	op, exists := s.upgradeClusterOperations[operationID]
+	if !exists {
+		return nil, dberr.NotFound("instance upgradeCluster operation with id %s not found", operationID)
+	}
+	return &op, nil
+}
+
+func (s *operations) UpdateUpgradeClusterOperation(op internal.UpgradeClusterOperation) (*internal.UpgradeClusterOperation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	oldOp, exists := s.upgradeClusterOperations[op.ID]
+	if !exists {
+		return nil, dberr.NotFound("instance operation with id %s not found", op.ID)
+	}
+	if oldOp.Version != op.Version {
+		return nil, dberr.Conflict("unable to update upgradeCluster operation with id %s (for instance id %s) - conflict", op.ID, op.InstanceID)
+	}
+	op.Version = op.Version + 1
+	s.upgradeClusterOperations[op.ID] = op
+
+	return &op, nil
+}
+
+// ListUpgradeClusterOperationsByInstanceID returns the upgrade cluster operations of the instance, the latest first
+func (s *operations) ListUpgradeClusterOperationsByInstanceID(instanceID string) ([]internal.UpgradeClusterOperation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]internal.UpgradeClusterOperation, 0)
+	for _, op := range s.upgradeClusterOperations {
+		if op.InstanceID == instanceID {
+			result = append(result, op)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+
+	return result, nil
+}
+
+func (s *operations) ListUpgradeClusterOperationsByOrchestrationID(orchestrationID string, pageSize, page int) ([]internal.UpgradeClusterOperation, int, int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	operations := make([]internal.UpgradeClusterOperation, 0)
+	for _, op := range s.getUpgradeClusterSortedByCreatedAt() {
+		if op.OrchestrationID == orchestrationID {
+			operations = append(operations, op)
+		}
+	}
+
+	offset := pagination.ConvertPageAndPageSizeToOffset(pageSize, page)
+	result := make([]internal.UpgradeClusterOperation, 0)
+	for i := offset; i < offset+pageSize && i < len(operations); i++ {
+		result = append(result, operations[i])
+	}
+
+	return result,
+		len(result),
+		len(operations),
+		nil
+}
+
+func (s *operations) getUpgradeClusterSortedByCreatedAt() []internal.UpgradeClusterOperation {
+	operationsList := make([]internal.UpgradeClusterOperation, 0, len(s.upgradeClusterOperations))
+	for _, v := range s.upgradeClusterOperations {
+		operationsList = append(operationsList, v)
+	}
+	sort.Slice(operationsList, func(i, j int) bool {
+		return operationsList[i].CreatedAt.Before(operationsList[j].CreatedAt)
+	})
+	return operationsList
+}
+
+func (s *operations) InsertPlanMigrationOperation(operation internal.PlanMigrationOperation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := operation.ID
+	if _, exists := s.planMigrationOperations[id]; exists {
+		return dberr.AlreadyExists("instance operation with id %s already exist", id)
+	}
+
+	s.planMigrationOperations[id] = operation
+	return nil
+}
+
+func (s *operations) GetPlanMigrationOperationByID(operationID string) (*internal.PlanMigrationOperation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	op, exists := s.planMigrationOperations[operationID]
+	if !exists {
+		return nil, dberr.NotFound("instance plan migration operation with id %s not found", operationID)
+	}
+	return &op, nil
+}
+
+func (s *operations) GetPlanMigrationOperationByInstanceID(instanceID string) (*internal.PlanMigrationOperation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var latest *internal.PlanMigrationOperation
+	for _, op := range s.planMigrationOperations {
+		if op.InstanceID != instanceID {
+			continue
+		}
+		if latest == nil || op.CreatedAt.After(latest.CreatedAt) {
+			found := op
+			latest = &found
+		}
+	}
+	if latest == nil {
+		return nil, dberr.NotFound("instance plan migration operation with instanceID %s not found", instanceID)
+	}
+	return latest, nil
+}
+
+func (s *operations) UpdatePlanMigrationOperation(op internal.PlanMigrationOperation) (*internal.PlanMigrationOperation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	oldOp, exists := s.planMigrationOperations[op.ID]
+	if !exists {
+		return nil, dberr.NotFound("instance operation with id %s not found", op.ID)
+	}
+	if oldOp.Version != op.Version {
+		return nil, dberr.Conflict("unable to update plan migration operation with id %s (for instance id %s) - conflict", op.ID, op.InstanceID)
+	}
+	op.Version = op.Version + 1
+	s.planMigrationOperations[op.ID] = op
+
+	return &op, nil
+}
+
+func (s *operations) InsertUpdatingOperation(operation internal.UpdatingOperation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := operation.ID
+	if _, exists := s.updatingOperations[id]; exists {
+		return dberr.AlreadyExists("instance operation with id %s already exist", id)
+	}
+
+	s.updatingOperations[id] = operation
+	return nil
+}
+
+func (s *operations) GetUpdatingOperationByID(operationID string) (*internal.UpdatingOperation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	op, exists := s.updatingOperations[operationID]
+	if !exists {
+		return nil, dberr.NotFound("instance updating operation with id %s not found", operationID)
+	}
+	return &op, nil
+}
+
+func (s *operations) GetUpdatingOperationByInstanceID(instanceID string) (*internal.UpdatingOperation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var latest *internal.UpdatingOperation
+	for _, op := range s.updatingOperations {
+		if op.InstanceID != instanceID {
+			continue
+		}
+		if latest == nil || op.CreatedAt.After(latest.CreatedAt) {
+			found := op
+			latest = &found
+		}
+	}
+	if latest == nil {
+		return nil, dberr.NotFound("instance updating operation with instanceID %s not found", instanceID)
+	}
+	return latest, nil
+}
+
+func (s *operations) UpdateUpdatingOperation(op internal.UpdatingOperation) (*internal.UpdatingOperation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	oldOp, exists := s.updatingOperations[op.ID]
+	if !exists {
+		return nil, dberr.NotFound("instance operation with id %s not found", op.ID)
+	}
+	if oldOp.Version != op.Version {
+		return nil, dberr.Conflict("unable to update updating operation with id %s (for instance id %s) - conflict", op.ID, op.InstanceID)
+	}
+	op.Version = op.Version + 1
+	s.updatingOperations[op.ID] = op
+
+	return &op, nil
+}
+
+func (s *operations) InsertSuspensionOperation(operation internal.SuspensionOperation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := operation.ID
+	if _, exists := s.suspensionOperations[id]; exists {
+		return dberr.AlreadyExists("instance operation with id %s already exist", id)
+	}
+
+	s.suspensionOperations[id] = operation
+	return nil
+}
+
+func (s *operations) GetSuspensionOperationByID(operationID string) (*internal.SuspensionOperation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	op, exists := s.suspensionOperations[operationID]
+	if !exists {
+		return nil, dberr.NotFound("instance suspension operation with id %s not found", operationID)
+	}
+	return &op, nil
+}
+
+func (s *operations) GetSuspensionOperationByInstanceID(instanceID string) (*internal.SuspensionOperation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var latest *internal.SuspensionOperation
+	for _, op := range s.suspensionOperations {
+		if op.InstanceID != instanceID {
+			continue
+		}
+		if latest == nil || op.CreatedAt.After(latest.CreatedAt) {
+			found := op
+			latest = &found
+		}
+	}
+	if latest == nil {
+		return nil, dberr.NotFound("instance suspension operation with instanceID %s not found", instanceID)
+	}
+	return latest, nil
+}
+
+func (s *operations) UpdateSuspensionOperation(op internal.SuspensionOperation) (*internal.SuspensionOperation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	oldOp, exists := s.suspensionOperations[op.ID]
+	if !exists {
+		return nil, dberr.NotFound("instance operation with id %s not found", op.ID)
+	}
+	if oldOp.Version != op.Version {
+		return nil, dberr.Conflict("unable to update suspension operation with id %s (for instance id %s) - conflict", op.ID, op.InstanceID)
+	}
+	op.Version = op.Version + 1
+	s.suspensionOperations[op.ID] = op
+
+	return &op, nil
+}
+
+func (s *operations) InsertAccountMigrationOperation(operation internal.AccountMigrationOperation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := operation.ID
+	if _, exists := s.accountMigrationOperations[id]; exists {
+		return dberr.AlreadyExists("instance operation with id %s already exist", id)
+	}
+
+	s.accountMigrationOperations[id] = operation
+	return nil
+}
+
+func (s *operations) GetAccountMigrationOperationByID(operationID string) (*internal.AccountMigrationOperation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	op, exists := s.accountMigrationOperations[operationID]
+	if !exists {
+		return nil, dberr.NotFound("instance account migration operation with id %s not found", operationID)
+	}
+	return &op, nil
+}
+
+func (s *operations) GetAccountMigrationOperationByInstanceID(instanceID string) (*internal.AccountMigrationOperation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var latest *internal.AccountMigrationOperation
+	for _, op := range s.accountMigrationOperations {
+		if op.InstanceID != instanceID {
+			continue
+		}
+		if latest == nil || op.CreatedAt.After(latest.CreatedAt) {
+			found := op
+			latest = &found
+		}
+	}
+	if latest == nil {
+		return nil, dberr.NotFound("instance account migration operation with instanceID %s not found", instanceID)
+	}
+	return latest, nil
+}
+
+func (s *operations) UpdateAccountMigrationOperation(op internal.AccountMigrationOperation) (*internal.AccountMigrationOperation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	oldOp, exists := s.accountMigrationOperations[op.ID]
+	if !exists {
+		return nil, dberr.NotFound("instance operation with id %s not found", op.ID)
+	}
+	if oldOp.Version != op.Version {
+		return nil, dberr.Conflict("unable to update account migration operation with id %s (for instance id %s) - conflict", op.ID, op.InstanceID)
+	}
+	op.Version = op.Version + 1
+	s.accountMigrationOperations[op.ID] = op
+
+	return &op, nil
+}
+
 func (s *operations) GetOperationByID(operationID string) (*internal.Operation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	var res *internal.Operation
 
 	provisionOp, exists := s.provisioningOperations[operationID]
@@ -188,6 +675,26 @@ func (s *operations) GetOperationByID(operationID string) (*internal.Operation,
 	if exists {
 		res = &upgradeKymaOp.Operation
 	}
+	upgradeClusterOp, exists := s.upgradeClusterOperations[operationID]
+	if exists {
+		res = &upgradeClusterOp.Operation
+	}
+	planMigrationOp, exists := s.planMigrationOperations[operationID]
+	if exists {
+		res = &planMigrationOp.Operation
+	}
+	updatingOp, exists := s.updatingOperations[operationID]
+	if exists {
+		res = &updatingOp.Operation
+	}
+	suspensionOp, exists := s.suspensionOperations[operationID]
+	if exists {
+		res = &suspensionOp.Operation
+	}
+	accountMigrationOp, exists := s.accountMigrationOperations[operationID]
+	if exists {
+		res = &accountMigrationOp.Operation
+	}
 	if res == nil {
 		return nil, dberr.NotFound("instance operation with id %s not found", operationID)
 	}
@@ -195,32 +702,104 @@ func (s *operations) GetOperationByID(operationID string) (*internal.Operation,
 	return res, nil
 }
 
+func (s *operations) GetOperationByInstanceAndID(instanceID, operationID string) (*internal.Operation, error) {
+	op, err := s.GetOperationByID(operationID)
+	if err != nil {
+		return nil, err
+	}
+	if op.InstanceID != instanceID {
+		return nil, dberr.NotFound("instance operation with id %s for instance %s not found", operationID, instanceID)
+	}
+
+	return op, nil
+}
+
 func (s *operations) GetOperationsInProgressByType(opType dbmodel.OperationType) ([]internal.Operation, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
+	ops := make([]internal.Operation, 0)
+	for _, op := range s.operationsByType(opType) {
+		if op.State == domain.InProgress {
+			ops = append(ops, op)
+		}
+	}
+
+	return ops, nil
+}
+
+// ListOperationsByType lists the operations of the given type matching the filter, sorted by the creation time
+func (s *operations) ListOperationsByType(opType dbmodel.OperationType, filter dbmodel.OperationFilter, pageSize, page int) ([]internal.Operation, int, int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	operations := make([]internal.Operation, 0)
+	for _, op := range s.operationsByType(opType) {
+		if matchOperationFilter(op, filter) {
+			operations = append(operations, op)
+		}
+	}
+	sort.Slice(operations, func(i, j int) bool {
+		return operationBefore(operations[i], operations[j])
+	})
+
+	offset := operationsPageOffset(filter, pageSize, page, len(operations), func(i int) internal.Operation {
+		return operations[i]
+	})
+	result := make([]internal.Operation, 0)
+	for i := offset; i < offset+pageSize && i < len(operations); i++ {
+		result = append(result, operations[i])
+	}
+
+	return result,
+		len(result),
+		len(operations),
+		nil
+}
+
+// operationsByType returns all operations of the given type, the caller must hold the lock
+func (s *operations) operationsByType(opType dbmodel.OperationType) []internal.Operation {
 	ops := make([]internal.Operation, 0)
 	switch opType {
 	case dbmodel.OperationTypeProvision:
 		for _, op := range s.provisioningOperations {
-			if op.State == domain.InProgress {
-				ops = append(ops, op.Operation)
-			}
+			ops = append(ops, op.Operation)
 		}
 	case dbmodel.OperationTypeDeprovision:
 		for _, op := range s.deprovisioningOperations {
-			if op.State == domain.InProgress {
-				ops = append(ops, op.Operation)
-			}
+			ops = append(ops, op.Operation)
+		}
+	case dbmodel.OperationTypeUpgradeKyma:
+		for _, op := range s.upgradeKymaOperations {
+			ops = append(ops, op.Operation)
+		}
+	case dbmodel.OperationTypeUpgradeCluster:
+		for _, op := range s.upgradeClusterOperations {
+			ops = append(ops, op.Operation)
+		}
+	case dbmodel.OperationTypeMigratePlan:
+		for _, op := range s.planMigrationOperations {
+			ops = append(ops, op.Operation)
+		}
+	case dbmodel.OperationTypeUpdate:
+		for _, op := range s.updatingOperations {
+			ops = append(ops, op.Operation)
+		}
+	case dbmodel.OperationTypeSuspension:
+		for _, op := range s.suspensionOperations {
+			ops = append(ops, op.Operation)
+		}
+	case dbmodel.OperationTypeAccountMigration:
+		for _, op := range s.accountMigrationOperations {
+			ops = append(ops, op.Operation)
 		}
 	}
-
-	return ops, nil
+	return ops
 }
 
 func (s *operations) GetOperationsForIDs(opIdList []string) ([]internal.Operation, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
 	ops := make([]internal.Operation, 0)
 	for _, opID := range opIdList {
@@ -231,6 +810,14 @@ func (s *operations) GetOperationsForIDs(opIdList []string) ([]internal.Operatio
 		}
 	}
 
+	for _, opID := range opIdList {
+		for _, op := range s.upgradeClusterOperations {
+			if op.Operation.ID == opID {
+				ops = append(ops, op.Operation)
+			}
+		}
+	}
+
 	for _, opID := range opIdList {
 		for _, op := range s.provisioningOperations {
 			if op.Operation.ID == opID {
@@ -246,6 +833,38 @@ func (s *operations) GetOperationsForIDs(opIdList []string) ([]internal.Operatio
 			}
 		}
 	}
+
+	for _, opID := range opIdList {
+		for _, op := range s.planMigrationOperations {
+			if op.Operation.ID == opID {
+				ops = append(ops, op.Operation)
+			}
+		}
+	}
+
+	for _, opID := range opIdList {
+		for _, op := range s.updatingOperations {
+			if op.Operation.ID == opID {
+				ops = append(ops, op.Operation)
+			}
+		}
+	}
+
+	for _, opID := range opIdList {
+		for _, op := range s.suspensionOperations {
+			if op.Operation.ID == opID {
+				ops = append(ops, op.Operation)
+			}
+		}
+	}
+
+	for _, opID := range opIdList {
+		for _, op := range s.accountMigrationOperations {
+			if op.Operation.ID == opID {
+				ops = append(ops, op.Operation)
+			}
+		}
+	}
 	if len(ops) == 0 {
 		return nil, dberr.NotFound("operations with ids from list %+q not exist", opIdList)
 	}
@@ -253,90 +872,374 @@ func (s *operations) GetOperationsForIDs(opIdList []string) ([]internal.Operatio
 	return ops, nil
 }
 
-func (s *operations) GetOperationStats() (internal.OperationStats, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// lastOperationByInstanceID returns the latest operation of the instance together with its type
+func (s *operations) lastOperationByInstanceID(instanceID string) (*internal.Operation, dbmodel.OperationType) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-	result := internal.OperationStats{
-		Provisioning:   map[domain.LastOperationState]int{domain.InProgress: 0, domain.Succeeded: 0, domain.Failed: 0},
-		Deprovisioning: map[domain.LastOperationState]int{domain.InProgress: 0, domain.Succeeded: 0, domain.Failed: 0},
+	var (
+		last     *internal.Operation
+		lastType dbmodel.OperationType
+	)
+	consider := func(op internal.Operation, opType dbmodel.OperationType) {
+		if op.InstanceID != instanceID {
+			return
+		}
+		if last == nil || op.CreatedAt.After(last.CreatedAt) {
+			last = &op
+			lastType = opType
+		}
+	}
+	for _, op := range s.provisioningOperations {
+		consider(op.Operation, dbmodel.OperationTypeProvision)
+	}
+	for _, op := range s.deprovisioningOperations {
+		consider(op.Operation, dbmodel.OperationTypeDeprovision)
+	}
+	for _, op := range s.upgradeKymaOperations {
+		consider(op.Operation, dbmodel.OperationTypeUpgradeKyma)
+	}
+	for _, op := range s.upgradeClusterOperations {
+		consider(op.Operation, dbmodel.OperationTypeUpgradeCluster)
+	}
+	for _, op := range s.planMigrationOperations {
+		consider(op.Operation, dbmodel.OperationTypeMigratePlan)
+	}
+	for _, op := range s.updatingOperations {
+		consider(op.Operation, dbmodel.OperationTypeUpdate)
+	}
+	for _, op := range s.suspensionOperations {
+		consider(op.Operation, dbmodel.OperationTypeSuspension)
 	}
+	for _, op := range s.accountMigrationOperations {
+		consider(op.Operation, dbmodel.OperationTypeAccountMigration)
+	}
+
+	return last, lastType
+}
+
+func (s *operations) ListOperationsByInstanceID(instanceID string) ([]internal.Operation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
+	ops := make([]internal.Operation, 0)
 	for _, op := range s.provisioningOperations {
-		result.Provisioning[op.State] = result.Provisioning[op.State] + 1
+		if op.InstanceID == instanceID {
+			ops = append(ops, op.Operation)
+		}
 	}
 	for _, op := range s.deprovisioningOperations {
-		result.Deprovisioning[op.State] = result.Deprovisioning[op.State] + 1
+		if op.InstanceID == instanceID {
+			ops = append(ops, op.Operation)
+		}
 	}
-	return result, nil
+	for _, op := range s.upgradeKymaOperations {
+		if op.InstanceID == instanceID {
+			ops = append(ops, op.Operation)
+		}
+	}
+	for _, op := range s.upgradeClusterOperations {
+		if op.InstanceID == instanceID {
+			ops = append(ops, op.Operation)
+		}
+	}
+	for _, op := range s.planMigrationOperations {
+		if op.InstanceID == instanceID {
+			ops = append(ops, op.Operation)
+		}
+	}
+	for _, op := range s.updatingOperations {
+		if op.InstanceID == instanceID {
+			ops = append(ops, op.Operation)
+		}
+	}
+	for _, op := range s.suspensionOperations {
+		if op.InstanceID == instanceID {
+			ops = append(ops, op.Operation)
+		}
+	}
+	for _, op := range s.accountMigrationOperations {
+		if op.InstanceID == instanceID {
+			ops = append(ops, op.Operation)
+		}
+	}
+
+	sort.Slice(ops, func(i, j int) bool {
+		return ops[i].CreatedAt.Before(ops[j].CreatedAt)
+	})
+
+	return ops, nil
 }
 
-func (s *operations) GetOperationStatsForOrchestration(orchestrationID string) (map[domain.LastOperationState]int, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+func (s *operations) ListOperationsByCorrelationID(correlationID string) ([]internal.Operation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-	result := map[domain.LastOperationState]int{
-		domain.InProgress: 0,
-		domain.Succeeded:  0,
-		domain.Failed:     0,
+	ops := make([]internal.Operation, 0)
+	for _, op := range s.provisioningOperations {
+		if op.CorrelationID == correlationID {
+			ops = append(ops, op.Operation)
+		}
+	}
+	for _, op := range s.deprovisioningOperations {
+		if op.CorrelationID == correlationID {
+			ops = append(ops, op.Operation)
+		}
 	}
 	for _, op := range s.upgradeKymaOperations {
-		result[op.State] = result[op.State] + 1
+		if op.CorrelationID == correlationID {
+			ops = append(ops, op.Operation)
+		}
+	}
+	for _, op := range s.upgradeClusterOperations {
+		if op.CorrelationID == correlationID {
+			ops = append(ops, op.Operation)
+		}
+	}
+	for _, op := range s.planMigrationOperations {
+		if op.CorrelationID == correlationID {
+			ops = append(ops, op.Operation)
+		}
+	}
+	for _, op := range s.updatingOperations {
+		if op.CorrelationID == correlationID {
+			ops = append(ops, op.Operation)
+		}
+	}
+	for _, op := range s.suspensionOperations {
+		if op.CorrelationID == correlationID {
+			ops = append(ops, op.Operation)
+		}
+	}
+	for _, op := range s.accountMigrationOperations {
+		if op.CorrelationID == correlationID {
+			ops = append(ops, op.Operation)
+		}
+	}
+
+	sort.Slice(ops, func(i, j int) bool {
+		return ops[i].CreatedAt.Before(ops[j].CreatedAt)
+	})
+
+	return ops, nil
+}
+
+func (s *operations) GetOperationStats() (internal.OperationStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := internal.NewOperationStats()
+	for _, op := range s.provisioningOperations {
+		planID, region := planAndRegion(op.ProvisioningParameters)
+		result.AddProvisioning(planID, region, op.State, 1)
+	}
+	for _, op := range s.deprovisioningOperations {
+		planID, region := planAndRegion(op.ProvisioningParameters)
+		result.AddDeprovisioning(planID, region, op.State, 1)
 	}
 	return result, nil
 }
 
-func (s *operations) ListUpgradeKymaOperationsByOrchestrationID(orchestrationID string, pageSize, page int) ([]internal.UpgradeKymaOperation, int, int, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// planAndRegion returns the plan ID and the region from the provisioning parameters, both are empty
+// if the parameters are not set
+func planAndRegion(provisioningParameters string) (string, string) {
+	var pp internal.ProvisioningParameters
+	if err := json.Unmarshal([]byte(provisioningParameters), &pp); err != nil {
+		return "", ""
+	}
+	region := ""
+	if pp.Parameters.Region != nil {
+		region = *pp.Parameters.Region
+	}
+	return pp.PlanID, region
+}
 
-	result := make([]internal.UpgradeKymaOperation, 0)
+func (s *operations) GetOperationTimeStats(from time.Time, window, interval time.Duration) (internal.OperationTimeStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stats := internal.NewOperationTimeStats(from, window, interval)
+	for _, op := range s.provisioningOperations {
+		addOperationTimeStats(stats, dbmodel.OperationTypeProvision, op.Operation)
+	}
+	for _, op := range s.deprovisioningOperations {
+		addOperationTimeStats(stats, dbmodel.OperationTypeDeprovision, op.Operation)
+	}
+	for _, op := range s.upgradeKymaOperations {
+		addOperationTimeStats(stats, dbmodel.OperationTypeUpgradeKyma, op.Operation)
+	}
+	for _, op := range s.upgradeClusterOperations {
+		addOperationTimeStats(stats, dbmodel.OperationTypeUpgradeCluster, op.Operation)
+	}
+	for _, op := range s.planMigrationOperations {
+		addOperationTimeStats(stats, dbmodel.OperationTypeMigratePlan, op.Operation)
+	}
+	for _, op := range s.updatingOperations {
+		addOperationTimeStats(stats, dbmodel.OperationTypeUpdate, op.Operation)
+	}
+	for _, op := range s.suspensionOperations {
+		addOperationTimeStats(stats, dbmodel.OperationTypeSuspension, op.Operation)
+	}
+	for _, op := range s.accountMigrationOperations {
+		addOperationTimeStats(stats, dbmodel.OperationTypeAccountMigration, op.Operation)
+	}
+	return stats, nil
+}
+
+func addOperationTimeStats(stats internal.OperationTimeStats, operationType dbmodel.OperationType, op internal.Operation) {
+	if idx, found := stats.BucketIndex(op.CreatedAt); found {
+		stats.Add(idx, string(operationType), internal.OperationCounts{Started: 1})
+	}
+
+	idx, found := stats.BucketIndex(op.UpdatedAt)
+	if !found {
+		return
+	}
+	switch op.State {
+	case domain.Succeeded:
+		stats.Add(idx, string(operationType), internal.OperationCounts{Succeeded: 1})
+	case domain.Failed:
+		stats.Add(idx, string(operationType), internal.OperationCounts{Failed: 1})
+	}
+}
+
+func (s *operations) GetOperationStatsForOrchestration(orchestrationID string) (map[domain.LastOperationState]int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
+	result := internal.NewOrchestrationStats()
 	for _, op := range s.upgradeKymaOperations {
 		if op.OrchestrationID == orchestrationID {
-			result = append(result, op)
+			result[orchestrationStatsState(op.Operation)]++
 		}
 	}
-	offset := pagination.ConvertPageAndPageSizeToOffset(pageSize, page)
+	for _, op := range s.upgradeClusterOperations {
+		if op.OrchestrationID == orchestrationID {
+			result[orchestrationStatsState(op.Operation)]++
+		}
+	}
+	return result, nil
+}
 
-	sortedOperations := s.getUpgradeSortedByCreatedAt(s.upgradeKymaOperations)
-	result = make([]internal.UpgradeKymaOperation, 0)
+func orchestrationStatsState(op internal.Operation) domain.LastOperationState {
+	if op.State == domain.InProgress && op.ProvisionerOperationID == "" {
+		return internal.OperationPending
+	}
+	return op.State
+}
 
-	for i := offset; i < offset+pageSize && i < len(sortedOperations)+offset; i++ {
-		result = append(result, s.upgradeKymaOperations[sortedOperations[i].OrchestrationID])
+func (s *operations) ListUpgradeKymaOperationsByOrchestrationID(orchestrationID string, filter dbmodel.OperationFilter, pageSize, page int) ([]internal.UpgradeKymaOperation, int, int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	operations := make([]internal.UpgradeKymaOperation, 0)
+	for _, op := range s.upgradeKymaOperations {
+		if op.OrchestrationID == orchestrationID && matchOperationFilter(op.Operation, filter) {
+			operations = append(operations, op)
+		}
+	}
+	sort.Slice(operations, func(i, j int) bool {
+		return operationBefore(operations[i].Operation, operations[j].Operation)
+	})
+
+	offset := operationsPageOffset(filter, pageSize, page, len(operations), func(i int) internal.Operation {
+		return operations[i].Operation
+	})
+	result := make([]internal.UpgradeKymaOperation, 0)
+	for i := offset; i < offset+pageSize && i < len(operations); i++ {
+		result = append(result, operations[i])
 	}
 
 	return result,
 		len(result),
-		len(s.upgradeKymaOperations),
+		len(operations),
 		nil
 }
 
+// ListUpgradeKymaOperationsByInstanceID returns the upgrade kyma operations of the instance, the latest first
 func (s *operations) ListUpgradeKymaOperationsByInstanceID(instanceID string) ([]internal.UpgradeKymaOperation, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
 	result := make([]internal.UpgradeKymaOperation, 0)
-
 	for _, op := range s.upgradeKymaOperations {
 		if op.InstanceID == instanceID {
 			result = append(result, op)
 		}
 	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+
+	return result, nil
+}
 
-	sortedOperations := s.getUpgradeSortedByCreatedAt(s.upgradeKymaOperations)
-	result = make([]internal.UpgradeKymaOperation, 0)
+func (s *operations) ListInstanceIDsUpgradedSince(since time.Time) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-	return sortedOperations, nil
+	upgraded := make(map[string]struct{})
+	for _, op := range s.upgradeKymaOperations {
+		if op.State == domain.Succeeded && !op.DryRun && !op.UpdatedAt.Before(since) {
+			upgraded[op.InstanceID] = struct{}{}
+		}
+	}
+
+	result := make([]string, 0, len(upgraded))
+	for id := range upgraded {
+		result = append(result, id)
+	}
+	return result, nil
 }
 
-func (s *operations) getUpgradeSortedByCreatedAt(operations map[string]internal.UpgradeKymaOperation) []internal.UpgradeKymaOperation {
-	operationsList := make([]internal.UpgradeKymaOperation, 0, len(operations))
-	for _, v := range operations {
-		operationsList = append(operationsList, v)
+// Checkpoint returns the function which restores the content of the storage from the time of the call
+func (s *operations) Checkpoint() func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	provisioningOperations := make(map[string]internal.ProvisioningOperation, len(s.provisioningOperations))
+	for k, v := range s.provisioningOperations {
+		provisioningOperations[k] = v
+	}
+	deprovisioningOperations := make(map[string]internal.DeprovisioningOperation, len(s.deprovisioningOperations))
+	for k, v := range s.deprovisioningOperations {
+		deprovisioningOperations[k] = v
+	}
+	upgradeKymaOperations := make(map[string]internal.UpgradeKymaOperation, len(s.upgradeKymaOperations))
+	for k, v := range s.upgradeKymaOperations {
+		upgradeKymaOperations[k] = v
+	}
+	upgradeClusterOperations := make(map[string]internal.UpgradeClusterOperation, len(s.upgradeClusterOperations))
+	for k, v := range s.upgradeClusterOperations {
+		upgradeClusterOperations[k] = v
+	}
+	planMigrationOperations := make(map[string]internal.PlanMigrationOperation, len(s.planMigrationOperations))
+	for k, v := range s.planMigrationOperations {
+		planMigrationOperations[k] = v
+	}
+	updatingOperations := make(map[string]internal.UpdatingOperation, len(s.updatingOperations))
+	for k, v := range s.updatingOperations {
+		updatingOperations[k] = v
+	}
+	suspensionOperations := make(map[string]internal.SuspensionOperation, len(s.suspensionOperations))
+	for k, v := range s.suspensionOperations {
+		suspensionOperations[k] = v
+	}
+	accountMigrationOperations := make(map[string]internal.AccountMigrationOperation, len(s.accountMigrationOperations))
+	for k, v := range s.accountMigrationOperations {
+		accountMigrationOperations[k] = v
+	}
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.provisioningOperations = provisioningOperations
+		s.deprovisioningOperations = deprovisioningOperations
+		s.upgradeKymaOperations = upgradeKymaOperations
+		s.upgradeClusterOperations = upgradeClusterOperations
+		s.planMigrationOperations = planMigrationOperations
+		s.updatingOperations = updatingOperations
+		s.suspensionOperations = suspensionOperations
+		s.accountMigrationOperations = accountMigrationOperations
 	}
-	sort.Slice(operationsList, func(i, j int) bool {
-		return operationsList[i].CreatedAt.Before(operationsList[j].CreatedAt)
-	})
-	return operationsList
 }
@@ -0,0 +1,67 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
+)
+
+type operationEvents struct {
+	mu sync.Mutex
+
+	events map[string]internal.OperationEvent
+}
+
+func NewOperationEvents() *operationEvents {
+	return &operationEvents{
+		events: make(map[string]internal.OperationEvent, 0),
+	}
+}
+
+func (s *operationEvents) InsertEvent(event internal.OperationEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.events[event.ID]; exists {
+		return dberr.AlreadyExists("operation event with id %s already exist", event.ID)
+	}
+	s.events[event.ID] = event
+
+	return nil
+}
+
+func (s *operationEvents) ListEventsByOperationID(operationID string) ([]internal.OperationEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]internal.OperationEvent, 0)
+	for _, event := range s.events {
+		if event.OperationID == operationID {
+			result = append(result, event)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+
+	return result, nil
+}
+
+// Checkpoint returns the function which restores the content of the storage from the time of the call
+func (s *operationEvents) Checkpoint() func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	events := make(map[string]internal.OperationEvent, len(s.events))
+	for k, v := range s.events {
+		events[k] = v
+	}
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.events = events
+	}
+}
@@ -0,0 +1,210 @@
+package memory
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession/dbmodel"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// the tests are meaningful when executed with the race detector: go test -race
+const (
+	concurrentWorkers = 10
+	updatesPerWorker  = 20
+)
+
+func TestOperations_ConcurrentAccess(t *testing.T) {
+	// given
+	ops := NewOperation()
+	instances := NewInstance(ops)
+	for i := 0; i < concurrentWorkers; i++ {
+		err := ops.InsertProvisioningOperation(fixProvisioningOperation(i))
+		require.NoError(t, err)
+		err = instances.Insert(internal.Instance{InstanceID: fmt.Sprintf("instance-%d", i)})
+		require.NoError(t, err)
+	}
+
+	// when
+	var wg sync.WaitGroup
+	for i := 0; i < concurrentWorkers; i++ {
+		wg.Add(2)
+		go func(id int) {
+			defer wg.Done()
+			for u := 0; u < updatesPerWorker; u++ {
+				updateProvisioningOperation(t, ops, fmt.Sprintf("operation-%d", id))
+			}
+		}(i)
+		go func(id int) {
+			defer wg.Done()
+			for u := 0; u < updatesPerWorker; u++ {
+				_, err := ops.GetProvisioningOperationByID(fmt.Sprintf("operation-%d", id))
+				assert.NoError(t, err)
+				_, err = ops.GetProvisioningOperationByInstanceID(fmt.Sprintf("instance-%d", id))
+				assert.NoError(t, err)
+				_, err = ops.GetOperationByID(fmt.Sprintf("operation-%d", id))
+				assert.NoError(t, err)
+				_, err = instances.GetByID(fmt.Sprintf("instance-%d", id))
+				assert.NoError(t, err)
+				_, err = instances.FindAllJoinedWithOperations()
+				assert.NoError(t, err)
+				_, _, _, err = instances.ListWithState(dbmodel.InstanceFilter{PageSize: concurrentWorkers})
+				assert.NoError(t, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// then
+	for i := 0; i < concurrentWorkers; i++ {
+		op, err := ops.GetProvisioningOperationByID(fmt.Sprintf("operation-%d", i))
+		require.NoError(t, err)
+		assert.Equal(t, updatesPerWorker, op.Version)
+	}
+}
+
+func TestOperations_CopyOnRead(t *testing.T) {
+	// given
+	ops := NewOperation()
+	err := ops.InsertProvisioningOperation(fixProvisioningOperation(0))
+	require.NoError(t, err)
+
+	// when
+	op, err := ops.GetProvisioningOperationByID("operation-0")
+	require.NoError(t, err)
+	op.Description = "changed by the caller"
+	op.State = domain.Failed
+
+	// then
+	stored, err := ops.GetProvisioningOperationByInstanceID("instance-0")
+	require.NoError(t, err)
+	assert.Equal(t, "", stored.Description)
+	assert.Equal(t, domain.InProgress, stored.State)
+}
+
+func TestOperations_ConcurrentReadersAndWriters(t *testing.T) {
+	// given
+	ops := NewOperation()
+	for i := 0; i < concurrentWorkers; i++ {
+		err := ops.InsertUpgradeKymaOperation(fixUpgradeKymaOperation(i))
+		require.NoError(t, err)
+	}
+
+	// when
+	var wg sync.WaitGroup
+	for i := 0; i < concurrentWorkers; i++ {
+		wg.Add(2)
+		go func(id int) {
+			defer wg.Done()
+			for u := 0; u < updatesPerWorker; u++ {
+				updateUpgradeKymaOperation(t, ops, fmt.Sprintf("upgrade-%d", id))
+			}
+		}(i)
+		go func(id int) {
+			defer wg.Done()
+			for u := 0; u < updatesPerWorker; u++ {
+				_, err := ops.GetUpgradeKymaOperationByID(fmt.Sprintf("upgrade-%d", id))
+				assert.NoError(t, err)
+				_, err = ops.GetOperationByInstanceAndID(fmt.Sprintf("instance-%d", id), fmt.Sprintf("upgrade-%d", id))
+				assert.NoError(t, err)
+				_, err = ops.ListUpgradeKymaOperationsByInstanceID(fmt.Sprintf("instance-%d", id))
+				assert.NoError(t, err)
+				_, err = ops.ListOperationsByInstanceID(fmt.Sprintf("instance-%d", id))
+				assert.NoError(t, err)
+				_, err = ops.GetOperationsInProgressByType(dbmodel.OperationTypeUpgradeKyma)
+				assert.NoError(t, err)
+				_, err = ops.GetOperationStats()
+				assert.NoError(t, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// then
+	for i := 0; i < concurrentWorkers; i++ {
+		op, err := ops.GetUpgradeKymaOperationByID(fmt.Sprintf("upgrade-%d", i))
+		require.NoError(t, err)
+		assert.Equal(t, updatesPerWorker, op.Version)
+	}
+}
+
+func TestOperations_CopyOnReadOfGenericOperation(t *testing.T) {
+	// given
+	ops := NewOperation()
+	err := ops.InsertUpgradeKymaOperation(fixUpgradeKymaOperation(0))
+	require.NoError(t, err)
+
+	// when
+	op, err := ops.GetOperationByID("upgrade-0")
+	require.NoError(t, err)
+	op.State = domain.Failed
+
+	// then
+	stored, err := ops.GetUpgradeKymaOperationByID("upgrade-0")
+	require.NoError(t, err)
+	assert.Equal(t, domain.InProgress, stored.State)
+}
+
+// updateProvisioningOperation retries the update on conflicts, it is called from goroutines so it does not stop the test
+func updateProvisioningOperation(t *testing.T, ops *operations, id string) {
+	for {
+		op, err := ops.GetProvisioningOperationByID(id)
+		if !assert.NoError(t, err) {
+			return
+		}
+		op.Description = fmt.Sprintf("updated at %s", time.Now())
+		_, err = ops.UpdateProvisioningOperation(*op)
+		if dberr.IsConflict(err) {
+			continue
+		}
+		assert.NoError(t, err)
+		return
+	}
+}
+
+func fixProvisioningOperation(id int) internal.ProvisioningOperation {
+	return internal.ProvisioningOperation{
+		Operation: internal.Operation{
+			ID:         fmt.Sprintf("operation-%d", id),
+			InstanceID: fmt.Sprintf("instance-%d", id),
+			State:      domain.InProgress,
+			CreatedAt:  time.Now(),
+		},
+	}
+}
+
+// updateUpgradeKymaOperation retries the update on conflicts, it is called from goroutines so it does not stop the test
+func updateUpgradeKymaOperation(t *testing.T, ops *operations, id string) {
+	for {
+		op, err := ops.GetUpgradeKymaOperationByID(id)
+		if !assert.NoError(t, err) {
+			return
+		}
+		op.Description = fmt.Sprintf("updated at %s", time.Now())
+		_, err = ops.UpdateUpgradeKymaOperation(*op)
+		if dberr.IsConflict(err) {
+			continue
+		}
+		assert.NoError(t, err)
+		return
+	}
+}
+
+func fixUpgradeKymaOperation(id int) internal.UpgradeKymaOperation {
+	return internal.UpgradeKymaOperation{
+		RuntimeOperation: internal.RuntimeOperation{
+			Operation: internal.Operation{
+				ID:         fmt.Sprintf("upgrade-%d", id),
+				InstanceID: fmt.Sprintf("instance-%d", id),
+				State:      domain.InProgress,
+				CreatedAt:  time.Now(),
+			},
+		},
+	}
+}
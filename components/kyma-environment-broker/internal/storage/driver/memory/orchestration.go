@@ -8,6 +8,7 @@ import (
 
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession/dbmodel"
 )
 
 type orchestration struct {
@@ -25,6 +26,12 @@ func NewOrchestrations() *orchestration {
 func (s *orchestration) Insert(orchestration internal.Orchestration) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	if _, exists := s.orchestrations[orchestration.OrchestrationID]; exists {
+		return dberr.AlreadyExists("orchestration with id %s already exist", orchestration.OrchestrationID)
+	}
+	if orchestration.Type == "" {
+		orchestration.Type = internal.UpgradeKymaOrchestration
+	}
 	s.orchestrations[orchestration.OrchestrationID] = orchestration
 
 	return nil
@@ -42,16 +49,21 @@ func (s *orchestration) GetByID(orchestrationID string) (*internal.Orchestration
 	return &inst, nil
 }
 
-func (s *orchestration) List(pageSize int, page int) ([]internal.Orchestration, int, int, error) {
+func (s *orchestration) List(filter dbmodel.OrchestrationFilter) ([]internal.Orchestration, int, int, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	result := make([]internal.Orchestration, 0)
-	offset := pagination.ConvertPageAndPageSizeToOffset(pageSize, page)
-
 	sortedOrchestrations := s.getSortedByCreatedAt(s.orchestrations)
 
-	for i := offset; i < offset+pageSize && i < len(sortedOrchestrations); i++ {
+	offset := pagination.ConvertPageAndPageSizeToOffset(filter.PageSize, filter.Page)
+	if filter.After != nil {
+		cursor := internal.Orchestration{OrchestrationID: filter.After.OrchestrationID, CreatedAt: filter.After.CreatedAt}
+		offset = sort.Search(len(sortedOrchestrations), func(i int) bool {
+			return orchestrationBefore(cursor, sortedOrchestrations[i])
+		})
+	}
+	for i := offset; i < offset+filter.PageSize && i < len(sortedOrchestrations); i++ {
 		result = append(result, s.orchestrations[sortedOrchestrations[i].OrchestrationID])
 	}
 
@@ -64,10 +76,13 @@ func (s *orchestration) List(pageSize int, page int) ([]internal.Orchestration,
 func (s *orchestration) Update(orchestration internal.Orchestration) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	if _, ok := s.orchestrations[orchestration.OrchestrationID]; !ok {
+	current, ok := s.orchestrations[orchestration.OrchestrationID]
+	if !ok {
 		return dberr.NotFound("orchestration with id %s not exist", orchestration.OrchestrationID)
 
 	}
+	// the type of the orchestration is not changed by the update
+	orchestration.Type = current.Type
 	s.orchestrations[orchestration.OrchestrationID] = orchestration
 
 	return nil
@@ -93,7 +108,32 @@ func (s *orchestration) getSortedByCreatedAt(orchestrations map[string]internal.
 		orchestrationsList = append(orchestrationsList, v)
 	}
 	sort.Slice(orchestrationsList, func(i, j int) bool {
-		return orchestrationsList[i].CreatedAt.Before(orchestrationsList[j].CreatedAt)
+		return orchestrationBefore(orchestrationsList[i], orchestrationsList[j])
 	})
 	return orchestrationsList
 }
+
+// orchestrationBefore orders the orchestrations by the creation time and the ID, the same as the cursor
+func orchestrationBefore(a, b internal.Orchestration) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+	return a.OrchestrationID < b.OrchestrationID
+}
+
+// Checkpoint returns the function which restores the content of the storage from the time of the call
+func (s *orchestration) Checkpoint() func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	orchestrations := make(map[string]internal.Orchestration, len(s.orchestrations))
+	for k, v := range s.orchestrations {
+		orchestrations[k] = v
+	}
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.orchestrations = orchestrations
+	}
+}
@@ -2,21 +2,25 @@ package memory
 
 import (
 	"sync"
+	"time"
 
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
 
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
 )
 
 type runtimeState struct {
 	mu sync.Mutex
 
 	runtimeStates map[string]internal.RuntimeState
+	operations    *operations
 }
 
-func NewRuntimeStates() *runtimeState {
+func NewRuntimeStates(operations *operations) *runtimeState {
 	return &runtimeState{
 		runtimeStates: make(map[string]internal.RuntimeState, 0),
+		operations:    operations,
 	}
 }
 
@@ -55,3 +59,80 @@ func (s *runtimeState) GetByOperationID(operationID string) (internal.RuntimeSta
 
 	return internal.RuntimeState{}, dberr.NotFound("runtime state with operation ID %s not found", operationID)
 }
+
+func (s *runtimeState) DeleteByOperationID(operationID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, rs := range s.runtimeStates {
+		if rs.OperationID == operationID {
+			delete(s.runtimeStates, id)
+		}
+	}
+
+	return nil
+}
+
+func (s *runtimeState) DeleteSupersededCreatedBefore(before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	newest := make(map[string]internal.RuntimeState)
+	newestKymaConfigured := make(map[string]internal.RuntimeState)
+	for _, rs := range s.runtimeStates {
+		if current, found := newest[rs.RuntimeID]; !found || rs.CreatedAt.After(current.CreatedAt) {
+			newest[rs.RuntimeID] = rs
+		}
+		if !s.kymaConfigured(rs.OperationID) {
+			continue
+		}
+		if current, found := newestKymaConfigured[rs.RuntimeID]; !found || rs.CreatedAt.After(current.CreatedAt) {
+			newestKymaConfigured[rs.RuntimeID] = rs
+		}
+	}
+
+	deleted := 0
+	for id, rs := range s.runtimeStates {
+		if !rs.CreatedAt.Before(before) || newest[rs.RuntimeID].ID == id {
+			continue
+		}
+		if kymaConfigured, found := newestKymaConfigured[rs.RuntimeID]; found && kymaConfigured.ID == id {
+			continue
+		}
+		delete(s.runtimeStates, id)
+		deleted++
+	}
+
+	return deleted, nil
+}
+
+// kymaConfigured returns true if the operation is the succeeded provisioning or Kyma upgrade
+func (s *runtimeState) kymaConfigured(operationID string) bool {
+	s.operations.mu.RLock()
+	defer s.operations.mu.RUnlock()
+
+	if op, found := s.operations.provisioningOperations[operationID]; found {
+		return op.State == domain.Succeeded
+	}
+	if op, found := s.operations.upgradeKymaOperations[operationID]; found {
+		return op.State == domain.Succeeded
+	}
+	return false
+}
+
+// Checkpoint returns the function which restores the content of the storage from the time of the call
+func (s *runtimeState) Checkpoint() func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	runtimeStates := make(map[string]internal.RuntimeState, len(s.runtimeStates))
+	for k, v := range s.runtimeStates {
+		runtimeStates[k] = v
+	}
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.runtimeStates = runtimeStates
+	}
+}
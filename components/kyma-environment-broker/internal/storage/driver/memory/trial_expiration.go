@@ -0,0 +1,52 @@
+package memory
+
+import (
+	"sync"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+)
+
+type trialExpirations struct {
+	mu sync.Mutex
+
+	data map[string]internal.TrialExpiration
+}
+
+func NewTrialExpirations() *trialExpirations {
+	return &trialExpirations{
+		data: make(map[string]internal.TrialExpiration, 0),
+	}
+}
+
+func (s *trialExpirations) GetExpiration(instanceID string) (internal.TrialExpiration, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expiration, exists := s.data[instanceID]
+	return expiration, exists, nil
+}
+
+func (s *trialExpirations) UpsertExpiration(expiration internal.TrialExpiration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data[expiration.InstanceID] = expiration
+	return nil
+}
+
+// Checkpoint returns the function which restores the content of the storage from the time of the call
+func (s *trialExpirations) Checkpoint() func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data := make(map[string]internal.TrialExpiration, len(s.data))
+	for k, v := range s.data {
+		data[k] = v
+	}
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.data = data
+	}
+}
@@ -0,0 +1,52 @@
+package postsql
+
+import (
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession/dbmodel"
+)
+
+type freeTierUsage struct {
+	dbsession.Factory
+}
+
+func NewFreeTierUsage(sess dbsession.Factory) *freeTierUsage {
+	return &freeTierUsage{
+		Factory: sess,
+	}
+}
+
+func (s *freeTierUsage) Insert(entry internal.FreeTierUsageEntry) error {
+	return s.NewWriteSession().InsertFreeTierUsage(dbmodel.FreeTierUsageDTO{
+		InstanceID:      entry.InstanceID,
+		GlobalAccountID: entry.GlobalAccountID,
+		PlanID:          entry.PlanID,
+		StartedAt:       entry.StartedAt,
+		FinishedAt:      entry.FinishedAt,
+	})
+}
+
+func (s *freeTierUsage) Finish(instanceID string, finishedAt time.Time) error {
+	return s.NewWriteSession().FinishFreeTierUsage(instanceID, finishedAt)
+}
+
+func (s *freeTierUsage) ListByGlobalAccountID(globalAccountID string) ([]internal.FreeTierUsageEntry, error) {
+	dtos, err := s.NewReadSession().ListFreeTierUsageByGlobalAccountID(globalAccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]internal.FreeTierUsageEntry, 0, len(dtos))
+	for _, dto := range dtos {
+		entries = append(entries, internal.FreeTierUsageEntry{
+			InstanceID:      dto.InstanceID,
+			GlobalAccountID: dto.GlobalAccountID,
+			PlanID:          dto.PlanID,
+			StartedAt:       dto.StartedAt,
+			FinishedAt:      dto.FinishedAt,
+		})
+	}
+	return entries, nil
+}
@@ -0,0 +1,74 @@
+package postsql
+
+import (
+	"encoding/json"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession/dbmodel"
+
+	"github.com/pkg/errors"
+)
+
+// installerOverridesData is stored as JSON in the data column
+type installerOverridesData struct {
+	Components         map[string]bool              `json:"components,omitempty"`
+	ComponentOverrides map[string]map[string]string `json:"componentOverrides,omitempty"`
+	GlobalOverrides    map[string]string            `json:"globalOverrides,omitempty"`
+}
+
+type installerOverrides struct {
+	dbsession.Factory
+}
+
+func NewInstallerOverrides(sess dbsession.Factory) *installerOverrides {
+	return &installerOverrides{
+		Factory: sess,
+	}
+}
+
+func (s *installerOverrides) GetOverrides(globalAccountID string) (internal.InstallerOverrides, bool, error) {
+	dto, dbErr := s.NewReadSession().GetInstallerOverrides(globalAccountID)
+	switch {
+	case dbErr == nil:
+	case dbErr.Code() == dberr.CodeNotFound:
+		return internal.InstallerOverrides{}, false, nil
+	default:
+		return internal.InstallerOverrides{}, false, dbErr
+	}
+
+	var data installerOverridesData
+	if err := json.Unmarshal([]byte(dto.Data), &data); err != nil {
+		return internal.InstallerOverrides{}, false, errors.Wrapf(err, "while unmarshalling installer overrides of global account %s", globalAccountID)
+	}
+
+	return internal.InstallerOverrides{
+		GlobalAccountID:    dto.GlobalAccountID,
+		Components:         data.Components,
+		ComponentOverrides: data.ComponentOverrides,
+		GlobalOverrides:    data.GlobalOverrides,
+		UpdatedAt:          dto.UpdatedAt,
+	}, true, nil
+}
+
+func (s *installerOverrides) UpsertOverrides(overrides internal.InstallerOverrides) error {
+	data, err := json.Marshal(installerOverridesData{
+		Components:         overrides.Components,
+		ComponentOverrides: overrides.ComponentOverrides,
+		GlobalOverrides:    overrides.GlobalOverrides,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "while marshalling installer overrides of global account %s", overrides.GlobalAccountID)
+	}
+
+	return s.NewWriteSession().UpsertInstallerOverrides(dbmodel.InstallerOverridesDTO{
+		GlobalAccountID: overrides.GlobalAccountID,
+		Data:            string(data),
+		UpdatedAt:       overrides.UpdatedAt,
+	})
+}
+
+func (s *installerOverrides) DeleteOverrides(globalAccountID string) error {
+	return s.NewWriteSession().DeleteInstallerOverrides(globalAccountID)
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession/dbmodel"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/predicate"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -126,6 +127,10 @@ func (s *Instance) Insert(instance internal.Instance) error {
 	sess := s.NewWriteSession()
 	return wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
 		err := sess.InsertInstance(instance)
+		if dberr.IsAlreadyExists(err) {
+			// the violated unique constraint is not a temporary failure, so the insert is not retried
+			return false, err
+		}
 		if err != nil {
 			log.Warn(errors.Wrapf(err, "while saving instance ID %s", instance.InstanceID).Error())
 			return false, nil
@@ -178,3 +183,36 @@ func (s *Instance) GetInstanceStats() (internal.InstanceStats, error) {
 func (s *Instance) List(filter dbmodel.InstanceFilter) ([]internal.Instance, int, int, error) {
 	return s.NewReadSession().ListInstances(filter)
 }
+
+func (s *Instance) ListWithState(filter dbmodel.InstanceFilter) ([]internal.InstanceWithState, int, int, error) {
+	dtos, count, totalCount, err := s.NewReadSession().ListInstancesWithState(filter)
+	if err != nil {
+		return nil, -1, -1, err
+	}
+
+	instances := make([]internal.InstanceWithState, 0, len(dtos))
+	for _, dto := range dtos {
+		instances = append(instances, toInstanceWithState(dto))
+	}
+	return instances, count, totalCount, nil
+}
+
+func toInstanceWithState(dto dbmodel.InstanceWithStateDTO) internal.InstanceWithState {
+	instance := internal.InstanceWithState{Instance: dto.Instance}
+	if !dto.LastOperationID.Valid {
+		return instance
+	}
+
+	instance.LastOperationType = dto.LastOperationType.String
+	instance.LastOperation = &internal.Operation{
+		ID:              dto.LastOperationID.String,
+		Version:         int(dto.LastOperationVersion.Int64),
+		CreatedAt:       dto.LastOperationCreatedAt.Time,
+		UpdatedAt:       dto.LastOperationUpdatedAt.Time,
+		InstanceID:      dto.Instance.InstanceID,
+		State:           domain.LastOperationState(dto.LastOperationState.String),
+		Description:     dto.LastOperationDescription.String,
+		OrchestrationID: dto.LastOperationOrchestrationID.String,
+	}
+	return instance
+}
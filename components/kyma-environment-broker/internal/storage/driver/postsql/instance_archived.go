@@ -0,0 +1,81 @@
+package postsql
+
+import (
+	"encoding/json"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession/dbmodel"
+
+	"github.com/pkg/errors"
+)
+
+type instancesArchived struct {
+	dbsession.Factory
+}
+
+func NewInstancesArchived(sess dbsession.Factory) *instancesArchived {
+	return &instancesArchived{
+		Factory: sess,
+	}
+}
+
+func (s *instancesArchived) Insert(archived internal.ArchivedInstance) error {
+	operations, err := json.Marshal(archived.Operations)
+	if err != nil {
+		return errors.Wrapf(err, "while marshalling operations of the archived instance %s", archived.InstanceID)
+	}
+
+	dbErr := s.NewWriteSession().InsertArchivedInstance(dbmodel.InstanceArchivedDTO{
+		Instance:   archived.Instance,
+		Operations: string(operations),
+		ArchivedAt: archived.ArchivedAt,
+	})
+	if dbErr != nil {
+		return dbErr
+	}
+	return nil
+}
+
+func (s *instancesArchived) GetByID(instanceID string) (*internal.ArchivedInstance, error) {
+	dto, dbErr := s.NewReadSession().GetArchivedInstanceByID(instanceID)
+	if dbErr != nil {
+		return nil, dbErr
+	}
+
+	archived, err := toArchivedInstance(dto)
+	if err != nil {
+		return nil, err
+	}
+	return &archived, nil
+}
+
+func (s *instancesArchived) List(filter dbmodel.InstanceFilter) ([]internal.ArchivedInstance, int, int, error) {
+	dtos, count, totalCount, err := s.NewReadSession().ListArchivedInstances(filter)
+	if err != nil {
+		return nil, -1, -1, err
+	}
+
+	result := make([]internal.ArchivedInstance, 0, len(dtos))
+	for _, dto := range dtos {
+		archived, err := toArchivedInstance(dto)
+		if err != nil {
+			return nil, -1, -1, err
+		}
+		result = append(result, archived)
+	}
+	return result, count, totalCount, nil
+}
+
+func toArchivedInstance(dto dbmodel.InstanceArchivedDTO) (internal.ArchivedInstance, error) {
+	var operations []internal.ArchivedOperation
+	if err := json.Unmarshal([]byte(dto.Operations), &operations); err != nil {
+		return internal.ArchivedInstance{}, errors.Wrapf(err, "while unmarshalling operations of the archived instance %s", dto.InstanceID)
+	}
+
+	return internal.ArchivedInstance{
+		Instance:   dto.Instance,
+		Operations: operations,
+		ArchivedAt: dto.ArchivedAt,
+	}, nil
+}
@@ -0,0 +1,88 @@
+package postsql
+
+import (
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession/dbmodel"
+)
+
+type jobs struct {
+	dbsession.Factory
+}
+
+func NewJobs(sess dbsession.Factory) *jobs {
+	return &jobs{
+		Factory: sess,
+	}
+}
+
+func (s *jobs) AcquireLock(jobName, owner string, now, until time.Time) (bool, error) {
+	acquired, err := s.NewWriteSession().AcquireJobLock(dbmodel.JobLockDTO{
+		JobName:     jobName,
+		Owner:       owner,
+		LockedUntil: until,
+	}, now)
+	if err != nil {
+		return false, err
+	}
+	return acquired, nil
+}
+
+func (s *jobs) ReleaseLock(jobName, owner string) error {
+	return s.NewWriteSession().ReleaseJobLock(jobName, owner)
+}
+
+func (s *jobs) InsertRun(run internal.JobRun) error {
+	return s.NewWriteSession().InsertJobRun(jobRunToDTO(run))
+}
+
+func (s *jobs) UpdateRun(run internal.JobRun) error {
+	return s.NewWriteSession().UpdateJobRun(jobRunToDTO(run))
+}
+
+func (s *jobs) ListRuns(jobName string, limit int) ([]internal.JobRun, error) {
+	dtos, err := s.NewReadSession().ListJobRuns(jobName, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	runs := make([]internal.JobRun, 0, len(dtos))
+	for _, dto := range dtos {
+		runs = append(runs, internal.JobRun{
+			ID:         dto.ID,
+			JobName:    dto.JobName,
+			Owner:      dto.Owner,
+			Trigger:    dto.Trigger,
+			State:      dto.State,
+			Error:      dto.Error,
+			Details:    dto.Details,
+			StartedAt:  dto.StartedAt,
+			FinishedAt: dto.FinishedAt,
+		})
+	}
+	return runs, nil
+}
+
+func (s *jobs) DeleteRunsStartedBefore(before time.Time) (int, error) {
+	deleted, err := s.NewWriteSession().DeleteJobRunsStartedBefore(before)
+	if err != nil {
+		return 0, err
+	}
+	return deleted, nil
+}
+
+func jobRunToDTO(run internal.JobRun) dbmodel.JobRunDTO {
+	return dbmodel.JobRunDTO{
+		ID:         run.ID,
+		JobName:    run.JobName,
+		Owner:      run.Owner,
+		Trigger:    run.Trigger,
+		State:      run.State,
+		Error:      run.Error,
+		Details:    run.Details,
+		StartedAt:  run.StartedAt,
+		FinishedAt: run.FinishedAt,
+	}
+}
@@ -0,0 +1,61 @@
+package postsql
+
+import (
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession/dbmodel"
+)
+
+type killSwitches struct {
+	dbsession.Factory
+}
+
+func NewKillSwitches(sess dbsession.Factory) *killSwitches {
+	return &killSwitches{
+		Factory: sess,
+	}
+}
+
+func (s *killSwitches) InsertEvent(event internal.KillSwitchEvent) error {
+	return s.NewWriteSession().InsertKillSwitchEvent(dbmodel.KillSwitchEventDTO{
+		ID:        event.ID,
+		Scope:     event.Scope,
+		Name:      event.Name,
+		Disabled:  event.Disabled,
+		Message:   event.Message,
+		ChangedBy: event.User,
+		CreatedAt: event.CreatedAt,
+	})
+}
+
+func (s *killSwitches) ListEvents() ([]internal.KillSwitchEvent, error) {
+	dtos, err := s.NewReadSession().ListKillSwitchEvents()
+	if err != nil {
+		return nil, err
+	}
+	return toKillSwitchEvents(dtos), nil
+}
+
+func (s *killSwitches) ListLatestEvents() ([]internal.KillSwitchEvent, error) {
+	dtos, err := s.NewReadSession().ListLatestKillSwitchEvents()
+	if err != nil {
+		return nil, err
+	}
+	return toKillSwitchEvents(dtos), nil
+}
+
+func toKillSwitchEvents(dtos []dbmodel.KillSwitchEventDTO) []internal.KillSwitchEvent {
+	events := make([]internal.KillSwitchEvent, 0, len(dtos))
+	for _, dto := range dtos {
+		events = append(events, internal.KillSwitchEvent{
+			ID:        dto.ID,
+			Scope:     dto.Scope,
+			Name:      dto.Name,
+			Disabled:  dto.Disabled,
+			Message:   dto.Message,
+			User:      dto.ChangedBy,
+			CreatedAt: dto.CreatedAt,
+		})
+	}
+	return events
+}
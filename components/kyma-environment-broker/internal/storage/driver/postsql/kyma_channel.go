@@ -0,0 +1,43 @@
+package postsql
+
+import (
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession/dbmodel"
+)
+
+type kymaChannels struct {
+	dbsession.Factory
+}
+
+func NewKymaChannels(sess dbsession.Factory) *kymaChannels {
+	return &kymaChannels{
+		Factory: sess,
+	}
+}
+
+func (s *kymaChannels) GetSubscription(globalAccountID string) (internal.KymaChannelSubscription, bool, error) {
+	dto, err := s.NewReadSession().GetKymaChannelSubscription(globalAccountID)
+
+	switch {
+	case err == nil:
+		return internal.KymaChannelSubscription{
+			GlobalAccountID: dto.GlobalAccountID,
+			Channel:         dto.Channel,
+			UpdatedAt:       dto.UpdatedAt,
+		}, true, nil
+	case err.Code() == dberr.CodeNotFound:
+		return internal.KymaChannelSubscription{}, false, nil
+	default:
+		return internal.KymaChannelSubscription{}, false, err
+	}
+}
+
+func (s *kymaChannels) UpsertSubscription(subscription internal.KymaChannelSubscription) error {
+	return s.NewWriteSession().UpsertKymaChannelSubscription(dbmodel.KymaChannelSubscriptionDTO{
+		GlobalAccountID: subscription.GlobalAccountID,
+		Channel:         subscription.Channel,
+		UpdatedAt:       subscription.UpdatedAt,
+	})
+}
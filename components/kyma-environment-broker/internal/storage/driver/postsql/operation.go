@@ -1,6 +1,7 @@
 package postsql
 
 import (
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"time"
@@ -134,20 +135,651 @@ func (s *operations) UpdateProvisioningOperation(op internal.ProvisioningOperati
 	return &op, lastErr
 }
 
+// ListProvisioningOperations lists the ProvisioningOperations matching the given filter, sorted by creation time
+func (s *operations) ListProvisioningOperations(filter dbmodel.OperationFilter, pageSize, page int) ([]internal.ProvisioningOperation, int, int, error) {
+	session := s.NewReadSession()
+	var (
+		operations        = make([]dbmodel.OperationDTO, 0)
+		lastErr           error
+		count, totalCount int
+	)
+	err := wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
+		operations, count, totalCount, lastErr = session.ListOperationsByType(dbmodel.OperationTypeProvision, filter, pageSize, page)
+		if lastErr != nil {
+			log.Errorf("while reading Operations from the storage: %v", lastErr)
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, -1, -1, errors.Wrapf(err, "while listing provisioning operations: %v", lastErr)
+	}
+
+	ret := make([]internal.ProvisioningOperation, 0, len(operations))
+	for i := range operations {
+		op, err := toProvisioningOperation(&operations[i])
+		if err != nil {
+			return nil, -1, -1, errors.Wrap(err, "while converting DTO to Operation")
+		}
+		ret = append(ret, *op)
+	}
+
+	return ret, count, totalCount, nil
+}
+
 // InsertDeprovisioningOperation insert new DeprovisioningOperation to storage
 func (s *operations) InsertDeprovisioningOperation(operation internal.DeprovisioningOperation) error {
 	session := s.NewWriteSession()
 
 	dto, err := deprovisioningOperationToDTO(&operation)
 	if err != nil {
-		return errors.Wrapf(err, "while converting Operation to DTO")
+		return errors.Wrapf(err, "while converting Operation to DTO")
+	}
+
+	var lastErr error
+	_ = wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
+		lastErr = session.InsertOperation(dto)
+		if lastErr != nil {
+			log.Warn(errors.Wrap(err, "while insert operation"))
+			return false, nil
+		}
+		return true, nil
+	})
+	return lastErr
+}
+
+// GetDeprovisioningOperationByID fetches the DeprovisioningOperation by given ID, returns error if not found
+func (s *operations) GetDeprovisioningOperationByID(operationID string) (*internal.DeprovisioningOperation, error) {
+	session := s.NewReadSession()
+	operation := dbmodel.OperationDTO{}
+	var lastErr error
+	err := wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
+		operation, lastErr = session.GetOperationByID(operationID)
+		if lastErr != nil {
+			if dberr.IsNotFound(lastErr) {
+				lastErr = dberr.NotFound("Operation with id %s not exist", operationID)
+				return false, lastErr
+			}
+			log.Warn(errors.Wrapf(lastErr, "while reading Operation from the storage"))
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "while getting operation by ID")
+	}
+	ret, err := toDeprovisioningOperation(&operation)
+	if err != nil {
+		return nil, errors.Wrapf(err, "while converting DTO to Operation")
+	}
+
+	return ret, nil
+}
+
+// GetDeprovisioningOperationByInstanceID fetches the DeprovisioningOperation by given instanceID, returns error if not found
+func (s *operations) GetDeprovisioningOperationByInstanceID(instanceID string) (*internal.DeprovisioningOperation, error) {
+	session := s.NewReadSession()
+	operation := dbmodel.OperationDTO{}
+	var lastErr dberr.Error
+	err := wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
+		operation, lastErr = session.GetOperationByTypeAndInstanceID(instanceID, dbmodel.OperationTypeDeprovision)
+		if lastErr != nil {
+			if dberr.IsNotFound(lastErr) {
+				lastErr = dberr.NotFound("operation does not exist")
+				return false, lastErr
+			}
+			log.Warn(errors.Wrapf(lastErr, "while reading Operation from the storage").Error())
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, lastErr
+	}
+	ret, err := toDeprovisioningOperation(&operation)
+	if err != nil {
+		return nil, errors.Wrapf(err, "while converting DTO to Operation")
+	}
+
+	return ret, nil
+}
+
+// UpdateDeprovisioningOperation updates DeprovisioningOperation, fails if not exists or optimistic locking failure occurs.
+func (s *operations) UpdateDeprovisioningOperation(operation internal.DeprovisioningOperation) (*internal.DeprovisioningOperation, error) {
+	session := s.NewWriteSession()
+	operation.UpdatedAt = time.Now()
+
+	dto, err := deprovisioningOperationToDTO(&operation)
+	if err != nil {
+		return nil, errors.Wrapf(err, "while converting Operation to DTO")
+	}
+
+	var lastErr error
+	_ = wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
+		lastErr = session.UpdateOperation(dto)
+		if lastErr != nil && dberr.IsNotFound(lastErr) {
+			_, lastErr = s.NewReadSession().GetOperationByID(operation.ID)
+			if lastErr != nil {
+				log.Warn(errors.Wrapf(lastErr, "while getting Operation").Error())
+				return false, nil
+			}
+
+			// the operation exists but the version is different
+			lastErr = dberr.Conflict("operation update conflict, operation ID: %s", operation.ID)
+			log.Warn(lastErr.Error())
+			return false, lastErr
+		}
+		return true, nil
+	})
+	operation.Version = operation.Version + 1
+	return &operation, lastErr
+}
+
+// InsertUpgradeKymaOperation insert new UpgradeKymaOperation to storage
+func (s *operations) InsertUpgradeKymaOperation(operation internal.UpgradeKymaOperation) error {
+	session := s.NewWriteSession()
+	dto, err := upgradeKymaOperationToDTO(&operation)
+	if err != nil {
+		return errors.Wrapf(err, "while inserting upgrade kyma operation (id: %s)", operation.ID)
+	}
+	var lastErr error
+	_ = wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
+		lastErr = session.InsertOperation(dto)
+		if lastErr != nil {
+			log.Warn(errors.Wrap(err, "while insert operation"))
+			return false, nil
+		}
+
+		//todo - insert link to orchestration
+		return true, nil
+	})
+	return lastErr
+}
+
+// GetUpgradeKymaOperationByID fetches the UpgradeKymaOperation by given ID, returns error if not found
+func (s *operations) GetUpgradeKymaOperationByID(operationID string) (*internal.UpgradeKymaOperation, error) {
+	session := s.NewReadSession()
+	operation := dbmodel.OperationDTO{}
+	var lastErr error
+	err := wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
+		operation, lastErr = session.GetOperationByID(operationID)
+		if lastErr != nil {
+			if dberr.IsNotFound(lastErr) {
+				lastErr = dberr.NotFound("Operation with id %s not exist", operationID)
+				return false, lastErr
+			}
+			log.Warn(errors.Wrapf(lastErr, "while reading Operation from the storage"))
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "while getting operation by ID")
+	}
+	ret, err := toUpgradeKymaOperation(&operation)
+	if err != nil {
+		return nil, errors.Wrapf(err, "while converting DTO to Operation")
+	}
+
+	return ret, nil
+}
+
+// GetUpgradeKymaOperationByInstanceID fetches the UpgradeKymaOperation by given instanceID, returns error if not found
+func (s *operations) GetUpgradeKymaOperationByInstanceID(instanceID string) (*internal.UpgradeKymaOperation, error) {
+	session := s.NewReadSession()
+	operation := dbmodel.OperationDTO{}
+	var lastErr dberr.Error
+	err := wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
+		operation, lastErr = session.GetOperationByTypeAndInstanceID(instanceID, dbmodel.OperationTypeUpgradeKyma)
+		if lastErr != nil {
+			if dberr.IsNotFound(lastErr) {
+				lastErr = dberr.NotFound("operation does not exist")
+				return false, lastErr
+			}
+			log.Warn(errors.Wrapf(lastErr, "while reading Operation from the storage").Error())
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, lastErr
+	}
+	ret, err := toUpgradeKymaOperation(&operation)
+	if err != nil {
+		return nil, errors.Wrapf(err, "while converting DTO to Operation")
+	}
+
+	return ret, nil
+}
+
+func (s *operations) ListUpgradeKymaOperationsByInstanceID(instanceID string) ([]internal.UpgradeKymaOperation, error) {
+	session := s.NewReadSession()
+	operations := []dbmodel.OperationDTO{}
+	var lastErr dberr.Error
+	err := wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
+		operations, lastErr = session.GetOperationsByTypeAndInstanceID(instanceID, dbmodel.OperationTypeUpgradeKyma)
+		if lastErr != nil {
+			log.Warn(errors.Wrapf(lastErr, "while reading Operation from the storage").Error())
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, lastErr
+	}
+	ret, err := toUpgradeKymaOperationList(operations)
+	if err != nil {
+		return nil, errors.Wrapf(err, "while converting DTO to Operation")
+	}
+
+	return ret, nil
+}
+
+func (s *operations) ListInstanceIDsUpgradedSince(since time.Time) ([]string, error) {
+	session := s.NewReadSession()
+	var (
+		instanceIDs []string
+		lastErr     dberr.Error
+	)
+	err := wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
+		instanceIDs, lastErr = session.ListInstanceIDsWithSucceededOperationsSince(dbmodel.OperationTypeUpgradeKyma, since)
+		if lastErr != nil {
+			log.Warn(errors.Wrapf(lastErr, "while reading upgraded instances from the storage").Error())
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, lastErr
+	}
+
+	return instanceIDs, nil
+}
+
+// UpdateUpgradeKymaOperation updates UpgradeKymaOperation, fails if not exists or optimistic locking failure occurs.
+func (s *operations) UpdateUpgradeKymaOperation(operation internal.UpgradeKymaOperation) (*internal.UpgradeKymaOperation, error) {
+	session := s.NewWriteSession()
+	operation.UpdatedAt = time.Now()
+	dto, err := upgradeKymaOperationToDTO(&operation)
+	if err != nil {
+		return nil, errors.Wrapf(err, "while converting Operation to DTO")
+	}
+
+	var lastErr error
+	_ = wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
+		lastErr = session.UpdateOperation(dto)
+		if lastErr != nil && dberr.IsNotFound(lastErr) {
+			_, lastErr = s.NewReadSession().GetOperationByID(operation.ID)
+			if lastErr != nil {
+				log.Warn(errors.Wrapf(lastErr, "while getting Operation").Error())
+				return false, nil
+			}
+
+			// the operation exists but the version is different
+			lastErr = dberr.Conflict("operation update conflict, operation ID: %s", operation.ID)
+			log.Warn(lastErr.Error())
+			return false, lastErr
+		}
+		return true, nil
+	})
+	operation.Version = operation.Version + 1
+	return &operation, lastErr
+}
+
+// InsertUpgradeClusterOperation insert new UpgradeClusterOperation to storage
+func (s *operations) InsertUpgradeClusterOperation(operation internal.UpgradeClusterOperation) error {
+	session := s.NewWriteSession()
+	dto, err := upgradeClusterOperationToDTO(&operation)
+	if err != nil {
+		return errors.Wrapf(err, "while inserting upgrade cluster operation (id: %s)", operation.ID)
+	}
+	var lastErr error
+	_ = wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
+		lastErr = session.InsertOperation(dto)
+		if lastErr != nil {
+			log.Warn(errors.Wrap(lastErr, "while insert operation"))
+			return false, nil
+		}
+		return true, nil
+	})
+	return lastErr
+}
+
+// GetUpgradeClusterOperationByID fetches the UpgradeClusterOperation by given ID, returns error if not found
+func (s *operations) GetUpgradeClusterOperationByID(operationID string) (*internal.UpgradeClusterOperation, error) {
+	session := s.NewReadSession()
+	operation := dbmodel.OperationDTO{}
+	var lastErr error
+	err := wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
+		operation, lastErr = session.GetOperationByID(operationID)
+		if lastErr != nil {
+			if dberr.IsNotFound(lastErr) {
+				lastErr = dberr.NotFound("Operation with id %s not exist", operationID)
+				return false, lastErr
+			}
+			log.Warn(errors.Wrapf(lastErr, "while reading Operation from the storage"))
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "while getting operation by ID")
+	}
+	ret, err := toUpgradeClusterOperation(&operation)
+	if err != nil {
+		return nil, errors.Wrapf(err, "while converting DTO to Operation")
+	}
+
+	return ret, nil
+}
+
+// ListUpgradeClusterOperationsByInstanceID fetches all UpgradeClusterOperations for the given instanceID
+func (s *operations) ListUpgradeClusterOperationsByInstanceID(instanceID string) ([]internal.UpgradeClusterOperation, error) {
+	session := s.NewReadSession()
+	operations := []dbmodel.OperationDTO{}
+	var lastErr dberr.Error
+	err := wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
+		operations, lastErr = session.GetOperationsByTypeAndInstanceID(instanceID, dbmodel.OperationTypeUpgradeCluster)
+		if lastErr != nil {
+			log.Warn(errors.Wrapf(lastErr, "while reading Operation from the storage").Error())
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, lastErr
+	}
+	ret, err := toUpgradeClusterOperationList(operations)
+	if err != nil {
+		return nil, errors.Wrapf(err, "while converting DTO to Operation")
+	}
+
+	return ret, nil
+}
+
+// ListUpgradeClusterOperationsByOrchestrationID fetches a page of UpgradeClusterOperations triggered by the given orchestration
+func (s *operations) ListUpgradeClusterOperationsByOrchestrationID(orchestrationID string, pageSize int, page int) ([]internal.UpgradeClusterOperation, int, int, error) {
+	session := s.NewReadSession()
+	var (
+		operations        = make([]dbmodel.OperationDTO, 0)
+		lastErr           error
+		count, totalCount int
+	)
+	err := wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
+		operations, count, totalCount, lastErr = session.ListOperationsByOrchestrationID(orchestrationID, dbmodel.OperationFilter{}, pageSize, page)
+		if lastErr != nil {
+			if dberr.IsNotFound(lastErr) {
+				lastErr = dberr.NotFound("Operations for orchestration ID %s not exist", orchestrationID)
+				return false, lastErr
+			}
+			log.Errorf("while reading Operation from the storage: %v", lastErr)
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, -1, -1, errors.Wrapf(err, "while getting operations by orchestration ID: %v", lastErr)
+	}
+	ret, err := toUpgradeClusterOperationList(operations)
+	if err != nil {
+		return nil, -1, -1, errors.Wrapf(err, "while converting DTO to Operation")
+	}
+
+	return ret, count, totalCount, nil
+}
+
+// UpdateUpgradeClusterOperation updates UpgradeClusterOperation, fails if not exists or optimistic locking failure occurs.
+func (s *operations) UpdateUpgradeClusterOperation(operation internal.UpgradeClusterOperation) (*internal.UpgradeClusterOperation, error) {
+	session := s.NewWriteSession()
+	operation.UpdatedAt = time.Now()
+	dto, err := upgradeClusterOperationToDTO(&operation)
+	if err != nil {
+		return nil, errors.Wrapf(err, "while converting Operation to DTO")
+	}
+
+	var lastErr error
+	_ = wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
+		lastErr = session.UpdateOperation(dto)
+		if lastErr != nil && dberr.IsNotFound(lastErr) {
+			_, lastErr = s.NewReadSession().GetOperationByID(operation.ID)
+			if lastErr != nil {
+				log.Warn(errors.Wrapf(lastErr, "while getting Operation").Error())
+				return false, nil
+			}
+
+			// the operation exists but the version is different
+			lastErr = dberr.Conflict("operation update conflict, operation ID: %s", operation.ID)
+			log.Warn(lastErr.Error())
+			return false, lastErr
+		}
+		return true, nil
+	})
+	operation.Version = operation.Version + 1
+	return &operation, lastErr
+}
+
+// InsertPlanMigrationOperation insert new PlanMigrationOperation to storage
+func (s *operations) InsertPlanMigrationOperation(operation internal.PlanMigrationOperation) error {
+	session := s.NewWriteSession()
+	dto, err := planMigrationOperationToDTO(&operation)
+	if err != nil {
+		return errors.Wrapf(err, "while inserting plan migration operation (id: %s)", operation.ID)
+	}
+	var lastErr error
+	_ = wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
+		lastErr = session.InsertOperation(dto)
+		if lastErr != nil {
+			log.Warn(errors.Wrap(lastErr, "while insert operation"))
+			return false, nil
+		}
+		return true, nil
+	})
+	return lastErr
+}
+
+// GetPlanMigrationOperationByID fetches the PlanMigrationOperation by given ID, returns error if not found
+func (s *operations) GetPlanMigrationOperationByID(operationID string) (*internal.PlanMigrationOperation, error) {
+	session := s.NewReadSession()
+	operation := dbmodel.OperationDTO{}
+	var lastErr error
+	err := wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
+		operation, lastErr = session.GetOperationByID(operationID)
+		if lastErr != nil {
+			if dberr.IsNotFound(lastErr) {
+				lastErr = dberr.NotFound("Operation with id %s not exist", operationID)
+				return false, lastErr
+			}
+			log.Warn(errors.Wrapf(lastErr, "while reading Operation from the storage"))
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "while getting operation by ID")
+	}
+	ret, err := toPlanMigrationOperation(&operation)
+	if err != nil {
+		return nil, errors.Wrapf(err, "while converting DTO to Operation")
+	}
+
+	return ret, nil
+}
+
+// GetPlanMigrationOperationByInstanceID fetches the latest PlanMigrationOperation of the given instance, returns error if not found
+func (s *operations) GetPlanMigrationOperationByInstanceID(instanceID string) (*internal.PlanMigrationOperation, error) {
+	session := s.NewReadSession()
+	operation := dbmodel.OperationDTO{}
+	var lastErr dberr.Error
+	err := wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
+		operation, lastErr = session.GetOperationByTypeAndInstanceID(instanceID, dbmodel.OperationTypeMigratePlan)
+		if lastErr != nil {
+			if dberr.IsNotFound(lastErr) {
+				lastErr = dberr.NotFound("operation does not exist")
+				return false, lastErr
+			}
+			log.Warn(errors.Wrapf(lastErr, "while reading Operation from the storage").Error())
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, lastErr
+	}
+	ret, err := toPlanMigrationOperation(&operation)
+	if err != nil {
+		return nil, errors.Wrapf(err, "while converting DTO to Operation")
+	}
+
+	return ret, nil
+}
+
+// UpdatePlanMigrationOperation updates PlanMigrationOperation, fails if not exists or optimistic locking failure occurs.
+func (s *operations) UpdatePlanMigrationOperation(operation internal.PlanMigrationOperation) (*internal.PlanMigrationOperation, error) {
+	session := s.NewWriteSession()
+	operation.UpdatedAt = time.Now()
+	dto, err := planMigrationOperationToDTO(&operation)
+	if err != nil {
+		return nil, errors.Wrapf(err, "while converting Operation to DTO")
+	}
+
+	var lastErr error
+	_ = wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
+		lastErr = session.UpdateOperation(dto)
+		if lastErr != nil && dberr.IsNotFound(lastErr) {
+			_, lastErr = s.NewReadSession().GetOperationByID(operation.ID)
+			if lastErr != nil {
+				log.Warn(errors.Wrapf(lastErr, "while getting Operation").Error())
+				return false, nil
+			}
+
+			// the operation exists but the version is different
+			lastErr = dberr.Conflict("operation update conflict, operation ID: %s", operation.ID)
+			log.Warn(lastErr.Error())
+			return false, lastErr
+		}
+		return true, nil
+	})
+	operation.Version = operation.Version + 1
+	return &operation, lastErr
+}
+
+// InsertUpdatingOperation insert new UpdatingOperation to storage
+func (s *operations) InsertUpdatingOperation(operation internal.UpdatingOperation) error {
+	session := s.NewWriteSession()
+	dto, err := updatingOperationToDTO(&operation)
+	if err != nil {
+		return errors.Wrapf(err, "while inserting updating operation (id: %s)", operation.ID)
+	}
+	var lastErr error
+	_ = wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
+		lastErr = session.InsertOperation(dto)
+		if lastErr != nil {
+			log.Warn(errors.Wrap(lastErr, "while insert operation"))
+			return false, nil
+		}
+		return true, nil
+	})
+	return lastErr
+}
+
+// GetUpdatingOperationByID fetches the UpdatingOperation by given ID, returns error if not found
+func (s *operations) GetUpdatingOperationByID(operationID string) (*internal.UpdatingOperation, error) {
+	session := s.NewReadSession()
+	operation := dbmodel.OperationDTO{}
+	var lastErr error
+	err := wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
+		operation, lastErr = session.GetOperationByID(operationID)
+		if lastErr != nil {
+			if dberr.IsNotFound(lastErr) {
+				lastErr = dberr.NotFound("Operation with id %s not exist", operationID)
+				return false, lastErr
+			}
+			log.Warn(errors.Wrapf(lastErr, "while reading Operation from the storage"))
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "while getting operation by ID")
+	}
+	ret, err := toUpdatingOperation(&operation)
+	if err != nil {
+		return nil, errors.Wrapf(err, "while converting DTO to Operation")
+	}
+
+	return ret, nil
+}
+
+// GetUpdatingOperationByInstanceID fetches the latest UpdatingOperation of the given instance, returns error if not found
+func (s *operations) GetUpdatingOperationByInstanceID(instanceID string) (*internal.UpdatingOperation, error) {
+	session := s.NewReadSession()
+	operation := dbmodel.OperationDTO{}
+	var lastErr dberr.Error
+	err := wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
+		operation, lastErr = session.GetOperationByTypeAndInstanceID(instanceID, dbmodel.OperationTypeUpdate)
+		if lastErr != nil {
+			if dberr.IsNotFound(lastErr) {
+				lastErr = dberr.NotFound("operation does not exist")
+				return false, lastErr
+			}
+			log.Warn(errors.Wrapf(lastErr, "while reading Operation from the storage").Error())
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, lastErr
+	}
+	ret, err := toUpdatingOperation(&operation)
+	if err != nil {
+		return nil, errors.Wrapf(err, "while converting DTO to Operation")
+	}
+
+	return ret, nil
+}
+
+// UpdateUpdatingOperation updates UpdatingOperation, fails if not exists or optimistic locking failure occurs.
+func (s *operations) UpdateUpdatingOperation(operation internal.UpdatingOperation) (*internal.UpdatingOperation, error) {
+	session := s.NewWriteSession()
+	operation.UpdatedAt = time.Now()
+	dto, err := updatingOperationToDTO(&operation)
+	if err != nil {
+		return nil, errors.Wrapf(err, "while converting Operation to DTO")
 	}
 
+	var lastErr error
+	_ = wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
+		lastErr = session.UpdateOperation(dto)
+		if lastErr != nil && dberr.IsNotFound(lastErr) {
+			_, lastErr = s.NewReadSession().GetOperationByID(operation.ID)
+			if lastErr != nil {
+				log.Warn(errors.Wrapf(lastErr, "while getting Operation").Error())
+				return false, nil
+			}
+
+			// the operation exists but the version is different
+			lastErr = dberr.Conflict("operation update conflict, operation ID: %s", operation.ID)
+			log.Warn(lastErr.Error())
+			return false, lastErr
+		}
+		return true, nil
+	})
+	operation.Version = operation.Version + 1
+	return &operation, lastErr
+}
+
+// InsertSuspensionOperation insert new SuspensionOperation to storage
+func (s *operations) InsertSuspensionOperation(operation internal.SuspensionOperation) error {
+	session := s.NewWriteSession()
+	dto, err := suspensionOperationToDTO(&operation)
+	if err != nil {
+		return errors.Wrapf(err, "while inserting suspension operation (id: %s)", operation.ID)
+	}
 	var lastErr error
 	_ = wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
 		lastErr = session.InsertOperation(dto)
 		if lastErr != nil {
-			log.Warn(errors.Wrap(err, "while insert operation"))
+			log.Warn(errors.Wrap(lastErr, "while insert operation"))
 			return false, nil
 		}
 		return true, nil
@@ -155,8 +787,8 @@ func (s *operations) InsertDeprovisioningOperation(operation internal.Deprovisio
 	return lastErr
 }
 
-// GetDeprovisioningOperationByID fetches the DeprovisioningOperation by given ID, returns error if not found
-func (s *operations) GetDeprovisioningOperationByID(operationID string) (*internal.DeprovisioningOperation, error) {
+// GetSuspensionOperationByID fetches the SuspensionOperation by given ID, returns error if not found
+func (s *operations) GetSuspensionOperationByID(operationID string) (*internal.SuspensionOperation, error) {
 	session := s.NewReadSession()
 	operation := dbmodel.OperationDTO{}
 	var lastErr error
@@ -175,7 +807,7 @@ func (s *operations) GetDeprovisioningOperationByID(operationID string) (*intern
 	if err != nil {
 		return nil, errors.Wrap(err, "while getting operation by ID")
 	}
-	ret, err := toDeprovisioningOperation(&operation)
+	ret, err := toSuspensionOperation(&operation)
 	if err != nil {
 		return nil, errors.Wrapf(err, "while converting DTO to Operation")
 	}
@@ -183,13 +815,13 @@ func (s *operations) GetDeprovisioningOperationByID(operationID string) (*intern
 	return ret, nil
 }
 
-// GetDeprovisioningOperationByInstanceID fetches the DeprovisioningOperation by given instanceID, returns error if not found
-func (s *operations) GetDeprovisioningOperationByInstanceID(instanceID string) (*internal.DeprovisioningOperation, error) {
+// GetSuspensionOperationByInstanceID fetches the latest SuspensionOperation of the given instance, returns error if not found
+func (s *operations) GetSuspensionOperationByInstanceID(instanceID string) (*internal.SuspensionOperation, error) {
 	session := s.NewReadSession()
 	operation := dbmodel.OperationDTO{}
 	var lastErr dberr.Error
 	err := wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
-		operation, lastErr = session.GetOperationByTypeAndInstanceID(instanceID, dbmodel.OperationTypeDeprovision)
+		operation, lastErr = session.GetOperationByTypeAndInstanceID(instanceID, dbmodel.OperationTypeSuspension)
 		if lastErr != nil {
 			if dberr.IsNotFound(lastErr) {
 				lastErr = dberr.NotFound("operation does not exist")
@@ -203,7 +835,7 @@ func (s *operations) GetDeprovisioningOperationByInstanceID(instanceID string) (
 	if err != nil {
 		return nil, lastErr
 	}
-	ret, err := toDeprovisioningOperation(&operation)
+	ret, err := toSuspensionOperation(&operation)
 	if err != nil {
 		return nil, errors.Wrapf(err, "while converting DTO to Operation")
 	}
@@ -211,12 +843,11 @@ func (s *operations) GetDeprovisioningOperationByInstanceID(instanceID string) (
 	return ret, nil
 }
 
-// UpdateDeprovisioningOperation updates DeprovisioningOperation, fails if not exists or optimistic locking failure occurs.
-func (s *operations) UpdateDeprovisioningOperation(operation internal.DeprovisioningOperation) (*internal.DeprovisioningOperation, error) {
+// UpdateSuspensionOperation updates SuspensionOperation, fails if not exists or optimistic locking failure occurs.
+func (s *operations) UpdateSuspensionOperation(operation internal.SuspensionOperation) (*internal.SuspensionOperation, error) {
 	session := s.NewWriteSession()
 	operation.UpdatedAt = time.Now()
-
-	dto, err := deprovisioningOperationToDTO(&operation)
+	dto, err := suspensionOperationToDTO(&operation)
 	if err != nil {
 		return nil, errors.Wrapf(err, "while converting Operation to DTO")
 	}
@@ -242,29 +873,27 @@ func (s *operations) UpdateDeprovisioningOperation(operation internal.Deprovisio
 	return &operation, lastErr
 }
 
-// InsertUpgradeKymaOperation insert new UpgradeKymaOperation to storage
-func (s *operations) InsertUpgradeKymaOperation(operation internal.UpgradeKymaOperation) error {
+// InsertAccountMigrationOperation insert new AccountMigrationOperation to storage
+func (s *operations) InsertAccountMigrationOperation(operation internal.AccountMigrationOperation) error {
 	session := s.NewWriteSession()
-	dto, err := upgradeKymaOperationToDTO(&operation)
+	dto, err := accountMigrationOperationToDTO(&operation)
 	if err != nil {
-		return errors.Wrapf(err, "while inserting upgrade kyma operation (id: %s)", operation.ID)
+		return errors.Wrapf(err, "while inserting account migration operation (id: %s)", operation.ID)
 	}
 	var lastErr error
 	_ = wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
 		lastErr = session.InsertOperation(dto)
 		if lastErr != nil {
-			log.Warn(errors.Wrap(err, "while insert operation"))
+			log.Warn(errors.Wrap(lastErr, "while insert operation"))
 			return false, nil
 		}
-
-		//todo - insert link to orchestration
 		return true, nil
 	})
 	return lastErr
 }
 
-// GetUpgradeKymaOperationByID fetches the UpgradeKymaOperation by given ID, returns error if not found
-func (s *operations) GetUpgradeKymaOperationByID(operationID string) (*internal.UpgradeKymaOperation, error) {
+// GetAccountMigrationOperationByID fetches the AccountMigrationOperation by given ID, returns error if not found
+func (s *operations) GetAccountMigrationOperationByID(operationID string) (*internal.AccountMigrationOperation, error) {
 	session := s.NewReadSession()
 	operation := dbmodel.OperationDTO{}
 	var lastErr error
@@ -283,7 +912,7 @@ func (s *operations) GetUpgradeKymaOperationByID(operationID string) (*internal.
 	if err != nil {
 		return nil, errors.Wrap(err, "while getting operation by ID")
 	}
-	ret, err := toUpgradeKymaOperation(&operation)
+	ret, err := toAccountMigrationOperation(&operation)
 	if err != nil {
 		return nil, errors.Wrapf(err, "while converting DTO to Operation")
 	}
@@ -291,13 +920,13 @@ func (s *operations) GetUpgradeKymaOperationByID(operationID string) (*internal.
 	return ret, nil
 }
 
-// GetUpgradeKymaOperationByInstanceID fetches the UpgradeKymaOperation by given instanceID, returns error if not found
-func (s *operations) GetUpgradeKymaOperationByInstanceID(instanceID string) (*internal.UpgradeKymaOperation, error) {
+// GetAccountMigrationOperationByInstanceID fetches the latest AccountMigrationOperation of the given instance, returns error if not found
+func (s *operations) GetAccountMigrationOperationByInstanceID(instanceID string) (*internal.AccountMigrationOperation, error) {
 	session := s.NewReadSession()
 	operation := dbmodel.OperationDTO{}
 	var lastErr dberr.Error
 	err := wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
-		operation, lastErr = session.GetOperationByTypeAndInstanceID(instanceID, dbmodel.OperationTypeUpgradeKyma)
+		operation, lastErr = session.GetOperationByTypeAndInstanceID(instanceID, dbmodel.OperationTypeAccountMigration)
 		if lastErr != nil {
 			if dberr.IsNotFound(lastErr) {
 				lastErr = dberr.NotFound("operation does not exist")
@@ -311,30 +940,7 @@ func (s *operations) GetUpgradeKymaOperationByInstanceID(instanceID string) (*in
 	if err != nil {
 		return nil, lastErr
 	}
-	ret, err := toUpgradeKymaOperation(&operation)
-	if err != nil {
-		return nil, errors.Wrapf(err, "while converting DTO to Operation")
-	}
-
-	return ret, nil
-}
-
-func (s *operations) ListUpgradeKymaOperationsByInstanceID(instanceID string) ([]internal.UpgradeKymaOperation, error) {
-	session := s.NewReadSession()
-	operations := []dbmodel.OperationDTO{}
-	var lastErr dberr.Error
-	err := wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
-		operations, lastErr = session.GetOperationsByTypeAndInstanceID(instanceID, dbmodel.OperationTypeUpgradeKyma)
-		if lastErr != nil {
-			log.Warn(errors.Wrapf(lastErr, "while reading Operation from the storage").Error())
-			return false, nil
-		}
-		return true, nil
-	})
-	if err != nil {
-		return nil, lastErr
-	}
-	ret, err := toUpgradeKymaOperationList(operations)
+	ret, err := toAccountMigrationOperation(&operation)
 	if err != nil {
 		return nil, errors.Wrapf(err, "while converting DTO to Operation")
 	}
@@ -342,11 +948,11 @@ func (s *operations) ListUpgradeKymaOperationsByInstanceID(instanceID string) ([
 	return ret, nil
 }
 
-// UpdateUpgradeKymaOperation updates UpgradeKymaOperation, fails if not exists or optimistic locking failure occurs.
-func (s *operations) UpdateUpgradeKymaOperation(operation internal.UpgradeKymaOperation) (*internal.UpgradeKymaOperation, error) {
+// UpdateAccountMigrationOperation updates AccountMigrationOperation, fails if not exists or optimistic locking failure occurs.
+func (s *operations) UpdateAccountMigrationOperation(operation internal.AccountMigrationOperation) (*internal.AccountMigrationOperation, error) {
 	session := s.NewWriteSession()
 	operation.UpdatedAt = time.Now()
-	dto, err := upgradeKymaOperationToDTO(&operation)
+	dto, err := accountMigrationOperationToDTO(&operation)
 	if err != nil {
 		return nil, errors.Wrapf(err, "while converting Operation to DTO")
 	}
@@ -396,6 +1002,31 @@ func (s *operations) GetOperationByID(operationID string) (*internal.Operation,
 	return &op, nil
 }
 
+// GetOperationByInstanceAndID fetches the operation of any type by given ID, returns error if not found
+// or the operation belongs to another instance
+func (s *operations) GetOperationByInstanceAndID(instanceID, operationID string) (*internal.Operation, error) {
+	session := s.NewReadSession()
+	operation := dbmodel.OperationDTO{}
+	var lastErr dberr.Error
+	err := wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
+		operation, lastErr = session.GetOperationByInstanceAndID(instanceID, operationID)
+		if lastErr != nil {
+			if dberr.IsNotFound(lastErr) {
+				lastErr = dberr.NotFound("Operation with id %s for instance %s not exist", operationID, instanceID)
+				return false, lastErr
+			}
+			log.Warn(errors.Wrapf(lastErr, "while reading Operation from the storage").Error())
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, lastErr
+	}
+	op := toOperation(&operation)
+	return &op, nil
+}
+
 func (s *operations) GetOperationsInProgressByType(operationType dbmodel.OperationType) ([]internal.Operation, error) {
 	session := s.NewReadSession()
 	operations := make([]dbmodel.OperationDTO, 0)
@@ -414,35 +1045,77 @@ func (s *operations) GetOperationsInProgressByType(operationType dbmodel.Operati
 	return toOperations(operations), nil
 }
 
+// ListOperationsByType lists the operations of the given type matching the filter, sorted by the creation time
+func (s *operations) ListOperationsByType(operationType dbmodel.OperationType, filter dbmodel.OperationFilter, pageSize, page int) ([]internal.Operation, int, int, error) {
+	session := s.NewReadSession()
+	var (
+		operations        = make([]dbmodel.OperationDTO, 0)
+		lastErr           error
+		count, totalCount int
+	)
+	err := wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
+		operations, count, totalCount, lastErr = session.ListOperationsByType(operationType, filter, pageSize, page)
+		if lastErr != nil {
+			log.Errorf("while reading Operations from the storage: %v", lastErr)
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, -1, -1, errors.Wrapf(err, "while listing %s operations: %v", operationType, lastErr)
+	}
+
+	return toOperations(operations), count, totalCount, nil
+}
+
 func (s *operations) GetOperationStats() (internal.OperationStats, error) {
 	entries, err := s.NewReadSession().GetOperationStats()
 	if err != nil {
 		return internal.OperationStats{}, err
 	}
 
-	result := internal.OperationStats{
-		Provisioning:   make(map[domain.LastOperationState]int),
-		Deprovisioning: make(map[domain.LastOperationState]int),
-	}
+	result := internal.NewOperationStats()
 	for _, e := range entries {
 		switch dbmodel.OperationType(e.Type) {
 		case dbmodel.OperationTypeProvision:
-			result.Provisioning[domain.LastOperationState(e.State)] = e.Total
+			result.AddProvisioning(e.PlanID, e.Region, domain.LastOperationState(e.State), e.Total)
 		case dbmodel.OperationTypeDeprovision:
-			result.Deprovisioning[domain.LastOperationState(e.State)] = e.Total
+			result.AddDeprovisioning(e.PlanID, e.Region, domain.LastOperationState(e.State), e.Total)
 		}
 	}
 	return result, nil
 }
 
+func (s *operations) GetOperationTimeStats(from time.Time, window, interval time.Duration) (internal.OperationTimeStats, error) {
+	stats := internal.NewOperationTimeStats(from, window, interval)
+	entries, err := s.NewReadSession().GetOperationBucketStats(from, from.Add(window), interval)
+	if err != nil {
+		return stats, err
+	}
+
+	for _, e := range entries {
+		var counts internal.OperationCounts
+		switch domain.LastOperationState(e.State) {
+		case "":
+			counts.Started = e.Total
+		case domain.Succeeded:
+			counts.Succeeded = e.Total
+		case domain.Failed:
+			counts.Failed = e.Total
+		}
+		stats.Add(e.Bucket, e.Type, counts)
+	}
+	return stats, nil
+}
+
 func (s *operations) GetOperationStatsForOrchestration(orchestrationID string) (map[domain.LastOperationState]int, error) {
 	entries, err := s.NewReadSession().GetOperationStatsForOrchestration(orchestrationID)
 	if err != nil {
 		return map[domain.LastOperationState]int{}, err
 	}
-	result := make(map[domain.LastOperationState]int, 3)
+	result := internal.NewOrchestrationStats()
 	for _, entry := range entries {
-		result[domain.LastOperationState(entry.State)] = entry.Total
+		result[domain.LastOperationState(entry.State)] += entry.Total
 	}
 	return result, nil
 }
@@ -465,7 +1138,45 @@ func (s *operations) GetOperationsForIDs(operationIDList []string) ([]internal.O
 	return toOperations(operations), nil
 }
 
-func (s *operations) ListUpgradeKymaOperationsByOrchestrationID(orchestrationID string, pageSize int, page int) ([]internal.UpgradeKymaOperation, int, int, error) {
+// ListOperationsByInstanceID returns all operations of the given instance sorted by creation time
+func (s *operations) ListOperationsByInstanceID(instanceID string) ([]internal.Operation, error) {
+	session := s.NewReadSession()
+	operations := make([]dbmodel.OperationDTO, 0)
+	err := wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
+		dto, err := session.GetOperationsByInstanceID(instanceID)
+		if err != nil {
+			log.Warn(errors.Wrapf(err, "while getting Operations from the storage").Error())
+			return false, nil
+		}
+		operations = dto
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toOperations(operations), nil
+}
+
+// ListOperationsByCorrelationID returns all operations created for the request with the given correlation ID sorted by creation time
+func (s *operations) ListOperationsByCorrelationID(correlationID string) ([]internal.Operation, error) {
+	session := s.NewReadSession()
+	operations := make([]dbmodel.OperationDTO, 0)
+	err := wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
+		dto, err := session.GetOperationsByCorrelationID(correlationID)
+		if err != nil {
+			log.Warn(errors.Wrapf(err, "while getting Operations from the storage").Error())
+			return false, nil
+		}
+		operations = dto
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toOperations(operations), nil
+}
+
+func (s *operations) ListUpgradeKymaOperationsByOrchestrationID(orchestrationID string, filter dbmodel.OperationFilter, pageSize int, page int) ([]internal.UpgradeKymaOperation, int, int, error) {
 	session := s.NewReadSession()
 	var (
 		operations        = make([]dbmodel.OperationDTO, 0)
@@ -473,7 +1184,7 @@ func (s *operations) ListUpgradeKymaOperationsByOrchestrationID(orchestrationID
 		count, totalCount int
 	)
 	err := wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
-		operations, count, totalCount, lastErr = session.ListOperationsByOrchestrationID(orchestrationID, pageSize, page)
+		operations, count, totalCount, lastErr = session.ListOperationsByOrchestrationID(orchestrationID, filter, pageSize, page)
 		if lastErr != nil {
 			if dberr.IsNotFound(lastErr) {
 				lastErr = dberr.NotFound("Operations for orchestration ID %s not exist", orchestrationID)
@@ -506,6 +1217,10 @@ func toOperation(op *dbmodel.OperationDTO) internal.Operation {
 		Description:            op.Description,
 		Version:                op.Version,
 		OrchestrationID:        storage.SQLNullStringToString(op.OrchestrationID),
+		CorrelationID:          storage.SQLNullStringToString(op.CorrelationID),
+		Stage:                  op.Stage,
+		StageStartedAt:         op.StageStartedAt.Time,
+		RetriedAt:              op.RetriedAt.Time,
 	}
 }
 
@@ -613,6 +1328,150 @@ func upgradeKymaOperationToDTO(op *internal.UpgradeKymaOperation) (dbmodel.Opera
 	return ret, nil
 }
 
+func toUpgradeClusterOperation(op *dbmodel.OperationDTO) (*internal.UpgradeClusterOperation, error) {
+	if op.Type != dbmodel.OperationTypeUpgradeCluster {
+		return nil, errors.New(fmt.Sprintf("expected operation type Upgrade Cluster, but was %s", op.Type))
+	}
+	var operation internal.UpgradeClusterOperation
+	err := json.Unmarshal([]byte(op.Data), &operation)
+	if err != nil {
+		return nil, errors.New("unable to unmarshall upgrade cluster data")
+	}
+	operation.Operation = toOperation(op)
+
+	return &operation, nil
+}
+
+func toUpgradeClusterOperationList(ops []dbmodel.OperationDTO) ([]internal.UpgradeClusterOperation, error) {
+	result := make([]internal.UpgradeClusterOperation, 0)
+
+	for _, op := range ops {
+		o, err := toUpgradeClusterOperation(&op)
+		if err != nil {
+			return nil, errors.Wrap(err, "while converting to upgrade cluster operation")
+		}
+		result = append(result, *o)
+	}
+
+	return result, nil
+}
+
+func upgradeClusterOperationToDTO(op *internal.UpgradeClusterOperation) (dbmodel.OperationDTO, error) {
+	serialized, err := json.Marshal(op)
+	if err != nil {
+		return dbmodel.OperationDTO{}, errors.Wrapf(err, "while serializing upgrade cluster data %v", op)
+	}
+
+	ret := operationToDB(&op.Operation)
+	ret.Data = string(serialized)
+	ret.Type = dbmodel.OperationTypeUpgradeCluster
+	return ret, nil
+}
+
+func toPlanMigrationOperation(op *dbmodel.OperationDTO) (*internal.PlanMigrationOperation, error) {
+	if op.Type != dbmodel.OperationTypeMigratePlan {
+		return nil, errors.New(fmt.Sprintf("expected operation type Migrate Plan, but was %s", op.Type))
+	}
+	var operation internal.PlanMigrationOperation
+	err := json.Unmarshal([]byte(op.Data), &operation)
+	if err != nil {
+		return nil, errors.New("unable to unmarshall plan migration data")
+	}
+	operation.Operation = toOperation(op)
+
+	return &operation, nil
+}
+
+func planMigrationOperationToDTO(op *internal.PlanMigrationOperation) (dbmodel.OperationDTO, error) {
+	serialized, err := json.Marshal(op)
+	if err != nil {
+		return dbmodel.OperationDTO{}, errors.Wrapf(err, "while serializing plan migration data %v", op)
+	}
+
+	ret := operationToDB(&op.Operation)
+	ret.Data = string(serialized)
+	ret.Type = dbmodel.OperationTypeMigratePlan
+	return ret, nil
+}
+
+func toUpdatingOperation(op *dbmodel.OperationDTO) (*internal.UpdatingOperation, error) {
+	if op.Type != dbmodel.OperationTypeUpdate {
+		return nil, errors.New(fmt.Sprintf("expected operation type Update, but was %s", op.Type))
+	}
+	var operation internal.UpdatingOperation
+	err := json.Unmarshal([]byte(op.Data), &operation)
+	if err != nil {
+		return nil, errors.New("unable to unmarshall updating data")
+	}
+	operation.Operation = toOperation(op)
+
+	return &operation, nil
+}
+
+func updatingOperationToDTO(op *internal.UpdatingOperation) (dbmodel.OperationDTO, error) {
+	serialized, err := json.Marshal(op)
+	if err != nil {
+		return dbmodel.OperationDTO{}, errors.Wrapf(err, "while serializing updating data %v", op)
+	}
+
+	ret := operationToDB(&op.Operation)
+	ret.Data = string(serialized)
+	ret.Type = dbmodel.OperationTypeUpdate
+	return ret, nil
+}
+
+func toSuspensionOperation(op *dbmodel.OperationDTO) (*internal.SuspensionOperation, error) {
+	if op.Type != dbmodel.OperationTypeSuspension {
+		return nil, errors.New(fmt.Sprintf("expected operation type Suspension, but was %s", op.Type))
+	}
+	var operation internal.SuspensionOperation
+	err := json.Unmarshal([]byte(op.Data), &operation)
+	if err != nil {
+		return nil, errors.New("unable to unmarshall suspension data")
+	}
+	operation.Operation = toOperation(op)
+
+	return &operation, nil
+}
+
+func suspensionOperationToDTO(op *internal.SuspensionOperation) (dbmodel.OperationDTO, error) {
+	serialized, err := json.Marshal(op)
+	if err != nil {
+		return dbmodel.OperationDTO{}, errors.Wrapf(err, "while serializing suspension data %v", op)
+	}
+
+	ret := operationToDB(&op.Operation)
+	ret.Data = string(serialized)
+	ret.Type = dbmodel.OperationTypeSuspension
+	return ret, nil
+}
+
+func toAccountMigrationOperation(op *dbmodel.OperationDTO) (*internal.AccountMigrationOperation, error) {
+	if op.Type != dbmodel.OperationTypeAccountMigration {
+		return nil, errors.New(fmt.Sprintf("expected operation type AccountMigration, but was %s", op.Type))
+	}
+	var operation internal.AccountMigrationOperation
+	err := json.Unmarshal([]byte(op.Data), &operation)
+	if err != nil {
+		return nil, errors.New("unable to unmarshall account migration data")
+	}
+	operation.Operation = toOperation(op)
+
+	return &operation, nil
+}
+
+func accountMigrationOperationToDTO(op *internal.AccountMigrationOperation) (dbmodel.OperationDTO, error) {
+	serialized, err := json.Marshal(op)
+	if err != nil {
+		return dbmodel.OperationDTO{}, errors.Wrapf(err, "while serializing account migration data %v", op)
+	}
+
+	ret := operationToDB(&op.Operation)
+	ret.Data = string(serialized)
+	ret.Type = dbmodel.OperationTypeAccountMigration
+	return ret, nil
+}
+
 func operationToDB(op *internal.Operation) dbmodel.OperationDTO {
 	return dbmodel.OperationDTO{
 		ID:                op.ID,
@@ -624,5 +1483,9 @@ func operationToDB(op *internal.Operation) dbmodel.OperationDTO {
 		Version:           op.Version,
 		InstanceID:        op.InstanceID,
 		OrchestrationID:   storage.StringToSQLNullString(op.OrchestrationID),
+		CorrelationID:     storage.StringToSQLNullString(op.CorrelationID),
+		Stage:             op.Stage,
+		StageStartedAt:    sql.NullTime{Time: op.StageStartedAt, Valid: !op.StageStartedAt.IsZero()},
+		RetriedAt:         sql.NullTime{Time: op.RetriedAt, Valid: !op.RetriedAt.IsZero()},
 	}
 }
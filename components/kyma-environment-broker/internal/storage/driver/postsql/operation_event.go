@@ -0,0 +1,97 @@
+package postsql
+
+import (
+	"database/sql"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession/dbmodel"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+type operationEvents struct {
+	dbsession.Factory
+}
+
+func NewOperationEvents(sess dbsession.Factory) *operationEvents {
+	return &operationEvents{
+		Factory: sess,
+	}
+}
+
+func (s *operationEvents) InsertEvent(event internal.OperationEvent) error {
+	dto := dbmodel.OperationEventDTO{
+		ID:          event.ID,
+		OperationID: event.OperationID,
+		InstanceID:  event.InstanceID,
+		Actor:       event.Actor,
+		StepName:    toNullString(event.StepName),
+		OldState:    toNullString(string(event.OldState)),
+		NewState:    string(event.NewState),
+		Description: toNullString(event.Description),
+		CreatedAt:   event.CreatedAt,
+	}
+
+	sess := s.NewWriteSession()
+	var lastErr dberr.Error
+	err := wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
+		lastErr = sess.InsertOperationEvent(dto)
+		if lastErr != nil {
+			if lastErr.Code() == dberr.CodeAlreadyExists {
+				return false, lastErr
+			}
+			log.Warnf("while saving event of the operation %s: %v", event.OperationID, lastErr)
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return lastErr
+	}
+	return nil
+}
+
+func (s *operationEvents) ListEventsByOperationID(operationID string) ([]internal.OperationEvent, error) {
+	sess := s.NewReadSession()
+	var (
+		dtos    []dbmodel.OperationEventDTO
+		lastErr dberr.Error
+	)
+	err := wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
+		dtos, lastErr = sess.ListOperationEventsByOperationID(operationID)
+		if lastErr != nil {
+			log.Warnf("while getting events of the operation %s: %v", operationID, lastErr)
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, lastErr
+	}
+
+	events := make([]internal.OperationEvent, 0, len(dtos))
+	for _, dto := range dtos {
+		events = append(events, internal.OperationEvent{
+			ID:          dto.ID,
+			OperationID: dto.OperationID,
+			InstanceID:  dto.InstanceID,
+			Actor:       dto.Actor,
+			StepName:    dto.StepName.String,
+			OldState:    domain.LastOperationState(dto.OldState.String),
+			NewState:    domain.LastOperationState(dto.NewState),
+			Description: dto.Description.String,
+			CreatedAt:   dto.CreatedAt,
+		})
+	}
+	return events, nil
+}
+
+func toNullString(s string) sql.NullString {
+	return sql.NullString{
+		String: s,
+		Valid:  s != "",
+	}
+}
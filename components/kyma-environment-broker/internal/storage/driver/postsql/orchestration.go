@@ -65,7 +65,7 @@ func (s *orchestration) GetByID(orchestrationID string) (*internal.Orchestration
 	return &orchestration, nil
 }
 
-func (s *orchestration) List(pageSize int, page int) ([]internal.Orchestration, int, int, error) {
+func (s *orchestration) List(filter dbmodel.OrchestrationFilter) ([]internal.Orchestration, int, int, error) {
 	sess := s.NewReadSession()
 	var (
 		orchestrations    = make([]internal.Orchestration, 0)
@@ -74,7 +74,7 @@ func (s *orchestration) List(pageSize int, page int) ([]internal.Orchestration,
 	)
 	err := wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
 		var dtos []dbmodel.OrchestrationDTO
-		dtos, count, totalCount, lastErr = sess.ListOrchestrations(pageSize, page)
+		dtos, count, totalCount, lastErr = sess.ListOrchestrations(filter)
 		if lastErr != nil {
 			if dberr.IsNotFound(lastErr) {
 				return false, dberr.NotFound("Orchestrations not exist")
@@ -2,6 +2,7 @@ package postsql
 
 import (
 	"encoding/json"
+	"time"
 
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
@@ -93,6 +94,43 @@ func (s *runtimeState) GetByOperationID(operationID string) (internal.RuntimeSta
 	return result, nil
 }
 
+func (s *runtimeState) DeleteByOperationID(operationID string) error {
+	sess := s.NewWriteSession()
+	var lastErr dberr.Error
+	err := wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
+		lastErr = sess.DeleteRuntimeStateByOperationID(operationID)
+		if lastErr != nil {
+			log.Warnf("while deleting RuntimeState for operation %s: %v", operationID, lastErr)
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return lastErr
+	}
+	return nil
+}
+
+func (s *runtimeState) DeleteSupersededCreatedBefore(before time.Time) (int, error) {
+	sess := s.NewWriteSession()
+	var (
+		deleted int
+		lastErr dberr.Error
+	)
+	err := wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
+		deleted, lastErr = sess.DeleteSupersededRuntimeStatesCreatedBefore(before)
+		if lastErr != nil {
+			log.Warnf("while deleting RuntimeStates created before %s: %v", before, lastErr)
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return 0, lastErr
+	}
+	return deleted, nil
+}
+
 func (s *runtimeState) runtimeStateToDB(op internal.RuntimeState) (dbmodel.RuntimeStateDTO, error) {
 	kymaCfg, err := json.Marshal(op.KymaConfig)
 	if err != nil {
@@ -0,0 +1,47 @@
+package postsql
+
+import (
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession/dbmodel"
+)
+
+type trialExpirations struct {
+	dbsession.Factory
+}
+
+func NewTrialExpirations(sess dbsession.Factory) *trialExpirations {
+	return &trialExpirations{
+		Factory: sess,
+	}
+}
+
+func (s *trialExpirations) GetExpiration(instanceID string) (internal.TrialExpiration, bool, error) {
+	dto, err := s.NewReadSession().GetTrialExpiration(instanceID)
+
+	switch {
+	case err == nil:
+		return internal.TrialExpiration{
+			InstanceID:      dto.InstanceID,
+			ExpiresAt:       dto.ExpiresAt,
+			NotifiedAt:      dto.NotifiedAt,
+			DeprovisionedAt: dto.DeprovisionedAt,
+			UpdatedAt:       dto.UpdatedAt,
+		}, true, nil
+	case err.Code() == dberr.CodeNotFound:
+		return internal.TrialExpiration{}, false, nil
+	default:
+		return internal.TrialExpiration{}, false, err
+	}
+}
+
+func (s *trialExpirations) UpsertExpiration(expiration internal.TrialExpiration) error {
+	return s.NewWriteSession().UpsertTrialExpiration(dbmodel.TrialExpirationDTO{
+		InstanceID:      expiration.InstanceID,
+		ExpiresAt:       expiration.ExpiresAt,
+		NotifiedAt:      expiration.NotifiedAt,
+		DeprovisionedAt: expiration.DeprovisionedAt,
+		UpdatedAt:       expiration.UpdatedAt,
+	})
+}
@@ -1,6 +1,8 @@
 package storage
 
 import (
+	"time"
+
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession/dbmodel"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/predicate"
@@ -18,17 +20,32 @@ type Instances interface {
 	GetInstanceStats() (internal.InstanceStats, error)
 	GetNumberOfInstancesForGlobalAccountID(globalAccountID string) (int, error)
 	List(dbmodel.InstanceFilter) ([]internal.Instance, int, int, error)
+	ListWithState(dbmodel.InstanceFilter) ([]internal.InstanceWithState, int, int, error)
 }
 
 type Operations interface {
 	Provisioning
 	Deprovisioning
 	UpgradeKyma
+	UpgradeCluster
+	PlanMigration
+	Updating
+	Suspension
+	AccountMigration
 
 	GetOperationByID(operationID string) (*internal.Operation, error)
+	// GetOperationByInstanceAndID returns the operation only if it belongs to the given instance
+	GetOperationByInstanceAndID(instanceID, operationID string) (*internal.Operation, error)
 	GetOperationsInProgressByType(operationType dbmodel.OperationType) ([]internal.Operation, error)
+	// ListOperationsByType lists the operations of the given type matching the filter, sorted by the creation time
+	ListOperationsByType(operationType dbmodel.OperationType, filter dbmodel.OperationFilter, pageSize, page int) ([]internal.Operation, int, int, error)
 	GetOperationStats() (internal.OperationStats, error)
+	GetOperationTimeStats(from time.Time, window, interval time.Duration) (internal.OperationTimeStats, error)
 	GetOperationsForIDs(operationIDList []string) ([]internal.Operation, error)
+	ListOperationsByInstanceID(instanceID string) ([]internal.Operation, error)
+	// ListOperationsByCorrelationID returns the operations created for the request with the given correlation ID
+	// sorted by the creation time
+	ListOperationsByCorrelationID(correlationID string) ([]internal.Operation, error)
 	GetOperationStatsForOrchestration(orchestrationID string) (map[domain.LastOperationState]int, error)
 }
 
@@ -37,6 +54,7 @@ type Provisioning interface {
 	GetProvisioningOperationByID(operationID string) (*internal.ProvisioningOperation, error)
 	GetProvisioningOperationByInstanceID(instanceID string) (*internal.ProvisioningOperation, error)
 	UpdateProvisioningOperation(operation internal.ProvisioningOperation) (*internal.ProvisioningOperation, error)
+	ListProvisioningOperations(filter dbmodel.OperationFilter, pageSize, page int) ([]internal.ProvisioningOperation, int, int, error)
 }
 
 type Deprovisioning interface {
@@ -50,7 +68,7 @@ type Orchestrations interface {
 	Insert(orchestration internal.Orchestration) error
 	Update(orchestration internal.Orchestration) error
 	GetByID(orchestrationID string) (*internal.Orchestration, error)
-	List(pageSize int, page int) ([]internal.Orchestration, int, int, error)
+	List(filter dbmodel.OrchestrationFilter) ([]internal.Orchestration, int, int, error)
 	ListByState(state string) ([]internal.Orchestration, error)
 }
 
@@ -58,6 +76,61 @@ type RuntimeStates interface {
 	Insert(runtimeState internal.RuntimeState) error
 	GetByOperationID(operationID string) (internal.RuntimeState, error)
 	ListByRuntimeID(runtimeID string) ([]internal.RuntimeState, error)
+	DeleteByOperationID(operationID string) error
+	// DeleteSupersededCreatedBefore removes the runtime states created before the given time and returns the number of
+	// removed entries. The newest state of every runtime and the newest state of its succeeded provisioning or Kyma upgrade,
+	// which holds the current Kyma version of the runtime, are kept regardless of their age.
+	DeleteSupersededCreatedBefore(before time.Time) (int, error)
+}
+
+type InstancesArchived interface {
+	Insert(archived internal.ArchivedInstance) error
+	GetByID(instanceID string) (*internal.ArchivedInstance, error)
+	// List returns the archived instances sorted by the creation time of the instance
+	List(filter dbmodel.InstanceFilter) ([]internal.ArchivedInstance, int, int, error)
+}
+
+type FreeTierUsage interface {
+	Insert(entry internal.FreeTierUsageEntry) error
+	// Finish records the end of the instance lifetime, the entry which is already finished is not changed
+	Finish(instanceID string, finishedAt time.Time) error
+	ListByGlobalAccountID(globalAccountID string) ([]internal.FreeTierUsageEntry, error)
+}
+
+type InstallerOverrides interface {
+	GetOverrides(globalAccountID string) (internal.InstallerOverrides, bool, error)
+	UpsertOverrides(overrides internal.InstallerOverrides) error
+	DeleteOverrides(globalAccountID string) error
+}
+
+type OperationEvents interface {
+	InsertEvent(event internal.OperationEvent) error
+	// ListEventsByOperationID returns the events of the operation sorted by the creation time
+	ListEventsByOperationID(operationID string) ([]internal.OperationEvent, error)
+}
+
+type KillSwitches interface {
+	InsertEvent(event internal.KillSwitchEvent) error
+	// ListEvents returns the events of all kill switches sorted by the creation time
+	ListEvents() ([]internal.KillSwitchEvent, error)
+	// ListLatestEvents returns the latest event of every kill switch sorted by the scope and the name
+	ListLatestEvents() ([]internal.KillSwitchEvent, error)
+}
+
+// Jobs keeps the locks which ensure that a periodic job is run by a single broker replica at a time,
+// and the history of the job runs
+type Jobs interface {
+	// AcquireLock takes the lock of the job for the owner until the given time. False is returned if the lock is held
+	// by another owner and did not expire before now. The owner holding the lock extends it.
+	AcquireLock(jobName, owner string, now, until time.Time) (bool, error)
+	// ReleaseLock releases the lock of the job if it is held by the owner
+	ReleaseLock(jobName, owner string) error
+	InsertRun(run internal.JobRun) error
+	UpdateRun(run internal.JobRun) error
+	// ListRuns returns at most limit latest runs of the job sorted from the newest one
+	ListRuns(jobName string, limit int) ([]internal.JobRun, error)
+	// DeleteRunsStartedBefore removes the runs of all jobs started before the given time and returns their number
+	DeleteRunsStartedBefore(before time.Time) (int, error)
 }
 
 type UpgradeKyma interface {
@@ -66,7 +139,55 @@ type UpgradeKyma interface {
 	GetUpgradeKymaOperationByID(operationID string) (*internal.UpgradeKymaOperation, error)
 	GetUpgradeKymaOperationByInstanceID(instanceID string) (*internal.UpgradeKymaOperation, error)
 	ListUpgradeKymaOperationsByInstanceID(instanceID string) ([]internal.UpgradeKymaOperation, error)
-	ListUpgradeKymaOperationsByOrchestrationID(orchestrationID string, pageSize int, page int) ([]internal.UpgradeKymaOperation, int, int, error)
+	ListUpgradeKymaOperationsByOrchestrationID(orchestrationID string, filter dbmodel.OperationFilter, pageSize int, page int) ([]internal.UpgradeKymaOperation, int, int, error)
+	// ListInstanceIDsUpgradedSince returns the IDs of the instances with the Kyma upgrade succeeded since the given time
+	ListInstanceIDsUpgradedSince(since time.Time) ([]string, error)
+}
+
+type UpgradeCluster interface {
+	InsertUpgradeClusterOperation(operation internal.UpgradeClusterOperation) error
+	UpdateUpgradeClusterOperation(operation internal.UpgradeClusterOperation) (*internal.UpgradeClusterOperation, error)
+	GetUpgradeClusterOperationByID(operationID string) (*internal.UpgradeClusterOperation, error)
+	ListUpgradeClusterOperationsByInstanceID(instanceID string) ([]internal.UpgradeClusterOperation, error)
+	ListUpgradeClusterOperationsByOrchestrationID(orchestrationID string, pageSize int, page int) ([]internal.UpgradeClusterOperation, int, int, error)
+}
+
+type PlanMigration interface {
+	InsertPlanMigrationOperation(operation internal.PlanMigrationOperation) error
+	UpdatePlanMigrationOperation(operation internal.PlanMigrationOperation) (*internal.PlanMigrationOperation, error)
+	GetPlanMigrationOperationByID(operationID string) (*internal.PlanMigrationOperation, error)
+	GetPlanMigrationOperationByInstanceID(instanceID string) (*internal.PlanMigrationOperation, error)
+}
+
+type Updating interface {
+	InsertUpdatingOperation(operation internal.UpdatingOperation) error
+	UpdateUpdatingOperation(operation internal.UpdatingOperation) (*internal.UpdatingOperation, error)
+	GetUpdatingOperationByID(operationID string) (*internal.UpdatingOperation, error)
+	GetUpdatingOperationByInstanceID(instanceID string) (*internal.UpdatingOperation, error)
+}
+
+type Suspension interface {
+	InsertSuspensionOperation(operation internal.SuspensionOperation) error
+	UpdateSuspensionOperation(operation internal.SuspensionOperation) (*internal.SuspensionOperation, error)
+	GetSuspensionOperationByID(operationID string) (*internal.SuspensionOperation, error)
+	GetSuspensionOperationByInstanceID(instanceID string) (*internal.SuspensionOperation, error)
+}
+
+type AccountMigration interface {
+	InsertAccountMigrationOperation(operation internal.AccountMigrationOperation) error
+	UpdateAccountMigrationOperation(operation internal.AccountMigrationOperation) (*internal.AccountMigrationOperation, error)
+	GetAccountMigrationOperationByID(operationID string) (*internal.AccountMigrationOperation, error)
+	GetAccountMigrationOperationByInstanceID(instanceID string) (*internal.AccountMigrationOperation, error)
+}
+
+type KymaChannels interface {
+	GetSubscription(globalAccountID string) (internal.KymaChannelSubscription, bool, error)
+	UpsertSubscription(subscription internal.KymaChannelSubscription) error
+}
+
+type TrialExpirations interface {
+	GetExpiration(instanceID string) (internal.TrialExpiration, bool, error)
+	UpsertExpiration(expiration internal.TrialExpiration) error
 }
 
 type LMSTenants interface {
@@ -13,13 +13,25 @@ import (
 )
 
 const (
-	schemaName             = "public"
-	InstancesTableName     = "instances"
-	OperationTableName     = "operations"
-	OrchestrationTableName = "orchestrations"
-	RuntimeStateTableName  = "runtime_states"
-	LMSTenantTableName     = "lms_tenants"
-	CreatedAtField         = "created_at"
+	schemaName                  = "public"
+	InstancesTableName          = "instances"
+	OperationTableName          = "operations"
+	OrchestrationTableName      = "orchestrations"
+	RuntimeStateTableName       = "runtime_states"
+	LMSTenantTableName          = "lms_tenants"
+	KymaChannelTableName        = "kyma_channel_subscriptions"
+	OperationEventTableName     = "operation_events"
+	InstallerOverridesTableName = "installer_overrides"
+	InstancesArchivedTableName  = "instances_archived"
+	FreeTierUsageTableName      = "free_tier_usage"
+	KillSwitchEventTableName    = "kill_switch_events"
+	JobLockTableName            = "job_locks"
+	JobRunTableName             = "job_runs"
+	TrialExpirationTableName    = "trial_expirations"
+	CreatedAtField              = "created_at"
+
+	// InstancesWithStateViewName is the view joining instances with their latest operation
+	InstancesWithStateViewName = "instances_with_state"
 )
 
 // InitializeDatabase opens database connection and initializes schema if it does not exist
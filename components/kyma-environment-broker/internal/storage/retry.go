@@ -0,0 +1,152 @@
+package storage
+
+import (
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
+)
+
+// maxConflictRetries limits the number of attempts, the conflict which is still returned after the last
+// attempt means the operation is heavily contended and the caller should repeat the whole step
+const maxConflictRetries = 5
+
+// RetryOnConflict calls the function again as long as it returns the optimistic locking conflict,
+// the function must read the latest version of the entity on every call. Other errors are returned as they are,
+// so the callers are still able to check them with dberr.IsNotFound
+func RetryOnConflict(fn func() error) error {
+	var err error
+	for i := 0; i < maxConflictRetries; i++ {
+		err = fn()
+		if !dberr.IsConflict(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// UpdateWithRetryProvisioningOperation applies the mutation on the latest version of the operation and stores it,
+// the operation is read and the mutation is applied again if the operation was changed in the meantime
+func UpdateWithRetryProvisioningOperation(storage Provisioning, operationID string, mutate func(*internal.ProvisioningOperation)) (*internal.ProvisioningOperation, error) {
+	var updated *internal.ProvisioningOperation
+	err := RetryOnConflict(func() error {
+		operation, err := storage.GetProvisioningOperationByID(operationID)
+		if err != nil {
+			return err
+		}
+		mutate(operation)
+		updated, err = storage.UpdateProvisioningOperation(*operation)
+		return err
+	})
+	return updated, err
+}
+
+// UpdateWithRetryDeprovisioningOperation applies the mutation on the latest version of the operation and stores it,
+// the operation is read and the mutation is applied again if the operation was changed in the meantime
+func UpdateWithRetryDeprovisioningOperation(storage Deprovisioning, operationID string, mutate func(*internal.DeprovisioningOperation)) (*internal.DeprovisioningOperation, error) {
+	var updated *internal.DeprovisioningOperation
+	err := RetryOnConflict(func() error {
+		operation, err := storage.GetDeprovisioningOperationByID(operationID)
+		if err != nil {
+			return err
+		}
+		mutate(operation)
+		updated, err = storage.UpdateDeprovisioningOperation(*operation)
+		return err
+	})
+	return updated, err
+}
+
+// UpdateWithRetryUpgradeKymaOperation applies the mutation on the latest version of the operation and stores it,
+// the operation is read and the mutation is applied again if the operation was changed in the meantime
+func UpdateWithRetryUpgradeKymaOperation(storage UpgradeKyma, operationID string, mutate func(*internal.UpgradeKymaOperation)) (*internal.UpgradeKymaOperation, error) {
+	var updated *internal.UpgradeKymaOperation
+	err := RetryOnConflict(func() error {
+		operation, err := storage.GetUpgradeKymaOperationByID(operationID)
+		if err != nil {
+			return err
+		}
+		mutate(operation)
+		updated, err = storage.UpdateUpgradeKymaOperation(*operation)
+		return err
+	})
+	return updated, err
+}
+
+// UpdateWithRetryUpgradeClusterOperation applies the mutation on the latest version of the operation and stores it,
+// the operation is read and the mutation is applied again if the operation was changed in the meantime
+func UpdateWithRetryUpgradeClusterOperation(storage UpgradeCluster, operationID string, mutate func(*internal.UpgradeClusterOperation)) (*internal.UpgradeClusterOperation, error) {
+	var updated *internal.UpgradeClusterOperation
+	err := RetryOnConflict(func() error {
+		operation, err := storage.GetUpgradeClusterOperationByID(operationID)
+		if err != nil {
+			return err
+		}
+		mutate(operation)
+		updated, err = storage.UpdateUpgradeClusterOperation(*operation)
+		return err
+	})
+	return updated, err
+}
+
+// UpdateWithRetryPlanMigrationOperation applies the mutation on the latest version of the operation and stores it,
+// the operation is read and the mutation is applied again if the operation was changed in the meantime
+func UpdateWithRetryPlanMigrationOperation(storage PlanMigration, operationID string, mutate func(*internal.PlanMigrationOperation)) (*internal.PlanMigrationOperation, error) {
+	var updated *internal.PlanMigrationOperation
+	err := RetryOnConflict(func() error {
+		operation, err := storage.GetPlanMigrationOperationByID(operationID)
+		if err != nil {
+			return err
+		}
+		mutate(operation)
+		updated, err = storage.UpdatePlanMigrationOperation(*operation)
+		return err
+	})
+	return updated, err
+}
+
+// UpdateWithRetryUpdatingOperation applies the mutation on the latest version of the operation and stores it,
+// the operation is read and the mutation is applied again if the operation was changed in the meantime
+func UpdateWithRetryUpdatingOperation(storage Updating, operationID string, mutate func(*internal.UpdatingOperation)) (*internal.UpdatingOperation, error) {
+	var updated *internal.UpdatingOperation
+	err := RetryOnConflict(func() error {
+		operation, err := storage.GetUpdatingOperationByID(operationID)
+		if err != nil {
+			return err
+		}
+		mutate(operation)
+		updated, err = storage.UpdateUpdatingOperation(*operation)
+		return err
+	})
+	return updated, err
+}
+
+// UpdateWithRetrySuspensionOperation applies the mutation on the latest version of the operation and stores it,
+// the operation is read and the mutation is applied again if the operation was changed in the meantime
+func UpdateWithRetrySuspensionOperation(storage Suspension, operationID string, mutate func(*internal.SuspensionOperation)) (*internal.SuspensionOperation, error) {
+	var updated *internal.SuspensionOperation
+	err := RetryOnConflict(func() error {
+		operation, err := storage.GetSuspensionOperationByID(operationID)
+		if err != nil {
+			return err
+		}
+		mutate(operation)
+		updated, err = storage.UpdateSuspensionOperation(*operation)
+		return err
+	})
+	return updated, err
+}
+
+// UpdateWithRetryAccountMigrationOperation applies the mutation on the latest version of the operation and stores it,
+// the operation is read and the mutation is applied again if the operation was changed in the meantime
+func UpdateWithRetryAccountMigrationOperation(storage AccountMigration, operationID string, mutate func(*internal.AccountMigrationOperation)) (*internal.AccountMigrationOperation, error) {
+	var updated *internal.AccountMigrationOperation
+	err := RetryOnConflict(func() error {
+		operation, err := storage.GetAccountMigrationOperationByID(operationID)
+		if err != nil {
+			return err
+		}
+		mutate(operation)
+		updated, err = storage.UpdateAccountMigrationOperation(*operation)
+		return err
+	})
+	return updated, err
+}
@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
+
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryOnConflict(t *testing.T) {
+	t.Run("should retry until conflict is resolved", func(t *testing.T) {
+		// given
+		calls := 0
+
+		// when
+		err := RetryOnConflict(func() error {
+			calls++
+			if calls < 3 {
+				return dberr.Conflict("conflict")
+			}
+			return nil
+		})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("should give up after max retries", func(t *testing.T) {
+		// given
+		calls := 0
+
+		// when
+		err := RetryOnConflict(func() error {
+			calls++
+			return dberr.Conflict("conflict")
+		})
+
+		// then
+		assert.True(t, dberr.IsConflict(err))
+		assert.Equal(t, maxConflictRetries, calls)
+	})
+
+	t.Run("should not retry other errors", func(t *testing.T) {
+		// given
+		calls := 0
+
+		// when
+		err := RetryOnConflict(func() error {
+			calls++
+			return dberr.NotFound("not found")
+		})
+
+		// then
+		assert.True(t, dberr.IsNotFound(err))
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestUpdateWithRetryUpgradeKymaOperation(t *testing.T) {
+	// given
+	operations := NewMemoryStorage().Operations()
+	err := operations.InsertUpgradeKymaOperation(internal.UpgradeKymaOperation{
+		Operation: internal.Operation{
+			ID:         "operation-id",
+			InstanceID: "instance-id",
+			State:      domain.InProgress,
+		},
+	})
+	require.NoError(t, err)
+
+	// when
+	attempts := 0
+	updated, err := UpdateWithRetryUpgradeKymaOperation(operations, "operation-id", func(op *internal.UpgradeKymaOperation) {
+		attempts++
+		if attempts == 1 {
+			// the operation is changed in the meantime by another process
+			concurrent := *op
+			concurrent.Description = "changed concurrently"
+			_, err := operations.UpdateUpgradeKymaOperation(concurrent)
+			require.NoError(t, err)
+		}
+		op.State = domain.Failed
+	})
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+	assert.Equal(t, domain.Failed, updated.State)
+	assert.Equal(t, "changed concurrently", updated.Description)
+
+	stored, err := operations.GetUpgradeKymaOperationByID("operation-id")
+	require.NoError(t, err)
+	assert.Equal(t, domain.Failed, stored.State)
+	assert.Equal(t, "changed concurrently", stored.Description)
+}
+
+func TestUpdateWithRetryProvisioningOperation_NotFound(t *testing.T) {
+	// given
+	operations := NewMemoryStorage().Operations()
+
+	// when
+	_, err := UpdateWithRetryProvisioningOperation(operations, "unknown", func(op *internal.ProvisioningOperation) {
+		t.Fatal("mutation must not be applied on a missing operation")
+	})
+
+	// then
+	assert.True(t, dberr.IsNotFound(err))
+}
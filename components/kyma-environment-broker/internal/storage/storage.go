@@ -1,11 +1,14 @@
 package storage
 
 import (
+	"sync"
+
 	"github.com/gocraft/dbr"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/driver/memory"
 	postgres "github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/driver/postsql"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/postsql"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
 
@@ -17,6 +20,19 @@ type BrokerStorage interface {
 	LMSTenants() LMSTenants
 	Orchestrations() Orchestrations
 	RuntimeStates() RuntimeStates
+	KymaChannels() KymaChannels
+	OperationEvents() OperationEvents
+	InstallerOverrides() InstallerOverrides
+	InstancesArchived() InstancesArchived
+	FreeTierUsage() FreeTierUsage
+	KillSwitches() KillSwitches
+	Jobs() Jobs
+	TrialExpirations() TrialExpirations
+
+	// InTransaction calls fn with the storage which writes within a single database transaction. The transaction
+	// is committed when fn succeeds and rolled back otherwise. The memory storage restores its content from the start
+	// of the transaction when fn fails, the writes made outside of the transaction in the meantime are lost as well.
+	InTransaction(fn func(tx BrokerStorage) error) error
 }
 
 const (
@@ -40,32 +56,90 @@ func NewFromConfig(cfg Config, log logrus.FieldLogger) (BrokerStorage, *dbr.Conn
 
 	enc := NewEncrypter(cfg.SecretKey)
 
+	return newPostgresStorage(fact, enc), connection, nil
+}
+
+func newPostgresStorage(fact dbsession.Factory, enc *Encrypter) storage {
 	return storage{
+		factory:        fact,
+		encrypter:      enc,
 		instance:       postgres.NewInstance(fact),
 		operation:      postgres.NewOperation(fact),
 		lmsTenants:     postgres.NewLMSTenants(fact),
 		orchestrations: postgres.NewOrchestrations(fact),
 		runtimeStates:  postgres.NewRuntimeStates(fact, enc),
-	}, connection, nil
+		kymaChannels:   postgres.NewKymaChannels(fact),
+		events:         postgres.NewOperationEvents(fact),
+		overrides:      postgres.NewInstallerOverrides(fact),
+		archived:       postgres.NewInstancesArchived(fact),
+		freeTierUsage:  postgres.NewFreeTierUsage(fact),
+		killSwitches:   postgres.NewKillSwitches(fact),
+		jobs:           postgres.NewJobs(fact),
+		expirations:    postgres.NewTrialExpirations(fact),
+	}
 }
 
 func NewMemoryStorage() BrokerStorage {
 	op := memory.NewOperation()
+	instance := memory.NewInstance(op)
+	lmsTenants := memory.NewLMSTenants()
+	orchestrations := memory.NewOrchestrations()
+	runtimeStates := memory.NewRuntimeStates(op)
+	kymaChannels := memory.NewKymaChannels()
+	events := memory.NewOperationEvents()
+	overrides := memory.NewInstallerOverrides()
+	archived := memory.NewInstancesArchived()
+	freeTierUsage := memory.NewFreeTierUsage()
+	killSwitches := memory.NewKillSwitches()
+	jobs := memory.NewJobs()
+	expirations := memory.NewTrialExpirations()
+
 	return storage{
+		memoryTx: &sync.Mutex{},
+		checkpoints: []func() func(){
+			op.Checkpoint, instance.Checkpoint, lmsTenants.Checkpoint, orchestrations.Checkpoint, runtimeStates.Checkpoint,
+			kymaChannels.Checkpoint, events.Checkpoint, overrides.Checkpoint, archived.Checkpoint, freeTierUsage.Checkpoint,
+			killSwitches.Checkpoint, jobs.Checkpoint, expirations.Checkpoint,
+		},
+
 		operation:      op,
-		instance:       memory.NewInstance(op),
-		lmsTenants:     memory.NewLMSTenants(),
-		orchestrations: memory.NewOrchestrations(),
-		runtimeStates:  memory.NewRuntimeStates(),
+		instance:       instance,
+		lmsTenants:     lmsTenants,
+		orchestrations: orchestrations,
+		runtimeStates:  runtimeStates,
+		kymaChannels:   kymaChannels,
+		events:         events,
+		overrides:      overrides,
+		archived:       archived,
+		freeTierUsage:  freeTierUsage,
+		killSwitches:   killSwitches,
+		jobs:           jobs,
+		expirations:    expirations,
 	}
 }
 
 type storage struct {
+	// factory and encrypter are nil for the memory storage
+	factory   dbsession.Factory
+	encrypter *Encrypter
+	// memoryTx serializes the transactions of the memory storage, the checkpoints of its drivers
+	// restore their content when the transaction is rolled back
+	memoryTx    *sync.Mutex
+	checkpoints []func() func()
+
 	instance       Instances
 	operation      Operations
 	lmsTenants     LMSTenants
 	orchestrations Orchestrations
 	runtimeStates  RuntimeStates
+	kymaChannels   KymaChannels
+	events         OperationEvents
+	overrides      InstallerOverrides
+	archived       InstancesArchived
+	freeTierUsage  FreeTierUsage
+	killSwitches   KillSwitches
+	jobs           Jobs
+	expirations    TrialExpirations
 }
 
 func (s storage) Instances() Instances {
@@ -95,3 +169,72 @@ func (s storage) Orchestrations() Orchestrations {
 func (s storage) RuntimeStates() RuntimeStates {
 	return s.runtimeStates
 }
+
+func (s storage) KymaChannels() KymaChannels {
+	return s.kymaChannels
+}
+
+func (s storage) OperationEvents() OperationEvents {
+	return s.events
+}
+
+func (s storage) InstallerOverrides() InstallerOverrides {
+	return s.overrides
+}
+
+func (s storage) InstancesArchived() InstancesArchived {
+	return s.archived
+}
+
+func (s storage) FreeTierUsage() FreeTierUsage {
+	return s.freeTierUsage
+}
+
+func (s storage) KillSwitches() KillSwitches {
+	return s.killSwitches
+}
+
+func (s storage) Jobs() Jobs {
+	return s.jobs
+}
+
+func (s storage) TrialExpirations() TrialExpirations {
+	return s.expirations
+}
+
+func (s storage) InTransaction(fn func(tx BrokerStorage) error) error {
+	if s.factory == nil {
+		return s.inMemoryTransaction(fn)
+	}
+
+	tx, dbErr := s.factory.NewSessionWithinTransaction()
+	if dbErr != nil {
+		return errors.Wrap(dbErr, "while starting transaction")
+	}
+	defer tx.RollbackUnlessCommitted()
+
+	if err := fn(newPostgresStorage(dbsession.NewTransactionFactory(s.factory, tx), s.encrypter)); err != nil {
+		return err
+	}
+	if dbErr := tx.Commit(); dbErr != nil {
+		return errors.Wrap(dbErr, "while committing transaction")
+	}
+	return nil
+}
+
+func (s storage) inMemoryTransaction(fn func(tx BrokerStorage) error) error {
+	s.memoryTx.Lock()
+	defer s.memoryTx.Unlock()
+
+	restores := make([]func(), 0, len(s.checkpoints))
+	for _, checkpoint := range s.checkpoints {
+		restores = append(restores, checkpoint())
+	}
+	if err := fn(s); err != nil {
+		for _, restore := range restores {
+			restore()
+		}
+		return err
+	}
+	return nil
+}
@@ -311,6 +311,59 @@ func TestSchemaInitializer(t *testing.T) {
 			assert.Equal(t, fixInstances[2].InstanceID, out[0].InstanceID)
 		})
 
+		t.Run("should list instances with their latest operation", func(t *testing.T) {
+			// given
+			containerCleanupFunc, cfg, err := InitTestDBContainer(t, ctx, "test_DB_1")
+			require.NoError(t, err)
+			defer containerCleanupFunc()
+
+			err = InitTestDBTables(t, cfg.ConnectionURL())
+			require.NoError(t, err)
+
+			psqlStorage, _, err := NewFromConfig(cfg, logrus.StandardLogger())
+			require.NoError(t, err)
+			require.NotNil(t, psqlStorage)
+
+			fixInstances := []internal.Instance{*fixInstance(instanceData{val: "A1"}), *fixInstance(instanceData{val: "B1"})}
+			for _, i := range fixInstances {
+				err = psqlStorage.Instances().Insert(i)
+				require.NoError(t, err)
+			}
+
+			provisioning := fixProvisionOperation("A1")
+			err = psqlStorage.Operations().InsertProvisioningOperation(provisioning)
+			require.NoError(t, err)
+
+			deprovisioning := fixDeprovisionOperation("A1")
+			deprovisioning.CreatedAt = provisioning.CreatedAt.Add(time.Hour)
+			err = psqlStorage.Operations().InsertDeprovisioningOperation(deprovisioning)
+			require.NoError(t, err)
+
+			// when
+			out, count, totalCount, err := psqlStorage.Instances().ListWithState(dbmodel.InstanceFilter{PageSize: 10, Page: 1})
+
+			// then
+			require.NoError(t, err)
+			assert.Equal(t, 2, count)
+			assert.Equal(t, 2, totalCount)
+
+			withOperations := out[0]
+			withoutOperations := out[1]
+			if out[0].InstanceID != "A1" {
+				withOperations, withoutOperations = out[1], out[0]
+			}
+
+			assertInstanceByIgnoreTime(t, fixInstances[0], withOperations.Instance)
+			require.NotNil(t, withOperations.LastOperation)
+			assert.Equal(t, deprovisioning.ID, withOperations.LastOperation.ID)
+			assert.Equal(t, string(dbmodel.OperationTypeDeprovision), withOperations.LastOperationType)
+			assert.Equal(t, domain.Succeeded, withOperations.LastOperation.State)
+			assert.False(t, withOperations.LastOperation.UpdatedAt.IsZero())
+
+			assertInstanceByIgnoreTime(t, fixInstances[1], withoutOperations.Instance)
+			assert.Nil(t, withoutOperations.LastOperation)
+		})
+
 		t.Run("should list instances based on filters", func(t *testing.T) {
 			// given
 			containerCleanupFunc, cfg, err := InitTestDBContainer(t, ctx, "test_DB_1")
@@ -457,6 +510,13 @@ func TestSchemaInitializer(t *testing.T) {
 			require.NoError(t, err)
 			assert.Equal(t, givenOperation.Operation.ID, op.ID)
 
+			op, err = svc.GetOperationByInstanceAndID("inst-id", "operation-id")
+			require.NoError(t, err)
+			assert.Equal(t, givenOperation.Operation.ID, op.ID)
+
+			_, err = svc.GetOperationByInstanceAndID("other-inst-id", "operation-id")
+			assert.True(t, dberr.IsNotFound(err))
+
 			// then
 			assertProvisioningOperation(t, givenOperation, *gotOperation)
 
@@ -482,6 +542,13 @@ func TestSchemaInitializer(t *testing.T) {
 
 			assert.Equal(t, 1, opStats[domain.InProgress])
 
+			from := givenOperation.CreatedAt.Truncate(time.Hour)
+			timeStats, err := svc.GetOperationTimeStats(from, 2*time.Hour, time.Hour)
+			require.NoError(t, err)
+
+			require.Len(t, timeStats.Buckets, 2)
+			assert.Equal(t, internal.OperationCounts{Started: 1}, timeStats.Buckets[0].Operations[string(dbmodel.OperationTypeProvision)])
+			assert.Empty(t, timeStats.Buckets[1].Operations)
 		})
 
 		t.Run("Deprovisioning", func(t *testing.T) {
@@ -609,12 +676,187 @@ func TestSchemaInitializer(t *testing.T) {
 
 			assertUpgradeKymaOperation(t, givenOperation2, *op)
 
-			ops, count, totalCount, err := svc.ListUpgradeKymaOperationsByOrchestrationID(orchestrationID, 10, 1)
+			ops, count, totalCount, err := svc.ListUpgradeKymaOperationsByOrchestrationID(orchestrationID, dbmodel.OperationFilter{}, 10, 1)
 			require.NoError(t, err)
 			assert.Len(t, ops, 2)
 			assert.Equal(t, count, 2)
 			assert.Equal(t, totalCount, 2)
 		})
+		t.Run("Upgrade cluster", func(t *testing.T) {
+			containerCleanupFunc, cfg, err := InitTestDBContainer(t, ctx, "test_DB_1")
+			require.NoError(t, err)
+			defer containerCleanupFunc()
+
+			orchestrationID := "orchestration-id"
+			givenOperation := internal.UpgradeClusterOperation{
+				RuntimeOperation: internal.RuntimeOperation{
+					Operation: internal.Operation{
+						ID:    "operation-id",
+						State: domain.InProgress,
+						// used Round and set timezone to be able to compare timestamps
+						CreatedAt:              time.Now().Truncate(time.Millisecond),
+						UpdatedAt:              time.Now().Truncate(time.Millisecond).Add(time.Second),
+						InstanceID:             "inst-id",
+						ProvisionerOperationID: "target-op-id",
+						Description:            "description",
+						Version:                1,
+						OrchestrationID:        orchestrationID,
+					},
+					ShootName:       "shoot-stage",
+					RuntimeID:       "runtime-id",
+					GlobalAccountID: "global-account-if",
+					SubAccountID:    "subaccount-id",
+				},
+				PlanID:                 "plan-id",
+				ProvisioningParameters: "{}",
+			}
+
+			err = InitTestDBTables(t, cfg.ConnectionURL())
+			require.NoError(t, err)
+
+			brokerStorage, _, err := NewFromConfig(cfg, logrus.StandardLogger())
+			require.NoError(t, err)
+
+			svc := brokerStorage.Operations()
+
+			// when
+			err = svc.InsertUpgradeClusterOperation(givenOperation)
+			require.NoError(t, err)
+
+			givenOperation.State = domain.Succeeded
+			updated, err := svc.UpdateUpgradeClusterOperation(givenOperation)
+			require.NoError(t, err)
+
+			// then
+			op, err := svc.GetUpgradeClusterOperationByID("operation-id")
+			require.NoError(t, err)
+			assert.Equal(t, domain.Succeeded, op.State)
+			assert.Equal(t, updated.Version, op.Version)
+			assert.Equal(t, "plan-id", op.PlanID)
+			assert.Equal(t, orchestrationID, op.OrchestrationID)
+
+			ops, err := svc.ListUpgradeClusterOperationsByInstanceID("inst-id")
+			require.NoError(t, err)
+			assert.Len(t, ops, 1)
+
+			ops, count, totalCount, err := svc.ListUpgradeClusterOperationsByOrchestrationID(orchestrationID, 10, 1)
+			require.NoError(t, err)
+			assert.Len(t, ops, 1)
+			assert.Equal(t, count, 1)
+			assert.Equal(t, totalCount, 1)
+
+			_, err = svc.GetUpgradeKymaOperationByID("operation-id")
+			assert.Error(t, err)
+		})
+
+		t.Run("List provisioning operations", func(t *testing.T) {
+			containerCleanupFunc, cfg, err := InitTestDBContainer(t, ctx, "test_DB_1")
+			require.NoError(t, err)
+			defer containerCleanupFunc()
+
+			err = InitTestDBTables(t, cfg.ConnectionURL())
+			require.NoError(t, err)
+
+			brokerStorage, _, err := NewFromConfig(cfg, logrus.StandardLogger())
+			require.NoError(t, err)
+
+			svc := brokerStorage.Operations()
+			now := time.Now().Truncate(time.Millisecond)
+			for i, fix := range []struct {
+				state     domain.LastOperationState
+				planID    string
+				createdAt time.Time
+			}{
+				{state: domain.Succeeded, planID: "gcp-plan", createdAt: now.Add(-3 * time.Hour)},
+				{state: domain.Failed, planID: "gcp-plan", createdAt: now.Add(-2 * time.Hour)},
+				{state: domain.Succeeded, planID: "azure-plan", createdAt: now.Add(-1 * time.Hour)},
+				{state: domain.Succeeded, planID: "gcp-plan", createdAt: now},
+			} {
+				err = svc.InsertProvisioningOperation(internal.ProvisioningOperation{
+					Operation: internal.Operation{
+						ID:         fmt.Sprintf("operation-%d", i),
+						InstanceID: fmt.Sprintf("inst-%d", i),
+						State:      fix.state,
+						CreatedAt:  fix.createdAt,
+						UpdatedAt:  fix.createdAt,
+					},
+					ProvisioningParameters: fmt.Sprintf(`{"plan_id": "%s"}`, fix.planID),
+				})
+				require.NoError(t, err)
+			}
+
+			// when
+			ops, count, totalCount, err := svc.ListProvisioningOperations(dbmodel.OperationFilter{
+				States:       []string{string(domain.Succeeded)},
+				PlanIDs:      []string{"gcp-plan"},
+				CreatedAfter: now.Add(-4 * time.Hour),
+			}, 1, 1)
+
+			// then
+			require.NoError(t, err)
+			require.Len(t, ops, 1)
+			assert.Equal(t, "operation-0", ops[0].ID)
+			assert.Equal(t, 1, count)
+			assert.Equal(t, 2, totalCount)
+
+			// when
+			ops, _, totalCount, err = svc.ListProvisioningOperations(dbmodel.OperationFilter{
+				CreatedAfter:  now.Add(-2 * time.Hour),
+				CreatedBefore: now,
+			}, 10, 1)
+
+			// then
+			require.NoError(t, err)
+			require.Len(t, ops, 2)
+			assert.Equal(t, "operation-1", ops[0].ID)
+			assert.Equal(t, "operation-2", ops[1].ID)
+			assert.Equal(t, 2, totalCount)
+		})
+
+		t.Run("List by instance ID", func(t *testing.T) {
+			containerCleanupFunc, cfg, err := InitTestDBContainer(t, ctx, "test_DB_1")
+			require.NoError(t, err)
+			defer containerCleanupFunc()
+
+			err = InitTestDBTables(t, cfg.ConnectionURL())
+			require.NoError(t, err)
+
+			brokerStorage, _, err := NewFromConfig(cfg, logrus.StandardLogger())
+			require.NoError(t, err)
+
+			svc := brokerStorage.Operations()
+			now := time.Now().Truncate(time.Millisecond)
+
+			// when
+			err = svc.InsertDeprovisioningOperation(internal.DeprovisioningOperation{
+				Operation: internal.Operation{ID: "deprovisioning-id", InstanceID: "inst-id", CreatedAt: now.Add(2 * time.Hour), UpdatedAt: now},
+			})
+			require.NoError(t, err)
+			err = svc.InsertProvisioningOperation(internal.ProvisioningOperation{
+				Operation:              internal.Operation{ID: "provisioning-id", InstanceID: "inst-id", CreatedAt: now, UpdatedAt: now},
+				ProvisioningParameters: "{}",
+			})
+			require.NoError(t, err)
+			err = svc.InsertUpgradeKymaOperation(internal.UpgradeKymaOperation{
+				RuntimeOperation: internal.RuntimeOperation{
+					Operation: internal.Operation{ID: "upgrade-id", InstanceID: "inst-id", CreatedAt: now.Add(time.Hour), UpdatedAt: now},
+				},
+			})
+			require.NoError(t, err)
+			err = svc.InsertProvisioningOperation(internal.ProvisioningOperation{
+				Operation:              internal.Operation{ID: "other-provisioning-id", InstanceID: "other-inst-id", CreatedAt: now, UpdatedAt: now},
+				ProvisioningParameters: "{}",
+			})
+			require.NoError(t, err)
+
+			// then
+			ops, err := svc.ListOperationsByInstanceID("inst-id")
+			require.NoError(t, err)
+			require.Len(t, ops, 3)
+			assert.Equal(t, "provisioning-id", ops[0].ID)
+			assert.Equal(t, "upgrade-id", ops[1].ID)
+			assert.Equal(t, "deprovisioning-id", ops[2].ID)
+		})
 	})
 
 	t.Run("Operations conflicts", func(t *testing.T) {
@@ -763,14 +1005,23 @@ func TestSchemaInitializer(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, givenOrchestration.Parameters, gotOrchestration.Parameters)
 
+		assert.Nil(t, gotOrchestration.Runtimes)
+
 		gotOrchestration.Description = "new modified description 1"
 		err = svc.Update(givenOrchestration)
 		require.NoError(t, err)
 
+		givenOrchestration.Runtimes = []internal.Runtime{{RuntimeID: "runtime-id", ShootName: "c-1234567"}}
+		err = svc.Update(givenOrchestration)
+		require.NoError(t, err)
+		gotOrchestration, err = svc.GetByID(fixID)
+		require.NoError(t, err)
+		assert.Equal(t, givenOrchestration.Runtimes, gotOrchestration.Runtimes)
+
 		err = svc.Insert(givenOrchestration)
 		assertError(t, dberr.CodeAlreadyExists, err)
 
-		l, count, totalCount, err := svc.List(10, 1)
+		l, count, totalCount, err := svc.List(dbmodel.OrchestrationFilter{PageSize: 10, Page: 1})
 		require.NoError(t, err)
 		assert.Len(t, l, 1)
 		assert.Equal(t, 1, count)
@@ -821,6 +1072,101 @@ func TestSchemaInitializer(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, fixID, state.KymaConfig.Version)
 		assert.Equal(t, fixID, state.ClusterConfig.KubernetesVersion)
+
+		// the state of the succeeded provisioning holds the current Kyma version and is kept
+		provisioning := fixProvisionOperation("succeeded")
+		err = brokerStorage.Operations().InsertProvisioningOperation(provisioning)
+		require.NoError(t, err)
+		succeededRuntimeState := givenRuntimeState
+		succeededRuntimeState.ID = "succeeded"
+		succeededRuntimeState.OperationID = provisioning.ID
+		succeededRuntimeState.CreatedAt = time.Now().Add(-72 * time.Hour)
+		err = svc.Insert(succeededRuntimeState)
+		require.NoError(t, err)
+
+		oldRuntimeState := givenRuntimeState
+		oldRuntimeState.ID = "old"
+		oldRuntimeState.OperationID = "old"
+		oldRuntimeState.CreatedAt = time.Now().Add(-48 * time.Hour)
+		err = svc.Insert(oldRuntimeState)
+		require.NoError(t, err)
+
+		// the newest state of another runtime is kept
+		otherRuntimeState := givenRuntimeState
+		otherRuntimeState.ID = "other"
+		otherRuntimeState.RuntimeID = "other"
+		otherRuntimeState.OperationID = "other"
+		otherRuntimeState.CreatedAt = time.Now().Add(-48 * time.Hour)
+		err = svc.Insert(otherRuntimeState)
+		require.NoError(t, err)
+
+		deleted, err := svc.DeleteSupersededCreatedBefore(time.Now().Add(-24 * time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 1, deleted)
+		_, err = svc.GetByOperationID("old")
+		assertError(t, dberr.CodeNotFound, err)
+		_, err = svc.GetByOperationID(provisioning.ID)
+		require.NoError(t, err)
+		_, err = svc.GetByOperationID("other")
+		require.NoError(t, err)
+
+		err = svc.DeleteByOperationID(fixID)
+		require.NoError(t, err)
+		_, err = svc.GetByOperationID(fixID)
+		assertError(t, dberr.CodeNotFound, err)
+	})
+
+	t.Run("Operation events", func(t *testing.T) {
+		containerCleanupFunc, cfg, err := InitTestDBContainer(t, ctx, "test_DB_1")
+		require.NoError(t, err)
+		defer containerCleanupFunc()
+
+		err = InitTestDBTables(t, cfg.ConnectionURL())
+		require.NoError(t, err)
+
+		brokerStorage, _, err := NewFromConfig(cfg, logrus.StandardLogger())
+		require.NoError(t, err)
+
+		svc := brokerStorage.OperationEvents()
+		now := time.Now()
+		givenEvents := []internal.OperationEvent{
+			{
+				ID:          "second",
+				OperationID: "operation-id",
+				InstanceID:  "instance-id",
+				Actor:       "kyma-environment-broker",
+				StepName:    "Check_Runtime_Status",
+				OldState:    domain.InProgress,
+				NewState:    domain.Succeeded,
+				CreatedAt:   now,
+			},
+			{
+				ID:          "first",
+				OperationID: "operation-id",
+				InstanceID:  "instance-id",
+				Actor:       "kyma-environment-broker",
+				NewState:    domain.InProgress,
+				CreatedAt:   now.Add(-time.Minute),
+			},
+		}
+		for _, event := range givenEvents {
+			err = svc.InsertEvent(event)
+			require.NoError(t, err)
+		}
+
+		// when
+		events, err := svc.ListEventsByOperationID("operation-id")
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		assert.Equal(t, "first", events[0].ID)
+		assert.Equal(t, domain.LastOperationState(""), events[0].OldState)
+		assert.Equal(t, "second", events[1].ID)
+		assert.Equal(t, "Check_Runtime_Status", events[1].StepName)
+
+		err = svc.InsertEvent(givenEvents[0])
+		assertError(t, dberr.CodeAlreadyExists, err)
 	})
 
 	t.Run("LMS Tenants", func(t *testing.T) {
@@ -857,6 +1203,208 @@ func TestSchemaInitializer(t *testing.T) {
 		assert.False(t, differentNameExists)
 		assert.NoError(t, dnErr)
 	})
+
+	t.Run("Kyma channels", func(t *testing.T) {
+		containerCleanupFunc, cfg, err := InitTestDBContainer(t, ctx, "test_DB_1")
+		require.NoError(t, err)
+		defer containerCleanupFunc()
+
+		err = InitTestDBTables(t, cfg.ConnectionURL())
+		require.NoError(t, err)
+
+		brokerStorage, _, err := NewFromConfig(cfg, logrus.StandardLogger())
+		require.NoError(t, err)
+		require.NotNil(t, brokerStorage)
+		svc := brokerStorage.KymaChannels()
+
+		// when
+		err = svc.UpsertSubscription(internal.KymaChannelSubscription{GlobalAccountID: "ga-id", Channel: "regular"})
+		require.NoError(t, err)
+		err = svc.UpsertSubscription(internal.KymaChannelSubscription{GlobalAccountID: "ga-id", Channel: "fast"})
+		require.NoError(t, err)
+
+		gotSub, found, err := svc.GetSubscription("ga-id")
+		_, otherExists, otherErr := svc.GetSubscription("other-ga-id")
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, "fast", gotSub.Channel)
+		assert.NoError(t, otherErr)
+		assert.False(t, otherExists)
+	})
+
+	t.Run("Instances archived", func(t *testing.T) {
+		containerCleanupFunc, cfg, err := InitTestDBContainer(t, ctx, "test_DB_1")
+		require.NoError(t, err)
+		defer containerCleanupFunc()
+
+		err = InitTestDBTables(t, cfg.ConnectionURL())
+		require.NoError(t, err)
+
+		brokerStorage, _, err := NewFromConfig(cfg, logrus.StandardLogger())
+		require.NoError(t, err)
+		require.NotNil(t, brokerStorage)
+		svc := brokerStorage.InstancesArchived()
+
+		fixInstance := internal.Instance{
+			InstanceID:             "instance-id",
+			RuntimeID:              "runtime-id",
+			GlobalAccountID:        "ga-id",
+			SubAccountID:           "sa-id",
+			ServicePlanName:        "azure",
+			ProvisioningParameters: "{}",
+			CreatedAt:              time.Now(),
+			UpdatedAt:              time.Now(),
+		}
+		archived := internal.ArchivedInstance{
+			Instance: fixInstance,
+			Operations: []internal.ArchivedOperation{
+				{Operation: internal.Operation{ID: "p-id", InstanceID: "instance-id", State: domain.Succeeded}, Type: "provision"},
+				{Operation: internal.Operation{ID: "d-id", InstanceID: "instance-id", State: domain.Succeeded}, Type: "deprovision"},
+			},
+			ArchivedAt: time.Now(),
+		}
+
+		// when
+		err = svc.Insert(archived)
+		require.NoError(t, err)
+		err = svc.Insert(archived)
+		assertError(t, dberr.CodeAlreadyExists, err)
+
+		got, err := svc.GetByID("instance-id")
+		require.NoError(t, err)
+		list, count, totalCount, err := svc.List(dbmodel.InstanceFilter{GlobalAccountIDs: []string{"ga-id"}, PageSize: 10, Page: 1})
+		require.NoError(t, err)
+		empty, _, _, err := svc.List(dbmodel.InstanceFilter{GlobalAccountIDs: []string{"other-ga-id"}, PageSize: 10, Page: 1})
+		require.NoError(t, err)
+
+		// then
+		assert.Equal(t, "runtime-id", got.RuntimeID)
+		require.Len(t, got.Operations, 2)
+		assert.Equal(t, "d-id", got.Operations[1].ID)
+		assert.Equal(t, "deprovision", got.Operations[1].Type)
+		assert.Len(t, list, 1)
+		assert.Equal(t, 1, count)
+		assert.Equal(t, 1, totalCount)
+		assert.Empty(t, empty)
+	})
+
+	t.Run("Installer overrides", func(t *testing.T) {
+		containerCleanupFunc, cfg, err := InitTestDBContainer(t, ctx, "test_DB_1")
+		require.NoError(t, err)
+		defer containerCleanupFunc()
+
+		err = InitTestDBTables(t, cfg.ConnectionURL())
+		require.NoError(t, err)
+
+		brokerStorage, _, err := NewFromConfig(cfg, logrus.StandardLogger())
+		require.NoError(t, err)
+		require.NotNil(t, brokerStorage)
+		svc := brokerStorage.InstallerOverrides()
+
+		// when
+		err = svc.UpsertOverrides(internal.InstallerOverrides{
+			GlobalAccountID: "ga-id",
+			GlobalOverrides: map[string]string{"global.domain": "example.com"},
+		})
+		require.NoError(t, err)
+		err = svc.UpsertOverrides(internal.InstallerOverrides{
+			GlobalAccountID:    "ga-id",
+			Components:         map[string]bool{"kiali": true},
+			ComponentOverrides: map[string]map[string]string{"monitoring": {"prometheus.resources.limits.memory": "4Gi"}},
+		})
+		require.NoError(t, err)
+
+		gotOverrides, found, err := svc.GetOverrides("ga-id")
+		_, otherExists, otherErr := svc.GetOverrides("other-ga-id")
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, map[string]bool{"kiali": true}, gotOverrides.Components)
+		assert.Equal(t, "4Gi", gotOverrides.ComponentOverrides["monitoring"]["prometheus.resources.limits.memory"])
+		assert.Empty(t, gotOverrides.GlobalOverrides)
+		assert.NoError(t, otherErr)
+		assert.False(t, otherExists)
+
+		// when
+		err = svc.DeleteOverrides("ga-id")
+		require.NoError(t, err)
+		_, found, err = svc.GetOverrides("ga-id")
+
+		// then
+		require.NoError(t, err)
+		assert.False(t, found)
+	})
+
+	t.Run("Kill switches", func(t *testing.T) {
+		containerCleanupFunc, cfg, err := InitTestDBContainer(t, ctx, "test_DB_1")
+		require.NoError(t, err)
+		defer containerCleanupFunc()
+
+		err = InitTestDBTables(t, cfg.ConnectionURL())
+		require.NoError(t, err)
+
+		brokerStorage, _, err := NewFromConfig(cfg, logrus.StandardLogger())
+		require.NoError(t, err)
+		require.NotNil(t, brokerStorage)
+		svc := brokerStorage.KillSwitches()
+		now := time.Now()
+
+		// when
+		err = svc.InsertEvent(internal.KillSwitchEvent{
+			ID:        "enable-id",
+			Scope:     "region",
+			Name:      "westeurope",
+			User:      "operator",
+			CreatedAt: now,
+		})
+		require.NoError(t, err)
+		err = svc.InsertEvent(internal.KillSwitchEvent{
+			ID:        "disable-id",
+			Scope:     "region",
+			Name:      "westeurope",
+			Disabled:  true,
+			Message:   "the region is under maintenance",
+			User:      "operator",
+			CreatedAt: now.Add(-time.Hour),
+		})
+		require.NoError(t, err)
+		duplicateErr := svc.InsertEvent(internal.KillSwitchEvent{ID: "enable-id", CreatedAt: now})
+
+		events, err := svc.ListEvents()
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, dberr.IsAlreadyExists(duplicateErr))
+		require.Len(t, events, 2)
+		assert.Equal(t, "disable-id", events[0].ID)
+		assert.True(t, events[0].Disabled)
+		assert.Equal(t, "the region is under maintenance", events[0].Message)
+		assert.Equal(t, "operator", events[0].User)
+		assert.Equal(t, "enable-id", events[1].ID)
+		assert.False(t, events[1].Disabled)
+
+		// when
+		err = svc.InsertEvent(internal.KillSwitchEvent{
+			ID:        "plan-id",
+			Scope:     "plan",
+			Name:      "azure",
+			Disabled:  true,
+			Message:   "maintenance",
+			User:      "operator",
+			CreatedAt: now,
+		})
+		require.NoError(t, err)
+		latest, err := svc.ListLatestEvents()
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, latest, 2)
+		assert.Equal(t, "plan-id", latest[0].ID)
+		assert.Equal(t, "enable-id", latest[1].ID)
+	})
 }
 
 func assertProvisioningOperation(t *testing.T, expected, got internal.ProvisioningOperation) {
@@ -10,6 +10,7 @@ import (
 
 	"github.com/sirupsen/logrus"
 
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession/dbmodel"
 	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/postsql"
 
 	"github.com/gocraft/dbr"
@@ -126,6 +127,15 @@ func InitTestDBTables(t *testing.T, connectionURL string) error {
 		t.Logf("Table %s added to database", name)
 	}
 
+	// views are created once all tables exist
+	for name, v := range FixViews() {
+		if _, err := connection.Exec(v); err != nil {
+			t.Logf("Cannot create view %s", name)
+			return err
+		}
+		t.Logf("View %s added to database", name)
+	}
+
 	return nil
 }
 
@@ -211,10 +221,16 @@ func FixTables() map[string]string {
 			dashboard_url varchar(255) NOT NULL,
 			provisioning_parameters text NOT NULL,
 			provider_region varchar(32) NOT NULL,
+			api_server_url varchar(255) NOT NULL DEFAULT '',
+			ca_bundle text NOT NULL DEFAULT '',
+			shoot_name varchar(255) NOT NULL DEFAULT '',
+			original_global_account_id varchar(255) NOT NULL DEFAULT '',
 			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
 			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
 			deleted_at TIMESTAMPTZ NOT NULL DEFAULT '0001-01-01 00:00:00+00'
-		)`, postsql.InstancesTableName),
+		);
+		CREATE UNIQUE INDEX IF NOT EXISTS %s ON %s (sub_account_id) WHERE service_plan_id = '%s'`,
+			postsql.InstancesTableName, dbmodel.TrialSubAccountIndexName, postsql.InstancesTableName, dbmodel.TrialPlanID),
 		postsql.OperationTableName: fmt.Sprintf(
 			`CREATE TABLE IF NOT EXISTS %s (
 			id varchar(255) PRIMARY KEY,
@@ -226,12 +242,17 @@ func FixTables() map[string]string {
 			type varchar(32) NOT NULL,
 			data json NOT NULL,
 			orchestration_id varchar(64),
+			correlation_id varchar(64),
+			stage varchar(64) NOT NULL DEFAULT '',
+			stage_started_at TIMESTAMPTZ,
+			retried_at TIMESTAMPTZ,
 			created_at TIMESTAMPTZ NOT NULL,
 			updated_at TIMESTAMPTZ NOT NULL
 			)`, postsql.OperationTableName),
 		postsql.OrchestrationTableName: fmt.Sprintf(
 			`CREATE TABLE IF NOT EXISTS %s (
 			orchestration_id varchar(255) PRIMARY KEY,
+			type varchar(32) NOT NULL DEFAULT 'upgradeKyma',
 			state varchar(32) NOT NULL,
 			description text,
 			parameters text NOT NULL,
@@ -247,6 +268,98 @@ func FixTables() map[string]string {
 			created_at TIMESTAMPTZ NOT NULL,
             unique (name, region)
 			)`, postsql.LMSTenantTableName),
+		postsql.KymaChannelTableName: fmt.Sprintf(
+			`CREATE TABLE IF NOT EXISTS %s (
+			global_account_id varchar(255) PRIMARY KEY,
+			channel varchar(32) NOT NULL,
+			updated_at TIMESTAMPTZ NOT NULL
+			)`, postsql.KymaChannelTableName),
+		postsql.OperationEventTableName: fmt.Sprintf(
+			`CREATE TABLE IF NOT EXISTS %s (
+			id varchar(255) PRIMARY KEY,
+			operation_id varchar(255) NOT NULL,
+			instance_id varchar(255) NOT NULL,
+			actor varchar(255) NOT NULL,
+			step_name varchar(255),
+			old_state varchar(32),
+			new_state varchar(32) NOT NULL,
+			description text,
+			created_at TIMESTAMPTZ NOT NULL
+			)`, postsql.OperationEventTableName),
+		postsql.InstancesArchivedTableName: fmt.Sprintf(
+			`CREATE TABLE IF NOT EXISTS %s (
+			instance_id varchar(255) PRIMARY KEY,
+			runtime_id varchar(255) NOT NULL,
+			global_account_id varchar(255) NOT NULL,
+			sub_account_id varchar(255) NOT NULL,
+			service_id varchar(255) NOT NULL,
+			service_name varchar(255) NOT NULL,
+			service_plan_id varchar(255) NOT NULL,
+			service_plan_name varchar(255) NOT NULL,
+			dashboard_url varchar(255) NOT NULL,
+			provisioning_parameters text NOT NULL,
+			provider_region varchar(32) NOT NULL,
+			api_server_url varchar(255) NOT NULL DEFAULT '',
+			ca_bundle text NOT NULL DEFAULT '',
+			shoot_name varchar(255) NOT NULL DEFAULT '',
+			created_at TIMESTAMPTZ NOT NULL,
+			updated_at TIMESTAMPTZ NOT NULL,
+			deleted_at TIMESTAMPTZ NOT NULL,
+			operations text NOT NULL,
+			archived_at TIMESTAMPTZ NOT NULL
+			)`, postsql.InstancesArchivedTableName),
+		postsql.FreeTierUsageTableName: fmt.Sprintf(
+			`CREATE TABLE IF NOT EXISTS %s (
+			instance_id varchar(255) PRIMARY KEY,
+			global_account_id varchar(255) NOT NULL,
+			plan_id varchar(255) NOT NULL,
+			started_at TIMESTAMPTZ NOT NULL,
+			finished_at TIMESTAMPTZ NOT NULL
+			)`, postsql.FreeTierUsageTableName),
+		postsql.KillSwitchEventTableName: fmt.Sprintf(
+			`CREATE TABLE IF NOT EXISTS %s (
+			id varchar(255) PRIMARY KEY,
+			scope varchar(32) NOT NULL,
+			name varchar(255) NOT NULL,
+			disabled boolean NOT NULL,
+			message text NOT NULL,
+			changed_by varchar(255) NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL
+			);
+			CREATE INDEX IF NOT EXISTS kill_switch_events_scope_name_created_at_idx ON %s (scope, name, created_at)`,
+			postsql.KillSwitchEventTableName, postsql.KillSwitchEventTableName),
+		postsql.JobLockTableName: fmt.Sprintf(
+			`CREATE TABLE IF NOT EXISTS %s (
+			job_name varchar(255) PRIMARY KEY,
+			owner varchar(255) NOT NULL,
+			locked_until TIMESTAMPTZ NOT NULL
+			)`, postsql.JobLockTableName),
+		postsql.JobRunTableName: fmt.Sprintf(
+			`CREATE TABLE IF NOT EXISTS %s (
+			id varchar(255) PRIMARY KEY,
+			job_name varchar(255) NOT NULL,
+			owner varchar(255) NOT NULL,
+			trigger varchar(32) NOT NULL,
+			state varchar(32) NOT NULL,
+			error text NOT NULL,
+			details text NOT NULL DEFAULT '',
+			started_at TIMESTAMPTZ NOT NULL,
+			finished_at TIMESTAMPTZ NOT NULL
+			)`, postsql.JobRunTableName),
+		postsql.TrialExpirationTableName: fmt.Sprintf(
+			`CREATE TABLE IF NOT EXISTS %s (
+			instance_id varchar(255) PRIMARY KEY,
+			expires_at TIMESTAMPTZ NOT NULL,
+			notified_at TIMESTAMPTZ NOT NULL,
+			deprovisioned_at TIMESTAMPTZ NOT NULL,
+			updated_at TIMESTAMPTZ NOT NULL
+			)`, postsql.TrialExpirationTableName),
+		postsql.InstallerOverridesTableName: fmt.Sprintf(
+			`CREATE TABLE IF NOT EXISTS %s (
+			global_account_id varchar(255) PRIMARY KEY,
+			data text NOT NULL,
+			updated_at TIMESTAMPTZ NOT NULL
+			)`, postsql.InstallerOverridesTableName),
 		postsql.RuntimeStateTableName: fmt.Sprintf(
 			`CREATE TABLE IF NOT EXISTS %s (
 			id varchar(255) PRIMARY KEY,
@@ -260,3 +373,30 @@ func FixTables() map[string]string {
 			)`, postsql.RuntimeStateTableName),
 	}
 }
+
+func FixViews() map[string]string {
+	return map[string]string{
+		postsql.InstancesWithStateViewName: fmt.Sprintf(
+			`CREATE OR REPLACE VIEW %s AS
+			SELECT
+				%s.*,
+				last_operation.id AS last_operation_id,
+				last_operation.type AS last_operation_type,
+				last_operation.state AS last_operation_state,
+				last_operation.version AS last_operation_version,
+				last_operation.description AS last_operation_description,
+				last_operation.orchestration_id AS last_operation_orchestration_id,
+				last_operation.created_at AS last_operation_created_at,
+				last_operation.updated_at AS last_operation_updated_at
+			FROM %s
+			LEFT JOIN LATERAL (
+				SELECT id, type, state, version, description, orchestration_id, created_at, updated_at
+				FROM %s
+				WHERE %s.instance_id = %s.instance_id
+				ORDER BY created_at DESC
+				LIMIT 1
+			) last_operation ON true`,
+			postsql.InstancesWithStateViewName, postsql.InstancesTableName, postsql.InstancesTableName,
+			postsql.OperationTableName, postsql.OperationTableName, postsql.InstancesTableName),
+	}
+}
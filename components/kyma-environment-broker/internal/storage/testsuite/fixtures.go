@@ -0,0 +1,195 @@
+package testsuite
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/ptr"
+
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+)
+
+const (
+	fixGlobalAccountID = "global-account-id"
+	fixPlanID          = "plan-id"
+	fixOtherPlanID     = "other-plan-id"
+)
+
+// fixTime returns the current time with the precision kept by all drivers
+func fixTime() time.Time {
+	return time.Now().Truncate(time.Millisecond)
+}
+
+func fixInstance(id string, createdAt time.Time) internal.Instance {
+	return internal.Instance{
+		InstanceID:             id,
+		RuntimeID:              fmt.Sprintf("runtime-%s", id),
+		GlobalAccountID:        fixGlobalAccountID,
+		SubAccountID:           fmt.Sprintf("subaccount-%s", id),
+		ServiceID:              "service-id",
+		ServiceName:            "kymaruntime",
+		ServicePlanID:          fixPlanID,
+		ServicePlanName:        "azure",
+		DashboardURL:           fmt.Sprintf("https://console.%s.kyma.local", id),
+		ProvisioningParameters: "{}",
+		ProviderRegion:         "westeurope",
+		CreatedAt:              createdAt,
+		UpdatedAt:              createdAt,
+	}
+}
+
+func fixOperation(id, instanceID string, state domain.LastOperationState, createdAt time.Time) internal.Operation {
+	return internal.Operation{
+		ID:          id,
+		InstanceID:  instanceID,
+		State:       state,
+		Description: fmt.Sprintf("operation %s", id),
+		CreatedAt:   createdAt,
+		UpdatedAt:   createdAt,
+	}
+}
+
+func fixProvisioningOperation(id, instanceID string, state domain.LastOperationState, createdAt time.Time) internal.ProvisioningOperation {
+	return internal.ProvisioningOperation{
+		Operation:              fixOperation(id, instanceID, state, createdAt),
+		ProvisioningParameters: fmt.Sprintf(`{"plan_id":"%s"}`, fixPlanID),
+		RuntimeID:              fmt.Sprintf("runtime-%s", instanceID),
+	}
+}
+
+func fixDeprovisioningOperation(id, instanceID string, state domain.LastOperationState, createdAt time.Time) internal.DeprovisioningOperation {
+	return internal.DeprovisioningOperation{
+		Operation:              fixOperation(id, instanceID, state, createdAt),
+		ProvisioningParameters: "{}",
+		RuntimeID:              fmt.Sprintf("runtime-%s", instanceID),
+	}
+}
+
+func fixUpgradeKymaOperation(id, instanceID string, state domain.LastOperationState, createdAt time.Time) internal.UpgradeKymaOperation {
+	return internal.UpgradeKymaOperation{
+		RuntimeOperation: internal.RuntimeOperation{
+			Operation: fixOperation(id, instanceID, state, createdAt),
+			RuntimeID: fmt.Sprintf("runtime-%s", instanceID),
+		},
+		PlanID:                 fixPlanID,
+		ProvisioningParameters: "{}",
+	}
+}
+
+func fixUpgradeClusterOperation(id, instanceID string, state domain.LastOperationState, createdAt time.Time) internal.UpgradeClusterOperation {
+	return internal.UpgradeClusterOperation{
+		RuntimeOperation: internal.RuntimeOperation{
+			Operation: fixOperation(id, instanceID, state, createdAt),
+			RuntimeID: fmt.Sprintf("runtime-%s", instanceID),
+		},
+		PlanID:                 fixPlanID,
+		ProvisioningParameters: "{}",
+	}
+}
+
+func fixPlanMigrationOperation(id, instanceID string, state domain.LastOperationState, createdAt time.Time) internal.PlanMigrationOperation {
+	return internal.PlanMigrationOperation{
+		Operation:              fixOperation(id, instanceID, state, createdAt),
+		SourcePlanID:           fixPlanID,
+		SourceRuntimeID:        fmt.Sprintf("runtime-%s", instanceID),
+		ProvisioningParameters: fmt.Sprintf(`{"plan_id":"%s"}`, fixOtherPlanID),
+	}
+}
+
+func fixUpdatingOperation(id, instanceID string, state domain.LastOperationState, createdAt time.Time) internal.UpdatingOperation {
+	return internal.UpdatingOperation{
+		Operation: fixOperation(id, instanceID, state, createdAt),
+		RuntimeID: fmt.Sprintf("runtime-%s", instanceID),
+		UpdatingParameters: internal.UpdatingParametersDTO{
+			AutoScalerMin: ptr.Integer(3),
+			AutoScalerMax: ptr.Integer(6),
+		},
+	}
+}
+
+func fixSuspensionOperation(id, instanceID string, state domain.LastOperationState, createdAt time.Time) internal.SuspensionOperation {
+	return internal.SuspensionOperation{
+		Operation: fixOperation(id, instanceID, state, createdAt),
+		RuntimeID: fmt.Sprintf("runtime-%s", instanceID),
+		ShootName: fmt.Sprintf("shoot-%s", instanceID),
+		Action:    internal.SuspensionActionSuspend,
+	}
+}
+
+func fixAccountMigrationOperation(id, instanceID string, state domain.LastOperationState, createdAt time.Time) internal.AccountMigrationOperation {
+	return internal.AccountMigrationOperation{
+		Operation:             fixOperation(id, instanceID, state, createdAt),
+		RuntimeID:             fmt.Sprintf("runtime-%s", instanceID),
+		ShootName:             fmt.Sprintf("shoot-%s", instanceID),
+		Tenant:                "ga",
+		SourceGlobalAccountID: "ga",
+		SourceSubAccountID:    "sa",
+		TargetGlobalAccountID: "new-ga",
+		TargetSubAccountID:    "new-sa",
+	}
+}
+
+func instanceIDs(instances []internal.Instance) []string {
+	ids := make([]string, 0, len(instances))
+	for _, instance := range instances {
+		ids = append(ids, instance.InstanceID)
+	}
+	return ids
+}
+
+func instanceWithStateIDs(instances []internal.InstanceWithState) []string {
+	ids := make([]string, 0, len(instances))
+	for _, instance := range instances {
+		ids = append(ids, instance.InstanceID)
+	}
+	return ids
+}
+
+func operationIDs(ops []internal.Operation) []string {
+	ids := make([]string, 0, len(ops))
+	for _, op := range ops {
+		ids = append(ids, op.ID)
+	}
+	return ids
+}
+
+func provisioningOperationIDs(ops []internal.ProvisioningOperation) []string {
+	ids := make([]string, 0, len(ops))
+	for _, op := range ops {
+		ids = append(ids, op.ID)
+	}
+	return ids
+}
+
+func upgradeKymaOperationIDs(ops []internal.UpgradeKymaOperation) []string {
+	ids := make([]string, 0, len(ops))
+	for _, op := range ops {
+		ids = append(ids, op.ID)
+	}
+	return ids
+}
+
+func upgradeClusterOperationIDs(ops []internal.UpgradeClusterOperation) []string {
+	ids := make([]string, 0, len(ops))
+	for _, op := range ops {
+		ids = append(ids, op.ID)
+	}
+	return ids
+}
+
+func orchestrationIDs(orchestrations []internal.Orchestration) []string {
+	ids := make([]string, 0, len(orchestrations))
+	for _, o := range orchestrations {
+		ids = append(ids, o.OrchestrationID)
+	}
+	return ids
+}
+
+func jobRunIDs(runs []internal.JobRun) []string {
+	ids := make([]string, 0, len(runs))
+	for _, run := range runs {
+		ids = append(ids, run.ID)
+	}
+	return ids
+}
@@ -0,0 +1,410 @@
+package testsuite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession/dbmodel"
+
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testInstanceLifecycle(t *testing.T, brokerStorage storage.BrokerStorage) {
+	// given
+	svc := brokerStorage.Instances()
+	instance := fixInstance("instance-id", fixTime())
+
+	// when
+	err := svc.Insert(instance)
+
+	// then
+	require.NoError(t, err)
+	got, err := svc.GetByID(instance.InstanceID)
+	require.NoError(t, err)
+	assert.Equal(t, instance.InstanceID, got.InstanceID)
+	assert.Equal(t, instance.RuntimeID, got.RuntimeID)
+	assert.Equal(t, instance.GlobalAccountID, got.GlobalAccountID)
+	assert.Equal(t, instance.SubAccountID, got.SubAccountID)
+	assert.Equal(t, instance.ServicePlanID, got.ServicePlanID)
+	assert.Equal(t, instance.ServicePlanName, got.ServicePlanName)
+	assert.Equal(t, instance.DashboardURL, got.DashboardURL)
+	assert.Equal(t, instance.ProviderRegion, got.ProviderRegion)
+
+	// when
+	err = svc.Insert(instance)
+
+	// then
+	assert.True(t, dberr.IsAlreadyExists(err), "the instance must not be inserted twice")
+
+	// when
+	instance.DashboardURL = "https://console.updated.kyma.local"
+	instance.OriginalGlobalAccountID = instance.GlobalAccountID
+	instance.GlobalAccountID = "migrated-global-account"
+	err = svc.Update(instance)
+
+	// then
+	require.NoError(t, err)
+	got, err = svc.GetByID(instance.InstanceID)
+	require.NoError(t, err)
+	assert.Equal(t, instance.DashboardURL, got.DashboardURL)
+	assert.Equal(t, instance.GlobalAccountID, got.GlobalAccountID)
+	assert.Equal(t, instance.OriginalGlobalAccountID, got.OriginalGlobalAccountID)
+
+	// when
+	err = svc.Delete(instance.InstanceID)
+
+	// then
+	require.NoError(t, err)
+	_, err = svc.GetByID(instance.InstanceID)
+	assert.True(t, dberr.IsNotFound(err))
+	assert.NoError(t, svc.Delete(instance.InstanceID), "the deletion of not existing instance must not fail")
+}
+
+func testTrialInstancePerSubAccount(t *testing.T, brokerStorage storage.BrokerStorage) {
+	// given
+	svc := brokerStorage.Instances()
+	now := fixTime()
+	trial := fixInstance("trial", now)
+	trial.ServicePlanID = dbmodel.TrialPlanID
+	require.NoError(t, svc.Insert(trial))
+
+	// when
+	otherTrial := fixInstance("other-trial", now)
+	otherTrial.ServicePlanID = dbmodel.TrialPlanID
+	otherTrial.SubAccountID = trial.SubAccountID
+	err := svc.Insert(otherTrial)
+
+	// then
+	assert.True(t, dberr.IsAlreadyExists(err), "only one trial instance is allowed per subaccount")
+	_, err = svc.GetByID(otherTrial.InstanceID)
+	assert.True(t, dberr.IsNotFound(err))
+
+	// when
+	paid := fixInstance("paid", now)
+	paid.SubAccountID = trial.SubAccountID
+	err = svc.Insert(paid)
+
+	// then
+	assert.NoError(t, err, "the instances of other plans are allowed in the subaccount with the trial instance")
+
+	// when
+	require.NoError(t, svc.Delete(trial.InstanceID))
+	err = svc.Insert(otherTrial)
+
+	// then
+	assert.NoError(t, err, "the trial instance is allowed when the previous one was removed")
+}
+
+func testFindInstances(t *testing.T, brokerStorage storage.BrokerStorage) {
+	// given
+	svc := brokerStorage.Instances()
+	now := fixTime()
+	for i, id := range []string{"instance-1", "instance-2", "instance-3"} {
+		require.NoError(t, svc.Insert(fixInstance(id, now.Add(time.Duration(i)*time.Minute))))
+	}
+
+	// when
+	instances, err := svc.FindAllInstancesForRuntimes([]string{"runtime-instance-1", "runtime-instance-3"})
+
+	// then
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"instance-1", "instance-3"}, instanceIDs(instances))
+
+	// when
+	_, err = svc.FindAllInstancesForRuntimes([]string{"not-existing-runtime"})
+
+	// then
+	assert.True(t, dberr.IsNotFound(err))
+
+	// when
+	instances, err = svc.FindAllInstancesForSubAccounts([]string{"subaccount-instance-2", "not-existing-subaccount"})
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []string{"instance-2"}, instanceIDs(instances))
+
+	// when
+	instances, err = svc.FindAllInstancesForSubAccounts([]string{"not-existing-subaccount"})
+
+	// then
+	require.NoError(t, err)
+	assert.Empty(t, instances)
+}
+
+func testInstanceStats(t *testing.T, brokerStorage storage.BrokerStorage) {
+	// given
+	svc := brokerStorage.Instances()
+	now := fixTime()
+	other := fixInstance("instance-3", now)
+	other.GlobalAccountID = "other-global-account-id"
+	for _, instance := range []internal.Instance{fixInstance("instance-1", now), fixInstance("instance-2", now), other} {
+		require.NoError(t, svc.Insert(instance))
+	}
+
+	// when
+	stats, err := svc.GetInstanceStats()
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, 3, stats.TotalNumberOfInstances)
+	assert.Equal(t, map[string]int{fixGlobalAccountID: 2, "other-global-account-id": 1}, stats.PerGlobalAccountID)
+
+	// when
+	count, err := svc.GetNumberOfInstancesForGlobalAccountID(fixGlobalAccountID)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	// when
+	count, err = svc.GetNumberOfInstancesForGlobalAccountID("not-existing-global-account-id")
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
+
+func testFindAllJoinedWithOperations(t *testing.T, brokerStorage storage.BrokerStorage) {
+	// given
+	now := fixTime()
+	require.NoError(t, brokerStorage.Instances().Insert(fixInstance("provisioned", now)))
+	require.NoError(t, brokerStorage.Instances().Insert(fixInstance("without-operations", now)))
+	require.NoError(t, brokerStorage.Operations().InsertProvisioningOperation(fixProvisioningOperation("provisioning", "provisioned", domain.Succeeded, now)))
+
+	// when
+	instances, err := brokerStorage.Instances().FindAllJoinedWithOperations()
+
+	// then
+	require.NoError(t, err)
+	require.Len(t, instances, 2)
+	for _, instance := range instances {
+		switch instance.InstanceID {
+		case "provisioned":
+			assert.Equal(t, string(dbmodel.OperationTypeProvision), instance.Type.String)
+			assert.Equal(t, string(domain.Succeeded), instance.State.String)
+		case "without-operations":
+			assert.False(t, instance.Type.Valid)
+		default:
+			t.Errorf("unexpected instance %s", instance.InstanceID)
+		}
+	}
+}
+
+func testListInstances(t *testing.T, brokerStorage storage.BrokerStorage) {
+	// given
+	svc := brokerStorage.Instances()
+	now := fixTime()
+	for i, id := range []string{"instance-1", "instance-2", "instance-3", "instance-4", "instance-5"} {
+		instance := fixInstance(id, now.Add(time.Duration(i)*time.Minute))
+		if i%2 == 1 {
+			instance.GlobalAccountID = "other-global-account-id"
+			instance.ProviderRegion = "eastus"
+		}
+		require.NoError(t, svc.Insert(instance))
+	}
+
+	// when
+	instances, count, totalCount, err := svc.List(dbmodel.InstanceFilter{PageSize: 2, Page: 1})
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []string{"instance-1", "instance-2"}, instanceIDs(instances))
+	assert.Equal(t, 2, count)
+	assert.Equal(t, 5, totalCount)
+
+	// when
+	instances, count, totalCount, err = svc.List(dbmodel.InstanceFilter{PageSize: 2, Page: 3})
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []string{"instance-5"}, instanceIDs(instances))
+	assert.Equal(t, 1, count)
+	assert.Equal(t, 5, totalCount)
+
+	// when
+	instances, count, totalCount, err = svc.List(dbmodel.InstanceFilter{})
+
+	// then
+	require.NoError(t, err)
+	assert.Len(t, instances, 5, "all instances are returned if the page is not specified")
+	assert.Equal(t, 5, count)
+	assert.Equal(t, 5, totalCount)
+
+	// when
+	instances, count, totalCount, err = svc.List(dbmodel.InstanceFilter{
+		PageSize:         10,
+		Page:             1,
+		GlobalAccountIDs: []string{"other-global-account-id"},
+	})
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []string{"instance-2", "instance-4"}, instanceIDs(instances))
+	assert.Equal(t, 2, count)
+	assert.Equal(t, 2, totalCount)
+
+	// when
+	instances, _, totalCount, err = svc.List(dbmodel.InstanceFilter{
+		PageSize:   10,
+		Page:       1,
+		Regions:    []string{"westeurope"},
+		RuntimeIDs: []string{"runtime-instance-1", "runtime-instance-2"},
+	})
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []string{"instance-1"}, instanceIDs(instances))
+	assert.Equal(t, 1, totalCount)
+
+	// when
+	instances, _, totalCount, err = svc.List(dbmodel.InstanceFilter{
+		PageSize: 10,
+		Page:     1,
+		Domains:  []string{"instance-3"},
+	})
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []string{"instance-3"}, instanceIDs(instances))
+	assert.Equal(t, 1, totalCount)
+}
+
+func testListInstancesWithCursor(t *testing.T, brokerStorage storage.BrokerStorage) {
+	// given
+	svc := brokerStorage.Instances()
+	now := fixTime()
+	// the instances created at the same time are ordered by the instance ID
+	for _, id := range []string{"instance-2", "instance-1", "instance-3"} {
+		require.NoError(t, svc.Insert(fixInstance(id, now)))
+	}
+	require.NoError(t, svc.Insert(fixInstance("instance-4", now.Add(time.Minute))))
+
+	// when
+	instances, count, totalCount, err := svc.ListWithState(dbmodel.InstanceFilter{
+		PageSize: 2,
+		After:    &dbmodel.InstanceCursor{CreatedAt: now.Add(-time.Minute), InstanceID: "instance-0"},
+	})
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []string{"instance-1", "instance-2"}, instanceWithStateIDs(instances))
+	assert.Equal(t, 2, count)
+	assert.Equal(t, 4, totalCount)
+
+	// given
+	// the instance created before the cursor does not shift the next page
+	require.NoError(t, svc.Insert(fixInstance("instance-0", now.Add(-time.Hour))))
+
+	// when
+	instances, count, totalCount, err = svc.ListWithState(dbmodel.InstanceFilter{
+		PageSize: 2,
+		Page:     5,
+		After:    &dbmodel.InstanceCursor{CreatedAt: now, InstanceID: "instance-2"},
+	})
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []string{"instance-3", "instance-4"}, instanceWithStateIDs(instances), "the page is ignored when the cursor is set")
+	assert.Equal(t, 2, count)
+	assert.Equal(t, 5, totalCount)
+
+	// when
+	list, _, _, err := svc.List(dbmodel.InstanceFilter{
+		After: &dbmodel.InstanceCursor{CreatedAt: now, InstanceID: "instance-1"},
+	})
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []string{"instance-2", "instance-3", "instance-4"}, instanceIDs(list), "all instances after the cursor are returned if the page size is not specified")
+}
+
+func testListInstancesWithSearch(t *testing.T, brokerStorage storage.BrokerStorage) {
+	// given
+	svc := brokerStorage.Instances()
+	now := fixTime()
+	for i, id := range []string{"alpha", "beta", "gamma"} {
+		require.NoError(t, svc.Insert(fixInstance(id, now.Add(time.Duration(i)*time.Minute))))
+	}
+	shoot := fixInstance("delta", now.Add(time.Hour))
+	shoot.DashboardURL = "https://console.c-4f2a9b1.kyma.local"
+	require.NoError(t, svc.Insert(shoot))
+	wildcard := fixInstance("wild_card", now.Add(2*time.Hour))
+	require.NoError(t, svc.Insert(wildcard))
+
+	for name, tc := range map[string]struct {
+		search   string
+		expected []string
+	}{
+		"instance ID":       {search: "gamma", expected: []string{"gamma"}},
+		"runtime ID":        {search: "runtime-beta", expected: []string{"beta"}},
+		"subaccount":        {search: "SUBACCOUNT-ALPHA", expected: []string{"alpha"}},
+		"global account":    {search: fixGlobalAccountID, expected: []string{"alpha", "beta", "gamma", "delta", "wild_card"}},
+		"shoot name":        {search: "c-4f2a9b1", expected: []string{"delta"}},
+		"literal wildcards": {search: "d_c", expected: []string{"wild_card"}},
+		"no match":          {search: "a%a", expected: []string{}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// when
+			instances, count, totalCount, err := svc.List(dbmodel.InstanceFilter{PageSize: 10, Page: 1, Search: tc.search})
+
+			// then
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, instanceIDs(instances))
+			assert.Equal(t, len(tc.expected), count)
+			assert.Equal(t, len(tc.expected), totalCount)
+		})
+	}
+}
+
+func testListInstancesWithState(t *testing.T, brokerStorage storage.BrokerStorage) {
+	// given
+	now := fixTime()
+	upgraded := fixInstance("upgraded", now)
+	upgraded.ProvisioningParameters = `{"parameters":{"name":"upgraded"}}`
+	upgraded.CABundle = "ca-bundle"
+	upgraded.ShootName = "c-upgraded"
+	require.NoError(t, brokerStorage.Instances().Insert(upgraded))
+	require.NoError(t, brokerStorage.Instances().Insert(fixInstance("without-operations", now.Add(time.Minute))))
+	require.NoError(t, brokerStorage.Operations().InsertProvisioningOperation(fixProvisioningOperation("provisioning", "upgraded", domain.Succeeded, now)))
+	require.NoError(t, brokerStorage.Operations().InsertUpgradeKymaOperation(fixUpgradeKymaOperation("upgrade", "upgraded", domain.InProgress, now.Add(time.Hour))))
+
+	// when
+	instances, count, totalCount, err := brokerStorage.Instances().ListWithState(dbmodel.InstanceFilter{PageSize: 10, Page: 1})
+
+	// then
+	require.NoError(t, err)
+	require.Len(t, instances, 2)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, 2, totalCount)
+
+	assert.Equal(t, "upgraded", instances[0].InstanceID)
+	assert.Equal(t, upgraded.ProvisioningParameters, instances[0].ProvisioningParameters)
+	assert.Equal(t, upgraded.ShootName, instances[0].ShootName)
+	require.NotNil(t, instances[0].LastOperation)
+	assert.Equal(t, "upgrade", instances[0].LastOperation.ID)
+	assert.Equal(t, domain.InProgress, instances[0].LastOperation.State)
+	assert.Equal(t, string(dbmodel.OperationTypeUpgradeKyma), instances[0].LastOperationType)
+
+	assert.Equal(t, "without-operations", instances[1].InstanceID)
+	assert.Nil(t, instances[1].LastOperation)
+	assert.Empty(t, instances[1].LastOperationType)
+
+	// when
+	instances, _, _, err = brokerStorage.Instances().ListWithState(dbmodel.InstanceFilter{PageSize: 10, Page: 1, SkipLargeColumns: true})
+
+	// then
+	require.NoError(t, err)
+	require.Len(t, instances, 2)
+	assert.Equal(t, "upgraded", instances[0].InstanceID)
+	assert.Equal(t, upgraded.ServicePlanID, instances[0].ServicePlanID)
+	assert.Equal(t, "{}", instances[0].ProvisioningParameters, "the provisioning parameters must not be loaded")
+	assert.Empty(t, instances[0].CABundle, "the CA bundle must not be loaded")
+	assert.Equal(t, upgraded.ShootName, instances[0].ShootName)
+	require.NotNil(t, instances[0].LastOperation)
+	assert.Equal(t, "upgrade", instances[0].LastOperation.ID)
+}
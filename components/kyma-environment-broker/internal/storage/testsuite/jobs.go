@@ -0,0 +1,148 @@
+package testsuite
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testJobLocks(t *testing.T, brokerStorage storage.BrokerStorage) {
+	// given
+	svc := brokerStorage.Jobs()
+	now := fixTime()
+
+	// when
+	acquired, err := svc.AcquireLock("job", "owner-1", now, now.Add(time.Minute))
+
+	// then
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	// when
+	acquired, err = svc.AcquireLock("job", "owner-2", now.Add(30*time.Second), now.Add(2*time.Minute))
+
+	// then
+	require.NoError(t, err)
+	assert.False(t, acquired, "the lock held by another owner must not be acquired")
+
+	// when
+	acquired, err = svc.AcquireLock("other-job", "owner-2", now, now.Add(time.Minute))
+
+	// then
+	require.NoError(t, err)
+	assert.True(t, acquired, "the locks of the jobs are independent")
+
+	// when
+	acquired, err = svc.AcquireLock("job", "owner-1", now.Add(30*time.Second), now.Add(2*time.Minute))
+
+	// then
+	require.NoError(t, err)
+	assert.True(t, acquired, "the owner extends its lock")
+
+	// when
+	acquired, err = svc.AcquireLock("job", "owner-2", now.Add(90*time.Second), now.Add(3*time.Minute))
+
+	// then
+	require.NoError(t, err)
+	assert.False(t, acquired, "the extended lock must not expire")
+
+	// when
+	acquired, err = svc.AcquireLock("job", "owner-2", now.Add(3*time.Minute), now.Add(4*time.Minute))
+
+	// then
+	require.NoError(t, err)
+	assert.True(t, acquired, "the expired lock is taken over")
+
+	// when
+	err = svc.ReleaseLock("job", "owner-1")
+
+	// then
+	require.NoError(t, err)
+	acquired, err = svc.AcquireLock("job", "owner-1", now.Add(3*time.Minute), now.Add(4*time.Minute))
+	require.NoError(t, err)
+	assert.False(t, acquired, "the lock must not be released by another owner")
+
+	// when
+	err = svc.ReleaseLock("job", "owner-2")
+
+	// then
+	require.NoError(t, err)
+	acquired, err = svc.AcquireLock("job", "owner-1", now.Add(3*time.Minute), now.Add(4*time.Minute))
+	require.NoError(t, err)
+	assert.True(t, acquired)
+}
+
+func testJobRuns(t *testing.T, brokerStorage storage.BrokerStorage) {
+	// given
+	svc := brokerStorage.Jobs()
+	now := fixTime()
+	for i := 0; i < 3; i++ {
+		// the runs are inserted in the reversed order of the start to verify the sorting
+		require.NoError(t, svc.InsertRun(internal.JobRun{
+			ID:        fmt.Sprintf("run-%d", i),
+			JobName:   "job",
+			Owner:     "owner",
+			Trigger:   "schedule",
+			State:     internal.Succeeded,
+			StartedAt: now.Add(-time.Duration(i) * time.Hour),
+		}))
+	}
+	require.NoError(t, svc.InsertRun(internal.JobRun{
+		ID:        "other-run",
+		JobName:   "other-job",
+		Owner:     "owner",
+		Trigger:   "manual",
+		State:     internal.InProgress,
+		StartedAt: now,
+	}))
+
+	// when
+	err := svc.InsertRun(internal.JobRun{ID: "run-0", JobName: "job", StartedAt: now})
+
+	// then
+	assert.True(t, dberr.IsAlreadyExists(err))
+
+	// when
+	runs, err := svc.ListRuns("job", 2)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []string{"run-0", "run-1"}, jobRunIDs(runs))
+
+	// when
+	err = svc.UpdateRun(internal.JobRun{ID: "other-run", State: internal.Failed, Error: "job failed", Details: `{"processed":1}`, FinishedAt: now.Add(time.Minute)})
+
+	// then
+	require.NoError(t, err)
+	runs, err = svc.ListRuns("other-job", 10)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, internal.Failed, runs[0].State)
+	assert.Equal(t, "job failed", runs[0].Error)
+	assert.Equal(t, `{"processed":1}`, runs[0].Details)
+	assert.Equal(t, "manual", runs[0].Trigger)
+	assert.True(t, now.Add(time.Minute).Equal(runs[0].FinishedAt))
+
+	// when
+	err = svc.UpdateRun(internal.JobRun{ID: "not-existing-id", State: internal.Failed})
+
+	// then
+	assert.True(t, dberr.IsNotFound(err))
+
+	// when
+	deleted, err := svc.DeleteRunsStartedBefore(now.Add(-30 * time.Minute))
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+	runs, err = svc.ListRuns("job", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"run-0"}, jobRunIDs(runs))
+}
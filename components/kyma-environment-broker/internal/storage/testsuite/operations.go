@@ -0,0 +1,829 @@
+package testsuite
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession/dbmodel"
+
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testProvisioningOperations(t *testing.T, brokerStorage storage.BrokerStorage) {
+	// given
+	svc := brokerStorage.Operations()
+	now := fixTime()
+	first := fixProvisioningOperation("first", "instance-id", domain.Failed, now)
+	latest := fixProvisioningOperation("latest", "instance-id", domain.InProgress, now.Add(time.Hour))
+
+	// when
+	require.NoError(t, svc.InsertProvisioningOperation(latest))
+	require.NoError(t, svc.InsertProvisioningOperation(first))
+	err := svc.InsertProvisioningOperation(first)
+
+	// then
+	assert.True(t, dberr.IsAlreadyExists(err), "the operation must not be inserted twice")
+
+	got, err := svc.GetProvisioningOperationByID(first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, first.InstanceID, got.InstanceID)
+	assert.Equal(t, first.State, got.State)
+	assert.Equal(t, first.Description, got.Description)
+	assert.Equal(t, first.ProvisioningParameters, got.ProvisioningParameters)
+	assert.Equal(t, first.RuntimeID, got.RuntimeID)
+
+	got, err = svc.GetProvisioningOperationByInstanceID("instance-id")
+	require.NoError(t, err)
+	assert.Equal(t, latest.ID, got.ID, "the latest provisioning operation of the instance is returned")
+
+	_, err = svc.GetProvisioningOperationByID("not-existing-id")
+	assert.Error(t, err)
+	_, err = svc.GetProvisioningOperationByInstanceID("not-existing-instance-id")
+	assert.True(t, dberr.IsNotFound(err))
+
+	// when
+	got.State = domain.Succeeded
+	updated, err := svc.UpdateProvisioningOperation(*got)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, got.Version+1, updated.Version)
+	got, err = svc.GetProvisioningOperationByID(latest.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.Succeeded, got.State)
+	assert.Equal(t, updated.Version, got.Version)
+
+	// when
+	_, err = svc.UpdateProvisioningOperation(latest)
+
+	// then
+	assert.True(t, dberr.IsConflict(err), "the update of the outdated operation must fail")
+}
+
+func testListProvisioningOperations(t *testing.T, brokerStorage storage.BrokerStorage) {
+	// given
+	svc := brokerStorage.Operations()
+	now := fixTime()
+	for i, state := range []domain.LastOperationState{domain.Succeeded, domain.Failed, domain.Succeeded, domain.Succeeded} {
+		op := fixProvisioningOperation(fmt.Sprintf("operation-%d", i), fmt.Sprintf("instance-%d", i), state, now.Add(time.Duration(i)*time.Minute))
+		if i == 3 {
+			op.ProvisioningParameters = fmt.Sprintf(`{"plan_id":"%s"}`, fixOtherPlanID)
+		}
+		require.NoError(t, svc.InsertProvisioningOperation(op))
+	}
+
+	// when
+	ops, count, totalCount, err := svc.ListProvisioningOperations(dbmodel.OperationFilter{}, 3, 1)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []string{"operation-0", "operation-1", "operation-2"}, provisioningOperationIDs(ops))
+	assert.Equal(t, 3, count)
+	assert.Equal(t, 4, totalCount)
+
+	// when
+	ops, count, totalCount, err = svc.ListProvisioningOperations(dbmodel.OperationFilter{
+		States:  []string{string(domain.Succeeded)},
+		PlanIDs: []string{fixPlanID},
+	}, 3, 1)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []string{"operation-0", "operation-2"}, provisioningOperationIDs(ops))
+	assert.Equal(t, 2, count)
+	assert.Equal(t, 2, totalCount)
+
+	// when
+	ops, _, totalCount, err = svc.ListProvisioningOperations(dbmodel.OperationFilter{
+		CreatedAfter:  now.Add(time.Minute),
+		CreatedBefore: now.Add(3 * time.Minute),
+	}, 3, 1)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []string{"operation-1", "operation-2"}, provisioningOperationIDs(ops))
+	assert.Equal(t, 2, totalCount)
+}
+
+func testDeprovisioningOperations(t *testing.T, brokerStorage storage.BrokerStorage) {
+	// given
+	svc := brokerStorage.Operations()
+	now := fixTime()
+	first := fixDeprovisioningOperation("first", "instance-id", domain.Failed, now)
+	latest := fixDeprovisioningOperation("latest", "instance-id", domain.InProgress, now.Add(time.Hour))
+
+	// when
+	require.NoError(t, svc.InsertDeprovisioningOperation(latest))
+	require.NoError(t, svc.InsertDeprovisioningOperation(first))
+	err := svc.InsertDeprovisioningOperation(first)
+
+	// then
+	assert.True(t, dberr.IsAlreadyExists(err), "the operation must not be inserted twice")
+
+	got, err := svc.GetDeprovisioningOperationByID(first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, first.InstanceID, got.InstanceID)
+	assert.Equal(t, first.State, got.State)
+	assert.Equal(t, first.RuntimeID, got.RuntimeID)
+
+	got, err = svc.GetDeprovisioningOperationByInstanceID("instance-id")
+	require.NoError(t, err)
+	assert.Equal(t, latest.ID, got.ID, "the latest deprovisioning operation of the instance is returned")
+
+	_, err = svc.GetDeprovisioningOperationByID("not-existing-id")
+	assert.Error(t, err)
+	_, err = svc.GetDeprovisioningOperationByInstanceID("not-existing-instance-id")
+	assert.True(t, dberr.IsNotFound(err))
+
+	// when
+	got.State = domain.Succeeded
+	updated, err := svc.UpdateDeprovisioningOperation(*got)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, got.Version+1, updated.Version)
+	got, err = svc.GetDeprovisioningOperationByID(latest.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.Succeeded, got.State)
+
+	// when
+	_, err = svc.UpdateDeprovisioningOperation(latest)
+
+	// then
+	assert.True(t, dberr.IsConflict(err), "the update of the outdated operation must fail")
+}
+
+func testUpgradeKymaOperations(t *testing.T, brokerStorage storage.BrokerStorage) {
+	// given
+	svc := brokerStorage.Operations()
+	now := fixTime()
+	first := fixUpgradeKymaOperation("first", "instance-id", domain.Succeeded, now)
+	latest := fixUpgradeKymaOperation("latest", "instance-id", domain.InProgress, now.Add(time.Hour))
+	other := fixUpgradeKymaOperation("other", "other-instance-id", domain.InProgress, now)
+
+	// when
+	for _, op := range []internal.UpgradeKymaOperation{first, latest, other} {
+		require.NoError(t, svc.InsertUpgradeKymaOperation(op))
+	}
+	err := svc.InsertUpgradeKymaOperation(first)
+
+	// then
+	assert.True(t, dberr.IsAlreadyExists(err), "the operation must not be inserted twice")
+
+	got, err := svc.GetUpgradeKymaOperationByID(first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, first.InstanceID, got.InstanceID)
+	assert.Equal(t, first.State, got.State)
+	assert.Equal(t, first.RuntimeID, got.RuntimeID)
+	assert.Equal(t, first.PlanID, got.PlanID)
+
+	got, err = svc.GetUpgradeKymaOperationByInstanceID("instance-id")
+	require.NoError(t, err)
+	assert.Equal(t, latest.ID, got.ID, "the latest upgrade kyma operation of the instance is returned")
+
+	ops, err := svc.ListUpgradeKymaOperationsByInstanceID("instance-id")
+	require.NoError(t, err)
+	assert.Equal(t, []string{latest.ID, first.ID}, upgradeKymaOperationIDs(ops), "the operations of the instance are returned, the latest first")
+
+	_, err = svc.GetUpgradeKymaOperationByID("not-existing-id")
+	assert.Error(t, err)
+	_, err = svc.GetUpgradeKymaOperationByInstanceID("not-existing-instance-id")
+	assert.True(t, dberr.IsNotFound(err))
+
+	// when
+	got.State = domain.Failed
+	updated, err := svc.UpdateUpgradeKymaOperation(*got)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, got.Version+1, updated.Version)
+	got, err = svc.GetUpgradeKymaOperationByID(latest.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.Failed, got.State)
+
+	// when
+	_, err = svc.UpdateUpgradeKymaOperation(latest)
+
+	// then
+	assert.True(t, dberr.IsConflict(err), "the update of the outdated operation must fail")
+}
+
+func testListUpgradeKymaOperationsByOrchestrationID(t *testing.T, brokerStorage storage.BrokerStorage) {
+	// given
+	svc := brokerStorage.Operations()
+	now := fixTime()
+	for i, fix := range []struct {
+		orchestrationID string
+		state           domain.LastOperationState
+	}{
+		{orchestrationID: "orchestration-id", state: domain.Succeeded},
+		{orchestrationID: "other-orchestration-id", state: domain.Succeeded},
+		{orchestrationID: "orchestration-id", state: domain.Failed},
+		{orchestrationID: "orchestration-id", state: domain.Succeeded},
+		{orchestrationID: "orchestration-id", state: domain.InProgress},
+		{orchestrationID: "orchestration-id", state: domain.Succeeded},
+	} {
+		// the operations are inserted in the reversed order of creation to verify the sorting
+		op := fixUpgradeKymaOperation(fmt.Sprintf("operation-%d", i), fmt.Sprintf("inst-%d", i), fix.state, now.Add(-time.Duration(i)*time.Minute))
+		op.OrchestrationID = fix.orchestrationID
+		require.NoError(t, svc.InsertUpgradeKymaOperation(op))
+	}
+
+	// when
+	ops, count, totalCount, err := svc.ListUpgradeKymaOperationsByOrchestrationID("orchestration-id", dbmodel.OperationFilter{}, 2, 1)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []string{"operation-5", "operation-4"}, upgradeKymaOperationIDs(ops))
+	assert.Equal(t, 2, count)
+	assert.Equal(t, 5, totalCount)
+
+	// when
+	ops, count, totalCount, err = svc.ListUpgradeKymaOperationsByOrchestrationID("orchestration-id", dbmodel.OperationFilter{}, 2, 3)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []string{"operation-0"}, upgradeKymaOperationIDs(ops))
+	assert.Equal(t, 1, count)
+	assert.Equal(t, 5, totalCount)
+
+	// when
+	ops, count, totalCount, err = svc.ListUpgradeKymaOperationsByOrchestrationID("orchestration-id", dbmodel.OperationFilter{
+		States: []string{string(domain.Succeeded)},
+	}, 2, 2)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []string{"operation-0"}, upgradeKymaOperationIDs(ops))
+	assert.Equal(t, 1, count)
+	assert.Equal(t, 3, totalCount)
+
+	// when
+	ops, count, totalCount, err = svc.ListUpgradeKymaOperationsByOrchestrationID("orchestration-id", dbmodel.OperationFilter{
+		After: &dbmodel.OperationCursor{CreatedAt: now.Add(-4 * time.Minute), OperationID: "operation-4"},
+	}, 2, 3)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []string{"operation-3", "operation-2"}, upgradeKymaOperationIDs(ops), "the page is ignored when the cursor is set")
+	assert.Equal(t, 2, count)
+	assert.Equal(t, 5, totalCount)
+
+	// when
+	ops, count, totalCount, err = svc.ListUpgradeKymaOperationsByOrchestrationID("not-existing-id", dbmodel.OperationFilter{}, 2, 1)
+
+	// then
+	require.NoError(t, err)
+	assert.Empty(t, ops)
+	assert.Equal(t, 0, count)
+	assert.Equal(t, 0, totalCount)
+}
+
+func testListInstanceIDsUpgradedSince(t *testing.T, brokerStorage storage.BrokerStorage) {
+	// given
+	svc := brokerStorage.Operations()
+	now := fixTime()
+	dryRun := fixUpgradeKymaOperation("dry-run", "dry-run-instance", domain.Succeeded, now)
+	dryRun.DryRun = true
+	for _, op := range []internal.UpgradeKymaOperation{
+		fixUpgradeKymaOperation("upgraded", "upgraded-instance", domain.Succeeded, now),
+		fixUpgradeKymaOperation("upgraded-again", "upgraded-instance", domain.Succeeded, now.Add(time.Minute)),
+		fixUpgradeKymaOperation("upgraded-before", "upgraded-before-instance", domain.Succeeded, now.Add(-time.Hour)),
+		fixUpgradeKymaOperation("failed", "failed-instance", domain.Failed, now),
+		dryRun,
+	} {
+		require.NoError(t, svc.InsertUpgradeKymaOperation(op))
+	}
+
+	// when
+	ids, err := svc.ListInstanceIDsUpgradedSince(now.Add(-time.Minute))
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []string{"upgraded-instance"}, ids)
+}
+
+func testUpgradeClusterOperations(t *testing.T, brokerStorage storage.BrokerStorage) {
+	// given
+	svc := brokerStorage.Operations()
+	now := fixTime()
+	first := fixUpgradeClusterOperation("first", "instance-id", domain.Succeeded, now)
+	first.OrchestrationID = "orchestration-id"
+	latest := fixUpgradeClusterOperation("latest", "instance-id", domain.InProgress, now.Add(time.Hour))
+	latest.OrchestrationID = "orchestration-id"
+	other := fixUpgradeClusterOperation("other", "other-instance-id", domain.InProgress, now.Add(time.Minute))
+	other.OrchestrationID = "orchestration-id"
+
+	// when
+	for _, op := range []internal.UpgradeClusterOperation{latest, first, other} {
+		require.NoError(t, svc.InsertUpgradeClusterOperation(op))
+	}
+	err := svc.InsertUpgradeClusterOperation(first)
+
+	// then
+	assert.True(t, dberr.IsAlreadyExists(err), "the operation must not be inserted twice")
+
+	got, err := svc.GetUpgradeClusterOperationByID(first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, first.InstanceID, got.InstanceID)
+	assert.Equal(t, first.State, got.State)
+	assert.Equal(t, first.OrchestrationID, got.OrchestrationID)
+
+	ops, err := svc.ListUpgradeClusterOperationsByInstanceID("instance-id")
+	require.NoError(t, err)
+	assert.Equal(t, []string{latest.ID, first.ID}, upgradeClusterOperationIDs(ops), "the operations of the instance are returned, the latest first")
+
+	ops, count, totalCount, err := svc.ListUpgradeClusterOperationsByOrchestrationID("orchestration-id", 2, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{first.ID, other.ID}, upgradeClusterOperationIDs(ops))
+	assert.Equal(t, 2, count)
+	assert.Equal(t, 3, totalCount)
+
+	_, err = svc.GetUpgradeClusterOperationByID("not-existing-id")
+	assert.Error(t, err)
+
+	// when
+	got.State = domain.Failed
+	updated, err := svc.UpdateUpgradeClusterOperation(*got)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, got.Version+1, updated.Version)
+	got, err = svc.GetUpgradeClusterOperationByID(first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.Failed, got.State)
+
+	// when
+	_, err = svc.UpdateUpgradeClusterOperation(first)
+
+	// then
+	assert.True(t, dberr.IsConflict(err), "the update of the outdated operation must fail")
+}
+
+func testPlanMigrationOperations(t *testing.T, brokerStorage storage.BrokerStorage) {
+	// given
+	svc := brokerStorage.Operations()
+	now := fixTime()
+	first := fixPlanMigrationOperation("first", "instance-id", domain.Failed, now)
+	latest := fixPlanMigrationOperation("latest", "instance-id", domain.InProgress, now.Add(time.Hour))
+
+	// when
+	require.NoError(t, svc.InsertPlanMigrationOperation(latest))
+	require.NoError(t, svc.InsertPlanMigrationOperation(first))
+	err := svc.InsertPlanMigrationOperation(first)
+
+	// then
+	assert.True(t, dberr.IsAlreadyExists(err), "the operation must not be inserted twice")
+
+	got, err := svc.GetPlanMigrationOperationByID(first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, first.InstanceID, got.InstanceID)
+	assert.Equal(t, first.SourcePlanID, got.SourcePlanID)
+	assert.Equal(t, first.SourceRuntimeID, got.SourceRuntimeID)
+	assert.Equal(t, first.ProvisioningParameters, got.ProvisioningParameters)
+
+	got, err = svc.GetPlanMigrationOperationByInstanceID("instance-id")
+	require.NoError(t, err)
+	assert.Equal(t, latest.ID, got.ID, "the latest plan migration operation of the instance is returned")
+
+	_, err = svc.GetPlanMigrationOperationByID("not-existing-id")
+	assert.Error(t, err)
+	_, err = svc.GetPlanMigrationOperationByInstanceID("not-existing-instance-id")
+	assert.True(t, dberr.IsNotFound(err))
+
+	// when
+	got.SourceRuntimeRemoved = true
+	updated, err := svc.UpdatePlanMigrationOperation(*got)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, got.Version+1, updated.Version)
+	got, err = svc.GetPlanMigrationOperationByID(latest.ID)
+	require.NoError(t, err)
+	assert.True(t, got.SourceRuntimeRemoved)
+
+	// when
+	_, err = svc.UpdatePlanMigrationOperation(latest)
+
+	// then
+	assert.True(t, dberr.IsConflict(err), "the update of the outdated operation must fail")
+}
+
+func testUpdatingOperations(t *testing.T, brokerStorage storage.BrokerStorage) {
+	// given
+	svc := brokerStorage.Operations()
+	now := fixTime()
+	first := fixUpdatingOperation("first", "instance-id", domain.Succeeded, now)
+	latest := fixUpdatingOperation("latest", "instance-id", domain.InProgress, now.Add(time.Hour))
+
+	// when
+	require.NoError(t, svc.InsertUpdatingOperation(latest))
+	require.NoError(t, svc.InsertUpdatingOperation(first))
+	err := svc.InsertUpdatingOperation(first)
+
+	// then
+	assert.True(t, dberr.IsAlreadyExists(err), "the operation must not be inserted twice")
+
+	got, err := svc.GetUpdatingOperationByID(first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, first.InstanceID, got.InstanceID)
+	assert.Equal(t, first.RuntimeID, got.RuntimeID)
+	assert.Equal(t, first.UpdatingParameters, got.UpdatingParameters)
+
+	got, err = svc.GetUpdatingOperationByInstanceID("instance-id")
+	require.NoError(t, err)
+	assert.Equal(t, latest.ID, got.ID, "the latest updating operation of the instance is returned")
+
+	_, err = svc.GetUpdatingOperationByID("not-existing-id")
+	assert.Error(t, err)
+	_, err = svc.GetUpdatingOperationByInstanceID("not-existing-instance-id")
+	assert.True(t, dberr.IsNotFound(err))
+
+	// when
+	got.ProvisionerOperationID = "provisioner-operation-id"
+	updated, err := svc.UpdateUpdatingOperation(*got)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, got.Version+1, updated.Version)
+	got, err = svc.GetUpdatingOperationByID(latest.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "provisioner-operation-id", got.ProvisionerOperationID)
+
+	// when
+	_, err = svc.UpdateUpdatingOperation(latest)
+
+	// then
+	assert.True(t, dberr.IsConflict(err), "the update of the outdated operation must fail")
+}
+
+func testSuspensionOperations(t *testing.T, brokerStorage storage.BrokerStorage) {
+	// given
+	svc := brokerStorage.Operations()
+	now := fixTime()
+	first := fixSuspensionOperation("first", "instance-id", domain.Succeeded, now)
+	latest := fixSuspensionOperation("latest", "instance-id", domain.InProgress, now.Add(time.Hour))
+	latest.Action = internal.SuspensionActionResume
+
+	// when
+	require.NoError(t, svc.InsertSuspensionOperation(latest))
+	require.NoError(t, svc.InsertSuspensionOperation(first))
+	err := svc.InsertSuspensionOperation(first)
+
+	// then
+	assert.True(t, dberr.IsAlreadyExists(err), "the operation must not be inserted twice")
+
+	got, err := svc.GetSuspensionOperationByID(first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, first.InstanceID, got.InstanceID)
+	assert.Equal(t, first.RuntimeID, got.RuntimeID)
+	assert.Equal(t, first.ShootName, got.ShootName)
+	assert.Equal(t, internal.SuspensionActionSuspend, got.Action)
+
+	got, err = svc.GetSuspensionOperationByInstanceID("instance-id")
+	require.NoError(t, err)
+	assert.Equal(t, latest.ID, got.ID, "the latest suspension operation of the instance is returned")
+	assert.Equal(t, internal.SuspensionActionResume, got.Action)
+
+	_, err = svc.GetSuspensionOperationByID("not-existing-id")
+	assert.Error(t, err)
+	_, err = svc.GetSuspensionOperationByInstanceID("not-existing-instance-id")
+	assert.True(t, dberr.IsNotFound(err))
+
+	// when
+	got.HibernationRequested = true
+	got.RetriedAt = now.Add(2 * time.Hour)
+	updated, err := svc.UpdateSuspensionOperation(*got)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, got.Version+1, updated.Version)
+	got, err = svc.GetSuspensionOperationByID(latest.ID)
+	require.NoError(t, err)
+	assert.True(t, got.HibernationRequested)
+	assert.True(t, now.Add(2*time.Hour).Equal(got.RetriedAt))
+	assert.True(t, now.Add(2*time.Hour).Equal(got.TimeoutStart()), "the timeout of the retried operation is measured from the retry")
+
+	// when
+	_, err = svc.UpdateSuspensionOperation(latest)
+
+	// then
+	assert.True(t, dberr.IsConflict(err), "the update of the outdated operation must fail")
+}
+
+func testAccountMigrationOperations(t *testing.T, brokerStorage storage.BrokerStorage) {
+	// given
+	svc := brokerStorage.Operations()
+	now := fixTime()
+	first := fixAccountMigrationOperation("first", "instance-id", domain.Succeeded, now)
+	latest := fixAccountMigrationOperation("latest", "instance-id", domain.InProgress, now.Add(time.Hour))
+	latest.TargetSubAccountID = "latest-sa"
+
+	// when
+	require.NoError(t, svc.InsertAccountMigrationOperation(latest))
+	require.NoError(t, svc.InsertAccountMigrationOperation(first))
+	err := svc.InsertAccountMigrationOperation(first)
+
+	// then
+	assert.True(t, dberr.IsAlreadyExists(err), "the operation must not be inserted twice")
+
+	got, err := svc.GetAccountMigrationOperationByID(first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, first.InstanceID, got.InstanceID)
+	assert.Equal(t, first.Tenant, got.Tenant)
+	assert.Equal(t, first.SourceSubAccountID, got.SourceSubAccountID)
+	assert.Equal(t, first.TargetSubAccountID, got.TargetSubAccountID)
+
+	got, err = svc.GetAccountMigrationOperationByInstanceID("instance-id")
+	require.NoError(t, err)
+	assert.Equal(t, latest.ID, got.ID, "the latest account migration operation of the instance is returned")
+	assert.Equal(t, "latest-sa", got.TargetSubAccountID)
+
+	_, err = svc.GetAccountMigrationOperationByID("not-existing-id")
+	assert.Error(t, err)
+	_, err = svc.GetAccountMigrationOperationByInstanceID("not-existing-instance-id")
+	assert.True(t, dberr.IsNotFound(err))
+
+	// when
+	got.AppliedChanges = []string{"instance"}
+	updated, err := svc.UpdateAccountMigrationOperation(*got)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, got.Version+1, updated.Version)
+	got, err = svc.GetAccountMigrationOperationByID(latest.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"instance"}, got.AppliedChanges)
+
+	// when
+	_, err = svc.UpdateAccountMigrationOperation(latest)
+
+	// then
+	assert.True(t, dberr.IsConflict(err), "the update of the outdated operation must fail")
+}
+
+func testGetOperations(t *testing.T, brokerStorage storage.BrokerStorage) {
+	// given
+	svc := brokerStorage.Operations()
+	now := fixTime()
+	require.NoError(t, svc.InsertProvisioningOperation(fixProvisioningOperation("provisioning", "instance-id", domain.InProgress, now)))
+	require.NoError(t, svc.InsertDeprovisioningOperation(fixDeprovisioningOperation("deprovisioning", "instance-id", domain.InProgress, now)))
+	require.NoError(t, svc.InsertUpgradeKymaOperation(fixUpgradeKymaOperation("upgrade-kyma", "instance-id", domain.Succeeded, now)))
+	require.NoError(t, svc.InsertUpgradeClusterOperation(fixUpgradeClusterOperation("upgrade-cluster", "instance-id", domain.InProgress, now)))
+	require.NoError(t, svc.InsertPlanMigrationOperation(fixPlanMigrationOperation("plan-migration", "other-instance-id", domain.InProgress, now)))
+	require.NoError(t, svc.InsertUpdatingOperation(fixUpdatingOperation("update", "other-instance-id", domain.InProgress, now)))
+	require.NoError(t, svc.InsertSuspensionOperation(fixSuspensionOperation("suspension", "other-instance-id", domain.InProgress, now)))
+	require.NoError(t, svc.InsertAccountMigrationOperation(fixAccountMigrationOperation("account-migration", "other-instance-id", domain.InProgress, now)))
+
+	for _, id := range []string{"provisioning", "deprovisioning", "upgrade-kyma", "upgrade-cluster", "plan-migration", "update", "suspension", "account-migration"} {
+		// when
+		op, err := svc.GetOperationByID(id)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, id, op.ID)
+	}
+
+	// when
+	_, err := svc.GetOperationByID("not-existing-id")
+
+	// then
+	assert.True(t, dberr.IsNotFound(err))
+
+	// when
+	op, err := svc.GetOperationByInstanceAndID("instance-id", "upgrade-kyma")
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, "upgrade-kyma", op.ID)
+	assert.Equal(t, domain.Succeeded, op.State)
+
+	// when
+	_, err = svc.GetOperationByInstanceAndID("other-instance-id", "upgrade-kyma")
+
+	// then
+	assert.True(t, dberr.IsNotFound(err), "the operation of another instance must not be returned")
+
+	// when
+	ops, err := svc.GetOperationsForIDs([]string{"upgrade-kyma", "upgrade-cluster", "plan-migration", "not-existing-id"})
+
+	// then
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"upgrade-kyma", "upgrade-cluster", "plan-migration"}, operationIDs(ops))
+
+	for opType, expected := range map[dbmodel.OperationType][]string{
+		dbmodel.OperationTypeProvision:        {"provisioning"},
+		dbmodel.OperationTypeDeprovision:      {"deprovisioning"},
+		dbmodel.OperationTypeUpgradeKyma:      {},
+		dbmodel.OperationTypeUpgradeCluster:   {"upgrade-cluster"},
+		dbmodel.OperationTypeMigratePlan:      {"plan-migration"},
+		dbmodel.OperationTypeUpdate:           {"update"},
+		dbmodel.OperationTypeSuspension:       {"suspension"},
+		dbmodel.OperationTypeAccountMigration: {"account-migration"},
+	} {
+		// when
+		ops, err := svc.GetOperationsInProgressByType(opType)
+
+		// then
+		require.NoError(t, err)
+		assert.ElementsMatch(t, expected, operationIDs(ops), "operations in progress of type %s", opType)
+	}
+}
+
+func testListOperationsByType(t *testing.T, brokerStorage storage.BrokerStorage) {
+	// given
+	svc := brokerStorage.Operations()
+	now := fixTime()
+	for i, fix := range []struct {
+		orchestrationID string
+		state           domain.LastOperationState
+	}{
+		{orchestrationID: "orchestration-id", state: domain.Failed},
+		{orchestrationID: "other-orchestration-id", state: domain.Failed},
+		{orchestrationID: "orchestration-id", state: domain.Succeeded},
+		{orchestrationID: "orchestration-id", state: domain.Failed},
+	} {
+		op := fixUpgradeKymaOperation(fmt.Sprintf("operation-%d", i), fmt.Sprintf("inst-%d", i), fix.state, now.Add(time.Duration(i)*time.Minute))
+		op.OrchestrationID = fix.orchestrationID
+		require.NoError(t, svc.InsertUpgradeKymaOperation(op))
+	}
+	require.NoError(t, svc.InsertProvisioningOperation(fixProvisioningOperation("provisioning", "inst-0", domain.Failed, now)))
+
+	// when
+	ops, count, totalCount, err := svc.ListOperationsByType(dbmodel.OperationTypeUpgradeKyma, dbmodel.OperationFilter{}, 3, 1)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []string{"operation-0", "operation-1", "operation-2"}, operationIDs(ops))
+	assert.Equal(t, 3, count)
+	assert.Equal(t, 4, totalCount)
+
+	// when
+	ops, count, totalCount, err = svc.ListOperationsByType(dbmodel.OperationTypeUpgradeKyma, dbmodel.OperationFilter{
+		States:          []string{string(domain.Failed)},
+		OrchestrationID: "orchestration-id",
+	}, 3, 1)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []string{"operation-0", "operation-3"}, operationIDs(ops))
+	assert.Equal(t, 2, count)
+	assert.Equal(t, 2, totalCount)
+
+	// when
+	ops, _, totalCount, err = svc.ListOperationsByType(dbmodel.OperationTypeUpgradeKyma, dbmodel.OperationFilter{
+		CreatedBefore: now.Add(3 * time.Minute),
+		After:         &dbmodel.OperationCursor{CreatedAt: now, OperationID: "operation-0"},
+	}, 3, 1)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []string{"operation-1", "operation-2"}, operationIDs(ops))
+	assert.Equal(t, 3, totalCount, "the cursor is not applied to the total count")
+
+	// when
+	ops, _, totalCount, err = svc.ListOperationsByType(dbmodel.OperationTypeProvision, dbmodel.OperationFilter{}, 3, 1)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []string{"provisioning"}, operationIDs(ops))
+	assert.Equal(t, 1, totalCount)
+}
+
+func testListOperationsByInstanceID(t *testing.T, brokerStorage storage.BrokerStorage) {
+	// given
+	svc := brokerStorage.Operations()
+	now := fixTime()
+	require.NoError(t, svc.InsertDeprovisioningOperation(fixDeprovisioningOperation("deprovisioning", "instance-id", domain.InProgress, now.Add(3*time.Hour))))
+	require.NoError(t, svc.InsertUpgradeClusterOperation(fixUpgradeClusterOperation("upgrade-cluster", "instance-id", domain.Succeeded, now.Add(2*time.Hour))))
+	require.NoError(t, svc.InsertUpgradeKymaOperation(fixUpgradeKymaOperation("upgrade-kyma", "instance-id", domain.Succeeded, now.Add(time.Hour))))
+	require.NoError(t, svc.InsertProvisioningOperation(fixProvisioningOperation("provisioning", "instance-id", domain.Succeeded, now)))
+	require.NoError(t, svc.InsertProvisioningOperation(fixProvisioningOperation("other", "other-instance-id", domain.Succeeded, now)))
+
+	// when
+	ops, err := svc.ListOperationsByInstanceID("instance-id")
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []string{"provisioning", "upgrade-kyma", "upgrade-cluster", "deprovisioning"}, operationIDs(ops), "the operations are sorted by the creation time")
+
+	// when
+	ops, err = svc.ListOperationsByInstanceID("not-existing-instance-id")
+
+	// then
+	require.NoError(t, err)
+	assert.Empty(t, ops)
+}
+
+func testListOperationsByCorrelationID(t *testing.T, brokerStorage storage.BrokerStorage) {
+	// given
+	svc := brokerStorage.Operations()
+	now := fixTime()
+	deprovisioning := fixDeprovisioningOperation("deprovisioning", "instance-id", domain.InProgress, now.Add(time.Hour))
+	deprovisioning.CorrelationID = "correlation-id"
+	require.NoError(t, svc.InsertDeprovisioningOperation(deprovisioning))
+	provisioning := fixProvisioningOperation("provisioning", "instance-id", domain.Succeeded, now)
+	provisioning.CorrelationID = "correlation-id"
+	require.NoError(t, svc.InsertProvisioningOperation(provisioning))
+	other := fixProvisioningOperation("other", "other-instance-id", domain.Succeeded, now)
+	other.CorrelationID = "other-correlation-id"
+	require.NoError(t, svc.InsertProvisioningOperation(other))
+	require.NoError(t, svc.InsertUpgradeKymaOperation(fixUpgradeKymaOperation("upgrade-kyma", "instance-id", domain.Succeeded, now)))
+
+	// when
+	ops, err := svc.ListOperationsByCorrelationID("correlation-id")
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []string{"provisioning", "deprovisioning"}, operationIDs(ops), "the operations are sorted by the creation time")
+	for _, op := range ops {
+		assert.Equal(t, "correlation-id", op.CorrelationID)
+	}
+
+	// when
+	ops, err = svc.ListOperationsByCorrelationID("not-existing-correlation-id")
+
+	// then
+	require.NoError(t, err)
+	assert.Empty(t, ops)
+}
+
+func testOperationStats(t *testing.T, brokerStorage storage.BrokerStorage) {
+	// given
+	svc := brokerStorage.Operations()
+	from := fixTime().Truncate(time.Hour)
+	require.NoError(t, svc.InsertProvisioningOperation(fixProvisioningOperation("provisioning-1", "instance-1", domain.Succeeded, from.Add(10*time.Minute))))
+	require.NoError(t, svc.InsertProvisioningOperation(fixProvisioningOperation("provisioning-2", "instance-2", domain.Succeeded, from.Add(20*time.Minute))))
+	inRegion := fixProvisioningOperation("provisioning-3", "instance-3", domain.InProgress, from.Add(70*time.Minute))
+	inRegion.ProvisioningParameters = `{"plan_id":"other-plan-id","parameters":{"region":"westeurope"}}`
+	require.NoError(t, svc.InsertProvisioningOperation(inRegion))
+	require.NoError(t, svc.InsertDeprovisioningOperation(fixDeprovisioningOperation("deprovisioning-1", "instance-1", domain.Failed, from.Add(80*time.Minute))))
+
+	upgradeKyma := fixUpgradeKymaOperation("upgrade-kyma", "instance-1", domain.Failed, from)
+	upgradeKyma.OrchestrationID = "orchestration-id"
+	otherUpgradeKyma := fixUpgradeKymaOperation("other-upgrade-kyma", "instance-2", domain.Succeeded, from)
+	otherUpgradeKyma.OrchestrationID = "other-orchestration-id"
+	upgradeCluster := fixUpgradeClusterOperation("upgrade-cluster", "instance-2", domain.Succeeded, from)
+	upgradeCluster.OrchestrationID = "orchestration-id"
+	pendingUpgradeKyma := fixUpgradeKymaOperation("pending-upgrade-kyma", "instance-4", domain.InProgress, from)
+	pendingUpgradeKyma.OrchestrationID = "orchestration-id"
+	canceledUpgradeKyma := fixUpgradeKymaOperation("canceled-upgrade-kyma", "instance-5", internal.OperationCanceled, from)
+	canceledUpgradeKyma.OrchestrationID = "orchestration-id"
+	require.NoError(t, svc.InsertUpgradeKymaOperation(upgradeKyma))
+	require.NoError(t, svc.InsertUpgradeKymaOperation(otherUpgradeKyma))
+	require.NoError(t, svc.InsertUpgradeKymaOperation(pendingUpgradeKyma))
+	require.NoError(t, svc.InsertUpgradeKymaOperation(canceledUpgradeKyma))
+	require.NoError(t, svc.InsertUpgradeClusterOperation(upgradeCluster))
+
+	// when
+	stats, err := svc.GetOperationStats()
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.Provisioning[domain.Succeeded])
+	assert.Equal(t, 1, stats.Provisioning[domain.InProgress])
+	assert.Equal(t, 0, stats.Provisioning[domain.Failed])
+	assert.Equal(t, 1, stats.Deprovisioning[domain.Failed])
+	assert.Equal(t, 0, stats.Deprovisioning[domain.Succeeded])
+
+	assert.Equal(t, 2, stats.PerPlan[fixPlanID].Provisioning[domain.Succeeded])
+	assert.Equal(t, 0, stats.PerPlan[fixPlanID].Provisioning[domain.InProgress])
+	assert.Equal(t, 1, stats.PerPlan["other-plan-id"].Provisioning[domain.InProgress])
+	assert.Equal(t, 1, stats.PerPlan[""].Deprovisioning[domain.Failed])
+	assert.Equal(t, 2, stats.PerRegion[""].Provisioning[domain.Succeeded])
+	assert.Equal(t, 1, stats.PerRegion[""].Deprovisioning[domain.Failed])
+	assert.Equal(t, 1, stats.PerRegion["westeurope"].Provisioning[domain.InProgress])
+	assert.Equal(t, 0, stats.PerRegion["westeurope"].Provisioning[domain.Succeeded])
+
+	// when
+	orchestrationStats, err := svc.GetOperationStatsForOrchestration("orchestration-id")
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, map[domain.LastOperationState]int{
+		internal.OperationPending:  1,
+		domain.InProgress:          0,
+		domain.Succeeded:           1,
+		domain.Failed:              1,
+		internal.OperationCanceled: 1,
+		internal.OperationSkipped:  0,
+	}, orchestrationStats, "the operations in progress not sent to the provisioner are pending, the operations of other orchestrations are not counted")
+
+	// when
+	timeStats, err := svc.GetOperationTimeStats(from, 2*time.Hour, time.Hour)
+
+	// then
+	require.NoError(t, err)
+	require.Len(t, timeStats.Buckets, 2)
+	provisioning := string(dbmodel.OperationTypeProvision)
+	deprovisioning := string(dbmodel.OperationTypeDeprovision)
+	assert.Equal(t, internal.OperationCounts{Started: 2, Succeeded: 2}, timeStats.Buckets[0].Operations[provisioning])
+	assert.Equal(t, internal.OperationCounts{Started: 1}, timeStats.Buckets[1].Operations[provisioning])
+	assert.Equal(t, internal.OperationCounts{Started: 1, Failed: 1}, timeStats.Buckets[1].Operations[deprovisioning])
+}
@@ -0,0 +1,171 @@
+package testsuite
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession/dbmodel"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testOrchestrationLifecycle(t *testing.T, brokerStorage storage.BrokerStorage) {
+	// given
+	svc := brokerStorage.Orchestrations()
+	now := fixTime()
+	orchestration := internal.Orchestration{
+		OrchestrationID: "orchestration-id",
+		State:           internal.Pending,
+		Description:     "scheduled",
+		CreatedAt:       now,
+		UpdatedAt:       now,
+		Parameters: internal.OrchestrationParameters{
+			DryRun: true,
+		},
+	}
+
+	// when
+	err := svc.Insert(orchestration)
+
+	// then
+	require.NoError(t, err)
+	got, err := svc.GetByID(orchestration.OrchestrationID)
+	require.NoError(t, err)
+	assert.Equal(t, orchestration.State, got.State)
+	assert.Equal(t, orchestration.Description, got.Description)
+	assert.Equal(t, orchestration.Parameters, got.Parameters)
+	assert.Equal(t, internal.UpgradeKymaOrchestration, got.Type, "the orchestration without the type upgrades Kyma")
+	assert.Nil(t, got.Runtimes)
+
+	// when
+	err = svc.Insert(orchestration)
+
+	// then
+	assert.True(t, dberr.IsAlreadyExists(err), "the orchestration must not be inserted twice")
+
+	// when
+	orchestration.State = internal.InProgress
+	orchestration.Runtimes = []internal.Runtime{{InstanceID: "instance-id", RuntimeID: "runtime-id", ShootName: "c-1234567"}}
+	err = svc.Update(orchestration)
+
+	// then
+	require.NoError(t, err)
+	got, err = svc.GetByID(orchestration.OrchestrationID)
+	require.NoError(t, err)
+	assert.Equal(t, internal.InProgress, got.State)
+	assert.Equal(t, orchestration.Runtimes, got.Runtimes)
+
+	// when
+	_, err = svc.GetByID("not-existing-id")
+
+	// then
+	assert.True(t, dberr.IsNotFound(err))
+
+	// when
+	err = svc.Update(internal.Orchestration{OrchestrationID: "not-existing-id", CreatedAt: now, UpdatedAt: now})
+
+	// then
+	assert.True(t, dberr.IsNotFound(err), "the not existing orchestration must not be created by the update")
+
+	// when
+	clusterOrchestration := internal.Orchestration{
+		OrchestrationID: "cluster-orchestration-id",
+		Type:            internal.UpgradeClusterOrchestration,
+		State:           internal.Pending,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	err = svc.Insert(clusterOrchestration)
+
+	// then
+	require.NoError(t, err)
+	got, err = svc.GetByID(clusterOrchestration.OrchestrationID)
+	require.NoError(t, err)
+	assert.Equal(t, internal.UpgradeClusterOrchestration, got.Type)
+}
+
+func testListOrchestrations(t *testing.T, brokerStorage storage.BrokerStorage) {
+	// given
+	svc := brokerStorage.Orchestrations()
+	now := fixTime()
+	for i, state := range []string{internal.Succeeded, internal.InProgress, internal.Failed, internal.InProgress, internal.Succeeded} {
+		// the orchestrations are inserted in the reversed order of creation to verify the sorting
+		createdAt := now.Add(-time.Duration(i) * time.Minute)
+		require.NoError(t, svc.Insert(internal.Orchestration{
+			OrchestrationID: fmt.Sprintf("orchestration-%d", i),
+			State:           state,
+			CreatedAt:       createdAt,
+			UpdatedAt:       createdAt,
+		}))
+	}
+
+	// when
+	orchestrations, count, totalCount, err := svc.List(dbmodel.OrchestrationFilter{PageSize: 2, Page: 1})
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []string{"orchestration-4", "orchestration-3"}, orchestrationIDs(orchestrations))
+	assert.Equal(t, 2, count)
+	assert.Equal(t, 5, totalCount)
+
+	// when
+	orchestrations, count, totalCount, err = svc.List(dbmodel.OrchestrationFilter{PageSize: 2, Page: 3})
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []string{"orchestration-0"}, orchestrationIDs(orchestrations))
+	assert.Equal(t, 1, count)
+	assert.Equal(t, 5, totalCount)
+
+	// when
+	orchestrations, count, totalCount, err = svc.List(dbmodel.OrchestrationFilter{
+		PageSize: 2,
+		Page:     5,
+		After:    &dbmodel.OrchestrationCursor{CreatedAt: now.Add(-3 * time.Minute), OrchestrationID: "orchestration-3"},
+	})
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []string{"orchestration-2", "orchestration-1"}, orchestrationIDs(orchestrations), "the page is ignored when the cursor is set")
+	assert.Equal(t, 2, count)
+	assert.Equal(t, 5, totalCount)
+
+	// given
+	// the orchestration created at the same time as the cursor is ordered by the ID
+	require.NoError(t, svc.Insert(internal.Orchestration{
+		OrchestrationID: "orchestration-10",
+		State:           internal.Pending,
+		CreatedAt:       now.Add(-3 * time.Minute),
+		UpdatedAt:       now.Add(-3 * time.Minute),
+	}))
+
+	// when
+	orchestrations, _, totalCount, err = svc.List(dbmodel.OrchestrationFilter{
+		PageSize: 2,
+		After:    &dbmodel.OrchestrationCursor{CreatedAt: now.Add(-3 * time.Minute), OrchestrationID: "orchestration-1"},
+	})
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []string{"orchestration-10", "orchestration-3"}, orchestrationIDs(orchestrations))
+	assert.Equal(t, 6, totalCount)
+
+	// when
+	orchestrations, err = svc.ListByState(internal.InProgress)
+
+	// then
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"orchestration-1", "orchestration-3"}, orchestrationIDs(orchestrations))
+
+	// when
+	orchestrations, err = svc.ListByState(internal.Canceled)
+
+	// then
+	require.NoError(t, err)
+	assert.Empty(t, orchestrations)
+}
@@ -0,0 +1,71 @@
+// Package testsuite holds the compliance tests of the storage drivers. Every driver must pass the same tests,
+// so the behavior of the memory driver used in the unit tests does not diverge from the postgres driver used
+// in production.
+package testsuite
+
+import (
+	"testing"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+)
+
+// StorageFactory returns an empty storage and the function which releases it
+type StorageFactory func(t *testing.T) (storage.BrokerStorage, func())
+
+type complianceTest struct {
+	name string
+	run  func(t *testing.T, brokerStorage storage.BrokerStorage)
+}
+
+var complianceTests = []complianceTest{
+	{name: "Instances/Insert, get, update and delete", run: testInstanceLifecycle},
+	{name: "Instances/Only one trial instance per subaccount", run: testTrialInstancePerSubAccount},
+	{name: "Instances/Find by runtimes and subaccounts", run: testFindInstances},
+	{name: "Instances/Statistics", run: testInstanceStats},
+	{name: "Instances/Find joined with operations", run: testFindAllJoinedWithOperations},
+	{name: "Instances/List", run: testListInstances},
+	{name: "Instances/List with state", run: testListInstancesWithState},
+	{name: "Instances/List with cursor", run: testListInstancesWithCursor},
+	{name: "Instances/List with search", run: testListInstancesWithSearch},
+
+	{name: "Operations/Provisioning", run: testProvisioningOperations},
+	{name: "Operations/List provisioning operations", run: testListProvisioningOperations},
+	{name: "Operations/Deprovisioning", run: testDeprovisioningOperations},
+	{name: "Operations/Upgrade kyma", run: testUpgradeKymaOperations},
+	{name: "Operations/List upgrade kyma operations by orchestration ID", run: testListUpgradeKymaOperationsByOrchestrationID},
+	{name: "Operations/List instances upgraded since", run: testListInstanceIDsUpgradedSince},
+	{name: "Operations/Upgrade cluster", run: testUpgradeClusterOperations},
+	{name: "Operations/Plan migration", run: testPlanMigrationOperations},
+	{name: "Operations/Updating", run: testUpdatingOperations},
+	{name: "Operations/Suspension", run: testSuspensionOperations},
+	{name: "Operations/Account migration", run: testAccountMigrationOperations},
+	{name: "Operations/Get operations of any type", run: testGetOperations},
+	{name: "Operations/List by type", run: testListOperationsByType},
+	{name: "Operations/List by instance ID", run: testListOperationsByInstanceID},
+	{name: "Operations/List by correlation ID", run: testListOperationsByCorrelationID},
+	{name: "Operations/Statistics", run: testOperationStats},
+
+	{name: "Orchestrations/Insert, get and update", run: testOrchestrationLifecycle},
+	{name: "Orchestrations/List", run: testListOrchestrations},
+
+	{name: "Jobs/Locks", run: testJobLocks},
+	{name: "Jobs/Runs", run: testJobRuns},
+
+	{name: "Trial expirations/Get and upsert", run: testTrialExpirations},
+
+	{name: "Transactions/Commit", run: testTransactionCommit},
+	{name: "Transactions/Rollback", run: testTransactionRollback},
+}
+
+// Run runs all compliance tests, every test gets a new storage from the factory
+func Run(t *testing.T, newStorage StorageFactory) {
+	for _, tc := range complianceTests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			brokerStorage, cleanup := newStorage(t)
+			defer cleanup()
+
+			tc.run(t, brokerStorage)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package testsuite
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
+
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testTransactionCommit(t *testing.T, brokerStorage storage.BrokerStorage) {
+	// given
+	instance := fixInstance("instance-id", fixTime())
+
+	// when
+	err := brokerStorage.InTransaction(func(tx storage.BrokerStorage) error {
+		if err := tx.Instances().Insert(instance); err != nil {
+			return err
+		}
+		return tx.Operations().InsertProvisioningOperation(internal.ProvisioningOperation{
+			Operation: internal.Operation{ID: "operation-id", InstanceID: instance.InstanceID, State: domain.Succeeded, CreatedAt: fixTime()},
+		})
+	})
+
+	// then
+	require.NoError(t, err)
+	got, err := brokerStorage.Instances().GetByID(instance.InstanceID)
+	require.NoError(t, err)
+	assert.Equal(t, instance.RuntimeID, got.RuntimeID)
+	operation, err := brokerStorage.Operations().GetProvisioningOperationByInstanceID(instance.InstanceID)
+	require.NoError(t, err)
+	assert.Equal(t, "operation-id", operation.ID)
+}
+
+func testTransactionRollback(t *testing.T, brokerStorage storage.BrokerStorage) {
+	// given
+	existing := fixInstance("existing-instance-id", fixTime())
+	require.NoError(t, brokerStorage.Instances().Insert(existing))
+
+	// when
+	err := brokerStorage.InTransaction(func(tx storage.BrokerStorage) error {
+		if err := tx.Instances().Insert(fixInstance("instance-id", fixTime())); err != nil {
+			return err
+		}
+		if err := tx.Operations().InsertProvisioningOperation(internal.ProvisioningOperation{
+			Operation: internal.Operation{ID: "operation-id", InstanceID: "instance-id", State: domain.Succeeded, CreatedAt: fixTime()},
+		}); err != nil {
+			return err
+		}
+		return errors.New("failure after the inserts")
+	})
+
+	// then
+	require.EqualError(t, err, "failure after the inserts")
+	_, err = brokerStorage.Instances().GetByID("instance-id")
+	assert.True(t, dberr.IsNotFound(err), "the insert of the instance must be rolled back")
+	_, err = brokerStorage.Operations().GetOperationByID("operation-id")
+	assert.True(t, dberr.IsNotFound(err), "the insert of the operation must be rolled back")
+	_, err = brokerStorage.Instances().GetByID(existing.InstanceID)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,65 @@
+package testsuite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testTrialExpirations(t *testing.T, brokerStorage storage.BrokerStorage) {
+	// given
+	svc := brokerStorage.TrialExpirations()
+	now := fixTime()
+
+	// when
+	_, found, err := svc.GetExpiration("instance-id")
+
+	// then
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	// when
+	err = svc.UpsertExpiration(internal.TrialExpiration{
+		InstanceID: "instance-id",
+		ExpiresAt:  now.Add(time.Hour),
+		UpdatedAt:  now,
+	})
+
+	// then
+	require.NoError(t, err)
+	expiration, found, err := svc.GetExpiration("instance-id")
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.True(t, now.Add(time.Hour).Equal(expiration.ExpiresAt))
+	assert.True(t, expiration.NotifiedAt.IsZero())
+	assert.True(t, expiration.DeprovisionedAt.IsZero())
+
+	// when
+	err = svc.UpsertExpiration(internal.TrialExpiration{
+		InstanceID:      "instance-id",
+		ExpiresAt:       now.Add(time.Hour),
+		NotifiedAt:      now.Add(time.Minute),
+		DeprovisionedAt: now.Add(2 * time.Minute),
+		UpdatedAt:       now.Add(2 * time.Minute),
+	})
+
+	// then
+	require.NoError(t, err)
+	expiration, found, err = svc.GetExpiration("instance-id")
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.True(t, now.Add(time.Minute).Equal(expiration.NotifiedAt))
+	assert.True(t, now.Add(2*time.Minute).Equal(expiration.DeprovisionedAt))
+
+	// when
+	_, found, err = svc.GetExpiration("other-instance-id")
+
+	// then
+	require.NoError(t, err)
+	assert.False(t, found, "the expirations of the instances are independent")
+}
@@ -0,0 +1,78 @@
+package suspension
+
+import (
+	"net/http"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/middleware"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+type Handler struct {
+	service *Service
+	log     logrus.FieldLogger
+}
+
+func NewHandler(service *Service, log logrus.FieldLogger) *Handler {
+	return &Handler{
+		service: service,
+		log:     log,
+	}
+}
+
+func (h *Handler) AttachRoutes(router *mux.Router) {
+	router.HandleFunc("/runtimes/{runtime_id}/suspend", h.suspend).Methods(http.MethodPost)
+	router.HandleFunc("/runtimes/{runtime_id}/resume", h.resume).Methods(http.MethodPost)
+}
+
+// suspend starts the hibernation of the cluster of the trial runtime
+func (h *Handler) suspend(w http.ResponseWriter, r *http.Request) {
+	correlationID, _ := middleware.CorrelationIDFromContext(r.Context())
+
+	operation, err := h.service.Suspend(mux.Vars(r)["runtime_id"], correlationID)
+	if err != nil {
+		h.writeError(w, err, "while suspending runtime")
+		return
+	}
+
+	httputil.WriteResponse(w, http.StatusAccepted, toResponse(operation))
+}
+
+// resume wakes up the cluster of the suspended trial runtime
+func (h *Handler) resume(w http.ResponseWriter, r *http.Request) {
+	correlationID, _ := middleware.CorrelationIDFromContext(r.Context())
+
+	operation, err := h.service.Resume(mux.Vars(r)["runtime_id"], correlationID)
+	if err != nil {
+		h.writeError(w, err, "while resuming runtime")
+		return
+	}
+
+	httputil.WriteResponse(w, http.StatusAccepted, toResponse(operation))
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, err error, context string) {
+	switch err.(type) {
+	case NotFoundError:
+		httputil.WriteErrorResponse(w, http.StatusNotFound, err)
+	case NotSupportedError:
+		httputil.WriteErrorResponse(w, http.StatusUnprocessableEntity, err)
+	case ConflictError:
+		httputil.WriteErrorResponse(w, http.StatusConflict, err)
+	default:
+		h.log.Errorf("%s: %v", context, err)
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, context))
+	}
+}
+
+func toResponse(operation internal.SuspensionOperation) runtime.SuspensionResponse {
+	return runtime.SuspensionResponse{
+		OperationID: operation.ID,
+		Action:      operation.Action,
+	}
+}
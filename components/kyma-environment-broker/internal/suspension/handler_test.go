@@ -0,0 +1,159 @@
+package suspension_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/suspension"
+
+	"github.com/gorilla/mux"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	// given
+	db := storage.NewMemoryStorage()
+	require.NoError(t, db.Instances().Insert(fixInstance("trial", broker.TrialPlanID)))
+	require.NoError(t, db.Instances().Insert(fixInstance("azure", broker.AzurePlanID)))
+	require.NoError(t, db.Operations().InsertProvisioningOperation(fixProvisioningOperation("trial")))
+	require.NoError(t, db.Operations().InsertProvisioningOperation(fixProvisioningOperation("azure")))
+
+	queue := &fakeQueue{}
+	router := mux.NewRouter()
+	suspension.NewHandler(suspension.NewService(db.Instances(), db.Operations(), queue, logrus.New()), logrus.New()).AttachRoutes(router)
+
+	t.Run("should return not found for unknown runtime", func(t *testing.T) {
+		// when
+		rr := serve(router, "/runtimes/unknown/suspend")
+
+		// then
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("should reject runtime of another plan than trial", func(t *testing.T) {
+		// when
+		rr := serve(router, "/runtimes/runtime-azure/suspend")
+
+		// then
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+
+	t.Run("should reject resumption of runtime which is not suspended", func(t *testing.T) {
+		// when
+		rr := serve(router, "/runtimes/runtime-trial/resume")
+
+		// then
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	var suspensionID string
+	t.Run("should start suspension of trial runtime", func(t *testing.T) {
+		// when
+		rr := serve(router, "/runtimes/runtime-trial/suspend")
+
+		// then
+		require.Equal(t, http.StatusAccepted, rr.Code)
+		var response runtime.SuspensionResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Equal(t, internal.SuspensionActionSuspend, response.Action)
+		assert.Equal(t, []string{response.OperationID}, queue.ids)
+		suspensionID = response.OperationID
+
+		operation, err := db.Operations().GetSuspensionOperationByID(response.OperationID)
+		require.NoError(t, err)
+		assert.Equal(t, "instance-trial", operation.InstanceID)
+		assert.Equal(t, "shoot-trial", operation.ShootName)
+	})
+
+	t.Run("should return suspension in progress", func(t *testing.T) {
+		// when
+		rr := serve(router, "/runtimes/runtime-trial/suspend")
+
+		// then
+		require.Equal(t, http.StatusAccepted, rr.Code)
+		var response runtime.SuspensionResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Equal(t, suspensionID, response.OperationID)
+		assert.Len(t, queue.ids, 1, "the suspension must not be started twice")
+	})
+
+	t.Run("should reject resumption while suspension is in progress", func(t *testing.T) {
+		// when
+		rr := serve(router, "/runtimes/runtime-trial/resume")
+
+		// then
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("should reject suspension of suspended runtime", func(t *testing.T) {
+		// given
+		_, err := storage.UpdateWithRetrySuspensionOperation(db.Operations(), suspensionID, func(op *internal.SuspensionOperation) {
+			op.State = domain.Succeeded
+		})
+		require.NoError(t, err)
+
+		// when
+		rr := serve(router, "/runtimes/runtime-trial/suspend")
+
+		// then
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("should start resumption of suspended runtime", func(t *testing.T) {
+		// when
+		rr := serve(router, "/runtimes/runtime-trial/resume")
+
+		// then
+		require.Equal(t, http.StatusAccepted, rr.Code)
+		var response runtime.SuspensionResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Equal(t, internal.SuspensionActionResume, response.Action)
+		assert.Len(t, queue.ids, 2)
+	})
+}
+
+type fakeQueue struct {
+	ids []string
+}
+
+func (q *fakeQueue) Add(operationID string) {
+	q.ids = append(q.ids, operationID)
+}
+
+func serve(router *mux.Router, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+func fixInstance(name, planID string) internal.Instance {
+	return internal.Instance{
+		InstanceID:    "instance-" + name,
+		RuntimeID:     "runtime-" + name,
+		ShootName:     "shoot-" + name,
+		ServicePlanID: planID,
+		CreatedAt:     time.Now(),
+	}
+}
+
+func fixProvisioningOperation(name string) internal.ProvisioningOperation {
+	return internal.ProvisioningOperation{
+		Operation: internal.Operation{
+			ID:         "provisioning-" + name,
+			InstanceID: "instance-" + name,
+			State:      domain.Succeeded,
+			CreatedAt:  time.Now(),
+		},
+	}
+}
@@ -0,0 +1,155 @@
+package suspension
+
+import (
+	"fmt"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dberr"
+
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Queue is the queue of the suspension process
+type Queue interface {
+	Add(operationID string)
+}
+
+// NotFoundError is returned if the runtime does not exist
+type NotFoundError struct {
+	message string
+}
+
+func (e NotFoundError) Error() string {
+	return e.message
+}
+
+// NotSupportedError is returned if the runtime cannot be suspended, e.g. it is not a trial runtime
+type NotSupportedError struct {
+	message string
+}
+
+func (e NotSupportedError) Error() string {
+	return e.message
+}
+
+// ConflictError is returned if another operation of the runtime is in progress or the runtime is already
+// in the requested state
+type ConflictError struct {
+	message string
+}
+
+func (e ConflictError) Error() string {
+	return e.message
+}
+
+// Service starts the suspension of the trial runtime, which hibernates the cluster of the runtime, and
+// the resumption which wakes the cluster up. Only one operation of the runtime can be in progress at a time.
+type Service struct {
+	instances  storage.Instances
+	operations storage.Operations
+	queue      Queue
+	log        logrus.FieldLogger
+}
+
+func NewService(instances storage.Instances, operations storage.Operations, queue Queue, log logrus.FieldLogger) *Service {
+	return &Service{
+		instances:  instances,
+		operations: operations,
+		queue:      queue,
+		log:        log,
+	}
+}
+
+// Suspend starts the suspension of the runtime and returns the suspension operation
+func (s *Service) Suspend(runtimeID, correlationID string) (internal.SuspensionOperation, error) {
+	return s.start(runtimeID, internal.SuspensionActionSuspend, correlationID)
+}
+
+// Resume starts the resumption of the suspended runtime and returns the suspension operation
+func (s *Service) Resume(runtimeID, correlationID string) (internal.SuspensionOperation, error) {
+	return s.start(runtimeID, internal.SuspensionActionResume, correlationID)
+}
+
+func (s *Service) start(runtimeID, action, correlationID string) (internal.SuspensionOperation, error) {
+	log := s.log.WithField("runtimeID", runtimeID).WithField("action", action)
+
+	instances, err := s.instances.FindAllInstancesForRuntimes([]string{runtimeID})
+	switch {
+	case dberr.IsNotFound(err):
+		return internal.SuspensionOperation{}, NotFoundError{message: fmt.Sprintf("runtime %s does not exist", runtimeID)}
+	case err != nil:
+		return internal.SuspensionOperation{}, errors.Wrap(err, "while getting instance of the runtime")
+	}
+	instance := instances[0]
+	if instance.ServicePlanID != broker.TrialPlanID {
+		return internal.SuspensionOperation{}, NotSupportedError{message: "only the trial runtimes can be suspended"}
+	}
+
+	latest, err := s.operations.GetSuspensionOperationByInstanceID(instance.InstanceID)
+	switch {
+	case dberr.IsNotFound(err):
+		latest = nil
+	case err != nil:
+		return internal.SuspensionOperation{}, errors.Wrap(err, "while getting suspension operation")
+	}
+	if latest != nil && latest.Action == action && latest.State == domain.InProgress {
+		log.Infof("Suspension operation %s is already in progress", latest.ID)
+		return *latest, nil
+	}
+	if err := s.checkState(instance, action, latest); err != nil {
+		return internal.SuspensionOperation{}, err
+	}
+
+	operation := internal.NewSuspensionOperation(instance, action)
+	operation.CorrelationID = correlationID
+	if err := s.operations.InsertSuspensionOperation(operation); err != nil {
+		return internal.SuspensionOperation{}, errors.Wrap(err, "while inserting suspension operation")
+	}
+
+	log.Infof("Starting suspension operation %s", operation.ID)
+	s.queue.Add(operation.ID)
+
+	return operation, nil
+}
+
+// checkState returns the error if the runtime is not provisioned, any operation of the runtime is in progress
+// or the runtime is already in the requested state. The runtime which suspension or resumption failed can be
+// suspended and resumed again, because the state of its cluster is not known.
+func (s *Service) checkState(instance internal.Instance, action string, latest *internal.SuspensionOperation) error {
+	provisioning, err := s.operations.GetProvisioningOperationByInstanceID(instance.InstanceID)
+	if err != nil {
+		return errors.Wrap(err, "while getting provisioning operation")
+	}
+	if provisioning.State != domain.Succeeded || instance.ShootName == "" {
+		return NotSupportedError{message: "the runtime can be suspended only when it is provisioned"}
+	}
+	_, err = s.operations.GetDeprovisioningOperationByInstanceID(instance.InstanceID)
+	switch {
+	case err == nil:
+		return NotSupportedError{message: "the runtime cannot be suspended when it is being deprovisioned"}
+	case !dberr.IsNotFound(err):
+		return errors.Wrap(err, "while getting deprovisioning operation")
+	}
+
+	operations, err := s.operations.ListOperationsByInstanceID(instance.InstanceID)
+	if err != nil {
+		return errors.Wrap(err, "while listing operations of the instance")
+	}
+	for _, op := range operations {
+		if op.State == domain.InProgress {
+			return ConflictError{message: fmt.Sprintf("operation %s of the runtime is in progress", op.ID)}
+		}
+	}
+
+	switch {
+	case action == internal.SuspensionActionSuspend && latest != nil && latest.Suspends() && latest.State == domain.Succeeded:
+		return ConflictError{message: "the runtime is already suspended"}
+	case action == internal.SuspensionActionResume && (latest == nil || !latest.Suspends() && latest.State == domain.Succeeded):
+		return ConflictError{message: "the runtime is not suspended"}
+	}
+	return nil
+}
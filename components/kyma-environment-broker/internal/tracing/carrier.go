@@ -0,0 +1,40 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/api/global"
+	"go.opentelemetry.io/otel/api/trace"
+)
+
+// mapCarrier keeps the propagated trace context in a map, so it can be stored together with the operation
+type mapCarrier map[string]string
+
+func (c mapCarrier) Get(key string) string {
+	return c[key]
+}
+
+func (c mapCarrier) Set(key string, value string) {
+	c[key] = value
+}
+
+// ToCarrier returns the trace context of the span from the given context, nil is returned if there is no span.
+// The carrier is stored in the operation, so the steps processed later, also after KEB restart,
+// are recorded in the trace of the request which created the operation.
+func ToCarrier(ctx context.Context) map[string]string {
+	if !trace.SpanFromContext(ctx).SpanContext().IsValid() {
+		return nil
+	}
+	carrier := mapCarrier{}
+	global.TextMapPropagator().Inject(ctx, carrier)
+	return carrier
+}
+
+// FromCarrier returns a new context with the trace context stored in the carrier
+func FromCarrier(carrier map[string]string) context.Context {
+	ctx := context.Background()
+	if len(carrier) == 0 {
+		return ctx
+	}
+	return global.TextMapPropagator().Extract(ctx, mapCarrier(carrier))
+}
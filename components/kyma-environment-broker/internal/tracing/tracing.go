@@ -0,0 +1,97 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/api/global"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/trace/jaeger"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/propagators"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+const instrumentationName = "github.com/kyma-project/control-plane/components/kyma-environment-broker"
+
+type Config struct {
+	// Enabled turns on the export of the spans to Jaeger
+	Enabled bool `envconfig:"default=false"`
+	// CollectorEndpoint is the URL of the Jaeger collector, e.g. http://jaeger-collector:14268/api/traces
+	CollectorEndpoint string `envconfig:"optional"`
+	// ServiceName is the name of the service reported to Jaeger
+	ServiceName string `envconfig:"default=kyma-environment-broker"`
+	// SampleRatio defines the fraction of the traces started by KEB which are sampled, the traces
+	// continued from the callers are sampled according to the decision of the caller
+	SampleRatio float64 `envconfig:"default=1"`
+}
+
+// Init registers the W3C trace context propagator and, if enabled, the Jaeger export pipeline.
+// The returned function flushes the spans which are not exported yet.
+func Init(cfg Config, log logrus.FieldLogger) (func(), error) {
+	global.SetTextMapPropagator(otel.NewCompositeTextMapPropagator(propagators.TraceContext{}, propagators.Baggage{}))
+
+	if !cfg.Enabled {
+		log.Info("Tracing is disabled")
+		return func() {}, nil
+	}
+	if cfg.CollectorEndpoint == "" {
+		return nil, errors.New("the Jaeger collector endpoint must be set when tracing is enabled")
+	}
+
+	flush, err := jaeger.InstallNewPipeline(
+		jaeger.WithCollectorEndpoint(cfg.CollectorEndpoint),
+		jaeger.WithProcess(jaeger.Process{ServiceName: cfg.ServiceName}),
+		jaeger.WithSDK(&sdktrace.Config{
+			DefaultSampler: sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio)),
+		}),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "while installing Jaeger export pipeline")
+	}
+	log.Infof("Tracing is enabled, spans are exported to %s", cfg.CollectorEndpoint)
+
+	return flush, nil
+}
+
+// Tracer returns the tracer of KEB, it does not record any span unless the tracing is enabled
+func Tracer() trace.Tracer {
+	return global.Tracer(instrumentationName)
+}
+
+// End records the error, if any, and ends the span
+func End(ctx context.Context, span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+	}
+	span.End()
+}
+
+// StartSpan starts the child span of the span in the given context, e.g. of the call to the external system or
+// to the storage. No span is started outside of any trace, so the calls made e.g. by the periodic jobs do not
+// start new traces.
+func StartSpan(ctx context.Context, name string, attributes ...label.KeyValue) (context.Context, trace.Span) {
+	if !trace.SpanFromContext(ctx).SpanContext().IsValid() {
+		return ctx, trace.SpanFromContext(context.Background())
+	}
+	return Tracer().Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// Trace runs the call within the child span of the span in the given context, see StartSpan
+func Trace(ctx context.Context, name string, call func() error) error {
+	ctx, span := StartSpan(ctx, name)
+	err := call()
+	End(ctx, span, err)
+	return err
+}
+
+// StartOperationSpan starts the span of the operation processing within the trace stored in the carrier of the operation
+func StartOperationSpan(carrier map[string]string, name, operationID, instanceID string) (context.Context, trace.Span) {
+	return Tracer().Start(FromCarrier(carrier), name, trace.WithAttributes(
+		label.String("keb.operation.id", operationID),
+		label.String("keb.instance.id", instanceID),
+	))
+}
@@ -0,0 +1,124 @@
+package tracing_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/tracing"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/api/global"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/api/trace/tracetest"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func TestTracing(t *testing.T) {
+	// given
+	recorder := &tracetest.StandardSpanRecorder{}
+	global.SetTracerProvider(tracetest.NewTracerProvider(tracetest.WithSpanRecorder(recorder)))
+	_, err := tracing.Init(tracing.Config{}, logrus.New())
+	require.NoError(t, err)
+
+	var carrier map[string]string
+	external := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the trace context is propagated to the called system
+		assert.NotEmpty(t, r.Header.Get("traceparent"))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer external.Close()
+	client := &http.Client{Transport: tracing.NewTransport(http.DefaultTransport)}
+
+	router := mux.NewRouter()
+	router.Use(tracing.Middleware("keb"))
+	router.HandleFunc("/instances/{instance_id}", func(w http.ResponseWriter, r *http.Request) {
+		carrier = tracing.ToCarrier(r.Context())
+
+		req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, external.URL, nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	// when
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPut, "/instances/instance-id", nil))
+
+	ctx, span := tracing.StartOperationSpan(carrier, "provisioning/step", "operation-id", "instance-id")
+	tracing.End(ctx, span, errors.New("step failed"))
+
+	// then
+	require.Equal(t, http.StatusCreated, rr.Code)
+	spans := recorder.Completed()
+	require.Len(t, spans, 3)
+
+	clientSpan, serverSpan, stepSpan := spans[0], spans[1], spans[2]
+	assert.Equal(t, "PUT /instances/{instance_id}", serverSpan.Name())
+	assert.Equal(t, trace.SpanKindServer, serverSpan.SpanKind())
+	assert.Equal(t, trace.SpanKindClient, clientSpan.SpanKind())
+	assert.Equal(t, serverSpan.SpanContext().SpanID, clientSpan.ParentSpanID())
+
+	// the step processed later continues the trace of the request which created the operation
+	assert.Equal(t, "provisioning/step", stepSpan.Name())
+	assert.Equal(t, serverSpan.SpanContext().TraceID, stepSpan.SpanContext().TraceID)
+	assert.Equal(t, serverSpan.SpanContext().SpanID, stepSpan.ParentSpanID())
+	assert.Equal(t, codes.Error, stepSpan.StatusCode())
+}
+
+func TestTransport_WithoutTrace(t *testing.T) {
+	// given
+	recorder := &tracetest.StandardSpanRecorder{}
+	global.SetTracerProvider(tracetest.NewTracerProvider(tracetest.WithSpanRecorder(recorder)))
+
+	external := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("traceparent"))
+	}))
+	defer external.Close()
+	client := &http.Client{Transport: tracing.NewTransport(http.DefaultTransport)}
+
+	// when
+	resp, err := client.Get(external.URL)
+
+	// then
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Empty(t, recorder.Completed())
+	assert.Nil(t, tracing.ToCarrier(resp.Request.Context()))
+}
+
+func TestTrace(t *testing.T) {
+	// given
+	recorder := &tracetest.StandardSpanRecorder{}
+	global.SetTracerProvider(tracetest.NewTracerProvider(tracetest.WithSpanRecorder(recorder)))
+
+	ctx, span := tracing.StartOperationSpan(nil, "upgrade_kyma/step", "operation-id", "instance-id")
+
+	// when
+	err := tracing.Trace(ctx, "storage/UpdateUpgradeKymaOperation", func() error {
+		return errors.New("conflict")
+	})
+	tracing.End(ctx, span, nil)
+	errWithoutTrace := tracing.Trace(context.Background(), "storage/UpdateUpgradeKymaOperation", func() error {
+		return nil
+	})
+
+	// then
+	require.EqualError(t, err, "conflict")
+	require.NoError(t, errWithoutTrace)
+	spans := recorder.Completed()
+	require.Len(t, spans, 2)
+
+	storageSpan, stepSpan := spans[0], spans[1]
+	assert.Equal(t, "storage/UpdateUpgradeKymaOperation", storageSpan.Name())
+	assert.Equal(t, stepSpan.SpanContext().SpanID, storageSpan.ParentSpanID())
+	assert.Equal(t, codes.Error, storageSpan.StatusCode())
+}
@@ -0,0 +1,56 @@
+package trialexpiration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Notification informs the owner of the trial instance about its upcoming expiration
+type Notification struct {
+	InstanceID      string    `json:"instanceID"`
+	RuntimeID       string    `json:"runtimeID"`
+	GlobalAccountID string    `json:"globalAccountID"`
+	SubAccountID    string    `json:"subAccountID"`
+	UserID          string    `json:"userID"`
+	ExpiresAt       time.Time `json:"expiresAt"`
+}
+
+// Notifier delivers the notifications to the owners of the trial instances
+type Notifier interface {
+	Notify(notification Notification) error
+}
+
+// WebhookNotifier posts the notifications as JSON to the webhook, e.g. the hook which sends the emails
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookNotifier(url string, client *http.Client) *WebhookNotifier {
+	return &WebhookNotifier{
+		url:    url,
+		client: client,
+	}
+}
+
+func (n *WebhookNotifier) Notify(notification Notification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return errors.Wrap(err, "while marshalling notification")
+	}
+
+	resp, err := n.client.Post(n.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "while posting notification to the webhook")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return errors.Errorf("webhook responded with unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
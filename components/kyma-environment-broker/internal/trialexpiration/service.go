@@ -0,0 +1,179 @@
+package trialexpiration
+
+import (
+	"context"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/event"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage/dbsession/dbmodel"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Actor is recorded as the actor of the deprovisioning operations triggered by the expiration of the trial
+const Actor = "trial-expiration"
+
+// Config configures the expiration of the trial instances
+type Config struct {
+	// Disabled turns off the periodic expiration of the trial instances
+	Disabled bool `envconfig:"default=true"`
+	// Interval defines how often the trial instances are checked
+	Interval time.Duration `envconfig:"default=1h"`
+	// Duration is the lifetime of the trial instance counted from its creation
+	Duration time.Duration `envconfig:"default=336h"`
+	// NotificationLeadTime defines how long before the expiration the owner of the instance is notified
+	NotificationLeadTime time.Duration `envconfig:"default=24h"`
+	// WebhookURL is the URL the expiration notifications are posted to, no notifications are sent if it is empty
+	WebhookURL string `envconfig:"optional"`
+}
+
+// Deprovisioner triggers the deprovisioning of the instance, it is implemented by the broker deprovisioning endpoint
+type Deprovisioner interface {
+	Deprovision(ctx context.Context, instanceID string, details domain.DeprovisionDetails, asyncAllowed bool) (domain.DeprovisionServiceSpec, error)
+}
+
+// Service expires the trial instances. Every trial instance gets the expiration time when it is seen for the first
+// time, the owner of the instance is notified before the instance expires and the expired instance is deprovisioned.
+// The expired instance is never deprovisioned without the notification if the notifier is configured, the expiration
+// is postponed instead so the owner always gets at least the notification lead time.
+type Service struct {
+	instances     storage.Instances
+	expirations   storage.TrialExpirations
+	deprovisioner Deprovisioner
+	notifier      Notifier
+	publisher     event.Publisher
+	cfg           Config
+	log           logrus.FieldLogger
+
+	now func() time.Time
+}
+
+// NewService creates the service, the notifier may be nil if the owners of the instances are not notified
+func NewService(instances storage.Instances, expirations storage.TrialExpirations, deprovisioner Deprovisioner, notifier Notifier, pub event.Publisher, cfg Config, log logrus.FieldLogger) *Service {
+	return &Service{
+		instances:     instances,
+		expirations:   expirations,
+		deprovisioner: deprovisioner,
+		notifier:      notifier,
+		publisher:     pub,
+		cfg:           cfg,
+		log:           log,
+		now:           time.Now,
+	}
+}
+
+// Run checks all trial instances, the instances which fail are reported together and retried in the next run
+func (s *Service) Run(ctx context.Context) error {
+	instances, _, _, err := s.instances.ListWithState(dbmodel.InstanceFilter{Plans: []string{broker.TrialPlanName}})
+	if err != nil {
+		return errors.Wrap(err, "while listing trial instances")
+	}
+
+	var result *multierror.Error
+	for _, instance := range instances {
+		if err := s.expire(ctx, instance); err != nil {
+			result = multierror.Append(result, errors.Wrapf(err, "while expiring trial instance %s", instance.InstanceID))
+		}
+	}
+	s.log.Infof("Trial expiration finished, checked %d instances", len(instances))
+
+	return result.ErrorOrNil()
+}
+
+func (s *Service) expire(ctx context.Context, instance internal.InstanceWithState) error {
+	// the instances deprovisioned by their owners are skipped
+	if instance.LastOperationType == string(dbmodel.OperationTypeDeprovision) {
+		return nil
+	}
+
+	expiration, found, err := s.expirations.GetExpiration(instance.InstanceID)
+	if err != nil {
+		return errors.Wrap(err, "while getting expiration")
+	}
+	if !found {
+		expiration = internal.TrialExpiration{
+			InstanceID: instance.InstanceID,
+			ExpiresAt:  instance.CreatedAt.Add(s.cfg.Duration),
+		}
+		if err := s.save(&expiration); err != nil {
+			return err
+		}
+	}
+	if !expiration.DeprovisionedAt.IsZero() {
+		return nil
+	}
+
+	now := s.now()
+	if s.notifier != nil && expiration.NotifiedAt.IsZero() && !now.Before(expiration.ExpiresAt.Add(-s.cfg.NotificationLeadTime)) {
+		if err := s.notify(instance, &expiration); err != nil {
+			return err
+		}
+	}
+	if now.Before(expiration.ExpiresAt) {
+		return nil
+	}
+
+	s.log.Infof("Trial instance %s expired at %s, triggering deprovisioning", instance.InstanceID, expiration.ExpiresAt)
+	spec, err := s.deprovisioner.Deprovision(ctx, instance.InstanceID, domain.DeprovisionDetails{
+		PlanID:    instance.ServicePlanID,
+		ServiceID: instance.ServiceID,
+	}, true)
+	if err != nil {
+		return errors.Wrap(err, "while triggering deprovisioning")
+	}
+	// the deprovisioning operation is created by the broker endpoint, which has no user, so its start is recorded here
+	s.publisher.Publish(ctx, process.OperationStateChanged{
+		Operation: internal.Operation{
+			ID:          spec.OperationData,
+			InstanceID:  instance.InstanceID,
+			State:       domain.InProgress,
+			Description: "deprovisioning triggered by the expiration of the trial",
+		},
+		Actor: Actor,
+	})
+	expiration.DeprovisionedAt = now
+	return s.save(&expiration)
+}
+
+// notify sends the notification and postpones the expiration if the owner would not get the whole lead time
+func (s *Service) notify(instance internal.InstanceWithState, expiration *internal.TrialExpiration) error {
+	now := s.now()
+	if latest := now.Add(s.cfg.NotificationLeadTime); expiration.ExpiresAt.Before(latest) {
+		expiration.ExpiresAt = latest
+	}
+
+	pp, err := instance.GetProvisioningParameters()
+	if err != nil {
+		return errors.Wrap(err, "while getting provisioning parameters")
+	}
+	err = s.notifier.Notify(Notification{
+		InstanceID:      instance.InstanceID,
+		RuntimeID:       instance.RuntimeID,
+		GlobalAccountID: instance.GlobalAccountID,
+		SubAccountID:    instance.SubAccountID,
+		UserID:          pp.ErsContext.UserID,
+		ExpiresAt:       expiration.ExpiresAt,
+	})
+	if err != nil {
+		return errors.Wrap(err, "while sending notification")
+	}
+	s.log.Infof("Owner of trial instance %s notified about the expiration at %s", instance.InstanceID, expiration.ExpiresAt)
+
+	expiration.NotifiedAt = now
+	return s.save(expiration)
+}
+
+func (s *Service) save(expiration *internal.TrialExpiration) error {
+	expiration.UpdatedAt = s.now()
+	if err := s.expirations.UpsertExpiration(*expiration); err != nil {
+		return errors.Wrap(err, "while saving expiration")
+	}
+	return nil
+}
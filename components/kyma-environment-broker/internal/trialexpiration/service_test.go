@@ -0,0 +1,241 @@
+package trialexpiration_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/event"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/trialexpiration"
+
+	"github.com/pivotal-cf/brokerapi/v7/domain"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var fixConfig = trialexpiration.Config{
+	Duration:             2 * time.Hour,
+	NotificationLeadTime: 30 * time.Minute,
+}
+
+func TestService(t *testing.T) {
+	t.Run("should store the expiration of the new trial instance", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		createdAt := time.Now().Add(-time.Hour)
+		require.NoError(t, db.Instances().Insert(fixInstance("trial", broker.TrialPlanName, createdAt)))
+		require.NoError(t, db.Instances().Insert(fixInstance("azure", broker.AzurePlanName, createdAt.Add(-24*time.Hour))))
+		notifier := &fakeNotifier{}
+		deprovisioner := &fakeDeprovisioner{}
+		svc := trialexpiration.NewService(db.Instances(), db.TrialExpirations(), deprovisioner, notifier, event.NewPubSub(), fixConfig, logrus.New())
+
+		// when
+		err := svc.Run(context.Background())
+
+		// then
+		require.NoError(t, err)
+		expiration, found, err := db.TrialExpirations().GetExpiration("trial")
+		require.NoError(t, err)
+		require.True(t, found)
+		assert.True(t, createdAt.Add(2*time.Hour).Equal(expiration.ExpiresAt))
+		assert.True(t, expiration.NotifiedAt.IsZero())
+		assert.Empty(t, notifier.notifications)
+		assert.Empty(t, deprovisioner.instanceIDs)
+
+		_, found, err = db.TrialExpirations().GetExpiration("azure")
+		require.NoError(t, err)
+		assert.False(t, found, "only the trial instances expire")
+	})
+
+	t.Run("should notify the owner before the expiration", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		require.NoError(t, db.Instances().Insert(fixInstance("trial", broker.TrialPlanName, time.Now().Add(-110*time.Minute))))
+		notifier := &fakeNotifier{}
+		deprovisioner := &fakeDeprovisioner{}
+		svc := trialexpiration.NewService(db.Instances(), db.TrialExpirations(), deprovisioner, notifier, event.NewPubSub(), fixConfig, logrus.New())
+
+		// when
+		err := svc.Run(context.Background())
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, notifier.notifications, 1)
+		assert.Equal(t, "trial", notifier.notifications[0].InstanceID)
+		assert.Equal(t, "user-trial", notifier.notifications[0].UserID)
+		assert.Empty(t, deprovisioner.instanceIDs)
+
+		// when
+		err = svc.Run(context.Background())
+
+		// then
+		require.NoError(t, err)
+		assert.Len(t, notifier.notifications, 1, "the owner must be notified only once")
+	})
+
+	t.Run("should postpone the expiration until the owner is notified", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		require.NoError(t, db.Instances().Insert(fixInstance("trial", broker.TrialPlanName, time.Now().Add(-3*time.Hour))))
+		notifier := &fakeNotifier{}
+		deprovisioner := &fakeDeprovisioner{}
+		svc := trialexpiration.NewService(db.Instances(), db.TrialExpirations(), deprovisioner, notifier, event.NewPubSub(), fixConfig, logrus.New())
+
+		// when
+		err := svc.Run(context.Background())
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, notifier.notifications, 1)
+		assert.WithinDuration(t, time.Now().Add(30*time.Minute), notifier.notifications[0].ExpiresAt, time.Minute)
+		assert.Empty(t, deprovisioner.instanceIDs)
+
+		expiration, _, err := db.TrialExpirations().GetExpiration("trial")
+		require.NoError(t, err)
+		assert.True(t, notifier.notifications[0].ExpiresAt.Equal(expiration.ExpiresAt))
+	})
+
+	t.Run("should deprovision the expired instance", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		require.NoError(t, db.Instances().Insert(fixInstance("trial", broker.TrialPlanName, time.Now().Add(-3*time.Hour))))
+		require.NoError(t, db.TrialExpirations().UpsertExpiration(internal.TrialExpiration{
+			InstanceID: "trial",
+			ExpiresAt:  time.Now().Add(-time.Minute),
+			NotifiedAt: time.Now().Add(-time.Hour),
+		}))
+		notifier := &fakeNotifier{}
+		deprovisioner := &fakeDeprovisioner{}
+		pub := &fakePublisher{}
+		svc := trialexpiration.NewService(db.Instances(), db.TrialExpirations(), deprovisioner, notifier, pub, fixConfig, logrus.New())
+
+		// when
+		err := svc.Run(context.Background())
+
+		// then
+		require.NoError(t, err)
+		assert.Empty(t, notifier.notifications)
+		assert.Equal(t, []string{"trial"}, deprovisioner.instanceIDs)
+		require.Len(t, pub.events, 1)
+		changed := pub.events[0].(process.OperationStateChanged)
+		assert.Equal(t, trialexpiration.Actor, changed.Actor)
+		assert.Equal(t, "deprovisioning-trial", changed.Operation.ID)
+		assert.Equal(t, domain.InProgress, changed.Operation.State)
+		expiration, _, err := db.TrialExpirations().GetExpiration("trial")
+		require.NoError(t, err)
+		assert.False(t, expiration.DeprovisionedAt.IsZero())
+
+		// when
+		err = svc.Run(context.Background())
+
+		// then
+		require.NoError(t, err)
+		assert.Len(t, deprovisioner.instanceIDs, 1, "the deprovisioning must be triggered only once")
+	})
+
+	t.Run("should deprovision the expired instance without notification if the notifier is not configured", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		require.NoError(t, db.Instances().Insert(fixInstance("trial", broker.TrialPlanName, time.Now().Add(-3*time.Hour))))
+		deprovisioner := &fakeDeprovisioner{}
+		svc := trialexpiration.NewService(db.Instances(), db.TrialExpirations(), deprovisioner, nil, event.NewPubSub(), fixConfig, logrus.New())
+
+		// when
+		err := svc.Run(context.Background())
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"trial"}, deprovisioner.instanceIDs)
+	})
+
+	t.Run("should skip the instance deprovisioned by its owner", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		require.NoError(t, db.Instances().Insert(fixInstance("trial", broker.TrialPlanName, time.Now().Add(-3*time.Hour))))
+		operation, err := internal.NewDeprovisioningOperationWithID("deprovisioning-trial", "trial")
+		require.NoError(t, err)
+		require.NoError(t, db.Operations().InsertDeprovisioningOperation(operation))
+		deprovisioner := &fakeDeprovisioner{}
+		svc := trialexpiration.NewService(db.Instances(), db.TrialExpirations(), deprovisioner, nil, event.NewPubSub(), fixConfig, logrus.New())
+
+		// when
+		err = svc.Run(context.Background())
+
+		// then
+		require.NoError(t, err)
+		assert.Empty(t, deprovisioner.instanceIDs)
+	})
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	// given
+	var received trialexpiration.Notification
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/failing" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	// when
+	err := trialexpiration.NewWebhookNotifier(server.URL, server.Client()).Notify(trialexpiration.Notification{InstanceID: "trial", UserID: "user"})
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, "trial", received.InstanceID)
+	assert.Equal(t, "user", received.UserID)
+
+	// when
+	err = trialexpiration.NewWebhookNotifier(server.URL+"/failing", server.Client()).Notify(trialexpiration.Notification{InstanceID: "trial"})
+
+	// then
+	assert.Error(t, err)
+}
+
+type fakeNotifier struct {
+	notifications []trialexpiration.Notification
+}
+
+func (n *fakeNotifier) Notify(notification trialexpiration.Notification) error {
+	n.notifications = append(n.notifications, notification)
+	return nil
+}
+
+type fakeDeprovisioner struct {
+	instanceIDs []string
+}
+
+func (d *fakeDeprovisioner) Deprovision(_ context.Context, instanceID string, _ domain.DeprovisionDetails, _ bool) (domain.DeprovisionServiceSpec, error) {
+	d.instanceIDs = append(d.instanceIDs, instanceID)
+	return domain.DeprovisionServiceSpec{IsAsync: true, OperationData: "deprovisioning-" + instanceID}, nil
+}
+
+type fakePublisher struct {
+	events []interface{}
+}
+
+func (p *fakePublisher) Publish(_ context.Context, ev interface{}) {
+	p.events = append(p.events, ev)
+}
+
+func fixInstance(id, planName string, createdAt time.Time) internal.Instance {
+	return internal.Instance{
+		InstanceID:             id,
+		RuntimeID:              "runtime-" + id,
+		ServicePlanName:        planName,
+		ProvisioningParameters: `{"ers_context":{"user_id":"user-` + id + `"}}`,
+		CreatedAt:              createdAt,
+	}
+}
@@ -0,0 +1,244 @@
+package upgradeverification
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/avs"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/provisioner"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/storage"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	batchv1 "k8s.io/api/batch/v1"
+	coreV1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	domainPlaceholder = "{domain}"
+	smokeTestJobName  = "keb-verification-%s-%d"
+	smokeTestLabel    = "kyma-project.io/upgrade-verification"
+	// smokeTestJobTTL removes the finished job which was not deleted by the check, e.g. after the verification timed out
+	smokeTestJobTTL = int32(time.Hour / time.Second)
+)
+
+// NewChecks creates the checks enabled in the configuration
+func NewChecks(cfg Config, httpClient *http.Client, avsClient EvaluationGetter, operations storage.Provisioning, provisionerClient provisioner.Client) []Check {
+	checks := make([]Check, 0)
+	for _, probe := range cfg.HTTPProbes {
+		checks = append(checks, NewHTTPProbe(httpClient, probe))
+	}
+	if cfg.AVS {
+		checks = append(checks, NewAVSCheck(avsClient, operations))
+	}
+	if cfg.SmokeTestImage != "" {
+		checks = append(checks, NewSmokeTest(provisionerClient, NewRuntimeClient, cfg.SmokeTestImage, cfg.SmokeTestNamespace))
+	}
+	return checks
+}
+
+// HTTPProbe checks if the endpoint of the runtime responds with a 2xx status code
+type HTTPProbe struct {
+	httpClient *http.Client
+	urlPattern string
+}
+
+func NewHTTPProbe(httpClient *http.Client, urlPattern string) *HTTPProbe {
+	return &HTTPProbe{httpClient: httpClient, urlPattern: urlPattern}
+}
+
+func (p *HTTPProbe) Name() string {
+	return fmt.Sprintf("http-probe %s", p.urlPattern)
+}
+
+func (p *HTTPProbe) Run(runtime Runtime, _ logrus.FieldLogger) (string, string) {
+	domain, err := runtimeDomain(runtime.DashboardURL)
+	if err != nil {
+		return internal.VerificationFailed, err.Error()
+	}
+	probeURL := strings.Replace(p.urlPattern, domainPlaceholder, domain, -1)
+
+	resp, err := p.httpClient.Get(probeURL)
+	if err != nil {
+		return internal.VerificationPending, fmt.Sprintf("while calling %s: %s", probeURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return internal.VerificationPending, fmt.Sprintf("%s returned %d status code", probeURL, resp.StatusCode)
+	}
+	return internal.VerificationPassed, fmt.Sprintf("%s returned %d status code", probeURL, resp.StatusCode)
+}
+
+// runtimeDomain returns the domain of the runtime from its dashboard URL, e.g. https://console.{domain}
+func runtimeDomain(dashboardURL string) (string, error) {
+	u, err := url.Parse(dashboardURL)
+	if err != nil || u.Host == "" {
+		return "", errors.Errorf("invalid dashboard URL %q", dashboardURL)
+	}
+	return strings.TrimPrefix(u.Host, "console."), nil
+}
+
+// EvaluationGetter fetches the AVS evaluation
+type EvaluationGetter interface {
+	GetEvaluation(evaluationId int64) (*avs.BasicEvaluationCreateResponse, error)
+}
+
+// AVSCheck checks if the internal AVS evaluation created during the provisioning of the runtime is active
+type AVSCheck struct {
+	avsClient  EvaluationGetter
+	operations storage.Provisioning
+}
+
+func NewAVSCheck(avsClient EvaluationGetter, operations storage.Provisioning) *AVSCheck {
+	return &AVSCheck{avsClient: avsClient, operations: operations}
+}
+
+func (c *AVSCheck) Name() string {
+	return "avs"
+}
+
+func (c *AVSCheck) Run(runtime Runtime, _ logrus.FieldLogger) (string, string) {
+	operation, err := c.operations.GetProvisioningOperationByInstanceID(runtime.InstanceID)
+	if err != nil {
+		return internal.VerificationPending, fmt.Sprintf("while getting provisioning operation: %s", err)
+	}
+	evaluationID := operation.Avs.AvsEvaluationInternalId
+	if evaluationID == 0 {
+		return internal.VerificationPassed, "runtime has no internal evaluation"
+	}
+
+	evaluation, err := c.avsClient.GetEvaluation(evaluationID)
+	if err != nil {
+		return internal.VerificationPending, fmt.Sprintf("while getting evaluation %d: %s", evaluationID, err)
+	}
+	if evaluation.Status != avs.StatusActive {
+		return internal.VerificationPending, fmt.Sprintf("evaluation %d has status %s", evaluationID, evaluation.Status)
+	}
+	return internal.VerificationPassed, fmt.Sprintf("evaluation %d is active", evaluationID)
+}
+
+// RuntimeClientFactory creates the client of the runtime cluster from its kubeconfig
+type RuntimeClientFactory func(kubeconfig string) (client.Client, error)
+
+// NewRuntimeClient creates the client of the runtime cluster from its kubeconfig
+func NewRuntimeClient(kubeconfig string) (client.Client, error) {
+	cfg, err := clientcmd.RESTConfigFromKubeConfig([]byte(kubeconfig))
+	if err != nil {
+		return nil, errors.Wrap(err, "while creating REST config from kubeconfig")
+	}
+	return client.New(cfg, client.Options{Scheme: scheme.Scheme})
+}
+
+// SmokeTest runs the job with the smoke tests in the runtime, the job is created once and its result is checked
+// until it finishes, the finished job is deleted from the runtime
+type SmokeTest struct {
+	provisionerClient provisioner.Client
+	newClient         RuntimeClientFactory
+	image             string
+	namespace         string
+}
+
+func NewSmokeTest(provisionerClient provisioner.Client, newClient RuntimeClientFactory, image, namespace string) *SmokeTest {
+	return &SmokeTest{
+		provisionerClient: provisionerClient,
+		newClient:         newClient,
+		image:             image,
+		namespace:         namespace,
+	}
+}
+
+func (s *SmokeTest) Name() string {
+	return "smoke-test"
+}
+
+func (s *SmokeTest) Run(runtime Runtime, log logrus.FieldLogger) (string, string) {
+	status, err := s.provisionerClient.RuntimeStatus(runtime.GlobalAccountID, runtime.RuntimeID)
+	if err != nil {
+		return internal.VerificationPending, fmt.Sprintf("while getting runtime status: %s", err)
+	}
+	if status.RuntimeConfiguration == nil || status.RuntimeConfiguration.Kubeconfig == nil {
+		return internal.VerificationPending, "runtime status does not contain kubeconfig"
+	}
+	cli, err := s.newClient(*status.RuntimeConfiguration.Kubeconfig)
+	if err != nil {
+		return internal.VerificationPending, fmt.Sprintf("while creating runtime client: %s", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	name := fmt.Sprintf(smokeTestJobName, runtime.OperationID, runtime.RetryCount)
+	job := &batchv1.Job{}
+	err = cli.Get(ctx, client.ObjectKey{Namespace: s.namespace, Name: name}, job)
+	switch {
+	case apierrors.IsNotFound(err):
+		err = cli.Create(ctx, s.job(name, runtime))
+		if err != nil && !apierrors.IsAlreadyExists(err) {
+			return internal.VerificationPending, fmt.Sprintf("while creating job %s: %s", name, err)
+		}
+		log.Infof("Created smoke test job %s/%s", s.namespace, name)
+		return internal.VerificationPending, fmt.Sprintf("job %s created", name)
+	case err != nil:
+		return internal.VerificationPending, fmt.Sprintf("while getting job %s: %s", name, err)
+	}
+
+	var state, message string
+	switch {
+	case job.Status.Succeeded > 0:
+		state, message = internal.VerificationPassed, fmt.Sprintf("job %s succeeded", name)
+	case job.Status.Failed > 0:
+		state, message = internal.VerificationFailed, fmt.Sprintf("job %s failed", name)
+	default:
+		return internal.VerificationPending, fmt.Sprintf("job %s is running", name)
+	}
+
+	// the result is kept in the verification, the job with its pods is not needed anymore
+	err = cli.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground))
+	if err != nil && !apierrors.IsNotFound(err) {
+		log.Warnf("Unable to delete smoke test job %s/%s, it is removed after %ds: %s", s.namespace, name, smokeTestJobTTL, err)
+	}
+	return state, message
+}
+
+func (s *SmokeTest) job(name string, runtime Runtime) *batchv1.Job {
+	backoffLimit := int32(0)
+	ttl := smokeTestJobTTL
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: s.namespace,
+			Labels:    map[string]string{smokeTestLabel: runtime.OperationID},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            &backoffLimit,
+			TTLSecondsAfterFinished: &ttl,
+			Template: coreV1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{smokeTestLabel: runtime.OperationID},
+				},
+				Spec: coreV1.PodSpec{
+					RestartPolicy: coreV1.RestartPolicyNever,
+					Containers: []coreV1.Container{
+						{
+							Name:  "smoke-test",
+							Image: s.image,
+							Env: []coreV1.EnvVar{
+								{Name: "RUNTIME_ID", Value: runtime.RuntimeID},
+								{Name: "GLOBALACCOUNT_ID", Value: runtime.GlobalAccountID},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
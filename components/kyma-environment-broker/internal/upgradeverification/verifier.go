@@ -0,0 +1,133 @@
+package upgradeverification
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+
+	"github.com/sirupsen/logrus"
+)
+
+type Config struct {
+	// Disabled turns off the verification, the upgrade operation succeeds as soon as the Provisioner upgraded the runtime
+	Disabled bool `envconfig:"default=true"`
+	// HTTPProbes are the URLs which must respond with a 2xx status code,
+	// the {domain} placeholder is replaced with the domain of the runtime, e.g. https://console.{domain}/healthz
+	HTTPProbes []string `envconfig:"optional"`
+	// AVS checks if the internal AVS evaluation of the runtime is active
+	AVS bool `envconfig:"default=false"`
+	// SmokeTestImage is the image of the job executed in the runtime, the smoke test is skipped if it is empty
+	SmokeTestImage     string `envconfig:"optional"`
+	SmokeTestNamespace string `envconfig:"default=kyma-system"`
+	// Interval defines how often the checks which have not passed yet are repeated
+	Interval time.Duration `envconfig:"default=1m"`
+	// Timeout is the time after which the checks which have not passed yet fail
+	Timeout time.Duration `envconfig:"default=30m"`
+}
+
+// Runtime is the upgraded runtime verified by the checks
+type Runtime struct {
+	OperationID     string
+	InstanceID      string
+	RuntimeID       string
+	GlobalAccountID string
+	DashboardURL    string
+	// RetryCount tells apart the verifications of the retried upgrade operation
+	RetryCount int
+}
+
+// Check verifies a single aspect of the upgraded runtime. It returns the VerificationPending state if the check
+// should be repeated later, for example because the runtime components are still starting.
+type Check interface {
+	Name() string
+	Run(runtime Runtime, log logrus.FieldLogger) (state string, message string)
+}
+
+// Verifier executes the post-upgrade checks, the checks which passed or failed are not executed again
+type Verifier struct {
+	checks   []Check
+	interval time.Duration
+	timeout  time.Duration
+	now      func() time.Time
+}
+
+// NewVerifier creates the Verifier executing the given checks
+func NewVerifier(cfg Config, checks ...Check) *Verifier {
+	return &Verifier{
+		checks:   checks,
+		interval: cfg.Interval,
+		timeout:  cfg.Timeout,
+		now:      time.Now,
+	}
+}
+
+// Verify executes the checks which have not finished yet and returns the updated verification together with
+// the time after which the verification must be repeated, the zero duration means the verification is finished
+func (v *Verifier) Verify(runtime Runtime, verification *internal.UpgradeVerification, log logrus.FieldLogger) (*internal.UpgradeVerification, time.Duration) {
+	now := v.now()
+	if verification == nil {
+		verification = &internal.UpgradeVerification{State: internal.VerificationPending, StartedAt: now}
+		for _, check := range v.checks {
+			verification.Checks = append(verification.Checks, internal.VerificationCheck{Name: check.Name(), State: internal.VerificationPending})
+		}
+	}
+
+	for i := range verification.Checks {
+		result := &verification.Checks[i]
+		if result.State != internal.VerificationPending {
+			continue
+		}
+		check, found := v.check(result.Name)
+		if !found {
+			// the check was removed from the configuration after the verification started
+			result.State = internal.VerificationPassed
+			result.Message = "check is not configured"
+			continue
+		}
+		result.State, result.Message = check.Run(runtime, log.WithField("check", result.Name))
+		result.CheckedAt = now
+		log.Infof("Verification check %s: %s %s", result.Name, result.State, result.Message)
+	}
+
+	timedOut := now.Sub(verification.StartedAt) > v.timeout
+	verification.State = internal.VerificationPassed
+	for i := range verification.Checks {
+		result := &verification.Checks[i]
+		if result.State == internal.VerificationPending && timedOut {
+			result.State = internal.VerificationFailed
+			result.Message = fmt.Sprintf("timed out after %s: %s", v.timeout, result.Message)
+		}
+		switch {
+		case result.State == internal.VerificationFailed:
+			verification.State = internal.VerificationFailed
+		case result.State == internal.VerificationPending && verification.State != internal.VerificationFailed:
+			verification.State = internal.VerificationPending
+		}
+	}
+
+	if verification.State == internal.VerificationPending {
+		return verification, v.interval
+	}
+	verification.FinishedAt = now
+	return verification, 0
+}
+
+// FailedCheck returns the first failed check of the verification
+func FailedCheck(verification *internal.UpgradeVerification) (internal.VerificationCheck, bool) {
+	for _, check := range verification.Checks {
+		if check.State == internal.VerificationFailed {
+			return check, true
+		}
+	}
+	return internal.VerificationCheck{}, false
+}
+
+func (v *Verifier) check(name string) (Check, bool) {
+	for _, check := range v.checks {
+		if check.Name() == name {
+			return check, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,151 @@
+package upgradeverification
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal"
+	"github.com/kyma-project/control-plane/components/kyma-environment-broker/internal/provisioner"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// fakeCheck returns the given states one after another and repeats the last one
+type fakeCheck struct {
+	name   string
+	states []string
+	runs   int
+}
+
+func (c *fakeCheck) Name() string {
+	return c.name
+}
+
+func (c *fakeCheck) Run(_ Runtime, _ logrus.FieldLogger) (string, string) {
+	state := c.states[len(c.states)-1]
+	if c.runs < len(c.states) {
+		state = c.states[c.runs]
+	}
+	c.runs++
+	return state, "fake"
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	t.Run("should repeat pending checks until all passed", func(t *testing.T) {
+		// given
+		passing := &fakeCheck{name: "passing", states: []string{internal.VerificationPassed}}
+		slow := &fakeCheck{name: "slow", states: []string{internal.VerificationPending, internal.VerificationPassed}}
+		verifier := NewVerifier(Config{Interval: time.Minute, Timeout: time.Hour}, passing, slow)
+
+		// when
+		verification, repeat := verifier.Verify(Runtime{}, nil, logrus.New())
+
+		// then
+		assert.Equal(t, time.Minute, repeat)
+		assert.Equal(t, internal.VerificationPending, verification.State)
+		require.Len(t, verification.Checks, 2)
+		assert.Equal(t, internal.VerificationPassed, verification.Checks[0].State)
+		assert.Equal(t, internal.VerificationPending, verification.Checks[1].State)
+
+		// when
+		verification, repeat = verifier.Verify(Runtime{}, verification, logrus.New())
+
+		// then
+		assert.Zero(t, repeat)
+		assert.Equal(t, internal.VerificationPassed, verification.State)
+		assert.False(t, verification.FinishedAt.IsZero())
+		assert.Equal(t, 1, passing.runs)
+		assert.Equal(t, 2, slow.runs)
+	})
+
+	t.Run("should fail pending checks after timeout", func(t *testing.T) {
+		// given
+		now := time.Now()
+		pending := &fakeCheck{name: "pending", states: []string{internal.VerificationPending}}
+		verifier := NewVerifier(Config{Interval: time.Minute, Timeout: time.Hour}, pending)
+		verification, _ := verifier.Verify(Runtime{}, nil, logrus.New())
+		verifier.now = func() time.Time { return now.Add(2 * time.Hour) }
+
+		// when
+		verification, repeat := verifier.Verify(Runtime{}, verification, logrus.New())
+
+		// then
+		assert.Zero(t, repeat)
+		assert.Equal(t, internal.VerificationFailed, verification.State)
+		check, failed := FailedCheck(verification)
+		require.True(t, failed)
+		assert.Equal(t, "pending", check.Name)
+		assert.True(t, strings.HasPrefix(check.Message, "timed out after 1h0m0s"))
+	})
+}
+
+func TestHTTPProbe_Run(t *testing.T) {
+	// given
+	healthy := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/healthz", r.URL.Path)
+		if !healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+	domain := strings.TrimPrefix(server.URL, "http://")
+	probe := NewHTTPProbe(server.Client(), "http://{domain}/healthz")
+	runtime := Runtime{DashboardURL: "https://console." + domain}
+
+	// when
+	state, _ := probe.Run(runtime, logrus.New())
+
+	// then
+	assert.Equal(t, internal.VerificationPassed, state)
+
+	// when
+	healthy = false
+	state, message := probe.Run(runtime, logrus.New())
+
+	// then
+	assert.Equal(t, internal.VerificationPending, state)
+	assert.Contains(t, message, "503")
+}
+
+func TestSmokeTest_Run(t *testing.T) {
+	// given
+	provisionerClient := provisioner.NewFakeClient()
+	provisionerClient.SetKubeconfig("runtime-id", "kubeconfig")
+	cli := fake.NewFakeClientWithScheme(scheme.Scheme)
+	smokeTest := NewSmokeTest(provisionerClient, func(string) (client.Client, error) {
+		return cli, nil
+	}, "smoke-test:latest", "kyma-system")
+	runtime := Runtime{OperationID: "operation-id", RuntimeID: "runtime-id", GlobalAccountID: "ga-id"}
+	key := client.ObjectKey{Namespace: "kyma-system", Name: "keb-verification-operation-id-0"}
+
+	// when
+	state, _ := smokeTest.Run(runtime, logrus.New())
+
+	// then
+	assert.Equal(t, internal.VerificationPending, state)
+	job := &batchv1.Job{}
+	require.NoError(t, cli.Get(context.TODO(), key, job))
+	require.NotNil(t, job.Spec.TTLSecondsAfterFinished)
+
+	// when
+	job.Status.Succeeded = 1
+	require.NoError(t, cli.Update(context.TODO(), job))
+	state, _ = smokeTest.Run(runtime, logrus.New())
+
+	// then
+	assert.Equal(t, internal.VerificationPassed, state)
+	err := cli.Get(context.TODO(), key, &batchv1.Job{})
+	assert.True(t, apierrors.IsNotFound(err), "the finished job must be deleted")
+}
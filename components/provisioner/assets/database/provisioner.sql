@@ -73,7 +73,8 @@ CREATE TABLE operation
     cluster_id uuid NOT NULL,
     foreign key (cluster_id) REFERENCES cluster (id) ON DELETE CASCADE,
     stage varchar(256) NOT NULL,
-    last_transition timestamp without time zone
+    last_transition timestamp without time zone,
+    installation_timeout_seconds integer NOT NULL DEFAULT 0
 );
 
 -- Kyma Release
@@ -51,6 +51,10 @@ func (v *validator) ValidateUpgradeInput(input gqlschema.UpgradeRuntimeInput) ap
 		return err.Append("validation error while starting Runtime upgrade")
 	}
 
+	if input.InstallationTimeoutSeconds != nil && *input.InstallationTimeoutSeconds <= 0 {
+		return apperrors.BadRequest("validation error while starting Runtime upgrade: installation timeout must be positive")
+	}
+
 	return nil
 }
 
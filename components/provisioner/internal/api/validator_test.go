@@ -193,6 +193,34 @@ func TestValidator_ValidateUpgradeInput(t *testing.T) {
 		//then
 		require.Error(t, err)
 	})
+
+	t.Run("Should return error when installation timeout is not positive", func(t *testing.T) {
+		//given
+		validator := NewValidator(nil)
+
+		kymaConfig := &gqlschema.KymaConfigInput{
+			Version: "1.5",
+			Components: []*gqlschema.ComponentConfigurationInput{
+				{
+					Component:     "core",
+					Configuration: nil,
+				},
+				{
+					Component:     "compass-runtime-agent",
+					Configuration: nil,
+				},
+			},
+		}
+		timeout := 0
+
+		input := gqlschema.UpgradeRuntimeInput{KymaConfig: kymaConfig, InstallationTimeoutSeconds: &timeout}
+
+		//when
+		err := validator.ValidateUpgradeInput(input)
+
+		//then
+		require.Error(t, err)
+	})
 }
 
 func TestValidator_ValidateUpgradeShootInput(t *testing.T) {
@@ -69,6 +69,14 @@ type Operation struct {
 	ClusterID      string
 	Stage          OperationStage
 	LastTransition *time.Time
+	// InstallationTimeoutSeconds overrides the time limit of waiting for the Kyma installation, 0 means the default time limit
+	InstallationTimeoutSeconds int
+}
+
+// InstallationTimeout returns the time limit of waiting for the Kyma installation requested for the operation,
+// 0 means the default time limit
+func (o Operation) InstallationTimeout() time.Duration {
+	return time.Duration(o.InstallationTimeoutSeconds) * time.Second
 }
 
 type RuntimeAgentConnectionStatus int
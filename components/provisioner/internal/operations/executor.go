@@ -108,7 +108,7 @@ func (e *Executor) process(operation model.Operation, cluster model.Cluster, log
 		log := logger.WithField("Stage", step.Name())
 		log.Infof("Starting processing")
 
-		if e.timeoutReached(operation, step.TimeLimit()) {
+		if e.timeoutReached(operation, e.timeLimit(step, operation)) {
 			log.Errorf("Timeout reached for operation")
 			return false, 0, NewNonRecoverableError(fmt.Errorf("error: timeout while processing operation"))
 		}
@@ -145,6 +145,15 @@ func (e *Executor) process(operation model.Operation, cluster model.Cluster, log
 	return false, 0, nil
 }
 
+// timeLimit returns the time limit of the step, the installation timeout requested for the operation
+// overrides the time limit of waiting for the Kyma installation
+func (e *Executor) timeLimit(step Step, operation model.Operation) time.Duration {
+	if step.Name() == model.WaitingForInstallation && operation.InstallationTimeout() > 0 {
+		return operation.InstallationTimeout()
+	}
+	return step.TimeLimit()
+}
+
 func (e *Executor) timeoutReached(operation model.Operation, timeout time.Duration) bool {
 
 	lastTimestamp := operation.StartTimestamp
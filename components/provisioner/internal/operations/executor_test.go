@@ -183,6 +183,37 @@ func TestStagesExecutor_Execute(t *testing.T) {
 		assert.False(t, mockStage.called)
 		assert.True(t, failureHandler.called)
 	})
+
+	t.Run("should use installation timeout of operation instead of time limit of waiting for installation", func(t *testing.T) {
+		// given
+		operationWithTimeout := operation
+		operationWithTimeout.InstallationTimeoutSeconds = 3600
+
+		dbSession := &mocks.ReadWriteSession{}
+		dbSession.On("GetOperation", operationId).Return(operationWithTimeout, nil)
+		dbSession.On("GetCluster", clusterId).Return(cluster, nil)
+		dbSession.On("TransitionOperation", operationId, "Provisioning steps finished", model.FinishedStage, mock.AnythingOfType("time.Time")).
+			Return(nil)
+		dbSession.On("UpdateOperationState", operationId, "Operation succeeded", model.Succeeded, mock.AnythingOfType("time.Time")).
+			Return(nil)
+
+		mockStage := NewMockStep(model.WaitingForInstallation, model.FinishedStage, 0, 0*time.Second)
+
+		installationStages := map[model.OperationStage]Step{
+			model.WaitingForInstallation: mockStage,
+		}
+
+		directorClient := &directorMocks.DirectorClient{}
+
+		executor := NewExecutor(dbSession, model.Upgrade, installationStages, failure.NewNoopFailureHandler(), directorClient)
+
+		// when
+		result := executor.Execute(operationId)
+
+		// then
+		assert.False(t, result.Requeue)
+		assert.True(t, mockStage.called)
+	})
 }
 
 type mockStep struct {
@@ -253,7 +253,7 @@ func (r *service) UpgradeRuntime(runtimeId string, input gqlschema.UpgradeRuntim
 	}
 	defer txSession.RollbackUnlessCommitted()
 
-	operation, dberr := r.setUpgradeStarted(txSession, cluster, kymaConfig)
+	operation, dberr := r.setUpgradeStarted(txSession, cluster, kymaConfig, input.InstallationTimeoutSeconds)
 	if dberr != nil {
 		return &gqlschema.OperationStatus{}, apperrors.Internal("failed to set upgrade started: %s", dberr.Error())
 	}
@@ -398,14 +398,18 @@ func (r *service) setGardenerShootUpgradeStarted(txSession dbsession.WriteSessio
 	return operation, nil
 }
 
-func (r *service) setUpgradeStarted(txSession dbsession.WriteSession, cluster model.Cluster, kymaConfig model.KymaConfig) (model.Operation, dberrors.Error) {
+func (r *service) setUpgradeStarted(txSession dbsession.WriteSession, cluster model.Cluster, kymaConfig model.KymaConfig, installationTimeoutSeconds *int) (model.Operation, dberrors.Error) {
 
 	err := txSession.InsertKymaConfig(kymaConfig)
 	if err != nil {
 		return model.Operation{}, err.Append("Failed to insert Kyma Config")
 	}
 
-	operation, err := r.setOperationStarted(txSession, cluster.ID, model.Upgrade, model.StartingUpgrade, time.Now(), "Starting Kyma upgrade")
+	operation := r.newOperation(cluster.ID, model.Upgrade, model.StartingUpgrade, time.Now(), "Starting Kyma upgrade")
+	if installationTimeoutSeconds != nil {
+		operation.InstallationTimeoutSeconds = *installationTimeoutSeconds
+	}
+	err = txSession.InsertOperation(operation)
 	if err != nil {
 		return model.Operation{}, err.Append("Failed to set operation started")
 	}
@@ -438,10 +442,24 @@ func (r *service) setOperationStarted(
 	operationStage model.OperationStage,
 	timestamp time.Time,
 	message string) (model.Operation, dberrors.Error) {
-	id := r.uuidGenerator.New()
+	operation := r.newOperation(runtimeID, operationType, operationStage, timestamp, message)
+
+	err := dbSession.InsertOperation(operation)
+	if err != nil {
+		return model.Operation{}, err.Append("failed to insert operation")
+	}
 
-	operation := model.Operation{
-		ID:             id,
+	return operation, nil
+}
+
+func (r *service) newOperation(
+	runtimeID string,
+	operationType model.OperationType,
+	operationStage model.OperationStage,
+	timestamp time.Time,
+	message string) model.Operation {
+	return model.Operation{
+		ID:             r.uuidGenerator.New(),
 		Type:           operationType,
 		StartTimestamp: timestamp,
 		State:          model.InProgress,
@@ -450,11 +468,4 @@ func (r *service) setOperationStarted(
 		Stage:          operationStage,
 		LastTransition: &timestamp,
 	}
-
-	err := dbSession.InsertOperation(operation)
-	if err != nil {
-		return model.Operation{}, err.Append("failed to insert operation")
-	}
-
-	return operation, nil
 }
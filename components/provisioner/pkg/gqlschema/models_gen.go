@@ -204,7 +204,8 @@ type RuntimeStatus struct {
 }
 
 type UpgradeRuntimeInput struct {
-	KymaConfig *KymaConfigInput `json:"kymaConfig"`
+	KymaConfig                 *KymaConfigInput `json:"kymaConfig"`
+	InstallationTimeoutSeconds *int             `json:"installationTimeoutSeconds"`
 }
 
 type UpgradeShootInput struct {
@@ -904,7 +904,8 @@ input ComponentConfigurationInput {
 }
 
 input UpgradeRuntimeInput {
-    kymaConfig: KymaConfigInput! # Kyma config to upgrade to
+    kymaConfig: KymaConfigInput!     # Kyma config to upgrade to
+    installationTimeoutSeconds: Int  # Time limit of the Kyma upgrade installation, the default time limit of the Provisioner is used if not set
 }
 
 # Shoot Upgrade Input
@@ -5037,6 +5038,12 @@ func (ec *executionContext) unmarshalInputUpgradeRuntimeInput(ctx context.Contex
 			if err != nil {
 				return it, err
 			}
+		case "installationTimeoutSeconds":
+			var err error
+			it.InstallationTimeoutSeconds, err = ec.unmarshalOInt2ᚖint(ctx, v)
+			if err != nil {
+				return it, err
+			}
 		}
 	}
 
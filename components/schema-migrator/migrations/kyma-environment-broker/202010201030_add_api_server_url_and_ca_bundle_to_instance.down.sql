@@ -0,0 +1,3 @@
+ALTER TABLE instances
+  DROP COLUMN api_server_url,
+  DROP COLUMN ca_bundle;
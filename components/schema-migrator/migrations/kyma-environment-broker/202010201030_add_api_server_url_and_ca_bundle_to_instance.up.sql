@@ -0,0 +1,3 @@
+ALTER TABLE instances
+  ADD COLUMN api_server_url varchar(255) NOT NULL DEFAULT '',
+  ADD COLUMN ca_bundle text NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+DROP TABLE kyma_channel_subscriptions;
@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS kyma_channel_subscriptions (
+    global_account_id varchar(255) PRIMARY KEY,
+    channel varchar(32) NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,3 @@
+DROP VIEW IF EXISTS instances_with_state;
+
+DROP INDEX IF EXISTS operations_instance_id_created_at_idx;
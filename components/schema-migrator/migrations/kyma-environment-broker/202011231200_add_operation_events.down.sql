@@ -0,0 +1 @@
+DROP TABLE operation_events;
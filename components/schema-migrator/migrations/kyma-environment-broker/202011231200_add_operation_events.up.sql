@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS operation_events (
+    id varchar(255) PRIMARY KEY,
+    operation_id varchar(255) NOT NULL,
+    instance_id varchar(255) NOT NULL,
+    actor varchar(255) NOT NULL,
+    step_name varchar(255),
+    old_state varchar(32),
+    new_state varchar(32) NOT NULL,
+    description text,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX operation_events_operation_id_idx ON operation_events (operation_id);
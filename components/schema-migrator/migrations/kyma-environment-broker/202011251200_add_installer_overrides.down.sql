@@ -0,0 +1 @@
+DROP TABLE installer_overrides;
@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS installer_overrides (
+    global_account_id varchar(255) PRIMARY KEY,
+    data text NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
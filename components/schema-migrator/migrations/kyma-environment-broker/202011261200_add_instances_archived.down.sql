@@ -0,0 +1 @@
+DROP TABLE instances_archived;
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS instances_archived (
+    instance_id varchar(255) PRIMARY KEY,
+    runtime_id varchar(255) NOT NULL,
+    global_account_id varchar(255) NOT NULL,
+    sub_account_id varchar(255) NOT NULL,
+    service_id varchar(255) NOT NULL,
+    service_name varchar(255) NOT NULL,
+    service_plan_id varchar(255) NOT NULL,
+    service_plan_name varchar(255) NOT NULL,
+    dashboard_url varchar(255) NOT NULL,
+    provisioning_parameters text NOT NULL,
+    provider_region varchar(32) NOT NULL,
+    api_server_url varchar(255) NOT NULL DEFAULT '',
+    ca_bundle text NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    deleted_at TIMESTAMPTZ NOT NULL,
+    operations text NOT NULL,
+    archived_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS instances_archived_global_account_id_idx ON instances_archived (global_account_id);
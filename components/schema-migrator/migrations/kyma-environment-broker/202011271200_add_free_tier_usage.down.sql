@@ -0,0 +1 @@
+DROP TABLE free_tier_usage;
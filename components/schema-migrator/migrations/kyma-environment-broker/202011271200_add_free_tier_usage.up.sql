@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS free_tier_usage (
+    instance_id varchar(255) PRIMARY KEY,
+    global_account_id varchar(255) NOT NULL,
+    plan_id varchar(255) NOT NULL,
+    started_at TIMESTAMPTZ NOT NULL,
+    finished_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS free_tier_usage_global_account_id_idx ON free_tier_usage (global_account_id);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS instances_trial_sub_account_id_uidx;
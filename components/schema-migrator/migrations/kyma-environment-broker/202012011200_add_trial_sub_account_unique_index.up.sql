@@ -0,0 +1,2 @@
+-- only one instance of the trial plan is allowed per subaccount
+CREATE UNIQUE INDEX IF NOT EXISTS instances_trial_sub_account_id_uidx ON instances (sub_account_id) WHERE service_plan_id = '7d55d31d-35ae-4438-bf13-6ffdfa107d9f';
@@ -0,0 +1 @@
+DROP TABLE kill_switch_events;
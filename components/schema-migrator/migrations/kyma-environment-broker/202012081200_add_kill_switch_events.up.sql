@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS kill_switch_events (
+    id varchar(255) PRIMARY KEY,
+    scope varchar(32) NOT NULL,
+    name varchar(255) NOT NULL,
+    disabled boolean NOT NULL,
+    message text NOT NULL,
+    changed_by varchar(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
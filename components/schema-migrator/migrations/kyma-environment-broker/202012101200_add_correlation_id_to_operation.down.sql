@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS operations_correlation_id_idx;
+ALTER TABLE operations DROP COLUMN correlation_id;
@@ -0,0 +1,5 @@
+-- the correlation ID links the operation with the request which created it and the calls to the other components
+ALTER TABLE operations
+    ADD COLUMN correlation_id varchar(64);
+
+CREATE INDEX IF NOT EXISTS operations_correlation_id_idx ON operations (correlation_id);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS instances_created_at_instance_id_idx;
@@ -0,0 +1,2 @@
+-- the runtimes are paged with the cursor which points to the creation time and the ID of the last instance of the page
+CREATE INDEX IF NOT EXISTS instances_created_at_instance_id_idx ON instances (created_at, instance_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS instances_archived_search_idx;
+DROP INDEX IF EXISTS instances_search_idx;
@@ -0,0 +1,5 @@
+-- the runtimes are searched by the text contained in any of the instance ID, runtime ID, global account, subaccount, or dashboard URL,
+-- the expression must be the same as the one used by the search query of the broker, otherwise the index is not used
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX IF NOT EXISTS instances_search_idx ON instances USING gin ((instance_id || ' ' || runtime_id || ' ' || global_account_id || ' ' || sub_account_id || ' ' || dashboard_url) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS instances_archived_search_idx ON instances_archived USING gin ((instance_id || ' ' || runtime_id || ' ' || global_account_id || ' ' || sub_account_id || ' ' || dashboard_url) gin_trgm_ops);
@@ -0,0 +1,23 @@
+ALTER TABLE instances ADD COLUMN IF NOT EXISTS shoot_name varchar(255) NOT NULL DEFAULT '';
+ALTER TABLE instances_archived ADD COLUMN IF NOT EXISTS shoot_name varchar(255) NOT NULL DEFAULT '';
+
+-- the columns of instances.* are resolved when the view is created, so the view must be recreated to contain the new column
+DROP VIEW IF EXISTS instances_with_state;
+CREATE VIEW instances_with_state AS
+SELECT
+    instances.*,
+    last_operation.id AS last_operation_id,
+    last_operation.type AS last_operation_type,
+    last_operation.state AS last_operation_state,
+    last_operation.version AS last_operation_version,
+    last_operation.description AS last_operation_description,
+    last_operation.orchestration_id AS last_operation_orchestration_id,
+    last_operation.created_at AS last_operation_created_at
+FROM instances
+LEFT JOIN LATERAL (
+    SELECT id, type, state, version, description, orchestration_id, created_at
+    FROM operations
+    WHERE operations.instance_id = instances.instance_id
+    ORDER BY created_at DESC
+    LIMIT 1
+) last_operation ON true;
@@ -0,0 +1,3 @@
+ALTER TABLE operations
+    DROP COLUMN stage_started_at,
+    DROP COLUMN stage;
@@ -0,0 +1,4 @@
+-- the stage of the process the operation is in and the time the operation entered it, used to measure the stage durations
+ALTER TABLE operations
+    ADD COLUMN stage varchar(64) NOT NULL DEFAULT '',
+    ADD COLUMN stage_started_at TIMESTAMPTZ;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS orchestrations_created_at_orchestration_id_idx;
+DROP INDEX IF EXISTS operations_orchestration_id_created_at_id_idx;
@@ -0,0 +1,3 @@
+-- the orchestrations and their operations are paged with the cursor which points to the creation time and the ID of the last object of the page
+CREATE INDEX IF NOT EXISTS orchestrations_created_at_orchestration_id_idx ON orchestrations (created_at, orchestration_id);
+CREATE INDEX IF NOT EXISTS operations_orchestration_id_created_at_id_idx ON operations (orchestration_id, created_at, id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS job_runs_job_name_started_at_idx;
+DROP TABLE IF EXISTS job_runs;
+DROP TABLE IF EXISTS job_locks;
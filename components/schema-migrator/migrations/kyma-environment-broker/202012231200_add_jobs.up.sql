@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS job_locks (
+    job_name varchar(255) PRIMARY KEY,
+    owner varchar(255) NOT NULL,
+    locked_until TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS job_runs (
+    id varchar(255) PRIMARY KEY,
+    job_name varchar(255) NOT NULL,
+    owner varchar(255) NOT NULL,
+    trigger varchar(32) NOT NULL,
+    state varchar(32) NOT NULL,
+    error text NOT NULL,
+    started_at TIMESTAMPTZ NOT NULL,
+    finished_at TIMESTAMPTZ NOT NULL
+);
+
+-- the scheduler reads the latest run of the job to decide if the job is due
+CREATE INDEX IF NOT EXISTS job_runs_job_name_started_at_idx ON job_runs (job_name, started_at);
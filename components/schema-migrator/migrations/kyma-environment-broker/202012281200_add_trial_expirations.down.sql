@@ -0,0 +1 @@
+DROP TABLE IF EXISTS trial_expirations;
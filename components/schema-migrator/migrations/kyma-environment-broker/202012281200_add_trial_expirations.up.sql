@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS trial_expirations (
+    instance_id varchar(255) PRIMARY KEY,
+    expires_at TIMESTAMPTZ NOT NULL,
+    notified_at TIMESTAMPTZ NOT NULL,
+    deprovisioned_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,22 @@
+DROP VIEW IF EXISTS instances_with_state;
+
+ALTER TABLE instances DROP COLUMN IF EXISTS original_global_account_id;
+
+CREATE VIEW instances_with_state AS
+SELECT
+    instances.*,
+    last_operation.id AS last_operation_id,
+    last_operation.type AS last_operation_type,
+    last_operation.state AS last_operation_state,
+    last_operation.version AS last_operation_version,
+    last_operation.description AS last_operation_description,
+    last_operation.orchestration_id AS last_operation_orchestration_id,
+    last_operation.created_at AS last_operation_created_at
+FROM instances
+LEFT JOIN LATERAL (
+    SELECT id, type, state, version, description, orchestration_id, created_at
+    FROM operations
+    WHERE operations.instance_id = instances.instance_id
+    ORDER BY created_at DESC
+    LIMIT 1
+) last_operation ON true;
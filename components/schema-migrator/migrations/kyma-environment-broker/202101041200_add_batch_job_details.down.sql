@@ -0,0 +1,5 @@
+ALTER TABLE job_runs
+    DROP COLUMN details;
+
+ALTER TABLE operations
+    DROP COLUMN retried_at;
@@ -0,0 +1,7 @@
+-- the time the operation was retried by the operations batch job, the timeout of the retried operation is measured from it
+ALTER TABLE operations
+    ADD COLUMN retried_at TIMESTAMPTZ;
+
+-- the job specific progress of the job run, e.g. the counters of the operations batch job
+ALTER TABLE job_runs
+    ADD COLUMN details text NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+ALTER TABLE orchestrations
+    DROP COLUMN type;
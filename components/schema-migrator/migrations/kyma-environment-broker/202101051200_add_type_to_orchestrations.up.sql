@@ -0,0 +1,3 @@
+-- the type of the operations performed by the orchestration, the existing orchestrations upgrade Kyma
+ALTER TABLE orchestrations
+    ADD COLUMN type varchar(32) NOT NULL DEFAULT 'upgradeKyma';
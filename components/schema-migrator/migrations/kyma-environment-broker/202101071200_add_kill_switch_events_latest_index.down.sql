@@ -0,0 +1 @@
+DROP INDEX IF EXISTS kill_switch_events_scope_name_created_at_idx;
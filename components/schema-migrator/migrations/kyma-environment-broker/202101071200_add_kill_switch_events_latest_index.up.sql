@@ -0,0 +1,2 @@
+-- every provisioning request reads only the latest event of every kill switch
+CREATE INDEX IF NOT EXISTS kill_switch_events_scope_name_created_at_idx ON kill_switch_events (scope, name, created_at);
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE operation DROP COLUMN installation_timeout_seconds;
+
+COMMIT;
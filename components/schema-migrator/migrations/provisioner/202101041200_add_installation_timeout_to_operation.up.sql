@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE operation ADD COLUMN installation_timeout_seconds integer NOT NULL DEFAULT 0;
+
+COMMIT;